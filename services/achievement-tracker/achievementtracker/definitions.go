@@ -0,0 +1,279 @@
+package achievementtracker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/eventbus/events"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+)
+
+// DefaultDefinitionsBucket is the MinIO bucket with achievement definitions.
+const DefaultDefinitionsBucket = "achievements"
+
+// DefaultReloadInterval is how often the definitions are read again from the bucket.
+const DefaultReloadInterval = 5 * time.Minute
+
+// Definition describes an achievement: every event matching the conditions adds to the counter
+// of the credited player, and the achievement unlocks once the counter reaches Threshold.
+// Conditions are glob patterns (path.Match), e.g. "player.used_*"; a list matches if any pattern does.
+type Definition struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Worlds limits the achievement to these worlds; empty applies to every world.
+	Worlds     []string `json:"worlds,omitempty"`
+	EventTypes []string `json:"event_types"`
+	// Payload maps payload paths (e.g. "result") to value patterns.
+	Payload map[string]string `json:"payload,omitempty"`
+	// Player is the payload path of the credited player; empty credits the player entity of the event.
+	Player string `json:"player,omitempty"`
+	// Count is the payload path of the amount added per event; empty counts events.
+	Count string `json:"count,omitempty"`
+	// Threshold is the counter value that unlocks the achievement; zero unlocks on the first event.
+	Threshold float64             `json:"threshold,omitempty"`
+	Reward    *events.QuestReward `json:"reward,omitempty"`
+}
+
+// defaultDefinitions apply when the definitions bucket is unavailable or empty.
+var defaultDefinitions = []Definition{
+	{ID: "first-breakthrough", Name: "Первый прорыв", Description: "Успешно прорвать узкое место культивации",
+		EventTypes: []string{"cultivation.breakthrough"}, Payload: map[string]string{"result": "success"}},
+	{ID: "skill-adept", Name: "Адепт навыков", Description: "Применить навыки 100 раз",
+		EventTypes: []string{"player.used_skill"}, Threshold: 100},
+	{ID: "city-helper", Name: "Опора города", Description: "Получить награды за 10 квестов",
+		EventTypes: []string{"quest.reward.granted"}, Threshold: 10, Reward: &events.QuestReward{Gold: 100, Reputation: 5}},
+	{ID: "wanderer", Name: "Странник", Description: "Совершить 1000 перемещений",
+		EventTypes: []string{"player.moved"}, Threshold: 1000},
+}
+
+// threshold returns the counter value that unlocks the achievement.
+func (d Definition) threshold() float64 {
+	if d.Threshold <= 0 {
+		return 1
+	}
+	return d.Threshold
+}
+
+// validate rejects definitions without an ID or event types and with invalid patterns.
+func (d Definition) validate() error {
+	if d.ID == "" {
+		return fmt.Errorf("achievement has no id")
+	}
+	if len(d.EventTypes) == 0 {
+		return fmt.Errorf("achievement %q has no event_types", d.ID)
+	}
+	patterns := slices.Clone(d.EventTypes)
+	for _, pattern := range d.Payload {
+		patterns = append(patterns, pattern)
+	}
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("achievement %q has invalid pattern %q: %w", d.ID, pattern, err)
+		}
+	}
+	return nil
+}
+
+// matches reports whether an event in worldID counts towards the achievement.
+func (d Definition) matches(worldID string, ev eventbus.Event) bool {
+	if len(d.Worlds) > 0 && !slices.Contains(d.Worlds, worldID) {
+		return false
+	}
+	if !matchAny(d.EventTypes, ev.Type) {
+		return false
+	}
+	pa := ev.Path()
+	for field, pattern := range d.Payload {
+		value, ok := pa.GetAny(field)
+		if !ok || value == nil || !matchPattern(pattern, fmt.Sprint(value)) {
+			return false
+		}
+	}
+	return true
+}
+
+// player returns the ID of the credited player; empty when the event has none.
+func (d Definition) player(ev eventbus.Event) string {
+	if d.Player != "" {
+		playerID, _ := ev.Path().GetString(d.Player)
+		return playerID
+	}
+	if info, ok := ev.GetEntityIDWithFallback(); ok {
+		if info.Type != "" && info.Type != "player" {
+			return ""
+		}
+		return info.ID
+	}
+	playerID, _ := ev.Path().GetString("player_id")
+	return playerID
+}
+
+// amount returns how much the event adds to the counter.
+func (d Definition) amount(ev eventbus.Event) float64 {
+	if d.Count == "" {
+		return 1
+	}
+	amount, _ := ev.Path().GetFloat(d.Count)
+	return amount
+}
+
+// matchAny reports whether value matches one of the patterns.
+func matchAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if matchPattern(pattern, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchPattern(pattern, value string) bool {
+	ok, err := path.Match(pattern, value)
+	return err == nil && ok
+}
+
+// Definitions holds the achievement definitions. They are read from every *.json object of the
+// definitions bucket (a definition or a list of definitions per object); the built-in definitions
+// apply until the bucket has any.
+type Definitions struct {
+	storage storage.ObjectStorage // nil — built-in definitions only
+	bucket  string
+
+	mu          sync.RWMutex
+	definitions []Definition
+}
+
+// NewDefinitions creates a store with the built-in definitions; see UseStorage for loading them from MinIO.
+func NewDefinitions() *Definitions {
+	return &Definitions{bucket: DefaultDefinitionsBucket, definitions: defaultDefinitions}
+}
+
+// UseStorage enables loading definitions from the bucket; empty bucket uses DefaultDefinitionsBucket.
+func (d *Definitions) UseStorage(client storage.ObjectStorage, bucket string) {
+	if bucket == "" {
+		bucket = DefaultDefinitionsBucket
+	}
+	d.storage, d.bucket = client, bucket
+}
+
+// Reload reads the definitions from the bucket. Invalid objects and definitions are skipped;
+// an unavailable bucket keeps the current definitions.
+func (d *Definitions) Reload() error {
+	if d.storage == nil {
+		return nil
+	}
+	objects, err := d.storage.ListObjects(d.bucket, "")
+	if err != nil && !storage.IsNotFound(err) {
+		return fmt.Errorf("list achievement definitions: %w", err)
+	}
+
+	var loaded []Definition
+	seen := make(map[string]bool)
+	for _, object := range objects {
+		if !strings.HasSuffix(object.Key, ".json") {
+			continue
+		}
+		data, err := d.storage.GetObject(d.bucket, object.Key)
+		if err != nil {
+			if storage.IsNotFound(err) {
+				continue // Removed while listing
+			}
+			return fmt.Errorf("read achievement definitions %s: %w", object.Key, err)
+		}
+		definitions, err := decodeDefinitions(data)
+		if err != nil {
+			logging.Warnf("Ignoring achievement definitions %s: %v", object.Key, err)
+			continue
+		}
+		for _, definition := range definitions {
+			if err := definition.validate(); err != nil {
+				logging.Warnf("Ignoring achievement in %s: %v", object.Key, err)
+				continue
+			}
+			// Objects are listed newest first: the newest definition of an ID wins
+			if seen[definition.ID] {
+				continue
+			}
+			seen[definition.ID] = true
+			loaded = append(loaded, definition)
+		}
+	}
+	if len(loaded) == 0 {
+		loaded = defaultDefinitions
+	}
+
+	d.mu.Lock()
+	d.definitions = loaded
+	d.mu.Unlock()
+	logging.Infof("Loaded %d achievement definitions from %s", len(loaded), d.bucket)
+	return nil
+}
+
+// Run loads the definitions and reloads them every interval until ctx is cancelled.
+func (d *Definitions) Run(ctx context.Context, interval time.Duration) {
+	if d.storage == nil {
+		return
+	}
+	if interval <= 0 {
+		interval = DefaultReloadInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := d.Reload(); err != nil {
+			logging.Warnf("Failed to reload achievement definitions, keeping %d current: %v", len(d.All()), err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Matching returns the definitions an event in worldID counts towards.
+func (d *Definitions) Matching(worldID string, ev eventbus.Event) []Definition {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	var matched []Definition
+	for _, definition := range d.definitions {
+		if definition.matches(worldID, ev) {
+			matched = append(matched, definition)
+		}
+	}
+	return matched
+}
+
+// All returns the current definitions.
+func (d *Definitions) All() []Definition {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return slices.Clone(d.definitions)
+}
+
+// decodeDefinitions reads a definition or a list of definitions.
+func decodeDefinitions(data []byte) ([]Definition, error) {
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("[")) {
+		var definitions []Definition
+		if err := json.Unmarshal(data, &definitions); err != nil {
+			return nil, err
+		}
+		return definitions, nil
+	}
+	var definition Definition
+	if err := json.Unmarshal(data, &definition); err != nil {
+		return nil, err
+	}
+	return []Definition{definition}, nil
+}
@@ -0,0 +1,76 @@
+package achievementtracker
+
+import (
+	"testing"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/minio/miniotest"
+)
+
+func TestDefinitionsReload(t *testing.T) {
+	storage := miniotest.New()
+	storage.Put("achievements", "combat.json", `[
+		{"id": "slayer", "name": "Slayer", "event_types": ["npc.killed"], "player": "killer.id", "threshold": 3},
+		{"id": "broken", "event_types": ["npc.*["]}
+	]`)
+	storage.Put("achievements", "gold.json", `{"id": "rich", "name": "Rich", "event_types": ["trade.*"], "count": "gold", "threshold": 1000, "worlds": ["ash-realm"]}`)
+	storage.Put("achievements", "notes.txt", `not a definition`)
+	storage.Put("achievements", "invalid.json", `{`)
+
+	definitions := NewDefinitions()
+	definitions.UseStorage(storage, "")
+	if err := definitions.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	all := definitions.All()
+	if len(all) != 2 {
+		t.Fatalf("expected the two valid definitions, got %+v", all)
+	}
+
+	trade := eventbus.NewEvent("trade.completed", "city-governor", "ash-realm", map[string]interface{}{"gold": 250.0})
+	if matched := definitions.Matching("ash-realm", trade); len(matched) != 1 || matched[0].ID != "rich" || matched[0].amount(trade) != 250 {
+		t.Errorf("expected rich to match with 250 gold, got %+v", matched)
+	}
+	if matched := definitions.Matching("pain-realm", trade); len(matched) != 0 {
+		t.Errorf("rich is limited to ash-realm, got %+v", matched)
+	}
+
+	kill := eventbus.NewEvent("npc.killed", "entity-actor", "ash-realm", map[string]interface{}{"killer": map[string]interface{}{"id": "player:kain"}})
+	matched := definitions.Matching("ash-realm", kill)
+	if len(matched) != 1 || matched[0].player(kill) != "player:kain" {
+		t.Errorf("expected slayer to credit the killer, got %+v", matched)
+	}
+}
+
+func TestDefinitionsFallback(t *testing.T) {
+	storage := miniotest.New()
+	definitions := NewDefinitions()
+	definitions.UseStorage(storage, "achievements")
+	if err := definitions.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if len(definitions.All()) != len(defaultDefinitions) {
+		t.Fatalf("expected built-in definitions for an empty bucket, got %+v", definitions.All())
+	}
+
+	success := eventbus.NewEvent("cultivation.breakthrough", "cultivation-module", "ash-realm", map[string]interface{}{"result": "success"})
+	failed := eventbus.NewEvent("cultivation.breakthrough", "cultivation-module", "ash-realm", map[string]interface{}{"result": "failed"})
+	if matched := definitions.Matching("ash-realm", success); len(matched) != 1 || matched[0].ID != "first-breakthrough" {
+		t.Errorf("expected a successful breakthrough to match, got %+v", matched)
+	}
+	if matched := definitions.Matching("ash-realm", failed); len(matched) != 0 {
+		t.Errorf("expected a failed breakthrough not to match, got %+v", matched)
+	}
+}
+
+func TestDefinitionPlayer(t *testing.T) {
+	definition := Definition{ID: "any", EventTypes: []string{"*"}}
+	payload := eventbus.NewEventPayload().WithEntity("npc:guard", "npc", "")
+	if id := definition.player(eventbus.NewStructuredEvent("npc.moved", "entity-actor", "ash-realm", payload)); id != "" {
+		t.Errorf("events of other entities must not credit anyone, got %q", id)
+	}
+	legacy := eventbus.NewEvent("player.moved", "game-service", "ash-realm", map[string]interface{}{"player_id": "player:kain"})
+	if id := definition.player(legacy); id != "player:kain" {
+		t.Errorf("expected player_id of a legacy event, got %q", id)
+	}
+}
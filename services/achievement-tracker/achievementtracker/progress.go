@@ -0,0 +1,220 @@
+package achievementtracker
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/entity"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+)
+
+// Progress is the achievement progress of a player, stored in the player entity under "achievements".
+type Progress struct {
+	// Counters holds the counter of every achievement the player has progressed in
+	Counters map[string]float64 `json:"counters,omitempty"`
+	// Unlocked holds the unlock time of every achievement the player has
+	Unlocked  map[string]time.Time `json:"unlocked,omitempty"`
+	UpdatedAt time.Time            `json:"updated_at"`
+}
+
+// clone returns a copy that does not share the maps.
+func (p Progress) clone() Progress {
+	c := Progress{UpdatedAt: p.UpdatedAt}
+	if p.Counters != nil {
+		c.Counters = make(map[string]float64, len(p.Counters))
+		for id, value := range p.Counters {
+			c.Counters[id] = value
+		}
+	}
+	if p.Unlocked != nil {
+		c.Unlocked = make(map[string]time.Time, len(p.Unlocked))
+		for id, at := range p.Unlocked {
+			c.Unlocked[id] = at
+		}
+	}
+	return c
+}
+
+// add increments the counter of an achievement not unlocked yet. Reports whether the counter
+// changed and whether it reached the threshold, unlocking the achievement at now.
+func (p *Progress) add(definition Definition, amount float64, now time.Time) (counted, unlocked bool) {
+	if _, ok := p.Unlocked[definition.ID]; ok || amount == 0 {
+		return false, false
+	}
+	if p.Counters == nil {
+		p.Counters = make(map[string]float64)
+	}
+	p.Counters[definition.ID] += amount
+	if p.Counters[definition.ID] < definition.threshold() {
+		return true, false
+	}
+	if p.Unlocked == nil {
+		p.Unlocked = make(map[string]time.Time)
+	}
+	p.Unlocked[definition.ID] = now
+	return true, true
+}
+
+// playerProgress is what AchievementTracker knows about a player.
+type playerProgress struct {
+	progress Progress
+	// worldID is the world of the last event of the player, used to persist the progress
+	worldID string
+	// dirty is set when the progress has changed since it was last persisted
+	dirty bool
+}
+
+// PlayerProgress is the progress of a player waiting to be persisted.
+type PlayerProgress struct {
+	PlayerID string
+	WorldID  string
+	Progress Progress
+}
+
+// ProgressStore keeps achievement progress in memory. Players not seen yet are read from their
+// entities in MinIO, which EntityManager keeps up to date from state_changes.
+type ProgressStore struct {
+	storage storage.ObjectStorage // nil — progress starts empty
+
+	mu      sync.Mutex
+	players map[string]*playerProgress
+}
+
+// NewProgressStore creates an in-memory progress store; see UseStorage for loading stored progress.
+func NewProgressStore() *ProgressStore {
+	return &ProgressStore{players: make(map[string]*playerProgress)}
+}
+
+// UseStorage enables reading stored progress from the entity buckets.
+func (st *ProgressStore) UseStorage(client storage.ObjectStorage) {
+	st.storage = client
+}
+
+// Update applies fn to the progress of the player under the store lock and returns a copy of the result.
+// fn reports whether it changed the progress and whether the caller persists the returned progress
+// itself; progress changed but not persisted is returned by the next TakeDirty.
+func (st *ProgressStore) Update(playerID, worldID string, fn func(progress *Progress) (changed, persisted bool)) Progress {
+	st.mu.Lock()
+	player, ok := st.players[playerID]
+	st.mu.Unlock()
+	if !ok {
+		player = &playerProgress{progress: st.load(playerID, worldID)}
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if current, ok := st.players[playerID]; ok {
+		// Loaded concurrently by another event
+		player = current
+	}
+	st.players[playerID] = player
+
+	changed, persisted := fn(&player.progress)
+	if changed {
+		player.progress.UpdatedAt = time.Now().UTC()
+		player.dirty = true
+	}
+	if persisted {
+		player.dirty = false
+	}
+	if worldID != "" {
+		player.worldID = worldID
+	}
+	return player.progress.clone()
+}
+
+// Get returns the progress of the player.
+func (st *ProgressStore) Get(playerID, worldID string) Progress {
+	return st.Update(playerID, worldID, func(*Progress) (bool, bool) { return false, false })
+}
+
+// TakeDirty returns the changed progress of every player and marks it persisted.
+func (st *ProgressStore) TakeDirty() []PlayerProgress {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	var dirty []PlayerProgress
+	for playerID, player := range st.players {
+		if !player.dirty {
+			continue
+		}
+		player.dirty = false
+		dirty = append(dirty, PlayerProgress{PlayerID: playerID, WorldID: player.worldID, Progress: player.progress.clone()})
+	}
+	return dirty
+}
+
+// MarkDirty returns the progress of the player by the next TakeDirty, e.g. after a failed publish.
+func (st *ProgressStore) MarkDirty(playerID string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if player, ok := st.players[playerID]; ok {
+		player.dirty = true
+	}
+}
+
+// load reads the achievement progress of the player entity; a missing entity or field starts from zero.
+func (st *ProgressStore) load(playerID, worldID string) Progress {
+	var progress Progress
+	if st.storage == nil {
+		return progress
+	}
+	for _, bucket := range []string{"entities-" + worldID, "entities-global"} {
+		data, err := st.storage.GetObject(bucket, playerID+".json")
+		if err != nil {
+			if !storage.IsNotFound(err) {
+				logging.Errorf("Failed to load achievements of %s, starting from zero: %v", playerID, err)
+				return progress
+			}
+			continue
+		}
+		if err := decodeProgress(data, &progress); err != nil {
+			logging.Warnf("Ignoring stored achievements of %s: %v", playerID, err)
+			return Progress{}
+		}
+		return progress
+	}
+	return progress
+}
+
+// decodeProgress reads the "achievements" field of a stored entity into progress.
+func decodeProgress(data []byte, progress *Progress) error {
+	var ent entity.Entity
+	if err := json.Unmarshal(data, &ent); err != nil {
+		return err
+	}
+	raw, ok := ent.Payload["achievements"]
+	if !ok {
+		return nil
+	}
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(encoded, progress); err != nil {
+		return fmt.Errorf("decode achievements: %w", err)
+	}
+	return nil
+}
+
+// progressValue returns the progress as it is stored in the player entity.
+func progressValue(progress Progress) map[string]interface{} {
+	var value map[string]interface{}
+	encoded, _ := json.Marshal(progress)
+	json.Unmarshal(encoded, &value)
+	return value
+}
+
+// stateChanges builds the state_changes that make EntityManager store the progress in the player entity.
+func stateChanges(playerID string, progress Progress) []interface{} {
+	return []interface{}{
+		map[string]interface{}{
+			"entity_id": playerID,
+			"operations": []interface{}{
+				map[string]interface{}{"op": "set", "path": "achievements", "value": progressValue(progress)},
+			},
+		},
+	}
+}
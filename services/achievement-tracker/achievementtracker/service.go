@@ -0,0 +1,60 @@
+package achievementtracker
+
+import (
+	"context"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	storage "multiverse-core.io/shared/minio"
+)
+
+// Service manages the AchievementTracker lifecycle.
+type Service struct {
+	bus            *eventbus.EventBus
+	tracker        *AchievementTracker
+	reloadInterval time.Duration
+	flushInterval  time.Duration
+}
+
+// NewService creates a new AchievementTracker service.
+func NewService(bus *eventbus.EventBus) *Service {
+	return &Service{
+		bus:            bus,
+		tracker:        NewAchievementTracker(bus),
+		reloadInterval: DefaultReloadInterval,
+		flushInterval:  DefaultFlushInterval,
+	}
+}
+
+// UseStorage loads achievement definitions from the bucket and stored progress from the player
+// entities in MinIO; definitions are reloaded every reloadInterval.
+func (s *Service) UseStorage(client storage.ObjectStorage, bucket string, reloadInterval time.Duration) {
+	s.tracker.definitions.UseStorage(client, bucket)
+	s.tracker.progress.UseStorage(client)
+	s.reloadInterval = reloadInterval
+}
+
+// SetFlushInterval sets how often changed progress is persisted through EntityManager.
+func (s *Service) SetFlushInterval(interval time.Duration) {
+	s.flushInterval = interval
+}
+
+// Run starts the service and blocks until context is cancelled.
+func (s *Service) Run(ctx context.Context) error {
+	go s.tracker.definitions.Run(ctx, s.reloadInterval)
+
+	// Subscribe to relevant event topics
+	topics := []string{
+		eventbus.TopicPlayerEvents,
+		eventbus.TopicWorldEvents,
+		eventbus.TopicGameEvents,
+	}
+
+	for _, topic := range topics {
+		go s.bus.Subscribe(ctx, topic, "achievement-tracker-group", s.tracker.HandleEvent)
+	}
+
+	// Returns after the final flush on shutdown
+	s.tracker.RunFlush(ctx, s.flushInterval)
+	return ctx.Err()
+}
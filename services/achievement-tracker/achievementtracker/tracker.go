@@ -0,0 +1,178 @@
+// Package achievementtracker tracks player achievements from the event stream.
+package achievementtracker
+
+import (
+	"context"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/eventbus/events"
+	"multiverse-core.io/shared/logging"
+
+	"github.com/google/uuid"
+)
+
+// source is the source of the events AchievementTracker publishes.
+const source = "achievement-tracker"
+
+// EventProgressUpdated persists the achievement progress of a player through its state_changes.
+const EventProgressUpdated = "achievement.progress.updated"
+
+// DefaultFlushInterval is how often changed progress is persisted.
+const DefaultFlushInterval = 10 * time.Second
+
+// AchievementTracker counts matching events per player and publishes achievement.unlocked.
+type AchievementTracker struct {
+	definitions *Definitions
+	progress    *ProgressStore
+	// publish sends an event to game_events; replaced in tests
+	publish func(ctx context.Context, event eventbus.Event) error
+	now     func() time.Time
+}
+
+// NewAchievementTracker creates a new AchievementTracker with the built-in definitions.
+func NewAchievementTracker(bus *eventbus.EventBus) *AchievementTracker {
+	return &AchievementTracker{
+		definitions: NewDefinitions(),
+		progress:    NewProgressStore(),
+		publish:     bus.PublishGameEvent,
+		now:         time.Now,
+	}
+}
+
+// HandleEvent credits the players of an event with progress in every matching achievement.
+func (at *AchievementTracker) HandleEvent(ev eventbus.Event) {
+	// Own events never count: an unlock must not advance another achievement of the same player
+	if ev.Source == source {
+		return
+	}
+	worldID := eventbus.GetWorldIDFromEvent(ev)
+	matched := at.definitions.Matching(worldID, ev)
+	if len(matched) == 0 {
+		return
+	}
+
+	// An event may credit different players in different achievements
+	var players []string
+	byPlayer := make(map[string][]Definition)
+	for _, definition := range matched {
+		playerID := definition.player(ev)
+		if playerID == "" {
+			continue
+		}
+		if _, ok := byPlayer[playerID]; !ok {
+			players = append(players, playerID)
+		}
+		byPlayer[playerID] = append(byPlayer[playerID], definition)
+	}
+	for _, playerID := range players {
+		at.credit(ev, playerID, worldID, byPlayer[playerID])
+	}
+}
+
+// credit adds the event to the player's counters and publishes the achievements it unlocks.
+// Progress is persisted with the unlock events or by the next flush.
+func (at *AchievementTracker) credit(ev eventbus.Event, playerID, worldID string, definitions []Definition) {
+	now := at.now().UTC()
+	var unlocked []Definition
+	progress := at.progress.Update(playerID, worldID, func(progress *Progress) (bool, bool) {
+		changed := false
+		for _, definition := range definitions {
+			counted, done := progress.add(definition, definition.amount(ev), now)
+			changed = changed || counted
+			if done {
+				unlocked = append(unlocked, definition)
+			}
+		}
+		return changed, len(unlocked) > 0
+	})
+
+	for _, definition := range unlocked {
+		at.publishUnlocked(ev, playerID, worldID, definition, progress)
+	}
+}
+
+// publishUnlocked publishes achievement.unlocked in the scope of the event that completed the achievement.
+// The event carries the whole progress of the player in its state_changes.
+func (at *AchievementTracker) publishUnlocked(cause eventbus.Event, playerID, worldID string, definition Definition, progress Progress) {
+	scope := eventbus.GetScopeFromEvent(cause)
+	unlockedEvent, err := events.NewChild(cause, source, events.AchievementUnlocked{
+		Subject: events.Subject{
+			Entity: events.EntityOf(playerID, "player", ""),
+			World:  events.WorldOf(worldID),
+			Scope:  scope,
+		},
+		AchievementID: definition.ID,
+		Name:          definition.Name,
+		Description:   definition.Description,
+		Progress:      progress.Counters[definition.ID],
+		Threshold:     definition.threshold(),
+		Reward:        definition.Reward,
+		UnlockedAt:    progress.Unlocked[definition.ID],
+	})
+	if err != nil {
+		logging.Errorf("Failed to build achievement.unlocked for %s: %v", definition.ID, err)
+		at.progress.MarkDirty(playerID)
+		return
+	}
+	// EntityManager stores the progress in the player entity
+	unlockedEvent.Payload["state_changes"] = stateChanges(playerID, progress)
+	unlockedEvent.ID = "achievement-" + uuid.New().String()[:8]
+	unlockedEvent.Timestamp = time.Now()
+	unlockedEvent.Scope = scope
+
+	if err := at.publish(context.Background(), unlockedEvent); err != nil {
+		logging.Errorf("Failed to publish achievement %s of %s: %v", definition.ID, playerID, err)
+		at.progress.MarkDirty(playerID)
+		return
+	}
+	logging.Infof("Player %s unlocked achievement %s in %s", playerID, definition.ID, worldID)
+}
+
+// Flush publishes achievement.progress.updated for every player whose progress changed since it
+// was last persisted. Returns the number of players persisted.
+func (at *AchievementTracker) Flush(ctx context.Context) int {
+	flushed := 0
+	for _, player := range at.progress.TakeDirty() {
+		payload := eventbus.NewEventPayload().
+			WithEntity(player.PlayerID, "player", "").
+			WithWorld(player.WorldID)
+
+		eventbus.SetNested(payload.GetCustom(), "achievements", progressValue(player.Progress))
+		// EntityManager stores the progress in the player entity
+		eventbus.SetNested(payload.GetCustom(), "state_changes", stateChanges(player.PlayerID, player.Progress))
+
+		progressEvent := eventbus.NewStructuredEvent(EventProgressUpdated, source, player.WorldID, payload)
+		progressEvent.ID = "achievement-progress-" + uuid.New().String()[:8]
+		progressEvent.Timestamp = time.Now()
+
+		if err := at.publish(ctx, progressEvent); err != nil {
+			logging.Errorf("Failed to persist achievements of %s: %v", player.PlayerID, err)
+			at.progress.MarkDirty(player.PlayerID)
+			continue
+		}
+		flushed++
+	}
+	return flushed
+}
+
+// RunFlush persists changed progress every interval until ctx is cancelled, then flushes once more.
+func (at *AchievementTracker) RunFlush(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultFlushInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// Progress of the last interval is not lost on shutdown
+			if n := at.Flush(context.Background()); n > 0 {
+				logging.Infof("Persisted achievements of %d players on shutdown", n)
+			}
+			return
+		case <-ticker.C:
+			at.Flush(ctx)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package achievementtracker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/eventbus/events"
+	"multiverse-core.io/shared/minio/miniotest"
+)
+
+func newTestTracker(definitions ...Definition) (*AchievementTracker, *[]eventbus.Event) {
+	var published []eventbus.Event
+	tracker := NewAchievementTracker(nil)
+	tracker.definitions.definitions = definitions
+	tracker.publish = func(ctx context.Context, event eventbus.Event) error {
+		published = append(published, event)
+		return nil
+	}
+	return tracker, &published
+}
+
+func playerEvent(eventType, playerID string, custom map[string]interface{}) eventbus.Event {
+	payload := eventbus.NewEventPayload().
+		WithEntity(playerID, "player", "").
+		WithWorld("ash-realm").
+		WithScope("city-ashes", "city")
+	for key, value := range custom {
+		eventbus.SetNested(payload.GetCustom(), key, value)
+	}
+	ev := eventbus.NewStructuredEvent(eventType, "game-service", "ash-realm", payload)
+	ev.ID = "ev-" + eventType
+	return ev
+}
+
+func TestTrackerUnlock(t *testing.T) {
+	reward := &events.QuestReward{Gold: 50, Reputation: 3}
+	tracker, published := newTestTracker(
+		Definition{ID: "skill-adept", Name: "Skill adept", EventTypes: []string{"player.used_skill"}, Threshold: 3, Reward: reward},
+		Definition{ID: "fire-master", EventTypes: []string{"player.used_skill"}, Payload: map[string]string{"skill_id": "fire_*"}, Threshold: 2},
+	)
+
+	tracker.HandleEvent(playerEvent("player.used_skill", "player:kain", map[string]interface{}{"skill_id": "fire_breath"}))
+	tracker.HandleEvent(playerEvent("player.used_skill", "player:kain", map[string]interface{}{"skill_id": "ice_shard"}))
+	if len(*published) != 0 {
+		t.Fatalf("expected no unlocks yet, got %+v", *published)
+	}
+	tracker.HandleEvent(playerEvent("player.used_skill", "player:kain", map[string]interface{}{"skill_id": "fire_breath"}))
+	if len(*published) != 2 {
+		t.Fatalf("expected both achievements to unlock, got %d events", len(*published))
+	}
+
+	var unlocked events.AchievementUnlocked
+	if err := events.Unmarshal((*published)[0], &unlocked); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if unlocked.AchievementID != "skill-adept" || unlocked.EntityID() != "player:kain" || unlocked.Progress != 3 ||
+		unlocked.Reward == nil || unlocked.Reward.Gold != 50 || unlocked.Scope == nil || unlocked.Scope.ID != "city-ashes" {
+		t.Errorf("unexpected unlock %+v", unlocked)
+	}
+	if (*published)[0].CausationID != "ev-player.used_skill" {
+		t.Errorf("expected the unlock to be caused by the skill use, got %q", (*published)[0].CausationID)
+	}
+	if _, ok := (*published)[0].Payload["state_changes"]; !ok {
+		t.Error("expected the unlock to persist the progress")
+	}
+
+	// Unlocked achievements no longer count, and the unlock persisted the progress
+	tracker.HandleEvent(playerEvent("player.used_skill", "player:kain", map[string]interface{}{"skill_id": "fire_breath"}))
+	if len(*published) != 2 {
+		t.Errorf("expected no repeated unlocks, got %d events", len(*published))
+	}
+	if n := tracker.Flush(context.Background()); n != 0 {
+		t.Errorf("expected nothing to flush after the unlock, got %d", n)
+	}
+
+	// Own events never count
+	own := playerEvent("player.used_skill", "player:lira", nil)
+	own.Source = source
+	tracker.HandleEvent(own)
+	if progress := tracker.progress.Get("player:lira", "ash-realm"); len(progress.Counters) != 0 {
+		t.Errorf("expected own events to be ignored, got %+v", progress)
+	}
+}
+
+func TestTrackerFlush(t *testing.T) {
+	storage := miniotest.New()
+	storage.Put("entities-ash-realm", "player:kain.json", `{"entity_id": "player:kain", "entity_type": "player",
+		"payload": {"achievements": {"counters": {"wanderer": 8}}}}`)
+	tracker, published := newTestTracker(Definition{ID: "wanderer", EventTypes: []string{"player.moved"}, Count: "distance", Threshold: 10})
+	tracker.progress.UseStorage(storage)
+
+	tracker.HandleEvent(playerEvent("player.moved", "player:kain", map[string]interface{}{"distance": 1.5}))
+	if len(*published) != 0 {
+		t.Fatalf("expected no unlock below the threshold, got %+v", *published)
+	}
+	if n := tracker.Flush(context.Background()); n != 1 || len(*published) != 1 {
+		t.Fatalf("expected the stored progress to be flushed, got %d", n)
+	}
+	flushed := (*published)[0]
+	if flushed.Type != EventProgressUpdated {
+		t.Errorf("expected %s, got %s", EventProgressUpdated, flushed.Type)
+	}
+	if counter, _ := flushed.Path().GetFloat("achievements.counters.wanderer"); counter != 9.5 {
+		t.Errorf("expected the stored counter to continue, got %v", counter)
+	}
+	if n := tracker.Flush(context.Background()); n != 0 {
+		t.Errorf("expected nothing to flush twice, got %d", n)
+	}
+
+	tracker.HandleEvent(playerEvent("player.moved", "player:kain", map[string]interface{}{"distance": 0.5}))
+	if len(*published) != 2 || (*published)[1].Type != events.TypeAchievementUnlocked {
+		t.Fatalf("expected the achievement to unlock at the threshold, got %+v", *published)
+	}
+}
+
+func TestRunFlushOnShutdown(t *testing.T) {
+	tracker, published := newTestTracker(Definition{ID: "skill-adept", EventTypes: []string{"player.used_skill"}, Threshold: 100})
+	tracker.HandleEvent(playerEvent("player.used_skill", "player:kain", nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	done := make(chan struct{})
+	go func() {
+		tracker.RunFlush(ctx, time.Hour)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("RunFlush did not return after cancellation")
+	}
+	if len(*published) != 1 || (*published)[0].Type != EventProgressUpdated {
+		t.Errorf("expected the progress to be flushed on shutdown, got %+v", *published)
+	}
+}
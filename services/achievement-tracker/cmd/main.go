@@ -0,0 +1,32 @@
+// Package main is the entry point for AchievementTracker.
+package main
+
+import (
+	"multiverse-core.io/services/achievement-tracker/achievementtracker"
+	"multiverse-core.io/shared/config"
+	"multiverse-core.io/shared/logging"
+	"multiverse-core.io/shared/service"
+)
+
+func main() {
+	app := service.Setup("achievement-tracker", []config.Option{
+		{Env: "ACHIEVEMENTS_BUCKET", Default: achievementtracker.DefaultDefinitionsBucket, Usage: "MinIO bucket with achievement definitions (*.json)"},
+		{Env: "ACHIEVEMENTS_RELOAD_INTERVAL", Default: "5m", Type: config.TypeDuration, Positive: true, Usage: "interval between reloads of achievement definitions"},
+		{Env: "ACHIEVEMENTS_FLUSH_INTERVAL", Default: "10s", Type: config.TypeDuration, Positive: true, Usage: "interval between persisting changed progress through EntityManager"},
+	})
+	env := app.Env
+
+	tracker := achievementtracker.NewService(app.Bus())
+	tracker.SetFlushInterval(env.Duration("ACHIEVEMENTS_FLUSH_INTERVAL"))
+
+	// Definitions and stored progress are read from MinIO
+	// (optional: without MinIO the built-in achievements apply and progress starts from zero)
+	minioClient, err := app.MinIO()
+	if err != nil {
+		logging.Warnf("MinIO unavailable, using built-in achievements without stored progress: %v", err)
+	} else {
+		tracker.UseStorage(minioClient, env.String("ACHIEVEMENTS_BUCKET"), env.Duration("ACHIEVEMENTS_RELOAD_INTERVAL"))
+	}
+
+	app.Run(tracker)
+}
@@ -0,0 +1,8 @@
+module multiverse-core.io/services/achievement-tracker
+
+go 1.24
+
+require (
+	github.com/segmentio/kafka-go v0.4.49
+	github.com/google/uuid v1.6.0
+)
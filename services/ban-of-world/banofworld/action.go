@@ -0,0 +1,121 @@
+package banofworld
+
+import (
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/jsonpath"
+)
+
+// Skill is the used skill of an action. Element and Tags come from the payload or,
+// when the payload only names the skill, from the skill entity in EntityManager.
+type Skill struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name,omitempty"`
+	Element string   `json:"element,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+}
+
+// Label returns the skill ID, or the name when the payload carries only the name.
+func (s Skill) Label() string {
+	if s.ID != "" {
+		return s.ID
+	}
+	return s.Name
+}
+
+// hasMetadata reports whether the element or tags of the skill are known.
+func (s Skill) hasMetadata() bool {
+	return s.Element != "" || len(s.Tags) > 0
+}
+
+// Action is a player action normalized from the payload of its event type.
+type Action struct {
+	Skill Skill
+	Item  string
+}
+
+// Payload fields naming the used skill and item. Producers disagree on names: GameService
+// commands use skill_id and item_id, test harness and orchestrator events add skill_name,
+// older events use skill or action.skill, full skill objects come as skill.{id,name,element,tags}.
+var (
+	skillIDPaths      = []string{"skill_id", "skill.id", "action.skill_id", "skill", "action.skill"}
+	skillNamePaths    = []string{"skill_name", "skill.name", "action.skill_name"}
+	skillElementPaths = []string{"skill.element", "skill_element", "action.skill_element"}
+	skillTagPaths     = []string{"skill.tags", "skill_tags", "action.skill_tags"}
+	itemPaths         = []string{"item_id", "item.id", "item", "action.item"}
+)
+
+// payloadAdapter extracts the action from the payload of one event type.
+type payloadAdapter func(pa *jsonpath.Accessor) Action
+
+// payloadAdapters normalize the payloads of known event types;
+// other player actions may carry either a skill or an item.
+var payloadAdapters = map[string]payloadAdapter{
+	"player.used_skill": func(pa *jsonpath.Accessor) Action { return Action{Skill: payloadSkill(pa)} },
+	"player.used_item":  func(pa *jsonpath.Accessor) Action { return Action{Item: firstString(pa, itemPaths)} },
+}
+
+func genericAction(pa *jsonpath.Accessor) Action {
+	return Action{Skill: payloadSkill(pa), Item: firstString(pa, itemPaths)}
+}
+
+// normalizeAction returns the action of an event through the adapter of its type.
+func normalizeAction(ev eventbus.Event) Action {
+	adapter, ok := payloadAdapters[ev.Type]
+	if !ok {
+		adapter = genericAction
+	}
+	return adapter(ev.Path())
+}
+
+// payloadSkill reads the skill fields of a payload.
+func payloadSkill(pa *jsonpath.Accessor) Skill {
+	skill := Skill{
+		ID:      firstString(pa, skillIDPaths),
+		Name:    firstString(pa, skillNamePaths),
+		Element: firstString(pa, skillElementPaths),
+	}
+	for _, field := range skillTagPaths {
+		value, _ := pa.GetAny(field)
+		if tags, ok := stringList(value); ok {
+			skill.Tags = tags
+			break
+		}
+	}
+	return skill
+}
+
+// stringList converts []string of events built in-process or []any after JSON decoding.
+func stringList(value any) ([]string, bool) {
+	switch values := value.(type) {
+	case []string:
+		return values, true
+	case []any:
+		list := make([]string, 0, len(values))
+		for _, v := range values {
+			if s, ok := v.(string); ok && s != "" {
+				list = append(list, s)
+			}
+		}
+		return list, true
+	}
+	return nil, false
+}
+
+// eventSkill extracts the used skill (ID, or name without ID).
+func eventSkill(ev eventbus.Event) string {
+	return normalizeAction(ev).Skill.Label()
+}
+
+// eventItem extracts the used item.
+func eventItem(ev eventbus.Event) string {
+	return normalizeAction(ev).Item
+}
+
+func firstString(pa *jsonpath.Accessor, paths []string) string {
+	for _, field := range paths {
+		if value, _ := pa.GetString(field); value != "" {
+			return value
+		}
+	}
+	return ""
+}
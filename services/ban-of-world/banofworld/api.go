@@ -0,0 +1,64 @@
+package banofworld
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+)
+
+// DefaultHTTPPort is the port of the BanOfWorld HTTP API.
+const DefaultHTTPPort = "8090"
+
+// routes builds the BanOfWorld HTTP API.
+func (s *Service) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", s.handleHealth)
+	mux.HandleFunc("POST /v1/check", s.handleCheck)
+	return mux
+}
+
+// handleHealth handles GET /health.
+func (s *Service) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"status": "healthy",
+		"time":   time.Now().Format(time.RFC3339),
+	})
+}
+
+// handleCheck handles POST /v1/check: the body is the player event about to be published.
+func (s *Service) handleCheck(w http.ResponseWriter, r *http.Request) {
+	var ev eventbus.Event
+	if err := json.NewDecoder(r.Body).Decode(&ev); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if ev.Type == "" || ev.Payload == nil {
+		http.Error(w, "type and payload are required", http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.ban.Check(ev))
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// serveHTTP runs the HTTP API until ctx is cancelled.
+func (s *Service) serveHTTP(ctx context.Context) {
+	go func() {
+		logging.Infof("BanOfWorld HTTP API listening on %s", s.server.Addr)
+		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logging.Errorf("BanOfWorld HTTP server failed: %v", err)
+		}
+	}()
+	<-ctx.Done()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	s.server.Shutdown(shutdownCtx)
+}
@@ -3,26 +3,36 @@ package banofworld
 
 import (
 	"context"
-	"log"
 	"time"
 
 	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/eventbus/events"
+	"multiverse-core.io/shared/logging"
 
 	"github.com/google/uuid"
 )
 
 // BanOfWorld protects world integrity through resonance with the Core.
 type BanOfWorld struct {
-	bus *eventbus.EventBus
+	bus        *eventbus.EventBus
+	boundaries *worldBoundaries
+	rules      *RuleEngine
+	ledger     *ViolationLedger
 }
 
 // NewBanOfWorld creates a new BanOfWorld.
 func NewBanOfWorld(bus *eventbus.EventBus) *BanOfWorld {
-	return &BanOfWorld{bus: bus}
+	return &BanOfWorld{bus: bus, boundaries: newWorldBoundaries(), rules: NewRuleEngine(), ledger: NewViolationLedger()}
 }
 
 // HandlePlayerEvent processes player events for world integrity checks.
 func (b *BanOfWorld) HandlePlayerEvent(ev eventbus.Event) {
+	// player_id in the payload can be forged: only actions confirmed by the player's session are judged
+	if err := eventbus.VerifyPlayer(ev, actorID(ev)); err != nil {
+		logging.Warnf("Ignoring %s %s: %v", ev.Type, ev.ID, err)
+		return
+	}
+
 	// Check for skill usage violations
 	if ev.Type == "player.used_skill" {
 		b.checkSkillUsage(ev)
@@ -37,6 +47,99 @@ func (b *BanOfWorld) HandlePlayerEvent(ev eventbus.Event) {
 	if ev.Type == "player.moved" {
 		b.checkMovement(ev)
 	}
+
+	// Other actions are checked against the forbiddance rules only
+	switch ev.Type {
+	case "player.used_skill", "player.used_item", "player.moved":
+	default:
+		b.checkActionRules(ev)
+	}
+}
+
+// ruleViolation returns the violation type and rule of the event, if any.
+func (b *BanOfWorld) ruleViolation(ev eventbus.Event) (string, Rule) {
+	rule, ok := b.rules.Match(ev)
+	if !ok {
+		return "", Rule{}
+	}
+	return rule.ViolationType, rule
+}
+
+// checkActionRules publishes a violation for any other player action matched by a forbiddance rule.
+func (b *BanOfWorld) checkActionRules(ev eventbus.Event) {
+	entityInfo, ok := ev.GetEntityIDWithFallback()
+	if !ok {
+		return
+	}
+	violationType, rule := b.ruleViolation(ev)
+	if violationType == "" {
+		return
+	}
+	playerID := entityInfo.ID
+	worldID := eventbus.GetWorldIDFromEvent(ev)
+	logging.Infof("Rule %s violated in %s: %s by %s", rule.ID, worldID, ev.Type, playerID)
+	tier, record := b.ledger.Record(playerID, worldID, violationType)
+
+	b.publishViolation(ev, newRuleViolation(ev, playerID, violationType, tier, rule))
+
+	b.punish(ev, playerID, violationType, tier, record)
+}
+
+// newRuleViolation returns the violation.detected payload of an action matched by a forbiddance rule.
+func newRuleViolation(ev eventbus.Event, playerID, violationType, tier string, rule Rule) events.ViolationDetected {
+	return events.ViolationDetected{
+		Subject: events.Subject{
+			Entity: events.EntityOf(playerID, "player", ""),
+			World:  events.WorldOf(eventbus.GetWorldIDFromEvent(ev)),
+		},
+		ViolationType: violationType,
+		Tier:          tier,
+		RuleID:        rule.ID,
+		Forbiddance:   rule.Forbiddance,
+		OriginalEvent: ev.ID,
+		Action:        ev.Type,
+	}
+}
+
+// publishViolation publishes violation.detected caused by the player's action ev,
+// linking the player to the world whose rules were broken.
+func (b *BanOfWorld) publishViolation(ev eventbus.Event, violation events.ViolationDetected) {
+	violationEvent, err := events.NewChild(ev, "ban-of-world", violation)
+	if err != nil {
+		logging.Errorf("Failed to build violation.detected for %s: %v", ev.ID, err)
+		return
+	}
+	violationEvent.ID = "violation-" + uuid.New().String()[:8]
+	violationEvent.Timestamp = ev.Timestamp
+	violationEvent.Scope = eventbus.GetScopeFromEvent(ev)
+
+	// ✨ Этап 6: Явные связи — игрок нарушил правила мира
+	if worldID := eventbus.GetWorldIDFromEvent(violationEvent); worldID != "" {
+		metadata := map[string]any{
+			"violation_type": violation.ViolationType,
+			"original_event": ev.ID,
+		}
+		if violation.Action != "" {
+			metadata["action"] = violation.Action
+		}
+		if violation.Skill != "" {
+			metadata["skill"] = violation.Skill
+		}
+		if violation.Item != "" {
+			metadata["item"] = violation.Item
+		}
+		violationEvent.Relations = []eventbus.Relation{
+			{
+				From:     violation.EntityID(),
+				To:       worldID,
+				Type:     eventbus.RelActedOn,
+				Directed: true,
+				Metadata: metadata,
+			},
+		}
+	}
+
+	b.bus.Publish(context.Background(), eventbus.TopicWorldEvents, violationEvent)
 }
 
 // checkSkillUsage checks if a skill usage violates world integrity — с универсальным доступом и иерархическими событиями:
@@ -44,10 +147,7 @@ func (b *BanOfWorld) checkSkillUsage(ev eventbus.Event) {
 	pa := ev.Path()
 
 	// Извлечение skill с поддержкой вложенной структуры и fallback
-	skill, _ := pa.GetString("skill")
-	if skill == "" {
-		skill, _ = pa.GetString("action.skill")
-	}
+	skill := eventSkill(ev)
 
 	// Извлечение playerID: новая структура entity.id → старая player_id
 	var playerID string
@@ -58,59 +158,25 @@ func (b *BanOfWorld) checkSkillUsage(ev eventbus.Event) {
 	}
 
 	if playerID == "" {
-		log.Printf("Skill usage missing player_id")
+		logging.Warnf("Skill usage missing player_id")
 		return
 	}
 
 	worldID := eventbus.GetWorldIDFromEvent(ev)
 
-	// Get world core violations
-	violationType := b.getViolationType(worldID, skill)
+	// Get world core violations from the forbiddance rules
+	violationType, rule := b.ruleViolation(ev)
 
 	if violationType != "" {
-		log.Printf("Violation detected in %s: %s used %s", worldID, playerID, skill)
-
-		// Publish violation event с иерархической структурой:
-		payload := eventbus.NewEventPayload().
-			WithEntity(playerID, "player", "").
-			WithWorld(worldID)
-
-		eventbus.SetNested(payload.GetCustom(), "skill", skill)
-		eventbus.SetNested(payload.GetCustom(), "violation_type", violationType)
-		eventbus.SetNested(payload.GetCustom(), "original_event", ev.ID)
-
-		// Иерархические пути для LLM:
-		eventbus.SetNested(payload.GetCustom(), "entity.id", playerID)
-		eventbus.SetNested(payload.GetCustom(), "world.entity.id", worldID)
-		eventbus.SetNested(payload.GetCustom(), "violation.skill", skill)
-		eventbus.SetNested(payload.GetCustom(), "violation.type", violationType)
-
-		violationEvent := eventbus.NewStructuredEvent("violation.detected", "ban-of-world", worldID, payload)
-		violationEvent.ID = "violation-" + uuid.New().String()[:8]
-		violationEvent.Timestamp = ev.Timestamp
-		if scope := eventbus.GetScopeFromEvent(ev); scope != nil {
-			violationEvent.Scope = scope
-		}
-
-		// ✨ Этап 6: Явные связи — игрок нарушил правила мира
-		violationEvent.Relations = []eventbus.Relation{
-			{
-				From:     playerID,
-				To:       worldID,
-				Type:     eventbus.RelActedOn,
-				Directed: true,
-				Metadata: map[string]any{
-					"violation_type": violationType,
-					"skill":          skill,
-					"original_event": ev.ID,
-				},
-			},
-		}
+		logging.Infof("Violation detected in %s: %s used %s", worldID, playerID, skill)
+		tier, record := b.ledger.Record(playerID, worldID, violationType)
 
-		b.bus.Publish(context.Background(), eventbus.TopicWorldEvents, violationEvent)
+		violation := newRuleViolation(ev, playerID, violationType, tier, rule)
+		violation.Skill = skill
+		b.publishViolation(ev, violation)
 
-		// Apply transformation or punishment
-		b.applyConsequence(ev, violationType)
+		// Escalate from warning to exile with the player's violation history
+		b.punish(ev, playerID, violationType, tier, record)
 	}
 }
 
@@ -119,10 +185,7 @@ func (b *BanOfWorld) checkItemUsage(ev eventbus.Event) {
 	pa := ev.Path()
 
 	// Извлечение item с поддержкой вложенной структуры: item или action.item
-	item, _ := pa.GetString("item")
-	if item == "" {
-		item, _ = pa.GetString("action.item")
-	}
+	item := eventItem(ev)
 
 	// Извлечение playerID: новая структура entity.id → старая player_id
 	var playerID string
@@ -136,43 +199,18 @@ func (b *BanOfWorld) checkItemUsage(ev eventbus.Event) {
 		return
 	}
 
-	violationType := b.getViolationType(eventbus.GetWorldIDFromEvent(ev), "item:"+item)
+	violationType, rule := b.ruleViolation(ev)
 
 	if violationType != "" {
 		worldID := eventbus.GetWorldIDFromEvent(ev)
-		log.Printf("Item violation detected in %s: %s used %s", worldID, playerID, item)
-
-		payload := eventbus.NewEventPayload().
-			WithEntity(playerID, "player", "").
-			WithWorld(worldID)
-
-		eventbus.SetNested(payload.GetCustom(), "item", item)
-		eventbus.SetNested(payload.GetCustom(), "violation_type", violationType)
-		eventbus.SetNested(payload.GetCustom(), "original_event", ev.ID)
-
-		violationEvent := eventbus.NewStructuredEvent("violation.detected", "ban-of-world", worldID, payload)
-		violationEvent.ID = "violation-" + uuid.New().String()[:8]
-		violationEvent.Timestamp = ev.Timestamp
-		violationEvent.Scope = eventbus.GetScopeFromEvent(ev)
-
-		// ✨ Этап 6: Явные связи — игрок нарушил правила мира через предмет
-		violationEvent.Relations = []eventbus.Relation{
-			{
-				From:     playerID,
-				To:       worldID,
-				Type:     eventbus.RelActedOn,
-				Directed: true,
-				Metadata: map[string]any{
-					"violation_type": violationType,
-					"item":           item,
-					"original_event": ev.ID,
-				},
-			},
-		}
+		logging.Infof("Item violation detected in %s: %s used %s", worldID, playerID, item)
+		tier, record := b.ledger.Record(playerID, worldID, violationType)
 
-		b.bus.Publish(context.Background(), eventbus.TopicWorldEvents, violationEvent)
+		violation := newRuleViolation(ev, playerID, violationType, tier, rule)
+		violation.Item = item
+		b.publishViolation(ev, violation)
 
-		b.applyConsequence(ev, violationType)
+		b.punish(ev, playerID, violationType, tier, record)
 	}
 }
 
@@ -189,55 +227,44 @@ func (b *BanOfWorld) checkMovement(ev eventbus.Event) {
 		playerID, _ = pa.GetString("player_id")
 	}
 
-	if playerID == "" || destination == "" {
+	if playerID == "" {
 		return
 	}
 
-	// Example: players cannot leave certain worlds
-	worldID := eventbus.GetWorldIDFromEvent(ev)
-	if worldID == "prison-realm" && destination != worldID {
-		log.Printf("Movement violation in %s: %s tried to leave", worldID, playerID)
+	// Coordinates outside the generated world
+	b.checkBoundaries(ev, playerID)
 
-		payload := eventbus.NewEventPayload().
-			WithEntity(playerID, "player", "")
-
-		eventbus.SetNested(payload.GetCustom(), "attempted_destination", destination)
-		eventbus.SetNested(payload.GetCustom(), "violation_type", "forbidden_movement")
-		eventbus.SetNested(payload.GetCustom(), "original_event", ev.ID)
+	if destination == "" {
+		return
+	}
 
-		violationEvent := eventbus.NewStructuredEvent("violation.detected", "ban-of-world", worldID, payload)
-		violationEvent.ID = "violation-" + uuid.New().String()[:8]
-		violationEvent.Scope = eventbus.GetScopeFromEvent(ev)
-		violationEvent.Timestamp = ev.Timestamp
+	// Prisoners cannot leave until their term is served; exiles cannot return
+	worldID := eventbus.GetWorldIDFromEvent(ev)
+	var violationType string
+	switch {
+	case worldID == "prison-realm" && destination != worldID && !b.ledger.Released(playerID):
+		violationType = "forbidden_movement"
+	case destination != worldID && b.ledger.Exiled(playerID, destination):
+		violationType = "exile_violation"
+	}
+	if violationType != "" {
+		logging.Infof("Movement violation in %s: %s tried to move to %s (%s)", worldID, playerID, destination, violationType)
 
-		b.bus.Publish(context.Background(), eventbus.TopicWorldEvents, violationEvent)
+		b.publishViolation(ev, events.ViolationDetected{
+			Subject: events.Subject{
+				Entity: events.EntityOf(playerID, "player", ""),
+				World:  events.WorldOf(worldID),
+			},
+			ViolationType:        violationType,
+			OriginalEvent:        ev.ID,
+			AttemptedDestination: destination,
+		})
 
 		// Teleport back or apply punishment
 		b.applyMovementConsequence(ev)
 	}
 }
 
-// getViolationType determines the type of violation based on world and action.
-func (b *BanOfWorld) getViolationType(worldID, action string) string {
-	// World-specific violation rules
-	switch worldID {
-	case "pain-realm":
-		if action == "fire_breath" || action == "item:healing_potion" {
-			return "elemental_conflict" // Fire and healing forbidden in World of Pain
-		}
-	case "memory-realm":
-		if action == "memory_erase" {
-			return "memory_violation" // Memory erasure forbidden in World of Memory
-		}
-	case "mechanism-realm":
-		if action == "organic_skill" {
-			return "mechanical_purity" // Organic skills forbidden in World of Mechanisms
-		}
-	}
-
-	return "" // No violation
-}
-
 // applyConsequence applies the appropriate consequence for a violation.
 func (b *BanOfWorld) applyConsequence(ev eventbus.Event, violationType string) {
 	pa := ev.Path()
@@ -252,15 +279,15 @@ func (b *BanOfWorld) applyConsequence(ev eventbus.Event, violationType string) {
 	switch violationType {
 	case "elemental_conflict":
 		// Transform fire breath to scream of pain
-		skill, _ := pa.GetString("skill")
+		skill := eventSkill(ev)
 		transformPayload := eventbus.NewEventPayload().
 			WithEntity(playerID, "player", "")
 
 		eventbus.SetNested(transformPayload.GetCustom(), "original", skill)
-		eventbus.SetNested(transformPayload.GetCustom(), "transformed", "scream_of_pain")
-		eventbus.SetNested(transformPayload.GetCustom(), "reason", "resonance_with_core")
+		eventbus.SetNested(transformPayload.GetCustom(), "transformed", skillTransformations["elemental_conflict"].Skill)
+		eventbus.SetNested(transformPayload.GetCustom(), "reason", skillTransformations["elemental_conflict"].Reason)
 
-		transformEvent := eventbus.NewStructuredEvent("skill.transformed", "ban-of-world", eventbus.GetWorldIDFromEvent(ev), transformPayload)
+		transformEvent := eventbus.NewStructuredEvent("skill.transformed", "ban-of-world", eventbus.GetWorldIDFromEvent(ev), transformPayload).CausedBy(ev)
 		transformEvent.ID = "transform-" + uuid.New().String()[:8]
 		transformEvent.Timestamp = time.Now()
 
@@ -275,7 +302,7 @@ func (b *BanOfWorld) applyConsequence(ev eventbus.Event, violationType string) {
 		eventbus.SetNested(punishPayload.GetCustom(), "duration", "1h")
 		eventbus.SetNested(punishPayload.GetCustom(), "reason", "violation_of_memory_laws")
 
-		punishEvent := eventbus.NewStructuredEvent("player.punished", "ban-of-world", eventbus.GetWorldIDFromEvent(ev), punishPayload)
+		punishEvent := eventbus.NewStructuredEvent("player.punished", "ban-of-world", eventbus.GetWorldIDFromEvent(ev), punishPayload).CausedBy(ev)
 		punishEvent.ID = "punish-" + uuid.New().String()[:8]
 		punishEvent.Timestamp = time.Now()
 
@@ -283,19 +310,34 @@ func (b *BanOfWorld) applyConsequence(ev eventbus.Event, violationType string) {
 
 	case "mechanical_purity":
 		// Transform organic skill to mechanical equivalent
-		skill, _ := pa.GetString("skill")
+		skill := eventSkill(ev)
 		transformPayload := eventbus.NewEventPayload().
 			WithEntity(playerID, "player", "")
 
 		eventbus.SetNested(transformPayload.GetCustom(), "original", skill)
-		eventbus.SetNested(transformPayload.GetCustom(), "transformed", "mechanical_equivalent")
-		eventbus.SetNested(transformPayload.GetCustom(), "reason", "mechanical_world_integrity")
+		eventbus.SetNested(transformPayload.GetCustom(), "transformed", skillTransformations["mechanical_purity"].Skill)
+		eventbus.SetNested(transformPayload.GetCustom(), "reason", skillTransformations["mechanical_purity"].Reason)
 
-		transformEvent := eventbus.NewStructuredEvent("skill.transformed", "ban-of-world", eventbus.GetWorldIDFromEvent(ev), transformPayload)
+		transformEvent := eventbus.NewStructuredEvent("skill.transformed", "ban-of-world", eventbus.GetWorldIDFromEvent(ev), transformPayload).CausedBy(ev)
 		transformEvent.ID = "transform-" + uuid.New().String()[:8]
 		transformEvent.Timestamp = time.Now()
 
 		b.bus.Publish(context.Background(), eventbus.TopicWorldEvents, transformEvent)
+
+	default:
+		// Violations of archivist rules without a dedicated transformation
+		punishPayload := eventbus.NewEventPayload().
+			WithEntity(playerID, "player", "")
+
+		eventbus.SetNested(punishPayload.GetCustom(), "punishment", "core_rejection")
+		eventbus.SetNested(punishPayload.GetCustom(), "duration", "10m")
+		eventbus.SetNested(punishPayload.GetCustom(), "reason", violationType)
+
+		punishEvent := eventbus.NewStructuredEvent("player.punished", "ban-of-world", eventbus.GetWorldIDFromEvent(ev), punishPayload).CausedBy(ev)
+		punishEvent.ID = "punish-" + uuid.New().String()[:8]
+		punishEvent.Timestamp = time.Now()
+
+		b.bus.Publish(context.Background(), eventbus.TopicWorldEvents, punishEvent)
 	}
 }
 
@@ -316,7 +358,9 @@ func (b *BanOfWorld) applyMovementConsequence(ev eventbus.Event) {
 			"destination": worldID, // Back to original world
 			"reason":      "forbidden_movement",
 		},
-		Timestamp: time.Now(),
+		Timestamp:     time.Now(),
+		CausationID:   ev.ID,
+		CorrelationID: ev.Correlation(),
 	}
 	b.bus.Publish(context.Background(), eventbus.TopicWorldEvents, teleportEvent)
 }
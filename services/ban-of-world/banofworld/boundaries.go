@@ -0,0 +1,120 @@
+package banofworld
+
+import (
+	"math"
+	"sync"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/eventbus/events"
+	"multiverse-core.io/shared/logging"
+	"multiverse-core.io/shared/spatial"
+)
+
+// worldBoundaries tracks known world extents and region areas for movement checks.
+type worldBoundaries struct {
+	mu      sync.RWMutex
+	bounds  map[string]spatial.BoundingBox // world ID → extent of the generated map
+	regions *spatial.WorldIndex            // region circles by world
+}
+
+func newWorldBoundaries() *worldBoundaries {
+	return &worldBoundaries{
+		bounds:  make(map[string]spatial.BoundingBox),
+		regions: spatial.NewWorldIndex(spatial.DefaultCellSize),
+	}
+}
+
+// HandleWorldEvent tracks world geography from system events:
+// region creation, map generation and region expansion.
+func (b *BanOfWorld) HandleWorldEvent(ev eventbus.Event) {
+	worldID := eventbus.GetWorldIDFromEvent(ev)
+	if worldID == "" {
+		return
+	}
+
+	switch ev.Type {
+	case "entity.created":
+		b.trackRegion(ev, worldID)
+	case "world.map.generated", "world.region.expanded":
+		if box, ok := parseBounds(ev); ok {
+			b.boundaries.mu.Lock()
+			b.boundaries.bounds[worldID] = box
+			b.boundaries.mu.Unlock()
+		}
+	}
+}
+
+// trackRegion indexes a region as a circle of its area around its coordinates.
+func (b *BanOfWorld) trackRegion(ev eventbus.Event, worldID string) {
+	entityInfo, ok := ev.GetEntityIDWithFallback()
+	if !ok || entityInfo.Type != "region" {
+		return
+	}
+	pa := ev.Path()
+	x, okX := pa.GetFloat("payload.coordinates.x")
+	y, okY := pa.GetFloat("payload.coordinates.y")
+	size, okSize := pa.GetFloat("payload.size")
+	if !okX || !okY || !okSize || size <= 0 {
+		return
+	}
+	circle := spatial.Circle{Center: spatial.Point{X: x, Y: y}, Radius: math.Sqrt(size / math.Pi)}
+	b.boundaries.regions.Insert(worldID, entityInfo.ID, circle)
+}
+
+// parseBounds extracts world bounds {min_x, min_y, max_x, max_y} from the event payload.
+func parseBounds(ev eventbus.Event) (spatial.BoundingBox, bool) {
+	pa := ev.Path()
+	minX, ok1 := pa.GetFloat("bounds.min_x")
+	minY, ok2 := pa.GetFloat("bounds.min_y")
+	maxX, ok3 := pa.GetFloat("bounds.max_x")
+	maxY, ok4 := pa.GetFloat("bounds.max_y")
+	if !ok1 || !ok2 || !ok3 || !ok4 || maxX <= minX || maxY <= minY {
+		return spatial.BoundingBox{}, false
+	}
+	return spatial.BoundingBox{Min: spatial.Point{X: minX, Y: minY}, Max: spatial.Point{X: maxX, Y: maxY}}, true
+}
+
+// eventPoint extracts the target point of a movement: location {x, y} or to {x, y}.
+func eventPoint(ev eventbus.Event) (spatial.Point, bool) {
+	pa := ev.Path()
+	for _, key := range []string{"location", "to"} {
+		x, okX := pa.GetFloat(key + ".x")
+		y, okY := pa.GetFloat(key + ".y")
+		if okX && okY {
+			return spatial.Point{X: x, Y: y}, true
+		}
+	}
+	return spatial.Point{}, false
+}
+
+// outOfBounds reports whether the point lies outside the known bounds of the world.
+// Worlds without known bounds are not checked.
+func (wb *worldBoundaries) outOfBounds(worldID string, p spatial.Point) bool {
+	wb.mu.RLock()
+	box, ok := wb.bounds[worldID]
+	wb.mu.RUnlock()
+	return ok && !box.Contains(p)
+}
+
+// checkBoundaries publishes a violation when a player moves outside the generated world.
+func (b *BanOfWorld) checkBoundaries(ev eventbus.Event, playerID string) {
+	point, ok := eventPoint(ev)
+	worldID := eventbus.GetWorldIDFromEvent(ev)
+	if !ok || worldID == "" || !b.boundaries.outOfBounds(worldID, point) {
+		return
+	}
+
+	logging.Infof("Boundary violation in %s: %s moved to (%.1f, %.1f)", worldID, playerID, point.X, point.Y)
+
+	b.publishViolation(ev, events.ViolationDetected{
+		Subject: events.Subject{
+			Entity: events.EntityOf(playerID, "player", ""),
+			World:  events.WorldOf(worldID),
+		},
+		ViolationType: "out_of_world_bounds",
+		OriginalEvent: ev.ID,
+		Location:      &events.Point{X: point.X, Y: point.Y},
+		// Регионы, в которые точка всё же попадает (например, ещё не учтённое расширение мира)
+		Regions: b.boundaries.regions.Containing(worldID, point),
+	})
+}
@@ -0,0 +1,112 @@
+package banofworld
+
+import (
+	"encoding/json"
+	"slices"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+)
+
+// Verdicts of the pre-publication check.
+const (
+	VerdictAllow     = "allow"
+	VerdictReject    = "reject"
+	VerdictTransform = "transform"
+)
+
+// skillTransformation is the skill a forbidden skill turns into.
+type skillTransformation struct {
+	Skill  string
+	Reason string
+}
+
+// skillTransformations maps violation types to the transformation of the forbidden skill.
+var skillTransformations = map[string]skillTransformation{
+	"elemental_conflict": {Skill: "scream_of_pain", Reason: "resonance_with_core"},
+	"mechanical_purity":  {Skill: "mechanical_equivalent", Reason: "mechanical_world_integrity"},
+}
+
+// CheckResult is the verdict on a player action before it is published.
+type CheckResult struct {
+	Verdict       string `json:"verdict"`
+	ViolationType string `json:"violation_type,omitempty"`
+	RuleID        string `json:"rule_id,omitempty"`
+	Forbiddance   string `json:"forbiddance,omitempty"`
+	Tier          string `json:"tier,omitempty"`
+	// Event is the action to publish instead of the original (VerdictTransform).
+	Event *eventbus.Event `json:"event,omitempty"`
+}
+
+// Check judges a player action before it enters the event stream.
+// A violation is recorded and punished as if it happened, but the action itself is
+// rejected or, for a transformable skill, replaced by its transformation.
+func (b *BanOfWorld) Check(ev eventbus.Event) CheckResult {
+	violationType, rule := b.ruleViolation(ev)
+	playerID := actorID(ev)
+	if violationType == "" || playerID == "" {
+		return CheckResult{Verdict: VerdictAllow}
+	}
+
+	worldID := eventbus.GetWorldIDFromEvent(ev)
+	tier, record := b.ledger.Record(playerID, worldID, violationType)
+	result := CheckResult{
+		Verdict:       VerdictReject,
+		ViolationType: violationType,
+		RuleID:        rule.ID,
+		Forbiddance:   rule.Forbiddance,
+		Tier:          tier,
+	}
+	if transformation, ok := skillTransformations[violationType]; ok && tier == TierTransformation {
+		if transformed, ok := transformSkill(ev, transformation); ok {
+			result.Verdict = VerdictTransform
+			result.Event = &transformed
+		}
+	}
+	logging.Infof("Action %s of %s in %s vetoed: %s (%s, %s)", ev.Type, playerID, worldID, result.Verdict, violationType, tier)
+
+	violation := newRuleViolation(ev, playerID, violationType, tier, rule)
+	violation.Prevented = true
+	violation.Verdict = result.Verdict
+	b.publishViolation(ev, violation)
+	b.punish(ev, playerID, violationType, tier, record)
+	return result
+}
+
+// transformSkill returns a copy of the action with the forbidden skill replaced.
+func transformSkill(ev eventbus.Event, transformation skillTransformation) (eventbus.Event, bool) {
+	original := eventSkill(ev)
+	if original == "" {
+		return eventbus.Event{}, false
+	}
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return eventbus.Event{}, false
+	}
+	var transformed eventbus.Event
+	if err := json.Unmarshal(data, &transformed); err != nil {
+		return eventbus.Event{}, false
+	}
+	pa := transformed.Path()
+	for _, field := range slices.Concat(skillIDPaths, skillNamePaths) {
+		if value, _ := pa.GetString(field); value == original {
+			pa.Set(field, transformation.Skill)
+		}
+	}
+	// Element and tags described the forbidden skill
+	for _, field := range slices.Concat(skillElementPaths, skillTagPaths) {
+		pa.Delete(field)
+	}
+	pa.Set("transformed_from", original)
+	pa.Set("transformation_reason", transformation.Reason)
+	return transformed, true
+}
+
+// actorID extracts the acting player: entity.id with fallback to player_id.
+func actorID(ev eventbus.Event) string {
+	if entityInfo, ok := ev.GetEntityIDWithFallback(); ok {
+		return entityInfo.ID
+	}
+	playerID, _ := ev.Path().GetString("player_id")
+	return playerID
+}
@@ -0,0 +1,62 @@
+package banofworld
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTransformSkill(t *testing.T) {
+	ev := playerAction("player.used_skill", "pain-realm", map[string]interface{}{"skill_id": "fire_breath", "target_id": "npc-1"})
+	transformed, ok := transformSkill(ev, skillTransformations["elemental_conflict"])
+	if !ok {
+		t.Fatal("expected transformation")
+	}
+	pa := transformed.Path()
+	if skill, _ := pa.GetString("skill_id"); skill != "scream_of_pain" {
+		t.Errorf("skill_id = %q", skill)
+	}
+	if original, _ := pa.GetString("transformed_from"); original != "fire_breath" {
+		t.Errorf("transformed_from = %q", original)
+	}
+	if skill := eventSkill(ev); skill != "fire_breath" {
+		t.Errorf("original event must not change, skill %q", skill)
+	}
+	if _, ok := transformSkill(playerAction("player.used_item", "pain-realm", map[string]interface{}{"item_id": "healing_potion"}), skillTransformations["elemental_conflict"]); ok {
+		t.Errorf("actions without a skill are not transformed")
+	}
+}
+
+func TestCheckAPI(t *testing.T) {
+	s := &Service{ban: NewBanOfWorld(nil)}
+	handler := s.routes()
+
+	check := func(body string) (*httptest.ResponseRecorder, CheckResult) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/check", strings.NewReader(body)))
+		var result CheckResult
+		if rec.Code == http.StatusOK {
+			json.NewDecoder(rec.Body).Decode(&result)
+		}
+		return rec, result
+	}
+
+	// Actions that match no rule are allowed without touching the ledger
+	allowed := playerAction("player.used_skill", "pain-realm", map[string]interface{}{"skill_id": "ice_shard"})
+	body, _ := json.Marshal(allowed)
+	rec, result := check(string(body))
+	if rec.Code != http.StatusOK || result.Verdict != VerdictAllow || result.Event != nil {
+		t.Errorf("expected allow, got %d %+v", rec.Code, result)
+	}
+	if _, ok := s.ban.ledger.Get("player-1"); ok {
+		t.Errorf("allowed action must not be recorded")
+	}
+
+	for _, body := range []string{"{", `{"payload": {}}`, `{"type": "player.used_skill"}`} {
+		if rec, _ := check(body); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", body, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,327 @@
+package banofworld
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+)
+
+// violationLedgerBucket stores player violation records: {player_id}.json
+const violationLedgerBucket = "violation-ledger"
+
+// Escalation tiers of consequences, from the first violation to persistent offenders.
+const (
+	TierWarning        = "warning"
+	TierTransformation = "transformation"
+	TierImprisonment   = "imprisonment"
+	TierExile          = "exile"
+)
+
+const (
+	// DefaultViolationHalfLife is the time after which a violation counts half as much.
+	DefaultViolationHalfLife = 24 * time.Hour
+	// DefaultLedgerInterval is how often karma decay is published and records are written to storage.
+	DefaultLedgerInterval = time.Minute
+
+	imprisonmentTerm = time.Hour
+	exileTerm        = 24 * time.Hour
+	// maxLedgerEntries limits the violation history kept per player
+	maxLedgerEntries = 20
+	// karmaPerViolation is the karma lost per unit of violation score
+	karmaPerViolation = 20
+	// karmaStep is the smallest karma recovery worth publishing
+	karmaStep = 5
+)
+
+// tierFor maps the violation score after a new violation to its consequence tier.
+func tierFor(score float64) string {
+	switch {
+	case score < 1.5:
+		return TierWarning
+	case score < 2.5:
+		return TierTransformation
+	case score < 3.5:
+		return TierImprisonment
+	default:
+		return TierExile
+	}
+}
+
+// karmaFor converts a violation score into karma in [-100, 0].
+func karmaFor(score float64) int {
+	karma := -int(math.Round(score * karmaPerViolation))
+	if karma < -100 {
+		karma = -100
+	}
+	return karma
+}
+
+// ViolationEntry is one violation in the player's history.
+type ViolationEntry struct {
+	WorldID       string    `json:"world_id"`
+	ViolationType string    `json:"violation_type"`
+	Tier          string    `json:"tier"`
+	At            time.Time `json:"at"`
+}
+
+// PlayerRecord is the violation history of one player.
+type PlayerRecord struct {
+	PlayerID string `json:"player_id"`
+	// Score is the decayed violation count at UpdatedAt; every violation adds 1
+	Score float64 `json:"score"`
+	// Karma is the karma last published for the player
+	Karma           int                  `json:"karma"`
+	Violations      []ViolationEntry     `json:"violations"`
+	ImprisonedUntil time.Time            `json:"imprisoned_until"` // zero — never imprisoned
+	Exiles          map[string]time.Time `json:"exiles,omitempty"` // world ID → end of exile
+	UpdatedAt       time.Time            `json:"updated_at"`
+}
+
+// decay reduces the score by the time passed since the last update.
+func (r *PlayerRecord) decay(now time.Time, halfLife time.Duration) {
+	if now.After(r.UpdatedAt) && halfLife > 0 {
+		r.Score *= math.Pow(0.5, now.Sub(r.UpdatedAt).Seconds()/halfLife.Seconds())
+	}
+	r.UpdatedAt = now
+}
+
+func (r *PlayerRecord) clone() PlayerRecord {
+	c := *r
+	c.Violations = append([]ViolationEntry(nil), r.Violations...)
+	c.Exiles = make(map[string]time.Time, len(r.Exiles))
+	for worldID, until := range r.Exiles {
+		c.Exiles[worldID] = until
+	}
+	return c
+}
+
+// ViolationLedger keeps player violation records in memory and snapshots changed records to MinIO.
+type ViolationLedger struct {
+	storage  storage.ObjectStorage // nil — records live in memory only
+	now      func() time.Time
+	halfLife time.Duration
+
+	records map[string]*PlayerRecord
+	dirty   map[string]bool
+	mu      sync.Mutex
+}
+
+// NewViolationLedger creates an in-memory ledger; see UseStorage for persistence.
+func NewViolationLedger() *ViolationLedger {
+	return &ViolationLedger{
+		now:      time.Now,
+		halfLife: DefaultViolationHalfLife,
+		records:  make(map[string]*PlayerRecord),
+		dirty:    make(map[string]bool),
+	}
+}
+
+// UseStorage enables persisting violation records to MinIO.
+func (l *ViolationLedger) UseStorage(client storage.ObjectStorage) {
+	l.storage = client
+}
+
+// SetHalfLife sets how fast violations are forgiven.
+func (l *ViolationLedger) SetHalfLife(halfLife time.Duration) {
+	if halfLife <= 0 {
+		return
+	}
+	l.mu.Lock()
+	l.halfLife = halfLife
+	l.mu.Unlock()
+}
+
+// Load reads all violation records from storage. Corrupted records are skipped.
+func (l *ViolationLedger) Load() error {
+	if l.storage == nil {
+		return nil
+	}
+	objects, err := l.storage.ListObjects(violationLedgerBucket, "")
+	if err != nil {
+		if storage.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("list violation records: %w", err)
+	}
+
+	loaded := make(map[string]*PlayerRecord)
+	for _, object := range objects {
+		if !strings.HasSuffix(object.Key, ".json") {
+			continue
+		}
+		data, err := l.storage.GetObject(violationLedgerBucket, object.Key)
+		if err != nil {
+			return fmt.Errorf("load violation record %s: %w", object.Key, err)
+		}
+		record := &PlayerRecord{}
+		if err := json.Unmarshal(data, record); err != nil || record.PlayerID == "" {
+			logging.Warnf("Skipping corrupted violation record %s: %v", object.Key, err)
+			continue
+		}
+		loaded[record.PlayerID] = record
+	}
+
+	l.mu.Lock()
+	for playerID, record := range loaded {
+		// Violations recorded before loading finished take precedence
+		if _, exists := l.records[playerID]; !exists {
+			l.records[playerID] = record
+		}
+	}
+	l.mu.Unlock()
+	logging.Infof("Loaded %d violation records", len(loaded))
+	return nil
+}
+
+// Record adds a violation to the player's history and returns its tier
+// together with a copy of the updated record.
+func (l *ViolationLedger) Record(playerID, worldID, violationType string) (string, PlayerRecord) {
+	now := l.now().UTC()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	record, ok := l.records[playerID]
+	if !ok {
+		record = &PlayerRecord{PlayerID: playerID, UpdatedAt: now}
+		l.records[playerID] = record
+	}
+	record.decay(now, l.halfLife)
+	record.Score++
+	tier := tierFor(record.Score)
+
+	record.Violations = append(record.Violations, ViolationEntry{WorldID: worldID, ViolationType: violationType, Tier: tier, At: now})
+	if len(record.Violations) > maxLedgerEntries {
+		record.Violations = record.Violations[len(record.Violations)-maxLedgerEntries:]
+	}
+	switch tier {
+	case TierImprisonment:
+		record.ImprisonedUntil = now.Add(imprisonmentTerm)
+	case TierExile:
+		if record.Exiles == nil {
+			record.Exiles = make(map[string]time.Time)
+		}
+		record.Exiles[worldID] = now.Add(exileTerm)
+	}
+	record.Karma = karmaFor(record.Score)
+	l.dirty[playerID] = true
+	return tier, record.clone()
+}
+
+// Decay applies forgiveness to all records and returns those whose karma recovered
+// by at least karmaStep or back to zero since it was last published.
+func (l *ViolationLedger) Decay() []PlayerRecord {
+	now := l.now().UTC()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var changed []PlayerRecord
+	for playerID, record := range l.records {
+		if record.Karma == 0 {
+			continue
+		}
+		record.decay(now, l.halfLife)
+		karma := karmaFor(record.Score)
+		if karma-record.Karma >= karmaStep || (karma == 0 && record.Karma != 0) {
+			record.Karma = karma
+			l.dirty[playerID] = true
+			changed = append(changed, record.clone())
+		}
+	}
+	sort.Slice(changed, func(i, j int) bool { return changed[i].PlayerID < changed[j].PlayerID })
+	return changed
+}
+
+// Get returns a copy of the player's record; false if the player has no violations.
+func (l *ViolationLedger) Get(playerID string) (PlayerRecord, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	record, ok := l.records[playerID]
+	if !ok {
+		return PlayerRecord{}, false
+	}
+	return record.clone(), true
+}
+
+// Released reports whether the player was imprisoned and has served the term.
+func (l *ViolationLedger) Released(playerID string) bool {
+	record, ok := l.Get(playerID)
+	return ok && !record.ImprisonedUntil.IsZero() && !l.now().Before(record.ImprisonedUntil)
+}
+
+// Exiled reports whether the player is currently exiled from the world.
+func (l *ViolationLedger) Exiled(playerID, worldID string) bool {
+	record, ok := l.Get(playerID)
+	if !ok {
+		return false
+	}
+	until, exiled := record.Exiles[worldID]
+	return exiled && l.now().Before(until)
+}
+
+// Snapshot writes changed records to storage. Records that failed to save stay dirty.
+func (l *ViolationLedger) Snapshot() error {
+	if l.storage == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	pending := make(map[string][]byte, len(l.dirty))
+	for playerID := range l.dirty {
+		data, err := json.Marshal(l.records[playerID])
+		if err != nil {
+			logging.Errorf("Failed to encode violation record %s: %v", playerID, err)
+			continue
+		}
+		pending[playerID] = data
+	}
+	l.dirty = make(map[string]bool)
+	l.mu.Unlock()
+
+	var firstErr error
+	for playerID, data := range pending {
+		if err := l.storage.PutObject(violationLedgerBucket, playerID+".json", bytes.NewReader(data), int64(len(data))); err != nil {
+			l.mu.Lock()
+			l.dirty[playerID] = true
+			l.mu.Unlock()
+			if firstErr == nil {
+				firstErr = fmt.Errorf("save violation record %s: %w", playerID, err)
+			}
+		}
+	}
+	return firstErr
+}
+
+// RunLedger publishes karma recovery and snapshots the ledger every interval,
+// and once more when ctx is cancelled.
+func (b *BanOfWorld) RunLedger(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultLedgerInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if err := b.ledger.Snapshot(); err != nil {
+				logging.Errorf("Final violation ledger snapshot failed: %v", err)
+			}
+			return
+		case <-ticker.C:
+			for _, record := range b.ledger.Decay() {
+				b.publishKarma(record, nil, "", "", "decay")
+			}
+			if err := b.ledger.Snapshot(); err != nil {
+				logging.Errorf("Violation ledger snapshot failed: %v", err)
+			}
+		}
+	}
+}
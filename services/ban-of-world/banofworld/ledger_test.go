@@ -0,0 +1,101 @@
+package banofworld
+
+import (
+	"testing"
+	"time"
+
+	"multiverse-core.io/shared/minio/miniotest"
+)
+
+// testLedger returns a ledger with a clock controlled by the test.
+func testLedger() (*ViolationLedger, *time.Time) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	ledger := NewViolationLedger()
+	ledger.now = func() time.Time { return now }
+	return ledger, &now
+}
+
+func TestViolationLedgerEscalation(t *testing.T) {
+	ledger, now := testLedger()
+	want := []string{TierWarning, TierTransformation, TierImprisonment, TierExile, TierExile}
+	for i, tier := range want {
+		got, record := ledger.Record("player-1", "pain-realm", "elemental_conflict")
+		if got != tier {
+			t.Errorf("violation %d: tier %s, want %s", i+1, got, tier)
+		}
+		if record.Karma != -20*(i+1) && record.Karma != -100 {
+			t.Errorf("violation %d: karma %d", i+1, record.Karma)
+		}
+	}
+	if !ledger.Exiled("player-1", "pain-realm") || ledger.Exiled("player-1", "memory-realm") {
+		t.Errorf("player must be exiled from pain-realm only")
+	}
+	if ledger.Released("player-1") {
+		t.Errorf("prison term is not served yet")
+	}
+
+	// Other players start from a warning
+	if tier, _ := ledger.Record("player-2", "pain-realm", "elemental_conflict"); tier != TierWarning {
+		t.Errorf("first violation of another player: %s", tier)
+	}
+
+	// Violations are forgiven over time: two half-lives later the score is a quarter
+	*now = now.Add(2 * DefaultViolationHalfLife)
+	if !ledger.Released("player-1") || ledger.Exiled("player-1", "pain-realm") {
+		t.Errorf("prison term and exile must be over")
+	}
+	if tier, record := ledger.Record("player-1", "pain-realm", "elemental_conflict"); tier != TierTransformation || record.Score != 2.25 {
+		t.Errorf("after forgiveness: tier %s, score %v", tier, record.Score)
+	}
+}
+
+func TestViolationLedgerDecay(t *testing.T) {
+	ledger, now := testLedger()
+	ledger.Record("player-1", "pain-realm", "elemental_conflict")
+	ledger.Record("player-1", "pain-realm", "elemental_conflict")
+	if changed := ledger.Decay(); len(changed) != 0 {
+		t.Errorf("no time passed, got %+v", changed)
+	}
+
+	// Karma recovers from -40 to -20 after one half-life
+	*now = now.Add(DefaultViolationHalfLife)
+	changed := ledger.Decay()
+	if len(changed) != 1 || changed[0].Karma != -20 {
+		t.Fatalf("expected recovered karma, got %+v", changed)
+	}
+	*now = now.Add(time.Minute)
+	if changed := ledger.Decay(); len(changed) != 0 {
+		t.Errorf("small recoveries are not published, got %+v", changed)
+	}
+	*now = now.Add(30 * DefaultViolationHalfLife)
+	if changed := ledger.Decay(); len(changed) != 1 || changed[0].Karma != 0 {
+		t.Errorf("expected karma back to zero, got %+v", changed)
+	}
+	if changed := ledger.Decay(); len(changed) != 0 {
+		t.Errorf("forgiven players are not published again, got %+v", changed)
+	}
+}
+
+func TestViolationLedgerPersistence(t *testing.T) {
+	store := miniotest.New()
+	ledger, _ := testLedger()
+	ledger.UseStorage(store)
+	ledger.Record("player-1", "pain-realm", "elemental_conflict")
+	ledger.Record("player-1", "memory-realm", "memory_violation")
+	if err := ledger.Snapshot(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store.Object(violationLedgerBucket, "player-1.json"); !ok {
+		t.Fatalf("record not saved: %v", store.Puts(violationLedgerBucket))
+	}
+
+	restored := NewViolationLedger()
+	restored.UseStorage(store)
+	if err := restored.Load(); err != nil {
+		t.Fatal(err)
+	}
+	record, ok := restored.Get("player-1")
+	if !ok || record.Score != 2 || len(record.Violations) != 2 || record.Violations[1].Tier != TierTransformation {
+		t.Errorf("unexpected restored record: %+v", record)
+	}
+}
@@ -0,0 +1,109 @@
+package banofworld
+
+import (
+	"context"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+
+	"github.com/google/uuid"
+)
+
+// EventKarma announces the karma of a player after a violation or as violations are forgiven.
+// Consumed by CityGovernor and RealityMonitor.
+const EventKarma = "player.reputation.karma"
+
+// punish applies the consequence of the violation's escalation tier.
+func (b *BanOfWorld) punish(ev eventbus.Event, playerID, violationType, tier string, record PlayerRecord) {
+	worldID := eventbus.GetWorldIDFromEvent(ev)
+	switch tier {
+	case TierWarning:
+		b.publishSanction(ev, playerID, "player.warned", map[string]any{
+			"reason": violationType,
+		})
+	case TierTransformation:
+		b.applyConsequence(ev, violationType)
+	case TierImprisonment:
+		b.publishSanction(ev, playerID, "player.imprisoned", map[string]any{
+			"reason":     violationType,
+			"prison":     "prison-realm",
+			"release_at": record.ImprisonedUntil,
+		})
+		b.publishTeleport(ev, playerID, worldID, "prison-realm", "imprisonment")
+	case TierExile:
+		b.publishSanction(ev, playerID, "player.exiled", map[string]any{
+			"reason":       violationType,
+			"exiled_until": record.Exiles[worldID],
+		})
+	}
+	logging.Infof("Player %s punished in %s: %s (%s, score %.2f)", playerID, worldID, tier, violationType, record.Score)
+
+	b.publishKarma(record, &ev, tier, violationType, "violation")
+}
+
+// publishSanction publishes a sanction event (warning, imprisonment, exile) for the player.
+func (b *BanOfWorld) publishSanction(ev eventbus.Event, playerID, eventType string, fields map[string]any) {
+	worldID := eventbus.GetWorldIDFromEvent(ev)
+	payload := eventbus.NewEventPayload().
+		WithEntity(playerID, "player", "").
+		WithWorld(worldID)
+
+	for path, value := range fields {
+		eventbus.SetNested(payload.GetCustom(), path, value)
+	}
+	eventbus.SetNested(payload.GetCustom(), "original_event", ev.ID)
+
+	sanctionEvent := eventbus.NewStructuredEvent(eventType, "ban-of-world", worldID, payload).CausedBy(ev)
+	sanctionEvent.ID = "sanction-" + uuid.New().String()[:8]
+	sanctionEvent.Timestamp = time.Now()
+	sanctionEvent.Scope = eventbus.GetScopeFromEvent(ev)
+	b.bus.Publish(context.Background(), eventbus.TopicWorldEvents, sanctionEvent)
+}
+
+// publishTeleport moves the player to the destination world as a consequence of cause.
+func (b *BanOfWorld) publishTeleport(cause eventbus.Event, playerID, worldID, destination, reason string) {
+	payload := eventbus.NewEventPayload().
+		WithEntity(playerID, "player", "").
+		WithWorld(worldID)
+
+	eventbus.SetNested(payload.GetCustom(), "player_id", playerID)
+	eventbus.SetNested(payload.GetCustom(), "destination", destination)
+	eventbus.SetNested(payload.GetCustom(), "reason", reason)
+
+	teleportEvent := eventbus.NewStructuredEvent("player.teleported", "ban-of-world", worldID, payload).CausedBy(cause)
+	teleportEvent.ID = "teleport-" + uuid.New().String()[:8]
+	teleportEvent.Timestamp = time.Now()
+	b.bus.Publish(context.Background(), eventbus.TopicWorldEvents, teleportEvent)
+}
+
+// publishKarma publishes player.reputation.karma for the player's latest world.
+// reason is "violation" or "decay". A violation's karma is caused by the violating event (nil on decay),
+// whose scope is kept so cities can react.
+func (b *BanOfWorld) publishKarma(record PlayerRecord, cause *eventbus.Event, tier, violationType, reason string) {
+	var worldID string
+	if n := len(record.Violations); n > 0 {
+		worldID = record.Violations[n-1].WorldID
+	}
+	payload := eventbus.NewEventPayload().
+		WithEntity(record.PlayerID, "player", "").
+		WithWorld(worldID)
+
+	eventbus.SetNested(payload.GetCustom(), "karma", record.Karma)
+	eventbus.SetNested(payload.GetCustom(), "violation_score", record.Score)
+	eventbus.SetNested(payload.GetCustom(), "violations", len(record.Violations))
+	eventbus.SetNested(payload.GetCustom(), "reason", reason)
+	if tier != "" {
+		eventbus.SetNested(payload.GetCustom(), "tier", tier)
+		eventbus.SetNested(payload.GetCustom(), "violation_type", violationType)
+	}
+
+	karmaEvent := eventbus.NewStructuredEvent(EventKarma, "ban-of-world", worldID, payload)
+	karmaEvent.ID = "karma-" + uuid.New().String()[:8]
+	karmaEvent.Timestamp = time.Now()
+	if cause != nil {
+		karmaEvent = karmaEvent.CausedBy(*cause)
+		karmaEvent.Scope = eventbus.GetScopeFromEvent(*cause)
+	}
+	b.bus.Publish(context.Background(), eventbus.TopicWorldEvents, karmaEvent)
+}
@@ -0,0 +1,281 @@
+package banofworld
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/archivist"
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+	"multiverse-core.io/shared/schema"
+)
+
+// Ontology profiles in OntologicalArchivist that carry forbiddance rules;
+// world-specific rules come from archivist.WorldProfileType profiles named by world ID.
+const (
+	// UniverseProfileType and UniverseProfileName locate the universe ban profile
+	// published by UniverseGenesisOracle; its rules apply to every world.
+	UniverseProfileType = "universe_ontology_profile"
+	UniverseProfileName = "cosmic_law"
+)
+
+// Rule is a declarative forbiddance: an action matching every set condition violates world integrity.
+// Conditions are glob patterns (path.Match), e.g. "fire_*"; a list matches if any pattern does.
+type Rule struct {
+	ID string `json:"id"`
+	// Forbiddance names the archetypal forbiddance of the profile the rule enforces.
+	Forbiddance string `json:"forbiddance,omitempty"`
+	// Worlds limits the rule to these worlds; empty applies to every world.
+	Worlds     []string `json:"worlds,omitempty"`
+	EventTypes []string `json:"event_types,omitempty"`
+	// Skills match the skill ID or name; SkillElements and SkillTags match the element
+	// and tags from the payload or the skill entity in EntityManager.
+	Skills        []string `json:"skills,omitempty"`
+	SkillElements []string `json:"skill_elements,omitempty"`
+	SkillTags     []string `json:"skill_tags,omitempty"`
+	Items         []string `json:"items,omitempty"`
+	// Payload maps payload paths (e.g. "action.target") to value patterns.
+	Payload       map[string]string `json:"payload,omitempty"`
+	ViolationType string            `json:"violation_type"`
+}
+
+// OntologyProfile is the part of a universe or world ontology profile used by the rule engine.
+type OntologyProfile struct {
+	ArchetypalForbiddances []string `json:"archetypal_forbiddances"`
+	ForbiddanceRules       []Rule   `json:"forbiddance_rules"`
+}
+
+// defaultRules apply to worlds without a world ontology profile.
+var defaultRules = []Rule{
+	// Fire and healing are forbidden in the World of Pain
+	{ID: "pain-realm-fire", Worlds: []string{"pain-realm"}, EventTypes: []string{"player.used_skill"}, Skills: []string{"fire_breath"}, ViolationType: "elemental_conflict"},
+	{ID: "pain-realm-healing", Worlds: []string{"pain-realm"}, EventTypes: []string{"player.used_item"}, Items: []string{"healing_potion"}, ViolationType: "elemental_conflict"},
+	// Memory erasure is forbidden in the World of Memory
+	{ID: "memory-realm-erase", Worlds: []string{"memory-realm"}, EventTypes: []string{"player.used_skill"}, Skills: []string{"memory_erase"}, ViolationType: "memory_violation"},
+	// Organic skills are forbidden in the World of Mechanisms
+	{ID: "mechanism-realm-organic", Worlds: []string{"mechanism-realm"}, EventTypes: []string{"player.used_skill"}, Skills: []string{"organic_skill"}, ViolationType: "mechanical_purity"},
+}
+
+// matches reports whether an event in worldID with the normalized action violates the rule.
+func (r Rule) matches(worldID string, ev eventbus.Event, action Action) bool {
+	if len(r.Worlds) > 0 && !slices.Contains(r.Worlds, worldID) {
+		return false
+	}
+	if !matchAny(r.EventTypes, ev.Type) || !matchAny(r.Items, action.Item) {
+		return false
+	}
+	skill := action.Skill
+	if !matchAny(r.Skills, skill.ID) && !matchAny(r.Skills, skill.Name) {
+		return false
+	}
+	if !matchAny(r.SkillElements, skill.Element) || !matchAnyOf(r.SkillTags, skill.Tags) {
+		return false
+	}
+	pa := ev.Path()
+	for field, pattern := range r.Payload {
+		value, ok := pa.GetAny(field)
+		if !ok || value == nil || !matchPattern(pattern, fmt.Sprint(value)) {
+			return false
+		}
+	}
+	return true
+}
+
+// validate rejects rules that would match every action or reference an unknown forbiddance.
+func (r Rule) validate(forbiddances []string) error {
+	if r.ViolationType == "" {
+		return fmt.Errorf("rule %q has no violation_type", r.ID)
+	}
+	if len(r.EventTypes) == 0 && len(r.Skills) == 0 && len(r.SkillElements) == 0 && len(r.SkillTags) == 0 &&
+		len(r.Items) == 0 && len(r.Payload) == 0 {
+		return fmt.Errorf("rule %q has no conditions", r.ID)
+	}
+	if r.Forbiddance != "" && len(forbiddances) > 0 && !slices.Contains(forbiddances, r.Forbiddance) {
+		return fmt.Errorf("rule %q enforces unknown forbiddance %q", r.ID, r.Forbiddance)
+	}
+	patterns := slices.Concat(r.EventTypes, r.Skills, r.SkillElements, r.SkillTags, r.Items)
+	for _, pattern := range r.Payload {
+		patterns = append(patterns, pattern)
+	}
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("rule %q has invalid pattern %q: %w", r.ID, pattern, err)
+		}
+	}
+	return nil
+}
+
+// matchAny reports whether value matches one of the patterns; no patterns match anything.
+func matchAny(patterns []string, value string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	if value == "" {
+		return false
+	}
+	for _, pattern := range patterns {
+		if matchPattern(pattern, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAnyOf reports whether one of the values matches one of the patterns; no patterns match anything.
+func matchAnyOf(patterns, values []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, value := range values {
+		if matchAny(patterns, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// needsSkillMetadata reports whether the rule matches skills by element or tags.
+func (r Rule) needsSkillMetadata() bool {
+	return len(r.SkillElements) > 0 || len(r.SkillTags) > 0
+}
+
+func matchPattern(pattern, value string) bool {
+	ok, err := path.Match(pattern, value)
+	return err == nil && ok
+}
+
+// profileRules returns the valid rules of a profile, restricted to worldID when set.
+func profileRules(profile OntologyProfile, worldID string) []Rule {
+	var rules []Rule
+	for _, rule := range profile.ForbiddanceRules {
+		if err := rule.validate(profile.ArchetypalForbiddances); err != nil {
+			logging.Warnf("Skipping forbiddance rule: %v", err)
+			continue
+		}
+		if worldID != "" {
+			rule.Worlds = []string{worldID}
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// RuleEngine matches player actions against forbiddance rules from the ontology profiles.
+// Without an archivist only defaultRules apply.
+type RuleEngine struct {
+	archivist *archivist.Client
+	skills    *SkillCatalog
+	worlds    *archivist.ProfileCache[OntologyProfile, []Rule]
+
+	mu       sync.RWMutex
+	universe []Rule
+}
+
+// NewRuleEngine creates a rule engine with the default rules.
+func NewRuleEngine() *RuleEngine {
+	return &RuleEngine{worlds: archivist.NewProfileCache(archivist.WorldProfileType, defaultRules, worldProfileRules)}
+}
+
+// UseArchivist loads rules from the ontology profiles in OntologicalArchivist.
+func (e *RuleEngine) UseArchivist(client *archivist.Client) {
+	e.archivist = client
+	e.worlds.UseClient(client)
+}
+
+// UseSkillCatalog looks up the element and tags of skills named only by ID in EntityManager.
+func (e *RuleEngine) UseSkillCatalog(skills *SkillCatalog) {
+	e.skills = skills
+}
+
+// Match returns the first rule the event violates; world rules are checked before universe rules.
+func (e *RuleEngine) Match(ev eventbus.Event) (Rule, bool) {
+	worldID := eventbus.GetWorldIDFromEvent(ev)
+	rules := e.rulesFor(worldID)
+	action := e.resolveAction(worldID, ev, rules)
+	for _, rule := range rules {
+		if rule.matches(worldID, ev, action) {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+// resolveAction normalizes the action and, if a rule matches skills by element or tags
+// the payload does not carry, completes the skill from its entity.
+func (e *RuleEngine) resolveAction(worldID string, ev eventbus.Event, rules []Rule) Action {
+	action := normalizeAction(ev)
+	if e.skills == nil || action.Skill.ID == "" || action.Skill.hasMetadata() || !slices.ContainsFunc(rules, Rule.needsSkillMetadata) {
+		return action
+	}
+	if skill, ok := e.skills.Lookup(context.Background(), worldID, action.Skill.ID); ok {
+		if action.Skill.Name != "" {
+			skill.Name = action.Skill.Name
+		}
+		action.Skill = skill
+	}
+	return action
+}
+
+// rulesFor returns the rules of a world, loading its profile on first use.
+func (e *RuleEngine) rulesFor(worldID string) []Rule {
+	world, _ := e.worlds.Get(worldID)
+	rules := slices.Clone(world)
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return append(rules, e.universe...)
+}
+
+// LoadUniverse loads the universe rules. A missing profile clears them;
+// on other errors the previous rules are kept.
+func (e *RuleEngine) LoadUniverse(ctx context.Context) error {
+	if e.archivist == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var profile OntologyProfile
+	err := e.archivist.GetSchema(ctx, UniverseProfileType, UniverseProfileName, &profile)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("failed to load universe ontology profile: %w", err)
+	}
+	rules := profileRules(profile, "")
+
+	e.mu.Lock()
+	e.universe = rules
+	e.mu.Unlock()
+	logging.Infof("Loaded %d universe forbiddance rules", len(rules))
+	return nil
+}
+
+// worldProfileRules returns the valid rules of a world profile.
+func worldProfileRules(worldID string, profile OntologyProfile) []Rule {
+	rules := profileRules(profile, worldID)
+	logging.Infof("Loaded %d forbiddance rules for world %s", len(rules), worldID)
+	return rules
+}
+
+// HandleSchemaChange reloads rules when the archivist announces a new profile version.
+func (e *RuleEngine) HandleSchemaChange(change schema.Change) {
+	if e.archivist == nil {
+		return
+	}
+	switch change.SchemaType {
+	case UniverseProfileType:
+		if change.Name != UniverseProfileName {
+			return
+		}
+		if err := e.LoadUniverse(context.Background()); err != nil {
+			logging.Infof("Keeping previous universe forbiddance rules: %v", err)
+		}
+	case archivist.WorldProfileType:
+		e.worlds.HandleSchemaChange(change)
+	}
+}
@@ -0,0 +1,130 @@
+package banofworld
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"multiverse-core.io/shared/archivist"
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/schema"
+)
+
+func playerAction(eventType, worldID string, payload map[string]interface{}) eventbus.Event {
+	payload["entity"] = map[string]interface{}{"id": "player-1", "type": "player"}
+	return eventbus.NewEvent(eventType, "test", worldID, payload)
+}
+
+func TestDefaultRules(t *testing.T) {
+	engine := NewRuleEngine()
+	cases := []struct {
+		ev   eventbus.Event
+		want string
+	}{
+		{playerAction("player.used_skill", "pain-realm", map[string]interface{}{"skill": "fire_breath"}), "elemental_conflict"},
+		{playerAction("player.used_skill", "pain-realm", map[string]interface{}{"action": map[string]interface{}{"skill": "fire_breath"}}), "elemental_conflict"},
+		{playerAction("player.used_item", "pain-realm", map[string]interface{}{"item": "healing_potion"}), "elemental_conflict"},
+		{playerAction("player.used_skill", "pain-realm", map[string]interface{}{"skill": "healing_potion"}), ""},
+		{playerAction("player.used_skill", "memory-realm", map[string]interface{}{"skill": "memory_erase"}), "memory_violation"},
+		{playerAction("player.used_skill", "mechanism-realm", map[string]interface{}{"skill": "organic_skill"}), "mechanical_purity"},
+		{playerAction("player.used_skill", "memory-realm", map[string]interface{}{"skill": "fire_breath"}), ""},
+	}
+	for _, c := range cases {
+		rule, _ := engine.Match(c.ev)
+		if rule.ViolationType != c.want {
+			t.Errorf("%s %v in %s: violation %q, want %q", c.ev.Type, c.ev.Payload, eventbus.GetWorldIDFromEvent(c.ev), rule.ViolationType, c.want)
+		}
+	}
+}
+
+func TestRuleValidation(t *testing.T) {
+	forbiddances := []string{"Фиксация Абсолютного Порядка"}
+	invalid := map[string]Rule{
+		"no violation":        {ID: "r", Skills: []string{"fire_*"}},
+		"no conditions":       {ID: "r", ViolationType: "v"},
+		"unknown forbiddance": {ID: "r", Forbiddance: "Иное", Skills: []string{"x"}, ViolationType: "v"},
+		"bad pattern":         {ID: "r", Payload: map[string]string{"action.target": "[core"}, ViolationType: "v"},
+	}
+	for name, rule := range invalid {
+		if err := rule.validate(forbiddances); err == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+	}
+	valid := Rule{ID: "r", Forbiddance: forbiddances[0], Skills: []string{"time_*"}, ViolationType: "order_fixation"}
+	if err := valid.validate(forbiddances); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestRuleEngineProfiles(t *testing.T) {
+	var universeVersion, worldRequests atomic.Int32
+	archivistServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/schemas/universe_ontology_profile/cosmic_law/latest":
+			if universeVersion.Load() == 0 {
+				w.Write([]byte(`{"archetypal_forbiddances": ["Фиксация Абсолютного Порядка"], "forbiddance_rules": [
+					{"id": "freeze", "forbiddance": "Фиксация Абсолютного Порядка", "skills": ["time_*"], "violation_type": "order_fixation"},
+					{"id": "broken", "violation_type": "everything"}]}`))
+				return
+			}
+			w.Write([]byte(`{"archetypal_forbiddances": ["Фиксация Абсолютного Порядка"]}`))
+		case "/v1/schemas/world_ontology_profile/ash-realm/latest":
+			worldRequests.Add(1)
+			w.Write([]byte(`{"forbiddance_rules": [
+				{"id": "core", "event_types": ["player.attacked"], "payload": {"action.target": "core-*", "action.power": "9*"}, "violation_type": "core_assault"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer archivistServer.Close()
+
+	engine := NewRuleEngine()
+	engine.UseArchivist(archivist.NewClient(archivistServer.URL, nil))
+	if err := engine.LoadUniverse(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Universe rules apply everywhere, the invalid one is skipped
+	if rule, ok := engine.Match(playerAction("player.used_skill", "memory-realm", map[string]interface{}{"skill": "time_stop"})); !ok || rule.ID != "freeze" {
+		t.Errorf("expected universe rule, got %+v", rule)
+	}
+	if _, ok := engine.Match(playerAction("player.used_item", "memory-realm", map[string]interface{}{"item": "apple"})); ok {
+		t.Errorf("rule without conditions must be skipped")
+	}
+
+	// World profile rules replace the default rules of the world and match payload fields
+	attack := playerAction("player.attacked", "ash-realm", map[string]interface{}{"action": map[string]interface{}{"target": "core-1", "power": 95}})
+	if rule, ok := engine.Match(attack); !ok || rule.ViolationType != "core_assault" {
+		t.Errorf("expected world rule, got %+v", rule)
+	}
+	weak := playerAction("player.attacked", "ash-realm", map[string]interface{}{"action": map[string]interface{}{"target": "core-1", "power": 15}})
+	if _, ok := engine.Match(weak); ok {
+		t.Errorf("all payload patterns must match")
+	}
+	if _, ok := engine.Match(playerAction("player.attacked", "pain-realm", attack.Payload)); ok {
+		t.Errorf("world rules must not apply to other worlds")
+	}
+	if rule, ok := engine.Match(playerAction("player.used_skill", "pain-realm", map[string]interface{}{"skill": "fire_breath"})); !ok || rule.ID != "pain-realm-fire" {
+		t.Errorf("worlds without a profile keep the default rules, got %+v", rule)
+	}
+	if n := worldRequests.Load(); n != 1 {
+		t.Errorf("world profile must be cached, loaded %d times", n)
+	}
+
+	// Hot reload on schema changes
+	universeVersion.Store(1)
+	engine.HandleSchemaChange(schema.Change{SchemaType: "entity", Name: "player"})
+	if _, ok := engine.Match(playerAction("player.used_skill", "memory-realm", map[string]interface{}{"skill": "time_stop"})); !ok {
+		t.Errorf("unrelated schema changes must not reload rules")
+	}
+	engine.HandleSchemaChange(schema.Change{SchemaType: UniverseProfileType, Name: UniverseProfileName, Version: "v2"})
+	if _, ok := engine.Match(playerAction("player.used_skill", "memory-realm", map[string]interface{}{"skill": "time_stop"})); ok {
+		t.Errorf("universe rules must be reloaded")
+	}
+	engine.HandleSchemaChange(schema.Change{SchemaType: archivist.WorldProfileType, Name: "ash-realm", Version: "v2"})
+	if n := worldRequests.Load(); n != 2 {
+		t.Errorf("world profile must be reloaded, loaded %d times", n)
+	}
+}
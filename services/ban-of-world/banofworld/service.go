@@ -3,14 +3,26 @@ package banofworld
 
 import (
 	"context"
+	"net/http"
+	"time"
 
+	"multiverse-core.io/shared/archivist"
 	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+	"multiverse-core.io/shared/schema"
 )
 
 // Service manages the BanOfWorld lifecycle.
 type Service struct {
 	bus *eventbus.EventBus
 	ban *BanOfWorld
+	// schemaChanges reloads forbiddance rules when the archivist announces a new profile version
+	schemaChanges *schema.ChangeSubscriber
+	// ledgerInterval is how often karma decay is published and the violation ledger is saved
+	ledgerInterval time.Duration
+	// server serves the pre-publication check API; nil — disabled
+	server *http.Server
 }
 
 // NewService creates a new BanOfWorld service.
@@ -21,10 +33,64 @@ func NewService(bus *eventbus.EventBus) *Service {
 	}
 }
 
+// UseArchivist loads forbiddance rules from the ontology profiles in OntologicalArchivist
+// and reloads them on schema changes.
+func (s *Service) UseArchivist(client *archivist.Client) {
+	s.ban.rules.UseArchivist(client)
+	s.schemaChanges = schema.NewChangeSubscriber(s.bus, "ban-of-world")
+	s.schemaChanges.OnChange(s.ban.rules.HandleSchemaChange)
+}
+
+// UseSkillCatalog lets forbiddance rules match skills by element and tags looked up in EntityManager.
+func (s *Service) UseSkillCatalog(skills *SkillCatalog) {
+	s.ban.rules.UseSkillCatalog(skills)
+}
+
+// UseLedgerStorage enables persisting the violation ledger to MinIO, publishing karma
+// decay and saving changed records every interval (<= 0 — DefaultLedgerInterval).
+func (s *Service) UseLedgerStorage(client storage.ObjectStorage, interval time.Duration) {
+	s.ban.ledger.UseStorage(client)
+	s.ledgerInterval = interval
+}
+
+// SetViolationHalfLife sets how fast violations are forgiven (default DefaultViolationHalfLife).
+func (s *Service) SetViolationHalfLife(halfLife time.Duration) {
+	s.ban.ledger.SetHalfLife(halfLife)
+}
+
+// UseHTTP enables the HTTP API with the pre-publication check used by GameService.
+func (s *Service) UseHTTP(port string) {
+	if port == "" {
+		port = DefaultHTTPPort
+	}
+	s.server = &http.Server{
+		Addr:         ":" + port,
+		Handler:      s.routes(),
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	}
+}
+
 // Run starts the service and blocks until context is cancelled.
 func (s *Service) Run(ctx context.Context) error {
+	if err := s.ban.ledger.Load(); err != nil {
+		logging.Errorf("Failed to load violation ledger, starting empty: %v", err)
+	}
+	go s.ban.RunLedger(ctx, s.ledgerInterval)
+
+	if s.schemaChanges != nil {
+		if err := s.ban.rules.LoadUniverse(ctx); err != nil {
+			logging.Infof("Universe forbiddance rules not loaded: %v", err)
+		}
+		go s.schemaChanges.Run(ctx)
+	}
+	if s.server != nil {
+		go s.serveHTTP(ctx)
+	}
 	// Subscribe to player_events for integrity checks
-	s.bus.Subscribe(ctx, eventbus.TopicPlayerEvents, "ban-of-world-group", s.ban.HandlePlayerEvent)
+	go s.bus.Subscribe(ctx, eventbus.TopicPlayerEvents, "ban-of-world-group", s.ban.HandlePlayerEvent)
+	// Subscribe to system_events to track world bounds and regions
+	go s.bus.Subscribe(ctx, eventbus.TopicSystemEvents, "ban-of-world-world-group", s.ban.HandleWorldEvent)
 	<-ctx.Done()
 	return ctx.Err()
 }
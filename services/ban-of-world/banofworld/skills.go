@@ -0,0 +1,138 @@
+package banofworld
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+)
+
+// SkillEntityType is the EntityManager entity type of skills; the entity ID is the skill ID.
+const SkillEntityType = "skill"
+
+// Skill metadata caching: skills rarely change, an unavailable EntityManager is retried sooner.
+const (
+	skillCacheTTL      = 10 * time.Minute
+	skillRetryInterval = time.Minute
+)
+
+// cachedSkill is a looked-up skill; found is false for skills without an entity.
+type cachedSkill struct {
+	skill     Skill
+	found     bool
+	expiresAt time.Time
+}
+
+// SkillCatalog looks up skill metadata (name, element, tags) in EntityManager,
+// so rules can match skills by element and tags when the payload only names the skill.
+type SkillCatalog struct {
+	BaseURL    string
+	httpClient *http.Client
+
+	mu    sync.Mutex
+	cache map[string]cachedSkill
+}
+
+// NewSkillCatalog creates a skill catalog reading from the EntityManager API at baseURL.
+func NewSkillCatalog(baseURL string) *SkillCatalog {
+	if baseURL == "" {
+		baseURL = "http://entity-manager:8085"
+	}
+	return &SkillCatalog{
+		BaseURL:    baseURL,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		cache:      make(map[string]cachedSkill),
+	}
+}
+
+// Lookup returns the skill entity of a world, falling back to the global entities.
+// Results, including missing skills, are cached; lookup failures return false.
+func (c *SkillCatalog) Lookup(ctx context.Context, worldID, skillID string) (Skill, bool) {
+	key := worldID + "/" + skillID
+	c.mu.Lock()
+	cached, ok := c.cache[key]
+	c.mu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.skill, cached.found
+	}
+
+	cached = cachedSkill{expiresAt: time.Now().Add(skillCacheTTL)}
+	for _, bucket := range []string{worldID, "global"} {
+		if bucket == "" {
+			continue
+		}
+		skill, err := c.fetch(ctx, bucket, skillID)
+		if err == nil {
+			cached.skill, cached.found = skill, true
+			break
+		}
+		if !storage.IsNotFound(err) {
+			logging.Warnf("Skill %s of %s unavailable: %v", skillID, worldID, err)
+			cached.expiresAt = time.Now().Add(skillRetryInterval)
+			break
+		}
+	}
+
+	c.mu.Lock()
+	c.cache[key] = cached
+	c.mu.Unlock()
+	return cached.skill, cached.found
+}
+
+// fetch reads a skill entity from GET /v1/worlds/{world_id}/entities.
+// A missing world or entity is reported as storage.ErrNotFound.
+func (c *SkillCatalog) fetch(ctx context.Context, worldID, skillID string) (Skill, error) {
+	query := url.Values{
+		"type":   {SkillEntityType},
+		"prefix": {skillID},
+		"limit":  {"1"},
+		"fields": {"name,element,tags"},
+	}
+	endpoint := fmt.Sprintf("%s/v1/worlds/%s/entities?%s", c.BaseURL, url.PathEscape(worldID), query.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return Skill{}, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return Skill{}, fmt.Errorf("entity manager connection failed: %v: %w", err, storage.ErrUnavailable)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return Skill{}, fmt.Errorf("world %s: %w", worldID, storage.ErrNotFound)
+	case resp.StatusCode != http.StatusOK:
+		body, _ := io.ReadAll(resp.Body)
+		return Skill{}, fmt.Errorf("entity manager returned status %d: %s: %w", resp.StatusCode, string(body), storage.ErrUnavailable)
+	}
+
+	var page struct {
+		Entities []struct {
+			ID      string                 `json:"id"`
+			Payload map[string]interface{} `json:"payload"`
+		} `json:"entities"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return Skill{}, fmt.Errorf("invalid entity list: %w", err)
+	}
+	// prefix also matches longer IDs (fire → fire_breath)
+	for _, ent := range page.Entities {
+		if ent.ID != skillID {
+			continue
+		}
+		skill := Skill{ID: skillID}
+		skill.Name, _ = ent.Payload["name"].(string)
+		skill.Element, _ = ent.Payload["element"].(string)
+		skill.Tags, _ = stringList(ent.Payload["tags"])
+		return skill, nil
+	}
+	return Skill{}, fmt.Errorf("skill %s in %s: %w", skillID, worldID, storage.ErrNotFound)
+}
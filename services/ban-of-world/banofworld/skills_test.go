@@ -0,0 +1,89 @@
+package banofworld
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestNormalizeAction(t *testing.T) {
+	cases := []struct {
+		ev   map[string]interface{}
+		want Skill
+	}{
+		{map[string]interface{}{"skill_id": "sky_rend", "skill_name": "Разрыв небес"}, Skill{ID: "sky_rend", Name: "Разрыв небес"}},
+		{map[string]interface{}{"skill": map[string]interface{}{"id": "fire_breath", "element": "fire", "tags": []interface{}{"breath", "area"}}}, Skill{ID: "fire_breath", Element: "fire", Tags: []string{"breath", "area"}}},
+		{map[string]interface{}{"action": map[string]interface{}{"skill": "memory_erase"}}, Skill{ID: "memory_erase"}},
+		{map[string]interface{}{"skill_name": "Разрыв небес", "skill_tags": []string{"void"}}, Skill{Name: "Разрыв небес", Tags: []string{"void"}}},
+	}
+	for _, c := range cases {
+		if got := normalizeAction(playerAction("player.used_skill", "pain-realm", c.ev)).Skill; !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%v: skill %+v, want %+v", c.ev, got, c.want)
+		}
+	}
+
+	// Items are read only from item actions and unknown action types
+	if action := normalizeAction(playerAction("player.used_item", "pain-realm", map[string]interface{}{"item_id": "healing_potion", "skill": "x"})); action.Item != "healing_potion" || action.Skill.Label() != "" {
+		t.Errorf("unexpected item action %+v", action)
+	}
+	if action := normalizeAction(playerAction("player.channeled", "pain-realm", map[string]interface{}{"skill_id": "void_call", "item": "staff"})); action.Item != "staff" || action.Skill.ID != "void_call" {
+		t.Errorf("unexpected generic action %+v", action)
+	}
+}
+
+func TestRulesMatchSkillMetadata(t *testing.T) {
+	var requests atomic.Int32
+	entityManager := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Query().Get("type") != SkillEntityType {
+			http.Error(w, "unexpected type", http.StatusBadRequest)
+			return
+		}
+		switch r.URL.Path + "?" + r.URL.Query().Get("prefix") {
+		case "/v1/worlds/pain-realm/entities?sky_rend":
+			w.Write([]byte(`{"entities": [{"id": "sky_rend", "type": "skill", "payload": {"name": "Разрыв небес", "element": "void", "tags": ["void", "ranged"]}}]}`))
+		case "/v1/worlds/global/entities?ember":
+			w.Write([]byte(`{"entities": [{"id": "ember", "type": "skill", "payload": {"element": "fire"}}]}`))
+		case "/v1/worlds/pain-realm/entities?ember":
+			w.Write([]byte(`{"entities": [{"id": "ember_dance", "type": "skill", "payload": {"element": "air"}}]}`))
+		default:
+			w.Write([]byte(`{"entities": []}`))
+		}
+	}))
+	defer entityManager.Close()
+
+	engine := NewRuleEngine()
+	engine.UseSkillCatalog(NewSkillCatalog(entityManager.URL))
+	engine.universe = []Rule{
+		{ID: "no-fire", SkillElements: []string{"fire"}, ViolationType: "elemental_conflict"},
+		{ID: "no-void", Worlds: []string{"pain-realm"}, SkillTags: []string{"void"}, ViolationType: "void_breach"},
+	}
+
+	// The element comes from the global skill entity; the longer prefixed ID is ignored
+	if rule, ok := engine.Match(playerAction("player.used_skill", "pain-realm", map[string]interface{}{"skill_id": "ember"})); !ok || rule.ID != "no-fire" {
+		t.Errorf("expected element rule, got %+v", rule)
+	}
+	// Tags come from the world skill entity, the payload name is kept
+	skill := engine.resolveAction("pain-realm", playerAction("player.used_skill", "pain-realm", map[string]interface{}{"skill_id": "sky_rend", "skill_name": "Sky Rend"}), engine.universe).Skill
+	if skill.Name != "Sky Rend" || skill.Element != "void" || !reflect.DeepEqual(skill.Tags, []string{"void", "ranged"}) {
+		t.Errorf("unexpected resolved skill %+v", skill)
+	}
+	if rule, ok := engine.Match(playerAction("player.used_skill", "pain-realm", map[string]interface{}{"skill_id": "sky_rend"})); !ok || rule.ID != "no-void" {
+		t.Errorf("expected tag rule, got %+v", rule)
+	}
+	if _, ok := engine.Match(playerAction("player.used_skill", "pain-realm", map[string]interface{}{"skill_id": "ice_shard"})); ok {
+		t.Errorf("skills without an entity must not match metadata rules")
+	}
+
+	// Metadata in the payload wins over the lookup; lookups are cached
+	before := requests.Load()
+	if _, ok := engine.Match(playerAction("player.used_skill", "pain-realm", map[string]interface{}{"skill": map[string]interface{}{"id": "ember", "element": "water"}})); ok {
+		t.Errorf("payload element must be used without lookup")
+	}
+	engine.Match(playerAction("player.used_skill", "pain-realm", map[string]interface{}{"skill_id": "sky_rend"}))
+	if n := requests.Load(); n != before {
+		t.Errorf("expected cached skills, got %d new requests", n-before)
+	}
+}
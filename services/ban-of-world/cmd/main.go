@@ -2,43 +2,51 @@
 package main
 
 import (
-	"context"
-	"log"
-	"os"
-	"os/signal"
-	"strings"
-	"syscall"
-
-	"multiverse-core.io/shared/eventbus"
 	"multiverse-core.io/services/ban-of-world/banofworld"
+	"multiverse-core.io/shared/archivist"
+	"multiverse-core.io/shared/config"
+	"multiverse-core.io/shared/logging"
+	"multiverse-core.io/shared/registry"
+	"multiverse-core.io/shared/service"
 )
 
 func main() {
-	// Initialize event bus
-	brokers := strings.Split(os.Getenv("KAFKA_BROKERS"), ",")
-	if len(brokers) == 0 || brokers[0] == "" {
-		brokers = []string{"redpanda:9092"}
-	}
-	bus := eventbus.NewEventBus(brokers)
+	app := service.Setup("ban-of-world", []config.Option{
+		{Env: "ARCHIVIST_URL", Type: config.TypeURL, Usage: "fallback archivist address"},
+		{Env: "RULES_FROM_ARCHIVIST", Default: "true", Type: config.TypeBool, Usage: "load forbiddance rules from ontology profiles (false uses built-in rules only)"},
+		{Env: "LEDGER_SNAPSHOT_INTERVAL", Default: "1m", Type: config.TypeDuration, Positive: true, Usage: "interval between karma decay updates and violation ledger snapshots"},
+		{Env: "BAN_OF_WORLD_PORT", Default: "8090", Type: config.TypeInt, Positive: true, Usage: "HTTP API port (pre-publication action checks)"},
+		{Env: "ENTITY_MANAGER_URL", Default: "http://entity-manager:8085", Type: config.TypeURL, Usage: "EntityManager address (skill element and tags for forbiddance rules); empty disables lookups"},
+		{Env: "VIOLATION_HALF_LIFE", Default: "24h", Type: config.TypeDuration, Positive: true, Usage: "time after which a violation counts half as much"},
+	})
+	env := app.Env
 
-	// Create and run service
-	service := banofworld.NewService(bus)
+	guardian := banofworld.NewService(app.Bus())
+	guardian.UseHTTP(env.String("BAN_OF_WORLD_PORT"))
+	guardian.SetViolationHalfLife(env.Duration("VIOLATION_HALF_LIFE"))
 
-	// Handle shutdown
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	// Violation ledger persistence (optional: without MinIO the ledger lives in memory)
+	ledgerInterval := env.Duration("LEDGER_SNAPSHOT_INTERVAL")
+	minioClient, err := app.MinIO()
+	if err != nil {
+		logging.Warnf("MinIO unavailable, violation ledger is kept in memory only: %v", err)
+		guardian.UseLedgerStorage(nil, ledgerInterval)
+	} else {
+		guardian.UseLedgerStorage(minioClient, ledgerInterval)
+	}
 
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	go func() {
-		<-sigChan
-		log.Println("Shutting down BanOfWorld...")
-		cancel()
-	}()
+	// Forbiddance rules come from the ontology profiles in the archivist
+	// (address through the service registry); built-in rules are the fallback
+	discovery := registry.NewDiscovery(app.Bus(), "ban-of-world")
+	if env.Bool("RULES_FROM_ARCHIVIST") {
+		guardian.UseArchivist(archivist.NewClient(env.String("ARCHIVIST_URL"), discovery))
+	}
+	app.Go(discovery.Run)
 
-	log.Println("BanOfWorld starting...")
-	if err := service.Run(ctx); err != nil && err != context.Canceled {
-		log.Fatal("Service failed:", err)
+	// Skills named only by ID are matched by element and tags from their entity
+	if url := env.String("ENTITY_MANAGER_URL"); url != "" {
+		guardian.UseSkillCatalog(banofworld.NewSkillCatalog(url))
 	}
-	log.Println("BanOfWorld stopped.")
+
+	app.Run(guardian)
 }
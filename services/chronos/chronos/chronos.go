@@ -0,0 +1,300 @@
+// Package chronos implements Chronos, the world time service: per-world clocks running at the
+// configured time scale, slowed down or sped up by the plan of the world, and time.syncTime ticks
+// carrying the world time and the in-world calendar date.
+package chronos
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/eventbus/events"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+	"multiverse-core.io/shared/worldtime"
+)
+
+const (
+	// DefaultTimeScale is how many world seconds pass per real second (1 real minute — 1 world hour).
+	DefaultTimeScale = 60
+	// DefaultTickInterval is how often time.syncTime is published for every world.
+	DefaultTickInterval = 10 * time.Second
+
+	clocksBucket = "chronos"
+	clocksObject = "clocks.json"
+)
+
+// WorldClock is the clock of a world together with the plan that sets its dilation.
+type WorldClock struct {
+	WorldID   string `json:"world_id"`
+	PlanLevel int    `json:"plan_level"`
+	worldtime.Clock
+}
+
+// Chronos keeps the clocks of all known worlds.
+type Chronos struct {
+	bus       *eventbus.EventBus
+	calendar  worldtime.Calendar
+	timeScale float64
+	// dilation multiplies the time scale per plan level; missing plans run at the base scale
+	dilation map[int]float64
+	now      func() time.Time
+	// storage persists the clocks; nil — clocks restart with the service
+	storage storage.ObjectStorage
+
+	mu     sync.Mutex
+	worlds map[string]*WorldClock
+	saveMu sync.Mutex
+}
+
+// NewChronos creates Chronos with the calendar and the base time scale (<= 0 — DefaultTimeScale).
+func NewChronos(bus *eventbus.EventBus, calendar worldtime.Calendar, timeScale float64) *Chronos {
+	if timeScale <= 0 {
+		timeScale = DefaultTimeScale
+	}
+	return &Chronos{
+		bus:       bus,
+		calendar:  calendar,
+		timeScale: timeScale,
+		dilation:  make(map[int]float64),
+		now:       time.Now,
+		worlds:    make(map[string]*WorldClock),
+	}
+}
+
+// SetDilation sets the time dilation per plan level, e.g. {1: 2} — time on Plan 1 runs twice as fast.
+// Already running clocks keep their rate until their plan changes or the service restarts.
+func (c *Chronos) SetDilation(dilation map[int]float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dilation = make(map[int]float64, len(dilation))
+	for plan, factor := range dilation {
+		c.dilation[plan] = factor
+	}
+}
+
+// ParseDilation parses "plan=factor" pairs, e.g. ["1=2", "2=0.5"].
+func ParseDilation(values []string) (map[int]float64, error) {
+	dilation := make(map[int]float64, len(values))
+	for _, value := range values {
+		planValue, factorValue, ok := strings.Cut(value, "=")
+		if !ok {
+			return nil, fmt.Errorf("dilation %q: want plan=factor", value)
+		}
+		plan, err := strconv.Atoi(strings.TrimSpace(planValue))
+		if err != nil {
+			return nil, fmt.Errorf("dilation %q: invalid plan level", value)
+		}
+		factor, err := strconv.ParseFloat(strings.TrimSpace(factorValue), 64)
+		if err != nil || factor <= 0 {
+			return nil, fmt.Errorf("dilation %q: factor must be a positive number", value)
+		}
+		dilation[plan] = factor
+	}
+	return dilation, nil
+}
+
+// UseStorage enables persisting the clocks to MinIO, so world time survives restarts.
+func (c *Chronos) UseStorage(client storage.ObjectStorage) {
+	c.storage = client
+}
+
+// Load reads the stored clocks. Stored clocks whose rate no longer matches the configured
+// scale and dilation continue at the new rate from now.
+func (c *Chronos) Load() error {
+	if c.storage == nil {
+		return nil
+	}
+	data, err := c.storage.GetObject(clocksBucket, clocksObject)
+	if err != nil {
+		if storage.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("load world clocks: %w", err)
+	}
+	var clocks []WorldClock
+	if err := json.Unmarshal(data, &clocks); err != nil {
+		return fmt.Errorf("decode world clocks: %w", err)
+	}
+
+	now := c.now()
+	c.mu.Lock()
+	for i := range clocks {
+		clock := clocks[i]
+		if _, ok := c.worlds[clock.WorldID]; ok || clock.WorldID == "" {
+			continue
+		}
+		if rate := c.rateLocked(clock.PlanLevel); clock.Rate != rate {
+			clock.SetRate(now, rate)
+		}
+		c.worlds[clock.WorldID] = &clock
+	}
+	c.mu.Unlock()
+	c.save()
+	return nil
+}
+
+// AddWorld starts the clock of a world at world time zero; known worlds are left as they are.
+func (c *Chronos) AddWorld(worldID string) {
+	if worldID == "" {
+		return
+	}
+	c.mu.Lock()
+	if _, ok := c.worlds[worldID]; ok {
+		c.mu.Unlock()
+		return
+	}
+	c.worlds[worldID] = &WorldClock{WorldID: worldID, Clock: worldtime.NewClock(c.now(), c.rateLocked(0))}
+	c.mu.Unlock()
+	logging.Infof("World clock started for %s", worldID)
+	c.save()
+}
+
+// SetPlan moves a world to a plan: from now on its clock runs with the dilation of the plan.
+func (c *Chronos) SetPlan(worldID string, planLevel int) {
+	if worldID == "" {
+		return
+	}
+	now := c.now()
+	c.mu.Lock()
+	clock, ok := c.worlds[worldID]
+	if !ok {
+		clock = &WorldClock{WorldID: worldID, Clock: worldtime.NewClock(now, c.rateLocked(planLevel))}
+		c.worlds[worldID] = clock
+	} else if clock.PlanLevel == planLevel {
+		c.mu.Unlock()
+		return
+	}
+	clock.PlanLevel = planLevel
+	clock.SetRate(now, c.rateLocked(planLevel))
+	rate := clock.Rate
+	c.mu.Unlock()
+	logging.Infof("World %s is on Plan %d, time rate %g", worldID, planLevel, rate)
+	c.save()
+}
+
+// rateLocked returns the world seconds per real second on the plan. c.mu must be held.
+func (c *Chronos) rateLocked(planLevel int) float64 {
+	factor, ok := c.dilation[planLevel]
+	if !ok || factor <= 0 {
+		factor = 1
+	}
+	return c.timeScale * factor
+}
+
+// HandleEvent registers generated worlds and follows their plan.
+func (c *Chronos) HandleEvent(ev eventbus.Event) {
+	switch ev.Type {
+	case "world.generated":
+		c.AddWorld(eventbus.GetWorldIDFromEvent(ev))
+	case "plan.initialized":
+		worldID, _ := ev.Payload["world_id"].(string)
+		if worldID == "" {
+			worldID = eventbus.GetWorldIDFromEvent(ev)
+		}
+		// plan_level is an int when published and a float64 after Kafka
+		switch level := ev.Payload["plan_level"].(type) {
+		case float64:
+			c.SetPlan(worldID, int(level))
+		case int:
+			c.SetPlan(worldID, level)
+		}
+	}
+}
+
+// Time returns the current tick of a world; false if the world has no clock.
+func (c *Chronos) Time(worldID string) (events.TimeSync, bool) {
+	now := c.now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clock, ok := c.worlds[worldID]
+	if !ok {
+		return events.TimeSync{}, false
+	}
+	return c.tickLocked(clock, now), true
+}
+
+func (c *Chronos) tickLocked(clock *WorldClock, now time.Time) events.TimeSync {
+	worldTime := clock.At(now)
+	date := c.calendar.Date(worldTime)
+	return events.TimeSync{
+		CurrentTimeUnixMs: now.UnixMilli(),
+		WorldTimeMs:       worldTime.Milliseconds(),
+		Rate:              clock.Rate,
+		PlanLevel:         clock.PlanLevel,
+		Calendar:          &date,
+	}
+}
+
+// ticks builds time.syncTime events for all worlds, ordered by world ID.
+func (c *Chronos) ticks() []eventbus.Event {
+	now := c.now()
+	c.mu.Lock()
+	worldIDs := make([]string, 0, len(c.worlds))
+	for worldID := range c.worlds {
+		worldIDs = append(worldIDs, worldID)
+	}
+	sort.Strings(worldIDs)
+	payloads := make([]events.TimeSync, len(worldIDs))
+	for i, worldID := range worldIDs {
+		payloads[i] = c.tickLocked(c.worlds[worldID], now)
+	}
+	c.mu.Unlock()
+
+	result := make([]eventbus.Event, 0, len(worldIDs))
+	for i, worldID := range worldIDs {
+		ev, err := events.New("chronos", worldID, payloads[i])
+		if err != nil {
+			logging.Errorf("Failed to build time.syncTime for %s: %v", worldID, err)
+			continue
+		}
+		result = append(result, ev)
+	}
+	return result
+}
+
+// Tick publishes time.syncTime for every world.
+func (c *Chronos) Tick(ctx context.Context) {
+	for _, ev := range c.ticks() {
+		if err := c.bus.PublishSystemEvent(ctx, ev); err != nil {
+			logging.Errorf("Failed to publish time.syncTime for %s: %v", eventbus.GetWorldIDFromEvent(ev), err)
+		}
+	}
+}
+
+// Clocks returns a snapshot of the clocks ordered by world ID.
+func (c *Chronos) Clocks() []WorldClock {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clocks := make([]WorldClock, 0, len(c.worlds))
+	for _, clock := range c.worlds {
+		clocks = append(clocks, *clock)
+	}
+	sort.Slice(clocks, func(i, j int) bool { return clocks[i].WorldID < clocks[j].WorldID })
+	return clocks
+}
+
+// save writes all clocks to storage; failures are logged and retried on the next change.
+// Clocks only change on rate changes: between them world time follows from the anchor.
+func (c *Chronos) save() {
+	if c.storage == nil {
+		return
+	}
+	c.saveMu.Lock()
+	defer c.saveMu.Unlock()
+	data, err := json.Marshal(c.Clocks())
+	if err != nil {
+		logging.Errorf("Failed to encode world clocks: %v", err)
+		return
+	}
+	if err := c.storage.PutObject(clocksBucket, clocksObject, bytes.NewReader(data), int64(len(data))); err != nil {
+		logging.Errorf("Failed to save world clocks: %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package chronos
+
+import (
+	"testing"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/eventbus/events"
+	"multiverse-core.io/shared/minio/miniotest"
+	"multiverse-core.io/shared/worldtime"
+)
+
+func newTestChronos(now *time.Time) *Chronos {
+	c := NewChronos(nil, worldtime.DefaultCalendar(), 60)
+	c.now = func() time.Time { return *now }
+	return c
+}
+
+func TestWorldClockDilation(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := newTestChronos(&now)
+	c.SetDilation(map[int]float64{1: 2})
+
+	c.AddWorld("world-1")
+	now = now.Add(10 * time.Minute)
+	tick, _ := c.Time("world-1")
+	if tick.WorldTimeMs != (10 * time.Hour).Milliseconds() || tick.Rate != 60 {
+		t.Fatalf("plan 0 tick = %+v", tick)
+	}
+	if tick.Calendar == nil || tick.Calendar.Hour != 10 || tick.Calendar.DayPhase != "утро" {
+		t.Errorf("calendar = %+v", tick.Calendar)
+	}
+
+	// Plan 1 runs twice as fast from the moment the world moves there
+	c.HandleEvent(eventbus.NewEvent("plan.initialized", "plan-manager", "world-1", map[string]any{"world_id": "world-1", "plan_level": float64(1)}))
+	now = now.Add(10 * time.Minute)
+	tick, _ = c.Time("world-1")
+	if tick.WorldTimeMs != (30*time.Hour).Milliseconds() || tick.PlanLevel != 1 || tick.Rate != 120 {
+		t.Errorf("plan 1 tick = %+v", tick)
+	}
+
+	if _, ok := c.Time("unknown"); ok {
+		t.Error("unknown world has a clock")
+	}
+}
+
+func TestTicksPerWorld(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := newTestChronos(&now)
+	c.HandleEvent(eventbus.NewEvent("world.generated", "world-generator", "world-b", nil))
+	c.AddWorld("world-a")
+	c.AddWorld("world-a")
+
+	ticks := c.ticks()
+	if len(ticks) != 2 {
+		t.Fatalf("ticks = %d, want 2", len(ticks))
+	}
+	for i, worldID := range []string{"world-a", "world-b"} {
+		if got := eventbus.GetWorldIDFromEvent(ticks[i]); got != worldID {
+			t.Errorf("tick %d world = %q, want %q", i, got, worldID)
+		}
+		var tick events.TimeSync
+		if err := events.Unmarshal(ticks[i], &tick); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := tick.WorldTime(); !ok || tick.CurrentTimeUnixMs != now.UnixMilli() {
+			t.Errorf("tick %d = %+v", i, tick)
+		}
+	}
+}
+
+func TestClocksSurviveRestart(t *testing.T) {
+	objects := miniotest.New()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := newTestChronos(&now)
+	c.UseStorage(objects)
+	c.AddWorld("world-1")
+
+	// The restarted service runs at a higher scale: world time continues from where it was
+	now = now.Add(time.Hour)
+	restarted := NewChronos(nil, worldtime.DefaultCalendar(), 120)
+	restarted.now = func() time.Time { return now }
+	restarted.UseStorage(objects)
+	if err := restarted.Load(); err != nil {
+		t.Fatal(err)
+	}
+	tick, ok := restarted.Time("world-1")
+	if !ok || tick.WorldTimeMs != (60*time.Hour).Milliseconds() || tick.Rate != 120 {
+		t.Fatalf("restored tick = %+v", tick)
+	}
+	now = now.Add(time.Minute)
+	if tick, _ := restarted.Time("world-1"); tick.WorldTimeMs != (62 * time.Hour).Milliseconds() {
+		t.Errorf("world time after restart = %v", time.Duration(tick.WorldTimeMs)*time.Millisecond)
+	}
+}
+
+func TestParseDilation(t *testing.T) {
+	dilation, err := ParseDilation([]string{"1=2", " 2 = 0.5 "})
+	if err != nil || dilation[1] != 2 || dilation[2] != 0.5 {
+		t.Errorf("dilation = %v, %v", dilation, err)
+	}
+	for _, invalid := range []string{"1", "x=2", "1=0", "1=fast"} {
+		if _, err := ParseDilation([]string{invalid}); err == nil {
+			t.Errorf("%q parsed", invalid)
+		}
+	}
+}
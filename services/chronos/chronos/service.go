@@ -0,0 +1,50 @@
+package chronos
+
+import (
+	"context"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+)
+
+// Service runs Chronos: follows world generation and plans and publishes world time ticks.
+type Service struct {
+	bus      *eventbus.EventBus
+	chronos  *Chronos
+	interval time.Duration
+	// worlds get a clock on start, before any world.generated is seen
+	worlds []string
+}
+
+// NewService creates the Chronos service publishing a tick for every world each interval.
+func NewService(bus *eventbus.EventBus, chronos *Chronos, interval time.Duration, worlds []string) *Service {
+	if interval <= 0 {
+		interval = DefaultTickInterval
+	}
+	return &Service{bus: bus, chronos: chronos, interval: interval, worlds: worlds}
+}
+
+// Run starts the service and blocks until ctx is cancelled.
+func (s *Service) Run(ctx context.Context) error {
+	if err := s.chronos.Load(); err != nil {
+		logging.Errorf("Failed to load world clocks, starting empty: %v", err)
+	}
+	for _, worldID := range s.worlds {
+		s.chronos.AddWorld(worldID)
+	}
+
+	// world.generated and plan.initialized
+	go s.bus.Subscribe(ctx, eventbus.TopicSystemEvents, "chronos-group", s.chronos.HandleEvent)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			s.chronos.Tick(ctx)
+		}
+	}
+}
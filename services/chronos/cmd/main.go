@@ -0,0 +1,46 @@
+// Package main is the entry point for Chronos.
+package main
+
+import (
+	"log"
+
+	"multiverse-core.io/services/chronos/chronos"
+	"multiverse-core.io/shared/config"
+	"multiverse-core.io/shared/logging"
+	"multiverse-core.io/shared/service"
+	"multiverse-core.io/shared/worldtime"
+)
+
+func main() {
+	app := service.Setup("chronos", []config.Option{
+		{Env: "CHRONOS_TICK_INTERVAL", Default: "10s", Type: config.TypeDuration, Positive: true, Usage: "how often time.syncTime is published for every world"},
+		{Env: "CHRONOS_TIME_SCALE", Default: "60", Type: config.TypeFloat, Positive: true, Usage: "world seconds per real second on Plan 0"},
+		{Env: "CHRONOS_PLAN_DILATION", Type: config.TypeList, Usage: "time scale multiplier per plan: plan=factor,... (1=2 — Plan 1 runs twice as fast)"},
+		{Env: "CHRONOS_WORLDS", Default: "pain-realm", Type: config.TypeList, Usage: "worlds whose clocks start without world.generated"},
+		{Env: "CHRONOS_DAY_LENGTH", Default: "24h", Type: config.TypeDuration, Positive: true, Usage: "length of a world day in world time"},
+		{Env: "CHRONOS_DAYS_PER_SEASON", Default: "30", Type: config.TypeInt, Positive: true, Usage: "world days per season"},
+	})
+	env := app.Env
+
+	dilation, err := chronos.ParseDilation(env.List("CHRONOS_PLAN_DILATION"))
+	if err != nil {
+		log.Fatalf("Invalid CHRONOS_PLAN_DILATION: %v", err)
+	}
+
+	calendar := worldtime.DefaultCalendar()
+	calendar.DayLength = env.Duration("CHRONOS_DAY_LENGTH")
+	calendar.DaysPerSeason = env.Int("CHRONOS_DAYS_PER_SEASON")
+
+	clocks := chronos.NewChronos(app.Bus(), calendar, env.Float("CHRONOS_TIME_SCALE"))
+	clocks.SetDilation(dilation)
+
+	// World clocks persistence (optional: without MinIO world time restarts with the service)
+	minioClient, err := app.MinIO()
+	if err != nil {
+		logging.Warnf("MinIO unavailable, world clocks are kept in memory only: %v", err)
+	} else {
+		clocks.UseStorage(minioClient)
+	}
+
+	app.Run(chronos.NewService(app.Bus(), clocks, env.Duration("CHRONOS_TICK_INTERVAL"), env.List("CHRONOS_WORLDS")))
+}
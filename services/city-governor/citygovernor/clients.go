@@ -0,0 +1,88 @@
+package citygovernor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	storage "multiverse-core.io/shared/minio"
+	"multiverse-core.io/shared/registry"
+)
+
+// SemanticMemoryClient reads entity context from SemanticMemory.
+type SemanticMemoryClient struct {
+	// BaseURL is the static fallback used when the registry has no live instance.
+	BaseURL    string
+	httpClient *http.Client
+	discovery  *registry.Discovery
+}
+
+// NewSemanticMemoryClient creates a new SemanticMemoryClient.
+// The address is resolved through discovery; baseURL is kept as fallback.
+func NewSemanticMemoryClient(baseURL string, discovery *registry.Discovery) *SemanticMemoryClient {
+	if baseURL == "" {
+		baseURL = "http://semantic-memory:8080"
+	}
+	if discovery != nil {
+		discovery.SetFallback(registry.ServiceSemanticMemory, baseURL)
+	}
+	return &SemanticMemoryClient{
+		BaseURL:    baseURL,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		discovery:  discovery,
+	}
+}
+
+// EntityContext returns the text context of an entity with its related events up to depth.
+// An unknown entity yields an empty string.
+func (c *SemanticMemoryClient) EntityContext(ctx context.Context, entityID string, depth int) (string, error) {
+	baseURL := c.BaseURL
+	if c.discovery != nil {
+		if resolved, err := c.discovery.Resolve(ctx, registry.ServiceSemanticMemory); err == nil {
+			baseURL = resolved
+		}
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"entity_ids": []string{entityID}, "depth": depth})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/v1/context", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if c.discovery != nil {
+			c.discovery.MarkFailed(registry.ServiceSemanticMemory, baseURL)
+		}
+		return "", fmt.Errorf("semantic memory connection failed: %v: %w", err, storage.ErrUnavailable)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Contexts map[string]string `json:"contexts"`
+	}
+	if err := decodeResponse(resp, "/v1/context", &result); err != nil {
+		return "", err
+	}
+	return result.Contexts[entityID], nil
+}
+
+// decodeResponse decodes a successful JSON response into out. A 404 is reported as
+// storage.ErrNotFound, other failures as storage.ErrUnavailable.
+func decodeResponse(resp *http.Response, path string, out interface{}) error {
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("%s: %w", path, storage.ErrNotFound)
+	case resp.StatusCode != http.StatusOK:
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s returned status %d: %s: %w", path, resp.StatusCode, string(body), storage.ErrUnavailable)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
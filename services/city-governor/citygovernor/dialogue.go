@@ -0,0 +1,315 @@
+package citygovernor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/entity"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+)
+
+// npcMemoriesBucket holds the short-term memory of NPCs: {world_id}/{npc_id}.json
+const npcMemoriesBucket = "npc-memories"
+
+const (
+	// dialogueTimeout bounds the context lookups and the Oracle call of one NPC response.
+	dialogueTimeout = 15 * time.Second
+	// maxNPCExchanges is how many recent exchanges an NPC remembers.
+	maxNPCExchanges = 10
+	// npcMemoryTTL is how long an NPC remembers an exchange.
+	npcMemoryTTL = 24 * time.Hour
+	// maxPromptExchanges is how many remembered exchanges go into the prompt.
+	maxPromptExchanges = 6
+
+	maxNPCProfileChars = 1500
+	maxResponseChars   = 600
+	maxMessageChars    = 500
+)
+
+// fallbackNPCResponse is used without an Oracle or when its answer is unusable.
+const fallbackNPCResponse = "Старейшина кивает вам и говорит: 'Добро пожаловать в наш город.'"
+
+// DialogueExchange is one remembered player line and the NPC answer.
+type DialogueExchange struct {
+	PlayerID    string    `json:"player_id"`
+	Interaction string    `json:"interaction_type,omitempty"`
+	Message     string    `json:"message,omitempty"`
+	Response    string    `json:"response"`
+	At          time.Time `json:"at"`
+}
+
+// NPCMemory is the short-term memory of one NPC, oldest exchange first.
+type NPCMemory struct {
+	WorldID   string             `json:"world_id"`
+	NPCID     string             `json:"npc_id"`
+	Exchanges []DialogueExchange `json:"exchanges"`
+}
+
+// DialogueRequest describes the interaction to answer.
+type DialogueRequest struct {
+	WorldID     string
+	CityID      string
+	CityName    string
+	NPCID       string
+	PlayerID    string
+	Interaction string
+	Message     string
+	City        CityState
+}
+
+// DialogueGenerator writes NPC responses with the Oracle from the NPC entity, city state,
+// player history and the NPC's memory of recent conversations. Without an Oracle, or when
+// its answer is unusable, the fallback line is used.
+type DialogueGenerator struct {
+	oracle  questOracle
+	memory  *SemanticMemoryClient
+	storage storage.ObjectStorage // NPC entities (EntityManager buckets) and NPC memories
+	now     func() time.Time
+
+	mu       sync.Mutex
+	memories map[string]*NPCMemory // {world_id}/{npc_id} → memory
+}
+
+// NewDialogueGenerator creates a generator that answers with the fallback line until an Oracle is set.
+func NewDialogueGenerator() *DialogueGenerator {
+	return &DialogueGenerator{
+		now:      time.Now,
+		memories: make(map[string]*NPCMemory),
+	}
+}
+
+// UseOracle enables NPC responses written by the Oracle.
+func (g *DialogueGenerator) UseOracle(oracle questOracle) {
+	g.oracle = oracle
+}
+
+// UseSemanticMemory enables player history in dialogue prompts.
+func (g *DialogueGenerator) UseSemanticMemory(memory *SemanticMemoryClient) {
+	g.memory = memory
+}
+
+// UseStorage enables loading NPC entities stored by EntityManager and persisting NPC memories.
+func (g *DialogueGenerator) UseStorage(client storage.ObjectStorage) {
+	g.storage = client
+}
+
+// Respond returns the NPC response to the interaction and remembers the exchange.
+// generated is false when the fallback line was used.
+func (g *DialogueGenerator) Respond(ctx context.Context, req DialogueRequest) (response string, generated bool) {
+	response = fallbackNPCResponse
+	if g.oracle != nil {
+		ctx, cancel := context.WithTimeout(ctx, dialogueTimeout)
+		defer cancel()
+		text, err := g.generateWithOracle(ctx, req)
+		if err == nil {
+			response, generated = text, true
+		} else {
+			logging.Errorf("Oracle response of NPC %s failed, using fallback: %v", req.NPCID, err)
+		}
+	}
+	g.remember(req, response)
+	return response, generated
+}
+
+func (g *DialogueGenerator) generateWithOracle(ctx context.Context, req DialogueRequest) (string, error) {
+	systemPrompt, userPrompt := buildDialoguePrompts(req, g.npcEntity(req.WorldID, req.NPCID),
+		playerHistory(ctx, g.memory, req.PlayerID), g.recall(req.WorldID, req.NPCID))
+
+	response, err := g.oracle.CallStructuredJSON(ctx, systemPrompt, userPrompt)
+	if err != nil {
+		return "", err
+	}
+	return parseDialogue(response)
+}
+
+// parseDialogue extracts the NPC line from the Oracle answer {"response": "..."}.
+func parseDialogue(response string) (string, error) {
+	var answer struct {
+		Response string `json:"response"`
+	}
+	if err := json.Unmarshal([]byte(response), &answer); err != nil {
+		return "", fmt.Errorf("invalid dialogue JSON: %w", err)
+	}
+	text := strings.TrimSpace(answer.Response)
+	if text == "" {
+		return "", errors.New("empty NPC response")
+	}
+	return truncateRunes(text, maxResponseChars), nil
+}
+
+// npcEntity returns the NPC stored by EntityManager: the world bucket first, then entities-global; nil if unknown.
+func (g *DialogueGenerator) npcEntity(worldID, npcID string) *entity.Entity {
+	if g.storage == nil {
+		return nil
+	}
+	for _, bucket := range []string{"entities-" + worldID, "entities-global"} {
+		data, err := g.storage.GetObject(bucket, npcID+".json")
+		if err != nil {
+			if !storage.IsNotFound(err) {
+				logging.Warnf("NPC %s entity unavailable: %v", npcID, err)
+				return nil
+			}
+			continue
+		}
+		var npc entity.Entity
+		if err := json.Unmarshal(data, &npc); err != nil {
+			logging.Infof("Corrupted NPC entity %s: %v", npcID, err)
+			return nil
+		}
+		return &npc
+	}
+	return nil
+}
+
+func npcMemoryKey(worldID, npcID string) string {
+	return worldID + "/" + npcID + ".json"
+}
+
+// loadLocked returns the NPC memory, reading it from storage on first use; the caller holds the lock.
+func (g *DialogueGenerator) loadLocked(worldID, npcID string) *NPCMemory {
+	key := npcMemoryKey(worldID, npcID)
+	if memory, ok := g.memories[key]; ok {
+		return memory
+	}
+	memory := &NPCMemory{WorldID: worldID, NPCID: npcID}
+	if g.storage != nil {
+		data, err := g.storage.GetObject(npcMemoriesBucket, key)
+		switch {
+		case err == nil:
+			if err := json.Unmarshal(data, memory); err != nil {
+				logging.Infof("Corrupted memory of NPC %s, starting over: %v", npcID, err)
+				memory = &NPCMemory{WorldID: worldID, NPCID: npcID}
+			}
+		case !storage.IsNotFound(err):
+			logging.Warnf("Memory of NPC %s unavailable: %v", npcID, err)
+		}
+	}
+	g.memories[key] = memory
+	return memory
+}
+
+// forgetLocked drops exchanges older than npcMemoryTTL and beyond maxNPCExchanges; the caller holds the lock.
+func (g *DialogueGenerator) forgetLocked(memory *NPCMemory) {
+	cutoff := g.now().Add(-npcMemoryTTL)
+	kept := memory.Exchanges[:0]
+	for _, exchange := range memory.Exchanges {
+		if exchange.At.After(cutoff) {
+			kept = append(kept, exchange)
+		}
+	}
+	if len(kept) > maxNPCExchanges {
+		kept = kept[len(kept)-maxNPCExchanges:]
+	}
+	memory.Exchanges = kept
+}
+
+// recall returns the exchanges the NPC still remembers, oldest first.
+func (g *DialogueGenerator) recall(worldID, npcID string) []DialogueExchange {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	memory := g.loadLocked(worldID, npcID)
+	g.forgetLocked(memory)
+	return append([]DialogueExchange(nil), memory.Exchanges...)
+}
+
+// remember adds the exchange to the NPC memory and persists it.
+func (g *DialogueGenerator) remember(req DialogueRequest, response string) {
+	g.mu.Lock()
+	memory := g.loadLocked(req.WorldID, req.NPCID)
+	memory.Exchanges = append(memory.Exchanges, DialogueExchange{
+		PlayerID:    req.PlayerID,
+		Interaction: req.Interaction,
+		Message:     truncateRunes(req.Message, maxMessageChars),
+		Response:    response,
+		At:          g.now(),
+	})
+	g.forgetLocked(memory)
+	data, err := json.Marshal(memory)
+	g.mu.Unlock()
+
+	if err != nil || g.storage == nil {
+		return
+	}
+	if err := g.storage.PutObject(npcMemoriesBucket, npcMemoryKey(req.WorldID, req.NPCID), bytes.NewReader(data), int64(len(data))); err != nil {
+		logging.Warnf("Failed to persist memory of NPC %s: %v", req.NPCID, err)
+	}
+}
+
+// buildDialoguePrompts builds the system and user prompts for one NPC response.
+func buildDialoguePrompts(req DialogueRequest, npc *entity.Entity, history string, exchanges []DialogueExchange) (systemPrompt, userPrompt string) {
+	var sb strings.Builder
+	name := req.NPCID
+	if npc != nil {
+		if npcName, ok := npc.Payload["name"].(string); ok && npcName != "" {
+			name = npcName
+		}
+	}
+	fmt.Fprintf(&sb, "Ты — %s, житель города «%s». Отвечай игроку от первого лица, в характере персонажа, 1-3 предложения.\n", name, req.CityName)
+	if npc != nil && len(npc.Payload) > 0 {
+		if profile, err := json.Marshal(npc.Payload); err == nil {
+			fmt.Fprintf(&sb, "\nОписание персонажа:\n%s\n", truncateRunes(string(profile), maxNPCProfileChars))
+		}
+	}
+	sb.WriteString("\nПомни прошлые разговоры и не противоречь им. Не выходи из роли.\n\n")
+	sb.WriteString(`Отвечай строго в формате JSON без пояснений: {"response": "реплика персонажа"}`)
+	systemPrompt = sb.String()
+
+	if len(exchanges) > maxPromptExchanges {
+		exchanges = exchanges[len(exchanges)-maxPromptExchanges:]
+	}
+	var recent strings.Builder
+	for _, exchange := range exchanges {
+		speaker := "Другой игрок"
+		if exchange.PlayerID == req.PlayerID {
+			speaker = "Этот игрок"
+		}
+		line := exchange.Message
+		if line == "" {
+			line = "(" + exchange.Interaction + ")"
+		}
+		fmt.Fprintf(&recent, "- %s: %s\n  Ты: %s\n", speaker, line, exchange.Response)
+	}
+	conversation := recent.String()
+	if conversation == "" {
+		conversation = "нет\n"
+	}
+	if history == "" {
+		history = "нет данных"
+	}
+	message := req.Message
+	if message == "" {
+		message = "нет"
+	}
+
+	userPrompt = fmt.Sprintf(`Игрок %s обращается к тебе: %s.
+Реплика игрока: %s
+
+Состояние города:
+- Репутация игроков у города: %d из 100
+- Население: %d
+- Активных квестов: %d, выполнено квестов: %d
+
+Недавние разговоры:
+%s
+История игрока:
+%s`, req.PlayerID, req.Interaction, truncateRunes(message, maxMessageChars),
+		req.City.Reputation, req.City.Population, len(req.City.ActiveQuests), req.City.CompletedQuests,
+		conversation, history)
+
+	return systemPrompt, userPrompt
+}
+
+// truncateRunes cuts text to at most limit characters.
+func truncateRunes(text string, limit int) string {
+	if runes := []rune(text); len(runes) > limit {
+		return string(runes[:limit])
+	}
+	return text
+}
@@ -0,0 +1,77 @@
+package citygovernor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"multiverse-core.io/shared/minio/miniotest"
+)
+
+func dialogueRequest(message string) DialogueRequest {
+	state := newCityState("world-1", "city-1")
+	state.Reputation = 65
+	return DialogueRequest{
+		WorldID: "world-1", CityID: "city-1", CityName: "Вельград", NPCID: "npc-smith",
+		PlayerID: "player-1", Interaction: "talk", Message: message, City: *state,
+	}
+}
+
+func TestDialogueGeneratorOracle(t *testing.T) {
+	client := miniotest.New()
+	npc := `{"id": "npc-smith", "type": "npc", "payload": {"name": "Кузнец Борислав", "profession": "кузнец"}}`
+	if err := client.PutObject("entities-world-1", "npc-smith.json", strings.NewReader(npc), int64(len(npc))); err != nil {
+		t.Fatal(err)
+	}
+
+	oracle := &fakeOracle{response: `{"response": "  Меч будет готов к рассвету. "}`}
+	generator := NewDialogueGenerator()
+	generator.UseOracle(oracle)
+	generator.UseStorage(client)
+
+	response, generated := generator.Respond(context.Background(), dialogueRequest("Сколько стоит меч?"))
+	if !generated || response != "Меч будет готов к рассвету." {
+		t.Fatalf("unexpected response %q (generated %v)", response, generated)
+	}
+	if !strings.Contains(oracle.systemPrompt, "Кузнец Борислав") || !strings.Contains(oracle.userPrompt, "65 из 100") {
+		t.Errorf("prompts must describe the NPC and the city:\n%s\n%s", oracle.systemPrompt, oracle.userPrompt)
+	}
+
+	// A restarted generator recalls the conversation from storage
+	restarted := NewDialogueGenerator()
+	restarted.UseOracle(oracle)
+	restarted.UseStorage(client)
+	restarted.Respond(context.Background(), dialogueRequest("А кинжал?"))
+	if !strings.Contains(oracle.userPrompt, "Сколько стоит меч?") || !strings.Contains(oracle.userPrompt, "Меч будет готов к рассвету.") {
+		t.Errorf("prompt must include the remembered conversation:\n%s", oracle.userPrompt)
+	}
+	if memory := restarted.recall("world-1", "npc-smith"); len(memory) != 2 {
+		t.Errorf("expected 2 remembered exchanges, got %+v", memory)
+	}
+}
+
+func TestDialogueGeneratorFallback(t *testing.T) {
+	cases := map[string]*fakeOracle{
+		"unavailable":    {err: errors.New("connection refused")},
+		"invalid json":   {response: "привет"},
+		"empty response": {response: `{"response": " "}`},
+	}
+	for name, oracle := range cases {
+		generator := NewDialogueGenerator()
+		generator.UseOracle(oracle)
+		if response, generated := generator.Respond(context.Background(), dialogueRequest("")); generated || response != fallbackNPCResponse {
+			t.Errorf("%s: expected fallback line, got %q", name, response)
+		}
+	}
+}
+
+func TestNPCMemoryLimit(t *testing.T) {
+	generator := NewDialogueGenerator()
+	for i := 0; i < maxNPCExchanges+3; i++ {
+		generator.Respond(context.Background(), dialogueRequest(""))
+	}
+	if memory := generator.recall("world-1", "npc-smith"); len(memory) != maxNPCExchanges {
+		t.Errorf("expected %d exchanges kept, got %d", maxNPCExchanges, len(memory))
+	}
+}
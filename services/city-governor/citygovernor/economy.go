@@ -0,0 +1,454 @@
+package citygovernor
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/eventbus/events"
+	"multiverse-core.io/shared/logging"
+
+	"github.com/google/uuid"
+)
+
+// EventMarketUpdated is published when city prices change noticeably.
+const EventMarketUpdated = "city.market.updated"
+
+// DefaultEconomyTimeScale is how many world seconds pass per real second (1 real minute — 1 world hour).
+const DefaultEconomyTimeScale = 60
+
+const (
+	// targetStockDays is the supply a city keeps in stock; prices are at base level at this stock.
+	targetStockDays = 3
+	// maxTickDays bounds one economy tick, e.g. after the service was down.
+	maxTickDays = 1.0
+	// specialtyFactor multiplies production of the resource a city specializes in.
+	specialtyFactor = 2.0
+	// priceElasticity controls how strongly prices follow the stock shortage.
+	priceElasticity = 0.5
+	// minPriceChange is the relative price change that triggers city.market.updated.
+	minPriceChange = 0.01
+	// maxTradeRoutes is the number of nearest cities of the same world a city trades with.
+	maxTradeRoutes = 2
+	// tradeShare is the part of the stock imbalance evened out along a route per world day.
+	tradeShare = 0.5
+)
+
+// resourceSpec describes a traded resource.
+type resourceSpec struct {
+	BasePrice   float64 // gold per unit at target stock
+	Consumption float64 // units per 100 inhabitants per world day
+}
+
+var cityResources = map[string]resourceSpec{
+	"food":  {BasePrice: 2, Consumption: 10},
+	"wood":  {BasePrice: 3, Consumption: 4},
+	"cloth": {BasePrice: 5, Consumption: 3},
+	"ore":   {BasePrice: 8, Consumption: 2},
+}
+
+// resourceNames lists resources in a stable order.
+var resourceNames = func() []string {
+	names := make([]string, 0, len(cityResources))
+	for name := range cityResources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}()
+
+// CityEconomy is the market of a city.
+type CityEconomy struct {
+	Stocks      map[string]float64 `json:"stocks"`
+	Production  map[string]float64 `json:"production"` // units per world day
+	Prices      map[string]float64 `json:"prices"`     // last published prices
+	TradeRoutes []string           `json:"trade_routes,omitempty"`
+}
+
+// PriceChange describes a published price change of a resource.
+type PriceChange struct {
+	Old    float64 `json:"old"`
+	New    float64 `json:"new"`
+	Change float64 `json:"change"` // relative change, 0.1 — +10%
+}
+
+// newCityEconomy creates a market with a day-scale production matching consumption.
+// Each city specializes in one resource (chosen by city ID) and produces a surplus of it.
+func newCityEconomy(cityID string, population int) *CityEconomy {
+	h := fnv.New32a()
+	h.Write([]byte(cityID))
+	specialty := resourceNames[int(h.Sum32())%len(resourceNames)]
+
+	economy := &CityEconomy{
+		Stocks:     make(map[string]float64, len(resourceNames)),
+		Production: make(map[string]float64, len(resourceNames)),
+		Prices:     make(map[string]float64, len(resourceNames)),
+	}
+	for _, name := range resourceNames {
+		consumption := dailyConsumption(name, population)
+		production := consumption * 0.8
+		if name == specialty {
+			production = consumption * specialtyFactor
+		}
+		economy.Production[name] = production
+		economy.Stocks[name] = consumption * targetStockDays
+		economy.Prices[name] = cityResources[name].BasePrice
+	}
+	return economy
+}
+
+func (e *CityEconomy) clone() *CityEconomy {
+	c := &CityEconomy{
+		Stocks:      make(map[string]float64, len(e.Stocks)),
+		Production:  make(map[string]float64, len(e.Production)),
+		Prices:      make(map[string]float64, len(e.Prices)),
+		TradeRoutes: append([]string(nil), e.TradeRoutes...),
+	}
+	for name, v := range e.Stocks {
+		c.Stocks[name] = v
+	}
+	for name, v := range e.Production {
+		c.Production[name] = v
+	}
+	for name, v := range e.Prices {
+		c.Prices[name] = v
+	}
+	return c
+}
+
+// dailyConsumption is how much of a resource a city consumes per world day.
+// Small settlements consume as much as a village of 100.
+func dailyConsumption(resource string, population int) float64 {
+	return cityResources[resource].Consumption * float64(max(population, 100)) / 100
+}
+
+func targetStock(resource string, population int) float64 {
+	return dailyConsumption(resource, population) * targetStockDays
+}
+
+// produce applies production and consumption over days.
+func (e *CityEconomy) produce(days float64, population int) {
+	for _, name := range resourceNames {
+		stock := e.Stocks[name] + (e.Production[name]-dailyConsumption(name, population))*days
+		e.Stocks[name] = max(stock, 0)
+	}
+}
+
+// scale multiplies all stocks by factor (quests bring supplies, violations cause losses).
+func (e *CityEconomy) scale(factor float64) {
+	for name, stock := range e.Stocks {
+		e.Stocks[name] = max(stock*factor, 0)
+	}
+}
+
+// supplyScarcest adds share of the target stock to the resource the city lacks most.
+func (e *CityEconomy) supplyScarcest(share float64, population int) {
+	scarcest, lowest := "", math.Inf(1)
+	for _, name := range resourceNames {
+		if fill := e.Stocks[name] / targetStock(name, population); fill < lowest {
+			scarcest, lowest = name, fill
+		}
+	}
+	e.Stocks[scarcest] += targetStock(scarcest, population) * share
+}
+
+// marketPrice is the price of a resource at the current stock: base price at target stock,
+// rising as the stock runs out and falling on surplus, within [base/4, base*4].
+func (e *CityEconomy) marketPrice(resource string, population int) float64 {
+	base := cityResources[resource].BasePrice
+	target := targetStock(resource, population)
+	ratio := target / max(e.Stocks[resource], target*0.01)
+	price := base * math.Pow(ratio, priceElasticity)
+	return math.Round(min(max(price, base/4), base*4)*100) / 100
+}
+
+// reprice recalculates prices and returns the changes of at least minPriceChange.
+// Published prices are updated only when there is something to publish.
+func (e *CityEconomy) reprice(population int) map[string]PriceChange {
+	changes := make(map[string]PriceChange)
+	prices := make(map[string]float64, len(resourceNames))
+	for _, name := range resourceNames {
+		price := e.marketPrice(name, population)
+		prices[name] = price
+		old := e.Prices[name]
+		if old <= 0 || math.Abs(price-old)/old >= minPriceChange {
+			changes[name] = PriceChange{Old: old, New: price, Change: relativeChange(old, price)}
+		}
+	}
+	if len(changes) > 0 {
+		e.Prices = prices
+	}
+	return changes
+}
+
+func relativeChange(old, price float64) float64 {
+	if old <= 0 {
+		return 0
+	}
+	return math.Round((price-old)/old*1000) / 1000
+}
+
+// trade evens out stocks between two cities over days: goods flow to the city with the lower fill.
+func trade(a, b *CityState, days float64) {
+	share := min(tradeShare*days, 1)
+	for _, name := range resourceNames {
+		targetA, targetB := targetStock(name, a.Population), targetStock(name, b.Population)
+		fillA, fillB := a.Economy.Stocks[name]/targetA, b.Economy.Stocks[name]/targetB
+		// Moving amount x equalizes fills at x = (fillA-fillB) / (1/targetA + 1/targetB)
+		amount := (fillA - fillB) / (1/targetA + 1/targetB) * share
+		a.Economy.Stocks[name] -= amount
+		b.Economy.Stocks[name] += amount
+	}
+}
+
+// assignTradeRoutes connects each located city with its nearest cities of the same world.
+func assignTradeRoutes(cities []*CityState) {
+	for _, city := range cities {
+		city.Economy.TradeRoutes = nil
+		if city.Location == nil {
+			continue
+		}
+		var neighbours []*CityState
+		for _, other := range cities {
+			if other != city && other.Location != nil && other.WorldID == city.WorldID {
+				neighbours = append(neighbours, other)
+			}
+		}
+		sort.SliceStable(neighbours, func(i, j int) bool {
+			return cityDistance(city, neighbours[i]) < cityDistance(city, neighbours[j])
+		})
+		for _, neighbour := range neighbours[:min(len(neighbours), maxTradeRoutes)] {
+			city.Economy.TradeRoutes = append(city.Economy.TradeRoutes, neighbour.CityID)
+		}
+	}
+}
+
+func cityDistance(a, b *CityState) float64 {
+	return math.Hypot(a.Location.X-b.Location.X, a.Location.Y-b.Location.Y)
+}
+
+// eventTime returns the time of a time.syncTime event: current_time_unix_ms or the event timestamp.
+func eventTime(ev eventbus.Event) time.Time {
+	var tick events.TimeSync
+	if err := events.Unmarshal(ev, &tick); err == nil && tick.CurrentTimeUnixMs != 0 {
+		return tick.Time()
+	}
+	return ev.Timestamp
+}
+
+// economyClock converts the time between time.syncTime events into world days: the world time
+// of Chronos ticks per world, or real time × timeScale for ticks without world time.
+type economyClock struct {
+	mu        sync.Mutex
+	timeScale float64
+	lastTick  time.Time
+	// worlds holds the last Chronos tick of every world
+	worlds map[string]worldTick
+}
+
+// worldTick is the world time and rate of the last Chronos tick of a world.
+type worldTick struct {
+	worldTime time.Duration
+	rate      float64
+}
+
+// advance returns world days elapsed since the previous tick; the first tick only starts the clock.
+func (c *economyClock) advance(now time.Time) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	last := c.lastTick
+	if !now.After(last) {
+		return 0
+	}
+	c.lastTick = now
+	if last.IsZero() {
+		return 0
+	}
+	return min(now.Sub(last).Hours()*c.timeScale/24, maxTickDays)
+}
+
+// advanceWorld returns world days elapsed in the world since its previous tick;
+// the first tick of a world only starts its clock.
+func (c *economyClock) advanceWorld(worldID string, worldTime time.Duration, rate float64) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.worlds == nil {
+		c.worlds = make(map[string]worldTick)
+	}
+	last, ok := c.worlds[worldID]
+	if ok && worldTime <= last.worldTime {
+		return 0
+	}
+	c.worlds[worldID] = worldTick{worldTime: worldTime, rate: rate}
+	if !ok {
+		return 0
+	}
+	return min((worldTime-last.worldTime).Hours()/24, maxTickDays)
+}
+
+// hasWorld reports whether the world is ticked by Chronos.
+func (c *economyClock) hasWorld(worldID string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.worlds[worldID]
+	return ok
+}
+
+// rate returns world seconds per real second in the world: the rate of its last Chronos tick or timeScale.
+func (c *economyClock) rate(worldID string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if tick, ok := c.worlds[worldID]; ok && tick.rate > 0 {
+		return tick.rate
+	}
+	return c.timeScale
+}
+
+// SetEconomyTimeScale sets how many world seconds pass per real second for the economy and
+// quest deadlines in worlds without Chronos ticks (<= 0 — DefaultEconomyTimeScale).
+func (cg *CityGovernor) SetEconomyTimeScale(scale float64) {
+	if scale <= 0 {
+		scale = DefaultEconomyTimeScale
+	}
+	cg.clock.mu.Lock()
+	cg.clock.timeScale = scale
+	cg.clock.mu.Unlock()
+}
+
+// populationChange is the population change of a city on one tick.
+type populationChange struct {
+	delta     int
+	milestone string
+}
+
+// handleTimeSync advances the economy of cities: production, trade along routes and prices,
+// and their population: births, deaths and migration.
+// A Chronos tick advances the cities of its world by the world time since the previous tick;
+// a tick without world time advances the cities of all worlds Chronos does not tick.
+func (cg *CityGovernor) handleTimeSync(ev eventbus.Event) {
+	// A tick with an invalid payload is handled as a tick without world time
+	var tick events.TimeSync
+	events.Unmarshal(ev, &tick)
+	worldID := eventbus.GetWorldIDFromEvent(ev)
+	worldTime, hasWorldTime := tick.WorldTime()
+
+	var days float64
+	if hasWorldTime && worldID != "" {
+		days = cg.clock.advanceWorld(worldID, worldTime, tick.Rate)
+	} else {
+		worldID = ""
+		days = cg.clock.advance(eventTime(ev))
+	}
+	if days <= 0 {
+		return
+	}
+
+	changes := make(map[string]map[string]PriceChange)
+	populations := make(map[string]populationChange)
+	states := cg.state.UpdateAll(func(all []*CityState) {
+		var cities []*CityState
+		for _, city := range all {
+			if (worldID != "" && city.WorldID == worldID) || (worldID == "" && !cg.clock.hasWorld(city.WorldID)) {
+				cities = append(cities, city)
+			}
+		}
+		byID := make(map[string]*CityState, len(cities))
+		for _, city := range cities {
+			if city.Economy == nil {
+				city.Economy = newCityEconomy(city.CityID, city.Population)
+			}
+			city.Economy.produce(days, city.Population)
+			if delta, milestone := city.simulatePopulation(days); delta != 0 || milestone != "" {
+				populations[cityKey(city.WorldID, city.CityID)] = populationChange{delta: delta, milestone: milestone}
+			}
+			byID[cityKey(city.WorldID, city.CityID)] = city
+		}
+
+		assignTradeRoutes(cities)
+		traded := make(map[[2]string]bool)
+		for _, city := range cities {
+			for _, neighbourID := range city.Economy.TradeRoutes {
+				// Routes may be listed by one or both cities; each pair trades once
+				pair := [2]string{cityKey(city.WorldID, min(city.CityID, neighbourID)), max(city.CityID, neighbourID)}
+				if neighbour := byID[cityKey(city.WorldID, neighbourID)]; neighbour != nil && !traded[pair] {
+					traded[pair] = true
+					trade(city, neighbour, days)
+				}
+			}
+		}
+
+		for _, city := range cities {
+			if priceChanges := city.Economy.reprice(city.Population); len(priceChanges) > 0 {
+				changes[cityKey(city.WorldID, city.CityID)] = priceChanges
+			}
+		}
+	})
+
+	for _, state := range states {
+		if priceChanges, ok := changes[cityKey(state.WorldID, state.CityID)]; ok {
+			cg.publishMarket(ev, state, priceChanges)
+		}
+		if change, ok := populations[cityKey(state.WorldID, state.CityID)]; ok {
+			cg.publishPopulation(ev, state, change.delta, change.milestone)
+		}
+	}
+}
+
+// adjustEconomy applies a market shock to a city and publishes the resulting price changes.
+func (cg *CityGovernor) adjustEconomy(cause eventbus.Event, worldID, cityID string, shock func(economy *CityEconomy, population int)) {
+	var changes map[string]PriceChange
+	state := cg.state.Update(worldID, cityID, func(state *CityState) {
+		if state.Economy == nil {
+			state.Economy = newCityEconomy(cityID, state.Population)
+		}
+		shock(state.Economy, state.Population)
+		changes = state.Economy.reprice(state.Population)
+	})
+	if len(changes) > 0 {
+		cg.publishMarket(cause, state, changes)
+	}
+}
+
+// economyQuestCompleted brings supplies of the scarcest resource: completed quests help the city.
+func (cg *CityGovernor) economyQuestCompleted(cause eventbus.Event, worldID, cityID string) {
+	cg.adjustEconomy(cause, worldID, cityID, func(economy *CityEconomy, population int) {
+		economy.supplyScarcest(0.1, population)
+	})
+}
+
+// economyViolation destroys part of the city stocks: theft and disorder.
+func (cg *CityGovernor) economyViolation(cause eventbus.Event, worldID, cityID string) {
+	cg.adjustEconomy(cause, worldID, cityID, func(economy *CityEconomy, population int) {
+		economy.scale(0.95)
+	})
+}
+
+// publishMarket publishes city.market.updated with current prices, stocks and price changes
+// caused by a world time tick or a market shock.
+func (cg *CityGovernor) publishMarket(cause eventbus.Event, state CityState, changes map[string]PriceChange) {
+	stocks := make(map[string]float64, len(state.Economy.Stocks))
+	for name, stock := range state.Economy.Stocks {
+		stocks[name] = math.Round(stock)
+	}
+
+	marketPayload := eventbus.NewEventPayload().
+		WithScope(state.CityID, "city").
+		WithWorld(state.WorldID)
+
+	eventbus.SetNested(marketPayload.GetCustom(), "city.id", state.CityID)
+	eventbus.SetNested(marketPayload.GetCustom(), "market.prices", state.Economy.Prices)
+	eventbus.SetNested(marketPayload.GetCustom(), "market.stocks", stocks)
+	eventbus.SetNested(marketPayload.GetCustom(), "market.changes", changes)
+	eventbus.SetNested(marketPayload.GetCustom(), "market.trade_routes", state.Economy.TradeRoutes)
+
+	marketEvent := eventbus.NewStructuredEvent(EventMarketUpdated, "city-governor", state.WorldID, marketPayload).CausedBy(cause)
+	marketEvent.ID = "market-update-" + uuid.New().String()[:8]
+	marketEvent.Timestamp = time.Now()
+	cg.bus.Publish(context.Background(), eventbus.TopicGameEvents, marketEvent)
+
+	logging.Infof("City %s market updated: %d price changes", state.CityID, len(changes))
+}
@@ -0,0 +1,175 @@
+package citygovernor
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func economyCity(cityID string, population int, x, y float64) *CityState {
+	state := newCityState("world-1", cityID)
+	state.Population = population
+	state.Location = &CityLocation{X: x, Y: y}
+	state.Economy = newCityEconomy(cityID, population)
+	return state
+}
+
+func TestCityEconomyPrices(t *testing.T) {
+	economy := newCityEconomy("city-1", 1000)
+	if changes := economy.reprice(1000); len(changes) != 0 {
+		t.Errorf("new city must trade at base prices, got changes %v", changes)
+	}
+
+	// Shortage raises the price, surplus lowers it, both within [base/4, base*4]
+	economy.Stocks["food"] = targetStock("food", 1000) / 4
+	economy.Stocks["ore"] = targetStock("ore", 1000) * 100
+	changes := economy.reprice(1000)
+	if food := changes["food"]; food.New != 4 || food.Change != 1 {
+		t.Errorf("food shortage: %+v", food)
+	}
+	if ore := changes["ore"]; ore.New != 2 {
+		t.Errorf("ore surplus: %+v", ore)
+	}
+	if _, ok := changes["wood"]; ok {
+		t.Errorf("unchanged resources must not be reported")
+	}
+
+	// Changes below minPriceChange are not published
+	economy.Stocks["food"] *= 1.01
+	if changes := economy.reprice(1000); len(changes) != 0 {
+		t.Errorf("expected no noticeable changes, got %v", changes)
+	}
+}
+
+func TestCityEconomyProduction(t *testing.T) {
+	economy := newCityEconomy("city-1", 1000)
+	initial := economy.clone()
+	economy.produce(1, 1000)
+	surplus := 0
+	for _, name := range resourceNames {
+		if economy.Stocks[name] > initial.Stocks[name] {
+			surplus++
+		}
+	}
+	if surplus != 1 {
+		t.Errorf("a city must produce a surplus of exactly one resource, got %d", surplus)
+	}
+
+	economy.produce(1000, 1000)
+	for name, stock := range economy.Stocks {
+		if stock < 0 {
+			t.Errorf("%s stock went negative: %v", name, stock)
+		}
+	}
+
+	// Supplies go to one exhausted resource
+	before := economy.clone()
+	economy.supplyScarcest(0.1, 1000)
+	supplied := 0
+	for name, stock := range economy.Stocks {
+		if stock != before.Stocks[name] {
+			supplied++
+			if before.Stocks[name] != 0 || math.Abs(stock-targetStock(name, 1000)*0.1) > 1e-9 {
+				t.Errorf("unexpected supply of %s: %v → %v", name, before.Stocks[name], stock)
+			}
+		}
+	}
+	if supplied != 1 {
+		t.Errorf("expected one supplied resource, got %d", supplied)
+	}
+}
+
+func TestCityTrade(t *testing.T) {
+	a := economyCity("city-a", 1000, 0, 0)
+	b := economyCity("city-b", 500, 10, 0)
+	a.Economy.Stocks["food"] = targetStock("food", 1000) * 2
+	b.Economy.Stocks["food"] = 0
+	total := a.Economy.Stocks["food"] + b.Economy.Stocks["food"]
+
+	trade(a, b, 2) // a full share: fills are equalized
+	fillA := a.Economy.Stocks["food"] / targetStock("food", 1000)
+	fillB := b.Economy.Stocks["food"] / targetStock("food", 500)
+	if math.Abs(fillA-fillB) > 1e-9 || math.Abs(a.Economy.Stocks["food"]+b.Economy.Stocks["food"]-total) > 1e-9 {
+		t.Errorf("trade must equalize fills and keep goods: %v vs %v", fillA, fillB)
+	}
+}
+
+func TestAssignTradeRoutes(t *testing.T) {
+	cities := []*CityState{
+		economyCity("city-a", 100, 0, 0),
+		economyCity("city-b", 100, 10, 0),
+		economyCity("city-c", 100, 20, 0),
+		economyCity("city-d", 100, 100, 0),
+		economyCity("city-e", 100, 1, 1),
+	}
+	cities[4].WorldID = "world-2" // other world
+	cities = append(cities, newCityState("world-1", "city-unknown"))
+	cities[5].Economy = newCityEconomy("city-unknown", 0)
+
+	assignTradeRoutes(cities)
+	if routes := cities[0].Economy.TradeRoutes; !reflect.DeepEqual(routes, []string{"city-b", "city-c"}) {
+		t.Errorf("city-a routes = %v", routes)
+	}
+	if routes := cities[3].Economy.TradeRoutes; !reflect.DeepEqual(routes, []string{"city-c", "city-b"}) {
+		t.Errorf("city-d routes = %v", routes)
+	}
+	if len(cities[4].Economy.TradeRoutes) != 0 || len(cities[5].Economy.TradeRoutes) != 0 {
+		t.Errorf("cities without neighbours or location must not trade")
+	}
+}
+
+func TestEconomyClock(t *testing.T) {
+	clock := &economyClock{timeScale: 60}
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	if days := clock.advance(start); days != 0 {
+		t.Errorf("first tick only starts the clock, got %v", days)
+	}
+	if days := clock.advance(start.Add(12 * time.Minute)); math.Abs(days-0.5) > 1e-9 {
+		t.Errorf("12 real minutes at scale 60 = half a world day, got %v", days)
+	}
+	if days := clock.advance(start); days != 0 {
+		t.Errorf("time must not go back, got %v", days)
+	}
+	if days := clock.advance(start.Add(24 * time.Hour)); days != maxTickDays {
+		t.Errorf("long pauses are capped, got %v", days)
+	}
+}
+
+func TestEconomyClockWorld(t *testing.T) {
+	clock := &economyClock{timeScale: 60}
+	if days := clock.advanceWorld("world-1", 10*time.Hour, 60); days != 0 {
+		t.Errorf("first world tick only starts the clock, got %v", days)
+	}
+	if days := clock.advanceWorld("world-1", 22*time.Hour, 60); math.Abs(days-0.5) > 1e-9 {
+		t.Errorf("12 world hours = half a world day, got %v", days)
+	}
+	if days := clock.advanceWorld("world-1", 20*time.Hour, 60); days != 0 {
+		t.Errorf("world time must not go back, got %v", days)
+	}
+	if !clock.hasWorld("world-1") || clock.hasWorld("world-2") {
+		t.Errorf("only world-1 is ticked by Chronos")
+	}
+	if clock.rate("world-2") != 60 {
+		t.Errorf("worlds without ticks use the time scale, got %v", clock.rate("world-2"))
+	}
+}
+
+func TestCityStoreUpdateAll(t *testing.T) {
+	store := NewCityStore()
+	store.Update("world-1", "city-1", func(state *CityState) { state.Population = 100 })
+	store.Update("world-2", "city-2", func(state *CityState) { state.Population = 200 })
+
+	states := store.UpdateAll(func(cities []*CityState) {
+		for _, city := range cities {
+			city.Economy = newCityEconomy(city.CityID, city.Population)
+		}
+	})
+	if len(states) != 2 || states[0].CityID != "city-1" || states[1].Economy == nil {
+		t.Fatalf("unexpected states: %+v", states)
+	}
+	states[0].Economy.Stocks["food"] = -1
+	if current, _ := store.Get("world-1", "city-1"); current.Economy.Stocks["food"] < 0 {
+		t.Errorf("returned economies must be copies")
+	}
+}
@@ -3,30 +3,74 @@ package citygovernor
 
 import (
 	"context"
-	"log"
 	"time"
 
 	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/eventbus/events"
+	"multiverse-core.io/shared/logging"
 
 	"github.com/google/uuid"
 )
 
+// playerActions are the event types reported on behalf of a player.
+var playerActions = map[string]bool{
+	"player.entered":  true,
+	"quest.completed": true,
+	"npc.interaction": true,
+}
+
 // CityGovernor manages city-related logic.
 type CityGovernor struct {
-	bus *eventbus.EventBus
+	bus      *eventbus.EventBus
+	state    *CityStore
+	quests   *QuestGenerator
+	dialogue *DialogueGenerator
+	clock    *economyClock
+	karma    *playerKarma
 }
 
 // NewCityGovernor creates a new CityGovernor.
 func NewCityGovernor(bus *eventbus.EventBus) *CityGovernor {
-	return &CityGovernor{bus: bus}
+	return &CityGovernor{
+		bus:      bus,
+		state:    NewCityStore(),
+		quests:   NewQuestGenerator(),
+		dialogue: NewDialogueGenerator(),
+		clock:    &economyClock{timeScale: DefaultEconomyTimeScale},
+		karma:    newPlayerKarma(),
+	}
 }
 
 // HandleEvent processes events for city management.
 func (cg *CityGovernor) HandleEvent(ev eventbus.Event) {
+	// System events are not city-scoped: cities created by WorldGenerator and world time
+	switch ev.Type {
+	case "entity.created":
+		cg.handleCityCreated(ev)
+		return
+	case events.TypeTimeSync:
+		cg.handleTimeSync(ev)
+		cg.expireQuests(ev)
+		return
+	case EventActiveQuestsRequested:
+		cg.handleActiveQuestsRequest(ev)
+		return
+	case EventPlayerKarma:
+		cg.handlePlayerKarma(ev)
+		return
+	}
 	if eventbus.GetScopeFromEvent(ev) == nil {
 		return // Not a city-scoped event
 	}
 
+	// Player actions are trusted only with the identity of the player's session
+	if playerActions[ev.Type] {
+		if err := eventbus.VerifyPlayer(ev, eventPlayerID(ev)); err != nil {
+			logging.Warnf("Ignoring %s %s: %v", ev.Type, ev.ID, err)
+			return
+		}
+	}
+
 	switch ev.Type {
 	case "player.entered":
 		cg.handlePlayerEntry(ev)
@@ -34,6 +78,8 @@ func (cg *CityGovernor) HandleEvent(ev eventbus.Event) {
 		cg.handleViolation(ev)
 	case "quest.completed":
 		cg.handleQuestCompletion(ev)
+	case events.TypeAchievementUnlocked:
+		cg.handleAchievementUnlocked(ev)
 	case "city.reputation.changed":
 		cg.handleReputationChange(ev)
 	case "npc.interaction":
@@ -62,19 +108,19 @@ func (cg *CityGovernor) handlePlayerEntry(ev eventbus.Event) {
 	}
 
 	if playerID == "" || cityID == "" {
-		log.Printf("Player entry missing player_id or city_id")
+		logging.Warnf("Player entry missing player_id or city_id")
 		return
 	}
 
 	worldID := eventbus.GetWorldIDFromEvent(ev)
 
 	// Generate welcome quest if player is new
-	if cg.isNewPlayerInCity(playerID, cityID) {
+	if cg.isNewPlayerInCity(worldID, playerID, cityID) {
 		cg.generateWelcomeQuest(ev)
 	}
 
 	// Update city population
-	cg.updateCityPopulation(cityID, 1)
+	cg.updateCityPopulation(ev, worldID, cityID, 1)
 
 	// Notify NPCs of new arrival — с иерархической структурой событий:
 	npcPayload := eventbus.NewEventPayload().
@@ -91,7 +137,7 @@ func (cg *CityGovernor) handlePlayerEntry(ev eventbus.Event) {
 	eventbus.SetNested(npcPayload.GetCustom(), "scope.id", cityID)
 	eventbus.SetNested(npcPayload.GetCustom(), "scope.type", "city")
 
-	npcEvent := eventbus.NewStructuredEvent("citizen.event", "city-governor", worldID, npcPayload)
+	npcEvent := eventbus.NewStructuredEvent("citizen.event", "city-governor", worldID, npcPayload).CausedBy(ev)
 	npcEvent.ID = "npc-notify-" + uuid.New().String()[:8]
 	npcEvent.Timestamp = time.Now()
 
@@ -107,7 +153,7 @@ func (cg *CityGovernor) handlePlayerEntry(ev eventbus.Event) {
 
 	cg.bus.Publish(context.Background(), eventbus.TopicGameEvents, npcEvent)
 
-	log.Printf("Player %s entered city %s", playerID, cityID)
+	logging.Infof("Player %s entered city %s", playerID, cityID)
 }
 
 // handleViolation handles world integrity violations in the city — с универсальным доступом и иерархическими событиями:
@@ -161,7 +207,7 @@ func (cg *CityGovernor) handleViolation(ev eventbus.Event) {
 	eventbus.SetNested(consequencePayload.GetCustom(), "scope.type", "city")
 	eventbus.SetNested(consequencePayload.GetCustom(), "violation.type", violationType)
 
-	consequenceEvent := eventbus.NewStructuredEvent("city.violation.consequence", "city-governor", worldID, consequencePayload)
+	consequenceEvent := eventbus.NewStructuredEvent("city.violation.consequence", "city-governor", worldID, consequencePayload).CausedBy(ev)
 	consequenceEvent.ID = "city-conseq-" + uuid.New().String()[:8]
 	consequenceEvent.Timestamp = time.Now()
 
@@ -182,9 +228,12 @@ func (cg *CityGovernor) handleViolation(ev eventbus.Event) {
 	cg.bus.Publish(context.Background(), eventbus.TopicGameEvents, consequenceEvent)
 
 	// Update city reputation
-	cg.updateCityReputation(cityID, -10) // Reputation decreases on violations
+	cg.updateCityReputation(ev, worldID, cityID, -10) // Reputation decreases on violations
+	cg.economyViolation(ev, worldID, cityID)
+	// The city no longer trusts the player with its quests
+	cg.failPlayerQuests(ev, worldID, cityID, playerID, "violation")
 
-	log.Printf("Applied consequence %s for violation %s in city %s", consequence, violationType, cityID)
+	logging.Infof("Applied consequence %s for violation %s in city %s", consequence, violationType, cityID)
 }
 
 // handleQuestCompletion handles quest completion events.
@@ -210,8 +259,13 @@ func (cg *CityGovernor) handleQuestCompletion(ev eventbus.Event) {
 
 	worldID := eventbus.GetWorldIDFromEvent(ev)
 
-	// Generate reward
-	reward := cg.generateQuestReward(questID, cityID)
+	// Only the player's active quests are rewarded: expired and failed quests are no longer active
+	quest, ok := cg.completeQuest(worldID, cityID, questID, playerID)
+	if !ok {
+		logging.Warnf("Ignoring completion of unknown or inactive quest %s by %s in city %s", questID, playerID, cityID)
+		return
+	}
+	questType, reward := quest.Type, quest.Reward
 
 	rewardPayload := eventbus.NewEventPayload().
 		WithEntity(playerID, "player", "").
@@ -222,24 +276,76 @@ func (cg *CityGovernor) handleQuestCompletion(ev eventbus.Event) {
 	eventbus.SetNested(rewardPayload.GetCustom(), "reward", reward)
 	eventbus.SetNested(rewardPayload.GetCustom(), "city.id", cityID)
 
-	rewardEvent := eventbus.NewStructuredEvent("quest.reward.granted", "city-governor", worldID, rewardPayload)
+	rewardEvent := eventbus.NewStructuredEvent("quest.reward.granted", "city-governor", worldID, rewardPayload).CausedBy(ev)
 	rewardEvent.ID = "quest-reward-" + uuid.New().String()[:8]
 	rewardEvent.Timestamp = time.Now()
 	cg.bus.Publish(context.Background(), eventbus.TopicGameEvents, rewardEvent)
 
-	// Update reputation based on quest type
-	questType, _ := pa.GetString("quest_type")
-	reputationChange := cg.getReputationChangeForQuest(questType)
-	cg.updateCityReputation(cityID, reputationChange)
+	cg.economyQuestCompleted(ev, worldID, cityID)
+
+	// Update reputation based on quest reward or type
+	reputationChange := reward.Reputation
+	if reputationChange == 0 {
+		reputationChange = cg.getReputationChangeForQuest(questType)
+	}
+	cg.updateCityReputation(ev, worldID, cityID, reputationChange)
 
 	// Generate new quest
 	cg.generateNewQuest(ev)
 
-	log.Printf("Granted reward for quest %s to player %s in city %s", questID, playerID, cityID)
+	logging.Infof("Granted reward for quest %s to player %s in city %s", questID, playerID, cityID)
+}
+
+// handleAchievementUnlocked grants the reward of an achievement unlocked in a city.
+func (cg *CityGovernor) handleAchievementUnlocked(ev eventbus.Event) {
+	unlocked, cityID, ok := achievementReward(ev)
+	if !ok {
+		return
+	}
+	playerID := unlocked.EntityID()
+	worldID := eventbus.GetWorldIDFromEvent(ev)
+	reward := *unlocked.Reward
+
+	rewardPayload := eventbus.NewEventPayload().
+		WithEntity(playerID, "player", "").
+		WithScope(cityID, "city").
+		WithWorld(worldID)
+
+	eventbus.SetNested(rewardPayload.GetCustom(), "achievement_id", unlocked.AchievementID)
+	eventbus.SetNested(rewardPayload.GetCustom(), "reward", reward)
+	eventbus.SetNested(rewardPayload.GetCustom(), "city.id", cityID)
+
+	rewardEvent := eventbus.NewStructuredEvent("achievement.reward.granted", "city-governor", worldID, rewardPayload).CausedBy(ev)
+	rewardEvent.ID = "achievement-reward-" + uuid.New().String()[:8]
+	rewardEvent.Timestamp = time.Now()
+	cg.bus.Publish(context.Background(), eventbus.TopicGameEvents, rewardEvent)
+
+	cg.updateCityReputation(ev, worldID, cityID, reward.Reputation)
+
+	logging.Infof("Granted reward for achievement %s to player %s in city %s", unlocked.AchievementID, playerID, cityID)
+}
+
+// achievementReward reads an achievement.unlocked that a city rewards: one with a reward,
+// unlocked by a player in a city scope.
+func achievementReward(ev eventbus.Event) (events.AchievementUnlocked, string, bool) {
+	var unlocked events.AchievementUnlocked
+	if err := events.Unmarshal(ev, &unlocked); err != nil {
+		logging.Warnf("Ignoring malformed achievement.unlocked %s: %v", ev.ID, err)
+		return unlocked, "", false
+	}
+	scope := eventbus.GetScopeFromEvent(ev)
+	if unlocked.Reward == nil || unlocked.EntityID() == "" || scope == nil || scope.Type != "city" || scope.ID == "" {
+		return unlocked, "", false
+	}
+	return unlocked, scope.ID, true
 }
 
-// handleReputationChange handles reputation changes.
+// handleReputationChange handles reputation changes made by other services.
+// Changes published by CityGovernor itself are already applied to the city state.
 func (cg *CityGovernor) handleReputationChange(ev eventbus.Event) {
+	if ev.Source == "city-governor" {
+		return
+	}
 	pa := ev.Path()
 	scope := eventbus.GetScopeFromEvent(ev)
 	if scope == nil {
@@ -247,13 +353,45 @@ func (cg *CityGovernor) handleReputationChange(ev eventbus.Event) {
 	}
 	cityID := scope.ID
 	change, _ := pa.GetFloat("change")
+	worldID := eventbus.GetWorldIDFromEvent(ev)
 
-	newReputation := cg.getCurrentReputation(cityID) + int(change)
+	state := cg.state.Update(worldID, cityID, func(state *CityState) {
+		state.adjustReputation(int(change))
+	})
 
 	// Apply reputation effects
-	cg.applyReputationEffects(cityID, newReputation)
+	cg.applyReputationEffects(ev, worldID, cityID, state.Reputation)
 
-	log.Printf("City %s reputation changed to %d", cityID, newReputation)
+	logging.Infof("City %s reputation changed to %d", cityID, state.Reputation)
+}
+
+// handleCityCreated seeds the state of a city created by WorldGenerator with its name and population.
+func (cg *CityGovernor) handleCityCreated(ev eventbus.Event) {
+	entityInfo, ok := ev.GetEntityIDWithFallback()
+	if !ok || entityInfo.Type != "city" {
+		return
+	}
+	pa := ev.Path()
+	name, _ := pa.GetString("payload.name")
+	population, _ := pa.GetFloat("payload.population")
+	x, okX := pa.GetFloat("payload.location.coordinates.x")
+	y, okY := pa.GetFloat("payload.location.coordinates.y")
+
+	cg.state.Update(eventbus.GetWorldIDFromEvent(ev), entityInfo.ID, func(state *CityState) {
+		if state.Name == "" {
+			state.Name = name
+		}
+		// A repeated event must not reset the accumulated population
+		if state.Population == 0 {
+			state.Population = int(population)
+			// The seeded population is not a milestone to announce
+			d := state.demographics()
+			d.Milestone, d.Peak = populationMilestone(state.Population), max(d.Peak, state.Population)
+		}
+		if okX && okY {
+			state.Location = &CityLocation{X: x, Y: y}
+		}
+	})
 }
 
 // handleNPCInteraction handles NPC interaction events.
@@ -287,7 +425,8 @@ func (cg *CityGovernor) handleNPCInteraction(ev eventbus.Event) {
 	}
 
 	// Generate interaction response
-	response := cg.generateNPCResponse(npcID, interactionType, cityID)
+	message, _ := pa.GetString("message")
+	response, generated := cg.generateNPCResponse(ev, playerID, npcID, interactionType, message)
 
 	responsePayload := eventbus.NewEventPayload().
 		WithEntity(playerID, "player", "").
@@ -297,51 +436,39 @@ func (cg *CityGovernor) handleNPCInteraction(ev eventbus.Event) {
 	eventbus.SetNested(responsePayload.GetCustom(), "npc_id", npcID)
 	eventbus.SetNested(responsePayload.GetCustom(), "interaction_type", interactionType)
 	eventbus.SetNested(responsePayload.GetCustom(), "response", response)
+	eventbus.SetNested(responsePayload.GetCustom(), "generated", generated)
 	eventbus.SetNested(responsePayload.GetCustom(), "city.id", cityID)
 
-	responseEvent := eventbus.NewStructuredEvent("npc.response.generated", "city-governor", eventbus.GetWorldIDFromEvent(ev), responsePayload)
+	responseEvent := eventbus.NewStructuredEvent("npc.response.generated", "city-governor", eventbus.GetWorldIDFromEvent(ev), responsePayload).CausedBy(ev)
 	responseEvent.ID = "npc-response-" + uuid.New().String()[:8]
 	responseEvent.Timestamp = time.Now()
 	cg.bus.Publish(context.Background(), eventbus.TopicGameEvents, responseEvent)
 
-	log.Printf("Generated NPC response for %s -> %s in city %s", playerID, npcID, cityID)
+	logging.Infof("Generated NPC response for %s -> %s in city %s", playerID, npcID, cityID)
 }
 
 // generateWelcomeQuest generates a welcome quest for new players.
 func (cg *CityGovernor) generateWelcomeQuest(ev eventbus.Event) {
-	pa := ev.Path()
-	playerID, _ := pa.GetString("player_id")
+	cg.offerQuest(ev, "welcome", "welcome", "quest-welcome-")
+}
+
+// generateNewQuest generates a new quest after completion.
+func (cg *CityGovernor) generateNewQuest(ev eventbus.Event) {
+	playerID := eventPlayerID(ev)
 	scope := eventbus.GetScopeFromEvent(ev)
 	if scope == nil {
 		return
 	}
-	cityID := scope.ID
-	worldID := eventbus.GetWorldIDFromEvent(ev)
-
-	questID := "welcome-" + uuid.New().String()[:8]
 
-	questPayload := eventbus.NewEventPayload().
-		WithEntity(playerID, "player", "").
-		WithScope(cityID, "city").
-		WithWorld(worldID)
-
-	eventbus.SetNested(questPayload.GetCustom(), "quest_id", questID)
-	eventbus.SetNested(questPayload.GetCustom(), "title", "Добро пожаловать в "+cg.getCityName(cityID))
-	eventbus.SetNested(questPayload.GetCustom(), "description", "Старейшина просит вас принести ему Слёзы Памяти из ближайшего леса.")
-	eventbus.SetNested(questPayload.GetCustom(), "reward", "50 золотых и репутация +10")
-	eventbus.SetNested(questPayload.GetCustom(), "quest_type", "welcome")
-	eventbus.SetNested(questPayload.GetCustom(), "city.id", cityID)
-
-	questEvent := eventbus.NewStructuredEvent("quest.assigned", "city-governor", worldID, questPayload)
-	questEvent.ID = "quest-welcome-" + uuid.New().String()[:8]
-	questEvent.Timestamp = time.Now()
-	cg.bus.Publish(context.Background(), eventbus.TopicGameEvents, questEvent)
+	// Determine quest type based on player history and city state
+	questType := cg.determineNextQuestType(eventbus.GetWorldIDFromEvent(ev), playerID, scope.ID)
+	cg.offerQuest(ev, questType, "quest", "quest-new-")
 }
 
-// generateNewQuest generates a new quest after completion.
-func (cg *CityGovernor) generateNewQuest(ev eventbus.Event) {
-	pa := ev.Path()
-	playerID, _ := pa.GetString("player_id")
+// offerQuest generates a quest of the given type for the player of the event,
+// records it as active and publishes quest.assigned.
+func (cg *CityGovernor) offerQuest(ev eventbus.Event, questType, questPrefix, eventPrefix string) {
+	playerID := eventPlayerID(ev)
 	scope := eventbus.GetScopeFromEvent(ev)
 	if scope == nil {
 		return
@@ -349,49 +476,93 @@ func (cg *CityGovernor) generateNewQuest(ev eventbus.Event) {
 	cityID := scope.ID
 	worldID := eventbus.GetWorldIDFromEvent(ev)
 
-	// Determine quest type based on player history and city state
-	questType := cg.determineNextQuestType(playerID, cityID)
-
-	questID := "quest-" + uuid.New().String()[:8]
-
-	questPayload := eventbus.NewEventPayload().
-		WithEntity(playerID, "player", "").
-		WithScope(cityID, "city").
-		WithWorld(worldID)
-
-	eventbus.SetNested(questPayload.GetCustom(), "quest_id", questID)
-	eventbus.SetNested(questPayload.GetCustom(), "title", cg.generateQuestTitle(questType, cityID))
-	eventbus.SetNested(questPayload.GetCustom(), "description", cg.generateQuestDescription(questType, cityID))
-	eventbus.SetNested(questPayload.GetCustom(), "reward", cg.generateQuestReward(questID, cityID))
-	eventbus.SetNested(questPayload.GetCustom(), "quest_type", questType)
-	eventbus.SetNested(questPayload.GetCustom(), "city.id", cityID)
+	// One active quest per player and city
+	if questID, ok := cg.activeQuestInCity(worldID, cityID, playerID); ok {
+		logging.Infof("Player %s already has active quest %s in city %s", playerID, questID, cityID)
+		return
+	}
+	// Cities do not trust players with bad karma
+	if !cg.trustsPlayer(playerID) {
+		logging.Infof("City %s refuses quests to player %s with karma %d", cityID, playerID, cg.karma.get(playerID))
+		return
+	}
 
-	questEvent := eventbus.NewStructuredEvent("quest.assigned", "city-governor", worldID, questPayload)
-	questEvent.ID = "quest-new-" + uuid.New().String()[:8]
+	city, _ := cg.state.Get(worldID, cityID)
+	quest := cg.quests.Generate(context.Background(), QuestRequest{
+		WorldID:  worldID,
+		CityID:   cityID,
+		CityName: cg.getCityName(worldID, cityID),
+		PlayerID: playerID,
+		Type:     questType,
+		City:     city,
+	})
+
+	questID := questPrefix + "-" + uuid.New().String()[:8]
+	assigned := cg.assignQuest(worldID, cityID, questID, playerID, quest)
+
+	questEvent, err := events.NewChild(ev, "city-governor", events.QuestAssigned{
+		Subject: events.Subject{
+			Entity: events.EntityOf(playerID, "player", ""),
+			World:  events.WorldOf(worldID),
+			Scope:  &eventbus.ScopeRef{ID: cityID, Type: "city"},
+		},
+		QuestID:     questID,
+		QuestType:   questType,
+		Title:       quest.Title,
+		Description: quest.Description,
+		Objectives:  quest.Objectives,
+		Reward:      quest.Reward,
+		Generated:   quest.Generated,
+		Deadline:    assigned.Deadline,
+		City:        &events.CityRef{ID: cityID},
+	})
+	if err != nil {
+		logging.Errorf("Failed to build quest.assigned for %s: %v", questID, err)
+		return
+	}
+	questEvent.ID = eventPrefix + uuid.New().String()[:8]
 	questEvent.Timestamp = time.Now()
 	cg.bus.Publish(context.Background(), eventbus.TopicGameEvents, questEvent)
 }
 
 // Helper methods (simplified implementations)
 
-func (cg *CityGovernor) isNewPlayerInCity(playerID, cityID string) bool {
-	// In a real implementation, this would check player history
-	return true // Simplified for example
+// eventPlayerID extracts the player ID: entity.id → player_id.
+func eventPlayerID(ev eventbus.Event) string {
+	if entityInfo, ok := ev.GetEntityIDWithFallback(); ok {
+		return entityInfo.ID
+	}
+	playerID, _ := ev.Path().GetString("player_id")
+	return playerID
 }
 
-func (cg *CityGovernor) updateCityPopulation(cityID string, delta int) {
-	// Publish population update event
-	popPayload := eventbus.NewEventPayload().
-		WithScope(cityID, "city").
-		WithWorld("global")
-
-	eventbus.SetNested(popPayload.GetCustom(), "delta", delta)
-	eventbus.SetNested(popPayload.GetCustom(), "city.id", cityID)
+// isNewPlayerInCity records the visit and reports whether it is the player's first visit to the city.
+func (cg *CityGovernor) isNewPlayerInCity(worldID, playerID, cityID string) bool {
+	isNew := false
+	cg.state.Update(worldID, cityID, func(state *CityState) {
+		if _, visited := state.Visitors[playerID]; !visited {
+			state.Visitors[playerID] = time.Now().UTC()
+			isNew = true
+		}
+	})
+	return isNew
+}
 
-	popEvent := eventbus.NewStructuredEvent("city.population.changed", "city-governor", "global", popPayload)
-	popEvent.ID = "pop-update-" + uuid.New().String()[:8]
-	popEvent.Timestamp = time.Now()
-	cg.bus.Publish(context.Background(), eventbus.TopicGameEvents, popEvent)
+// assignQuest records a quest assigned by the city as active, with its deadline.
+func (cg *CityGovernor) assignQuest(worldID, cityID, questID, playerID string, quest Quest) CityQuest {
+	assignedAt := time.Now().UTC()
+	assigned := CityQuest{
+		PlayerID:   playerID,
+		Type:       quest.Type,
+		Title:      quest.Title,
+		Reward:     quest.Reward,
+		AssignedAt: assignedAt,
+		Deadline:   cg.questDeadline(worldID, quest.Type, assignedAt),
+	}
+	cg.state.Update(worldID, cityID, func(state *CityState) {
+		state.ActiveQuests[questID] = assigned
+	})
+	return assigned
 }
 
 func (cg *CityGovernor) getCityConsequence(violationType, cityID string) string {
@@ -406,23 +577,28 @@ func (cg *CityGovernor) getCityConsequence(violationType, cityID string) string
 	}
 }
 
-func (cg *CityGovernor) updateCityReputation(cityID string, delta int) {
+func (cg *CityGovernor) updateCityReputation(cause eventbus.Event, worldID, cityID string, delta int) {
+	if delta == 0 {
+		return
+	}
+	state := cg.state.Update(worldID, cityID, func(state *CityState) {
+		state.adjustReputation(delta)
+	})
+
 	repPayload := eventbus.NewEventPayload().
 		WithScope(cityID, "city").
-		WithWorld("global")
+		WithWorld(worldID)
 
 	eventbus.SetNested(repPayload.GetCustom(), "change", delta)
+	eventbus.SetNested(repPayload.GetCustom(), "reputation", state.Reputation)
 	eventbus.SetNested(repPayload.GetCustom(), "city.id", cityID)
 
-	repEvent := eventbus.NewStructuredEvent("city.reputation.changed", "city-governor", "global", repPayload)
+	repEvent := eventbus.NewStructuredEvent("city.reputation.changed", "city-governor", worldID, repPayload).CausedBy(cause)
 	repEvent.ID = "rep-update-" + uuid.New().String()[:8]
 	repEvent.Timestamp = time.Now()
 	cg.bus.Publish(context.Background(), eventbus.TopicGameEvents, repEvent)
-}
 
-func (cg *CityGovernor) generateQuestReward(questID, cityID string) string {
-	// Generate reward based on quest and city
-	return "100 золотых и уникальный предмет"
+	cg.applyReputationEffects(cause, worldID, cityID, state.Reputation)
 }
 
 func (cg *CityGovernor) getReputationChangeForQuest(questType string) int {
@@ -438,12 +614,14 @@ func (cg *CityGovernor) getReputationChangeForQuest(questType string) int {
 	}
 }
 
-func (cg *CityGovernor) getCurrentReputation(cityID string) int {
-	// In real implementation, this would query city state
-	return 50 // Default reputation
+func (cg *CityGovernor) getCurrentReputation(worldID, cityID string) int {
+	if state, ok := cg.state.Get(worldID, cityID); ok {
+		return state.Reputation
+	}
+	return defaultReputation
 }
 
-func (cg *CityGovernor) applyReputationEffects(cityID string, reputation int) {
+func (cg *CityGovernor) applyReputationEffects(cause eventbus.Event, worldID, cityID string, reputation int) {
 	// Apply effects based on reputation level
 	var effect string
 	if reputation > 75 {
@@ -456,24 +634,40 @@ func (cg *CityGovernor) applyReputationEffects(cityID string, reputation int) {
 
 	effectPayload := eventbus.NewEventPayload().
 		WithScope(cityID, "city").
-		WithWorld("global")
+		WithWorld(worldID)
 
 	eventbus.SetNested(effectPayload.GetCustom(), "effect", effect)
 	eventbus.SetNested(effectPayload.GetCustom(), "level", reputation)
 	eventbus.SetNested(effectPayload.GetCustom(), "city.id", cityID)
 
-	effectEvent := eventbus.NewStructuredEvent("city.reputation.effect", "city-governor", "global", effectPayload)
+	effectEvent := eventbus.NewStructuredEvent("city.reputation.effect", "city-governor", worldID, effectPayload).CausedBy(cause)
 	effectEvent.ID = "rep-effect-" + uuid.New().String()[:8]
 	effectEvent.Timestamp = time.Now()
 	cg.bus.Publish(context.Background(), eventbus.TopicGameEvents, effectEvent)
 }
 
-func (cg *CityGovernor) generateNPCResponse(npcID, interactionType, cityID string) string {
-	// Generate context-aware NPC response
-	return "Старейшина кивает вам и говорит: 'Добро пожаловать в наш город.'"
+// generateNPCResponse answers the player on behalf of the NPC from the NPC entity, city state,
+// player history and the NPC's memory of recent conversations.
+func (cg *CityGovernor) generateNPCResponse(ev eventbus.Event, playerID, npcID, interactionType, message string) (string, bool) {
+	cityID := eventbus.GetScopeFromEvent(ev).ID
+	worldID := eventbus.GetWorldIDFromEvent(ev)
+	city, _ := cg.state.Get(worldID, cityID)
+	return cg.dialogue.Respond(context.Background(), DialogueRequest{
+		WorldID:     worldID,
+		CityID:      cityID,
+		CityName:    cg.getCityName(worldID, cityID),
+		NPCID:       npcID,
+		PlayerID:    playerID,
+		Interaction: interactionType,
+		Message:     message,
+		City:        city,
+	})
 }
 
-func (cg *CityGovernor) getCityName(cityID string) string {
+func (cg *CityGovernor) getCityName(worldID, cityID string) string {
+	if state, ok := cg.state.Get(worldID, cityID); ok && state.Name != "" {
+		return state.Name
+	}
 	// Map city IDs to names
 	switch cityID {
 	case "city-ashes":
@@ -485,15 +679,10 @@ func (cg *CityGovernor) getCityName(cityID string) string {
 	}
 }
 
-func (cg *CityGovernor) determineNextQuestType(playerID, cityID string) string {
-	// Determine quest type based on player and city state
-	return "help_citizen" // Simplified
-}
-
-func (cg *CityGovernor) generateQuestTitle(questType, cityID string) string {
-	return "Помощь горожанину"
-}
-
-func (cg *CityGovernor) generateQuestDescription(questType, cityID string) string {
-	return "Один из горожан просит вашей помощи с доставкой посылки."
+// determineNextQuestType picks the next quest type: a city that trusts players asks for dangerous work.
+func (cg *CityGovernor) determineNextQuestType(worldID, playerID, cityID string) string {
+	if cg.getCurrentReputation(worldID, cityID) >= 70 {
+		return "defeat_monster"
+	}
+	return "help_citizen"
 }
@@ -0,0 +1,63 @@
+package citygovernor
+
+import (
+	"testing"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/eventbus/events"
+)
+
+func achievementEvent(t *testing.T, scope *eventbus.ScopeRef, reward *events.QuestReward) eventbus.Event {
+	t.Helper()
+	ev, err := events.New("achievement-tracker", "world-1", events.AchievementUnlocked{
+		Subject: events.Subject{
+			Entity: events.EntityOf("player-1", "player", ""),
+			World:  events.WorldOf("world-1"),
+			Scope:  scope,
+		},
+		AchievementID: "city-helper",
+		Reward:        reward,
+	})
+	if err != nil {
+		t.Fatalf("events.New: %v", err)
+	}
+	return ev
+}
+
+func TestAchievementReward(t *testing.T) {
+	city := &eventbus.ScopeRef{ID: "city-1", Type: "city"}
+	reward := &events.QuestReward{Gold: 100, Reputation: 5}
+
+	unlocked, cityID, ok := achievementReward(achievementEvent(t, city, reward))
+	if !ok || cityID != "city-1" || unlocked.EntityID() != "player-1" || unlocked.Reward.Reputation != 5 {
+		t.Fatalf("expected a reward in city-1, got %+v in %q (%v)", unlocked, cityID, ok)
+	}
+
+	if _, _, ok := achievementReward(achievementEvent(t, city, nil)); ok {
+		t.Error("achievements without a reward are not rewarded")
+	}
+	if _, _, ok := achievementReward(achievementEvent(t, &eventbus.ScopeRef{ID: "player-1", Type: "solo"}, reward)); ok {
+		t.Error("achievements outside a city are not rewarded")
+	}
+}
+
+func TestUnverifiedPlayerActionsIgnored(t *testing.T) {
+	cg := NewCityGovernor(nil)
+	entered := func(identity map[string]any) eventbus.Event {
+		payload := eventbus.NewEventPayload().
+			WithEntity("player-1", "player", "").
+			WithScope("city-1", "city").
+			WithWorld("world-1").
+			ToMap()
+		if identity != nil {
+			payload["identity"] = identity
+		}
+		return eventbus.NewEvent("player.entered", "game-service", "world-1", payload)
+	}
+
+	cg.HandleEvent(entered(nil))
+	cg.HandleEvent(entered(map[string]any{"player_id": "player-2", "world_id": "world-1", "verified": true}))
+	if state, ok := cg.state.Get("world-1", "city-1"); ok {
+		t.Errorf("unverified entries must not change the city, got %+v", state)
+	}
+}
@@ -0,0 +1,58 @@
+package citygovernor
+
+import (
+	"sync"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+)
+
+// EventPlayerKarma is published by BanOfWorld with the player's karma in [-100, 0]
+// after violations and as they are forgiven.
+const EventPlayerKarma = "player.reputation.karma"
+
+// questKarmaThreshold is the karma at or below which cities refuse to offer quests.
+const questKarmaThreshold = -40
+
+// playerKarma keeps the latest karma of players with unforgiven violations.
+type playerKarma struct {
+	mu     sync.Mutex
+	values map[string]int
+}
+
+func newPlayerKarma() *playerKarma {
+	return &playerKarma{values: make(map[string]int)}
+}
+
+func (k *playerKarma) set(playerID string, karma int) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if karma >= 0 {
+		delete(k.values, playerID)
+		return
+	}
+	k.values[playerID] = karma
+}
+
+// get returns the player's karma; 0 for players without violations.
+func (k *playerKarma) get(playerID string) int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.values[playerID]
+}
+
+// handlePlayerKarma records the karma announced by BanOfWorld.
+func (cg *CityGovernor) handlePlayerKarma(ev eventbus.Event) {
+	playerID := eventPlayerID(ev)
+	karma, ok := ev.Path().GetFloat("karma")
+	if playerID == "" || !ok {
+		return
+	}
+	cg.karma.set(playerID, int(karma))
+	logging.Infof("Player %s karma is %d", playerID, int(karma))
+}
+
+// trustsPlayer reports whether cities still offer quests to the player.
+func (cg *CityGovernor) trustsPlayer(playerID string) bool {
+	return cg.karma.get(playerID) > questKarmaThreshold
+}
@@ -0,0 +1,29 @@
+package citygovernor
+
+import (
+	"testing"
+
+	"multiverse-core.io/shared/eventbus"
+)
+
+func TestPlayerKarma(t *testing.T) {
+	cg := NewCityGovernor(nil)
+	if !cg.trustsPlayer("player-1") {
+		t.Errorf("players without violations are trusted")
+	}
+	cg.handlePlayerKarma(eventbus.NewEvent(EventPlayerKarma, "ban-of-world", "world-1", map[string]interface{}{
+		"entity": map[string]interface{}{"id": "player-1", "type": "player"},
+		"karma":  -60,
+	}))
+	if cg.trustsPlayer("player-1") || cg.karma.get("player-1") != -60 {
+		t.Errorf("player with karma -60 must not be trusted")
+	}
+	cg.karma.set("player-1", -20)
+	if !cg.trustsPlayer("player-1") {
+		t.Errorf("recovered karma restores trust")
+	}
+	cg.karma.set("player-1", 0)
+	if len(cg.karma.values) != 0 {
+		t.Errorf("forgiven players are forgotten")
+	}
+}
@@ -0,0 +1,210 @@
+package citygovernor
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+
+	"github.com/google/uuid"
+)
+
+// Population events: every change (game_events, with state_changes for EntityManager) and
+// milestones reached by the city (world_events, for the narrative).
+const (
+	EventPopulationChanged = "city.population.changed"
+	EventCityGrew          = "city.grew"
+	EventCityDeclined      = "city.declined"
+	EventCityAbandoned     = "city.abandoned"
+)
+
+// Population rates per resident and world day.
+const (
+	birthRate = 0.002
+	deathRate = 0.0015
+	// migrationRate is the migration at the best (or worst) reputation and prosperity.
+	migrationRate = 0.003
+	// famineMortality is the extra death rate per missing share of food.
+	famineMortality = 3.0
+	// declineHysteresis is the share of a milestone the population must fall below to
+	// announce the decline, so a city hovering around a milestone does not flap.
+	declineHysteresis = 0.9
+)
+
+// populationMilestones are the city sizes announced by city.grew and city.declined.
+var populationMilestones = []int{100, 500, 1000, 5000, 10000, 50000, 100000}
+
+// CityDemographics is the population model of a city.
+type CityDemographics struct {
+	Births    float64 `json:"births"`    // residents per world day at the last tick
+	Deaths    float64 `json:"deaths"`    // residents per world day at the last tick
+	Migration float64 `json:"migration"` // residents per world day at the last tick; negative — emigration
+	Remainder float64 `json:"remainder"` // fractional residents carried between ticks
+	Milestone int     `json:"milestone"` // last announced milestone; 0 below the first one
+	Peak      int     `json:"peak"`
+	Abandoned bool    `json:"abandoned,omitempty"`
+}
+
+// populationMilestone returns the largest milestone not above population; 0 below the first one.
+func populationMilestone(population int) int {
+	reached := 0
+	for _, milestone := range populationMilestones {
+		if population >= milestone {
+			reached = milestone
+		}
+	}
+	return reached
+}
+
+// demographics returns the population model, creating it at the current population:
+// cities seeded by WorldGenerator and older snapshots start without announcing milestones.
+func (s *CityState) demographics() *CityDemographics {
+	if s.Demographics == nil {
+		s.Demographics = &CityDemographics{
+			Milestone: populationMilestone(s.Population),
+			Peak:      s.Population,
+		}
+	}
+	return s.Demographics
+}
+
+// changePopulation changes population by delta and returns the milestone event type; "" if none.
+func (s *CityState) changePopulation(delta int) string {
+	s.demographics()
+	s.adjustPopulation(delta)
+	return s.passMilestone()
+}
+
+// passMilestone records the milestone of the current population and returns its event type; "" if none.
+func (s *CityState) passMilestone() string {
+	d := s.demographics()
+	d.Peak = max(d.Peak, s.Population)
+	if s.Population == 0 {
+		if d.Peak == 0 || d.Abandoned {
+			return ""
+		}
+		d.Abandoned, d.Milestone = true, 0
+		return EventCityAbandoned
+	}
+	d.Abandoned = false
+
+	reached := populationMilestone(s.Population)
+	switch {
+	case reached > d.Milestone:
+		d.Milestone = reached
+		return EventCityGrew
+	case reached < d.Milestone && float64(s.Population) < float64(d.Milestone)*declineHysteresis:
+		d.Milestone = reached
+		return EventCityDeclined
+	}
+	return ""
+}
+
+// simulatePopulation advances births, deaths and migration by days of world time and returns
+// the population change and the milestone event type. Food shortage lowers births and raises
+// deaths; reputation and prosperity attract or drive away migrants. Abandoned cities stay empty
+// until someone arrives.
+func (s *CityState) simulatePopulation(days float64) (int, string) {
+	if s.Population <= 0 || days <= 0 {
+		return 0, ""
+	}
+	d := s.demographics()
+	population := float64(s.Population)
+
+	food, prosperity := 1.0, 1.0
+	if s.Economy != nil {
+		food = min(s.Economy.Stocks["food"]/targetStock("food", s.Population), 1)
+		prosperity = s.Economy.prosperity(s.Population)
+	}
+	attraction := float64(s.Reputation-defaultReputation)/float64(maxReputation-defaultReputation) + prosperity - 1
+
+	d.Births = population * birthRate * food
+	d.Deaths = population * deathRate * (1 + famineMortality*(1-food))
+	d.Migration = population * migrationRate * min(max(attraction, -1), 1)
+
+	change := (d.Births-d.Deaths+d.Migration)*days + d.Remainder
+	delta := int(change)
+	d.Remainder = change - float64(delta)
+	before := s.Population
+	milestone := s.changePopulation(delta)
+	return s.Population - before, milestone
+}
+
+// prosperity is the mean stock of resources relative to the target stock, in [0, 2]; 1 — a supplied city.
+func (e *CityEconomy) prosperity(population int) float64 {
+	var total float64
+	for _, name := range resourceNames {
+		total += min(max(e.Stocks[name]/targetStock(name, population), 0), 2)
+	}
+	return total / float64(len(resourceNames))
+}
+
+// populationStateChanges stores the population of the city entity through EntityManager.
+func populationStateChanges(state CityState) []interface{} {
+	var demographics map[string]interface{}
+	encoded, _ := json.Marshal(state.Demographics)
+	json.Unmarshal(encoded, &demographics)
+	return []interface{}{
+		map[string]interface{}{
+			"entity_id": state.CityID,
+			"operations": []interface{}{
+				map[string]interface{}{"op": "set", "path": "population", "value": state.Population},
+				map[string]interface{}{"op": "set", "path": "demographics", "value": demographics},
+			},
+		},
+	}
+}
+
+func (cg *CityGovernor) updateCityPopulation(cause eventbus.Event, worldID, cityID string, delta int) {
+	var milestone string
+	state := cg.state.Update(worldID, cityID, func(state *CityState) {
+		milestone = state.changePopulation(delta)
+	})
+	cg.publishPopulation(cause, state, delta, milestone)
+}
+
+// publishPopulation publishes city.population.changed and the milestone event, if any.
+func (cg *CityGovernor) publishPopulation(cause eventbus.Event, state CityState, delta int, milestone string) {
+	popPayload := eventbus.NewEventPayload().
+		WithScope(state.CityID, "city").
+		WithWorld(state.WorldID)
+
+	eventbus.SetNested(popPayload.GetCustom(), "delta", delta)
+	eventbus.SetNested(popPayload.GetCustom(), "population", state.Population)
+	eventbus.SetNested(popPayload.GetCustom(), "city.id", state.CityID)
+	if d := state.Demographics; d != nil {
+		eventbus.SetNested(popPayload.GetCustom(), "births", d.Births)
+		eventbus.SetNested(popPayload.GetCustom(), "deaths", d.Deaths)
+		eventbus.SetNested(popPayload.GetCustom(), "migration", d.Migration)
+	}
+	// EntityManager stores the population in the city entity
+	eventbus.SetNested(popPayload.GetCustom(), "state_changes", populationStateChanges(state))
+
+	popEvent := eventbus.NewStructuredEvent(EventPopulationChanged, "city-governor", state.WorldID, popPayload).CausedBy(cause)
+	popEvent.ID = "pop-update-" + uuid.New().String()[:8]
+	popEvent.Timestamp = time.Now()
+	cg.bus.Publish(context.Background(), eventbus.TopicGameEvents, popEvent)
+
+	if milestone != "" {
+		cg.publishMilestone(cause, state, milestone)
+	}
+}
+
+// publishMilestone publishes city.grew, city.declined or city.abandoned to world_events.
+func (cg *CityGovernor) publishMilestone(cause eventbus.Event, state CityState, eventType string) {
+	payload := eventbus.NewEventPayload().
+		WithEntity(state.CityID, "city", state.Name).
+		WithScope(state.CityID, "city").
+		WithWorld(state.WorldID)
+
+	eventbus.SetNested(payload.GetCustom(), "city.id", state.CityID)
+	eventbus.SetNested(payload.GetCustom(), "population", state.Population)
+	eventbus.SetNested(payload.GetCustom(), "milestone", state.Demographics.Milestone)
+	eventbus.SetNested(payload.GetCustom(), "peak", state.Demographics.Peak)
+
+	event := eventbus.NewStructuredEvent(eventType, "city-governor", state.WorldID, payload).CausedBy(cause)
+	event.ID = "city-milestone-" + uuid.New().String()[:8]
+	event.Timestamp = time.Now()
+	cg.bus.Publish(context.Background(), eventbus.TopicWorldEvents, event)
+}
@@ -0,0 +1,61 @@
+package citygovernor
+
+import "testing"
+
+func TestPopulationMilestones(t *testing.T) {
+	state := newCityState("world-1", "city-1")
+	state.Population = 480
+	if event := state.changePopulation(30); event != EventCityGrew || state.Demographics.Milestone != 500 {
+		t.Fatalf("expected city.grew at 500, got %q %+v", event, state.Demographics)
+	}
+	// Hovering around the milestone is not a decline
+	if event := state.changePopulation(-40); event != "" {
+		t.Errorf("expected no event above the decline hysteresis, got %q", event)
+	}
+	if event := state.changePopulation(-30); event != EventCityDeclined || state.Demographics.Milestone != 100 {
+		t.Errorf("expected city.declined to 100, got %q %+v", event, state.Demographics)
+	}
+	if event := state.changePopulation(-1000); event != EventCityAbandoned || state.Population != 0 {
+		t.Errorf("expected city.abandoned, got %q with population %d", event, state.Population)
+	}
+	if event := state.changePopulation(0); event != "" {
+		t.Errorf("abandonment must be announced once, got %q", event)
+	}
+	if state.Demographics.Peak != 510 {
+		t.Errorf("expected peak 510, got %d", state.Demographics.Peak)
+	}
+}
+
+func TestSimulatePopulation(t *testing.T) {
+	city := func(reputation int, food float64) *CityState {
+		state := newCityState("world-1", "city-1")
+		state.Population = 1000
+		state.Reputation = reputation
+		state.Economy = newCityEconomy("city-1", state.Population)
+		state.Economy.Stocks["food"] = targetStock("food", state.Population) * food
+		return state
+	}
+
+	if delta, _ := city(100, 1).simulatePopulation(1); delta <= 0 {
+		t.Errorf("a reputable supplied city must grow, got %d", delta)
+	}
+	if delta, _ := city(20, 0).simulatePopulation(1); delta >= 0 {
+		t.Errorf("a starving disliked city must shrink, got %d", delta)
+	}
+
+	// Fractional residents accumulate between small ticks
+	state := city(defaultReputation, 1)
+	total := 0
+	for i := 0; i < 400; i++ {
+		delta, _ := state.simulatePopulation(0.01)
+		total += delta
+	}
+	if total == 0 || state.Demographics.Births <= state.Demographics.Deaths {
+		t.Errorf("small ticks must add up, got %d residents, %+v", total, state.Demographics)
+	}
+
+	abandoned := newCityState("world-1", "city-2")
+	if delta, event := abandoned.simulatePopulation(1); delta != 0 || event != "" {
+		t.Errorf("empty cities stay empty, got %d %q", delta, event)
+	}
+}
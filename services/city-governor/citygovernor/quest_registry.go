@@ -0,0 +1,196 @@
+package citygovernor
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+
+	"github.com/google/uuid"
+)
+
+// Quest lifecycle events.
+const (
+	// EventQuestExpired is published when a quest deadline passes.
+	EventQuestExpired = "quest.expired"
+	// EventQuestFailed is published when a quest is failed, e.g. after a violation in its city.
+	EventQuestFailed = "quest.failed"
+	// EventActiveQuestsRequested asks for the active quests of a player (entity.id).
+	EventActiveQuestsRequested = "quest.active.requested"
+	// EventActiveQuestsList answers EventActiveQuestsRequested.
+	EventActiveQuestsList = "quest.active.list"
+)
+
+// questDurations is the world time in days given to complete a quest of each type.
+var questDurations = map[string]float64{
+	"welcome":        1,
+	"help_citizen":   2,
+	"defeat_monster": 3,
+}
+
+const defaultQuestDays = 2
+
+// ActiveQuest is an active quest together with its city.
+type ActiveQuest struct {
+	QuestID string `json:"quest_id"`
+	WorldID string `json:"world_id"`
+	CityID  string `json:"city_id"`
+	CityQuest
+}
+
+// questDeadline converts the world time given for a quest type into a real-time deadline
+// at the current time rate of the world.
+func (cg *CityGovernor) questDeadline(worldID, questType string, assignedAt time.Time) time.Time {
+	days, ok := questDurations[questType]
+	if !ok {
+		days = defaultQuestDays
+	}
+	return assignedAt.Add(time.Duration(days * 24 * float64(time.Hour) / cg.clock.rate(worldID)))
+}
+
+// PlayerQuests returns the active quests of a player ordered by assignment time.
+// An empty worldID returns quests from all worlds.
+func (cs *CityStore) PlayerQuests(worldID, playerID string) []ActiveQuest {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	var quests []ActiveQuest
+	for _, state := range cs.cities {
+		if worldID != "" && state.WorldID != worldID {
+			continue
+		}
+		for questID, quest := range state.ActiveQuests {
+			if quest.PlayerID == playerID {
+				quests = append(quests, ActiveQuest{QuestID: questID, WorldID: state.WorldID, CityID: state.CityID, CityQuest: quest})
+			}
+		}
+	}
+	sort.Slice(quests, func(i, j int) bool {
+		if !quests[i].AssignedAt.Equal(quests[j].AssignedAt) {
+			return quests[i].AssignedAt.Before(quests[j].AssignedAt)
+		}
+		return quests[i].QuestID < quests[j].QuestID
+	})
+	return quests
+}
+
+// activeQuestInCity returns the ID of the player's active quest in the city.
+func (cg *CityGovernor) activeQuestInCity(worldID, cityID, playerID string) (string, bool) {
+	state, ok := cg.state.Get(worldID, cityID)
+	if !ok {
+		return "", false
+	}
+	for questID, quest := range state.ActiveQuests {
+		if quest.PlayerID == playerID {
+			return questID, true
+		}
+	}
+	return "", false
+}
+
+// completeQuest removes the player's active quest and counts it as completed.
+// False if the quest is not active in the city or belongs to another player.
+func (cg *CityGovernor) completeQuest(worldID, cityID, questID, playerID string) (CityQuest, bool) {
+	if _, known := cg.state.Get(worldID, cityID); !known {
+		return CityQuest{}, false
+	}
+	var completed CityQuest
+	found := false
+	cg.state.Update(worldID, cityID, func(state *CityState) {
+		quest, ok := state.ActiveQuests[questID]
+		if !ok || (quest.PlayerID != "" && quest.PlayerID != playerID) {
+			return
+		}
+		delete(state.ActiveQuests, questID)
+		state.CompletedQuests++
+		completed, found = quest, true
+	})
+	return completed, found
+}
+
+// expireQuests removes quests whose deadline has passed by the time of the tick and publishes quest.expired.
+func (cg *CityGovernor) expireQuests(tick eventbus.Event) {
+	now := eventTime(tick)
+	var expired []ActiveQuest
+	cg.state.UpdateAll(func(cities []*CityState) {
+		for _, city := range cities {
+			for questID, quest := range city.ActiveQuests {
+				if !quest.Deadline.IsZero() && !now.Before(quest.Deadline) {
+					delete(city.ActiveQuests, questID)
+					expired = append(expired, ActiveQuest{QuestID: questID, WorldID: city.WorldID, CityID: city.CityID, CityQuest: quest})
+				}
+			}
+		}
+	})
+	for _, quest := range expired {
+		cg.publishQuestOutcome(tick, EventQuestExpired, quest, "deadline")
+	}
+}
+
+// failPlayerQuests fails all active quests of the player in the city.
+func (cg *CityGovernor) failPlayerQuests(cause eventbus.Event, worldID, cityID, playerID, reason string) {
+	if _, ok := cg.activeQuestInCity(worldID, cityID, playerID); !ok {
+		return
+	}
+	var failed []ActiveQuest
+	cg.state.Update(worldID, cityID, func(state *CityState) {
+		for questID, quest := range state.ActiveQuests {
+			if quest.PlayerID == playerID {
+				delete(state.ActiveQuests, questID)
+				failed = append(failed, ActiveQuest{QuestID: questID, WorldID: worldID, CityID: cityID, CityQuest: quest})
+			}
+		}
+	})
+	for _, quest := range failed {
+		cg.publishQuestOutcome(cause, EventQuestFailed, quest, reason)
+	}
+}
+
+// publishQuestOutcome publishes quest.expired or quest.failed for the quest's player.
+func (cg *CityGovernor) publishQuestOutcome(cause eventbus.Event, eventType string, quest ActiveQuest, reason string) {
+	payload := eventbus.NewEventPayload().
+		WithEntity(quest.PlayerID, "player", "").
+		WithScope(quest.CityID, "city").
+		WithWorld(quest.WorldID)
+
+	eventbus.SetNested(payload.GetCustom(), "quest_id", quest.QuestID)
+	eventbus.SetNested(payload.GetCustom(), "quest_type", quest.Type)
+	eventbus.SetNested(payload.GetCustom(), "title", quest.Title)
+	eventbus.SetNested(payload.GetCustom(), "deadline", quest.Deadline)
+	eventbus.SetNested(payload.GetCustom(), "reason", reason)
+	eventbus.SetNested(payload.GetCustom(), "city.id", quest.CityID)
+
+	outcomeEvent := eventbus.NewStructuredEvent(eventType, "city-governor", quest.WorldID, payload).CausedBy(cause)
+	outcomeEvent.ID = "quest-outcome-" + uuid.New().String()[:8]
+	outcomeEvent.Timestamp = time.Now()
+	cg.bus.Publish(context.Background(), eventbus.TopicGameEvents, outcomeEvent)
+
+	logging.Infof("Quest %s of player %s in city %s: %s (%s)", quest.QuestID, quest.PlayerID, quest.CityID, eventType, reason)
+}
+
+// handleActiveQuestsRequest answers quest.active.requested with the player's active quests.
+// Without a world in the request quests from all worlds are listed.
+func (cg *CityGovernor) handleActiveQuestsRequest(ev eventbus.Event) {
+	playerID := eventPlayerID(ev)
+	if playerID == "" {
+		return
+	}
+	worldID := eventbus.GetWorldIDFromEvent(ev)
+	quests := cg.state.PlayerQuests(worldID, playerID)
+	if quests == nil {
+		quests = []ActiveQuest{}
+	}
+
+	payload := eventbus.NewEventPayload().
+		WithEntity(playerID, "player", "").
+		WithWorld(worldID)
+
+	eventbus.SetNested(payload.GetCustom(), "request_id", ev.ID)
+	eventbus.SetNested(payload.GetCustom(), "quests", quests)
+
+	listEvent := eventbus.NewStructuredEvent(EventActiveQuestsList, "city-governor", worldID, payload).CausedBy(ev)
+	listEvent.ID = "quest-list-" + uuid.New().String()[:8]
+	listEvent.Timestamp = time.Now()
+	cg.bus.Publish(context.Background(), eventbus.TopicGameEvents, listEvent)
+}
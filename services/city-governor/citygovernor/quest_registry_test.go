@@ -0,0 +1,67 @@
+package citygovernor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuestDeadline(t *testing.T) {
+	cg := NewCityGovernor(nil)
+	assignedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// At scale 60 a world day lasts 24 real minutes
+	if deadline := cg.questDeadline("world-1", "defeat_monster", assignedAt); !deadline.Equal(assignedAt.Add(72 * time.Minute)) {
+		t.Errorf("defeat_monster deadline = %v", deadline)
+	}
+	cg.SetEconomyTimeScale(1)
+	if deadline := cg.questDeadline("world-1", "unknown", assignedAt); !deadline.Equal(assignedAt.Add(48 * time.Hour)) {
+		t.Errorf("default deadline = %v", deadline)
+	}
+
+	// A world ticked by Chronos uses the rate of its plan
+	cg.clock.advanceWorld("world-2", time.Hour, 120)
+	if deadline := cg.questDeadline("world-2", "unknown", assignedAt); !deadline.Equal(assignedAt.Add(24 * time.Minute)) {
+		t.Errorf("world-2 deadline = %v", deadline)
+	}
+}
+
+func TestQuestRegistry(t *testing.T) {
+	cg := NewCityGovernor(nil)
+	first := cg.assignQuest("world-1", "city-1", "quest-1", "player-1", Quest{Type: "welcome", Title: "Добро пожаловать"})
+	cg.assignQuest("world-1", "city-2", "quest-2", "player-1", Quest{Type: "help_citizen"})
+	cg.assignQuest("world-2", "city-3", "quest-3", "player-1", Quest{Type: "help_citizen"})
+	cg.assignQuest("world-1", "city-1", "quest-4", "player-2", Quest{Type: "help_citizen"})
+	if first.Deadline.IsZero() || !first.Deadline.After(first.AssignedAt) {
+		t.Errorf("assigned quest must have a deadline: %+v", first)
+	}
+
+	if questID, ok := cg.activeQuestInCity("world-1", "city-1", "player-1"); !ok || questID != "quest-1" {
+		t.Errorf("active quest in city = %q, %v", questID, ok)
+	}
+	if quests := cg.state.PlayerQuests("world-1", "player-1"); len(quests) != 2 || quests[0].QuestID != "quest-1" || quests[0].Title != "Добро пожаловать" {
+		t.Errorf("player quests in world-1 = %+v", quests)
+	}
+	if quests := cg.state.PlayerQuests("", "player-1"); len(quests) != 3 {
+		t.Errorf("player quests in all worlds = %+v", quests)
+	}
+
+	// Quests of other players and unknown quests are not completed
+	if _, ok := cg.completeQuest("world-1", "city-1", "quest-4", "player-1"); ok {
+		t.Errorf("a player must not complete another player's quest")
+	}
+	if _, ok := cg.completeQuest("world-1", "city-9", "quest-1", "player-1"); ok {
+		t.Errorf("unknown city must not complete quests")
+	}
+	if quest, ok := cg.completeQuest("world-1", "city-1", "quest-1", "player-1"); !ok || quest.Type != "welcome" {
+		t.Errorf("expected completed welcome quest, got %+v, %v", quest, ok)
+	}
+	if _, ok := cg.completeQuest("world-1", "city-1", "quest-1", "player-1"); ok {
+		t.Errorf("a quest must be completed only once")
+	}
+	if state, _ := cg.state.Get("world-1", "city-1"); state.CompletedQuests != 1 || len(state.ActiveQuests) != 1 {
+		t.Errorf("unexpected city state after completion: %+v", state)
+	}
+	if _, ok := cg.state.Get("world-1", "city-9"); ok {
+		t.Errorf("completion attempts must not create cities")
+	}
+}
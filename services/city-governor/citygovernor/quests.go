@@ -0,0 +1,394 @@
+package citygovernor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/archivist"
+	"multiverse-core.io/shared/eventbus/events"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+	"multiverse-core.io/shared/schema"
+)
+
+// Quest schema in OntologicalArchivist: schemas/quest/city_quest.
+const (
+	questSchemaType = "quest"
+	questSchemaName = "city_quest"
+)
+
+const (
+	// questSchemaTTL is how long a fetched quest schema is reused.
+	questSchemaTTL = 5 * time.Minute
+	// questGenerationTimeout bounds the context lookups and the Oracle call of one quest.
+	questGenerationTimeout = 20 * time.Second
+	// playerHistoryDepth is the depth of related events requested from SemanticMemory.
+	playerHistoryDepth = 2
+	maxHistoryChars    = 2000
+
+	maxQuestObjectives = 5
+	maxQuestGold       = 1000
+	maxQuestReputation = 20
+)
+
+// worldsBucket holds world records written by WorldGenerator: {world_id}/world.json
+const worldsBucket = "worlds"
+
+// defaultQuestSchema is used when the archivist has no city quest schema.
+const defaultQuestSchema = `{
+  "type": "object",
+  "required": ["title", "description", "objectives", "reward"],
+  "properties": {
+    "title": {"type": "string", "minLength": 1, "maxLength": 120},
+    "description": {"type": "string", "minLength": 1, "maxLength": 1000},
+    "objectives": {"type": "array", "minItems": 1, "maxItems": 5, "items": {"type": "string", "minLength": 1}},
+    "reward": {
+      "type": "object",
+      "required": ["gold", "reputation"],
+      "properties": {
+        "gold": {"type": "integer", "minimum": 0, "maximum": 1000},
+        "items": {"type": "array", "items": {"type": "string"}},
+        "reputation": {"type": "integer", "minimum": 0, "maximum": 20}
+      }
+    }
+  }
+}`
+
+// QuestReward is what the player receives for completing a quest; quest.assigned carries it as is.
+type QuestReward = events.QuestReward
+
+// Quest is a quest offered by a city.
+type Quest struct {
+	Type        string      `json:"type"`
+	Title       string      `json:"title"`
+	Description string      `json:"description"`
+	Objectives  []string    `json:"objectives"`
+	Reward      QuestReward `json:"reward"`
+	Generated   bool        `json:"-"` // true if written by the Oracle, false for template quests
+}
+
+// QuestRequest describes the quest to generate.
+type QuestRequest struct {
+	WorldID  string
+	CityID   string
+	CityName string
+	PlayerID string
+	Type     string
+	City     CityState
+}
+
+// questOracle is the part of the Oracle client used for quest generation.
+type questOracle interface {
+	CallStructuredJSON(ctx context.Context, systemPrompt, userPrompt string) (string, error)
+}
+
+// worldContext is the part of the WorldGenerator world record used in quest prompts.
+type worldContext struct {
+	Concept struct {
+		Core  string `json:"core"`
+		Theme string `json:"theme"`
+		Era   string `json:"era"`
+	} `json:"concept"`
+	Ontology struct {
+		System    string   `json:"system"`
+		Carriers  []string `json:"carriers"`
+		Paths     []string `json:"paths"`
+		Forbidden []string `json:"forbidden"`
+	} `json:"ontology"`
+}
+
+// QuestGenerator writes city quests with the Oracle from city state, player history and
+// world ontology. Without an Oracle, or when its answer is unusable, template quests are used.
+type QuestGenerator struct {
+	oracle    questOracle
+	archivist *archivist.Client
+	memory    *SemanticMemoryClient
+	worlds    storage.ObjectStorage
+	now       func() time.Time
+
+	mu            sync.Mutex
+	schema        *schema.Validator
+	schemaText    string
+	schemaExpires time.Time
+	ontologies    map[string]*worldContext // world ID → world context
+}
+
+// NewQuestGenerator creates a generator that uses template quests until an Oracle is set.
+func NewQuestGenerator() *QuestGenerator {
+	return &QuestGenerator{
+		now:        time.Now,
+		ontologies: make(map[string]*worldContext),
+	}
+}
+
+// UseOracle enables quest generation by the Oracle.
+func (g *QuestGenerator) UseOracle(oracle questOracle) {
+	g.oracle = oracle
+}
+
+// UseArchivist enables loading the quest schema from OntologicalArchivist.
+func (g *QuestGenerator) UseArchivist(client *archivist.Client) {
+	g.archivist = client
+}
+
+// UseSemanticMemory enables player history in quest prompts.
+func (g *QuestGenerator) UseSemanticMemory(memory *SemanticMemoryClient) {
+	g.memory = memory
+}
+
+// UseWorldStorage enables world concept and ontology in quest prompts.
+func (g *QuestGenerator) UseWorldStorage(client storage.ObjectStorage) {
+	g.worlds = client
+}
+
+// Generate returns a quest for the request, falling back to a template quest
+// when the Oracle is unavailable or its answer fails validation.
+func (g *QuestGenerator) Generate(ctx context.Context, req QuestRequest) Quest {
+	if g.oracle != nil {
+		ctx, cancel := context.WithTimeout(ctx, questGenerationTimeout)
+		defer cancel()
+		quest, err := g.generateWithOracle(ctx, req)
+		if err == nil {
+			return quest
+		}
+		logging.Errorf("Oracle quest generation for city %s failed, using template: %v", req.CityID, err)
+	}
+	return templateQuest(req)
+}
+
+func (g *QuestGenerator) generateWithOracle(ctx context.Context, req QuestRequest) (Quest, error) {
+	validator, schemaText := g.questSchema(ctx)
+	systemPrompt, userPrompt := buildQuestPrompts(req, g.worldContext(req.WorldID), playerHistory(ctx, g.memory, req.PlayerID), schemaText)
+
+	response, err := g.oracle.CallStructuredJSON(ctx, systemPrompt, userPrompt)
+	if err != nil {
+		return Quest{}, err
+	}
+	return parseQuest(response, validator, req.Type)
+}
+
+// parseQuest validates the Oracle answer against the quest schema and normalizes it.
+func parseQuest(response string, validator *schema.Validator, questType string) (Quest, error) {
+	var raw map[string]interface{}
+	if err := json.Unmarshal([]byte(response), &raw); err != nil {
+		return Quest{}, fmt.Errorf("invalid quest JSON: %w", err)
+	}
+	if validator != nil {
+		if err := validator.Validate(raw); err != nil {
+			return Quest{}, fmt.Errorf("quest does not match schema: %w", err)
+		}
+	}
+
+	var quest Quest
+	if err := json.Unmarshal([]byte(response), &quest); err != nil {
+		return Quest{}, fmt.Errorf("invalid quest JSON: %w", err)
+	}
+	quest.Title = strings.TrimSpace(quest.Title)
+	quest.Description = strings.TrimSpace(quest.Description)
+	if quest.Title == "" || quest.Description == "" {
+		return Quest{}, errors.New("quest without title or description")
+	}
+
+	objectives := make([]string, 0, len(quest.Objectives))
+	for _, objective := range quest.Objectives {
+		if objective = strings.TrimSpace(objective); objective != "" && len(objectives) < maxQuestObjectives {
+			objectives = append(objectives, objective)
+		}
+	}
+	quest.Objectives = objectives
+	quest.Type = questType
+	// The schema may be more permissive than the city economy allows
+	quest.Reward.Gold = min(max(quest.Reward.Gold, 0), maxQuestGold)
+	quest.Reward.Reputation = min(max(quest.Reward.Reputation, 0), maxQuestReputation)
+	quest.Generated = true
+	return quest, nil
+}
+
+// questSchema returns the cached quest schema validator and its text for the prompt.
+// The built-in schema is used when the archivist has no quest schema or is unavailable.
+func (g *QuestGenerator) questSchema(ctx context.Context) (*schema.Validator, string) {
+	g.mu.Lock()
+	if g.schemaText != "" && g.now().Before(g.schemaExpires) {
+		defer g.mu.Unlock()
+		return g.schema, g.schemaText
+	}
+	g.mu.Unlock()
+
+	schemaText := defaultQuestSchema
+	if g.archivist != nil {
+		var questSchema json.RawMessage
+		switch err := g.archivist.GetSchema(ctx, questSchemaType, questSchemaName, &questSchema); {
+		case err == nil:
+			schemaText = string(questSchema)
+		case storage.IsNotFound(err):
+			// No quest schema published yet: the built-in one is cached like a fetched one
+		default:
+			// Retry the archivist on the next quest
+			logging.Warnf("Quest schema unavailable, using built-in schema: %v", err)
+			validator, _ := schema.NewValidator([]byte(defaultQuestSchema))
+			return validator, defaultQuestSchema
+		}
+	}
+
+	validator, err := schema.NewValidator([]byte(schemaText))
+	if err != nil {
+		logging.Warnf("Invalid quest schema: %v", err)
+	}
+
+	g.mu.Lock()
+	g.schema, g.schemaText, g.schemaExpires = validator, schemaText, g.now().Add(questSchemaTTL)
+	g.mu.Unlock()
+	return validator, schemaText
+}
+
+// playerHistory returns the player's context from SemanticMemory, truncated for the prompt.
+func playerHistory(ctx context.Context, memory *SemanticMemoryClient, playerID string) string {
+	if memory == nil || playerID == "" {
+		return ""
+	}
+	history, err := memory.EntityContext(ctx, playerID, playerHistoryDepth)
+	if err != nil {
+		logging.Warnf("Player history for %s unavailable: %v", playerID, err)
+		return ""
+	}
+	return truncateRunes(history, maxHistoryChars)
+}
+
+// worldContext returns the concept and ontology of a world; nil if unknown.
+// Worlds are cached: their concept and ontology do not change after generation.
+func (g *QuestGenerator) worldContext(worldID string) *worldContext {
+	if g.worlds == nil || worldID == "" {
+		return nil
+	}
+	g.mu.Lock()
+	world, ok := g.ontologies[worldID]
+	g.mu.Unlock()
+	if ok {
+		return world
+	}
+
+	data, err := g.worlds.GetObject(worldsBucket, worldID+"/world.json")
+	if err != nil {
+		if !storage.IsNotFound(err) {
+			logging.Warnf("World %s record unavailable: %v", worldID, err)
+		}
+		return nil
+	}
+	world = &worldContext{}
+	if err := json.Unmarshal(data, world); err != nil {
+		logging.Infof("Corrupted world record %s: %v", worldID, err)
+		return nil
+	}
+
+	g.mu.Lock()
+	g.ontologies[worldID] = world
+	g.mu.Unlock()
+	return world
+}
+
+// buildQuestPrompts builds the system and user prompts for one city quest.
+func buildQuestPrompts(req QuestRequest, world *worldContext, history, schemaText string) (systemPrompt, userPrompt string) {
+	var sb strings.Builder
+	sb.WriteString("Ты — Градоначальник, выдающий игрокам квесты от имени города.\n")
+	if world != nil {
+		fmt.Fprintf(&sb, `
+Концепция мира:
+- Ядро: %s
+- Тема: %s
+- Эпоха: %s
+
+Онтология мира:
+- Система: %s
+- Носители силы: %s
+- Пути развития: %s
+- Запреты: %s
+`, world.Concept.Core, world.Concept.Theme, world.Concept.Era, world.Ontology.System,
+			strings.Join(world.Ontology.Carriers, ", "), strings.Join(world.Ontology.Paths, ", "), strings.Join(world.Ontology.Forbidden, ", "))
+	}
+	sb.WriteString("\nКвест не должен нарушать онтологию и запреты мира.\n\nОтвечай строго в формате JSON без пояснений.")
+	systemPrompt = sb.String()
+
+	if history == "" {
+		history = "нет данных"
+	}
+	userPrompt = fmt.Sprintf(`Создай квест типа «%s» для игрока %s в городе «%s».
+
+Состояние города:
+- Репутация игроков у города: %d из 100
+- Население: %d
+- Активных квестов: %d, выполнено квестов: %d
+
+История игрока:
+%s
+
+Требования:
+- квест продолжает историю игрока и соответствует состоянию города
+- 1-%d цели, награда: золото до %d, репутация до %d
+
+Ответ должен соответствовать JSON Schema:
+%s`, req.Type, req.PlayerID, req.CityName, req.City.Reputation, req.City.Population,
+		len(req.City.ActiveQuests), req.City.CompletedQuests, history,
+		maxQuestObjectives, maxQuestGold, maxQuestReputation, schemaText)
+
+	return systemPrompt, userPrompt
+}
+
+// questTemplate is a fallback quest; %s in the title and description is the city name.
+type questTemplate struct {
+	Title       string
+	Description string
+	Objectives  []string
+	Reward      QuestReward
+}
+
+var questTemplates = map[string]questTemplate{
+	"welcome": {
+		Title:       "Добро пожаловать в %s",
+		Description: "Старейшина города %s просит вас принести ему Слёзы Памяти из ближайшего леса.",
+		Objectives:  []string{"Найти Слёзы Памяти в ближайшем лесу", "Принести их старейшине"},
+		Reward:      QuestReward{Gold: 50, Reputation: 10},
+	},
+	"help_citizen": {
+		Title:       "Помощь горожанину",
+		Description: "Один из горожан %s просит вашей помощи с доставкой посылки.",
+		Objectives:  []string{"Забрать посылку у горожанина", "Доставить посылку адресату"},
+		Reward:      QuestReward{Gold: 100, Items: []string{"уникальный предмет"}, Reputation: 5},
+	},
+	"defeat_monster": {
+		Title:       "Угроза у стен",
+		Description: "Стража %s просит избавить окрестности от чудовища, нападающего на путников.",
+		Objectives:  []string{"Выследить чудовище", "Победить чудовище", "Доложить страже"},
+		Reward:      QuestReward{Gold: 250, Reputation: 10},
+	},
+}
+
+// templateQuest builds a quest from the template of its type.
+func templateQuest(req QuestRequest) Quest {
+	tmpl, ok := questTemplates[req.Type]
+	if !ok {
+		tmpl = questTemplates["help_citizen"]
+	}
+	return Quest{
+		Type:        req.Type,
+		Title:       formatTemplate(tmpl.Title, req.CityName),
+		Description: formatTemplate(tmpl.Description, req.CityName),
+		Objectives:  append([]string(nil), tmpl.Objectives...),
+		Reward: QuestReward{
+			Gold:       tmpl.Reward.Gold,
+			Items:      append([]string(nil), tmpl.Reward.Items...),
+			Reputation: tmpl.Reward.Reputation,
+		},
+	}
+}
+
+func formatTemplate(text, cityName string) string {
+	if strings.Contains(text, "%s") {
+		return fmt.Sprintf(text, cityName)
+	}
+	return text
+}
@@ -0,0 +1,113 @@
+package citygovernor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"multiverse-core.io/shared/archivist"
+)
+
+// fakeOracle returns a fixed answer and records the last prompts.
+type fakeOracle struct {
+	response     string
+	err          error
+	systemPrompt string
+	userPrompt   string
+}
+
+func (f *fakeOracle) CallStructuredJSON(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	f.systemPrompt, f.userPrompt = systemPrompt, userPrompt
+	return f.response, f.err
+}
+
+func questRequest() QuestRequest {
+	state := newCityState("world-1", "city-1")
+	state.Reputation = 80
+	return QuestRequest{WorldID: "world-1", CityID: "city-1", CityName: "Вельград", PlayerID: "player-1", Type: "help_citizen", City: *state}
+}
+
+func TestQuestGeneratorOracle(t *testing.T) {
+	oracle := &fakeOracle{response: `{
+		"title": "  Пропавший караван ",
+		"description": "Караван купцов не вернулся с перевала.",
+		"objectives": ["Найти караван", " ", "Вернуть товары"],
+		"reward": {"gold": 300, "items": ["амулет"], "reputation": 15}
+	}`}
+	generator := NewQuestGenerator()
+	generator.UseOracle(oracle)
+
+	quest := generator.Generate(context.Background(), questRequest())
+	if !quest.Generated || quest.Title != "Пропавший караван" || quest.Type != "help_citizen" {
+		t.Fatalf("unexpected quest: %+v", quest)
+	}
+	if len(quest.Objectives) != 2 || quest.Reward.Gold != 300 || quest.Reward.Reputation != 15 {
+		t.Errorf("unexpected objectives or reward: %+v", quest)
+	}
+	if !strings.Contains(oracle.userPrompt, "Вельград") || !strings.Contains(oracle.userPrompt, "80 из 100") {
+		t.Errorf("prompt must describe the city state:\n%s", oracle.userPrompt)
+	}
+}
+
+func TestQuestGeneratorFallback(t *testing.T) {
+	cases := map[string]*fakeOracle{
+		"unavailable":     {err: errors.New("connection refused")},
+		"invalid json":    {response: "квест"},
+		"schema mismatch": {response: `{"title": "Квест", "description": "Описание", "objectives": [], "reward": {"gold": 5000, "reputation": 1}}`},
+	}
+	for name, oracle := range cases {
+		generator := NewQuestGenerator()
+		generator.UseOracle(oracle)
+
+		quest := generator.Generate(context.Background(), questRequest())
+		want := templateQuest(questRequest())
+		if quest.Generated || quest.Title != want.Title || quest.Reward.Gold != want.Reward.Gold {
+			t.Errorf("%s: expected template quest, got %+v", name, quest)
+		}
+	}
+
+	// Without an Oracle template quests mention the city
+	quest := NewQuestGenerator().Generate(context.Background(), QuestRequest{Type: "welcome", CityName: "Вельград"})
+	if quest.Title != "Добро пожаловать в Вельград" || quest.Reward.Reputation != 10 {
+		t.Errorf("unexpected welcome quest: %+v", quest)
+	}
+}
+
+func TestQuestGeneratorArchivistSchema(t *testing.T) {
+	var requests atomic.Int32
+	archivistServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path != "/v1/schemas/quest/city_quest/latest" {
+			http.NotFound(w, r)
+			return
+		}
+		// The published schema requires an item reward
+		w.Write([]byte(`{"type": "object", "required": ["title", "description", "reward"],
+			"properties": {"reward": {"type": "object", "required": ["items"]}}}`))
+	}))
+	defer archivistServer.Close()
+
+	oracle := &fakeOracle{response: `{"title": "Квест", "description": "Описание", "objectives": ["Цель"], "reward": {"gold": 10, "reputation": 1}}`}
+	generator := NewQuestGenerator()
+	generator.UseOracle(oracle)
+	generator.UseArchivist(archivist.NewClient(archivistServer.URL, nil))
+
+	if quest := generator.Generate(context.Background(), questRequest()); quest.Generated {
+		t.Errorf("quest without items must be rejected by the archivist schema")
+	}
+	if !strings.Contains(oracle.userPrompt, `"items"`) {
+		t.Errorf("prompt must include the archivist schema")
+	}
+
+	oracle.response = `{"title": "Квест", "description": "Описание", "reward": {"gold": 10, "items": ["меч"], "reputation": 1}}`
+	if quest := generator.Generate(context.Background(), questRequest()); !quest.Generated || quest.Reward.Items[0] != "меч" {
+		t.Errorf("expected generated quest, got %+v", quest)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("schema must be cached, archivist called %d times", n)
+	}
+}
@@ -3,14 +3,20 @@ package citygovernor
 
 import (
 	"context"
+	"time"
 
+	"multiverse-core.io/shared/archivist"
 	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+	"multiverse-core.io/shared/oracle"
 )
 
 // Service manages the CityGovernor lifecycle.
 type Service struct {
-	bus      *eventbus.EventBus
-	governor *CityGovernor
+	bus              *eventbus.EventBus
+	governor         *CityGovernor
+	snapshotInterval time.Duration
 }
 
 // NewService creates a new CityGovernor service.
@@ -21,12 +27,60 @@ func NewService(bus *eventbus.EventBus) *Service {
 	}
 }
 
+// UseStateStorage enables persisting city states to MinIO, snapshotting
+// changed states every interval (<= 0 — DefaultSnapshotInterval).
+// World records in the same storage give quest prompts the world ontology; NPC entities
+// give dialogue prompts the NPC profile, and NPC memories are persisted there too.
+func (s *Service) UseStateStorage(client storage.ObjectStorage, interval time.Duration) {
+	s.governor.state.UseStorage(client)
+	s.governor.quests.UseWorldStorage(client)
+	s.governor.dialogue.UseStorage(client)
+	s.snapshotInterval = interval
+}
+
+// SetEconomyTimeScale sets how many world seconds pass per real second in the city economy and quest deadlines.
+func (s *Service) SetEconomyTimeScale(scale float64) {
+	s.governor.SetEconomyTimeScale(scale)
+}
+
+// UseQuestGeneration enables Oracle-driven quests validated against the archivist quest schema,
+// with player history from SemanticMemory. archivist and memory may be nil.
+func (s *Service) UseQuestGeneration(client *oracle.Client, archivistClient *archivist.Client, memory *SemanticMemoryClient) {
+	if client != nil {
+		s.governor.quests.UseOracle(client)
+	}
+	if archivistClient != nil {
+		s.governor.quests.UseArchivist(archivistClient)
+	}
+	if memory != nil {
+		s.governor.quests.UseSemanticMemory(memory)
+	}
+}
+
+// UseDialogueGeneration enables NPC responses written by the Oracle, with player history
+// from SemanticMemory. memory may be nil.
+func (s *Service) UseDialogueGeneration(client *oracle.Client, memory *SemanticMemoryClient) {
+	if client != nil {
+		s.governor.dialogue.UseOracle(client)
+	}
+	if memory != nil {
+		s.governor.dialogue.UseSemanticMemory(memory)
+	}
+}
+
 // Run starts the service and blocks until context is cancelled.
 func (s *Service) Run(ctx context.Context) error {
-	// Subscribe to game_events and world_events for city management
+	if err := s.governor.state.Load(); err != nil {
+		logging.Errorf("Failed to load city states, starting empty: %v", err)
+	}
+	go s.governor.state.Run(ctx, s.snapshotInterval)
+
+	// Subscribe to game_events and world_events for city management,
+	// system_events for cities created by WorldGenerator
 	topics := []string{
 		eventbus.TopicGameEvents,
 		eventbus.TopicWorldEvents,
+		eventbus.TopicSystemEvents,
 	}
 
 	for _, topic := range topics {
@@ -0,0 +1,295 @@
+package citygovernor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+)
+
+// cityStatesBucket stores city state snapshots: {world_id}/{city_id}.json
+const cityStatesBucket = "city-states"
+
+// Reputation bounds; new cities start at defaultReputation.
+const (
+	defaultReputation = 50
+	minReputation     = 0
+	maxReputation     = 100
+)
+
+// DefaultSnapshotInterval is how often changed city states are written to storage.
+const DefaultSnapshotInterval = time.Minute
+
+// CityQuest is a quest assigned by the city and not yet completed.
+type CityQuest struct {
+	PlayerID   string      `json:"player_id,omitempty"`
+	Type       string      `json:"type"`
+	Title      string      `json:"title,omitempty"`
+	Reward     QuestReward `json:"reward"`
+	AssignedAt time.Time   `json:"assigned_at"`
+	Deadline   time.Time   `json:"deadline"` // zero — the quest does not expire
+}
+
+// CityState is the accumulated state of one city.
+type CityState struct {
+	WorldID         string               `json:"world_id"`
+	CityID          string               `json:"city_id"`
+	Name            string               `json:"name,omitempty"`
+	Location        *CityLocation        `json:"location,omitempty"`
+	Reputation      int                  `json:"reputation"`
+	Population      int                  `json:"population"`
+	Visitors        map[string]time.Time `json:"visitors"` // player ID → first visit
+	ActiveQuests    map[string]CityQuest `json:"active_quests"`
+	CompletedQuests int                  `json:"completed_quests"`
+	Economy         *CityEconomy         `json:"economy,omitempty"`
+	Demographics    *CityDemographics    `json:"demographics,omitempty"`
+	UpdatedAt       time.Time            `json:"updated_at"`
+}
+
+// CityLocation is the position of a city on the world map.
+type CityLocation struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+func newCityState(worldID, cityID string) *CityState {
+	return &CityState{
+		WorldID:      worldID,
+		CityID:       cityID,
+		Reputation:   defaultReputation,
+		Visitors:     make(map[string]time.Time),
+		ActiveQuests: make(map[string]CityQuest),
+	}
+}
+
+// clone returns a deep copy of the state, safe to read outside the store lock.
+func (s *CityState) clone() CityState {
+	c := *s
+	c.Visitors = make(map[string]time.Time, len(s.Visitors))
+	for id, at := range s.Visitors {
+		c.Visitors[id] = at
+	}
+	c.ActiveQuests = make(map[string]CityQuest, len(s.ActiveQuests))
+	for id, quest := range s.ActiveQuests {
+		c.ActiveQuests[id] = quest
+	}
+	if s.Location != nil {
+		location := *s.Location
+		c.Location = &location
+	}
+	if s.Economy != nil {
+		c.Economy = s.Economy.clone()
+	}
+	if s.Demographics != nil {
+		demographics := *s.Demographics
+		c.Demographics = &demographics
+	}
+	return c
+}
+
+// adjustReputation changes reputation by delta within [minReputation, maxReputation].
+func (s *CityState) adjustReputation(delta int) {
+	s.Reputation = min(max(s.Reputation+delta, minReputation), maxReputation)
+}
+
+// adjustPopulation changes population by delta; population never goes below zero.
+func (s *CityState) adjustPopulation(delta int) {
+	s.Population = max(s.Population+delta, 0)
+}
+
+// CityStore keeps city states in memory and snapshots changed states to MinIO.
+type CityStore struct {
+	storage storage.ObjectStorage // nil — state lives in memory only
+	now     func() time.Time
+
+	cities map[string]*CityState // {world_id}/{city_id} → state
+	dirty  map[string]bool
+	mu     sync.Mutex
+}
+
+// NewCityStore creates an in-memory city store; see UseStorage for persistence.
+func NewCityStore() *CityStore {
+	return &CityStore{
+		now:    time.Now,
+		cities: make(map[string]*CityState),
+		dirty:  make(map[string]bool),
+	}
+}
+
+// UseStorage enables persisting city states to MinIO.
+func (cs *CityStore) UseStorage(client storage.ObjectStorage) {
+	cs.storage = client
+}
+
+func cityKey(worldID, cityID string) string {
+	if worldID == "" {
+		worldID = "global"
+	}
+	return worldID + "/" + cityID
+}
+
+// Load reads all city state snapshots from storage. Corrupted snapshots are skipped.
+func (cs *CityStore) Load() error {
+	if cs.storage == nil {
+		return nil
+	}
+	objects, err := cs.storage.ListObjects(cityStatesBucket, "")
+	if err != nil {
+		if storage.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("list city states: %w", err)
+	}
+
+	loaded := make(map[string]*CityState)
+	for _, object := range objects {
+		if !strings.HasSuffix(object.Key, ".json") {
+			continue
+		}
+		data, err := cs.storage.GetObject(cityStatesBucket, object.Key)
+		if err != nil {
+			return fmt.Errorf("load city state %s: %w", object.Key, err)
+		}
+		state := &CityState{}
+		if err := json.Unmarshal(data, state); err != nil || state.CityID == "" {
+			logging.Warnf("Skipping corrupted city state %s: %v", object.Key, err)
+			continue
+		}
+		if state.Visitors == nil {
+			state.Visitors = make(map[string]time.Time)
+		}
+		if state.ActiveQuests == nil {
+			state.ActiveQuests = make(map[string]CityQuest)
+		}
+		loaded[cityKey(state.WorldID, state.CityID)] = state
+	}
+
+	cs.mu.Lock()
+	for key, state := range loaded {
+		// Events handled before loading finished take precedence
+		if _, exists := cs.cities[key]; !exists {
+			cs.cities[key] = state
+		}
+	}
+	cs.mu.Unlock()
+	logging.Infof("Loaded %d city states", len(loaded))
+	return nil
+}
+
+// Update applies fn to the city state, creating it with defaults if needed,
+// and returns a copy of the result. The state is written on the next snapshot.
+func (cs *CityStore) Update(worldID, cityID string, fn func(state *CityState)) CityState {
+	key := cityKey(worldID, cityID)
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	state, ok := cs.cities[key]
+	if !ok {
+		state = newCityState(worldID, cityID)
+		cs.cities[key] = state
+	}
+	fn(state)
+	state.UpdatedAt = cs.now().UTC()
+	cs.dirty[key] = true
+	return state.clone()
+}
+
+// UpdateAll applies fn to all known cities at once and returns copies of the results.
+// Every city is written on the next snapshot.
+func (cs *CityStore) UpdateAll(fn func(states []*CityState)) []CityState {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	keys := make([]string, 0, len(cs.cities))
+	for key := range cs.cities {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	states := make([]*CityState, len(keys))
+	for i, key := range keys {
+		states[i] = cs.cities[key]
+	}
+	fn(states)
+
+	now := cs.now().UTC()
+	result := make([]CityState, len(states))
+	for i, state := range states {
+		state.UpdatedAt = now
+		cs.dirty[keys[i]] = true
+		result[i] = state.clone()
+	}
+	return result
+}
+
+// Get returns a copy of the city state; false if the city is unknown.
+func (cs *CityStore) Get(worldID, cityID string) (CityState, bool) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	state, ok := cs.cities[cityKey(worldID, cityID)]
+	if !ok {
+		return CityState{}, false
+	}
+	return state.clone(), true
+}
+
+// Snapshot writes changed city states to storage. States that failed to save stay dirty.
+func (cs *CityStore) Snapshot() error {
+	if cs.storage == nil {
+		return nil
+	}
+
+	cs.mu.Lock()
+	pending := make(map[string][]byte, len(cs.dirty))
+	for key := range cs.dirty {
+		data, err := json.Marshal(cs.cities[key])
+		if err != nil {
+			logging.Errorf("Failed to encode city state %s: %v", key, err)
+			continue
+		}
+		pending[key] = data
+	}
+	cs.dirty = make(map[string]bool)
+	cs.mu.Unlock()
+
+	var firstErr error
+	for key, data := range pending {
+		if err := cs.storage.PutObject(cityStatesBucket, key+".json", bytes.NewReader(data), int64(len(data))); err != nil {
+			cs.mu.Lock()
+			cs.dirty[key] = true
+			cs.mu.Unlock()
+			if firstErr == nil {
+				firstErr = fmt.Errorf("save city state %s: %w", key, err)
+			}
+		}
+	}
+	return firstErr
+}
+
+// Run snapshots city states every interval and once more when ctx is cancelled.
+func (cs *CityStore) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultSnapshotInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if err := cs.Snapshot(); err != nil {
+				logging.Errorf("Final city state snapshot failed: %v", err)
+			}
+			return
+		case <-ticker.C:
+			if err := cs.Snapshot(); err != nil {
+				logging.Errorf("City state snapshot failed: %v", err)
+			}
+		}
+	}
+}
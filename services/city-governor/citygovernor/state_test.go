@@ -0,0 +1,81 @@
+package citygovernor
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"multiverse-core.io/shared/minio/miniotest"
+)
+
+func TestCityStateBounds(t *testing.T) {
+	store := NewCityStore()
+
+	state := store.Update("world-1", "city-1", func(state *CityState) {
+		state.adjustReputation(80)
+		state.adjustPopulation(-5)
+	})
+	if state.Reputation != maxReputation || state.Population != 0 {
+		t.Errorf("expected clamped reputation %d and population 0, got %d and %d", maxReputation, state.Reputation, state.Population)
+	}
+
+	state = store.Update("world-1", "city-1", func(state *CityState) { state.adjustReputation(-500) })
+	if state.Reputation != minReputation {
+		t.Errorf("expected reputation %d, got %d", minReputation, state.Reputation)
+	}
+
+	// Returned states are copies
+	state.Visitors["player-1"] = time.Now()
+	if current, _ := store.Get("world-1", "city-1"); len(current.Visitors) != 0 {
+		t.Errorf("modifying a returned state must not change the store")
+	}
+	if _, ok := store.Get("world-2", "city-1"); ok {
+		t.Errorf("cities must be separated by world")
+	}
+}
+
+func TestCityStoreSnapshotAndLoad(t *testing.T) {
+	client := miniotest.New()
+	store := NewCityStore()
+	store.UseStorage(client)
+
+	store.Update("world-1", "city-1", func(state *CityState) {
+		state.Name = "Вельград"
+		state.adjustReputation(-20)
+		state.adjustPopulation(120)
+		state.Visitors["player-1"] = time.Now().UTC()
+		state.ActiveQuests["quest-1"] = CityQuest{PlayerID: "player-1", Type: "welcome"}
+		state.CompletedQuests = 2
+	})
+
+	// Failed writes are retried on the next snapshot
+	client.FailPuts(errors.New("storage unavailable"))
+	if err := store.Snapshot(); err == nil {
+		t.Fatalf("expected snapshot error")
+	}
+	client.FailPuts(nil)
+	if err := store.Snapshot(); err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	if _, err := client.GetObject(cityStatesBucket, "world-1/city-1.json"); err != nil {
+		t.Fatalf("snapshot not written: %v", err)
+	}
+
+	client.Put(cityStatesBucket, "world-1/broken.json", "{")
+
+	restored := NewCityStore()
+	restored.UseStorage(client)
+	if err := restored.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	state, ok := restored.Get("world-1", "city-1")
+	if !ok {
+		t.Fatalf("city state not restored")
+	}
+	if state.Name != "Вельград" || state.Reputation != 30 || state.Population != 120 || state.CompletedQuests != 2 {
+		t.Errorf("unexpected restored state: %+v", state)
+	}
+	if _, ok := state.Visitors["player-1"]; !ok || state.ActiveQuests["quest-1"].Type != "welcome" {
+		t.Errorf("visitors and quests not restored: %+v", state)
+	}
+}
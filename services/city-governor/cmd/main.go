@@ -2,43 +2,52 @@
 package main
 
 import (
-	"context"
-	"log"
-	"os"
-	"os/signal"
-	"strings"
-	"syscall"
-
-	"multiverse-core.io/shared/eventbus"
 	"multiverse-core.io/services/city-governor/citygovernor"
+	"multiverse-core.io/shared/archivist"
+	"multiverse-core.io/shared/config"
+	"multiverse-core.io/shared/logging"
+	"multiverse-core.io/shared/oracle"
+	"multiverse-core.io/shared/registry"
+	"multiverse-core.io/shared/service"
 )
 
 func main() {
-	// Initialize event bus
-	brokers := strings.Split(os.Getenv("KAFKA_BROKERS"), ",")
-	if len(brokers) == 0 || brokers[0] == "" {
-		brokers = []string{"redpanda:9092"}
-	}
-	bus := eventbus.NewEventBus(brokers)
-
-	// Create and run service
-	service := citygovernor.NewService(bus)
+	app := service.Setup("city-governor", config.OracleOptions, []config.Option{
+		{Env: "CITY_SNAPSHOT_INTERVAL", Default: "1m", Type: config.TypeDuration, Positive: true, Usage: "interval between city state snapshots to MinIO"},
+		{Env: "ECONOMY_TIME_SCALE", Default: "60", Type: config.TypeFloat, Positive: true, Usage: "world seconds per real second in the city economy and quest deadlines"},
+		{Env: "QUEST_ORACLE_ENABLED", Default: "true", Type: config.TypeBool, Usage: "generate quests with the Oracle (false uses template quests only)"},
+		{Env: "NPC_DIALOGUE_ORACLE_ENABLED", Default: "true", Type: config.TypeBool, Usage: "generate NPC responses with the Oracle (false uses a fixed line)"},
+		{Env: "ARCHIVIST_URL", Type: config.TypeURL, Usage: "fallback archivist address"},
+		{Env: "SEMANTIC_MEMORY_URL", Type: config.TypeURL, Usage: "fallback semantic memory address"},
+	})
+	env := app.Env
+	app.UseOracleAudit()
 
-	// Handle shutdown
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	governor := citygovernor.NewService(app.Bus())
+	governor.SetEconomyTimeScale(env.Float("ECONOMY_TIME_SCALE"))
 
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	go func() {
-		<-sigChan
-		log.Println("Shutting down CityGovernor...")
-		cancel()
-	}()
+	// City state persistence (optional: without MinIO the state lives in memory)
+	minioClient, err := app.MinIO()
+	if err != nil {
+		logging.Warnf("MinIO unavailable, city state is kept in memory only: %v", err)
+	} else {
+		governor.UseStateStorage(minioClient, env.Duration("CITY_SNAPSHOT_INTERVAL"))
+	}
 
-	log.Println("CityGovernor starting...")
-	if err := service.Run(ctx); err != nil && err != context.Canceled {
-		log.Fatal("Service failed:", err)
+	// Quests are written by the Oracle from the archivist quest schema and player history
+	// (addresses through the service registry); template quests are the fallback
+	discovery := registry.NewDiscovery(app.Bus(), "city-governor")
+	memory := citygovernor.NewSemanticMemoryClient(env.String("SEMANTIC_MEMORY_URL"), discovery)
+	if env.Bool("QUEST_ORACLE_ENABLED") {
+		governor.UseQuestGeneration(oracle.NewClient(),
+			archivist.NewClient(env.String("ARCHIVIST_URL"), discovery), memory)
+	}
+	// NPC responses are written by the Oracle from the NPC entity, city state, player history
+	// and the NPC's memory of recent conversations
+	if env.Bool("NPC_DIALOGUE_ORACLE_ENABLED") {
+		governor.UseDialogueGeneration(oracle.NewClient(), memory)
 	}
-	log.Println("CityGovernor stopped.")
+	app.Go(discovery.Run)
+
+	app.Run(governor)
 }
@@ -0,0 +1,40 @@
+// Package main is the entry point for CombatResolver.
+package main
+
+import (
+	"multiverse-core.io/services/combat-resolver/combatresolver"
+	"multiverse-core.io/shared/archivist"
+	"multiverse-core.io/shared/config"
+	"multiverse-core.io/shared/logging"
+	"multiverse-core.io/shared/registry"
+	"multiverse-core.io/shared/service"
+)
+
+func main() {
+	app := service.Setup("combat-resolver", []config.Option{
+		{Env: "ARCHIVIST_URL", Type: config.TypeURL, Usage: "fallback archivist address"},
+		{Env: "COMBAT_RULES_FROM_ARCHIVIST", Default: "true", Type: config.TypeBool, Usage: "load combat rules from world ontology profiles (false uses built-in rules only)"},
+	})
+	env := app.Env
+
+	resolver := combatresolver.NewService(app.Bus())
+
+	// Combatants and skills are read from their entities, which EntityManager stores in MinIO
+	// (without MinIO every target is unknown and no attack is resolved)
+	minioClient, err := app.MinIO()
+	if err != nil {
+		logging.Warnf("MinIO unavailable, attacks are not resolved: %v", err)
+	} else {
+		resolver.UseEntityStorage(minioClient)
+	}
+
+	// Combat rules come from the world ontology profiles in the archivist
+	// (address through the service registry); built-in rules are the fallback
+	discovery := registry.NewDiscovery(app.Bus(), "combat-resolver")
+	if env.Bool("COMBAT_RULES_FROM_ARCHIVIST") {
+		resolver.UseArchivist(archivist.NewClient(env.String("ARCHIVIST_URL"), discovery))
+	}
+	app.Go(discovery.Run)
+
+	app.Run(resolver)
+}
@@ -0,0 +1,242 @@
+package combatresolver
+
+import (
+	"encoding/json"
+	"slices"
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/entity"
+	"multiverse-core.io/shared/jsonpath"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+)
+
+// Entity caching: health changes with every fight, skills rarely change.
+const (
+	combatantTTL = 30 * time.Second
+	skillTTL     = 10 * time.Minute
+)
+
+// Combatant is the combat view of an entity. Stats are read from the payload field
+// or from the same field under "stats" (hp or stats.hp).
+type Combatant struct {
+	ID       string
+	Type     string
+	HP       float64
+	MaxHP    float64
+	Attack   float64
+	Defense  float64
+	Accuracy float64
+	Evasion  float64
+	Element  string
+	// Resistances reduce the damage of an element by a share in [0, 1]
+	Resistances map[string]float64
+	// hpPath is the payload path hp was read from and is stored back to; empty when the entity has no hp
+	hpPath string
+}
+
+// stat reads a numeric stat of the payload and returns the path it was found at.
+func stat(pa *jsonpath.Accessor, name string) (float64, string, bool) {
+	for _, path := range []string{name, "stats." + name} {
+		if value, ok := pa.GetFloat(path); ok {
+			return value, path, true
+		}
+	}
+	return 0, "", false
+}
+
+// decodeCombatant reads the combat stats of an entity.
+func decodeCombatant(ent entity.Entity) Combatant {
+	pa := jsonpath.New(ent.Payload)
+	c := Combatant{ID: ent.ID, Type: ent.Type}
+	if hp, path, ok := stat(pa, "hp"); ok {
+		c.HP, c.hpPath = hp, path
+		c.MaxHP = hp
+		if maxHP, _, ok := stat(pa, "max_hp"); ok && maxHP > 0 {
+			c.MaxHP = maxHP
+		}
+	}
+	c.Attack, _, _ = stat(pa, "attack")
+	c.Defense, _, _ = stat(pa, "defense")
+	c.Accuracy, _, _ = stat(pa, "accuracy")
+	c.Evasion, _, _ = stat(pa, "evasion")
+	c.Element, _ = pa.GetString("element")
+	if resistances, ok := pa.GetMap("resistances"); ok {
+		c.Resistances = make(map[string]float64, len(resistances))
+		for element, value := range resistances {
+			if share, ok := value.(float64); ok {
+				c.Resistances[element] = share
+			}
+		}
+	}
+	return c
+}
+
+// Effect is a status effect a skill applies on hit.
+type Effect struct {
+	Type      string  `json:"type"`
+	Magnitude float64 `json:"magnitude,omitempty"`
+	// DurationSeconds is how long the effect lasts; zero lasts until another service removes it
+	DurationSeconds float64 `json:"duration_seconds,omitempty"`
+	// Chance is the probability to apply the effect on hit; zero always applies it
+	Chance float64 `json:"chance,omitempty"`
+}
+
+// Skill is the combat view of a skill entity; the zero Skill with an ID is a basic attack.
+type Skill struct {
+	ID string
+	// Power is the damage of the skill before attack and modifiers; zero uses the base damage of the world
+	Power    float64
+	Element  string
+	Accuracy float64
+	Tags     []string
+	Effects  []Effect
+}
+
+// support reports whether the skill helps its target instead of attacking it.
+func (s Skill) support() bool {
+	return slices.Contains(s.Tags, "support") || slices.Contains(s.Tags, "healing")
+}
+
+// decodeSkill reads the combat fields of a skill entity: power (or damage), element, accuracy, tags and effects.
+func decodeSkill(ent entity.Entity) Skill {
+	pa := jsonpath.New(ent.Payload)
+	skill := Skill{ID: ent.ID}
+	if power, ok := pa.GetFloat("power"); ok {
+		skill.Power = power
+	} else {
+		skill.Power, _ = pa.GetFloat("damage")
+	}
+	skill.Element, _ = pa.GetString("element")
+	skill.Accuracy, _ = pa.GetFloat("accuracy")
+	if tags, ok := pa.GetSlice("tags"); ok {
+		for _, tag := range tags {
+			if s, ok := tag.(string); ok {
+				skill.Tags = append(skill.Tags, s)
+			}
+		}
+	}
+	if effects, ok := ent.Payload["effects"]; ok {
+		encoded, _ := json.Marshal(effects)
+		if err := json.Unmarshal(encoded, &skill.Effects); err != nil {
+			logging.Warnf("Ignoring invalid effects of skill %s: %v", ent.ID, err)
+			skill.Effects = nil
+		}
+	}
+	return skill
+}
+
+type cachedCombatant struct {
+	combatant Combatant
+	expiresAt time.Time
+}
+
+type cachedSkill struct {
+	skill     Skill
+	found     bool
+	expiresAt time.Time
+}
+
+// CombatantStore reads combatants and skills from their entities in MinIO, which EntityManager
+// keeps up to date from state_changes. Health changed by resolved attacks is kept in memory
+// until the entity is read again, so consecutive hits add up before EntityManager stores them.
+type CombatantStore struct {
+	storage storage.ObjectStorage // nil — every entity is unknown
+
+	mu         sync.Mutex
+	combatants map[string]cachedCombatant
+	skills     map[string]cachedSkill
+}
+
+// NewCombatantStore creates an empty store; see UseStorage for reading entities.
+func NewCombatantStore() *CombatantStore {
+	return &CombatantStore{
+		combatants: make(map[string]cachedCombatant),
+		skills:     make(map[string]cachedSkill),
+	}
+}
+
+// UseStorage enables reading entities from the entity buckets.
+func (st *CombatantStore) UseStorage(client storage.ObjectStorage) {
+	st.storage = client
+}
+
+// Combatant returns the combatant of an entity; false when the entity is unknown.
+func (st *CombatantStore) Combatant(worldID, id string) (Combatant, bool) {
+	return st.Update(worldID, id, func(*Combatant) {})
+}
+
+// Update applies fn to the combatant under the store lock and returns a copy of the result;
+// false when the entity is unknown.
+func (st *CombatantStore) Update(worldID, id string, fn func(c *Combatant)) (Combatant, bool) {
+	key := worldID + "/" + id
+	st.mu.Lock()
+	cached, ok := st.combatants[key]
+	st.mu.Unlock()
+	if !ok || time.Now().After(cached.expiresAt) {
+		ent, found := st.load(worldID, id)
+		if !found {
+			return Combatant{}, false
+		}
+		cached = cachedCombatant{combatant: decodeCombatant(ent)}
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if current, ok := st.combatants[key]; ok && time.Now().Before(current.expiresAt) {
+		// Loaded concurrently by another event
+		cached = current
+	}
+	fn(&cached.combatant)
+	cached.expiresAt = time.Now().Add(combatantTTL)
+	st.combatants[key] = cached
+	return cached.combatant, true
+}
+
+// Skill returns the skill entity; false when the skill has no entity.
+func (st *CombatantStore) Skill(worldID, id string) (Skill, bool) {
+	key := worldID + "/" + id
+	st.mu.Lock()
+	cached, ok := st.skills[key]
+	st.mu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.skill, cached.found
+	}
+
+	cached = cachedSkill{skill: Skill{ID: id}, expiresAt: time.Now().Add(skillTTL)}
+	if ent, found := st.load(worldID, id); found {
+		cached.skill, cached.found = decodeSkill(ent), true
+	}
+	st.mu.Lock()
+	st.skills[key] = cached
+	st.mu.Unlock()
+	return cached.skill, cached.found
+}
+
+// load reads the entity of the world, falling back to the global entities.
+func (st *CombatantStore) load(worldID, id string) (entity.Entity, bool) {
+	if st.storage == nil {
+		return entity.Entity{}, false
+	}
+	for _, bucket := range []string{"entities-" + worldID, "entities-global"} {
+		data, err := st.storage.GetObject(bucket, id+".json")
+		if err != nil {
+			if !storage.IsNotFound(err) {
+				logging.Errorf("Failed to load entity %s: %v", id, err)
+				return entity.Entity{}, false
+			}
+			continue
+		}
+		var ent entity.Entity
+		if err := json.Unmarshal(data, &ent); err != nil {
+			logging.Warnf("Ignoring invalid entity %s: %v", id, err)
+			return entity.Entity{}, false
+		}
+		if ent.ID == "" {
+			ent.ID = id
+		}
+		return ent, true
+	}
+	return entity.Entity{}, false
+}
@@ -0,0 +1,99 @@
+package combatresolver
+
+import (
+	"hash/fnv"
+	"math"
+	"math/rand"
+)
+
+// Hit chance bounds: every attack can miss and every attack can hit.
+const (
+	minHitChance = 0.05
+	maxHitChance = 0.99
+)
+
+// Attack is an attack of Attacker on Target with Skill.
+type Attack struct {
+	Attacker Combatant
+	Target   Combatant
+	Skill    Skill
+}
+
+// Outcome is the resolved attack.
+type Outcome struct {
+	Hit        bool    `json:"hit"`
+	Critical   bool    `json:"critical"`
+	HitChance  float64 `json:"hit_chance"`
+	Damage     float64 `json:"damage"`
+	DamageType string  `json:"damage_type"`
+	// TargetHP is the health of the target after the attack
+	TargetHP    float64  `json:"target_hp"`
+	TargetMaxHP float64  `json:"target_max_hp"`
+	Defeated    bool     `json:"defeated"`
+	Effects     []Effect `json:"effects,omitempty"`
+}
+
+// resolve resolves an attack with the world rules. roll returns uniform numbers in [0, 1)
+// and is called in a fixed order (hit, critical, then every effect), so the same rolls
+// always give the same outcome.
+func resolve(rules CombatRules, attack Attack, roll func() float64) Outcome {
+	attacker, target, skill := attack.Attacker, attack.Target, attack.Skill
+	outcome := Outcome{
+		DamageType:  damageType(attacker, skill),
+		TargetHP:    target.HP,
+		TargetMaxHP: target.MaxHP,
+	}
+
+	outcome.HitChance = math.Min(math.Max(rules.HitChance+skill.Accuracy+attacker.Accuracy-target.Evasion, minHitChance), maxHitChance)
+	if roll() >= outcome.HitChance {
+		return outcome
+	}
+	outcome.Hit = true
+
+	power := skill.Power
+	if power <= 0 {
+		power = rules.BaseDamage
+	}
+	damage := power + attacker.Attack
+	damage *= rules.elementMultiplier(outcome.DamageType, target.Element)
+	damage *= 1 - math.Min(math.Max(target.Resistances[outcome.DamageType], 0), 1)
+	damage *= 100 / (100 + math.Max(target.Defense, 0)*rules.DefenseFactor)
+	if roll() < rules.CritChance {
+		outcome.Critical = true
+		damage *= rules.CritMultiplier
+	}
+	outcome.Damage = math.Max(math.Round(damage), rules.MinDamage)
+
+	outcome.TargetHP = math.Max(target.HP-outcome.Damage, 0)
+	outcome.Defeated = outcome.TargetHP == 0
+
+	for _, effect := range skill.Effects {
+		if effect.Type == "" {
+			continue
+		}
+		if effect.Chance > 0 && roll() >= effect.Chance {
+			continue
+		}
+		outcome.Effects = append(outcome.Effects, effect)
+	}
+	return outcome
+}
+
+// damageType is the element of the skill, then of the attacker; physical without either.
+func damageType(attacker Combatant, skill Skill) string {
+	switch {
+	case skill.Element != "":
+		return skill.Element
+	case attacker.Element != "":
+		return attacker.Element
+	default:
+		return "physical"
+	}
+}
+
+// eventRoll returns rolls seeded by the seed, so resolving the same event again gives the same outcome.
+func eventRoll(seed string) func() float64 {
+	h := fnv.New64a()
+	h.Write([]byte(seed))
+	return rand.New(rand.NewSource(int64(h.Sum64()))).Float64
+}
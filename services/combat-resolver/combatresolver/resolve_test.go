@@ -0,0 +1,84 @@
+package combatresolver
+
+import (
+	"reflect"
+	"testing"
+)
+
+// rolls returns the given rolls in order, then 0.
+func rolls(values ...float64) func() float64 {
+	return func() float64 {
+		if len(values) == 0 {
+			return 0
+		}
+		v := values[0]
+		values = values[1:]
+		return v
+	}
+}
+
+func TestResolveDamage(t *testing.T) {
+	attack := Attack{
+		Attacker: Combatant{ID: "player:kain", Attack: 5},
+		Target:   Combatant{ID: "npc:frost-wolf", HP: 50, MaxHP: 50, Defense: 25, Element: "ice"},
+		Skill:    Skill{ID: "fire_breath", Power: 15, Element: "fire"},
+	}
+
+	// (15 + 5) * 1.5 (fire → ice) * 100 / 125 = 24
+	outcome := resolve(defaultRules, attack, rolls(0.5, 0.5))
+	if !outcome.Hit || outcome.Critical || outcome.Damage != 24 || outcome.TargetHP != 26 || outcome.DamageType != "fire" {
+		t.Fatalf("unexpected outcome %+v", outcome)
+	}
+
+	critical := resolve(defaultRules, attack, rolls(0.5, 0.01))
+	if !critical.Critical || critical.Damage != 36 {
+		t.Errorf("expected a critical hit for 36, got %+v", critical)
+	}
+
+	miss := resolve(defaultRules, attack, rolls(0.95))
+	if miss.Hit || miss.Damage != 0 || miss.TargetHP != 50 {
+		t.Errorf("expected a miss above the hit chance, got %+v", miss)
+	}
+
+	attack.Target.HP = 10
+	attack.Target.Resistances = map[string]float64{"fire": 0.5}
+	if defeated := resolve(defaultRules, attack, rolls(0.5, 0.5)); !defeated.Defeated || defeated.TargetHP != 0 || defeated.Damage != 12 {
+		t.Errorf("expected the resisted hit to defeat the target, got %+v", defeated)
+	}
+}
+
+func TestResolveEffectsAndBounds(t *testing.T) {
+	attack := Attack{
+		Attacker: Combatant{ID: "npc:bandit"},
+		Target:   Combatant{ID: "player:kain", HP: 100, MaxHP: 100, Evasion: 2},
+		Skill: Skill{ID: "poison_blade", Effects: []Effect{
+			{Type: "poison", Magnitude: 3, DurationSeconds: 30},
+			{Type: "stun", Chance: 0.2},
+		}},
+	}
+
+	// Evasion cannot make an attack impossible to hit
+	outcome := resolve(defaultRules, attack, rolls(0.01, 0.5, 0.5))
+	if outcome.HitChance != minHitChance || !outcome.Hit {
+		t.Fatalf("expected a hit at the minimum chance, got %+v", outcome)
+	}
+	if outcome.Damage != defaultRules.BaseDamage || outcome.DamageType != "physical" {
+		t.Errorf("expected base physical damage, got %+v", outcome)
+	}
+	if len(outcome.Effects) != 1 || outcome.Effects[0].Type != "poison" {
+		t.Errorf("expected only the guaranteed effect, got %+v", outcome.Effects)
+	}
+}
+
+func TestEventRollDeterministic(t *testing.T) {
+	attack := Attack{
+		Attacker: Combatant{ID: "player:kain", Attack: 3},
+		Target:   Combatant{ID: "npc:bandit", HP: 40, MaxHP: 40},
+		Skill:    Skill{ID: "slash", Power: 8, Effects: []Effect{{Type: "bleed", Chance: 0.5}}},
+	}
+	first := resolve(defaultRules, attack, eventRoll("ev-1/npc:bandit"))
+	again := resolve(defaultRules, attack, eventRoll("ev-1/npc:bandit"))
+	if !reflect.DeepEqual(first, again) {
+		t.Errorf("the same event must resolve the same way: %+v vs %+v", first, again)
+	}
+}
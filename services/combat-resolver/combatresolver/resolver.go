@@ -0,0 +1,237 @@
+// Package combatresolver resolves attacks deterministically from entity stats and world combat rules.
+package combatresolver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/eventbus/events"
+	"multiverse-core.io/shared/logging"
+
+	"github.com/google/uuid"
+)
+
+// source is the source of the events CombatResolver publishes.
+const source = "combat-resolver"
+
+// EventCombatResult carries the mechanics of a resolved attack for the narrator and
+// the state_changes of the target for EntityManager.
+const EventCombatResult = "combat.result"
+
+// attackEvents are the events resolved as attacks when they have a target.
+var attackEvents = map[string]bool{
+	events.TypePlayerUsedSkill: true,
+	"npc.attack":               true,
+	"npc.attacked_player":      true,
+	"npc.used_skill":           true,
+}
+
+// CombatResolver resolves attacks and publishes combat.result.
+type CombatResolver struct {
+	rules      *Rules
+	combatants *CombatantStore
+	// publish sends an event to world_events; replaced in tests
+	publish func(ctx context.Context, event eventbus.Event) error
+}
+
+// NewCombatResolver creates a new CombatResolver with the default rules.
+func NewCombatResolver(bus *eventbus.EventBus) *CombatResolver {
+	return &CombatResolver{
+		rules:      NewRules(),
+		combatants: NewCombatantStore(),
+		publish:    bus.PublishWorldEvent,
+	}
+}
+
+// HandleEvent resolves attack events.
+func (cr *CombatResolver) HandleEvent(ev eventbus.Event) {
+	if !attackEvents[ev.Type] || ev.Source == source {
+		return
+	}
+	attackerID, targetID := eventAttacker(ev), eventTarget(ev)
+	if attackerID == "" || targetID == "" || attackerID == targetID {
+		return // Not an attack on someone else
+	}
+	worldID := eventbus.GetWorldIDFromEvent(ev)
+	rules := cr.rules.For(worldID)
+
+	// Attackers without an entity (e.g. invented by the narrator) attack with base stats
+	attacker, ok := cr.combatants.Combatant(worldID, attackerID)
+	if !ok {
+		attacker = Combatant{ID: attackerID}
+	}
+	var skill Skill
+	if skillID := eventSkill(ev); skillID != "" {
+		skill, _ = cr.combatants.Skill(worldID, skillID)
+		if skill.support() {
+			return
+		}
+	}
+
+	var outcome Outcome
+	var resolved bool
+	var defaultHP bool
+	target, ok := cr.combatants.Update(worldID, targetID, func(target *Combatant) {
+		if target.hpPath == "" {
+			target.HP, target.MaxHP, target.hpPath = rules.DefaultHP, rules.DefaultHP, "hp"
+			defaultHP = true
+		}
+		if target.HP <= 0 {
+			return
+		}
+		outcome = resolve(rules, Attack{Attacker: attacker, Target: *target, Skill: skill}, eventRoll(ev.ID+"/"+targetID))
+		target.HP = outcome.TargetHP
+		resolved = true
+	})
+	switch {
+	case !ok:
+		logging.Warnf("Ignoring attack of %s on unknown target %s in %s", attackerID, targetID, worldID)
+		return
+	case !resolved:
+		logging.Infof("Ignoring attack of %s on defeated %s", attackerID, targetID)
+		return
+	}
+
+	cr.publishResult(ev, worldID, attacker, target, skill, outcome, defaultHP)
+}
+
+// publishResult publishes combat.result in the scope of the attack. Hits carry the target
+// changes in state_changes: health, defeat and applied effects.
+func (cr *CombatResolver) publishResult(cause eventbus.Event, worldID string, attacker, target Combatant, skill Skill, outcome Outcome, defaultHP bool) {
+	payload := eventbus.NewEventPayload().
+		WithEntity(attacker.ID, attacker.Type, "").
+		WithTarget(target.ID, target.Type, "").
+		WithWorld(worldID)
+
+	eventbus.SetNested(payload.GetCustom(), "skill", skill.ID)
+	eventbus.SetNested(payload.GetCustom(), "hit", outcome.Hit)
+	eventbus.SetNested(payload.GetCustom(), "critical", outcome.Critical)
+	eventbus.SetNested(payload.GetCustom(), "hit_chance", outcome.HitChance)
+	eventbus.SetNested(payload.GetCustom(), "damage", outcome.Damage)
+	eventbus.SetNested(payload.GetCustom(), "damage_type", outcome.DamageType)
+	eventbus.SetNested(payload.GetCustom(), "target_hp", outcome.TargetHP)
+	eventbus.SetNested(payload.GetCustom(), "target_max_hp", outcome.TargetMaxHP)
+	eventbus.SetNested(payload.GetCustom(), "defeated", outcome.Defeated)
+	eventbus.SetNested(payload.GetCustom(), "effects", effectsValue(outcome.Effects))
+	eventbus.SetNested(payload.GetCustom(), "original_event", cause.ID)
+	// The narrator describes the outcome instead of inventing it
+	eventbus.SetNested(payload.GetCustom(), "description", describe(attacker.ID, target.ID, skill.ID, outcome))
+	if outcome.Hit {
+		appliedAt := cause.Timestamp
+		if appliedAt.IsZero() {
+			appliedAt = time.Now()
+		}
+		eventbus.SetNested(payload.GetCustom(), "state_changes", stateChanges(target, attacker.ID, skill.ID, outcome, defaultHP, appliedAt.UTC()))
+	}
+
+	resultEvent := eventbus.NewStructuredEvent(EventCombatResult, source, worldID, payload).CausedBy(cause)
+	resultEvent.ID = "combat-" + uuid.New().String()[:8]
+	resultEvent.Timestamp = time.Now()
+	resultEvent.Scope = eventbus.GetScopeFromEvent(cause)
+
+	if err := cr.publish(context.Background(), resultEvent); err != nil {
+		logging.Errorf("Failed to publish combat result of %s: %v", cause.ID, err)
+		return
+	}
+	logging.Infof("Combat in %s: %s", worldID, describe(attacker.ID, target.ID, skill.ID, outcome))
+}
+
+// stateChanges builds the state_changes that make EntityManager store the outcome in the target entity.
+func stateChanges(target Combatant, attackerID, skillID string, outcome Outcome, defaultHP bool, appliedAt time.Time) []interface{} {
+	operations := []interface{}{
+		map[string]interface{}{"op": "set", "path": target.hpPath, "value": outcome.TargetHP},
+	}
+	if defaultHP {
+		operations = append(operations, map[string]interface{}{"op": "set", "path": "max_hp", "value": outcome.TargetMaxHP})
+	}
+	if outcome.Defeated {
+		operations = append(operations, map[string]interface{}{"op": "set", "path": "defeated", "value": true})
+	}
+	for _, effect := range outcome.Effects {
+		value := map[string]interface{}{
+			"magnitude":  effect.Magnitude,
+			"source":     attackerID,
+			"applied_at": appliedAt.Format(time.RFC3339),
+		}
+		if skillID != "" {
+			value["skill"] = skillID
+		}
+		if effect.DurationSeconds > 0 {
+			value["expires_at"] = appliedAt.Add(time.Duration(effect.DurationSeconds * float64(time.Second))).Format(time.RFC3339)
+		}
+		operations = append(operations, map[string]interface{}{"op": "set", "path": "effects." + effect.Type, "value": value})
+	}
+	return []interface{}{
+		map[string]interface{}{"entity_id": target.ID, "operations": operations},
+	}
+}
+
+// effectsValue lists the applied effects as they appear in combat.result.
+func effectsValue(effects []Effect) []interface{} {
+	list := make([]interface{}, 0, len(effects))
+	for _, effect := range effects {
+		list = append(list, map[string]interface{}{
+			"type":             effect.Type,
+			"magnitude":        effect.Magnitude,
+			"duration_seconds": effect.DurationSeconds,
+		})
+	}
+	return list
+}
+
+// describe is a short factual summary of the outcome for the narrator.
+func describe(attackerID, targetID, skillID string, outcome Outcome) string {
+	with := ""
+	if skillID != "" {
+		with = " (" + skillID + ")"
+	}
+	if !outcome.Hit {
+		return fmt.Sprintf("%s промахивается по %s%s", attackerID, targetID, with)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s наносит %s %.0f урона (%s)%s", attackerID, targetID, outcome.Damage, outcome.DamageType, with)
+	if outcome.Critical {
+		b.WriteString(", критический удар")
+	}
+	for _, effect := range outcome.Effects {
+		fmt.Fprintf(&b, ", эффект %s", effect.Type)
+	}
+	if outcome.Defeated {
+		fmt.Fprintf(&b, "; %s повержен", targetID)
+	} else {
+		fmt.Fprintf(&b, "; осталось %.0f/%.0f здоровья", outcome.TargetHP, outcome.TargetMaxHP)
+	}
+	return b.String()
+}
+
+// eventAttacker extracts the attacker: entity.id → player_id.
+func eventAttacker(ev eventbus.Event) string {
+	if entityInfo, ok := ev.GetEntityIDWithFallback(); ok {
+		return entityInfo.ID
+	}
+	playerID, _ := ev.Path().GetString("player_id")
+	return playerID
+}
+
+// eventTarget extracts the target: target.entity.id → target_id, or target as a plain ID.
+func eventTarget(ev eventbus.Event) string {
+	if targetInfo, ok := ev.GetTargetEntityID(); ok {
+		return targetInfo.ID
+	}
+	target, _ := ev.Payload["target"].(string)
+	return target
+}
+
+// eventSkill extracts the skill: skill_id (GameService commands) → skill → skill.id → action.skill.
+func eventSkill(ev eventbus.Event) string {
+	pa := ev.Path()
+	for _, path := range []string{"skill_id", "skill", "skill.id", "action.skill"} {
+		if skill, ok := pa.GetString(path); ok && skill != "" {
+			return skill
+		}
+	}
+	return ""
+}
@@ -0,0 +1,126 @@
+package combatresolver
+
+import (
+	"context"
+	"testing"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/minio/miniotest"
+)
+
+func newTestResolver() (*CombatResolver, *[]eventbus.Event) {
+	storage := miniotest.New()
+	storage.Put("entities-ash-realm", "player:kain.json", `{"id": "player:kain", "type": "player", "payload": {"stats": {"hp": 80, "attack": 5}}}`)
+	storage.Put("entities-ash-realm", "npc:wolf.json", `{"id": "npc:wolf", "type": "npc", "payload": {"hp": 30, "max_hp": 40, "element": "ice"}}`)
+	storage.Put("entities-ash-realm", "npc:shade.json", `{"id": "npc:shade", "type": "npc", "payload": {"name": "Тень"}}`)
+	storage.Put("entities-global", "fire_breath.json", `{"id": "fire_breath", "type": "skill", "payload": {"power": 15, "element": "fire",
+		"effects": [{"type": "burn", "magnitude": 2, "duration_seconds": 10}]}}`)
+	storage.Put("entities-global", "mend.json", `{"id": "mend", "type": "skill", "payload": {"tags": ["healing"]}}`)
+
+	var published []eventbus.Event
+	resolver := NewCombatResolver(nil)
+	resolver.combatants.UseStorage(storage)
+	rules := defaultRules
+	rules.HitChance, rules.CritChance = 1, 0 // every attack hits without criticals
+	resolver.rules.worlds.Set("ash-realm", rules)
+	resolver.publish = func(ctx context.Context, event eventbus.Event) error {
+		published = append(published, event)
+		return nil
+	}
+	return resolver, &published
+}
+
+func skillEvent(id, skill, targetID string) eventbus.Event {
+	payload := eventbus.NewEventPayload().
+		WithEntity("player:kain", "player", "").
+		WithTarget(targetID, "", "").
+		WithWorld("ash-realm").
+		WithScope("player:kain", "solo")
+	eventbus.SetNested(payload.GetCustom(), "skill_id", skill)
+	ev := eventbus.NewStructuredEvent("player.used_skill", "game-service", "ash-realm", payload)
+	ev.ID = id
+	return ev
+}
+
+// operations returns the state_changes operations of a combat result by path.
+func operations(t *testing.T, ev eventbus.Event) map[string]interface{} {
+	t.Helper()
+	changes, ok := ev.Payload["state_changes"].([]interface{})
+	if !ok || len(changes) != 1 {
+		t.Fatalf("expected state_changes of the target, got %v", ev.Payload["state_changes"])
+	}
+	ops := make(map[string]interface{})
+	for _, op := range changes[0].(map[string]interface{})["operations"].([]interface{}) {
+		m := op.(map[string]interface{})
+		ops[m["path"].(string)] = m["value"]
+	}
+	return ops
+}
+
+func TestCombatResolverSkill(t *testing.T) {
+	resolver, published := newTestResolver()
+
+	// (15 + 5) * 1.5 (fire → ice) = 30: the wolf is defeated
+	resolver.HandleEvent(skillEvent("ev-1", "fire_breath", "npc:wolf"))
+	if len(*published) != 1 {
+		t.Fatalf("expected one combat result, got %d", len(*published))
+	}
+	result := (*published)[0]
+	pa := result.Path()
+	if damage, _ := pa.GetFloat("damage"); damage != 30 {
+		t.Errorf("expected 30 damage, got %v", damage)
+	}
+	if defeated, _ := pa.GetBool("defeated"); !defeated {
+		t.Error("expected the wolf to be defeated")
+	}
+	if target, _ := result.GetTargetEntityID(); target == nil || target.ID != "npc:wolf" || target.Type != "npc" {
+		t.Errorf("expected the wolf as target, got %+v", target)
+	}
+	if result.CausationID != "ev-1" || result.Scope == nil || result.Scope.ID != "player:kain" {
+		t.Errorf("expected the result in the scope of the attack, got %+v", result)
+	}
+	ops := operations(t, result)
+	if ops["hp"] != 0.0 || ops["defeated"] != true || ops["effects.burn"] == nil {
+		t.Errorf("unexpected state changes %v", ops)
+	}
+
+	// Defeated targets are not attacked again
+	resolver.HandleEvent(skillEvent("ev-2", "fire_breath", "npc:wolf"))
+	if len(*published) != 1 {
+		t.Errorf("expected no result for a defeated target, got %d", len(*published))
+	}
+}
+
+func TestCombatResolverTargets(t *testing.T) {
+	resolver, published := newTestResolver()
+
+	// Unknown targets, healing skills and own events are not resolved
+	resolver.HandleEvent(skillEvent("ev-1", "fire_breath", "npc:ghost"))
+	resolver.HandleEvent(skillEvent("ev-2", "mend", "npc:wolf"))
+	own := skillEvent("ev-3", "fire_breath", "npc:wolf")
+	own.Source = source
+	resolver.HandleEvent(own)
+	if len(*published) != 0 {
+		t.Fatalf("expected no combat results, got %+v", *published)
+	}
+
+	// A target without hp gets the default health; consecutive hits add up
+	attack := eventbus.NewEvent("npc.attacked_player", "narrative-orchestrator", "ash-realm", map[string]interface{}{
+		"entity_id": "npc:wolf",
+		"target":    "npc:shade",
+	})
+	attack.ID = "ev-4"
+	resolver.HandleEvent(attack)
+	attack.ID = "ev-5"
+	resolver.HandleEvent(attack)
+	if len(*published) != 2 {
+		t.Fatalf("expected two combat results, got %d", len(*published))
+	}
+	first := operations(t, (*published)[0])
+	if first["hp"] != 90.0 || first["max_hp"] != 100.0 {
+		t.Errorf("expected default health reduced by base damage, got %v", first)
+	}
+	if second := operations(t, (*published)[1]); second["hp"] != 80.0 {
+		t.Errorf("expected the second hit to continue from 90, got %v", second)
+	}
+}
@@ -0,0 +1,121 @@
+package combatresolver
+
+import (
+	"fmt"
+
+	"multiverse-core.io/shared/archivist"
+	"multiverse-core.io/shared/logging"
+	"multiverse-core.io/shared/schema"
+)
+
+// CombatRules describes how attacks are resolved in a world.
+type CombatRules struct {
+	// BaseDamage is the power of attacks without a skill entity
+	BaseDamage float64 `json:"base_damage"`
+	// DefaultHP is the health of combatants whose entity has no hp
+	DefaultHP float64 `json:"default_hp"`
+	// HitChance is the base hit probability before accuracy and evasion
+	HitChance      float64 `json:"hit_chance"`
+	CritChance     float64 `json:"crit_chance"`
+	CritMultiplier float64 `json:"crit_multiplier"`
+	// DefenseFactor scales defense: damage is multiplied by 100 / (100 + defense * factor)
+	DefenseFactor float64 `json:"defense_factor"`
+	// MinDamage is dealt by every hit
+	MinDamage float64 `json:"min_damage"`
+	// Elements maps the attacking element to damage multipliers against defending elements
+	Elements map[string]map[string]float64 `json:"elements,omitempty"`
+}
+
+// defaultRules apply to worlds without combat_rules in their ontology profile.
+var defaultRules = CombatRules{
+	BaseDamage:     10,
+	DefaultHP:      100,
+	HitChance:      0.9,
+	CritChance:     0.05,
+	CritMultiplier: 1.5,
+	DefenseFactor:  1,
+	MinDamage:      1,
+	Elements: map[string]map[string]float64{
+		"fire":  {"ice": 1.5, "nature": 1.5, "water": 0.5},
+		"water": {"fire": 1.5, "earth": 0.5},
+		"ice":   {"water": 1.5, "fire": 0.5},
+		"earth": {"lightning": 1.5},
+	},
+}
+
+// validate rejects rules that cannot resolve an attack.
+func (r CombatRules) validate() error {
+	if r.BaseDamage < 0 || r.MinDamage < 0 || r.DefenseFactor < 0 {
+		return fmt.Errorf("base_damage, min_damage and defense_factor must not be negative")
+	}
+	if r.DefaultHP <= 0 {
+		return fmt.Errorf("default_hp must be positive")
+	}
+	if r.HitChance <= 0 || r.HitChance > 1 || r.CritChance < 0 || r.CritChance > 1 {
+		return fmt.Errorf("hit_chance and crit_chance must be within (0, 1]")
+	}
+	if r.CritMultiplier < 1 {
+		return fmt.Errorf("crit_multiplier must be at least 1")
+	}
+	for attacking, multipliers := range r.Elements {
+		for defending, multiplier := range multipliers {
+			if multiplier < 0 {
+				return fmt.Errorf("element multiplier %s→%s is negative", attacking, defending)
+			}
+		}
+	}
+	return nil
+}
+
+// elementMultiplier returns the damage multiplier of an attacking element against a defending one.
+func (r CombatRules) elementMultiplier(attacking, defending string) float64 {
+	if multiplier, ok := r.Elements[attacking][defending]; ok {
+		return multiplier
+	}
+	return 1
+}
+
+// worldProfile is the part of a world ontology profile used by CombatResolver.
+type worldProfile struct {
+	CombatRules *CombatRules `json:"combat_rules"`
+}
+
+// profileRules returns the combat rules of a world profile; missing or invalid rules select the default.
+func profileRules(worldID string, profile worldProfile) CombatRules {
+	if profile.CombatRules == nil {
+		return defaultRules
+	}
+	if err := profile.CombatRules.validate(); err != nil {
+		logging.Warnf("Invalid combat rules of world %s, using default: %v", worldID, err)
+		return defaultRules
+	}
+	logging.Infof("Loaded combat rules of world %s", worldID)
+	return *profile.CombatRules
+}
+
+// Rules keeps the combat rules of worlds loaded from their ontology profiles.
+// Without an archivist every world uses defaultRules.
+type Rules struct {
+	worlds *archivist.ProfileCache[worldProfile, CombatRules]
+}
+
+// NewRules creates combat rules with the default rules.
+func NewRules() *Rules {
+	return &Rules{worlds: archivist.NewProfileCache(archivist.WorldProfileType, defaultRules, profileRules)}
+}
+
+// UseArchivist loads combat rules from the world ontology profiles in OntologicalArchivist.
+func (r *Rules) UseArchivist(client *archivist.Client) {
+	r.worlds.UseClient(client)
+}
+
+// For returns the combat rules of a world, loading its profile on first use.
+func (r *Rules) For(worldID string) CombatRules {
+	rules, _ := r.worlds.Get(worldID)
+	return rules
+}
+
+// HandleSchemaChange reloads the rules of a world when the archivist announces a new profile version.
+func (r *Rules) HandleSchemaChange(change schema.Change) {
+	r.worlds.HandleSchemaChange(change)
+}
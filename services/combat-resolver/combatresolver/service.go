@@ -0,0 +1,58 @@
+package combatresolver
+
+import (
+	"context"
+
+	"multiverse-core.io/shared/archivist"
+	"multiverse-core.io/shared/eventbus"
+	storage "multiverse-core.io/shared/minio"
+	"multiverse-core.io/shared/schema"
+)
+
+// Service manages the CombatResolver lifecycle.
+type Service struct {
+	bus      *eventbus.EventBus
+	resolver *CombatResolver
+	// schemaChanges reloads combat rules when the archivist announces a new profile version
+	schemaChanges *schema.ChangeSubscriber
+}
+
+// NewService creates a new CombatResolver service.
+func NewService(bus *eventbus.EventBus) *Service {
+	return &Service{
+		bus:      bus,
+		resolver: NewCombatResolver(bus),
+	}
+}
+
+// UseArchivist loads combat rules from OntologicalArchivist and reloads them on schema changes.
+func (s *Service) UseArchivist(client *archivist.Client) {
+	s.resolver.rules.UseArchivist(client)
+	s.schemaChanges = schema.NewChangeSubscriber(s.bus, "combat-resolver")
+	s.schemaChanges.OnChange(s.resolver.rules.HandleSchemaChange)
+}
+
+// UseEntityStorage enables reading combatants and skills from their entities in MinIO.
+func (s *Service) UseEntityStorage(client storage.ObjectStorage) {
+	s.resolver.combatants.UseStorage(client)
+}
+
+// Run starts the service and blocks until context is cancelled.
+func (s *Service) Run(ctx context.Context) error {
+	if s.schemaChanges != nil {
+		go s.schemaChanges.Run(ctx)
+	}
+
+	// Subscribe to relevant event topics
+	topics := []string{
+		eventbus.TopicPlayerEvents,
+		eventbus.TopicWorldEvents,
+	}
+
+	for _, topic := range topics {
+		go s.bus.Subscribe(ctx, topic, "combat-resolver-group", s.resolver.HandleEvent)
+	}
+
+	<-ctx.Done()
+	return ctx.Err()
+}
@@ -2,43 +2,39 @@
 package main
 
 import (
-	"context"
-	"log"
-	"os"
-	"os/signal"
-	"strings"
-	"syscall"
-
-	"multiverse-core.io/shared/eventbus"
 	"multiverse-core.io/services/cultivation-module/cultivationmodule"
+	"multiverse-core.io/shared/archivist"
+	"multiverse-core.io/shared/config"
+	"multiverse-core.io/shared/logging"
+	"multiverse-core.io/shared/registry"
+	"multiverse-core.io/shared/service"
 )
 
 func main() {
-	// Initialize event bus
-	brokers := strings.Split(os.Getenv("KAFKA_BROKERS"), ",")
-	if len(brokers) == 0 || brokers[0] == "" {
-		brokers = []string{"redpanda:9092"}
-	}
-	bus := eventbus.NewEventBus(brokers)
-
-	// Create and run service
-	service := cultivationmodule.NewService(bus)
+	app := service.Setup("cultivation-module", []config.Option{
+		{Env: "ARCHIVIST_URL", Type: config.TypeURL, Usage: "fallback archivist address"},
+		{Env: "PROGRESSION_FROM_ARCHIVIST", Default: "true", Type: config.TypeBool, Usage: "load cultivation progression and dao compatibility matrices from the archivist (false uses built-in rules only)"},
+	})
+	env := app.Env
 
-	// Handle shutdown
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	cultivation := cultivationmodule.NewService(app.Bus())
 
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	go func() {
-		<-sigChan
-		log.Println("Shutting down CultivationModule...")
-		cancel()
-	}()
+	// Cultivation states are stored in player entities by EntityManager and read back from MinIO
+	// (optional: without MinIO every player starts from zero after a restart)
+	minioClient, err := app.MinIO()
+	if err != nil {
+		logging.Warnf("MinIO unavailable, stored cultivation states are not loaded: %v", err)
+	} else {
+		cultivation.UseEntityStorage(minioClient)
+	}
 
-	log.Println("CultivationModule starting...")
-	if err := service.Run(ctx); err != nil && err != context.Canceled {
-		log.Fatal("Service failed:", err)
+	// Progression tables come from the world ontology profiles in the archivist
+	// (address through the service registry); the built-in table is the fallback
+	discovery := registry.NewDiscovery(app.Bus(), "cultivation-module")
+	if env.Bool("PROGRESSION_FROM_ARCHIVIST") {
+		cultivation.UseArchivist(archivist.NewClient(env.String("ARCHIVIST_URL"), discovery))
 	}
-	log.Println("CultivationModule stopped.")
+	app.Go(discovery.Run)
+
+	app.Run(cultivation)
 }
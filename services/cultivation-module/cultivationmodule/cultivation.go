@@ -3,22 +3,34 @@ package cultivationmodule
 
 import (
 	"context"
-	"log"
+	"math/rand"
 	"time"
 
 	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
 
 	"github.com/google/uuid"
 )
 
 // CultivationModule manages cultivation systems across plans.
 type CultivationModule struct {
-	bus *eventbus.EventBus
+	bus          *eventbus.EventBus
+	progressions *Progressions
+	daoMatrices  *DaoMatrices
+	states       *StateStore
+	// roll returns a uniform random number in [0, 1) for breakthrough attempts
+	roll func() float64
 }
 
 // NewCultivationModule creates a new CultivationModule.
 func NewCultivationModule(bus *eventbus.EventBus) *CultivationModule {
-	return &CultivationModule{bus: bus}
+	return &CultivationModule{
+		bus:          bus,
+		progressions: NewProgressions(),
+		daoMatrices:  NewDaoMatrices(),
+		states:       NewStateStore(),
+		roll:         rand.Float64,
+	}
 }
 
 // HandleEvent processes events for cultivation management.
@@ -26,12 +38,20 @@ func (cm *CultivationModule) HandleEvent(ev eventbus.Event) {
 	switch ev.Type {
 	case "player.used_skill":
 		cm.processSkillUsage(ev)
+	case "cultivation.breakthrough.attempt":
+		cm.handleBreakthroughAttempt(ev)
 	case "ascension.completed":
 		cm.handleAscension(ev)
 	case "dao.interaction.attempt":
 		cm.handleDaoInteraction(ev)
 	case "cultivation.form.created":
 		cm.handleCultivationForm(ev)
+	case "technique.learn.attempt":
+		cm.handleTechniqueLearn(ev)
+	case "technique.forget.attempt":
+		cm.handleTechniqueForget(ev)
+	case "world.generated":
+		cm.handleWorldGenerated(ev)
 	}
 }
 
@@ -44,6 +64,9 @@ func (cm *CultivationModule) processSkillUsage(ev eventbus.Event) {
 	if skill == "" {
 		skill, _ = pa.GetString("action.skill") // fallback на вложенную структуру
 	}
+	if skill == "" {
+		skill, _ = pa.GetString("skill_id") // команды GameService
+	}
 
 	// Извлечение playerID: новая структура entity.id → старая player_id
 	var playerID string
@@ -54,13 +77,30 @@ func (cm *CultivationModule) processSkillUsage(ev eventbus.Event) {
 	}
 
 	if playerID == "" {
-		log.Printf("Skill usage missing player_id")
+		logging.Warnf("Skill usage missing player_id")
 		return
 	}
 
 	// Извлечение world_id с поддержкой обеих структур:
 	worldID := eventbus.GetWorldIDFromEvent(ev)
 
+	// Only known skills advance cultivation; unverified players are trusted
+	if skill != "" {
+		if known, verified := cm.states.KnowsSkill(playerID, worldID, skill); verified && !known {
+			cm.publishSkillUnknown(ev, playerID, worldID, skill)
+			return
+		}
+	}
+
+	// Progress is accumulated as qi in the player's cultivation state
+	progress := cm.calculateProgress(skill, worldID)
+	table := cm.progressions.For(worldID)
+	qi := progress * table.QiPerProgress
+	var advanced int
+	state := cm.states.Update(playerID, worldID, func(state *CultivationState) {
+		advanced = state.gainQi(table, qi)
+	})
+
 	// Update cultivation progress based on skill usage — с иерархической структурой событий:
 	payload := eventbus.NewEventPayload().
 		WithEntity(playerID, "player", "").
@@ -68,7 +108,12 @@ func (cm *CultivationModule) processSkillUsage(ev eventbus.Event) {
 
 	// Добавляем данные через dot-notation для гибкости:
 	eventbus.SetNested(payload.GetCustom(), "skill_used", skill)
-	eventbus.SetNested(payload.GetCustom(), "progress_gained", cm.calculateProgress(skill, worldID))
+	eventbus.SetNested(payload.GetCustom(), "progress_gained", progress)
+	eventbus.SetNested(payload.GetCustom(), "qi_gained", qi)
+	eventbus.SetNested(payload.GetCustom(), "stages_advanced", advanced)
+	eventbus.SetNested(payload.GetCustom(), "cultivation", state)
+	// EntityManager stores the state in the player entity
+	eventbus.SetNested(payload.GetCustom(), "state_changes", stateChanges(playerID, state))
 
 	// Сохраняем также иерархические пути для совместимости с LLM:
 	eventbus.SetNested(payload.GetCustom(), "entity.id", playerID)
@@ -81,6 +126,63 @@ func (cm *CultivationModule) processSkillUsage(ev eventbus.Event) {
 	cm.bus.Publish(context.Background(), eventbus.TopicWorldEvents, progressEvent)
 }
 
+// handleBreakthroughAttempt attempts to break through the player's bottleneck and publishes cultivation.breakthrough.
+func (cm *CultivationModule) handleBreakthroughAttempt(ev eventbus.Event) {
+	pa := ev.Path()
+
+	// Извлечение playerID: новая структура entity.id → старая player_id
+	var playerID string
+	if entityInfo, ok := ev.GetEntityIDWithFallback(); ok {
+		playerID = entityInfo.ID
+	} else {
+		playerID, _ = pa.GetString("player_id")
+	}
+
+	if playerID == "" {
+		logging.Warnf("Breakthrough attempt missing player_id")
+		return
+	}
+
+	worldID := eventbus.GetWorldIDFromEvent(ev)
+	table := cm.progressions.For(worldID)
+
+	var before CultivationState
+	var result string
+	var chance float64
+	state := cm.states.Update(playerID, worldID, func(state *CultivationState) {
+		state.label(table)
+		before = *state
+		result, chance = state.breakthrough(table, cm.roll())
+	})
+
+	payload := eventbus.NewEventPayload().
+		WithEntity(playerID, "player", "").
+		WithWorld(worldID)
+
+	eventbus.SetNested(payload.GetCustom(), "result", result)
+	eventbus.SetNested(payload.GetCustom(), "chance", chance)
+	eventbus.SetNested(payload.GetCustom(), "from.realm", before.RealmName)
+	eventbus.SetNested(payload.GetCustom(), "from.stage", before.StageName)
+	eventbus.SetNested(payload.GetCustom(), "to.realm", state.RealmName)
+	eventbus.SetNested(payload.GetCustom(), "to.stage", state.StageName)
+	eventbus.SetNested(payload.GetCustom(), "cultivation", state)
+	eventbus.SetNested(payload.GetCustom(), "original_event", ev.ID)
+	// Only attempts that changed the state are stored
+	if result == BreakthroughSuccess || result == BreakthroughFailed {
+		eventbus.SetNested(payload.GetCustom(), "state_changes", stateChanges(playerID, state))
+	}
+
+	breakthroughEvent := eventbus.NewStructuredEvent("cultivation.breakthrough", "cultivation-module", worldID, payload)
+	breakthroughEvent.ID = "cult-breakthrough-" + uuid.New().String()[:8]
+	breakthroughEvent.Timestamp = time.Now()
+	breakthroughEvent.Scope = eventbus.GetScopeFromEvent(ev)
+
+	cm.bus.Publish(context.Background(), eventbus.TopicWorldEvents, breakthroughEvent)
+
+	logging.Infof("Breakthrough of %s in %s: %s (%s/%s → %s/%s, chance %.2f)",
+		playerID, worldID, result, before.RealmName, before.StageName, state.RealmName, state.StageName, chance)
+}
+
 // handleAscension handles post-ascension cultivation changes — с универсальным доступом и иерархическими событиями:
 func (cm *CultivationModule) handleAscension(ev eventbus.Event) {
 	pa := ev.Path()
@@ -98,7 +200,7 @@ func (cm *CultivationModule) handleAscension(ev eventbus.Event) {
 	toPlan, _ := pa.GetFloat("to_plan")
 
 	if playerID == "" {
-		log.Printf("Ascension missing player_id")
+		logging.Warnf("Ascension missing player_id")
 		return
 	}
 
@@ -129,7 +231,7 @@ func (cm *CultivationModule) handleAscension(ev eventbus.Event) {
 
 	cm.bus.Publish(context.Background(), eventbus.TopicWorldEvents, updateEvent)
 
-	log.Printf("Cultivation system updated for %s at Plan %d", playerID, int(toPlan))
+	logging.Infof("Cultivation system updated for %s at Plan %d", playerID, int(toPlan))
 }
 
 // handleDaoInteraction handles attempts to interact with other daos — с универсальным доступом и иерархическими событиями:
@@ -150,20 +252,27 @@ func (cm *CultivationModule) handleDaoInteraction(ev eventbus.Event) {
 		targetDao, _ = pa.GetString("dao.target.id") // fallback на вложенную структуру
 	}
 
+	// Путь самого игрока: без него применяются только встроенные правила
+	sourceDao, _ := pa.GetString("source_dao")
+	if sourceDao == "" {
+		sourceDao, _ = pa.GetString("dao.source.id")
+	}
+
 	interactionType, _ := pa.GetString("interaction_type")
 	if interactionType == "" {
 		interactionType, _ = pa.GetString("action.type") // fallback
 	}
 
 	if playerID == "" || targetDao == "" {
-		log.Printf("Dao interaction missing required fields")
+		logging.Warnf("Dao interaction missing required fields")
 		return
 	}
 
 	worldID := eventbus.GetWorldIDFromEvent(ev)
 
 	// Check if interaction is allowed
-	if cm.isDaoInteractionAllowed(playerID, targetDao, interactionType, worldID) {
+	allowed, compatibility, known := cm.daoInteractionOutcome(sourceDao, targetDao, interactionType, worldID)
+	if allowed {
 		successPayload := eventbus.NewEventPayload().
 			WithEntity(playerID, "player", "").
 			WithWorld(worldID)
@@ -171,6 +280,9 @@ func (cm *CultivationModule) handleDaoInteraction(ev eventbus.Event) {
 		eventbus.SetNested(successPayload.GetCustom(), "target_dao", targetDao)
 		eventbus.SetNested(successPayload.GetCustom(), "interaction_type", interactionType)
 		eventbus.SetNested(successPayload.GetCustom(), "result", "harmony_achieved")
+		if known {
+			setDaoRelation(successPayload.GetCustom(), sourceDao, compatibility)
+		}
 
 		// Иерархические пути для LLM:
 		eventbus.SetNested(successPayload.GetCustom(), "entity.id", playerID)
@@ -191,6 +303,9 @@ func (cm *CultivationModule) handleDaoInteraction(ev eventbus.Event) {
 		eventbus.SetNested(conflictPayload.GetCustom(), "target_dao", targetDao)
 		eventbus.SetNested(conflictPayload.GetCustom(), "interaction_type", interactionType)
 		eventbus.SetNested(conflictPayload.GetCustom(), "result", "dao_conflict")
+		if known {
+			setDaoRelation(conflictPayload.GetCustom(), sourceDao, compatibility)
+		}
 
 		// Иерархические пути для LLM:
 		eventbus.SetNested(conflictPayload.GetCustom(), "entity.id", playerID)
@@ -230,7 +345,7 @@ func (cm *CultivationModule) handleCultivationForm(ev eventbus.Event) {
 	}
 
 	if formID == "" || playerID == "" {
-		log.Printf("Cultivation form missing required fields")
+		logging.Warnf("Cultivation form missing required fields")
 		return
 	}
 
@@ -336,7 +451,7 @@ func (cm *CultivationModule) mergeDaoPaths(ev eventbus.Event, targetPlan int) {
 
 	cm.bus.Publish(context.Background(), eventbus.TopicWorldEvents, mergeEvent)
 
-	log.Printf("Hybrid dao formed for %s at Plan %d", playerID, targetPlan)
+	logging.Infof("Hybrid dao formed for %s at Plan %d", playerID, targetPlan)
 }
 
 // calculateProgress calculates cultivation progress based on skill and world.
@@ -367,8 +482,28 @@ func (cm *CultivationModule) getSystemTypeForPlan(plan int) string {
 	}
 }
 
-// isDaoInteractionAllowed checks if dao interaction is allowed.
-func (cm *CultivationModule) isDaoInteractionAllowed(playerID, targetDao, interactionType, worldID string) bool {
+// daoInteractionOutcome decides a dao interaction by the world's compatibility matrix;
+// pairs the matrix does not define fall back to the built-in rules (known is false).
+func (cm *CultivationModule) daoInteractionOutcome(sourceDao, targetDao, interactionType, worldID string) (allowed bool, compatibility DaoCompatibility, known bool) {
+	if matrix, ok := cm.daoMatrices.For(worldID); ok && sourceDao != "" {
+		if allowed, compatibility, known := matrix.outcome(sourceDao, targetDao, interactionType); known {
+			return allowed, compatibility, true
+		}
+	}
+	return cm.isDaoInteractionAllowed(targetDao, interactionType, worldID), DaoCompatibility{}, false
+}
+
+// setDaoRelation adds the matrix relation that decided the interaction to the payload.
+func setDaoRelation(payload map[string]any, sourceDao string, compatibility DaoCompatibility) {
+	eventbus.SetNested(payload, "source_dao", sourceDao)
+	eventbus.SetNested(payload, "dao.relation", compatibility.Relation)
+	if compatibility.Reason != "" {
+		eventbus.SetNested(payload, "dao.relation_reason", compatibility.Reason)
+	}
+}
+
+// isDaoInteractionAllowed checks if dao interaction is allowed by the built-in rules.
+func (cm *CultivationModule) isDaoInteractionAllowed(targetDao, interactionType, worldID string) bool {
 	// Example rules
 	if worldID == "pain-realm" && interactionType == "harmonize" {
 		return false // Harmonization forbidden in World of Pain
@@ -0,0 +1,97 @@
+package cultivationmodule
+
+import (
+	"multiverse-core.io/shared/archivist"
+	"multiverse-core.io/shared/logging"
+	"multiverse-core.io/shared/schema"
+)
+
+// DaoMatrixType holds the dao compatibility matrices generated by WorldGenerator, named by world ID.
+const DaoMatrixType = "dao_compatibility_matrix"
+
+// Relations between dao paths in the compatibility matrix.
+const (
+	// DaoHarmonize paths succeed in every interaction
+	DaoHarmonize = "harmonize"
+	// DaoAbsorb lets the source absorb the target; harmonizing or merging them conflicts
+	DaoAbsorb = "absorb"
+	// DaoConflict paths conflict in every interaction
+	DaoConflict = "conflict"
+)
+
+// DaoCompatibility is the relation of two paths; harmonize and conflict are symmetric,
+// absorb goes from source to target.
+type DaoCompatibility struct {
+	Source   string `json:"source"`
+	Target   string `json:"target"`
+	Relation string `json:"relation"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// DaoMatrix is the dao compatibility matrix of a world.
+type DaoMatrix struct {
+	Paths []string           `json:"paths"`
+	Pairs []DaoCompatibility `json:"pairs"`
+}
+
+// relation returns the relation of source to target; false if the matrix does not define it.
+func (m DaoMatrix) relation(source, target string) (DaoCompatibility, bool) {
+	for _, pair := range m.Pairs {
+		if pair.Source == source && pair.Target == target {
+			return pair, true
+		}
+		if pair.Relation != DaoAbsorb && pair.Source == target && pair.Target == source {
+			return pair, true
+		}
+	}
+	return DaoCompatibility{}, false
+}
+
+// outcome decides whether an interaction of source with target succeeds.
+// known is false when the matrix has no relation for the pair.
+func (m DaoMatrix) outcome(source, target, interactionType string) (allowed bool, pair DaoCompatibility, known bool) {
+	pair, known = m.relation(source, target)
+	if !known {
+		return false, pair, false
+	}
+	switch pair.Relation {
+	case DaoHarmonize:
+		return true, pair, true
+	case DaoAbsorb:
+		return interactionType != "harmonize" && interactionType != "merge", pair, true
+	default:
+		return false, pair, true
+	}
+}
+
+// loadedMatrix logs the matrix loaded for a world.
+func loadedMatrix(worldID string, matrix DaoMatrix) DaoMatrix {
+	logging.Infof("Loaded dao compatibility matrix of world %s: %d pairs", worldID, len(matrix.Pairs))
+	return matrix
+}
+
+// DaoMatrices keeps the dao compatibility matrices of worlds loaded from OntologicalArchivist.
+// Without an archivist only the built-in rules apply.
+type DaoMatrices struct {
+	worlds *archivist.ProfileCache[DaoMatrix, DaoMatrix]
+}
+
+// NewDaoMatrices creates an empty matrix cache.
+func NewDaoMatrices() *DaoMatrices {
+	return &DaoMatrices{worlds: archivist.NewProfileCache(DaoMatrixType, DaoMatrix{}, loadedMatrix)}
+}
+
+// UseArchivist loads matrices from OntologicalArchivist.
+func (d *DaoMatrices) UseArchivist(client *archivist.Client) {
+	d.worlds.UseClient(client)
+}
+
+// For returns the matrix of a world, loading it on first use; false if the world has none.
+func (d *DaoMatrices) For(worldID string) (DaoMatrix, bool) {
+	return d.worlds.Get(worldID)
+}
+
+// HandleSchemaChange reloads the matrix of a world when the archivist announces a new version.
+func (d *DaoMatrices) HandleSchemaChange(change schema.Change) {
+	d.worlds.HandleSchemaChange(change)
+}
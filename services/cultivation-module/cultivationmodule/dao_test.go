@@ -0,0 +1,71 @@
+package cultivationmodule
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"multiverse-core.io/shared/archivist"
+	"multiverse-core.io/shared/schema"
+)
+
+func TestDaoMatrixOutcome(t *testing.T) {
+	matrix := DaoMatrix{Pairs: []DaoCompatibility{
+		{Source: "flame", Target: "ice", Relation: DaoConflict},
+		{Source: "void", Target: "flame", Relation: DaoAbsorb},
+		{Source: "ice", Target: "water", Relation: DaoHarmonize},
+	}}
+	cases := []struct {
+		source, target, interaction string
+		allowed, known              bool
+	}{
+		{"ice", "flame", "study", false, true},
+		{"water", "ice", "harmonize", true, true},
+		{"void", "flame", "absorb", true, true},
+		{"void", "flame", "merge", false, true},
+		{"flame", "void", "absorb", false, false},
+		{"flame", "water", "absorb", false, false},
+	}
+	for _, c := range cases {
+		allowed, _, known := matrix.outcome(c.source, c.target, c.interaction)
+		if allowed != c.allowed || known != c.known {
+			t.Errorf("%s %s %s: allowed %v known %v, want %v %v", c.source, c.interaction, c.target, allowed, known, c.allowed, c.known)
+		}
+	}
+}
+
+func TestDaoInteractionOutcome(t *testing.T) {
+	var version int
+	archivistServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/schemas/dao_compatibility_matrix/ash-realm/latest" {
+			http.NotFound(w, r)
+			return
+		}
+		relation := "conflict"
+		if version > 0 {
+			relation = "harmonize"
+		}
+		w.Write([]byte(`{"paths": ["ember", "ash"], "pairs": [{"source": "ember", "target": "ash", "relation": "` + relation + `"}]}`))
+	}))
+	defer archivistServer.Close()
+
+	cm := NewCultivationModule(nil)
+	cm.daoMatrices.UseArchivist(archivist.NewClient(archivistServer.URL, nil))
+
+	if allowed, compatibility, known := cm.daoInteractionOutcome("ash", "ember", "study", "ash-realm"); allowed || !known || compatibility.Relation != DaoConflict {
+		t.Errorf("expected conflict from the matrix, got %v %+v %v", allowed, compatibility, known)
+	}
+	// Pairs outside the matrix and worlds without one use the built-in rules
+	if allowed, _, known := cm.daoInteractionOutcome("ash", "forbidden_dao", "absorb", "ash-realm"); allowed || known {
+		t.Errorf("expected built-in rule, got %v %v", allowed, known)
+	}
+	if allowed, _, known := cm.daoInteractionOutcome("ember", "ash", "harmonize", "pain-realm"); allowed || known {
+		t.Errorf("expected built-in pain-realm rule, got %v %v", allowed, known)
+	}
+
+	version = 1
+	cm.daoMatrices.HandleSchemaChange(schema.Change{SchemaType: DaoMatrixType, Name: "ash-realm", Version: "v2"})
+	if allowed, _, _ := cm.daoInteractionOutcome("ash", "ember", "harmonize", "ash-realm"); !allowed {
+		t.Errorf("matrix must be reloaded on schema change")
+	}
+}
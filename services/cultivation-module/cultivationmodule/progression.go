@@ -0,0 +1,150 @@
+package cultivationmodule
+
+import (
+	"fmt"
+
+	"multiverse-core.io/shared/archivist"
+	"multiverse-core.io/shared/logging"
+	"multiverse-core.io/shared/schema"
+)
+
+// Stage is one step of a realm.
+type Stage struct {
+	Name string `json:"name"`
+	// Qi is the qi needed to complete the stage
+	Qi float64 `json:"qi"`
+	// Bottleneck stops progression at the end of the stage until a breakthrough succeeds;
+	// the last stage of a realm is always a bottleneck.
+	Bottleneck bool `json:"bottleneck,omitempty"`
+	// BreakthroughChance is the base success probability of a breakthrough out of the stage
+	BreakthroughChance float64 `json:"breakthrough_chance,omitempty"`
+}
+
+// Realm is a major level of cultivation made of stages.
+type Realm struct {
+	Name   string  `json:"name"`
+	Stages []Stage `json:"stages"`
+}
+
+// ProgressionTable describes how cultivation advances in a world.
+type ProgressionTable struct {
+	// QiPerProgress converts progress gained from an action into qi
+	QiPerProgress float64 `json:"qi_per_progress"`
+	Realms        []Realm `json:"realms"`
+}
+
+// defaultProgression applies to worlds without cultivation_progression in their ontology profile.
+var defaultProgression = ProgressionTable{
+	QiPerProgress: 10,
+	Realms: []Realm{
+		{Name: "qi_condensation", Stages: []Stage{
+			{Name: "early", Qi: 100},
+			{Name: "middle", Qi: 200},
+			{Name: "late", Qi: 400, Bottleneck: true, BreakthroughChance: 0.6},
+		}},
+		{Name: "foundation_establishment", Stages: []Stage{
+			{Name: "early", Qi: 800},
+			{Name: "middle", Qi: 1600, Bottleneck: true, BreakthroughChance: 0.7},
+			{Name: "late", Qi: 3200, Bottleneck: true, BreakthroughChance: 0.4},
+		}},
+		{Name: "golden_core", Stages: []Stage{
+			{Name: "early", Qi: 6400},
+			{Name: "middle", Qi: 12800, Bottleneck: true, BreakthroughChance: 0.5},
+			{Name: "late", Qi: 25600, Bottleneck: true, BreakthroughChance: 0.25},
+		}},
+	},
+}
+
+// validate rejects tables that cannot be progressed through.
+func (t ProgressionTable) validate() error {
+	if t.QiPerProgress <= 0 {
+		return fmt.Errorf("qi_per_progress must be positive")
+	}
+	if len(t.Realms) == 0 {
+		return fmt.Errorf("no realms")
+	}
+	for _, realm := range t.Realms {
+		if realm.Name == "" || len(realm.Stages) == 0 {
+			return fmt.Errorf("realm %q has no name or stages", realm.Name)
+		}
+		for _, stage := range realm.Stages {
+			if stage.Qi <= 0 {
+				return fmt.Errorf("stage %s/%s needs positive qi", realm.Name, stage.Name)
+			}
+			if stage.BreakthroughChance < 0 || stage.BreakthroughChance > 1 {
+				return fmt.Errorf("stage %s/%s has breakthrough_chance outside [0, 1]", realm.Name, stage.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// stage returns the stage at the position; false if the position is outside the table.
+func (t ProgressionTable) stage(realm, stage int) (Stage, bool) {
+	if realm < 0 || realm >= len(t.Realms) || stage < 0 || stage >= len(t.Realms[realm].Stages) {
+		return Stage{}, false
+	}
+	return t.Realms[realm].Stages[stage], true
+}
+
+// isBottleneck reports whether progression stops at the end of the stage.
+func (t ProgressionTable) isBottleneck(realm, stage int) bool {
+	s, ok := t.stage(realm, stage)
+	return ok && (s.Bottleneck || stage == len(t.Realms[realm].Stages)-1)
+}
+
+// next returns the position after the stage; false at the peak of the last realm.
+func (t ProgressionTable) next(realm, stage int) (int, int, bool) {
+	if stage+1 < len(t.Realms[realm].Stages) {
+		return realm, stage + 1, true
+	}
+	if realm+1 < len(t.Realms) {
+		return realm + 1, 0, true
+	}
+	return realm, stage, false
+}
+
+// worldProfile is the part of a world ontology profile used by CultivationModule.
+type worldProfile struct {
+	CultivationProgression *ProgressionTable `json:"cultivation_progression"`
+}
+
+// profileProgression returns the progression table of a world profile; a missing or invalid table selects the default.
+func profileProgression(worldID string, profile worldProfile) ProgressionTable {
+	if profile.CultivationProgression == nil {
+		return defaultProgression
+	}
+	if err := profile.CultivationProgression.validate(); err != nil {
+		logging.Warnf("Invalid cultivation progression of world %s, using default: %v", worldID, err)
+		return defaultProgression
+	}
+	logging.Infof("Loaded cultivation progression of world %s: %d realms", worldID, len(profile.CultivationProgression.Realms))
+	return *profile.CultivationProgression
+}
+
+// Progressions keeps the progression tables of worlds loaded from their ontology profiles.
+// Without an archivist every world uses defaultProgression.
+type Progressions struct {
+	worlds *archivist.ProfileCache[worldProfile, ProgressionTable]
+}
+
+// NewProgressions creates progression tables with the default table.
+func NewProgressions() *Progressions {
+	return &Progressions{worlds: archivist.NewProfileCache(archivist.WorldProfileType, defaultProgression, profileProgression)}
+}
+
+// UseArchivist loads progression tables from the world ontology profiles in OntologicalArchivist.
+func (p *Progressions) UseArchivist(client *archivist.Client) {
+	p.worlds.UseClient(client)
+}
+
+// For returns the progression table of a world, loading its profile on first use.
+func (p *Progressions) For(worldID string) ProgressionTable {
+	table, _ := p.worlds.Get(worldID)
+	return table
+}
+
+// HandleSchemaChange reloads the table of a world when the archivist announces a new profile version.
+func (p *Progressions) HandleSchemaChange(change schema.Change) {
+	p.worlds.HandleSchemaChange(change)
+}
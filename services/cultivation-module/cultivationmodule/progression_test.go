@@ -0,0 +1,108 @@
+package cultivationmodule
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"multiverse-core.io/shared/archivist"
+	"multiverse-core.io/shared/schema"
+)
+
+func TestGainQiStopsAtBottleneck(t *testing.T) {
+	table := defaultProgression
+	var state CultivationState
+
+	// 100 + 200 qi completes the first two stages of qi_condensation
+	if advanced := state.gainQi(table, 350); advanced != 2 || state.StageName != "late" || state.Qi != 50 {
+		t.Fatalf("unexpected state after 350 qi: advanced %d, %+v", advanced, state)
+	}
+	if state.gainQi(table, 1000); !state.Bottleneck || state.Qi != 400 || state.RealmName != "qi_condensation" {
+		t.Fatalf("expected bottleneck at the end of the realm, got %+v", state)
+	}
+	if advanced := state.gainQi(table, 100); advanced != 0 || state.Qi != 400 {
+		t.Errorf("qi must not accumulate at a bottleneck, got %+v", state)
+	}
+}
+
+func TestBreakthrough(t *testing.T) {
+	table := defaultProgression
+	state := CultivationState{Stage: 2}
+	if result, _ := state.breakthrough(table, 0); result != BreakthroughNotReady {
+		t.Errorf("breakthrough without bottleneck: %s", result)
+	}
+
+	state.gainQi(table, 400)
+	result, chance := state.breakthrough(table, 0.9)
+	if result != BreakthroughFailed || chance != 0.6 || state.Qi != 280 || state.Bottleneck || state.Attempts != 1 {
+		t.Fatalf("expected failed breakthrough losing qi, got %s %+v", result, state)
+	}
+
+	// Every failed attempt makes the next one easier
+	state.gainQi(table, 120)
+	result, chance = state.breakthrough(table, 0.62)
+	if result != BreakthroughSuccess || chance != 0.65 {
+		t.Fatalf("expected success with chance 0.65, got %s %.2f", result, chance)
+	}
+	if state.RealmName != "foundation_establishment" || state.StageName != "early" || state.Qi != 0 || state.Attempts != 0 {
+		t.Errorf("unexpected state after breakthrough: %+v", state)
+	}
+
+	peak := CultivationState{Realm: 2, Stage: 2, Bottleneck: true}
+	if result, _ := peak.breakthrough(table, 0); result != BreakthroughPeak {
+		t.Errorf("breakthrough at the peak: %s", result)
+	}
+}
+
+func TestDecodeCultivation(t *testing.T) {
+	var state CultivationState
+	stored := `{"entity_id": "player-1", "entity_type": "player", "payload": {"cultivation": {"realm": 1, "stage": 2, "qi": 150, "bottleneck": true, "attempts": 3}}}`
+	if err := decodeCultivation([]byte(stored), &state); err != nil {
+		t.Fatal(err)
+	}
+	if state.Realm != 1 || state.Stage != 2 || state.Qi != 150 || !state.Bottleneck || state.Attempts != 3 {
+		t.Errorf("unexpected state %+v", state)
+	}
+	changes := stateChanges("player-1", state)
+	op := changes[0].(map[string]interface{})["operations"].([]interface{})[0].(map[string]interface{})
+	if op["path"] != "cultivation" || op["value"].(map[string]interface{})["qi"] != 150.0 {
+		t.Errorf("unexpected state change %v", op)
+	}
+}
+
+func TestProgressionsFromProfile(t *testing.T) {
+	var version int
+	archivistServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v1/schemas/world_ontology_profile/ash-realm/latest" && version == 0:
+			w.Write([]byte(`{"cultivation_progression": {"qi_per_progress": 5, "realms": [
+				{"name": "ember", "stages": [{"name": "spark", "qi": 10, "breakthrough_chance": 0.5}]}]}}`))
+		case r.URL.Path == "/v1/schemas/world_ontology_profile/ash-realm/latest":
+			w.Write([]byte(`{"cultivation_progression": {"qi_per_progress": 0, "realms": []}}`))
+		case r.URL.Path == "/v1/schemas/world_ontology_profile/memory-realm/latest":
+			w.Write([]byte(`{"archetypal_forbiddances": []}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer archivistServer.Close()
+
+	progressions := NewProgressions()
+	progressions.UseArchivist(archivist.NewClient(archivistServer.URL, nil))
+	if table := progressions.For("ash-realm"); table.QiPerProgress != 5 || table.Realms[0].Name != "ember" {
+		t.Errorf("expected world table, got %+v", table)
+	}
+	if table := progressions.For("memory-realm"); table.Realms[0].Name != "qi_condensation" {
+		t.Errorf("profile without a table must use the default, got %+v", table)
+	}
+	if table := progressions.For("pain-realm"); table.Realms[0].Name != "qi_condensation" {
+		t.Errorf("world without a profile must use the default, got %+v", table)
+	}
+
+	// An invalid new version falls back to the default table
+	version = 1
+	progressions.HandleSchemaChange(schema.Change{SchemaType: archivist.WorldProfileType, Name: "ash-realm", Version: "v2"})
+	if table := progressions.For("ash-realm"); table.Realms[0].Name != "qi_condensation" {
+		t.Errorf("invalid table must be rejected, got %+v", table)
+	}
+}
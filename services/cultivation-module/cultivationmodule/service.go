@@ -4,13 +4,18 @@ package cultivationmodule
 import (
 	"context"
 
+	"multiverse-core.io/shared/archivist"
 	"multiverse-core.io/shared/eventbus"
+	storage "multiverse-core.io/shared/minio"
+	"multiverse-core.io/shared/schema"
 )
 
 // Service manages the CultivationModule lifecycle.
 type Service struct {
 	bus         *eventbus.EventBus
 	cultivation *CultivationModule
+	// schemaChanges reloads progression tables and dao matrices when the archivist announces a new version
+	schemaChanges *schema.ChangeSubscriber
 }
 
 // NewService creates a new CultivationModule service.
@@ -21,8 +26,27 @@ func NewService(bus *eventbus.EventBus) *Service {
 	}
 }
 
+// UseArchivist loads progression tables and dao compatibility matrices from OntologicalArchivist
+// and reloads them on schema changes.
+func (s *Service) UseArchivist(client *archivist.Client) {
+	s.cultivation.progressions.UseArchivist(client)
+	s.cultivation.daoMatrices.UseArchivist(client)
+	s.schemaChanges = schema.NewChangeSubscriber(s.bus, "cultivation-module")
+	s.schemaChanges.OnChange(s.cultivation.progressions.HandleSchemaChange)
+	s.schemaChanges.OnChange(s.cultivation.daoMatrices.HandleSchemaChange)
+}
+
+// UseEntityStorage enables reading stored cultivation states from the player entities in MinIO.
+func (s *Service) UseEntityStorage(client storage.ObjectStorage) {
+	s.cultivation.states.UseStorage(client)
+}
+
 // Run starts the service and blocks until context is cancelled.
 func (s *Service) Run(ctx context.Context) error {
+	if s.schemaChanges != nil {
+		go s.schemaChanges.Run(ctx)
+	}
+
 	// Subscribe to relevant event topics
 	topics := []string{
 		eventbus.TopicPlayerEvents,
@@ -0,0 +1,266 @@
+package cultivationmodule
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/entity"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+)
+
+const (
+	// breakthroughFailureQiLoss is the share of stage qi lost on a failed breakthrough
+	breakthroughFailureQiLoss = 0.3
+	// breakthroughAttemptBonus raises the chance of every next attempt at the same bottleneck
+	breakthroughAttemptBonus = 0.05
+	// maxBreakthroughChance keeps every breakthrough a risk
+	maxBreakthroughChance = 0.95
+)
+
+// Breakthrough results published in cultivation.breakthrough.
+const (
+	BreakthroughSuccess  = "success"
+	BreakthroughFailed   = "failed"
+	BreakthroughNotReady = "not_ready"
+	BreakthroughPeak     = "peak"
+)
+
+// CultivationState is the cultivation of a player, stored in the player entity under "cultivation".
+type CultivationState struct {
+	Realm int `json:"realm"`
+	Stage int `json:"stage"`
+	// RealmName and StageName are denormalized for readers without the progression table
+	RealmName string  `json:"realm_name"`
+	StageName string  `json:"stage_name"`
+	Qi        float64 `json:"qi"`
+	// Bottleneck is set when the stage is complete and only a breakthrough advances further
+	Bottleneck bool `json:"bottleneck"`
+	// Attempts counts failed breakthroughs at the current bottleneck
+	Attempts  int       `json:"attempts"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// label refreshes the realm and stage names from the table.
+func (s *CultivationState) label(table ProgressionTable) {
+	if s.Realm >= len(table.Realms) {
+		s.Realm = len(table.Realms) - 1
+	}
+	if s.Stage >= len(table.Realms[s.Realm].Stages) {
+		s.Stage = len(table.Realms[s.Realm].Stages) - 1
+	}
+	s.RealmName = table.Realms[s.Realm].Name
+	s.StageName = table.Realms[s.Realm].Stages[s.Stage].Name
+}
+
+// gainQi accumulates qi and advances through stages until the next bottleneck.
+// Returns the number of stages advanced.
+func (s *CultivationState) gainQi(table ProgressionTable, qi float64) int {
+	s.label(table)
+	if s.Bottleneck {
+		return 0
+	}
+	s.Qi += qi
+	advanced := 0
+	for {
+		stage, _ := table.stage(s.Realm, s.Stage)
+		if s.Qi < stage.Qi {
+			break
+		}
+		if table.isBottleneck(s.Realm, s.Stage) {
+			// Qi above the requirement is lost: the bottleneck holds it back
+			s.Qi = stage.Qi
+			s.Bottleneck = true
+			break
+		}
+		s.Qi -= stage.Qi
+		s.Realm, s.Stage, _ = table.next(s.Realm, s.Stage)
+		advanced++
+	}
+	s.label(table)
+	return advanced
+}
+
+// breakthroughChance is the success probability of the next breakthrough attempt.
+func (s *CultivationState) breakthroughChance(table ProgressionTable) float64 {
+	stage, _ := table.stage(s.Realm, s.Stage)
+	return math.Min(stage.BreakthroughChance+float64(s.Attempts)*breakthroughAttemptBonus, maxBreakthroughChance)
+}
+
+// breakthrough attempts to pass the bottleneck; roll is uniform in [0, 1).
+// Returns the result and the chance the attempt had.
+func (s *CultivationState) breakthrough(table ProgressionTable, roll float64) (string, float64) {
+	s.label(table)
+	if !s.Bottleneck {
+		return BreakthroughNotReady, 0
+	}
+	realm, stage, ok := table.next(s.Realm, s.Stage)
+	if !ok {
+		return BreakthroughPeak, 0
+	}
+	chance := s.breakthroughChance(table)
+	if roll >= chance {
+		s.Qi *= 1 - breakthroughFailureQiLoss
+		s.Bottleneck = false
+		s.Attempts++
+		return BreakthroughFailed, chance
+	}
+	s.Realm, s.Stage = realm, stage
+	s.Qi = 0
+	s.Bottleneck = false
+	s.Attempts = 0
+	s.label(table)
+	return BreakthroughSuccess, chance
+}
+
+// playerRecord is what CultivationModule knows about a player.
+type playerRecord struct {
+	state CultivationState
+	// skills holds the skill and technique IDs the player knows; nil when the stored entity
+	// could not be read and known skills are not verified
+	skills map[string]bool
+}
+
+// StateStore keeps cultivation states and known skills in memory. Players not seen yet are read
+// from their entities in MinIO, which EntityManager keeps up to date from state_changes.
+type StateStore struct {
+	storage storage.ObjectStorage // nil — states start empty, skills are not verified
+
+	mu      sync.Mutex
+	players map[string]*playerRecord
+}
+
+// NewStateStore creates an in-memory state store; see UseStorage for loading stored states.
+func NewStateStore() *StateStore {
+	return &StateStore{players: make(map[string]*playerRecord)}
+}
+
+// UseStorage enables reading stored states from the entity buckets.
+func (st *StateStore) UseStorage(client storage.ObjectStorage) {
+	st.storage = client
+}
+
+// Update applies fn to the state of the player under the store lock and returns a copy of the result.
+func (st *StateStore) Update(playerID, worldID string, fn func(state *CultivationState)) CultivationState {
+	var state CultivationState
+	st.with(playerID, worldID, func(player *playerRecord) {
+		fn(&player.state)
+		player.state.UpdatedAt = time.Now().UTC()
+		state = player.state
+	})
+	return state
+}
+
+// KnowsSkill reports whether the player knows the skill; verified is false when the
+// known skills of the player could not be loaded.
+func (st *StateStore) KnowsSkill(playerID, worldID, skill string) (known, verified bool) {
+	st.with(playerID, worldID, func(player *playerRecord) {
+		known, verified = player.skills[skill], player.skills != nil
+	})
+	return known, verified
+}
+
+// with runs fn on the record of the player under the store lock, loading it on first use.
+func (st *StateStore) with(playerID, worldID string, fn func(player *playerRecord)) {
+	st.mu.Lock()
+	player, ok := st.players[playerID]
+	st.mu.Unlock()
+	if !ok {
+		player = st.load(playerID, worldID)
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if current, ok := st.players[playerID]; ok {
+		// Loaded concurrently by another event
+		player = current
+	}
+	st.players[playerID] = player
+	fn(player)
+}
+
+// load reads the cultivation and skills of the player entity; a missing entity or field starts from zero.
+func (st *StateStore) load(playerID, worldID string) *playerRecord {
+	player := &playerRecord{}
+	if st.storage == nil {
+		return player
+	}
+	for _, bucket := range []string{"entities-" + worldID, "entities-global"} {
+		data, err := st.storage.GetObject(bucket, playerID+".json")
+		if err != nil {
+			if !storage.IsNotFound(err) {
+				logging.Errorf("Failed to load cultivation of %s, starting from zero: %v", playerID, err)
+				return player
+			}
+			continue
+		}
+		if err := decodeCultivation(data, &player.state); err != nil {
+			logging.Warnf("Ignoring stored cultivation of %s: %v", playerID, err)
+		}
+		player.skills = decodeSkills(data)
+		return player
+	}
+	// No entity yet: the player knows nothing
+	player.skills = make(map[string]bool)
+	return player
+}
+
+// decodeCultivation reads the "cultivation" field of a stored entity into state.
+func decodeCultivation(data []byte, state *CultivationState) error {
+	var ent entity.Entity
+	if err := json.Unmarshal(data, &ent); err != nil {
+		return err
+	}
+	raw, ok := ent.Payload["cultivation"]
+	if !ok {
+		return nil
+	}
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(encoded, state); err != nil {
+		return fmt.Errorf("decode cultivation: %w", err)
+	}
+	return nil
+}
+
+// decodeSkills reads the "skills" list of a stored entity: IDs as strings or objects with an id field.
+// Returns nil when the entity cannot be decoded.
+func decodeSkills(data []byte) map[string]bool {
+	var ent entity.Entity
+	if err := json.Unmarshal(data, &ent); err != nil {
+		return nil
+	}
+	skills := make(map[string]bool)
+	list, _ := ent.Payload["skills"].([]interface{})
+	for _, item := range list {
+		switch v := item.(type) {
+		case string:
+			skills[v] = true
+		case map[string]interface{}:
+			if id, ok := v["id"].(string); ok {
+				skills[id] = true
+			}
+		}
+	}
+	return skills
+}
+
+// stateChanges builds the state_changes that make EntityManager store the state in the player entity.
+func stateChanges(playerID string, state CultivationState) []interface{} {
+	var value map[string]interface{}
+	encoded, _ := json.Marshal(state)
+	json.Unmarshal(encoded, &value)
+	return []interface{}{
+		map[string]interface{}{
+			"entity_id": playerID,
+			"operations": []interface{}{
+				map[string]interface{}{"op": "set", "path": "cultivation", "value": value},
+			},
+		},
+	}
+}
@@ -0,0 +1,282 @@
+package cultivationmodule
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+
+	"github.com/google/uuid"
+)
+
+// TechniqueEntityType is the type of technique entities in EntityManager.
+const TechniqueEntityType = "technique"
+
+// defaultTechniquePath is the path of techniques in worlds without a dao compatibility matrix.
+const defaultTechniquePath = "qi"
+
+// Reasons published in technique.learn.rejected and technique.forget.rejected.
+const (
+	TechniqueUnknown            = "unknown_technique"
+	TechniquePrerequisiteNotMet = "prerequisite_not_met"
+	TechniqueAlreadyKnown       = "already_known"
+	TechniqueNotKnown           = "not_known"
+)
+
+// Technique is a cultivation technique of a world. Learning it requires reaching
+// its realm and stage; a learned technique is a skill the player can use.
+type Technique struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	Realm     int    `json:"required_realm"`
+	Stage     int    `json:"required_stage"`
+	RealmName string `json:"required_realm_name"`
+	StageName string `json:"required_stage_name"`
+}
+
+// requirementMet reports whether the cultivation reaches the realm and stage of the technique.
+func (t Technique) requirementMet(state CultivationState) bool {
+	return state.Realm > t.Realm || (state.Realm == t.Realm && state.Stage >= t.Stage)
+}
+
+// buildTechniqueLibrary derives the techniques of a world: one per dao path and realm, learnable
+// from the first stage of the realm. IDs are stable, so the library is rebuilt rather than stored.
+func buildTechniqueLibrary(worldID string, paths []string, table ProgressionTable) []Technique {
+	if len(paths) == 0 {
+		paths = []string{defaultTechniquePath}
+	}
+	library := make([]Technique, 0, len(paths)*len(table.Realms))
+	for _, path := range paths {
+		for realm, r := range table.Realms {
+			library = append(library, Technique{
+				ID:        "technique-" + slug(worldID) + "-" + slug(path) + "-" + slug(r.Name),
+				Name:      path + " (" + r.Name + ")",
+				Path:      path,
+				Realm:     realm,
+				Stage:     0,
+				RealmName: r.Name,
+				StageName: r.Stages[0].Name,
+			})
+		}
+	}
+	return library
+}
+
+// slug lowercases s and replaces everything but letters and digits with underscores.
+func slug(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		default:
+			return '_'
+		}
+	}, strings.TrimSpace(s))
+}
+
+// techniqueLibrary returns the techniques of a world from its dao paths and progression table.
+func (cm *CultivationModule) techniqueLibrary(worldID string) []Technique {
+	matrix, _ := cm.daoMatrices.For(worldID)
+	return buildTechniqueLibrary(worldID, matrix.Paths, cm.progressions.For(worldID))
+}
+
+// lookupTechnique finds a technique of the world library by ID.
+func (cm *CultivationModule) lookupTechnique(worldID, techniqueID string) (Technique, bool) {
+	for _, technique := range cm.techniqueLibrary(worldID) {
+		if technique.ID == techniqueID {
+			return technique, true
+		}
+	}
+	return Technique{}, false
+}
+
+// handleWorldGenerated creates the technique entities of a new world.
+func (cm *CultivationModule) handleWorldGenerated(ev eventbus.Event) {
+	worldID := eventbus.GetWorldIDFromEvent(ev)
+	if worldID == "" {
+		logging.Warnf("World generated event missing world_id")
+		return
+	}
+
+	library := cm.techniqueLibrary(worldID)
+	for _, technique := range library {
+		payload := eventbus.NewEventPayload().
+			WithEntity(technique.ID, TechniqueEntityType, technique.Name).
+			WithWorld(worldID)
+
+		eventbus.SetNested(payload.GetCustom(), "payload.name", technique.Name)
+		eventbus.SetNested(payload.GetCustom(), "payload.path", technique.Path)
+		eventbus.SetNested(payload.GetCustom(), "payload.required_realm", technique.Realm)
+		eventbus.SetNested(payload.GetCustom(), "payload.required_stage", technique.Stage)
+		eventbus.SetNested(payload.GetCustom(), "payload.required_realm_name", technique.RealmName)
+		eventbus.SetNested(payload.GetCustom(), "payload.required_stage_name", technique.StageName)
+
+		event := eventbus.NewStructuredEvent("entity.created", "cultivation-module", worldID, payload)
+		cm.bus.Publish(context.Background(), eventbus.TopicSystemEvents, event)
+	}
+	logging.Infof("Created technique library of world %s: %d techniques", worldID, len(library))
+}
+
+// handleTechniqueLearn teaches a technique of the world library to a player whose cultivation reaches it.
+func (cm *CultivationModule) handleTechniqueLearn(ev eventbus.Event) {
+	pa := ev.Path()
+
+	// Извлечение playerID: новая структура entity.id → старая player_id
+	var playerID string
+	if entityInfo, ok := ev.GetEntityIDWithFallback(); ok {
+		playerID = entityInfo.ID
+	} else {
+		playerID, _ = pa.GetString("player_id")
+	}
+
+	techniqueID, _ := pa.GetString("technique_id")
+	if techniqueID == "" {
+		techniqueID, _ = pa.GetString("technique.id") // fallback на вложенную структуру
+	}
+
+	if playerID == "" || techniqueID == "" {
+		logging.Warnf("Technique learn attempt missing required fields")
+		return
+	}
+
+	worldID := eventbus.GetWorldIDFromEvent(ev)
+
+	technique, found := cm.lookupTechnique(worldID, techniqueID)
+	var reason string
+	var state CultivationState
+	cm.states.with(playerID, worldID, func(player *playerRecord) {
+		player.state.label(cm.progressions.For(worldID))
+		state = player.state
+		switch {
+		case !found:
+			reason = TechniqueUnknown
+		case player.skills[techniqueID]:
+			reason = TechniqueAlreadyKnown
+		case !technique.requirementMet(player.state):
+			reason = TechniquePrerequisiteNotMet
+		case player.skills != nil:
+			player.skills[techniqueID] = true
+		}
+	})
+
+	payload := eventbus.NewEventPayload().
+		WithEntity(playerID, "player", "").
+		WithWorld(worldID)
+
+	eventbus.SetNested(payload.GetCustom(), "technique_id", techniqueID)
+	eventbus.SetNested(payload.GetCustom(), "original_event", ev.ID)
+	if found {
+		eventbus.SetNested(payload.GetCustom(), "technique", technique)
+	}
+
+	eventType := "technique.learned"
+	if reason != "" {
+		eventType = "technique.learn.rejected"
+		eventbus.SetNested(payload.GetCustom(), "reason", reason)
+		eventbus.SetNested(payload.GetCustom(), "cultivation.realm", state.RealmName)
+		eventbus.SetNested(payload.GetCustom(), "cultivation.stage", state.StageName)
+	} else {
+		// EntityManager adds the technique to the player's skills
+		eventbus.SetNested(payload.GetCustom(), "state_changes", skillChanges(playerID, "add_to_slice", techniqueID))
+	}
+
+	learnEvent := eventbus.NewStructuredEvent(eventType, "cultivation-module", worldID, payload)
+	learnEvent.ID = "technique-learn-" + uuid.New().String()[:8]
+	learnEvent.Timestamp = time.Now()
+	learnEvent.Scope = eventbus.GetScopeFromEvent(ev)
+
+	cm.bus.Publish(context.Background(), eventbus.TopicWorldEvents, learnEvent)
+
+	logging.Infof("Technique %s for %s in %s: %s %s", techniqueID, playerID, worldID, eventType, reason)
+}
+
+// handleTechniqueForget removes a known technique or skill from a player.
+func (cm *CultivationModule) handleTechniqueForget(ev eventbus.Event) {
+	pa := ev.Path()
+
+	// Извлечение playerID: новая структура entity.id → старая player_id
+	var playerID string
+	if entityInfo, ok := ev.GetEntityIDWithFallback(); ok {
+		playerID = entityInfo.ID
+	} else {
+		playerID, _ = pa.GetString("player_id")
+	}
+
+	techniqueID, _ := pa.GetString("technique_id")
+	if techniqueID == "" {
+		techniqueID, _ = pa.GetString("technique.id") // fallback на вложенную структуру
+	}
+
+	if playerID == "" || techniqueID == "" {
+		logging.Warnf("Technique forget attempt missing required fields")
+		return
+	}
+
+	worldID := eventbus.GetWorldIDFromEvent(ev)
+
+	var known bool
+	cm.states.with(playerID, worldID, func(player *playerRecord) {
+		// Without verified skills the request is trusted
+		known = player.skills == nil || player.skills[techniqueID]
+		delete(player.skills, techniqueID)
+	})
+
+	payload := eventbus.NewEventPayload().
+		WithEntity(playerID, "player", "").
+		WithWorld(worldID)
+
+	eventbus.SetNested(payload.GetCustom(), "technique_id", techniqueID)
+	eventbus.SetNested(payload.GetCustom(), "original_event", ev.ID)
+
+	eventType := "technique.forgotten"
+	if known {
+		// EntityManager removes the technique from the player's skills
+		eventbus.SetNested(payload.GetCustom(), "state_changes", skillChanges(playerID, "remove_from_slice", techniqueID))
+	} else {
+		eventType = "technique.forget.rejected"
+		eventbus.SetNested(payload.GetCustom(), "reason", TechniqueNotKnown)
+	}
+
+	forgetEvent := eventbus.NewStructuredEvent(eventType, "cultivation-module", worldID, payload)
+	forgetEvent.ID = "technique-forget-" + uuid.New().String()[:8]
+	forgetEvent.Timestamp = time.Now()
+	forgetEvent.Scope = eventbus.GetScopeFromEvent(ev)
+
+	cm.bus.Publish(context.Background(), eventbus.TopicWorldEvents, forgetEvent)
+}
+
+// publishSkillUnknown reports a skill used by a player who does not know it.
+func (cm *CultivationModule) publishSkillUnknown(ev eventbus.Event, playerID, worldID, skill string) {
+	payload := eventbus.NewEventPayload().
+		WithEntity(playerID, "player", "").
+		WithWorld(worldID)
+
+	eventbus.SetNested(payload.GetCustom(), "skill", skill)
+	eventbus.SetNested(payload.GetCustom(), "original_event", ev.ID)
+
+	unknownEvent := eventbus.NewStructuredEvent("skill.unknown", "cultivation-module", worldID, payload)
+	unknownEvent.ID = "skill-unknown-" + uuid.New().String()[:8]
+	unknownEvent.Timestamp = time.Now()
+	unknownEvent.Scope = eventbus.GetScopeFromEvent(ev)
+
+	cm.bus.Publish(context.Background(), eventbus.TopicWorldEvents, unknownEvent)
+
+	logging.Warnf("Rejected skill %s of %s in %s: skill unknown", skill, playerID, worldID)
+}
+
+// skillChanges builds the state_changes that add or remove a skill of the player entity.
+func skillChanges(playerID, op, skill string) []interface{} {
+	return []interface{}{
+		map[string]interface{}{
+			"entity_id": playerID,
+			"operations": []interface{}{
+				map[string]interface{}{"op": op, "path": "skills", "value": skill},
+			},
+		},
+	}
+}
@@ -0,0 +1,68 @@
+package cultivationmodule
+
+import (
+	"testing"
+
+	"multiverse-core.io/shared/minio/miniotest"
+)
+
+func TestBuildTechniqueLibrary(t *testing.T) {
+	library := buildTechniqueLibrary("world-1", []string{"Flame Dao", "Sword"}, defaultProgression)
+	if len(library) != 6 {
+		t.Fatalf("expected a technique per path and realm, got %d", len(library))
+	}
+	first := library[0]
+	if first.ID != "technique-world_1-flame_dao-qi_condensation" || first.Path != "Flame Dao" || first.Realm != 0 || first.StageName != "early" {
+		t.Errorf("unexpected first technique: %+v", first)
+	}
+	if library[5].RealmName != "golden_core" || library[5].Realm != 2 {
+		t.Errorf("unexpected last technique: %+v", library[5])
+	}
+
+	if fallback := buildTechniqueLibrary("world-1", nil, defaultProgression); len(fallback) != 3 || fallback[0].Path != defaultTechniquePath {
+		t.Errorf("worlds without dao paths get %s techniques, got %+v", defaultTechniquePath, fallback)
+	}
+}
+
+func TestTechniqueRequirement(t *testing.T) {
+	technique := Technique{Realm: 1, Stage: 1}
+	cases := []struct {
+		state CultivationState
+		met   bool
+	}{
+		{CultivationState{Realm: 0, Stage: 2}, false},
+		{CultivationState{Realm: 1, Stage: 0}, false},
+		{CultivationState{Realm: 1, Stage: 1}, true},
+		{CultivationState{Realm: 2, Stage: 0}, true},
+	}
+	for _, c := range cases {
+		if got := technique.requirementMet(c.state); got != c.met {
+			t.Errorf("requirement at %d/%d: got %v, want %v", c.state.Realm, c.state.Stage, got, c.met)
+		}
+	}
+}
+
+func TestKnowsSkill(t *testing.T) {
+	store := NewStateStore()
+	entities := miniotest.New()
+	entities.Put("entities-world-1", "player-1.json", `{"entity_id": "player-1", "entity_type": "player", "payload": {"skills": ["fireball", {"id": "technique-1"}]}}`)
+	store.UseStorage(entities)
+
+	for _, skill := range []string{"fireball", "technique-1"} {
+		if known, verified := store.KnowsSkill("player-1", "world-1", skill); !known || !verified {
+			t.Errorf("stored skill %s: known %v, verified %v", skill, known, verified)
+		}
+	}
+	if known, verified := store.KnowsSkill("player-1", "world-1", "ice_lance"); known || !verified {
+		t.Errorf("unknown skill: known %v, verified %v", known, verified)
+	}
+	// A player without an entity knows nothing yet
+	if known, verified := store.KnowsSkill("player-2", "world-1", "fireball"); known || !verified {
+		t.Errorf("player without entity: known %v, verified %v", known, verified)
+	}
+
+	// Without storage skills cannot be verified
+	if _, verified := NewStateStore().KnowsSkill("player-1", "world-1", "fireball"); verified {
+		t.Error("skills must not be verified without storage")
+	}
+}
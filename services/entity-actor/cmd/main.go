@@ -2,68 +2,27 @@
 package main
 
 import (
-	"context"
 	"log"
-	"os"
-	"os/signal"
-	"strings"
-	"syscall"
 
 	"multiverse-core.io/services/entity-actor/entityactor"
+	"multiverse-core.io/shared/service"
 )
 
 func main() {
-	// Конфигурация из окружения
+	app := service.Setup("entity-actor")
+	env := app.Env
+
 	cfg := entityactor.Config{
-		KafkaBrokers:   getEnvBrokers("KAFKA_BROKERS", []string{"redpanda:9092"}),
-		MinioEndpoint:  getEnv("MINIO_ENDPOINT", "minio:9000"),
-		MinioAccessKey: getEnv("MINIO_ACCESS_KEY", "minioadmin"),
-		MinioSecretKey: getEnv("MINIO_SECRET_KEY", "minioadmin"),
+		KafkaBrokers:   env.List("KAFKA_BROKERS"),
+		MinioEndpoint:  env.String("MINIO_ENDPOINT"),
+		MinioAccessKey: env.String("MINIO_ACCESS_KEY"),
+		MinioSecretKey: env.String("MINIO_SECRET_KEY"),
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	// Graceful shutdown
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	go func() {
-		<-sigChan
-		log.Println("Shutting down EntityActor...")
-		cancel()
-	}()
-
-	// Запуск сервиса
-	service, err := entityactor.NewService(cfg)
+	actors, err := entityactor.NewService(cfg)
 	if err != nil {
 		log.Fatal("Failed to initialize EntityActor:", err)
 	}
 
-	service.Start(ctx)
-	<-ctx.Done()
-	service.Stop()
-	log.Println("EntityActor stopped.")
-}
-
-func getEnv(key, fallback string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return fallback
-}
-
-func getEnvBrokers(key string, fallback []string) []string {
-	if value := os.Getenv(key); value != "" {
-		parts := strings.Split(value, ",")
-		brokers := make([]string, 0, len(parts))
-		for _, part := range parts {
-			if trimmed := strings.TrimSpace(part); trimmed != "" {
-				brokers = append(brokers, trimmed)
-			}
-		}
-		if len(brokers) > 0 {
-			return brokers
-		}
-	}
-	return fallback
+	app.Run(service.Background(actors.Start, actors.Stop))
 }
@@ -10,6 +10,7 @@ import (
 
 	"multiverse-core.io/shared/eventbus"
 	"multiverse-core.io/shared/intent"
+	"multiverse-core.io/shared/logging"
 	"multiverse-core.io/shared/redis"
 	"multiverse-core.io/shared/rules"
 	"multiverse-core.io/shared/tinyml"
@@ -459,7 +460,7 @@ func (a *Actor) publishResult(result *rules.RuleResult, intent *intent.IntentRes
 		}
 
 		if err := eventbus.ValidateEventRelations(publishEvent); err != nil {
-			log.Printf("Invalid relations in entity-actor publishResult: %v", err)
+			logging.Warnf("Invalid relations in entity-actor publishResult: %v", err)
 			publishEvent.Relations = nil
 		}
 	}
@@ -8,7 +8,9 @@ import (
 	"time"
 
 	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/eventbus/events"
 	"multiverse-core.io/shared/intent"
+	"multiverse-core.io/shared/logging"
 	"multiverse-core.io/shared/minio"
 	"multiverse-core.io/shared/redis"
 	"multiverse-core.io/shared/rules"
@@ -45,7 +47,7 @@ type Service struct {
 
 // NewService создает новый сервис EntityActor
 func NewService(cfg Config) (*Service, error) {
-	logger := log.New(log.Writer(), "EntityActor: ", log.LstdFlags|log.Lshortfile)
+	logger := logging.StdLogger("EntityActor")
 
 	// Валидация конфигурации
 	if err := validateConfig(cfg); err != nil {
@@ -94,7 +96,7 @@ func NewService(cfg Config) (*Service, error) {
 
 	// Инициализация Manager
 	manager := NewManager(
-		log.New(log.Writer(), "EntityActorManager: ", log.LstdFlags|log.Lshortfile),
+		logging.StdLogger("EntityActorManager"),
 		eventBus,
 		minioClient,
 		redisClient,
@@ -184,7 +186,7 @@ func (s *Service) handleEvent(ctx context.Context, ev eventbus.Event) {
 		s.handlePlayerAction(ctx, ev)
 	case "player.moved":
 		s.handlePlayerMoved(ctx, ev)
-	case "player.used_skill":
+	case events.TypePlayerUsedSkill:
 		s.handlePlayerUsedSkill(ctx, ev)
 	case "player.used_item":
 		s.handlePlayerUsedItem(ctx, ev)
@@ -439,24 +441,13 @@ func (s *Service) handlePlayerMoved(ctx context.Context, ev eventbus.Event) {
 func (s *Service) handlePlayerUsedSkill(ctx context.Context, ev eventbus.Event) {
 	s.logger.Printf("Handling player used skill event")
 
-	// Извлекаем entity ID с поддержкой новой структуры
-	entityInfo, ok := ev.GetEntityIDWithFallback()
-	var entityID string
-	if ok && entityInfo != nil {
-		entityID = entityInfo.ID
-	}
-
-	pa := ev.Path()
-	skillID, _ := pa.GetString("skill_id")
-
-	// Извлекаем target ID с поддержкой новой структуры
-	targetInfo, ok := ev.GetTargetEntityID()
-	var targetID string
-	if ok && targetInfo != nil {
-		targetID = targetInfo.ID
+	var used events.PlayerUsedSkill
+	if err := events.Unmarshal(ev, &used); err != nil {
+		s.logger.Printf("Invalid player.used_skill event %s: %v", ev.ID, err)
+		return
 	}
 
-	s.logger.Printf("Player %s used skill %s on target %s", entityID, skillID, targetID)
+	s.logger.Printf("Player %s used skill %s on target %s", used.EntityID(), used.SkillName(), used.TargetID())
 	// В production: применить эффект навыка
 }
 
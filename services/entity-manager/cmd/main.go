@@ -2,68 +2,45 @@
 package main
 
 import (
-	"context"
 	"log"
-	"os"
-	"os/signal"
-	"strings"
-	"syscall"
 
 	"multiverse-core.io/services/entity-manager/entitymanager"
+	"multiverse-core.io/shared/config"
+	"multiverse-core.io/shared/service"
 )
 
 func main() {
-	// Конфигурация из окружения
+	app := service.Setup("entity-manager", []config.Option{
+		{Env: "ARCHIVIST_URL", Default: "http://ontological-archivist:8081", Type: config.TypeURL, Usage: "резервный адрес архивариуса"},
+		{Env: "ENTITY_MANAGER_PORT", Default: "8085", Type: config.TypeInt, Positive: true},
+		{Env: "ENTITY_HISTORY_VERSIONS", Default: "20", Type: config.TypeInt, Usage: "версий снапшота на сущность"},
+		{Env: "ENTITY_CACHE_SIZE", Default: "1000", Type: config.TypeInt, Usage: "сущностей в кэше записи, отрицательное значение отключает кэш"},
+		{Env: "ENTITY_CACHE_FLUSH_INTERVAL_MS", Default: "2000", Type: config.TypeMillis, Usage: "период сброса кэша в MinIO"},
+		{Env: "ENTITY_MANAGER_GROUP", Default: entitymanager.DefaultConsumerGroup, Usage: "группа потребителей Kafka для топиков сущностей"},
+		{Env: "ENTITY_MANAGER_WORKERS", Default: "4", Type: config.TypeInt, Positive: true, Usage: "обработчиков событий; события одной сущности обрабатываются по порядку"},
+	})
+	env := app.Env
+
 	cfg := entitymanager.Config{
-		MinioEndpoint:  getEnv("MINIO_ENDPOINT", "minio:9000"),
-		MinioAccessKey: getEnv("MINIO_ACCESS_KEY", "minioadmin"),
-		MinioSecretKey: getEnv("MINIO_SECRET_KEY", "minioadmin"),
-		KafkaBrokers:   getEnvBrokers("KAFKA_BROKERS", []string{"redpanda:9092"}),
+		MinioEndpoint:  env.String("MINIO_ENDPOINT"),
+		MinioAccessKey: env.String("MINIO_ACCESS_KEY"),
+		MinioSecretKey: env.String("MINIO_SECRET_KEY"),
+		KafkaBrokers:   env.List("KAFKA_BROKERS"),
+		ArchivistURL:   env.String("ARCHIVIST_URL"),
+		HTTPPort:       env.String("ENTITY_MANAGER_PORT"),
+
+		HistoryVersions:    env.Int("ENTITY_HISTORY_VERSIONS"),
+		CacheSize:          env.Int("ENTITY_CACHE_SIZE"),
+		CacheFlushInterval: env.Duration("ENTITY_CACHE_FLUSH_INTERVAL_MS"),
+		ConsumerGroup:      env.String("ENTITY_MANAGER_GROUP"),
+		Workers:            env.Int("ENTITY_MANAGER_WORKERS"),
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	// Graceful shutdown
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	go func() {
-		<-sigChan
-		log.Println("Shutting down EntityManager...")
-		cancel()
-	}()
-
-	// Запуск сервиса
-	service, err := entitymanager.NewService(cfg)
+	manager, err := entitymanager.NewService(cfg)
 	if err != nil {
 		log.Fatal("Failed to initialize EntityManager:", err)
 	}
 
-	service.Start(ctx)
-	<-ctx.Done()
-	service.Stop()
-	log.Println("EntityManager stopped.")
-}
-
-func getEnv(key, fallback string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return fallback
-}
-
-func getEnvBrokers(key string, fallback []string) []string {
-	if value := os.Getenv(key); value != "" {
-		parts := strings.Split(value, ",")
-		brokers := make([]string, 0, len(parts))
-		for _, part := range parts {
-			if trimmed := strings.TrimSpace(part); trimmed != "" {
-				brokers = append(brokers, trimmed)
-			}
-		}
-		if len(brokers) > 0 {
-			return brokers
-		}
-	}
-	return fallback
+	// Run stops gracefully: consumers, queued events, then the write-back cache
+	app.Run(manager)
 }
@@ -0,0 +1,300 @@
+// services/entitymanager/archive.go
+package entitymanager
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"multiverse-core.io/shared/entity"
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/eventbus/events"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+)
+
+// Archive layout: entities/{entity_id}.json followed by manifest.json with the checksums.
+// The manifest is written last because the checksums are computed while streaming the entities.
+const (
+	archiveFormatVersion = 1
+	archiveManifestName  = "manifest.json"
+	archiveEntitiesDir   = "entities/"
+)
+
+// ErrInvalidArchive is returned when an import archive is malformed or fails its checksums.
+var ErrInvalidArchive = errors.New("invalid entity archive")
+
+// ArchiveEntry describes one entity in the archive manifest.
+type ArchiveEntry struct {
+	ID     string `json:"id"`
+	Type   string `json:"type"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// ArchiveManifest lists the entities of an exported world.
+type ArchiveManifest struct {
+	FormatVersion int            `json:"format_version"`
+	WorldID       string         `json:"world_id"`
+	ExportedAt    time.Time      `json:"exported_at"`
+	EntityCount   int            `json:"entity_count"`
+	Entities      []ArchiveEntry `json:"entities"`
+}
+
+// ImportOptions controls an archive import.
+type ImportOptions struct {
+	// WorldID is the target world; empty imports into the world of the manifest
+	WorldID string
+	// Overwrite replaces existing entities; otherwise they are skipped
+	Overwrite bool
+	// Republish publishes entity.created for every imported entity
+	Republish bool
+}
+
+// ImportResult summarizes an archive import.
+type ImportResult struct {
+	SourceWorldID string   `json:"source_world_id"`
+	WorldID       string   `json:"world_id"`
+	Imported      int      `json:"imported"`
+	Skipped       []string `json:"skipped,omitempty"`
+	Republished   int      `json:"republished"`
+}
+
+// isEntityKey reports whether a bucket key is an entity snapshot, not a version or an index.
+func isEntityKey(key string) bool {
+	return strings.HasSuffix(key, ".json") && !strings.Contains(key, "/")
+}
+
+// ExportWorld writes all entities of a world as a gzip-compressed tar archive to w.
+// Cached changes are flushed first so the archive matches the latest state.
+func (m *Manager) ExportWorld(ctx context.Context, worldID string, w io.Writer) (*ArchiveManifest, error) {
+	if m.cache != nil {
+		m.cache.Flush(ctx)
+	}
+
+	bucket := bucketForWorld(worldID)
+	objects, err := m.minio.ListObjects(bucket, "")
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for _, info := range objects {
+		if isEntityKey(info.Key) {
+			keys = append(keys, info.Key)
+		}
+	}
+	sort.Strings(keys)
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	manifest := &ArchiveManifest{
+		FormatVersion: archiveFormatVersion,
+		WorldID:       worldID,
+		ExportedAt:    time.Now().UTC(),
+		Entities:      []ArchiveEntry{},
+	}
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		data, err := m.minio.GetObject(bucket, key)
+		if err != nil {
+			if storage.IsNotFound(err) {
+				continue // deleted while exporting
+			}
+			return nil, err
+		}
+		var ent entity.Entity
+		if err := json.Unmarshal(data, &ent); err != nil || ent.ID == "" {
+			logging.Warnf("Skipping corrupted entity %s/%s in export: %v", bucket, key, err)
+			continue
+		}
+
+		sum := sha256.Sum256(data)
+		if err := writeTarFile(tw, archiveEntitiesDir+ent.ID+".json", data, manifest.ExportedAt); err != nil {
+			return nil, err
+		}
+		manifest.Entities = append(manifest.Entities, ArchiveEntry{
+			ID:     ent.ID,
+			Type:   ent.Type,
+			Size:   int64(len(data)),
+			SHA256: hex.EncodeToString(sum[:]),
+		})
+	}
+	manifest.EntityCount = len(manifest.Entities)
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeTarFile(tw, archiveManifestName, data, manifest.ExportedAt); err != nil {
+		return nil, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	logging.Infof("Exported %d entities of world %s", manifest.EntityCount, worldID)
+	return manifest, nil
+}
+
+func writeTarFile(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	header := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), ModTime: modTime}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// readArchive reads an archive and verifies every entity against the manifest checksums.
+// Nothing is imported from an archive that fails verification.
+func readArchive(r io.Reader) (*ArchiveManifest, map[string][]byte, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+	}
+	defer gz.Close()
+
+	var manifest *ArchiveManifest
+	files := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+		}
+		switch name := path.Clean(header.Name); {
+		case name == archiveManifestName:
+			manifest = &ArchiveManifest{}
+			if err := json.Unmarshal(data, manifest); err != nil {
+				return nil, nil, fmt.Errorf("%w: manifest: %v", ErrInvalidArchive, err)
+			}
+		case strings.HasPrefix(name, archiveEntitiesDir):
+			files[strings.TrimSuffix(strings.TrimPrefix(name, archiveEntitiesDir), ".json")] = data
+		}
+	}
+
+	if manifest == nil {
+		return nil, nil, fmt.Errorf("%w: no %s", ErrInvalidArchive, archiveManifestName)
+	}
+	if manifest.FormatVersion != archiveFormatVersion {
+		return nil, nil, fmt.Errorf("%w: unsupported format version %d", ErrInvalidArchive, manifest.FormatVersion)
+	}
+	if len(manifest.Entities) != manifest.EntityCount || len(files) != manifest.EntityCount {
+		return nil, nil, fmt.Errorf("%w: manifest lists %d entities, archive holds %d", ErrInvalidArchive, manifest.EntityCount, len(files))
+	}
+	for _, entry := range manifest.Entities {
+		data, ok := files[entry.ID]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: entity %s is missing", ErrInvalidArchive, entry.ID)
+		}
+		sum := sha256.Sum256(data)
+		if int64(len(data)) != entry.Size || hex.EncodeToString(sum[:]) != entry.SHA256 {
+			return nil, nil, fmt.Errorf("%w: checksum mismatch for entity %s", ErrInvalidArchive, entry.ID)
+		}
+	}
+	return manifest, files, nil
+}
+
+// ImportWorld imports an archive written by ExportWorld into opts.WorldID (or the exported world).
+// Entities keep their history entries; their world reference is moved to the target world.
+// Existing entities are skipped unless opts.Overwrite is set.
+func (m *Manager) ImportWorld(ctx context.Context, r io.Reader, opts ImportOptions) (*ImportResult, error) {
+	manifest, files, err := readArchive(r)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Republish && m.publish == nil {
+		return nil, fmt.Errorf("republishing imported entities requires an event bus")
+	}
+
+	worldID := opts.WorldID
+	if worldID == "" {
+		worldID = manifest.WorldID
+	}
+	bucket := bucketForWorld(worldID)
+	result := &ImportResult{SourceWorldID: manifest.WorldID, WorldID: worldID}
+
+	for _, entry := range manifest.Entities {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		var ent entity.Entity
+		if err := json.Unmarshal(files[entry.ID], &ent); err != nil || ent.ID != entry.ID {
+			return result, fmt.Errorf("%w: entity %s cannot be decoded", ErrInvalidArchive, entry.ID)
+		}
+
+		if !opts.Overwrite {
+			_, err := m.loadEntityFromBucket(ctx, bucket, ent.ID)
+			if err == nil {
+				result.Skipped = append(result.Skipped, ent.ID)
+				continue
+			}
+			if !storage.IsNotFound(err) {
+				return result, err
+			}
+		}
+
+		if ent.World != nil {
+			ent.World.ID = worldID
+		}
+		if err := m.storeEntity(ctx, bucket, &ent); err != nil {
+			return result, fmt.Errorf("failed to import entity %s: %w", ent.ID, err)
+		}
+		result.Imported++
+
+		if opts.Republish {
+			if err := m.publish(ctx, importedEntityCreated(&ent, worldID)); err != nil {
+				logging.Errorf("Failed to republish imported entity %s: %v", ent.ID, err)
+				continue
+			}
+			result.Republished++
+		}
+	}
+	logging.Infof("Imported %d entities of world %s into %s (%d skipped)", result.Imported, manifest.WorldID, worldID, len(result.Skipped))
+	return result, nil
+}
+
+// storeEntity writes an entity as is, through the write-back cache when it is enabled.
+func (m *Manager) storeEntity(ctx context.Context, bucket string, ent *entity.Entity) error {
+	if m.cache != nil {
+		m.cache.Put(ctx, bucket, ent)
+		return nil
+	}
+	return m.writeEntity(ctx, bucket, ent)
+}
+
+// importedEntityCreated announces an imported entity. The imported flag tells HandleEvent
+// that the entity is already stored with its history.
+func importedEntityCreated(ent *entity.Entity, worldID string) eventbus.Event {
+	name, _ := ent.Payload["name"].(string)
+	payload := eventbus.NewEventPayload().
+		WithEntity(ent.ID, ent.Type, name).
+		WithWorld(worldID)
+	eventbus.SetNested(payload.GetCustom(), "payload", ent.Payload)
+	eventbus.SetNested(payload.GetCustom(), "imported", true)
+	return eventbus.NewStructuredEvent(events.TypeEntityCreated, "entity-manager", worldID, payload)
+}
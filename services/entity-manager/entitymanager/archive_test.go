@@ -0,0 +1,116 @@
+// services/entitymanager/archive_test.go
+package entitymanager
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"multiverse-core.io/shared/entity"
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/minio/miniotest"
+)
+
+func TestExportImportWorld(t *testing.T) {
+	ctx := context.Background()
+	source := NewManagerWithStorage(miniotest.New())
+	for _, ent := range []*entity.Entity{
+		{ID: "city-1", Type: "city", Payload: map[string]interface{}{"name": "Вельград"}, World: &entity.WorldRef{ID: "world-1"}},
+		{ID: "npc-1", Type: "npc", Payload: map[string]interface{}{"name": "Борислав"}, History: []entity.HistoryEntry{{EventID: "ev-1"}}},
+	} {
+		if err := source.writeEntity(ctx, "entities-world-1", ent); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var archive bytes.Buffer
+	manifest, err := source.ExportWorld(ctx, "world-1", &archive)
+	if err != nil || manifest.EntityCount != 2 || manifest.Entities[0].ID != "city-1" || manifest.Entities[0].SHA256 == "" {
+		t.Fatalf("unexpected manifest %+v (%v)", manifest, err)
+	}
+
+	var published []eventbus.Event
+	target := NewManagerWithStorage(miniotest.New())
+	target.publish = func(ctx context.Context, event eventbus.Event) error {
+		published = append(published, event)
+		return nil
+	}
+	existing := &entity.Entity{ID: "npc-1", Type: "npc", Payload: map[string]interface{}{"name": "Другой"}}
+	if err := target.writeEntity(ctx, "entities-staging", existing); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := target.ImportWorld(ctx, bytes.NewReader(archive.Bytes()), ImportOptions{WorldID: "staging", Republish: true})
+	if err != nil || result.Imported != 1 || len(result.Skipped) != 1 || result.Republished != 1 {
+		t.Fatalf("unexpected import result %+v (%v)", result, err)
+	}
+	city, err := target.loadEntityFromBucket(ctx, "entities-staging", "city-1")
+	if err != nil || city.World.ID != "staging" || city.Payload["name"] != "Вельград" {
+		t.Errorf("expected the city moved to the target world, got %+v (%v)", city, err)
+	}
+	if imported, _ := published[0].Path().GetBool("imported"); !imported || published[0].Type != "entity.created" {
+		t.Errorf("unexpected republished event %+v", published[0])
+	}
+
+	result, err = target.ImportWorld(ctx, bytes.NewReader(archive.Bytes()), ImportOptions{WorldID: "staging", Overwrite: true})
+	if err != nil || result.Imported != 2 {
+		t.Fatalf("expected overwrite of both entities, got %+v (%v)", result, err)
+	}
+	if npc, _ := target.loadEntityFromBucket(ctx, "entities-staging", "npc-1"); npc.Payload["name"] != "Борислав" || len(npc.History) != 1 {
+		t.Errorf("expected the archived npc with its history, got %+v", npc)
+	}
+}
+
+func TestImportRejectsCorruptedArchive(t *testing.T) {
+	ctx := context.Background()
+	source := NewManagerWithStorage(miniotest.New())
+	source.writeEntity(ctx, "entities-world-1", &entity.Entity{ID: "npc-1", Type: "npc", Payload: map[string]interface{}{"name": "Борислав"}})
+
+	var archive bytes.Buffer
+	if _, err := source.ExportWorld(ctx, "world-1", &archive); err != nil {
+		t.Fatal(err)
+	}
+	tampered := tamperArchive(t, archive.Bytes())
+	data := archive.Bytes()
+	data[len(data)/2] ^= 0xff
+
+	target := NewManagerWithStorage(miniotest.New())
+	for name, body := range map[string][]byte{"corrupted": data, "not an archive": []byte("{}"), "tampered": tampered} {
+		if _, err := target.ImportWorld(ctx, bytes.NewReader(body), ImportOptions{}); !errors.Is(err, ErrInvalidArchive) {
+			t.Errorf("%s: expected ErrInvalidArchive, got %v", name, err)
+		}
+	}
+	if _, err := target.loadEntityFromBucket(ctx, "entities-world-1", "npc-1"); err == nil {
+		t.Error("nothing must be imported from an invalid archive")
+	}
+}
+
+// tamperArchive rewrites the entities of an archive, keeping its manifest.
+func tamperArchive(t *testing.T, archive []byte) []byte {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	zw := gzip.NewWriter(&out)
+	tw := tar.NewWriter(zw)
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		data, _ := io.ReadAll(tr)
+		if header.Name != archiveManifestName {
+			data = bytes.Replace(data, []byte("Борислав"), []byte("Самозванец"), 1)
+		}
+		writeTarFile(tw, header.Name, data, header.ModTime)
+	}
+	tw.Close()
+	zw.Close()
+	return out.Bytes()
+}
@@ -0,0 +1,246 @@
+// services/entitymanager/cache.go
+package entitymanager
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/entity"
+	"multiverse-core.io/shared/logging"
+)
+
+// Write-back cache defaults.
+const (
+	DefaultCacheSize          = 1000
+	DefaultCacheFlushInterval = 2 * time.Second
+)
+
+// cacheEntry is a cached entity of a bucket.
+type cacheEntry struct {
+	key    string
+	bucket string
+	ent    *entity.Entity
+	dirty  bool
+	// version grows with every write, so a flush never marks a newer change as clean
+	version uint64
+}
+
+// writeLockStripes is the number of locks serializing the writes of entities.
+const writeLockStripes = 64
+
+// EntityCache is an LRU write-back cache of hot entities. Writes only mark entities dirty;
+// dirty entities are persisted by Flush (periodically and on shutdown) or when evicted.
+// An evicted dirty entity stays pending, and readable, until its write succeeds; failed
+// writes are retried by the next flush, so pending entities may exceed the capacity
+// while storage is down. Entities are copied in and out, so callers may mutate what they get.
+type EntityCache struct {
+	capacity int
+	write    func(ctx context.Context, bucket string, ent *entity.Entity) error
+	// writeLocks serialize the writes of an entity, so an older version never lands after a newer one
+	writeLocks [writeLockStripes]sync.Mutex
+
+	mu      sync.Mutex
+	items   map[string]*list.Element
+	order   *list.List // front is the most recently used
+	pending map[string]*cacheEntry
+	version uint64
+}
+
+// NewEntityCache creates a cache of up to capacity entities persisted through write.
+func NewEntityCache(capacity int, write func(ctx context.Context, bucket string, ent *entity.Entity) error) *EntityCache {
+	return &EntityCache{
+		capacity: capacity,
+		write:    write,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+		pending:  make(map[string]*cacheEntry),
+	}
+}
+
+// Get returns a copy of a cached entity.
+func (c *EntityCache) Get(bucket, entityID string) (*entity.Entity, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := bucket + "/" + entityID
+	if elem, ok := c.items[key]; ok {
+		c.order.MoveToFront(elem)
+		return cloneEntity(elem.Value.(*cacheEntry).ent), true
+	}
+	// Storage does not have the evicted changes yet
+	if entry, ok := c.pending[key]; ok {
+		return cloneEntity(entry.ent), true
+	}
+	return nil, false
+}
+
+// Put stores a changed entity; it is persisted by the next flush.
+func (c *EntityCache) Put(ctx context.Context, bucket string, ent *entity.Entity) {
+	c.store(ctx, bucket, ent, true)
+}
+
+// Add caches an entity just loaded from storage without marking it dirty.
+// A cached version is kept: it may hold changes that are not flushed yet.
+func (c *EntityCache) Add(ctx context.Context, bucket string, ent *entity.Entity) {
+	c.store(ctx, bucket, ent, false)
+}
+
+func (c *EntityCache) store(ctx context.Context, bucket string, ent *entity.Entity, dirty bool) {
+	key := bucket + "/" + ent.ID
+	copied := cloneEntity(ent)
+
+	c.mu.Lock()
+	if elem, ok := c.items[key]; ok {
+		c.order.MoveToFront(elem)
+		if dirty {
+			c.version++
+			entry := elem.Value.(*cacheEntry)
+			entry.ent, entry.dirty, entry.version = copied, true, c.version
+		}
+		c.mu.Unlock()
+		return
+	}
+
+	entry := &cacheEntry{key: key, bucket: bucket, ent: copied, dirty: dirty}
+	if pending, ok := c.pending[key]; ok {
+		// An evicted entity comes back; unless replaced, its unwritten changes win over storage
+		delete(c.pending, key)
+		if !dirty {
+			entry = pending
+		}
+	}
+	if entry.version == 0 {
+		c.version++
+		entry.version = c.version
+	}
+	c.items[key] = c.order.PushFront(entry)
+
+	// Evicted dirty entities are written right away, outside the lock
+	var evicted []string
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		entry := oldest.Value.(*cacheEntry)
+		c.order.Remove(oldest)
+		delete(c.items, entry.key)
+		if entry.dirty {
+			c.pending[entry.key] = entry
+			evicted = append(evicted, entry.key)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, key := range evicted {
+		if _, err := c.writeKey(ctx, key); err != nil {
+			logging.Errorf("Failed to write evicted entity %s, retrying on next flush: %v", key, err)
+		}
+	}
+}
+
+// writeKey writes the current version of a dirty entity and marks it clean, unless it changed
+// meanwhile. It reports whether anything was written; a failed write leaves the entity dirty.
+func (c *EntityCache) writeKey(ctx context.Context, key string) (bool, error) {
+	lock := &c.writeLocks[keyStripe(key)]
+	lock.Lock()
+	defer lock.Unlock()
+
+	c.mu.Lock()
+	entry := c.lookupLocked(key)
+	if entry == nil || !entry.dirty {
+		c.mu.Unlock()
+		return false, nil
+	}
+	bucket, ent, version := entry.bucket, cloneEntity(entry.ent), entry.version
+	c.mu.Unlock()
+
+	if err := c.write(ctx, bucket, ent); err != nil {
+		return false, err
+	}
+
+	c.mu.Lock()
+	if current := c.lookupLocked(key); current != nil && current.version == version {
+		current.dirty = false
+		delete(c.pending, key)
+	}
+	c.mu.Unlock()
+	return true, nil
+}
+
+// lookupLocked returns the cached or pending entry of a key; c.mu is held.
+func (c *EntityCache) lookupLocked(key string) *cacheEntry {
+	if elem, ok := c.items[key]; ok {
+		return elem.Value.(*cacheEntry)
+	}
+	return c.pending[key]
+}
+
+// keyStripe returns the write lock of a key (FNV-1a).
+func keyStripe(key string) uint32 {
+	h := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		h = (h ^ uint32(key[i])) * 16777619
+	}
+	return h % writeLockStripes
+}
+
+// Flush persists all dirty entities, evicted ones included, and returns how many were written.
+// Entities that fail to write stay dirty and are retried by the next flush.
+func (c *EntityCache) Flush(ctx context.Context) int {
+	c.mu.Lock()
+	var keys []string
+	for key, elem := range c.items {
+		if elem.Value.(*cacheEntry).dirty {
+			keys = append(keys, key)
+		}
+	}
+	for key := range c.pending {
+		keys = append(keys, key)
+	}
+	c.mu.Unlock()
+
+	written := 0
+	for _, key := range keys {
+		ok, err := c.writeKey(ctx, key)
+		if err != nil {
+			logging.Errorf("Failed to flush entity %s: %v", key, err)
+			continue
+		}
+		if ok {
+			written++
+		}
+	}
+	return written
+}
+
+// Run flushes the cache every interval until ctx is done. The final flush is done by the owner on shutdown.
+func (c *EntityCache) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if written := c.Flush(ctx); written > 0 {
+				logging.Infof("Flushed %d cached entities", written)
+			}
+		}
+	}
+}
+
+// cloneEntity deep-copies an entity through its JSON form, as stored in MinIO.
+func cloneEntity(ent *entity.Entity) *entity.Entity {
+	data, err := json.Marshal(ent)
+	if err != nil {
+		logging.Errorf("Failed to copy entity %s: %v", ent.ID, err)
+		return ent
+	}
+	var copied entity.Entity
+	if err := json.Unmarshal(data, &copied); err != nil {
+		logging.Errorf("Failed to copy entity %s: %v", ent.ID, err)
+		return ent
+	}
+	return &copied
+}
@@ -0,0 +1,160 @@
+// services/entitymanager/cache_test.go
+package entitymanager
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"multiverse-core.io/shared/entity"
+)
+
+// recordingWriter records entity writes by bucket/id.
+type recordingWriter struct {
+	writes map[string]int
+	fail   bool
+}
+
+func (w *recordingWriter) write(ctx context.Context, bucket string, ent *entity.Entity) error {
+	if w.fail {
+		return errors.New("minio down")
+	}
+	w.writes[bucket+"/"+ent.ID]++
+	return nil
+}
+
+func TestEntityCacheCoalescesWrites(t *testing.T) {
+	ctx := context.Background()
+	w := &recordingWriter{writes: map[string]int{}}
+	cache := NewEntityCache(10, w.write)
+
+	ent := entity.NewEntity("npc-1", "npc", map[string]interface{}{"hp": 10.0})
+	for hp := 9; hp >= 5; hp-- {
+		ent.Payload["hp"] = float64(hp)
+		cache.Put(ctx, "entities-w1", ent)
+	}
+
+	if len(w.writes) != 0 {
+		t.Fatalf("expected no writes before flush, got %v", w.writes)
+	}
+	if n := cache.Flush(ctx); n != 1 {
+		t.Fatalf("expected 1 entity flushed, got %d", n)
+	}
+	if w.writes["entities-w1/npc-1"] != 1 {
+		t.Errorf("expected a single write, got %v", w.writes)
+	}
+	if n := cache.Flush(ctx); n != 0 {
+		t.Errorf("expected clean cache after flush, got %d writes", n)
+	}
+
+	got, ok := cache.Get("entities-w1", "npc-1")
+	if !ok || got.Payload["hp"] != 5.0 {
+		t.Fatalf("expected cached hp 5, got %v (%v)", got, ok)
+	}
+}
+
+func TestEntityCacheCopiesEntities(t *testing.T) {
+	ctx := context.Background()
+	cache := NewEntityCache(10, (&recordingWriter{writes: map[string]int{}}).write)
+
+	ent := entity.NewEntity("npc-1", "npc", map[string]interface{}{"hp": 10.0})
+	cache.Put(ctx, "entities-w1", ent)
+	ent.Payload["hp"] = 1.0
+
+	got, _ := cache.Get("entities-w1", "npc-1")
+	if got.Payload["hp"] != 10.0 {
+		t.Fatalf("cache must not share the caller's entity, got hp %v", got.Payload["hp"])
+	}
+	got.Payload["hp"] = 2.0
+	again, _ := cache.Get("entities-w1", "npc-1")
+	if again.Payload["hp"] != 10.0 {
+		t.Errorf("cache must not share returned entities, got hp %v", again.Payload["hp"])
+	}
+}
+
+func TestEntityCacheEvictionWritesDirty(t *testing.T) {
+	ctx := context.Background()
+	w := &recordingWriter{writes: map[string]int{}}
+	cache := NewEntityCache(2, w.write)
+
+	cache.Put(ctx, "entities-w1", entity.NewEntity("a", "npc", nil))
+	cache.Add(ctx, "entities-w1", entity.NewEntity("b", "npc", nil))
+	cache.Get("entities-w1", "a") // b becomes least recently used
+	cache.Add(ctx, "entities-w1", entity.NewEntity("c", "npc", nil))
+
+	if _, ok := cache.Get("entities-w1", "b"); ok {
+		t.Error("expected b to be evicted")
+	}
+	if len(w.writes) != 0 {
+		t.Fatalf("clean eviction must not write, got %v", w.writes)
+	}
+
+	cache.Add(ctx, "entities-w1", entity.NewEntity("d", "npc", nil))
+	if w.writes["entities-w1/a"] != 1 {
+		t.Errorf("expected dirty a to be written on eviction, got %v", w.writes)
+	}
+}
+
+func TestEntityCacheAddKeepsDirtyEntity(t *testing.T) {
+	ctx := context.Background()
+	cache := NewEntityCache(10, (&recordingWriter{writes: map[string]int{}}).write)
+
+	cache.Put(ctx, "entities-w1", entity.NewEntity("npc-1", "npc", map[string]interface{}{"hp": 5.0}))
+	cache.Add(ctx, "entities-w1", entity.NewEntity("npc-1", "npc", map[string]interface{}{"hp": 10.0}))
+
+	got, _ := cache.Get("entities-w1", "npc-1")
+	if got.Payload["hp"] != 5.0 {
+		t.Errorf("stale stored entity must not replace unflushed changes, got hp %v", got.Payload["hp"])
+	}
+}
+
+func TestEntityCacheFlushRetriesFailures(t *testing.T) {
+	ctx := context.Background()
+	w := &recordingWriter{writes: map[string]int{}, fail: true}
+	cache := NewEntityCache(10, w.write)
+
+	cache.Put(ctx, "entities-w1", entity.NewEntity("npc-1", "npc", nil))
+	if n := cache.Flush(ctx); n != 0 {
+		t.Fatalf("expected failed flush, got %d writes", n)
+	}
+
+	w.fail = false
+	if n := cache.Flush(ctx); n != 1 {
+		t.Errorf("expected entity to stay dirty after a failed write, got %d writes", n)
+	}
+}
+
+func TestEntityCacheKeepsFailedEvictions(t *testing.T) {
+	ctx := context.Background()
+	w := &recordingWriter{writes: map[string]int{}, fail: true}
+	cache := NewEntityCache(1, w.write)
+
+	cache.Put(ctx, "entities-w1", entity.NewEntity("a", "npc", map[string]interface{}{"hp": 5.0}))
+	cache.Add(ctx, "entities-w1", entity.NewEntity("b", "npc", nil)) // evicts a, the write fails
+
+	// The only copy of the change is still readable instead of stale storage
+	got, ok := cache.Get("entities-w1", "a")
+	if !ok || got.Payload["hp"] != 5.0 {
+		t.Fatalf("expected the unwritten change of a, got %v (%v)", got, ok)
+	}
+	// Loading a from storage must not replace the unwritten change either
+	cache.Add(ctx, "entities-w1", entity.NewEntity("a", "npc", map[string]interface{}{"hp": 10.0}))
+	if got, _ := cache.Get("entities-w1", "a"); got.Payload["hp"] != 5.0 {
+		t.Errorf("stale stored entity must not replace an unwritten eviction, got hp %v", got.Payload["hp"])
+	}
+	cache.Add(ctx, "entities-w1", entity.NewEntity("c", "npc", nil)) // evicts a again
+
+	w.fail = false
+	if n := cache.Flush(ctx); n != 1 {
+		t.Fatalf("expected the evicted entity to be written by flush, got %d writes", n)
+	}
+	if w.writes["entities-w1/a"] != 1 {
+		t.Errorf("expected a written once, got %v", w.writes)
+	}
+	if _, ok := cache.Get("entities-w1", "a"); ok {
+		t.Error("expected a to leave the cache once written")
+	}
+	if n := cache.Flush(ctx); n != 0 {
+		t.Errorf("expected nothing left to flush, got %d writes", n)
+	}
+}
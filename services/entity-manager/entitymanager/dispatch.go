@@ -0,0 +1,82 @@
+// services/entitymanager/dispatch.go
+package entitymanager
+
+import (
+	"hash/fnv"
+	"sync"
+
+	"multiverse-core.io/shared/eventbus"
+)
+
+// DefaultWorkers is the number of workers handling consumed events.
+const DefaultWorkers = 4
+
+// workerQueueSize is the number of events queued per worker before subscriptions block.
+const workerQueueSize = 64
+
+// dispatcher handles events on a fixed set of workers. Events of one entity always go to
+// the same worker, so its changes are applied in the order they were consumed, while
+// different entities are handled concurrently.
+type dispatcher struct {
+	queues []chan eventbus.Event
+	wg     sync.WaitGroup
+}
+
+// newDispatcher starts workers (<= 0 — DefaultWorkers) calling handle.
+func newDispatcher(workers int, handle func(eventbus.Event)) *dispatcher {
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	d := &dispatcher{queues: make([]chan eventbus.Event, workers)}
+	for i := range d.queues {
+		queue := make(chan eventbus.Event, workerQueueSize)
+		d.queues[i] = queue
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			for ev := range queue {
+				handle(ev)
+			}
+		}()
+	}
+	return d
+}
+
+// Dispatch queues the event on the worker of its entity; blocks while that worker is full.
+// Must not be called after Close.
+func (d *dispatcher) Dispatch(ev eventbus.Event) {
+	h := fnv.New32a()
+	h.Write([]byte(shardKey(ev)))
+	d.queues[h.Sum32()%uint32(len(d.queues))] <- ev
+}
+
+// Close waits until the queued events are handled and stops the workers.
+func (d *dispatcher) Close() {
+	for _, queue := range d.queues {
+		close(queue)
+	}
+	d.wg.Wait()
+}
+
+// shardKey returns the entity an event changes: the first state change or snapshot,
+// then the event entity; events without an entity are spread by event ID.
+func shardKey(ev eventbus.Event) string {
+	if changes, ok := ev.Payload["state_changes"].([]interface{}); ok && len(changes) > 0 {
+		if change, ok := changes[0].(map[string]interface{}); ok {
+			if info := eventbus.ExtractEntityID(change); info != nil {
+				return info.ID
+			}
+		}
+	}
+	if snapshots, ok := ev.Payload["entity_snapshots"].([]interface{}); ok && len(snapshots) > 0 {
+		if snapshot, ok := snapshots[0].(map[string]interface{}); ok {
+			if id, ok := snapshot["id"].(string); ok && id != "" {
+				return id
+			}
+		}
+	}
+	if info := eventbus.ExtractEntityID(ev.Payload); info != nil {
+		return info.ID
+	}
+	return ev.ID
+}
@@ -0,0 +1,59 @@
+// services/entitymanager/dispatch_test.go
+package entitymanager
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"multiverse-core.io/shared/eventbus"
+)
+
+func TestDispatcherKeepsEntityOrder(t *testing.T) {
+	var mu sync.Mutex
+	handled := make(map[string][]int)
+	d := newDispatcher(4, func(ev eventbus.Event) {
+		entityID := shardKey(ev)
+		mu.Lock()
+		handled[entityID] = append(handled[entityID], int(ev.Payload["seq"].(float64)))
+		mu.Unlock()
+	})
+
+	for seq := 0; seq < 100; seq++ {
+		entityID := fmt.Sprintf("npc-%d", seq%7)
+		d.Dispatch(eventbus.Event{ID: fmt.Sprintf("ev-%d", seq), Payload: map[string]interface{}{
+			"entity": map[string]interface{}{"id": entityID},
+			"seq":    float64(seq),
+		}})
+	}
+	// Close handles every queued event before returning
+	d.Close()
+
+	total := 0
+	for entityID, seqs := range handled {
+		total += len(seqs)
+		for i := 1; i < len(seqs); i++ {
+			if seqs[i] < seqs[i-1] {
+				t.Errorf("events of %s handled out of order: %v", entityID, seqs)
+				break
+			}
+		}
+	}
+	if total != 100 {
+		t.Errorf("expected 100 handled events, got %d", total)
+	}
+}
+
+func TestShardKey(t *testing.T) {
+	cases := map[string]eventbus.Event{
+		"npc-1":  {ID: "ev-1", Payload: map[string]interface{}{"state_changes": []interface{}{map[string]interface{}{"entity_id": "npc-1"}}}},
+		"city-1": {ID: "ev-2", Payload: map[string]interface{}{"entity_snapshots": []interface{}{map[string]interface{}{"id": "city-1"}}}},
+		"p-1":    {ID: "ev-3", Payload: map[string]interface{}{"entity": map[string]interface{}{"id": "p-1"}}},
+		"ev-4":   {ID: "ev-4", Payload: map[string]interface{}{}},
+	}
+	for want, ev := range cases {
+		if got := shardKey(ev); got != want {
+			t.Errorf("%s: expected shard key %q, got %q", ev.ID, want, got)
+		}
+	}
+}
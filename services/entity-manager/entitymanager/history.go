@@ -0,0 +1,170 @@
+// services/entitymanager/history.go
+package entitymanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"multiverse-core.io/shared/entity"
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+)
+
+// historyPrefix holds versioned entity snapshots: _history/{entity_id}/{unix_nano}.json.
+// Zero-padded timestamps keep the keys in chronological order.
+const historyPrefix = "_history/"
+
+// DefaultHistoryVersions is the number of snapshot versions kept per entity.
+const DefaultHistoryVersions = 20
+
+// EventEntityRestored is published with the restored snapshot; HandleEvent persists it
+// through the regular entity_snapshots path.
+const EventEntityRestored = "entity.restored"
+
+// SnapshotVersion describes a stored version of an entity.
+type SnapshotVersion struct {
+	Key     string    `json:"key"`
+	SavedAt time.Time `json:"saved_at"`
+	Size    int64     `json:"size"`
+}
+
+// historyKey returns the object key of an entity version saved at the given time.
+func historyKey(entityID string, savedAt time.Time) string {
+	return fmt.Sprintf("%s%s/%020d.json", historyPrefix, entityID, savedAt.UnixNano())
+}
+
+// parseHistoryKey returns the save time encoded in a version key.
+func parseHistoryKey(key string) (time.Time, bool) {
+	name := key[strings.LastIndex(key, "/")+1:]
+	nanos, err := strconv.ParseInt(strings.TrimSuffix(name, ".json"), 10, 64)
+	if err != nil || !strings.HasSuffix(name, ".json") {
+		return time.Time{}, false
+	}
+	return time.Unix(0, nanos).UTC(), true
+}
+
+// versionAt returns the latest version saved at or before the given time.
+// Versions must be sorted by SavedAt.
+func versionAt(versions []SnapshotVersion, at time.Time) (SnapshotVersion, bool) {
+	i := sort.Search(len(versions), func(i int) bool { return versions[i].SavedAt.After(at) })
+	if i == 0 {
+		return SnapshotVersion{}, false
+	}
+	return versions[i-1], true
+}
+
+// saveVersion stores a versioned copy of a saved entity and drops versions beyond the retention limit.
+// History failures are logged only: the current snapshot has already been written.
+func (m *Manager) saveVersion(ctx context.Context, bucket string, ent *entity.Entity, data []byte) {
+	if m.historyVersions <= 0 {
+		return
+	}
+
+	key := historyKey(ent.ID, time.Now().UTC())
+	if err := m.minio.PutObject(bucket, key, bytes.NewReader(data), int64(len(data))); err != nil {
+		logging.Errorf("Failed to save version of entity %s: %v", ent.ID, err)
+		return
+	}
+
+	versions, err := m.listVersions(ctx, bucket, ent.ID)
+	if err != nil {
+		logging.Errorf("Failed to list versions of entity %s: %v", ent.ID, err)
+		return
+	}
+	for _, version := range versions[:max(0, len(versions)-m.historyVersions)] {
+		if err := m.minio.RemoveObject(bucket, version.Key); err != nil {
+			logging.Errorf("Failed to prune version %s: %v", version.Key, err)
+		}
+	}
+}
+
+// listVersions returns the stored versions of an entity, oldest first.
+func (m *Manager) listVersions(ctx context.Context, bucket, entityID string) ([]SnapshotVersion, error) {
+	objects, err := m.minio.ListObjects(bucket, historyPrefix+entityID+"/")
+	if err != nil {
+		return nil, err
+	}
+	var versions []SnapshotVersion
+	for _, info := range objects {
+		savedAt, ok := parseHistoryKey(info.Key)
+		if !ok {
+			continue
+		}
+		versions = append(versions, SnapshotVersion{Key: info.Key, SavedAt: savedAt, Size: info.Size})
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].SavedAt.Before(versions[j].SavedAt) })
+	return versions, nil
+}
+
+// EntityHistory returns the stored versions of an entity in a world, oldest first.
+func (m *Manager) EntityHistory(ctx context.Context, entityID, worldID string) ([]SnapshotVersion, error) {
+	return m.listVersions(ctx, bucketForWorld(worldID), entityID)
+}
+
+// RestoreEntity reconstructs the entity as it was at the given time and republishes it
+// as entity.restored with an entity_snapshots payload, so the restored state is validated
+// and persisted like any other snapshot. Used to roll back bad Oracle-generated events.
+func (m *Manager) RestoreEntity(ctx context.Context, entityID, worldID string, at time.Time) (*entity.Entity, *SnapshotVersion, error) {
+	if m.publish == nil {
+		return nil, nil, fmt.Errorf("entity restore requires an event bus")
+	}
+
+	bucket := bucketForWorld(worldID)
+	versions, err := m.listVersions(ctx, bucket, entityID)
+	if err != nil {
+		return nil, nil, err
+	}
+	version, ok := versionAt(versions, at)
+	if !ok {
+		return nil, nil, fmt.Errorf("no version of entity %s at %s: %w", entityID, at.Format(time.RFC3339), storage.ErrNotFound)
+	}
+
+	obj, err := m.minio.GetObjectStream(bucket, version.Key)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer obj.Close()
+	var ent entity.Entity
+	if err := json.NewDecoder(obj).Decode(&ent); err != nil {
+		return nil, nil, storage.ClassifyError(err)
+	}
+	ent.UpdatedAt = time.Now().UTC()
+
+	snapshot, err := toMap(&ent)
+	if err != nil {
+		return nil, nil, err
+	}
+	payload := eventbus.NewEventPayload().
+		WithEntity(ent.ID, ent.Type, "").
+		WithWorld(worldID)
+	eventbus.SetNested(payload.GetCustom(), "entity_snapshots", []interface{}{snapshot})
+	eventbus.SetNested(payload.GetCustom(), "restored_from.key", version.Key)
+	eventbus.SetNested(payload.GetCustom(), "restored_from.saved_at", version.SavedAt.Format(time.RFC3339Nano))
+
+	event := eventbus.NewStructuredEvent(EventEntityRestored, "entity-manager", worldID, payload)
+	if err := m.publish(ctx, event); err != nil {
+		return nil, nil, fmt.Errorf("failed to publish %s: %w", EventEntityRestored, err)
+	}
+	logging.Infof("Restoring entity %s to version %s", entityID, version.Key)
+	return &ent, &version, nil
+}
+
+// toMap converts an entity to a generic map as it travels in event payloads.
+func toMap(ent *entity.Entity) (map[string]interface{}, error) {
+	data, err := json.Marshal(ent)
+	if err != nil {
+		return nil, err
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,87 @@
+// services/entitymanager/history_test.go
+package entitymanager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"multiverse-core.io/shared/entity"
+	"multiverse-core.io/shared/minio/miniotest"
+)
+
+func TestHistoryKey(t *testing.T) {
+	savedAt := time.Date(2025, 3, 1, 12, 0, 0, 42, time.UTC)
+	key := historyKey("npc-1", savedAt)
+	if key != "_history/npc-1/01740830400000000042.json" {
+		t.Fatalf("unexpected key %q", key)
+	}
+	if parsed, ok := parseHistoryKey(key); !ok || !parsed.Equal(savedAt) {
+		t.Errorf("expected %s, got %s (%v)", savedAt, parsed, ok)
+	}
+	if _, ok := parseHistoryKey("_history/npc-1/latest.json"); ok {
+		t.Error("expected non-timestamp key to be rejected")
+	}
+
+	// Zero padding keeps lexical order chronological
+	if historyKey("npc-1", time.Unix(9, 0)) >= historyKey("npc-1", time.Unix(10, 0)) {
+		t.Error("expected keys to sort chronologically")
+	}
+}
+
+func TestVersionAt(t *testing.T) {
+	base := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	versions := []SnapshotVersion{
+		{Key: "v1", SavedAt: base},
+		{Key: "v2", SavedAt: base.Add(time.Minute)},
+		{Key: "v3", SavedAt: base.Add(2 * time.Minute)},
+	}
+
+	cases := []struct {
+		at   time.Time
+		want string
+	}{
+		{base.Add(-time.Second), ""},
+		{base, "v1"},
+		{base.Add(90 * time.Second), "v2"},
+		{base.Add(time.Hour), "v3"},
+	}
+	for _, c := range cases {
+		version, ok := versionAt(versions, c.at)
+		if version.Key != c.want || ok != (c.want != "") {
+			t.Errorf("at %s: expected %q, got %q (%v)", c.at, c.want, version.Key, ok)
+		}
+	}
+}
+
+func TestWriteEntityKeepsHistory(t *testing.T) {
+	ctx := context.Background()
+	objects := miniotest.New()
+	m := NewManagerWithStorage(objects)
+	m.historyVersions = 2
+
+	for _, level := range []float64{1, 2, 3} {
+		ent := &entity.Entity{ID: "npc-1", Type: "npc", Payload: map[string]interface{}{"level": level}}
+		if err := m.writeEntity(ctx, "entities-world-1", ent); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	versions, err := m.EntityHistory(ctx, "npc-1", "world-1")
+	if err != nil || len(versions) != 2 {
+		t.Fatalf("expected 2 retained versions, got %v (%v)", versions, err)
+	}
+	ent, err := m.loadEntityFromBucket(ctx, "entities-world-1", "npc-1")
+	if err != nil || ent.Payload["level"] != float64(3) {
+		t.Fatalf("expected latest entity, got %+v (%v)", ent, err)
+	}
+
+	// History and index objects are not mistaken for entities
+	idx, err := m.RebuildTypeIndex(ctx, "entities-world-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := idx.ids(""); len(ids) != 1 || ids[0] != "npc-1" {
+		t.Errorf("unexpected rebuilt index %v", idx.Types)
+	}
+}
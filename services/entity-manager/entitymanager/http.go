@@ -0,0 +1,222 @@
+// services/entitymanager/http.go
+package entitymanager
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+)
+
+// routes builds the EntityManager HTTP API.
+func (s *Service) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", s.handleHealth)
+	mux.HandleFunc("POST /v1/entities/spawn", s.handleSpawn)
+	mux.HandleFunc("GET /v1/worlds/{world_id}/entities", s.handleListEntities)
+	mux.HandleFunc("POST /v1/worlds/{world_id}/entities/reindex", s.handleReindex)
+	mux.HandleFunc("GET /v1/worlds/{world_id}/entities/{entity_id}/history", s.handleEntityHistory)
+	mux.HandleFunc("POST /v1/worlds/{world_id}/entities/{entity_id}/restore", s.handleRestoreEntity)
+	mux.HandleFunc("GET /v1/worlds/{world_id}/export", s.handleExportWorld)
+	mux.HandleFunc("POST /v1/worlds/{world_id}/import", s.handleImportWorld)
+	return mux
+}
+
+// handleHealth handles GET /health.
+func (s *Service) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"status": "healthy",
+		"time":   time.Now().Format(time.RFC3339),
+	})
+}
+
+// handleSpawn handles POST /v1/entities/spawn.
+func (s *Service) handleSpawn(w http.ResponseWriter, r *http.Request) {
+	var req SpawnRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	result, err := s.Spawn(r.Context(), req)
+	if err != nil {
+		logging.Errorf("Spawn from template %s/%s failed: %v", req.EntityType, req.Template, err)
+		switch {
+		case errors.Is(err, ErrSchemaValidation):
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		case storage.IsNotFound(err):
+			http.Error(w, "Template not found", http.StatusNotFound)
+		case storage.IsUnavailable(err):
+			http.Error(w, "Archivist unavailable", http.StatusServiceUnavailable)
+		case errors.Is(err, ErrMissingVariables), req.WorldID == "" || req.EntityType == "" || req.Template == "":
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, "Failed to spawn entity", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	// The entity is persisted asynchronously from the published entity.created event
+	writeJSON(w, http.StatusAccepted, result)
+}
+
+// handleListEntities handles GET /v1/worlds/{world_id}/entities?type=&prefix=&cursor=&limit=&fields=.
+func (s *Service) handleListEntities(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	q := ListQuery{
+		WorldID: r.PathValue("world_id"),
+		Type:    query.Get("type"),
+		Prefix:  query.Get("prefix"),
+		Cursor:  query.Get("cursor"),
+	}
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		q.Limit = limit
+	}
+	if raw := query.Get("fields"); raw != "" {
+		for _, field := range strings.Split(raw, ",") {
+			if field = strings.TrimSpace(field); field != "" {
+				q.Fields = append(q.Fields, field)
+			}
+		}
+	}
+
+	result, err := s.manager.ListEntities(r.Context(), q)
+	if err != nil {
+		writeStorageError(w, err, "Failed to list entities")
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// handleReindex handles POST /v1/worlds/{world_id}/entities/reindex.
+func (s *Service) handleReindex(w http.ResponseWriter, r *http.Request) {
+	idx, err := s.manager.RebuildTypeIndex(r.Context(), bucketForWorld(r.PathValue("world_id")))
+	if err != nil {
+		writeStorageError(w, err, "Failed to rebuild type index")
+		return
+	}
+
+	counts := make(map[string]int, len(idx.Types))
+	for entityType, ids := range idx.Types {
+		counts[entityType] = len(ids)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"types": counts})
+}
+
+// handleEntityHistory handles GET /v1/worlds/{world_id}/entities/{entity_id}/history.
+func (s *Service) handleEntityHistory(w http.ResponseWriter, r *http.Request) {
+	versions, err := s.manager.EntityHistory(r.Context(), r.PathValue("entity_id"), r.PathValue("world_id"))
+	if err != nil {
+		writeStorageError(w, err, "Failed to list entity versions")
+		return
+	}
+	if versions == nil {
+		versions = []SnapshotVersion{}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"versions": versions})
+}
+
+// handleRestoreEntity handles POST /v1/worlds/{world_id}/entities/{entity_id}/restore with {"at": RFC3339}.
+func (s *Service) handleRestoreEntity(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		At time.Time `json:"at"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.At.IsZero() {
+		http.Error(w, "Invalid JSON: at (RFC3339) is required", http.StatusBadRequest)
+		return
+	}
+
+	entityID := r.PathValue("entity_id")
+	ent, version, err := s.manager.RestoreEntity(r.Context(), entityID, r.PathValue("world_id"), req.At)
+	if err != nil {
+		if storage.IsNotFound(err) {
+			http.Error(w, "No version of the entity at the requested time", http.StatusNotFound)
+			return
+		}
+		writeStorageError(w, err, "Failed to restore entity "+entityID)
+		return
+	}
+
+	// The restored snapshot is persisted asynchronously from the published entity.restored event
+	writeJSON(w, http.StatusAccepted, map[string]interface{}{
+		"entity":        ent,
+		"restored_from": version,
+	})
+}
+
+// maxImportBytes bounds an uploaded world archive.
+const maxImportBytes = 512 << 20
+
+// handleExportWorld handles GET /v1/worlds/{world_id}/export: the entities of the world as a .tar.gz archive.
+func (s *Service) handleExportWorld(w http.ResponseWriter, r *http.Request) {
+	worldID := r.PathValue("world_id")
+	// Large worlds take longer than the server write timeout
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", `attachment; filename="entities-`+worldID+`.tar.gz"`)
+	if _, err := s.manager.ExportWorld(r.Context(), worldID, w); err != nil {
+		// Nothing is written before the bucket is listed; later failures truncate the archive,
+		// which then fails verification on import
+		w.Header().Del("Content-Disposition")
+		w.Header().Del("Content-Type")
+		writeStorageError(w, err, "Failed to export world "+worldID)
+	}
+}
+
+// handleImportWorld handles POST /v1/worlds/{world_id}/import?overwrite=true&republish=true
+// with an archive written by the export endpoint as the body.
+func (s *Service) handleImportWorld(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	opts := ImportOptions{
+		WorldID:   r.PathValue("world_id"),
+		Overwrite: query.Get("overwrite") == "true",
+		Republish: query.Get("republish") == "true",
+	}
+	http.NewResponseController(w).SetReadDeadline(time.Time{})
+
+	result, err := s.manager.ImportWorld(r.Context(), http.MaxBytesReader(w, r.Body, maxImportBytes), opts)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &tooLarge):
+			http.Error(w, "Archive too large", http.StatusRequestEntityTooLarge)
+		case errors.Is(err, ErrInvalidArchive):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			writeStorageError(w, err, "Failed to import world "+opts.WorldID)
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// writeStorageError maps a MinIO error to an HTTP status.
+func writeStorageError(w http.ResponseWriter, err error, message string) {
+	logging.Infof("%s: %v", message, err)
+	switch {
+	case storage.IsNotFound(err):
+		http.Error(w, "World not found", http.StatusNotFound)
+	case storage.IsUnavailable(err):
+		http.Error(w, "Storage unavailable", http.StatusServiceUnavailable)
+	default:
+		http.Error(w, message, http.StatusInternalServerError)
+	}
+}
+
+// writeJSON writes v as a JSON response with the given status.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
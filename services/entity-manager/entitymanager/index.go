@@ -0,0 +1,172 @@
+// services/entitymanager/index.go
+package entitymanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"multiverse-core.io/shared/entity"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+)
+
+// typeIndexKey is the per-bucket object mapping entity types to entity IDs.
+// It lives under a prefix so it never collides with {entity_id}.json objects.
+const typeIndexKey = "_index/types.json"
+
+// TypeIndex lists the entity IDs of a bucket by entity type. IDs are kept sorted.
+type TypeIndex struct {
+	Types     map[string][]string `json:"types"`
+	UpdatedAt time.Time           `json:"updated_at"`
+}
+
+// has reports whether the entity is indexed under the given type.
+func (idx *TypeIndex) has(entityType, entityID string) bool {
+	ids := idx.Types[entityType]
+	i := sort.SearchStrings(ids, entityID)
+	return i < len(ids) && ids[i] == entityID
+}
+
+// add indexes the entity under its type, removing it from any other type
+// (entities created by state_changes start as "unknown"). Returns true if the index changed.
+func (idx *TypeIndex) add(entityType, entityID string) bool {
+	if idx.Types == nil {
+		idx.Types = make(map[string][]string)
+	}
+	if idx.has(entityType, entityID) {
+		return false
+	}
+	for otherType := range idx.Types {
+		idx.remove(otherType, entityID)
+	}
+
+	ids := idx.Types[entityType]
+	i := sort.SearchStrings(ids, entityID)
+	ids = append(ids, "")
+	copy(ids[i+1:], ids[i:])
+	ids[i] = entityID
+	idx.Types[entityType] = ids
+	return true
+}
+
+// remove drops the entity from a type, deleting the type when it becomes empty.
+func (idx *TypeIndex) remove(entityType, entityID string) {
+	ids := idx.Types[entityType]
+	i := sort.SearchStrings(ids, entityID)
+	if i == len(ids) || ids[i] != entityID {
+		return
+	}
+	ids = append(ids[:i], ids[i+1:]...)
+	if len(ids) == 0 {
+		delete(idx.Types, entityType)
+		return
+	}
+	idx.Types[entityType] = ids
+}
+
+// ids returns the sorted IDs of a type, or of all types when entityType is empty.
+func (idx *TypeIndex) ids(entityType string) []string {
+	if entityType != "" {
+		return idx.Types[entityType]
+	}
+	var all []string
+	for _, ids := range idx.Types {
+		all = append(all, ids...)
+	}
+	sort.Strings(all)
+	return all
+}
+
+// indexEntity records a saved entity in its bucket's type index. The cached index only
+// short-circuits writes of already indexed entities; changes are applied to a fresh copy
+// from MinIO to keep concurrent writers from dropping each other's entries.
+func (m *Manager) indexEntity(ctx context.Context, bucket string, ent *entity.Entity) {
+	m.indexMu.Lock()
+	defer m.indexMu.Unlock()
+
+	if m.indexes == nil {
+		m.indexes = make(map[string]*TypeIndex)
+	}
+	if cached, ok := m.indexes[bucket]; ok && cached.has(ent.Type, ent.ID) {
+		return
+	}
+
+	idx, err := m.readTypeIndex(ctx, bucket)
+	if err != nil {
+		logging.Errorf("Failed to load type index of %s, entity %s not indexed: %v", bucket, ent.ID, err)
+		return
+	}
+	if idx.add(ent.Type, ent.ID) {
+		if err := m.writeTypeIndex(ctx, bucket, idx); err != nil {
+			logging.Errorf("Failed to save type index of %s: %v", bucket, err)
+			return
+		}
+	}
+	m.indexes[bucket] = idx
+}
+
+// readTypeIndex loads the type index of a bucket, rebuilding it when it doesn't exist yet.
+func (m *Manager) readTypeIndex(ctx context.Context, bucket string) (*TypeIndex, error) {
+	obj, err := m.minio.GetObjectStream(bucket, typeIndexKey)
+	if storage.IsNotFound(err) {
+		return m.RebuildTypeIndex(ctx, bucket)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Close()
+
+	var idx TypeIndex
+	if err := json.NewDecoder(obj).Decode(&idx); err != nil {
+		return nil, storage.ClassifyError(err)
+	}
+	if idx.Types == nil {
+		idx.Types = make(map[string][]string)
+	}
+	return &idx, nil
+}
+
+// writeTypeIndex stores the type index of a bucket.
+func (m *Manager) writeTypeIndex(ctx context.Context, bucket string, idx *TypeIndex) error {
+	idx.UpdatedAt = time.Now().UTC()
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return err
+	}
+	return m.minio.PutObject(bucket, typeIndexKey, bytes.NewReader(data), int64(len(data)))
+}
+
+// RebuildTypeIndex scans every entity object of a bucket and rewrites its type index.
+// Used when the index is missing (buckets written before indexing) or drifted.
+func (m *Manager) RebuildTypeIndex(ctx context.Context, bucket string) (*TypeIndex, error) {
+	objects, err := m.minio.ListObjects(bucket, "")
+	if err != nil {
+		return nil, err
+	}
+	idx := &TypeIndex{Types: make(map[string][]string)}
+	for _, info := range objects {
+		// The listing is recursive: skip nested objects like _index/ and _history/
+		if strings.Contains(info.Key, "/") || !strings.HasSuffix(info.Key, ".json") {
+			continue
+		}
+		ent, err := m.loadEntityFromBucket(ctx, bucket, strings.TrimSuffix(info.Key, ".json"))
+		if err != nil {
+			logging.Warnf("Skipping %s/%s while rebuilding type index: %v", bucket, info.Key, err)
+			continue
+		}
+		if ent.ID == "" {
+			continue
+		}
+		idx.add(ent.Type, ent.ID)
+	}
+
+	if err := m.writeTypeIndex(ctx, bucket, idx); err != nil {
+		return nil, err
+	}
+	logging.Infof("Rebuilt type index of %s: %d types", bucket, len(idx.Types))
+	return idx, nil
+}
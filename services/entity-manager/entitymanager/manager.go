@@ -5,18 +5,34 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"log"
 	"os"
+	"sync"
 
+	"multiverse-core.io/shared/archivist"
 	"multiverse-core.io/shared/entity"
 	"multiverse-core.io/shared/eventbus"
-
-	"github.com/minio/minio-go/v7"
-	"github.com/minio/minio-go/v7/pkg/credentials"
+	"multiverse-core.io/shared/eventbus/events"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
 )
 
 type Manager struct {
-	minio *minio.Client // ← теперь это *github.com/minio/minio-go/v7.Client
+	minio storage.ObjectStorage
+
+	// schemas validates payloads before they are persisted; nil disables validation
+	schemas *archivist.EntitySchemas
+	// publish reports rejected writes (entity.validation.failed)
+	publish func(ctx context.Context, event eventbus.Event) error
+
+	// historyVersions is the number of versioned snapshots kept per entity; 0 disables history
+	historyVersions int
+
+	// indexes caches per-bucket type indexes to skip rewriting them for known entities
+	indexMu sync.Mutex
+	indexes map[string]*TypeIndex
+
+	// cache holds hot entities and defers their writes; nil writes every change through to MinIO
+	cache *EntityCache
 }
 
 // NewManager creates a new EntityManager with MinIO client.
@@ -26,15 +42,22 @@ func NewManager() (*Manager, error) {
 		minioEndpoint = "minio:9000"
 	}
 
-	minioClient, err := minio.New(minioEndpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4("minioadmin", "minioadmin", ""),
-		Secure: false,
+	minioClient, err := storage.NewMinIOOfficialClient(storage.Config{
+		Endpoint:        minioEndpoint,
+		AccessKeyID:     "minioadmin",
+		SecretAccessKey: "minioadmin",
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	return &Manager{minio: minioClient}, nil
+	return NewManagerWithStorage(minioClient), nil
+}
+
+// NewManagerWithStorage creates an EntityManager over the given object storage
+// without schema validation or the write-back cache.
+func NewManagerWithStorage(objects storage.ObjectStorage) *Manager {
+	return &Manager{minio: objects, historyVersions: DefaultHistoryVersions}
 }
 
 // getBucketForEntity determines the MinIO bucket for an entity.
@@ -46,71 +69,78 @@ func (m *Manager) getBucketForEntity(ent *entity.Entity, evt *eventbus.Event) st
 	return "entities-" + worldID
 }
 
-// ensureBucket creates a bucket if it doesn't exist.
-func (m *Manager) ensureBucket(ctx context.Context, bucket string) error {
-	exists, err := m.minio.BucketExists(ctx, bucket)
-	if err != nil {
-		return err
-	}
-	if !exists {
-		return m.minio.MakeBucket(ctx, bucket, minio.MakeBucketOptions{})
-	}
-	return nil
-}
-
 // loadEntityFromMinIO loads an entity from MinIO (first in world bucket, then global).
+// Returns an error wrapping storage.ErrNotFound when the entity exists in neither bucket,
+// or storage.ErrUnavailable when MinIO cannot be reached.
 func (m *Manager) loadEntityFromMinIO(ctx context.Context, entityID, worldID string) (*entity.Entity, error) {
 	// Try world-specific bucket
-	bucket := "entities-" + worldID
-	obj, err := m.minio.GetObject(ctx, bucket, entityID+".json", minio.GetObjectOptions{})
-	if err == nil {
-		defer obj.Close()
-		var ent entity.Entity
-		if err := json.NewDecoder(obj).Decode(&ent); err != nil {
-			return nil, err
-		}
-		return &ent, nil
+	ent, err := m.loadEntityFromBucket(ctx, "entities-"+worldID, entityID)
+	if err == nil || !storage.IsNotFound(err) {
+		return ent, err
 	}
 
 	// Try global bucket
-	bucket = "entities-global"
-	obj, err = m.minio.GetObject(ctx, bucket, entityID+".json", minio.GetObjectOptions{})
-	if err == nil {
-		defer obj.Close()
-		var ent entity.Entity
-		if err := json.NewDecoder(obj).Decode(&ent); err != nil {
-			return nil, err
+	return m.loadEntityFromBucket(ctx, "entities-global", entityID)
+}
+
+// loadEntityFromBucket reads and decodes a single entity object.
+// Cached entities are served without a MinIO round-trip and may be newer than the stored object.
+func (m *Manager) loadEntityFromBucket(ctx context.Context, bucket, entityID string) (*entity.Entity, error) {
+	if m.cache != nil {
+		if ent, ok := m.cache.Get(bucket, entityID); ok {
+			return ent, nil
 		}
-		return &ent, nil
 	}
 
-	return nil, err // not found
+	obj, err := m.minio.GetObjectStream(bucket, entityID+".json")
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Close()
+
+	var ent entity.Entity
+	if err := json.NewDecoder(obj).Decode(&ent); err != nil {
+		return nil, storage.ClassifyError(err)
+	}
+	if m.cache != nil {
+		m.cache.Add(ctx, bucket, &ent)
+	}
+	return &ent, nil
 }
 
 // saveSnapshotToMinIO saves an entity to its appropriate bucket.
+// With the write-back cache enabled the write is deferred until the next flush.
 func (m *Manager) saveSnapshotToMinIO(ctx context.Context, ent *entity.Entity, evt *eventbus.Event) error {
 	bucket := m.getBucketForEntity(ent, evt)
-	if err := m.ensureBucket(ctx, bucket); err != nil {
-		return err
+	if m.cache != nil {
+		m.cache.Put(ctx, bucket, ent)
+		return nil
 	}
+	return m.writeEntity(ctx, bucket, ent)
+}
 
+// writeEntity stores an entity object together with its version and type index entry.
+func (m *Manager) writeEntity(ctx context.Context, bucket string, ent *entity.Entity) error {
 	data, err := json.Marshal(ent)
-
 	if err != nil {
 		return err
 	}
 
-	_, err = m.minio.PutObject(ctx, bucket, ent.ID+".json",
-		bytes.NewReader(data), int64(len(data)),
-		minio.PutObjectOptions{ContentType: "application/json; charset=utf-8"})
-	return err
+	// The storage client creates the bucket on first write
+	if err := m.minio.PutObject(bucket, ent.ID+".json", bytes.NewReader(data), int64(len(data))); err != nil {
+		return err
+	}
+
+	m.saveVersion(ctx, bucket, ent, data)
+	m.indexEntity(ctx, bucket, ent)
+	return nil
 }
 
 // CreateEntityActor creates a new Entity-Actor for an entity
 func (m *Manager) CreateEntityActor(entityID string, entityType string) error {
 	// In a real implementation, this would create and register an EntityActor
 	// For now, we'll just log the action
-	log.Printf("Creating Entity-Actor for entity %s of type %s", entityID, entityType)
+	logging.Infof("Creating Entity-Actor for entity %s of type %s", entityID, entityType)
 	return nil
 }
 
@@ -118,14 +148,15 @@ func (m *Manager) CreateEntityActor(entityID string, entityType string) error {
 func (m *Manager) DestroyEntityActor(entityID string) error {
 	// In a real implementation, this would clean up the EntityActor
 	// For now, we'll just log the action
-	log.Printf("Destroying Entity-Actor for entity %s", entityID)
+	logging.Infof("Destroying Entity-Actor for entity %s", entityID)
 	return nil
 }
 
 // SubscribeToEvents subscribes to events for Entity-Actor lifecycle management
+// in the background until ctx is cancelled.
 func (m *Manager) SubscribeToEvents(ctx context.Context, bus *eventbus.EventBus) {
 	// Create Entity-Actor when entity is created
-	bus.Subscribe(ctx, "entity.created", "entity-actor-group", func(ev eventbus.Event) {
+	go bus.Subscribe(ctx, "entity.created", "entity-actor-group", func(ev eventbus.Event) {
 		entityInfo := eventbus.ExtractEntityID(ev.Payload)
 		entityID := ""
 		entityType := ""
@@ -137,7 +168,7 @@ func (m *Manager) SubscribeToEvents(ctx context.Context, bus *eventbus.EventBus)
 	})
 
 	// Destroy Entity-Actor when entity is deleted
-	bus.Subscribe(ctx, "entity.deleted", "entity-actor-group", func(ev eventbus.Event) {
+	go bus.Subscribe(ctx, "entity.deleted", "entity-actor-group", func(ev eventbus.Event) {
 		entityInfo := eventbus.ExtractEntityID(ev.Payload)
 		entityID := ""
 		if entityInfo != nil {
@@ -159,20 +190,24 @@ func (m *Manager) HandleEvent(ev eventbus.Event) {
 					// Convert map to Entity
 					data, err := json.Marshal(snapMap)
 					if err != nil {
-						log.Printf("Failed to marshal snapshot for entity: %v", err)
+						logging.Errorf("Failed to marshal snapshot for entity: %v", err)
 						continue
 					}
 					
 					var ent entity.Entity
 					if err := json.Unmarshal(data, &ent); err != nil {
-						log.Printf("Failed to unmarshal entity: %v", err)
+						logging.Errorf("Failed to unmarshal entity: %v", err)
+						continue
+					}
+
+					if !m.validateForWrite(ctx, &entityWrite{entityID: ent.ID, entityType: ent.Type, payload: ent.Payload, event: &ev}) {
 						continue
 					}
 
 					if err := m.saveSnapshotToMinIO(ctx, &ent, &ev); err != nil {
-						log.Printf("Failed to save snapshot for %s: %v", ent.ID, err)
+						logging.Errorf("Failed to save snapshot for %s: %v", ent.ID, err)
 					} else {
-						log.Printf("Saved entity %s to bucket %s", ent.ID, m.getBucketForEntity(&ent, &ev))
+						logging.Infof("Saved entity %s to bucket %s", ent.ID, m.getBucketForEntity(&ent, &ev))
 					}
 				}
 			}
@@ -194,43 +229,37 @@ func (m *Manager) HandleEvent(ev eventbus.Event) {
 					worldID := eventbus.GetWorldIDFromEvent(ev)
 					ent, err := m.loadEntityFromMinIO(ctx, entityID, worldID)
 					if err != nil {
+						if !storage.IsNotFound(err) {
+							// MinIO unavailable or object corrupted: creating a blank entity here would overwrite real state
+							logging.Errorf("Failed to load entity %s, skipping state changes: %v", entityID, err)
+							continue
+						}
 						// Create new entity if not found
 						ent = entity.NewEntity(entityID, "unknown", nil)
 					}
 
-					// Apply operations
-					if opsRaw, ok := changeMap["operations"].([]interface{}); ok {
-						for _, opRaw := range opsRaw {
-							if opMap, ok := opRaw.(map[string]interface{}); ok {
-								opType, _ := opMap["op"].(string)
-								path, _ := opMap["path"].(string)
-
-								switch OperationType(opType) {
-								case OpSet:
-									if value, exists := opMap["value"]; exists {
-										ent.Set(path, value)
-									}
-								case OpAddToSlice:
-									if value, exists := opMap["value"].(string); exists {
-										ent.AddToStringSlice(path, value)
-									}
-								case OpRemoveFromSlice:
-									if value, exists := opMap["value"].(string); exists {
-										ent.RemoveFromStringSlice(path, value)
-									}
-								case OpRemove:
-									ent.Remove(path)
-								}
-							}
-						}
+					// Apply operations as one atomic patch: a failed operation or test rejects the change set
+					operations, _ := changeMap["operations"].([]interface{})
+					ops, err := entity.ParsePatch(operations)
+					if err == nil {
+						_, err = ent.ApplyPatch(ops)
+					}
+					if err != nil {
+						logging.Warnf("Rejected state changes for entity %s: %v", entityID, err)
+						continue
+					}
+
+					// Reject the whole change set if it breaks the schema; the stored entity stays as it was
+					if !m.validateForWrite(ctx, &entityWrite{entityID: entityID, entityType: ent.Type, payload: ent.Payload, event: &ev, operations: operations}) {
+						continue
 					}
 
 					// Add to history and save
 					ent.AddHistoryEntry(ev.ID, ev.Timestamp)
 					if err := m.saveSnapshotToMinIO(ctx, ent, &ev); err != nil {
-						log.Printf("Failed to update entity %s: %v", entityID, err)
+						logging.Errorf("Failed to update entity %s: %v", entityID, err)
 					} else {
-						log.Printf("Updated entity %s", entityID)
+						logging.Infof("Updated entity %s", entityID)
 					}
 				}
 			}
@@ -238,55 +267,41 @@ func (m *Manager) HandleEvent(ev eventbus.Event) {
 	}
 
 	// 3. Process entity.created events (for new entities)
-	if ev.Type == "entity.created" {
-		// Extract entity data from event payload
-		entityInfo := eventbus.ExtractEntityID(ev.Payload)
-		entityID := ""
-		entityType := ""
-		if entityInfo != nil {
-			entityID = entityInfo.ID
-			entityType = entityInfo.Type
+	if ev.Type == events.TypeEntityCreated {
+		// Entities announced by an archive import are already stored with their history
+		if imported, _ := ev.Path().GetBool("imported"); imported && ev.Source == "entity-manager" {
+			return
 		}
-		idExists := entityID != ""
-		typeExists := entityType != ""
-		payloadRaw, payloadExists := ev.Payload["payload"]
-
-		if idExists && typeExists && payloadExists {
-			// Convert payload to map[string]interface{}
-			var payload map[string]interface{}
-			if payloadMap, ok := payloadRaw.(map[string]interface{}); ok {
-				payload = payloadMap
-			} else {
-				// Try to marshal and unmarshal if it's a different type
-				data, err := json.Marshal(payloadRaw)
-				if err != nil {
-					log.Printf("Failed to marshal payload for entity %s: %v", entityID, err)
-					return
-				}
-				if err := json.Unmarshal(data, &payload); err != nil {
-					log.Printf("Failed to unmarshal payload for entity %s: %v", entityID, err)
-					return
-				}
+		var created events.EntityCreated
+		if err := events.Unmarshal(ev, &created); err != nil {
+			logging.Warnf("Invalid entity.created event %s: %v", ev.ID, err)
+			return
+		}
+		entityID, entityType := created.EntityID(), created.EntityType()
+
+		if entityID != "" && entityType != "" && created.Payload != nil {
+			if !m.validateForWrite(ctx, &entityWrite{entityID: entityID, entityType: entityType, payload: created.Payload, event: &ev}) {
+				return
 			}
 
 			// Create new entity
-			ent := entity.NewEntity(entityID, entityType, payload)
+			ent := entity.NewEntity(entityID, entityType, created.Payload)
 
 			// Add history entry
 			ent.AddHistoryEntry(ev.ID, ev.Timestamp)
 
 			// Save to MinIO
 			if err := m.saveSnapshotToMinIO(ctx, ent, &ev); err != nil {
-				log.Printf("Failed to save created entity %s: %v", entityID, err)
+				logging.Errorf("Failed to save created entity %s: %v", entityID, err)
 			} else {
-				log.Printf("Saved newly created entity %s to bucket %s", entityID, m.getBucketForEntity(ent, &ev))
+				logging.Infof("Saved newly created entity %s to bucket %s", entityID, m.getBucketForEntity(ent, &ev))
 			}
 		} else {
-			log.Printf("Incomplete entity.created event payload for entity_id=%v, entity_type=%v, payload=%v",
-				entityID, entityType, payloadExists)
+			logging.Infof("Incomplete entity.created event payload for entity_id=%v, entity_type=%v, payload=%v",
+				entityID, entityType, created.Payload != nil)
 		}
 	}
 
-	log.Println(ev)
+	logging.Debugf("%+v", ev)
 
 }
@@ -1,20 +1,20 @@
 // services/entitymanager/manager.go
 package entitymanager
 
+import "multiverse-core.io/shared/entity"
+
 type OperationType string
 
+// Legacy operation names; state_changes also accept JSON-Patch (RFC 6902) operations.
 const (
-	OpSet             OperationType = "set"
-	OpAddToSlice      OperationType = "add_to_slice"
-	OpRemoveFromSlice OperationType = "remove_from_slice"
-	OpRemove          OperationType = "remove"
+	OpSet             OperationType = entity.PatchSet
+	OpAddToSlice      OperationType = entity.PatchAddToSlice
+	OpRemoveFromSlice OperationType = entity.PatchRemoveFromSlice
+	OpRemove          OperationType = entity.PatchRemove
 )
 
-type Operation struct {
-	Op    OperationType `json:"op"`
-	Path  string        `json:"path"`
-	Value interface{}   `json:"value,omitempty"`
-}
+// Operation is a single state_changes operation, applied with entity.ApplyPatch.
+type Operation = entity.PatchOperation
 
 type StateChange struct {
 	EntityID   string      `json:"entity_id"`
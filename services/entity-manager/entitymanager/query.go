@@ -0,0 +1,144 @@
+// services/entitymanager/query.go
+package entitymanager
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"multiverse-core.io/shared/entity"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+)
+
+// Page size limits of entity listing.
+const (
+	defaultListLimit = 50
+	maxListLimit     = 500
+)
+
+// ListQuery selects entities of a world bucket.
+type ListQuery struct {
+	// WorldID selects the entities-{world_id} bucket; empty or "global" lists entities-global.
+	WorldID string
+	// Type filters by entity type; empty lists all types.
+	Type string
+	// Prefix filters by entity ID prefix.
+	Prefix string
+	// Cursor is the last entity ID of the previous page (exclusive).
+	Cursor string
+	Limit  int
+	// Fields projects the payload to the given (dot-separated) paths; empty returns the whole payload.
+	Fields []string
+}
+
+// EntitySummary is a listed entity with its (projected) payload.
+type EntitySummary struct {
+	ID        string                 `json:"id"`
+	Type      string                 `json:"type"`
+	UpdatedAt time.Time              `json:"updated_at"`
+	Payload   map[string]interface{} `json:"payload"`
+}
+
+// ListResult is a page of listed entities.
+type ListResult struct {
+	Entities []EntitySummary `json:"entities"`
+	// NextCursor is set when more entities follow; pass it as cursor to get the next page.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// bucketForWorld returns the entity bucket of a world.
+func bucketForWorld(worldID string) string {
+	if worldID == "" || worldID == "global" {
+		return "entities-global"
+	}
+	return "entities-" + worldID
+}
+
+// ListEntities lists entities of a world by type and ID prefix using the bucket's type index.
+// Entities are ordered by ID; indexed entities whose objects are gone are skipped.
+func (m *Manager) ListEntities(ctx context.Context, q ListQuery) (*ListResult, error) {
+	bucket := bucketForWorld(q.WorldID)
+	idx, err := m.readTypeIndex(ctx, bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := q.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+
+	ids, next := pageIDs(idx.ids(q.Type), q.Prefix, q.Cursor, limit)
+	result := &ListResult{Entities: make([]EntitySummary, 0, len(ids)), NextCursor: next}
+	for _, id := range ids {
+		ent, err := m.loadEntityFromBucket(ctx, bucket, id)
+		if err != nil {
+			if storage.IsNotFound(err) {
+				continue
+			}
+			if storage.IsUnavailable(err) {
+				return nil, err
+			}
+			logging.Warnf("Skipping entity %s/%s in listing: %v", bucket, id, err)
+			continue
+		}
+		result.Entities = append(result.Entities, EntitySummary{
+			ID:        ent.ID,
+			Type:      ent.Type,
+			UpdatedAt: ent.UpdatedAt,
+			Payload:   projectPayload(ent, q.Fields),
+		})
+	}
+	return result, nil
+}
+
+// pageIDs selects up to limit sorted IDs with the prefix after the cursor.
+// The returned cursor is empty on the last page.
+func pageIDs(ids []string, prefix, cursor string, limit int) ([]string, string) {
+	start := sort.SearchStrings(ids, prefix)
+	if cursor != "" && cursor >= prefix {
+		start = sort.Search(len(ids), func(i int) bool { return ids[i] > cursor })
+	}
+
+	page := make([]string, 0, limit)
+	for i := start; i < len(ids) && strings.HasPrefix(ids[i], prefix); i++ {
+		if len(page) == limit {
+			return page, page[len(page)-1]
+		}
+		page = append(page, ids[i])
+	}
+	return page, ""
+}
+
+// projectPayload copies the requested payload paths, keeping their nesting.
+// Missing paths are omitted.
+func projectPayload(ent *entity.Entity, fields []string) map[string]interface{} {
+	if len(fields) == 0 {
+		return ent.Payload
+	}
+
+	projected := make(map[string]interface{})
+	for _, field := range fields {
+		value, ok := ent.GetPath(field)
+		if !ok {
+			continue
+		}
+		parts := strings.Split(field, ".")
+		node := projected
+		for _, part := range parts[:len(parts)-1] {
+			child, ok := node[part].(map[string]interface{})
+			if !ok {
+				child = make(map[string]interface{})
+				node[part] = child
+			}
+			node = child
+		}
+		node[parts[len(parts)-1]] = value
+	}
+	return projected
+}
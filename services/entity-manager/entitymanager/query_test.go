@@ -0,0 +1,63 @@
+// services/entitymanager/query_test.go
+package entitymanager
+
+import (
+	"reflect"
+	"testing"
+
+	"multiverse-core.io/shared/entity"
+)
+
+func TestTypeIndex(t *testing.T) {
+	idx := &TypeIndex{}
+	idx.add("unknown", "npc-2")
+	idx.add("npc", "npc-3")
+	idx.add("npc", "npc-1")
+	if !idx.add("npc", "npc-2") {
+		t.Fatal("expected retyped entity to change the index")
+	}
+	if idx.add("npc", "npc-2") {
+		t.Error("expected indexing an already indexed entity to be a no-op")
+	}
+	idx.add("item", "item-1")
+
+	if got := idx.ids("npc"); !reflect.DeepEqual(got, []string{"npc-1", "npc-2", "npc-3"}) {
+		t.Errorf("unexpected npc ids: %v", got)
+	}
+	if _, ok := idx.Types["unknown"]; ok {
+		t.Error("expected empty type to be removed")
+	}
+	if got := idx.ids(""); !reflect.DeepEqual(got, []string{"item-1", "npc-1", "npc-2", "npc-3"}) {
+		t.Errorf("unexpected ids of all types: %v", got)
+	}
+}
+
+func TestPageIDs(t *testing.T) {
+	ids := []string{"item-1", "npc-guard-1", "npc-guard-2", "npc-guard-3", "npc-merchant-1"}
+
+	page, next := pageIDs(ids, "npc-guard", "", 2)
+	if !reflect.DeepEqual(page, []string{"npc-guard-1", "npc-guard-2"}) || next != "npc-guard-2" {
+		t.Fatalf("unexpected first page %v, cursor %q", page, next)
+	}
+	page, next = pageIDs(ids, "npc-guard", next, 2)
+	if !reflect.DeepEqual(page, []string{"npc-guard-3"}) || next != "" {
+		t.Errorf("unexpected last page %v, cursor %q", page, next)
+	}
+	if page, _ = pageIDs(ids, "", "npc-guard-3", 10); !reflect.DeepEqual(page, []string{"npc-merchant-1"}) {
+		t.Errorf("unexpected page after cursor: %v", page)
+	}
+}
+
+func TestProjectPayload(t *testing.T) {
+	ent := entity.NewEntity("npc-1", "npc", map[string]interface{}{
+		"name":  "Лира",
+		"stats": map[string]interface{}{"hp": 10, "mp": 5},
+		"bio":   "длинная история",
+	})
+
+	got := projectPayload(ent, []string{"name", "stats.hp", "missing.path"})
+	want := map[string]interface{}{"name": "Лира", "stats": map[string]interface{}{"hp": 10}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
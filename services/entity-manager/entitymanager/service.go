@@ -3,11 +3,16 @@ package entitymanager
 
 import (
 	"context"
-	"log"
-	"multiverse-core.io/shared/eventbus"
+	"net/http"
+	"sync"
+	"time"
 
-	"github.com/minio/minio-go/v7"
-	"github.com/minio/minio-go/v7/pkg/credentials"
+	"multiverse-core.io/shared/archivist"
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+	"multiverse-core.io/shared/registry"
+	"multiverse-core.io/shared/schema"
 )
 
 type Config struct {
@@ -15,54 +20,179 @@ type Config struct {
 	MinioAccessKey string
 	MinioSecretKey string
 	KafkaBrokers   []string
+	ArchivistURL   string // fallback archivist address when the registry has no live instance
+	HTTPPort       string
+	// HistoryVersions is the number of snapshot versions kept per entity
+	// (0 uses DefaultHistoryVersions, negative disables history)
+	HistoryVersions int
+	// CacheSize is the number of hot entities kept by the write-back cache
+	// (0 uses DefaultCacheSize, negative writes every change through to MinIO)
+	CacheSize int
+	// CacheFlushInterval is how often dirty cached entities are written to MinIO
+	// (0 uses DefaultCacheFlushInterval)
+	CacheFlushInterval time.Duration
+	// ConsumerGroup is the Kafka consumer group of the entity topics (empty uses DefaultConsumerGroup)
+	ConsumerGroup string
+	// Workers is the number of workers handling consumed events (0 uses DefaultWorkers)
+	Workers int
+}
+
+// DefaultConsumerGroup is the Kafka consumer group EntityManager reads entity topics with.
+const DefaultConsumerGroup = "entity-manager-group"
+
+// entityTopics are the topics whose events change entities.
+var entityTopics = []string{
+	eventbus.TopicPlayerEvents,
+	eventbus.TopicWorldEvents,
+	eventbus.TopicGameEvents,
+	eventbus.TopicSystemEvents,
 }
 
 type Service struct {
-	manager *Manager
-	bus     *eventbus.EventBus
+	manager   *Manager
+	bus       *eventbus.EventBus
+	discovery *registry.Discovery
+	archivist *archivist.Client
+	server    *http.Server
+
+	flushInterval time.Duration
+	// schemaChanges drops cached entity schemas when the archivist announces a new version
+	schemaChanges *schema.ChangeSubscriber
+
+	consumerGroup string
+	workers       int
+	// subscriptions tracks the topic consumers; events are handed to the dispatcher until they stop
+	subscriptions sync.WaitGroup
+	dispatcher    *dispatcher
+	stopOnce      sync.Once
 }
 
 func NewService(cfg Config) (*Service, error) {
-	// Создаём MinIO клиент напрямую
-	minioClient, err := minio.New(cfg.MinioEndpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(cfg.MinioAccessKey, cfg.MinioSecretKey, ""),
-		Secure: false,
+	minioClient, err := storage.NewMinIOOfficialClient(storage.Config{
+		Endpoint:        cfg.MinioEndpoint,
+		AccessKeyID:     cfg.MinioAccessKey,
+		SecretAccessKey: cfg.MinioSecretKey,
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	manager := &Manager{minio: minioClient}
 	bus := eventbus.NewEventBus(cfg.KafkaBrokers)
+	discovery := registry.NewDiscovery(bus, "entity-manager")
+	archivistClient := archivist.NewClient(cfg.ArchivistURL, discovery)
+	schema.RegisterCustomFormats()
 
-	return &Service{
-		manager: manager,
-		bus:     bus,
-	}, nil
-}
+	historyVersions := cfg.HistoryVersions
+	if historyVersions == 0 {
+		historyVersions = DefaultHistoryVersions
+	}
 
-func (s *Service) Start(ctx context.Context) {
-	log.Println("EntityManager started")
+	manager := &Manager{
+		minio:           minioClient,
+		schemas:         archivist.NewEntitySchemas(archivistClient),
+		publish:         bus.PublishSystemEvent,
+		historyVersions: historyVersions,
+	}
 
-	// Subscribe to all event topics for entity management
-	topics := []string{
-		eventbus.TopicPlayerEvents,
-		eventbus.TopicWorldEvents,
-		eventbus.TopicGameEvents,
-		eventbus.TopicSystemEvents,
+	cacheSize := cfg.CacheSize
+	if cacheSize == 0 {
+		cacheSize = DefaultCacheSize
+	}
+	if cacheSize > 0 {
+		manager.cache = NewEntityCache(cacheSize, manager.writeEntity)
+	}
+	flushInterval := cfg.CacheFlushInterval
+	if flushInterval <= 0 {
+		flushInterval = DefaultCacheFlushInterval
 	}
 
-	for _, topic := range topics {
+	schemaChanges := schema.NewChangeSubscriber(bus, "entity-manager")
+	schemaChanges.OnChange(manager.schemas.HandleSchemaChange)
+
+	s := &Service{
+		manager:   manager,
+		bus:       bus,
+		discovery: discovery,
+		archivist: archivistClient,
+
+		flushInterval: flushInterval,
+		schemaChanges: schemaChanges,
+		consumerGroup: cfg.ConsumerGroup,
+		workers:       cfg.Workers,
+	}
+	if s.consumerGroup == "" {
+		s.consumerGroup = DefaultConsumerGroup
+	}
+
+	port := cfg.HTTPPort
+	if port == "" {
+		port = "8085"
+	}
+	s.server = &http.Server{
+		Addr:         ":" + port,
+		Handler:      s.routes(),
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	}
+	return s, nil
+}
+
+// Run consumes the entity topics and serves the HTTP API until ctx is cancelled, then stops gracefully.
+func (s *Service) Run(ctx context.Context) error {
+	logging.Infof("EntityManager started: consumer group %s", s.consumerGroup)
+
+	// Events of one entity are handled in order by one worker, different entities concurrently
+	s.dispatcher = newDispatcher(s.workers, s.manager.HandleEvent)
+	for _, topic := range entityTopics {
 		topic := topic
+		s.subscriptions.Add(1)
 		go func() {
-			s.bus.Subscribe(ctx, topic, "entity-manager-group", s.manager.HandleEvent)
+			defer s.subscriptions.Done()
+			s.bus.Subscribe(ctx, topic, s.consumerGroup, s.dispatcher.Dispatch)
 		}()
 	}
 
 	// Subscribe to Entity-Actor lifecycle events
 	s.manager.SubscribeToEvents(ctx, s.bus)
+
+	if s.manager.cache != nil {
+		go s.manager.cache.Run(ctx, s.flushInterval)
+	}
+
+	go s.discovery.Run(ctx)
+	go s.schemaChanges.Run(ctx)
+	go func() {
+		logging.Infof("EntityManager HTTP API listening on %s", s.server.Addr)
+		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logging.Errorf("EntityManager HTTP server failed: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	s.Stop()
+	return ctx.Err()
 }
 
+// Stop shuts the HTTP API down, waits for the consumers and the events they queued,
+// then writes the changes still held by the write-back cache. The context passed to Run
+// must be cancelled first so the consumers stop; safe to call more than once.
 func (s *Service) Stop() {
-	s.bus.Close()
+	s.stopOnce.Do(func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		s.server.Shutdown(shutdownCtx)
+
+		if s.dispatcher != nil {
+			s.subscriptions.Wait()
+			s.dispatcher.Close()
+		}
+		s.bus.Close()
+
+		// Persist changes still held by the write-back cache once no more events arrive
+		if s.manager.cache != nil {
+			flushCtx, cancelFlush := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancelFlush()
+			logging.Infof("Flushed %d cached entities on shutdown", s.manager.cache.Flush(flushCtx))
+		}
+	})
 }
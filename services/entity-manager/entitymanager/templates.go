@@ -0,0 +1,228 @@
+// services/entitymanager/templates.go
+package entitymanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrMissingVariables is returned when a template needs variables the request didn't provide.
+	ErrMissingVariables = errors.New("missing template variables")
+	// ErrSchemaValidation is returned when an entity payload doesn't match its entity type schema.
+	ErrSchemaValidation = errors.New("entity payload does not match schema")
+)
+
+// placeholderPattern matches {{name}} placeholders in template string values.
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([a-zA-Z0-9_.-]+)\s*\}\}`)
+
+// TemplateVariable describes a {{name}} placeholder of a template payload.
+type TemplateVariable struct {
+	Description string      `json:"description,omitempty"`
+	Required    bool        `json:"required,omitempty"`
+	Default     interface{} `json:"default,omitempty"`
+}
+
+// EntityTemplate mirrors the template stored by OntologicalArchivist.
+type EntityTemplate struct {
+	EntityType  string                      `json:"entity_type"`
+	Name        string                      `json:"name"`
+	Version     int                         `json:"version"`
+	Description string                      `json:"description,omitempty"`
+	Variables   map[string]TemplateVariable `json:"variables,omitempty"`
+	Payload     map[string]interface{}      `json:"payload"`
+}
+
+// SpawnRequest instantiates an entity from a template.
+type SpawnRequest struct {
+	WorldID    string `json:"world_id"`
+	EntityType string `json:"entity_type"`
+	Template   string `json:"template"`
+	// Version of the template; 0 uses the latest one.
+	Version int `json:"version,omitempty"`
+	// EntityID is generated as {entity_type}-{uuid} when empty.
+	EntityID  string                 `json:"entity_id,omitempty"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+	// Overrides are deep-merged into the rendered payload.
+	Overrides map[string]interface{} `json:"overrides,omitempty"`
+}
+
+// SpawnResult describes the spawned entity.
+type SpawnResult struct {
+	EntityID        string                 `json:"entity_id"`
+	EntityType      string                 `json:"entity_type"`
+	WorldID         string                 `json:"world_id"`
+	Template        string                 `json:"template"`
+	TemplateVersion int                    `json:"template_version"`
+	EventID         string                 `json:"event_id"`
+	Payload         map[string]interface{} `json:"payload"`
+}
+
+// Spawn renders a template with variables and overrides, validates the payload against
+// the entity type schema and publishes entity.created. The entity is persisted by
+// HandleEvent like any other created entity.
+func (s *Service) Spawn(ctx context.Context, req SpawnRequest) (*SpawnResult, error) {
+	if req.WorldID == "" || req.EntityType == "" || req.Template == "" {
+		return nil, fmt.Errorf("world_id, entity_type and template are required")
+	}
+
+	tmpl := &EntityTemplate{}
+	if err := s.archivist.GetTemplate(ctx, req.EntityType, req.Template, req.Version, tmpl); err != nil {
+		return nil, err
+	}
+
+	entityID := req.EntityID
+	if entityID == "" {
+		entityID = req.EntityType + "-" + uuid.NewString()
+	}
+
+	// entity_id and world_id are always available to templates
+	variables := map[string]interface{}{"entity_id": entityID, "world_id": req.WorldID}
+	for name, value := range req.Variables {
+		variables[name] = value
+	}
+
+	payload, err := RenderTemplate(tmpl, variables)
+	if err != nil {
+		return nil, err
+	}
+	mergeOverrides(payload, req.Overrides)
+
+	if err := s.ValidatePayload(ctx, req.EntityType, payload); err != nil {
+		return nil, err
+	}
+
+	eventPayload := eventbus.NewEventPayload().
+		WithEntity(entityID, req.EntityType, "").
+		WithWorld(req.WorldID)
+	eventbus.SetNested(eventPayload.GetCustom(), "payload", payload)
+	eventbus.SetNested(eventPayload.GetCustom(), "template.name", tmpl.Name)
+	eventbus.SetNested(eventPayload.GetCustom(), "template.version", tmpl.Version)
+
+	event := eventbus.NewStructuredEvent("entity.created", "entity-manager", req.WorldID, eventPayload)
+	if err := s.bus.Publish(ctx, eventbus.TopicSystemEvents, event); err != nil {
+		return nil, fmt.Errorf("failed to publish entity.created: %w", err)
+	}
+	logging.Infof("Spawned entity %s from template %s/%s v%d", entityID, req.EntityType, tmpl.Name, tmpl.Version)
+
+	return &SpawnResult{
+		EntityID:        entityID,
+		EntityType:      req.EntityType,
+		WorldID:         req.WorldID,
+		Template:        tmpl.Name,
+		TemplateVersion: tmpl.Version,
+		EventID:         event.ID,
+		Payload:         payload,
+	}, nil
+}
+
+// ValidatePayload validates an entity payload against the payload part of its type schema.
+// Entity types without a schema in the archivist are accepted as is.
+func (s *Service) ValidatePayload(ctx context.Context, entityType string, payload map[string]interface{}) error {
+	violations, err := s.manager.schemas.Validate(ctx, entityType, payload)
+	if err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		descriptions := make([]string, len(violations))
+		for i, violation := range violations {
+			descriptions[i] = violation.Field + ": " + violation.Description
+		}
+		return fmt.Errorf("%w: %s", ErrSchemaValidation, strings.Join(descriptions, "; "))
+	}
+	return nil
+}
+
+// RenderTemplate substitutes {{name}} placeholders in the template payload.
+// A value consisting of a single placeholder takes the variable as is (keeping its type);
+// placeholders inside longer strings are formatted into the string.
+// Missing variables fall back to the declared default; without one the spawn fails.
+func RenderTemplate(tmpl *EntityTemplate, variables map[string]interface{}) (map[string]interface{}, error) {
+	values := make(map[string]interface{}, len(variables)+len(tmpl.Variables))
+	for name, variable := range tmpl.Variables {
+		if variable.Default != nil {
+			values[name] = variable.Default
+		}
+	}
+	for name, value := range variables {
+		values[name] = value
+	}
+
+	missing := make(map[string]bool)
+	for name, variable := range tmpl.Variables {
+		if _, ok := values[name]; !ok && variable.Required {
+			missing[name] = true
+		}
+	}
+
+	rendered := renderValue(tmpl.Payload, values, missing)
+	if len(missing) > 0 {
+		names := make([]string, 0, len(missing))
+		for name := range missing {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("%w: %s", ErrMissingVariables, strings.Join(names, ", "))
+	}
+	return rendered.(map[string]interface{}), nil
+}
+
+// renderValue returns a rendered copy of value, recording unresolved placeholders in missing.
+func renderValue(value interface{}, values map[string]interface{}, missing map[string]bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			out[key] = renderValue(item, values, missing)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = renderValue(item, values, missing)
+		}
+		return out
+	case string:
+		if match := placeholderPattern.FindStringSubmatch(v); match != nil && match[0] == v {
+			resolved, ok := values[match[1]]
+			if !ok {
+				missing[match[1]] = true
+			}
+			return resolved
+		}
+		return placeholderPattern.ReplaceAllStringFunc(v, func(placeholder string) string {
+			name := placeholderPattern.FindStringSubmatch(placeholder)[1]
+			resolved, ok := values[name]
+			if !ok {
+				missing[name] = true
+				return placeholder
+			}
+			return fmt.Sprint(resolved)
+		})
+	default:
+		return v
+	}
+}
+
+// mergeOverrides deep-merges overrides into payload: nested objects are merged,
+// any other value replaces the rendered one.
+func mergeOverrides(payload, overrides map[string]interface{}) {
+	for key, override := range overrides {
+		overrideMap, isMap := override.(map[string]interface{})
+		current, currentIsMap := payload[key].(map[string]interface{})
+		if isMap && currentIsMap {
+			mergeOverrides(current, overrideMap)
+			continue
+		}
+		payload[key] = override
+	}
+}
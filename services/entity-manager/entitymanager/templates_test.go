@@ -0,0 +1,59 @@
+package entitymanager
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	tmpl := &EntityTemplate{
+		Name: "guard",
+		Variables: map[string]TemplateVariable{
+			"level": {Default: 3.0},
+			"name":  {Required: true},
+		},
+		Payload: map[string]interface{}{
+			"name":  "{{name}}",
+			"title": "Guard {{name}} of {{world_id}}",
+			"stats": map[string]interface{}{"level": "{{level}}"},
+			"tags":  []interface{}{"guard", "{{ name }}"},
+		},
+	}
+
+	payload, err := RenderTemplate(tmpl, map[string]interface{}{"name": "Borin", "world_id": "world-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if payload["title"] != "Guard Borin of world-1" {
+		t.Errorf("unexpected title: %v", payload["title"])
+	}
+	if level := payload["stats"].(map[string]interface{})["level"]; level != 3.0 {
+		t.Errorf("single placeholder must keep the default's type, got %#v", level)
+	}
+	if tags := payload["tags"].([]interface{}); tags[1] != "Borin" {
+		t.Errorf("unexpected tags: %v", tags)
+	}
+	if tmpl.Payload["name"] != "{{name}}" {
+		t.Error("rendering must not modify the template")
+	}
+
+	if _, err := RenderTemplate(tmpl, nil); !errors.Is(err, ErrMissingVariables) {
+		t.Errorf("expected ErrMissingVariables, got %v", err)
+	}
+}
+
+func TestMergeOverrides(t *testing.T) {
+	payload := map[string]interface{}{
+		"stats": map[string]interface{}{"level": 1, "hp": 10},
+		"name":  "Borin",
+	}
+	mergeOverrides(payload, map[string]interface{}{
+		"stats": map[string]interface{}{"hp": 50},
+		"name":  "Dwalin",
+	})
+
+	stats := payload["stats"].(map[string]interface{})
+	if stats["hp"] != 50 || stats["level"] != 1 || payload["name"] != "Dwalin" {
+		t.Errorf("unexpected merge result: %v", payload)
+	}
+}
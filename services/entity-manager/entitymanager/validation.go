@@ -0,0 +1,69 @@
+// services/entitymanager/validation.go
+package entitymanager
+
+import (
+	"context"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+	"multiverse-core.io/shared/schema"
+)
+
+// EventValidationFailed is published when an entity write is rejected by its schema.
+const EventValidationFailed = "entity.validation.failed"
+
+// validateForWrite checks an entity before it is persisted. It returns false when the entity
+// must be rejected; the rejection is published as entity.validation.failed with the violations.
+// If the archivist is unreachable the write is allowed, so schema storage outages don't block the world.
+func (m *Manager) validateForWrite(ctx context.Context, ent *entityWrite) bool {
+	if m.schemas == nil {
+		return true
+	}
+
+	violations, err := m.schemas.Validate(ctx, ent.entityType, ent.payload)
+	if err != nil {
+		logging.Infof("Schema validation of entity %s skipped: %v", ent.entityID, err)
+		return true
+	}
+	if len(violations) == 0 {
+		return true
+	}
+
+	logging.Warnf("Rejected entity %s (%s): %d schema violations", ent.entityID, ent.entityType, len(violations))
+	m.publishValidationFailed(ctx, ent, violations)
+	return false
+}
+
+// entityWrite describes a pending entity write for validation and failure reporting.
+type entityWrite struct {
+	entityID   string
+	entityType string
+	payload    map[string]interface{}
+	event      *eventbus.Event
+	// operations are the rejected state_changes operations, if the write came from them
+	operations []interface{}
+}
+
+// publishValidationFailed reports a rejected write with the field-level diff against the schema.
+func (m *Manager) publishValidationFailed(ctx context.Context, ent *entityWrite, violations []schema.FieldError) {
+	if m.publish == nil {
+		return
+	}
+
+	worldID := eventbus.GetWorldIDFromEvent(*ent.event)
+	payload := eventbus.NewEventPayload().
+		WithEntity(ent.entityID, ent.entityType, "").
+		WithWorld(worldID)
+	eventbus.SetNested(payload.GetCustom(), "source_event.id", ent.event.ID)
+	eventbus.SetNested(payload.GetCustom(), "source_event.type", ent.event.Type)
+	eventbus.SetNested(payload.GetCustom(), "violations", violations)
+	eventbus.SetNested(payload.GetCustom(), "rejected_payload", ent.payload)
+	if len(ent.operations) > 0 {
+		eventbus.SetNested(payload.GetCustom(), "operations", ent.operations)
+	}
+
+	event := eventbus.NewStructuredEvent(EventValidationFailed, "entity-manager", worldID, payload)
+	if err := m.publish(ctx, event); err != nil {
+		logging.Errorf("Failed to publish %s for entity %s: %v", EventValidationFailed, ent.entityID, err)
+	}
+}
@@ -0,0 +1,68 @@
+package entitymanager
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"multiverse-core.io/shared/archivist"
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/schema"
+)
+
+const npcSchema = `{
+	"type": "object",
+	"properties": {
+		"entity_id": {"type": "string"},
+		"payload": {
+			"type": "object",
+			"properties": {"name": {"type": "string"}, "level": {"type": "integer"}},
+			"required": ["name"]
+		}
+	}
+}`
+
+func newTestArchivist(t *testing.T, requests *int32) *archivist.Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		if r.URL.Path != "/v1/schemas/entity/npc/latest" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(npcSchema))
+	}))
+	t.Cleanup(server.Close)
+	return archivist.NewClient(server.URL, nil)
+}
+
+func TestValidateForWriteRejects(t *testing.T) {
+	var requests int32
+	var published []eventbus.Event
+	m := &Manager{
+		schemas: archivist.NewEntitySchemas(newTestArchivist(t, &requests)),
+		publish: func(ctx context.Context, event eventbus.Event) error {
+			published = append(published, event)
+			return nil
+		},
+	}
+
+	ev := eventbus.NewEvent("entity.created", "test", "world-1", nil)
+	ok := m.validateForWrite(context.Background(), &entityWrite{
+		entityID:   "npc-1",
+		entityType: "npc",
+		payload:    map[string]interface{}{"level": 1},
+		event:      &ev,
+	})
+	if ok {
+		t.Fatal("expected invalid entity to be rejected")
+	}
+	if len(published) != 1 || published[0].Type != EventValidationFailed {
+		t.Fatalf("expected %s event, got %v", EventValidationFailed, published)
+	}
+	violations, _ := published[0].Payload["violations"].([]schema.FieldError)
+	if len(violations) != 1 || violations[0].Field != "(root)" {
+		t.Errorf("expected missing name violation, got %v", published[0].Payload["violations"])
+	}
+}
@@ -0,0 +1,30 @@
+// Package main is the entry point for EventArchiver.
+package main
+
+import (
+	"log"
+
+	"multiverse-core.io/services/event-archiver/eventarchiver"
+	"multiverse-core.io/shared/config"
+	"multiverse-core.io/shared/service"
+)
+
+func main() {
+	app := service.Setup("event-archiver", []config.Option{
+		{Env: "EVENT_ARCHIVE_BUCKET", Default: eventarchiver.DefaultBucket, Usage: "MinIO bucket of the event log"},
+		{Env: "EVENT_ARCHIVE_FLUSH_INTERVAL", Default: "1m", Type: config.TypeDuration, Positive: true, Usage: "how often buffered events are written to MinIO"},
+		{Env: "EVENT_ARCHIVER_PORT", Default: eventarchiver.DefaultHTTPPort, Type: config.TypeInt, Positive: true, Usage: "HTTP API port (replay)"},
+	})
+	env := app.Env
+
+	minioClient, err := app.MinIO()
+	if err != nil {
+		log.Fatalf("Failed to create MinIO client: %v", err)
+	}
+
+	archiver := eventarchiver.NewService(app.Bus(), minioClient, env.String("EVENT_ARCHIVE_BUCKET"))
+	archiver.SetFlushInterval(env.Duration("EVENT_ARCHIVE_FLUSH_INTERVAL"))
+	archiver.UseHTTP(env.String("EVENT_ARCHIVER_PORT"))
+
+	app.Run(archiver)
+}
@@ -0,0 +1,72 @@
+package eventarchiver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"multiverse-core.io/shared/logging"
+)
+
+// DefaultHTTPPort is the port of the EventArchiver replay API.
+const DefaultHTTPPort = "8095"
+
+// routes builds the EventArchiver HTTP API.
+func (s *Service) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", s.handleHealth)
+	mux.HandleFunc("POST /v1/replay", s.handleReplay)
+	return mux
+}
+
+// handleHealth handles GET /health.
+func (s *Service) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":   "healthy",
+		"time":     time.Now().Format(time.RFC3339),
+		"buffered": s.archiver.Buffered(),
+	})
+}
+
+// handleReplay handles POST /v1/replay: republishes a time range of the archive (see ReplayRequest).
+// The replay runs within the request; a client disconnect stops it.
+func (s *Service) handleReplay(w http.ResponseWriter, r *http.Request) {
+	var req ReplayRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	report, err := s.Replay(r.Context(), req)
+	switch {
+	case errors.Is(err, ErrInvalidReplay):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case err != nil:
+		logging.Errorf("Replay failed: %v", err)
+		writeJSON(w, http.StatusBadGateway, map[string]interface{}{"error": err.Error(), "report": report})
+	default:
+		writeJSON(w, http.StatusOK, report)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// serveHTTP runs the HTTP API until ctx is cancelled.
+func (s *Service) serveHTTP(ctx context.Context) {
+	go func() {
+		logging.Infof("EventArchiver HTTP API listening on %s", s.server.Addr)
+		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logging.Errorf("EventArchiver HTTP server failed: %v", err)
+		}
+	}()
+	<-ctx.Done()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	s.server.Shutdown(shutdownCtx)
+}
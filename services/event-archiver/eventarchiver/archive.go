@@ -0,0 +1,152 @@
+// Package eventarchiver implements the EventArchiver: an event log of every Kafka topic in MinIO
+// and the replay of a stored time range back into Kafka.
+package eventarchiver
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultBucket is the MinIO bucket of the event log.
+	DefaultBucket = "event-archive"
+	// DefaultFlushInterval is how often buffered events are written to MinIO.
+	DefaultFlushInterval = time.Minute
+	// maxSegmentEvents flushes a segment early so a busy hour is not held in memory.
+	maxSegmentEvents = 5000
+)
+
+// hourLayout names the hourly segment directory of a topic: {topic}/{2006-01-02T15}/.
+const hourLayout = "2006-01-02T15"
+
+// segmentKey identifies an hourly segment of a topic.
+type segmentKey struct {
+	topic string
+	hour  time.Time
+}
+
+// prefix returns the object prefix of the segment.
+func (k segmentKey) prefix() string {
+	return hourPrefix(k.topic, k.hour)
+}
+
+// hourPrefix returns the object prefix holding the events of a topic for the hour containing t.
+func hourPrefix(topic string, t time.Time) string {
+	return topic + "/" + t.UTC().Truncate(time.Hour).Format(hourLayout) + "/"
+}
+
+// Archiver buffers consumed events and writes them to MinIO as JSON Lines.
+// Events are grouped by topic and by the hour of their timestamp; every flush adds
+// a new part to the hourly segment, so parts are never rewritten.
+type Archiver struct {
+	storage storage.ObjectStorage
+	bucket  string
+	now     func() time.Time
+
+	mu      sync.Mutex
+	buffers map[segmentKey][]eventbus.Event
+}
+
+// NewArchiver creates an archiver writing to the given bucket (DefaultBucket when empty).
+func NewArchiver(objects storage.ObjectStorage, bucket string) *Archiver {
+	if bucket == "" {
+		bucket = DefaultBucket
+	}
+	return &Archiver{
+		storage: objects,
+		bucket:  bucket,
+		now:     time.Now,
+		buffers: make(map[segmentKey][]eventbus.Event),
+	}
+}
+
+// Record buffers an event consumed from the topic. Events without a timestamp are filed
+// under the time they were received.
+func (a *Archiver) Record(topic string, ev eventbus.Event) {
+	at := ev.Timestamp
+	if at.IsZero() {
+		at = a.now()
+	}
+	key := segmentKey{topic: topic, hour: at.UTC().Truncate(time.Hour)}
+
+	a.mu.Lock()
+	a.buffers[key] = append(a.buffers[key], ev)
+	full := len(a.buffers[key]) >= maxSegmentEvents
+	a.mu.Unlock()
+
+	if full {
+		if err := a.flushSegment(key); err != nil {
+			logging.Errorf("Failed to flush %s segment early: %v", key.prefix(), err)
+		}
+	}
+}
+
+// Flush writes every buffered event. Segments that fail to upload stay buffered
+// for the next flush; the errors are joined.
+func (a *Archiver) Flush() error {
+	a.mu.Lock()
+	keys := make([]segmentKey, 0, len(a.buffers))
+	for key := range a.buffers {
+		keys = append(keys, key)
+	}
+	a.mu.Unlock()
+
+	var errs []error
+	for _, key := range keys {
+		if err := a.flushSegment(key); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key.prefix(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// flushSegment uploads the buffered events of one segment as a new part.
+func (a *Archiver) flushSegment(key segmentKey) error {
+	a.mu.Lock()
+	batch := a.buffers[key]
+	delete(a.buffers, key)
+	a.mu.Unlock()
+	if len(batch) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, ev := range batch {
+		if err := encoder.Encode(ev); err != nil {
+			logging.Warnf("Skipping unencodable event %s from %s: %v", ev.ID, key.topic, err)
+		}
+	}
+
+	// Parts sort by write time within the hour
+	object := fmt.Sprintf("%s%020d-%s.jsonl", key.prefix(), a.now().UnixNano(), uuid.NewString()[:8])
+	if err := a.storage.PutObject(a.bucket, object, bytes.NewReader(buf.Bytes()), int64(buf.Len())); err != nil {
+		// Keep the events for the next flush, ahead of those received meanwhile
+		a.mu.Lock()
+		a.buffers[key] = append(batch, a.buffers[key]...)
+		a.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Buffered returns the number of events waiting to be written.
+func (a *Archiver) Buffered() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	n := 0
+	for _, batch := range a.buffers {
+		n += len(batch)
+	}
+	return n
+}
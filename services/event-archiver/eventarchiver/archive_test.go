@@ -0,0 +1,186 @@
+package eventarchiver
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/minio/miniotest"
+)
+
+type published struct {
+	topic string
+	event eventbus.Event
+}
+
+func recordingPublisher(out *[]published) Publisher {
+	return func(ctx context.Context, topic string, ev eventbus.Event) error {
+		*out = append(*out, published{topic: topic, event: ev})
+		return nil
+	}
+}
+
+func archivedEventAt(id, worldID string, at time.Time) eventbus.Event {
+	ev := eventbus.NewEvent("test.event", "test", worldID, map[string]any{"world_id": worldID})
+	ev.ID = id
+	ev.Timestamp = at
+	return ev
+}
+
+func TestArchiveAndReplay(t *testing.T) {
+	objects := miniotest.New()
+	archiver := NewArchiver(objects, "")
+	base := time.Date(2026, 10, 1, 12, 30, 0, 0, time.UTC)
+
+	archiver.Record(eventbus.TopicPlayerEvents, archivedEventAt("p1", "w1", base.Add(40*time.Minute)))
+	archiver.Record(eventbus.TopicWorldEvents, archivedEventAt("w1", "w1", base))
+	archiver.Record(eventbus.TopicPlayerEvents, archivedEventAt("p0", "w2", base.Add(10*time.Minute)))
+	if err := archiver.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if archiver.Buffered() != 0 {
+		t.Fatalf("Buffered = %d after flush, want 0", archiver.Buffered())
+	}
+
+	parts := objects.Puts(DefaultBucket)
+	if len(parts) != 3 {
+		t.Fatalf("parts = %v, want 3 (two hours of player events, one of world events)", parts)
+	}
+	for _, part := range parts {
+		if !strings.HasSuffix(part, ".jsonl") || strings.Count(part, "/") != 2 {
+			t.Errorf("part %q does not match {topic}/{hour}/{part}.jsonl", part)
+		}
+	}
+
+	var out []published
+	report, err := Replay(context.Background(), objects, "", ReplayRequest{
+		From:        base,
+		To:          base.Add(2 * time.Hour),
+		Topics:      []string{eventbus.TopicPlayerEvents, eventbus.TopicWorldEvents},
+		TargetTopic: "replay_events",
+		WorldMap:    map[string]string{"w1": "staging"},
+	}, recordingPublisher(&out))
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+
+	var ids []string
+	for _, p := range out {
+		ids = append(ids, p.event.ID)
+		if p.topic != "replay_events" {
+			t.Errorf("event %s published to %s, want replay_events", p.event.ID, p.topic)
+		}
+	}
+	if got := strings.Join(ids, ","); got != "w1,p0,p1" {
+		t.Errorf("replay order = %s, want w1,p0,p1", got)
+	}
+	if report.Events != 3 || report.Published != 3 || report.Remapped != 2 || report.Segments != 3 {
+		t.Errorf("report = %+v", report)
+	}
+
+	if got := eventbus.GetWorldIDFromEvent(out[0].event); got != "staging" {
+		t.Errorf("remapped world = %q, want staging", got)
+	}
+	if got := out[0].event.Payload["world_id"]; got != "staging" {
+		t.Errorf("remapped payload world_id = %v, want staging", got)
+	}
+	if got := eventbus.GetWorldIDFromEvent(out[1].event); got != "w2" {
+		t.Errorf("unmapped world = %q, want w2", got)
+	}
+}
+
+func TestReplayFiltersRangeAndDryRun(t *testing.T) {
+	objects := miniotest.New()
+	archiver := NewArchiver(objects, "")
+	base := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	archiver.Record(eventbus.TopicPlayerEvents, archivedEventAt("early", "w1", base.Add(5*time.Minute)))
+	archiver.Record(eventbus.TopicPlayerEvents, archivedEventAt("inside", "w1", base.Add(20*time.Minute)))
+	archiver.Record(eventbus.TopicPlayerEvents, archivedEventAt("late", "w1", base.Add(50*time.Minute)))
+	if err := archiver.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	var out []published
+	report, err := Replay(context.Background(), objects, "", ReplayRequest{
+		From:   base.Add(10 * time.Minute),
+		To:     base.Add(30 * time.Minute),
+		Topics: []string{eventbus.TopicPlayerEvents},
+		DryRun: true,
+	}, recordingPublisher(&out))
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if len(out) != 0 {
+		t.Errorf("dry run published %d events", len(out))
+	}
+	if report.Events != 1 || report.Published != 0 || report.ByTopic[eventbus.TopicPlayerEvents] != 1 {
+		t.Errorf("report = %+v", report)
+	}
+}
+
+func TestReplayRejectsInvalidRange(t *testing.T) {
+	base := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	for name, req := range map[string]ReplayRequest{
+		"missing":  {From: base},
+		"reversed": {From: base, To: base.Add(-time.Hour)},
+		"too long": {From: base, To: base.Add(maxReplayRange + time.Hour)},
+	} {
+		_, err := Replay(context.Background(), miniotest.New(), "", req, recordingPublisher(new([]published)))
+		if !errors.Is(err, ErrInvalidReplay) {
+			t.Errorf("%s: err = %v, want ErrInvalidReplay", name, err)
+		}
+	}
+}
+
+func TestFlushKeepsEventsOnUploadFailure(t *testing.T) {
+	objects := miniotest.New()
+	archiver := NewArchiver(objects, "")
+	archiver.Record(eventbus.TopicPlayerEvents, archivedEventAt("p1", "w1", time.Now()))
+
+	objects.FailPuts(errors.New("minio down"))
+	if err := archiver.Flush(); err == nil {
+		t.Fatal("Flush succeeded with failing storage")
+	}
+	if archiver.Buffered() != 1 {
+		t.Fatalf("Buffered = %d after failed flush, want 1", archiver.Buffered())
+	}
+
+	objects.FailPuts(nil)
+	if err := archiver.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if archiver.Buffered() != 0 {
+		t.Errorf("Buffered = %d after retry, want 0", archiver.Buffered())
+	}
+}
+
+func TestReplayToNonCoreTopic(t *testing.T) {
+	t.Setenv("KAFKA_AUTO_CREATE_TOPICS", "false")
+	objects := miniotest.New()
+	archiver := NewArchiver(objects, "")
+	base := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	archiver.Record(eventbus.TopicPlayerEvents, archivedEventAt("p1", "w1", base.Add(time.Minute)))
+	if err := archiver.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	// A real EventBus without a broker: publishing to replay_events fails instead of panicking
+	bus := eventbus.NewEventBus([]string{"127.0.0.1:1"})
+	defer bus.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	report, err := Replay(ctx, objects, "", ReplayRequest{
+		From:        base,
+		To:          base.Add(time.Hour),
+		TargetTopic: "replay_events",
+	}, bus.Publish)
+	if err == nil || !strings.Contains(err.Error(), "replay_events") {
+		t.Fatalf("expected a publish error for replay_events, got %v", err)
+	}
+	if report.Events != 1 || report.Published != 0 {
+		t.Errorf("report = %+v", report)
+	}
+}
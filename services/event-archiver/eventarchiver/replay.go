@@ -0,0 +1,246 @@
+package eventarchiver
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+)
+
+// maxReplayRange bounds a single replay: every hour of the range is listed in every topic.
+const maxReplayRange = 31 * 24 * time.Hour
+
+// ErrInvalidReplay — the replay request is malformed.
+var ErrInvalidReplay = errors.New("invalid replay request")
+
+// ReplayRequest selects the archived events to republish.
+type ReplayRequest struct {
+	// From and To bound the event timestamps: From inclusive, To exclusive
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+	// Topics to replay; empty — every core topic
+	Topics []string `json:"topics,omitempty"`
+	// TargetTopic receives every replayed event; empty — the topic the event was archived from
+	TargetTopic string `json:"target_topic,omitempty"`
+	// WorldMap remaps world IDs (old → new), e.g. to seed a new environment from production traffic
+	WorldMap map[string]string `json:"world_map,omitempty"`
+	// DryRun only counts the events that would be published
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// validate checks the request and fills the default topics.
+func (r *ReplayRequest) validate() error {
+	if r.From.IsZero() || r.To.IsZero() {
+		return fmt.Errorf("%w: from and to are required", ErrInvalidReplay)
+	}
+	if !r.To.After(r.From) {
+		return fmt.Errorf("%w: to must be after from", ErrInvalidReplay)
+	}
+	if r.To.Sub(r.From) > maxReplayRange {
+		return fmt.Errorf("%w: range exceeds %s", ErrInvalidReplay, maxReplayRange)
+	}
+	if len(r.Topics) == 0 {
+		r.Topics = eventbus.CoreTopics
+	}
+	return nil
+}
+
+// ReplayReport summarizes a replay.
+type ReplayReport struct {
+	From      time.Time      `json:"from"`
+	To        time.Time      `json:"to"`
+	Segments  int            `json:"segments"`
+	Events    int            `json:"events"`
+	Published int            `json:"published"`
+	ByTopic   map[string]int `json:"by_topic"`
+	Remapped  int            `json:"remapped"`
+	DryRun    bool           `json:"dry_run,omitempty"`
+}
+
+// Publisher publishes a replayed event; EventBus.Publish satisfies it.
+type Publisher func(ctx context.Context, topic string, ev eventbus.Event) error
+
+// archivedEvent is an event read back from the log with the topic it was archived from.
+type archivedEvent struct {
+	topic string
+	event eventbus.Event
+}
+
+// Replay republishes the archived events of the time range in timestamp order, hour by hour,
+// so only one hour of traffic is held in memory. Replayed events keep their IDs:
+// consumers that upsert by ID (SemanticMemory) rebuild their indexes without duplicates.
+func Replay(ctx context.Context, objects storage.ObjectStorage, bucket string, req ReplayRequest, publish Publisher) (*ReplayReport, error) {
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+	if bucket == "" {
+		bucket = DefaultBucket
+	}
+
+	report := &ReplayReport{From: req.From, To: req.To, ByTopic: make(map[string]int), DryRun: req.DryRun}
+	for hour := req.From.UTC().Truncate(time.Hour); hour.Before(req.To); hour = hour.Add(time.Hour) {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		var batch []archivedEvent
+		for _, topic := range req.Topics {
+			events, segments, err := readHour(objects, bucket, topic, hour)
+			if err != nil {
+				return report, fmt.Errorf("read %s: %w", hourPrefix(topic, hour), err)
+			}
+			report.Segments += segments
+			for _, ev := range events {
+				if !ev.Timestamp.Before(req.From) && ev.Timestamp.Before(req.To) {
+					batch = append(batch, archivedEvent{topic: topic, event: ev})
+				}
+			}
+		}
+		sort.SliceStable(batch, func(i, j int) bool {
+			return batch[i].event.Timestamp.Before(batch[j].event.Timestamp)
+		})
+
+		for _, archived := range batch {
+			report.Events++
+			ev := archived.event
+			if remapWorld(&ev, req.WorldMap) {
+				report.Remapped++
+			}
+			topic := archived.topic
+			if req.TargetTopic != "" {
+				topic = req.TargetTopic
+			}
+			report.ByTopic[topic]++
+			if req.DryRun {
+				continue
+			}
+			if err := publish(ctx, topic, ev); err != nil {
+				return report, fmt.Errorf("publish %s to %s: %w", ev.ID, topic, err)
+			}
+			report.Published++
+		}
+	}
+	logging.Infof("Replayed %d/%d events from %d segments (%s — %s)", report.Published, report.Events, report.Segments, req.From, req.To)
+	return report, nil
+}
+
+// readHour reads every part of the hourly segment of a topic.
+func readHour(objects storage.ObjectStorage, bucket, topic string, hour time.Time) ([]eventbus.Event, int, error) {
+	parts, err := objects.ListObjects(bucket, hourPrefix(topic, hour))
+	if storage.IsNotFound(err) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var events []eventbus.Event
+	for _, part := range parts {
+		partEvents, err := readPart(objects, bucket, part.Key)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%s: %w", part.Key, err)
+		}
+		events = append(events, partEvents...)
+	}
+	return events, len(parts), nil
+}
+
+// readPart decodes a JSON Lines part; undecodable lines are skipped.
+func readPart(objects storage.ObjectStorage, bucket, key string) ([]eventbus.Event, error) {
+	obj, err := objects.GetObjectStream(bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Close()
+
+	var events []eventbus.Event
+	scanner := bufio.NewScanner(obj)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var ev eventbus.Event
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+			logging.Warnf("Skipping corrupted line %d of %s: %v", line, key, err)
+			continue
+		}
+		events = append(events, ev)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, storage.ClassifyError(err)
+	}
+	return events, nil
+}
+
+// remapWorld replaces the world ID of the event and of its payload references
+// (world.entity.id, world_id, entity.world.entity.id). Reports whether anything changed.
+func remapWorld(ev *eventbus.Event, worldMap map[string]string) bool {
+	if len(worldMap) == 0 {
+		return false
+	}
+	changed := false
+	if ev.World != nil {
+		if to, ok := worldMap[ev.World.Entity.ID]; ok {
+			ev.World = &eventbus.WorldRef{Entity: eventbus.EntityRef{ID: to, Type: ev.World.Entity.Type}}
+			changed = true
+		}
+	}
+	if ev.Payload == nil {
+		return changed
+	}
+
+	// Payload maps are shared with the decoded event: replace them, do not mutate in place
+	payload := make(map[string]any, len(ev.Payload))
+	for key, value := range ev.Payload {
+		payload[key] = value
+	}
+	if worldID, ok := payload["world_id"].(string); ok {
+		if to, ok := worldMap[worldID]; ok {
+			payload["world_id"] = to
+			changed = true
+		}
+	}
+	if world, ok := remapWorldRef(payload["world"], worldMap); ok {
+		payload["world"] = world
+		changed = true
+	}
+	if entity, ok := payload["entity"].(map[string]any); ok {
+		if world, ok := remapWorldRef(entity["world"], worldMap); ok {
+			remapped := make(map[string]any, len(entity))
+			for key, value := range entity {
+				remapped[key] = value
+			}
+			remapped["world"] = world
+			payload["entity"] = remapped
+			changed = true
+		}
+	}
+	ev.Payload = payload
+	return changed
+}
+
+// remapWorldRef remaps a {"entity": {"id": ...}} world reference.
+func remapWorldRef(raw any, worldMap map[string]string) (map[string]any, bool) {
+	world, ok := raw.(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	entity, ok := world["entity"].(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	id, _ := entity["id"].(string)
+	to, ok := worldMap[id]
+	if !ok {
+		return nil, false
+	}
+	return map[string]any{"entity": map[string]any{"id": to, "type": "world"}}, true
+}
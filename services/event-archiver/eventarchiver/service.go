@@ -0,0 +1,92 @@
+package eventarchiver
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+)
+
+// Service archives every core topic and serves the replay API.
+type Service struct {
+	bus      *eventbus.EventBus
+	storage  storage.ObjectStorage
+	bucket   string
+	archiver *Archiver
+	interval time.Duration
+	// server serves the replay API; nil — disabled
+	server *http.Server
+}
+
+// NewService creates an EventArchiver writing to the bucket (DefaultBucket when empty).
+func NewService(bus *eventbus.EventBus, objects storage.ObjectStorage, bucket string) *Service {
+	if bucket == "" {
+		bucket = DefaultBucket
+	}
+	return &Service{
+		bus:      bus,
+		storage:  objects,
+		bucket:   bucket,
+		archiver: NewArchiver(objects, bucket),
+		interval: DefaultFlushInterval,
+	}
+}
+
+// SetFlushInterval changes how often buffered events are written to MinIO.
+func (s *Service) SetFlushInterval(interval time.Duration) {
+	if interval > 0 {
+		s.interval = interval
+	}
+}
+
+// UseHTTP enables the replay API.
+func (s *Service) UseHTTP(port string) {
+	if port == "" {
+		port = DefaultHTTPPort
+	}
+	s.server = &http.Server{
+		Addr:        ":" + port,
+		Handler:     s.routes(),
+		ReadTimeout: 10 * time.Second,
+		// A replay of a long range republishes many events before responding
+		WriteTimeout: 30 * time.Minute,
+	}
+}
+
+// Run consumes every core topic until ctx is cancelled, then writes the remaining events.
+func (s *Service) Run(ctx context.Context) error {
+	if s.server != nil {
+		go s.serveHTTP(ctx)
+	}
+
+	for _, topic := range eventbus.CoreTopics {
+		topic := topic
+		go s.bus.Subscribe(ctx, topic, "event-archiver-"+topic, func(ev eventbus.Event) {
+			s.archiver.Record(topic, ev)
+		})
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if err := s.archiver.Flush(); err != nil {
+				logging.Errorf("Failed to flush event archive on shutdown, %d events lost: %v", s.archiver.Buffered(), err)
+			}
+			return ctx.Err()
+		case <-ticker.C:
+			if err := s.archiver.Flush(); err != nil {
+				logging.Errorf("Failed to flush event archive, %d events kept for retry: %v", s.archiver.Buffered(), err)
+			}
+		}
+	}
+}
+
+// Replay republishes archived events through the service's event bus.
+func (s *Service) Replay(ctx context.Context, req ReplayRequest) (*ReplayReport, error) {
+	return Replay(ctx, s.storage, s.bucket, req, s.bus.Publish)
+}
@@ -2,72 +2,83 @@ package main
 
 import (
 	"context"
-	"log"
-	"os"
-	"os/signal"
-	"strings"
-	"syscall"
-	"time"
 
 	"multiverse-core.io/services/game-service/gameservice"
+	"multiverse-core.io/shared/config"
+	"multiverse-core.io/shared/logging"
+	"multiverse-core.io/shared/service"
 )
 
 func main() {
-	// Конфигурация из окружения
-	cfg := gameservice.Config{
-		KafkaBrokers: getEnvBrokers("KAFKA_BROKERS", []string{"redpanda:9092"}),
-		HTTPAddr:     getEnv("HTTP_ADDR", ":8080"),
-		CacheTTL:     getEnvDuration("CACHE_TTL", time.Minute*5),
-	}
-
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	app := service.Setup("game-service", []config.Option{
+		{Env: "HTTP_ADDR", Default: ":8080", Required: true},
+		{Env: "GRPC_ADDR", Usage: "адрес gRPC API, например :9090 (пусто — gRPC отключён)"},
+		{Env: "CACHE_TTL", Default: "5m", Type: config.TypeDuration, Positive: true},
+		{Env: "ASSETS_PUBLIC_ENDPOINT", Type: config.TypeURL, Usage: "внешний адрес MinIO для загрузки ассетов"},
+		{Env: "SESSION_SECRET", Secret: true, RequiredIn: []string{config.TierProduction}, Usage: "ключ подписи токенов сессий"},
+		{Env: "SESSION_TTL", Default: "24h", Type: config.TypeDuration, Positive: true, Usage: "время жизни токена сессии"},
+		{Env: "SEMANTIC_MEMORY_URL", Default: "http://semantic-memory:8080", Type: config.TypeURL},
+		{Env: "ARCHIVIST_URL", Type: config.TypeURL, Usage: "адрес OntologicalArchivist для проверки /v1/state-changes по схемам сущностей"},
+		{Env: "BAN_OF_WORLD_URL", Type: config.TypeURL, Usage: "адрес BanOfWorld для проверки действий до публикации (пусто — без проверки)"},
+		{Env: "TRAVEL_SPEED", Default: "5", Type: config.TypeFloat, Positive: true, Usage: "скорость игрока, единиц координат в игровой час"},
+		{Env: "WORLD_TIME_SCALE", Default: "60", Type: config.TypeFloat, Positive: true, Usage: "игровых секунд за реальную секунду"},
+		{Env: "ENGINE_API_TOKENS", Secret: true, Usage: "токены внешних движков для /v1/state-changes через запятую (пусто — эндпоинт отключён)"},
+		{Env: "STATE_CHANGES_RATE", Default: "5", Type: config.TypeFloat, Positive: true, Usage: "запросов /v1/state-changes в секунду на игрока"},
+		{Env: "STATE_CHANGES_BURST", Default: "20", Type: config.TypeInt, Positive: true, Usage: "запросов /v1/state-changes подряд сверх лимита"},
+		{Env: "RATE_LIMIT_AUTH_RATE", Default: "0.2", Type: config.TypeFloat, Positive: true, Usage: "регистраций и входов в секунду на IP"},
+		{Env: "RATE_LIMIT_AUTH_BURST", Default: "5", Type: config.TypeInt, Positive: true, Usage: "регистраций и входов подряд сверх лимита"},
+		{Env: "RATE_LIMIT_ACTIONS_RATE", Default: "5", Type: config.TypeFloat, Positive: true, Usage: "команд, выборов и загрузок ассетов в секунду на игрока"},
+		{Env: "RATE_LIMIT_ACTIONS_BURST", Default: "20", Type: config.TypeInt, Positive: true, Usage: "команд, выборов и загрузок ассетов подряд сверх лимита"},
+		{Env: "RATE_LIMIT_READ_RATE", Default: "20", Type: config.TypeFloat, Positive: true, Usage: "запросов чтения в секунду на клиента"},
+		{Env: "RATE_LIMIT_READ_BURST", Default: "60", Type: config.TypeInt, Positive: true, Usage: "запросов чтения подряд сверх лимита"},
+		{Env: "RATE_LIMIT_WEBSOCKET_RATE", Default: "10", Type: config.TypeFloat, Positive: true, Usage: "подключений и сообщений WebSocket в секунду на клиента"},
+		{Env: "RATE_LIMIT_WEBSOCKET_BURST", Default: "30", Type: config.TypeInt, Positive: true, Usage: "подключений и сообщений WebSocket подряд сверх лимита"},
+		{Env: "RATE_LIMIT_TRUST_PROXY", Default: "false", Type: config.TypeBool, Usage: "брать IP клиента из X-Forwarded-For (GameService за балансировщиком)"},
+		{Env: "NARRATIVE_BUCKET", Default: gameservice.DefaultNarrativeBucket, Usage: "бакет MinIO архива повествования"},
+	})
+	env := app.Env
 
-	// Graceful shutdown
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	go func() {
-		<-sigChan
-		log.Println("Shutting down GameService...")
-		cancel()
-	}()
+	cfg := gameservice.Config{
+		KafkaBrokers: env.List("KAFKA_BROKERS"),
+		HTTPAddr:     env.String("HTTP_ADDR"),
+		GRPCAddr:     env.String("GRPC_ADDR"),
+		CacheTTL:     env.Duration("CACHE_TTL"),
 
-	// Запуск сервиса
-	service := gameservice.NewService(cfg)
-	service.Start(ctx)
-	<-ctx.Done()
-	service.Stop()
-	log.Println("GameService stopped.")
-}
+		AssetsPublicEndpoint: env.String("ASSETS_PUBLIC_ENDPOINT"),
+		SessionSecret:        env.String("SESSION_SECRET"),
+		SessionTTL:           env.Duration("SESSION_TTL"),
+		SemanticMemoryURL:    env.String("SEMANTIC_MEMORY_URL"),
+		ArchivistURL:         env.String("ARCHIVIST_URL"),
+		BanOfWorldURL:        env.String("BAN_OF_WORLD_URL"),
+		TravelSpeed:          env.Float("TRAVEL_SPEED"),
+		WorldTimeScale:       env.Float("WORLD_TIME_SCALE"),
+		EngineTokens:         env.List("ENGINE_API_TOKENS"),
+		NarrativeBucket:      env.String("NARRATIVE_BUCKET"),
 
-func getEnv(key, fallback string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+		RateLimits: map[string]gameservice.RateLimit{
+			gameservice.RateClassAuth:         {Rate: env.Float("RATE_LIMIT_AUTH_RATE"), Burst: env.Int("RATE_LIMIT_AUTH_BURST")},
+			gameservice.RateClassActions:      {Rate: env.Float("RATE_LIMIT_ACTIONS_RATE"), Burst: env.Int("RATE_LIMIT_ACTIONS_BURST")},
+			gameservice.RateClassRead:         {Rate: env.Float("RATE_LIMIT_READ_RATE"), Burst: env.Int("RATE_LIMIT_READ_BURST")},
+			gameservice.RateClassWebSocket:    {Rate: env.Float("RATE_LIMIT_WEBSOCKET_RATE"), Burst: env.Int("RATE_LIMIT_WEBSOCKET_BURST")},
+			gameservice.RateClassStateChanges: {Rate: env.Float("STATE_CHANGES_RATE"), Burst: env.Int("STATE_CHANGES_BURST")},
+		},
+		RateLimitTrustProxy: env.Bool("RATE_LIMIT_TRUST_PROXY"),
 	}
-	return fallback
-}
 
-func getEnvBrokers(key string, fallback []string) []string {
-	if value := os.Getenv(key); value != "" {
-		parts := strings.Split(value, ",")
-		brokers := make([]string, 0, len(parts))
-		for _, part := range parts {
-			if trimmed := strings.TrimSpace(part); trimmed != "" {
-				brokers = append(brokers, trimmed)
-			}
-		}
-		if len(brokers) > 0 {
-			return brokers
-		}
+	// Архив повествования работает через общий клиент MinIO; без него GET /v1/narratives отключён
+	objects, err := app.MinIO()
+	if err != nil {
+		logging.Warnf("Failed to create MinIO client, narrative archive disabled: %v", err)
+	} else {
+		cfg.Objects = objects
 	}
-	return fallback
-}
 
-func getEnvDuration(key string, fallback time.Duration) time.Duration {
-	if value := os.Getenv(key); value != "" {
-		if d, err := time.ParseDuration(value); err == nil {
-			return d
-		}
-	}
-	return fallback
-}
\ No newline at end of file
+	game := gameservice.NewService(cfg)
+	app.Run(service.Background(func(ctx context.Context) error {
+		game.Start(ctx)
+		return nil
+	}, func() error {
+		game.Stop()
+		return nil
+	}))
+}
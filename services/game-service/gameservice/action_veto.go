@@ -0,0 +1,113 @@
+package gameservice
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+)
+
+// Вердикты BanOfWorld на действие игрока
+const (
+	VerdictAllow     = "allow"
+	VerdictReject    = "reject"
+	VerdictTransform = "transform"
+)
+
+// ErrActionVetoed — BanOfWorld запретил действие до публикации (403)
+var ErrActionVetoed = errors.New("action vetoed by BanOfWorld")
+
+// ActionVerdict — ответ BanOfWorld POST /v1/check
+type ActionVerdict struct {
+	Verdict       string `json:"verdict"`
+	ViolationType string `json:"violation_type,omitempty"`
+	RuleID        string `json:"rule_id,omitempty"`
+	Forbiddance   string `json:"forbiddance,omitempty"`
+	Tier          string `json:"tier,omitempty"`
+	// Event — преобразованное действие, публикуемое вместо исходного (VerdictTransform)
+	Event *eventbus.Event `json:"event,omitempty"`
+}
+
+// actionCheck проверяет событие игрока перед публикацией и возвращает событие для публикации
+type actionCheck func(ctx context.Context, event eventbus.Event) (eventbus.Event, *ActionVerdict, error)
+
+// BanOfWorldClient синхронно проверяет действия игроков в BanOfWorld до попадания в поток событий
+type BanOfWorldClient struct {
+	BaseURL    string
+	httpClient *http.Client
+}
+
+// NewBanOfWorldClient создает клиент BanOfWorld; пустой адрес — проверка отключена (nil)
+func NewBanOfWorldClient(baseURL string) *BanOfWorldClient {
+	if baseURL == "" {
+		return nil
+	}
+	return &BanOfWorldClient{
+		BaseURL: baseURL,
+		// Проверка стоит на пути каждого действия, поэтому таймаут короткий
+		httpClient: &http.Client{Timeout: 2 * time.Second},
+	}
+}
+
+// Check отправляет событие на проверку. Сбой соединения или 5xx — storage.ErrUnavailable.
+func (c *BanOfWorldClient) Check(ctx context.Context, event eventbus.Event) (*ActionVerdict, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+"/v1/check", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("ban of world connection failed: %v: %w", err, storage.ErrUnavailable)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("ban of world returned status %d: %s: %w", resp.StatusCode, string(body), storage.ErrUnavailable)
+	}
+	var verdict ActionVerdict
+	if err := json.NewDecoder(resp.Body).Decode(&verdict); err != nil {
+		return nil, fmt.Errorf("decode verdict: %v: %w", err, storage.ErrUnavailable)
+	}
+	return &verdict, nil
+}
+
+// Screen применяет вердикт BanOfWorld к событию: запрет — ErrActionVetoed,
+// преобразование — событие BanOfWorld вместо исходного. Без клиента и при недоступности
+// BanOfWorld действие пропускается: асинхронная проверка по player_events всё равно сработает.
+func (c *BanOfWorldClient) Screen(ctx context.Context, event eventbus.Event) (eventbus.Event, *ActionVerdict, error) {
+	if c == nil {
+		return event, nil, nil
+	}
+	verdict, err := c.Check(ctx, event)
+	if err != nil {
+		logging.Infof("BanOfWorld check of %s skipped: %v", event.ID, err)
+		return event, nil, nil
+	}
+
+	switch verdict.Verdict {
+	case VerdictReject:
+		return event, verdict, fmt.Errorf("%w: %s (%s)", ErrActionVetoed, verdict.ViolationType, verdict.Tier)
+	case VerdictTransform:
+		if verdict.Event == nil || verdict.Event.Type != event.Type {
+			logging.Infof("BanOfWorld returned invalid transformation of %s, publishing original", event.ID)
+			return event, nil, nil
+		}
+		return *verdict.Event, verdict, nil
+	default:
+		return event, verdict, nil
+	}
+}
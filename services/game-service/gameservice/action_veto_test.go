@@ -0,0 +1,97 @@
+package gameservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"multiverse-core.io/shared/eventbus"
+)
+
+// banOfWorldStub отвечает вердиктом по навыку действия
+func banOfWorldStub() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event eventbus.Event
+		json.NewDecoder(r.Body).Decode(&event)
+		switch skill, _ := event.Payload["skill_id"].(string); skill {
+		case "memory_erase":
+			json.NewEncoder(w).Encode(ActionVerdict{Verdict: VerdictReject, ViolationType: "memory_violation", Tier: "warning"})
+		case "fire_breath":
+			event.Payload["skill_id"] = "scream_of_pain"
+			json.NewEncoder(w).Encode(ActionVerdict{Verdict: VerdictTransform, ViolationType: "elemental_conflict", Event: &event})
+		case "broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			json.NewEncoder(w).Encode(ActionVerdict{Verdict: VerdictAllow})
+		}
+	}))
+}
+
+func skillEvent(skill string) eventbus.Event {
+	return eventbus.NewEvent("player.used_skill", "game-service", "world-1", map[string]interface{}{"skill_id": skill})
+}
+
+func TestBanOfWorldScreen(t *testing.T) {
+	server := banOfWorldStub()
+	defer server.Close()
+	client := NewBanOfWorldClient(server.URL)
+	ctx := context.Background()
+
+	if _, verdict, err := client.Screen(ctx, skillEvent("memory_erase")); !errors.Is(err, ErrActionVetoed) || verdict.ViolationType != "memory_violation" {
+		t.Errorf("expected veto, got %+v %v", verdict, err)
+	}
+	event, verdict, err := client.Screen(ctx, skillEvent("fire_breath"))
+	if err != nil || verdict.Verdict != VerdictTransform || event.Payload["skill_id"] != "scream_of_pain" {
+		t.Errorf("expected transformed event, got %+v %+v %v", event.Payload, verdict, err)
+	}
+	if event, _, err := client.Screen(ctx, skillEvent("ice_shard")); err != nil || event.Payload["skill_id"] != "ice_shard" {
+		t.Errorf("expected allowed event, got %+v %v", event.Payload, err)
+	}
+
+	// Недоступный BanOfWorld не блокирует действия
+	if event, _, err := client.Screen(ctx, skillEvent("broken")); err != nil || event.Payload["skill_id"] != "broken" {
+		t.Errorf("expected fail-open on server error, got %v", err)
+	}
+	var disabled *BanOfWorldClient
+	if NewBanOfWorldClient("") != nil {
+		t.Errorf("empty address must disable the check")
+	}
+	if _, _, err := disabled.Screen(ctx, skillEvent("memory_erase")); err != nil {
+		t.Errorf("disabled check must allow everything, got %v", err)
+	}
+}
+
+func TestActionBatchProcessorVeto(t *testing.T) {
+	server := banOfWorldStub()
+	defer server.Close()
+	var published []eventbus.Event
+	processor := NewActionBatchProcessor(func(ctx context.Context, event eventbus.Event) error {
+		published = append(published, event)
+		return nil
+	})
+	processor.UseCheck(NewBanOfWorldClient(server.URL).Screen)
+
+	actions := []BatchAction{
+		{ClientSeq: 1, Type: "player.used_skill", Payload: map[string]interface{}{"skill_id": "memory_erase"}},
+		{ClientSeq: 2, Type: "player.used_skill", Payload: map[string]interface{}{"skill_id": "fire_breath"}},
+	}
+	resp, err := processor.Process(context.Background(), BatchActionsRequest{PlayerID: "player-1", WorldID: "world-1", Actions: actions})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Results[0].Status != ActionStatusRejected || resp.Results[1].Status != ActionStatusAccepted || resp.LastSeq != 2 {
+		t.Errorf("unexpected results: %+v", resp)
+	}
+	if len(published) != 1 || published[0].Payload["skill_id"] != "scream_of_pain" {
+		t.Errorf("expected only the transformed action published, got %v", published)
+	}
+
+	// Запрещённое действие не проверяется и не наказывается повторно
+	resp, _ = processor.Process(context.Background(), BatchActionsRequest{PlayerID: "player-1", WorldID: "world-1", Actions: actions[:1]})
+	if resp.Results[0].Status != ActionStatusDuplicate {
+		t.Errorf("expected duplicate on resend, got %+v", resp.Results[0])
+	}
+}
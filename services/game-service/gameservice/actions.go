@@ -0,0 +1,328 @@
+package gameservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"multiverse-core.io/shared/entity"
+	"multiverse-core.io/shared/eventbus"
+	storage "multiverse-core.io/shared/minio"
+)
+
+// Команды игрока POST /v1/actions
+const (
+	CommandMove        = "move"
+	CommandUseSkill    = "use_skill"
+	CommandUseItem     = "use_item"
+	CommandTalkToNPC   = "talk_to_npc"
+	CommandAcceptQuest = "accept_quest"
+)
+
+// commandEventTypes — тип события player.* для каждой команды
+var commandEventTypes = map[string]string{
+	CommandMove:        "player.moved",
+	CommandUseSkill:    "player.used_skill",
+	CommandUseItem:     "player.used_item",
+	CommandTalkToNPC:   "player.talked_to_npc",
+	CommandAcceptQuest: "player.accepted_quest",
+}
+
+// Ошибки проверки команды
+var (
+	// ErrInvalidCommand — команда неизвестна или заполнена неверно (400)
+	ErrInvalidCommand = errors.New("invalid command")
+	// ErrCommandNotAllowed — команда противоречит состоянию игрока (409)
+	ErrCommandNotAllowed = errors.New("command not allowed")
+)
+
+// ActionPoint — координаты перемещения
+type ActionPoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// ActionCommand — тело POST /v1/actions. Набор параметров зависит от команды:
+//
+//	move:         location и/или location_id (по координатам — путешествие по маршруту, см. TravelPlanner)
+//	use_skill:    skill_id, необязательно target_id
+//	use_item:     item_id, необязательно target_id
+//	talk_to_npc:  npc_id, необязательно message
+//	accept_quest: quest_id, необязательно npc_id (кто выдал)
+type ActionCommand struct {
+	PlayerID   string       `json:"player_id"`
+	WorldID    string       `json:"world_id"`
+	Command    string       `json:"command"`
+	Location   *ActionPoint `json:"location,omitempty"`
+	LocationID string       `json:"location_id,omitempty"`
+	SkillID    string       `json:"skill_id,omitempty"`
+	ItemID     string       `json:"item_id,omitempty"`
+	TargetID   string       `json:"target_id,omitempty"`
+	NPCID      string       `json:"npc_id,omitempty"`
+	QuestID    string       `json:"quest_id,omitempty"`
+	Message    string       `json:"message,omitempty"`
+}
+
+// buildCommandEvent проверяет команду по состоянию сущности игрока и создает событие player.*.
+// scope события — сам игрок, чтобы событие попало его GM.
+func buildCommandEvent(player *entity.Entity, cmd ActionCommand, now time.Time) (eventbus.Event, error) {
+	eventType, ok := commandEventTypes[cmd.Command]
+	if !ok {
+		return eventbus.Event{}, fmt.Errorf("%w: unknown command %q", ErrInvalidCommand, cmd.Command)
+	}
+	if player.Type != "player" {
+		return eventbus.Event{}, fmt.Errorf("%w: entity %s is not a player", ErrCommandNotAllowed, player.ID)
+	}
+	if isDead(player) {
+		return eventbus.Event{}, fmt.Errorf("%w: player %s is dead", ErrCommandNotAllowed, player.ID)
+	}
+
+	name, _ := player.Payload["name"].(string)
+	payload := eventbus.NewEventPayload().
+		WithEntity(player.ID, "player", name).
+		WithWorld(cmd.WorldID).
+		WithScope(player.ID, "player")
+	custom := payload.GetCustom()
+	custom["command"] = cmd.Command
+
+	switch cmd.Command {
+	case CommandMove:
+		if cmd.Location == nil && cmd.LocationID == "" {
+			return eventbus.Event{}, fmt.Errorf("%w: move requires location or location_id", ErrInvalidCommand)
+		}
+		location := map[string]interface{}{}
+		if cmd.Location != nil {
+			if !isFinite(cmd.Location.X) || !isFinite(cmd.Location.Y) {
+				return eventbus.Event{}, fmt.Errorf("%w: location must be finite", ErrInvalidCommand)
+			}
+			location["x"], location["y"] = cmd.Location.X, cmd.Location.Y
+		}
+		if cmd.LocationID != "" {
+			location["location"] = cmd.LocationID
+		}
+		custom["location"] = location
+
+	case CommandUseSkill:
+		if cmd.SkillID == "" {
+			return eventbus.Event{}, fmt.Errorf("%w: use_skill requires skill_id", ErrInvalidCommand)
+		}
+		if !listHas(player.Payload["skills"], cmd.SkillID) {
+			return eventbus.Event{}, fmt.Errorf("%w: player does not know skill %s", ErrCommandNotAllowed, cmd.SkillID)
+		}
+		custom["skill_id"] = cmd.SkillID
+		if cmd.TargetID != "" {
+			payload.WithTarget(cmd.TargetID, "", "")
+		}
+
+	case CommandUseItem:
+		if cmd.ItemID == "" {
+			return eventbus.Event{}, fmt.Errorf("%w: use_item requires item_id", ErrInvalidCommand)
+		}
+		if !listHas(player.Payload["inventory"], cmd.ItemID) {
+			return eventbus.Event{}, fmt.Errorf("%w: item %s is not in inventory", ErrCommandNotAllowed, cmd.ItemID)
+		}
+		custom["item_id"] = cmd.ItemID
+		if cmd.TargetID != "" {
+			payload.WithTarget(cmd.TargetID, "", "")
+		}
+
+	case CommandTalkToNPC:
+		if cmd.NPCID == "" {
+			return eventbus.Event{}, fmt.Errorf("%w: talk_to_npc requires npc_id", ErrInvalidCommand)
+		}
+		payload.WithTarget(cmd.NPCID, "npc", "")
+		if cmd.Message != "" {
+			custom["message"] = cmd.Message
+		}
+
+	case CommandAcceptQuest:
+		if cmd.QuestID == "" {
+			return eventbus.Event{}, fmt.Errorf("%w: accept_quest requires quest_id", ErrInvalidCommand)
+		}
+		active, _ := player.GetPath("quests.active")
+		completed, _ := player.GetPath("quests.completed")
+		if listHas(active, cmd.QuestID) || listHas(completed, cmd.QuestID) {
+			return eventbus.Event{}, fmt.Errorf("%w: quest %s already accepted", ErrCommandNotAllowed, cmd.QuestID)
+		}
+		custom["quest_id"] = cmd.QuestID
+		if cmd.NPCID != "" {
+			payload.WithSource(cmd.NPCID, "npc", "")
+		}
+	}
+
+	event := eventbus.NewStructuredEvent(eventType, "game-service", cmd.WorldID, payload)
+	event.Timestamp = now.UTC()
+	event.Scope = &eventbus.ScopeRef{ID: player.ID, Type: "player"}
+	return event, nil
+}
+
+// isDead проверяет, что игрок мёртв (status = dead или health.current <= 0)
+func isDead(player *entity.Entity) bool {
+	if status, _ := player.Payload["status"].(string); status == "dead" {
+		return true
+	}
+	if hp, ok := player.GetPath("health.current"); ok {
+		if value, ok := hp.(float64); ok && value <= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// listHas проверяет, что список payload содержит id: строкой или объектом с полем id
+func listHas(list interface{}, id string) bool {
+	var items []interface{}
+	switch v := list.(type) {
+	case []interface{}:
+		items = v
+	case []string:
+		for _, item := range v {
+			if item == id {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+	for _, item := range items {
+		switch v := item.(type) {
+		case string:
+			if v == id {
+				return true
+			}
+		case map[string]interface{}:
+			if v["id"] == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isFinite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+// ErrPublishFailed — событие не удалось опубликовать в Kafka (503)
+var ErrPublishFailed = errors.New("failed to publish action")
+
+// ActionResult — результат команды игрока
+type ActionResult struct {
+	EventID   string `json:"event_id"`
+	EventType string `json:"event_type"`
+	// Verdict и ViolationType заполнены, если BanOfWorld заменил действие
+	Verdict       string `json:"verdict,omitempty"`
+	ViolationType string `json:"violation_type,omitempty"`
+	// Travel — начатое путешествие: player.moved будет опубликовано по прибытии
+	Travel *Journey `json:"travel,omitempty"`
+}
+
+// PerformAction проверяет команду по сущности игрока и публикует событие player.*.
+// Общая логика REST POST /v1/actions и gRPC PerformAction.
+func (s *Service) PerformAction(ctx context.Context, cmd ActionCommand) (*ActionResult, error) {
+	if err := bindSessionPlayer(ctx, &cmd.PlayerID, &cmd.WorldID); err != nil {
+		return nil, err
+	}
+	if cmd.PlayerID == "" || cmd.WorldID == "" {
+		return nil, fmt.Errorf("%w: player_id and world_id are required", ErrInvalidCommand)
+	}
+
+	player, err := s.GetEntity(ctx, cmd.PlayerID, cmd.WorldID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load player: %w", err)
+	}
+
+	event, err := buildCommandEvent(player, cmd, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	// BanOfWorld может запретить действие или заменить его до попадания в поток событий
+	event, verdict, err := s.banOfWorld.Screen(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+	result := &ActionResult{EventID: event.ID, EventType: event.Type}
+	if verdict != nil && verdict.Verdict == VerdictTransform {
+		result.Verdict = verdict.Verdict
+		result.ViolationType = verdict.ViolationType
+	}
+
+	// Перемещение по координатам идёт по маршруту: player.moved публикуется по прибытии
+	if cmd.Command == CommandMove && cmd.Location != nil {
+		journey, err := s.travel.Plan(ctx, player, cmd.WorldID, *cmd.Location)
+		if err != nil {
+			return nil, err
+		}
+		if journey != nil {
+			// Путешествие переживает запрос, но сохраняет identity сессии
+			if err := s.travel.Start(context.WithoutCancel(ctx), journey, event); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrPublishFailed, err)
+			}
+			result.Travel = journey
+			return result, nil
+		}
+	}
+
+	if err := s.publishPlayer(ctx, event); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPublishFailed, err)
+	}
+	return result, nil
+}
+
+// PerformActionHandler обрабатывает POST /v1/actions — команда игрока проверяется по его сущности
+// и публикуется как событие player.* в player_events
+func (s *Service) PerformActionHandler(w http.ResponseWriter, r *http.Request) {
+	var cmd ActionCommand
+	if err := json.NewDecoder(r.Body).Decode(&cmd); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("Invalid request body"))
+		return
+	}
+
+	result, err := s.PerformAction(r.Context(), cmd)
+	if err != nil {
+		writeCommandError(w, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"event_id":   result.EventID,
+		"event_type": result.EventType,
+	}
+	if result.Verdict != "" {
+		response["verdict"] = result.Verdict
+		response["violation_type"] = result.ViolationType
+	}
+	if result.Travel != nil {
+		response["travel"] = result.Travel
+		response["world_duration_s"] = result.Travel.WorldDuration.Seconds()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(response)
+}
+
+// writeCommandError отвечает на ошибку команды: 400, 403, 404, 409, 503 или 500
+func writeCommandError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidCommand):
+		w.WriteHeader(http.StatusBadRequest)
+	case errors.Is(err, ErrCommandNotAllowed):
+		w.WriteHeader(http.StatusConflict)
+	case errors.Is(err, ErrActionVetoed), errors.Is(err, ErrForeignPlayer):
+		w.WriteHeader(http.StatusForbidden)
+	case storage.IsNotFound(err):
+		w.WriteHeader(http.StatusNotFound)
+	case errors.Is(err, ErrPublishFailed), storage.IsUnavailable(err):
+		w.WriteHeader(http.StatusServiceUnavailable)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	w.Write([]byte(err.Error()))
+}
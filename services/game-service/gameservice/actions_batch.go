@@ -0,0 +1,289 @@
+package gameservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+)
+
+// Ограничения пакетной отправки действий
+const (
+	maxBatchActions = 200
+	// actionDedupTTL — сколько помнить обработанные действия игрока для дедупликации
+	actionDedupTTL = 24 * time.Hour
+)
+
+// Статусы обработки действия из пакета
+const (
+	ActionStatusAccepted  = "accepted"
+	ActionStatusDuplicate = "duplicate"
+	ActionStatusRejected  = "rejected"
+	ActionStatusFailed    = "failed"
+	// ActionStatusSkipped — действие не отправлено, потому что предыдущее не удалось опубликовать
+	// (порядок действий важнее частичной доставки; клиент повторит их следующим пакетом)
+	ActionStatusSkipped = "skipped"
+)
+
+// BatchAction — действие, накопленное клиентом офлайн
+type BatchAction struct {
+	ClientSeq       int64                  `json:"client_seq"`
+	ClientActionID  string                 `json:"client_action_id,omitempty"`
+	Type            string                 `json:"type"`
+	Payload         map[string]interface{} `json:"payload,omitempty"`
+	ClientTimestamp string                 `json:"client_timestamp,omitempty"`
+}
+
+// BatchActionsRequest — тело POST /v1/actions/batch
+type BatchActionsRequest struct {
+	PlayerID string        `json:"player_id"`
+	WorldID  string        `json:"world_id"`
+	Actions  []BatchAction `json:"actions"`
+}
+
+// BatchActionResult — результат обработки одного действия
+type BatchActionResult struct {
+	ClientSeq       int64     `json:"client_seq"`
+	ClientActionID  string    `json:"client_action_id,omitempty"`
+	Status          string    `json:"status"`
+	EventID         string    `json:"event_id,omitempty"`
+	ServerTimestamp time.Time `json:"server_timestamp,omitempty"`
+	Error           string    `json:"error,omitempty"`
+}
+
+// BatchActionsResponse — ответ POST /v1/actions/batch
+type BatchActionsResponse struct {
+	Results    []BatchActionResult `json:"results"`
+	Accepted   int                 `json:"accepted"`
+	Duplicates int                 `json:"duplicates"`
+	Rejected   int                 `json:"rejected"`
+	Failed     int                 `json:"failed"`
+	// LastSeq — последний принятый сервером client_seq игрока; клиент может удалить из очереди всё до него
+	LastSeq int64 `json:"last_seq"`
+}
+
+// playerActionLog — обработанные действия одного игрока в мире
+type playerActionLog struct {
+	// mutex упорядочивает пакеты одного игрока, чтобы порядок публикации совпадал с client_seq
+	mutex     sync.Mutex
+	lastSeq   int64
+	seenIDs   map[string]time.Time
+	updatedAt time.Time
+}
+
+// ActionBatchProcessor проверяет, упорядочивает, дедуплицирует и публикует пакеты действий
+type ActionBatchProcessor struct {
+	publish func(ctx context.Context, event eventbus.Event) error
+	// check проверяет действие в BanOfWorld перед публикацией (nil — без проверки)
+	check actionCheck
+	now   func() time.Time
+
+	logs  map[string]*playerActionLog
+	mutex sync.Mutex
+}
+
+// NewActionBatchProcessor создает обработчик пакетов, публикующий события через publish
+func NewActionBatchProcessor(publish func(ctx context.Context, event eventbus.Event) error) *ActionBatchProcessor {
+	return &ActionBatchProcessor{
+		publish: publish,
+		now:     time.Now,
+		logs:    make(map[string]*playerActionLog),
+	}
+}
+
+// UseCheck включает проверку каждого действия перед публикацией
+func (p *ActionBatchProcessor) UseCheck(check actionCheck) {
+	p.check = check
+}
+
+// Process обрабатывает пакет: действия сортируются по client_seq и публикуются по порядку.
+// Время события всегда назначает сервер; время клиента сохраняется в payload только для справки.
+func (p *ActionBatchProcessor) Process(ctx context.Context, req BatchActionsRequest) (*BatchActionsResponse, error) {
+	if req.PlayerID == "" || req.WorldID == "" {
+		return nil, fmt.Errorf("player_id and world_id are required")
+	}
+	if len(req.Actions) == 0 {
+		return nil, fmt.Errorf("actions must not be empty")
+	}
+	if len(req.Actions) > maxBatchActions {
+		return nil, fmt.Errorf("too many actions: %d (max %d)", len(req.Actions), maxBatchActions)
+	}
+
+	actions := make([]BatchAction, len(req.Actions))
+	copy(actions, req.Actions)
+	sort.SliceStable(actions, func(i, j int) bool {
+		return actions[i].ClientSeq < actions[j].ClientSeq
+	})
+
+	actionLog := p.playerLog(req.WorldID, req.PlayerID)
+	actionLog.mutex.Lock()
+	defer actionLog.mutex.Unlock()
+
+	response := &BatchActionsResponse{Results: make([]BatchActionResult, 0, len(actions))}
+	publishFailed := false
+	var lastServerTime time.Time
+
+	for _, action := range actions {
+		result := BatchActionResult{ClientSeq: action.ClientSeq, ClientActionID: action.ClientActionID}
+		validationErr := validateBatchAction(action)
+
+		switch {
+		case publishFailed:
+			result.Status = ActionStatusSkipped
+		case validationErr != nil:
+			result.Status = ActionStatusRejected
+			result.Error = validationErr.Error()
+			response.Rejected++
+		case action.ClientSeq <= actionLog.lastSeq || (action.ClientActionID != "" && !actionLog.seenIDs[action.ClientActionID].IsZero()):
+			result.Status = ActionStatusDuplicate
+			response.Duplicates++
+		default:
+			// Серверное время строго возрастает внутри пакета, чтобы сохранить порядок действий
+			serverTime := p.now().UTC()
+			if !serverTime.After(lastServerTime) {
+				serverTime = lastServerTime.Add(time.Microsecond)
+			}
+			lastServerTime = serverTime
+
+			event := buildBatchActionEvent(req.PlayerID, req.WorldID, action, serverTime)
+			var vetoErr error
+			if p.check != nil {
+				event, _, vetoErr = p.check(ctx, event)
+			}
+			if vetoErr != nil {
+				// Запрет окончателен (нарушение уже наказано), поэтому действие считается обработанным
+				// и при повторной отправке станет дубликатом
+				actionLog.lastSeq = action.ClientSeq
+				if action.ClientActionID != "" {
+					actionLog.seenIDs[action.ClientActionID] = serverTime
+				}
+				result.Status = ActionStatusRejected
+				result.Error = vetoErr.Error()
+				response.Rejected++
+			} else if err := p.publish(ctx, event); err != nil {
+				logging.Errorf("Failed to publish batched action %d of player %s: %v", action.ClientSeq, req.PlayerID, err)
+				result.Status = ActionStatusFailed
+				result.Error = "failed to publish action"
+				response.Failed++
+				publishFailed = true
+			} else {
+				actionLog.lastSeq = action.ClientSeq
+				if action.ClientActionID != "" {
+					actionLog.seenIDs[action.ClientActionID] = serverTime
+				}
+				result.Status = ActionStatusAccepted
+				result.EventID = event.ID
+				result.ServerTimestamp = serverTime
+				response.Accepted++
+			}
+		}
+
+		response.Results = append(response.Results, result)
+	}
+
+	cutoff := p.now().Add(-actionDedupTTL)
+	for id, seenAt := range actionLog.seenIDs {
+		if seenAt.Before(cutoff) {
+			delete(actionLog.seenIDs, id)
+		}
+	}
+	response.LastSeq = actionLog.lastSeq
+	return response, nil
+}
+
+// playerLog возвращает журнал действий игрока, попутно удаляя журналы,
+// неактивные дольше actionDedupTTL
+func (p *ActionBatchProcessor) playerLog(worldID, playerID string) *playerActionLog {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := p.now()
+	cutoff := now.Add(-actionDedupTTL)
+	for key, actionLog := range p.logs {
+		if actionLog.updatedAt.Before(cutoff) {
+			delete(p.logs, key)
+		}
+	}
+
+	key := worldID + "|" + playerID
+	actionLog, exists := p.logs[key]
+	if !exists {
+		actionLog = &playerActionLog{seenIDs: make(map[string]time.Time)}
+		p.logs[key] = actionLog
+	}
+	actionLog.updatedAt = now
+	return actionLog
+}
+
+// validateBatchAction проверяет отдельное действие пакета
+func validateBatchAction(action BatchAction) error {
+	if action.ClientSeq <= 0 {
+		return fmt.Errorf("client_seq must be positive")
+	}
+	if !strings.HasPrefix(action.Type, eventbus.TypePlayerAction) || len(action.Type) == len(eventbus.TypePlayerAction) {
+		return fmt.Errorf("type must be a player action (%s*)", eventbus.TypePlayerAction)
+	}
+	if action.ClientTimestamp != "" {
+		if _, err := time.Parse(time.RFC3339, action.ClientTimestamp); err != nil {
+			return fmt.Errorf("client_timestamp must be RFC3339")
+		}
+	}
+	return nil
+}
+
+// buildBatchActionEvent создает событие игрока с серверным временем.
+// Сущность, мир и identity берутся из запроса и сессии, а не из payload клиента.
+func buildBatchActionEvent(playerID, worldID string, action BatchAction, serverTime time.Time) eventbus.Event {
+	payload := make(map[string]interface{}, len(action.Payload)+3)
+	for k, v := range action.Payload {
+		payload[k] = v
+	}
+	delete(payload, "entity")
+	delete(payload, "world")
+	delete(payload, "identity")
+	payload["client_seq"] = action.ClientSeq
+	if action.ClientActionID != "" {
+		payload["client_action_id"] = action.ClientActionID
+	}
+	if action.ClientTimestamp != "" {
+		payload["client_timestamp"] = action.ClientTimestamp
+	}
+
+	event := eventbus.NewEvent(action.Type, "game-service", worldID, payload)
+	event.Timestamp = serverTime
+	eventbus.SetNested(event.Payload, "entity.id", playerID)
+	eventbus.SetNested(event.Payload, "entity.type", "player")
+	return event
+}
+
+// BatchActionsHandler обрабатывает POST /v1/actions/batch — очередь действий, накопленных клиентом офлайн
+func (s *Service) BatchActionsHandler(w http.ResponseWriter, r *http.Request) {
+	var req BatchActionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("Invalid request body"))
+		return
+	}
+	if err := bindSessionPlayer(r.Context(), &req.PlayerID, &req.WorldID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	response, err := s.actionBatches.Process(r.Context(), req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,107 @@
+package gameservice
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+)
+
+func TestActionBatchProcessor(t *testing.T) {
+	var published []eventbus.Event
+	processor := NewActionBatchProcessor(func(ctx context.Context, event eventbus.Event) error {
+		published = append(published, event)
+		return nil
+	})
+	fixed := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	processor.now = func() time.Time { return fixed }
+
+	resp, err := processor.Process(context.Background(), BatchActionsRequest{
+		PlayerID: "player-1",
+		WorldID:  "world-1",
+		Actions: []BatchAction{
+			{ClientSeq: 2, ClientActionID: "b", Type: "player.moved"},
+			{ClientSeq: 1, ClientActionID: "a", Type: "player.moved", ClientTimestamp: "2020-01-01T00:00:00Z"},
+			{ClientSeq: 3, Type: "system.shutdown"},
+			{ClientSeq: 2, ClientActionID: "b", Type: "player.moved"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantStatuses := []string{ActionStatusAccepted, ActionStatusAccepted, ActionStatusDuplicate, ActionStatusRejected}
+	for i, want := range wantStatuses {
+		if resp.Results[i].Status != want {
+			t.Errorf("result %d: expected %s, got %s", i, want, resp.Results[i].Status)
+		}
+	}
+	if resp.LastSeq != 2 || resp.Accepted != 2 || resp.Duplicates != 1 || resp.Rejected != 1 {
+		t.Errorf("unexpected counters: %+v", resp)
+	}
+
+	if len(published) != 2 || published[0].Payload["client_seq"] != int64(1) {
+		t.Fatalf("expected actions published in client_seq order, got %v", published)
+	}
+	if !published[0].Timestamp.Equal(fixed) || !published[1].Timestamp.After(published[0].Timestamp) {
+		t.Errorf("expected server timestamps increasing from %s, got %s and %s", fixed, published[0].Timestamp, published[1].Timestamp)
+	}
+	if ref := eventbus.ExtractEntityID(published[0].Payload); ref == nil || ref.ID != "player-1" {
+		t.Errorf("expected event entity player-1, got %v", ref)
+	}
+
+	// Повторная отправка той же очереди после переподключения не публикует действия повторно
+	resp, err = processor.Process(context.Background(), BatchActionsRequest{
+		PlayerID: "player-1",
+		WorldID:  "world-1",
+		Actions:  []BatchAction{{ClientSeq: 1, ClientActionID: "a", Type: "player.moved"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Results[0].Status != ActionStatusDuplicate || len(published) != 2 {
+		t.Errorf("expected duplicate on resend, got %s", resp.Results[0].Status)
+	}
+}
+
+func TestActionBatchProcessorPublishFailure(t *testing.T) {
+	calls := 0
+	processor := NewActionBatchProcessor(func(ctx context.Context, event eventbus.Event) error {
+		calls++
+		if calls == 1 {
+			return errors.New("kafka unavailable")
+		}
+		return nil
+	})
+
+	resp, err := processor.Process(context.Background(), BatchActionsRequest{
+		PlayerID: "player-1",
+		WorldID:  "world-1",
+		Actions: []BatchAction{
+			{ClientSeq: 1, Type: "player.moved"},
+			{ClientSeq: 2, Type: "player.moved"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Results[0].Status != ActionStatusFailed || resp.Results[1].Status != ActionStatusSkipped {
+		t.Errorf("expected failed then skipped, got %s and %s", resp.Results[0].Status, resp.Results[1].Status)
+	}
+	if resp.LastSeq != 0 {
+		t.Errorf("failed action must not advance last_seq, got %d", resp.LastSeq)
+	}
+}
+
+func TestActionBatchProcessorValidation(t *testing.T) {
+	processor := NewActionBatchProcessor(func(ctx context.Context, event eventbus.Event) error { return nil })
+
+	if _, err := processor.Process(context.Background(), BatchActionsRequest{WorldID: "world-1", Actions: []BatchAction{{ClientSeq: 1, Type: "player.moved"}}}); err == nil {
+		t.Error("expected error without player_id")
+	}
+	if _, err := processor.Process(context.Background(), BatchActionsRequest{PlayerID: "p", WorldID: "w"}); err == nil {
+		t.Error("expected error for empty batch")
+	}
+}
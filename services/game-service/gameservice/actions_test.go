@@ -0,0 +1,89 @@
+package gameservice
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"multiverse-core.io/shared/entity"
+	"multiverse-core.io/shared/eventbus"
+)
+
+func testPlayer() *entity.Entity {
+	return entity.NewEntity("player:kain", "player", map[string]interface{}{
+		"name":      "Каин",
+		"skills":    []interface{}{"sky_rend", map[string]interface{}{"id": "void_call"}},
+		"inventory": []interface{}{"item:wolf-fang"},
+		"health":    map[string]interface{}{"current": 42.0},
+		"quests":    map[string]interface{}{"active": []interface{}{"quest:wolves"}},
+	})
+}
+
+func TestBuildCommandEvent(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		cmd       ActionCommand
+		eventType string
+		field     string
+		value     interface{}
+	}{
+		{ActionCommand{Command: CommandMove, Location: &ActionPoint{X: 1, Y: 2}}, "player.moved", "location.x", 1.0},
+		{ActionCommand{Command: CommandUseSkill, SkillID: "void_call", TargetID: "npc:wolf-5"}, "player.used_skill", "skill_id", "void_call"},
+		{ActionCommand{Command: CommandUseItem, ItemID: "item:wolf-fang"}, "player.used_item", "item_id", "item:wolf-fang"},
+		{ActionCommand{Command: CommandTalkToNPC, NPCID: "npc:merchant", Message: "Привет"}, "player.talked_to_npc", "target.entity.id", "npc:merchant"},
+		{ActionCommand{Command: CommandAcceptQuest, QuestID: "quest:bandits"}, "player.accepted_quest", "quest_id", "quest:bandits"},
+	}
+
+	for _, tc := range cases {
+		tc.cmd.PlayerID, tc.cmd.WorldID = "player:kain", "world-1"
+		event, err := buildCommandEvent(testPlayer(), tc.cmd, now)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.cmd.Command, err)
+		}
+		if event.Type != tc.eventType || !event.Timestamp.Equal(now) {
+			t.Errorf("%s: unexpected event %s at %s", tc.cmd.Command, event.Type, event.Timestamp)
+		}
+		if value, _ := event.Path().GetAny(tc.field); value != tc.value {
+			t.Errorf("%s: expected %s=%v, got %v", tc.cmd.Command, tc.field, tc.value, value)
+		}
+		if scope := eventbus.GetScopeFromEvent(event); scope == nil || scope.ID != "player:kain" {
+			t.Errorf("%s: expected player scope, got %+v", tc.cmd.Command, scope)
+		}
+		if info := eventbus.ExtractEntityID(event.Payload); info == nil || info.ID != "player:kain" {
+			t.Errorf("%s: expected player entity, got %+v", tc.cmd.Command, info)
+		}
+		if eventbus.GetWorldIDFromEvent(event) != "world-1" {
+			t.Errorf("%s: expected world-1", tc.cmd.Command)
+		}
+	}
+}
+
+func TestBuildCommandEventRejects(t *testing.T) {
+	dead := testPlayer()
+	dead.SetPath("health.current", 0.0)
+	npc := entity.NewEntity("npc:wolf-5", "npc", nil)
+
+	cases := []struct {
+		name   string
+		player *entity.Entity
+		cmd    ActionCommand
+		want   error
+	}{
+		{"unknown command", testPlayer(), ActionCommand{Command: "fly"}, ErrInvalidCommand},
+		{"move without destination", testPlayer(), ActionCommand{Command: CommandMove}, ErrInvalidCommand},
+		{"missing skill id", testPlayer(), ActionCommand{Command: CommandUseSkill}, ErrInvalidCommand},
+		{"unknown skill", testPlayer(), ActionCommand{Command: CommandUseSkill, SkillID: "fireball"}, ErrCommandNotAllowed},
+		{"item not in inventory", testPlayer(), ActionCommand{Command: CommandUseItem, ItemID: "item:sword"}, ErrCommandNotAllowed},
+		{"quest already active", testPlayer(), ActionCommand{Command: CommandAcceptQuest, QuestID: "quest:wolves"}, ErrCommandNotAllowed},
+		{"dead player", dead, ActionCommand{Command: CommandTalkToNPC, NPCID: "npc:merchant"}, ErrCommandNotAllowed},
+		{"not a player", npc, ActionCommand{Command: CommandTalkToNPC, NPCID: "npc:merchant"}, ErrCommandNotAllowed},
+	}
+
+	for _, tc := range cases {
+		tc.cmd.WorldID = "world-1"
+		if _, err := buildCommandEvent(tc.player, tc.cmd, time.Now()); !errors.Is(err, tc.want) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+	}
+}
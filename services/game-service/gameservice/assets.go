@@ -0,0 +1,317 @@
+package gameservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// AssetsBucket — отдельный бакет для медиа-ассетов (портреты, гербы, звуки)
+const AssetsBucket = "assets"
+
+const (
+	// assetUploadTTL — время жизни presigned-политики загрузки
+	assetUploadTTL = 15 * time.Minute
+	// assetCacheControl — ключи ассетов уникальны для каждой загрузки, поэтому содержимое не меняется
+	assetCacheControl = "public, max-age=31536000, immutable"
+)
+
+// Ошибки загрузки ассетов
+var (
+	ErrInvalidAssetRequest = errors.New("invalid asset upload request")
+	ErrUnknownAssetKind    = errors.New("unknown asset kind")
+	ErrAssetContentType    = errors.New("content type is not allowed for asset kind")
+	ErrAssetTooLarge       = errors.New("asset is too large")
+	ErrInvalidAssetKey     = errors.New("invalid asset key")
+	ErrStorageNotAvailable = errors.New("storage is not available")
+)
+
+// AssetKind описывает допустимые типы содержимого и размер ассетов одного вида
+type AssetKind struct {
+	ContentTypes map[string]string // content type → расширение файла
+	MaxSize      int64
+}
+
+// AssetKinds — виды ассетов, которые можно загрузить
+var AssetKinds = map[string]AssetKind{
+	"portrait": {
+		ContentTypes: map[string]string{"image/png": ".png", "image/jpeg": ".jpg", "image/webp": ".webp"},
+		MaxSize:      5 << 20,
+	},
+	"emblem": {
+		ContentTypes: map[string]string{"image/png": ".png", "image/webp": ".webp"},
+		MaxSize:      1 << 20,
+	},
+	"audio": {
+		ContentTypes: map[string]string{"audio/mpeg": ".mp3", "audio/ogg": ".ogg", "audio/wav": ".wav"},
+		MaxSize:      20 << 20,
+	},
+}
+
+// assetKeyPattern — ключ ассета: {world_id}/{kind}/{uuid}{ext}
+var assetKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+/([a-z]+)/[0-9a-f-]{36}\.[a-z0-9]+$`)
+
+// AssetUploadRequest — тело POST /v1/assets/uploads
+type AssetUploadRequest struct {
+	WorldID     string `json:"world_id"`
+	Kind        string `json:"kind"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+}
+
+// AssetUploadTicket — presigned-форма для загрузки ассета напрямую в MinIO
+type AssetUploadTicket struct {
+	AssetKey  string            `json:"asset_key"`
+	UploadURL string            `json:"upload_url"`
+	Method    string            `json:"method"`
+	FormData  map[string]string `json:"form_data"`
+	MaxSize   int64             `json:"max_size"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+// AssetRef — ссылка на загруженный ассет; в payload сущности хранится только Key
+type AssetRef struct {
+	Key         string `json:"asset_key"`
+	Kind        string `json:"kind"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	URL         string `json:"url"`
+}
+
+// newAssetKey проверяет запрос на загрузку и возвращает ключ будущего ассета
+func newAssetKey(req AssetUploadRequest) (string, AssetKind, error) {
+	if req.WorldID == "" || req.Kind == "" || req.ContentType == "" {
+		return "", AssetKind{}, fmt.Errorf("%w: world_id, kind and content_type are required", ErrInvalidAssetRequest)
+	}
+	if strings.ContainsAny(req.WorldID, "/\\.") {
+		return "", AssetKind{}, fmt.Errorf("%w: world_id %q", ErrInvalidAssetRequest, req.WorldID)
+	}
+	kind, ok := AssetKinds[req.Kind]
+	if !ok {
+		return "", AssetKind{}, fmt.Errorf("%w: %s", ErrUnknownAssetKind, req.Kind)
+	}
+	ext, ok := kind.ContentTypes[req.ContentType]
+	if !ok {
+		return "", AssetKind{}, fmt.Errorf("%w: %s for %s", ErrAssetContentType, req.ContentType, req.Kind)
+	}
+	if req.Size > kind.MaxSize {
+		return "", AssetKind{}, fmt.Errorf("%w: %d bytes (max %d)", ErrAssetTooLarge, req.Size, kind.MaxSize)
+	}
+	return req.WorldID + "/" + req.Kind + "/" + uuid.NewString() + ext, kind, nil
+}
+
+// assetKindFromKey возвращает вид ассета по ключу
+func assetKindFromKey(key string) (string, AssetKind, error) {
+	match := assetKeyPattern.FindStringSubmatch(key)
+	if match == nil {
+		return "", AssetKind{}, fmt.Errorf("%w: %s", ErrInvalidAssetKey, key)
+	}
+	kind, ok := AssetKinds[match[1]]
+	if !ok {
+		return "", AssetKind{}, fmt.Errorf("%w: %s", ErrUnknownAssetKind, match[1])
+	}
+	return match[1], kind, nil
+}
+
+// assetURL — путь, по которому GameService отдает ассет
+func assetURL(key string) string {
+	return "/v1/assets/" + key
+}
+
+// CreateAssetUpload выдает presigned-форму для загрузки ассета.
+// Если задан публичный адрес MinIO, он подставляется в ссылку: подпись POST-политики не зависит от хоста.
+func (s *Service) CreateAssetUpload(ctx context.Context, req AssetUploadRequest) (*AssetUploadTicket, error) {
+	key, kind, err := newAssetKey(req)
+	if err != nil {
+		return nil, err
+	}
+	if s.minioClient == nil {
+		return nil, ErrStorageNotAvailable
+	}
+
+	uploadURL, formData, err := s.minioClient.PresignAssetUpload(ctx, key, req.ContentType, kind.MaxSize, assetUploadTTL)
+	if err != nil {
+		return nil, err
+	}
+	if s.cfg.AssetsPublicEndpoint != "" {
+		if public, err := url.Parse(s.cfg.AssetsPublicEndpoint); err == nil {
+			uploadURL.Scheme = public.Scheme
+			uploadURL.Host = public.Host
+		}
+	}
+
+	return &AssetUploadTicket{
+		AssetKey:  key,
+		UploadURL: uploadURL.String(),
+		Method:    http.MethodPost,
+		FormData:  formData,
+		MaxSize:   kind.MaxSize,
+		ExpiresAt: time.Now().UTC().Add(assetUploadTTL),
+	}, nil
+}
+
+// CompleteAssetUpload проверяет загруженный объект и возвращает ссылку на ассет.
+// Объект, не прошедший проверку типа или размера, удаляется.
+func (s *Service) CompleteAssetUpload(ctx context.Context, key string) (*AssetRef, error) {
+	kindName, kind, err := assetKindFromKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if s.minioClient == nil {
+		return nil, ErrStorageNotAvailable
+	}
+
+	info, err := s.minioClient.StatAsset(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	var violation error
+	if _, ok := kind.ContentTypes[info.ContentType]; !ok {
+		violation = fmt.Errorf("%w: %s for %s", ErrAssetContentType, info.ContentType, kindName)
+	} else if info.Size > kind.MaxSize {
+		violation = fmt.Errorf("%w: %d bytes (max %d)", ErrAssetTooLarge, info.Size, kind.MaxSize)
+	}
+	if violation != nil {
+		if err := s.minioClient.RemoveAsset(ctx, key); err != nil {
+			logging.Errorf("Failed to remove rejected asset %s: %v", key, err)
+		}
+		return nil, violation
+	}
+
+	return &AssetRef{
+		Key:         key,
+		Kind:        kindName,
+		ContentType: info.ContentType,
+		Size:        info.Size,
+		URL:         assetURL(key),
+	}, nil
+}
+
+// CreateAssetUploadHandler обрабатывает POST /v1/assets/uploads
+func (s *Service) CreateAssetUploadHandler(w http.ResponseWriter, r *http.Request) {
+	var req AssetUploadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("Invalid request body"))
+		return
+	}
+	if err := bindSessionPlayer(r.Context(), nil, &req.WorldID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	ticket, err := s.CreateAssetUpload(r.Context(), req)
+	if err != nil {
+		writeAssetError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(ticket)
+}
+
+// CompleteAssetUploadHandler обрабатывает POST /v1/assets/uploads/complete
+func (s *Service) CompleteAssetUploadHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		AssetKey string `json:"asset_key"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("Invalid request body"))
+		return
+	}
+	// Ключ ассета начинается с мира: завершить можно только загрузку в мир своей сессии
+	worldID, _, _ := strings.Cut(req.AssetKey, "/")
+	if err := bindSessionPlayer(r.Context(), nil, &worldID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	ref, err := s.CompleteAssetUpload(r.Context(), req.AssetKey)
+	if err != nil {
+		writeAssetError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ref)
+}
+
+// GetAssetHandler обрабатывает GET /v1/assets/{asset_key} — отдает ассет с долгим кэшированием.
+// Содержимое по ключу никогда не меняется, поэтому повторные запросы с If-None-Match получают 304.
+func (s *Service) GetAssetHandler(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["asset_key"]
+	if _, _, err := assetKindFromKey(key); err != nil {
+		writeAssetError(w, err)
+		return
+	}
+	if s.minioClient == nil {
+		writeAssetError(w, ErrStorageNotAvailable)
+		return
+	}
+
+	obj, info, err := s.minioClient.OpenAsset(r.Context(), key)
+	if err != nil {
+		writeAssetError(w, err)
+		return
+	}
+	defer obj.Close()
+
+	etag := `"` + info.ETag + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", assetCacheControl)
+	w.Header().Set("Last-Modified", info.LastModified.UTC().Format(http.TimeFormat))
+	if match := r.Header.Get("If-None-Match"); match != "" && (match == etag || match == "*") {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", info.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(info.Size, 10))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if _, err := io.Copy(w, obj); err != nil {
+		logging.Errorf("Failed to stream asset %s: %v", key, err)
+	}
+}
+
+// writeAssetError переводит ошибку ассетов в HTTP-статус
+func writeAssetError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrAssetTooLarge):
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+	case errors.Is(err, ErrAssetContentType):
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+	case storage.IsNotFound(err):
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("Asset not found"))
+		return
+	case errors.Is(err, ErrStorageNotAvailable), storage.IsUnavailable(err):
+		w.WriteHeader(http.StatusServiceUnavailable)
+	case errors.Is(err, ErrInvalidAssetRequest), errors.Is(err, ErrUnknownAssetKind), errors.Is(err, ErrInvalidAssetKey):
+		w.WriteHeader(http.StatusBadRequest)
+	default:
+		logging.Errorf("Asset request failed: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("Asset request failed"))
+		return
+	}
+	w.Write([]byte(err.Error()))
+}
@@ -0,0 +1,44 @@
+package gameservice
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNewAssetKey(t *testing.T) {
+	key, kind, err := newAssetKey(AssetUploadRequest{WorldID: "world-1", Kind: "portrait", ContentType: "image/png", Size: 1024})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(key, "world-1/portrait/") || !strings.HasSuffix(key, ".png") {
+		t.Errorf("unexpected asset key %q", key)
+	}
+	if kindName, _, err := assetKindFromKey(key); err != nil || kindName != "portrait" {
+		t.Errorf("expected generated key to parse as portrait, got %q (%v)", kindName, err)
+	}
+	if kind.MaxSize != AssetKinds["portrait"].MaxSize {
+		t.Errorf("expected portrait limits, got %+v", kind)
+	}
+
+	cases := []struct {
+		req  AssetUploadRequest
+		want error
+	}{
+		{AssetUploadRequest{WorldID: "../world", Kind: "portrait", ContentType: "image/png"}, ErrInvalidAssetRequest},
+		{AssetUploadRequest{WorldID: "world-1", Kind: "video", ContentType: "video/mp4"}, ErrUnknownAssetKind},
+		{AssetUploadRequest{WorldID: "world-1", Kind: "emblem", ContentType: "image/svg+xml"}, ErrAssetContentType},
+		{AssetUploadRequest{WorldID: "world-1", Kind: "emblem", ContentType: "image/png", Size: 2 << 20}, ErrAssetTooLarge},
+	}
+	for _, c := range cases {
+		if _, _, err := newAssetKey(c.req); !errors.Is(err, c.want) {
+			t.Errorf("%+v: expected %v, got %v", c.req, c.want, err)
+		}
+	}
+
+	for _, key := range []string{"world-1/portrait/../../secrets.json", "world-1/video/0f8fad5b-d9cb-469f-a165-70867728950e.mp4"} {
+		if _, _, err := assetKindFromKey(key); err == nil {
+			t.Errorf("expected key %q to be rejected", key)
+		}
+	}
+}
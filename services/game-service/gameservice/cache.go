@@ -1,95 +1,237 @@
 package gameservice
 
 import (
+	"encoding/json"
+	"net/http"
+	"strings"
 	"sync"
 	"time"
+
 	"multiverse-core.io/shared/entity"
+	"multiverse-core.io/shared/eventbus"
 )
 
+// staleGrace — сколько после инвалидации сущность, прочитанная из MinIO, живёт в кэше вместо TTL:
+// EntityManager пишет изменения в MinIO асинхронно (кэш записи сбрасывается раз в 2 с),
+// и первое чтение после события может вернуть ещё старое состояние.
+const staleGrace = 5 * time.Second
+
 // EntityCacheKey представляет ключ для кэширования сущностей
 type EntityCacheKey struct {
 	EntityID string
 	WorldID  string
 }
 
-// EntityCache реализует кэш сущностей с TTL
+// EntityCache реализует кэш сущностей с TTL и сбросом по событиям изменения сущностей
 type EntityCache struct {
 	cache map[EntityCacheKey]*CachedEntity
-	mutex sync.RWMutex
-	ttl   time.Duration
+	// worlds — миры, в которых закэширована сущность: событие сбрасывает её во всех мирах
+	worlds map[string]map[string]bool
+	// invalidated — время последней инвалидации сущности, пока не истёк staleGrace
+	invalidated map[string]time.Time
+	mutex       sync.RWMutex
+	ttl         time.Duration
+	now         func() time.Time
+
+	stats CacheStats
 }
 
 // CachedEntity представляет сущность в кэше с метаданными
 type CachedEntity struct {
 	Entity    *entity.Entity
 	Timestamp time.Time
+	// ExpiresAt — конец жизни записи: Timestamp + TTL или конец staleGrace после инвалидации
+	ExpiresAt time.Time
+}
+
+// CacheStats — счётчики кэша сущностей (GET /v1/cache/stats)
+type CacheStats struct {
+	Entries int    `json:"entries"`
+	Hits    uint64 `json:"hits"`
+	Misses  uint64 `json:"misses"`
+	// Evictions — записи, удалённые по истечении срока жизни
+	Evictions uint64 `json:"evictions"`
+	// Invalidations — события изменения сущностей; StaleInvalidations — из них сбросившие закэшированную сущность
+	Invalidations      uint64 `json:"invalidations"`
+	StaleInvalidations uint64 `json:"stale_invalidations"`
 }
 
 // NewEntityCache создает новый кэш сущностей с заданным TTL
 func NewEntityCache(ttl time.Duration) *EntityCache {
 	cache := &EntityCache{
-		cache: make(map[EntityCacheKey]*CachedEntity),
-		ttl:   ttl,
+		cache:       make(map[EntityCacheKey]*CachedEntity),
+		worlds:      make(map[string]map[string]bool),
+		invalidated: make(map[string]time.Time),
+		ttl:         ttl,
+		now:         time.Now,
 	}
-	
+
 	// Запускаем горутину для очистки устаревших записей
 	go cache.cleanup()
-	
+
 	return cache
 }
 
 // Get возвращает сущность из кэша, если она существует и не устарела
 func (ec *EntityCache) Get(entityID, worldID string) (*entity.Entity, bool) {
 	key := EntityCacheKey{EntityID: entityID, WorldID: worldID}
-	
-	ec.mutex.RLock()
-	defer ec.mutex.RUnlock()
-	
-	if cached, exists := ec.cache[key]; exists {
-		if time.Since(cached.Timestamp) < ec.ttl {
-			return cached.Entity, true
-		}
+
+	ec.mutex.Lock()
+	defer ec.mutex.Unlock()
+
+	if cached, exists := ec.cache[key]; exists && ec.now().Before(cached.ExpiresAt) {
+		ec.stats.Hits++
+		return cached.Entity, true
 	}
-	
+	ec.stats.Misses++
 	return nil, false
 }
 
-// Set добавляет сущность в кэш
+// Set добавляет сущность в кэш. Сущность, сброшенная событием меньше staleGrace назад,
+// кэшируется только до конца staleGrace: она могла быть прочитана до записи изменения.
 func (ec *EntityCache) Set(entityID, worldID string, entity *entity.Entity) {
 	key := EntityCacheKey{EntityID: entityID, WorldID: worldID}
-	
+
 	ec.mutex.Lock()
 	defer ec.mutex.Unlock()
-	
+
+	now := ec.now()
+	expiresAt := now.Add(ec.ttl)
+	if at, ok := ec.invalidated[entityID]; ok && now.Before(at.Add(staleGrace)) {
+		expiresAt = at.Add(staleGrace)
+	}
 	ec.cache[key] = &CachedEntity{
 		Entity:    entity,
-		Timestamp: time.Now(),
+		Timestamp: now,
+		ExpiresAt: expiresAt,
 	}
+	if ec.worlds[entityID] == nil {
+		ec.worlds[entityID] = make(map[string]bool)
+	}
+	ec.worlds[entityID][worldID] = true
 }
 
 // Delete удаляет сущность из кэша
 func (ec *EntityCache) Delete(entityID, worldID string) {
-	key := EntityCacheKey{EntityID: entityID, WorldID: worldID}
-	
 	ec.mutex.Lock()
 	defer ec.mutex.Unlock()
-	
+
+	ec.deleteLocked(EntityCacheKey{EntityID: entityID, WorldID: worldID})
+}
+
+func (ec *EntityCache) deleteLocked(key EntityCacheKey) {
 	delete(ec.cache, key)
+	if worlds := ec.worlds[key.EntityID]; worlds != nil {
+		delete(worlds, key.WorldID)
+		if len(worlds) == 0 {
+			delete(ec.worlds, key.EntityID)
+		}
+	}
+}
+
+// Invalidate сбрасывает сущность во всех мирах: глобальные сущности кэшируются под каждым миром запроса.
+func (ec *EntityCache) Invalidate(entityID string) {
+	ec.mutex.Lock()
+	defer ec.mutex.Unlock()
+
+	ec.stats.Invalidations++
+	ec.invalidated[entityID] = ec.now()
+	if len(ec.worlds[entityID]) > 0 {
+		ec.stats.StaleInvalidations++
+	}
+	for worldID := range ec.worlds[entityID] {
+		ec.deleteLocked(EntityCacheKey{EntityID: entityID, WorldID: worldID})
+	}
+}
+
+// InvalidateFromEvent сбрасывает сущности, которые событие меняет в EntityManager:
+// сущность событий entity.* (entity.created, entity.updated, entity.deleted, entity.restored),
+// сущности state_changes и entity_snapshots. Возвращает число сброшенных сущностей.
+func (ec *EntityCache) InvalidateFromEvent(event eventbus.Event) int {
+	ids := changedEntityIDs(event)
+	for _, id := range ids {
+		ec.Invalidate(id)
+	}
+	return len(ids)
+}
+
+// changedEntityIDs возвращает ID сущностей, изменяемых событием, без повторов
+func changedEntityIDs(event eventbus.Event) []string {
+	seen := make(map[string]bool)
+	var ids []string
+	add := func(id string) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	if strings.HasPrefix(event.Type, eventbus.TypeEntity) {
+		if info := eventbus.ExtractEntityID(event.Payload); info != nil {
+			add(info.ID)
+		}
+	}
+	if changes, ok := event.Payload["state_changes"].([]interface{}); ok {
+		for _, raw := range changes {
+			if change, ok := raw.(map[string]interface{}); ok {
+				if info := eventbus.ExtractEntityID(change); info != nil {
+					add(info.ID)
+				}
+			}
+		}
+	}
+	if snapshots, ok := event.Payload["entity_snapshots"].([]interface{}); ok {
+		for _, raw := range snapshots {
+			if snapshot, ok := raw.(map[string]interface{}); ok {
+				id, _ := snapshot["id"].(string)
+				add(id)
+			}
+		}
+	}
+	return ids
+}
+
+// Stats возвращает счётчики кэша
+func (ec *EntityCache) Stats() CacheStats {
+	ec.mutex.RLock()
+	defer ec.mutex.RUnlock()
+
+	stats := ec.stats
+	stats.Entries = len(ec.cache)
+	return stats
 }
 
 // cleanup периодически удаляет устаревшие записи из кэша
 func (ec *EntityCache) cleanup() {
 	ticker := time.NewTicker(ec.ttl)
 	defer ticker.Stop()
-	
+
 	for range ticker.C {
-		ec.mutex.Lock()
-		now := time.Now()
-		for key, cached := range ec.cache {
-			if now.Sub(cached.Timestamp) >= ec.ttl {
-				delete(ec.cache, key)
-			}
+		ec.evictExpired()
+	}
+}
+
+// evictExpired удаляет записи с истёкшим сроком жизни и забывает давние инвалидации
+func (ec *EntityCache) evictExpired() {
+	ec.mutex.Lock()
+	defer ec.mutex.Unlock()
+
+	now := ec.now()
+	for key, cached := range ec.cache {
+		if !now.Before(cached.ExpiresAt) {
+			ec.deleteLocked(key)
+			ec.stats.Evictions++
+		}
+	}
+	for entityID, at := range ec.invalidated {
+		if !now.Before(at.Add(staleGrace)) {
+			delete(ec.invalidated, entityID)
 		}
-		ec.mutex.Unlock()
 	}
-}
\ No newline at end of file
+}
+
+// GetCacheStatsHandler обрабатывает GET /v1/cache/stats — счётчики кэша сущностей
+func (s *Service) GetCacheStatsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.entityCache.Stats())
+}
@@ -0,0 +1,111 @@
+package gameservice
+
+import (
+	"testing"
+	"time"
+
+	"multiverse-core.io/shared/entity"
+	"multiverse-core.io/shared/eventbus"
+)
+
+func newTestEntityCache(now *time.Time) *EntityCache {
+	return &EntityCache{
+		cache:       make(map[EntityCacheKey]*CachedEntity),
+		worlds:      make(map[string]map[string]bool),
+		invalidated: make(map[string]time.Time),
+		ttl:         time.Minute,
+		now:         func() time.Time { return *now },
+	}
+}
+
+func TestEntityCacheInvalidateFromEvent(t *testing.T) {
+	now := time.Now()
+	cache := newTestEntityCache(&now)
+	for _, id := range []string{"npc-1", "player-1", "city-1", "item-1"} {
+		cache.Set(id, "world-1", &entity.Entity{ID: id})
+	}
+	cache.Set("npc-1", "world-2", &entity.Entity{ID: "npc-1"})
+
+	updated := eventbus.NewStructuredEvent("entity.updated", "entity-manager", "world-1",
+		eventbus.NewEventPayload().WithEntity("npc-1", "npc", "Старейшина"))
+	if n := cache.InvalidateFromEvent(updated); n != 1 {
+		t.Fatalf("expected 1 invalidated entity, got %d", n)
+	}
+	for _, world := range []string{"world-1", "world-2"} {
+		if _, ok := cache.Get("npc-1", world); ok {
+			t.Errorf("npc-1 must be invalidated in %s", world)
+		}
+	}
+
+	changed := eventbus.Event{Type: "player.moved", Payload: map[string]interface{}{
+		"state_changes": []interface{}{
+			map[string]interface{}{"entity_id": "player-1", "operations": []interface{}{}},
+			map[string]interface{}{"entity_id": "player-1", "operations": []interface{}{}},
+		},
+		"entity_snapshots": []interface{}{
+			map[string]interface{}{"id": "city-1"},
+		},
+	}}
+	if n := cache.InvalidateFromEvent(changed); n != 2 {
+		t.Fatalf("expected 2 invalidated entities, got %d", n)
+	}
+	if _, ok := cache.Get("item-1", "world-1"); !ok {
+		t.Error("item-1 is not changed by the events and must stay cached")
+	}
+
+	// Сущность из payload не-entity события не сбрасывается
+	moved := eventbus.Event{Type: "player.moved", Payload: map[string]interface{}{"entity_id": "item-1"}}
+	if n := cache.InvalidateFromEvent(moved); n != 0 {
+		t.Errorf("expected no invalidation for a plain event, got %d", n)
+	}
+}
+
+func TestEntityCacheStats(t *testing.T) {
+	now := time.Now()
+	cache := newTestEntityCache(&now)
+	cache.Set("npc-1", "world-1", &entity.Entity{ID: "npc-1"})
+	cache.Set("npc-2", "world-1", &entity.Entity{ID: "npc-2"})
+
+	cache.Get("npc-1", "world-1")
+	cache.Get("npc-1", "world-1")
+	cache.Get("npc-3", "world-1")
+	cache.Invalidate("npc-1")
+	cache.Invalidate("npc-3")
+
+	now = now.Add(2 * time.Minute)
+	cache.evictExpired()
+
+	want := CacheStats{Entries: 0, Hits: 2, Misses: 1, Evictions: 1, Invalidations: 2, StaleInvalidations: 1}
+	if got := cache.Stats(); got != want {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestEntityCacheStaleGrace(t *testing.T) {
+	now := time.Now()
+	cache := newTestEntityCache(&now)
+	cache.Set("npc-1", "world-1", &entity.Entity{ID: "npc-1"})
+	cache.Invalidate("npc-1")
+
+	// Перечитанная сразу после события сущность может быть старой: кэшируется только до конца staleGrace
+	now = now.Add(time.Second)
+	cache.Set("npc-1", "world-1", &entity.Entity{ID: "npc-1"})
+	if _, ok := cache.Get("npc-1", "world-1"); !ok {
+		t.Fatal("expected the re-read entity to be cached during the grace period")
+	}
+	now = now.Add(staleGrace)
+	if _, ok := cache.Get("npc-1", "world-1"); ok {
+		t.Fatal("expected the re-read entity to expire with the grace period")
+	}
+
+	// После staleGrace сущность снова кэшируется на полный TTL
+	cache.evictExpired()
+	cache.Set("npc-1", "world-1", &entity.Entity{ID: "npc-1"})
+	now = now.Add(30 * time.Second)
+	if _, ok := cache.Get("npc-1", "world-1"); !ok {
+		t.Error("expected the entity to be cached for the full TTL after the grace period")
+	}
+	if len(cache.invalidated) != 0 {
+		t.Errorf("expected old invalidations to be forgotten, got %v", cache.invalidated)
+	}
+}
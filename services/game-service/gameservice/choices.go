@@ -0,0 +1,240 @@
+package gameservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+
+	"github.com/gorilla/mux"
+)
+
+// Типы событий точек выбора (публикует NarrativeOrchestrator, кроме player.choice.selected)
+const (
+	EventChoiceOffered  = "narrative.choice.offered"
+	EventChoiceSelected = "player.choice.selected"
+	EventChoiceResolved = "narrative.choice.resolved"
+)
+
+// Ошибки выбора варианта
+var (
+	ErrChoiceNotFound = errors.New("choice not found or already resolved")
+	ErrChoiceExpired  = errors.New("choice expired")
+	ErrUnknownOption  = errors.New("unknown option")
+)
+
+// ChoiceOption — вариант выбора
+type ChoiceOption struct {
+	ID          string `json:"id"`
+	Text        string `json:"text"`
+	Consequence string `json:"consequence,omitempty"`
+}
+
+// OpenChoice — выбор, предложенный игрокам и ещё не разрешённый
+type OpenChoice struct {
+	ID            string         `json:"choice_id"`
+	WorldID       string         `json:"world_id"`
+	ScopeID       string         `json:"scope_id,omitempty"`
+	Prompt        string         `json:"prompt"`
+	Options       []ChoiceOption `json:"options"`
+	DefaultOption string         `json:"default_option"`
+	Participants  []string       `json:"participants,omitempty"`
+	ExpiresAt     time.Time      `json:"expires_at"`
+}
+
+// SelectChoiceRequest — тело POST /v1/choices/{choice_id}/select
+type SelectChoiceRequest struct {
+	PlayerID string `json:"player_id"`
+	WorldID  string `json:"world_id"`
+	OptionID string `json:"option_id"`
+}
+
+// ChoiceBook хранит открытые точки выбора, чтобы проверять ответы игроков
+// и отдавать выборы переподключившимся клиентам
+type ChoiceBook struct {
+	publish func(ctx context.Context, event eventbus.Event) error
+	now     func() time.Time
+
+	choices map[string]*OpenChoice
+	mutex   sync.RWMutex
+}
+
+// NewChoiceBook создает книгу выборов, публикующую ответы игроков через publish
+func NewChoiceBook(publish func(ctx context.Context, event eventbus.Event) error) *ChoiceBook {
+	return &ChoiceBook{
+		publish: publish,
+		now:     time.Now,
+		choices: make(map[string]*OpenChoice),
+	}
+}
+
+// HandleEvent отслеживает предложенные и разрешённые выборы
+func (b *ChoiceBook) HandleEvent(event eventbus.Event) {
+	choiceID, _ := event.Payload["choice_id"].(string)
+	if choiceID == "" {
+		return
+	}
+
+	switch event.Type {
+	case EventChoiceOffered:
+		choice := &OpenChoice{ID: choiceID, WorldID: eventbus.GetWorldIDFromEvent(event)}
+		choice.Prompt, _ = event.Payload["prompt"].(string)
+		choice.DefaultOption, _ = event.Payload["default_option"].(string)
+		if scope := eventbus.GetScopeFromEvent(event); scope != nil {
+			choice.ScopeID = scope.ID
+		}
+		// Варианты и участники приходят как JSON — перекодируем в типизированные структуры
+		if raw, err := json.Marshal(event.Payload["options"]); err == nil {
+			json.Unmarshal(raw, &choice.Options)
+		}
+		if raw, err := json.Marshal(event.Payload["participants"]); err == nil {
+			json.Unmarshal(raw, &choice.Participants)
+		}
+		if expiresAt, ok := event.Payload["expires_at"].(string); ok {
+			choice.ExpiresAt, _ = time.Parse(time.RFC3339, expiresAt)
+		}
+
+		b.mutex.Lock()
+		b.choices[choiceID] = choice
+		b.cleanupLocked()
+		b.mutex.Unlock()
+	case EventChoiceResolved:
+		b.mutex.Lock()
+		delete(b.choices, choiceID)
+		b.mutex.Unlock()
+	}
+}
+
+// Open возвращает открытые выборы мира, отсортированные по времени истечения
+func (b *ChoiceBook) Open(worldID string) []OpenChoice {
+	now := b.now()
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	result := make([]OpenChoice, 0)
+	for _, choice := range b.choices {
+		if choice.WorldID != worldID || (!choice.ExpiresAt.IsZero() && now.After(choice.ExpiresAt)) {
+			continue
+		}
+		result = append(result, *choice)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ExpiresAt.Before(result[j].ExpiresAt)
+	})
+	return result
+}
+
+// Select проверяет ответ игрока и публикует player.choice.selected.
+// Окончательно выбор разрешает NarrativeOrchestrator: побеждает первый полученный им ответ.
+func (b *ChoiceBook) Select(ctx context.Context, choiceID string, req SelectChoiceRequest) (*eventbus.Event, error) {
+	if req.PlayerID == "" || req.WorldID == "" || req.OptionID == "" {
+		return nil, fmt.Errorf("player_id, world_id and option_id are required")
+	}
+
+	b.mutex.RLock()
+	choice, ok := b.choices[choiceID]
+	b.mutex.RUnlock()
+	if !ok || choice.WorldID != req.WorldID {
+		return nil, ErrChoiceNotFound
+	}
+	if !choice.ExpiresAt.IsZero() && b.now().After(choice.ExpiresAt) {
+		return nil, ErrChoiceExpired
+	}
+	known := false
+	for _, option := range choice.Options {
+		if option.ID == req.OptionID {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownOption, req.OptionID)
+	}
+
+	payload := eventbus.NewEventPayload().
+		WithEntity(req.PlayerID, "player", "").
+		WithWorld(req.WorldID)
+	eventbus.SetNested(payload.GetCustom(), "choice_id", choiceID)
+	eventbus.SetNested(payload.GetCustom(), "option_id", req.OptionID)
+	if choice.ScopeID != "" {
+		eventbus.SetNested(payload.GetCustom(), "scope.id", choice.ScopeID)
+	}
+
+	event := eventbus.NewStructuredEvent(EventChoiceSelected, "game-service", req.WorldID, payload)
+	if err := b.publish(ctx, event); err != nil {
+		return nil, fmt.Errorf("failed to publish choice selection: %w", err)
+	}
+	return &event, nil
+}
+
+// cleanupLocked удаляет давно истёкшие выборы, о разрешении которых сервис не узнал
+func (b *ChoiceBook) cleanupLocked() {
+	threshold := b.now().Add(-time.Hour)
+	for id, choice := range b.choices {
+		if !choice.ExpiresAt.IsZero() && choice.ExpiresAt.Before(threshold) {
+			delete(b.choices, id)
+		}
+	}
+}
+
+// GetOpenChoicesHandler обрабатывает GET /v1/choices?world_id= — открытые выборы мира
+func (s *Service) GetOpenChoicesHandler(w http.ResponseWriter, r *http.Request) {
+	worldID := r.URL.Query().Get("world_id")
+	if worldID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("world_id is required"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"choices": s.choices.Open(worldID),
+	})
+}
+
+// SelectChoiceHandler обрабатывает POST /v1/choices/{choice_id}/select — ответ игрока на точку выбора
+func (s *Service) SelectChoiceHandler(w http.ResponseWriter, r *http.Request) {
+	choiceID := mux.Vars(r)["choice_id"]
+
+	var req SelectChoiceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("Invalid request body"))
+		return
+	}
+	if err := bindSessionPlayer(r.Context(), &req.PlayerID, &req.WorldID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	event, err := s.choices.Select(r.Context(), choiceID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrChoiceNotFound):
+			w.WriteHeader(http.StatusNotFound)
+		case errors.Is(err, ErrChoiceExpired):
+			w.WriteHeader(http.StatusGone)
+		case errors.Is(err, ErrUnknownOption), req.PlayerID == "" || req.WorldID == "" || req.OptionID == "":
+			w.WriteHeader(http.StatusBadRequest)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{
+		"choice_id": choiceID,
+		"option_id": req.OptionID,
+		"event_id":  event.ID,
+	})
+}
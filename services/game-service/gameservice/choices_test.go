@@ -0,0 +1,61 @@
+package gameservice
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+)
+
+func TestChoiceBook(t *testing.T) {
+	var published []eventbus.Event
+	book := NewChoiceBook(func(ctx context.Context, event eventbus.Event) error {
+		published = append(published, event)
+		return nil
+	})
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	book.now = func() time.Time { return now }
+
+	book.HandleEvent(eventbus.NewEvent(EventChoiceOffered, "narrative-orchestrator", "world-1", map[string]any{
+		"choice_id": "choice-1",
+		"prompt":    "Открыть дверь?",
+		"options": []any{
+			map[string]any{"id": "open", "text": "Открыть"},
+			map[string]any{"id": "leave", "text": "Уйти"},
+		},
+		"default_option": "leave",
+		"expires_at":     now.Add(time.Minute).Format(time.RFC3339),
+	}))
+
+	if open := book.Open("world-1"); len(open) != 1 || len(open[0].Options) != 2 {
+		t.Fatalf("expected one open choice with two options, got %+v", open)
+	}
+
+	ctx := context.Background()
+	if _, err := book.Select(ctx, "choice-1", SelectChoiceRequest{PlayerID: "player-1", WorldID: "world-1", OptionID: "fly"}); !errors.Is(err, ErrUnknownOption) {
+		t.Errorf("expected ErrUnknownOption, got %v", err)
+	}
+	if _, err := book.Select(ctx, "choice-1", SelectChoiceRequest{PlayerID: "player-1", WorldID: "world-2", OptionID: "open"}); !errors.Is(err, ErrChoiceNotFound) {
+		t.Errorf("expected ErrChoiceNotFound for another world, got %v", err)
+	}
+
+	event, err := book.Select(ctx, "choice-1", SelectChoiceRequest{PlayerID: "player-1", WorldID: "world-1", OptionID: "open"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(published) != 1 || event.Type != EventChoiceSelected || published[0].Payload["option_id"] != "open" {
+		t.Fatalf("expected player.choice.selected with option_id, got %+v", published)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, err := book.Select(ctx, "choice-1", SelectChoiceRequest{PlayerID: "player-2", WorldID: "world-1", OptionID: "leave"}); !errors.Is(err, ErrChoiceExpired) {
+		t.Errorf("expected ErrChoiceExpired, got %v", err)
+	}
+
+	book.HandleEvent(eventbus.NewEvent(EventChoiceResolved, "narrative-orchestrator", "world-1", map[string]any{"choice_id": "choice-1"}))
+	if _, err := book.Select(ctx, "choice-1", SelectChoiceRequest{PlayerID: "player-2", WorldID: "world-1", OptionID: "leave"}); !errors.Is(err, ErrChoiceNotFound) {
+		t.Errorf("expected ErrChoiceNotFound after resolution, got %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package gameservice
+
+import (
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+)
+
+// maxChronicleEntries ограничивает количество хранимых записей хроники на мир
+const maxChronicleEntries = 200
+
+// ChronicleEntry представляет одну запись хроники мира (сгенерированное повествование)
+type ChronicleEntry struct {
+	EventID   string    `json:"event_id"`
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	ScopeID   string    `json:"scope_id,omitempty"`
+	Narrative string    `json:"narrative"`
+}
+
+// ChronicleStore хранит последние повествовательные события по мирам
+type ChronicleStore struct {
+	entries map[string][]ChronicleEntry
+	mutex   sync.RWMutex
+}
+
+// NewChronicleStore создает новое хранилище хроник
+func NewChronicleStore() *ChronicleStore {
+	return &ChronicleStore{
+		entries: make(map[string][]ChronicleEntry),
+	}
+}
+
+// Record добавляет повествовательное событие в хронику его мира
+func (cs *ChronicleStore) Record(event eventbus.Event) {
+	worldID := eventbus.GetWorldIDFromEvent(event)
+	if worldID == "" {
+		return
+	}
+
+	narrative := narrativeText(event)
+	if narrative == "" {
+		return
+	}
+
+	entry := ChronicleEntry{
+		EventID:   event.ID,
+		Type:      event.Type,
+		Timestamp: event.Timestamp,
+		Narrative: narrative,
+	}
+	if scope := eventbus.GetScopeFromEvent(event); scope != nil {
+		entry.ScopeID = scope.ID
+	}
+
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+
+	entries := append(cs.entries[worldID], entry)
+	if len(entries) > maxChronicleEntries {
+		entries = entries[len(entries)-maxChronicleEntries:]
+	}
+	cs.entries[worldID] = entries
+}
+
+// Recent возвращает до limit последних записей хроники мира, от новых к старым
+func (cs *ChronicleStore) Recent(worldID string, limit int) []ChronicleEntry {
+	cs.mutex.RLock()
+	defer cs.mutex.RUnlock()
+
+	entries := cs.entries[worldID]
+	if limit <= 0 || limit > len(entries) {
+		limit = len(entries)
+	}
+
+	result := make([]ChronicleEntry, 0, limit)
+	for i := len(entries) - 1; i >= 0 && len(result) < limit; i-- {
+		result = append(result, entries[i])
+	}
+	return result
+}
@@ -0,0 +1,152 @@
+package gameservice
+
+import (
+	"bytes"
+	"context"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	storage "multiverse-core.io/shared/minio"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// CredentialsBucket — учётные данные игроков: {world_id}/{player_id}.json.
+// Хранятся отдельно от сущности игрока: её payload отдаётся клиентам.
+const CredentialsBucket = "player-credentials"
+
+// MinPasswordLength — минимальная длина пароля игрока
+const MinPasswordLength = 8
+
+// Параметры хэширования паролей (PBKDF2-HMAC-SHA256)
+const (
+	passwordAlgorithm = "pbkdf2-sha256"
+	passwordKeyLength = 32
+	passwordSaltSize  = 16
+)
+
+// passwordIterations — число итераций для новых паролей; в тестах уменьшается
+var passwordIterations = 600_000
+
+// Ошибки регистрации и входа
+var (
+	ErrPlayerExists       = errors.New("player already exists")
+	ErrInvalidCredentials = errors.New("invalid player_id or password")
+	ErrWeakPassword       = fmt.Errorf("password must be at least %d characters", MinPasswordLength)
+)
+
+// PlayerCredential — хэш пароля игрока
+type PlayerCredential struct {
+	Algorithm  string    `json:"algorithm"`
+	Iterations int       `json:"iterations"`
+	Salt       string    `json:"salt"`
+	Hash       string    `json:"hash"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// NewPlayerCredential хэширует пароль со случайной солью
+func NewPlayerCredential(password string) (*PlayerCredential, error) {
+	if len(password) < MinPasswordLength {
+		return nil, ErrWeakPassword
+	}
+	salt := make([]byte, passwordSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	hash, err := pbkdf2.Key(sha256.New, password, salt, passwordIterations, passwordKeyLength)
+	if err != nil {
+		return nil, err
+	}
+	return &PlayerCredential{
+		Algorithm:  passwordAlgorithm,
+		Iterations: passwordIterations,
+		Salt:       base64.RawStdEncoding.EncodeToString(salt),
+		Hash:       base64.RawStdEncoding.EncodeToString(hash),
+		CreatedAt:  time.Now().UTC(),
+	}, nil
+}
+
+// Verify сравнивает пароль с хэшем за постоянное время
+func (c *PlayerCredential) Verify(password string) bool {
+	if c.Algorithm != passwordAlgorithm || c.Iterations <= 0 {
+		return false
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(c.Salt)
+	if err != nil {
+		return false
+	}
+	want, err := base64.RawStdEncoding.DecodeString(c.Hash)
+	if err != nil {
+		return false
+	}
+	got, err := pbkdf2.Key(sha256.New, password, salt, c.Iterations, len(want))
+	return err == nil && subtle.ConstantTimeCompare(got, want) == 1
+}
+
+// credentialStore хранит учётные данные игроков (MinioClient)
+type credentialStore interface {
+	// LoadCredential возвращает учётные данные; ошибка оборачивает storage.ErrNotFound, если их нет
+	LoadCredential(ctx context.Context, worldID, playerID string) (*PlayerCredential, error)
+	// CreateCredential сохраняет учётные данные, только если их ещё нет; иначе ErrPlayerExists
+	CreateCredential(ctx context.Context, worldID, playerID string, cred *PlayerCredential) error
+	// RemoveCredential удаляет учётные данные (откат неудачной регистрации)
+	RemoveCredential(ctx context.Context, worldID, playerID string) error
+}
+
+func credentialKey(worldID, playerID string) string {
+	return worldID + "/" + playerID + ".json"
+}
+
+// LoadCredential читает учётные данные игрока
+func (mc *MinioClient) LoadCredential(ctx context.Context, worldID, playerID string) (*PlayerCredential, error) {
+	obj, err := mc.client.GetObject(ctx, CredentialsBucket, credentialKey(worldID, playerID), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, storage.ClassifyError(err)
+	}
+	defer obj.Close()
+
+	var cred PlayerCredential
+	if err := json.NewDecoder(obj).Decode(&cred); err != nil {
+		return nil, storage.ClassifyError(err)
+	}
+	return &cred, nil
+}
+
+// CreateCredential сохраняет учётные данные условной записью (If-None-Match: *),
+// поэтому из двух одновременных регистраций одного игрока проходит одна
+func (mc *MinioClient) CreateCredential(ctx context.Context, worldID, playerID string, cred *PlayerCredential) error {
+	if err := mc.ensureBucket(ctx, CredentialsBucket); err != nil {
+		return err
+	}
+	data, err := json.Marshal(cred)
+	if err != nil {
+		return err
+	}
+	opts := minio.PutObjectOptions{ContentType: "application/json"}
+	opts.SetMatchETagExcept("*")
+	_, err = mc.client.PutObject(ctx, CredentialsBucket, credentialKey(worldID, playerID),
+		bytes.NewReader(data), int64(len(data)), opts)
+	if err != nil {
+		if minio.ToErrorResponse(err).StatusCode == http.StatusPreconditionFailed {
+			return ErrPlayerExists
+		}
+		return storage.ClassifyError(err)
+	}
+	return nil
+}
+
+// RemoveCredential удаляет учётные данные игрока
+func (mc *MinioClient) RemoveCredential(ctx context.Context, worldID, playerID string) error {
+	if err := mc.client.RemoveObject(ctx, CredentialsBucket, credentialKey(worldID, playerID), minio.RemoveObjectOptions{}); err != nil {
+		return storage.ClassifyError(err)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package gameservice
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"multiverse-core.io/shared/entity"
+	storage "multiverse-core.io/shared/minio"
+)
+
+// memCredentials хранит учётные данные в памяти
+type memCredentials struct {
+	mu    sync.Mutex
+	creds map[string]*PlayerCredential
+}
+
+func newMemCredentials() *memCredentials {
+	return &memCredentials{creds: make(map[string]*PlayerCredential)}
+}
+
+func (m *memCredentials) LoadCredential(_ context.Context, worldID, playerID string) (*PlayerCredential, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cred, ok := m.creds[credentialKey(worldID, playerID)]
+	if !ok {
+		return nil, fmt.Errorf("%w: no credentials", storage.ErrNotFound)
+	}
+	return cred, nil
+}
+
+func (m *memCredentials) CreateCredential(_ context.Context, worldID, playerID string, cred *PlayerCredential) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.creds[credentialKey(worldID, playerID)]; ok {
+		return ErrPlayerExists
+	}
+	m.creds[credentialKey(worldID, playerID)] = cred
+	return nil
+}
+
+func (m *memCredentials) RemoveCredential(_ context.Context, worldID, playerID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.creds, credentialKey(worldID, playerID))
+	return nil
+}
+
+func TestPlayerCredential(t *testing.T) {
+	defer func(n int) { passwordIterations = n }(passwordIterations)
+	passwordIterations = 1000
+
+	if _, err := NewPlayerCredential("short"); !errors.Is(err, ErrWeakPassword) {
+		t.Fatalf("expected weak password, got %v", err)
+	}
+	cred, err := NewPlayerCredential("correct horse")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cred.Verify("correct horse") {
+		t.Error("expected the password to match")
+	}
+	if cred.Verify("correct horsE") || cred.Verify("") {
+		t.Error("expected other passwords to be rejected")
+	}
+	other, _ := NewPlayerCredential("correct horse")
+	if other.Salt == cred.Salt || other.Hash == cred.Hash {
+		t.Error("expected a fresh salt per credential")
+	}
+}
+
+func TestLoginRequiresPassword(t *testing.T) {
+	defer func(n int) { passwordIterations = n }(passwordIterations)
+	passwordIterations = 1000
+
+	creds := newMemCredentials()
+	service := &Service{
+		entityCache: NewEntityCache(time.Minute),
+		sessions:    NewSessionManager("secret", time.Hour),
+	}
+	service.playerService = &PlayerService{entityCache: service.entityCache, credentials: creds}
+	service.entityCache.Set("player-1", "world-1", entity.NewEntity("player-1", "player", map[string]interface{}{"name": "Каин"}))
+	cred, _ := NewPlayerCredential("secret-password")
+	creds.CreateCredential(context.Background(), "world-1", "player-1", cred)
+
+	post := func(handler http.HandlerFunc, body map[string]string) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(body)
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(data)))
+		return rec
+	}
+
+	cases := []struct {
+		name    string
+		handler http.HandlerFunc
+		body    map[string]string
+		want    int
+	}{
+		{"login without password", service.LoginPlayerHandler,
+			map[string]string{"player_id": "player-1", "world_id": "world-1"}, http.StatusBadRequest},
+		{"login with wrong password", service.LoginPlayerHandler,
+			map[string]string{"player_id": "player-1", "world_id": "world-1", "password": "guess-password"}, http.StatusUnauthorized},
+		{"login of unknown player", service.LoginPlayerHandler,
+			map[string]string{"player_id": "player-2", "world_id": "world-1", "password": "secret-password"}, http.StatusUnauthorized},
+		{"login in other world", service.LoginPlayerHandler,
+			map[string]string{"player_id": "player-1", "world_id": "world-2", "password": "secret-password"}, http.StatusUnauthorized},
+		{"register existing player", service.RegisterPlayerHandler,
+			map[string]string{"player_id": "player-1", "player_name": "Авель", "world_id": "world-1", "password": "other-password"}, http.StatusConflict},
+		{"register with short password", service.RegisterPlayerHandler,
+			map[string]string{"player_id": "player-3", "player_name": "Авель", "world_id": "world-1", "password": "123"}, http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		rec := post(tc.handler, tc.body)
+		if rec.Code != tc.want {
+			t.Errorf("%s: expected %d, got %d: %s", tc.name, tc.want, rec.Code, rec.Body.String())
+		}
+		if bytes.Contains(rec.Body.Bytes(), []byte(`"token"`)) {
+			t.Errorf("%s: session issued", tc.name)
+		}
+	}
+
+	rec := post(service.LoginPlayerHandler, map[string]string{"player_id": "player-1", "world_id": "world-1", "password": "secret-password"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected login, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Token string `json:"token"`
+	}
+	json.NewDecoder(rec.Body).Decode(&resp)
+	claims, err := service.sessions.Verify(resp.Token)
+	if err != nil || claims.PlayerID != "player-1" || claims.WorldID != "world-1" {
+		t.Errorf("unexpected session %+v: %v", claims, err)
+	}
+}
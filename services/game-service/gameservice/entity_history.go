@@ -0,0 +1,221 @@
+package gameservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/entity"
+	"multiverse-core.io/shared/eventbus"
+	storage "multiverse-core.io/shared/minio"
+
+	"github.com/gorilla/mux"
+)
+
+// Ограничения страницы истории сущности
+const (
+	defaultHistoryLimit = 20
+	maxHistoryLimit     = 100
+	// historyLookupWorkers — сколько событий страницы запрашивается у SemanticMemory параллельно
+	historyLookupWorkers = 8
+)
+
+// SemanticMemoryClient читает проиндексированные события из SemanticMemory
+type SemanticMemoryClient struct {
+	BaseURL    string
+	httpClient *http.Client
+}
+
+// NewSemanticMemoryClient создает клиент SemanticMemory
+func NewSemanticMemoryClient(baseURL string) *SemanticMemoryClient {
+	if baseURL == "" {
+		baseURL = "http://semantic-memory:8080"
+	}
+	return &SemanticMemoryClient{
+		BaseURL:    baseURL,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// GetEvent возвращает событие по ID. Неизвестное событие — storage.ErrNotFound,
+// сбой соединения или 5xx — storage.ErrUnavailable.
+func (c *SemanticMemoryClient) GetEvent(ctx context.Context, eventID string) (*eventbus.Event, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+"/v1/events/"+url.PathEscape(eventID), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("semantic memory connection failed: %v: %w", err, storage.ErrUnavailable)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("event %s: %w", eventID, storage.ErrNotFound)
+	case resp.StatusCode != http.StatusOK:
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("semantic memory returned status %d: %s: %w", resp.StatusCode, string(body), storage.ErrUnavailable)
+	}
+
+	var event eventbus.Event
+	if err := json.NewDecoder(resp.Body).Decode(&event); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// EntityHistoryItem — событие в истории сущности
+type EntityHistoryItem struct {
+	EventID   string    `json:"event_id"`
+	EventType string    `json:"event_type,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	Summary   string    `json:"summary,omitempty"`
+	// Resolved — событие найдено в SemanticMemory; иначе известны только ID и время из истории сущности
+	Resolved bool `json:"resolved"`
+}
+
+// EntityHistoryPage — страница истории сущности, от новых событий к старым
+type EntityHistoryPage struct {
+	EntityID string              `json:"entity_id"`
+	Items    []EntityHistoryItem `json:"items"`
+	Total    int                 `json:"total"`
+	// NextOffset задан, если есть более старые события
+	NextOffset *int `json:"next_offset,omitempty"`
+	// Partial — часть событий не удалось получить из-за недоступности SemanticMemory
+	Partial bool `json:"partial,omitempty"`
+}
+
+// historyPage выбирает страницу истории: offset считается от самого нового события
+func historyPage(history []entity.HistoryEntry, offset, limit int) ([]entity.HistoryEntry, *int) {
+	page := make([]entity.HistoryEntry, 0, limit)
+	for i := len(history) - 1 - offset; i >= 0 && len(page) < limit; i-- {
+		page = append(page, history[i])
+	}
+	if next := offset + len(page); next < len(history) {
+		return page, &next
+	}
+	return page, nil
+}
+
+// eventSummary возвращает краткое описание события для UI
+func eventSummary(event *eventbus.Event) string {
+	for _, path := range []string{"narrative", "description", "summary"} {
+		if text, ok := event.Path().GetString(path); ok && text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+// EntityHistory загружает сущность и разрешает события страницы её истории через SemanticMemory
+func (s *Service) EntityHistory(ctx context.Context, entityID, worldID string, offset, limit int) (*EntityHistoryPage, error) {
+	ent, err := s.GetEntity(ctx, entityID, worldID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, next := historyPage(ent.History, offset, limit)
+	result := &EntityHistoryPage{
+		EntityID:   entityID,
+		Items:      make([]EntityHistoryItem, len(entries)),
+		Total:      len(ent.History),
+		NextOffset: next,
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mutex   sync.Mutex
+		workers = make(chan struct{}, historyLookupWorkers)
+	)
+	for i, entry := range entries {
+		result.Items[i] = EntityHistoryItem{EventID: entry.EventID, Timestamp: entry.Timestamp}
+		if s.semantic == nil {
+			result.Partial = true
+			continue
+		}
+
+		wg.Add(1)
+		workers <- struct{}{}
+		go func(item *EntityHistoryItem) {
+			defer wg.Done()
+			defer func() { <-workers }()
+
+			event, err := s.semantic.GetEvent(ctx, item.EventID)
+			if err != nil {
+				if !storage.IsNotFound(err) {
+					mutex.Lock()
+					result.Partial = true
+					mutex.Unlock()
+				}
+				return
+			}
+			item.EventType = event.Type
+			item.Summary = eventSummary(event)
+			item.Resolved = true
+		}(&result.Items[i])
+	}
+	wg.Wait()
+	return result, nil
+}
+
+// GetEntityHistoryHandler обрабатывает GET /entities/{entity_id}/history?world_id=...&offset=...&limit=...
+func (s *Service) GetEntityHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	entityID := mux.Vars(r)["entity_id"]
+	query := r.URL.Query()
+
+	worldID := query.Get("world_id")
+	if worldID == "" {
+		worldID = r.Header.Get("X-World-ID")
+	}
+	if worldID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("world_id is required"))
+		return
+	}
+
+	offset, limit := 0, defaultHistoryLimit
+	if raw := query.Get("offset"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("offset must be a non-negative integer"))
+			return
+		}
+		offset = value
+	}
+	if raw := query.Get("limit"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil || value <= 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("limit must be a positive integer"))
+			return
+		}
+		limit = min(value, maxHistoryLimit)
+	}
+
+	page, err := s.EntityHistory(r.Context(), entityID, worldID, offset, limit)
+	if err != nil {
+		switch {
+		case storage.IsNotFound(err):
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("Entity not found"))
+		case storage.IsUnavailable(err):
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(fmt.Sprintf("Storage unavailable: %v", err)))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(fmt.Sprintf("Failed to load entity history: %v", err)))
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
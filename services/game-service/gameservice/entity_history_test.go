@@ -0,0 +1,82 @@
+package gameservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"multiverse-core.io/shared/entity"
+	"multiverse-core.io/shared/eventbus"
+	storage "multiverse-core.io/shared/minio"
+)
+
+func TestHistoryPage(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	history := make([]entity.HistoryEntry, 5)
+	for i := range history {
+		history[i] = entity.HistoryEntry{EventID: fmt.Sprintf("e%d", i+1), Timestamp: base.Add(time.Duration(i) * time.Minute)}
+	}
+
+	page, next := historyPage(history, 0, 2)
+	if len(page) != 2 || page[0].EventID != "e5" || page[1].EventID != "e4" {
+		t.Fatalf("expected newest events first, got %+v", page)
+	}
+	if next == nil || *next != 2 {
+		t.Fatalf("expected next offset 2, got %v", next)
+	}
+
+	page, next = historyPage(history, 4, 2)
+	if len(page) != 1 || page[0].EventID != "e1" || next != nil {
+		t.Errorf("expected last page with e1 only, got %+v next=%v", page, next)
+	}
+	if page, next := historyPage(history, 10, 2); len(page) != 0 || next != nil {
+		t.Errorf("expected empty page past the end, got %+v", page)
+	}
+}
+
+func TestSemanticMemoryClientGetEvent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch strings.TrimPrefix(r.URL.Path, "/v1/events/") {
+		case "e1":
+			event := eventbus.NewEvent("player.moved", "game-service", "world-1", map[string]interface{}{
+				"narrative": "Каин вошёл в лес",
+			})
+			event.ID = "e1"
+			json.NewEncoder(w).Encode(event)
+		case "broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"event_not_found"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewSemanticMemoryClient(server.URL)
+	ctx := context.Background()
+
+	event, err := client.GetEvent(ctx, "e1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if event.Type != "player.moved" || eventSummary(event) != "Каин вошёл в лес" {
+		t.Errorf("unexpected event %+v", event)
+	}
+
+	if _, err := client.GetEvent(ctx, "missing"); !storage.IsNotFound(err) {
+		t.Errorf("expected not found, got %v", err)
+	}
+	if _, err := client.GetEvent(ctx, "broken"); !storage.IsUnavailable(err) {
+		t.Errorf("expected unavailable, got %v", err)
+	}
+
+	server.Close()
+	if _, err := client.GetEvent(ctx, "e1"); !storage.IsUnavailable(err) {
+		t.Errorf("expected unavailable after shutdown, got %v", err)
+	}
+}
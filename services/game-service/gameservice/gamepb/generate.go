@@ -0,0 +1,4 @@
+// Package gamepb — сгенерированные типы и заглушки gRPC API GameService (game.proto).
+package gamepb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative game.proto
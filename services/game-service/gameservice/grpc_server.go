@@ -0,0 +1,400 @@
+package gameservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"multiverse-core.io/services/game-service/gameservice/gamepb"
+	"multiverse-core.io/shared/entity"
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// grpcSubscriberBuffer — сколько событий ждут отправки медленному подписчику, прежде чем новые будут отброшены
+const grpcSubscriberBuffer = 256
+
+// grpcPublicMethods — методы, доступные без токена сессии (как REST /players/* и GET /entities/{id})
+var grpcPublicMethods = map[string]bool{
+	gamepb.GameService_Register_FullMethodName:  true,
+	gamepb.GameService_Login_FullMethodName:     true,
+	gamepb.GameService_GetEntity_FullMethodName: true,
+}
+
+// grpcSubscriber — поток Subscribe с фильтрами, как у клиента WebSocket
+type grpcSubscriber struct {
+	client *wsClient
+	events chan eventbus.Event
+}
+
+// GRPCServer — gRPC API GameService (gamepb.GameService). Методы вызывают ту же логику сервиса,
+// что и REST, а Subscribe получает те же события, что и клиенты WebSocket.
+type GRPCServer struct {
+	gamepb.UnimplementedGameServiceServer
+
+	service *Service
+	server  *grpc.Server
+	addr    string
+
+	subscribers map[*grpcSubscriber]struct{}
+	mutex       sync.Mutex
+}
+
+// NewGRPCServer создает gRPC-сервер сервиса на адресе addr (например, ":9090")
+func NewGRPCServer(service *Service, addr string) *GRPCServer {
+	gs := &GRPCServer{
+		service:     service,
+		addr:        addr,
+		subscribers: make(map[*grpcSubscriber]struct{}),
+	}
+	gs.server = grpc.NewServer(
+		grpc.UnaryInterceptor(gs.unaryAuth),
+		grpc.StreamInterceptor(gs.streamAuth),
+	)
+	gamepb.RegisterGameServiceServer(gs.server, gs)
+	return gs
+}
+
+// Start начинает принимать подключения в отдельной горутине
+func (gs *GRPCServer) Start() error {
+	listener, err := net.Listen("tcp", gs.addr)
+	if err != nil {
+		return err
+	}
+	go gs.Serve(listener)
+	return nil
+}
+
+// Serve обслуживает подключения listener до остановки сервера
+func (gs *GRPCServer) Serve(listener net.Listener) {
+	logging.Infof("gRPC server starting on %s", listener.Addr())
+	if err := gs.server.Serve(listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		logging.Errorf("gRPC server error: %v", err)
+	}
+}
+
+// Stop дожидается завершения вызовов; потоки Subscribe бесконечны, поэтому через 5 секунд они обрываются
+func (gs *GRPCServer) Stop() {
+	done := make(chan struct{})
+	go func() {
+		gs.server.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		gs.server.Stop()
+	}
+	logging.Infof("gRPC server stopped")
+}
+
+// authenticate проверяет токен из метаданных "authorization: Bearer <token>" и кладёт сессию в контекст,
+// чтобы bindSessionPlayer и withSessionIdentity работали так же, как для REST
+func (gs *GRPCServer) authenticate(ctx context.Context, method string) (context.Context, error) {
+	token := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, value := range md.Get("authorization") {
+			if strings.HasPrefix(value, "Bearer ") {
+				token = strings.TrimSpace(strings.TrimPrefix(value, "Bearer "))
+			}
+		}
+	}
+	if token == "" {
+		if grpcPublicMethods[method] {
+			return ctx, nil
+		}
+		return nil, status.Error(codes.Unauthenticated, ErrMissingToken.Error())
+	}
+	claims, err := gs.service.sessions.Verify(token)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return context.WithValue(ctx, sessionContextKey{}, claims), nil
+}
+
+func (gs *GRPCServer) unaryAuth(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := gs.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// sessionStream подменяет контекст потока контекстом с сессией
+type sessionStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *sessionStream) Context() context.Context {
+	return s.ctx
+}
+
+func (gs *GRPCServer) streamAuth(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := gs.authenticate(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &sessionStream{ServerStream: stream, ctx: ctx})
+}
+
+// grpcError переводит ошибки сервиса в коды gRPC, как writeCommandError — в коды HTTP
+func grpcError(err error) error {
+	switch {
+	case errors.Is(err, ErrInvalidCommand):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, ErrCommandNotAllowed):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, ErrActionVetoed), errors.Is(err, ErrForeignPlayer):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case storage.IsNotFound(err):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, ErrPublishFailed), storage.IsUnavailable(err):
+		return status.Error(codes.Unavailable, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// Register регистрирует игрока и открывает сессию
+func (gs *GRPCServer) Register(ctx context.Context, req *gamepb.RegisterRequest) (*gamepb.Session, error) {
+	if req.GetPlayerId() == "" || req.GetPlayerName() == "" || req.GetWorldId() == "" || req.GetPassword() == "" {
+		return nil, status.Error(codes.InvalidArgument, "player_id, player_name, world_id and password are required")
+	}
+	player, err := gs.service.RegisterPlayer(ctx, req.GetPlayerId(), req.GetPlayerName(), req.GetWorldId(), req.GetPassword())
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrWeakPassword):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, ErrPlayerExists):
+			return nil, status.Error(codes.AlreadyExists, err.Error())
+		case storage.IsUnavailable(err):
+			return nil, status.Errorf(codes.Unavailable, "failed to register player: %v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to register player: %v", err)
+	}
+	return gs.openSession(player, req.GetPlayerId(), req.GetWorldId())
+}
+
+// Login открывает сессию существующего игрока
+func (gs *GRPCServer) Login(ctx context.Context, req *gamepb.LoginRequest) (*gamepb.Session, error) {
+	if req.GetPlayerId() == "" || req.GetWorldId() == "" || req.GetPassword() == "" {
+		return nil, status.Error(codes.InvalidArgument, "player_id, world_id and password are required")
+	}
+	player, err := gs.service.LoginPlayer(ctx, req.GetPlayerId(), req.GetWorldId(), req.GetPassword())
+	if err != nil {
+		if errors.Is(err, ErrInvalidCredentials) {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		if storage.IsUnavailable(err) {
+			return nil, status.Errorf(codes.Unavailable, "failed to login player: %v", err)
+		}
+		return nil, status.Errorf(codes.NotFound, "failed to login player: %v", err)
+	}
+	return gs.openSession(player, req.GetPlayerId(), req.GetWorldId())
+}
+
+func (gs *GRPCServer) openSession(player *entity.Entity, playerID, worldID string) (*gamepb.Session, error) {
+	session, err := gs.service.issueSession(player, playerID, worldID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	pbPlayer, err := toPBEntity(session.Player, worldID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &gamepb.Session{Player: pbPlayer, Token: session.Token, ExpiresAt: timestamppb.New(session.ExpiresAt)}, nil
+}
+
+// GetEntity возвращает сущность мира из кэша или MinIO
+func (gs *GRPCServer) GetEntity(ctx context.Context, req *gamepb.GetEntityRequest) (*gamepb.Entity, error) {
+	if req.GetEntityId() == "" || req.GetWorldId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "entity_id and world_id are required")
+	}
+	ent, err := gs.service.GetEntity(ctx, req.GetEntityId(), req.GetWorldId())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	pbEntity, err := toPBEntity(ent, req.GetWorldId())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return pbEntity, nil
+}
+
+// PerformAction проверяет и публикует команду игрока
+func (gs *GRPCServer) PerformAction(ctx context.Context, req *gamepb.ActionCommand) (*gamepb.ActionResult, error) {
+	cmd := ActionCommand{
+		PlayerID:   req.GetPlayerId(),
+		WorldID:    req.GetWorldId(),
+		Command:    req.GetCommand(),
+		LocationID: req.GetLocationId(),
+		SkillID:    req.GetSkillId(),
+		ItemID:     req.GetItemId(),
+		TargetID:   req.GetTargetId(),
+		NPCID:      req.GetNpcId(),
+		QuestID:    req.GetQuestId(),
+		Message:    req.GetMessage(),
+	}
+	if location := req.GetLocation(); location != nil {
+		cmd.Location = &ActionPoint{X: location.GetX(), Y: location.GetY()}
+	}
+
+	result, err := gs.service.PerformAction(ctx, cmd)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	response := &gamepb.ActionResult{
+		EventId:       result.EventID,
+		EventType:     result.EventType,
+		Verdict:       result.Verdict,
+		ViolationType: result.ViolationType,
+	}
+	if journey := result.Travel; journey != nil {
+		response.Travel = &gamepb.Travel{
+			From:           toPBPoint(journey.From),
+			To:             toPBPoint(journey.To),
+			Distance:       journey.Distance,
+			StartedAt:      timestamppb.New(journey.StartedAt),
+			ArrivesAt:      timestamppb.New(journey.ArrivesAt),
+			WorldDurationS: journey.WorldDuration.Seconds(),
+		}
+		for _, waypoint := range journey.Waypoints {
+			response.Travel.Waypoints = append(response.Travel.Waypoints, toPBPoint(waypoint))
+		}
+	}
+	return response, nil
+}
+
+// Subscribe передаёт события, которые рассылаются клиентам WebSocket, пока клиент не отключится.
+// Без подписок в запросе передаются все события.
+func (gs *GRPCServer) Subscribe(req *gamepb.SubscribeRequest, stream gamepb.GameService_SubscribeServer) error {
+	client := &wsClient{subscriptions: make([]Subscription, 0, len(req.GetSubscriptions()))}
+	for _, sub := range req.GetSubscriptions() {
+		client.filtered = true
+		client.subscriptions = append(client.subscriptions, Subscription{
+			WorldID:    sub.GetWorldId(),
+			ScopeIDs:   sub.GetScopeIds(),
+			EventTypes: sub.GetEventTypes(),
+		})
+	}
+	subscriber := &grpcSubscriber{client: client, events: make(chan eventbus.Event, grpcSubscriberBuffer)}
+
+	gs.mutex.Lock()
+	gs.subscribers[subscriber] = struct{}{}
+	gs.mutex.Unlock()
+	defer func() {
+		gs.mutex.Lock()
+		delete(gs.subscribers, subscriber)
+		gs.mutex.Unlock()
+	}()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event := <-subscriber.events:
+			pbEvent, err := toPBEvent(event)
+			if err != nil {
+				logging.Errorf("Failed to convert event %s for gRPC subscriber: %v", event.ID, err)
+				continue
+			}
+			if err := stream.Send(pbEvent); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Broadcast передаёт сообщение рассылки WebSocket подписчикам Subscribe.
+// Медленный подписчик не задерживает рассылку: события сверх его буфера отбрасываются.
+func (gs *GRPCServer) Broadcast(message []byte) {
+	var event eventbus.Event
+	if err := json.Unmarshal(message, &event); err != nil {
+		return
+	}
+
+	gs.mutex.Lock()
+	defer gs.mutex.Unlock()
+	for subscriber := range gs.subscribers {
+		if !subscriber.client.accepts(event) {
+			continue
+		}
+		select {
+		case subscriber.events <- event:
+		default:
+			logging.Infof("gRPC subscriber is too slow, dropping event %s", event.ID)
+		}
+	}
+}
+
+func toPBPoint(p ActionPoint) *gamepb.Point {
+	return &gamepb.Point{X: p.X, Y: p.Y}
+}
+
+// toStruct переводит payload в google.protobuf.Struct через JSON:
+// в payload бывают типы, которых structpb.NewStruct не принимает (int64, []string, time.Time)
+func toStruct(payload map[string]interface{}) (*structpb.Struct, error) {
+	if payload == nil {
+		return &structpb.Struct{}, nil
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	result := &structpb.Struct{}
+	if err := protojson.Unmarshal(data, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func toPBEntity(ent *entity.Entity, worldID string) (*gamepb.Entity, error) {
+	payload, err := toStruct(ent.Payload)
+	if err != nil {
+		return nil, err
+	}
+	if ent.World != nil && ent.World.ID != "" {
+		worldID = ent.World.ID
+	}
+	return &gamepb.Entity{
+		Id:        ent.ID,
+		Type:      ent.Type,
+		WorldId:   worldID,
+		CreatedAt: timestamppb.New(ent.CreatedAt),
+		UpdatedAt: timestamppb.New(ent.UpdatedAt),
+		Payload:   payload,
+	}, nil
+}
+
+func toPBEvent(event eventbus.Event) (*gamepb.Event, error) {
+	payload, err := toStruct(event.Payload)
+	if err != nil {
+		return nil, err
+	}
+	result := &gamepb.Event{
+		Id:        event.ID,
+		Type:      event.Type,
+		Source:    event.Source,
+		WorldId:   eventbus.GetWorldIDFromEvent(event),
+		Timestamp: timestamppb.New(event.Timestamp),
+		Payload:   payload,
+	}
+	if scope := eventbus.GetScopeFromEvent(event); scope != nil {
+		result.Scope = &gamepb.Scope{Id: scope.ID, Type: scope.Type}
+	}
+	return result, nil
+}
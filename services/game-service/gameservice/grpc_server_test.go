@@ -0,0 +1,114 @@
+package gameservice
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"multiverse-core.io/services/game-service/gameservice/gamepb"
+	"multiverse-core.io/shared/entity"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// startTestGRPCServer запускает gRPC API сервиса в памяти и возвращает клиент
+func startTestGRPCServer(t *testing.T, service *Service) (*GRPCServer, gamepb.GameServiceClient) {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := NewGRPCServer(service, "")
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return server, gamepb.NewGameServiceClient(conn)
+}
+
+func TestGRPCServer(t *testing.T) {
+	service := &Service{
+		entityCache: NewEntityCache(time.Minute),
+		sessions:    NewSessionManager("secret", time.Hour),
+	}
+	service.entityCache.Set("npc-1", "world-1", entity.NewEntity("npc-1", "npc", map[string]interface{}{
+		"name":  "Стражник",
+		"level": int64(3),
+	}))
+	server, client := startTestGRPCServer(t, service)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// GetEntity доступен без сессии, как GET /entities/{id}
+	npc, err := client.GetEntity(ctx, &gamepb.GetEntityRequest{EntityId: "npc-1", WorldId: "world-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if npc.GetType() != "npc" || npc.GetPayload().GetFields()["level"].GetNumberValue() != 3 {
+		t.Errorf("unexpected entity %v", npc)
+	}
+	if _, err := client.GetEntity(ctx, &gamepb.GetEntityRequest{EntityId: "npc-2", WorldId: "world-1"}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound, got %v", err)
+	}
+
+	// Команды и подписка требуют токен
+	if _, err := client.PerformAction(ctx, &gamepb.ActionCommand{Command: CommandMove}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected Unauthenticated, got %v", err)
+	}
+	token, _, err := service.sessions.Issue("kain", "world-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	authCtx := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+	if _, err := client.PerformAction(authCtx, &gamepb.ActionCommand{PlayerId: "abel", Command: CommandMove}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied for another player, got %v", err)
+	}
+
+	stream, err := client.Subscribe(authCtx, &gamepb.SubscribeRequest{Subscriptions: []*gamepb.Subscription{
+		{WorldId: "world-1", EventTypes: []string{"narrative.*"}},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Поток регистрируется на сервере асинхронно
+	for deadline := time.Now().Add(2 * time.Second); ; {
+		server.mutex.Lock()
+		subscribed := len(server.subscribers) == 1
+		server.mutex.Unlock()
+		if subscribed {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("subscriber was not registered")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	for _, event := range []interface{}{
+		scopedEvent("player.moved", "world-1", "kain"),
+		scopedEvent("narrative.generate", "world-2", "kain"),
+		scopedEvent("narrative.generate", "world-1", "kain"),
+	} {
+		message, _ := json.Marshal(event)
+		server.Broadcast(message)
+	}
+
+	received, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if received.GetType() != "narrative.generate" || received.GetWorldId() != "world-1" || received.GetScope().GetId() != "kain" {
+		t.Errorf("expected only the matching narrative event, got %v", received)
+	}
+}
@@ -2,12 +2,15 @@ package gameservice
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"time"
 
+	"multiverse-core.io/shared/entity"
 	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
 
 	"github.com/gorilla/mux"
 )
@@ -29,7 +32,7 @@ func NewEntityStreamHandler(entityCache *EntityCache, broadcast chan []byte, min
 
 func (h *EntityStreamHandler) HandleEntityEvent(event eventbus.Event) {
 	// Обработка событий, связанных с сущностями
-	log.Printf("Handling entity event: %s", event.Type)
+	logging.Infof("Handling entity event: %s", event.Type)
 
 	// TODO: Реализовать логику обработки событий сущностей
 	// Это может включать:
@@ -62,7 +65,7 @@ func NewEventStreamHandler(broadcast chan []byte) *EventStreamHandler {
 
 func (h *EventStreamHandler) HandleGameEvent(event eventbus.Event) {
 	// Обработка игровых событий
-	log.Printf("Handling game event: %s", event.Type)
+	logging.Infof("Handling game event: %s", event.Type)
 
 	// Добавляем событие в буфер
 	h.eventBuffer = append(h.eventBuffer, event)
@@ -99,32 +102,41 @@ func (s *Service) GetEntityHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Пытаемся получить сущность из кэша
-	if entity, found := s.entityCache.Get(entityID, worldID); found {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(entity)
-		return
-	}
-
-	// Если сущности нет в кэше и доступен MinIO клиент, загружаем из MinIO
-	if s.minioClient != nil {
-		entity, err := s.minioClient.LoadEntity(r.Context(), entityID, worldID)
-		if err != nil {
+	// Сущность из кэша или MinIO
+	entity, err := s.GetEntity(r.Context(), entityID, worldID)
+	if err != nil {
+		switch {
+		case storage.IsNotFound(err):
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("Entity not found"))
+		case storage.IsUnavailable(err):
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(fmt.Sprintf("Storage unavailable: %v", err)))
+		default:
 			w.WriteHeader(http.StatusInternalServerError)
 			w.Write([]byte(fmt.Sprintf("Failed to load entity: %v", err)))
-			return
 		}
-
-		// Сохраняем в кэш
-		s.entityCache.Set(entityID, worldID, entity)
-
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(entity)
 		return
 	}
 
-	w.WriteHeader(http.StatusNotFound)
-	w.Write([]byte("Entity not found"))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entity)
+}
+
+// SessionGrant — игрок и токен открытой для него сессии
+type SessionGrant struct {
+	Player    *entity.Entity `json:"player"`
+	Token     string         `json:"token"`
+	ExpiresAt time.Time      `json:"expires_at"`
+}
+
+// issueSession открывает сессию игрока после регистрации или входа (REST и gRPC)
+func (s *Service) issueSession(player *entity.Entity, playerID, worldID string) (*SessionGrant, error) {
+	token, claims, err := s.sessions.Issue(playerID, worldID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+	return &SessionGrant{Player: player, Token: token, ExpiresAt: time.Unix(claims.ExpiresAt, 0).UTC()}, nil
 }
 
 func (s *Service) RegisterPlayerHandler(w http.ResponseWriter, r *http.Request) {
@@ -133,6 +145,7 @@ func (s *Service) RegisterPlayerHandler(w http.ResponseWriter, r *http.Request)
 		PlayerID   string `json:"player_id"`
 		PlayerName string `json:"player_name"`
 		WorldID    string `json:"world_id"`
+		Password   string `json:"password"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -142,26 +155,44 @@ func (s *Service) RegisterPlayerHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Проверяем обязательные поля
-	if req.PlayerID == "" || req.PlayerName == "" || req.WorldID == "" {
+	if req.PlayerID == "" || req.PlayerName == "" || req.WorldID == "" || req.Password == "" {
 		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte("player_id, player_name and world_id are required"))
+		w.Write([]byte("player_id, player_name, world_id and password are required"))
 		return
 	}
 
 	// Регистрируем игрока
-	entity, err := s.RegisterPlayer(r.Context(), req.PlayerID, req.PlayerName, req.WorldID)
+	entity, err := s.RegisterPlayer(r.Context(), req.PlayerID, req.PlayerName, req.WorldID, req.Password)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		switch {
+		case errors.Is(err, ErrWeakPassword):
+			w.WriteHeader(http.StatusBadRequest)
+		case errors.Is(err, ErrPlayerExists):
+			w.WriteHeader(http.StatusConflict)
+		case storage.IsUnavailable(err):
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
 		w.Write([]byte(fmt.Sprintf("Failed to register player: %v", err)))
 		return
 	}
 
+	session, err := s.issueSession(entity, req.PlayerID, req.WorldID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
 	// Возвращаем успешный ответ
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"message": "Player registered successfully",
-		"player":  entity,
+		"message":    "Player registered successfully",
+		"player":     session.Player,
+		"token":      session.Token,
+		"expires_at": session.ExpiresAt,
 	})
 }
 
@@ -170,6 +201,7 @@ func (s *Service) LoginPlayerHandler(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		PlayerID string `json:"player_id"`
 		WorldID  string `json:"world_id"`
+		Password string `json:"password"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -179,47 +211,42 @@ func (s *Service) LoginPlayerHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Проверяем обязательные поля
-	if req.PlayerID == "" || req.WorldID == "" {
+	if req.PlayerID == "" || req.WorldID == "" || req.Password == "" {
 		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte("player_id and world_id are required"))
+		w.Write([]byte("player_id, world_id and password are required"))
 		return
 	}
 
 	// Входим как игрок
-	entity, err := s.LoginPlayer(r.Context(), req.PlayerID, req.WorldID)
+	entity, err := s.LoginPlayer(r.Context(), req.PlayerID, req.WorldID, req.Password)
 	if err != nil {
-		w.WriteHeader(http.StatusNotFound)
+		switch {
+		case errors.Is(err, ErrInvalidCredentials):
+			w.WriteHeader(http.StatusUnauthorized)
+		case storage.IsUnavailable(err):
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
 		w.Write([]byte(fmt.Sprintf("Failed to login player: %v", err)))
 		return
 	}
 
-	// Возвращаем успешный ответ
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"message": "Player logged in successfully",
-		"player":  entity,
-	})
-}
-
-func (s *Service) GetEntityHistoryHandler(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	entityID := vars["entity_id"]
-
-	// TODO: Реализовать получение истории сущности по ID
-	// Это может включать запрос к EntityManager или другому сервису
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"entity_id": entityID,
-		"history":   []interface{}{}, // Заглушка
-	})
-}
+	// Токен сессии подтверждает личность игрока в действиях и WebSocket
+	session, err := s.issueSession(entity, req.PlayerID, req.WorldID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+		return
+	}
 
-func (s *Service) GetRecentEventsHandler(w http.ResponseWriter, r *http.Request) {
-	// TODO: Реализовать получение последних событий
-	// Это может включать запрос к Event Bus или другому сервису
+	// Возвращаем успешный ответ
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"events": []interface{}{}, // Заглушка
+		"message":    "Player logged in successfully",
+		"player":     session.Player,
+		"token":      session.Token,
+		"expires_at": session.ExpiresAt,
 	})
 }
 
@@ -256,7 +283,7 @@ func (s *Service) RunTestHandler(w http.ResponseWriter, r *http.Request) {
 			err = s.bus.PublishGameEvent(ctx, ev)
 		}
 		if err != nil {
-			log.Printf("Failed to publish %s event %s: %v", topic, ev.Type, err)
+			logging.Errorf("Failed to publish %s event %s: %v", topic, ev.Type, err)
 		} else {
 			results = append(results, map[string]interface{}{
 				"step":       len(results) + 1,
@@ -2,10 +2,11 @@ package gameservice
 
 import (
 	"context"
-	"log"
 	"net/http"
 	"time"
 
+	"multiverse-core.io/shared/logging"
+
 	"github.com/gorilla/mux"
 )
 
@@ -35,9 +36,9 @@ func NewHTTPServer(addr string) *HTTPServer {
 func (hs *HTTPServer) Start() {
 	// Запуск HTTP сервера в отдельной горутине
 	go func() {
-		log.Printf("HTTP server starting on %s", hs.server.Addr)
+		logging.Infof("HTTP server starting on %s", hs.server.Addr)
 		if err := hs.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Printf("HTTP server error: %v", err)
+			logging.Errorf("HTTP server error: %v", err)
 		}
 	}()
 }
@@ -47,22 +48,56 @@ func (hs *HTTPServer) Stop() {
 	defer cancel()
 	
 	if err := hs.server.Shutdown(ctx); err != nil {
-		log.Printf("HTTP server shutdown error: %v", err)
+		logging.Errorf("HTTP server shutdown error: %v", err)
 	}
-	log.Println("HTTP server stopped")
+	logging.Infof("HTTP server stopped")
 }
 
 func (hs *HTTPServer) RegisterRoutes(service *Service, wsServer *WebSocketServer) {
+	// Действия игрока и WebSocket требуют токен сессии из /players/login
+	auth := service.sessions.RequireSession
+	// Лимиты частоты по классам эндпоинтов: за auth лимит считается на игрока сессии, иначе на IP
+	limit := service.rateLimiter.Limit
+
 	// WebSocket endpoints
-	hs.router.HandleFunc("/ws/entities", wsServer.HandleWebSocket)
-	hs.router.HandleFunc("/ws/events", wsServer.HandleWebSocket)
-	hs.router.HandleFunc("/ws/actions", wsServer.HandleWebSocket)
+	hs.router.HandleFunc("/ws/entities", auth(limit(RateClassWebSocket, wsServer.HandleWebSocket)))
+	hs.router.HandleFunc("/ws/events", auth(limit(RateClassWebSocket, wsServer.HandleWebSocket)))
+	hs.router.HandleFunc("/ws/actions", auth(limit(RateClassWebSocket, wsServer.HandleWebSocket)))
 
 	// REST API endpoints
-	hs.router.HandleFunc("/entities/{entity_id}", service.GetEntityHandler).Methods("GET")
-	hs.router.HandleFunc("/players/register", service.RegisterPlayerHandler).Methods("POST")
-	hs.router.HandleFunc("/players/login", service.LoginPlayerHandler).Methods("POST")
-	hs.router.HandleFunc("/entities/{entity_id}/history", service.GetEntityHistoryHandler).Methods("GET")
-	hs.router.HandleFunc("/events/recent", service.GetRecentEventsHandler).Methods("GET")
-	hs.router.HandleFunc("/run_test", service.RunTestHandler).Methods("GET")
+	hs.router.HandleFunc("/entities/{entity_id}", limit(RateClassRead, service.GetEntityHandler)).Methods("GET")
+	hs.router.HandleFunc("/players/register", limit(RateClassAuth, service.RegisterPlayerHandler)).Methods("POST")
+	hs.router.HandleFunc("/players/login", limit(RateClassAuth, service.LoginPlayerHandler)).Methods("POST")
+	hs.router.HandleFunc("/entities/{entity_id}/history", limit(RateClassRead, service.GetEntityHistoryHandler)).Methods("GET")
+	hs.router.HandleFunc("/events/recent", limit(RateClassRead, service.GetRecentEventsHandler)).Methods("GET")
+	hs.router.HandleFunc("/v1/cache/stats", service.GetCacheStatsHandler).Methods("GET")
+	// Тестовый сценарий публикует события от имени произвольных игроков: только с токеном движка
+	hs.router.HandleFunc("/run_test", RequireEngine(service.cfg.EngineTokens, service.RunTestHandler)).Methods("GET")
+
+	// Команды игрока
+	hs.router.HandleFunc("/v1/actions", auth(limit(RateClassActions, service.PerformActionHandler))).Methods("POST")
+
+	// Пакетная отправка действий, накопленных клиентом офлайн
+	hs.router.HandleFunc("/v1/actions/batch", auth(limit(RateClassActions, service.BatchActionsHandler))).Methods("POST")
+
+	// Изменения состояния сущностей от внешних игровых движков: токен движка, а не сессия игрока
+	hs.router.HandleFunc("/v1/state-changes", RequireEngine(service.cfg.EngineTokens, service.StateChangesHandler)).Methods("POST")
+
+	// Журнал повествования scope с постраничной выдачей
+	hs.router.HandleFunc("/v1/narratives", limit(RateClassRead, service.GetNarrativesHandler)).Methods("GET")
+
+	// Точки выбора повествования
+	hs.router.HandleFunc("/v1/choices", limit(RateClassRead, service.GetOpenChoicesHandler)).Methods("GET")
+	hs.router.HandleFunc("/v1/choices/{choice_id}/select", auth(limit(RateClassActions, service.SelectChoiceHandler))).Methods("POST")
+
+	// Медиа-ассеты: presigned-загрузка в MinIO и раздача с кэшированием
+	hs.router.HandleFunc("/v1/assets/uploads", auth(limit(RateClassActions, service.CreateAssetUploadHandler))).Methods("POST")
+	hs.router.HandleFunc("/v1/assets/uploads/complete", auth(limit(RateClassActions, service.CompleteAssetUploadHandler))).Methods("POST")
+	hs.router.HandleFunc("/v1/assets/{asset_key:.+}", limit(RateClassRead, service.GetAssetHandler)).Methods("GET")
+
+	// Публичное read-only API для витрины миров (без аутентификации)
+	hs.router.HandleFunc("/public/worlds/{world_id}", limit(RateClassRead, service.GetPublicWorldSummaryHandler)).Methods("GET")
+	hs.router.HandleFunc("/public/worlds/{world_id}/map", limit(RateClassRead, service.GetPublicWorldMapHandler)).Methods("GET")
+	hs.router.HandleFunc("/public/worlds/{world_id}/chronicles", limit(RateClassRead, service.GetPublicChroniclesHandler)).Methods("GET")
+	hs.router.HandleFunc("/public/worlds/{world_id}/factions", limit(RateClassRead, service.GetPublicFactionsHandler)).Methods("GET")
 }
\ No newline at end of file
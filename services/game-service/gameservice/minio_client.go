@@ -4,9 +4,15 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
 	"os"
+	"strings"
+	"time"
 
 	"multiverse-core.io/shared/entity"
+	storage "multiverse-core.io/shared/minio"
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
@@ -36,32 +42,34 @@ func NewMinioClient() (*MinioClient, error) {
 	return &MinioClient{client: minioClient}, nil
 }
 
+// LoadEntity загружает сущность из бакета мира, затем из глобального.
+// Ошибка оборачивает storage.ErrNotFound, если сущности нет ни в одном бакете,
+// или storage.ErrUnavailable, если MinIO недоступен.
 func (mc *MinioClient) LoadEntity(ctx context.Context, entityID, worldID string) (*entity.Entity, error) {
 	// Try world-specific bucket
-	bucket := "entities-" + worldID
-	obj, err := mc.client.GetObject(ctx, bucket, entityID+".json", minio.GetObjectOptions{})
-	if err == nil {
-		defer obj.Close()
-		var ent entity.Entity
-		if err := json.NewDecoder(obj).Decode(&ent); err != nil {
-			return nil, err
-		}
-		return &ent, nil
+	ent, err := mc.loadEntityFromBucket(ctx, "entities-"+worldID, entityID)
+	if err == nil || !storage.IsNotFound(err) {
+		return ent, err
 	}
 
 	// Try global bucket
-	bucket = "entities-global"
-	obj, err = mc.client.GetObject(ctx, bucket, entityID+".json", minio.GetObjectOptions{})
-	if err == nil {
-		defer obj.Close()
-		var ent entity.Entity
-		if err := json.NewDecoder(obj).Decode(&ent); err != nil {
-			return nil, err
-		}
-		return &ent, nil
+	return mc.loadEntityFromBucket(ctx, "entities-global", entityID)
+}
+
+// loadEntityFromBucket читает и декодирует один объект сущности
+func (mc *MinioClient) loadEntityFromBucket(ctx context.Context, bucket, entityID string) (*entity.Entity, error) {
+	obj, err := mc.client.GetObject(ctx, bucket, entityID+".json", minio.GetObjectOptions{})
+	if err != nil {
+		return nil, storage.ClassifyError(err)
 	}
+	defer obj.Close()
 
-	return nil, err // not found
+	// GetObject ленивый: отсутствие ключа проявляется только при чтении
+	var ent entity.Entity
+	if err := json.NewDecoder(obj).Decode(&ent); err != nil {
+		return nil, storage.ClassifyError(err)
+	}
+	return &ent, nil
 }
 
 func (mc *MinioClient) SaveEntity(ctx context.Context, ent *entity.Entity, worldID string) error {
@@ -89,3 +97,124 @@ func (mc *MinioClient) SaveEntity(ctx context.Context, ent *entity.Entity, world
 		minio.PutObjectOptions{ContentType: "application/json"})
 	return err
 }
+
+// WorldsBucket — состояние миров WorldGenerator: {world_id}/world.json
+const WorldsBucket = "worlds"
+
+// LoadWorldGeography загружает географию и границы мира, сохранённые WorldGenerator.
+// Ошибка оборачивает storage.ErrNotFound, если мир не генерировался.
+func (mc *MinioClient) LoadWorldGeography(ctx context.Context, worldID string) (*WorldGeography, error) {
+	obj, err := mc.client.GetObject(ctx, WorldsBucket, worldID+"/world.json", minio.GetObjectOptions{})
+	if err != nil {
+		return nil, storage.ClassifyError(err)
+	}
+	defer obj.Close()
+
+	var geo WorldGeography
+	if err := json.NewDecoder(obj).Decode(&geo); err != nil {
+		return nil, storage.ClassifyError(err)
+	}
+	return &geo, nil
+}
+
+// ListEntities загружает все сущности из бакета мира
+func (mc *MinioClient) ListEntities(ctx context.Context, worldID string) ([]*entity.Entity, error) {
+	bucket := "entities-" + worldID
+	entities := make([]*entity.Entity, 0)
+
+	for info := range mc.client.ListObjects(ctx, bucket, minio.ListObjectsOptions{Recursive: true}) {
+		if info.Err != nil {
+			return nil, storage.ClassifyError(info.Err)
+		}
+		// Сущности лежат в корне бакета; вложенные ключи (_index/, _history/) — служебные объекты EntityManager
+		if !strings.HasSuffix(info.Key, ".json") || strings.Contains(info.Key, "/") {
+			continue
+		}
+
+		obj, err := mc.client.GetObject(ctx, bucket, info.Key, minio.GetObjectOptions{})
+		if err != nil {
+			return nil, storage.ClassifyError(err)
+		}
+		var ent entity.Entity
+		err = json.NewDecoder(obj).Decode(&ent)
+		obj.Close()
+		if err != nil {
+			err = storage.ClassifyError(err)
+			if storage.IsNotFound(err) {
+				// Сущность удалена между листингом и чтением
+				continue
+			}
+			return nil, fmt.Errorf("decode %s: %w", info.Key, err)
+		}
+		entities = append(entities, &ent)
+	}
+
+	return entities, nil
+}
+
+// ensureBucket создает бакет, если его ещё нет
+func (mc *MinioClient) ensureBucket(ctx context.Context, bucket string) error {
+	exists, err := mc.client.BucketExists(ctx, bucket)
+	if err != nil {
+		return storage.ClassifyError(err)
+	}
+	if !exists {
+		if err := mc.client.MakeBucket(ctx, bucket, minio.MakeBucketOptions{}); err != nil {
+			return storage.ClassifyError(err)
+		}
+	}
+	return nil
+}
+
+// PresignAssetUpload создает presigned POST-политику для загрузки ассета.
+// Политика закрепляет ключ, тип содержимого и предельный размер, поэтому MinIO сам отклонит чужой файл.
+func (mc *MinioClient) PresignAssetUpload(ctx context.Context, key, contentType string, maxSize int64, expires time.Duration) (*url.URL, map[string]string, error) {
+	if err := mc.ensureBucket(ctx, AssetsBucket); err != nil {
+		return nil, nil, err
+	}
+
+	policy := minio.NewPostPolicy()
+	policy.SetBucket(AssetsBucket)
+	policy.SetKey(key)
+	policy.SetContentType(contentType)
+	policy.SetContentLengthRange(1, maxSize)
+	policy.SetExpires(time.Now().UTC().Add(expires))
+
+	uploadURL, formData, err := mc.client.PresignedPostPolicy(ctx, policy)
+	if err != nil {
+		return nil, nil, storage.ClassifyError(err)
+	}
+	return uploadURL, formData, nil
+}
+
+// StatAsset возвращает метаданные загруженного ассета
+func (mc *MinioClient) StatAsset(ctx context.Context, key string) (minio.ObjectInfo, error) {
+	info, err := mc.client.StatObject(ctx, AssetsBucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return info, storage.ClassifyError(err)
+	}
+	return info, nil
+}
+
+// OpenAsset открывает ассет для чтения вместе с его метаданными
+func (mc *MinioClient) OpenAsset(ctx context.Context, key string) (io.ReadCloser, minio.ObjectInfo, error) {
+	obj, err := mc.client.GetObject(ctx, AssetsBucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, minio.ObjectInfo{}, storage.ClassifyError(err)
+	}
+	// GetObject ленивый: отсутствие ключа проявляется только при Stat/чтении
+	info, err := obj.Stat()
+	if err != nil {
+		obj.Close()
+		return nil, info, storage.ClassifyError(err)
+	}
+	return obj, info, nil
+}
+
+// RemoveAsset удаляет ассет (например, не прошедший проверку после загрузки)
+func (mc *MinioClient) RemoveAsset(ctx context.Context, key string) error {
+	if err := mc.client.RemoveObject(ctx, AssetsBucket, key, minio.RemoveObjectOptions{}); err != nil {
+		return storage.ClassifyError(err)
+	}
+	return nil
+}
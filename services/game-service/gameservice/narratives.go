@@ -0,0 +1,244 @@
+package gameservice
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+)
+
+// DefaultNarrativeBucket — бакет архива повествования
+const DefaultNarrativeBucket = "narratives"
+
+// Ограничения страницы GET /v1/narratives
+const (
+	defaultNarrativesLimit = 20
+	maxNarrativesLimit     = 100
+	// narrativeCursorDigits — ширина метки времени в имени записи, чтобы имена сортировались по времени
+	narrativeCursorDigits = 20
+)
+
+// ErrInvalidNarrativeCursor — параметр before не является курсором или временем RFC3339 (400)
+var ErrInvalidNarrativeCursor = errors.New("invalid narrative cursor")
+
+// NarrativeEntry — одна запись журнала повествования scope
+type NarrativeEntry struct {
+	// Cursor — положение записи в журнале; передаётся в before, чтобы получить записи старше неё
+	Cursor    string    `json:"cursor"`
+	EventID   string    `json:"event_id"`
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	WorldID   string    `json:"world_id,omitempty"`
+	ScopeID   string    `json:"scope_id"`
+	ScopeType string    `json:"scope_type,omitempty"`
+	Narrative string    `json:"narrative"`
+	Mood      string    `json:"mood,omitempty"`
+}
+
+// NarrativePage — страница журнала, от новых записей к старым
+type NarrativePage struct {
+	Narratives []NarrativeEntry `json:"narratives"`
+	// NextBefore — курсор следующей (более старой) страницы; пусто — записей больше нет
+	NextBefore string `json:"next_before,omitempty"`
+}
+
+// NarrativeArchive хранит повествование из narrative_output в MinIO: по объекту на событие,
+// сгруппированные по scope, чтобы переподключившийся игрок мог прочитать свою историю
+type NarrativeArchive struct {
+	objects storage.ObjectStorage
+	bucket  string
+}
+
+// NewNarrativeArchive создает архив в бакете (DefaultNarrativeBucket, если пусто)
+func NewNarrativeArchive(objects storage.ObjectStorage, bucket string) *NarrativeArchive {
+	if bucket == "" {
+		bucket = DefaultNarrativeBucket
+	}
+	return &NarrativeArchive{objects: objects, bucket: bucket}
+}
+
+// narrativeText возвращает текст повествования события: narrative или description
+func narrativeText(event eventbus.Event) string {
+	narrative, ok := event.Path().GetString("narrative")
+	if !ok || narrative == "" {
+		narrative, _ = event.Path().GetString("description")
+	}
+	return narrative
+}
+
+// narrativePrefix — префикс записей scope; scope_id экранируется, чтобы "/" не создавал вложенных префиксов
+func narrativePrefix(scopeID string) string {
+	return url.PathEscape(scopeID) + "/"
+}
+
+// narrativeCursor — имя записи: метка времени фиксированной ширины и ID события.
+// Имена сортируются по времени, а повторно полученное событие перезаписывает ту же запись.
+func narrativeCursor(timestamp time.Time, eventID string) string {
+	return fmt.Sprintf("%0*d-%s", narrativeCursorDigits, timestamp.UnixNano(), eventID)
+}
+
+// Record сохраняет повествовательное событие в журнал его scope.
+// События без текста или без scope не попадают ни в один журнал и пропускаются.
+func (a *NarrativeArchive) Record(event eventbus.Event) {
+	narrative := narrativeText(event)
+	scope := eventbus.GetScopeFromEvent(event)
+	if narrative == "" || scope == nil || scope.ID == "" {
+		return
+	}
+
+	timestamp := event.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	entry := NarrativeEntry{
+		Cursor:    narrativeCursor(timestamp, event.ID),
+		EventID:   event.ID,
+		Type:      event.Type,
+		Timestamp: timestamp.UTC(),
+		WorldID:   eventbus.GetWorldIDFromEvent(event),
+		ScopeID:   scope.ID,
+		ScopeType: scope.Type,
+		Narrative: narrative,
+	}
+	entry.Mood, _ = event.Path().GetString("mood")
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		logging.Errorf("Failed to encode narrative %s: %v", event.ID, err)
+		return
+	}
+	key := narrativePrefix(scope.ID) + entry.Cursor + ".json"
+	if err := a.objects.PutObject(a.bucket, key, bytes.NewReader(data), int64(len(data))); err != nil {
+		logging.Errorf("Failed to archive narrative %s of scope %s: %v", event.ID, scope.ID, err)
+	}
+}
+
+// parseNarrativeCursor принимает курсор из NarrativeEntry или время RFC3339
+func parseNarrativeCursor(before string) (string, error) {
+	if before == "" {
+		return "", nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, before); err == nil {
+		// Курсор-метка без ID меньше имени любой записи того же момента: она не попадёт в страницу
+		return fmt.Sprintf("%0*d", narrativeCursorDigits, t.UnixNano()), nil
+	}
+	digits, _, _ := strings.Cut(before, "-")
+	if len(digits) != narrativeCursorDigits {
+		return "", fmt.Errorf("%w: %q", ErrInvalidNarrativeCursor, before)
+	}
+	if _, err := strconv.ParseUint(digits, 10, 64); err != nil {
+		return "", fmt.Errorf("%w: %q", ErrInvalidNarrativeCursor, before)
+	}
+	return before, nil
+}
+
+// Page возвращает до limit записей scope, более старых, чем курсор before (пусто — самые новые)
+func (a *NarrativeArchive) Page(scopeID, before string, limit int) (*NarrativePage, error) {
+	cursor, err := parseNarrativeCursor(before)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultNarrativesLimit
+	}
+	if limit > maxNarrativesLimit {
+		limit = maxNarrativesLimit
+	}
+
+	prefix := narrativePrefix(scopeID)
+	objects, err := a.objects.ListObjects(a.bucket, prefix)
+	if storage.IsNotFound(err) {
+		return &NarrativePage{Narratives: []NarrativeEntry{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cursors := make([]string, 0, len(objects))
+	for _, object := range objects {
+		name := strings.TrimSuffix(strings.TrimPrefix(object.Key, prefix), ".json")
+		if cursor == "" || name < cursor {
+			cursors = append(cursors, name)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(cursors)))
+
+	page := &NarrativePage{Narratives: make([]NarrativeEntry, 0, limit)}
+	for _, name := range cursors {
+		if len(page.Narratives) == limit {
+			page.NextBefore = page.Narratives[limit-1].Cursor
+			break
+		}
+		data, err := a.objects.GetObject(a.bucket, prefix+name+".json")
+		if storage.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var entry NarrativeEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			logging.Warnf("Skipping corrupted narrative %s%s: %v", prefix, name, err)
+			continue
+		}
+		page.Narratives = append(page.Narratives, entry)
+	}
+	return page, nil
+}
+
+// GetNarrativesHandler обрабатывает GET /v1/narratives?scope_id=...&before=...&limit=... —
+// журнал повествования scope от новых записей к старым
+func (s *Service) GetNarrativesHandler(w http.ResponseWriter, r *http.Request) {
+	if s.narratives == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("Narrative archive is not configured"))
+		return
+	}
+
+	query := r.URL.Query()
+	scopeID := query.Get("scope_id")
+	if scopeID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("scope_id is required"))
+		return
+	}
+	limit := 0
+	if raw := query.Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("limit must be a positive integer"))
+			return
+		}
+		limit = parsed
+	}
+
+	page, err := s.narratives.Page(scopeID, query.Get("before"), limit)
+	switch {
+	case errors.Is(err, ErrInvalidNarrativeCursor):
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return
+	case storage.IsUnavailable(err):
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(fmt.Sprintf("Failed to read narratives: %v", err)))
+		return
+	case err != nil:
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(fmt.Sprintf("Failed to read narratives: %v", err)))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
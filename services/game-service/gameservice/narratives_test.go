@@ -0,0 +1,86 @@
+package gameservice
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/minio/miniotest"
+)
+
+func narrativeEvent(id, scopeID, narrative string, at time.Time) eventbus.Event {
+	event := eventbus.NewEvent("narrative.generate", "narrative-orchestrator", "world-1", map[string]interface{}{
+		"narrative": narrative,
+	})
+	event.ID = id
+	event.Timestamp = at
+	if scopeID != "" {
+		eventbus.SetNested(event.Payload, "scope.id", scopeID)
+		eventbus.SetNested(event.Payload, "scope.type", "player")
+	}
+	return event
+}
+
+func TestNarrativeArchivePages(t *testing.T) {
+	objects := miniotest.New()
+	archive := NewNarrativeArchive(objects, "")
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 5; i++ {
+		archive.Record(narrativeEvent(fmt.Sprintf("ev-%d", i), "player:kain", fmt.Sprintf("Глава %d", i), start.Add(time.Duration(i)*time.Minute)))
+	}
+	archive.Record(narrativeEvent("other", "player:abel", "Чужая история", start))
+	archive.Record(narrativeEvent("unscoped", "", "Без scope", start))
+	// Повторная доставка события не дублирует запись
+	archive.Record(narrativeEvent("ev-4", "player:kain", "Глава 4", start.Add(4*time.Minute)))
+
+	if puts := objects.Puts(DefaultNarrativeBucket); len(puts) != 7 {
+		t.Fatalf("expected 7 writes, got %v", puts)
+	}
+
+	page, err := archive.Page("player:kain", "", 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page.Narratives) != 2 || page.Narratives[0].EventID != "ev-4" || page.Narratives[1].EventID != "ev-3" {
+		t.Fatalf("expected newest narratives first, got %+v", page.Narratives)
+	}
+	if page.NextBefore != page.Narratives[1].Cursor {
+		t.Errorf("expected next cursor %s, got %s", page.Narratives[1].Cursor, page.NextBefore)
+	}
+
+	var ids []string
+	for before := page.NextBefore; before != ""; {
+		next, err := archive.Page("player:kain", before, 2)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, entry := range next.Narratives {
+			ids = append(ids, entry.EventID)
+		}
+		before = next.NextBefore
+	}
+	if fmt.Sprint(ids) != "[ev-2 ev-1 ev-0]" {
+		t.Errorf("expected remaining pages ev-2..ev-0, got %v", ids)
+	}
+
+	// before принимает и время: записи строго раньше него
+	page, err = archive.Page("player:kain", start.Add(time.Minute).Format(time.RFC3339), 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page.Narratives) != 1 || page.Narratives[0].EventID != "ev-0" || page.NextBefore != "" {
+		t.Errorf("expected only ev-0 before 12:01, got %+v", page)
+	}
+
+	if _, err := archive.Page("player:kain", "yesterday", 10); !errors.Is(err, ErrInvalidNarrativeCursor) {
+		t.Errorf("expected ErrInvalidNarrativeCursor, got %v", err)
+	}
+
+	page, err = archive.Page("player:nobody", "", 10)
+	if err != nil || len(page.Narratives) != 0 {
+		t.Errorf("expected empty page for unknown scope, got %+v, %v", page, err)
+	}
+}
@@ -2,46 +2,70 @@ package gameservice
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
 
 	"multiverse-core.io/shared/entity"
 	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/eventbus/events"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
 )
 
 // PlayerService управляет регистрацией и входом игроков
 type PlayerService struct {
 	entityCache *EntityCache
 	minioClient *MinioClient
+	credentials credentialStore
 	eventBus    *eventbus.EventBus
 }
 
 // NewPlayerService создает новый сервис управления игроками
 func NewPlayerService(entityCache *EntityCache, minioClient *MinioClient, eventBus *eventbus.EventBus) *PlayerService {
-	return &PlayerService{
+	ps := &PlayerService{
 		entityCache: entityCache,
 		minioClient: minioClient,
 		eventBus:    eventBus,
 	}
+	if minioClient != nil {
+		ps.credentials = minioClient
+	}
+	return ps
 }
 
-// RegisterPlayer регистрирует нового игрока, создавая сущность если её нет
-func (ps *PlayerService) RegisterPlayer(ctx context.Context, playerID, playerName, worldID string) (*entity.Entity, error) {
-	// Пытаемся получить сущность игрока из кэша
-	if entity, found := ps.entityCache.Get(playerID, worldID); found {
-		return entity, nil
+// RegisterPlayer регистрирует нового игрока с паролем. Занятый player_id (сущность или
+// учётные данные уже есть) даёт ErrPlayerExists: регистрация не выдаёт сессию чужого игрока.
+func (ps *PlayerService) RegisterPlayer(ctx context.Context, playerID, playerName, worldID, password string) (*entity.Entity, error) {
+	cred, err := NewPlayerCredential(password)
+	if err != nil {
+		return nil, err
+	}
+	if ps.credentials == nil {
+		return nil, fmt.Errorf("%w: credential storage not available", storage.ErrUnavailable)
 	}
 
-	// Если сущности нет в кэше, пытаемся загрузить из MinIO
-	playerEntity, err := ps.minioClient.LoadEntity(ctx, playerID, worldID)
+	if _, found := ps.entityCache.Get(playerID, worldID); found {
+		return nil, ErrPlayerExists
+	}
+	_, err = ps.minioClient.LoadEntity(ctx, playerID, worldID)
 	if err == nil {
-		// Сущность найдена в MinIO, добавляем в кэш
-		ps.entityCache.Set(playerID, worldID, playerEntity)
-		return playerEntity, nil
+		return nil, ErrPlayerExists
+	}
+	if !storage.IsNotFound(err) {
+		// MinIO недоступен — нельзя создавать игрока, иначе затрём существующую сущность
+		return nil, fmt.Errorf("failed to load player entity: %w", err)
+	}
+
+	// Учётные данные записываются первыми и только если их нет: одновременная регистрация проиграет
+	if err := ps.credentials.CreateCredential(ctx, worldID, playerID, cred); err != nil {
+		if errors.Is(err, ErrPlayerExists) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to save player credentials: %w", err)
 	}
 
 	// Если сущность не найдена, создаем новую
-	playerEntity = entity.NewEntity(playerID, "player", map[string]interface{}{
+	playerEntity := entity.NewEntity(playerID, "player", map[string]interface{}{
 		"name": playerName,
 		"location": map[string]interface{}{
 			"world_id": worldID,
@@ -51,6 +75,9 @@ func (ps *PlayerService) RegisterPlayer(ctx context.Context, playerID, playerNam
 	// Сохраняем новую сущность в MinIO
 	err = ps.minioClient.SaveEntity(ctx, playerEntity, worldID)
 	if err != nil {
+		if rmErr := ps.credentials.RemoveCredential(ctx, worldID, playerID); rmErr != nil {
+			logging.Warnf("Failed to remove credentials of unregistered player %s: %v", playerID, rmErr)
+		}
 		return nil, fmt.Errorf("failed to save new player entity: %w", err)
 	}
 
@@ -58,22 +85,44 @@ func (ps *PlayerService) RegisterPlayer(ctx context.Context, playerID, playerNam
 	ps.entityCache.Set(playerID, worldID, playerEntity)
 
 	// Публикуем событие о создании игрока
-	event := eventbus.NewEvent("entity.created", "game-service", worldID, map[string]any{
-		"entity_id":   playerID,
-		"entity_type": "player",
-		"payload":     playerEntity.Payload,
+	event, err := events.New("game-service", worldID, events.EntityCreated{
+		Subject: events.Subject{
+			Entity: events.EntityOf(playerID, "player", playerName),
+			World:  events.WorldOf(worldID),
+		},
+		Payload: playerEntity.Payload,
 	})
-
-	err = ps.eventBus.Publish(ctx, eventbus.TopicSystemEvents, event)
+	if err == nil {
+		err = ps.eventBus.Publish(ctx, eventbus.TopicSystemEvents, event)
+	}
 	if err != nil {
-		log.Printf("Warning: Failed to publish player created event: %v", err)
+		logging.Warnf("Failed to publish player created event: %v", err)
 	}
 
 	return playerEntity, nil
 }
 
-// LoginPlayer выполняет вход игрока (возвращает существующую сущность)
-func (ps *PlayerService) LoginPlayer(ctx context.Context, playerID, worldID string) (*entity.Entity, error) {
+// LoginPlayer проверяет пароль игрока и возвращает его сущность. Неизвестный игрок и неверный
+// пароль дают одну ошибку ErrInvalidCredentials, чтобы вход не раскрывал занятые player_id.
+func (ps *PlayerService) LoginPlayer(ctx context.Context, playerID, worldID, password string) (*entity.Entity, error) {
+	if ps.credentials == nil {
+		return nil, fmt.Errorf("%w: credential storage not available", storage.ErrUnavailable)
+	}
+	cred, err := ps.credentials.LoadCredential(ctx, worldID, playerID)
+	if err != nil {
+		if storage.IsNotFound(err) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("failed to load player credentials: %w", err)
+	}
+	if !cred.Verify(password) {
+		return nil, ErrInvalidCredentials
+	}
+	return ps.loadPlayer(ctx, playerID, worldID)
+}
+
+// loadPlayer возвращает сущность игрока из кэша или MinIO
+func (ps *PlayerService) loadPlayer(ctx context.Context, playerID, worldID string) (*entity.Entity, error) {
 	// Пытаемся получить сущность игрока из кэша
 	if entity, found := ps.entityCache.Get(playerID, worldID); found {
 		return entity, nil
@@ -82,7 +131,10 @@ func (ps *PlayerService) LoginPlayer(ctx context.Context, playerID, worldID stri
 	// Если сущности нет в кэше, пытаемся загрузить из MinIO
 	playerEntity, err := ps.minioClient.LoadEntity(ctx, playerID, worldID)
 	if err != nil {
-		return nil, fmt.Errorf("player not found: %w", err)
+		if storage.IsNotFound(err) {
+			return nil, fmt.Errorf("player not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to load player entity: %w", err)
 	}
 
 	// Добавляем в кэш
@@ -94,7 +146,7 @@ func (ps *PlayerService) LoginPlayer(ctx context.Context, playerID, worldID stri
 // UpdatePlayerLocation обновляет местоположение игрока
 func (ps *PlayerService) UpdatePlayerLocation(ctx context.Context, playerID, worldID, newWorldID string) error {
 	// Получаем сущность игрока
-	playerEntity, err := ps.LoginPlayer(ctx, playerID, worldID)
+	playerEntity, err := ps.loadPlayer(ctx, playerID, worldID)
 	if err != nil {
 		return err
 	}
@@ -120,20 +172,8 @@ func (ps *PlayerService) UpdatePlayerLocation(ctx context.Context, playerID, wor
 
 	err = ps.eventBus.Publish(ctx, eventbus.TopicPlayerEvents, event)
 	if err != nil {
-		log.Printf("Warning: Failed to publish player moved event: %v", err)
+		logging.Warnf("Failed to publish player moved event: %v", err)
 	}
 
 	return nil
 }
-
-// GetOrCreatePlayer получает существующего игрока или создает нового
-func (ps *PlayerService) GetOrCreatePlayer(ctx context.Context, playerID, playerName, worldID string) (*entity.Entity, error) {
-	// Сначала пытаемся найти игрока
-	playerEntity, err := ps.LoginPlayer(ctx, playerID, worldID)
-	if err == nil {
-		return playerEntity, nil
-	}
-
-	// Если не найден, создаем нового
-	return ps.RegisterPlayer(ctx, playerID, playerName, worldID)
-}
@@ -0,0 +1,403 @@
+package gameservice
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/entity"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+
+	"github.com/gorilla/mux"
+)
+
+// Время жизни кэша для маршрутов публичного API.
+// Данные карты меняются редко, хроники — постоянно.
+const (
+	publicSummaryTTL    = 5 * time.Minute
+	publicMapTTL        = 10 * time.Minute
+	publicChroniclesTTL = 30 * time.Second
+	publicFactionsTTL   = time.Minute
+
+	defaultChroniclesLimit = 50
+)
+
+// publicCacheEntry — закэшированный сериализованный ответ публичного API
+type publicCacheEntry struct {
+	body      []byte
+	etag      string
+	expiresAt time.Time
+}
+
+// PublicResponseCache кэширует ответы публичного API по маршруту и запросу,
+// чтобы публичный сайт не нагружал MinIO при каждом обращении
+type PublicResponseCache struct {
+	entries map[string]*publicCacheEntry
+	mutex   sync.RWMutex
+}
+
+// NewPublicResponseCache создает новый кэш публичных ответов
+func NewPublicResponseCache() *PublicResponseCache {
+	return &PublicResponseCache{
+		entries: make(map[string]*publicCacheEntry),
+	}
+}
+
+// get возвращает неустаревшую запись кэша
+func (pc *PublicResponseCache) get(key string) (*publicCacheEntry, bool) {
+	pc.mutex.RLock()
+	defer pc.mutex.RUnlock()
+
+	entry, exists := pc.entries[key]
+	if !exists || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry, true
+}
+
+// set сохраняет ответ в кэш и удаляет устаревшие записи
+func (pc *PublicResponseCache) set(key string, entry *publicCacheEntry) {
+	pc.mutex.Lock()
+	defer pc.mutex.Unlock()
+
+	now := time.Now()
+	for k, e := range pc.entries {
+		if now.After(e.expiresAt) {
+			delete(pc.entries, k)
+		}
+	}
+	pc.entries[key] = entry
+}
+
+// computeETag вычисляет сильный ETag по содержимому ответа
+func computeETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches проверяет заголовок If-None-Match на совпадение с ETag
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		candidate = strings.TrimPrefix(candidate, "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// servePublic отдает ответ публичного API с кэшированием, ETag и заголовками для CDN.
+// key строится обработчиком только из известных нормализованных параметров запроса:
+// произвольные query-параметры не должны порождать новые записи кэша.
+func (s *Service) servePublic(w http.ResponseWriter, r *http.Request, key string, ttl time.Duration, contentType string, build func(ctx context.Context) (interface{}, error)) {
+	entry, found := s.publicCache.get(key)
+	if !found {
+		data, err := build(r.Context())
+		if err != nil {
+			switch {
+			case storage.IsNotFound(err):
+				http.Error(w, "world not found", http.StatusNotFound)
+			case storage.IsUnavailable(err):
+				logging.Warnf("Public API %s: storage unavailable: %v", r.URL.Path, err)
+				http.Error(w, "world data temporarily unavailable", http.StatusServiceUnavailable)
+			default:
+				logging.Errorf("Public API %s failed: %v", r.URL.Path, err)
+				http.Error(w, "failed to load world data", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		body, err := json.Marshal(data)
+		if err != nil {
+			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+			return
+		}
+
+		entry = &publicCacheEntry{
+			body:      body,
+			etag:      computeETag(body),
+			expiresAt: time.Now().Add(ttl),
+		}
+		s.publicCache.set(key, entry)
+	}
+
+	maxAge := int(time.Until(entry.expiresAt).Seconds())
+	if maxAge < 0 {
+		maxAge = 0
+	}
+
+	w.Header().Set("ETag", entry.etag)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d, stale-while-revalidate=%d", maxAge, int(ttl.Seconds())))
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Vary", "Accept-Encoding")
+
+	if etagMatches(r.Header.Get("If-None-Match"), entry.etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Write(entry.body)
+}
+
+// WorldSummary — публичное описание мира
+type WorldSummary struct {
+	WorldID      string         `json:"world_id"`
+	Name         string         `json:"name,omitempty"`
+	Theme        string         `json:"theme,omitempty"`
+	Core         string         `json:"core,omitempty"`
+	Era          string         `json:"era,omitempty"`
+	UniqueTraits interface{}    `json:"unique_traits,omitempty"`
+	EntityCounts map[string]int `json:"entity_counts"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+}
+
+// GeoJSONFeatureCollection — карта мира в формате GeoJSON
+type GeoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []GeoJSONFeature `json:"features"`
+}
+
+// GeoJSONFeature — один географический объект карты
+type GeoJSONFeature struct {
+	Type       string                 `json:"type"`
+	ID         string                 `json:"id"`
+	Geometry   GeoJSONGeometry        `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// GeoJSONGeometry — геометрия объекта (в мире используются точки)
+type GeoJSONGeometry struct {
+	Type        string    `json:"type"`
+	Coordinates []float64 `json:"coordinates"`
+}
+
+// FactionStanding — положение фракции в мире
+type FactionStanding struct {
+	FactionID string                 `json:"faction_id"`
+	Name      string                 `json:"name"`
+	Influence float64                `json:"influence"`
+	Relations map[string]interface{} `json:"relations,omitempty"`
+}
+
+// publicEntityTypes — типы сущностей, отображаемые на публичной карте
+var publicEntityTypes = map[string]bool{
+	"region":     true,
+	"city":       true,
+	"water_body": true,
+}
+
+// GetPublicWorldSummaryHandler возвращает публичную сводку мира
+func (s *Service) GetPublicWorldSummaryHandler(w http.ResponseWriter, r *http.Request) {
+	worldID := mux.Vars(r)["world_id"]
+
+	s.servePublic(w, r, "summary/"+worldID, publicSummaryTTL, "application/json", func(ctx context.Context) (interface{}, error) {
+		entities, err := s.listWorldEntities(ctx, worldID)
+		if err != nil {
+			return nil, err
+		}
+
+		summary := WorldSummary{
+			WorldID:      worldID,
+			EntityCounts: make(map[string]int),
+		}
+		for _, ent := range entities {
+			if ent.Type == "player" {
+				continue
+			}
+			summary.EntityCounts[ent.Type]++
+			if ent.UpdatedAt.After(summary.UpdatedAt) {
+				summary.UpdatedAt = ent.UpdatedAt
+			}
+			if ent.ID == worldID {
+				summary.Name = payloadString(ent, "name")
+				summary.Theme = payloadString(ent, "theme")
+				summary.Core = payloadString(ent, "core")
+				summary.Era = payloadString(ent, "era")
+				summary.UniqueTraits, _ = ent.Get("unique_traits")
+			}
+		}
+		return summary, nil
+	})
+}
+
+// GetPublicWorldMapHandler возвращает карту мира в формате GeoJSON
+func (s *Service) GetPublicWorldMapHandler(w http.ResponseWriter, r *http.Request) {
+	worldID := mux.Vars(r)["world_id"]
+
+	s.servePublic(w, r, "map/"+worldID, publicMapTTL, "application/geo+json", func(ctx context.Context) (interface{}, error) {
+		entities, err := s.listWorldEntities(ctx, worldID)
+		if err != nil {
+			return nil, err
+		}
+
+		collection := GeoJSONFeatureCollection{
+			Type:     "FeatureCollection",
+			Features: make([]GeoJSONFeature, 0),
+		}
+		for _, ent := range entities {
+			if !publicEntityTypes[ent.Type] {
+				continue
+			}
+			x, y, ok := entityCoordinates(ent)
+			if !ok {
+				continue
+			}
+
+			properties := map[string]interface{}{
+				"entity_type": ent.Type,
+				"name":        payloadString(ent, "name"),
+			}
+			for _, key := range []string{"biome", "type", "size", "population"} {
+				if value, exists := ent.Get(key); exists {
+					properties[key] = value
+				}
+			}
+
+			collection.Features = append(collection.Features, GeoJSONFeature{
+				Type:       "Feature",
+				ID:         ent.ID,
+				Geometry:   GeoJSONGeometry{Type: "Point", Coordinates: []float64{x, y}},
+				Properties: properties,
+			})
+		}
+		sort.Slice(collection.Features, func(i, j int) bool {
+			return collection.Features[i].ID < collection.Features[j].ID
+		})
+		return collection, nil
+	})
+}
+
+// GetPublicChroniclesHandler возвращает последние хроники мира.
+//
+// Query params:
+//
+//	?limit=N (default: 50, max: 200)
+func (s *Service) GetPublicChroniclesHandler(w http.ResponseWriter, r *http.Request) {
+	worldID := mux.Vars(r)["world_id"]
+
+	limit := defaultChroniclesLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+	if limit > maxChronicleEntries {
+		limit = maxChronicleEntries
+	}
+
+	key := "chronicles/" + worldID + "?limit=" + strconv.Itoa(limit)
+	s.servePublic(w, r, key, publicChroniclesTTL, "application/json", func(ctx context.Context) (interface{}, error) {
+		return map[string]interface{}{
+			"world_id":   worldID,
+			"chronicles": s.chronicles.Recent(worldID, limit),
+		}, nil
+	})
+}
+
+// GetPublicFactionsHandler возвращает положение фракций мира, отсортированное по влиянию
+func (s *Service) GetPublicFactionsHandler(w http.ResponseWriter, r *http.Request) {
+	worldID := mux.Vars(r)["world_id"]
+
+	s.servePublic(w, r, "factions/"+worldID, publicFactionsTTL, "application/json", func(ctx context.Context) (interface{}, error) {
+		entities, err := s.listWorldEntities(ctx, worldID)
+		if err != nil {
+			return nil, err
+		}
+
+		standings := make([]FactionStanding, 0)
+		for _, ent := range entities {
+			if ent.Type != "faction" {
+				continue
+			}
+			standing := FactionStanding{
+				FactionID: ent.ID,
+				Name:      payloadString(ent, "name"),
+				Influence: payloadFloat(ent, "influence", "standing", "power"),
+			}
+			if relations, ok := ent.Payload["relations"].(map[string]interface{}); ok {
+				standing.Relations = relations
+			}
+			standings = append(standings, standing)
+		}
+		sort.SliceStable(standings, func(i, j int) bool {
+			return standings[i].Influence > standings[j].Influence
+		})
+
+		return map[string]interface{}{
+			"world_id": worldID,
+			"factions": standings,
+		}, nil
+	})
+}
+
+// listWorldEntities загружает сущности мира из MinIO
+func (s *Service) listWorldEntities(ctx context.Context, worldID string) ([]*entity.Entity, error) {
+	if s.minioClient == nil {
+		return nil, fmt.Errorf("%w: MinIO client not available", storage.ErrUnavailable)
+	}
+	return s.minioClient.ListEntities(ctx, worldID)
+}
+
+// payloadString возвращает первое непустое строковое поле payload сущности
+func payloadString(ent *entity.Entity, keys ...string) string {
+	for _, key := range keys {
+		if value, ok := ent.Get(key); ok {
+			if str, ok := value.(string); ok && str != "" {
+				return str
+			}
+		}
+	}
+	return ""
+}
+
+// payloadFloat возвращает первое числовое поле payload сущности
+func payloadFloat(ent *entity.Entity, keys ...string) float64 {
+	for _, key := range keys {
+		if value, ok := ent.Get(key); ok {
+			switch v := value.(type) {
+			case float64:
+				return v
+			case int:
+				return float64(v)
+			}
+		}
+	}
+	return 0
+}
+
+// entityCoordinates извлекает координаты сущности (coordinates или location.coordinates)
+func entityCoordinates(ent *entity.Entity) (float64, float64, bool) {
+	for _, path := range []string{"coordinates", "location.coordinates"} {
+		raw, ok := ent.Get(path)
+		if !ok {
+			continue
+		}
+		point, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		x, okX := point["x"].(float64)
+		y, okY := point["y"].(float64)
+		if okX && okY {
+			return x, y, true
+		}
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,124 @@
+package gameservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	storage "multiverse-core.io/shared/minio"
+
+	"github.com/gorilla/mux"
+)
+
+func TestServePublicETag(t *testing.T) {
+	service := &Service{publicCache: NewPublicResponseCache()}
+	builds := 0
+	serve := func(header string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/public/worlds/world-1", nil)
+		if header != "" {
+			req.Header.Set("If-None-Match", header)
+		}
+		rec := httptest.NewRecorder()
+		service.servePublic(rec, req, "summary/world-1", time.Minute, "application/json", func(ctx context.Context) (interface{}, error) {
+			builds++
+			return map[string]string{"world_id": "world-1"}, nil
+		})
+		return rec
+	}
+
+	first := serve("")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" || first.Body.String() != `{"world_id":"world-1"}` {
+		t.Fatalf("unexpected response %d %q: %s", first.Code, etag, first.Body.String())
+	}
+	cacheControl := first.Header().Get("Cache-Control")
+	if !strings.HasPrefix(cacheControl, "public, max-age=") || !strings.HasSuffix(cacheControl, "stale-while-revalidate=60") {
+		t.Errorf("unexpected Cache-Control %q", cacheControl)
+	}
+	if first.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("unexpected Content-Type %q", first.Header().Get("Content-Type"))
+	}
+
+	for _, header := range []string{etag, "W/" + etag, `"other", ` + etag, "*"} {
+		rec := serve(header)
+		if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+			t.Errorf("If-None-Match %s: expected 304 without body, got %d", header, rec.Code)
+		}
+		if rec.Header().Get("ETag") != etag || rec.Header().Get("Cache-Control") == "" {
+			t.Errorf("If-None-Match %s: expected cache headers on 304", header)
+		}
+	}
+	if rec := serve(`"other"`); rec.Code != http.StatusOK || rec.Body.Len() == 0 {
+		t.Errorf("expected full response for a stale ETag, got %d", rec.Code)
+	}
+	if builds != 1 {
+		t.Errorf("expected the response to be built once, got %d", builds)
+	}
+}
+
+func TestServePublicErrors(t *testing.T) {
+	service := &Service{publicCache: NewPublicResponseCache()}
+	cases := []struct {
+		err  error
+		want int
+	}{
+		{fmt.Errorf("%w: connection refused", storage.ErrUnavailable), http.StatusServiceUnavailable},
+		{fmt.Errorf("%w: NoSuchBucket", storage.ErrNotFound), http.StatusNotFound},
+		{errors.New("decode failed"), http.StatusInternalServerError},
+	}
+	for _, tc := range cases {
+		for attempt := 0; attempt < 2; attempt++ {
+			rec := httptest.NewRecorder()
+			service.servePublic(rec, httptest.NewRequest(http.MethodGet, "/public/worlds/world-1", nil), "summary/world-1", time.Minute, "application/json",
+				func(ctx context.Context) (interface{}, error) { return nil, tc.err })
+			if rec.Code != tc.want || rec.Header().Get("ETag") != "" {
+				t.Errorf("%v: expected uncached %d, got %d", tc.err, tc.want, rec.Code)
+			}
+		}
+	}
+
+	// Без MinIO данные мира временно недоступны
+	router := mux.NewRouter()
+	router.HandleFunc("/public/worlds/{world_id}", service.GetPublicWorldSummaryHandler)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/public/worlds/world-1", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 without storage, got %d", rec.Code)
+	}
+}
+
+func TestPublicCacheKeyIgnoresUnknownParams(t *testing.T) {
+	service := &Service{publicCache: NewPublicResponseCache(), chronicles: NewChronicleStore()}
+	router := mux.NewRouter()
+	router.HandleFunc("/public/worlds/{world_id}/chronicles", service.GetPublicChroniclesHandler)
+
+	for _, target := range []string{
+		"/public/worlds/world-1/chronicles",
+		"/public/worlds/world-1/chronicles?limit=50",
+		"/public/worlds/world-1/chronicles?cb=1",
+		"/public/worlds/world-1/chronicles?cb=2&limit=50",
+		"/public/worlds/world-1/chronicles?limit=200",
+		"/public/worlds/world-1/chronicles?limit=100500",
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: unexpected status %d", target, rec.Code)
+		}
+	}
+
+	service.publicCache.mutex.RLock()
+	defer service.publicCache.mutex.RUnlock()
+	if len(service.publicCache.entries) != 2 {
+		keys := make([]string, 0, len(service.publicCache.entries))
+		for key := range service.publicCache.entries {
+			keys = append(keys, key)
+		}
+		t.Errorf("expected entries for limits 50 and 200, got %v", keys)
+	}
+}
@@ -0,0 +1,299 @@
+package gameservice
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+)
+
+// EventPlayerThrottled — клиент превысил лимит запросов или сообщений WebSocket.
+// BanOfWorld и CityGovernor могут реагировать на него как на нарушение.
+const EventPlayerThrottled = "player.throttled"
+
+// Классы эндпоинтов с собственными лимитами
+const (
+	// RateClassAuth — регистрация и вход
+	RateClassAuth = "auth"
+	// RateClassActions — команды игрока, пакеты действий, выборы, загрузка ассетов
+	RateClassActions = "actions"
+	// RateClassRead — чтение сущностей, событий, повествования и публичное API
+	RateClassRead = "read"
+	// RateClassWebSocket — подключения и сообщения WebSocket
+	RateClassWebSocket = "websocket"
+	// RateClassStateChanges — изменения состояния от внешних движков, на игрока запроса
+	RateClassStateChanges = "state_changes"
+)
+
+// ErrRateLimited — клиент превысил лимит класса эндпоинтов (429)
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// RateLimit — лимит класса: Rate запросов в секунду, до Burst подряд
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// DefaultRateLimits — лимиты классов по умолчанию на одного клиента
+var DefaultRateLimits = map[string]RateLimit{
+	RateClassAuth:         {Rate: 0.2, Burst: 5},
+	RateClassActions:      {Rate: 5, Burst: 20},
+	RateClassRead:         {Rate: 20, Burst: 60},
+	RateClassWebSocket:    {Rate: 10, Burst: 30},
+	RateClassStateChanges: {Rate: 5, Burst: 20},
+}
+
+const (
+	// throttleReportInterval — player.throttled публикуется не чаще раза в интервал на клиента и класс
+	throttleReportInterval = time.Minute
+	// rateLimitPruneInterval — как часто забываются заполнившиеся бакеты
+	rateLimitPruneInterval = 10 * time.Second
+	// maxWSThrottledMessages — после стольких отклонённых сообщений подряд соединение WebSocket закрывается
+	maxWSThrottledMessages = 20
+)
+
+// tokenBucket — бакет одного клиента в классе
+type tokenBucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// take расходует запрос из бакета, пополняя его со скоростью rate до burst.
+// Если запросов не осталось, возвращает время до следующего разрешённого.
+func (b *tokenBucket) take(now time.Time, rate, burst float64) (bool, time.Duration) {
+	if elapsed := now.Sub(b.updatedAt).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(burst, b.tokens+elapsed*rate)
+	}
+	b.updatedAt = now
+
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// refilled сообщает, успел ли бакет заполниться: такой бакет не отличается от нового
+func (b *tokenBucket) refilled(now time.Time, rate, burst float64) bool {
+	refill := time.Duration((burst - b.tokens) / rate * float64(time.Second))
+	return now.Sub(b.updatedAt) > refill
+}
+
+// RateClient — клиент, к которому применяется лимит: игрок сессии или IP-адрес
+type RateClient struct {
+	PlayerID  string
+	WorldID   string
+	SessionID string
+	IP        string
+}
+
+// key возвращает ключ бакета клиента
+func (c RateClient) key() string {
+	if c.PlayerID != "" {
+		return "player:" + c.WorldID + "/" + c.PlayerID
+	}
+	return "ip:" + c.IP
+}
+
+// throttleReport — отклонённые запросы клиента в классе с последней публикации player.throttled
+type throttleReport struct {
+	reportedAt time.Time
+	rejected   int
+}
+
+// RateLimiter ограничивает частоту запросов каждого клиента по классам эндпоинтов (token bucket)
+// и публикует player.throttled, когда клиент упирается в лимит.
+type RateLimiter struct {
+	limits map[string]RateLimit
+	// trustProxy — брать IP клиента из X-Forwarded-For (GameService за балансировщиком)
+	trustProxy bool
+	publish    func(ctx context.Context, event eventbus.Event) error
+	now        func() time.Time
+
+	buckets  map[string]*tokenBucket    // {class}|{client} → бакет
+	reports  map[string]*throttleReport // {class}|{client} → отклонённые запросы
+	prunedAt time.Time
+	mutex    sync.Mutex
+}
+
+// NewRateLimiter создает ограничитель. Классы без лимита или с нулевыми значениями
+// получают DefaultRateLimits; publish == nil — player.throttled не публикуется.
+func NewRateLimiter(limits map[string]RateLimit, trustProxy bool, publish func(ctx context.Context, event eventbus.Event) error) *RateLimiter {
+	merged := make(map[string]RateLimit, len(DefaultRateLimits))
+	for class, limit := range DefaultRateLimits {
+		if custom, ok := limits[class]; ok {
+			if custom.Rate > 0 {
+				limit.Rate = custom.Rate
+			}
+			if custom.Burst > 0 {
+				limit.Burst = custom.Burst
+			}
+		}
+		merged[class] = limit
+	}
+	return &RateLimiter{
+		limits:     merged,
+		trustProxy: trustProxy,
+		publish:    publish,
+		now:        time.Now,
+		buckets:    make(map[string]*tokenBucket),
+		reports:    make(map[string]*throttleReport),
+	}
+}
+
+// Allow расходует запрос клиента в классе. Если лимит исчерпан, возвращает время до следующего
+// разрешённого запроса. Запросы классов без лимита всегда разрешены.
+func (l *RateLimiter) Allow(class string, client RateClient) (bool, time.Duration) {
+	limit, ok := l.limits[class]
+	if !ok {
+		return true, 0
+	}
+	rate, burst := limit.Rate, float64(limit.Burst)
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	l.pruneLocked(now)
+
+	key := class + "|" + client.key()
+	bucket, exists := l.buckets[key]
+	if !exists {
+		bucket = &tokenBucket{tokens: burst, updatedAt: now}
+		l.buckets[key] = bucket
+	}
+	return bucket.take(now, rate, burst)
+}
+
+// pruneLocked забывает заполнившиеся бакеты и старые отчёты; вызывается под мьютексом
+func (l *RateLimiter) pruneLocked(now time.Time) {
+	if now.Sub(l.prunedAt) < rateLimitPruneInterval {
+		return
+	}
+	l.prunedAt = now
+	for key, bucket := range l.buckets {
+		limit := l.limits[key[:strings.IndexByte(key, '|')]]
+		if bucket.refilled(now, limit.Rate, float64(limit.Burst)) {
+			delete(l.buckets, key)
+		}
+	}
+	for key, report := range l.reports {
+		if report.rejected == 0 && now.Sub(report.reportedAt) >= throttleReportInterval {
+			delete(l.reports, key)
+		}
+	}
+}
+
+// Throttled учитывает отклонённый запрос и публикует player.throttled: сразу при первом
+// отклонении, затем не чаще раза в throttleReportInterval с числом отклонений за это время.
+func (l *RateLimiter) Throttled(ctx context.Context, class string, client RateClient, endpoint string, retryAfter time.Duration) {
+	key := class + "|" + client.key()
+
+	l.mutex.Lock()
+	now := l.now()
+	report, exists := l.reports[key]
+	if !exists {
+		report = &throttleReport{}
+		l.reports[key] = report
+	}
+	report.rejected++
+	if exists && now.Sub(report.reportedAt) < throttleReportInterval {
+		l.mutex.Unlock()
+		return
+	}
+	rejected := report.rejected
+	report.reportedAt, report.rejected = now, 0
+	l.mutex.Unlock()
+
+	logging.Warnf("Client %s throttled on %s (%s): %d rejected", client.key(), endpoint, class, rejected)
+	if l.publish == nil {
+		return
+	}
+	event := throttledEvent(class, client, endpoint, rejected, retryAfter, now)
+	if err := l.publish(ctx, event); err != nil {
+		logging.Errorf("Failed to publish %s for %s: %v", EventPlayerThrottled, client.key(), err)
+	}
+}
+
+// throttledEvent создает player.throttled; событие клиента без сессии не привязано к игроку
+func throttledEvent(class string, client RateClient, endpoint string, rejected int, retryAfter time.Duration, now time.Time) eventbus.Event {
+	payload := eventbus.NewEventPayload().WithWorld(client.WorldID)
+	if client.PlayerID != "" {
+		payload.WithEntity(client.PlayerID, "player", "").WithScope(client.PlayerID, "player")
+	}
+	throttle := map[string]interface{}{
+		"class":          class,
+		"endpoint":       endpoint,
+		"rejected":       rejected,
+		"retry_after_ms": retryAfter.Milliseconds(),
+		"window_s":       throttleReportInterval.Seconds(),
+	}
+	if client.IP != "" {
+		throttle["ip"] = client.IP
+	}
+	if client.SessionID != "" {
+		throttle["session_id"] = client.SessionID
+	}
+	payload.GetCustom()["throttle"] = throttle
+
+	event := eventbus.NewStructuredEvent(EventPlayerThrottled, "game-service", client.WorldID, payload)
+	event.Timestamp = now.UTC()
+	if client.PlayerID != "" {
+		event.Scope = &eventbus.ScopeRef{ID: client.PlayerID, Type: "player"}
+	}
+	return event
+}
+
+// Client возвращает клиента запроса: игрока сессии (после RequireSession) и IP-адрес
+func (l *RateLimiter) Client(r *http.Request) RateClient {
+	client := RateClient{IP: clientIP(r, l.trustProxy)}
+	if claims, ok := SessionFromContext(r.Context()); ok {
+		client.PlayerID, client.WorldID, client.SessionID = claims.PlayerID, claims.WorldID, claims.SessionID
+	}
+	return client
+}
+
+// clientIP возвращает IP клиента: первый адрес X-Forwarded-For за доверенным прокси, иначе адрес соединения
+func clientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			if ip := strings.TrimSpace(strings.Split(forwarded, ",")[0]); ip != "" {
+				return ip
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// Limit пропускает запросы клиента в пределах лимита класса, остальным отвечает 429 с Retry-After.
+// Для маршрутов с сессией оборачивается в RequireSession, чтобы лимит считался на игрока.
+func (l *RateLimiter) Limit(class string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		client := l.Client(r)
+		if allowed, wait := l.Allow(class, client); !allowed {
+			l.Throttled(r.Context(), class, client, r.Method+" "+r.URL.Path, wait)
+			writeRateLimited(w, wait, ErrRateLimited)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// writeRateLimited отвечает 429 с Retry-After в целых секундах
+func writeRateLimited(w http.ResponseWriter, wait time.Duration, err error) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	w.WriteHeader(http.StatusTooManyRequests)
+	w.Write([]byte(err.Error()))
+}
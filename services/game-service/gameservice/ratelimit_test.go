@@ -0,0 +1,167 @@
+package gameservice
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestRateLimiterClasses(t *testing.T) {
+	now := time.Now()
+	limiter := NewRateLimiter(map[string]RateLimit{RateClassActions: {Burst: 2}}, false, nil)
+	limiter.now = func() time.Time { return now }
+	if got := limiter.limits[RateClassActions]; got.Rate != DefaultRateLimits[RateClassActions].Rate || got.Burst != 2 {
+		t.Fatalf("expected the default rate with burst 2, got %+v", got)
+	}
+
+	kain := RateClient{PlayerID: "kain", WorldID: "world-1", IP: "10.0.0.1"}
+	for i := 0; i < 2; i++ {
+		if ok, _ := limiter.Allow(RateClassActions, kain); !ok {
+			t.Fatalf("request %d must fit into the burst", i+1)
+		}
+	}
+	ok, wait := limiter.Allow(RateClassActions, kain)
+	if ok || wait <= 0 {
+		t.Fatalf("expected the third request to be limited with a wait, got %v %v", ok, wait)
+	}
+
+	// Классы и клиенты ограничиваются независимо; игрок с сессией не делит бакет со своим IP
+	if ok, _ := limiter.Allow(RateClassRead, kain); !ok {
+		t.Error("read requests must not be limited by the actions bucket")
+	}
+	if ok, _ := limiter.Allow(RateClassActions, RateClient{IP: "10.0.0.1"}); !ok {
+		t.Error("anonymous client of the same IP must have its own bucket")
+	}
+	if ok, _ := limiter.Allow("unknown", kain); !ok {
+		t.Error("classes without a limit must not be limited")
+	}
+
+	now = now.Add(wait)
+	if ok, _ := limiter.Allow(RateClassActions, kain); !ok {
+		t.Error("expected a request to be allowed after the wait")
+	}
+}
+
+func TestRateLimiterThrottledReports(t *testing.T) {
+	now := time.Now()
+	var published []eventbus.Event
+	limiter := NewRateLimiter(nil, false, func(ctx context.Context, event eventbus.Event) error {
+		published = append(published, event)
+		return nil
+	})
+	limiter.now = func() time.Time { return now }
+	kain := RateClient{PlayerID: "kain", WorldID: "world-1", SessionID: "s-1", IP: "10.0.0.1"}
+
+	limiter.Throttled(context.Background(), RateClassActions, kain, "POST /v1/actions", 200*time.Millisecond)
+	for i := 0; i < 4; i++ {
+		limiter.Throttled(context.Background(), RateClassActions, kain, "POST /v1/actions", time.Second)
+	}
+	if len(published) != 1 {
+		t.Fatalf("expected a single player.throttled within the interval, got %d", len(published))
+	}
+
+	event := published[0]
+	if event.Type != EventPlayerThrottled || eventbus.GetWorldIDFromEvent(event) != "world-1" {
+		t.Errorf("unexpected event %s of world %s", event.Type, eventbus.GetWorldIDFromEvent(event))
+	}
+	if info := eventbus.ExtractEntityID(event.Payload); info == nil || info.ID != "kain" {
+		t.Errorf("expected player kain in the event, got %+v", info)
+	}
+	throttle, _ := event.Payload["throttle"].(map[string]interface{})
+	if throttle["class"] != RateClassActions || throttle["rejected"] != 1 || throttle["session_id"] != "s-1" || throttle["retry_after_ms"] != int64(200) {
+		t.Errorf("unexpected throttle details %v", throttle)
+	}
+
+	// После интервала публикуется число отклонений, накопленных с прошлого события
+	now = now.Add(throttleReportInterval)
+	limiter.Throttled(context.Background(), RateClassActions, kain, "POST /v1/actions", time.Second)
+	if len(published) != 2 {
+		t.Fatalf("expected a second player.throttled after the interval, got %d", len(published))
+	}
+	if throttle, _ := published[1].Payload["throttle"].(map[string]interface{}); throttle["rejected"] != 5 {
+		t.Errorf("expected 5 rejected requests, got %v", throttle["rejected"])
+	}
+
+	// Клиент без сессии не привязан к игроку
+	limiter.Throttled(context.Background(), RateClassAuth, RateClient{IP: "10.0.0.2"}, "POST /players/login", time.Second)
+	anonymous := published[len(published)-1]
+	if info := eventbus.ExtractEntityID(anonymous.Payload); info != nil {
+		t.Errorf("anonymous client must not be attributed to a player, got %+v", info)
+	}
+	if throttle, _ := anonymous.Payload["throttle"].(map[string]interface{}); throttle["ip"] != "10.0.0.2" {
+		t.Errorf("expected the client IP, got %v", throttle)
+	}
+}
+
+func TestRateLimiterMiddleware(t *testing.T) {
+	var published int
+	limiter := NewRateLimiter(map[string]RateLimit{RateClassAuth: {Rate: 0.01, Burst: 1}}, true, func(ctx context.Context, event eventbus.Event) error {
+		published++
+		return nil
+	})
+	handler := limiter.Limit(RateClassAuth, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	request := func(forwardedFor string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/players/login", nil)
+		r.Header.Set("X-Forwarded-For", forwardedFor)
+		w := httptest.NewRecorder()
+		handler(w, r)
+		return w
+	}
+
+	if w := request("203.0.113.7, 10.0.0.1"); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	w := request("203.0.113.7")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "100" {
+		t.Fatalf("expected 429 with Retry-After 100, got %d %q", w.Code, w.Header().Get("Retry-After"))
+	}
+	if w := request("203.0.113.8"); w.Code != http.StatusOK {
+		t.Errorf("another forwarded client must not be limited, got %d", w.Code)
+	}
+	if published != 1 {
+		t.Errorf("expected 1 player.throttled, got %d", published)
+	}
+}
+
+func TestWebSocketMessageRateLimit(t *testing.T) {
+	ws := NewWebSocketServer()
+	ws.UseRateLimiter(NewRateLimiter(map[string]RateLimit{RateClassWebSocket: {Rate: 0.01, Burst: 1}}, false, nil))
+	server := httptest.NewServer(http.HandlerFunc(ws.HandleWebSocket))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	subscribe := map[string]any{"subscribe": map[string]any{"world_id": "world-1"}}
+	var reply map[string]any
+	if err := conn.WriteJSON(subscribe); err != nil || conn.ReadJSON(&reply) != nil || reply["type"] != "subscriptions" {
+		t.Fatalf("expected subscription ack, got %v (%v)", reply, err)
+	}
+	for i := 1; i < maxWSThrottledMessages; i++ {
+		reply = nil
+		if err := conn.WriteJSON(subscribe); err != nil || conn.ReadJSON(&reply) != nil || reply["error"] != ErrRateLimited.Error() {
+			t.Fatalf("message %d: expected a rate limit error, got %v (%v)", i, reply, err)
+		}
+	}
+
+	// Сообщение сверх maxWSThrottledMessages отклонённых подряд закрывает соединение
+	conn.WriteJSON(subscribe)
+	_, _, err = conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.ClosePolicyViolation) {
+		t.Errorf("expected policy violation close, got %v", err)
+	}
+}
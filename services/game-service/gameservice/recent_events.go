@@ -0,0 +1,159 @@
+package gameservice
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"multiverse-core.io/shared/eventbus"
+)
+
+// Ограничения буфера последних событий
+const (
+	// maxRecentEvents — ёмкость кольцевого буфера событий на мир
+	maxRecentEvents      = 1000
+	defaultRecentLimit   = 50
+	maxRecentEventsLimit = 500
+)
+
+// RecentEventsQuery — выборка последних событий мира
+type RecentEventsQuery struct {
+	WorldID string
+	// ScopeID оставляет события этого scope (события без scope не подходят)
+	ScopeID string
+	// TypePrefix оставляет события, тип которых начинается с префикса ("narrative.", "player.moved")
+	TypePrefix string
+	Limit      int
+}
+
+// eventRing — кольцевой буфер событий одного мира
+type eventRing struct {
+	events []eventbus.Event
+	next   int // позиция следующей записи после заполнения буфера
+}
+
+func (r *eventRing) add(event eventbus.Event, capacity int) {
+	if len(r.events) < capacity {
+		r.events = append(r.events, event)
+		return
+	}
+	r.events[r.next] = event
+	r.next = (r.next + 1) % capacity
+}
+
+// each обходит события от новых к старым, пока fn возвращает true
+func (r *eventRing) each(fn func(event eventbus.Event) bool) {
+	n := len(r.events)
+	for i := 0; i < n; i++ {
+		if !fn(r.events[(r.next-1-i+2*n)%n]) {
+			return
+		}
+	}
+}
+
+// RecentEventStore хранит последние события каждого мира из подписок на Kafka,
+// чтобы клиенты могли восстановить состояние после переподключения
+type RecentEventStore struct {
+	capacity int
+	worlds   map[string]*eventRing
+	mutex    sync.RWMutex
+}
+
+// NewRecentEventStore создает хранилище с буфером на capacity событий для каждого мира
+func NewRecentEventStore(capacity int) *RecentEventStore {
+	return &RecentEventStore{
+		capacity: capacity,
+		worlds:   make(map[string]*eventRing),
+	}
+}
+
+// Record добавляет событие в буфер его мира; события без мира не сохраняются
+func (rs *RecentEventStore) Record(event eventbus.Event) {
+	worldID := eventbus.GetWorldIDFromEvent(event)
+	if worldID == "" {
+		return
+	}
+
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+
+	ring, exists := rs.worlds[worldID]
+	if !exists {
+		ring = &eventRing{}
+		rs.worlds[worldID] = ring
+	}
+	ring.add(event, rs.capacity)
+}
+
+// Query возвращает до limit последних подходящих событий мира в хронологическом порядке
+func (rs *RecentEventStore) Query(q RecentEventsQuery) []eventbus.Event {
+	limit := q.Limit
+	if limit <= 0 {
+		limit = defaultRecentLimit
+	}
+	if limit > maxRecentEventsLimit {
+		limit = maxRecentEventsLimit
+	}
+
+	rs.mutex.RLock()
+	defer rs.mutex.RUnlock()
+
+	result := make([]eventbus.Event, 0, limit)
+	ring, exists := rs.worlds[q.WorldID]
+	if !exists {
+		return result
+	}
+
+	ring.each(func(event eventbus.Event) bool {
+		if q.TypePrefix != "" && !strings.HasPrefix(event.Type, q.TypePrefix) {
+			return true
+		}
+		if q.ScopeID != "" {
+			if scope := eventbus.GetScopeFromEvent(event); scope == nil || scope.ID != q.ScopeID {
+				return true
+			}
+		}
+		result = append(result, event)
+		return len(result) < limit
+	})
+
+	// Собирали от новых к старым; клиенту удобнее воспроизводить по порядку
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return result
+}
+
+// GetRecentEventsHandler обрабатывает GET /events/recent?world_id=...&scope_id=...&event_type=...&limit=...
+func (s *Service) GetRecentEventsHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	q := RecentEventsQuery{
+		WorldID:    query.Get("world_id"),
+		ScopeID:    query.Get("scope_id"),
+		TypePrefix: query.Get("event_type"),
+	}
+	if q.WorldID == "" {
+		q.WorldID = r.Header.Get("X-World-ID")
+	}
+	if q.WorldID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("world_id is required"))
+		return
+	}
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("limit must be a positive integer"))
+			return
+		}
+		q.Limit = limit
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"events": s.recentEvents.Query(q),
+	})
+}
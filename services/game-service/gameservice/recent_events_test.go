@@ -0,0 +1,49 @@
+package gameservice
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRecentEventStoreRingBuffer(t *testing.T) {
+	store := NewRecentEventStore(3)
+	for i := 1; i <= 5; i++ {
+		event := scopedEvent(fmt.Sprintf("player.moved.%d", i), "world-1", "")
+		event.ID = fmt.Sprintf("e%d", i)
+		store.Record(event)
+	}
+	store.Record(scopedEvent("player.moved", "", ""))
+
+	events := store.Query(RecentEventsQuery{WorldID: "world-1"})
+	if len(events) != 3 {
+		t.Fatalf("expected 3 buffered events, got %d", len(events))
+	}
+	for i, want := range []string{"e3", "e4", "e5"} {
+		if events[i].ID != want {
+			t.Errorf("position %d: expected %s, got %s", i, want, events[i].ID)
+		}
+	}
+
+	if events := store.Query(RecentEventsQuery{WorldID: "world-1", Limit: 2}); len(events) != 2 || events[0].ID != "e4" || events[1].ID != "e5" {
+		t.Errorf("expected the 2 newest events in order, got %+v", events)
+	}
+	if events := store.Query(RecentEventsQuery{WorldID: "world-2"}); len(events) != 0 {
+		t.Errorf("expected no events for unknown world, got %d", len(events))
+	}
+}
+
+func TestRecentEventStoreFilters(t *testing.T) {
+	store := NewRecentEventStore(10)
+	store.Record(scopedEvent("player.moved", "world-1", "player:kain"))
+	store.Record(scopedEvent("narrative.generated", "world-1", "player:kain"))
+	store.Record(scopedEvent("narrative.generated", "world-1", "player:abel"))
+	store.Record(scopedEvent("weather.changed", "world-1", ""))
+
+	if events := store.Query(RecentEventsQuery{WorldID: "world-1", ScopeID: "player:kain"}); len(events) != 2 {
+		t.Errorf("expected 2 events of scope, got %d", len(events))
+	}
+	events := store.Query(RecentEventsQuery{WorldID: "world-1", ScopeID: "player:kain", TypePrefix: "narrative."})
+	if len(events) != 1 || events[0].Type != "narrative.generated" {
+		t.Errorf("expected one narrative event of scope, got %+v", events)
+	}
+}
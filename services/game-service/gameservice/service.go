@@ -4,26 +4,72 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"os"
 	"time"
 
+	"multiverse-core.io/shared/archivist"
 	"multiverse-core.io/shared/entity"
 	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
 )
 
 type Config struct {
 	KafkaBrokers []string
 	HTTPAddr     string
 	CacheTTL     time.Duration
+	// GRPCAddr — адрес gRPC API (пустой — gRPC отключён)
+	GRPCAddr string
+	// AssetsPublicEndpoint — внешний адрес MinIO для presigned-загрузок ассетов (например, https://cdn.example.com)
+	AssetsPublicEndpoint string
+	// SessionSecret — ключ подписи токенов сессий (пустой — случайный ключ на время жизни процесса)
+	SessionSecret string
+	// SessionTTL — время жизни токена сессии (0 — DefaultSessionTTL)
+	SessionTTL time.Duration
+	// SemanticMemoryURL — адрес SemanticMemory для разрешения событий истории сущностей
+	SemanticMemoryURL string
+	// ArchivistURL — адрес OntologicalArchivist для проверки изменений состояния по схемам сущностей (пустой — archivist.DefaultURL)
+	ArchivistURL string
+	// BanOfWorldURL — адрес BanOfWorld для проверки действий до публикации (пустой — без проверки)
+	BanOfWorldURL string
+	// TravelSpeed — скорость перемещения игрока, единиц координат мира в игровой час (0 — DefaultTravelSpeed)
+	TravelSpeed float64
+	// WorldTimeScale — игровых секунд за реальную секунду (0 — DefaultWorldTimeScale)
+	WorldTimeScale float64
+	// EngineTokens — токены внешних движков для POST /v1/state-changes (пусто — эндпоинт отключён)
+	EngineTokens []string
+	// RateLimits — лимиты классов эндпоинтов на клиента (нет класса или нули — DefaultRateLimits)
+	RateLimits map[string]RateLimit
+	// RateLimitTrustProxy — брать IP клиента без сессии из X-Forwarded-For
+	RateLimitTrustProxy bool
+	// Objects — хранилище архива повествования (nil — архив и GET /v1/narratives отключены)
+	Objects storage.ObjectStorage
+	// NarrativeBucket — бакет архива повествования (пусто — DefaultNarrativeBucket)
+	NarrativeBucket string
 }
 
 type Service struct {
 	bus           *eventbus.EventBus
 	httpServer    *HTTPServer
+	grpcServer    *GRPCServer
 	wsServer      *WebSocketServer
 	entityCache   *EntityCache
 	minioClient   *MinioClient
 	playerService *PlayerService
+	publicCache   *PublicResponseCache
+	chronicles    *ChronicleStore
+	narratives    *NarrativeArchive
+	recentEvents  *RecentEventStore
+	actionBatches *ActionBatchProcessor
+	choices       *ChoiceBook
+	sessions      *SessionManager
+	semantic      *SemanticMemoryClient
+	banOfWorld    *BanOfWorldClient
+	entitySchemas *archivist.EntitySchemas
+	travel        *TravelPlanner
+	publishPlayer func(ctx context.Context, event eventbus.Event) error
+	publishWorld  func(ctx context.Context, event eventbus.Event) error
+	rateLimiter   *RateLimiter
 	broadcast     chan []byte
 	cfg           Config
 }
@@ -36,25 +82,65 @@ func NewService(cfg Config) *Service {
 	bus := eventbus.NewEventBus(cfg.KafkaBrokers)
 	minioClient, err := NewMinioClient()
 	if err != nil {
-		log.Printf("Warning: Failed to create MinIO client: %v", err)
+		logging.Warnf("Failed to create MinIO client: %v", err)
 	}
 
-	playerService := NewPlayerService(NewEntityCache(cfg.CacheTTL), minioClient, bus)
+	// Один кэш для сущностей и игроков: события изменения сущностей сбрасывают оба
+	entityCache := NewEntityCache(cfg.CacheTTL)
+	playerService := NewPlayerService(entityCache, minioClient, bus)
+	// События игрока от запросов с сессией получают подтверждённую identity
+	publishPlayer := withSessionIdentity(bus.PublishPlayerEvent)
+	banOfWorld := NewBanOfWorldClient(cfg.BanOfWorldURL)
+	actionBatches := NewActionBatchProcessor(publishPlayer)
+	actionBatches.UseCheck(banOfWorld.Screen)
+	loadGeography := func(ctx context.Context, worldID string) (*WorldGeography, error) {
+		if minioClient == nil {
+			return nil, fmt.Errorf("%w: MinIO client not available", storage.ErrNotFound)
+		}
+		return minioClient.LoadWorldGeography(ctx, worldID)
+	}
 
-	return &Service{
+	rateLimiter := NewRateLimiter(cfg.RateLimits, cfg.RateLimitTrustProxy, publishPlayer)
+	wsServer := NewWebSocketServer()
+	wsServer.UseRateLimiter(rateLimiter)
+
+	var narratives *NarrativeArchive
+	if cfg.Objects != nil {
+		narratives = NewNarrativeArchive(cfg.Objects, cfg.NarrativeBucket)
+	}
+
+	service := &Service{
 		bus:           bus,
 		httpServer:    NewHTTPServer(cfg.HTTPAddr),
-		wsServer:      NewWebSocketServer(),
-		entityCache:   NewEntityCache(cfg.CacheTTL),
+		wsServer:      wsServer,
+		entityCache:   entityCache,
 		minioClient:   minioClient,
 		playerService: playerService,
+		publicCache:   NewPublicResponseCache(),
+		chronicles:    NewChronicleStore(),
+		narratives:    narratives,
+		recentEvents:  NewRecentEventStore(maxRecentEvents),
+		actionBatches: actionBatches,
+		choices:       NewChoiceBook(publishPlayer),
+		sessions:      NewSessionManager(cfg.SessionSecret, cfg.SessionTTL),
+		semantic:      NewSemanticMemoryClient(cfg.SemanticMemoryURL),
+		banOfWorld:    banOfWorld,
+		entitySchemas: archivist.NewEntitySchemas(archivist.NewClient(cfg.ArchivistURL, nil)),
+		travel:        NewTravelPlanner(publishPlayer, loadGeography, cfg.TravelSpeed, cfg.WorldTimeScale),
+		publishPlayer: publishPlayer,
+		publishWorld:  withSessionIdentity(bus.PublishWorldEvent),
+		rateLimiter:   rateLimiter,
 		broadcast:     make(chan []byte, 100), // Буферизованный канал для broadcast сообщений
 		cfg:           cfg,
 	}
+	if cfg.GRPCAddr != "" {
+		service.grpcServer = NewGRPCServer(service, cfg.GRPCAddr)
+	}
+	return service
 }
 
 func (s *Service) Start(ctx context.Context) {
-	log.Println("GameService started. Initializing components...")
+	logging.Infof("GameService started. Initializing components...")
 
 	// Загружаем начальные данные из MinIO
 	s.loadInitialData(ctx)
@@ -62,6 +148,11 @@ func (s *Service) Start(ctx context.Context) {
 	// Запуск HTTP сервера
 	s.httpServer.RegisterRoutes(s, s.wsServer)
 	s.httpServer.Start()
+	if s.grpcServer != nil {
+		if err := s.grpcServer.Start(); err != nil {
+			logging.Errorf("Failed to start gRPC server on %s: %v", s.cfg.GRPCAddr, err)
+		}
+	}
 
 	// Запуск WebSocket сервера
 	go s.wsServer.BroadcastLoop(s.broadcast)
@@ -82,6 +173,26 @@ func (s *Service) Start(ctx context.Context) {
 		}()
 	}
 
+	// Кэш сущностей каждого экземпляра сбрасывается по всем событиям изменения сущностей,
+	// поэтому у экземпляра своя группа, а не общая game-service-group
+	cacheTopics := []string{
+		eventbus.TopicWorldEvents,
+		eventbus.TopicGameEvents,
+		eventbus.TopicPlayerEvents,
+		eventbus.TopicSystemEvents,
+	}
+	for _, topic := range cacheTopics {
+		topic := topic
+		go s.bus.Subscribe(ctx, topic, cacheGroupID(), func(event eventbus.Event) {
+			s.entityCache.InvalidateFromEvent(event)
+		})
+	}
+
+	// Отдельная группа получает всё повествование для архива, независимо от game-service-group
+	if s.narratives != nil {
+		go s.bus.Subscribe(ctx, eventbus.TopicNarrativeOutput, "game-service-narratives-group", s.narratives.Record)
+	}
+
 	// Запуск обработчиков событий
 	entityHandler := NewEntityStreamHandler(s.entityCache, s.broadcast, s.minioClient)
 	eventHandler := NewEventStreamHandler(s.broadcast)
@@ -89,16 +200,33 @@ func (s *Service) Start(ctx context.Context) {
 	// Передаем обработчики в основной цикл
 	go s.eventProcessingLoop(entityHandler, eventHandler)
 
-	log.Println("GameService fully initialized and running.")
+	logging.Infof("GameService fully initialized and running.")
+}
+
+// cacheGroupID возвращает группу потребителей для сброса кэша этого экземпляра
+func cacheGroupID() string {
+	instanceID, err := os.Hostname()
+	if err != nil || instanceID == "" {
+		instanceID = "local"
+	}
+	return "game-service-cache-" + instanceID
 }
 
 func (s *Service) Stop() {
 	s.bus.Close()
 	s.httpServer.Stop()
+	if s.grpcServer != nil {
+		s.grpcServer.Stop()
+	}
 	close(s.broadcast)
 }
 
 func (s *Service) handleEvent(event eventbus.Event) {
+	// Все события мира попадают в буфер для GET /events/recent
+	s.recentEvents.Record(event)
+	// Изменение географии мира сбрасывает его сетку проходимости
+	s.travel.HandleEvent(event)
+
 	// Определяем тип события и передаем его соответствующему обработчику
 	switch {
 	case len(event.Type) >= len(eventbus.TypeEntity) && event.Type[:len(eventbus.TypeEntity)] == eventbus.TypeEntity:
@@ -107,6 +235,8 @@ func (s *Service) handleEvent(event eventbus.Event) {
 		entityHandler.HandleEntityEvent(event)
 	case len(event.Type) >= len(eventbus.TypeNarrative) && event.Type[:len(eventbus.TypeNarrative)] == eventbus.TypeNarrative:
 		// Повествовательные события
+		s.chronicles.Record(event)
+		s.choices.HandleEvent(event)
 		// TODO: Обработать повествовательные события
 		// message, _ := json.Marshal(map[string]interface{}{
 		// 	"type":  "narrative_event",
@@ -125,12 +255,16 @@ func (s *Service) eventProcessingLoop(entityHandler *EntityStreamHandler, eventH
 	for message := range s.broadcast {
 		// Отправляем сообщение всем подключенным WebSocket клиентам
 		s.wsServer.BroadcastMessage(message)
+		// Подписчики gRPC Subscribe получают те же события
+		if s.grpcServer != nil {
+			s.grpcServer.Broadcast(message)
+		}
 	}
 }
 
 func (s *Service) loadInitialData(ctx context.Context) {
 	if s.minioClient == nil {
-		log.Println("MinIO client not available, skipping initial data load")
+		logging.Infof("MinIO client not available, skipping initial data load")
 		return
 	}
 
@@ -140,7 +274,7 @@ func (s *Service) loadInitialData(ctx context.Context) {
 	// 2. Загрузку последних событий
 	// 3. Инициализацию игрового состояния
 
-	log.Println("Initial data loading completed")
+	logging.Infof("Initial data loading completed")
 }
 
 // GetEntity получает сущность из кэша или MinIO
@@ -162,20 +296,15 @@ func (s *Service) GetEntity(ctx context.Context, entityID, worldID string) (*ent
 		return entity, nil
 	}
 
-	return nil, fmt.Errorf("entity not found in cache and MinIO client not available")
-}
-
-// RegisterPlayer регистрирует нового игрока
-func (s *Service) RegisterPlayer(ctx context.Context, playerID, playerName, worldID string) (*entity.Entity, error) {
-	return s.playerService.RegisterPlayer(ctx, playerID, playerName, worldID)
+	return nil, fmt.Errorf("%w: entity not in cache and MinIO client not available", storage.ErrNotFound)
 }
 
-// LoginPlayer выполняет вход игрока
-func (s *Service) LoginPlayer(ctx context.Context, playerID, worldID string) (*entity.Entity, error) {
-	return s.playerService.LoginPlayer(ctx, playerID, worldID)
+// RegisterPlayer регистрирует нового игрока с паролем
+func (s *Service) RegisterPlayer(ctx context.Context, playerID, playerName, worldID, password string) (*entity.Entity, error) {
+	return s.playerService.RegisterPlayer(ctx, playerID, playerName, worldID, password)
 }
 
-// GetOrCreatePlayer получает существующего игрока или создает нового
-func (s *Service) GetOrCreatePlayer(ctx context.Context, playerID, playerName, worldID string) (*entity.Entity, error) {
-	return s.playerService.GetOrCreatePlayer(ctx, playerID, playerName, worldID)
+// LoginPlayer проверяет пароль и выполняет вход игрока
+func (s *Service) LoginPlayer(ctx context.Context, playerID, worldID, password string) (*entity.Entity, error) {
+	return s.playerService.LoginPlayer(ctx, playerID, worldID, password)
 }
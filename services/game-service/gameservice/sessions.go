@@ -0,0 +1,203 @@
+package gameservice
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+)
+
+// DefaultSessionTTL — время жизни токена сессии по умолчанию
+const DefaultSessionTTL = 24 * time.Hour
+
+// Ошибки проверки токена сессии
+var (
+	ErrMissingToken  = errors.New("session token is required")
+	ErrInvalidToken  = errors.New("invalid session token")
+	ErrTokenExpired  = errors.New("session token expired")
+	ErrForeignPlayer = errors.New("request does not match the session player")
+)
+
+// SessionClaims — содержимое токена сессии игрока
+type SessionClaims struct {
+	SessionID string `json:"sid"`
+	PlayerID  string `json:"player_id"`
+	WorldID   string `json:"world_id"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// Identity возвращает подтверждённую личность игрока для payload публикуемых событий
+func (c *SessionClaims) Identity() map[string]interface{} {
+	return map[string]interface{}{
+		"player_id":  c.PlayerID,
+		"world_id":   c.WorldID,
+		"session_id": c.SessionID,
+		"verified":   true,
+	}
+}
+
+// SessionManager выпускает и проверяет токены сессий.
+// Токен — base64url(JSON claims) + "." + base64url(HMAC-SHA256(claims)).
+type SessionManager struct {
+	secret []byte
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+// NewSessionManager создает менеджер сессий. Без секрета генерируется случайный ключ:
+// токены перестают действовать после перезапуска, и несколько реплик не примут чужие токены.
+func NewSessionManager(secret string, ttl time.Duration) *SessionManager {
+	if ttl <= 0 {
+		ttl = DefaultSessionTTL
+	}
+	key := []byte(secret)
+	if secret == "" {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			log.Fatalf("Failed to generate session secret: %v", err)
+		}
+		logging.Warnf("SESSION_SECRET is not set, using a random key; sessions will not survive restarts")
+	}
+	return &SessionManager{secret: key, ttl: ttl, now: time.Now}
+}
+
+// Issue выпускает токен сессии игрока в мире
+func (m *SessionManager) Issue(playerID, worldID string) (string, *SessionClaims, error) {
+	sid := make([]byte, 16)
+	if _, err := rand.Read(sid); err != nil {
+		return "", nil, fmt.Errorf("failed to generate session id: %w", err)
+	}
+	now := m.now().UTC()
+	claims := &SessionClaims{
+		SessionID: hex.EncodeToString(sid),
+		PlayerID:  playerID,
+		WorldID:   worldID,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(m.ttl).Unix(),
+	}
+
+	body, err := json.Marshal(claims)
+	if err != nil {
+		return "", nil, err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(body)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(m.sign(encoded)), claims, nil
+}
+
+// Verify проверяет подпись и срок действия токена
+func (m *SessionManager) Verify(token string) (*SessionClaims, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	got, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(got, m.sign(encoded)) {
+		return nil, ErrInvalidToken
+	}
+	body, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var claims SessionClaims
+	if err := json.Unmarshal(body, &claims); err != nil || claims.PlayerID == "" || claims.WorldID == "" {
+		return nil, ErrInvalidToken
+	}
+	if m.now().Unix() >= claims.ExpiresAt {
+		return nil, ErrTokenExpired
+	}
+	return &claims, nil
+}
+
+func (m *SessionManager) sign(encoded string) []byte {
+	mac := hmac.New(sha256.New, m.secret)
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
+
+type sessionContextKey struct{}
+
+// SessionFromContext возвращает сессию запроса, прошедшего RequireSession
+func SessionFromContext(ctx context.Context) (*SessionClaims, bool) {
+	claims, ok := ctx.Value(sessionContextKey{}).(*SessionClaims)
+	return claims, ok
+}
+
+// sessionToken извлекает токен из заголовка Authorization: Bearer или, для WebSocket
+// (браузер не может задать заголовки при upgrade), из параметра ?token=
+func sessionToken(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+	}
+	return r.URL.Query().Get("token")
+}
+
+// RequireSession пропускает только запросы с действующим токеном сессии и кладёт её в контекст
+func (m *SessionManager) RequireSession(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := sessionToken(r)
+		if token == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(ErrMissingToken.Error()))
+			return
+		}
+		claims, err := m.Verify(token)
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(err.Error()))
+			return
+		}
+		next(w, r.WithContext(context.WithValue(r.Context(), sessionContextKey{}, claims)))
+	}
+}
+
+// bindSessionPlayer подставляет игрока и мир сессии в незаполненные поля запроса
+// и запрещает действовать от имени другого игрока или в другом мире
+func bindSessionPlayer(ctx context.Context, playerID, worldID *string) error {
+	claims, ok := SessionFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	if playerID != nil {
+		if *playerID == "" {
+			*playerID = claims.PlayerID
+		} else if *playerID != claims.PlayerID {
+			return ErrForeignPlayer
+		}
+	}
+	if worldID != nil {
+		if *worldID == "" {
+			*worldID = claims.WorldID
+		} else if *worldID != claims.WorldID {
+			return ErrForeignPlayer
+		}
+	}
+	return nil
+}
+
+// withSessionIdentity оборачивает публикацию событий игрока: событие, опубликованное в рамках
+// запроса с сессией, получает в payload поле identity с подтверждённым игроком. BanOfWorld и
+// CityGovernor проверяют его через eventbus.VerifyPlayer и отбрасывают действия без identity.
+func withSessionIdentity(publish func(ctx context.Context, event eventbus.Event) error) func(ctx context.Context, event eventbus.Event) error {
+	return func(ctx context.Context, event eventbus.Event) error {
+		if claims, ok := SessionFromContext(ctx); ok {
+			if event.Payload == nil {
+				event.Payload = make(map[string]interface{})
+			}
+			event.Payload["identity"] = claims.Identity()
+		}
+		return publish(ctx, event)
+	}
+}
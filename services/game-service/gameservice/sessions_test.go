@@ -0,0 +1,122 @@
+package gameservice
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+)
+
+func TestSessionTokens(t *testing.T) {
+	sessions := NewSessionManager("secret", time.Hour)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	sessions.now = func() time.Time { return now }
+
+	token, issued, err := sessions.Issue("player-1", "world-1")
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+
+	claims, err := sessions.Verify(token)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if claims.PlayerID != "player-1" || claims.WorldID != "world-1" || claims.SessionID != issued.SessionID {
+		t.Errorf("unexpected claims %+v", claims)
+	}
+
+	body, signature, _ := strings.Cut(token, ".")
+	forged, _, _ := NewSessionManager("other-secret", time.Hour).Issue("player-1", "world-1")
+	_, forgedSignature, _ := strings.Cut(forged, ".")
+	for _, bad := range []string{"", "garbage", body + "." + forgedSignature, body + "x." + signature} {
+		if _, err := sessions.Verify(bad); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("expected invalid token for %q, got %v", bad, err)
+		}
+	}
+
+	now = now.Add(time.Hour)
+	if _, err := sessions.Verify(token); !errors.Is(err, ErrTokenExpired) {
+		t.Errorf("expected expired token, got %v", err)
+	}
+}
+
+func TestRequireSession(t *testing.T) {
+	sessions := NewSessionManager("secret", time.Hour)
+	token, _, _ := sessions.Issue("player-1", "world-1")
+
+	var seen *SessionClaims
+	handler := sessions.RequireSession(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = SessionFromContext(r.Context())
+	})
+
+	cases := []struct {
+		name   string
+		setup  func(r *http.Request)
+		status int
+	}{
+		{"no token", func(r *http.Request) {}, http.StatusUnauthorized},
+		{"bad token", func(r *http.Request) { r.Header.Set("Authorization", "Bearer nope") }, http.StatusUnauthorized},
+		{"bearer header", func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+token) }, http.StatusOK},
+		{"query parameter", func(r *http.Request) { r.URL.RawQuery = "token=" + token }, http.StatusOK},
+	}
+	for _, tc := range cases {
+		seen = nil
+		req := httptest.NewRequest(http.MethodGet, "/ws/events", nil)
+		tc.setup(req)
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+
+		if rec.Code != tc.status {
+			t.Errorf("%s: expected %d, got %d", tc.name, tc.status, rec.Code)
+		}
+		if (tc.status == http.StatusOK) != (seen != nil && seen.PlayerID == "player-1") {
+			t.Errorf("%s: unexpected session in context: %+v", tc.name, seen)
+		}
+	}
+}
+
+func TestBindSessionPlayer(t *testing.T) {
+	ctx := context.WithValue(context.Background(), sessionContextKey{}, &SessionClaims{PlayerID: "player-1", WorldID: "world-1"})
+
+	playerID, worldID := "", ""
+	if err := bindSessionPlayer(ctx, &playerID, &worldID); err != nil || playerID != "player-1" || worldID != "world-1" {
+		t.Errorf("expected session values to fill the request, got %q %q (%v)", playerID, worldID, err)
+	}
+
+	other := "player-2"
+	if err := bindSessionPlayer(ctx, &other, nil); !errors.Is(err, ErrForeignPlayer) {
+		t.Errorf("expected foreign player to be rejected, got %v", err)
+	}
+}
+
+func TestBatchActionsCarrySessionIdentity(t *testing.T) {
+	var published []eventbus.Event
+	processor := NewActionBatchProcessor(withSessionIdentity(func(ctx context.Context, event eventbus.Event) error {
+		published = append(published, event)
+		return nil
+	}))
+
+	ctx := context.WithValue(context.Background(), sessionContextKey{}, &SessionClaims{SessionID: "s1", PlayerID: "player-1", WorldID: "world-1"})
+	_, err := processor.Process(ctx, BatchActionsRequest{
+		PlayerID: "player-1",
+		WorldID:  "world-1",
+		Actions: []BatchAction{{
+			ClientSeq: 1,
+			Type:      eventbus.TypePlayerAction + "moved",
+			Payload:   map[string]interface{}{"identity": map[string]interface{}{"player_id": "admin"}},
+		}},
+	})
+	if err != nil || len(published) != 1 {
+		t.Fatalf("expected one published event, got %d (%v)", len(published), err)
+	}
+
+	identity, _ := published[0].Payload["identity"].(map[string]interface{})
+	if identity["player_id"] != "player-1" || identity["session_id"] != "s1" {
+		t.Errorf("expected session identity to replace the client's, got %v", identity)
+	}
+}
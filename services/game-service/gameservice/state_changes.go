@@ -0,0 +1,345 @@
+package gameservice
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"multiverse-core.io/shared/entity"
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+	"multiverse-core.io/shared/schema"
+)
+
+// TypeEntityStateChanged — тип события с изменениями состояния сущностей от внешнего движка
+const TypeEntityStateChanged = "entity.state_changed"
+
+// Ограничения POST /v1/state-changes
+const (
+	maxStateChanges          = 100
+	maxStateChangeOperations = 50
+)
+
+// Ошибки приёма изменений состояния
+var (
+	// ErrInvalidStateChanges — изменения не соответствуют формату state_changes (400)
+	ErrInvalidStateChanges = errors.New("invalid state changes")
+	// ErrStateChangesSchema — сущность после изменений не соответствует схеме своего типа (422)
+	ErrStateChangesSchema = errors.New("state changes violate entity schema")
+	// ErrEngineTokenRequired — запрос без действующего токена движка (401)
+	ErrEngineTokenRequired = errors.New("engine token is required")
+	// ErrStateChangesDisabled — токены движков не настроены (403)
+	ErrStateChangesDisabled = errors.New("engine API is disabled: no engine tokens configured")
+)
+
+// stateChangeOps — операции, которые применяет EntityManager (entity.ApplyPatch)
+var stateChangeOps = map[string]bool{
+	entity.PatchAdd:             true,
+	entity.PatchRemove:          true,
+	entity.PatchReplace:         true,
+	entity.PatchMove:            true,
+	entity.PatchCopy:            true,
+	entity.PatchTest:            true,
+	entity.PatchSet:             true,
+	entity.PatchAddToSlice:      true,
+	entity.PatchRemoveFromSlice: true,
+}
+
+// StateChange — изменения одной сущности в формате state_changes EntityManager
+type StateChange struct {
+	EntityID   string                   `json:"entity_id"`
+	Operations []map[string]interface{} `json:"operations"`
+}
+
+// StateChangesRequest — тело POST /v1/state-changes
+type StateChangesRequest struct {
+	PlayerID     string        `json:"player_id"`
+	WorldID      string        `json:"world_id"`
+	StateChanges []StateChange `json:"state_changes"`
+	// Description — необязательное описание изменения для истории и SemanticMemory
+	Description string `json:"description,omitempty"`
+}
+
+// StateChangesSchemaError — нарушения схемы типа в сущности после применения её изменений
+type StateChangesSchemaError struct {
+	EntityID   string              `json:"entity_id"`
+	EntityType string              `json:"entity_type"`
+	Violations []schema.FieldError `json:"violations"`
+}
+
+func (e *StateChangesSchemaError) Error() string {
+	return fmt.Sprintf("%v: entity %s (%s): %d violations", ErrStateChangesSchema, e.EntityID, e.EntityType, len(e.Violations))
+}
+
+func (e *StateChangesSchemaError) Unwrap() error {
+	return ErrStateChangesSchema
+}
+
+// validateStateChanges проверяет изменения до публикации: EntityManager отклоняет
+// неверный набор операций целиком, но узнать об этом клиент уже не сможет
+func validateStateChanges(changes []StateChange) error {
+	if len(changes) == 0 {
+		return fmt.Errorf("%w: state_changes must not be empty", ErrInvalidStateChanges)
+	}
+	if len(changes) > maxStateChanges {
+		return fmt.Errorf("%w: too many state changes: %d (max %d)", ErrInvalidStateChanges, len(changes), maxStateChanges)
+	}
+	for i, change := range changes {
+		if change.EntityID == "" {
+			return fmt.Errorf("%w: change %d: entity_id is required", ErrInvalidStateChanges, i)
+		}
+		if len(change.Operations) == 0 {
+			return fmt.Errorf("%w: change %d: operations must not be empty", ErrInvalidStateChanges, i)
+		}
+		if len(change.Operations) > maxStateChangeOperations {
+			return fmt.Errorf("%w: change %d: too many operations: %d (max %d)", ErrInvalidStateChanges, i, len(change.Operations), maxStateChangeOperations)
+		}
+		ops, err := parseStateChange(change)
+		if err != nil {
+			return fmt.Errorf("%w: change %d: %v", ErrInvalidStateChanges, i, err)
+		}
+		for j, op := range ops {
+			if err := validatePatchOperation(op); err != nil {
+				return fmt.Errorf("%w: change %d, operation %d: %v", ErrInvalidStateChanges, i, j, err)
+			}
+		}
+	}
+	return nil
+}
+
+// parseStateChange разбирает операции изменения одной сущности
+func parseStateChange(change StateChange) ([]entity.PatchOperation, error) {
+	raw := make([]interface{}, len(change.Operations))
+	for i, op := range change.Operations {
+		raw[i] = op
+	}
+	return entity.ParsePatch(raw)
+}
+
+// checkStateChangesSchema применяет изменения к текущему состоянию сущностей и проверяет результат
+// по схеме типа из OntologicalArchivist, как EntityManager при записи: иначе неверное значение
+// отклонилось бы уже после ответа 202. Сущности, которых нет или которые не удалось загрузить,
+// и недоступный архивариус публикацию не задерживают — их проверит EntityManager.
+func (s *Service) checkStateChangesSchema(ctx context.Context, worldID string, changes []StateChange) error {
+	if s.entitySchemas == nil {
+		return nil
+	}
+	// Несколько изменений одной сущности применяются последовательно, как в EntityManager
+	patched := make(map[string]*entity.Entity)
+	for i, change := range changes {
+		ent, ok := patched[change.EntityID]
+		if !ok {
+			loaded, err := s.GetEntity(ctx, change.EntityID, worldID)
+			if err != nil {
+				if !storage.IsNotFound(err) {
+					logging.Warnf("Schema check of entity %s skipped: %v", change.EntityID, err)
+				}
+				continue
+			}
+			copied := *loaded
+			ent = &copied
+			patched[change.EntityID] = ent
+		}
+
+		ops, err := parseStateChange(change)
+		if err != nil {
+			return fmt.Errorf("%w: change %d: %v", ErrInvalidStateChanges, i, err)
+		}
+		payload, _, err := entity.PatchDocument(ent.Payload, ops)
+		if err != nil {
+			return fmt.Errorf("%w: change %d: %v", ErrInvalidStateChanges, i, err)
+		}
+		ent.Payload = payload
+
+		violations, err := s.entitySchemas.Validate(ctx, ent.Type, payload)
+		if err != nil {
+			logging.Warnf("Schema check of entity %s skipped: %v", change.EntityID, err)
+			continue
+		}
+		if len(violations) > 0 {
+			return &StateChangesSchemaError{EntityID: change.EntityID, EntityType: ent.Type, Violations: violations}
+		}
+	}
+	return nil
+}
+
+// validatePatchOperation проверяет поля одной операции
+func validatePatchOperation(op entity.PatchOperation) error {
+	if !stateChangeOps[op.Op] {
+		return fmt.Errorf("unknown op %q", op.Op)
+	}
+	if err := validatePatchPath(op.Path); err != nil {
+		return fmt.Errorf("path: %v", err)
+	}
+	switch op.Op {
+	case entity.PatchMove, entity.PatchCopy:
+		if err := validatePatchPath(op.From); err != nil {
+			return fmt.Errorf("from: %v", err)
+		}
+	case entity.PatchRemove:
+	default:
+		if !op.HasValue {
+			return fmt.Errorf("%s requires value", op.Op)
+		}
+	}
+	return nil
+}
+
+// validatePatchPath допускает JSON Pointer ("/stats/hp") и устаревший путь через точку ("stats.hp")
+func validatePatchPath(path string) error {
+	switch {
+	case path == "" || path == "/":
+		// Замена всего payload не поддерживается: изменения описываются по полям
+		return fmt.Errorf("must point to a field")
+	case strings.HasPrefix(path, "/"):
+		for _, segment := range strings.Split(path[1:], "/") {
+			if segment == "" {
+				return fmt.Errorf("empty segment in %q", path)
+			}
+		}
+	default:
+		for _, segment := range strings.Split(path, ".") {
+			if segment == "" {
+				return fmt.Errorf("empty segment in %q", path)
+			}
+		}
+	}
+	return nil
+}
+
+// buildStateChangesEvent создает entity.state_changed для world_events.
+// Сущность и scope события — игрок, от имени которого прислан запрос, чтобы изменения попали его GM.
+func buildStateChangesEvent(req StateChangesRequest, now time.Time) eventbus.Event {
+	payload := eventbus.NewEventPayload().
+		WithEntity(req.PlayerID, "player", "").
+		WithWorld(req.WorldID).
+		WithScope(req.PlayerID, "player")
+	custom := payload.GetCustom()
+
+	changes := make([]interface{}, len(req.StateChanges))
+	for i, change := range req.StateChanges {
+		operations := make([]interface{}, len(change.Operations))
+		for j, op := range change.Operations {
+			operations[j] = op
+		}
+		changes[i] = map[string]interface{}{
+			"entity_id":  change.EntityID,
+			"operations": operations,
+		}
+	}
+	custom["state_changes"] = changes
+	if req.Description != "" {
+		custom["description"] = req.Description
+	}
+
+	event := eventbus.NewStructuredEvent(TypeEntityStateChanged, "game-service", req.WorldID, payload)
+	event.Timestamp = now.UTC()
+	event.Scope = &eventbus.ScopeRef{ID: req.PlayerID, Type: "player"}
+	return event
+}
+
+// RequireEngine пропускает только запросы внешних движков с токеном из ENGINE_API_TOKENS
+// (Authorization: Bearer <token>). Токены сессий игроков не принимаются: движок меняет
+// любые сущности мира, а игрок мог бы так начислить себе золото или изменить чужие характеристики.
+func RequireEngine(tokens []string, next http.HandlerFunc) http.HandlerFunc {
+	sums := make([][sha256.Size]byte, 0, len(tokens))
+	for _, token := range tokens {
+		if token != "" {
+			sums = append(sums, sha256.Sum256([]byte(token)))
+		}
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		if len(sums) == 0 {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(ErrStateChangesDisabled.Error()))
+			return
+		}
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "Bearer ") {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(ErrEngineTokenRequired.Error()))
+			return
+		}
+		// Сравниваем хэши за постоянное время, не раскрывая длину токена
+		got := sha256.Sum256([]byte(strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))))
+		for _, sum := range sums {
+			if subtle.ConstantTimeCompare(got[:], sum[:]) == 1 {
+				next(w, r)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(ErrEngineTokenRequired.Error()))
+	}
+}
+
+// StateChangesHandler обрабатывает POST /v1/state-changes — изменения состояния сущностей от внешнего
+// игрового движка (после RequireEngine). Изменения проверяются, в том числе по схемам типов сущностей, и публикуются как entity.state_changed в world_events,
+// откуда их применяют EntityManager и индексирует SemanticMemory.
+func (s *Service) StateChangesHandler(w http.ResponseWriter, r *http.Request) {
+	var req StateChangesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("Invalid request body"))
+		return
+	}
+	if req.PlayerID == "" || req.WorldID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("player_id and world_id are required"))
+		return
+	}
+
+	// Лимит считается на игрока запроса: один движок обслуживает многих игроков
+	client := s.rateLimiter.Client(r)
+	client.PlayerID, client.WorldID = req.PlayerID, req.WorldID
+	if allowed, wait := s.rateLimiter.Allow(RateClassStateChanges, client); !allowed {
+		s.rateLimiter.Throttled(r.Context(), RateClassStateChanges, client, r.Method+" "+r.URL.Path, wait)
+		writeRateLimited(w, wait, ErrRateLimited)
+		return
+	}
+	if err := validateStateChanges(req.StateChanges); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	if err := s.checkStateChangesSchema(r.Context(), req.WorldID, req.StateChanges); err != nil {
+		var schemaErr *StateChangesSchemaError
+		if errors.As(err, &schemaErr) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":       ErrStateChangesSchema.Error(),
+				"entity_id":   schemaErr.EntityID,
+				"entity_type": schemaErr.EntityType,
+				"violations":  schemaErr.Violations,
+			})
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	event := buildStateChangesEvent(req, time.Now())
+	if err := s.publishWorld(r.Context(), event); err != nil {
+		logging.Errorf("Failed to publish state changes of player %s: %v", req.PlayerID, err)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(fmt.Sprintf("Failed to publish state changes: %v", err)))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"event_id":   event.ID,
+		"event_type": event.Type,
+		"changes":    len(req.StateChanges),
+	})
+}
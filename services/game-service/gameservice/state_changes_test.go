@@ -0,0 +1,221 @@
+package gameservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"multiverse-core.io/shared/archivist"
+	"multiverse-core.io/shared/entity"
+	"multiverse-core.io/shared/eventbus"
+)
+
+func TestValidateStateChanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		changes []StateChange
+		valid   bool
+	}{
+		{"json patch", []StateChange{{EntityID: "npc-1", Operations: []map[string]interface{}{
+			{"op": "replace", "path": "/stats/hp", "value": 10.0},
+			{"op": "remove", "path": "/buffs/0"},
+			{"op": "move", "from": "/inventory/0", "path": "/equipped/weapon"},
+		}}}, true},
+		{"legacy ops", []StateChange{{EntityID: "npc-1", Operations: []map[string]interface{}{
+			{"op": "set", "path": "stats.hp", "value": nil},
+			{"op": "add_to_slice", "path": "inventory", "value": "sword"},
+		}}}, true},
+		{"empty", nil, false},
+		{"missing entity", []StateChange{{Operations: []map[string]interface{}{{"op": "remove", "path": "/a"}}}}, false},
+		{"no operations", []StateChange{{EntityID: "npc-1"}}, false},
+		{"unknown op", []StateChange{{EntityID: "npc-1", Operations: []map[string]interface{}{{"op": "merge", "path": "/a", "value": 1.0}}}}, false},
+		{"whole payload", []StateChange{{EntityID: "npc-1", Operations: []map[string]interface{}{{"op": "replace", "path": "/", "value": 1.0}}}}, false},
+		{"empty segment", []StateChange{{EntityID: "npc-1", Operations: []map[string]interface{}{{"op": "set", "path": "stats..hp", "value": 1.0}}}}, false},
+		{"missing value", []StateChange{{EntityID: "npc-1", Operations: []map[string]interface{}{{"op": "add", "path": "/a"}}}}, false},
+		{"missing from", []StateChange{{EntityID: "npc-1", Operations: []map[string]interface{}{{"op": "copy", "path": "/a"}}}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateStateChanges(tt.changes)
+			if tt.valid && err != nil {
+				t.Errorf("expected valid, got %v", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidStateChanges) {
+				t.Errorf("expected ErrInvalidStateChanges, got %v", err)
+			}
+		})
+	}
+}
+
+func TestBuildStateChangesEvent(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	event := buildStateChangesEvent(StateChangesRequest{
+		PlayerID: "player-1",
+		WorldID:  "world-1",
+		StateChanges: []StateChange{{EntityID: "npc-1", Operations: []map[string]interface{}{
+			{"op": "replace", "path": "/stats/hp", "value": 10.0},
+		}}},
+	}, now)
+
+	if event.Type != TypeEntityStateChanged || !event.Timestamp.Equal(now) {
+		t.Errorf("unexpected event %s at %s", event.Type, event.Timestamp)
+	}
+	if event.Scope == nil || event.Scope.ID != "player-1" || event.Scope.Type != "player" {
+		t.Errorf("expected player scope, got %+v", event.Scope)
+	}
+	if eventbus.GetWorldIDFromEvent(event) != "world-1" {
+		t.Errorf("expected world-1, got %s", eventbus.GetWorldIDFromEvent(event))
+	}
+
+	// Формат должен совпадать с тем, что применяет EntityManager
+	changes, _ := event.Payload["state_changes"].([]interface{})
+	if len(changes) != 1 {
+		t.Fatalf("expected 1 state change, got %v", event.Payload["state_changes"])
+	}
+	change := changes[0].(map[string]interface{})
+	if ref := eventbus.ExtractEntityID(change); ref == nil || ref.ID != "npc-1" {
+		t.Errorf("expected change of npc-1, got %v", ref)
+	}
+	operations, _ := change["operations"].([]interface{})
+	ops, err := entity.ParsePatch(operations)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ent := entity.NewEntity("npc-1", "npc", map[string]interface{}{"stats": map[string]interface{}{"hp": 3.0}})
+	if _, err := ent.ApplyPatch(ops); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if hp, _ := ent.GetPath("stats.hp"); hp != 10.0 {
+		t.Errorf("expected hp 10, got %v", hp)
+	}
+}
+
+func TestStateChangesRequireEngine(t *testing.T) {
+	var published []eventbus.Event
+	sessions := NewSessionManager("secret", time.Hour)
+	service := &Service{
+		sessions:    sessions,
+		rateLimiter: NewRateLimiter(map[string]RateLimit{RateClassStateChanges: {Rate: 1, Burst: 2}}, false, nil),
+		publishWorld: withSessionIdentity(func(_ context.Context, event eventbus.Event) error {
+			published = append(published, event)
+			return nil
+		}),
+	}
+	handler := RequireEngine([]string{"engine-token"}, service.StateChangesHandler)
+	playerToken, _, _ := sessions.Issue("player-1", "world-1")
+
+	send := func(handler http.HandlerFunc, token, playerID string) int {
+		body := `{"player_id": "` + playerID + `", "world_id": "world-1", "state_changes": [
+			{"entity_id": "player-1", "operations": [{"op": "replace", "path": "/gold", "value": 1000000}]}]}`
+		req := httptest.NewRequest(http.MethodPost, "/v1/state-changes", strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec.Code
+	}
+
+	// Сессия игрока не даёт права менять сущности
+	if code := send(handler, playerToken, "player-1"); code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for a player session, got %d", code)
+	}
+	if code := send(handler, "", "player-1"); code != http.StatusUnauthorized {
+		t.Errorf("expected 401 without token, got %d", code)
+	}
+	if code := send(RequireEngine(nil, service.StateChangesHandler), "engine-token", "player-1"); code != http.StatusForbidden {
+		t.Errorf("expected 403 without configured tokens, got %d", code)
+	}
+	if len(published) != 0 {
+		t.Fatalf("expected nothing published, got %d events", len(published))
+	}
+
+	// Движок ограничен на каждого игрока отдельно
+	for i := 0; i < 2; i++ {
+		if code := send(handler, "engine-token", "player-1"); code != http.StatusAccepted {
+			t.Fatalf("request %d: expected 202, got %d", i, code)
+		}
+	}
+	if code := send(handler, "engine-token", "player-1"); code != http.StatusTooManyRequests {
+		t.Errorf("expected 429 over the burst, got %d", code)
+	}
+	if code := send(handler, "engine-token", "player-2"); code != http.StatusAccepted {
+		t.Errorf("expected other player not to be limited, got %d", code)
+	}
+	if len(published) != 3 {
+		t.Errorf("expected 3 events, got %d", len(published))
+	}
+}
+
+func TestStateChangesEntitySchema(t *testing.T) {
+	archivistServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/schemas/entity/npc/latest" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"type": "object", "properties": {"payload": {
+			"type": "object",
+			"properties": {"name": {"type": "string"}, "stats": {"type": "object", "properties": {"hp": {"type": "integer", "minimum": 0}}}},
+			"required": ["name"]
+		}}}`))
+	}))
+	defer archivistServer.Close()
+
+	var published []eventbus.Event
+	service := &Service{
+		entityCache:   NewEntityCache(time.Minute),
+		entitySchemas: archivist.NewEntitySchemas(archivist.NewClient(archivistServer.URL, nil)),
+		rateLimiter:   NewRateLimiter(nil, false, nil),
+		publishWorld: func(_ context.Context, event eventbus.Event) error {
+			published = append(published, event)
+			return nil
+		},
+	}
+	service.entityCache.Set("npc-1", "world-1", entity.NewEntity("npc-1", "npc", map[string]interface{}{
+		"name": "Borin", "stats": map[string]interface{}{"hp": 10.0},
+	}))
+	handler := RequireEngine([]string{"engine-token"}, service.StateChangesHandler)
+
+	send := func(changes string) *httptest.ResponseRecorder {
+		body := `{"player_id": "player-1", "world_id": "world-1", "state_changes": ` + changes + `}`
+		req := httptest.NewRequest(http.MethodPost, "/v1/state-changes", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer engine-token")
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+
+	rec := send(`[{"entity_id": "npc-1", "operations": [{"op": "replace", "path": "/stats/hp", "value": "lots"}]}]`)
+	var resp struct {
+		EntityID   string        `json:"entity_id"`
+		Violations []interface{} `json:"violations"`
+	}
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if rec.Code != http.StatusUnprocessableEntity || resp.EntityID != "npc-1" || len(resp.Violations) != 1 {
+		t.Fatalf("expected 422 with the hp violation, got %d: %+v", rec.Code, resp)
+	}
+
+	// Изменения одной сущности применяются последовательно
+	if rec := send(`[{"entity_id": "npc-1", "operations": [{"op": "add", "path": "/stats/mp", "value": 5}]},
+		{"entity_id": "npc-1", "operations": [{"op": "move", "from": "/stats/mp", "path": "/stats/hp"}]}]`); rec.Code != http.StatusAccepted {
+		t.Errorf("expected 202 for a valid sequence, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := send(`[{"entity_id": "npc-1", "operations": [{"op": "remove", "path": "/missing"}]}]`); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an inapplicable patch, got %d", rec.Code)
+	}
+	// Сущности, которой ещё нет, проверяет EntityManager
+	if rec := send(`[{"entity_id": "npc-2", "operations": [{"op": "replace", "path": "/stats/hp", "value": "lots"}]}]`); rec.Code != http.StatusAccepted {
+		t.Errorf("expected 202 for an unknown entity, got %d", rec.Code)
+	}
+	if len(published) != 2 {
+		t.Errorf("expected 2 events, got %d", len(published))
+	}
+	if cached, _ := service.entityCache.Get("npc-1", "world-1"); cached.Payload["stats"].(map[string]interface{})["hp"] != 10.0 {
+		t.Errorf("the check must not change the cached entity, got %v", cached.Payload)
+	}
+}
@@ -0,0 +1,389 @@
+package gameservice
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/entity"
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+	"multiverse-core.io/shared/spatial"
+)
+
+// EventPlayerTravelling — промежуточное событие перемещения игрока по маршруту
+const EventPlayerTravelling = "player.travelling"
+
+// Параметры перемещения
+const (
+	// DefaultTravelSpeed — скорость игрока по равнине, единиц координат мира в игровой час
+	DefaultTravelSpeed = 5.0
+	// DefaultWorldTimeScale — сколько игровых секунд проходит за одну реальную
+	DefaultWorldTimeScale = 60.0
+
+	// maxTravelUpdates — наибольшее число промежуточных player.travelling за путешествие
+	maxTravelUpdates = 10
+	// minTravelUpdateInterval — промежуточные события не публикуются чаще (в реальном времени)
+	minTravelUpdateInterval = time.Second
+
+	// navGridCellsPerSide — разрешение сетки проходимости по большей стороне мира
+	navGridCellsPerSide = 200
+	// riverCost — переправа через реку
+	riverCost = 3.0
+)
+
+// biomeCosts — множители длины пути по биому региона (ключевое слово на английском или русском)
+var biomeCosts = []struct {
+	keywords []string
+	cost     float64
+}{
+	{[]string{"mountain", "peak", "гор", "скал", "пик"}, 3},
+	{[]string{"swamp", "marsh", "bog", "болот", "топ"}, 2.5},
+	{[]string{"snow", "ice", "tundra", "glacier", "снег", "лед", "лёд", "тундр"}, 2},
+	{[]string{"desert", "dune", "пустын", "дюн"}, 1.8},
+	{[]string{"jungle", "forest", "wood", "джунгл", "лес"}, 1.5},
+	{[]string{"hill", "холм"}, 1.3},
+}
+
+// biomeCost возвращает множитель длины пути для биома (1 — равнина и неизвестные биомы)
+func biomeCost(biome string) float64 {
+	biome = strings.ToLower(biome)
+	for _, entry := range biomeCosts {
+		for _, keyword := range entry.keywords {
+			if strings.Contains(biome, keyword) {
+				return entry.cost
+			}
+		}
+	}
+	return 1
+}
+
+// WorldGeography — география мира из состояния WorldGenerator (worlds/{world_id}/world.json)
+type WorldGeography struct {
+	Bounds struct {
+		MinX float64 `json:"min_x"`
+		MinY float64 `json:"min_y"`
+		MaxX float64 `json:"max_x"`
+		MaxY float64 `json:"max_y"`
+	} `json:"bounds"`
+	Geography struct {
+		Regions     []GeographyArea `json:"regions"`
+		WaterBodies []GeographyArea `json:"water_bodies"`
+	} `json:"geography"`
+}
+
+// GeographyArea — регион или водоём: круг площади Size вокруг Coordinates
+type GeographyArea struct {
+	Name        string      `json:"name"`
+	Biome       string      `json:"biome,omitempty"`
+	Type        string      `json:"type,omitempty"` // для водоёмов: river, sea, lake
+	Coordinates ActionPoint `json:"coordinates"`
+	Size        float64     `json:"size"`
+}
+
+func (a GeographyArea) circle() spatial.Circle {
+	return spatial.Circle{
+		Center: spatial.Point{X: a.Coordinates.X, Y: a.Coordinates.Y},
+		Radius: math.Sqrt(math.Max(a.Size, 0) / math.Pi),
+	}
+}
+
+// buildNavGrid строит сетку проходимости: регионы задают стоимость по биому,
+// реки замедляют, остальные водоёмы непроходимы
+func buildNavGrid(geo *WorldGeography) *spatial.NavGrid {
+	bounds := spatial.BoundingBox{
+		Min: spatial.Point{X: geo.Bounds.MinX, Y: geo.Bounds.MinY},
+		Max: spatial.Point{X: geo.Bounds.MaxX, Y: geo.Bounds.MaxY},
+	}
+	if bounds.Max.X <= bounds.Min.X || bounds.Max.Y <= bounds.Min.Y {
+		// Миры до появления границ занимают 100×100
+		bounds = spatial.BoundingBox{Max: spatial.Point{X: 100, Y: 100}}
+	}
+	side := math.Max(bounds.Max.X-bounds.Min.X, bounds.Max.Y-bounds.Min.Y)
+	grid := spatial.NewNavGrid(bounds, side/navGridCellsPerSide)
+
+	for _, region := range geo.Geography.Regions {
+		grid.SetCost(region.circle(), biomeCost(region.Biome))
+	}
+	for _, water := range geo.Geography.WaterBodies {
+		if strings.EqualFold(water.Type, "river") {
+			grid.SetCost(water.circle(), riverCost)
+		} else {
+			grid.SetCost(water.circle(), math.Inf(1))
+		}
+	}
+	return grid
+}
+
+// Journey — путешествие игрока по маршруту
+type Journey struct {
+	PlayerID      string        `json:"player_id"`
+	WorldID       string        `json:"world_id"`
+	From          ActionPoint   `json:"from"`
+	To            ActionPoint   `json:"to"`
+	Waypoints     []ActionPoint `json:"waypoints"`
+	Distance      float64       `json:"distance"`
+	WorldDuration time.Duration `json:"-"`
+	StartedAt     time.Time     `json:"started_at"`
+	ArrivesAt     time.Time     `json:"arrives_at"`
+
+	playerName string
+	route      *spatial.Route
+	cancel     chan struct{}
+}
+
+// progress возвращает долю пройденного пути в момент now
+func (j *Journey) progress(now time.Time) float64 {
+	total := j.ArrivesAt.Sub(j.StartedAt)
+	if total <= 0 {
+		return 1
+	}
+	return math.Min(math.Max(float64(now.Sub(j.StartedAt))/float64(total), 0), 1)
+}
+
+// TravelPlanner прокладывает маршруты перемещения игроков по географии мира и ведёт путешествия:
+// вместо мгновенного player.moved публикуются player.travelling по ходу движения,
+// а player.moved — по прибытии
+type TravelPlanner struct {
+	publish       func(ctx context.Context, event eventbus.Event) error
+	loadGeography func(ctx context.Context, worldID string) (*WorldGeography, error)
+	speed         float64 // единиц координат в игровой час
+	timeScale     float64 // игровых секунд в реальной
+	now           func() time.Time
+	after         func(d time.Duration) <-chan time.Time
+
+	grids     map[string]*spatial.NavGrid // мир → сетка проходимости
+	journeys  map[string]*Journey         // игрок → текущее путешествие
+	positions map[string]ActionPoint      // мир/игрок → точка последнего прибытия
+	mutex     sync.Mutex
+}
+
+// NewTravelPlanner создает планировщик путешествий (speed и timeScale <= 0 — значения по умолчанию)
+func NewTravelPlanner(publish func(ctx context.Context, event eventbus.Event) error,
+	loadGeography func(ctx context.Context, worldID string) (*WorldGeography, error),
+	speed, timeScale float64) *TravelPlanner {
+	if speed <= 0 {
+		speed = DefaultTravelSpeed
+	}
+	if timeScale <= 0 {
+		timeScale = DefaultWorldTimeScale
+	}
+	return &TravelPlanner{
+		publish:       publish,
+		loadGeography: loadGeography,
+		speed:         speed,
+		timeScale:     timeScale,
+		now:           time.Now,
+		after:         time.After,
+		grids:         make(map[string]*spatial.NavGrid),
+		journeys:      make(map[string]*Journey),
+		positions:     make(map[string]ActionPoint),
+	}
+}
+
+// HandleEvent сбрасывает сетку проходимости мира, география которого изменилась
+func (p *TravelPlanner) HandleEvent(event eventbus.Event) {
+	switch event.Type {
+	case "world.map.generated", "world.region.expanded":
+		worldID := eventbus.GetWorldIDFromEvent(event)
+		p.mutex.Lock()
+		delete(p.grids, worldID)
+		p.mutex.Unlock()
+	}
+}
+
+// Plan прокладывает маршрут игрока к точке to. Возвращает nil без ошибки, если перемещение
+// остаётся мгновенным: текущая позиция игрока неизвестна или у мира нет сгенерированной географии.
+// Недостижимая точка — ErrCommandNotAllowed.
+func (p *TravelPlanner) Plan(ctx context.Context, player *entity.Entity, worldID string, to ActionPoint) (*Journey, error) {
+	from, ok := p.position(player, worldID)
+	if !ok {
+		return nil, nil
+	}
+	grid, err := p.grid(ctx, worldID)
+	if err != nil {
+		if storage.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to load world geography: %w", err)
+	}
+
+	route, err := grid.FindPath(spatial.Point{X: from.X, Y: from.Y}, spatial.Point{X: to.X, Y: to.Y})
+	if err != nil {
+		return nil, fmt.Errorf("%w: destination is unreachable: %v", ErrCommandNotAllowed, err)
+	}
+
+	worldDuration := route.TravelTime(p.speed)
+	now := p.now().UTC()
+	journey := &Journey{
+		PlayerID:      player.ID,
+		WorldID:       worldID,
+		From:          from,
+		To:            to,
+		Waypoints:     make([]ActionPoint, 0, len(route.Waypoints)),
+		Distance:      route.Distance,
+		WorldDuration: worldDuration,
+		StartedAt:     now,
+		ArrivesAt:     now.Add(time.Duration(float64(worldDuration) / p.timeScale)),
+		route:         route,
+		cancel:        make(chan struct{}),
+	}
+	journey.playerName, _ = player.Payload["name"].(string)
+	for _, point := range route.Waypoints {
+		journey.Waypoints = append(journey.Waypoints, ActionPoint{X: point.X, Y: point.Y})
+	}
+	return journey, nil
+}
+
+// Start публикует начало путешествия и в фоне — промежуточные player.travelling и событие
+// прибытия arrival. Новое путешествие игрока прерывает предыдущее.
+// ctx не должен отменяться вместе с HTTP-запросом (см. context.WithoutCancel).
+func (p *TravelPlanner) Start(ctx context.Context, journey *Journey, arrival eventbus.Event) error {
+	if err := p.publish(ctx, p.travellingEvent(journey, 0)); err != nil {
+		return fmt.Errorf("failed to publish travel start: %w", err)
+	}
+
+	p.mutex.Lock()
+	if previous, ok := p.journeys[journey.PlayerID]; ok {
+		close(previous.cancel)
+	}
+	p.journeys[journey.PlayerID] = journey
+	p.mutex.Unlock()
+
+	go p.run(ctx, journey, arrival)
+	return nil
+}
+
+// run публикует промежуточные события через равные интервалы и событие прибытия
+func (p *TravelPlanner) run(ctx context.Context, journey *Journey, arrival eventbus.Event) {
+	total := journey.ArrivesAt.Sub(journey.StartedAt)
+	updates := maxTravelUpdates
+	if limit := int(total / minTravelUpdateInterval); limit < updates {
+		updates = limit
+	}
+	interval := total / time.Duration(updates+1)
+
+	for i := 1; i <= updates+1; i++ {
+		select {
+		case <-journey.cancel:
+			return
+		case <-ctx.Done():
+			return
+		case <-p.after(interval):
+		}
+		if i <= updates {
+			if err := p.publish(ctx, p.travellingEvent(journey, float64(i)/float64(updates+1))); err != nil {
+				logging.Errorf("Failed to publish travel progress for %s: %v", journey.PlayerID, err)
+			}
+		}
+	}
+
+	p.mutex.Lock()
+	if p.journeys[journey.PlayerID] != journey {
+		p.mutex.Unlock()
+		return
+	}
+	delete(p.journeys, journey.PlayerID)
+	p.positions[journey.WorldID+"/"+journey.PlayerID] = journey.To
+	p.mutex.Unlock()
+
+	arrival.Timestamp = p.now().UTC()
+	eventbus.SetNested(arrival.Payload, "travel", map[string]interface{}{
+		"from":             map[string]interface{}{"x": journey.From.X, "y": journey.From.Y},
+		"distance":         journey.Distance,
+		"world_duration_s": journey.WorldDuration.Seconds(),
+		"started_at":       journey.StartedAt.Format(time.RFC3339),
+	})
+	if err := p.publish(ctx, arrival); err != nil {
+		logging.Errorf("Failed to publish arrival of %s: %v", journey.PlayerID, err)
+	}
+}
+
+// travellingEvent создает player.travelling с позицией игрока на доле fraction маршрута.
+// Первое событие путешествия содержит весь маршрут.
+func (p *TravelPlanner) travellingEvent(journey *Journey, fraction float64) eventbus.Event {
+	point := journey.route.PointAt(fraction)
+
+	payload := eventbus.NewEventPayload().
+		WithEntity(journey.PlayerID, "player", journey.playerName).
+		WithWorld(journey.WorldID).
+		WithScope(journey.PlayerID, "player")
+	custom := payload.GetCustom()
+	custom["location"] = map[string]interface{}{"x": point.X, "y": point.Y}
+	travel := map[string]interface{}{
+		"from":             map[string]interface{}{"x": journey.From.X, "y": journey.From.Y},
+		"to":               map[string]interface{}{"x": journey.To.X, "y": journey.To.Y},
+		"progress":         fraction,
+		"distance":         journey.Distance,
+		"world_duration_s": journey.WorldDuration.Seconds(),
+		"world_elapsed_s":  journey.WorldDuration.Seconds() * fraction,
+		"arrives_at":       journey.ArrivesAt.Format(time.RFC3339),
+	}
+	if fraction == 0 {
+		travel["waypoints"] = journey.Waypoints
+	}
+	custom["travel"] = travel
+
+	event := eventbus.NewStructuredEvent(EventPlayerTravelling, "game-service", journey.WorldID, payload)
+	event.Timestamp = p.now().UTC()
+	event.Scope = &eventbus.ScopeRef{ID: journey.PlayerID, Type: "player"}
+	return event
+}
+
+// position возвращает текущую позицию игрока: точку на маршруте текущего путешествия,
+// точку последнего прибытия или координаты из сущности
+func (p *TravelPlanner) position(player *entity.Entity, worldID string) (ActionPoint, bool) {
+	p.mutex.Lock()
+	journey, travelling := p.journeys[player.ID]
+	last, arrived := p.positions[worldID+"/"+player.ID]
+	p.mutex.Unlock()
+
+	if travelling && journey.WorldID == worldID {
+		point := journey.route.PointAt(journey.progress(p.now()))
+		return ActionPoint{X: point.X, Y: point.Y}, true
+	}
+	if arrived {
+		return last, true
+	}
+	for _, path := range []string{"location", "coordinates", "location.coordinates"} {
+		raw, ok := player.GetPath(path)
+		if !ok {
+			continue
+		}
+		point, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		x, okX := point["x"].(float64)
+		y, okY := point["y"].(float64)
+		if okX && okY {
+			return ActionPoint{X: x, Y: y}, true
+		}
+	}
+	return ActionPoint{}, false
+}
+
+// grid возвращает сетку проходимости мира, строя её по географии при первом обращении
+func (p *TravelPlanner) grid(ctx context.Context, worldID string) (*spatial.NavGrid, error) {
+	p.mutex.Lock()
+	grid, ok := p.grids[worldID]
+	p.mutex.Unlock()
+	if ok {
+		return grid, nil
+	}
+
+	geo, err := p.loadGeography(ctx, worldID)
+	if err != nil {
+		return nil, err
+	}
+	grid = buildNavGrid(geo)
+
+	p.mutex.Lock()
+	p.grids[worldID] = grid
+	p.mutex.Unlock()
+	return grid, nil
+}
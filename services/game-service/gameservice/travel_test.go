@@ -0,0 +1,139 @@
+package gameservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"multiverse-core.io/shared/entity"
+	"multiverse-core.io/shared/eventbus"
+	storage "multiverse-core.io/shared/minio"
+)
+
+// testGeography — мир 100×100 с озером в центре
+func testGeography() *WorldGeography {
+	geo := &WorldGeography{}
+	geo.Bounds.MaxX, geo.Bounds.MaxY = 100, 100
+	geo.Geography.Regions = []GeographyArea{{Name: "Долина", Biome: "plains", Coordinates: ActionPoint{X: 50, Y: 50}, Size: 5000}}
+	geo.Geography.WaterBodies = []GeographyArea{{Name: "Зеркальное озеро", Type: "lake", Coordinates: ActionPoint{X: 50, Y: 50}, Size: 700}}
+	return geo
+}
+
+func travellingPlayer(x, y float64) *entity.Entity {
+	return entity.NewEntity("player:kain", "player", map[string]interface{}{
+		"name":     "Каин",
+		"location": map[string]interface{}{"world_id": "world-1", "x": x, "y": y},
+	})
+}
+
+func TestBiomeCost(t *testing.T) {
+	cases := map[string]float64{"Mountains": 3, "Болота": 2.5, "Тёмный лес": 1.5, "plains": 1, "": 1}
+	for biome, want := range cases {
+		if got := biomeCost(biome); got != want {
+			t.Errorf("biomeCost(%q) = %v, want %v", biome, got, want)
+		}
+	}
+}
+
+func TestTravelPlannerJourney(t *testing.T) {
+	var mu sync.Mutex
+	var published []eventbus.Event
+	arrived := make(chan struct{})
+	planner := NewTravelPlanner(func(ctx context.Context, event eventbus.Event) error {
+		mu.Lock()
+		published = append(published, event)
+		mu.Unlock()
+		if event.Type == "player.moved" {
+			close(arrived)
+		}
+		return nil
+	}, func(ctx context.Context, worldID string) (*WorldGeography, error) {
+		return testGeography(), nil
+	}, 5, 60)
+	planner.after = func(time.Duration) <-chan time.Time {
+		ch := make(chan time.Time, 1)
+		ch <- time.Now()
+		return ch
+	}
+
+	player := travellingPlayer(20, 50)
+	journey, err := planner.Plan(context.Background(), player, "world-1", ActionPoint{X: 80, Y: 50})
+	if err != nil || journey == nil {
+		t.Fatalf("Plan: %v, %v", journey, err)
+	}
+	// Озеро радиусом ~15 на прямой: маршрут обходит его
+	if journey.Distance <= 60 || len(journey.Waypoints) < 3 {
+		t.Errorf("route must go around the lake, got %+v", journey)
+	}
+	if journey.WorldDuration < 12*time.Hour || !journey.ArrivesAt.After(journey.StartedAt) {
+		t.Errorf("unexpected travel time %v, arrives at %v", journey.WorldDuration, journey.ArrivesAt)
+	}
+
+	arrival, err := buildCommandEvent(player, ActionCommand{Command: CommandMove, WorldID: "world-1", Location: &ActionPoint{X: 80, Y: 50}}, time.Now())
+	if err != nil {
+		t.Fatalf("buildCommandEvent: %v", err)
+	}
+	if err := planner.Start(context.Background(), journey, arrival); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	select {
+	case <-arrived:
+	case <-time.After(time.Second):
+		t.Fatal("player did not arrive")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(published) != maxTravelUpdates+2 {
+		t.Fatalf("expected start, %d updates and arrival, got %d events", maxTravelUpdates, len(published))
+	}
+	first := published[0]
+	if first.Type != EventPlayerTravelling {
+		t.Errorf("first event = %s", first.Type)
+	}
+	if _, ok := first.Path().GetAny("travel.waypoints"); !ok {
+		t.Errorf("first travelling event must carry the route")
+	}
+	if progress, _ := published[5].Path().GetFloat("travel.progress"); progress <= 0 || progress >= 1 {
+		t.Errorf("intermediate progress = %v", progress)
+	}
+	if distance, _ := published[len(published)-1].Path().GetFloat("travel.distance"); distance != journey.Distance {
+		t.Errorf("arrival must describe the journey, got distance %v", distance)
+	}
+
+	// После прибытия следующий маршрут начинается из точки назначения
+	if from, ok := planner.position(player, "world-1"); !ok || from != (ActionPoint{X: 80, Y: 50}) {
+		t.Errorf("position after arrival = %v", from)
+	}
+}
+
+func TestTravelPlannerInstantAndUnreachable(t *testing.T) {
+	loadErr := error(nil)
+	planner := NewTravelPlanner(func(ctx context.Context, event eventbus.Event) error { return nil },
+		func(ctx context.Context, worldID string) (*WorldGeography, error) {
+			if loadErr != nil {
+				return nil, loadErr
+			}
+			return testGeography(), nil
+		}, 0, 0)
+
+	// Позиция игрока неизвестна — мгновенное перемещение
+	noPosition := entity.NewEntity("player:abel", "player", map[string]interface{}{"name": "Авель"})
+	if journey, err := planner.Plan(context.Background(), noPosition, "world-1", ActionPoint{X: 10, Y: 10}); journey != nil || err != nil {
+		t.Errorf("expected instant move without position, got %v, %v", journey, err)
+	}
+
+	// Точка в озере недостижима
+	if _, err := planner.Plan(context.Background(), travellingPlayer(20, 50), "world-1", ActionPoint{X: 50, Y: 50}); !errors.Is(err, ErrCommandNotAllowed) {
+		t.Errorf("expected ErrCommandNotAllowed for lake, got %v", err)
+	}
+
+	// Мир без географии — мгновенное перемещение
+	loadErr = fmt.Errorf("%w: no world record", storage.ErrNotFound)
+	if journey, err := planner.Plan(context.Background(), travellingPlayer(20, 50), "world-2", ActionPoint{X: 10, Y: 10}); journey != nil || err != nil {
+		t.Errorf("expected instant move without geography, got %v, %v", journey, err)
+	}
+}
@@ -2,9 +2,13 @@ package gameservice
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
+	"strings"
 	"sync"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
 
 	"github.com/gorilla/websocket"
 )
@@ -16,68 +20,390 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
+// Subscription — фильтр событий клиента. Пустое поле не ограничивает выборку.
+// scope_ids применяется только к событиям со scope: события уровня мира без scope доставляются всем подписчикам мира.
+// event_types — точные типы или префиксы со звёздочкой ("narrative.*").
+type Subscription struct {
+	WorldID    string   `json:"world_id,omitempty"`
+	ScopeIDs   []string `json:"scope_ids,omitempty"`
+	EventTypes []string `json:"event_types,omitempty"`
+}
+
+// Matches проверяет, подходит ли событие под подписку.
+func (s Subscription) Matches(event eventbus.Event) bool {
+	if s.WorldID != "" && eventbus.GetWorldIDFromEvent(event) != s.WorldID {
+		return false
+	}
+	if len(s.ScopeIDs) > 0 {
+		if scope := eventbus.GetScopeFromEvent(event); scope != nil && scope.ID != "" && !containsString(s.ScopeIDs, scope.ID) {
+			return false
+		}
+	}
+	if len(s.EventTypes) > 0 {
+		matched := false
+		for _, pattern := range s.EventTypes {
+			if pattern == event.Type || (strings.HasSuffix(pattern, "*") && strings.HasPrefix(event.Type, strings.TrimSuffix(pattern, "*"))) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// Параметры повторной доставки событий после переподключения
+const (
+	// defaultReplayBufferSize — сколько последних событий хранится для каждой сессии
+	defaultReplayBufferSize = 256
+	// defaultReplayRetention — сколько хранится поток отключившейся сессии
+	defaultReplayRetention = 2 * time.Minute
+)
+
+// clientMessage — управляющее сообщение клиента.
+// {"subscribe": {...}} добавляет подписку; {"unsubscribe": {...}} удаляет подписки с тем же world_id,
+// а {"unsubscribe": {}} — все подписки (клиент снова получает все события). {"resume": {"last_seq": N}} включает нумерацию событий
+// и повторно отправляет пропущенные после N.
+type clientMessage struct {
+	Subscribe   *Subscription  `json:"subscribe,omitempty"`
+	Unsubscribe *Subscription  `json:"unsubscribe,omitempty"`
+	Resume      *ResumeRequest `json:"resume,omitempty"`
+}
+
+// ResumeRequest — рукопожатие возобновления: последний полученный клиентом seq (0 — ничего не получено)
+type ResumeRequest struct {
+	LastSeq uint64 `json:"last_seq"`
+}
+
+// sequencedMessage — событие в нумерованном потоке сессии.
+// После resume клиент получает события в виде {"type": "event", "seq": N, "event": {...}}.
+type sequencedMessage struct {
+	Type  string          `json:"type"`
+	Seq   uint64          `json:"seq"`
+	Event json.RawMessage `json:"event"`
+}
+
+// replayStream — поток событий сессии игрока. Переживает переподключения: подписки, нумерация
+// и буфер последних событий сохраняются, пока сессия не пробудет отключённой дольше retention.
+type replayStream struct {
+	client         *wsClient
+	conn           *websocket.Conn // nil, пока клиент отключён
+	sequenced      bool            // клиент выполнил resume и ждёт нумерованные события
+	seq            uint64
+	buffer         []sequencedMessage
+	disconnectedAt time.Time
+}
+
+// record присваивает событию следующий номер и сохраняет его в буфере
+func (st *replayStream) record(message []byte, capacity int) sequencedMessage {
+	st.seq++
+	msg := sequencedMessage{Type: "event", Seq: st.seq, Event: json.RawMessage(message)}
+	if len(st.buffer) >= capacity {
+		copy(st.buffer, st.buffer[1:])
+		st.buffer = st.buffer[:len(st.buffer)-1]
+	}
+	st.buffer = append(st.buffer, msg)
+	return msg
+}
+
+// since возвращает события после lastSeq. ok = false, если часть пропущенных событий
+// уже вытеснена из буфера или lastSeq из другого потока (например, до перезапуска сервиса).
+func (st *replayStream) since(lastSeq uint64) ([]sequencedMessage, bool) {
+	if lastSeq > st.seq {
+		return nil, false
+	}
+	if lastSeq == st.seq {
+		return nil, true
+	}
+	if len(st.buffer) == 0 || st.buffer[0].Seq > lastSeq+1 {
+		return nil, false
+	}
+	return st.buffer[lastSeq+1-st.buffer[0].Seq:], true
+}
+
+// send отправляет событие в подключение потока в формате, который выбрал клиент
+func (st *replayStream) send(msg sequencedMessage) error {
+	if !st.sequenced {
+		return st.conn.WriteMessage(websocket.TextMessage, msg.Event)
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return st.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+// wsClient — состояние подключения. Пока у клиента нет подписок, он получает все события
+// (как до появления подписок); с подписками — только подходящие под его фильтры.
+// Подключение с сессией игрока получает события только мира сессии.
+type wsClient struct {
+	filtered      bool
+	subscriptions []Subscription
+	worldID       string // мир сессии игрока; пусто — подключение без сессии
+}
+
+// accepts проверяет, нужно ли отправлять событие клиенту.
+func (c *wsClient) accepts(event eventbus.Event) bool {
+	if c.worldID != "" && eventbus.GetWorldIDFromEvent(event) != c.worldID {
+		return false
+	}
+	if !c.filtered {
+		return true
+	}
+	for _, sub := range c.subscriptions {
+		if sub.Matches(event) {
+			return true
+		}
+	}
+	return false
+}
+
+// apply применяет управляющее сообщение и возвращает ответ клиенту.
+func (c *wsClient) apply(msg clientMessage) map[string]interface{} {
+	switch {
+	case msg.Subscribe != nil:
+		sub := *msg.Subscribe
+		if c.worldID != "" {
+			// Подписка сессии ограничена её миром
+			if sub.WorldID != "" && sub.WorldID != c.worldID {
+				return map[string]interface{}{"type": "error", "error": "subscription to another world is not allowed"}
+			}
+			sub.WorldID = c.worldID
+		}
+		c.filtered = true
+		c.subscriptions = append(c.subscriptions, sub)
+	case msg.Unsubscribe != nil:
+		kept := c.subscriptions[:0]
+		for _, sub := range c.subscriptions {
+			if msg.Unsubscribe.WorldID != "" && sub.WorldID != msg.Unsubscribe.WorldID {
+				kept = append(kept, sub)
+			}
+		}
+		c.subscriptions = kept
+		c.filtered = len(kept) > 0
+	default:
+		return map[string]interface{}{"type": "error", "error": "expected subscribe or unsubscribe"}
+	}
+	return map[string]interface{}{"type": "subscriptions", "subscriptions": c.subscriptions}
+}
+
 type WebSocketServer struct {
-	clients   map[*websocket.Conn]bool
-	broadcast chan []byte
-	mutex     sync.Mutex // Мьютекс для синхронизации доступа к clients
+	clients map[*websocket.Conn]*wsClient
+	// sessions — потоки подключений с сессией игрока по ID сессии
+	sessions        map[string]*replayStream
+	replayBuffer    int
+	replayRetention time.Duration
+	broadcast       chan []byte
+	// limiter ограничивает частоту сообщений клиента (nil — без ограничения)
+	limiter *RateLimiter
+	mutex   sync.Mutex // Мьютекс для синхронизации доступа к clients, sessions и записи в соединения
 }
 
 func NewWebSocketServer() *WebSocketServer {
 	return &WebSocketServer{
-		clients:   make(map[*websocket.Conn]bool),
-		broadcast: make(chan []byte),
+		clients:         make(map[*websocket.Conn]*wsClient),
+		sessions:        make(map[string]*replayStream),
+		replayBuffer:    defaultReplayBufferSize,
+		replayRetention: defaultReplayRetention,
+		broadcast:       make(chan []byte),
+	}
+}
+
+// UseRateLimiter ограничивает сообщения клиентов лимитом класса RateClassWebSocket
+func (w *WebSocketServer) UseRateLimiter(limiter *RateLimiter) {
+	w.limiter = limiter
+}
+
+// register регистрирует подключение. Подключение с сессией присоединяется к её потоку
+// (новое подключение той же сессии вытесняет предыдущее), остальные получают собственный фильтр.
+func (w *WebSocketServer) register(conn *websocket.Conn, claims *SessionClaims) (*wsClient, *replayStream) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if claims == nil || claims.SessionID == "" {
+		client := &wsClient{subscriptions: make([]Subscription, 0)}
+		w.clients[conn] = client
+		return client, nil
+	}
+
+	stream, exists := w.sessions[claims.SessionID]
+	if !exists {
+		stream = &replayStream{client: &wsClient{subscriptions: make([]Subscription, 0), worldID: claims.WorldID}}
+		w.sessions[claims.SessionID] = stream
+	}
+	// Формат событий выбирается заново: до resume клиент получает события без нумерации
+	stream.conn, stream.sequenced = conn, false
+	return stream.client, stream
+}
+
+// unregister отключает подключение; поток сессии остаётся для возобновления
+func (w *WebSocketServer) unregister(conn *websocket.Conn, stream *replayStream) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if stream == nil {
+		delete(w.clients, conn)
+		return
+	}
+	if stream.conn == conn {
+		stream.conn = nil
+		stream.disconnectedAt = time.Now()
+	}
+}
+
+// resume включает нумерацию событий потока и отправляет события после lastSeq.
+// Если пропущенных событий уже нет в буфере, клиент должен перезагрузить состояние целиком.
+// Вызывается под мьютексом.
+func (w *WebSocketServer) resume(stream *replayStream, req ResumeRequest) map[string]interface{} {
+	stream.sequenced = true
+	missed, ok := stream.since(req.LastSeq)
+	if !ok {
+		return map[string]interface{}{"type": "resume_failed", "seq": stream.seq, "error": "missed events are no longer available"}
 	}
+	for _, msg := range missed {
+		if err := stream.send(msg); err != nil {
+			return map[string]interface{}{"type": "resume_failed", "seq": stream.seq, "error": err.Error()}
+		}
+	}
+	return map[string]interface{}{"type": "resumed", "seq": stream.seq, "replayed": len(missed)}
 }
 
 func (w *WebSocketServer) HandleWebSocket(wr http.ResponseWriter, r *http.Request) {
 	conn, err := upgrader.Upgrade(wr, r, nil)
 	if err != nil {
-		log.Printf("Failed to upgrade connection: %v", err)
+		logging.Errorf("Failed to upgrade connection: %v", err)
 		return
 	}
 	defer conn.Close()
 
-	// Регистрируем нового клиента с блокировкой
-	w.mutex.Lock()
-	w.clients[conn] = true
-	w.mutex.Unlock()
+	// Регистрируем нового клиента; подключения с сессией продолжают её поток событий
+	claims, _ := SessionFromContext(r.Context())
+	client, stream := w.register(conn, claims)
+
+	var rateClient RateClient
+	if w.limiter != nil {
+		rateClient = w.limiter.Client(r)
+	}
+	throttled := 0
 
 	// Обрабатываем входящие сообщения от клиента
 	for {
 		_, message, err := conn.ReadMessage()
 		if err != nil {
-			log.Printf("Client disconnected: %v", err)
-			// Удаляем клиента с блокировкой
-			w.mutex.Lock()
-			delete(w.clients, conn)
-			w.mutex.Unlock()
+			logging.Infof("Client disconnected: %v", err)
+			w.unregister(conn, stream)
 			break
 		}
 
-		// Обрабатываем сообщение от клиента
-		// Пытаемся разобрать сообщение как событие
-		var event map[string]interface{}
-		if err := json.Unmarshal(message, &event); err != nil {
-			log.Printf("Failed to parse client message: %v", err)
+		// Сообщения сверх лимита отклоняются; клиент, продолжающий слать их подряд, отключается
+		if w.limiter != nil {
+			if allowed, wait := w.limiter.Allow(RateClassWebSocket, rateClient); !allowed {
+				w.limiter.Throttled(r.Context(), RateClassWebSocket, rateClient, r.URL.Path, wait)
+				if throttled++; throttled >= maxWSThrottledMessages {
+					w.closeThrottled(conn, stream)
+					break
+				}
+				w.reply(conn, map[string]interface{}{"type": "error", "error": ErrRateLimited.Error(), "retry_after_ms": wait.Milliseconds()})
+				continue
+			}
+			throttled = 0
+		}
+
+		var msg clientMessage
+		if err := json.Unmarshal(message, &msg); err != nil {
+			logging.Errorf("Failed to parse client message: %v", err)
 			continue
 		}
 
-		// TODO: Реализовать обработку действий от клиента
-		log.Printf("Received message from client: %v", event)
+		// Подписки меняются и подтверждаются под тем же мьютексом, что и рассылка:
+		// gorilla/websocket не допускает параллельной записи в соединение
+		w.mutex.Lock()
+		var response map[string]interface{}
+		switch {
+		case msg.Resume != nil && stream == nil:
+			response = map[string]interface{}{"type": "error", "error": "resume requires a player session"}
+		case msg.Resume != nil:
+			response = w.resume(stream, *msg.Resume)
+		default:
+			response = client.apply(msg)
+		}
+		reply, _ := json.Marshal(response)
+		err = conn.WriteMessage(websocket.TextMessage, reply)
+		w.mutex.Unlock()
+		if err != nil {
+			logging.Errorf("Failed to send message to client: %v", err)
+		}
 	}
 }
 
+// reply отправляет клиенту ответ под мьютексом записи
+func (w *WebSocketServer) reply(conn *websocket.Conn, response map[string]interface{}) {
+	message, _ := json.Marshal(response)
+	w.mutex.Lock()
+	err := conn.WriteMessage(websocket.TextMessage, message)
+	w.mutex.Unlock()
+	if err != nil {
+		logging.Errorf("Failed to send message to client: %v", err)
+	}
+}
+
+// closeThrottled отключает клиента, превысившего лимит сообщений, с кодом 1008 (policy violation)
+func (w *WebSocketServer) closeThrottled(conn *websocket.Conn, stream *replayStream) {
+	logging.Warnf("Closing WebSocket connection: %d messages in a row over the rate limit", maxWSThrottledMessages)
+	closing := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, ErrRateLimited.Error())
+	w.mutex.Lock()
+	conn.WriteControl(websocket.CloseMessage, closing, time.Now().Add(time.Second))
+	w.mutex.Unlock()
+	w.unregister(conn, stream)
+}
+
 func (w *WebSocketServer) BroadcastMessage(message []byte) {
-	// Отправляем сообщение всем подключенным клиентам с блокировкой
+	// Сообщения — JSON событий; метаданные для фильтров разбираем один раз на всех клиентов
+	var event eventbus.Event
+	parsed := true
+	if err := json.Unmarshal(message, &event); err != nil {
+		logging.Errorf("Failed to parse broadcast message: %v", err)
+		parsed = false
+	}
+
+	// Отправляем сообщение подписанным клиентам с блокировкой
 	w.mutex.Lock()
 	defer w.mutex.Unlock()
 
-	for client := range w.clients {
-		err := client.WriteMessage(websocket.TextMessage, message)
+	for conn, client := range w.clients {
+		if !client.accepts(event) {
+			continue
+		}
+		err := conn.WriteMessage(websocket.TextMessage, message)
 		if err != nil {
-			log.Printf("Failed to send message to client: %v", err)
-			client.Close()
-			delete(w.clients, client)
+			logging.Errorf("Failed to send message to client: %v", err)
+			conn.Close()
+			delete(w.clients, conn)
+		}
+	}
+
+	// Потоки сессий нумеруют и буферизуют события и во время отключения клиента
+	now := time.Now()
+	for sessionID, stream := range w.sessions {
+		if stream.conn == nil && now.Sub(stream.disconnectedAt) > w.replayRetention {
+			delete(w.sessions, sessionID)
+			continue
+		}
+		// В нумерованный поток попадают только события: сообщение вкладывается в конверт как JSON
+		if !parsed || !stream.client.accepts(event) {
+			continue
+		}
+		msg := stream.record(message, w.replayBuffer)
+		if stream.conn == nil {
+			continue
+		}
+		if err := stream.send(msg); err != nil {
+			logging.Errorf("Failed to send message to session %s: %v", sessionID, err)
+			stream.conn.Close()
+			stream.conn = nil
+			stream.disconnectedAt = now
 		}
 	}
 }
@@ -86,4 +412,13 @@ func (w *WebSocketServer) BroadcastLoop(broadcast <-chan []byte) {
 	for message := range broadcast {
 		w.BroadcastMessage(message)
 	}
-}
\ No newline at end of file
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,254 @@
+package gameservice
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+
+	"github.com/gorilla/websocket"
+)
+
+func scopedEvent(eventType, worldID, scopeID string) eventbus.Event {
+	ev := eventbus.NewEvent(eventType, "test", worldID, map[string]any{})
+	if scopeID != "" {
+		ev.Scope = &eventbus.ScopeRef{ID: scopeID, Type: "player"}
+	}
+	return ev
+}
+
+func TestSubscriptionMatches(t *testing.T) {
+	sub := Subscription{
+		WorldID:    "world-1",
+		ScopeIDs:   []string{"player:kain"},
+		EventTypes: []string{"narrative.*", "player.moved"},
+	}
+
+	cases := []struct {
+		name  string
+		event eventbus.Event
+		want  bool
+	}{
+		{"matching scope and type prefix", scopedEvent("narrative.generated", "world-1", "player:kain"), true},
+		{"exact type", scopedEvent("player.moved", "world-1", "player:kain"), true},
+		{"world event without scope", scopedEvent("narrative.generated", "world-1", ""), true},
+		{"other world", scopedEvent("narrative.generated", "world-2", "player:kain"), false},
+		{"other scope", scopedEvent("narrative.generated", "world-1", "player:abel"), false},
+		{"other type", scopedEvent("player.died", "world-1", "player:kain"), false},
+	}
+	for _, tc := range cases {
+		if got := sub.Matches(tc.event); got != tc.want {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, got)
+		}
+	}
+
+	if !(Subscription{}).Matches(scopedEvent("anything", "world-9", "x")) {
+		t.Error("empty subscription must match every event")
+	}
+}
+
+func TestClientSubscriptions(t *testing.T) {
+	client := &wsClient{}
+	event := scopedEvent("player.moved", "world-2", "")
+	if !client.accepts(event) {
+		t.Fatal("client without subscriptions must receive every event")
+	}
+
+	client.apply(clientMessage{Subscribe: &Subscription{WorldID: "world-1"}})
+	if client.accepts(event) {
+		t.Error("subscribed client must not receive events of other worlds")
+	}
+	client.apply(clientMessage{Subscribe: &Subscription{WorldID: "world-2"}})
+	if !client.accepts(event) {
+		t.Error("expected event to match the second subscription")
+	}
+
+	client.apply(clientMessage{Unsubscribe: &Subscription{WorldID: "world-2"}})
+	if client.accepts(event) || len(client.subscriptions) != 1 {
+		t.Errorf("expected world-2 subscription removed, got %+v", client.subscriptions)
+	}
+	client.apply(clientMessage{Unsubscribe: &Subscription{}})
+	if !client.accepts(scopedEvent("player.moved", "world-1", "")) || !client.accepts(event) {
+		t.Error("client that unsubscribed from everything must receive every event again")
+	}
+
+	if reply := client.apply(clientMessage{}); reply["type"] != "error" {
+		t.Errorf("expected error reply, got %v", reply)
+	}
+}
+
+func TestSessionClientSubscriptions(t *testing.T) {
+	client := &wsClient{worldID: "world-1"}
+	if client.accepts(scopedEvent("player.moved", "world-2", "")) || !client.accepts(scopedEvent("player.moved", "world-1", "")) {
+		t.Error("session client must receive only events of its world")
+	}
+
+	if reply := client.apply(clientMessage{Subscribe: &Subscription{WorldID: "world-2"}}); reply["type"] != "error" || len(client.subscriptions) != 0 {
+		t.Errorf("expected subscription to another world rejected, got %v", reply)
+	}
+	client.apply(clientMessage{Subscribe: &Subscription{EventTypes: []string{"narrative.*"}}})
+	if len(client.subscriptions) != 1 || client.subscriptions[0].WorldID != "world-1" {
+		t.Errorf("expected subscription bound to the session world, got %+v", client.subscriptions)
+	}
+	if client.accepts(scopedEvent("player.moved", "world-1", "")) || !client.accepts(scopedEvent("narrative.generated", "world-1", "")) {
+		t.Error("expected the subscription filter to apply")
+	}
+
+	client.apply(clientMessage{Unsubscribe: &Subscription{WorldID: "world-1"}})
+	if !client.accepts(scopedEvent("player.moved", "world-1", "")) || client.accepts(scopedEvent("player.moved", "world-2", "")) {
+		t.Error("after unsubscribing the session client must receive every event of its world only")
+	}
+}
+
+func TestWebSocketBroadcastFiltering(t *testing.T) {
+	ws := NewWebSocketServer()
+	server := httptest.NewServer(http.HandlerFunc(ws.HandleWebSocket))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	if err := conn.WriteJSON(map[string]any{"subscribe": map[string]any{"world_id": "world-1"}}); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	var ack map[string]any
+	if err := conn.ReadJSON(&ack); err != nil || ack["type"] != "subscriptions" {
+		t.Fatalf("expected subscription ack, got %v (%v)", ack, err)
+	}
+
+	for _, worldID := range []string{"world-2", "world-1"} {
+		message, _ := json.Marshal(scopedEvent("player.moved", worldID, ""))
+		ws.BroadcastMessage(message)
+	}
+
+	var received eventbus.Event
+	if err := conn.ReadJSON(&received); err != nil {
+		t.Fatalf("read event: %v", err)
+	}
+	if got := eventbus.GetWorldIDFromEvent(received); got != "world-1" {
+		t.Errorf("expected only the world-1 event, got %s", got)
+	}
+}
+
+func TestReplayStreamSince(t *testing.T) {
+	stream := &replayStream{}
+	for i := 0; i < 5; i++ {
+		stream.record([]byte(`{}`), 3)
+	}
+	if stream.seq != 5 || len(stream.buffer) != 3 || stream.buffer[0].Seq != 3 {
+		t.Fatalf("expected seq 5 with events 3..5 buffered, got seq %d buffer %+v", stream.seq, stream.buffer)
+	}
+
+	if missed, ok := stream.since(3); !ok || len(missed) != 2 || missed[0].Seq != 4 {
+		t.Errorf("expected events 4 and 5, got %+v (ok=%v)", missed, ok)
+	}
+	if missed, ok := stream.since(2); !ok || len(missed) != 3 {
+		t.Errorf("expected the whole buffer, got %+v (ok=%v)", missed, ok)
+	}
+	if missed, ok := stream.since(5); !ok || len(missed) != 0 {
+		t.Errorf("expected nothing missed, got %+v (ok=%v)", missed, ok)
+	}
+	if _, ok := stream.since(1); ok {
+		t.Error("expected a gap: event 2 is no longer buffered")
+	}
+	if _, ok := stream.since(9); ok {
+		t.Error("expected failure for seq from another stream")
+	}
+}
+
+func TestWebSocketResume(t *testing.T) {
+	sessions := NewSessionManager("secret", time.Hour)
+	token, _, _ := sessions.Issue("player:kain", "world-1")
+	ws := NewWebSocketServer()
+	server := httptest.NewServer(sessions.RequireSession(ws.HandleWebSocket))
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "?token=" + token
+
+	broadcast := func(id string) {
+		event := scopedEvent("player.moved", "world-1", "")
+		event.ID = id
+		message, _ := json.Marshal(event)
+		ws.BroadcastMessage(message)
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	broadcast("e1")
+	var first eventbus.Event
+	if err := conn.ReadJSON(&first); err != nil || first.ID != "e1" {
+		t.Fatalf("expected raw event e1 before resume, got %+v (%v)", first, err)
+	}
+	conn.Close()
+
+	// Пока клиент отключён, события продолжают нумероваться в потоке сессии
+	broadcast("e2")
+	broadcast("e3")
+
+	conn, _, err = websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("redial: %v", err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if err := conn.WriteJSON(map[string]any{"resume": map[string]any{"last_seq": 1}}); err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+
+	for _, want := range []struct {
+		seq uint64
+		id  string
+	}{{2, "e2"}, {3, "e3"}} {
+		var msg struct {
+			Type  string         `json:"type"`
+			Seq   uint64         `json:"seq"`
+			Event eventbus.Event `json:"event"`
+		}
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("read replay: %v", err)
+		}
+		if msg.Type != "event" || msg.Seq != want.seq || msg.Event.ID != want.id {
+			t.Errorf("expected replay of %s as seq %d, got %+v", want.id, want.seq, msg)
+		}
+	}
+
+	var ack map[string]any
+	if err := conn.ReadJSON(&ack); err != nil || ack["type"] != "resumed" || ack["replayed"] != 2.0 {
+		t.Fatalf("expected resumed ack, got %v (%v)", ack, err)
+	}
+
+	broadcast("e4")
+	var next sequencedMessage
+	if err := conn.ReadJSON(&next); err != nil || next.Seq != 4 {
+		t.Errorf("expected live event with seq 4, got %+v (%v)", next, err)
+	}
+}
+
+func TestWebSocketResumeRequiresSession(t *testing.T) {
+	ws := NewWebSocketServer()
+	server := httptest.NewServer(http.HandlerFunc(ws.HandleWebSocket))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	conn.WriteJSON(map[string]any{"resume": map[string]any{"last_seq": 0}})
+	var reply map[string]any
+	if err := conn.ReadJSON(&reply); err != nil || reply["type"] != "error" {
+		t.Errorf("expected error for resume without session, got %v (%v)", reply, err)
+	}
+}
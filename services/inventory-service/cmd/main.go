@@ -0,0 +1,40 @@
+// Package main is the entry point for InventoryService.
+package main
+
+import (
+	"multiverse-core.io/services/inventory-service/inventoryservice"
+	"multiverse-core.io/shared/archivist"
+	"multiverse-core.io/shared/config"
+	"multiverse-core.io/shared/logging"
+	"multiverse-core.io/shared/registry"
+	"multiverse-core.io/shared/service"
+)
+
+func main() {
+	app := service.Setup("inventory-service", []config.Option{
+		{Env: "ARCHIVIST_URL", Type: config.TypeURL, Usage: "fallback archivist address"},
+		{Env: "ITEM_RULES_FROM_ARCHIVIST", Default: "true", Type: config.TypeBool, Usage: "load item rules from world ontology profiles (false leaves items unrestricted)"},
+	})
+	env := app.Env
+
+	inventory := inventoryservice.NewService(app.Bus())
+
+	// Inventories and items are read from their entities, which EntityManager stores in MinIO
+	// (without MinIO every entity is unknown and every operation is rejected)
+	minioClient, err := app.MinIO()
+	if err != nil {
+		logging.Warnf("MinIO unavailable, inventory operations are rejected: %v", err)
+	} else {
+		inventory.UseEntityStorage(minioClient)
+	}
+
+	// Item rules come from the world ontology profiles in the archivist
+	// (address through the service registry)
+	discovery := registry.NewDiscovery(app.Bus(), "inventory-service")
+	if env.Bool("ITEM_RULES_FROM_ARCHIVIST") {
+		inventory.UseArchivist(archivist.NewClient(env.String("ARCHIVIST_URL"), discovery))
+	}
+	app.Go(discovery.Run)
+
+	app.Run(inventory)
+}
@@ -0,0 +1,343 @@
+package inventoryservice
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/entity"
+	"multiverse-core.io/shared/jsonpath"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+)
+
+// Entity caching: inventories changed by the service are kept in memory until EntityManager
+// has stored them; item definitions rarely change.
+const (
+	holdingsTTL = 30 * time.Second
+	itemTTL     = 10 * time.Minute
+)
+
+// Stack is a number of identical items in an inventory.
+type Stack struct {
+	ID       string
+	Quantity int
+	// fields are the other fields of the inventory entry (e.g. a name), kept as they are
+	fields map[string]interface{}
+}
+
+// Holdings are the items an entity holds and the items it has equipped.
+// Inventory entries are item IDs (one item each) or objects {"id", "quantity"};
+// equipment maps slots to item IDs.
+type Holdings struct {
+	ID        string
+	Type      string
+	Items     []Stack
+	Equipment map[string]string
+}
+
+// decodeHoldings reads the inventory and equipment of an entity. Repeated entries of an item add up.
+func decodeHoldings(ent entity.Entity) Holdings {
+	h := Holdings{ID: ent.ID, Type: ent.Type, Equipment: make(map[string]string)}
+	pa := jsonpath.New(ent.Payload)
+	if inventory, ok := pa.GetSlice("inventory"); ok {
+		for _, entry := range inventory {
+			switch v := entry.(type) {
+			case string:
+				h.add(v, 1, nil)
+			case map[string]interface{}:
+				id, _ := v["id"].(string)
+				quantity := 1
+				if q, ok := v["quantity"].(float64); ok {
+					quantity = int(q)
+				}
+				if id == "" || quantity <= 0 {
+					continue
+				}
+				fields := make(map[string]interface{})
+				for key, value := range v {
+					if key != "id" && key != "quantity" {
+						fields[key] = value
+					}
+				}
+				h.add(id, quantity, fields)
+			}
+		}
+	}
+	if equipment, ok := pa.GetMap("equipment"); ok {
+		for slot, value := range equipment {
+			switch v := value.(type) {
+			case string:
+				h.Equipment[slot] = v
+			case map[string]interface{}:
+				if id, ok := v["id"].(string); ok {
+					h.Equipment[slot] = id
+				}
+			}
+		}
+	}
+	return h
+}
+
+// clone returns a copy of the holdings that can be changed independently.
+func (h Holdings) clone() Holdings {
+	c := h
+	c.Items = make([]Stack, len(h.Items))
+	copy(c.Items, h.Items)
+	c.Equipment = maps.Clone(h.Equipment)
+	return c
+}
+
+// Quantity returns how many of the item the entity holds.
+func (h Holdings) Quantity(itemID string) int {
+	for _, stack := range h.Items {
+		if stack.ID == itemID {
+			return stack.Quantity
+		}
+	}
+	return 0
+}
+
+// equipped returns how many slots hold the item.
+func (h Holdings) equipped(itemID string) int {
+	count := 0
+	for _, id := range h.Equipment {
+		if id == itemID {
+			count++
+		}
+	}
+	return count
+}
+
+// add puts items into the inventory, stacking them with the items already held.
+func (h *Holdings) add(itemID string, quantity int, fields map[string]interface{}) {
+	for i := range h.Items {
+		if h.Items[i].ID == itemID {
+			h.Items[i].Quantity += quantity
+			return
+		}
+	}
+	h.Items = append(h.Items, Stack{ID: itemID, Quantity: quantity, fields: fields})
+}
+
+// remove takes items out of the inventory; the caller checks the quantity first.
+// Stacks that run out are dropped.
+func (h *Holdings) remove(itemID string, quantity int) {
+	for i := range h.Items {
+		if h.Items[i].ID != itemID {
+			continue
+		}
+		h.Items[i].Quantity -= quantity
+		if h.Items[i].Quantity <= 0 {
+			h.Items = slices.Delete(h.Items, i, i+1)
+		}
+		return
+	}
+}
+
+// stack returns the inventory entry of an item, for moving its fields along with it.
+func (h Holdings) stack(itemID string) Stack {
+	for _, stack := range h.Items {
+		if stack.ID == itemID {
+			return stack
+		}
+	}
+	return Stack{ID: itemID}
+}
+
+// inventoryValue encodes the inventory as it is stored in the entity payload.
+func (h Holdings) inventoryValue() []interface{} {
+	list := make([]interface{}, 0, len(h.Items))
+	for _, stack := range h.Items {
+		entry := make(map[string]interface{}, len(stack.fields)+2)
+		maps.Copy(entry, stack.fields)
+		entry["id"] = stack.ID
+		entry["quantity"] = stack.Quantity
+		list = append(list, entry)
+	}
+	return list
+}
+
+// Item is the inventory view of an item entity. Items without an entity use the zero
+// restrictions: transferable, not consumable, without a slot.
+type Item struct {
+	ID string
+	// Bound items (transferable: false or bound: true) never change hands
+	Bound bool
+	// Consumable items are used up by player.used_item
+	Consumable bool
+	// Slot is the equipment slot the item goes to when a request names none
+	Slot string
+}
+
+// decodeItem reads the inventory fields of an item entity.
+func decodeItem(ent entity.Entity) Item {
+	pa := jsonpath.New(ent.Payload)
+	item := Item{ID: ent.ID}
+	if transferable, ok := pa.GetBool("transferable"); ok && !transferable {
+		item.Bound = true
+	}
+	if bound, ok := pa.GetBool("bound"); ok && bound {
+		item.Bound = true
+	}
+	item.Consumable, _ = pa.GetBool("consumable")
+	item.Slot, _ = pa.GetString("slot")
+	return item
+}
+
+type cachedHoldings struct {
+	holdings  Holdings
+	expiresAt time.Time
+}
+
+type cachedItem struct {
+	item      Item
+	expiresAt time.Time
+}
+
+// entityLock serializes the operations on one entity; refs counts the operations waiting for it.
+type entityLock struct {
+	sync.Mutex
+	refs int
+}
+
+// Store reads inventories and items from their entities in MinIO, which EntityManager keeps
+// up to date from state_changes. Inventories changed by the service are kept in memory, so
+// the next operation sees them before EntityManager stores them.
+type Store struct {
+	storage storage.ObjectStorage // nil — every entity is unknown
+
+	mu       sync.Mutex
+	locks    map[string]*entityLock
+	holdings map[string]cachedHoldings
+	items    map[string]cachedItem
+}
+
+// NewStore creates an empty store; see UseStorage for reading entities.
+func NewStore() *Store {
+	return &Store{
+		locks:    make(map[string]*entityLock),
+		holdings: make(map[string]cachedHoldings),
+		items:    make(map[string]cachedItem),
+	}
+}
+
+// UseStorage enables reading entities from the entity buckets.
+func (st *Store) UseStorage(client storage.ObjectStorage) {
+	st.storage = client
+}
+
+// Lock locks the inventories of the entities and returns the function that unlocks them.
+// Entities are locked in a fixed order, so operations on overlapping pairs cannot deadlock.
+func (st *Store) Lock(worldID string, ids ...string) func() {
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id != "" {
+			keys = append(keys, worldID+"/"+id)
+		}
+	}
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+
+	locks := make([]*entityLock, len(keys))
+	st.mu.Lock()
+	for i, key := range keys {
+		lock, ok := st.locks[key]
+		if !ok {
+			lock = &entityLock{}
+			st.locks[key] = lock
+		}
+		lock.refs++
+		locks[i] = lock
+	}
+	st.mu.Unlock()
+
+	for _, lock := range locks {
+		lock.Lock()
+	}
+	return func() {
+		for i := len(locks) - 1; i >= 0; i-- {
+			locks[i].Unlock()
+		}
+		st.mu.Lock()
+		for i, key := range keys {
+			if locks[i].refs--; locks[i].refs == 0 {
+				delete(st.locks, key)
+			}
+		}
+		st.mu.Unlock()
+	}
+}
+
+// Holdings returns the holdings of an entity; false when the entity is unknown.
+// The caller holds the lock of the entity.
+func (st *Store) Holdings(worldID, id string) (Holdings, bool) {
+	key := worldID + "/" + id
+	st.mu.Lock()
+	cached, ok := st.holdings[key]
+	st.mu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.holdings.clone(), true
+	}
+	ent, found := st.load(worldID, id)
+	if !found {
+		return Holdings{}, false
+	}
+	return decodeHoldings(ent), true
+}
+
+// Put keeps changed holdings until EntityManager has stored them. The caller holds the lock of the entity.
+func (st *Store) Put(worldID string, h Holdings) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.holdings[worldID+"/"+h.ID] = cachedHoldings{holdings: h.clone(), expiresAt: time.Now().Add(holdingsTTL)}
+}
+
+// Item returns the item entity; items without an entity have no restrictions.
+func (st *Store) Item(worldID, id string) Item {
+	key := worldID + "/" + id
+	st.mu.Lock()
+	cached, ok := st.items[key]
+	st.mu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.item
+	}
+
+	cached = cachedItem{item: Item{ID: id}, expiresAt: time.Now().Add(itemTTL)}
+	if ent, found := st.load(worldID, id); found {
+		cached.item = decodeItem(ent)
+	}
+	st.mu.Lock()
+	st.items[key] = cached
+	st.mu.Unlock()
+	return cached.item
+}
+
+// load reads the entity of the world, falling back to the global entities.
+func (st *Store) load(worldID, id string) (entity.Entity, bool) {
+	if st.storage == nil {
+		return entity.Entity{}, false
+	}
+	for _, bucket := range []string{"entities-" + worldID, "entities-global"} {
+		data, err := st.storage.GetObject(bucket, id+".json")
+		if err != nil {
+			if !storage.IsNotFound(err) {
+				logging.Errorf("Failed to load entity %s: %v", id, err)
+				return entity.Entity{}, false
+			}
+			continue
+		}
+		var ent entity.Entity
+		if err := json.Unmarshal(data, &ent); err != nil {
+			logging.Warnf("Ignoring invalid entity %s: %v", id, err)
+			return entity.Entity{}, false
+		}
+		if ent.ID == "" {
+			ent.ID = id
+		}
+		return ent, true
+	}
+	return entity.Entity{}, false
+}
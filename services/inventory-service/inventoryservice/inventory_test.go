@@ -0,0 +1,43 @@
+package inventoryservice
+
+import (
+	"reflect"
+	"testing"
+
+	"multiverse-core.io/shared/entity"
+)
+
+func TestDecodeHoldings(t *testing.T) {
+	h := decodeHoldings(entity.Entity{ID: "player:kain", Type: "player", Payload: map[string]interface{}{
+		"inventory": []interface{}{
+			"healing_potion",
+			map[string]interface{}{"id": "arrow", "quantity": 20.0, "name": "Стрела"},
+			"healing_potion",
+			map[string]interface{}{"id": "ghost", "quantity": 0.0},
+		},
+		"equipment": map[string]interface{}{"main_hand": "sword:ash", "off_hand": map[string]interface{}{"id": "shield"}},
+	}})
+
+	if h.Quantity("healing_potion") != 2 || h.Quantity("arrow") != 20 || h.Quantity("ghost") != 0 {
+		t.Fatalf("unexpected holdings %+v", h.Items)
+	}
+	if h.Equipment["main_hand"] != "sword:ash" || h.Equipment["off_hand"] != "shield" {
+		t.Errorf("unexpected equipment %v", h.Equipment)
+	}
+
+	h.remove("healing_potion", 2)
+	want := []interface{}{map[string]interface{}{"id": "arrow", "quantity": 20, "name": "Стрела"}}
+	if got := h.inventoryValue(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the empty stack dropped and fields kept, got %v", got)
+	}
+}
+
+func TestDecodeItem(t *testing.T) {
+	item := decodeItem(entity.Entity{ID: "ring:oath", Payload: map[string]interface{}{"transferable": false, "slot": "finger"}})
+	if !item.Bound || item.Slot != "finger" || item.Consumable {
+		t.Errorf("unexpected item %+v", item)
+	}
+	if potion := decodeItem(entity.Entity{ID: "healing_potion", Payload: map[string]interface{}{"consumable": true}}); potion.Bound || !potion.Consumable {
+		t.Errorf("unexpected item %+v", potion)
+	}
+}
@@ -0,0 +1,366 @@
+// Package inventoryservice owns entity inventories: it grants, consumes, transfers and equips items
+// with validated quantities and publishes the changes as item.* events.
+package inventoryservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+
+	"github.com/google/uuid"
+)
+
+// source is the source of the events InventoryService publishes.
+const source = "inventory-service"
+
+// Inventory operations, as named in item rules and item.rejected.
+const (
+	OpGrant    = "grant"
+	OpConsume  = "consume"
+	OpTransfer = "transfer"
+	OpEquip    = "equip"
+	OpUnequip  = "unequip"
+)
+
+// operations lists the known operations.
+var operations = []string{OpGrant, OpConsume, OpTransfer, OpEquip, OpUnequip}
+
+// Events published for completed operations; each carries the state_changes of the entities involved.
+const (
+	EventItemGranted     = "item.granted"
+	EventItemConsumed    = "item.consumed"
+	EventItemTransferred = "item.transferred"
+	EventItemEquipped    = "item.equipped"
+	EventItemUnequipped  = "item.unequipped"
+	// EventItemRejected is published when a requested operation is not allowed
+	EventItemRejected = "item.rejected"
+)
+
+// resultEvents maps operations to the events announcing them.
+var resultEvents = map[string]string{
+	OpGrant:    EventItemGranted,
+	OpConsume:  EventItemConsumed,
+	OpTransfer: EventItemTransferred,
+	OpEquip:    EventItemEquipped,
+	OpUnequip:  EventItemUnequipped,
+}
+
+// maxQuantity bounds the quantity of one operation, so a malformed request cannot flood an inventory.
+const maxQuantity = 1_000_000
+
+// Reasons an operation is rejected; item.rejected carries their codes.
+var (
+	ErrInvalidRequest  = errors.New("invalid_request")
+	ErrInvalidQuantity = errors.New("invalid_quantity")
+	ErrUnknownEntity   = errors.New("unknown_entity")
+	ErrInsufficient    = errors.New("insufficient_quantity")
+	ErrEquipped        = errors.New("item_equipped")
+	ErrNoSlot          = errors.New("no_slot")
+	ErrForbidden       = errors.New("forbidden")
+)
+
+// rejectionCodes are the errors reported in item.rejected; other errors are not the fault of the request.
+var rejectionCodes = []error{ErrInvalidRequest, ErrInvalidQuantity, ErrUnknownEntity, ErrInsufficient, ErrEquipped, ErrNoSlot, ErrForbidden}
+
+// Request is an inventory operation. EntityID holds the items (the sender of a transfer);
+// TargetID receives transferred items.
+type Request struct {
+	Op       string
+	WorldID  string
+	EntityID string
+	TargetID string
+	ItemID   string
+	Quantity int
+	Slot     string
+	// Cause is the event that requested the operation; the result is published in its scope
+	Cause eventbus.Event
+}
+
+// InventoryService applies inventory operations. Operations on an entity (or a pair of entities
+// for transfers) run under their locks and update both inventories together: the result event
+// carries the changes of both, and nothing changes if it cannot be published.
+type InventoryService struct {
+	rules *Rules
+	store *Store
+	// publish sends an event to world_events; replaced in tests
+	publish func(ctx context.Context, event eventbus.Event) error
+	// handled guards against applying a redelivered request twice
+	handled handledRequests
+}
+
+// NewInventoryService creates a new InventoryService without item rules.
+func NewInventoryService(bus *eventbus.EventBus) *InventoryService {
+	return &InventoryService{
+		rules:   NewRules(),
+		store:   NewStore(),
+		publish: bus.PublishWorldEvent,
+	}
+}
+
+// Grant gives an entity items, e.g. a quest reward or loot.
+func (is *InventoryService) Grant(worldID, entityID, itemID string, quantity int, cause eventbus.Event) error {
+	return is.Apply(Request{Op: OpGrant, WorldID: worldID, EntityID: entityID, ItemID: itemID, Quantity: quantity, Cause: cause})
+}
+
+// Consume uses up items of an entity.
+func (is *InventoryService) Consume(worldID, entityID, itemID string, quantity int, cause eventbus.Event) error {
+	return is.Apply(Request{Op: OpConsume, WorldID: worldID, EntityID: entityID, ItemID: itemID, Quantity: quantity, Cause: cause})
+}
+
+// Transfer moves items from one entity to another.
+func (is *InventoryService) Transfer(worldID, fromID, toID, itemID string, quantity int, cause eventbus.Event) error {
+	return is.Apply(Request{Op: OpTransfer, WorldID: worldID, EntityID: fromID, TargetID: toID, ItemID: itemID, Quantity: quantity, Cause: cause})
+}
+
+// Equip puts a held item into an equipment slot; an empty slot uses the slot of the item.
+func (is *InventoryService) Equip(worldID, entityID, itemID, slot string, cause eventbus.Event) error {
+	return is.Apply(Request{Op: OpEquip, WorldID: worldID, EntityID: entityID, ItemID: itemID, Slot: slot, Cause: cause})
+}
+
+// Unequip empties an equipment slot; the item stays in the inventory.
+func (is *InventoryService) Unequip(worldID, entityID, slot string, cause eventbus.Event) error {
+	return is.Apply(Request{Op: OpUnequip, WorldID: worldID, EntityID: entityID, Slot: slot, Cause: cause})
+}
+
+// validate checks the request before any inventory is read.
+func (r Request) validate() error {
+	if r.EntityID == "" {
+		return fmt.Errorf("%w: no entity", ErrInvalidRequest)
+	}
+	switch r.Op {
+	case OpGrant, OpConsume, OpTransfer:
+		if r.ItemID == "" {
+			return fmt.Errorf("%w: no item", ErrInvalidRequest)
+		}
+		if r.Quantity <= 0 || r.Quantity > maxQuantity {
+			return fmt.Errorf("%w: %d is not within 1..%d", ErrInvalidQuantity, r.Quantity, maxQuantity)
+		}
+	case OpEquip:
+		if r.ItemID == "" {
+			return fmt.Errorf("%w: no item", ErrInvalidRequest)
+		}
+	case OpUnequip:
+		if r.Slot == "" {
+			return fmt.Errorf("%w: no slot", ErrNoSlot)
+		}
+	default:
+		return fmt.Errorf("%w: unknown operation %q", ErrInvalidRequest, r.Op)
+	}
+	if r.Op == OpTransfer && (r.TargetID == "" || r.TargetID == r.EntityID) {
+		return fmt.Errorf("%w: transfer needs another entity to receive the items", ErrInvalidRequest)
+	}
+	return nil
+}
+
+// Apply validates and applies an operation and publishes its result event.
+func (is *InventoryService) Apply(req Request) error {
+	if err := req.validate(); err != nil {
+		return err
+	}
+	item := Item{ID: req.ItemID}
+	if req.ItemID != "" {
+		item = is.store.Item(req.WorldID, req.ItemID)
+		if req.Op == OpTransfer && item.Bound {
+			return fmt.Errorf("%w: %s is bound to its owner", ErrForbidden, req.ItemID)
+		}
+		if rule := is.rules.Forbidding(req.WorldID, req.Op, req.ItemID); rule != nil {
+			reason := rule.Reason
+			if reason == "" {
+				reason = "rule " + rule.ID
+			}
+			return fmt.Errorf("%w: %s of %s in %s: %s", ErrForbidden, req.Op, req.ItemID, req.WorldID, reason)
+		}
+	}
+
+	unlock := is.store.Lock(req.WorldID, req.EntityID, req.TargetID)
+	defer unlock()
+
+	holder, ok := is.store.Holdings(req.WorldID, req.EntityID)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownEntity, req.EntityID)
+	}
+	var recipient Holdings
+	if req.Op == OpTransfer {
+		if recipient, ok = is.store.Holdings(req.WorldID, req.TargetID); !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownEntity, req.TargetID)
+		}
+	}
+	before := []Holdings{holder.clone(), recipient.clone()}
+
+	slot, err := is.change(req, item, &holder, &recipient)
+	if err != nil {
+		return err
+	}
+
+	changed := []Holdings{holder}
+	if req.Op == OpTransfer {
+		changed = append(changed, recipient)
+	}
+	for _, h := range changed {
+		is.store.Put(req.WorldID, h)
+	}
+	if err := is.publishResult(req, slot, holder, recipient); err != nil {
+		// Nothing happened: the next operation must see the inventories as they were
+		for i := range changed {
+			is.store.Put(req.WorldID, before[i])
+		}
+		return err
+	}
+	return nil
+}
+
+// change applies the operation to the holdings and returns the equipment slot it used.
+func (is *InventoryService) change(req Request, item Item, holder, recipient *Holdings) (string, error) {
+	switch req.Op {
+	case OpGrant:
+		holder.add(req.ItemID, req.Quantity, nil)
+	case OpConsume, OpTransfer:
+		held := holder.Quantity(req.ItemID)
+		if held < req.Quantity {
+			return "", fmt.Errorf("%w: %s holds %d of %s, %d needed", ErrInsufficient, holder.ID, held, req.ItemID, req.Quantity)
+		}
+		if held-req.Quantity < holder.equipped(req.ItemID) {
+			return "", fmt.Errorf("%w: %s has %s equipped", ErrEquipped, holder.ID, req.ItemID)
+		}
+		stack := holder.stack(req.ItemID)
+		holder.remove(req.ItemID, req.Quantity)
+		if req.Op == OpTransfer {
+			recipient.add(req.ItemID, req.Quantity, stack.fields)
+		}
+	case OpEquip:
+		slot := req.Slot
+		if slot == "" {
+			slot = item.Slot
+		}
+		if slot == "" {
+			return "", fmt.Errorf("%w: %s has no equipment slot", ErrNoSlot, req.ItemID)
+		}
+		// One item can fill as many slots as there are items held, the slot being replaced aside
+		equipped := holder.equipped(req.ItemID)
+		if holder.Equipment[slot] == req.ItemID {
+			equipped--
+		}
+		if holder.Quantity(req.ItemID) <= equipped {
+			return "", fmt.Errorf("%w: %s holds no free %s", ErrInsufficient, holder.ID, req.ItemID)
+		}
+		holder.Equipment[slot] = req.ItemID
+		return slot, nil
+	case OpUnequip:
+		if holder.Equipment[req.Slot] == "" {
+			return "", fmt.Errorf("%w: slot %s of %s is empty", ErrInvalidRequest, req.Slot, holder.ID)
+		}
+		delete(holder.Equipment, req.Slot)
+		return req.Slot, nil
+	}
+	return "", nil
+}
+
+// publishResult publishes the result event of an operation in the scope of its cause.
+func (is *InventoryService) publishResult(req Request, slot string, holder, recipient Holdings) error {
+	payload := eventbus.NewEventPayload().
+		WithEntity(holder.ID, holder.Type, "").
+		WithWorld(req.WorldID)
+	if req.Op == OpTransfer {
+		payload.WithTarget(recipient.ID, recipient.Type, "")
+	}
+
+	custom := payload.GetCustom()
+	eventbus.SetNested(custom, "operation", req.Op)
+	if req.ItemID != "" {
+		eventbus.SetNested(custom, "item_id", req.ItemID)
+		eventbus.SetNested(custom, "held", holder.Quantity(req.ItemID))
+	}
+	if req.Quantity > 0 && req.Op != OpEquip {
+		eventbus.SetNested(custom, "quantity", req.Quantity)
+	}
+	if slot != "" {
+		eventbus.SetNested(custom, "slot", slot)
+	}
+	if req.Cause.ID != "" {
+		eventbus.SetNested(custom, "original_event", req.Cause.ID)
+	}
+	eventbus.SetNested(custom, "state_changes", stateChanges(req.Op, slot, holder, recipient))
+
+	ev := is.newEvent(resultEvents[req.Op], req, payload)
+	if err := is.publish(context.Background(), ev); err != nil {
+		return fmt.Errorf("publish %s: %w", ev.Type, err)
+	}
+	logging.Infof("Inventory in %s: %s %s %d×%s %s", req.WorldID, req.Op, holder.ID, req.Quantity, req.ItemID, recipient.ID)
+	return nil
+}
+
+// stateChanges builds the state_changes that make EntityManager store the changed inventories.
+// A transfer changes both entities in the same event.
+func stateChanges(op, slot string, holder, recipient Holdings) []interface{} {
+	var operations []interface{}
+	switch op {
+	case OpEquip:
+		operations = []interface{}{map[string]interface{}{"op": "set", "path": "equipment." + slot, "value": holder.Equipment[slot]}}
+	case OpUnequip:
+		operations = []interface{}{map[string]interface{}{"op": "remove", "path": "equipment." + slot}}
+	default:
+		operations = []interface{}{map[string]interface{}{"op": "set", "path": "inventory", "value": holder.inventoryValue()}}
+	}
+	changes := []interface{}{
+		map[string]interface{}{"entity_id": holder.ID, "operations": operations},
+	}
+	if op == OpTransfer {
+		changes = append(changes, map[string]interface{}{"entity_id": recipient.ID, "operations": []interface{}{
+			map[string]interface{}{"op": "set", "path": "inventory", "value": recipient.inventoryValue()},
+		}})
+	}
+	return changes
+}
+
+// Reject publishes item.rejected for a request the service refused.
+func (is *InventoryService) Reject(req Request, reason error) {
+	payload := eventbus.NewEventPayload().
+		WithEntity(req.EntityID, "", "").
+		WithWorld(req.WorldID)
+	if req.TargetID != "" {
+		payload.WithTarget(req.TargetID, "", "")
+	}
+
+	custom := payload.GetCustom()
+	eventbus.SetNested(custom, "operation", req.Op)
+	eventbus.SetNested(custom, "item_id", req.ItemID)
+	eventbus.SetNested(custom, "quantity", req.Quantity)
+	eventbus.SetNested(custom, "reason", rejectionCode(reason))
+	eventbus.SetNested(custom, "error", reason.Error())
+	if req.Cause.ID != "" {
+		eventbus.SetNested(custom, "original_event", req.Cause.ID)
+	}
+
+	ev := is.newEvent(EventItemRejected, req, payload)
+	if err := is.publish(context.Background(), ev); err != nil {
+		logging.Errorf("Failed to publish item rejection of %s: %v", req.Cause.ID, err)
+		return
+	}
+	logging.Infof("Rejected %s of %s by %s in %s: %v", req.Op, req.ItemID, req.EntityID, req.WorldID, reason)
+}
+
+// newEvent creates an event caused by the request.
+func (is *InventoryService) newEvent(eventType string, req Request, payload *eventbus.EventPayload) eventbus.Event {
+	ev := eventbus.NewStructuredEvent(eventType, source, req.WorldID, payload)
+	if req.Cause.ID != "" {
+		ev = ev.CausedBy(req.Cause)
+		ev.Scope = eventbus.GetScopeFromEvent(req.Cause)
+	}
+	ev.ID = "item-" + uuid.New().String()[:8]
+	ev.Timestamp = time.Now()
+	return ev
+}
+
+// rejectionCode returns the code of a rejection reason; empty for errors that are not rejections.
+func rejectionCode(err error) string {
+	for _, code := range rejectionCodes {
+		if errors.Is(err, code) {
+			return code.Error()
+		}
+	}
+	return ""
+}
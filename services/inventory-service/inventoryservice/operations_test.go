@@ -0,0 +1,184 @@
+package inventoryservice
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/minio/miniotest"
+)
+
+func newTestInventory() (*InventoryService, *[]eventbus.Event) {
+	storage := miniotest.New()
+	storage.Put("entities-ash-realm", "player:kain.json", `{"id": "player:kain", "type": "player", "payload": {
+		"inventory": ["healing_potion", {"id": "arrow", "quantity": 20}, "sword:ash", "ring:oath"]}}`)
+	storage.Put("entities-ash-realm", "npc:trader.json", `{"id": "npc:trader", "type": "npc", "payload": {"inventory": ["arrow"]}}`)
+	storage.Put("entities-global", "ring:oath.json", `{"id": "ring:oath", "type": "item", "payload": {"bound": true, "slot": "finger"}}`)
+	storage.Put("entities-global", "sword:ash.json", `{"id": "sword:ash", "type": "item", "payload": {"slot": "main_hand"}}`)
+	storage.Put("entities-global", "healing_potion.json", `{"id": "healing_potion", "type": "item", "payload": {"consumable": true}}`)
+
+	var published []eventbus.Event
+	inventory := NewInventoryService(nil)
+	inventory.store.UseStorage(storage)
+	inventory.rules.worlds.Set("ash-realm", []ItemRule{
+		{ID: "no-relics", Items: []string{"relic:*"}, Operations: []string{OpGrant, OpTransfer}, Reason: "relics stay in the vault"},
+	})
+	inventory.publish = func(ctx context.Context, event eventbus.Event) error {
+		published = append(published, event)
+		return nil
+	}
+	return inventory, &published
+}
+
+// inventories returns the inventory values of a result event by entity.
+func inventories(t *testing.T, ev eventbus.Event) map[string][]interface{} {
+	t.Helper()
+	changes, ok := ev.Payload["state_changes"].([]interface{})
+	if !ok {
+		t.Fatalf("expected state_changes, got %v", ev.Payload["state_changes"])
+	}
+	result := make(map[string][]interface{})
+	for _, change := range changes {
+		m := change.(map[string]interface{})
+		op := m["operations"].([]interface{})[0].(map[string]interface{})
+		if op["path"] == "inventory" {
+			result[m["entity_id"].(string)] = op["value"].([]interface{})
+		}
+	}
+	return result
+}
+
+func TestTransfer(t *testing.T) {
+	inventory, published := newTestInventory()
+
+	if err := inventory.Transfer("ash-realm", "player:kain", "npc:trader", "arrow", 15, eventbus.Event{ID: "ev-1"}); err != nil {
+		t.Fatalf("transfer failed: %v", err)
+	}
+	if len(*published) != 1 || (*published)[0].Type != EventItemTransferred {
+		t.Fatalf("expected one item.transferred, got %+v", *published)
+	}
+	changes := inventories(t, (*published)[0])
+	if len(changes) != 2 {
+		t.Fatalf("expected the inventories of both entities in one event, got %v", changes)
+	}
+	if got := changes["npc:trader"]; len(got) != 1 || got[0].(map[string]interface{})["quantity"] != 16 {
+		t.Errorf("expected the trader to hold 16 arrows, got %v", got)
+	}
+
+	// The next operation sees the transfer before EntityManager stores it
+	err := inventory.Transfer("ash-realm", "player:kain", "npc:trader", "arrow", 6, eventbus.Event{ID: "ev-2"})
+	if !errors.Is(err, ErrInsufficient) {
+		t.Errorf("expected insufficient quantity for 6 of the 5 arrows left, got %v", err)
+	}
+
+	for _, tc := range []struct {
+		name string
+		req  Request
+		want error
+	}{
+		{"zero quantity", Request{Op: OpTransfer, EntityID: "player:kain", TargetID: "npc:trader", ItemID: "arrow"}, ErrInvalidQuantity},
+		{"to itself", Request{Op: OpTransfer, EntityID: "player:kain", TargetID: "player:kain", ItemID: "arrow", Quantity: 1}, ErrInvalidRequest},
+		{"bound item", Request{Op: OpTransfer, EntityID: "player:kain", TargetID: "npc:trader", ItemID: "ring:oath", Quantity: 1}, ErrForbidden},
+		{"world rule", Request{Op: OpTransfer, EntityID: "player:kain", TargetID: "npc:trader", ItemID: "relic:crown", Quantity: 1}, ErrForbidden},
+		{"unknown recipient", Request{Op: OpTransfer, EntityID: "player:kain", TargetID: "npc:ghost", ItemID: "arrow", Quantity: 1}, ErrUnknownEntity},
+	} {
+		tc.req.WorldID = "ash-realm"
+		if err := inventory.Apply(tc.req); !errors.Is(err, tc.want) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+	}
+	if len(*published) != 1 {
+		t.Errorf("expected rejected operations to publish nothing, got %d events", len(*published))
+	}
+}
+
+func TestPublishFailureKeepsInventories(t *testing.T) {
+	inventory, _ := newTestInventory()
+	inventory.publish = func(ctx context.Context, event eventbus.Event) error { return errors.New("broker down") }
+
+	if err := inventory.Transfer("ash-realm", "player:kain", "npc:trader", "arrow", 20, eventbus.Event{}); err == nil {
+		t.Fatal("expected the transfer to fail")
+	}
+	unlock := inventory.store.Lock("ash-realm", "player:kain", "npc:trader")
+	defer unlock()
+	kain, _ := inventory.store.Holdings("ash-realm", "player:kain")
+	trader, _ := inventory.store.Holdings("ash-realm", "npc:trader")
+	if kain.Quantity("arrow") != 20 || trader.Quantity("arrow") != 1 {
+		t.Errorf("expected both inventories unchanged, got %d and %d arrows", kain.Quantity("arrow"), trader.Quantity("arrow"))
+	}
+}
+
+func TestEquip(t *testing.T) {
+	inventory, published := newTestInventory()
+
+	if err := inventory.Equip("ash-realm", "player:kain", "sword:ash", "", eventbus.Event{}); err != nil {
+		t.Fatalf("equip failed: %v", err)
+	}
+	if slot, _ := (*published)[0].Path().GetString("slot"); slot != "main_hand" {
+		t.Errorf("expected the slot of the item, got %q", slot)
+	}
+	if err := inventory.Equip("ash-realm", "player:kain", "sword:ash", "off_hand", eventbus.Event{}); !errors.Is(err, ErrInsufficient) {
+		t.Errorf("expected one sword to fill one slot, got %v", err)
+	}
+	if err := inventory.Equip("ash-realm", "player:kain", "arrow", "", eventbus.Event{}); !errors.Is(err, ErrNoSlot) {
+		t.Errorf("expected no slot for arrows, got %v", err)
+	}
+	if err := inventory.Consume("ash-realm", "player:kain", "sword:ash", 1, eventbus.Event{}); !errors.Is(err, ErrEquipped) {
+		t.Errorf("expected the equipped sword to stay, got %v", err)
+	}
+
+	if err := inventory.Unequip("ash-realm", "player:kain", "main_hand", eventbus.Event{}); err != nil {
+		t.Fatalf("unequip failed: %v", err)
+	}
+	if err := inventory.Consume("ash-realm", "player:kain", "sword:ash", 1, eventbus.Event{}); err != nil {
+		t.Errorf("expected the unequipped sword to be consumable, got %v", err)
+	}
+}
+
+func TestHandleEvent(t *testing.T) {
+	inventory, published := newTestInventory()
+
+	grant := eventbus.NewEvent("item.grant.requested", "city-governor", "ash-realm", map[string]interface{}{
+		"entity_id": "player:kain",
+		"item_id":   "arrow",
+		"quantity":  5.0,
+	})
+	grant.ID = "ev-1"
+	inventory.HandleEvent(grant)
+	inventory.HandleEvent(grant) // redelivered
+	if len(*published) != 1 || (*published)[0].Type != EventItemGranted || (*published)[0].CausationID != "ev-1" {
+		t.Fatalf("expected one item.granted, got %+v", *published)
+	}
+	if held, _ := (*published)[0].Path().GetFloat("held"); held != 25 {
+		t.Errorf("expected 25 arrows held, got %v", held)
+	}
+
+	fractional := eventbus.NewEvent("item.consume.requested", "game-service", "ash-realm", map[string]interface{}{
+		"entity_id": "player:kain",
+		"item_id":   "arrow",
+		"quantity":  1.5,
+	})
+	fractional.ID = "ev-2"
+	inventory.HandleEvent(fractional)
+	rejected := (*published)[1]
+	if reason, _ := rejected.Path().GetString("reason"); rejected.Type != EventItemRejected || reason != "invalid_quantity" {
+		t.Errorf("expected item.rejected for a fractional quantity, got %s %v", rejected.Type, rejected.Payload)
+	}
+
+	// Consumable items are used up by player.used_item, others are not
+	for i, item := range []string{"healing_potion", "sword:ash"} {
+		used := eventbus.NewEvent("player.used_item", "game-service", "ash-realm", map[string]interface{}{
+			"entity_id": "player:kain",
+			"item_id":   item,
+		})
+		used.ID = "ev-used-" + item
+		inventory.HandleEvent(used)
+		if len(*published) != 3 {
+			t.Fatalf("%d: expected only the potion consumed, got %d events", i, len(*published))
+		}
+	}
+	if (*published)[2].Type != EventItemConsumed {
+		t.Errorf("expected item.consumed, got %s", (*published)[2].Type)
+	}
+}
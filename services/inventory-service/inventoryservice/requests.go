@@ -0,0 +1,134 @@
+package inventoryservice
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+)
+
+// requestEvents map the events requesting inventory operations to the operations.
+var requestEvents = map[string]string{
+	"item.grant.requested":    OpGrant,
+	"item.consume.requested":  OpConsume,
+	"item.transfer.requested": OpTransfer,
+	"item.equip.requested":    OpEquip,
+	"item.unequip.requested":  OpUnequip,
+}
+
+// eventPlayerUsedItem is published by GameService for the use_item command; consumable items are used up.
+const eventPlayerUsedItem = "player.used_item"
+
+// handledTTL is how long a handled request is remembered, so a redelivered event is not applied twice.
+const handledTTL = 10 * time.Minute
+
+// handledRequests remembers the IDs of recently handled events.
+type handledRequests struct {
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+// first records the event ID and reports whether it was not handled before.
+func (h *handledRequests) first(id string, now time.Time) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if at, ok := h.seen[id]; ok && now.Sub(at) < handledTTL {
+		return false
+	}
+	if h.seen == nil {
+		h.seen = make(map[string]time.Time)
+	}
+	for seenID, at := range h.seen {
+		if now.Sub(at) >= handledTTL {
+			delete(h.seen, seenID)
+		}
+	}
+	h.seen[id] = now
+	return true
+}
+
+// HandleEvent applies requested inventory operations; refused requests are answered with item.rejected.
+func (is *InventoryService) HandleEvent(ev eventbus.Event) {
+	if ev.Source == source {
+		return
+	}
+	op, requested := requestEvents[ev.Type]
+	if !requested && ev.Type != eventPlayerUsedItem {
+		return
+	}
+	if ev.ID != "" && !is.handled.first(ev.ID, time.Now()) {
+		logging.Infof("Ignoring redelivered inventory request %s", ev.ID)
+		return
+	}
+
+	if ev.Type == eventPlayerUsedItem {
+		is.useItem(ev)
+		return
+	}
+
+	req, err := requestFromEvent(op, ev)
+	if err == nil {
+		err = is.Apply(req)
+	}
+	switch {
+	case err == nil:
+	case rejectionCode(err) != "":
+		is.Reject(req, err)
+	default:
+		logging.Errorf("Failed to apply %s request %s: %v", op, ev.ID, err)
+	}
+}
+
+// useItem consumes one consumable item used by a player. GameService has already checked
+// that the item is in the inventory, so a failure is only logged.
+func (is *InventoryService) useItem(ev eventbus.Event) {
+	req, err := requestFromEvent(OpConsume, ev)
+	if err != nil || !is.store.Item(req.WorldID, req.ItemID).Consumable {
+		return
+	}
+	if err := is.Apply(req); err != nil {
+		logging.Warnf("Used item %s of %s not consumed: %v", req.ItemID, req.EntityID, err)
+	}
+}
+
+// requestFromEvent reads an operation from its request event: the holder from entity.id
+// (or player_id), the recipient from target.entity.id (or target_id, or target as an ID),
+// item_id (or item, item.id), quantity (1 when absent) and slot.
+func requestFromEvent(op string, ev eventbus.Event) (Request, error) {
+	req := Request{Op: op, WorldID: eventbus.GetWorldIDFromEvent(ev), Quantity: 1, Cause: ev}
+	pa := ev.Path()
+	if entityInfo, ok := ev.GetEntityIDWithFallback(); ok {
+		req.EntityID = entityInfo.ID
+	} else {
+		req.EntityID, _ = pa.GetString("player_id")
+	}
+	if targetInfo, ok := ev.GetTargetEntityID(); ok {
+		req.TargetID = targetInfo.ID
+	} else {
+		req.TargetID, _ = ev.Payload["target"].(string)
+	}
+	for _, path := range []string{"item_id", "item", "item.id"} {
+		if item, ok := pa.GetString(path); ok && item != "" {
+			req.ItemID = item
+			break
+		}
+	}
+	req.Slot, _ = pa.GetString("slot")
+
+	if value, ok := ev.Payload["quantity"]; ok {
+		switch quantity := value.(type) {
+		case int:
+			req.Quantity = quantity
+		case float64:
+			if quantity != float64(int(quantity)) {
+				return req, fmt.Errorf("%w: %v is not a whole number", ErrInvalidQuantity, quantity)
+			}
+			req.Quantity = int(quantity)
+		default:
+			return req, fmt.Errorf("%w: %v is not a number", ErrInvalidQuantity, value)
+		}
+	}
+	return req, nil
+}
@@ -0,0 +1,115 @@
+package inventoryservice
+
+import (
+	"fmt"
+	"path"
+	"slices"
+
+	"multiverse-core.io/shared/archivist"
+	"multiverse-core.io/shared/logging"
+	"multiverse-core.io/shared/schema"
+)
+
+// ItemRule forbids operations with matching items in a world.
+// Items are glob patterns (path.Match) of item IDs, e.g. "relic:*".
+type ItemRule struct {
+	ID    string   `json:"id"`
+	Items []string `json:"items"`
+	// Operations are the forbidden operations; empty forbids transfers only
+	Operations []string `json:"operations,omitempty"`
+	// Reason explains the rule in item.rejected
+	Reason string `json:"reason,omitempty"`
+}
+
+// validate rejects rules that match no item or name an unknown operation.
+func (r ItemRule) validate() error {
+	if len(r.Items) == 0 {
+		return fmt.Errorf("item rule %q has no items", r.ID)
+	}
+	for _, pattern := range r.Items {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("item rule %q has invalid pattern %q: %w", r.ID, pattern, err)
+		}
+	}
+	for _, op := range r.Operations {
+		if !slices.Contains(operations, op) {
+			return fmt.Errorf("item rule %q forbids unknown operation %q", r.ID, op)
+		}
+	}
+	return nil
+}
+
+// forbids reports whether the rule forbids the operation with the item.
+func (r ItemRule) forbids(op, itemID string) bool {
+	forbidden := r.Operations
+	if len(forbidden) == 0 {
+		forbidden = []string{OpTransfer}
+	}
+	if !slices.Contains(forbidden, op) {
+		return false
+	}
+	for _, pattern := range r.Items {
+		if ok, err := path.Match(pattern, itemID); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// worldProfile is the part of a world ontology profile used by InventoryService.
+type worldProfile struct {
+	ItemRules []ItemRule `json:"item_rules"`
+}
+
+// profileItemRules returns the valid item rules of a world profile.
+func profileItemRules(worldID string, profile worldProfile) []ItemRule {
+	var rules []ItemRule
+	for _, rule := range profile.ItemRules {
+		if err := rule.validate(); err != nil {
+			logging.Warnf("Skipping item rule of world %s: %v", worldID, err)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	if len(rules) > 0 {
+		logging.Infof("Loaded %d item rules of world %s", len(rules), worldID)
+	}
+	return rules
+}
+
+// Rules keeps the item rules of worlds loaded from their ontology profiles.
+// Without an archivist no world restricts items.
+type Rules struct {
+	worlds *archivist.ProfileCache[worldProfile, []ItemRule]
+}
+
+// NewRules creates item rules without restrictions.
+func NewRules() *Rules {
+	return &Rules{worlds: archivist.NewProfileCache(archivist.WorldProfileType, []ItemRule(nil), profileItemRules)}
+}
+
+// UseArchivist loads item rules from the world ontology profiles in OntologicalArchivist.
+func (r *Rules) UseArchivist(client *archivist.Client) {
+	r.worlds.UseClient(client)
+}
+
+// Forbidding returns the rule of the world that forbids the operation with the item; nil if none does.
+func (r *Rules) Forbidding(worldID, op, itemID string) *ItemRule {
+	for _, rule := range r.For(worldID) {
+		if rule.forbids(op, itemID) {
+			return &rule
+		}
+	}
+	return nil
+}
+
+// For returns the item rules of a world, loading its profile on first use.
+func (r *Rules) For(worldID string) []ItemRule {
+	rules, _ := r.worlds.Get(worldID)
+	return rules
+}
+
+// HandleSchemaChange reloads the rules of a world when the archivist announces a new profile version.
+func (r *Rules) HandleSchemaChange(change schema.Change) {
+	r.worlds.HandleSchemaChange(change)
+}
@@ -0,0 +1,59 @@
+package inventoryservice
+
+import (
+	"context"
+
+	"multiverse-core.io/shared/archivist"
+	"multiverse-core.io/shared/eventbus"
+	storage "multiverse-core.io/shared/minio"
+	"multiverse-core.io/shared/schema"
+)
+
+// Service manages the InventoryService lifecycle.
+type Service struct {
+	bus       *eventbus.EventBus
+	inventory *InventoryService
+	// schemaChanges reloads item rules when the archivist announces a new profile version
+	schemaChanges *schema.ChangeSubscriber
+}
+
+// NewService creates a new InventoryService service.
+func NewService(bus *eventbus.EventBus) *Service {
+	return &Service{
+		bus:       bus,
+		inventory: NewInventoryService(bus),
+	}
+}
+
+// UseArchivist loads item rules from OntologicalArchivist and reloads them on schema changes.
+func (s *Service) UseArchivist(client *archivist.Client) {
+	s.inventory.rules.UseArchivist(client)
+	s.schemaChanges = schema.NewChangeSubscriber(s.bus, "inventory-service")
+	s.schemaChanges.OnChange(s.inventory.rules.HandleSchemaChange)
+}
+
+// UseEntityStorage enables reading inventories and items from their entities in MinIO.
+func (s *Service) UseEntityStorage(client storage.ObjectStorage) {
+	s.inventory.store.UseStorage(client)
+}
+
+// Run starts the service and blocks until context is cancelled.
+func (s *Service) Run(ctx context.Context) error {
+	if s.schemaChanges != nil {
+		go s.schemaChanges.Run(ctx)
+	}
+
+	// Subscribe to relevant event topics
+	topics := []string{
+		eventbus.TopicPlayerEvents,
+		eventbus.TopicWorldEvents,
+		eventbus.TopicGameEvents,
+	}
+
+	for _, topic := range topics {
+		go s.bus.Subscribe(ctx, topic, "inventory-service-group", s.inventory.HandleEvent)
+	}
+
+	<-ctx.Done()
+	return ctx.Err()
+}
@@ -4,60 +4,56 @@ package main
 import (
 	"context"
 	"log"
-	"os"
-	"os/signal"
-	"strings"
-	"syscall"
 
 	"multiverse-core.io/services/narrative-orchestrator/narrativeorchestrator"
+	"multiverse-core.io/shared/config"
+	"multiverse-core.io/shared/service"
 )
 
 func main() {
+	app := service.Setup("narrative-orchestrator", config.OracleOptions, []config.Option{
+		{Env: "SEMANTIC_MEMORY_URL", Default: "http://semantic-memory:8080", Type: config.TypeURL},
+		{Env: "SCOPE_MANAGER_ENABLED", Default: "true", Type: config.TypeBool, Usage: "create GM scopes automatically from player activity"},
+		{Env: "SCOPE_GROUP_RADIUS", Default: "50", Type: config.TypeFloat, Positive: true, Usage: "players closer than this share a group scope"},
+		{Env: "SCOPE_IDLE_TTL", Default: "10m", Type: config.TypeDuration, Positive: true, Usage: "scopes without player activity are removed after this time"},
+		{Env: "GM_SNAPSHOT_RETENTION", Default: "10", Type: config.TypeInt, Positive: true, Usage: "GM snapshots kept per scope"},
+		{Env: "GM_SNAPSHOT_PRUNE_INTERVAL", Default: "10m", Type: config.TypeDuration, Positive: true, Usage: "how often old GM snapshots are removed"},
+		{Env: "GEOMETRY_CACHE_TTL", Default: "1m", Type: config.TypeDuration, Positive: true, Usage: "how long scope geometries from SemanticMemory are cached"},
+		{Env: "GEOMETRY_REFRESH_INTERVAL", Default: "30s", Type: config.TypeDuration, Positive: true, Usage: "how often geometries of active GMs are refreshed in one batch per world"},
+		{Env: "ARCHIVIST_URL", Type: config.TypeURL, Usage: "fallback archivist address"},
+		{Env: "FORBIDDANCES_FROM_ARCHIVIST", Default: "true", Type: config.TypeBool, Usage: "load world forbiddances for prompts and event filtering from ontology profiles (false uses built-in rules only)"},
+		{Env: "NARRATIVE_PORT", Default: "8087", Type: config.TypeInt, Positive: true, Usage: "port of the canon HTTP API"},
+	})
+	env := app.Env
+	app.UseOracleAudit()
+
 	cfg := narrativeorchestrator.Config{
-		KafkaBrokers: getEnvBrokers("KAFKA_BROKERS", []string{"redpanda:9092"}),
+		KafkaBrokers:              env.List("KAFKA_BROKERS"),
+		SnapshotRetention:         env.Int("GM_SNAPSHOT_RETENTION"),
+		SnapshotPruneInterval:     env.Duration("GM_SNAPSHOT_PRUNE_INTERVAL"),
+		HTTPPort:                  env.String("NARRATIVE_PORT"),
+		GeometryCacheTTL:          env.Duration("GEOMETRY_CACHE_TTL"),
+		GeometryRefresh:           env.Duration("GEOMETRY_REFRESH_INTERVAL"),
+		ForbiddancesFromArchivist: env.Bool("FORBIDDANCES_FROM_ARCHIVIST"),
+		ArchivistURL:              env.String("ARCHIVIST_URL"),
+	}
+	if env.Bool("SCOPE_MANAGER_ENABLED") {
+		cfg.Scopes = &narrativeorchestrator.ScopeManagerConfig{
+			GroupRadius: env.Float("SCOPE_GROUP_RADIUS"),
+			IdleTTL:     env.Duration("SCOPE_IDLE_TTL"),
+		}
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	go func() {
-		<-sigChan
-		log.Println("Shutting down NarrativeOrchestrator...")
-		cancel()
-	}()
-
-	service, err := narrativeorchestrator.NewService(cfg)
+	orchestrator, err := narrativeorchestrator.NewService(cfg)
 	if err != nil {
 		log.Fatal("Failed to initialize NarrativeOrchestrator:", err)
 	}
 
-	service.Start(ctx)
-	<-ctx.Done()
-	service.Stop()
-	log.Println("NarrativeOrchestrator stopped.")
-}
-
-func getEnv(key, fallback string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return fallback
-}
-
-func getEnvBrokers(key string, fallback []string) []string {
-	if value := os.Getenv(key); value != "" {
-		parts := strings.Split(value, ",")
-		brokers := make([]string, 0, len(parts))
-		for _, part := range parts {
-			if trimmed := strings.TrimSpace(part); trimmed != "" {
-				brokers = append(brokers, trimmed)
-			}
-		}
-		if len(brokers) > 0 {
-			return brokers
-		}
-	}
-	return fallback
+	app.Run(service.Background(func(ctx context.Context) error {
+		orchestrator.Start(ctx)
+		return nil
+	}, func() error {
+		orchestrator.Stop()
+		return nil
+	}))
 }
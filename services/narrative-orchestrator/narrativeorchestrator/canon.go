@@ -0,0 +1,379 @@
+// services/narrativeorchestrator/canon.go
+
+package narrativeorchestrator
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/minio"
+
+	"github.com/google/uuid"
+)
+
+// Канон хранится в canonBucket: {world_id}/world.json — факты мира,
+// {world_id}/scopes/{sha256(scope_id)}.json — факты скоупа (CanonLedger).
+const canonBucket = "gnue-canon"
+
+// Ограничения канона
+const (
+	// maxCanonEntries — фактов в реестре; сверх него удаляются старейшие извлечённые
+	maxCanonEntries = 200
+	// maxPromptCanon — фактов канона в промте
+	maxPromptCanon = 20
+)
+
+// Источники записей канона
+const (
+	CanonSourceExtracted = "extracted" // извлечена из new_events Oracle
+	CanonSourceManual    = "manual"    // добавлена или исправлена через API
+)
+
+// Значения payload.canon в new_events: факт скоупа или всего мира
+const (
+	CanonScopeFact = "scope"
+	CanonWorldFact = "world"
+)
+
+// ErrCanonEntryNotFound — записи канона с таким ID нет в реестре.
+var ErrCanonEntryNotFound = errors.New("canon entry not found")
+
+// durableEventPattern — типы событий, устанавливающие долговременные факты, если Oracle
+// не отметил их payload.canon: гибель, разрушение, основание, открытие, союзы и т.п.
+var durableEventPattern = regexp.MustCompile(`\.(died|killed|destroyed|created|founded|born|revealed|discovered|conquered|allied|betrayed|cursed|sealed|crowned|married)$`)
+
+// CanonEntry — факт канона: то, что уже произошло и не должно быть опровергнуто повествованием.
+type CanonEntry struct {
+	ID       string   `json:"id"`
+	Fact     string   `json:"fact"`
+	Entities []string `json:"entities,omitempty"`
+	Source   string   `json:"source"`
+	// EventID — событие, из которого извлечён факт
+	EventID   string    `json:"event_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CanonLedger — реестр канона мира (ScopeID пуст) или скоупа ГМ.
+type CanonLedger struct {
+	WorldID   string       `json:"world_id"`
+	ScopeID   string       `json:"scope_id,omitempty"`
+	Entries   []CanonEntry `json:"entries"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+// CanonStore хранит реестры канона в MinIO и кэширует их в памяти.
+// Без хранилища канон живёт только в памяти процесса.
+type CanonStore struct {
+	storage minio.ObjectStorage
+	now     func() time.Time
+
+	mu      sync.Mutex
+	ledgers map[string]*CanonLedger // ключ объекта → реестр
+}
+
+// NewCanonStore создаёт хранилище канона; storage может быть nil.
+func NewCanonStore(storage minio.ObjectStorage) *CanonStore {
+	return &CanonStore{
+		storage: storage,
+		now:     time.Now,
+		ledgers: make(map[string]*CanonLedger),
+	}
+}
+
+func canonKey(worldID, scopeID string) string {
+	if scopeID == "" {
+		return path.Join(worldID, "world.json")
+	}
+	return path.Join(worldID, "scopes", fmt.Sprintf("%x.json", sha256.Sum256([]byte(scopeID))))
+}
+
+// Ledger возвращает копию реестра канона; отсутствующий реестр — пустой.
+func (s *CanonStore) Ledger(worldID, scopeID string) (CanonLedger, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ledger, err := s.ledgerLocked(worldID, scopeID)
+	if err != nil {
+		return CanonLedger{}, err
+	}
+	copied := *ledger
+	copied.Entries = append([]CanonEntry(nil), ledger.Entries...)
+	return copied, nil
+}
+
+// Record добавляет извлечённые факты, пропуская уже известные; возвращает число добавленных.
+func (s *CanonStore) Record(worldID, scopeID string, entries []CanonEntry) (int, error) {
+	if len(entries) == 0 {
+		return 0, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ledger, err := s.ledgerLocked(worldID, scopeID)
+	if err != nil {
+		return 0, err
+	}
+	known := make(map[string]bool, len(ledger.Entries))
+	for _, entry := range ledger.Entries {
+		known[normalizeFact(entry.Fact)] = true
+	}
+	added := 0
+	for _, entry := range entries {
+		key := normalizeFact(entry.Fact)
+		if known[key] {
+			continue
+		}
+		known[key] = true
+		ledger.Entries = append(ledger.Entries, s.stamp(entry))
+		added++
+	}
+	if added == 0 {
+		return 0, nil
+	}
+	trimCanon(ledger)
+	return added, s.saveLocked(ledger)
+}
+
+// Add добавляет факт вручную.
+func (s *CanonStore) Add(worldID, scopeID string, entry CanonEntry) (CanonEntry, error) {
+	entry.ID = ""
+	entry.Source = CanonSourceManual
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ledger, err := s.ledgerLocked(worldID, scopeID)
+	if err != nil {
+		return CanonEntry{}, err
+	}
+	entry = s.stamp(entry)
+	ledger.Entries = append(ledger.Entries, entry)
+	trimCanon(ledger)
+	return entry, s.saveLocked(ledger)
+}
+
+// Update исправляет текст и сущности факта; исправленный факт становится ручным.
+func (s *CanonStore) Update(worldID, scopeID, id, fact string, entities []string) (CanonEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ledger, err := s.ledgerLocked(worldID, scopeID)
+	if err != nil {
+		return CanonEntry{}, err
+	}
+	for i := range ledger.Entries {
+		entry := &ledger.Entries[i]
+		if entry.ID != id {
+			continue
+		}
+		entry.Fact = fact
+		if entities != nil {
+			entry.Entities = entities
+		}
+		entry.Source = CanonSourceManual
+		entry.UpdatedAt = s.now().UTC()
+		return *entry, s.saveLocked(ledger)
+	}
+	return CanonEntry{}, fmt.Errorf("%s: %w", id, ErrCanonEntryNotFound)
+}
+
+// Delete удаляет факт из реестра.
+func (s *CanonStore) Delete(worldID, scopeID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ledger, err := s.ledgerLocked(worldID, scopeID)
+	if err != nil {
+		return err
+	}
+	for i, entry := range ledger.Entries {
+		if entry.ID == id {
+			ledger.Entries = append(ledger.Entries[:i], ledger.Entries[i+1:]...)
+			return s.saveLocked(ledger)
+		}
+	}
+	return fmt.Errorf("%s: %w", id, ErrCanonEntryNotFound)
+}
+
+// Merge переносит факты скоупов sources в скоуп target (gm.merged).
+func (s *CanonStore) Merge(worldID, target string, sources []string) error {
+	var entries []CanonEntry
+	for _, source := range sources {
+		ledger, err := s.Ledger(worldID, source)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, ledger.Entries...)
+	}
+	_, err := s.Record(worldID, target, entries)
+	return err
+}
+
+// Relevant возвращает факты канона мира и скоупа для промта: сначала связанные с entityIDs,
+// затем общие (без сущностей), новые — первыми, не больше limit.
+func (s *CanonStore) Relevant(worldID, scopeID string, entityIDs []string, limit int) ([]string, error) {
+	world, err := s.Ledger(worldID, "")
+	if err != nil {
+		return nil, err
+	}
+	scope, err := s.Ledger(worldID, scopeID)
+	if err != nil {
+		return nil, err
+	}
+	return relevantCanon(append(world.Entries, scope.Entries...), entityIDs, limit), nil
+}
+
+func relevantCanon(entries []CanonEntry, entityIDs []string, limit int) []string {
+	focus := make(map[string]bool, len(entityIDs))
+	for _, id := range entityIDs {
+		focus[id] = true
+	}
+	rank := func(entry CanonEntry) int {
+		if len(entry.Entities) == 0 {
+			return 1
+		}
+		for _, id := range entry.Entities {
+			if focus[id] {
+				return 0
+			}
+		}
+		return 2 // факт о сущностях вне внимания ГМ
+	}
+	var candidates []CanonEntry
+	for _, entry := range entries {
+		if rank(entry) < 2 {
+			candidates = append(candidates, entry)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if ri, rj := rank(candidates[i]), rank(candidates[j]); ri != rj {
+			return ri < rj
+		}
+		return candidates[i].UpdatedAt.After(candidates[j].UpdatedAt)
+	})
+	var facts []string
+	seen := make(map[string]bool)
+	for _, entry := range candidates {
+		if len(facts) == limit {
+			break
+		}
+		if key := normalizeFact(entry.Fact); !seen[key] {
+			seen[key] = true
+			facts = append(facts, entry.Fact)
+		}
+	}
+	return facts
+}
+
+// ledgerLocked возвращает реестр из кэша или MinIO; отсутствующий реестр создаётся пустым.
+func (s *CanonStore) ledgerLocked(worldID, scopeID string) (*CanonLedger, error) {
+	key := canonKey(worldID, scopeID)
+	if ledger, ok := s.ledgers[key]; ok {
+		return ledger, nil
+	}
+	ledger := &CanonLedger{WorldID: worldID, ScopeID: scopeID}
+	if s.storage != nil {
+		data, err := s.storage.GetObject(canonBucket, key)
+		switch {
+		case err == nil:
+			if err := json.Unmarshal(data, ledger); err != nil {
+				return nil, fmt.Errorf("invalid canon ledger %s: %w", key, err)
+			}
+		case !minio.IsNotFound(err):
+			return nil, fmt.Errorf("load canon ledger %s: %w", key, err)
+		}
+	}
+	s.ledgers[key] = ledger
+	return ledger, nil
+}
+
+func (s *CanonStore) saveLocked(ledger *CanonLedger) error {
+	ledger.UpdatedAt = s.now().UTC()
+	if s.storage == nil {
+		return nil
+	}
+	data, err := json.Marshal(ledger)
+	if err != nil {
+		return err
+	}
+	key := canonKey(ledger.WorldID, ledger.ScopeID)
+	if err := s.storage.PutObject(canonBucket, key, bytes.NewReader(data), int64(len(data))); err != nil {
+		return fmt.Errorf("save canon ledger %s: %w", key, err)
+	}
+	return nil
+}
+
+// stamp присваивает записи ID и время создания.
+func (s *CanonStore) stamp(entry CanonEntry) CanonEntry {
+	now := s.now().UTC()
+	if entry.ID == "" {
+		entry.ID = uuid.New().String()
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = now
+	}
+	entry.UpdatedAt = now
+	return entry
+}
+
+// trimCanon удаляет старейшие извлечённые факты сверх maxCanonEntries; ручные факты сохраняются.
+func trimCanon(ledger *CanonLedger) {
+	excess := len(ledger.Entries) - maxCanonEntries
+	if excess <= 0 {
+		return
+	}
+	kept := ledger.Entries[:0]
+	for _, entry := range ledger.Entries {
+		if excess > 0 && entry.Source == CanonSourceExtracted {
+			excess--
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	ledger.Entries = kept
+}
+
+// extractCanon извлекает долговременные факты из опубликованных new_events: события,
+// отмеченные Oracle payload.canon ("scope" или "world"), и события долговременных типов.
+// Возвращает факты скоупа и факты мира.
+func extractCanon(events []eventbus.Event) (scope, world []CanonEntry) {
+	for _, ev := range events {
+		mark, _ := ev.Payload["canon"].(string)
+		if mark == "" && !durableEventPattern.MatchString(ev.Type) {
+			continue
+		}
+		fact := strings.TrimSpace(formatEventDescription(ev))
+		if fact == "" {
+			continue
+		}
+		entry := CanonEntry{
+			Fact:     fact,
+			Entities: uniqueIDs(extractEntityIDs(ev.Payload)),
+			Source:   CanonSourceExtracted,
+			EventID:  ev.ID,
+		}
+		if mark == CanonWorldFact {
+			world = append(world, entry)
+		} else {
+			scope = append(scope, entry)
+		}
+	}
+	return scope, world
+}
+
+func uniqueIDs(ids []string) []string {
+	var unique []string
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,142 @@
+package narrativeorchestrator
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/minio/miniotest"
+)
+
+func TestExtractCanon(t *testing.T) {
+	events := []eventbus.Event{
+		generatedEvent("npc.died", "Стражник Орин пал у ворот", "npc:orin"),
+		generatedEvent("environment.sound", "За стеной слышен вой"),
+		generatedEvent("faction.pact", "Гильдии заключили союз", "faction:smiths", "faction:smiths"),
+		generatedEvent("city.renamed", "Город отныне зовётся Пепельными Вратами"),
+	}
+	events[2].Payload["canon"] = CanonScopeFact
+	events[3].Payload["canon"] = CanonWorldFact
+
+	scope, world := extractCanon(events)
+	if len(scope) != 2 || scope[0].Fact != "Стражник Орин пал у ворот" || scope[0].EventID != events[0].ID {
+		t.Fatalf("unexpected scope canon %+v", scope)
+	}
+	if !reflect.DeepEqual(scope[1].Entities, []string{"faction:smiths"}) || scope[1].Source != CanonSourceExtracted {
+		t.Errorf("unexpected marked fact %+v", scope[1])
+	}
+	if len(world) != 1 || world[0].Fact != "Город отныне зовётся Пепельными Вратами" {
+		t.Errorf("unexpected world canon %+v", world)
+	}
+}
+
+func TestCanonStore(t *testing.T) {
+	storage := miniotest.New()
+	store := NewCanonStore(storage)
+
+	added, err := store.Record("pain-realm", "player:kain", []CanonEntry{
+		{Fact: "Стражник Орин пал у ворот", Entities: []string{"npc:orin"}, Source: CanonSourceExtracted},
+		{Fact: "стражник Орин пал у ворот.", Source: CanonSourceExtracted},
+		{Fact: "Лавка кузнеца сгорела", Entities: []string{"npc:smith"}, Source: CanonSourceExtracted},
+	})
+	if err != nil || added != 2 {
+		t.Fatalf("expected 2 facts recorded, got %d, %v", added, err)
+	}
+	if _, err := store.Add("pain-realm", "", CanonEntry{Fact: "Над миром нет солнца"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Реестры сохраняются в MinIO и читаются новым хранилищем
+	reloaded := NewCanonStore(storage)
+	ledger, err := reloaded.Ledger("pain-realm", "player:kain")
+	if err != nil || len(ledger.Entries) != 2 || ledger.Entries[0].ID == "" {
+		t.Fatalf("unexpected reloaded ledger %+v, %v", ledger, err)
+	}
+
+	// Сначала факты о сущностях ГМ, затем общие; факты о чужих сущностях не попадают в промт
+	facts, err := reloaded.Relevant("pain-realm", "player:kain", []string{"npc:orin"}, maxPromptCanon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"Стражник Орин пал у ворот", "Над миром нет солнца"}; !reflect.DeepEqual(facts, want) {
+		t.Errorf("relevant canon %v, want %v", facts, want)
+	}
+
+	updated, err := reloaded.Update("pain-realm", "player:kain", ledger.Entries[1].ID, "Лавка кузнеца уцелела", nil)
+	if err != nil || updated.Source != CanonSourceManual || !reflect.DeepEqual(updated.Entities, []string{"npc:smith"}) {
+		t.Errorf("unexpected update %+v, %v", updated, err)
+	}
+	if err := reloaded.Delete("pain-realm", "player:kain", "missing"); !errors.Is(err, ErrCanonEntryNotFound) {
+		t.Errorf("expected ErrCanonEntryNotFound, got %v", err)
+	}
+
+	// Слияние скоупов переносит канон источника
+	if err := reloaded.Merge("pain-realm", "group:g1", []string{"player:kain"}); err != nil {
+		t.Fatal(err)
+	}
+	if group, _ := reloaded.Ledger("pain-realm", "group:g1"); len(group.Entries) != 2 {
+		t.Errorf("expected merged canon, got %+v", group.Entries)
+	}
+}
+
+func TestTrimCanonKeepsManualFacts(t *testing.T) {
+	ledger := &CanonLedger{Entries: []CanonEntry{{ID: "manual", Source: CanonSourceManual}}}
+	for i := 0; i < maxCanonEntries+5; i++ {
+		ledger.Entries = append(ledger.Entries, CanonEntry{ID: "extracted", Source: CanonSourceExtracted, UpdatedAt: time.Now()})
+	}
+	trimCanon(ledger)
+	if len(ledger.Entries) != maxCanonEntries || ledger.Entries[0].ID != "manual" {
+		t.Errorf("expected %d entries with the manual fact kept, got %d", maxCanonEntries, len(ledger.Entries))
+	}
+}
+
+func TestCanonHTTP(t *testing.T) {
+	s := &Service{orchestrator: NewNarrativeOrchestratorWithStorage(nil, miniotest.New())}
+	srv := httptest.NewServer(s.routes())
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/v1/worlds/pain-realm/canon", "application/json", strings.NewReader(`{"scope_id": "city:ashgate", "fact": "Ворота города заперты", "entities": ["city:ashgate"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entry CanonEntry
+	json.NewDecoder(resp.Body).Decode(&entry)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated || entry.ID == "" || entry.Source != CanonSourceManual {
+		t.Fatalf("unexpected add response %d %+v", resp.StatusCode, entry)
+	}
+
+	req, _ := http.NewRequest(http.MethodPut, srv.URL+"/v1/worlds/pain-realm/canon/"+entry.ID, strings.NewReader(`{"scope_id": "city:ashgate", "fact": "Ворота города открыты"}`))
+	if resp, err := http.DefaultClient.Do(req); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("update failed: %v %v", resp, err)
+	}
+
+	resp, err = http.Get(srv.URL + "/v1/worlds/pain-realm/canon?scope_id=city:ashgate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ledger CanonLedger
+	json.NewDecoder(resp.Body).Decode(&ledger)
+	resp.Body.Close()
+	if len(ledger.Entries) != 1 || ledger.Entries[0].Fact != "Ворота города открыты" {
+		t.Fatalf("unexpected ledger %+v", ledger)
+	}
+
+	req, _ = http.NewRequest(http.MethodDelete, srv.URL+"/v1/worlds/pain-realm/canon/"+entry.ID+"?scope_id=city:ashgate", nil)
+	if resp, err := http.DefaultClient.Do(req); err != nil || resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("delete failed: %v %v", resp, err)
+	}
+	req, _ = http.NewRequest(http.MethodDelete, srv.URL+"/v1/worlds/pain-realm/canon/"+entry.ID+"?scope_id=city:ashgate", nil)
+	if resp, err := http.DefaultClient.Do(req); err != nil || resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for a deleted entry: %v %v", resp, err)
+	}
+	if resp, err := http.Post(srv.URL+"/v1/worlds/pain-realm/canon", "application/json", strings.NewReader(`{"fact": " "}`)); err != nil || resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for an empty fact: %v %v", resp, err)
+	}
+}
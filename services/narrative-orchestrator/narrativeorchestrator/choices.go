@@ -0,0 +1,332 @@
+// services/narrativeorchestrator/choices.go
+
+package narrativeorchestrator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+
+	"github.com/google/uuid"
+)
+
+// Типы событий точек выбора
+const (
+	// EventChoiceOffered — ГМ предлагает игрокам выбор (narrative_output, GameService показывает его клиентам)
+	EventChoiceOffered = "narrative.choice.offered"
+	// EventChoiceSelected — игрок выбрал вариант (player_events, публикует GameService)
+	EventChoiceSelected = "player.choice.selected"
+	// EventChoiceResolved — выбор разрешён игроком или по таймауту (narrative_output)
+	EventChoiceResolved = "narrative.choice.resolved"
+)
+
+// Ограничения точек выбора
+const (
+	minChoiceOptions     = 2
+	maxChoiceOptions     = 3
+	defaultChoiceTimeout = 2 * time.Minute
+	minChoiceTimeout     = 15 * time.Second
+	maxChoiceTimeout     = 30 * time.Minute
+	// maxResolvedChoices — сколько разрешённых выборов ждут следующего промта
+	maxResolvedChoices = 5
+)
+
+// Кто разрешил выбор
+const (
+	ChoiceResolvedByPlayer  = "player"
+	ChoiceResolvedByTimeout = "timeout"
+)
+
+// ChoiceOption — вариант выбора с последствием, которое Oracle учтёт после выбора
+type ChoiceOption struct {
+	ID          string `json:"id"`
+	Text        string `json:"text"`
+	Consequence string `json:"consequence,omitempty"`
+}
+
+// OracleChoice — точка выбора в ответе Oracle
+type OracleChoice struct {
+	Prompt         string         `json:"prompt"`
+	Options        []ChoiceOption `json:"options"`
+	DefaultOption  string         `json:"default_option,omitempty"`
+	TimeoutSeconds int            `json:"timeout_seconds,omitempty"`
+}
+
+// PendingChoice — выбор, ожидающий ответа игроков. Сохраняется в снапшоте ГМ.
+type PendingChoice struct {
+	ID            string         `json:"id"`
+	Prompt        string         `json:"prompt"`
+	Options       []ChoiceOption `json:"options"`
+	DefaultOption string         `json:"default_option"`
+	Participants  []string       `json:"participants,omitempty"`
+	OfferedAt     time.Time      `json:"offered_at"`
+	ExpiresAt     time.Time      `json:"expires_at"`
+}
+
+// option возвращает вариант по ID
+func (c *PendingChoice) option(id string) (ChoiceOption, bool) {
+	for _, option := range c.Options {
+		if option.ID == id {
+			return option, true
+		}
+	}
+	return ChoiceOption{}, false
+}
+
+// ResolvedChoice — итог выбора, передаётся в следующий промт ГМ
+type ResolvedChoice struct {
+	ChoiceID   string       `json:"choice_id"`
+	Prompt     string       `json:"prompt"`
+	Option     ChoiceOption `json:"option"`
+	ResolvedBy string       `json:"resolved_by"`
+	PlayerID   string       `json:"player_id,omitempty"`
+	ResolvedAt time.Time    `json:"resolved_at"`
+}
+
+// promptLine форматирует итог выбора для промта Oracle
+func (r ResolvedChoice) promptLine() string {
+	who := "Никто не ответил, выбран вариант по умолчанию"
+	if r.ResolvedBy == ChoiceResolvedByPlayer {
+		who = fmt.Sprintf("Игрок %s выбрал", r.PlayerID)
+	}
+	line := fmt.Sprintf("%q — %s: %q", r.Prompt, who, r.Option.Text)
+	if r.Option.Consequence != "" {
+		line += ". Последствие: " + r.Option.Consequence
+	}
+	return line
+}
+
+// normalizeChoice проверяет выбор от Oracle: 2–3 варианта с текстом, уникальные ID,
+// корректный вариант по умолчанию и таймаут в допустимых пределах.
+func normalizeChoice(choice *OracleChoice) (*OracleChoice, error) {
+	if choice == nil || strings.TrimSpace(choice.Prompt) == "" {
+		return nil, fmt.Errorf("choice prompt is empty")
+	}
+
+	options := make([]ChoiceOption, 0, maxChoiceOptions)
+	seen := make(map[string]bool)
+	for _, option := range choice.Options {
+		if strings.TrimSpace(option.Text) == "" {
+			continue
+		}
+		if option.ID == "" || seen[option.ID] {
+			option.ID = fmt.Sprintf("option-%d", len(options)+1)
+		}
+		seen[option.ID] = true
+		options = append(options, option)
+		if len(options) == maxChoiceOptions {
+			break
+		}
+	}
+	if len(options) < minChoiceOptions {
+		return nil, fmt.Errorf("choice needs at least %d options, got %d", minChoiceOptions, len(options))
+	}
+
+	normalized := &OracleChoice{
+		Prompt:         choice.Prompt,
+		Options:        options,
+		DefaultOption:  options[0].ID,
+		TimeoutSeconds: int(defaultChoiceTimeout / time.Second),
+	}
+	if seen[choice.DefaultOption] {
+		normalized.DefaultOption = choice.DefaultOption
+	}
+	if choice.TimeoutSeconds > 0 {
+		timeout := time.Duration(choice.TimeoutSeconds) * time.Second
+		if timeout < minChoiceTimeout {
+			timeout = minChoiceTimeout
+		}
+		if timeout > maxChoiceTimeout {
+			timeout = maxChoiceTimeout
+		}
+		normalized.TimeoutSeconds = int(timeout / time.Second)
+	}
+	return normalized, nil
+}
+
+// offerChoice сохраняет выбор как ожидающий и публикует narrative.choice.offered.
+// У ГМ одновременно может быть только один ожидающий выбор; cause — событие, на которое ответил Oracle.
+func (no *NarrativeOrchestrator) offerChoice(gm *GMInstance, choice *OracleChoice, cause eventbus.Event) {
+	normalized, err := normalizeChoice(choice)
+	if err != nil {
+		warnLog(gm.ScopeID, gm.WorldID, "Ignoring invalid choice from Oracle", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	now := time.Now().UTC()
+	gm.mu.Lock()
+	if gm.PendingChoice != nil {
+		gm.mu.Unlock()
+		debugLog(gm.ScopeID, gm.WorldID, "GM already has a pending choice, new one ignored", map[string]interface{}{})
+		return
+	}
+	pending := &PendingChoice{
+		ID:            uuid.NewString(),
+		Prompt:        normalized.Prompt,
+		Options:       normalized.Options,
+		DefaultOption: normalized.DefaultOption,
+		Participants:  append([]string(nil), gm.FocusEntities...),
+		OfferedAt:     now,
+		ExpiresAt:     now.Add(time.Duration(normalized.TimeoutSeconds) * time.Second),
+	}
+	gm.PendingChoice = pending
+	gm.mu.Unlock()
+
+	payload := map[string]interface{}{
+		"choice_id":      pending.ID,
+		"prompt":         pending.Prompt,
+		"options":        pending.Options,
+		"default_option": pending.DefaultOption,
+		"participants":   pending.Participants,
+		"expires_at":     pending.ExpiresAt.Format(time.RFC3339),
+	}
+	eventbus.SetNested(payload, "scope.id", gm.ScopeID)
+	eventbus.SetNested(payload, "scope.type", gm.ScopeType)
+	outputEvent := eventbus.NewEvent(EventChoiceOffered, "narrative-orchestrator", gm.WorldID, payload).CausedBy(cause)
+
+	if err := no.bus.Publish(context.Background(), eventbus.TopicNarrativeOutput, outputEvent); err != nil {
+		errorLog(gm.ScopeID, gm.WorldID, "Failed to publish choice", map[string]interface{}{
+			"error":     err.Error(),
+			"choice_id": pending.ID,
+		})
+		return
+	}
+	infoLog(gm.ScopeID, gm.WorldID, "Offered choice to players", map[string]interface{}{
+		"choice_id":  pending.ID,
+		"options":    len(pending.Options),
+		"expires_at": pending.ExpiresAt,
+	})
+}
+
+// HandleChoiceSelected обрабатывает player.choice.selected: первый корректный ответ разрешает выбор.
+func (no *NarrativeOrchestrator) HandleChoiceSelected(ev eventbus.Event) {
+	choiceID, _ := ev.Payload["choice_id"].(string)
+	optionID, _ := ev.Payload["option_id"].(string)
+	playerID := ""
+	if info := eventbus.ExtractEntityID(ev.Payload); info != nil {
+		playerID = info.ID
+	}
+	if choiceID == "" || optionID == "" {
+		warnLog("", eventbus.GetWorldIDFromEvent(ev), "Choice selection without choice_id or option_id", map[string]interface{}{
+			"event_id": ev.ID,
+		})
+		return
+	}
+
+	no.mu.RLock()
+	var owner *GMInstance
+	for _, gm := range no.gms {
+		gm.mu.Lock()
+		if gm.PendingChoice != nil && gm.PendingChoice.ID == choiceID {
+			owner = gm
+		}
+		gm.mu.Unlock()
+		if owner != nil {
+			break
+		}
+	}
+	no.mu.RUnlock()
+
+	if owner == nil {
+		debugLog("", eventbus.GetWorldIDFromEvent(ev), "Choice is not pending (already resolved or unknown)", map[string]interface{}{
+			"choice_id": choiceID,
+		})
+		return
+	}
+	no.resolveChoice(owner, &ev, choiceID, optionID, ChoiceResolvedByPlayer, playerID)
+}
+
+// resolveExpiredChoices разрешает просроченные выборы вариантами по умолчанию.
+// Вызывается по таймеру, поэтому переживает и перезапуск сервиса (выбор хранится в снапшоте).
+func (no *NarrativeOrchestrator) resolveExpiredChoices(gms []*GMInstance, now time.Time) {
+	for _, gm := range gms {
+		gm.mu.Lock()
+		pending := gm.PendingChoice
+		expired := pending != nil && !now.Before(pending.ExpiresAt)
+		gm.mu.Unlock()
+
+		if expired {
+			no.resolveChoice(gm, nil, pending.ID, pending.DefaultOption, ChoiceResolvedByTimeout, "")
+		}
+	}
+}
+
+// resolveChoice фиксирует итог выбора для следующего промта, публикует narrative.choice.resolved
+// и сразу запускает обработку ГМ, чтобы история продолжилась с учётом выбора.
+// cause — ответ игрока; при разрешении по таймауту он nil и событие начинает новую цепочку.
+func (no *NarrativeOrchestrator) resolveChoice(gm *GMInstance, cause *eventbus.Event, choiceID, optionID, resolvedBy, playerID string) bool {
+	gm.mu.Lock()
+	pending := gm.PendingChoice
+	if pending == nil || pending.ID != choiceID {
+		gm.mu.Unlock()
+		return false
+	}
+	option, ok := pending.option(optionID)
+	if !ok {
+		gm.mu.Unlock()
+		warnLog(gm.ScopeID, gm.WorldID, "Unknown option selected", map[string]interface{}{
+			"choice_id": choiceID,
+			"option_id": optionID,
+		})
+		return false
+	}
+
+	resolved := ResolvedChoice{
+		ChoiceID:   pending.ID,
+		Prompt:     pending.Prompt,
+		Option:     option,
+		ResolvedBy: resolvedBy,
+		PlayerID:   playerID,
+		ResolvedAt: time.Now().UTC(),
+	}
+	gm.PendingChoice = nil
+	gm.ResolvedChoices = append(gm.ResolvedChoices, resolved)
+	if len(gm.ResolvedChoices) > maxResolvedChoices {
+		gm.ResolvedChoices = gm.ResolvedChoices[len(gm.ResolvedChoices)-maxResolvedChoices:]
+	}
+	gm.mu.Unlock()
+
+	payload := map[string]interface{}{
+		"choice_id":   resolved.ChoiceID,
+		"prompt":      resolved.Prompt,
+		"option":      resolved.Option,
+		"resolved_by": resolved.ResolvedBy,
+		"description": resolved.promptLine(),
+	}
+	eventbus.SetNested(payload, "scope.id", gm.ScopeID)
+	eventbus.SetNested(payload, "scope.type", gm.ScopeType)
+	if playerID != "" {
+		eventbus.SetNested(payload, "entity.id", playerID)
+		eventbus.SetNested(payload, "entity.type", "player")
+	}
+	outputEvent := eventbus.NewEvent(EventChoiceResolved, "narrative-orchestrator", gm.WorldID, payload)
+	if cause != nil {
+		outputEvent = outputEvent.CausedBy(*cause)
+	}
+
+	if err := no.bus.Publish(context.Background(), eventbus.TopicNarrativeOutput, outputEvent); err != nil {
+		errorLog(gm.ScopeID, gm.WorldID, "Failed to publish resolved choice", map[string]interface{}{
+			"error":     err.Error(),
+			"choice_id": choiceID,
+		})
+	}
+	infoLog(gm.ScopeID, gm.WorldID, "Choice resolved", map[string]interface{}{
+		"choice_id":   choiceID,
+		"option_id":   optionID,
+		"resolved_by": resolvedBy,
+	})
+
+	if err := no.saveSnapshot(gm.ScopeID, gm); err != nil {
+		warnLog(gm.ScopeID, gm.WorldID, "Failed to save GM snapshot after choice", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	go no.processBatchForGM(gm)
+	return true
+}
@@ -0,0 +1,60 @@
+// services/narrativeorchestrator/choices_test.go
+
+package narrativeorchestrator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeChoice(t *testing.T) {
+	choice, err := normalizeChoice(&OracleChoice{
+		Prompt: "Открыть дверь?",
+		Options: []ChoiceOption{
+			{ID: "open", Text: "Открыть", Consequence: "Из-за двери вырвется туман"},
+			{ID: "open", Text: "Выломать"},
+			{Text: "  "},
+			{ID: "leave", Text: "Уйти"},
+			{ID: "wait", Text: "Ждать"},
+		},
+		DefaultOption:  "missing",
+		TimeoutSeconds: 5,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(choice.Options) != maxChoiceOptions {
+		t.Fatalf("expected %d options, got %d", maxChoiceOptions, len(choice.Options))
+	}
+	if choice.Options[1].ID == "open" {
+		t.Errorf("expected duplicate option ID to be replaced, got %q", choice.Options[1].ID)
+	}
+	if choice.DefaultOption != "open" {
+		t.Errorf("expected unknown default to fall back to the first option, got %q", choice.DefaultOption)
+	}
+	if choice.TimeoutSeconds != int(minChoiceTimeout.Seconds()) {
+		t.Errorf("expected timeout clamped to %v, got %ds", minChoiceTimeout, choice.TimeoutSeconds)
+	}
+
+	if _, err := normalizeChoice(&OracleChoice{Prompt: "Один путь", Options: []ChoiceOption{{ID: "a", Text: "Идти"}}}); err == nil {
+		t.Error("expected error for a choice with a single option")
+	}
+}
+
+func TestBuildStructuredPrompt_Choices(t *testing.T) {
+	s := minimalSections()
+	s.ResolvedChoices = []string{ResolvedChoice{
+		Prompt:     "Открыть дверь?",
+		Option:     ChoiceOption{ID: "open", Text: "Открыть", Consequence: "Из-за двери вырвется туман"},
+		ResolvedBy: ChoiceResolvedByTimeout,
+	}.promptLine()}
+	s.PendingChoice = "Бежать или драться?"
+
+	_, usr := BuildStructuredPrompt(s)
+	for _, want := range []string{"<resolved_choices>", "Из-за двери вырвется туман", "<pending_choice>Бежать или драться?</pending_choice>"} {
+		if !strings.Contains(usr, want) {
+			t.Errorf("user prompt missing %q", want)
+		}
+	}
+}
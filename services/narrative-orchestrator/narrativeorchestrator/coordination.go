@@ -0,0 +1,375 @@
+// services/narrativeorchestrator/coordination.go
+
+package narrativeorchestrator
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/spatial"
+)
+
+// coordinationFactTTL — сколько принятый координатором факт блокирует противоречащие ему события
+const coordinationFactTTL = 10 * time.Minute
+
+// coordinatorScopePrefix — префикс координаторов, созданных для групп ГМ без ГМ локации
+const coordinatorScopePrefix = "coord:"
+
+// coordinatedFact — событие, принятое координатором локации.
+type coordinatedFact struct {
+	EventID     string
+	EventType   string
+	ScopeID     string // скоуп ГМ, предложившего событие
+	Description string
+	AcceptedAt  time.Time
+}
+
+// rejectedEvent — событие ГМ, отклонённое координатором из-за уже принятого факта.
+type rejectedEvent struct {
+	Event eventbus.Event
+	Fact  coordinatedFact
+	// Duplicate — событие повторяет факт; иначе противоречит ему
+	Duplicate bool
+}
+
+// arbitration — итог арбитража событий одного ГМ.
+type arbitration struct {
+	Accepted []eventbus.Event
+	Rejected []rejectedEvent
+}
+
+// LocationCoordinator — ГМ уровня локации, арбитрирующий new_events ГМ игроков и групп,
+// чьи области видимости пересекаются: первый принятый факт о сущностях становится общим,
+// повторы и противоречия от других ГМ отклоняются.
+type LocationCoordinator struct {
+	ScopeID string
+	WorldID string
+	// Elected — арбитр выбран из существующих ГМ локации; иначе создан для группы ГМ
+	Elected bool
+
+	mu         sync.Mutex
+	facts      map[string]coordinatedFact // ключ факта → принятое событие
+	children   map[string]time.Time       // скоупы ГМ → последнее участие в арбитраже
+	lastActive time.Time
+}
+
+func newLocationCoordinator(scopeID, worldID string, elected bool) *LocationCoordinator {
+	return &LocationCoordinator{
+		ScopeID:  scopeID,
+		WorldID:  worldID,
+		Elected:  elected,
+		facts:    make(map[string]coordinatedFact),
+		children: make(map[string]time.Time),
+	}
+}
+
+// Arbitrate принимает события ГМ scopeID, не противоречащие фактам других ГМ. Свои факты ГМ
+// может уточнять: событие с тем же ключом от того же ГМ заменяет прежний факт.
+func (c *LocationCoordinator) Arbitrate(scopeID string, proposals []eventbus.Event, now time.Time) arbitration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.evictLocked(now)
+	c.children[scopeID] = now
+	c.lastActive = now
+
+	var result arbitration
+	for _, ev := range proposals {
+		key := factKey(ev)
+		description := formatEventDescription(ev)
+		if fact, ok := c.facts[key]; ok && fact.ScopeID != scopeID {
+			result.Rejected = append(result.Rejected, rejectedEvent{
+				Event:     ev,
+				Fact:      fact,
+				Duplicate: normalizeFact(fact.Description) == normalizeFact(description),
+			})
+			continue
+		}
+		c.facts[key] = coordinatedFact{
+			EventID:     ev.ID,
+			EventType:   ev.Type,
+			ScopeID:     scopeID,
+			Description: description,
+			AcceptedAt:  now,
+		}
+		result.Accepted = append(result.Accepted, ev)
+	}
+	return result
+}
+
+// Children возвращает скоупы ГМ, участвовавших в арбитраже за coordinationFactTTL, кроме except.
+func (c *LocationCoordinator) Children(except string) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ids := make([]string, 0, len(c.children))
+	for id := range c.children {
+		if id != except {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// idle сообщает, что координатор не арбитрировал дольше coordinationFactTTL.
+func (c *LocationCoordinator) idle(now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return now.Sub(c.lastActive) > coordinationFactTTL
+}
+
+func (c *LocationCoordinator) evictLocked(now time.Time) {
+	for key, fact := range c.facts {
+		if now.Sub(fact.AcceptedAt) > coordinationFactTTL {
+			delete(c.facts, key)
+		}
+	}
+	for id, seen := range c.children {
+		if now.Sub(seen) > coordinationFactTTL {
+			delete(c.children, id)
+		}
+	}
+}
+
+// factKey — о чём событие: тип и участвующие сущности. События без сущностей
+// (погода, звук в городе) описывают локацию целиком и совпадают по типу.
+func factKey(ev eventbus.Event) string {
+	ids := extractEntityIDs(ev.Payload)
+	sort.Strings(ids)
+	unique := ids[:0]
+	for i, id := range ids {
+		if id != "" && (i == 0 || id != ids[i-1]) {
+			unique = append(unique, id)
+		}
+	}
+	return ev.Type + "|" + strings.Join(unique, ",")
+}
+
+// normalizeFact приводит описание к виду для сравнения повторов: регистр, пробелы, точка в конце.
+func normalizeFact(description string) string {
+	return strings.TrimRight(strings.Join(strings.Fields(strings.ToLower(description)), " "), ".!…")
+}
+
+// isChildScopeType — ГМ, повествование которых согласуется координатором локации.
+func isChildScopeType(scopeType string) bool {
+	return scopeType == ScopeTypePlayer || scopeType == ScopeTypeGroup
+}
+
+// isLocationScopeType — ГМ, которые могут быть избраны координаторами.
+func isLocationScopeType(scopeType string) bool {
+	return scopeType == "location" || scopeType == ScopeTypeCity || scopeType == "region"
+}
+
+// scopesOverlap сообщает, пересекаются ли области видимости; круги сравниваются точно,
+// полигоны — по ограничивающим прямоугольникам.
+func scopesOverlap(a, b spatial.VisibilityScope) bool {
+	if a.IsEmpty() || b.IsEmpty() {
+		return false
+	}
+	if a.Polygon == nil && b.Polygon == nil && a.Override == nil && b.Override == nil {
+		return spatial.DistanceBetween(a.Center, b.Center) <= a.Radius+b.Radius
+	}
+	return a.Bounds().Intersects(b.Bounds())
+}
+
+func boundsArea(b spatial.BoundingBox) float64 {
+	if b.IsInfinite() {
+		return math.Inf(1)
+	}
+	return (b.Max.X - b.Min.X) * (b.Max.Y - b.Min.Y)
+}
+
+// coordinatorFor возвращает координатора локации ГМ и остальных ГМ, чьё повествование он
+// согласует. ГМ игрока или группы координируется, когда его область пересекается с областью
+// другого такого ГМ: арбитром избирается наименьший пересекающийся ГМ локации, а без него
+// создаётся координатор группы. ГМ локации арбитрирует свои события, если уже избран.
+// nil — ГМ повествует самостоятельно.
+func (no *NarrativeOrchestrator) coordinatorFor(gm *GMInstance) (*LocationCoordinator, []*GMInstance) {
+	gm.mu.Lock()
+	scope := gm.VisibilityScope
+	gm.mu.Unlock()
+
+	if isLocationScopeType(gm.ScopeType) {
+		no.coordMu.Lock()
+		coord := no.coordinators[gm.ScopeID]
+		no.coordMu.Unlock()
+		if coord == nil {
+			return nil, nil
+		}
+		return coord, no.gmsByScope(coord.Children(gm.ScopeID))
+	}
+	if !isChildScopeType(gm.ScopeType) || scope.IsEmpty() {
+		return nil, nil
+	}
+
+	var peers []*GMInstance
+	var arbiter *GMInstance
+	arbiterArea := math.Inf(1)
+	no.mu.RLock()
+	for _, id := range no.scopes.Intersecting(gm.WorldID, scope.Bounds()) {
+		other, ok := no.gms[id]
+		if !ok || other == gm || !scopesOverlap(scope, other.VisibilityScope) {
+			continue
+		}
+		switch {
+		case isChildScopeType(other.ScopeType):
+			peers = append(peers, other)
+		case isLocationScopeType(other.ScopeType):
+			// Избирается самая маленькая локация; при равенстве — с меньшим ID
+			area := boundsArea(other.VisibilityScope.Bounds())
+			if area < arbiterArea || (area == arbiterArea && arbiter != nil && other.ScopeID < arbiter.ScopeID) {
+				arbiter, arbiterArea = other, area
+			}
+		}
+	}
+	no.mu.RUnlock()
+	if len(peers) == 0 {
+		return nil, nil
+	}
+	sort.Slice(peers, func(i, j int) bool { return peers[i].ScopeID < peers[j].ScopeID })
+
+	arbiterID, elected := "", arbiter != nil
+	if elected {
+		arbiterID = arbiter.ScopeID
+	} else {
+		// Координатор группы именуется по наименьшему скоупу, чтобы все её ГМ пришли к одному
+		arbiterID = gm.ScopeID
+		if peers[0].ScopeID < arbiterID {
+			arbiterID = peers[0].ScopeID
+		}
+		arbiterID = coordinatorScopePrefix + arbiterID
+	}
+
+	no.coordMu.Lock()
+	defer no.coordMu.Unlock()
+	if no.coordinators == nil {
+		no.coordinators = make(map[string]*LocationCoordinator)
+	}
+	coord, ok := no.coordinators[arbiterID]
+	if !ok {
+		coord = newLocationCoordinator(arbiterID, gm.WorldID, elected)
+		no.coordinators[arbiterID] = coord
+		infoLog(gm.ScopeID, gm.WorldID, "Location coordinator assigned", map[string]interface{}{
+			"coordinator_scope_id": arbiterID,
+			"elected":              elected,
+			"peers_count":          len(peers),
+		})
+	}
+	return coord, peers
+}
+
+// gmsByScope возвращает существующие ГМ скоупов.
+func (no *NarrativeOrchestrator) gmsByScope(scopeIDs []string) []*GMInstance {
+	no.mu.RLock()
+	defer no.mu.RUnlock()
+	var gms []*GMInstance
+	for _, id := range scopeIDs {
+		if gm, ok := no.gms[id]; ok {
+			gms = append(gms, gm)
+		}
+	}
+	return gms
+}
+
+// coordinate пропускает события и повествование ГМ через координатора его локации.
+// Принятые события помечаются payload.coordination {scope_id, scopes} и попадают в историю
+// остальных ГМ локации, которые получают и повествование о них. Если событие ГМ противоречит
+// уже принятому факту, его повествование заменяется описанием принятых фактов.
+func (no *NarrativeOrchestrator) coordinate(gm *GMInstance, events []eventbus.Event, narrative string, cause eventbus.Event) ([]eventbus.Event, string) {
+	coord, peers := no.coordinatorFor(gm)
+	if coord == nil {
+		return events, narrative
+	}
+
+	result := coord.Arbitrate(gm.ScopeID, events, time.Now())
+
+	scopes := make([]interface{}, 0, len(peers)+1)
+	scopes = append(scopes, gm.ScopeID)
+	for _, peer := range peers {
+		scopes = append(scopes, peer.ScopeID)
+	}
+	for _, ev := range result.Accepted {
+		eventbus.SetNested(ev.Payload, "coordination.scope_id", coord.ScopeID)
+		eventbus.SetNested(ev.Payload, "coordination.scopes", scopes)
+	}
+
+	var facts []string
+	contradicted := false
+	for _, rejected := range result.Rejected {
+		warnLog(gm.ScopeID, gm.WorldID, "Coordinator rejected generated event", map[string]interface{}{
+			"coordinator_scope_id": coord.ScopeID,
+			"event_type":           rejected.Event.Type,
+			"accepted_event_id":    rejected.Fact.EventID,
+			"accepted_scope_id":    rejected.Fact.ScopeID,
+			"duplicate":            rejected.Duplicate,
+		})
+		if !rejected.Duplicate {
+			contradicted = true
+		}
+		facts = append(facts, rejected.Fact.Description)
+	}
+
+	// Остальные ГМ локации узнают о принятых событиях сразу, а не по маршрутизации
+	var accepted []string
+	for _, ev := range result.Accepted {
+		accepted = append(accepted, formatEventDescription(ev))
+	}
+	for _, peer := range peers {
+		peer.mu.Lock()
+		for _, ev := range result.Accepted {
+			peer.History = append(peer.History, newHistoryEntry(ev))
+		}
+		peer.mu.Unlock()
+		if len(accepted) > 0 {
+			no.publishCoordinatedNarrative(peer, coord, strings.Join(accepted, ". "), cause)
+		}
+	}
+
+	if contradicted {
+		narrative = strings.Join(append(facts, accepted...), ". ")
+	}
+	return result.Accepted, narrative
+}
+
+// publishCoordinatedNarrative публикует ГМ повествование о фактах, принятых координатором.
+func (no *NarrativeOrchestrator) publishCoordinatedNarrative(gm *GMInstance, coord *LocationCoordinator, narrative string, cause eventbus.Event) {
+	payload := map[string]interface{}{"narrative": narrative}
+	eventbus.SetNested(payload, "scope.id", gm.ScopeID)
+	eventbus.SetNested(payload, "scope.type", gm.ScopeType)
+	eventbus.SetNested(payload, "coordination.scope_id", coord.ScopeID)
+	ev := eventbus.NewEvent("narrative.generate", "narrative-orchestrator", gm.WorldID, payload).CausedBy(cause)
+	if err := no.bus.Publish(context.Background(), eventbus.TopicNarrativeOutput, ev); err != nil {
+		errorLog(gm.ScopeID, gm.WorldID, "Failed to publish coordinated narrative", map[string]interface{}{
+			"error":                err.Error(),
+			"coordinator_scope_id": coord.ScopeID,
+		})
+	}
+}
+
+// coordinatedFor сообщает, что событие уже передано ГМ координатором его локации.
+func coordinatedFor(ev eventbus.Event, scopeID string) bool {
+	scopes, _ := eventbus.GetNested(ev.Payload, "coordination.scopes")
+	list, _ := scopes.([]interface{})
+	for _, id := range list {
+		if id == scopeID {
+			return true
+		}
+	}
+	return false
+}
+
+// sweepCoordinators удаляет координаторов без арбитража дольше coordinationFactTTL.
+func (no *NarrativeOrchestrator) sweepCoordinators(now time.Time) {
+	no.coordMu.Lock()
+	defer no.coordMu.Unlock()
+	for id, coord := range no.coordinators {
+		if coord.idle(now) {
+			delete(no.coordinators, id)
+		}
+	}
+}
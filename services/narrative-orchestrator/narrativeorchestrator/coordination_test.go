@@ -0,0 +1,136 @@
+package narrativeorchestrator
+
+import (
+	"testing"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/spatial"
+)
+
+func generatedEvent(eventType, description string, mentions ...interface{}) eventbus.Event {
+	return eventbus.NewEvent(eventType, "narrative-orchestrator", "pain-realm", map[string]interface{}{
+		"description": description,
+		"mentions":    mentions,
+	})
+}
+
+func TestLocationCoordinator_Arbitrate(t *testing.T) {
+	coord := newLocationCoordinator("city:ashgate", "pain-realm", true)
+	now := time.Now()
+
+	first := coord.Arbitrate("player:kain", []eventbus.Event{
+		generatedEvent("npc.died", "Стражник Орин пал у ворот", "npc:orin"),
+		generatedEvent("weather.changed", "Начался дождь"),
+	}, now)
+	if len(first.Accepted) != 2 || len(first.Rejected) != 0 {
+		t.Fatalf("expected both events accepted, got %+v", first)
+	}
+
+	// Другой ГМ той же локации: повтор, противоречие и новый факт
+	second := coord.Arbitrate("player:lira", []eventbus.Event{
+		generatedEvent("npc.died", "стражник  Орин пал у ворот.", "npc:orin"),
+		generatedEvent("weather.changed", "Ясное небо над городом"),
+		generatedEvent("npc.spoke", "Торговец зазывает покупателей", "npc:merchant"),
+	}, now.Add(time.Minute))
+	if len(second.Accepted) != 1 || second.Accepted[0].Type != "npc.spoke" {
+		t.Fatalf("expected only npc.spoke accepted, got %+v", second.Accepted)
+	}
+	if len(second.Rejected) != 2 || !second.Rejected[0].Duplicate || second.Rejected[1].Duplicate {
+		t.Fatalf("expected duplicate and contradiction, got %+v", second.Rejected)
+	}
+	if second.Rejected[1].Fact.ScopeID != "player:kain" || second.Rejected[1].Fact.Description != "Начался дождь" {
+		t.Errorf("contradiction must point to the accepted fact, got %+v", second.Rejected[1].Fact)
+	}
+
+	// ГМ уточняет свой факт
+	if update := coord.Arbitrate("player:kain", []eventbus.Event{generatedEvent("weather.changed", "Дождь усилился")}, now.Add(2*time.Minute)); len(update.Accepted) != 1 {
+		t.Errorf("own facts must be updatable, got %+v", update)
+	}
+	if children := coord.Children("player:kain"); len(children) != 1 || children[0] != "player:lira" {
+		t.Errorf("unexpected children %v", children)
+	}
+
+	// Факты устаревают
+	late := coord.Arbitrate("player:lira", []eventbus.Event{generatedEvent("weather.changed", "Ясное небо над городом")}, now.Add(2*time.Minute+coordinationFactTTL+time.Second))
+	if len(late.Accepted) != 1 {
+		t.Errorf("expired facts must not block events, got %+v", late)
+	}
+	if coord.idle(now.Add(2 * coordinationFactTTL)) {
+		t.Errorf("coordinator is active")
+	}
+}
+
+func testGM(scopeID, scopeType string, scope spatial.VisibilityScope) *GMInstance {
+	return &GMInstance{ScopeID: scopeID, ScopeType: scopeType, WorldID: "pain-realm", VisibilityScope: scope}
+}
+
+func TestCoordinatorFor(t *testing.T) {
+	no := &NarrativeOrchestrator{
+		gms:    make(map[string]*GMInstance),
+		scopes: spatial.NewWorldIndex(spatial.DefaultCellSize),
+	}
+	add := func(gm *GMInstance) {
+		no.gms[gm.ScopeID] = gm
+		no.indexScope(gm)
+	}
+	kain := testGM("player:kain", ScopeTypePlayer, spatial.VisibilityScope{Center: spatial.Point{X: 0, Y: 0}, Radius: 200})
+	lira := testGM("player:lira", ScopeTypePlayer, spatial.VisibilityScope{Center: spatial.Point{X: 300, Y: 0}, Radius: 200})
+	hermit := testGM("player:hermit", ScopeTypePlayer, spatial.VisibilityScope{Center: spatial.Point{X: 5000, Y: 0}, Radius: 200})
+	add(kain)
+	add(lira)
+	add(hermit)
+
+	if coord, _ := no.coordinatorFor(hermit); coord != nil {
+		t.Errorf("GM without overlapping scopes must narrate alone, got %s", coord.ScopeID)
+	}
+
+	// Без ГМ локации создаётся координатор группы, общий для обоих ГМ
+	coord, peers := no.coordinatorFor(lira)
+	if coord == nil || coord.ScopeID != "coord:player:kain" || coord.Elected {
+		t.Fatalf("expected created coordinator, got %+v", coord)
+	}
+	if len(peers) != 1 || peers[0] != kain {
+		t.Errorf("unexpected peers %v", peers)
+	}
+	if other, _ := no.coordinatorFor(kain); other != coord {
+		t.Errorf("overlapping GMs must share the coordinator")
+	}
+
+	// Избирается наименьший пересекающийся ГМ локации
+	square := func(size float64) *spatial.Polygon {
+		return &spatial.Polygon{{X: -size, Y: -size}, {X: size, Y: -size}, {X: size, Y: size}, {X: -size, Y: size}}
+	}
+	add(testGM("region:ashlands", "region", spatial.VisibilityScope{Polygon: square(3000), Margin: 200}))
+	add(testGM("city:ashgate", ScopeTypeCity, spatial.VisibilityScope{Polygon: square(500), Margin: 200}))
+	coord, _ = no.coordinatorFor(kain)
+	if coord == nil || coord.ScopeID != "city:ashgate" || !coord.Elected {
+		t.Fatalf("expected elected city coordinator, got %+v", coord)
+	}
+
+	// ГМ локации арбитрирует свои события и согласует их с участниками
+	coord.Arbitrate("player:kain", nil, time.Now())
+	city, children := no.coordinatorFor(no.gms["city:ashgate"])
+	if city != coord || len(children) != 1 || children[0] != kain {
+		t.Errorf("location GM must use its coordinator, got %v %v", city, children)
+	}
+	if region, _ := no.coordinatorFor(no.gms["region:ashlands"]); region != nil {
+		t.Errorf("location GM without children must narrate alone")
+	}
+
+	no.sweepCoordinators(time.Now().Add(2 * coordinationFactTTL))
+	if len(no.coordinators) != 0 {
+		t.Errorf("idle coordinators must be removed, got %d", len(no.coordinators))
+	}
+}
+
+func TestCoordinatedFor(t *testing.T) {
+	ev := generatedEvent("npc.spoke", "Торговец зазывает покупателей")
+	if coordinatedFor(ev, "player:lira") {
+		t.Errorf("uncoordinated event")
+	}
+	eventbus.SetNested(ev.Payload, "coordination.scopes", []interface{}{"player:kain", "player:lira"})
+	if !coordinatedFor(ev, "player:lira") || coordinatedFor(ev, "player:hermit") {
+		t.Errorf("unexpected coordination of %v", ev.Payload["coordination"])
+	}
+}
@@ -0,0 +1,386 @@
+// services/narrativeorchestrator/forbiddances.go
+
+package narrativeorchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/archivist"
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/jsonpath"
+	"multiverse-core.io/shared/minio"
+	"multiverse-core.io/shared/schema"
+)
+
+// Профили онтологии в OntologicalArchivist с запретами миров — те же, что применяет BanOfWorld.
+const (
+	// universeProfileType/universeProfileName — профиль вселенной, его правила действуют во всех мирах
+	universeProfileType = "universe_ontology_profile"
+	universeProfileName = "cosmic_law"
+	// worldProfileType — профили миров, имя профиля — ID мира
+	worldProfileType = "world_ontology_profile"
+)
+
+const (
+	// forbiddanceProfileTTL — время жизни загруженного профиля; изменения приходят и событиями schema.updated
+	forbiddanceProfileTTL = 10 * time.Minute
+	// forbiddanceRetryInterval — через сколько повторить загрузку профиля, если архивариус был недоступен
+	forbiddanceRetryInterval = time.Minute
+	// forbiddanceLoadTimeout — таймаут загрузки одного профиля
+	forbiddanceLoadTimeout = 5 * time.Second
+)
+
+// ForbiddanceRule — декларативный запрет BanOfWorld (forbiddance_rules профиля онтологии):
+// действие, подходящее под все заданные условия, нарушает целостность мира.
+// Условия — шаблоны path.Match (например "fire_*"); список подходит, если подходит любой шаблон.
+type ForbiddanceRule struct {
+	ID string `json:"id"`
+	// Forbiddance — архетипический запрет профиля, который обеспечивает правило
+	Forbiddance string `json:"forbiddance,omitempty"`
+	// Worlds ограничивает правило мирами; пусто — все миры
+	Worlds        []string          `json:"worlds,omitempty"`
+	EventTypes    []string          `json:"event_types,omitempty"`
+	Skills        []string          `json:"skills,omitempty"`
+	SkillElements []string          `json:"skill_elements,omitempty"`
+	SkillTags     []string          `json:"skill_tags,omitempty"`
+	Items         []string          `json:"items,omitempty"`
+	Payload       map[string]string `json:"payload,omitempty"` // путь в payload → шаблон значения
+	ViolationType string            `json:"violation_type"`
+}
+
+// ForbiddanceProfile — часть профиля онтологии вселенной или мира с запретами.
+type ForbiddanceProfile struct {
+	ArchetypalForbiddances []string          `json:"archetypal_forbiddances"`
+	ForbiddanceRules       []ForbiddanceRule `json:"forbiddance_rules"`
+}
+
+// defaultForbiddanceRules действуют в мирах без профиля онтологии — как правила по умолчанию BanOfWorld.
+var defaultForbiddanceRules = []ForbiddanceRule{
+	// В Мире Боли запрещены огонь и исцеление
+	{ID: "pain-realm-fire", Worlds: []string{"pain-realm"}, EventTypes: []string{"player.used_skill"}, Skills: []string{"fire_breath"}, ViolationType: "elemental_conflict"},
+	{ID: "pain-realm-healing", Worlds: []string{"pain-realm"}, EventTypes: []string{"player.used_item"}, Items: []string{"healing_potion"}, ViolationType: "elemental_conflict"},
+	// В Мире Памяти запрещено стирание памяти
+	{ID: "memory-realm-erase", Worlds: []string{"memory-realm"}, EventTypes: []string{"player.used_skill"}, Skills: []string{"memory_erase"}, ViolationType: "memory_violation"},
+	// В Мире Механизмов запрещены органические навыки
+	{ID: "mechanism-realm-organic", Worlds: []string{"mechanism-realm"}, EventTypes: []string{"player.used_skill"}, Skills: []string{"organic_skill"}, ViolationType: "mechanical_purity"},
+}
+
+// Пути навыка и предмета в payload события — те же, что разбирает BanOfWorld.
+var (
+	skillIDPaths      = []string{"skill_id", "skill.id", "action.skill_id", "skill", "action.skill"}
+	skillNamePaths    = []string{"skill_name", "skill.name", "action.skill_name"}
+	skillElementPaths = []string{"skill.element", "skill_element", "action.skill_element"}
+	skillTagPaths     = []string{"skill.tags", "skill_tags", "action.skill_tags"}
+	itemPaths         = []string{"item_id", "item.id", "item", "action.item"}
+)
+
+// appliesTo сообщает, действует ли правило в мире.
+func (r ForbiddanceRule) appliesTo(worldID string) bool {
+	return len(r.Worlds) == 0 || slices.Contains(r.Worlds, worldID)
+}
+
+// matches сообщает, нарушает ли событие в мире worldID правило.
+func (r ForbiddanceRule) matches(worldID string, ev eventbus.Event) bool {
+	if !r.appliesTo(worldID) {
+		return false
+	}
+	pa := ev.Path()
+	if !matchAny(r.EventTypes, ev.Type) || !matchAny(r.Items, firstString(pa, itemPaths)) {
+		return false
+	}
+	if !matchAny(r.Skills, firstString(pa, skillIDPaths)) && !matchAny(r.Skills, firstString(pa, skillNamePaths)) {
+		return false
+	}
+	if !matchAny(r.SkillElements, firstString(pa, skillElementPaths)) || !matchAnyOf(r.SkillTags, stringsAt(pa, skillTagPaths)) {
+		return false
+	}
+	for field, pattern := range r.Payload {
+		value, ok := pa.GetAny(field)
+		if !ok || value == nil || !matchPattern(pattern, fmt.Sprint(value)) {
+			return false
+		}
+	}
+	return true
+}
+
+// validate отклоняет правила без условий (под них подошло бы любое событие) и с неверными шаблонами.
+func (r ForbiddanceRule) validate() error {
+	patterns := slices.Concat(r.EventTypes, r.Skills, r.SkillElements, r.SkillTags, r.Items)
+	for _, pattern := range r.Payload {
+		patterns = append(patterns, pattern)
+	}
+	if len(patterns) == 0 {
+		return fmt.Errorf("rule %q has no conditions", r.ID)
+	}
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("rule %q has invalid pattern %q: %w", r.ID, pattern, err)
+		}
+	}
+	return nil
+}
+
+// describe описывает правило для промта Oracle.
+func (r ForbiddanceRule) describe() string {
+	var parts []string
+	add := func(label string, values []string) {
+		if len(values) > 0 {
+			parts = append(parts, label+" "+strings.Join(values, ", "))
+		}
+	}
+	add("события", r.EventTypes)
+	add("навыки", r.Skills)
+	add("стихии навыков", r.SkillElements)
+	add("теги навыков", r.SkillTags)
+	add("предметы", r.Items)
+	if len(r.Payload) > 0 {
+		fields := make([]string, 0, len(r.Payload))
+		for field, pattern := range r.Payload {
+			fields = append(fields, field+"="+pattern)
+		}
+		sort.Strings(fields)
+		add("payload", fields)
+	}
+
+	description := strings.Join(parts, "; ")
+	if r.Forbiddance != "" {
+		description = r.Forbiddance + ": " + description
+	}
+	return description
+}
+
+// WorldForbiddances — запреты, действующие в мире: из профиля мира (или правила по умолчанию) и профиля вселенной.
+type WorldForbiddances struct {
+	WorldID    string
+	Archetypal []string
+	Rules      []ForbiddanceRule
+}
+
+// add добавляет действующие в мире правила и архетипические запреты профиля.
+func (f *WorldForbiddances) add(profile ForbiddanceProfile, rules []ForbiddanceRule) {
+	for _, forbiddance := range profile.ArchetypalForbiddances {
+		if forbiddance != "" && !slices.Contains(f.Archetypal, forbiddance) {
+			f.Archetypal = append(f.Archetypal, forbiddance)
+		}
+	}
+	for _, rule := range rules {
+		if !rule.appliesTo(f.WorldID) {
+			continue
+		}
+		if err := rule.validate(); err != nil {
+			warnLog("", f.WorldID, "Skipping forbiddance rule", map[string]interface{}{"error": err.Error()})
+			continue
+		}
+		f.Rules = append(f.Rules, rule)
+	}
+}
+
+// Match возвращает первое правило, которое нарушает событие.
+func (f WorldForbiddances) Match(ev eventbus.Event) (ForbiddanceRule, bool) {
+	for _, rule := range f.Rules {
+		if rule.matches(f.WorldID, ev) {
+			return rule, true
+		}
+	}
+	return ForbiddanceRule{}, false
+}
+
+// PromptLines — строки раздела «ЗАПРЕЩЕНО В ЭТОМ МИРЕ»: архетипические запреты и описания правил.
+func (f WorldForbiddances) PromptLines() []string {
+	lines := append([]string(nil), f.Archetypal...)
+	for _, rule := range f.Rules {
+		if description := rule.describe(); description != "" {
+			lines = append(lines, description)
+		}
+	}
+	return lines
+}
+
+// cachedForbiddanceProfile — загруженный профиль; found=false — профиля нет в архивариусе.
+type cachedForbiddanceProfile struct {
+	profile   ForbiddanceProfile
+	found     bool
+	expiresAt time.Time
+}
+
+// ForbiddanceStore загружает профили запретов миров из OntologicalArchivist и кэширует их.
+// Без архивариуса действуют только defaultForbiddanceRules.
+type ForbiddanceStore struct {
+	archivist *archivist.Client
+
+	mu       sync.Mutex
+	profiles map[string]*cachedForbiddanceProfile
+	now      func() time.Time
+}
+
+// NewForbiddanceStore создаёт хранилище запретов; client может быть nil.
+func NewForbiddanceStore(client *archivist.Client) *ForbiddanceStore {
+	return &ForbiddanceStore{
+		archivist: client,
+		profiles:  make(map[string]*cachedForbiddanceProfile),
+		now:       time.Now,
+	}
+}
+
+// UseArchivist загружает запреты миров из профилей онтологии в OntologicalArchivist.
+func (no *NarrativeOrchestrator) UseArchivist(client *archivist.Client) {
+	no.forbiddances = NewForbiddanceStore(client)
+}
+
+// For возвращает запреты мира. Правила профиля мира заменяют правила по умолчанию,
+// правила профиля вселенной добавляются к ним.
+func (s *ForbiddanceStore) For(ctx context.Context, worldID string) WorldForbiddances {
+	forbiddances := WorldForbiddances{WorldID: worldID}
+	if s == nil {
+		forbiddances.add(ForbiddanceProfile{}, defaultForbiddanceRules)
+		return forbiddances
+	}
+
+	if world, found := s.profile(ctx, worldProfileType, worldID); found {
+		forbiddances.add(world, world.ForbiddanceRules)
+	} else {
+		forbiddances.add(ForbiddanceProfile{}, defaultForbiddanceRules)
+	}
+	if universe, found := s.profile(ctx, universeProfileType, universeProfileName); found {
+		forbiddances.add(universe, universe.ForbiddanceRules)
+	}
+	return forbiddances
+}
+
+// profile возвращает профиль из кэша или загружает его. Если архивариус недоступен,
+// остаётся прежний профиль, а загрузка повторяется через forbiddanceRetryInterval.
+func (s *ForbiddanceStore) profile(ctx context.Context, schemaType, name string) (ForbiddanceProfile, bool) {
+	if s.archivist == nil || name == "" {
+		return ForbiddanceProfile{}, false
+	}
+	key := schemaType + "/" + name
+
+	s.mu.Lock()
+	cached, ok := s.profiles[key]
+	s.mu.Unlock()
+	if ok && s.now().Before(cached.expiresAt) {
+		return cached.profile, cached.found
+	}
+
+	loadCtx, cancel := context.WithTimeout(ctx, forbiddanceLoadTimeout)
+	defer cancel()
+	var profile ForbiddanceProfile
+	loaded := &cachedForbiddanceProfile{expiresAt: s.now().Add(forbiddanceProfileTTL)}
+	switch err := s.archivist.GetSchema(loadCtx, schemaType, name, &profile); {
+	case err == nil:
+		loaded.profile, loaded.found = profile, true
+	case errors.Is(err, minio.ErrNotFound):
+		// Профиля нет: для мира действуют правила по умолчанию
+	default:
+		warnLog("", "", "Ontology profile unavailable, keeping current forbiddances", map[string]interface{}{
+			"profile": key,
+			"error":   err.Error(),
+		})
+		if ok {
+			loaded.profile, loaded.found = cached.profile, cached.found
+		}
+		loaded.expiresAt = s.now().Add(forbiddanceRetryInterval)
+	}
+
+	s.mu.Lock()
+	s.profiles[key] = loaded
+	s.mu.Unlock()
+	return loaded.profile, loaded.found
+}
+
+// HandleSchemaChange сбрасывает профиль, новую версию которого анонсировал архивариус.
+func (s *ForbiddanceStore) HandleSchemaChange(change schema.Change) {
+	if change.SchemaType != worldProfileType && change.SchemaType != universeProfileType {
+		return
+	}
+	s.mu.Lock()
+	delete(s.profiles, change.SchemaType+"/"+change.Name)
+	s.mu.Unlock()
+}
+
+// dropForbidden убирает из событий Oracle нарушающие запреты мира, чтобы они не попали в мир и канон.
+func dropForbidden(gm *GMInstance, forbiddances WorldForbiddances, events []eventbus.Event) []eventbus.Event {
+	allowed := events[:0]
+	for _, ev := range events {
+		if rule, ok := forbiddances.Match(ev); ok {
+			warnLog(gm.ScopeID, gm.WorldID, "Oracle event violates world forbiddance, dropped", map[string]interface{}{
+				"event_type":     ev.Type,
+				"rule_id":        rule.ID,
+				"violation_type": rule.ViolationType,
+			})
+			continue
+		}
+		allowed = append(allowed, ev)
+	}
+	return allowed
+}
+
+// matchAny сообщает, подходит ли значение под один из шаблонов; без шаблонов подходит любое.
+func matchAny(patterns []string, value string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	if value == "" {
+		return false
+	}
+	for _, pattern := range patterns {
+		if matchPattern(pattern, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAnyOf сообщает, подходит ли одно из значений под один из шаблонов; без шаблонов подходит любое.
+func matchAnyOf(patterns, values []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, value := range values {
+		if matchAny(patterns, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchPattern(pattern, value string) bool {
+	ok, err := path.Match(pattern, value)
+	return err == nil && ok
+}
+
+// firstString возвращает первое непустое строковое значение по путям.
+func firstString(pa *jsonpath.Accessor, paths []string) string {
+	for _, field := range paths {
+		if value, _ := pa.GetString(field); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// stringsAt возвращает первый список строк по путям.
+func stringsAt(pa *jsonpath.Accessor, paths []string) []string {
+	for _, field := range paths {
+		values, ok := pa.GetSlice(field)
+		if !ok {
+			continue
+		}
+		result := make([]string, 0, len(values))
+		for _, value := range values {
+			if s, ok := value.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package narrativeorchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"multiverse-core.io/shared/archivist"
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/schema"
+)
+
+// fakeArchivist отдаёт профили онтологии по пути /v1/schemas/{type}/{name}/latest
+type fakeArchivist struct {
+	mu       sync.Mutex
+	profiles map[string]ForbiddanceProfile
+	down     bool
+	requests int
+}
+
+func (f *fakeArchivist) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests++
+	if f.down {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	profile, ok := f.profiles[r.URL.Path]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(profile)
+}
+
+func newTestForbiddanceStore(t *testing.T, fake *fakeArchivist) (*ForbiddanceStore, *time.Time) {
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	now := time.Now()
+	store := NewForbiddanceStore(archivist.NewClient(server.URL, nil))
+	store.now = func() time.Time { return now }
+	return store, &now
+}
+
+func oracleEvent(eventType, worldID string, payload map[string]interface{}) eventbus.Event {
+	return eventbus.NewEvent(eventType, "narrative-orchestrator", worldID, payload)
+}
+
+func TestForbiddanceStoreProfiles(t *testing.T) {
+	archivist := &fakeArchivist{profiles: map[string]ForbiddanceProfile{
+		"/v1/schemas/world_ontology_profile/ash-realm/latest": {
+			ArchetypalForbiddances: []string{"Угасание Пламени"},
+			ForbiddanceRules: []ForbiddanceRule{
+				{ID: "ash-water", Forbiddance: "Угасание Пламени", SkillElements: []string{"water*"}, ViolationType: "elemental_conflict"},
+				{ID: "broken", ViolationType: "elemental_conflict"},
+			},
+		},
+		"/v1/schemas/universe_ontology_profile/cosmic_law/latest": {
+			ArchetypalForbiddances: []string{"Фиксация Абсолютного Порядка"},
+			ForbiddanceRules: []ForbiddanceRule{
+				{ID: "no-time-stop", EventTypes: []string{"time.*"}, Payload: map[string]string{"effect": "stop"}, ViolationType: "causality"},
+			},
+		},
+	}}
+	store, _ := newTestForbiddanceStore(t, archivist)
+	ctx := context.Background()
+
+	ash := store.For(ctx, "ash-realm")
+	if len(ash.Rules) != 2 || ash.Rules[0].ID != "ash-water" || ash.Rules[1].ID != "no-time-stop" {
+		t.Fatalf("expected the valid world rule and the universe rule, got %+v", ash.Rules)
+	}
+	lines := ash.PromptLines()
+	if len(lines) != 4 || lines[0] != "Угасание Пламени" || lines[2] != "Угасание Пламени: стихии навыков water*" {
+		t.Errorf("unexpected prompt lines %q", lines)
+	}
+
+	// Мир без профиля получает правила по умолчанию и правила вселенной
+	pain := store.For(ctx, "pain-realm")
+	if len(pain.Rules) != 3 || pain.Rules[0].ID != "pain-realm-fire" {
+		t.Errorf("expected pain-realm default rules with the universe rule, got %+v", pain.Rules)
+	}
+
+	requests := archivist.requests
+	store.For(ctx, "ash-realm")
+	if archivist.requests != requests {
+		t.Errorf("expected cached profiles, got %d more requests", archivist.requests-requests)
+	}
+}
+
+func TestForbiddanceStoreReload(t *testing.T) {
+	archivist := &fakeArchivist{profiles: map[string]ForbiddanceProfile{
+		"/v1/schemas/world_ontology_profile/ash-realm/latest": {
+			ForbiddanceRules: []ForbiddanceRule{{ID: "ash-water", Skills: []string{"water_*"}, ViolationType: "elemental_conflict"}},
+		},
+	}}
+	store, now := newTestForbiddanceStore(t, archivist)
+	ctx := context.Background()
+	store.For(ctx, "ash-realm")
+
+	// Недоступный архивариус не сбрасывает загруженные запреты
+	archivist.down = true
+	*now = now.Add(forbiddanceProfileTTL)
+	if rules := store.For(ctx, "ash-realm").Rules; len(rules) != 1 || rules[0].ID != "ash-water" {
+		t.Fatalf("expected the previous rules while the archivist is down, got %+v", rules)
+	}
+	requests := archivist.requests
+	store.For(ctx, "ash-realm")
+	if archivist.requests != requests {
+		t.Error("expected no reload before the retry interval")
+	}
+
+	// Новая версия профиля сбрасывает кэш
+	archivist.down = false
+	archivist.profiles["/v1/schemas/world_ontology_profile/ash-realm/latest"] = ForbiddanceProfile{
+		ForbiddanceRules: []ForbiddanceRule{{ID: "ash-ice", Skills: []string{"ice_*"}, ViolationType: "elemental_conflict"}},
+	}
+	store.HandleSchemaChange(schema.Change{SchemaType: worldProfileType, Name: "ash-realm"})
+	if rules := store.For(ctx, "ash-realm").Rules; len(rules) != 1 || rules[0].ID != "ash-ice" {
+		t.Errorf("expected the new profile after a schema change, got %+v", rules)
+	}
+}
+
+func TestDropForbidden(t *testing.T) {
+	var store *ForbiddanceStore // без архивариуса — правила по умолчанию
+	forbiddances := store.For(context.Background(), "pain-realm")
+	gm := &GMInstance{ScopeID: "player:kain", WorldID: "pain-realm"}
+
+	events := []eventbus.Event{
+		oracleEvent("player.used_item", "pain-realm", map[string]interface{}{"item": map[string]interface{}{"id": "healing_potion"}}),
+		oracleEvent("environment.sound", "pain-realm", map[string]interface{}{"description": "Скрежет за дверью"}),
+		oracleEvent("player.used_skill", "pain-realm", map[string]interface{}{"skill_name": "fire_breath"}),
+		oracleEvent("player.used_skill", "pain-realm", map[string]interface{}{"skill": map[string]interface{}{"id": "scream_of_pain"}}),
+	}
+	allowed := dropForbidden(gm, forbiddances, events)
+	if len(allowed) != 2 || allowed[0].Type != "environment.sound" || allowed[1].Type != "player.used_skill" {
+		t.Fatalf("expected healing and fire to be dropped, got %+v", allowed)
+	}
+
+	// В других мирах правила Мира Боли не действуют
+	other := store.For(context.Background(), "memory-realm")
+	if _, ok := other.Match(events[0]); ok {
+		t.Error("pain-realm rules must not apply in memory-realm")
+	}
+}
+
+func TestForbiddanceRuleTags(t *testing.T) {
+	rule := ForbiddanceRule{ID: "no-holy", SkillTags: []string{"holy*"}, ViolationType: "faith_conflict"}
+	forbiddances := WorldForbiddances{WorldID: "ash-realm", Rules: []ForbiddanceRule{rule}}
+
+	if _, ok := forbiddances.Match(oracleEvent("npc.cast", "ash-realm", map[string]interface{}{
+		"skill": map[string]interface{}{"id": "light", "tags": []interface{}{"support", "holy_light"}},
+	})); !ok {
+		t.Error("expected a skill tagged holy_light to match")
+	}
+	if _, ok := forbiddances.Match(oracleEvent("npc.cast", "ash-realm", map[string]interface{}{
+		"skill": map[string]interface{}{"id": "shadow", "tags": []interface{}{"dark"}},
+	})); ok {
+		t.Error("expected a skill without holy tags not to match")
+	}
+}
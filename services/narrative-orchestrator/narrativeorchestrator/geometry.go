@@ -0,0 +1,94 @@
+// services/narrativeorchestrator/geometry.go
+
+package narrativeorchestrator
+
+import (
+	"context"
+	"time"
+
+	"multiverse-core.io/shared/spatial"
+)
+
+// DefaultGeometryRefreshInterval — как часто обновляются геометрии скоупов активных ГМ.
+// Меньше spatial.DefaultGeometryTTL, чтобы геометрии активных ГМ не устаревали в кэше провайдера.
+const DefaultGeometryRefreshInterval = 30 * time.Second
+
+// geometryRefresher — провайдер, обновляющий геометрии сущностей мира пакетом мимо кэша.
+type geometryRefresher interface {
+	Refresh(ctx context.Context, worldID string, entityIDs []string) (map[string]*spatial.Geometry, error)
+}
+
+// SetGeometryCacheTTL задаёт время жизни геометрий в кэше провайдера (0 — spatial.DefaultGeometryTTL).
+func (no *NarrativeOrchestrator) SetGeometryCacheTTL(ttl time.Duration) {
+	if provider, ok := no.geoProvider.(*spatial.SemanticMemoryProvider); ok && ttl > 0 {
+		provider.TTL = ttl
+	}
+}
+
+// RunGeometryRefresh периодически обновляет геометрии скоупов активных ГМ, пока ctx не отменён.
+func (no *NarrativeOrchestrator) RunGeometryRefresh(ctx context.Context, interval time.Duration) {
+	if _, ok := no.geoProvider.(geometryRefresher); !ok {
+		return
+	}
+	if interval <= 0 {
+		interval = DefaultGeometryRefreshInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			no.RefreshGeometries(ctx)
+		}
+	}
+}
+
+// RefreshGeometries запрашивает геометрии скоупов всех ГМ одним пакетом на мир
+// и пересчитывает их области видимости. Возвращает число обновлённых ГМ.
+func (no *NarrativeOrchestrator) RefreshGeometries(ctx context.Context) int {
+	refresher, ok := no.geoProvider.(geometryRefresher)
+	if !ok {
+		return 0
+	}
+
+	no.mu.RLock()
+	byWorld := make(map[string][]*GMInstance)
+	for _, gm := range no.gms {
+		byWorld[gm.WorldID] = append(byWorld[gm.WorldID], gm)
+	}
+	no.mu.RUnlock()
+
+	refreshed := 0
+	for worldID, gms := range byWorld {
+		scopeIDs := make([]string, len(gms))
+		for i, gm := range gms {
+			scopeIDs[i] = gm.ScopeID
+		}
+		geometries, err := refresher.Refresh(ctx, worldID, scopeIDs)
+		if err != nil {
+			warnLog("", worldID, "Failed to refresh GM geometries", map[string]interface{}{
+				"scopes": len(scopeIDs),
+				"error":  err.Error(),
+			})
+			continue
+		}
+
+		// Области видимости пересчитываются из только что обновлённого кэша, без HTTP-запросов
+		for _, gm := range gms {
+			no.mu.RLock()
+			active := no.gms[gm.ScopeID] == gm
+			no.mu.RUnlock()
+			if !active || geometries[gm.ScopeID] == nil {
+				continue // ГМ удалён во время обновления или скоуп без геометрии
+			}
+			gm.mu.Lock()
+			gm.UpdateVisibilityScope(no.geoProvider)
+			no.indexScope(gm)
+			gm.mu.Unlock()
+			refreshed++
+		}
+	}
+	return refreshed
+}
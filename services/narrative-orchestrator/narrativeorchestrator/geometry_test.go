@@ -0,0 +1,67 @@
+package narrativeorchestrator
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"multiverse-core.io/shared/spatial"
+)
+
+// refreshingProvider — StaticProvider, запоминающий пакетные обновления по мирам
+type refreshingProvider struct {
+	spatial.StaticProvider
+	refreshed map[string][]string
+}
+
+func (p *refreshingProvider) Refresh(ctx context.Context, worldID string, entityIDs []string) (map[string]*spatial.Geometry, error) {
+	p.refreshed[worldID] = append([]string(nil), entityIDs...)
+	sort.Strings(p.refreshed[worldID])
+	return p.GetGeometries(ctx, worldID, entityIDs)
+}
+
+func TestRefreshGeometries(t *testing.T) {
+	provider := &refreshingProvider{
+		StaticProvider: spatial.StaticProvider{
+			"player:kain": {Point: &spatial.Point{X: 0, Y: 0}},
+			"player:lira": {Point: &spatial.Point{X: 1000, Y: 0}},
+		},
+		refreshed: make(map[string][]string),
+	}
+	no := &NarrativeOrchestrator{
+		gms:         make(map[string]*GMInstance),
+		scopes:      spatial.NewWorldIndex(spatial.DefaultCellSize),
+		geoProvider: provider,
+	}
+	for _, gm := range []*GMInstance{
+		{ScopeID: "player:kain", ScopeType: ScopeTypePlayer, WorldID: "pain-realm", Config: map[string]interface{}{}},
+		{ScopeID: "player:lira", ScopeType: ScopeTypePlayer, WorldID: "pain-realm", Config: map[string]interface{}{}},
+		{ScopeID: "player:ghost", ScopeType: ScopeTypePlayer, WorldID: "other-realm", Config: map[string]interface{}{}},
+	} {
+		no.gms[gm.ScopeID] = gm
+	}
+
+	if n := no.RefreshGeometries(context.Background()); n != 2 {
+		t.Fatalf("expected 2 GMs with geometry to be refreshed, got %d", n)
+	}
+	if got := provider.refreshed["pain-realm"]; len(got) != 2 || got[0] != "player:kain" || got[1] != "player:lira" {
+		t.Errorf("expected one batch of both pain-realm scopes, got %v", got)
+	}
+	if got := provider.refreshed["other-realm"]; len(got) != 1 {
+		t.Errorf("expected a batch for other-realm, got %v", got)
+	}
+
+	lira := no.gms["player:lira"]
+	if lira.VisibilityScope.IsEmpty() || lira.VisibilityScope.Center.X != 1000 {
+		t.Errorf("expected lira's scope around her geometry, got %+v", lira.VisibilityScope)
+	}
+	if !no.gms["player:ghost"].VisibilityScope.IsEmpty() {
+		t.Error("GM without geometry must keep an empty scope")
+	}
+
+	// Без пакетного обновления в провайдере обновление не выполняется
+	no.geoProvider = provider.StaticProvider
+	if n := no.RefreshGeometries(context.Background()); n != 0 {
+		t.Errorf("expected no refresh without a refreshing provider, got %d", n)
+	}
+}
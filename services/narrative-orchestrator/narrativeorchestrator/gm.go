@@ -7,16 +7,73 @@ import (
 	"sync"
 	"time"
 
+	"multiverse-core.io/shared/eventbus"
 	"multiverse-core.io/shared/spatial"
 )
 
+// maxHistoryDescription — предел длины описания события в истории ГМ (в символах)
+const maxHistoryDescription = 200
+
 type HistoryEntry struct {
 	EventID     string    `json:"event_id"`
 	EventType   string    `json:"event_type"`
 	Description string    `json:"description,omitempty"`
+	Entities    []string  `json:"entities,omitempty"` // ID сущностей, участвующих в событии
 	Timestamp   time.Time `json:"timestamp"`
 }
 
+// newHistoryEntry сохраняет смысл события для истории: тип, краткое описание и участников.
+func newHistoryEntry(ev eventbus.Event) HistoryEntry {
+	description := []rune(formatEventDescription(ev))
+	if len(description) > maxHistoryDescription {
+		description = append(description[:maxHistoryDescription-1], '…')
+	}
+
+	var entities []string
+	seen := make(map[string]bool)
+	for _, id := range extractEntityIDs(ev.Payload) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			entities = append(entities, id)
+		}
+	}
+
+	return HistoryEntry{
+		EventID:     ev.ID,
+		EventType:   ev.Type,
+		Description: string(description),
+		Entities:    entities,
+		Timestamp:   ev.Timestamp,
+	}
+}
+
+// Event восстанавливает событие из записи истории, когда полные события недоступны
+// (SemanticMemory не ответила): кластеры промта сохраняют тип, описание, участников и время.
+func (h HistoryEntry) Event(worldID string) eventbus.Event {
+	eventType := h.EventType
+	if eventType == "" {
+		// Записи снапшотов, сделанных до появления типа в истории
+		eventType = "history.fallback"
+	}
+	payload := map[string]interface{}{
+		"description": h.Description,
+	}
+	if len(h.Entities) > 0 {
+		mentions := make([]interface{}, len(h.Entities))
+		for i, id := range h.Entities {
+			mentions[i] = id
+		}
+		payload["mentions"] = mentions
+	}
+	ev := eventbus.NewEvent(eventType, "narrative-orchestrator", worldID, payload)
+	// Сохраняем ссылку на исходное событие и его время
+	ev.ID = h.EventID
+	if !h.Timestamp.IsZero() {
+		ev.Timestamp = h.Timestamp
+	}
+	return ev
+}
+
 type GMInstance struct {
 	ScopeID         string                 `json:"scope_id"`
 	ScopeType       string                 `json:"scope_type"`
@@ -29,6 +86,11 @@ type GMInstance struct {
 	LastProcessTime int64                  `json:"last_process_time"`
 	CreatedAt       time.Time              `json:"created_at"`
 
+	// PendingChoice — выбор, ожидающий ответа игроков (не больше одного на ГМ)
+	PendingChoice *PendingChoice `json:"pending_choice,omitempty"`
+	// ResolvedChoices — итоги выборов, ещё не переданные в промт Oracle
+	ResolvedChoices []ResolvedChoice `json:"resolved_choices,omitempty"`
+
 	// EmittedEventIDs — event_id которые этот ГМ сам опубликовал, с временем публикации.
 	// Используется для предотвращения каскадной реакции на свои же события.
 	// Записи старше LastProcessTime - 1min автоматически вычищаются.
@@ -0,0 +1,115 @@
+// services/narrativeorchestrator/http.go
+
+package narrativeorchestrator
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"multiverse-core.io/shared/logging"
+	"multiverse-core.io/shared/minio"
+)
+
+// routes собирает HTTP API NarrativeOrchestrator: просмотр и правка канона.
+func (s *Service) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.HandleFunc("GET /v1/worlds/{world_id}/canon", s.handleGetCanon)
+	mux.HandleFunc("POST /v1/worlds/{world_id}/canon", s.handleAddCanon)
+	mux.HandleFunc("PUT /v1/worlds/{world_id}/canon/{entry_id}", s.handleUpdateCanon)
+	mux.HandleFunc("DELETE /v1/worlds/{world_id}/canon/{entry_id}", s.handleDeleteCanon)
+	return mux
+}
+
+// CanonRequest — факт канона для POST и PUT; пустой scope_id — канон мира.
+type CanonRequest struct {
+	ScopeID  string   `json:"scope_id,omitempty"`
+	Fact     string   `json:"fact"`
+	Entities []string `json:"entities,omitempty"`
+}
+
+// handleGetCanon обрабатывает GET /v1/worlds/{world_id}/canon?scope_id=: реестр мира или скоупа.
+func (s *Service) handleGetCanon(w http.ResponseWriter, r *http.Request) {
+	ledger, err := s.orchestrator.canon.Ledger(r.PathValue("world_id"), r.URL.Query().Get("scope_id"))
+	if err != nil {
+		writeCanonError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, ledger)
+}
+
+// handleAddCanon обрабатывает POST /v1/worlds/{world_id}/canon.
+func (s *Service) handleAddCanon(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeCanonRequest(w, r)
+	if !ok {
+		return
+	}
+	entry, err := s.orchestrator.canon.Add(r.PathValue("world_id"), req.ScopeID, CanonEntry{Fact: req.Fact, Entities: req.Entities})
+	if err != nil {
+		writeCanonError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, entry)
+}
+
+// handleUpdateCanon обрабатывает PUT /v1/worlds/{world_id}/canon/{entry_id}.
+func (s *Service) handleUpdateCanon(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeCanonRequest(w, r)
+	if !ok {
+		return
+	}
+	entry, err := s.orchestrator.canon.Update(r.PathValue("world_id"), req.ScopeID, r.PathValue("entry_id"), req.Fact, req.Entities)
+	if err != nil {
+		writeCanonError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, entry)
+}
+
+// handleDeleteCanon обрабатывает DELETE /v1/worlds/{world_id}/canon/{entry_id}?scope_id=.
+func (s *Service) handleDeleteCanon(w http.ResponseWriter, r *http.Request) {
+	err := s.orchestrator.canon.Delete(r.PathValue("world_id"), r.URL.Query().Get("scope_id"), r.PathValue("entry_id"))
+	if err != nil {
+		writeCanonError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func decodeCanonRequest(w http.ResponseWriter, r *http.Request) (CanonRequest, bool) {
+	var req CanonRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return req, false
+	}
+	req.Fact = strings.TrimSpace(req.Fact)
+	if req.Fact == "" {
+		http.Error(w, "fact is required", http.StatusBadRequest)
+		return req, false
+	}
+	return req, true
+}
+
+func writeCanonError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrCanonEntryNotFound):
+		http.Error(w, "Canon entry not found", http.StatusNotFound)
+	case minio.IsUnavailable(err):
+		http.Error(w, "Canon storage unavailable", http.StatusServiceUnavailable)
+	default:
+		logging.Errorf("Canon request failed: %v", err)
+		http.Error(w, "Canon request failed", http.StatusInternalServerError)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logging.Errorf("Failed to encode response: %v", err)
+	}
+}
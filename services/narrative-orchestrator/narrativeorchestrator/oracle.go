@@ -7,10 +7,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
 	"multiverse-core.io/shared/oracle"
-	"net/http"
+	"multiverse-core.io/shared/worldtime"
 	"strings"
 	"time"
 )
@@ -19,6 +19,8 @@ type OracleResponse struct {
 	Narrative string                   `json:"narrative"`
 	Mood      []string                 `json:"mood,omitempty"`
 	NewEvents []map[string]interface{} `json:"new_events"`
+	// Choice — необязательная точка выбора для игроков
+	Choice *OracleChoice `json:"choice,omitempty"`
 }
 
 // PromptInput — данные для генерации промта.
@@ -30,6 +32,7 @@ type PromptInput struct {
 	EventClusters   []EventCluster
 	TimeContext     string
 	TriggerEvent    string
+	Forbidden       []string // Запреты мира (раздел «ЗАПРЕЩЕНО В ЭТОМ МИРЕ»)
 }
 
 // BuildPrompt генерирует промт для Oracle.
@@ -46,7 +49,7 @@ ID области: ` + input.ScopeID + `
 Сущности в области:
 ` + input.EntitiesContext + `
 
-### ТРЕБОВАНИЯ К ФОРМАТУ ОТВЕТА
+` + buildForbiddenSection(input.Forbidden) + `### ТРЕБОВАНИЯ К ФОРМАТУ ОТВЕТА
 
 Отвечай СТРОГО валидным JSON без дополнительного текста, пояснений или блоков кода.
 
@@ -113,8 +116,8 @@ ID области: ` + input.ScopeID + `
 ### СОЗДАНИЕ СОБЫТИЙ
 Генерируй события ТОЛЬКО в формате, описанном выше. Не добавляй полей вне спецификации.
 `)
-	log.Println("system:", system)
-	log.Println("user:", user)
+	logging.Debugf("system: %s", system)
+	logging.Debugf("user: %s", user)
 	return system, user
 }
 
@@ -128,7 +131,7 @@ func CallOracle(ctx context.Context, systemPrompt, userPrompt string) (*OracleRe
 		return nil, fmt.Errorf("empty content")
 	}
 
-	log.Printf("Oracle response: %s", content)
+	logging.Infof("Oracle response: %s", content)
 	var result OracleResponse
 	if err := json.Unmarshal([]byte(content), &result); err != nil {
 		return nil, fmt.Errorf("invalid JSON: %s", content)
@@ -173,7 +176,7 @@ func CallOracleStructured(ctx context.Context, sections PromptSections) (*Oracle
 		return nil, fmt.Errorf("empty content from oracle")
 	}
 
-	log.Printf("Oracle structured response: %s", content)
+	logging.Infof("Oracle structured response: %s", content)
 
 	// Fallback: очистить markdown code block если есть
 	cleaned := cleanJSONResponse(content)
@@ -194,7 +197,7 @@ func CallOracleStructured(ctx context.Context, sections PromptSections) (*Oracle
 		maxEvents = 3
 	}
 	if len(result.NewEvents) > maxEvents {
-		log.Printf("[WARN] Oracle returned %d events, trimming to %d", len(result.NewEvents), maxEvents)
+		logging.Warnf("Oracle returned %d events, trimming to %d", len(result.NewEvents), maxEvents)
 		result.NewEvents = result.NewEvents[:maxEvents]
 	}
 
@@ -238,12 +241,23 @@ func buildEventClusters(clusters []EventCluster) string {
 	return strings.Join(lines, "\n")
 }
 
-func BuildTimeContext(lastEventTime *time.Time, lastMood []string) string {
+// BuildTimeContext описывает время для промта: мировую дату из тиков Chronos,
+// а для миров без мирового времени (date == nil) — реальное время.
+func BuildTimeContext(date *worldtime.Date, lastEventTime *time.Time, lastMood []string) string {
 	var lines []string
 	now := time.Now()
-	lines = append(lines, "Абсолютное время: "+now.Format("15:04, 02.01.2006"))
-	lines = append(lines, "- Сутки: "+getDayPhase(now))
-	lines = append(lines, "- Сезон: "+getSeason(now))
+	if date != nil {
+		lines = append(lines, "Мировое время: "+date.String())
+		lines = append(lines, "- Сутки: "+date.DayPhase)
+		lines = append(lines, "- Сезон: "+date.Season)
+		if date.Festival != "" {
+			lines = append(lines, "- Праздник: "+date.Festival)
+		}
+	} else {
+		lines = append(lines, "Абсолютное время: "+now.Format("15:04, 02.01.2006"))
+		lines = append(lines, "- Сутки: "+getDayPhase(now))
+		lines = append(lines, "- Сезон: "+getSeason(now))
+	}
 
 	if lastEventTime != nil {
 		ago := now.Sub(*lastEventTime)
@@ -338,8 +352,10 @@ func (c *SemanticMemoryClient) GetEventsForEntities(entityIDs []string, worldID
 		return nil, fmt.Errorf("failed to marshal events request: %w", err)
 	}
 
-	resp, err := http.Post(c.BaseURL+"/v1/events-by-entities", "application/json", bytes.NewBuffer(reqBody))
+	baseURL := c.baseURL(context.Background())
+	resp, err := semanticHTTPClient.Post(baseURL+"/v1/events-by-entities", "application/json", bytes.NewBuffer(reqBody))
 	if err != nil {
+		c.markFailed(baseURL)
 		errorLog("", "", "Failed to call semantic memory service", map[string]interface{}{
 			"error": err.Error(),
 		})
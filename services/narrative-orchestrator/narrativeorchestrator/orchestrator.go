@@ -5,47 +5,52 @@ package narrativeorchestrator
 import (
 	"bytes"
 	"context"
-	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
-	"path"
 	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"multiverse-core.io/shared/config"
+	"multiverse-core.io/shared/entity"
 	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/eventbus/events"
+	"multiverse-core.io/shared/logging"
 	"multiverse-core.io/shared/minio"
+	"multiverse-core.io/shared/oracle"
+	"multiverse-core.io/shared/registry"
 	"multiverse-core.io/shared/spatial"
+	"multiverse-core.io/shared/tracing"
 )
 
 // Log levels
 const (
-	DEBUG = "DEBUG"
-	INFO  = "INFO"
-	WARN  = "WARN"
-	ERROR = "ERROR"
+	DEBUG = slog.LevelDebug
+	INFO  = slog.LevelInfo
+	WARN  = slog.LevelWarn
+	ERROR = slog.LevelError
 )
 
-// Structured logging function
-func structuredLog(level, scopeID, worldID, msg string, fields map[string]interface{}) {
-	fieldStr := ""
-	for k, v := range fields {
-		fieldStr += fmt.Sprintf(" %s=%v", k, v)
+// structuredLog пишет запись в логгер по умолчанию (см. shared/logging) с полями world_id и scope_id;
+// поля fields добавляются в порядке ключей.
+func structuredLog(level slog.Level, scopeID, worldID, msg string, fields map[string]interface{}) {
+	logger := logging.With(slog.Default(), worldID, scopeID, "")
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
 	}
-
-	if scopeID != "" {
-		fieldStr = fmt.Sprintf(" scope_id=%s%s", scopeID, fieldStr)
-	}
-	if worldID != "" {
-		fieldStr = fmt.Sprintf(" world_id=%s%s", worldID, fieldStr)
+	sort.Strings(keys)
+	attrs := make([]any, 0, 2*len(keys))
+	for _, k := range keys {
+		attrs = append(attrs, k, fields[k])
 	}
-
-	log.Printf("[%s] %s%s", level, msg, fieldStr)
+	logger.Log(context.Background(), level, msg, attrs...)
 }
 
 // Helper functions for different log levels
@@ -65,9 +70,32 @@ func errorLog(scopeID, worldID, msg string, fields map[string]interface{}) {
 	structuredLog(ERROR, scopeID, worldID, msg, fields)
 }
 
+// semanticHTTPClient передаёт SemanticMemory трассу вызова (заголовок traceparent).
+var semanticHTTPClient = &http.Client{Transport: tracing.NewTransport("semantic-memory", nil)}
+
 type SemanticMemoryClient struct {
-	BaseURL string
-	logger  *log.Logger
+	BaseURL   string // резервный адрес, если в реестре нет живого экземпляра
+	logger    *log.Logger
+	discovery *registry.Discovery
+}
+
+// baseURL возвращает адрес живого экземпляра SemanticMemory.
+func (c *SemanticMemoryClient) baseURL(ctx context.Context) string {
+	if c.discovery == nil {
+		return c.BaseURL
+	}
+	url, err := c.discovery.Resolve(ctx, registry.ServiceSemanticMemory)
+	if err != nil {
+		return c.BaseURL
+	}
+	return url
+}
+
+// markFailed сообщает реестру о недоступном экземпляре для переключения на другой.
+func (c *SemanticMemoryClient) markFailed(url string) {
+	if c.discovery != nil {
+		c.discovery.MarkFailed(registry.ServiceSemanticMemory, url)
+	}
 }
 
 type GetContextResponse struct {
@@ -113,8 +141,15 @@ func (c *SemanticMemoryClient) GetContextWithEvents(ctx context.Context, entityI
 		return nil, fmt.Errorf("failed to marshal context request: %w", err)
 	}
 
-	resp, err := http.Post(c.BaseURL+"/v1/context-with-events", "application/json", bytes.NewBuffer(reqBody))
+	baseURL := c.baseURL(ctx)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/v1/context-with-events", bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create context request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := semanticHTTPClient.Do(req)
 	if err != nil {
+		c.markFailed(baseURL)
 		errorLog("", "", "Failed to call semantic memory service", map[string]interface{}{
 			"error": err.Error(),
 		})
@@ -147,22 +182,27 @@ type NarrativeOrchestrator struct {
 	mu          sync.RWMutex
 	bus         *eventbus.EventBus
 	semantic    *SemanticMemoryClient
-	minioClient minio.ClientInterface
+	minioClient minio.ObjectStorage // nil — снапшоты не сохраняются
 	configStore *config.Store
 	geoProvider spatial.GeometryProvider
+	scopes      *spatial.WorldIndex // области видимости ГМ по мирам для пространственной маршрутизации
+	discovery   *registry.Discovery
+	clocks      *worldClocks // мировое время миров по тикам Chronos
+	canon       *CanonStore  // канон миров и скоупов (см. canon.go)
 	logger      *log.Logger
-}
 
-func NewNarrativeOrchestrator(bus *eventbus.EventBus) *NarrativeOrchestrator {
-	logger := log.New(log.Writer(), "NarrativeOrchestrator: ", log.LstdFlags|log.Lshortfile)
+	// forbiddances — запреты миров из профилей онтологии (см. forbiddances.go)
+	forbiddances *ForbiddanceStore
 
-	debugLog("", "", "Initializing Narrative Orchestrator", map[string]interface{}{})
+	// coordinators — координаторы локаций по скоупу арбитра (см. coordination.go)
+	coordMu      sync.Mutex
+	coordinators map[string]*LocationCoordinator
 
-	semanticURL := os.Getenv("SEMANTIC_MEMORY_URL")
-	if semanticURL == "" {
-		semanticURL = "http://semantic-memory:8080"
-	}
+	// snapshotRetention — сколько последних снапшотов хранится для каждого скоупа
+	snapshotRetention int
+}
 
+func NewNarrativeOrchestrator(bus *eventbus.EventBus) *NarrativeOrchestrator {
 	minioCfg := minio.Config{
 		Endpoint:        os.Getenv("MINIO_ENDPOINT"),
 		AccessKeyID:     os.Getenv("MINIO_ACCESS_KEY"),
@@ -171,31 +211,60 @@ func NewNarrativeOrchestrator(bus *eventbus.EventBus) *NarrativeOrchestrator {
 		Region:          "us-east-1",
 	}
 
-	minioClient, err := minio.NewMinIOOfficialClient(minioCfg)
-	if err != nil {
+	// Без хранилища orchestrator работает без снапшотов и профилей из MinIO
+	var storage minio.ObjectStorage
+	if minioClient, err := minio.NewMinIOOfficialClient(minioCfg); err != nil {
 		errorLog("", "", "Failed to initialize MinIO client", map[string]interface{}{
 			"error": err.Error(),
 		})
-		// Continue with nil client to maintain backward compatibility
-		minioClient = nil
+	} else {
+		storage = minioClient
 	}
 
+	return NewNarrativeOrchestratorWithStorage(bus, storage)
+}
+
+// NewNarrativeOrchestratorWithStorage создаёт orchestrator поверх заданного хранилища
+// снапшотов и конфигов; storage может быть nil.
+func NewNarrativeOrchestratorWithStorage(bus *eventbus.EventBus, storage minio.ObjectStorage) *NarrativeOrchestrator {
+	logger := logging.StdLogger("NarrativeOrchestrator")
+
+	debugLog("", "", "Initializing Narrative Orchestrator", map[string]interface{}{})
+
+	semanticURL := os.Getenv("SEMANTIC_MEMORY_URL")
+	if semanticURL == "" {
+		semanticURL = "http://semantic-memory:8080"
+	}
+
+	discovery := registry.NewDiscovery(bus, "narrative-orchestrator")
+	discovery.SetFallback(registry.ServiceSemanticMemory, semanticURL)
+
 	geoProvider := spatial.NewSemanticMemoryProvider(semanticURL)
-	configStore := config.NewStore(minioClient, "gnue-configs")
+	configStore := config.NewStore(storage, "gnue-configs")
+	configStore.UseEventBus(bus)
 
 	infoLog("", "", "Successfully initialized Narrative Orchestrator", map[string]interface{}{
 		"semantic_url": semanticURL,
-		"minio_ready":  minioClient != nil,
+		"minio_ready":  storage != nil,
 	})
 
 	return &NarrativeOrchestrator{
 		gms:         make(map[string]*GMInstance),
 		bus:         bus,
-		semantic:    &SemanticMemoryClient{BaseURL: semanticURL, logger: logger},
-		minioClient: minioClient,
+		semantic:    &SemanticMemoryClient{BaseURL: semanticURL, logger: logger, discovery: discovery},
+		minioClient: storage,
 		configStore: configStore,
 		geoProvider: geoProvider,
+		scopes:      spatial.NewWorldIndex(spatial.DefaultCellSize),
+		discovery:   discovery,
+		clocks:      newWorldClocks(),
+		canon:       NewCanonStore(storage),
 		logger:      logger,
+
+		coordinators: make(map[string]*LocationCoordinator),
+		forbiddances: NewForbiddanceStore(nil),
+
+		snapshotRetention: DefaultSnapshotRetention,
 	}
 }
 
@@ -273,6 +342,14 @@ func (no *NarrativeOrchestrator) CreateGM(ev eventbus.Event) {
 
 	profile, err := no.configStore.GetProfile(scopeType)
 	if err != nil {
+		// MinIO недоступен — не создаём GM с профилем по умолчанию, повторим на следующем событии
+		if minio.IsUnavailable(err) {
+			errorLog(scopeID, worldID, "Config storage unavailable, GM creation postponed", map[string]interface{}{
+				"scope_type": scopeType,
+				"error":      err.Error(),
+			})
+			return
+		}
 		warnLog(scopeID, worldID, "Failed to get profile for scope type", map[string]interface{}{
 			"scope_type": scopeType,
 			"error":      err.Error(),
@@ -288,6 +365,13 @@ func (no *NarrativeOrchestrator) CreateGM(ev eventbus.Event) {
 
 	override, err := no.configStore.GetOverride(scopeID)
 	if err != nil {
+		if minio.IsUnavailable(err) {
+			errorLog(scopeID, worldID, "Config storage unavailable, GM creation postponed", map[string]interface{}{
+				"scope_id": scopeID,
+				"error":    err.Error(),
+			})
+			return
+		}
 		warnLog(scopeID, worldID, "Failed to get override for scope", map[string]interface{}{
 			"scope_id": scopeID,
 			"error":    err.Error(),
@@ -324,9 +408,7 @@ func (no *NarrativeOrchestrator) CreateGM(ev eventbus.Event) {
 			"error": err.Error(),
 		})
 	}
-	if geometry == nil {
-		geometry = &spatial.Geometry{Point: &spatial.Point{X: 0, Y: 0}}
-	}
+	// Без геометрии область пуста (мир — бесконечен): ГМ не получает события по координатам
 	gm.VisibilityScope = spatial.DefaultScope(scopeType, geometry, gm.Config)
 	gm.UpdateVisibilityScope(no.geoProvider)
 
@@ -343,7 +425,7 @@ func (no *NarrativeOrchestrator) CreateGM(ev eventbus.Event) {
 		warnLog(scopeID, worldID, "Failed to load GM snapshot", map[string]interface{}{
 			"error": err.Error(),
 		})
-		if strings.Contains(err.Error(), "no snapshots") {
+		if errors.Is(err, errNoSnapshots) {
 			err := no.saveSnapshot(scopeID, gm)
 			if err != nil {
 				warnLog(scopeID, worldID, "Failed to save GM snapshot", map[string]interface{}{
@@ -377,6 +459,7 @@ func (no *NarrativeOrchestrator) CreateGM(ev eventbus.Event) {
 	no.mu.Lock()
 	no.gms[scopeID] = gm
 	no.mu.Unlock()
+	no.indexScope(gm)
 
 	infoLog(scopeID, worldID, "GM created successfully", map[string]interface{}{
 		"focus_entities_count": len(gm.FocusEntities),
@@ -394,6 +477,7 @@ func (no *NarrativeOrchestrator) DeleteGMByScope(scopeID string) {
 		delete(no.gms, scopeID)
 	}
 	no.mu.Unlock()
+	no.scopes.Remove(scopeID)
 
 	if exists {
 		infoLog(scopeID, "", "GM deleted successfully", map[string]interface{}{})
@@ -475,6 +559,7 @@ func (no *NarrativeOrchestrator) MergeGM(ev eventbus.Event) {
 
 		srcGM.stopTTL()
 		delete(no.gms, srcID)
+		no.scopes.Remove(srcID)
 
 		infoLog(targetScopeID, worldID, "Merged source GM into target", map[string]interface{}{
 			"source_scope_id": srcID,
@@ -482,9 +567,23 @@ func (no *NarrativeOrchestrator) MergeGM(ev eventbus.Event) {
 	}
 	no.mu.Unlock()
 
+	// Канон скоупов-источников переходит к целевому ГМ
+	sourceScopeIDs := make([]string, 0, len(sourceScopeIDsRaw))
+	for _, raw := range sourceScopeIDsRaw {
+		if srcID, ok := raw.(string); ok && srcID != "" && srcID != targetScopeID {
+			sourceScopeIDs = append(sourceScopeIDs, srcID)
+		}
+	}
+	if err := no.canon.Merge(worldID, targetScopeID, sourceScopeIDs); err != nil {
+		warnLog(targetScopeID, worldID, "Failed to merge canon of source scopes", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
 	// Update visibility after merge (HTTP call outside lock)
 	targetGM.mu.Lock()
 	targetGM.UpdateVisibilityScope(no.geoProvider)
+	no.indexScope(targetGM)
 	targetGM.mu.Unlock()
 
 	infoLog(targetScopeID, worldID, "GM merge completed", map[string]interface{}{
@@ -530,7 +629,7 @@ func (no *NarrativeOrchestrator) SplitGM(ev eventbus.Event) {
 			"narrative-orchestrator",
 			worldID,
 			fmt.Sprintf("Split GM %s -> %s", sourceScopeID, newScopeID),
-		)
+		).CausedBy(ev)
 		// Add scope and focus_entities to payload
 		eventbus.SetNested(createEv.Payload, "scope.id", newScopeID)
 		eventbus.SetNested(createEv.Payload, "scope.type", scopeDef["scope_type"])
@@ -576,37 +675,37 @@ func (no *NarrativeOrchestrator) HandleTimerEvent(ev eventbus.Event) {
 		"event_id": ev.ID,
 	})
 
-	currentTimeMsRaw, ok := ev.Payload["current_time_unix_ms"]
-	if !ok {
-		warnLog("", worldID, "Timer event missing current_time_unix_ms", map[string]interface{}{
+	var tick events.TimeSync
+	if err := events.Unmarshal(ev, &tick); err != nil {
+		warnLog("", worldID, "Timer event has invalid payload", map[string]interface{}{
 			"event_id": ev.ID,
+			"error":    err.Error(),
 		})
 		return
 	}
-	
-	var currentTimeMs int64
-	switch v := currentTimeMsRaw.(type) {
-	case float64:
-		currentTimeMs = int64(v)
-	case int:
-		currentTimeMs = int64(v)
-	case int64:
-		currentTimeMs = v
-	default:
-		warnLog("", worldID, "Timer event has invalid current_time_unix_ms type", map[string]interface{}{
+	if tick.CurrentTimeUnixMs == 0 {
+		warnLog("", worldID, "Timer event missing current_time_unix_ms", map[string]interface{}{
 			"event_id": ev.ID,
-			"type":     fmt.Sprintf("%T", currentTimeMsRaw),
 		})
 		return
 	}
+	currentTimeMs := tick.CurrentTimeUnixMs
+	no.clocks.record(worldID, tick)
 
+	// Тик мира обрабатывает ГМ этого мира и ГМ миров, для которых тиков нет
 	no.mu.RLock()
 	gms := make([]*GMInstance, 0, len(no.gms))
 	for _, gm := range no.gms {
-		gms = append(gms, gm)
+		if worldID == "" || gm.WorldID == worldID || !no.clocks.has(gm.WorldID) {
+			gms = append(gms, gm)
+		}
 	}
 	no.mu.RUnlock()
 
+	// Просроченные выборы разрешаются вариантом по умолчанию
+	no.resolveExpiredChoices(gms, tick.Time())
+	no.sweepCoordinators(time.Now())
+
 	infoLog("", worldID, "Processing timer event for GMs", map[string]interface{}{
 		"gms_count":       len(gms),
 		"current_time_ms": currentTimeMs,
@@ -663,46 +762,27 @@ func (no *NarrativeOrchestrator) processEntityUpdate(gm *GMInstance, changes []m
 			continue
 		}
 
-		prefix := "entity_" + strings.Replace(entityID, ":", "_", -1) + "_"
-
-		if opsRaw, ok := change["operations"].([]interface{}); ok {
-			for _, opRaw := range opsRaw {
-				if op, ok := opRaw.(map[string]interface{}); ok {
-					opType, _ := op["op"].(string)
-					opPath, _ := op["path"].(string)
-
-					switch opType {
-					case "set":
-						if value, exists := op["value"]; exists {
-							gm.Config[prefix+opPath] = value
-						}
-					case "add_to_slice":
-						if value, exists := op["value"].(string); exists {
-							key := prefix + opPath
-							slice, ok := gm.Config[key].([]interface{})
-							if !ok {
-								slice = []interface{}{}
-							}
-							gm.Config[key] = append(slice, value)
-						}
-					case "remove_from_slice":
-						if value, exists := op["value"].(string); exists {
-							key := prefix + opPath
-							if slice, ok := gm.Config[key].([]interface{}); ok {
-								for i, v := range slice {
-									if v == value {
-										gm.Config[key] = append(slice[:i], slice[i+1:]...)
-										break
-									}
-								}
-							}
-						}
-					case "remove":
-						delete(gm.Config, prefix+opPath)
-					}
-				}
-			}
+		// Entity state mirrors are kept as one document per entity and patched like in EntityManager
+		key := "entity_" + strings.Replace(entityID, ":", "_", -1)
+		operations, _ := change["operations"].([]interface{})
+		ops, err := entity.ParsePatch(operations)
+		if err != nil {
+			warnLog(gm.ScopeID, gm.WorldID, "Invalid entity operations", map[string]interface{}{
+				"entity_id": entityID,
+				"error":     err.Error(),
+			})
+			continue
 		}
+		state, _ := gm.Config[key].(map[string]interface{})
+		patched, _, err := entity.PatchDocument(state, ops)
+		if err != nil {
+			warnLog(gm.ScopeID, gm.WorldID, "Rejected entity state changes", map[string]interface{}{
+				"entity_id": entityID,
+				"error":     err.Error(),
+			})
+			continue
+		}
+		gm.Config[key] = patched
 
 		updatedEntities = append(updatedEntities, entityID)
 	}
@@ -712,6 +792,7 @@ func (no *NarrativeOrchestrator) processEntityUpdate(gm *GMInstance, changes []m
 
 	// HTTP call to update visibility — outside any lock
 	gm.UpdateVisibilityScope(no.geoProvider)
+	no.indexScope(gm)
 
 	infoLog(gm.ScopeID, gm.WorldID, "Completed entity update processing", map[string]interface{}{
 		"updated_entities":  updatedEntities,
@@ -719,104 +800,20 @@ func (no *NarrativeOrchestrator) processEntityUpdate(gm *GMInstance, changes []m
 	})
 }
 
-func (no *NarrativeOrchestrator) loadSnapshot(scopeID string) (*GMInstance, error) {
-	debugLog(scopeID, "", "Loading GM snapshot", map[string]interface{}{
-		"scope_id": scopeID,
-	})
-
-	if no.minioClient == nil {
-		warnLog(scopeID, "", "MinIO client not available, cannot load snapshot", map[string]interface{}{})
-		return nil, fmt.Errorf("minio client not available")
-	}
-
-	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(scopeID)))
-	prefix := path.Join("gnue", "gm-snapshots", "v1", hash)
-
-	objects, err := no.minioClient.ListObjects("gnue-snapshots", prefix)
-	if err != nil {
-		errorLog(scopeID, "", "Failed to list snapshot objects from MinIO", map[string]interface{}{
-			"error":  err.Error(),
-			"prefix": prefix,
-		})
-		return nil, err
-	}
-
-	if len(objects) == 0 {
-		infoLog(scopeID, "", "No snapshots found for GM", map[string]interface{}{
-			"prefix": prefix,
-		})
-		return nil, fmt.Errorf("no snapshots")
-	}
-
-	objectKey := objects[0].Key
-	data, err := no.minioClient.GetObject("gnue-snapshots", objectKey)
-	if err != nil {
-		errorLog(scopeID, "", "Failed to get snapshot object from MinIO", map[string]interface{}{
-			"error":      err.Error(),
-			"object_key": objectKey,
-		})
-		return nil, err
-	}
-
-	var gm GMInstance
-	if err := json.Unmarshal(data, &gm); err != nil {
-		errorLog(scopeID, "", "Failed to unmarshal GM snapshot", map[string]interface{}{
-			"error":      err.Error(),
-			"object_key": objectKey,
-		})
-		return nil, err
-	}
-
-	infoLog(scopeID, "", "Successfully loaded GM snapshot", map[string]interface{}{
-		"object_key":    objectKey,
-		"snapshot_size": len(data),
-	})
-
-	return &gm, nil
-}
-
-func (no *NarrativeOrchestrator) saveSnapshot(scopeID string, gm *GMInstance) error {
-	debugLog(scopeID, gm.WorldID, "Saving GM snapshot", map[string]interface{}{
-		"scope_id": scopeID,
-	})
-
-	if no.minioClient == nil {
-		warnLog(scopeID, gm.WorldID, "MinIO client not available, cannot save snapshot", map[string]interface{}{})
-		return fmt.Errorf("minio client not available")
-	}
-
-	data, err := json.Marshal(gm)
-	if err != nil {
-		errorLog(scopeID, gm.WorldID, "Failed to marshal GM for snapshot", map[string]interface{}{
-			"error": err.Error(),
-		})
-		return err
-	}
-
-	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(scopeID)))
-	timestamp := time.Now().Unix()
-	key := path.Join("gnue", "gm-snapshots", "v1", hash, fmt.Sprintf("%d_001.json", timestamp))
-
-	err = no.minioClient.PutObject("gnue-snapshots", key, bytes.NewReader(data), int64(len(data)))
-	if err != nil {
-		errorLog(scopeID, gm.WorldID, "Failed to save GM snapshot to MinIO", map[string]interface{}{
-			"error":      err.Error(),
-			"object_key": key,
-		})
-		return err
+// indexScope registers the GM's visibility scope in the spatial index of its world.
+// GMs without geometry are not indexed and receive no events by coordinates.
+func (no *NarrativeOrchestrator) indexScope(gm *GMInstance) {
+	if gm.VisibilityScope.IsEmpty() {
+		no.scopes.Remove(gm.ScopeID)
+		return
 	}
-
-	infoLog(scopeID, gm.WorldID, "Successfully saved GM snapshot", map[string]interface{}{
-		"object_key":    key,
-		"snapshot_size": len(data),
-	})
-
-	return nil
+	scope := gm.VisibilityScope
+	no.scopes.Insert(gm.WorldID, gm.ScopeID, &scope)
 }
 
 // findGMsForEvent returns all GMs that should receive this event:
 // 1. Exact scope_id match
-// 2. Spatial match — event point is within GM's VisibilityScope
+// 2. Spatial match — event point is within GM's VisibilityScope (looked up in the world's spatial index)
 func (no *NarrativeOrchestrator) findGMsForEvent(ev eventbus.Event) []*GMInstance {
 	no.mu.RLock()
 	defer no.mu.RUnlock()
@@ -833,17 +830,16 @@ func (no *NarrativeOrchestrator) findGMsForEvent(ev eventbus.Event) []*GMInstanc
 		}
 	}
 
-	// 2. Spatial routing — check if event point falls within any GM's visibility
+	// 2. Spatial routing — scopes of the event's world that contain the event point
 	eventPoint, hasPoint := no.extractEventPoint(ev)
 	if hasPoint {
-		for _, gm := range no.gms {
-			if matched[gm.ScopeID] {
+		for _, scopeID := range no.scopes.Containing(eventbus.GetWorldIDFromEvent(ev), eventPoint) {
+			gm, exists := no.gms[scopeID]
+			if !exists || matched[scopeID] {
 				continue
 			}
-			if gm.VisibilityScope.IsInScope(eventPoint) {
-				result = append(result, gm)
-				matched[gm.ScopeID] = true
-			}
+			result = append(result, gm)
+			matched[scopeID] = true
 		}
 	}
 
@@ -958,6 +954,16 @@ func (no *NarrativeOrchestrator) dispatchEventToGM(ev eventbus.Event, gm *GMInst
 		}
 	}
 
+	// Событие, согласованное координатором локации, уже добавлено в историю ГМ
+	if coordinatedFor(ev, gm.ScopeID) {
+		gm.mu.Unlock()
+		debugLog(gm.ScopeID, gm.WorldID, "Skipping event delivered by location coordinator", map[string]interface{}{
+			"event_id":   ev.ID,
+			"event_type": ev.Type,
+		})
+		return
+	}
+
 	triggers := gm.Config["triggers"]
 	gm.mu.Unlock()
 
@@ -978,20 +984,7 @@ func (no *NarrativeOrchestrator) dispatchEventToGM(ev eventbus.Event, gm *GMInst
 	// Буферизация — per-GM lock
 	gm.mu.Lock()
 
-	// Extract description from payload
-	description := ""
-	if desc, ok := ev.Payload["description"].(string); ok {
-		description = desc
-	} else if detail, ok := ev.Payload["detail"].(string); ok {
-		description = detail
-	}
-
-	gm.History = append(gm.History, HistoryEntry{
-		EventID:     ev.ID,
-		EventType:   ev.Type,
-		Description: description,
-		Timestamp:   ev.Timestamp,
-	})
+	gm.History = append(gm.History, newHistoryEntry(ev))
 
 	// Проверка переполнения
 	maxSize := 100
@@ -1126,7 +1119,7 @@ func (no *NarrativeOrchestrator) HandleMechanicalResult(ev eventbus.Event) {
 			"mood":      mood,
 			"entity_id": entityID,
 		},
-	)
+	).CausedBy(ev)
 	// Set scope in payload
 	eventbus.SetNested(narrativeEvent.Payload, "scope.id", scopeRef.ID)
 	eventbus.SetNested(narrativeEvent.Payload, "scope.type", scopeRef.Type)
@@ -1184,10 +1177,7 @@ func (no *NarrativeOrchestrator) processEventForGM(ev eventbus.Event, gm *GMInst
 
 	if ev.Type != "batch.process" && ev.Type != "time.syncTime" {
 		gm.mu.Lock()
-		gm.History = append(gm.History, HistoryEntry{
-			EventID:   ev.ID,
-			Timestamp: ev.Timestamp,
-		})
+		gm.History = append(gm.History, newHistoryEntry(ev))
 		gm.mu.Unlock()
 	}
 
@@ -1208,7 +1198,7 @@ func (no *NarrativeOrchestrator) processEventForGM(ev eventbus.Event, gm *GMInst
 	eventTypes := []string{}
 	// Include world entity ID in context to ensure world data is loaded
 	entityIDs := append([]string{gm.WorldID}, focusEntities...)
-	contexts, err := no.semantic.GetContextWithEvents(context.Background(), entityIDs, eventTypes, 2)
+	contexts, err := no.semantic.GetContextWithEvents(ev.TraceContext(context.Background()), entityIDs, eventTypes, 2)
 	if err != nil {
 		warnLog(gm.ScopeID, gm.WorldID, "Failed to get context with events, continuing without", map[string]interface{}{
 			"error": err.Error(),
@@ -1234,16 +1224,7 @@ func (no *NarrativeOrchestrator) processEventForGM(ev eventbus.Event, gm *GMInst
 			})
 			fullEvents = make([]eventbus.Event, len(historyCopy))
 			for i, he := range historyCopy {
-				fullEvents[i] = eventbus.NewEvent(
-					"history.fallback",
-					"narrative-orchestrator",
-					gm.WorldID,
-					map[string]interface{}{
-						"description": he.Description,
-					},
-				)
-				// Override ID to preserve history reference
-				fullEvents[i].ID = he.EventID
+				fullEvents[i] = he.Event(gm.WorldID)
 			}
 		}
 
@@ -1269,14 +1250,16 @@ func (no *NarrativeOrchestrator) processEventForGM(ev eventbus.Event, gm *GMInst
 	// Кластеризация событий
 	clusters := clusterEvents(fullEvents)
 
-	// Триггер — описание инициирующего события
+	// Триггер — описание инициирующего события; от него же ведут своё происхождение опубликованные события
 	var triggerEvent string
+	cause := ev
 	switch {
 	case ev.Type == "batch.process":
 		// Батч — триггером является последнее реальное событие
 		if len(fullEvents) > 0 {
 			lastEv := fullEvents[len(fullEvents)-1]
 			triggerEvent = formatEventDescription(lastEv)
+			cause = lastEv
 		} else {
 			triggerEvent = "Прошло время. Мир продолжает жить."
 		}
@@ -1284,6 +1267,7 @@ func (no *NarrativeOrchestrator) processEventForGM(ev eventbus.Event, gm *GMInst
 		if len(fullEvents) > 0 {
 			lastEv := fullEvents[len(fullEvents)-1]
 			triggerEvent = formatEventDescription(lastEv)
+			cause = lastEv
 		} else {
 			triggerEvent = "Прошло время. Мир продолжает жить."
 		}
@@ -1347,29 +1331,57 @@ func (no *NarrativeOrchestrator) processEventForGM(ev eventbus.Event, gm *GMInst
 			}
 		}
 	}
+	// Итоги выборов игроков передаются в промт и удаляются после успешного ответа Oracle
+	resolvedChoices := make([]string, len(gm.ResolvedChoices))
+	for i, choice := range gm.ResolvedChoices {
+		resolvedChoices[i] = choice.promptLine()
+	}
+	pendingChoice := ""
+	if gm.PendingChoice != nil {
+		pendingChoice = gm.PendingChoice.Prompt
+	}
 	gm.mu.Unlock()
 
-	timeContext := BuildTimeContext(lastEventTime, lastMood)
+	// Канон мира и скоупа: сначала факты о сущностях ГМ и участниках накопленных событий
+	canonEntities := append([]string(nil), focusEntities...)
+	for _, fe := range fullEvents {
+		canonEntities = append(canonEntities, extractEntityIDs(fe.Payload)...)
+	}
+	if ledgerCanon, err := no.canon.Relevant(gm.WorldID, gm.ScopeID, canonEntities, maxPromptCanon); err != nil {
+		warnLog(gm.ScopeID, gm.WorldID, "Failed to load canon, continuing without", map[string]interface{}{
+			"error": err.Error(),
+		})
+	} else {
+		canon = append(canon, ledgerCanon...)
+	}
+
+	timeContext := BuildTimeContext(no.clocks.date(gm.WorldID), lastEventTime, lastMood)
+
+	// Запреты мира: попадают в промт и отсекают нарушающие их события Oracle
+	forbiddances := no.forbiddances.For(cause.TraceContext(context.Background()), gm.WorldID)
 
 	// Формируем промт
 	sections := PromptSections{
-		WorldFacts:     worldContext,
-		EntityStates:   entitiesContext,
-		Canon:          canon,
-		ScopeID:        gm.ScopeID,
-		ScopeType:      gm.ScopeType,
-		WorldID:        gm.WorldID,
-		TimeContext:    timeContext,
-		EventClusters:  clusters,
-		TriggerEvent:   triggerEvent,
-		LastMood:       lastMood,
-		MaxEvents:      4,
-		DefaultSource:  "narrative-orchestrator",
-		DefaultWorldID: gm.WorldID,
-	}
-
-	// Вызов Oracle
-	ctx, cancel := context.WithTimeout(context.Background(), 90*time.Second)
+		WorldFacts:      worldContext,
+		EntityStates:    entitiesContext,
+		Canon:           canon,
+		Forbidden:       forbiddances.PromptLines(),
+		ScopeID:         gm.ScopeID,
+		ScopeType:       gm.ScopeType,
+		WorldID:         gm.WorldID,
+		TimeContext:     timeContext,
+		EventClusters:   clusters,
+		TriggerEvent:    triggerEvent,
+		LastMood:        lastMood,
+		ResolvedChoices: resolvedChoices,
+		PendingChoice:   pendingChoice,
+		MaxEvents:       4,
+		DefaultSource:   "narrative-orchestrator",
+		DefaultWorldID:  gm.WorldID,
+	}
+
+	// Вызов Oracle в трассе события-причины; журнал аудита помечает вызов скоупом ГМ
+	ctx, cancel := context.WithTimeout(oracle.WithAuditScope(cause.TraceContext(context.Background()), gm.ScopeID), 90*time.Second)
 	defer cancel()
 
 	infoLog(gm.ScopeID, gm.WorldID, "Calling Oracle (structured) for narrative generation", map[string]interface{}{
@@ -1378,6 +1390,13 @@ func (no *NarrativeOrchestrator) processEventForGM(ev eventbus.Event, gm *GMInst
 	})
 
 	oracleResp, err := CallOracleStructured(ctx, sections)
+	if errors.Is(err, oracle.ErrCircuitOpen) {
+		// Oracle недоступен — событие остаётся в истории ГМ без повествования
+		warnLog(gm.ScopeID, gm.WorldID, "Oracle unavailable, narrative generation skipped", map[string]interface{}{
+			"event_id": cause.ID,
+		})
+		return
+	}
 	if err != nil {
 		errorLog(gm.ScopeID, gm.WorldID, "Oracle call failed", map[string]interface{}{
 			"error": err.Error(),
@@ -1401,6 +1420,20 @@ func (no *NarrativeOrchestrator) processEventForGM(ev eventbus.Event, gm *GMInst
 		gm.mu.Unlock()
 	}
 
+	// Переданные в промт итоги выборов учтены; разрешённые во время вызова Oracle остаются до следующего
+	if len(resolvedChoices) > 0 {
+		gm.mu.Lock()
+		if len(gm.ResolvedChoices) >= len(resolvedChoices) {
+			gm.ResolvedChoices = gm.ResolvedChoices[len(resolvedChoices):]
+		}
+		gm.mu.Unlock()
+	}
+
+	if oracleResp.Choice != nil {
+		no.offerChoice(gm, oracleResp.Choice, cause)
+	}
+
+	outputEvents := make([]eventbus.Event, 0, len(oracleResp.NewEvents))
 	for i, evMap := range oracleResp.NewEvents {
 		eventType, _ := evMap["event_type"].(string)
 		payload, _ := evMap["payload"].(map[string]interface{})
@@ -1410,7 +1443,7 @@ func (no *NarrativeOrchestrator) processEventForGM(ev eventbus.Event, gm *GMInst
 			"narrative-orchestrator",
 			gm.WorldID,
 			payload,
-		)
+		).CausedBy(cause)
 
 		// Handle the scope_id from evMap which is of type interface{}
 		if scopeIDVal, ok := evMap["scope_id"]; ok && scopeIDVal != nil {
@@ -1434,6 +1467,17 @@ func (no *NarrativeOrchestrator) processEventForGM(ev eventbus.Event, gm *GMInst
 			}
 		}
 
+		outputEvents = append(outputEvents, outputEvent)
+	}
+
+	outputEvents = dropForbidden(gm, forbiddances, outputEvents)
+
+	// ГМ, чьи области пересекаются, согласуют события через координатора локации
+	outputEvents, narrative := no.coordinate(gm, outputEvents, oracleResp.Narrative, cause)
+
+	for i, outputEvent := range outputEvents {
+		eventType := outputEvent.Type
+
 		// Запоминаем event_id чтобы не реагировать на своё же событие
 		gm.mu.Lock()
 		gm.trackEmitted(outputEvent.ID)
@@ -1455,15 +1499,37 @@ func (no *NarrativeOrchestrator) processEventForGM(ev eventbus.Event, gm *GMInst
 		}
 	}
 
-	if oracleResp.Narrative != "" {
+	// Долговременные факты опубликованных событий пополняют канон скоупа и мира
+	scopeCanon, worldCanon := extractCanon(outputEvents)
+	for _, ledger := range []struct {
+		scopeID string
+		entries []CanonEntry
+	}{{gm.ScopeID, scopeCanon}, {"", worldCanon}} {
+		if added, err := no.canon.Record(gm.WorldID, ledger.scopeID, ledger.entries); err != nil {
+			errorLog(gm.ScopeID, gm.WorldID, "Failed to record canon", map[string]interface{}{
+				"error":       err.Error(),
+				"world_canon": ledger.scopeID == "",
+			})
+		} else if added > 0 {
+			infoLog(gm.ScopeID, gm.WorldID, "Canon updated", map[string]interface{}{
+				"added":       added,
+				"world_canon": ledger.scopeID == "",
+			})
+		}
+	}
+
+	if narrative != "" {
 		narrativePayload := map[string]interface{}{}
-		narrativePayload["narrative"] = oracleResp.Narrative
+		narrativePayload["narrative"] = narrative
+		// scope позволяет GameService вести журнал повествования по scope
+		eventbus.SetNested(narrativePayload, "scope.id", gm.ScopeID)
+		eventbus.SetNested(narrativePayload, "scope.type", gm.ScopeType)
 		outputEvent := eventbus.NewEvent(
 			"narrative.generate",
 			"narrative-orchestrator",
 			gm.WorldID,
 			narrativePayload,
-		)
+		).CausedBy(cause)
 
 		err := no.bus.Publish(context.Background(), eventbus.TopicNarrativeOutput, outputEvent)
 		if err != nil {
@@ -1474,7 +1540,7 @@ func (no *NarrativeOrchestrator) processEventForGM(ev eventbus.Event, gm *GMInst
 		} else {
 			infoLog(gm.ScopeID, gm.WorldID, "Published narrative event", map[string]interface{}{
 				"event_id":         outputEvent.ID,
-				"narrative_length": len(oracleResp.Narrative),
+				"narrative_length": len(narrative),
 			})
 		}
 	}
@@ -14,6 +14,7 @@ type PromptSections struct {
 	WorldFacts   string   // Канон мира, законы, история
 	EntityStates string   // Текущее состояние сущностей в области
 	Canon        []string // Неизменные факты мира (из KnowledgeBase)
+	Forbidden    []string // Запреты мира из профиля онтологии (см. forbiddances.go)
 
 	// SITUATION: что происходит сейчас (меняется каждый вызов)
 	ScopeID       string
@@ -24,6 +25,10 @@ type PromptSections struct {
 	TriggerEvent  string
 	LastMood      []string
 
+	// CHOICES: точки выбора игроков
+	ResolvedChoices []string // Итоги выборов, которые нужно учесть в продолжении
+	PendingChoice   string   // Вопрос выбора, на который игроки ещё не ответили
+
 	// CONSTRAINTS: параметры из конфига GM
 	MaxEvents      int    // default 3
 	DefaultSource  string // e.g. "narrative-orchestrator"
@@ -57,6 +62,7 @@ func BuildStructuredPrompt(s PromptSections) (systemPrompt, userPrompt string) {
 
 	if len(s.Canon) > 0 {
 		sys.WriteString("\n<canon>\n")
+		sys.WriteString("Эти факты уже произошли — не противоречь им.\n")
 		for _, fact := range s.Canon {
 			sys.WriteString("• ")
 			sys.WriteString(fact)
@@ -65,6 +71,17 @@ func BuildStructuredPrompt(s PromptSections) (systemPrompt, userPrompt string) {
 		sys.WriteString("</canon>\n")
 	}
 
+	if len(s.Forbidden) > 0 {
+		sys.WriteString("\n<forbidden>\n")
+		sys.WriteString("ЗАПРЕЩЕНО В ЭТОМ МИРЕ. Не создавай событий, нарушающих эти запреты: такие события будут отброшены.\n")
+		for _, forbiddance := range s.Forbidden {
+			sys.WriteString("• ")
+			sys.WriteString(forbiddance)
+			sys.WriteString("\n")
+		}
+		sys.WriteString("</forbidden>\n")
+	}
+
 	sys.WriteString("\n<rules>\n")
 	sys.WriteString(fmt.Sprintf("• МАКСИМУМ %d событий в new_events.\n", maxEvents))
 	sys.WriteString("• События должны быть СЕМАНТИЧЕСКИМИ (звук за дверью, появление сущности), а не атомарными действиями.\n")
@@ -77,6 +94,9 @@ func BuildStructuredPrompt(s PromptSections) (systemPrompt, userPrompt string) {
 	sys.WriteString("• entity/target/source: объекты с полем id (опционально): {\"entity\": {\"id\": \"xxx\", \"type\": \"player\", \"name\": \"Имя\"}}.\n")
 	sys.WriteString("• payload — объект с произвольными полями, релевантными событию. Всегда валидный объект {}.\n")
 	sys.WriteString("• mood — массив строк (может быть пустым []).\n")
+	sys.WriteString("• payload.canon — необязательно: \"scope\" (факт области) или \"world\" (факт мира), если событие устанавливает долговременный факт: гибель, разрушение, союз, открытие.\n")
+	sys.WriteString("• choice — необязательно. Добавляй только в поворотный момент, когда решение игрока меняет ход истории: 2–3 варианта, у каждого id, text и consequence.\n")
+	sys.WriteString("• Не добавляй choice, если в <situation> есть <pending_choice>: игроки ещё не ответили на предыдущий выбор.\n")
 	sys.WriteString("• Ответ должен начинаться с { и заканчиваться }. Без комментариев //, многоточий ..., кавычек-ёлочек «».\n")
 	sys.WriteString("</rules>\n")
 
@@ -95,7 +115,8 @@ func BuildStructuredPrompt(s PromptSections) (systemPrompt, userPrompt string) {
 	sys.WriteString("      \"target\": {\"entity\": {\"id\": \"цель-ид\", \"type\": \"...\", \"name\": \"...\"}},\n")
 	sys.WriteString("      \"payload\": {\"description\": \"краткое описание\", \"любые_поля\": \"в зависимости от контекста\"}\n")
 	sys.WriteString("    }\n")
-	sys.WriteString("  ]\n")
+	sys.WriteString("  ],\n")
+	sys.WriteString("  \"choice\": {\"prompt\": \"вопрос игрокам\", \"options\": [{\"id\": \"a\", \"text\": \"вариант\", \"consequence\": \"что произойдёт\"}], \"default_option\": \"a\", \"timeout_seconds\": 120}\n")
 	sys.WriteString("}\n")
 	sys.WriteString("</schema>\n")
 	sys.WriteString("\n<examples>\n")
@@ -136,6 +157,18 @@ func BuildStructuredPrompt(s PromptSections) (systemPrompt, userPrompt string) {
 	usr.WriteString("<events>\n")
 	usr.WriteString(buildEventClusters(s.EventClusters))
 	usr.WriteString("</events>\n")
+	if len(s.ResolvedChoices) > 0 {
+		usr.WriteString("<resolved_choices>\n")
+		for _, choice := range s.ResolvedChoices {
+			usr.WriteString("• ")
+			usr.WriteString(choice)
+			usr.WriteString("\n")
+		}
+		usr.WriteString("</resolved_choices>\n")
+	}
+	if s.PendingChoice != "" {
+		usr.WriteString(fmt.Sprintf("<pending_choice>%s</pending_choice>\n", s.PendingChoice))
+	}
 	if s.TriggerEvent != "" {
 		usr.WriteString("<trigger>\n")
 		usr.WriteString(s.TriggerEvent)
@@ -146,7 +179,11 @@ func BuildStructuredPrompt(s PromptSections) (systemPrompt, userPrompt string) {
 	usr.WriteString("\n<task>Продолжи повествование: что логично происходит дальше в этой области?\n\n")
 	usr.WriteString("— Учитывай факты, характеры, обстановку.\n")
 	usr.WriteString("— Даже если событий мало — мир живёт.\n")
-	usr.WriteString("— Используй стилевые модификаторы: «внезапно», «плавно», «тревожно».</task>\n")
+	usr.WriteString("— Используй стилевые модификаторы: «внезапно», «плавно», «тревожно».\n")
+	if len(s.ResolvedChoices) > 0 {
+		usr.WriteString("— Игроки сделали выбор (<resolved_choices>): продолжи историю с его последствиями.\n")
+	}
+	usr.WriteString("</task>\n")
 
 	userPrompt = strings.TrimSpace(usr.String())
 
@@ -160,6 +197,7 @@ func MigratePromptInput(old PromptInput) PromptSections {
 	return PromptSections{
 		WorldFacts:     old.WorldContext,
 		EntityStates:   old.EntitiesContext,
+		Forbidden:      old.Forbidden,
 		ScopeID:        old.ScopeID,
 		ScopeType:      old.ScopeType,
 		TimeContext:    old.TimeContext,
@@ -171,6 +209,23 @@ func MigratePromptInput(old PromptInput) PromptSections {
 	}
 }
 
+// buildForbiddenSection формирует раздел «ЗАПРЕЩЕНО В ЭТОМ МИРЕ» для BuildPrompt; без запретов — пусто.
+func buildForbiddenSection(forbidden []string) string {
+	if len(forbidden) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("### ЗАПРЕЩЕНО В ЭТОМ МИРЕ\n")
+	sb.WriteString("Не создавай событий, нарушающих эти запреты: такие события будут отброшены.\n")
+	for _, forbiddance := range forbidden {
+		sb.WriteString("• ")
+		sb.WriteString(forbiddance)
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
 // BuildTimeContextStructured — улучшенная версия BuildTimeContext с поддержкой игрового времени.
 // gameTimeMs — опциональное игровое время в миллисекундах (nil = не используется).
 func BuildTimeContextStructured(lastEventTime *time.Time, lastMood []string, gameTimeMs *int64) string {
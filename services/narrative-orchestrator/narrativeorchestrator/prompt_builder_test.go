@@ -5,6 +5,10 @@ package narrativeorchestrator
 import (
 	"strings"
 	"testing"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/worldtime"
 )
 
 func minimalSections() PromptSections {
@@ -85,6 +89,27 @@ func TestBuildStructuredPrompt_CanonOmittedWhenEmpty(t *testing.T) {
 	}
 }
 
+func TestBuildStructuredPrompt_ForbiddenInSystem(t *testing.T) {
+	s := minimalSections()
+	s.Forbidden = []string{"Фиксация Абсолютного Порядка", "предметы healing_potion"}
+	sys, usr := BuildStructuredPrompt(s)
+
+	if !strings.Contains(sys, "<forbidden>") || !strings.Contains(sys, "ЗАПРЕЩЕНО В ЭТОМ МИРЕ") || !strings.Contains(sys, "• предметы healing_potion") {
+		t.Errorf("system prompt missing forbidden section: %s", sys)
+	}
+	if strings.Contains(usr, "healing_potion") {
+		t.Error("forbiddances must be in the system prompt only")
+	}
+
+	legacy, _ := BuildPrompt(PromptInput{Forbidden: s.Forbidden})
+	if !strings.Contains(legacy, "### ЗАПРЕЩЕНО В ЭТОМ МИРЕ\n") || !strings.Contains(legacy, "• Фиксация Абсолютного Порядка") {
+		t.Errorf("legacy prompt missing forbidden section: %s", legacy)
+	}
+	if legacy, _ := BuildPrompt(PromptInput{}); strings.Contains(legacy, "ЗАПРЕЩЕНО") {
+		t.Error("legacy prompt must NOT contain the forbidden section without forbiddances")
+	}
+}
+
 func TestBuildStructuredPrompt_MaxEventsDefault(t *testing.T) {
 	s := minimalSections()
 	s.MaxEvents = 0 // должно применить default 3
@@ -177,3 +202,45 @@ func TestCleanJSONResponse_CleanJSON(t *testing.T) {
 		t.Errorf("cleanJSONResponse clean: got %q want %q", got, input)
 	}
 }
+
+func TestBuildTimeContext_WorldDate(t *testing.T) {
+	date := worldtime.DefaultCalendar().Date(44*24*time.Hour + 23*time.Hour)
+	got := BuildTimeContext(&date, nil, []string{"тревога"})
+	for _, want := range []string{"Мировое время: год 1, лето, день 15, 23:00 (ночь)", "- Сезон: лето", "- Праздник: Ночь Фонарей", "- Атмосфера: тревога"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("time context missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "Абсолютное время") {
+		t.Errorf("world date must replace wall-clock time:\n%s", got)
+	}
+}
+
+func TestHistoryEntry_FallbackClusters(t *testing.T) {
+	ev := eventbus.NewEvent("player.attacked", "game-service", "pain-realm", map[string]interface{}{
+		"entity":   map[string]interface{}{"id": "kain-777", "type": "player"},
+		"target":   "wolf-12",
+		"mentions": []interface{}{"wolf-12", "old-mill"},
+	})
+	ev.Timestamp = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	entry := newHistoryEntry(ev)
+	if entry.EventType != "player.attacked" || entry.Description == "" {
+		t.Fatalf("expected type and description in history, got %+v", entry)
+	}
+	if strings.Join(entry.Entities, ",") != "wolf-12,old-mill,kain-777" {
+		t.Errorf("unexpected entities: %v", entry.Entities)
+	}
+
+	restored := entry.Event("pain-realm")
+	if restored.ID != ev.ID || restored.Type != ev.Type || !restored.Timestamp.Equal(ev.Timestamp) {
+		t.Errorf("expected restored event to keep ID, type and time, got %s %s %s", restored.ID, restored.Type, restored.Timestamp)
+	}
+
+	clusters := buildEventClusters(clusterEvents([]eventbus.Event{restored}))
+	for _, want := range []string{"player.attacked", entry.Description, "wolf-12", "old-mill"} {
+		if !strings.Contains(clusters, want) {
+			t.Errorf("event clusters missing %q: %s", want, clusters)
+		}
+	}
+}
@@ -0,0 +1,418 @@
+// services/narrativeorchestrator/scopes.go
+
+package narrativeorchestrator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/spatial"
+
+	"github.com/google/uuid"
+)
+
+// Типы скоупов, которые создаёт менеджер скоупов
+const (
+	ScopeTypePlayer = "player"
+	ScopeTypeGroup  = "group"
+	ScopeTypeCity   = "city"
+)
+
+// Настройки менеджера скоупов по умолчанию
+const (
+	DefaultGroupRadius  = 50.0
+	DefaultScopeIdleTTL = 10 * time.Minute
+	// scopeSweepInterval — как часто удаляются простаивающие скоупы
+	scopeSweepInterval = time.Minute
+)
+
+// ScopeManagerConfig — настройки автоматического управления скоупами ГМ.
+type ScopeManagerConfig struct {
+	// GroupRadius — игроки ближе этого расстояния объединяются в группу.
+	// Игрок покидает группу, отойдя от всех её участников дальше 2 × GroupRadius.
+	GroupRadius float64
+	// IdleTTL — скоуп без активности игроков удаляется по истечении этого времени
+	IdleTTL time.Duration
+}
+
+// managedScope — скоуп, созданный менеджером.
+type managedScope struct {
+	id        string
+	scopeType string
+	worldID   string
+	// members — игроки группы или города; у скоупа игрока — сам игрок
+	members    map[string]bool
+	lastActive time.Time
+	// announced — время последней публикации gm.created: ГМ мог быть удалён orchestrator по TTL,
+	// поэтому активный скоуп объявляется повторно (CreateGM идемпотентен)
+	announced time.Time
+}
+
+// trackedPlayer — игрок, замеченный в player_events.
+type trackedPlayer struct {
+	id       string
+	worldID  string
+	point    spatial.Point
+	hasPoint bool
+	// scopeID — скоуп игрока или его группы
+	scopeID  string
+	lastSeen time.Time
+}
+
+// ScopeManager создаёт скоупы ГМ по активности игроков: скоуп игрока при первом действии,
+// скоуп города при действиях в городе, группу — когда игроки сходятся ближе GroupRadius,
+// и удаляет простаивающие скоупы. Управляет ГМ только событиями gm.created / gm.merged /
+// gm.deleted в system_events — теми же, что можно опубликовать вручную.
+type ScopeManager struct {
+	cfg     ScopeManagerConfig
+	publish func(ev eventbus.Event)
+	now     func() time.Time
+
+	mu      sync.Mutex
+	scopes  map[string]*managedScope
+	players map[string]*trackedPlayer
+}
+
+// NewScopeManager создаёт менеджер скоупов, публикующий управляющие события в bus.
+func NewScopeManager(bus *eventbus.EventBus, cfg ScopeManagerConfig) *ScopeManager {
+	return newScopeManager(cfg, func(ev eventbus.Event) {
+		if err := bus.PublishSystemEvent(context.Background(), ev); err != nil {
+			warnLog(scopeIDOf(ev), eventbus.GetWorldIDFromEvent(ev), "Failed to publish scope event", map[string]interface{}{
+				"event_type": ev.Type,
+				"error":      err.Error(),
+			})
+		}
+	})
+}
+
+func newScopeManager(cfg ScopeManagerConfig, publish func(ev eventbus.Event)) *ScopeManager {
+	if cfg.GroupRadius <= 0 {
+		cfg.GroupRadius = DefaultGroupRadius
+	}
+	if cfg.IdleTTL <= 0 {
+		cfg.IdleTTL = DefaultScopeIdleTTL
+	}
+	return &ScopeManager{
+		cfg:     cfg,
+		publish: publish,
+		now:     time.Now,
+		scopes:  make(map[string]*managedScope),
+		players: make(map[string]*trackedPlayer),
+	}
+}
+
+// Run удаляет простаивающие скоупы, пока ctx не отменён.
+func (m *ScopeManager) Run(ctx context.Context) {
+	ticker := time.NewTicker(scopeSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Sweep()
+		}
+	}
+}
+
+// HandlePlayerEvent учитывает активность игрока из player_events.
+func (m *ScopeManager) HandlePlayerEvent(ev eventbus.Event) {
+	if !strings.HasPrefix(ev.Type, eventbus.TypePlayerAction) {
+		return
+	}
+	playerID := activityPlayerID(ev)
+	worldID := eventbus.GetWorldIDFromEvent(ev)
+	if playerID == "" || worldID == "" {
+		return
+	}
+	now := m.now()
+
+	var out []eventbus.Event
+	m.mu.Lock()
+	player, ok := m.players[playerID]
+	if ok && player.worldID != worldID {
+		// Игрок перешёл в другой мир — покидает скоуп прежнего мира
+		out = append(out, m.leaveLocked(&ev, player)...)
+		ok = false
+	}
+	if !ok {
+		player = &trackedPlayer{id: playerID, worldID: worldID}
+		m.players[playerID] = player
+	}
+	player.lastSeen = now
+	if point, ok := activityPoint(ev.Payload); ok {
+		player.point, player.hasPoint = point, true
+	}
+
+	if player.scopeID == "" {
+		player.scopeID = playerScopeID(playerID)
+	}
+	out = append(out, m.activateLocked(&ev, player.scopeID, ScopeTypePlayer, worldID, playerID)...)
+	if cityID := activityCityID(ev); cityID != "" {
+		out = append(out, m.activateLocked(&ev, cityID, ScopeTypeCity, worldID, playerID)...)
+	}
+	out = append(out, m.regroupLocked(&ev, player)...)
+	m.mu.Unlock()
+
+	for _, e := range out {
+		m.publish(e)
+	}
+}
+
+// Sweep удаляет скоупы без активности дольше IdleTTL и забывает неактивных игроков.
+func (m *ScopeManager) Sweep() {
+	now := m.now()
+	var out []eventbus.Event
+	m.mu.Lock()
+	for id, player := range m.players {
+		if now.Sub(player.lastSeen) > m.cfg.IdleTTL {
+			delete(m.players, id)
+		}
+	}
+	ids := make([]string, 0, len(m.scopes))
+	for id := range m.scopes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if scope := m.scopes[id]; now.Sub(scope.lastActive) > m.cfg.IdleTTL {
+			out = append(out, m.deleteLocked(nil, scope, "idle"))
+		}
+	}
+	m.mu.Unlock()
+
+	for _, e := range out {
+		m.publish(e)
+	}
+}
+
+// activateLocked отмечает активность в скоупе, создавая его при необходимости.
+func (m *ScopeManager) activateLocked(cause *eventbus.Event, scopeID, scopeType, worldID, playerID string) []eventbus.Event {
+	now := m.now()
+	scope, ok := m.scopes[scopeID]
+	if !ok {
+		scope = &managedScope{id: scopeID, scopeType: scopeType, worldID: worldID, members: make(map[string]bool)}
+		m.scopes[scopeID] = scope
+	}
+	scope.members[playerID] = true
+	scope.lastActive = now
+	if ok && now.Sub(scope.announced) < m.cfg.IdleTTL/2 {
+		return nil
+	}
+	scope.announced = now
+	return []eventbus.Event{m.createdEvent(cause, scope)}
+}
+
+// regroupLocked выводит игрока из группы, от участников которой он отошёл,
+// и объединяет его скоуп со скоупом ближайшего игрока в радиусе GroupRadius.
+func (m *ScopeManager) regroupLocked(cause *eventbus.Event, player *trackedPlayer) []eventbus.Event {
+	if !player.hasPoint {
+		return nil
+	}
+	var out []eventbus.Event
+	if group := m.scopes[player.scopeID]; group != nil && group.scopeType == ScopeTypeGroup && !m.nearMemberLocked(player, group, 2*m.cfg.GroupRadius) {
+		out = append(out, m.leaveLocked(cause, player)...)
+		player.scopeID = playerScopeID(player.id)
+		out = append(out, m.activateLocked(cause, player.scopeID, ScopeTypePlayer, player.worldID, player.id)...)
+	}
+
+	other := m.nearestLocked(player)
+	if other == nil {
+		return out
+	}
+
+	// Целевая группа — группа одного из игроков, иначе новая
+	target := m.scopes[player.scopeID]
+	if target == nil || target.scopeType != ScopeTypeGroup {
+		target = m.scopes[other.scopeID]
+	}
+	if target == nil || target.scopeType != ScopeTypeGroup {
+		target = &managedScope{
+			id:         "group:" + uuid.New().String()[:8],
+			scopeType:  ScopeTypeGroup,
+			worldID:    player.worldID,
+			members:    make(map[string]bool),
+			lastActive: m.now(),
+		}
+		target.members[player.id] = true
+		target.members[other.id] = true
+		target.announced = target.lastActive
+		m.scopes[target.id] = target
+		out = append(out, m.createdEvent(cause, target))
+	}
+
+	var sources []string
+	for _, sourceID := range []string{player.scopeID, other.scopeID} {
+		source := m.scopes[sourceID]
+		if source == nil || source.id == target.id {
+			continue
+		}
+		// Участники скоупа-источника (игрок или вся другая группа) переходят в целевую группу
+		for memberID := range source.members {
+			target.members[memberID] = true
+			if member := m.players[memberID]; member != nil {
+				member.scopeID = target.id
+			}
+		}
+		delete(m.scopes, source.id)
+		sources = append(sources, source.id)
+	}
+	target.lastActive = m.now()
+	if len(sources) > 0 {
+		out = append(out, m.mergedEvent(cause, target, sources))
+	}
+	return out
+}
+
+// nearestLocked возвращает ближайшего активного игрока того же мира в радиусе GroupRadius
+// из другого скоупа; nil — такого нет.
+func (m *ScopeManager) nearestLocked(player *trackedPlayer) *trackedPlayer {
+	now := m.now()
+	var nearest *trackedPlayer
+	best := m.cfg.GroupRadius
+	for _, other := range m.players {
+		if other == player || other.worldID != player.worldID || !other.hasPoint || other.scopeID == player.scopeID ||
+			now.Sub(other.lastSeen) > m.cfg.IdleTTL {
+			continue
+		}
+		distance := spatial.DistanceBetween(player.point, other.point)
+		// При равном расстоянии — игрок с меньшим ID, чтобы выбор не зависел от порядка обхода
+		if distance > best || (nearest != nil && distance == best && other.id > nearest.id) {
+			continue
+		}
+		nearest, best = other, distance
+	}
+	return nearest
+}
+
+// nearMemberLocked сообщает, есть ли в группе другой участник ближе radius.
+func (m *ScopeManager) nearMemberLocked(player *trackedPlayer, group *managedScope, radius float64) bool {
+	for memberID := range group.members {
+		member := m.players[memberID]
+		if member == nil || member == player || !member.hasPoint {
+			continue
+		}
+		if spatial.DistanceBetween(player.point, member.point) <= radius {
+			return true
+		}
+	}
+	return false
+}
+
+// leaveLocked выводит игрока из его скоупа; опустевший скоуп удаляется.
+func (m *ScopeManager) leaveLocked(cause *eventbus.Event, player *trackedPlayer) []eventbus.Event {
+	scope := m.scopes[player.scopeID]
+	player.scopeID = ""
+	if scope == nil {
+		return nil
+	}
+	delete(scope.members, player.id)
+	if len(scope.members) > 0 {
+		return nil
+	}
+	return []eventbus.Event{m.deleteLocked(cause, scope, "empty")}
+}
+
+// deleteLocked удаляет скоуп и возвращает gm.deleted.
+func (m *ScopeManager) deleteLocked(cause *eventbus.Event, scope *managedScope, reason string) eventbus.Event {
+	delete(m.scopes, scope.id)
+	for _, player := range m.players {
+		if player.scopeID == scope.id {
+			player.scopeID = ""
+		}
+	}
+	ev := m.scopeEvent(cause, "gm.deleted", scope, fmt.Sprintf("Scope %s removed (%s)", scope.id, reason))
+	ev.Payload["reason"] = reason
+	return ev
+}
+
+func (m *ScopeManager) createdEvent(cause *eventbus.Event, scope *managedScope) eventbus.Event {
+	ev := m.scopeEvent(cause, "gm.created", scope, fmt.Sprintf("Scope %s started by player activity", scope.id))
+	eventbus.SetNested(ev.Payload, "focus_entities", scope.memberList())
+	return ev
+}
+
+func (m *ScopeManager) mergedEvent(cause *eventbus.Event, target *managedScope, sources []string) eventbus.Event {
+	ev := m.scopeEvent(cause, "gm.merged", target, fmt.Sprintf("Scopes %s merged into %s", strings.Join(sources, ", "), target.id))
+	sourceIDs := make([]interface{}, len(sources))
+	for i, id := range sources {
+		sourceIDs[i] = id
+	}
+	ev.Payload["source_scope_ids"] = sourceIDs
+	return ev
+}
+
+// scopeEvent собирает управляющее событие скоупа; cause — событие игрока, nil для удаления по простою.
+func (m *ScopeManager) scopeEvent(cause *eventbus.Event, eventType string, scope *managedScope, description string) eventbus.Event {
+	ev := eventbus.NewEventWithDescription(eventType, "narrative-orchestrator", scope.worldID, description)
+	if cause != nil {
+		ev = ev.CausedBy(*cause)
+	}
+	eventbus.SetNested(ev.Payload, "scope.id", scope.id)
+	eventbus.SetNested(ev.Payload, "scope.type", scope.scopeType)
+	return ev
+}
+
+// memberList возвращает участников скоупа по порядку.
+func (s *managedScope) memberList() []interface{} {
+	ids := make([]string, 0, len(s.members))
+	for id := range s.members {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	members := make([]interface{}, len(ids))
+	for i, id := range ids {
+		members[i] = id
+	}
+	return members
+}
+
+func playerScopeID(playerID string) string {
+	return "player:" + playerID
+}
+
+func scopeIDOf(ev eventbus.Event) string {
+	if scope := eventbus.GetScopeFromEvent(ev); scope != nil {
+		return scope.ID
+	}
+	return ""
+}
+
+// activityPlayerID возвращает ID игрока события: player_id или сущность события типа player.
+func activityPlayerID(ev eventbus.Event) string {
+	if id, ok := ev.Payload["player_id"].(string); ok && id != "" {
+		return id
+	}
+	if entity := eventbus.ExtractEntityID(ev.Payload); entity != nil && (entity.Type == "player" || entity.Type == "") {
+		return entity.ID
+	}
+	return ""
+}
+
+// activityCityID возвращает город события: скоуп типа city или city_id.
+func activityCityID(ev eventbus.Event) string {
+	if scope := eventbus.GetScopeFromEvent(ev); scope != nil && scope.Type == ScopeTypeCity {
+		return scope.ID
+	}
+	cityID, _ := ev.Payload["city_id"].(string)
+	return cityID
+}
+
+// activityPoint возвращает позицию игрока: location или цель перемещения to.
+func activityPoint(payload map[string]interface{}) (spatial.Point, bool) {
+	for _, key := range []string{"location", "to"} {
+		if loc, ok := payload[key].(map[string]interface{}); ok {
+			x, okX := loc["x"].(float64)
+			y, okY := loc["y"].(float64)
+			if okX && okY {
+				return spatial.Point{X: x, Y: y}, true
+			}
+		}
+	}
+	return spatial.Point{}, false
+}
@@ -0,0 +1,156 @@
+// services/narrativeorchestrator/scopes_test.go
+
+package narrativeorchestrator
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+)
+
+// newTestScopeManager возвращает менеджер скоупов с управляемыми часами и записью опубликованных событий.
+func newTestScopeManager() (*ScopeManager, *[]eventbus.Event, *time.Time) {
+	var published []eventbus.Event
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	m := newScopeManager(ScopeManagerConfig{GroupRadius: 50, IdleTTL: 10 * time.Minute}, func(ev eventbus.Event) {
+		published = append(published, ev)
+	})
+	m.now = func() time.Time { return now }
+	return m, &published, &now
+}
+
+func playerMoved(playerID string, x, y float64) eventbus.Event {
+	return eventbus.NewEvent("player.moved", "game-service", "pain-realm", map[string]interface{}{
+		"player_id": playerID,
+		"to":        map[string]interface{}{"x": x, "y": y},
+	})
+}
+
+func scopeEventSummary(events []eventbus.Event) []string {
+	var summary []string
+	for _, ev := range events {
+		summary = append(summary, ev.Type+" "+scopeIDOf(ev))
+	}
+	return summary
+}
+
+func TestScopeManager_PlayerScope(t *testing.T) {
+	m, published, now := newTestScopeManager()
+
+	m.HandlePlayerEvent(playerMoved("kain", 0, 0))
+	if got := scopeEventSummary(*published); len(got) != 1 || got[0] != "gm.created player:kain" {
+		t.Fatalf("expected gm.created for player:kain, got %v", got)
+	}
+	created := (*published)[0]
+	if created.CausationID == "" {
+		t.Error("expected gm.created to be caused by the player event")
+	}
+	if eventbus.GetScopeFromEvent(created).Type != ScopeTypePlayer {
+		t.Errorf("expected scope type %q", ScopeTypePlayer)
+	}
+
+	// Повторная активность не объявляет скоуп заново, пока не прошла половина IdleTTL
+	*now = now.Add(time.Minute)
+	m.HandlePlayerEvent(playerMoved("kain", 1, 1))
+	if len(*published) != 1 {
+		t.Fatalf("expected no new events, got %v", scopeEventSummary(*published))
+	}
+	*now = now.Add(6 * time.Minute)
+	m.HandlePlayerEvent(playerMoved("kain", 2, 2))
+	if len(*published) != 2 || (*published)[1].Type != "gm.created" {
+		t.Fatalf("expected scope to be re-announced, got %v", scopeEventSummary(*published))
+	}
+
+	// Событие не игрока игнорируется
+	m.HandlePlayerEvent(eventbus.NewEvent("entity.created", "entity-manager", "pain-realm", map[string]interface{}{"player_id": "kain"}))
+	if len(*published) != 2 {
+		t.Errorf("expected non-player event to be ignored, got %v", scopeEventSummary(*published))
+	}
+}
+
+func TestScopeManager_CityScope(t *testing.T) {
+	m, published, _ := newTestScopeManager()
+
+	ev := playerMoved("kain", 0, 0)
+	ev.Payload["city_id"] = "city-ashford"
+	m.HandlePlayerEvent(ev)
+
+	got := scopeEventSummary(*published)
+	if len(got) != 2 || got[1] != "gm.created city-ashford" {
+		t.Fatalf("expected player and city scopes, got %v", got)
+	}
+	if eventbus.GetScopeFromEvent((*published)[1]).Type != ScopeTypeCity {
+		t.Errorf("expected scope type %q", ScopeTypeCity)
+	}
+}
+
+func TestScopeManager_GroupsNearbyPlayers(t *testing.T) {
+	m, published, _ := newTestScopeManager()
+
+	m.HandlePlayerEvent(playerMoved("kain", 0, 0))
+	m.HandlePlayerEvent(playerMoved("lira", 200, 0))
+	*published = nil
+
+	// Лира подходит к Каину — их скоупы объединяются в группу
+	m.HandlePlayerEvent(playerMoved("lira", 30, 0))
+	got := scopeEventSummary(*published)
+	if len(got) != 2 || !strings.HasPrefix(got[0], "gm.created group:") || !strings.HasPrefix(got[1], "gm.merged group:") {
+		t.Fatalf("expected gm.created and gm.merged for a group, got %v", got)
+	}
+	merged := (*published)[1]
+	sources, _ := merged.Payload["source_scope_ids"].([]interface{})
+	if len(sources) != 2 || sources[0] != "player:lira" || sources[1] != "player:kain" {
+		t.Errorf("unexpected merge sources: %v", sources)
+	}
+	groupID := scopeIDOf(merged)
+	if m.players["kain"].scopeID != groupID || m.players["lira"].scopeID != groupID {
+		t.Fatalf("expected both players in %s", groupID)
+	}
+
+	// Третий игрок присоединяется к существующей группе
+	*published = nil
+	m.HandlePlayerEvent(playerMoved("oren", 10, 10))
+	got = scopeEventSummary(*published)
+	if len(got) != 2 || got[0] != "gm.created player:oren" || got[1] != "gm.merged "+groupID {
+		t.Fatalf("expected oren to be merged into %s, got %v", groupID, got)
+	}
+
+	// Уходя дальше 2 × GroupRadius от всех участников, игрок покидает группу
+	*published = nil
+	m.HandlePlayerEvent(playerMoved("oren", 500, 500))
+	got = scopeEventSummary(*published)
+	if len(got) != 1 || got[0] != "gm.created player:oren" {
+		t.Fatalf("expected oren to get a player scope again, got %v", got)
+	}
+	if m.players["oren"].scopeID != "player:oren" || m.scopes[groupID].members["oren"] {
+		t.Error("expected oren to leave the group")
+	}
+}
+
+func TestScopeManager_SweepRemovesIdleScopes(t *testing.T) {
+	m, published, now := newTestScopeManager()
+
+	m.HandlePlayerEvent(playerMoved("kain", 0, 0))
+	*now = now.Add(5 * time.Minute)
+	m.HandlePlayerEvent(playerMoved("lira", 500, 0))
+	*published = nil
+
+	*now = now.Add(6 * time.Minute)
+	m.Sweep()
+	got := scopeEventSummary(*published)
+	if len(got) != 1 || got[0] != "gm.deleted player:kain" {
+		t.Fatalf("expected only the idle scope to be deleted, got %v", got)
+	}
+	if _, ok := m.players["kain"]; ok {
+		t.Error("expected idle player to be forgotten")
+	}
+
+	// Вернувшийся игрок получает скоуп заново
+	*published = nil
+	m.HandlePlayerEvent(playerMoved("kain", 0, 0))
+	if got := scopeEventSummary(*published); len(got) != 1 || got[0] != "gm.created player:kain" {
+		t.Fatalf("expected player scope to be recreated, got %v", got)
+	}
+}
@@ -4,40 +4,96 @@ package narrativeorchestrator
 
 import (
 	"context"
-	"log"
+	"net/http"
 	"time"
 
+	"multiverse-core.io/shared/archivist"
+	"multiverse-core.io/shared/config"
 	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/eventbus/events"
+	"multiverse-core.io/shared/logging"
+	"multiverse-core.io/shared/schema"
 )
 
 type Config struct {
 	KafkaBrokers []string
+	// Scopes — настройки менеджера скоупов; nil — скоупы ГМ создаются только событиями gm.*
+	Scopes *ScopeManagerConfig
+	// SnapshotRetention — сколько последних снапшотов хранится для каждого скоупа
+	SnapshotRetention int
+	// SnapshotPruneInterval — как часто удаляются старые снапшоты
+	SnapshotPruneInterval time.Duration
+	// HTTPPort — порт HTTP API канона; пусто — API не запускается
+	HTTPPort string
+	// GeometryCacheTTL — время жизни геометрий скоупов в кэше (0 — spatial.DefaultGeometryTTL)
+	GeometryCacheTTL time.Duration
+	// GeometryRefresh — как часто обновляются геометрии активных ГМ (0 — DefaultGeometryRefreshInterval)
+	GeometryRefresh time.Duration
+	// ForbiddancesFromArchivist — загружать запреты миров из профилей онтологии; false — только встроенные правила
+	ForbiddancesFromArchivist bool
+	// ArchivistURL — резервный адрес OntologicalArchivist
+	ArchivistURL string
 }
 
 type Service struct {
-	orchestrator   *NarrativeOrchestrator
-	bus            *eventbus.EventBus
-	defaultWorldID string
+	orchestrator  *NarrativeOrchestrator
+	scopes        *ScopeManager
+	bus           *eventbus.EventBus
+	pruneInterval time.Duration
+	geoRefresh    time.Duration
+	server        *http.Server
+	schemaChanges *schema.ChangeSubscriber
 }
 
 func NewService(cfg Config) (*Service, error) {
 	bus := eventbus.NewEventBus(cfg.KafkaBrokers)
 	orchestrator := NewNarrativeOrchestrator(bus)
-
-	return &Service{
-		orchestrator:   orchestrator,
-		bus:            bus,
-		defaultWorldID: "pain-realm", // Default world for timer events
-	}, nil
+	orchestrator.SetSnapshotRetention(cfg.SnapshotRetention)
+	orchestrator.SetGeometryCacheTTL(cfg.GeometryCacheTTL)
+
+	service := &Service{
+		orchestrator:  orchestrator,
+		bus:           bus,
+		pruneInterval: cfg.SnapshotPruneInterval,
+		geoRefresh:    cfg.GeometryRefresh,
+	}
+	if cfg.ForbiddancesFromArchivist {
+		orchestrator.UseArchivist(archivist.NewClient(cfg.ArchivistURL, orchestrator.discovery))
+		// Новая версия профиля онтологии сбрасывает закэшированные запреты
+		service.schemaChanges = schema.NewChangeSubscriber(bus, "narrative-orchestrator")
+		service.schemaChanges.OnChange(orchestrator.forbiddances.HandleSchemaChange)
+	}
+	if cfg.Scopes != nil {
+		service.scopes = NewScopeManager(bus, *cfg.Scopes)
+	}
+	if cfg.HTTPPort != "" {
+		service.server = &http.Server{
+			Addr:         ":" + cfg.HTTPPort,
+			Handler:      service.routes(),
+			ReadTimeout:  10 * time.Second,
+			WriteTimeout: 10 * time.Second,
+		}
+	}
+	return service, nil
 }
 
 func (s *Service) Start(ctx context.Context) {
-	log.Println("NarrativeOrchestrator started")
+	logging.Infof("NarrativeOrchestrator started")
+
+	// Отслеживаем анонсы сервисов (адрес SemanticMemory)
+	go s.orchestrator.discovery.Run(ctx)
 
-	// Запускаем таймер для periodic time.syncTime событий (default: every 5 seconds)
-	go s.startTimerTicker(ctx)
+	// Удаляем снапшоты ГМ сверх retention
+	go s.orchestrator.RunSnapshotPruner(ctx, s.pruneInterval)
 
-	// Системные события: gm.*, time.syncTime
+	// Обновляем геометрии скоупов активных ГМ пакетом на мир
+	go s.orchestrator.RunGeometryRefresh(ctx, s.geoRefresh)
+
+	if s.schemaChanges != nil {
+		go s.schemaChanges.Run(ctx)
+	}
+
+	// Системные события: gm.*, time.syncTime (тики мирового времени от Chronos), config.updated
 	go s.bus.Subscribe(ctx, eventbus.TopicSystemEvents, "narrative-scope-group", func(ev eventbus.Event) {
 		switch ev.Type {
 		case "gm.created":
@@ -48,8 +104,14 @@ func (s *Service) Start(ctx context.Context) {
 			s.orchestrator.MergeGM(ev)
 		case "gm.split":
 			s.orchestrator.SplitGM(ev)
-		case "time.syncTime":
+		case events.TypeTimeSync:
 			s.orchestrator.HandleTimerEvent(ev)
+		case config.EventConfigUpdated:
+			// Профиль изменён (в том числе другим экземпляром) — сбрасываем его кэш
+			if kind, _ := ev.Payload["kind"].(string); kind == config.KindProfile {
+				id, _ := ev.Payload["id"].(string)
+				s.orchestrator.configStore.Invalidate(id)
+			}
 		}
 	})
 
@@ -57,43 +119,40 @@ func (s *Service) Start(ctx context.Context) {
 	go s.bus.Subscribe(ctx, eventbus.TopicWorldEvents, "narrative-world-group", s.orchestrator.HandleGameEvent)
 	go s.bus.Subscribe(ctx, eventbus.TopicGameEvents, "narrative-game-group", s.orchestrator.HandleGameEvent)
 
-	// NEW: Mechanical results from Entity-Actors
-	go s.bus.Subscribe(ctx, "mechanical_results", "narrative-mechanical-group", func(ev eventbus.Event) {
-		s.orchestrator.HandleMechanicalResult(ev)
+	// Ответы игроков на точки выбора
+	go s.bus.Subscribe(ctx, eventbus.TopicPlayerEvents, "narrative-choice-group", func(ev eventbus.Event) {
+		if ev.Type == EventChoiceSelected {
+			s.orchestrator.HandleChoiceSelected(ev)
+		}
 	})
-}
 
-func (s *Service) startTimerTicker(ctx context.Context) {
-	ticker := time.NewTicker(10 * time.Second)
-	defer ticker.Stop()
-
-	log.Println("Timer ticker started for time.syncTime events")
-
-	for {
-		select {
-		case <-ctx.Done():
-			log.Println("Timer ticker stopped")
-			return
-		case <-ticker.C:
-			ev := eventbus.NewEvent(
-				"time.syncTime",
-				"narrative-orchestrator",
-				s.defaultWorldID,
-				map[string]interface{}{
-					"current_time_unix_ms": time.Now().UnixMilli(),
-				},
-			)
-
-			// Publish timer event to default world
-			if err := s.bus.PublishSystemEvent(ctx, ev); err != nil {
-				log.Printf("Failed to publish time.syncTime: %v", err)
-			} else {
-				log.Printf("Published time.syncTime event: %s for world %s", ev.ID, s.defaultWorldID)
+	// Скоупы ГМ по активности игроков: gm.created / gm.merged / gm.deleted в system_events
+	if s.scopes != nil {
+		go s.scopes.Run(ctx)
+		go s.bus.Subscribe(ctx, eventbus.TopicPlayerEvents, "narrative-scope-manager-group", s.scopes.HandlePlayerEvent)
+	}
+
+	// HTTP API канона
+	if s.server != nil {
+		go func() {
+			logging.Infof("NarrativeOrchestrator HTTP API listening on %s", s.server.Addr)
+			if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logging.Errorf("NarrativeOrchestrator HTTP server failed: %v", err)
 			}
-		}
+		}()
 	}
+
+	// NEW: Mechanical results from Entity-Actors
+	go s.bus.Subscribe(ctx, "mechanical_results", "narrative-mechanical-group", func(ev eventbus.Event) {
+		s.orchestrator.HandleMechanicalResult(ev)
+	})
 }
 
 func (s *Service) Stop() {
+	if s.server != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		s.server.Shutdown(ctx)
+	}
 	s.bus.Close()
 }
@@ -0,0 +1,123 @@
+package narrativeorchestrator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"multiverse-core.io/shared/minio"
+	"multiverse-core.io/shared/minio/miniotest"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	storage := miniotest.New()
+	no := NewNarrativeOrchestratorWithStorage(nil, storage)
+
+	gm := &GMInstance{ScopeID: "region:forest", ScopeType: "region", WorldID: "w1", State: map[string]interface{}{"mood": "calm"}}
+	if err := no.saveSnapshot(gm.ScopeID, gm); err != nil {
+		t.Fatal(err)
+	}
+	// Версия снапшота и указатель latest.json на неё
+	puts := storage.Puts("gnue-snapshots")
+	if len(puts) != 2 || !strings.HasPrefix(puts[0], "gnue/gm-snapshots/v1/") || !strings.HasSuffix(puts[1], "/latest.json") {
+		t.Fatalf("unexpected snapshot writes %v", puts)
+	}
+
+	loaded, err := no.loadSnapshot(gm.ScopeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.WorldID != "w1" || loaded.State["mood"] != "calm" {
+		t.Errorf("unexpected snapshot %+v", loaded)
+	}
+	if _, err := no.loadSnapshot("region:unknown"); !errors.Is(err, errNoSnapshots) {
+		t.Errorf("expected errNoSnapshots for a scope without snapshots, got %v", err)
+	}
+}
+
+func TestSnapshotCorruptionFallsBack(t *testing.T) {
+	storage := miniotest.New()
+	no := NewNarrativeOrchestratorWithStorage(nil, storage)
+
+	gm := &GMInstance{ScopeID: "region:forest", WorldID: "w1", State: map[string]interface{}{"mood": "calm"}}
+	if err := no.saveSnapshot(gm.ScopeID, gm); err != nil {
+		t.Fatal(err)
+	}
+	gm.State["mood"] = "tense"
+	if err := no.saveSnapshot(gm.ScopeID, gm); err != nil {
+		t.Fatal(err)
+	}
+
+	// Последняя версия повреждена — загружается предыдущая
+	latest := storage.Puts("gnue-snapshots")[2]
+	storage.Put("gnue-snapshots", latest, `{"world_id":"w1","state":{"mood":"forged"}}`)
+	loaded, err := no.loadSnapshot(gm.ScopeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.State["mood"] != "calm" {
+		t.Errorf("expected fallback to the previous snapshot, got %v", loaded.State["mood"])
+	}
+
+	// Повреждены все версии — ошибка, а не чужое состояние
+	previous := storage.Puts("gnue-snapshots")[0]
+	storage.Put("gnue-snapshots", previous, `{}`)
+	if _, err := no.loadSnapshot(gm.ScopeID); !errors.Is(err, errSnapshotCorrupted) {
+		t.Errorf("expected errSnapshotCorrupted, got %v", err)
+	}
+}
+
+func TestPruneSnapshots(t *testing.T) {
+	storage := miniotest.New()
+	no := NewNarrativeOrchestratorWithStorage(nil, storage)
+	no.SetSnapshotRetention(2)
+
+	// Версия старого формата — самая старая
+	dir := snapshotDir("region:forest")
+	storage.Put("gnue-snapshots", dir+"/1700000000_001.json", `{"world_id":"w1"}`)
+	for i := 0; i < 3; i++ {
+		if err := no.saveSnapshot("region:forest", &GMInstance{WorldID: "w1"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := no.saveSnapshot("region:lake", &GMInstance{WorldID: "w1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := no.PruneSnapshots()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 2 {
+		t.Errorf("expected 2 pruned versions, got %d", removed)
+	}
+	versions, err := no.listSnapshots(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 2 || versions[0].hash == "" || versions[1].hash == "" {
+		t.Errorf("expected the 2 newest versions to remain, got %+v", versions)
+	}
+	if _, err := no.loadSnapshot("region:forest"); err != nil {
+		t.Errorf("expected latest snapshot to survive pruning: %v", err)
+	}
+	if lake, _ := no.listSnapshots(snapshotDir("region:lake")); len(lake) != 1 {
+		t.Errorf("expected other scopes untouched, got %d versions", len(lake))
+	}
+}
+
+func TestOrchestratorWithoutStorage(t *testing.T) {
+	no := NewNarrativeOrchestratorWithStorage(nil, nil)
+	if no.minioClient != nil {
+		t.Fatal("expected no storage")
+	}
+	if err := no.saveSnapshot("region:forest", &GMInstance{}); err == nil {
+		t.Error("expected error saving without storage")
+	}
+	if _, err := no.loadSnapshot("region:forest"); err == nil {
+		t.Error("expected error loading without storage")
+	}
+	if _, err := no.configStore.GetProfile("region"); !minio.IsUnavailable(err) {
+		t.Errorf("expected unavailable config store, got %v", err)
+	}
+}
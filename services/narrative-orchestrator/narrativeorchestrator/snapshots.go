@@ -0,0 +1,372 @@
+// services/narrativeorchestrator/snapshots.go
+
+package narrativeorchestrator
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"multiverse-core.io/shared/minio"
+)
+
+// Снапшоты ГМ хранятся в snapshotBucket по ключам gnue/gm-snapshots/v1/{sha256(scope_id)}/:
+//   - {unix_nano}-{sha256 содержимого}.json — версии снапшота; хэш в имени проверяется при загрузке;
+//   - latest.json — указатель на последнюю версию (SnapshotPointer);
+//   - {unix}_001.json — версии старого формата без хэша, читаются и удаляются по retention наравне с новыми.
+const (
+	snapshotBucket      = "gnue-snapshots"
+	snapshotRoot        = "gnue/gm-snapshots/v1"
+	snapshotPointerName = "latest.json"
+)
+
+// Настройки хранения снапшотов по умолчанию
+const (
+	DefaultSnapshotRetention     = 10
+	DefaultSnapshotPruneInterval = 10 * time.Minute
+)
+
+// errNoSnapshots — у скоупа нет ни одного снапшота.
+var errNoSnapshots = errors.New("no snapshots")
+
+// errSnapshotCorrupted — содержимое снапшота не совпадает с его хэшем.
+var errSnapshotCorrupted = errors.New("snapshot hash mismatch")
+
+// SnapshotPointer — содержимое latest.json: последняя версия снапшота скоупа.
+type SnapshotPointer struct {
+	ScopeID string    `json:"scope_id"`
+	Key     string    `json:"key"`
+	SHA256  string    `json:"sha256"`
+	Size    int64     `json:"size"`
+	SavedAt time.Time `json:"saved_at"`
+}
+
+// snapshotVersion — версия снапшота в хранилище.
+type snapshotVersion struct {
+	key     string
+	savedAt time.Time
+	// hash — SHA-256 содержимого из имени объекта; пусто у версий старого формата
+	hash string
+}
+
+// SetSnapshotRetention задаёт, сколько последних снапшотов хранится для каждого скоупа.
+func (no *NarrativeOrchestrator) SetSnapshotRetention(n int) {
+	if n > 0 {
+		no.snapshotRetention = n
+	}
+}
+
+func snapshotDir(scopeID string) string {
+	return path.Join(snapshotRoot, fmt.Sprintf("%x", sha256.Sum256([]byte(scopeID))))
+}
+
+// parseSnapshotKey разбирает имя версии снапшота; ok == false — объект не является версией.
+func parseSnapshotKey(key string) (snapshotVersion, bool) {
+	name, ok := strings.CutSuffix(path.Base(key), ".json")
+	if !ok {
+		return snapshotVersion{}, false
+	}
+	if stamp, hash, ok := strings.Cut(name, "-"); ok {
+		nanos, err := strconv.ParseInt(stamp, 10, 64)
+		if err != nil || len(hash) != sha256.Size*2 {
+			return snapshotVersion{}, false
+		}
+		return snapshotVersion{key: key, savedAt: time.Unix(0, nanos).UTC(), hash: hash}, true
+	}
+	if stamp, ok := strings.CutSuffix(name, "_001"); ok {
+		seconds, err := strconv.ParseInt(stamp, 10, 64)
+		if err != nil {
+			return snapshotVersion{}, false
+		}
+		return snapshotVersion{key: key, savedAt: time.Unix(seconds, 0).UTC()}, true
+	}
+	return snapshotVersion{}, false
+}
+
+// listSnapshots возвращает версии снапшотов скоупа, новые — первыми.
+// Порядок определяется временем из имени объекта, а не порядком листинга.
+func (no *NarrativeOrchestrator) listSnapshots(dir string) ([]snapshotVersion, error) {
+	objects, err := no.minioClient.ListObjects(snapshotBucket, dir+"/")
+	if err != nil {
+		return nil, err
+	}
+	var versions []snapshotVersion
+	for _, obj := range objects {
+		if version, ok := parseSnapshotKey(obj.Key); ok {
+			versions = append(versions, version)
+		}
+	}
+	sortSnapshots(versions)
+	return versions, nil
+}
+
+func sortSnapshots(versions []snapshotVersion) {
+	sort.Slice(versions, func(i, j int) bool {
+		if !versions[i].savedAt.Equal(versions[j].savedAt) {
+			return versions[i].savedAt.After(versions[j].savedAt)
+		}
+		return versions[i].key > versions[j].key
+	})
+}
+
+// loadSnapshot восстанавливает ГМ из последнего целого снапшота: сначала по указателю latest.json,
+// затем — если указателя нет или версия повреждена — из более старых версий.
+func (no *NarrativeOrchestrator) loadSnapshot(scopeID string) (*GMInstance, error) {
+	debugLog(scopeID, "", "Loading GM snapshot", map[string]interface{}{
+		"scope_id": scopeID,
+	})
+
+	if no.minioClient == nil {
+		warnLog(scopeID, "", "MinIO client not available, cannot load snapshot", map[string]interface{}{})
+		return nil, fmt.Errorf("minio client not available")
+	}
+
+	dir := snapshotDir(scopeID)
+	pointer, err := no.readSnapshotPointer(dir)
+	if err != nil && !minio.IsNotFound(err) {
+		warnLog(scopeID, "", "Failed to read snapshot pointer, scanning versions", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	if pointer != nil {
+		gm, err := no.readSnapshot(pointer.Key, pointer.SHA256)
+		if err == nil {
+			infoLog(scopeID, "", "Successfully loaded GM snapshot", map[string]interface{}{
+				"object_key":    pointer.Key,
+				"snapshot_size": pointer.Size,
+			})
+			return gm, nil
+		}
+		warnLog(scopeID, "", "Latest GM snapshot unusable, falling back to older versions", map[string]interface{}{
+			"error":      err.Error(),
+			"object_key": pointer.Key,
+		})
+	}
+
+	versions, err := no.listSnapshots(dir)
+	if err != nil {
+		errorLog(scopeID, "", "Failed to list snapshot objects from MinIO", map[string]interface{}{
+			"error":  err.Error(),
+			"prefix": dir,
+		})
+		return nil, err
+	}
+	if len(versions) == 0 {
+		infoLog(scopeID, "", "No snapshots found for GM", map[string]interface{}{
+			"prefix": dir,
+		})
+		return nil, errNoSnapshots
+	}
+
+	var lastErr error
+	for _, version := range versions {
+		if pointer != nil && version.key == pointer.Key {
+			continue
+		}
+		gm, err := no.readSnapshot(version.key, version.hash)
+		if err != nil {
+			warnLog(scopeID, "", "Skipping unusable GM snapshot", map[string]interface{}{
+				"error":      err.Error(),
+				"object_key": version.key,
+			})
+			lastErr = err
+			continue
+		}
+		infoLog(scopeID, "", "Successfully loaded GM snapshot", map[string]interface{}{
+			"object_key": version.key,
+		})
+		return gm, nil
+	}
+	if lastErr == nil {
+		// Единственная версия — та, на которую указывал повреждённый указатель
+		lastErr = errSnapshotCorrupted
+	}
+	errorLog(scopeID, "", "No usable GM snapshot", map[string]interface{}{
+		"versions": len(versions),
+	})
+	return nil, fmt.Errorf("no usable snapshot among %d versions: %w", len(versions), lastErr)
+}
+
+func (no *NarrativeOrchestrator) readSnapshotPointer(dir string) (*SnapshotPointer, error) {
+	data, err := no.minioClient.GetObject(snapshotBucket, path.Join(dir, snapshotPointerName))
+	if err != nil {
+		return nil, err
+	}
+	var pointer SnapshotPointer
+	if err := json.Unmarshal(data, &pointer); err != nil {
+		return nil, err
+	}
+	if pointer.Key == "" {
+		return nil, fmt.Errorf("snapshot pointer without key")
+	}
+	return &pointer, nil
+}
+
+// readSnapshot декодирует версию снапшота потоком (снапшот с историей может быть большим),
+// попутно считая SHA-256; expectedHash == "" — версия старого формата без проверки.
+func (no *NarrativeOrchestrator) readSnapshot(key, expectedHash string) (*GMInstance, error) {
+	stream, err := no.minioClient.GetObjectStream(snapshotBucket, key)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	hasher := sha256.New()
+	reader := io.TeeReader(stream, hasher)
+	var gm GMInstance
+	decodeErr := json.NewDecoder(reader).Decode(&gm)
+	// Дочитываем остаток, чтобы хэш покрывал весь объект
+	if _, err := io.Copy(io.Discard, reader); err != nil {
+		return nil, minio.ClassifyError(err)
+	}
+	if expectedHash != "" && hex.EncodeToString(hasher.Sum(nil)) != expectedHash {
+		return nil, errSnapshotCorrupted
+	}
+	if decodeErr != nil {
+		return nil, fmt.Errorf("decode snapshot: %w", decodeErr)
+	}
+	return &gm, nil
+}
+
+// saveSnapshot записывает новую версию снапшота и переводит на неё указатель latest.json.
+// Старые версии удаляет PruneSnapshots.
+func (no *NarrativeOrchestrator) saveSnapshot(scopeID string, gm *GMInstance) error {
+	debugLog(scopeID, gm.WorldID, "Saving GM snapshot", map[string]interface{}{
+		"scope_id": scopeID,
+	})
+
+	if no.minioClient == nil {
+		warnLog(scopeID, gm.WorldID, "MinIO client not available, cannot save snapshot", map[string]interface{}{})
+		return fmt.Errorf("minio client not available")
+	}
+
+	data, err := json.Marshal(gm)
+	if err != nil {
+		errorLog(scopeID, gm.WorldID, "Failed to marshal GM for snapshot", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return err
+	}
+
+	sum := sha256.Sum256(data)
+	pointer := SnapshotPointer{
+		ScopeID: scopeID,
+		SHA256:  hex.EncodeToString(sum[:]),
+		Size:    int64(len(data)),
+		SavedAt: time.Now().UTC(),
+	}
+	dir := snapshotDir(scopeID)
+	pointer.Key = path.Join(dir, fmt.Sprintf("%020d-%s.json", pointer.SavedAt.UnixNano(), pointer.SHA256))
+
+	if err := no.minioClient.PutObject(snapshotBucket, pointer.Key, bytes.NewReader(data), pointer.Size); err != nil {
+		errorLog(scopeID, gm.WorldID, "Failed to save GM snapshot to MinIO", map[string]interface{}{
+			"error":      err.Error(),
+			"object_key": pointer.Key,
+		})
+		return err
+	}
+
+	// Без указателя снапшот всё равно найдётся при сканировании версий
+	pointerData, _ := json.Marshal(pointer)
+	if err := no.minioClient.PutObject(snapshotBucket, path.Join(dir, snapshotPointerName), bytes.NewReader(pointerData), int64(len(pointerData))); err != nil {
+		warnLog(scopeID, gm.WorldID, "Failed to update GM snapshot pointer", map[string]interface{}{
+			"error":      err.Error(),
+			"object_key": pointer.Key,
+		})
+	}
+
+	infoLog(scopeID, gm.WorldID, "Successfully saved GM snapshot", map[string]interface{}{
+		"object_key":    pointer.Key,
+		"snapshot_size": len(data),
+	})
+
+	return nil
+}
+
+// PruneSnapshots удаляет у каждого скоупа версии снапшотов сверх snapshotRetention последних.
+// Версия, на которую указывает latest.json, не удаляется. Возвращает число удалённых объектов.
+func (no *NarrativeOrchestrator) PruneSnapshots() (int, error) {
+	if no.minioClient == nil {
+		return 0, fmt.Errorf("minio client not available")
+	}
+
+	objects, err := no.minioClient.ListObjects(snapshotBucket, snapshotRoot+"/")
+	if err != nil {
+		return 0, err
+	}
+	byScope := make(map[string][]snapshotVersion)
+	for _, obj := range objects {
+		if version, ok := parseSnapshotKey(obj.Key); ok {
+			dir := path.Dir(obj.Key)
+			byScope[dir] = append(byScope[dir], version)
+		}
+	}
+
+	removed := 0
+	for dir, versions := range byScope {
+		if len(versions) <= no.snapshotRetention {
+			continue
+		}
+		sortSnapshots(versions)
+		keep := ""
+		if pointer, err := no.readSnapshotPointer(dir); err == nil {
+			keep = pointer.Key
+		}
+		for _, version := range versions[no.snapshotRetention:] {
+			if version.key == keep {
+				continue
+			}
+			if err := no.minioClient.RemoveObject(snapshotBucket, version.key); err != nil {
+				warnLog("", "", "Failed to prune GM snapshot", map[string]interface{}{
+					"error":      err.Error(),
+					"object_key": version.key,
+				})
+				continue
+			}
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// RunSnapshotPruner периодически удаляет старые снапшоты, пока ctx не отменён.
+func (no *NarrativeOrchestrator) RunSnapshotPruner(ctx context.Context, interval time.Duration) {
+	if no.minioClient == nil {
+		return
+	}
+	if interval <= 0 {
+		interval = DefaultSnapshotPruneInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			removed, err := no.PruneSnapshots()
+			if err != nil {
+				warnLog("", "", "Failed to prune GM snapshots", map[string]interface{}{
+					"error": err.Error(),
+				})
+				continue
+			}
+			if removed > 0 {
+				infoLog("", "", "Pruned old GM snapshots", map[string]interface{}{
+					"removed":   removed,
+					"retention": no.snapshotRetention,
+				})
+			}
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package narrativeorchestrator
+
+import (
+	"sync"
+
+	"multiverse-core.io/shared/eventbus/events"
+	"multiverse-core.io/shared/worldtime"
+)
+
+// worldClocks хранит последний тик мирового времени каждого мира (тики Chronos).
+type worldClocks struct {
+	mu    sync.RWMutex
+	ticks map[string]events.TimeSync
+}
+
+func newWorldClocks() *worldClocks {
+	return &worldClocks{ticks: make(map[string]events.TimeSync)}
+}
+
+// record запоминает тик мира; тики без мирового времени не запоминаются.
+func (c *worldClocks) record(worldID string, tick events.TimeSync) {
+	if _, ok := tick.WorldTime(); !ok || worldID == "" {
+		return
+	}
+	c.mu.Lock()
+	c.ticks[worldID] = tick
+	c.mu.Unlock()
+}
+
+// has сообщает, что для мира приходят тики мирового времени.
+func (c *worldClocks) has(worldID string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	_, ok := c.ticks[worldID]
+	return ok
+}
+
+// date возвращает мировую дату последнего тика мира; nil — мировое время миру неизвестно.
+func (c *worldClocks) date(worldID string) *worldtime.Date {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	tick, ok := c.ticks[worldID]
+	if !ok || tick.Calendar == nil {
+		return nil
+	}
+	date := *tick.Calendar
+	return &date
+}
@@ -0,0 +1,41 @@
+// Package main is the entry point for NotificationGateway.
+package main
+
+import (
+	"multiverse-core.io/services/notification-gateway/notificationgateway"
+	"multiverse-core.io/shared/config"
+	"multiverse-core.io/shared/logging"
+	"multiverse-core.io/shared/service"
+)
+
+func main() {
+	app := service.Setup("notification-gateway", []config.Option{
+		{Env: "NOTIFICATION_CONFIG_BUCKET", Default: notificationgateway.DefaultConfigBucket, Usage: "MinIO bucket with the channels and routes"},
+		{Env: "NOTIFICATION_CONFIG_KEY", Default: notificationgateway.DefaultConfigKey, Usage: "object with the channels and routes (YAML or JSON)"},
+		{Env: "NOTIFICATION_RELOAD_INTERVAL", Default: "5m", Type: config.TypeDuration, Positive: true, Usage: "interval between reloads of the channels and routes"},
+		{Env: "NOTIFICATION_TIMEOUT", Default: "10s", Type: config.TypeDuration, Positive: true, Usage: "timeout of one delivery attempt"},
+		{Env: "NOTIFICATION_MAX_ATTEMPTS", Default: "5", Type: config.TypeInt, Positive: true, Usage: "delivery attempts per message"},
+		{Env: "NOTIFICATION_RETRY_BACKOFF", Default: "2s", Type: config.TypeDuration, Positive: true, Usage: "delay before the first retry, doubled for every next one"},
+		{Env: "NOTIFICATION_MAX_BACKOFF", Default: "1m", Type: config.TypeDuration, Positive: true, Usage: "longest delay between retries"},
+		{Env: "NOTIFICATION_GATEWAY_PORT", Default: notificationgateway.DefaultHTTPPort, Type: config.TypeInt, Positive: true, Usage: "admin API port (test sends)"},
+	})
+	env := app.Env
+
+	gateway := notificationgateway.NewService(app.Bus(), env.Duration("NOTIFICATION_TIMEOUT"))
+	gateway.SetRetryPolicy(notificationgateway.RetryPolicy{
+		MaxAttempts: env.Int("NOTIFICATION_MAX_ATTEMPTS"),
+		Backoff:     env.Duration("NOTIFICATION_RETRY_BACKOFF"),
+		MaxBackoff:  env.Duration("NOTIFICATION_MAX_BACKOFF"),
+	})
+	gateway.UseHTTP(env.String("NOTIFICATION_GATEWAY_PORT"))
+
+	// Channels and routes are read from MinIO (without MinIO nothing is delivered)
+	minioClient, err := app.MinIO()
+	if err != nil {
+		logging.Warnf("MinIO unavailable, no notification channels: %v", err)
+	} else {
+		gateway.UseStorage(minioClient, env.String("NOTIFICATION_CONFIG_BUCKET"), env.String("NOTIFICATION_CONFIG_KEY"), env.Duration("NOTIFICATION_RELOAD_INTERVAL"))
+	}
+
+	app.Run(gateway)
+}
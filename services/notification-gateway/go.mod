@@ -0,0 +1,8 @@
+module multiverse-core.io/services/notification-gateway
+
+go 1.24
+
+require (
+	github.com/segmentio/kafka-go v0.4.49
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,118 @@
+package notificationgateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+)
+
+// DefaultHTTPPort is the port of the NotificationGateway admin API.
+const DefaultHTTPPort = "8093"
+
+// testSendTimeout bounds a test delivery, retries included.
+const testSendTimeout = 2 * time.Minute
+
+// defaultTestMessage is sent when a test request names neither a message nor a route.
+const defaultTestMessage = "Тестовое уведомление multiverse-core"
+
+// routes builds the NotificationGateway admin API.
+func (s *Service) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", s.handleHealth)
+	mux.HandleFunc("GET /v1/admin/channels", s.handleChannels)
+	mux.HandleFunc("POST /v1/admin/test-send", s.handleTestSend)
+	return mux
+}
+
+// handleHealth handles GET /health.
+func (s *Service) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"status": "healthy",
+		"time":   time.Now().Format(time.RFC3339),
+	})
+}
+
+// channelInfo describes a channel without its URL and token.
+type channelInfo struct {
+	Name   string       `json:"name"`
+	Type   string       `json:"type"`
+	Routes []string     `json:"routes"`
+	Stats  ChannelStats `json:"stats"`
+}
+
+// handleChannels handles GET /v1/admin/channels: the configured channels, their routes and delivery counters.
+func (s *Service) handleChannels(w http.ResponseWriter, r *http.Request) {
+	cfg := s.gateway.config.Current()
+	channels := make([]channelInfo, 0, len(cfg.Channels))
+	for _, channel := range cfg.Channels {
+		info := channelInfo{Name: channel.Name, Type: channel.Type, Routes: []string{}, Stats: s.gateway.dispatcher.Stats(channel.Name)}
+		for _, route := range cfg.Routes {
+			for _, name := range route.Channels {
+				if name == channel.Name {
+					info.Routes = append(info.Routes, route.ID)
+					break
+				}
+			}
+		}
+		channels = append(channels, info)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"channels": channels})
+}
+
+// testSendRequest is the body of POST /v1/admin/test-send: a message for the channels,
+// or an event rendered by a route (sent to the route channels unless channels are given).
+type testSendRequest struct {
+	Channel  string          `json:"channel,omitempty"`
+	Channels []string        `json:"channels,omitempty"`
+	Message  string          `json:"message,omitempty"`
+	Route    string          `json:"route,omitempty"`
+	Event    *eventbus.Event `json:"event,omitempty"`
+}
+
+// handleTestSend handles POST /v1/admin/test-send. The response lists the outcome per channel;
+// the status is 502 if any delivery failed.
+func (s *Service) handleTestSend(w http.ResponseWriter, r *http.Request) {
+	var req testSendRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	channels := req.Channels
+	if req.Channel != "" {
+		channels = append(channels, req.Channel)
+	}
+	message := req.Message
+	if message == "" && req.Route == "" {
+		message = defaultTestMessage
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), testSendTimeout)
+	defer cancel()
+	text, results, err := s.gateway.TestSend(ctx, channels, message, req.Route, req.Event)
+	switch {
+	case errors.Is(err, errNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	status := http.StatusOK
+	for _, result := range results {
+		if !result.Delivered {
+			status = http.StatusBadGateway
+		}
+	}
+	writeJSON(w, status, map[string]interface{}{"text": text, "results": results})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
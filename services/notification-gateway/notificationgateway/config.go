@@ -0,0 +1,309 @@
+package notificationgateway
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"sync"
+	"text/template"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Default location of the channels and routes in MinIO.
+const (
+	DefaultConfigBucket = "gnue-configs"
+	DefaultConfigKey    = "notification-gateway/channels.yaml"
+)
+
+// DefaultReloadInterval is how often the configuration is read again from MinIO.
+const DefaultReloadInterval = 5 * time.Minute
+
+// Channel types.
+const (
+	ChannelDiscord  = "discord"
+	ChannelTelegram = "telegram"
+	ChannelWebhook  = "webhook"
+)
+
+// Channel is an external destination of notifications.
+type Channel struct {
+	Name string `yaml:"name" json:"name"`
+	Type string `yaml:"type" json:"type"`
+	// URL is the Discord or generic webhook; for Telegram it overrides the Bot API address
+	URL string `yaml:"url,omitempty" json:"url,omitempty"`
+	// Token and ChatID address a Telegram chat through a bot
+	Token  string `yaml:"token,omitempty" json:"token,omitempty"`
+	ChatID string `yaml:"chat_id,omitempty" json:"chat_id,omitempty"`
+	// Headers are added to webhook requests, e.g. Authorization
+	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+}
+
+// validate rejects channels that cannot be delivered to.
+func (c Channel) validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("channel has no name")
+	}
+	switch c.Type {
+	case ChannelDiscord, ChannelWebhook:
+		if c.URL == "" {
+			return fmt.Errorf("channel %q has no url", c.Name)
+		}
+	case ChannelTelegram:
+		if c.Token == "" || c.ChatID == "" {
+			return fmt.Errorf("channel %q needs token and chat_id", c.Name)
+		}
+	default:
+		return fmt.Errorf("channel %q has unknown type %q", c.Name, c.Type)
+	}
+	return nil
+}
+
+// Route sends matching events to channels. Conditions are glob patterns (path.Match),
+// e.g. "narrative.*"; a list matches if any pattern does.
+type Route struct {
+	ID         string   `yaml:"id" json:"id"`
+	EventTypes []string `yaml:"event_types" json:"event_types"`
+	// Worlds limits the route to these worlds; empty applies to every world.
+	Worlds []string `yaml:"worlds,omitempty" json:"worlds,omitempty"`
+	// Payload maps payload paths (e.g. "scope.type") to value patterns.
+	Payload  map[string]string `yaml:"payload,omitempty" json:"payload,omitempty"`
+	Channels []string          `yaml:"channels" json:"channels"`
+	// Template is a text/template over Notification; empty uses defaultTemplate
+	Template string `yaml:"template,omitempty" json:"template,omitempty"`
+
+	tmpl *template.Template
+}
+
+// defaultTemplate renders routes without a template.
+const defaultTemplate = `[{{.World}}] {{if .Text}}{{.Text}}{{else}}{{.Type}}{{end}}`
+
+// compile validates the route against the channels and parses its template.
+func (r *Route) compile(channels map[string]Channel) error {
+	if r.ID == "" {
+		return fmt.Errorf("route has no id")
+	}
+	if len(r.EventTypes) == 0 {
+		return fmt.Errorf("route %q has no event_types", r.ID)
+	}
+	if len(r.Channels) == 0 {
+		return fmt.Errorf("route %q has no channels", r.ID)
+	}
+	for _, name := range r.Channels {
+		if _, ok := channels[name]; !ok {
+			return fmt.Errorf("route %q sends to unknown channel %q", r.ID, name)
+		}
+	}
+	patterns := slices.Clone(r.EventTypes)
+	patterns = append(patterns, r.Worlds...)
+	for _, pattern := range r.Payload {
+		patterns = append(patterns, pattern)
+	}
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("route %q has invalid pattern %q: %w", r.ID, pattern, err)
+		}
+	}
+	text := r.Template
+	if text == "" {
+		text = defaultTemplate
+	}
+	tmpl, err := template.New(r.ID).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return fmt.Errorf("route %q has invalid template: %w", r.ID, err)
+	}
+	r.tmpl = tmpl
+	return nil
+}
+
+// matches reports whether the route sends the event.
+func (r Route) matches(ev eventbus.Event) bool {
+	if !matchAny(r.EventTypes, ev.Type) {
+		return false
+	}
+	if len(r.Worlds) > 0 && !matchAny(r.Worlds, eventbus.GetWorldIDFromEvent(ev)) {
+		return false
+	}
+	pa := ev.Path()
+	for field, pattern := range r.Payload {
+		value, ok := pa.GetAny(field)
+		if !ok || value == nil || !matchPattern(pattern, fmt.Sprint(value)) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchAny(patterns []string, value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, pattern := range patterns {
+		if matchPattern(pattern, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchPattern(pattern, value string) bool {
+	ok, err := path.Match(pattern, value)
+	return err == nil && ok
+}
+
+// Config is the set of channels and the routes to them.
+type Config struct {
+	Channels []Channel `yaml:"channels" json:"channels"`
+	Routes   []Route   `yaml:"routes" json:"routes"`
+}
+
+// ParseConfig parses a configuration file (YAML or JSON). ${VAR} references are replaced
+// with environment variables, so webhook URLs and bot tokens can stay in .env.
+// Invalid channels and routes are rejected together with the file.
+func ParseConfig(data []byte) (*Config, error) {
+	var cfg Config
+	if err := yaml.Unmarshal(expandEnv(data), &cfg); err != nil {
+		return nil, fmt.Errorf("invalid notification config: %w", err)
+	}
+	channels := make(map[string]Channel, len(cfg.Channels))
+	for _, channel := range cfg.Channels {
+		if err := channel.validate(); err != nil {
+			return nil, err
+		}
+		if _, dup := channels[channel.Name]; dup {
+			return nil, fmt.Errorf("channel %q is defined twice", channel.Name)
+		}
+		channels[channel.Name] = channel
+	}
+	for i := range cfg.Routes {
+		if err := cfg.Routes[i].compile(channels); err != nil {
+			return nil, err
+		}
+	}
+	return &cfg, nil
+}
+
+// envReference matches ${VAR}; bare $ is left alone, templates use it for variables.
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandEnv replaces ${VAR} references with environment variables.
+func expandEnv(data []byte) []byte {
+	return envReference.ReplaceAllFunc(data, func(ref []byte) []byte {
+		return []byte(os.Getenv(string(ref[2 : len(ref)-1])))
+	})
+}
+
+// Channel returns the channel with the name.
+func (c *Config) Channel(name string) (Channel, bool) {
+	for _, channel := range c.Channels {
+		if channel.Name == name {
+			return channel, true
+		}
+	}
+	return Channel{}, false
+}
+
+// Route returns the route with the ID.
+func (c *Config) Route(id string) (Route, bool) {
+	for _, route := range c.Routes {
+		if route.ID == id {
+			return route, true
+		}
+	}
+	return Route{}, false
+}
+
+// ConfigStore keeps the current configuration loaded from MinIO.
+// Without storage or a configuration file nothing is delivered.
+type ConfigStore struct {
+	storage storage.ObjectStorage
+	bucket  string
+	key     string
+
+	mu     sync.RWMutex
+	config *Config
+}
+
+// NewConfigStore creates a store without channels; see UseStorage for loading them from MinIO.
+func NewConfigStore() *ConfigStore {
+	return &ConfigStore{bucket: DefaultConfigBucket, key: DefaultConfigKey, config: &Config{}}
+}
+
+// UseStorage enables loading the configuration from bucket/key; empty values use the defaults.
+func (s *ConfigStore) UseStorage(client storage.ObjectStorage, bucket, key string) {
+	if bucket == "" {
+		bucket = DefaultConfigBucket
+	}
+	if key == "" {
+		key = DefaultConfigKey
+	}
+	s.storage, s.bucket, s.key = client, bucket, key
+}
+
+// Set replaces the current configuration.
+func (s *ConfigStore) Set(cfg *Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.config = cfg
+}
+
+// Current returns the current configuration; it must not be modified.
+func (s *ConfigStore) Current() *Config {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.config
+}
+
+// Reload reads the configuration from MinIO. A missing file leaves no channels;
+// an unavailable or invalid file keeps the current configuration.
+func (s *ConfigStore) Reload() error {
+	if s.storage == nil {
+		return nil
+	}
+	data, err := s.storage.GetObject(s.bucket, s.key)
+	if storage.IsNotFound(err) {
+		logging.Infof("Notification config %s/%s not found, nothing is delivered", s.bucket, s.key)
+		s.Set(&Config{})
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read notification config %s/%s: %w", s.bucket, s.key, err)
+	}
+	cfg, err := ParseConfig(data)
+	if err != nil {
+		return err
+	}
+	s.Set(cfg)
+	logging.Infof("Loaded %d notification channels and %d routes from %s/%s", len(cfg.Channels), len(cfg.Routes), s.bucket, s.key)
+	return nil
+}
+
+// Run loads the configuration and reloads it every interval until ctx is cancelled.
+func (s *ConfigStore) Run(ctx context.Context, interval time.Duration) {
+	if s.storage == nil {
+		return
+	}
+	if interval <= 0 {
+		interval = DefaultReloadInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.Reload(); err != nil {
+			logging.Warnf("Failed to reload notification config, keeping the current one: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
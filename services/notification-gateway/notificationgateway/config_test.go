@@ -0,0 +1,82 @@
+package notificationgateway
+
+import (
+	"strings"
+	"testing"
+
+	"multiverse-core.io/shared/eventbus"
+)
+
+const testConfig = `
+channels:
+  - name: lore
+    type: discord
+    url: ${TEST_LORE_WEBHOOK}
+  - name: ops
+    type: telegram
+    token: bot-token
+    chat_id: "-100"
+routes:
+  - id: narratives
+    event_types: ["narrative.*"]
+    worlds: ["ash-realm"]
+    channels: [lore]
+    template: '📜 {{.World}}: {{.Text}}'
+  - id: anomalies
+    event_types: ["reality.anomaly.detected"]
+    channels: [ops, lore]
+    template: '⚠️ {{.Field "anomaly_type"}} в мире {{.World}}{{range $i, $w := .Payload.regions}} {{$w}}{{end}}'
+`
+
+func TestParseConfig(t *testing.T) {
+	t.Setenv("TEST_LORE_WEBHOOK", "https://discord.test/hook")
+	cfg, err := ParseConfig([]byte(testConfig))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if lore, _ := cfg.Channel("lore"); lore.URL != "https://discord.test/hook" {
+		t.Errorf("expected the webhook from the environment, got %q", lore.URL)
+	}
+
+	for name, invalid := range map[string]string{
+		"unknown channel":  "channels: []\nroutes: [{id: r, event_types: [a], channels: [missing]}]",
+		"no token":         "channels: [{name: t, type: telegram, chat_id: '1'}]",
+		"unknown type":     "channels: [{name: s, type: slack, url: 'https://s'}]",
+		"invalid template": "channels: [{name: w, type: webhook, url: 'https://w'}]\nroutes: [{id: r, event_types: [a], channels: [w], template: '{{.Text'}]",
+	} {
+		if _, err := ParseConfig([]byte(invalid)); err == nil {
+			t.Errorf("%s: expected the config to be rejected", name)
+		}
+	}
+}
+
+func TestRouteRender(t *testing.T) {
+	t.Setenv("TEST_LORE_WEBHOOK", "https://discord.test/hook")
+	cfg, err := ParseConfig([]byte(testConfig))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	narratives, _ := cfg.Route("narratives")
+	anomalies, _ := cfg.Route("anomalies")
+
+	narrative := eventbus.NewEvent("narrative.generate", "narrative-orchestrator", "ash-realm", map[string]interface{}{
+		"narrative": "Пепел оседает на крыши города.",
+	})
+	if !narratives.matches(narrative) {
+		t.Fatal("expected the narrative route to match")
+	}
+	if text, _ := narratives.render(newNotification(narrative)); text != "📜 ash-realm: Пепел оседает на крыши города." {
+		t.Errorf("unexpected text %q", text)
+	}
+	if narratives.matches(eventbus.NewEvent("narrative.generate", "narrative-orchestrator", "pain-realm", nil)) {
+		t.Error("expected the route to skip other worlds")
+	}
+
+	anomaly := eventbus.NewEvent("reality.anomaly.detected", "reality-monitor", "ash-realm", map[string]interface{}{
+		"anomaly_type": "spatial_integrity",
+		"regions":      []interface{}{"north"},
+	})
+	if text, _ := anomalies.render(newNotification(anomaly)); !strings.HasPrefix(text, "⚠️ spatial_integrity в мире ash-realm north") {
+		t.Errorf("unexpected text %q", text)
+	}
+}
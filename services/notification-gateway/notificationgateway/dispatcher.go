@@ -0,0 +1,203 @@
+package notificationgateway
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/logging"
+)
+
+// Delivery defaults.
+const (
+	DefaultMaxAttempts = 5
+	DefaultBackoff     = 2 * time.Second
+	DefaultMaxBackoff  = time.Minute
+	DefaultQueueSize   = 256
+)
+
+// RetryPolicy controls redelivery of failed messages: the delay doubles from Backoff
+// up to MaxBackoff, or is what a rate-limited channel asked for.
+type RetryPolicy struct {
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+}
+
+// delay returns the wait before the attempt after the failed one (attempts start from 1).
+func (p RetryPolicy) delay(attempt int, err error) time.Duration {
+	delay := p.Backoff
+	for i := 1; i < attempt && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	delay = min(delay, p.MaxBackoff)
+	var failure *deliveryError
+	if errors.As(err, &failure) && failure.retryAfter > delay {
+		delay = failure.retryAfter
+	}
+	return delay
+}
+
+// ChannelStats counts the deliveries of a channel.
+type ChannelStats struct {
+	Delivered   int64     `json:"delivered"`
+	Failed      int64     `json:"failed"`
+	Dropped     int64     `json:"dropped"`
+	Queued      int       `json:"queued"`
+	LastError   string    `json:"last_error,omitempty"`
+	LastErrorAt time.Time `json:"last_error_at,omitempty"`
+}
+
+type delivery struct {
+	channel Channel
+	message Message
+}
+
+// Dispatcher delivers messages with retries. Every channel has its own queue and worker,
+// so a slow or rate-limited channel delays only its own messages, which stay in order.
+// A full queue drops new messages; queued messages are lost on shutdown.
+type Dispatcher struct {
+	// send makes one delivery attempt; replaced in tests
+	send      func(ctx context.Context, ch Channel, msg Message) error
+	retry     RetryPolicy
+	queueSize int
+
+	mu     sync.Mutex
+	ctx    context.Context // nil until Run
+	queues map[string]chan delivery
+	stats  map[string]*ChannelStats
+	wg     sync.WaitGroup
+}
+
+// NewDispatcher creates a dispatcher delivering through the sender with the default policy.
+func NewDispatcher(sender *Sender) *Dispatcher {
+	return &Dispatcher{
+		send:      sender.Send,
+		retry:     RetryPolicy{MaxAttempts: DefaultMaxAttempts, Backoff: DefaultBackoff, MaxBackoff: DefaultMaxBackoff},
+		queueSize: DefaultQueueSize,
+		queues:    make(map[string]chan delivery),
+		stats:     make(map[string]*ChannelStats),
+	}
+}
+
+// SetRetryPolicy overrides the retry policy; zero fields keep their defaults.
+func (d *Dispatcher) SetRetryPolicy(policy RetryPolicy) {
+	if policy.MaxAttempts > 0 {
+		d.retry.MaxAttempts = policy.MaxAttempts
+	}
+	if policy.Backoff > 0 {
+		d.retry.Backoff = policy.Backoff
+	}
+	if policy.MaxBackoff > 0 {
+		d.retry.MaxBackoff = policy.MaxBackoff
+	}
+}
+
+// Enqueue queues a message for the channel; false when the queue of the channel is full.
+func (d *Dispatcher) Enqueue(ch Channel, msg Message) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	queue, ok := d.queues[ch.Name]
+	if !ok {
+		queue = make(chan delivery, d.queueSize)
+		d.queues[ch.Name] = queue
+		if d.ctx != nil {
+			d.startWorker(ch.Name, queue)
+		}
+	}
+	select {
+	case queue <- delivery{channel: ch, message: msg}:
+		return true
+	default:
+		d.channelStats(ch.Name).Dropped++
+		logging.Warnf("Notification queue of channel %s is full, dropping message", ch.Name)
+		return false
+	}
+}
+
+// Run delivers queued messages until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	d.mu.Lock()
+	d.ctx = ctx
+	for name, queue := range d.queues {
+		d.startWorker(name, queue)
+	}
+	d.mu.Unlock()
+
+	<-ctx.Done()
+	d.wg.Wait()
+}
+
+// startWorker delivers the messages of a channel queue; d.mu is held.
+func (d *Dispatcher) startWorker(name string, queue chan delivery) {
+	ctx := d.ctx
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case next := <-queue:
+				if _, err := d.Deliver(ctx, next.channel, next.message); err != nil && ctx.Err() == nil {
+					logging.Errorf("Failed to deliver notification to %s: %v", name, err)
+				}
+			}
+		}
+	}()
+}
+
+// Deliver sends a message, retrying failures that are not permanent, and returns the number of attempts.
+func (d *Dispatcher) Deliver(ctx context.Context, ch Channel, msg Message) (int, error) {
+	var err error
+	attempt := 1
+	for ; ; attempt++ {
+		if err = d.send(ctx, ch, msg); err == nil {
+			break
+		}
+		var failure *deliveryError
+		if attempt >= d.retry.MaxAttempts || errors.As(err, &failure) && failure.permanent {
+			break
+		}
+		delay := d.retry.delay(attempt, err)
+		logging.Infof("Delivery to %s failed (attempt %d/%d), retrying in %s: %v", ch.Name, attempt, d.retry.MaxAttempts, delay, err)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return attempt, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	stats := d.channelStats(ch.Name)
+	if err != nil {
+		stats.Failed++
+		stats.LastError, stats.LastErrorAt = err.Error(), time.Now()
+	} else {
+		stats.Delivered++
+	}
+	return attempt, err
+}
+
+// channelStats returns the counters of a channel; d.mu is held.
+func (d *Dispatcher) channelStats(name string) *ChannelStats {
+	stats, ok := d.stats[name]
+	if !ok {
+		stats = &ChannelStats{}
+		d.stats[name] = stats
+	}
+	return stats
+}
+
+// Stats returns a copy of the counters of a channel.
+func (d *Dispatcher) Stats(name string) ChannelStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	stats := *d.channelStats(name)
+	stats.Queued = len(d.queues[name])
+	return stats
+}
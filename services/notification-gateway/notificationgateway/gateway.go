@@ -0,0 +1,115 @@
+// Package notificationgateway delivers world narratives and anomaly alerts to external channels
+// (Discord, Telegram, webhooks) by routing rules and templates from its configuration.
+package notificationgateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+)
+
+// Gateway routes events to channels.
+type Gateway struct {
+	config     *ConfigStore
+	dispatcher *Dispatcher
+}
+
+// NewGateway creates a gateway without channels delivering through the sender.
+func NewGateway(sender *Sender) *Gateway {
+	return &Gateway{
+		config:     NewConfigStore(),
+		dispatcher: NewDispatcher(sender),
+	}
+}
+
+// HandleEvent renders the event with every matching route and queues it for the route channels.
+// A channel gets the event once, from the first route sending it there.
+func (g *Gateway) HandleEvent(ev eventbus.Event) {
+	cfg := g.config.Current()
+	n := newNotification(ev)
+	sent := make(map[string]bool)
+	for _, route := range cfg.Routes {
+		if !route.matches(ev) {
+			continue
+		}
+		text, err := route.render(n)
+		if err != nil {
+			logging.Warnf("Skipping notification of %s: %v", ev.ID, err)
+			continue
+		}
+		if text == "" {
+			continue
+		}
+		for _, name := range route.Channels {
+			channel, ok := cfg.Channel(name)
+			if !ok || sent[name] {
+				continue
+			}
+			sent[name] = true
+			g.dispatcher.Enqueue(channel, Message{Text: text, Notification: &n})
+		}
+	}
+}
+
+// TestResult is the outcome of a test delivery to a channel.
+type TestResult struct {
+	Channel   string `json:"channel"`
+	Delivered bool   `json:"delivered"`
+	Attempts  int    `json:"attempts"`
+	Error     string `json:"error,omitempty"`
+}
+
+// errNotFound marks unknown channels and routes in test sends.
+var errNotFound = errors.New("not found")
+
+// TestSend delivers a message to the channels right away, with retries, bypassing the queues.
+// With a route the message is rendered from the event by the route template and goes to the
+// route channels unless channels are given.
+func (g *Gateway) TestSend(ctx context.Context, channels []string, text, routeID string, ev *eventbus.Event) (string, []TestResult, error) {
+	cfg := g.config.Current()
+	var n *Notification
+	if routeID != "" {
+		route, ok := cfg.Route(routeID)
+		if !ok {
+			return "", nil, fmt.Errorf("route %q %w", routeID, errNotFound)
+		}
+		if ev == nil {
+			ev = &eventbus.Event{ID: "test", Type: "notification.test", Source: "notification-gateway"}
+		}
+		notification := newNotification(*ev)
+		rendered, err := route.render(notification)
+		if err != nil {
+			return "", nil, err
+		}
+		text, n = rendered, &notification
+		if len(channels) == 0 {
+			channels = route.Channels
+		}
+	}
+	if len(channels) == 0 {
+		return "", nil, fmt.Errorf("no channel to send to")
+	}
+
+	targets := make([]Channel, 0, len(channels))
+	for _, name := range channels {
+		channel, ok := cfg.Channel(name)
+		if !ok {
+			return "", nil, fmt.Errorf("channel %q %w", name, errNotFound)
+		}
+		targets = append(targets, channel)
+	}
+
+	results := make([]TestResult, 0, len(targets))
+	for _, channel := range targets {
+		attempts, err := g.dispatcher.Deliver(ctx, channel, Message{Text: text, Notification: n})
+		result := TestResult{Channel: channel.Name, Delivered: err == nil, Attempts: attempts}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	return text, results, nil
+}
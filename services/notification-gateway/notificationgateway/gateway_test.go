@@ -0,0 +1,162 @@
+package notificationgateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+)
+
+// recorder captures delivery attempts and fails the first ones.
+type recorder struct {
+	mu       sync.Mutex
+	failures []error
+	sent     []string
+}
+
+func (r *recorder) send(ctx context.Context, ch Channel, msg Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.failures) > 0 {
+		err := r.failures[0]
+		r.failures = r.failures[1:]
+		return err
+	}
+	r.sent = append(r.sent, ch.Name+": "+msg.Text)
+	return nil
+}
+
+func newTestGateway(t *testing.T) (*Gateway, *recorder) {
+	t.Helper()
+	t.Setenv("TEST_LORE_WEBHOOK", "https://discord.test/hook")
+	cfg, err := ParseConfig([]byte(testConfig))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	rec := &recorder{}
+	gateway := NewGateway(NewSender(time.Second))
+	gateway.config.Set(cfg)
+	gateway.dispatcher.send = rec.send
+	gateway.dispatcher.SetRetryPolicy(RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond, MaxBackoff: time.Millisecond})
+	return gateway, rec
+}
+
+func TestHandleEventRoutes(t *testing.T) {
+	gateway, rec := newTestGateway(t)
+
+	gateway.HandleEvent(eventbus.NewEvent("reality.anomaly.detected", "reality-monitor", "ash-realm", map[string]interface{}{"anomaly_type": "chaos"}))
+	gateway.HandleEvent(eventbus.NewEvent("narrative.generate", "narrative-orchestrator", "pain-realm", map[string]interface{}{"narrative": "..."}))
+	gateway.HandleEvent(eventbus.NewEvent("player.moved", "game-service", "ash-realm", nil))
+	if queued := gateway.dispatcher.Stats("ops").Queued + gateway.dispatcher.Stats("lore").Queued; queued != 2 {
+		t.Fatalf("expected the anomaly queued for both channels only, got %d messages", queued)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		gateway.dispatcher.Run(ctx)
+		close(done)
+	}()
+	deadline := time.Now().Add(time.Second)
+	for gateway.dispatcher.Stats("ops").Delivered+gateway.dispatcher.Stats("lore").Delivered < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	<-done
+	if len(rec.sent) != 2 || !strings.Contains(rec.sent[0], "⚠️ chaos в мире ash-realm") {
+		t.Errorf("unexpected deliveries %v", rec.sent)
+	}
+}
+
+func TestDeliverRetries(t *testing.T) {
+	gateway, rec := newTestGateway(t)
+	channel, _ := gateway.config.Current().Channel("ops")
+
+	rec.failures = []error{&deliveryError{status: 502, err: errors.New("bad gateway")}, &deliveryError{status: 429, err: errors.New("slow down")}}
+	attempts, err := gateway.dispatcher.Deliver(context.Background(), channel, Message{Text: "hello"})
+	if err != nil || attempts != 3 {
+		t.Errorf("expected delivery on the third attempt, got %d attempts: %v", attempts, err)
+	}
+
+	rec.failures = []error{&deliveryError{status: 400, permanent: true, err: errors.New("chat not found")}}
+	if attempts, err := gateway.dispatcher.Deliver(context.Background(), channel, Message{Text: "hello"}); err == nil || attempts != 1 {
+		t.Errorf("expected a rejected message not to be retried, got %d attempts: %v", attempts, err)
+	}
+	if stats := gateway.dispatcher.Stats("ops"); stats.Delivered != 1 || stats.Failed != 1 || stats.LastError == "" {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	policy := RetryPolicy{MaxAttempts: 5, Backoff: time.Second, MaxBackoff: 5 * time.Second}
+	if delay := policy.delay(4, errors.New("timeout")); delay != 5*time.Second {
+		t.Errorf("expected the backoff capped at 5s, got %s", delay)
+	}
+	if delay := policy.delay(1, &deliveryError{retryAfter: 30 * time.Second}); delay != 30*time.Second {
+		t.Errorf("expected the delay asked by the channel, got %s", delay)
+	}
+}
+
+func TestSenderTelegram(t *testing.T) {
+	var got map[string]interface{}
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path != "/botsecret/sendMessage" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if calls == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"ok": false, "parameters": {"retry_after": 3}}`))
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer server.Close()
+
+	channel := Channel{Name: "ops", Type: ChannelTelegram, URL: server.URL, Token: "secret", ChatID: "-100"}
+	sender := NewSender(time.Second)
+	err := sender.Send(context.Background(), channel, Message{Text: "hello"})
+	var failure *deliveryError
+	if !errors.As(err, &failure) || failure.permanent || failure.retryAfter != 3*time.Second {
+		t.Fatalf("expected a rate limit of 3s, got %v", err)
+	}
+	if err := sender.Send(context.Background(), channel, Message{Text: "hello"}); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	if got["chat_id"] != "-100" || got["text"] != "hello" {
+		t.Errorf("unexpected request %v", got)
+	}
+}
+
+func TestTestSendEndpoint(t *testing.T) {
+	gateway, rec := newTestGateway(t)
+	svc := &Service{gateway: gateway}
+
+	request := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		svc.routes().ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/admin/test-send", strings.NewReader(body)))
+		return w
+	}
+
+	if w := request(`{"channel": "ops", "message": "ping"}`); w.Code != http.StatusOK || len(rec.sent) != 1 || rec.sent[0] != "ops: ping" {
+		t.Errorf("expected the message delivered, got %d %s %v", w.Code, w.Body, rec.sent)
+	}
+	w := request(`{"route": "narratives", "event": {"type": "narrative.generate", "payload": {"world_id": "ash-realm", "narrative": "Рассвет"}}}`)
+	if w.Code != http.StatusOK || rec.sent[1] != "lore: 📜 ash-realm: Рассвет" {
+		t.Errorf("expected the event rendered by the route, got %d %s %v", w.Code, w.Body, rec.sent)
+	}
+	if w := request(`{"channel": "missing"}`); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown channel, got %d", w.Code)
+	}
+
+	rec.failures = []error{&deliveryError{permanent: true, err: errors.New("forbidden")}}
+	if w := request(`{"channel": "ops"}`); w.Code != http.StatusBadGateway {
+		t.Errorf("expected 502 for a failed delivery, got %d %s", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,69 @@
+package notificationgateway
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/jsonpath"
+)
+
+// textPaths are the payload fields holding the human-readable text of an event, in order of preference.
+var textPaths = []string{"narrative", "description", "prompt", "message", "text"}
+
+// Notification is the data route templates are rendered with, e.g.
+// "📜 {{.World}}: {{.Text}}" or "⚠️ {{.Field \"anomaly_type\"}} in {{.World}}".
+type Notification struct {
+	EventID string
+	Type    string
+	Source  string
+	World   string
+	// Scope is the scope ID of the event, if any
+	Scope string
+	Time  time.Time
+	// Text is the narrative or description of the event
+	Text    string
+	Payload map[string]interface{}
+}
+
+// newNotification extracts the template data of an event.
+func newNotification(ev eventbus.Event) Notification {
+	n := Notification{
+		EventID: ev.ID,
+		Type:    ev.Type,
+		Source:  ev.Source,
+		World:   eventbus.GetWorldIDFromEvent(ev),
+		Time:    ev.Timestamp,
+		Payload: ev.Payload,
+	}
+	if scope := eventbus.GetScopeFromEvent(ev); scope != nil {
+		n.Scope = scope.ID
+	}
+	pa := ev.Path()
+	for _, path := range textPaths {
+		if text, ok := pa.GetString(path); ok && strings.TrimSpace(text) != "" {
+			n.Text = text
+			break
+		}
+	}
+	return n
+}
+
+// Field returns a payload value by dot path as text; empty if the path is missing.
+func (n Notification) Field(path string) string {
+	value, ok := jsonpath.New(n.Payload).GetAny(path)
+	if !ok || value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+// render renders the notification with the template of the route.
+func (r Route) render(n Notification) (string, error) {
+	var b strings.Builder
+	if err := r.tmpl.Execute(&b, n); err != nil {
+		return "", fmt.Errorf("render route %q: %w", r.ID, err)
+	}
+	return strings.TrimSpace(b.String()), nil
+}
@@ -0,0 +1,167 @@
+package notificationgateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTelegramAPI is the Telegram Bot API address used when a channel has no url.
+const DefaultTelegramAPI = "https://api.telegram.org"
+
+// Message length limits of the channels; longer texts are cut.
+const (
+	discordMaxLength  = 2000
+	telegramMaxLength = 4096
+)
+
+// Message is a rendered notification on its way to a channel.
+type Message struct {
+	Text string
+	// Notification is the event the message was rendered from; nil for test messages
+	Notification *Notification
+}
+
+// deliveryError describes a failed delivery attempt.
+type deliveryError struct {
+	status int
+	// retryAfter is the delay the channel asked for (HTTP 429)
+	retryAfter time.Duration
+	// permanent errors (rejected requests) are not retried
+	permanent bool
+	err       error
+}
+
+func (e *deliveryError) Error() string {
+	if e.status != 0 {
+		return fmt.Sprintf("HTTP %d: %v", e.status, e.err)
+	}
+	return e.err.Error()
+}
+
+func (e *deliveryError) Unwrap() error { return e.err }
+
+// Sender delivers messages to Discord and generic webhooks and through the Telegram Bot API.
+type Sender struct {
+	client *http.Client
+}
+
+// NewSender creates a sender with the request timeout.
+func NewSender(timeout time.Duration) *Sender {
+	return &Sender{client: &http.Client{Timeout: timeout}}
+}
+
+// Send makes one delivery attempt; the dispatcher retries failures that are not permanent.
+func (s *Sender) Send(ctx context.Context, ch Channel, msg Message) error {
+	endpoint, body := ch.URL, map[string]interface{}{}
+	switch ch.Type {
+	case ChannelDiscord:
+		body["content"] = truncate(msg.Text, discordMaxLength)
+	case ChannelTelegram:
+		api := ch.URL
+		if api == "" {
+			api = DefaultTelegramAPI
+		}
+		endpoint = strings.TrimSuffix(api, "/") + "/bot" + ch.Token + "/sendMessage"
+		body["chat_id"] = ch.ChatID
+		body["text"] = truncate(msg.Text, telegramMaxLength)
+	case ChannelWebhook:
+		body["text"] = msg.Text
+		if n := msg.Notification; n != nil {
+			body["event_id"] = n.EventID
+			body["event_type"] = n.Type
+			body["world_id"] = n.World
+			body["source"] = n.Source
+			body["timestamp"] = n.Time
+			body["payload"] = n.Payload
+		}
+	default:
+		return &deliveryError{permanent: true, err: fmt.Errorf("unknown channel type %q", ch.Type)}
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return &deliveryError{permanent: true, err: err}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return &deliveryError{permanent: true, err: err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if ch.Type == ChannelWebhook {
+		for name, value := range ch.Headers {
+			req.Header.Set(name, value)
+		}
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		// The bot token is part of Telegram URLs: report the channel instead of the request
+		return &deliveryError{err: fmt.Errorf("channel %s unreachable: %w", ch.Name, unwrapURLError(err))}
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	failure := &deliveryError{status: resp.StatusCode, err: fmt.Errorf("%s", strings.TrimSpace(string(respBody)))}
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		failure.retryAfter = retryAfter(resp.Header, respBody)
+	case resp.StatusCode >= 500, resp.StatusCode == http.StatusRequestTimeout:
+		// Server errors and timeouts are retried
+	default:
+		failure.permanent = true
+	}
+	return failure
+}
+
+// retryAfter reads the delay a rate-limited channel asked for: the Retry-After header,
+// retry_after of Discord or parameters.retry_after of Telegram (in seconds).
+func retryAfter(header http.Header, body []byte) time.Duration {
+	if seconds, err := strconv.ParseFloat(header.Get("Retry-After"), 64); err == nil && seconds > 0 {
+		return time.Duration(seconds * float64(time.Second))
+	}
+	var limited struct {
+		RetryAfter float64 `json:"retry_after"`
+		Parameters struct {
+			RetryAfter float64 `json:"retry_after"`
+		} `json:"parameters"`
+	}
+	if json.Unmarshal(body, &limited) == nil {
+		if limited.Parameters.RetryAfter > 0 {
+			return time.Duration(limited.Parameters.RetryAfter * float64(time.Second))
+		}
+		if limited.RetryAfter > 0 {
+			return time.Duration(limited.RetryAfter * float64(time.Second))
+		}
+	}
+	return 0
+}
+
+// unwrapURLError drops the request URL from transport errors.
+func unwrapURLError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
+
+// truncate cuts the text to max runes, marking the cut with an ellipsis.
+func truncate(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max-1]) + "…"
+}
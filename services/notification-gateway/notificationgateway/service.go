@@ -0,0 +1,82 @@
+package notificationgateway
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+)
+
+// Service manages the NotificationGateway lifecycle.
+type Service struct {
+	bus            *eventbus.EventBus
+	gateway        *Gateway
+	reloadInterval time.Duration
+	server         *http.Server
+}
+
+// NewService creates a new NotificationGateway service delivering with the request timeout.
+func NewService(bus *eventbus.EventBus, timeout time.Duration) *Service {
+	return &Service{
+		bus:     bus,
+		gateway: NewGateway(NewSender(timeout)),
+	}
+}
+
+// UseStorage loads channels and routes from bucket/key in MinIO, reloading them every interval.
+func (s *Service) UseStorage(client storage.ObjectStorage, bucket, key string, interval time.Duration) {
+	s.gateway.config.UseStorage(client, bucket, key)
+	s.reloadInterval = interval
+}
+
+// SetRetryPolicy overrides the delivery retry policy; zero fields keep their defaults.
+func (s *Service) SetRetryPolicy(policy RetryPolicy) {
+	s.gateway.dispatcher.SetRetryPolicy(policy)
+}
+
+// UseHTTP enables the admin API with test sends.
+func (s *Service) UseHTTP(port string) {
+	if port == "" {
+		port = DefaultHTTPPort
+	}
+	s.server = &http.Server{
+		Addr:        ":" + port,
+		Handler:     s.routes(),
+		ReadTimeout: 10 * time.Second,
+		// Test sends wait for the deliveries, retries included
+		WriteTimeout: testSendTimeout + 30*time.Second,
+	}
+}
+
+// Run starts the service and blocks until context is cancelled.
+func (s *Service) Run(ctx context.Context) error {
+	go s.gateway.config.Run(ctx, s.reloadInterval)
+	go s.gateway.dispatcher.Run(ctx)
+	if s.server != nil {
+		go s.serveHTTP(ctx)
+	}
+
+	// Narratives come from narrative_output, anomaly alerts from system_events
+	go s.bus.Subscribe(ctx, eventbus.TopicNarrativeOutput, "notification-gateway-narrative-group", s.gateway.HandleEvent)
+	go s.bus.Subscribe(ctx, eventbus.TopicSystemEvents, "notification-gateway-system-group", s.gateway.HandleEvent)
+
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// serveHTTP runs the admin API until ctx is cancelled.
+func (s *Service) serveHTTP(ctx context.Context) {
+	go func() {
+		logging.Infof("NotificationGateway HTTP API listening on %s", s.server.Addr)
+		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logging.Errorf("NotificationGateway HTTP server failed: %v", err)
+		}
+	}()
+	<-ctx.Done()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	s.server.Shutdown(shutdownCtx)
+}
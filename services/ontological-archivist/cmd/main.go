@@ -2,89 +2,48 @@
 package main
 
 import (
-	"context"
-	"log"
 	"net/http"
-	"os"
-	"os/signal"
-	"strings"
-	"syscall"
 	"time"
 
 	"multiverse-core.io/services/ontological-archivist/ontologicalarchivist"
+	"multiverse-core.io/shared/config"
+	"multiverse-core.io/shared/registry"
+	"multiverse-core.io/shared/service"
 
 	"github.com/gorilla/mux"
 )
 
 func main() {
-	// Create service
+	app := service.Setup("ontological-archivist", config.RegistryOptions, []config.Option{
+		{Env: "ONTOLOGICAL_PORT", Default: "8081", Type: config.TypeInt, Positive: true},
+	})
+	env := app.Env
+
 	cfg := ontologicalarchivist.Config{
-		MinioEndpoint:  getEnv("MINIO_ENDPOINT", "minio:9000"),
-		MinioAccessKey: getEnv("MINIO_ACCESS_KEY", "minioadmin"),
-		MinioSecretKey: getEnv("MINIO_SECRET_KEY", "minioadmin"),
-		KafkaBrokers:   getEnvBrokers("KAFKA_BROKERS", []string{"redpanda:9092"}),
+		MinioEndpoint:  env.String("MINIO_ENDPOINT"),
+		MinioAccessKey: env.String("MINIO_ACCESS_KEY"),
+		MinioSecretKey: env.String("MINIO_SECRET_KEY"),
+		KafkaBrokers:   env.List("KAFKA_BROKERS"),
 	}
-	service := ontologicalarchivist.NewService(cfg)
+	archivist := ontologicalarchivist.NewService(cfg)
+	// Schema changes are announced as schema.updated in system_events
+	archivist.UseEventBus(app.Bus())
 
 	// Setup HTTP server
 	r := mux.NewRouter()
-	service.SetupRoutes(r)
-
-	ONTOLOGICAL_PORT := os.Getenv("ONTOLOGICAL_PORT")
-	if ONTOLOGICAL_PORT == "" {
-		ONTOLOGICAL_PORT = "8081"
-	}
+	archivist.SetupRoutes(r)
 
+	port := env.String("ONTOLOGICAL_PORT")
 	server := &http.Server{
-		Addr:         ":" + ONTOLOGICAL_PORT,
+		Addr:         ":" + port,
 		Handler:      r,
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 10 * time.Second,
 	}
 
-	// Handle shutdown
-	_, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	go func() {
-		<-sigChan
-		log.Println("Shutting down OntologicalArchivist...")
+	// Publish endpoint to the service registry
+	announcer := registry.NewAnnouncer(app.Bus(), registry.ServiceArchivist, "http://ontological-archivist:"+port, "schemas")
+	app.Go(announcer.Run)
 
-		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer shutdownCancel()
-		server.Shutdown(shutdownCtx)
-
-		cancel()
-	}()
-
-	log.Println("OntologicalArchivist starting on :" + ONTOLOGICAL_PORT)
-	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		log.Fatal("Server failed:", err)
-	}
-	log.Println("OntologicalArchivist stopped.")
-}
-
-func getEnv(key, fallback string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return fallback
-}
-
-func getEnvBrokers(key string, fallback []string) []string {
-	if value := os.Getenv(key); value != "" {
-		parts := strings.Split(value, ",")
-		brokers := make([]string, 0, len(parts))
-		for _, part := range parts {
-			if trimmed := strings.TrimSpace(part); trimmed != "" {
-				brokers = append(brokers, trimmed)
-			}
-		}
-		if len(brokers) > 0 {
-			return brokers
-		}
-	}
-	return fallback
+	app.Run(service.HTTPServer(server))
 }
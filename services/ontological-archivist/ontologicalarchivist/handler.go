@@ -5,11 +5,15 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
-	"log"
 	"net/http"
+	"strconv"
 	"time"
 
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+
 	"github.com/gorilla/mux"
 )
 
@@ -54,7 +58,7 @@ func (s *Service) handleSaveSchema(w http.ResponseWriter, r *http.Request) {
 	defer cancel()
 
 	if err := s.SaveSchema(ctx, req.SchemaType, req.Name, req.Version, req.Schema); err != nil {
-		log.Printf("Save schema failed: %v", err)
+		logging.Errorf("Save schema failed: %v", err)
 		http.Error(w, "Failed to save schema", http.StatusInternalServerError)
 		return
 	}
@@ -64,7 +68,8 @@ func (s *Service) handleSaveSchema(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(`{"status": "success"}`))
 }
 
-// handleGetSchema handles GET /v1/schemas/{schema_type}/{name}/{version}
+// handleGetSchema handles GET /v1/schemas/{schema_type}/{name}/{version}.
+// The version "latest" resolves to the newest non-deprecated version.
 func (s *Service) handleGetSchema(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	schemaType := vars["schema_type"]
@@ -74,19 +79,248 @@ func (s *Service) handleGetSchema(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	schemaData, err := s.GetSchema(ctx, schemaType, name, version)
+	version, err := s.ResolveSchemaVersion(ctx, schemaType, name, version)
+	var schemaData []byte
+	if err == nil {
+		schemaData, err = s.GetSchema(ctx, schemaType, name, version)
+	}
 	if err != nil {
-		log.Printf("Get schema failed: %v", err)
+		logging.Errorf("Get schema failed: %v", err)
+		if storage.IsUnavailable(err) {
+			http.Error(w, "Schema storage unavailable", http.StatusServiceUnavailable)
+			return
+		}
 		http.Error(w, "Schema not found", http.StatusNotFound)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-Schema-Version", version)
 	w.Write(schemaData)
 }
 
+// publishSchemaRequest is the body of POST /v1/schemas/{schema_type}/{name}/versions.
+type publishSchemaRequest struct {
+	Version string          `json:"version,omitempty"`
+	Schema  json.RawMessage `json:"schema"`
+}
+
+// handlePublishSchema handles POST /v1/schemas/{schema_type}/{name}/versions.
+// Responds 201 with the new version, or 200 with the latest one if the schema is unchanged.
+func (s *Service) handlePublishSchema(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	var req publishSchemaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	published, created, err := s.PublishSchema(ctx, vars["schema_type"], vars["name"], req.Version, req.Schema)
+	if err != nil {
+		logging.Errorf("Publish schema failed: %v", err)
+		switch {
+		case errors.Is(err, ErrInvalidSchema):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, ErrSchemaVersionExists):
+			http.Error(w, err.Error(), http.StatusConflict)
+		case storage.IsUnavailable(err):
+			http.Error(w, "Schema storage unavailable", http.StatusServiceUnavailable)
+		default:
+			http.Error(w, "Failed to publish schema", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if created {
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"schema":  published,
+		"created": created,
+	})
+}
+
+// handleListSchemaVersions handles GET /v1/schemas/{schema_type}/{name}/versions
+func (s *Service) handleListSchemaVersions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	versions, err := s.SchemaVersions(ctx, vars["schema_type"], vars["name"])
+	if err != nil {
+		logging.Errorf("List schema versions failed: %v", err)
+		http.Error(w, "Schema storage unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if len(versions) == 0 {
+		http.Error(w, "Schema not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(map[string]interface{}{"versions": versions})
+}
+
+// handleDiffSchema handles GET /v1/schemas/{schema_type}/{name}/diff?from=...&to=...
+// Without to the latest version is used, without from the version preceding to.
+func (s *Service) handleDiffSchema(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	query := r.URL.Query()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	diff, err := s.DiffSchemas(ctx, vars["schema_type"], vars["name"], query.Get("from"), query.Get("to"))
+	if err != nil {
+		logging.Errorf("Diff schema failed: %v", err)
+		if storage.IsUnavailable(err) {
+			http.Error(w, "Schema storage unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		http.Error(w, "Schema not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(diff)
+}
+
+// handleDeprecateSchema handles POST /v1/schemas/{schema_type}/{name}/{version}/deprecate
+func (s *Service) handleDeprecateSchema(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	// The body with a reason is optional
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	version, err := s.DeprecateSchema(ctx, vars["schema_type"], vars["name"], vars["version"], req.Reason)
+	if err != nil {
+		logging.Errorf("Deprecate schema failed: %v", err)
+		switch {
+		case storage.IsNotFound(err):
+			http.Error(w, "Schema not found", http.StatusNotFound)
+		case storage.IsUnavailable(err):
+			http.Error(w, "Schema storage unavailable", http.StatusServiceUnavailable)
+		default:
+			http.Error(w, "Failed to deprecate schema", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(version)
+}
+
+// handleSaveTemplate handles POST /v1/templates. Each save creates a new template version.
+func (s *Service) handleSaveTemplate(w http.ResponseWriter, r *http.Request) {
+	var req EntityTemplate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	saved, err := s.SaveTemplate(ctx, req)
+	if err != nil {
+		logging.Errorf("Save template failed: %v", err)
+		switch {
+		case errors.Is(err, ErrInvalidTemplate):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case storage.IsUnavailable(err):
+			http.Error(w, "Template storage unavailable", http.StatusServiceUnavailable)
+		default:
+			http.Error(w, "Failed to save template", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(saved)
+}
+
+// handleGetTemplate handles GET /v1/templates/{entity_type}/{name}[/{version}].
+// Without a version the latest one is returned.
+func (s *Service) handleGetTemplate(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	version := 0
+	if raw, ok := vars["version"]; ok {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "Invalid version", http.StatusBadRequest)
+			return
+		}
+		version = parsed
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tmpl, err := s.GetTemplate(ctx, vars["entity_type"], vars["name"], version)
+	if err != nil {
+		logging.Errorf("Get template failed: %v", err)
+		if storage.IsUnavailable(err) {
+			http.Error(w, "Template storage unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		http.Error(w, "Template not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(tmpl)
+}
+
+// handleListTemplates handles GET /v1/templates/{entity_type}
+func (s *Service) handleListTemplates(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	summaries, err := s.ListTemplates(ctx, mux.Vars(r)["entity_type"])
+	if err != nil {
+		logging.Errorf("List templates failed: %v", err)
+		http.Error(w, "Template storage unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(map[string]interface{}{"templates": summaries})
+}
+
 // SetupRoutes sets up HTTP routes.
 func (s *Service) SetupRoutes(r *mux.Router) {
 	r.HandleFunc("/v1/schemas", s.handleSaveSchema).Methods("POST")
+	// Fixed segments are registered before {version} so they are not taken for versions
+	r.HandleFunc("/v1/schemas/{schema_type}/{name}/versions", s.handlePublishSchema).Methods("POST")
+	r.HandleFunc("/v1/schemas/{schema_type}/{name}/versions", s.handleListSchemaVersions).Methods("GET")
+	r.HandleFunc("/v1/schemas/{schema_type}/{name}/diff", s.handleDiffSchema).Methods("GET")
 	r.HandleFunc("/v1/schemas/{schema_type}/{name}/{version}", s.handleGetSchema).Methods("GET")
+	r.HandleFunc("/v1/schemas/{schema_type}/{name}/{version}/deprecate", s.handleDeprecateSchema).Methods("POST")
+	r.HandleFunc("/v1/validate", s.handleValidate).Methods("POST")
+	r.HandleFunc("/v1/templates", s.handleSaveTemplate).Methods("POST")
+	r.HandleFunc("/v1/templates/{entity_type}", s.handleListTemplates).Methods("GET")
+	r.HandleFunc("/v1/templates/{entity_type}/{name}", s.handleGetTemplate).Methods("GET")
+	r.HandleFunc("/v1/templates/{entity_type}/{name}/{version}", s.handleGetTemplate).Methods("GET")
+	r.HandleFunc("/health", s.handleHealth).Methods("GET")
+}
+
+// handleHealth handles GET /health (used by registry discovery).
+func (s *Service) handleHealth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(map[string]string{
+		"status": "healthy",
+		"time":   time.Now().Format(time.RFC3339),
+	})
 }
@@ -0,0 +1,395 @@
+// Package ontologicalarchivist versions ontological schemas.
+package ontologicalarchivist
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	storage "multiverse-core.io/shared/minio"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// schemasBucket holds schemas as {schema_type}/{name}/v{version}.json and deprecation
+// markers as {schema_type}/{name}/deprecated/v{version}.json.
+const schemasBucket = "schemas"
+
+// LatestSchemaVersion resolves to the newest non-deprecated version of a schema.
+const LatestSchemaVersion = "latest"
+
+var (
+	// ErrInvalidSchema is returned when a schema fails validation on publish.
+	ErrInvalidSchema = errors.New("invalid schema")
+	// ErrSchemaVersionExists is returned when an explicit version is already published.
+	ErrSchemaVersionExists = errors.New("schema version already exists")
+)
+
+var schemaVersionPattern = regexp.MustCompile(`^[0-9A-Za-z_-]+(\.[0-9A-Za-z_-]+)*$`)
+
+// reservedSchemaVersions collide with routes under /v1/schemas/{schema_type}/{name}.
+var reservedSchemaVersions = map[string]bool{LatestSchemaVersion: true, "versions": true, "diff": true}
+
+// SchemaVersion describes one stored version of a schema.
+type SchemaVersion struct {
+	SchemaType        string     `json:"schema_type"`
+	Name              string     `json:"name"`
+	Version           string     `json:"version"`
+	CreatedAt         time.Time  `json:"created_at"`
+	Deprecated        bool       `json:"deprecated"`
+	DeprecatedAt      *time.Time `json:"deprecated_at,omitempty"`
+	DeprecationReason string     `json:"deprecation_reason,omitempty"`
+}
+
+// schemaDeprecation is the body of a deprecation marker.
+type schemaDeprecation struct {
+	DeprecatedAt time.Time `json:"deprecated_at"`
+	Reason       string    `json:"reason,omitempty"`
+}
+
+// SchemaChange is one structural difference between two schema versions.
+// Path is a JSON Pointer; From/To hold the removed and added values.
+type SchemaChange struct {
+	Op   string      `json:"op"` // added, removed or changed
+	Path string      `json:"path"`
+	From interface{} `json:"from,omitempty"`
+	To   interface{} `json:"to,omitempty"`
+}
+
+// SchemaDiff lists changes from one schema version to another.
+type SchemaDiff struct {
+	SchemaType string         `json:"schema_type"`
+	Name       string         `json:"name"`
+	From       string         `json:"from"`
+	To         string         `json:"to"`
+	Changes    []SchemaChange `json:"changes"`
+}
+
+// schemaKey builds the object key of a schema version.
+func schemaKey(schemaType, name, version string) string {
+	return schemaType + "/" + name + "/v" + version + ".json"
+}
+
+// deprecationKey builds the object key of a deprecation marker.
+func deprecationKey(schemaType, name, version string) string {
+	return schemaType + "/" + name + "/deprecated/v" + version + ".json"
+}
+
+// compareSchemaVersions orders dotted versions component-wise: numeric components
+// numerically ("1.10" > "1.9"), others lexically; a longer version wins a tie ("1.0.1" > "1.0").
+func compareSchemaVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		switch {
+		case aErr == nil && bErr == nil && an != bn:
+			if an < bn {
+				return -1
+			}
+			return 1
+		case (aErr != nil || bErr != nil) && as[i] != bs[i]:
+			return strings.Compare(as[i], bs[i])
+		}
+	}
+	switch {
+	case len(as) < len(bs):
+		return -1
+	case len(as) > len(bs):
+		return 1
+	}
+	return 0
+}
+
+// nextSchemaVersion bumps the last numeric component of latest ("1.0" -> "1.1").
+func nextSchemaVersion(latest string) string {
+	if latest == "" {
+		return "1.0"
+	}
+	parts := strings.Split(latest, ".")
+	last, err := strconv.Atoi(parts[len(parts)-1])
+	if err != nil {
+		return latest + ".1"
+	}
+	parts[len(parts)-1] = strconv.Itoa(last + 1)
+	return strings.Join(parts, ".")
+}
+
+// SchemaVersions lists stored versions of a schema in ascending order.
+func (s *Service) SchemaVersions(ctx context.Context, schemaType, name string) ([]SchemaVersion, error) {
+	var versions []SchemaVersion
+	deprecated := make(map[string]bool)
+
+	prefix := schemaType + "/" + name + "/"
+	for obj := range s.minio.ListObjects(ctx, schemasBucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if obj.Err != nil {
+			return nil, storage.ClassifyError(obj.Err)
+		}
+		rest := strings.TrimPrefix(obj.Key, prefix)
+		if marker := strings.TrimPrefix(rest, "deprecated/"); marker != rest {
+			if version, ok := parseSchemaFile(marker); ok {
+				deprecated[version] = true
+			}
+			continue
+		}
+		if version, ok := parseSchemaFile(rest); ok {
+			versions = append(versions, SchemaVersion{
+				SchemaType: schemaType,
+				Name:       name,
+				Version:    version,
+				CreatedAt:  obj.LastModified.UTC(),
+			})
+		}
+	}
+
+	for i := range versions {
+		if !deprecated[versions[i].Version] {
+			continue
+		}
+		versions[i].Deprecated = true
+		if marker, err := s.readDeprecation(ctx, schemaType, name, versions[i].Version); err == nil {
+			versions[i].DeprecatedAt = &marker.DeprecatedAt
+			versions[i].DeprecationReason = marker.Reason
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return compareSchemaVersions(versions[i].Version, versions[j].Version) < 0
+	})
+	return versions, nil
+}
+
+// parseSchemaFile extracts the version from v{version}.json.
+func parseSchemaFile(file string) (string, bool) {
+	if strings.Contains(file, "/") || !strings.HasPrefix(file, "v") || !strings.HasSuffix(file, ".json") {
+		return "", false
+	}
+	version := strings.TrimSuffix(strings.TrimPrefix(file, "v"), ".json")
+	return version, version != ""
+}
+
+func (s *Service) readDeprecation(ctx context.Context, schemaType, name, version string) (*schemaDeprecation, error) {
+	obj, err := s.minio.GetObject(ctx, schemasBucket, deprecationKey(schemaType, name, version), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, storage.ClassifyError(err)
+	}
+	defer obj.Close()
+
+	var marker schemaDeprecation
+	if err := json.NewDecoder(obj).Decode(&marker); err != nil {
+		return nil, storage.ClassifyError(err)
+	}
+	return &marker, nil
+}
+
+// ResolveSchemaVersion maps "latest" to the newest non-deprecated version
+// (or the newest one if all are deprecated); other versions are returned as is.
+func (s *Service) ResolveSchemaVersion(ctx context.Context, schemaType, name, version string) (string, error) {
+	if version != LatestSchemaVersion {
+		return version, nil
+	}
+	versions, err := s.SchemaVersions(ctx, schemaType, name)
+	if err != nil {
+		return "", err
+	}
+	if len(versions) == 0 {
+		return "", fmt.Errorf("schema %s/%s: %w", schemaType, name, storage.ErrNotFound)
+	}
+	for i := len(versions) - 1; i >= 0; i-- {
+		if !versions[i].Deprecated {
+			return versions[i].Version, nil
+		}
+	}
+	return versions[len(versions)-1].Version, nil
+}
+
+// PublishSchema stores a new schema version. Without an explicit version the last
+// version is bumped; an explicit version must not exist yet. If the schema is identical
+// to the newest version, nothing is stored and that version is returned with created = false.
+func (s *Service) PublishSchema(ctx context.Context, schemaType, name, version string, schemaData []byte) (*SchemaVersion, bool, error) {
+	if !templateNamePattern.MatchString(schemaType) || !templateNamePattern.MatchString(name) {
+		return nil, false, fmt.Errorf("%w: schema_type and name must match %s", ErrInvalidSchema, templateNamePattern)
+	}
+	if version != "" && (!schemaVersionPattern.MatchString(version) || reservedSchemaVersions[version]) {
+		return nil, false, fmt.Errorf("%w: invalid version %q", ErrInvalidSchema, version)
+	}
+	var schema interface{}
+	if err := json.Unmarshal(schemaData, &schema); err != nil {
+		return nil, false, fmt.Errorf("%w: schema must be valid JSON", ErrInvalidSchema)
+	}
+
+	// Serialize version assignment so concurrent publishes don't overwrite each other
+	s.schemasMu.Lock()
+	defer s.schemasMu.Unlock()
+
+	versions, err := s.SchemaVersions(ctx, schemaType, name)
+	if err != nil {
+		return nil, false, err
+	}
+
+	latest := ""
+	if len(versions) > 0 {
+		newest := versions[len(versions)-1]
+		latest = newest.Version
+
+		previous, err := s.GetSchema(ctx, schemaType, name, latest)
+		if err != nil {
+			return nil, false, err
+		}
+		var previousSchema interface{}
+		if json.Unmarshal(previous, &previousSchema) == nil && reflect.DeepEqual(previousSchema, schema) {
+			return &newest, false, nil
+		}
+	}
+
+	if version == "" {
+		version = nextSchemaVersion(latest)
+	}
+	for _, v := range versions {
+		if v.Version == version {
+			return nil, false, fmt.Errorf("%w: %s/%s v%s", ErrSchemaVersionExists, schemaType, name, version)
+		}
+	}
+
+	if err := s.SaveSchema(ctx, schemaType, name, version, schemaData); err != nil {
+		return nil, false, storage.ClassifyError(err)
+	}
+	return &SchemaVersion{
+		SchemaType: schemaType,
+		Name:       name,
+		Version:    version,
+		CreatedAt:  time.Now().UTC(),
+	}, true, nil
+}
+
+// DeprecateSchema marks a schema version deprecated. Deprecated versions stay readable
+// but are skipped when resolving "latest".
+func (s *Service) DeprecateSchema(ctx context.Context, schemaType, name, version, reason string) (*SchemaVersion, error) {
+	versions, err := s.SchemaVersions(ctx, schemaType, name)
+	if err != nil {
+		return nil, err
+	}
+	var target *SchemaVersion
+	for i := range versions {
+		if versions[i].Version == version {
+			target = &versions[i]
+		}
+	}
+	if target == nil {
+		return nil, fmt.Errorf("schema %s/%s v%s: %w", schemaType, name, version, storage.ErrNotFound)
+	}
+	if target.Deprecated {
+		return target, nil
+	}
+
+	marker := schemaDeprecation{DeprecatedAt: time.Now().UTC(), Reason: reason}
+	data, err := json.Marshal(marker)
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.minio.PutObject(ctx, schemasBucket, deprecationKey(schemaType, name, version),
+		bytes.NewReader(data), int64(len(data)),
+		minio.PutObjectOptions{ContentType: "application/json; charset=utf-8"})
+	if err != nil {
+		return nil, storage.ClassifyError(err)
+	}
+
+	target.Deprecated = true
+	target.DeprecatedAt = &marker.DeprecatedAt
+	target.DeprecationReason = reason
+
+	// Deprecation changes what "latest" resolves to
+	schemaData, _ := s.GetSchema(ctx, schemaType, name, version)
+	s.notifySchemaChange(ctx, schemaType, name, version, schemaData, true)
+	return target, nil
+}
+
+// DiffSchemas compares two versions of a schema. An empty to means the latest version,
+// an empty from means the version preceding to.
+func (s *Service) DiffSchemas(ctx context.Context, schemaType, name, from, to string) (*SchemaDiff, error) {
+	versions, err := s.SchemaVersions(ctx, schemaType, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("schema %s/%s: %w", schemaType, name, storage.ErrNotFound)
+	}
+	if to == "" || to == LatestSchemaVersion {
+		to = versions[len(versions)-1].Version
+	}
+	if from == "" {
+		for i, v := range versions {
+			if v.Version == to && i > 0 {
+				from = versions[i-1].Version
+			}
+		}
+		if from == "" {
+			from = to
+		}
+	}
+
+	docs := make([]interface{}, 2)
+	for i, version := range []string{from, to} {
+		data, err := s.GetSchema(ctx, schemaType, name, version)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &docs[i]); err != nil {
+			return nil, fmt.Errorf("schema %s/%s v%s is not valid JSON: %w", schemaType, name, version, err)
+		}
+	}
+
+	return &SchemaDiff{
+		SchemaType: schemaType,
+		Name:       name,
+		From:       from,
+		To:         to,
+		Changes:    diffJSON("", docs[0], docs[1], []SchemaChange{}),
+	}, nil
+}
+
+// diffJSON appends changes between two decoded JSON documents. Objects are compared
+// key by key; arrays and scalars are compared as whole values.
+func diffJSON(path string, from, to interface{}, changes []SchemaChange) []SchemaChange {
+	fromObj, fromIsObj := from.(map[string]interface{})
+	toObj, toIsObj := to.(map[string]interface{})
+	if !fromIsObj || !toIsObj {
+		if !reflect.DeepEqual(from, to) {
+			changes = append(changes, SchemaChange{Op: "changed", Path: path, From: from, To: to})
+		}
+		return changes
+	}
+
+	keys := make([]string, 0, len(fromObj)+len(toObj))
+	for key := range fromObj {
+		keys = append(keys, key)
+	}
+	for key := range toObj {
+		if _, ok := fromObj[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		child := path + "/" + strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+		fromValue, inFrom := fromObj[key]
+		toValue, inTo := toObj[key]
+		switch {
+		case !inTo:
+			changes = append(changes, SchemaChange{Op: "removed", Path: child, From: fromValue})
+		case !inFrom:
+			changes = append(changes, SchemaChange{Op: "added", Path: child, To: toValue})
+		default:
+			changes = diffJSON(child, fromValue, toValue, changes)
+		}
+	}
+	return changes
+}
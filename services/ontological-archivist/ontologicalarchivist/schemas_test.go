@@ -0,0 +1,64 @@
+package ontologicalarchivist
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestCompareSchemaVersions(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0", "1.0", 0},
+		{"1.0", "1.1", -1},
+		{"1.10", "1.9", 1},
+		{"2.0", "1.99", 1},
+		{"1.0", "1.0.1", -1},
+		{"1.0-beta", "1.0-alpha", 1},
+	}
+	for _, tc := range cases {
+		if got := compareSchemaVersions(tc.a, tc.b); got != tc.want {
+			t.Errorf("compare(%s, %s): expected %d, got %d", tc.a, tc.b, tc.want, got)
+		}
+	}
+}
+
+func TestNextSchemaVersion(t *testing.T) {
+	for latest, want := range map[string]string{"": "1.0", "1.0": "1.1", "1.9": "1.10", "3": "4", "1.0-beta": "1.0-beta.1"} {
+		if got := nextSchemaVersion(latest); got != want {
+			t.Errorf("next(%q): expected %s, got %s", latest, want, got)
+		}
+	}
+}
+
+func TestDiffJSON(t *testing.T) {
+	var from, to interface{}
+	json.Unmarshal([]byte(`{
+		"type": "object",
+		"required": ["id"],
+		"properties": {"name": {"type": "string"}, "age": {"type": "integer"}, "a/b": 1}
+	}`), &from)
+	json.Unmarshal([]byte(`{
+		"type": "object",
+		"required": ["id", "name"],
+		"properties": {"name": {"type": "string", "minLength": 1}, "age": {"type": "number"}, "tags": {"type": "array"}}
+	}`), &to)
+
+	got := diffJSON("", from, to, []SchemaChange{})
+	want := []SchemaChange{
+		{Op: "removed", Path: "/properties/a~1b", From: 1.0},
+		{Op: "changed", Path: "/properties/age/type", From: "integer", To: "number"},
+		{Op: "added", Path: "/properties/name/minLength", To: 1.0},
+		{Op: "added", Path: "/properties/tags", To: map[string]interface{}{"type": "array"}},
+		{Op: "changed", Path: "/required", From: []interface{}{"id"}, To: []interface{}{"id", "name"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected diff:\n got %+v\nwant %+v", got, want)
+	}
+
+	if changes := diffJSON("", from, from, []SchemaChange{}); len(changes) != 0 {
+		t.Errorf("expected no changes for identical schemas, got %+v", changes)
+	}
+}
@@ -4,8 +4,14 @@ package ontologicalarchivist
 import (
 	"context"
 	"log"
+	"sync"
 	"time"
 
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+	"multiverse-core.io/shared/schema"
+
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
 )
@@ -17,9 +23,15 @@ type Config struct {
 	KafkaBrokers   []string
 }
 
-// Service manages ontological schemas in MinIO.
+// Service manages ontological schemas and entity templates in MinIO.
 type Service struct {
 	minio *minio.Client
+
+	templatesMu sync.Mutex // serializes template version assignment
+	schemasMu   sync.Mutex // serializes schema version assignment
+	validators  validatorCache
+
+	bus *eventbus.EventBus // publishes schema.updated; nil disables notifications
 }
 
 // NewService creates a new OntologicalArchivist service.
@@ -36,28 +48,67 @@ func NewService(cfg Config) *Service {
 	// Create schemas bucket
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
-	minioClient.MakeBucket(ctx, "schemas", minio.MakeBucketOptions{})
+	minioClient.MakeBucket(ctx, schemasBucket, minio.MakeBucketOptions{})
+	minioClient.MakeBucket(ctx, templatesBucket, minio.MakeBucketOptions{})
+
+	// Schemas may use the Multiverse formats (entity_id, event_id, ...)
+	schema.RegisterCustomFormats()
 
 	return &Service{minio: minioClient}
 }
 
-// SaveSchema saves a schema to MinIO.
+// UseEventBus enables schema.updated notifications on the system events topic.
+func (s *Service) UseEventBus(bus *eventbus.EventBus) {
+	s.bus = bus
+}
+
+// notifySchemaChange publishes schema.updated so consumers can drop cached schemas.
+// Failures are logged: the schema is already stored and consumers fall back to cache TTLs.
+func (s *Service) notifySchemaChange(ctx context.Context, schemaType, name, version string, schemaData []byte, deprecated bool) {
+	if s.bus == nil {
+		return
+	}
+	change := schema.Change{
+		SchemaType: schemaType,
+		Name:       name,
+		Version:    version,
+		Deprecated: deprecated,
+		UpdatedAt:  time.Now().UTC(),
+	}
+	if schemaData != nil {
+		change.Hash = schema.ContentHash(schemaData)
+	}
+	if err := s.bus.PublishSystemEvent(ctx, schema.NewChangeEvent("ontological-archivist", change)); err != nil {
+		logging.Errorf("Failed to publish schema change %s/%s v%s: %v", schemaType, name, version, err)
+	}
+}
+
+// SaveSchema saves a schema to MinIO, overwriting the version if it exists.
+// Use PublishSchema to add a new version instead.
 func (s *Service) SaveSchema(ctx context.Context, schemaType, name, version string, schemaData []byte) error {
-	key := schemaType + "/" + name + "/v" + version + ".json"
-	_, err := s.minio.PutObject(ctx, "schemas", key,
+	_, err := s.minio.PutObject(ctx, schemasBucket, schemaKey(schemaType, name, version),
 		NewBytesReader(schemaData), int64(len(schemaData)),
 		minio.PutObjectOptions{ContentType: "application/json; charset=utf-8"})
-	return err
+	if err != nil {
+		return err
+	}
+	s.validators.invalidate(schemaType, name, version)
+	s.notifySchemaChange(ctx, schemaType, name, version, schemaData, false)
+	return nil
 }
 
 // GetSchema retrieves a schema from MinIO.
+// Errors wrap storage.ErrNotFound or storage.ErrUnavailable.
 func (s *Service) GetSchema(ctx context.Context, schemaType, name, version string) ([]byte, error) {
-	key := schemaType + "/" + name + "/v" + version + ".json"
-	obj, err := s.minio.GetObject(ctx, "schemas", key, minio.GetObjectOptions{})
+	obj, err := s.minio.GetObject(ctx, schemasBucket, schemaKey(schemaType, name, version), minio.GetObjectOptions{})
 	if err != nil {
-		return nil, err
+		return nil, storage.ClassifyError(err)
 	}
 	defer obj.Close()
 
-	return ReadAll(obj)
+	data, err := ReadAll(obj)
+	if err != nil {
+		return nil, storage.ClassifyError(err)
+	}
+	return data, nil
 }
@@ -0,0 +1,185 @@
+// Package ontologicalarchivist stores versioned entity templates.
+package ontologicalarchivist
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	storage "multiverse-core.io/shared/minio"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// templatesBucket holds entity templates as {entity_type}/{name}/v{version}.json.
+const templatesBucket = "templates"
+
+// ErrInvalidTemplate is returned when a template fails validation on save.
+var ErrInvalidTemplate = errors.New("invalid template")
+
+var templateNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+
+// TemplateVariable describes a placeholder used in a template payload as {{name}}.
+type TemplateVariable struct {
+	Description string      `json:"description,omitempty"`
+	Required    bool        `json:"required,omitempty"`
+	Default     interface{} `json:"default,omitempty"`
+}
+
+// EntityTemplate is a reusable payload for frequently spawned entities of one type.
+// Versions are immutable: every save creates a new version.
+type EntityTemplate struct {
+	EntityType  string                      `json:"entity_type"`
+	Name        string                      `json:"name"`
+	Version     int                         `json:"version"`
+	Description string                      `json:"description,omitempty"`
+	Variables   map[string]TemplateVariable `json:"variables,omitempty"`
+	Payload     map[string]interface{}      `json:"payload"`
+	CreatedAt   time.Time                   `json:"created_at"`
+}
+
+// TemplateSummary lists the versions of one template.
+type TemplateSummary struct {
+	EntityType    string `json:"entity_type"`
+	Name          string `json:"name"`
+	LatestVersion int    `json:"latest_version"`
+	Versions      []int  `json:"versions"`
+}
+
+// validateTemplate checks identifiers that become part of object keys.
+func validateTemplate(tmpl *EntityTemplate) error {
+	if !templateNamePattern.MatchString(tmpl.EntityType) || !templateNamePattern.MatchString(tmpl.Name) {
+		return fmt.Errorf("%w: entity_type and name must match %s", ErrInvalidTemplate, templateNamePattern)
+	}
+	if len(tmpl.Payload) == 0 {
+		return fmt.Errorf("%w: payload must not be empty", ErrInvalidTemplate)
+	}
+	return nil
+}
+
+// templateKey builds the object key of a template version.
+func templateKey(entityType, name string, version int) string {
+	return fmt.Sprintf("%s/%s/v%d.json", entityType, name, version)
+}
+
+// parseTemplateKey extracts name and version from {entity_type}/{name}/v{version}.json.
+func parseTemplateKey(key string) (name string, version int, ok bool) {
+	parts := strings.Split(key, "/")
+	if len(parts) != 3 || !strings.HasPrefix(parts[2], "v") || !strings.HasSuffix(parts[2], ".json") {
+		return "", 0, false
+	}
+	version, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(parts[2], "v"), ".json"))
+	if err != nil || version <= 0 {
+		return "", 0, false
+	}
+	return parts[1], version, true
+}
+
+// templateVersions lists stored versions of a template in ascending order.
+func (s *Service) templateVersions(ctx context.Context, entityType, name string) ([]int, error) {
+	var versions []int
+	prefix := entityType + "/" + name + "/"
+	for obj := range s.minio.ListObjects(ctx, templatesBucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if obj.Err != nil {
+			return nil, storage.ClassifyError(obj.Err)
+		}
+		if _, version, ok := parseTemplateKey(obj.Key); ok {
+			versions = append(versions, version)
+		}
+	}
+	sort.Ints(versions)
+	return versions, nil
+}
+
+// SaveTemplate stores a new version of a template and returns it with the assigned version.
+func (s *Service) SaveTemplate(ctx context.Context, tmpl EntityTemplate) (*EntityTemplate, error) {
+	if err := validateTemplate(&tmpl); err != nil {
+		return nil, err
+	}
+
+	// Serialize version assignment so concurrent saves don't overwrite each other
+	s.templatesMu.Lock()
+	defer s.templatesMu.Unlock()
+
+	versions, err := s.templateVersions(ctx, tmpl.EntityType, tmpl.Name)
+	if err != nil {
+		return nil, err
+	}
+	tmpl.Version = 1
+	if len(versions) > 0 {
+		tmpl.Version = versions[len(versions)-1] + 1
+	}
+	tmpl.CreatedAt = time.Now().UTC()
+
+	data, err := json.Marshal(tmpl)
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.minio.PutObject(ctx, templatesBucket, templateKey(tmpl.EntityType, tmpl.Name, tmpl.Version),
+		bytes.NewReader(data), int64(len(data)),
+		minio.PutObjectOptions{ContentType: "application/json; charset=utf-8"})
+	if err != nil {
+		return nil, storage.ClassifyError(err)
+	}
+	return &tmpl, nil
+}
+
+// GetTemplate returns a template version; version 0 means the latest one.
+// Errors wrap storage.ErrNotFound or storage.ErrUnavailable.
+func (s *Service) GetTemplate(ctx context.Context, entityType, name string, version int) (*EntityTemplate, error) {
+	if version <= 0 {
+		versions, err := s.templateVersions(ctx, entityType, name)
+		if err != nil {
+			return nil, err
+		}
+		if len(versions) == 0 {
+			return nil, fmt.Errorf("template %s/%s: %w", entityType, name, storage.ErrNotFound)
+		}
+		version = versions[len(versions)-1]
+	}
+
+	obj, err := s.minio.GetObject(ctx, templatesBucket, templateKey(entityType, name, version), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, storage.ClassifyError(err)
+	}
+	defer obj.Close()
+
+	var tmpl EntityTemplate
+	if err := json.NewDecoder(obj).Decode(&tmpl); err != nil {
+		return nil, storage.ClassifyError(err)
+	}
+	return &tmpl, nil
+}
+
+// ListTemplates lists templates of an entity type with their versions.
+func (s *Service) ListTemplates(ctx context.Context, entityType string) ([]TemplateSummary, error) {
+	byName := make(map[string][]int)
+	for obj := range s.minio.ListObjects(ctx, templatesBucket, minio.ListObjectsOptions{Prefix: entityType + "/", Recursive: true}) {
+		if obj.Err != nil {
+			return nil, storage.ClassifyError(obj.Err)
+		}
+		if name, version, ok := parseTemplateKey(obj.Key); ok {
+			byName[name] = append(byName[name], version)
+		}
+	}
+
+	summaries := make([]TemplateSummary, 0, len(byName))
+	for name, versions := range byName {
+		sort.Ints(versions)
+		summaries = append(summaries, TemplateSummary{
+			EntityType:    entityType,
+			Name:          name,
+			LatestVersion: versions[len(versions)-1],
+			Versions:      versions,
+		})
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Name < summaries[j].Name })
+	return summaries, nil
+}
@@ -0,0 +1,192 @@
+// Package ontologicalarchivist validates documents against stored schemas.
+package ontologicalarchivist
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+	"multiverse-core.io/shared/schema"
+)
+
+// ErrInvalidValidationRequest is returned when a validation request cannot be served.
+var ErrInvalidValidationRequest = errors.New("invalid validation request")
+
+// ValidationRequest is the body of POST /v1/validate.
+// SchemaPath optionally selects a subschema by JSON Pointer (e.g. "/properties/payload").
+type ValidationRequest struct {
+	SchemaType string                 `json:"schema_type"`
+	Name       string                 `json:"name"`
+	Version    string                 `json:"version,omitempty"`
+	SchemaPath string                 `json:"schema_path,omitempty"`
+	Document   map[string]interface{} `json:"document"`
+}
+
+// ValidationResult lists the violations of a document; the schema version is the resolved one.
+type ValidationResult struct {
+	Valid         bool                `json:"valid"`
+	SchemaVersion string              `json:"schema_version"`
+	Errors        []schema.FieldError `json:"errors"`
+}
+
+// validatorCache keeps compiled validators of schema versions.
+// Versions published through PublishSchema are immutable; SaveSchema overwrites drop the entry.
+type validatorCache struct {
+	mu         sync.Mutex
+	validators map[string]*schema.Validator
+}
+
+func validatorCacheKey(schemaType, name, version, schemaPath string) string {
+	return schemaType + "/" + name + "/" + version + "#" + schemaPath
+}
+
+func (c *validatorCache) get(key string) (*schema.Validator, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	validator, ok := c.validators[key]
+	return validator, ok
+}
+
+func (c *validatorCache) put(key string, validator *schema.Validator) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.validators == nil {
+		c.validators = make(map[string]*schema.Validator)
+	}
+	c.validators[key] = validator
+}
+
+// invalidate drops cached validators of one schema version.
+func (c *validatorCache) invalidate(schemaType, name, version string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	prefix := validatorCacheKey(schemaType, name, version, "")
+	for key := range c.validators {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.validators, key)
+		}
+	}
+}
+
+// selectSubschema returns the part of a schema addressed by a JSON Pointer.
+func selectSubschema(schemaData []byte, pointer string) ([]byte, error) {
+	if pointer == "" || pointer == "/" {
+		return schemaData, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("%w: schema_path must be a JSON Pointer", ErrInvalidValidationRequest)
+	}
+
+	var node interface{}
+	if err := json.Unmarshal(schemaData, &node); err != nil {
+		return nil, err
+	}
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch current := node.(type) {
+		case map[string]interface{}:
+			next, ok := current[token]
+			if !ok {
+				return nil, fmt.Errorf("%w: schema has no %s", ErrInvalidValidationRequest, pointer)
+			}
+			node = next
+		case []interface{}:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(current) {
+				return nil, fmt.Errorf("%w: schema has no %s", ErrInvalidValidationRequest, pointer)
+			}
+			node = current[index]
+		default:
+			return nil, fmt.Errorf("%w: schema has no %s", ErrInvalidValidationRequest, pointer)
+		}
+	}
+	return json.Marshal(node)
+}
+
+// Validate checks a document against a stored schema version ("latest" if empty).
+// Errors wrap storage.ErrNotFound, storage.ErrUnavailable or ErrInvalidValidationRequest.
+func (s *Service) Validate(ctx context.Context, req ValidationRequest) (*ValidationResult, error) {
+	if req.SchemaType == "" || req.Name == "" {
+		return nil, fmt.Errorf("%w: schema_type and name are required", ErrInvalidValidationRequest)
+	}
+	if req.Document == nil {
+		return nil, fmt.Errorf("%w: document must be a JSON object", ErrInvalidValidationRequest)
+	}
+	if req.Version == "" {
+		req.Version = LatestSchemaVersion
+	}
+
+	version, err := s.ResolveSchemaVersion(ctx, req.SchemaType, req.Name, req.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	key := validatorCacheKey(req.SchemaType, req.Name, version, req.SchemaPath)
+	validator, ok := s.validators.get(key)
+	if !ok {
+		schemaData, err := s.GetSchema(ctx, req.SchemaType, req.Name, version)
+		if err != nil {
+			return nil, err
+		}
+		if schemaData, err = selectSubschema(schemaData, req.SchemaPath); err != nil {
+			return nil, err
+		}
+		if validator, err = schema.NewValidator(schemaData); err != nil {
+			return nil, err
+		}
+		s.validators.put(key, validator)
+	}
+
+	violations, err := validator.ValidateFields(req.Document)
+	if err != nil {
+		// The stored schema itself is not a valid JSON Schema
+		return nil, fmt.Errorf("%w: schema %s/%s v%s cannot be loaded: %v", ErrInvalidValidationRequest, req.SchemaType, req.Name, version, err)
+	}
+	if violations == nil {
+		violations = []schema.FieldError{}
+	}
+	return &ValidationResult{
+		Valid:         len(violations) == 0,
+		SchemaVersion: version,
+		Errors:        violations,
+	}, nil
+}
+
+// handleValidate handles POST /v1/validate. Schema violations are reported with 200 and "valid": false.
+func (s *Service) handleValidate(w http.ResponseWriter, r *http.Request) {
+	var req ValidationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := s.Validate(ctx, req)
+	if err != nil {
+		logging.Errorf("Validate failed: %v", err)
+		switch {
+		case errors.Is(err, ErrInvalidValidationRequest):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case storage.IsNotFound(err):
+			http.Error(w, "Schema not found", http.StatusNotFound)
+		case storage.IsUnavailable(err):
+			http.Error(w, "Schema storage unavailable", http.StatusServiceUnavailable)
+		default:
+			http.Error(w, "Failed to validate document", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,51 @@
+package ontologicalarchivist
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"multiverse-core.io/shared/schema"
+)
+
+func TestSelectSubschema(t *testing.T) {
+	full := []byte(`{"type": "object", "properties": {"payload": {"type": "object", "required": ["name"]}, "a/b": {"allOf": [{"type": "string"}]}}}`)
+
+	sub, err := selectSubschema(full, "/properties/payload")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var payload map[string]interface{}
+	json.Unmarshal(sub, &payload)
+	if payload["type"] != "object" || payload["required"] == nil {
+		t.Errorf("expected payload subschema, got %s", sub)
+	}
+
+	if sub, err := selectSubschema(full, "/properties/a~1b/allOf/0"); err != nil || string(sub) != `{"type":"string"}` {
+		t.Errorf("expected escaped pointer with array index to resolve, got %s (%v)", sub, err)
+	}
+	if sub, _ := selectSubschema(full, ""); string(sub) != string(full) {
+		t.Error("empty pointer must select the whole schema")
+	}
+	for _, pointer := range []string{"/properties/missing", "/properties/a~1b/allOf/5", "properties"} {
+		if _, err := selectSubschema(full, pointer); !errors.Is(err, ErrInvalidValidationRequest) {
+			t.Errorf("%s: expected invalid request, got %v", pointer, err)
+		}
+	}
+}
+
+func TestValidatorCacheInvalidate(t *testing.T) {
+	var cache validatorCache
+	validator, _ := schema.NewValidator([]byte(`{}`))
+	cache.put(validatorCacheKey("entity", "npc", "1.0", ""), validator)
+	cache.put(validatorCacheKey("entity", "npc", "1.0", "/properties/payload"), validator)
+	cache.put(validatorCacheKey("entity", "npc", "1.0.1", ""), validator)
+
+	cache.invalidate("entity", "npc", "1.0")
+	if _, ok := cache.get(validatorCacheKey("entity", "npc", "1.0", "/properties/payload")); ok {
+		t.Error("expected every validator of v1.0 dropped")
+	}
+	if _, ok := cache.get(validatorCacheKey("entity", "npc", "1.0.1", "")); !ok {
+		t.Error("validators of other versions must stay cached")
+	}
+}
@@ -2,43 +2,35 @@
 package main
 
 import (
-	"context"
-	"log"
-	"os"
-	"os/signal"
-	"strings"
-	"syscall"
-
-	"multiverse-core.io/shared/eventbus"
 	"multiverse-core.io/services/plan-manager/planmanager"
+	"multiverse-core.io/shared/config"
+	"multiverse-core.io/shared/logging"
+	"multiverse-core.io/shared/service"
+	"multiverse-core.io/shared/spatial"
 )
 
 func main() {
-	// Initialize event bus
-	brokers := strings.Split(os.Getenv("KAFKA_BROKERS"), ",")
-	if len(brokers) == 0 || brokers[0] == "" {
-		brokers = []string{"redpanda:9092"}
-	}
-	bus := eventbus.NewEventBus(brokers)
+	app := service.Setup("plan-manager", []config.Option{
+		{Env: "PLAN_MANAGER_PORT", Default: "8091", Type: config.TypeInt, Positive: true, Usage: "HTTP API port (plan topology)"},
+		{Env: "SEMANTIC_MEMORY_URL", Default: "http://semantic-memory:8080", Type: config.TypeURL, Usage: "semantic memory address (ritual site geometry)"},
+	})
+	env := app.Env
 
-	// Create and run service
-	service := planmanager.NewService(bus)
+	plans := planmanager.NewService(app.Bus())
+	plans.UseHTTP(env.String("PLAN_MANAGER_PORT"))
 
-	// Handle shutdown
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	// Ritual sites are located through the entity geometry in SemanticMemory
+	plans.UseSpatial(spatial.NewSemanticMemoryProvider(env.String("SEMANTIC_MEMORY_URL")))
 
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	go func() {
-		<-sigChan
-		log.Println("Shutting down PlanManager...")
-		cancel()
-	}()
-
-	log.Println("PlanManager starting...")
-	if err := service.Run(ctx); err != nil && err != context.Canceled {
-		log.Fatal("Service failed:", err)
+	// Plan topology persistence and ritual validation against stored entities
+	// (optional: without MinIO the topology lives in memory and rituals are not validated)
+	minioClient, err := app.MinIO()
+	if err != nil {
+		logging.Warnf("MinIO unavailable, plan topology is kept in memory only and rituals are not validated: %v", err)
+	} else {
+		plans.UseTopologyStorage(minioClient)
+		plans.UseEntityStorage(minioClient)
 	}
-	log.Println("PlanManager stopped.")
+
+	app.Run(plans)
 }
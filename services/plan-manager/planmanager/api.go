@@ -0,0 +1,85 @@
+package planmanager
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"multiverse-core.io/shared/logging"
+)
+
+// DefaultHTTPPort is the port of the PlanManager HTTP API.
+const DefaultHTTPPort = "8091"
+
+// routes builds the PlanManager HTTP API.
+func (s *Service) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", s.handleHealth)
+	mux.HandleFunc("GET /v1/topology", s.handleTopology)
+	mux.HandleFunc("GET /v1/topology/worlds/{world_id}", s.handleTopologyWorld)
+	mux.HandleFunc("GET /v1/convergence-zones", s.handleConvergenceZones)
+	return mux
+}
+
+// handleHealth handles GET /health.
+func (s *Service) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"status": "healthy",
+		"time":   time.Now().Format(time.RFC3339),
+	})
+}
+
+// handleTopology handles GET /v1/topology: every world by plan and the ascension edges.
+func (s *Service) handleTopology(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.manager.topology.Graph())
+}
+
+// handleTopologyWorld handles GET /v1/topology/worlds/{world_id}: the world and its ascension edges.
+func (s *Service) handleTopologyWorld(w http.ResponseWriter, r *http.Request) {
+	worldID := r.PathValue("world_id")
+	world, ok := s.manager.topology.World(worldID)
+	if !ok {
+		http.Error(w, "world not found", http.StatusNotFound)
+		return
+	}
+	ascendsTo, ascendsFrom := []string{}, []string{}
+	for _, edge := range s.manager.topology.Graph().Edges {
+		if edge.From == worldID {
+			ascendsTo = append(ascendsTo, edge.To)
+		}
+		if edge.To == worldID {
+			ascendsFrom = append(ascendsFrom, edge.From)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"world":        world,
+		"ascends_to":   ascendsTo,
+		"ascends_from": ascendsFrom,
+	})
+}
+
+// handleConvergenceZones handles GET /v1/convergence-zones: the open zones with participants and expiry.
+func (s *Service) handleConvergenceZones(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"zones": s.manager.zones.List()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// serveHTTP runs the HTTP API until ctx is cancelled.
+func (s *Service) serveHTTP(ctx context.Context) {
+	go func() {
+		logging.Infof("PlanManager HTTP API listening on %s", s.server.Addr)
+		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logging.Errorf("PlanManager HTTP server failed: %v", err)
+		}
+	}()
+	<-ctx.Done()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	s.server.Shutdown(shutdownCtx)
+}
@@ -3,96 +3,106 @@ package planmanager
 
 import (
 	"context"
-	"log"
 
 	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+	"multiverse-core.io/shared/spatial"
 )
 
 // PlanManager manages the hierarchy of plans and ascension routing.
 type PlanManager struct {
-	bus *eventbus.EventBus
+	bus        *eventbus.EventBus
+	topology   *Topology
+	ceremonies *Ceremonies
+	zones      *Zones
+	// entities reads players and rituals stored by EntityManager; nil — rituals are not validated
+	entities storage.ObjectStorage
+	// geometry locates ritual sites; nil — site constraints cannot be met
+	geometry spatial.GeometryProvider
 }
 
 // NewPlanManager creates a new PlanManager.
 func NewPlanManager(bus *eventbus.EventBus) *PlanManager {
-	return &PlanManager{bus: bus}
+	return &PlanManager{bus: bus, topology: NewTopology(), ceremonies: NewCeremonies(), zones: NewZones()}
 }
 
 // HandleWorldEvent processes world events for plan management.
 func (pm *PlanManager) HandleWorldEvent(ev eventbus.Event) {
 	switch ev.Type {
 	case "ascension.attempt":
-		pm.routeAscension(ev)
+		pm.startCeremony(ev)
+	case "trial.completed":
+		pm.completeTrial(ev)
 	case "plan.convergence.requested":
 		pm.activateConvergenceZone(ev)
+	case "plan.convergence.entered":
+		pm.trackParticipant(ev, true)
+	case "plan.convergence.left":
+		pm.trackParticipant(ev, false)
 	case "world.generated":
 		pm.initializeWorldPlan(ev)
+	case "plan.initialized":
+		pm.registerWorldPlan(ev)
 	}
 }
 
-// routeAscension routes an ascension attempt to the appropriate plan.
-func (pm *PlanManager) routeAscension(ev eventbus.Event) {
-	currentPlan, _ := ev.Payload["current_plan"].(float64)
-	playerID, _ := ev.Payload["player_id"].(string)
-
-	if playerID == "" {
-		log.Printf("Ascension attempt missing player_id")
+// routeAscension routes a granted ascension to a world of the next plan from the plan topology.
+func (pm *PlanManager) routeAscension(playerID, worldID string, currentPlan int, ritualID string) {
+	targetPlan := currentPlan + 1
+	targetWorld, ok := pm.topology.Route(worldID, targetPlan)
+	if !ok {
+		unroutableEvent := eventbus.NewEvent(
+			"ascension.unroutable",
+			"plan-manager",
+			worldID,
+			map[string]interface{}{
+				"player_id": playerID,
+				"from_plan": currentPlan,
+				"to_plan":   targetPlan,
+				"reason":    "no_world_on_plan",
+				"ritual_id": ritualID,
+			},
+		)
+		pm.bus.Publish(context.Background(), eventbus.TopicSystemEvents, unroutableEvent)
+		logging.Infof("Ascension for %s not routed: no world on Plan %d", playerID, targetPlan)
 		return
 	}
-
-	targetPlan := int(currentPlan + 1)
-	targetWorld := pm.getTargetWorldForPlan(targetPlan, eventbus.GetWorldIDFromEvent(ev))
+	// The route becomes an ascension edge: later ascensions from the world follow it
+	pm.topology.Connect(worldID, targetWorld)
 
 	routeEvent := eventbus.NewEvent(
 		"ascension.routed",
 		"plan-manager",
-		eventbus.GetWorldIDFromEvent(ev),
+		worldID,
 		map[string]interface{}{
 			"player_id":    playerID,
 			"from_plan":    currentPlan,
 			"to_plan":      targetPlan,
 			"target_world": targetWorld,
-			"ritual_id":    ev.Payload["ritual_id"],
+			"ritual_id":    ritualID,
 		},
 	)
 
 	pm.bus.Publish(context.Background(), eventbus.TopicSystemEvents, routeEvent)
-	log.Printf("Ascension for %s routed from Plan %d to Plan %d (world: %s)",
-		playerID, int(currentPlan), targetPlan, targetWorld)
-}
-
-// activateConvergenceZone activates a convergence zone for plan merging.
-func (pm *PlanManager) activateConvergenceZone(ev eventbus.Event) {
-	planLevel, _ := ev.Payload["plan_level"].(float64)
-	worldID, _ := ev.Payload["world_id"].(string)
-
-	if worldID == "" {
-		log.Printf("Convergence request missing world_id")
-		return
-	}
-
-	zoneEvent := eventbus.NewEvent(
-		"plan.convergence.activated",
-		"plan-manager",
-		worldID,
-		map[string]interface{}{
-			"zone_id":        "convergence-zone-" + worldID,
-			"plan_level":     planLevel,
-			"duration_hours": 24,
-			"participants":   ev.Payload["participants"],
-		},
-	)
-
-	pm.bus.Publish(context.Background(), eventbus.TopicSystemEvents, zoneEvent)
-	log.Printf("Convergence zone activated for %s at Plan %d", worldID, int(planLevel))
+	logging.Infof("Ascension for %s routed from Plan %d to Plan %d (world: %s)",
+		playerID, currentPlan, targetPlan, targetWorld)
 }
 
 // initializeWorldPlan initializes the plan level for a newly generated world.
 func (pm *PlanManager) initializeWorldPlan(ev eventbus.Event) {
-	worldID, _ := ev.Payload["world_id"].(string)
+	worldID := eventbus.GetWorldIDFromEvent(ev)
+	if worldID == "" {
+		worldID, _ = ev.Payload["world_id"].(string)
+	}
 	if worldID == "" {
 		return
 	}
+	// Redelivered events must not move a world already placed on a plan
+	if world, ok := pm.topology.World(worldID); ok {
+		logging.Infof("World %s already initialized at Plan %d", worldID, world.PlanLevel)
+		return
+	}
 
 	// Determine plan level based on world seed or constraints
 	planLevel := 0 // Default to Plan 0 (base worlds)
@@ -106,6 +116,8 @@ func (pm *PlanManager) initializeWorldPlan(ev eventbus.Event) {
 		}
 	}
 
+	pm.topology.Register(worldID, planLevel)
+
 	initEvent := eventbus.NewEvent(
 		"plan.initialized",
 		"plan-manager",
@@ -117,20 +129,39 @@ func (pm *PlanManager) initializeWorldPlan(ev eventbus.Event) {
 	)
 
 	pm.bus.Publish(context.Background(), eventbus.TopicSystemEvents, initEvent)
-	log.Printf("World %s initialized at Plan %d", worldID, planLevel)
+	logging.Infof("World %s initialized at Plan %d", worldID, planLevel)
+}
+
+// registerWorldPlan records a world initialized on a plan in the topology.
+// Optional ascends_to / ascends_from lists add ascension edges to and from the world.
+func (pm *PlanManager) registerWorldPlan(ev eventbus.Event) {
+	worldID, _ := ev.Payload["world_id"].(string)
+	if worldID == "" {
+		worldID = eventbus.GetWorldIDFromEvent(ev)
+	}
+	planLevel, ok := ev.Payload["plan_level"].(float64)
+	if worldID == "" || !ok {
+		logging.Warnf("Plan initialization missing world_id or plan_level")
+		return
+	}
+
+	pm.topology.Register(worldID, int(planLevel))
+	for _, to := range stringList(ev.Payload["ascends_to"]) {
+		pm.topology.Connect(worldID, to)
+	}
+	for _, from := range stringList(ev.Payload["ascends_from"]) {
+		pm.topology.Connect(from, worldID)
+	}
 }
 
-// getTargetWorldForPlan determines the target world for ascension to a plan.
-func (pm *PlanManager) getTargetWorldForPlan(plan int, currentWorld string) string {
-	switch plan {
-	case 1:
-		return "convergence-zone-1"
-	case 2:
-		return "abstract-realm"
-	default:
-		if plan > 2 {
-			return "plan-omega"
+// stringList returns the strings of a payload list.
+func stringList(value interface{}) []string {
+	items, _ := value.([]interface{})
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok && s != "" {
+			result = append(result, s)
 		}
-		return currentWorld // Fallback
 	}
+	return result
 }
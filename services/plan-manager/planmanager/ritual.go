@@ -0,0 +1,399 @@
+package planmanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/entity"
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+	"multiverse-core.io/shared/spatial"
+
+	"github.com/google/uuid"
+)
+
+// DefaultTrialTimeout is how long a ceremony waits for the result of an issued trial.
+const DefaultTrialTimeout = 10 * time.Minute
+
+// Reasons published in ascension.denied.
+const (
+	DenyPlayerUnknown      = "player_unknown"
+	DenyCultivationTooLow  = "cultivation_too_low"
+	DenyMissingArtifacts   = "missing_artifacts"
+	DenyOutsideRitualSite  = "outside_ritual_site"
+	DenyRitualSiteUnknown  = "ritual_site_unknown"
+	DenyCeremonyInProgress = "ceremony_in_progress"
+	DenyTrialFailed        = "trial_failed"
+	DenyTrialTimeout       = "trial_timeout"
+)
+
+// trialKinds are issued in order; a ceremony to a higher plan faces more of them.
+var trialKinds = []string{"heart_demon", "heavenly_tribulation", "dao_comprehension"}
+
+// RitualRequirements are the conditions of an ascension ritual. Ritual entities
+// (ritual_id of ascension.attempt) override the defaults of the target plan.
+type RitualRequirements struct {
+	// MinRealm and MinStage are positions in the player's cultivation progression
+	MinRealm  int      `json:"min_realm"`
+	MinStage  int      `json:"min_stage"`
+	Artifacts []string `json:"required_artifacts"`
+	// SiteID is the entity whose geometry the player must stand in; empty — anywhere
+	SiteID string `json:"site_id"`
+	Trials int    `json:"trials"`
+}
+
+// defaultRequirements asks for the golden core realm of the default progression
+// and one trial per plan level, up to every trial kind.
+func defaultRequirements(targetPlan int) RitualRequirements {
+	trials := targetPlan
+	if trials < 1 {
+		trials = 1
+	}
+	if trials > len(trialKinds) {
+		trials = len(trialKinds)
+	}
+	return RitualRequirements{MinRealm: 2, Trials: trials}
+}
+
+// applyRitual overrides the requirements with the fields present in a ritual entity payload.
+func (r *RitualRequirements) applyRitual(payload map[string]interface{}) {
+	if v, ok := payload["min_realm"].(float64); ok {
+		r.MinRealm = int(v)
+	}
+	if v, ok := payload["min_stage"].(float64); ok {
+		r.MinStage = int(v)
+	}
+	if v, ok := payload["required_artifacts"]; ok {
+		r.Artifacts = stringList(v)
+	}
+	if v, ok := payload["site_id"].(string); ok {
+		r.SiteID = v
+	}
+	if v, ok := payload["trials"].(float64); ok && v >= 1 {
+		r.Trials = min(int(v), len(trialKinds))
+	}
+}
+
+// Ceremony is an ascension ritual in progress: the trials issued to the player one by one.
+type Ceremony struct {
+	ID       string    `json:"ceremony_id"`
+	PlayerID string    `json:"player_id"`
+	WorldID  string    `json:"world_id"`
+	RitualID string    `json:"ritual_id,omitempty"`
+	FromPlan int       `json:"from_plan"`
+	ToPlan   int       `json:"to_plan"`
+	Trials   []string  `json:"trials"`
+	Current  int       `json:"current"` // index of the issued trial
+	Deadline time.Time `json:"deadline"`
+}
+
+// Ceremonies keeps the ceremonies in progress, one per player.
+type Ceremonies struct {
+	now     func() time.Time
+	timeout time.Duration
+
+	mu       sync.Mutex
+	byID     map[string]*Ceremony
+	byPlayer map[string]string
+}
+
+// NewCeremonies creates an empty ceremony registry.
+func NewCeremonies() *Ceremonies {
+	return &Ceremonies{
+		now:      time.Now,
+		timeout:  DefaultTrialTimeout,
+		byID:     make(map[string]*Ceremony),
+		byPlayer: make(map[string]string),
+	}
+}
+
+// start registers a ceremony for the player; false if the player already has one.
+func (c *Ceremonies) start(ceremony *Ceremony) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, busy := c.byPlayer[ceremony.PlayerID]; busy {
+		return false
+	}
+	ceremony.Deadline = c.now().Add(c.timeout)
+	c.byID[ceremony.ID] = ceremony
+	c.byPlayer[ceremony.PlayerID] = ceremony.ID
+	return true
+}
+
+// advance records the result of the current trial. Returns a copy of the ceremony and whether
+// it finished: passing the last trial or failing any finishes and removes it.
+func (c *Ceremonies) advance(ceremonyID string, passed bool) (Ceremony, bool, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ceremony, ok := c.byID[ceremonyID]
+	if !ok {
+		return Ceremony{}, false, false
+	}
+	if passed && ceremony.Current+1 < len(ceremony.Trials) {
+		ceremony.Current++
+		ceremony.Deadline = c.now().Add(c.timeout)
+		return *ceremony, false, true
+	}
+	c.removeLocked(ceremony)
+	return *ceremony, true, true
+}
+
+// expire removes and returns the ceremonies whose trial deadline passed.
+func (c *Ceremonies) expire() []Ceremony {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	var expired []Ceremony
+	for _, ceremony := range c.byID {
+		if now.After(ceremony.Deadline) {
+			expired = append(expired, *ceremony)
+			c.removeLocked(ceremony)
+		}
+	}
+	sort.Slice(expired, func(i, j int) bool { return expired[i].ID < expired[j].ID })
+	return expired
+}
+
+func (c *Ceremonies) removeLocked(ceremony *Ceremony) {
+	delete(c.byID, ceremony.ID)
+	delete(c.byPlayer, ceremony.PlayerID)
+}
+
+// loadEntity reads an entity stored by EntityManager: the world bucket first, then entities-global.
+func loadEntity(client storage.ObjectStorage, worldID, entityID string) (*entity.Entity, error) {
+	for _, bucket := range []string{"entities-" + worldID, "entities-global"} {
+		data, err := client.GetObject(bucket, entityID+".json")
+		if err != nil {
+			if storage.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		var ent entity.Entity
+		if err := json.Unmarshal(data, &ent); err != nil {
+			return nil, fmt.Errorf("decode entity %s: %w", entityID, err)
+		}
+		return &ent, nil
+	}
+	return nil, storage.ErrNotFound
+}
+
+// validateRitual checks the player entity against the requirements and returns the reasons
+// the ritual is denied. site is the geometry of the ritual site; nil if it could not be found.
+func validateRitual(req RitualRequirements, player *entity.Entity, site *spatial.Geometry) []string {
+	var reasons []string
+
+	cultivation, _ := player.Payload["cultivation"].(map[string]interface{})
+	realm, _ := cultivation["realm"].(float64)
+	stage, _ := cultivation["stage"].(float64)
+	if int(realm) < req.MinRealm || (int(realm) == req.MinRealm && int(stage) < req.MinStage) {
+		reasons = append(reasons, DenyCultivationTooLow)
+	}
+
+	for _, artifact := range req.Artifacts {
+		if !listHas(player.Payload["inventory"], artifact) {
+			reasons = append(reasons, DenyMissingArtifacts)
+			break
+		}
+	}
+
+	if req.SiteID != "" {
+		location, _ := player.Payload["location"].(map[string]interface{})
+		x, okX := location["x"].(float64)
+		y, okY := location["y"].(float64)
+		switch {
+		case site == nil:
+			reasons = append(reasons, DenyRitualSiteUnknown)
+		case !okX || !okY || !site.Contains(spatial.Point{X: x, Y: y}):
+			reasons = append(reasons, DenyOutsideRitualSite)
+		}
+	}
+	return reasons
+}
+
+// listHas reports whether a payload list contains id as a string or an object with an id field.
+func listHas(list interface{}, id string) bool {
+	items, _ := list.([]interface{})
+	for _, item := range items {
+		switch v := item.(type) {
+		case string:
+			if v == id {
+				return true
+			}
+		case map[string]interface{}:
+			if v["id"] == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// startCeremony validates an ascension attempt and issues the first trial of its ceremony.
+func (pm *PlanManager) startCeremony(ev eventbus.Event) {
+	currentPlan, hasPlan := ev.Payload["current_plan"].(float64)
+	playerID, _ := ev.Payload["player_id"].(string)
+	ritualID, _ := ev.Payload["ritual_id"].(string)
+
+	if playerID == "" {
+		logging.Warnf("Ascension attempt missing player_id")
+		return
+	}
+
+	worldID := eventbus.GetWorldIDFromEvent(ev)
+	if world, ok := pm.topology.World(worldID); ok && !hasPlan {
+		currentPlan = float64(world.PlanLevel)
+	}
+
+	ceremony := &Ceremony{
+		ID:       "ceremony-" + uuid.New().String()[:8],
+		PlayerID: playerID,
+		WorldID:  worldID,
+		RitualID: ritualID,
+		FromPlan: int(currentPlan),
+		ToPlan:   int(currentPlan) + 1,
+	}
+
+	req, reasons := pm.checkRitual(ceremony)
+	if len(reasons) > 0 {
+		pm.publishCeremonyEvent("ascension.denied", *ceremony, map[string]interface{}{
+			"reasons":      reasons,
+			"requirements": req,
+		})
+		logging.Infof("Ascension of %s denied: %v", playerID, reasons)
+		return
+	}
+
+	ceremony.Trials = trialKinds[:req.Trials]
+	if !pm.ceremonies.start(ceremony) {
+		pm.publishCeremonyEvent("ascension.denied", *ceremony, map[string]interface{}{
+			"reasons": []string{DenyCeremonyInProgress},
+		})
+		return
+	}
+
+	pm.publishCeremonyEvent("ritual.started", *ceremony, map[string]interface{}{
+		"requirements": req,
+	})
+	pm.issueTrial(*ceremony)
+	logging.Infof("Ascension ritual %s of %s started: %d trials", ceremony.ID, playerID, len(ceremony.Trials))
+}
+
+// checkRitual resolves the requirements of the ceremony and validates the player against them.
+// Without entity storage the player cannot be checked and only the requirements are resolved.
+func (pm *PlanManager) checkRitual(ceremony *Ceremony) (RitualRequirements, []string) {
+	req := defaultRequirements(ceremony.ToPlan)
+	if pm.entities == nil {
+		return req, nil
+	}
+
+	if ceremony.RitualID != "" {
+		if ritual, err := loadEntity(pm.entities, ceremony.WorldID, ceremony.RitualID); err == nil {
+			req.applyRitual(ritual.Payload)
+		} else {
+			logging.Infof("Ritual %s not loaded, using plan defaults: %v", ceremony.RitualID, err)
+		}
+	}
+
+	player, err := loadEntity(pm.entities, ceremony.WorldID, ceremony.PlayerID)
+	if err != nil {
+		logging.Infof("Player %s not loaded for ascension: %v", ceremony.PlayerID, err)
+		return req, []string{DenyPlayerUnknown}
+	}
+
+	var site *spatial.Geometry
+	if req.SiteID != "" && pm.geometry != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if site, err = pm.geometry.GetGeometry(ctx, ceremony.WorldID, req.SiteID); err != nil {
+			logging.Warnf("Ritual site %s geometry unavailable: %v", req.SiteID, err)
+			site = nil
+		}
+	}
+	return req, validateRitual(req, player, site)
+}
+
+// issueTrial publishes the current trial of the ceremony.
+func (pm *PlanManager) issueTrial(ceremony Ceremony) {
+	pm.publishCeremonyEvent("trial.issued", ceremony, map[string]interface{}{
+		"trial":       ceremony.Trials[ceremony.Current],
+		"trial_index": ceremony.Current + 1,
+		"trials":      len(ceremony.Trials),
+		"deadline":    ceremony.Deadline.UTC().Format(time.RFC3339),
+	})
+}
+
+// completeTrial advances a ceremony by a trial result: the next trial, ascension.granted
+// after the last one or ascension.denied on failure.
+func (pm *PlanManager) completeTrial(ev eventbus.Event) {
+	ceremonyID, _ := ev.Payload["ceremony_id"].(string)
+	passed, _ := ev.Payload["success"].(bool)
+	if ceremonyID == "" {
+		logging.Warnf("Trial result missing ceremony_id")
+		return
+	}
+
+	ceremony, finished, ok := pm.ceremonies.advance(ceremonyID, passed)
+	switch {
+	case !ok:
+		logging.Infof("Trial result for unknown ceremony %s", ceremonyID)
+	case !passed:
+		pm.publishCeremonyEvent("ascension.denied", ceremony, map[string]interface{}{
+			"reasons": []string{DenyTrialFailed},
+			"trial":   ceremony.Trials[ceremony.Current],
+		})
+	case !finished:
+		pm.issueTrial(ceremony)
+	default:
+		pm.publishCeremonyEvent("ascension.granted", ceremony, nil)
+		pm.routeAscension(ceremony.PlayerID, ceremony.WorldID, ceremony.FromPlan, ceremony.RitualID)
+	}
+}
+
+// expireCeremonies denies the ceremonies whose trial was not completed in time.
+func (pm *PlanManager) expireCeremonies() {
+	for _, ceremony := range pm.ceremonies.expire() {
+		pm.publishCeremonyEvent("ascension.denied", ceremony, map[string]interface{}{
+			"reasons": []string{DenyTrialTimeout},
+			"trial":   ceremony.Trials[ceremony.Current],
+		})
+		logging.Infof("Ascension ritual %s of %s expired", ceremony.ID, ceremony.PlayerID)
+	}
+}
+
+// RunExpiry expires overdue ceremonies and closes elapsed convergence zones every interval
+// until ctx is cancelled.
+func (pm *PlanManager) RunExpiry(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			pm.expireCeremonies()
+			pm.closeExpiredZones()
+		}
+	}
+}
+
+// publishCeremonyEvent publishes a ceremony event with the ceremony fields and extra.
+func (pm *PlanManager) publishCeremonyEvent(eventType string, ceremony Ceremony, extra map[string]interface{}) {
+	payload := map[string]interface{}{
+		"ceremony_id": ceremony.ID,
+		"player_id":   ceremony.PlayerID,
+		"ritual_id":   ceremony.RitualID,
+		"from_plan":   ceremony.FromPlan,
+		"to_plan":     ceremony.ToPlan,
+	}
+	for key, value := range extra {
+		payload[key] = value
+	}
+	event := eventbus.NewEvent(eventType, "plan-manager", ceremony.WorldID, payload)
+	pm.bus.Publish(context.Background(), eventbus.TopicSystemEvents, event)
+}
@@ -0,0 +1,100 @@
+package planmanager
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"multiverse-core.io/shared/entity"
+	"multiverse-core.io/shared/minio/miniotest"
+	"multiverse-core.io/shared/spatial"
+)
+
+func TestValidateRitual(t *testing.T) {
+	site := &spatial.Geometry{Circle: &spatial.Circle{Center: spatial.Point{X: 0, Y: 0}, Radius: 10}}
+	req := RitualRequirements{MinRealm: 2, MinStage: 1, Artifacts: []string{"jade-seal"}, SiteID: "altar-1"}
+
+	ready := entity.NewEntity("player-1", "player", map[string]interface{}{
+		"cultivation": map[string]interface{}{"realm": float64(2), "stage": float64(1)},
+		"inventory":   []interface{}{"sword", map[string]interface{}{"id": "jade-seal"}},
+		"location":    map[string]interface{}{"x": float64(3), "y": float64(4)},
+	})
+	if reasons := validateRitual(req, ready, site); len(reasons) != 0 {
+		t.Fatalf("expected a valid ritual, got %v", reasons)
+	}
+
+	unready := entity.NewEntity("player-2", "player", map[string]interface{}{
+		"cultivation": map[string]interface{}{"realm": float64(2), "stage": float64(0)},
+		"location":    map[string]interface{}{"x": float64(30), "y": float64(4)},
+	})
+	want := []string{DenyCultivationTooLow, DenyMissingArtifacts, DenyOutsideRitualSite}
+	if reasons := validateRitual(req, unready, site); !reflect.DeepEqual(reasons, want) {
+		t.Errorf("expected %v, got %v", want, reasons)
+	}
+
+	if reasons := validateRitual(req, ready, nil); !reflect.DeepEqual(reasons, []string{DenyRitualSiteUnknown}) {
+		t.Errorf("expected an unknown site, got %v", reasons)
+	}
+}
+
+func TestCheckRitualUsesStoredEntities(t *testing.T) {
+	store := miniotest.New()
+	store.PutObject("entities-world-1", "ritual-1.json", bytes.NewReader([]byte(
+		`{"entity_id": "ritual-1", "entity_type": "ritual", "payload": {"min_realm": 1, "required_artifacts": ["jade-seal"], "trials": 5}}`)), 0)
+	store.PutObject("entities-global", "player-1.json", bytes.NewReader([]byte(
+		`{"entity_id": "player-1", "entity_type": "player", "payload": {"cultivation": {"realm": 1, "stage": 0}, "inventory": ["jade-seal"]}}`)), 0)
+
+	pm := NewPlanManager(nil)
+	pm.entities = store
+
+	req, reasons := pm.checkRitual(&Ceremony{PlayerID: "player-1", WorldID: "world-1", RitualID: "ritual-1", ToPlan: 1})
+	if len(reasons) != 0 {
+		t.Fatalf("expected the ritual to pass, got %v", reasons)
+	}
+	if req.MinRealm != 1 || req.Trials != len(trialKinds) {
+		t.Errorf("ritual entity must override the plan defaults, got %+v", req)
+	}
+
+	if _, reasons := pm.checkRitual(&Ceremony{PlayerID: "player-2", WorldID: "world-1", ToPlan: 1}); !reflect.DeepEqual(reasons, []string{DenyPlayerUnknown}) {
+		t.Errorf("expected an unknown player, got %v", reasons)
+	}
+}
+
+func TestCeremonyStages(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	ceremonies := NewCeremonies()
+	ceremonies.now = func() time.Time { return now }
+
+	ceremony := &Ceremony{ID: "c-1", PlayerID: "player-1", Trials: trialKinds[:2]}
+	if !ceremonies.start(ceremony) {
+		t.Fatal("ceremony not started")
+	}
+	if ceremonies.start(&Ceremony{ID: "c-2", PlayerID: "player-1"}) {
+		t.Fatal("a player must not run two ceremonies")
+	}
+
+	if c, finished, ok := ceremonies.advance("c-1", true); !ok || finished || c.Current != 1 {
+		t.Fatalf("expected the second trial, got %+v finished=%v", c, finished)
+	}
+	if _, finished, ok := ceremonies.advance("c-1", true); !ok || !finished {
+		t.Fatal("passing the last trial must finish the ceremony")
+	}
+	if _, _, ok := ceremonies.advance("c-1", true); ok {
+		t.Error("a finished ceremony must be removed")
+	}
+
+	// A failed trial finishes the ceremony; an overdue one expires
+	ceremonies.start(&Ceremony{ID: "c-3", PlayerID: "player-1", Trials: trialKinds})
+	if _, finished, _ := ceremonies.advance("c-3", false); !finished {
+		t.Error("a failed trial must finish the ceremony")
+	}
+	ceremonies.start(&Ceremony{ID: "c-4", PlayerID: "player-2", Trials: trialKinds})
+	if expired := ceremonies.expire(); len(expired) != 0 {
+		t.Fatalf("nothing is overdue yet, got %v", expired)
+	}
+	now = now.Add(DefaultTrialTimeout + time.Second)
+	if expired := ceremonies.expire(); len(expired) != 1 || expired[0].ID != "c-4" {
+		t.Errorf("expected c-4 to expire, got %v", expired)
+	}
+}
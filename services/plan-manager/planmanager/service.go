@@ -3,14 +3,24 @@ package planmanager
 
 import (
 	"context"
+	"net/http"
+	"time"
 
 	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+	"multiverse-core.io/shared/spatial"
 )
 
+// expiryInterval is how often overdue ascension trials and elapsed convergence zones are closed.
+const expiryInterval = 30 * time.Second
+
 // Service manages the PlanManager lifecycle.
 type Service struct {
 	bus     *eventbus.EventBus
 	manager *PlanManager
+	// server serves the plan topology API; nil — disabled
+	server *http.Server
 }
 
 // NewService creates a new PlanManager service.
@@ -21,13 +31,53 @@ func NewService(bus *eventbus.EventBus) *Service {
 	}
 }
 
+// UseTopologyStorage enables persisting the plan topology and open convergence zones to MinIO.
+func (s *Service) UseTopologyStorage(client storage.ObjectStorage) {
+	s.manager.topology.UseStorage(client)
+	s.manager.zones.UseStorage(client)
+}
+
+// UseEntityStorage enables validating ascension rituals against the players and ritual entities in MinIO.
+func (s *Service) UseEntityStorage(client storage.ObjectStorage) {
+	s.manager.entities = client
+}
+
+// UseSpatial locates ritual sites for the location constraints of ascension rituals.
+func (s *Service) UseSpatial(provider spatial.GeometryProvider) {
+	s.manager.geometry = provider
+}
+
+// UseHTTP enables the HTTP API for inspecting the plan topology.
+func (s *Service) UseHTTP(port string) {
+	if port == "" {
+		port = DefaultHTTPPort
+	}
+	s.server = &http.Server{
+		Addr:         ":" + port,
+		Handler:      s.routes(),
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	}
+}
+
 // Run starts the service and blocks until context is cancelled.
 func (s *Service) Run(ctx context.Context) error {
+	if err := s.manager.topology.Load(); err != nil {
+		logging.Errorf("Failed to load plan topology, starting empty: %v", err)
+	}
+	if err := s.manager.zones.Load(); err != nil {
+		logging.Errorf("Failed to load convergence zones, starting empty: %v", err)
+	}
+	if s.server != nil {
+		go s.serveHTTP(ctx)
+	}
+	go s.manager.RunExpiry(ctx, expiryInterval)
+
 	// Subscribe to world_events for ascension and convergence events
-	s.bus.Subscribe(ctx, eventbus.TopicWorldEvents, "plan-manager-group", s.manager.HandleWorldEvent)
+	go s.bus.Subscribe(ctx, eventbus.TopicWorldEvents, "plan-manager-group", s.manager.HandleWorldEvent)
 
 	// Also subscribe to system_events for world generation
-	s.bus.Subscribe(ctx, eventbus.TopicSystemEvents, "plan-manager-group", s.manager.HandleWorldEvent)
+	go s.bus.Subscribe(ctx, eventbus.TopicSystemEvents, "plan-manager-group", s.manager.HandleWorldEvent)
 
 	<-ctx.Done()
 	return ctx.Err()
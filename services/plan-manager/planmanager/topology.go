@@ -0,0 +1,253 @@
+package planmanager
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+)
+
+const (
+	// topologyBucket stores the plan topology as a single document
+	topologyBucket = "plan-topology"
+	topologyObject = "topology.json"
+)
+
+// PlanWorld is a world registered on a plan.
+type PlanWorld struct {
+	WorldID   string    `json:"world_id"`
+	PlanLevel int       `json:"plan_level"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// AscensionEdge leads from a world to the world its cultivators ascend to.
+type AscensionEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// PlanGraph is a snapshot of the plan topology.
+type PlanGraph struct {
+	Worlds []PlanWorld      `json:"worlds"`
+	Edges  []AscensionEdge  `json:"edges"`
+	Plans  map[int][]string `json:"plans"` // plan level → world IDs
+}
+
+// Topology keeps the worlds of every plan and the ascension edges between them,
+// persisting them to MinIO after every change.
+type Topology struct {
+	storage storage.ObjectStorage // nil — topology lives in memory only
+	now     func() time.Time
+
+	mu     sync.RWMutex
+	worlds map[string]*PlanWorld
+	edges  map[string]map[string]bool // from → to
+
+	// saveMu keeps an older snapshot from overwriting a newer one
+	saveMu sync.Mutex
+}
+
+// NewTopology creates an empty in-memory topology; see UseStorage for persistence.
+func NewTopology() *Topology {
+	return &Topology{
+		now:    time.Now,
+		worlds: make(map[string]*PlanWorld),
+		edges:  make(map[string]map[string]bool),
+	}
+}
+
+// UseStorage enables persisting the topology to MinIO.
+func (t *Topology) UseStorage(client storage.ObjectStorage) {
+	t.storage = client
+}
+
+// Load reads the stored topology. Worlds registered before loading finished take precedence.
+func (t *Topology) Load() error {
+	if t.storage == nil {
+		return nil
+	}
+	data, err := t.storage.GetObject(topologyBucket, topologyObject)
+	if err != nil {
+		if storage.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("load plan topology: %w", err)
+	}
+	var graph PlanGraph
+	if err := json.Unmarshal(data, &graph); err != nil {
+		return fmt.Errorf("decode plan topology: %w", err)
+	}
+
+	t.mu.Lock()
+	for _, world := range graph.Worlds {
+		if _, exists := t.worlds[world.WorldID]; !exists && world.WorldID != "" {
+			world := world
+			t.worlds[world.WorldID] = &world
+		}
+	}
+	for _, edge := range graph.Edges {
+		t.addEdgeLocked(edge.From, edge.To)
+	}
+	t.mu.Unlock()
+	logging.Infof("Loaded plan topology: %d worlds, %d edges", len(graph.Worlds), len(graph.Edges))
+	return nil
+}
+
+// Register places a world on a plan, moving it if it was on another one.
+func (t *Topology) Register(worldID string, planLevel int) {
+	t.mu.Lock()
+	world, ok := t.worlds[worldID]
+	if ok && world.PlanLevel == planLevel {
+		t.mu.Unlock()
+		return
+	}
+	if !ok {
+		world = &PlanWorld{WorldID: worldID}
+		t.worlds[worldID] = world
+	}
+	world.PlanLevel = planLevel
+	world.UpdatedAt = t.now().UTC()
+	t.mu.Unlock()
+	t.save()
+}
+
+// Connect adds an ascension edge between two worlds.
+func (t *Topology) Connect(from, to string) {
+	if from == "" || to == "" || from == to {
+		return
+	}
+	t.mu.Lock()
+	added := t.addEdgeLocked(from, to)
+	t.mu.Unlock()
+	if added {
+		t.save()
+	}
+}
+
+// Remove drops a world and its ascension edges.
+func (t *Topology) Remove(worldID string) {
+	t.mu.Lock()
+	_, exists := t.worlds[worldID]
+	delete(t.worlds, worldID)
+	if _, ok := t.edges[worldID]; ok {
+		exists = true
+		delete(t.edges, worldID)
+	}
+	for _, targets := range t.edges {
+		if targets[worldID] {
+			exists = true
+			delete(targets, worldID)
+		}
+	}
+	t.mu.Unlock()
+	if exists {
+		t.save()
+	}
+}
+
+func (t *Topology) addEdgeLocked(from, to string) bool {
+	if t.edges[from][to] {
+		return false
+	}
+	if t.edges[from] == nil {
+		t.edges[from] = make(map[string]bool)
+	}
+	t.edges[from][to] = true
+	return true
+}
+
+// World returns the registered world; false if it is unknown.
+func (t *Topology) World(worldID string) (PlanWorld, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	world, ok := t.worlds[worldID]
+	if !ok {
+		return PlanWorld{}, false
+	}
+	return *world, true
+}
+
+// Route picks the world on the target plan an ascension from the world leads to:
+// an ascension edge of the world first, then the first world of the plan by ID.
+// false when no world is registered on the plan.
+func (t *Topology) Route(fromWorld string, targetPlan int) (string, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var candidates []string
+	for to := range t.edges[fromWorld] {
+		if world, ok := t.worlds[to]; ok && world.PlanLevel == targetPlan {
+			candidates = append(candidates, to)
+		}
+	}
+	if len(candidates) == 0 {
+		for id, world := range t.worlds {
+			if world.PlanLevel == targetPlan {
+				candidates = append(candidates, id)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return "", false
+	}
+	sort.Strings(candidates)
+	return candidates[0], true
+}
+
+// Graph returns a snapshot of the topology sorted by plan level and world ID.
+func (t *Topology) Graph() PlanGraph {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	graph := PlanGraph{
+		Worlds: make([]PlanWorld, 0, len(t.worlds)),
+		Edges:  []AscensionEdge{},
+		Plans:  make(map[int][]string),
+	}
+	for _, world := range t.worlds {
+		graph.Worlds = append(graph.Worlds, *world)
+	}
+	sort.Slice(graph.Worlds, func(i, j int) bool {
+		if graph.Worlds[i].PlanLevel != graph.Worlds[j].PlanLevel {
+			return graph.Worlds[i].PlanLevel < graph.Worlds[j].PlanLevel
+		}
+		return graph.Worlds[i].WorldID < graph.Worlds[j].WorldID
+	})
+	for _, world := range graph.Worlds {
+		graph.Plans[world.PlanLevel] = append(graph.Plans[world.PlanLevel], world.WorldID)
+	}
+	for from, targets := range t.edges {
+		for to := range targets {
+			graph.Edges = append(graph.Edges, AscensionEdge{From: from, To: to})
+		}
+	}
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		if graph.Edges[i].From != graph.Edges[j].From {
+			return graph.Edges[i].From < graph.Edges[j].From
+		}
+		return graph.Edges[i].To < graph.Edges[j].To
+	})
+	return graph
+}
+
+// save writes the whole topology to storage; failures are logged and retried on the next change.
+func (t *Topology) save() {
+	if t.storage == nil {
+		return
+	}
+	t.saveMu.Lock()
+	defer t.saveMu.Unlock()
+	data, err := json.Marshal(t.Graph())
+	if err != nil {
+		logging.Errorf("Failed to encode plan topology: %v", err)
+		return
+	}
+	if err := t.storage.PutObject(topologyBucket, topologyObject, bytes.NewReader(data), int64(len(data))); err != nil {
+		logging.Errorf("Failed to save plan topology: %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package planmanager
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"multiverse-core.io/shared/minio/miniotest"
+)
+
+func TestTopologyRoute(t *testing.T) {
+	topology := NewTopology()
+	if _, ok := topology.Route("world-a", 1); ok {
+		t.Fatal("route without worlds on the plan")
+	}
+
+	topology.Register("world-a", 0)
+	topology.Register("zone-b", 1)
+	topology.Register("zone-c", 1)
+	if target, ok := topology.Route("world-a", 1); !ok || target != "zone-b" {
+		t.Errorf("expected the first world of the plan, got %q", target)
+	}
+
+	// Ascension edges take precedence over other worlds of the plan
+	topology.Connect("world-a", "zone-c")
+	if target, _ := topology.Route("world-a", 1); target != "zone-c" {
+		t.Errorf("expected the connected world, got %q", target)
+	}
+	// Edges to worlds on other plans are ignored
+	topology.Register("zone-c", 2)
+	if target, _ := topology.Route("world-a", 1); target != "zone-b" {
+		t.Errorf("expected zone-b after zone-c moved, got %q", target)
+	}
+}
+
+func TestTopologyPersistence(t *testing.T) {
+	store := miniotest.New()
+	topology := NewTopology()
+	topology.UseStorage(store)
+	topology.Register("world-a", 0)
+	topology.Register("realm-b", 1)
+	topology.Connect("world-a", "realm-b")
+
+	restored := NewTopology()
+	restored.UseStorage(store)
+	if err := restored.Load(); err != nil {
+		t.Fatal(err)
+	}
+	graph := restored.Graph()
+	if len(graph.Worlds) != 2 || len(graph.Edges) != 1 || graph.Edges[0] != (AscensionEdge{From: "world-a", To: "realm-b"}) {
+		t.Fatalf("unexpected restored topology: %+v", graph)
+	}
+	if plans := graph.Plans[1]; len(plans) != 1 || plans[0] != "realm-b" {
+		t.Errorf("unexpected plan 1 worlds: %v", plans)
+	}
+
+	// Nothing stored yet is not an error
+	if err := NewTopology().Load(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTopologyAPI(t *testing.T) {
+	service := NewService(nil)
+	service.manager.topology.Register("world-a", 0)
+	service.manager.topology.Register("realm-b", 1)
+	service.manager.topology.Connect("world-a", "realm-b")
+	server := httptest.NewServer(service.routes())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/v1/topology/worlds/realm-b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var body struct {
+		World       PlanWorld `json:"world"`
+		AscendsFrom []string  `json:"ascends_from"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body.World.PlanLevel != 1 || len(body.AscendsFrom) != 1 || body.AscendsFrom[0] != "world-a" {
+		t.Errorf("unexpected world response: %+v", body)
+	}
+
+	missing, err := http.Get(server.URL + "/v1/topology/worlds/unknown")
+	if err != nil {
+		t.Fatal(err)
+	}
+	missing.Body.Close()
+	if missing.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown world, got %d", missing.StatusCode)
+	}
+}
@@ -0,0 +1,338 @@
+package planmanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+)
+
+const (
+	// DefaultZoneDuration is how long a convergence zone stays open unless the request sets duration_hours.
+	DefaultZoneDuration = 24 * time.Hour
+
+	// zonesObject stores the open convergence zones next to the plan topology
+	zonesObject = "convergence-zones.json"
+
+	// convergenceScopeType is the GM scope type of convergence zones
+	convergenceScopeType = "convergence_zone"
+	// worldScopeType is the GM scope of a world; zone GMs are merged into it on close
+	worldScopeType = "world"
+)
+
+// ConvergenceZone is an open convergence zone of a world.
+type ConvergenceZone struct {
+	ZoneID       string    `json:"zone_id"`
+	WorldID      string    `json:"world_id"`
+	PlanLevel    int       `json:"plan_level"`
+	Participants []string  `json:"participants"`
+	ActivatedAt  time.Time `json:"activated_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+func (z *ConvergenceZone) clone() ConvergenceZone {
+	c := *z
+	c.Participants = append([]string(nil), z.Participants...)
+	return c
+}
+
+// hasParticipant reports whether the player is in the zone.
+func (z *ConvergenceZone) hasParticipant(playerID string) bool {
+	for _, id := range z.Participants {
+		if id == playerID {
+			return true
+		}
+	}
+	return false
+}
+
+// Zones keeps the open convergence zones, persisting them to MinIO after every change
+// so zones still close on time after a restart.
+type Zones struct {
+	storage storage.ObjectStorage // nil — zones live in memory only
+	now     func() time.Time
+
+	mu    sync.Mutex
+	zones map[string]*ConvergenceZone
+
+	// saveMu keeps an older snapshot from overwriting a newer one
+	saveMu sync.Mutex
+}
+
+// NewZones creates an empty in-memory zone registry; see UseStorage for persistence.
+func NewZones() *Zones {
+	return &Zones{now: time.Now, zones: make(map[string]*ConvergenceZone)}
+}
+
+// UseStorage enables persisting open zones to MinIO.
+func (zs *Zones) UseStorage(client storage.ObjectStorage) {
+	zs.storage = client
+}
+
+// Load reads the stored open zones. Zones opened before loading finished take precedence.
+func (zs *Zones) Load() error {
+	if zs.storage == nil {
+		return nil
+	}
+	data, err := zs.storage.GetObject(topologyBucket, zonesObject)
+	if err != nil {
+		if storage.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("load convergence zones: %w", err)
+	}
+	var stored []ConvergenceZone
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return fmt.Errorf("decode convergence zones: %w", err)
+	}
+
+	zs.mu.Lock()
+	for _, zone := range stored {
+		if _, exists := zs.zones[zone.ZoneID]; !exists && zone.ZoneID != "" {
+			zone := zone
+			zs.zones[zone.ZoneID] = &zone
+		}
+	}
+	zs.mu.Unlock()
+	logging.Infof("Loaded %d convergence zones", len(stored))
+	return nil
+}
+
+// open opens a zone for duration. A zone that is already open keeps its expiry and
+// gains the new participants; created is false then.
+func (zs *Zones) open(zoneID, worldID string, planLevel int, participants []string, duration time.Duration) (ConvergenceZone, bool) {
+	zs.mu.Lock()
+	zone, exists := zs.zones[zoneID]
+	if !exists {
+		now := zs.now().UTC()
+		zone = &ConvergenceZone{
+			ZoneID:       zoneID,
+			WorldID:      worldID,
+			PlanLevel:    planLevel,
+			Participants: []string{},
+			ActivatedAt:  now,
+			ExpiresAt:    now.Add(duration),
+		}
+		zs.zones[zoneID] = zone
+	}
+	for _, playerID := range participants {
+		if !zone.hasParticipant(playerID) {
+			zone.Participants = append(zone.Participants, playerID)
+		}
+	}
+	result := zone.clone()
+	zs.mu.Unlock()
+	zs.save()
+	return result, !exists
+}
+
+// enter adds a participant; false if the zone is not open.
+func (zs *Zones) enter(zoneID, playerID string) (ConvergenceZone, bool) {
+	return zs.update(zoneID, func(zone *ConvergenceZone) {
+		if !zone.hasParticipant(playerID) {
+			zone.Participants = append(zone.Participants, playerID)
+		}
+	})
+}
+
+// leave removes a participant; false if the zone is not open.
+func (zs *Zones) leave(zoneID, playerID string) (ConvergenceZone, bool) {
+	return zs.update(zoneID, func(zone *ConvergenceZone) {
+		for i, id := range zone.Participants {
+			if id == playerID {
+				zone.Participants = append(zone.Participants[:i], zone.Participants[i+1:]...)
+				return
+			}
+		}
+	})
+}
+
+func (zs *Zones) update(zoneID string, fn func(zone *ConvergenceZone)) (ConvergenceZone, bool) {
+	zs.mu.Lock()
+	zone, ok := zs.zones[zoneID]
+	if !ok {
+		zs.mu.Unlock()
+		return ConvergenceZone{}, false
+	}
+	fn(zone)
+	result := zone.clone()
+	zs.mu.Unlock()
+	zs.save()
+	return result, true
+}
+
+// expire removes and returns the zones whose duration elapsed.
+func (zs *Zones) expire() []ConvergenceZone {
+	zs.mu.Lock()
+	now := zs.now()
+	var expired []ConvergenceZone
+	for id, zone := range zs.zones {
+		if !now.Before(zone.ExpiresAt) {
+			expired = append(expired, zone.clone())
+			delete(zs.zones, id)
+		}
+	}
+	zs.mu.Unlock()
+	if len(expired) > 0 {
+		zs.save()
+	}
+	sort.Slice(expired, func(i, j int) bool { return expired[i].ZoneID < expired[j].ZoneID })
+	return expired
+}
+
+// List returns the open zones sorted by ID.
+func (zs *Zones) List() []ConvergenceZone {
+	zs.mu.Lock()
+	defer zs.mu.Unlock()
+	zones := make([]ConvergenceZone, 0, len(zs.zones))
+	for _, zone := range zs.zones {
+		zones = append(zones, zone.clone())
+	}
+	sort.Slice(zones, func(i, j int) bool { return zones[i].ZoneID < zones[j].ZoneID })
+	return zones
+}
+
+// save writes the open zones to storage; failures are logged and retried on the next change.
+func (zs *Zones) save() {
+	if zs.storage == nil {
+		return
+	}
+	zs.saveMu.Lock()
+	defer zs.saveMu.Unlock()
+	data, err := json.Marshal(zs.List())
+	if err != nil {
+		logging.Errorf("Failed to encode convergence zones: %v", err)
+		return
+	}
+	if err := zs.storage.PutObject(topologyBucket, zonesObject, bytes.NewReader(data), int64(len(data))); err != nil {
+		logging.Errorf("Failed to save convergence zones: %v", err)
+	}
+}
+
+// activateConvergenceZone opens a convergence zone for plan merging: the zone joins the plan
+// topology and NarrativeOrchestrator gets a zone GM.
+func (pm *PlanManager) activateConvergenceZone(ev eventbus.Event) {
+	planLevel, _ := ev.Payload["plan_level"].(float64)
+	worldID, _ := ev.Payload["world_id"].(string)
+	if worldID == "" {
+		worldID = eventbus.GetWorldIDFromEvent(ev)
+	}
+
+	if worldID == "" {
+		logging.Warnf("Convergence request missing world_id")
+		return
+	}
+
+	duration := DefaultZoneDuration
+	if hours, ok := ev.Payload["duration_hours"].(float64); ok && hours > 0 {
+		duration = time.Duration(hours * float64(time.Hour))
+	}
+
+	zoneID := "convergence-zone-" + worldID
+	zone, created := pm.zones.open(zoneID, worldID, int(planLevel), stringList(ev.Payload["participants"]), duration)
+	if !created {
+		logging.Infof("Convergence zone %s already open until %s, participants: %d",
+			zoneID, zone.ExpiresAt.Format(time.RFC3339), len(zone.Participants))
+		return
+	}
+
+	pm.topology.Register(zoneID, zone.PlanLevel)
+	pm.topology.Connect(worldID, zoneID)
+
+	// NarrativeOrchestrator creates the GM of the zone
+	gmPayload := eventbus.NewEventPayload().
+		WithWorld(worldID).
+		WithScope(zoneID, convergenceScopeType)
+	eventbus.SetNested(gmPayload.GetCustom(), "focus_entities", zone.Participants)
+	gmEvent := eventbus.NewStructuredEvent("gm.created", "plan-manager", worldID, gmPayload)
+	pm.bus.Publish(context.Background(), eventbus.TopicSystemEvents, gmEvent)
+
+	zoneEvent := eventbus.NewEvent(
+		"plan.convergence.activated",
+		"plan-manager",
+		worldID,
+		map[string]interface{}{
+			"zone_id":        zoneID,
+			"plan_level":     zone.PlanLevel,
+			"duration_hours": duration.Hours(),
+			"expires_at":     zone.ExpiresAt.Format(time.RFC3339),
+			"participants":   zone.Participants,
+		},
+	)
+
+	pm.bus.Publish(context.Background(), eventbus.TopicSystemEvents, zoneEvent)
+	logging.Infof("Convergence zone activated for %s at Plan %d until %s", worldID, zone.PlanLevel, zone.ExpiresAt.Format(time.RFC3339))
+}
+
+// trackParticipant records a player entering or leaving an open convergence zone.
+func (pm *PlanManager) trackParticipant(ev eventbus.Event, entered bool) {
+	zoneID, _ := ev.Payload["zone_id"].(string)
+	playerID, _ := ev.Payload["player_id"].(string)
+	if playerID == "" {
+		if entityInfo, ok := ev.GetEntityIDWithFallback(); ok {
+			playerID = entityInfo.ID
+		}
+	}
+	if zoneID == "" || playerID == "" {
+		logging.Warnf("Convergence participant event missing zone_id or player_id")
+		return
+	}
+
+	var zone ConvergenceZone
+	var ok bool
+	if entered {
+		zone, ok = pm.zones.enter(zoneID, playerID)
+	} else {
+		zone, ok = pm.zones.leave(zoneID, playerID)
+	}
+	if !ok {
+		logging.Infof("Convergence zone %s is not open, ignoring %s", zoneID, ev.Type)
+		return
+	}
+	logging.Infof("Convergence zone %s: %s %s, participants: %d", zoneID, playerID, ev.Type, len(zone.Participants))
+}
+
+// closeExpiredZones closes the zones whose duration elapsed: the zone GM is merged into
+// the world GM, the zone leaves the plan topology and plan.convergence.closed is published.
+func (pm *PlanManager) closeExpiredZones() {
+	for _, zone := range pm.zones.expire() {
+		pm.topology.Remove(zone.ZoneID)
+
+		mergePayload := eventbus.NewEventPayload().
+			WithWorld(zone.WorldID).
+			WithScope(zone.WorldID, worldScopeType)
+		eventbus.SetNested(mergePayload.GetCustom(), "source_scope_ids", []string{zone.ZoneID})
+		mergeEvent := eventbus.NewStructuredEvent("gm.merged", "plan-manager", zone.WorldID, mergePayload)
+		pm.bus.Publish(context.Background(), eventbus.TopicSystemEvents, mergeEvent)
+
+		// Without a world GM the merge is skipped and the zone GM is removed here
+		deletePayload := eventbus.NewEventPayload().
+			WithWorld(zone.WorldID).
+			WithScope(zone.ZoneID, convergenceScopeType)
+		deleteEvent := eventbus.NewStructuredEvent("gm.deleted", "plan-manager", zone.WorldID, deletePayload)
+		pm.bus.Publish(context.Background(), eventbus.TopicSystemEvents, deleteEvent)
+
+		closedEvent := eventbus.NewEvent(
+			"plan.convergence.closed",
+			"plan-manager",
+			zone.WorldID,
+			map[string]interface{}{
+				"zone_id":      zone.ZoneID,
+				"plan_level":   zone.PlanLevel,
+				"participants": zone.Participants,
+				"reason":       "expired",
+				"merged_into":  zone.WorldID,
+				"activated_at": zone.ActivatedAt.Format(time.RFC3339),
+			},
+		)
+		pm.bus.Publish(context.Background(), eventbus.TopicSystemEvents, closedEvent)
+		logging.Infof("Convergence zone %s closed after %s", zone.ZoneID, zone.ExpiresAt.Sub(zone.ActivatedAt))
+	}
+}
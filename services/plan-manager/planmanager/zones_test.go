@@ -0,0 +1,74 @@
+package planmanager
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"multiverse-core.io/shared/minio/miniotest"
+)
+
+func TestZoneLifecycle(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := miniotest.New()
+	zones := NewZones()
+	zones.now = func() time.Time { return now }
+	zones.UseStorage(store)
+
+	zone, created := zones.open("zone-a", "world-a", 1, []string{"player-1"}, 2*time.Hour)
+	if !created || zone.ExpiresAt != now.Add(2*time.Hour) {
+		t.Fatalf("unexpected new zone: %+v", zone)
+	}
+	// Reopening keeps the expiry and adds participants
+	now = now.Add(time.Hour)
+	if zone, created = zones.open("zone-a", "world-a", 1, []string{"player-2"}, 2*time.Hour); created || zone.ExpiresAt != now.Add(time.Hour) {
+		t.Fatalf("reopened zone must keep its expiry: %+v", zone)
+	}
+
+	zones.enter("zone-a", "player-3")
+	zones.enter("zone-a", "player-3")
+	zone, _ = zones.leave("zone-a", "player-1")
+	if want := []string{"player-2", "player-3"}; !reflect.DeepEqual(zone.Participants, want) {
+		t.Errorf("expected participants %v, got %v", want, zone.Participants)
+	}
+	if _, ok := zones.enter("zone-b", "player-1"); ok {
+		t.Error("entering a zone that is not open")
+	}
+
+	// Open zones survive a restart
+	restored := NewZones()
+	restored.UseStorage(store)
+	if err := restored.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if list := restored.List(); len(list) != 1 || len(list[0].Participants) != 2 {
+		t.Fatalf("unexpected restored zones: %+v", list)
+	}
+
+	if expired := zones.expire(); len(expired) != 0 {
+		t.Fatalf("zone closed early: %+v", expired)
+	}
+	now = now.Add(time.Hour)
+	if expired := zones.expire(); len(expired) != 1 || expired[0].ZoneID != "zone-a" {
+		t.Fatalf("expected zone-a to close, got %+v", expired)
+	}
+	if len(zones.List()) != 0 {
+		t.Error("a closed zone must be removed")
+	}
+}
+
+func TestTopologyRemove(t *testing.T) {
+	topology := NewTopology()
+	topology.Register("world-a", 0)
+	topology.Register("zone-a", 1)
+	topology.Connect("world-a", "zone-a")
+
+	topology.Remove("zone-a")
+	graph := topology.Graph()
+	if len(graph.Worlds) != 1 || len(graph.Edges) != 0 {
+		t.Errorf("zone and its edges must be removed: %+v", graph)
+	}
+	if _, ok := topology.Route("world-a", 1); ok {
+		t.Error("routing to a removed zone")
+	}
+}
@@ -1,48 +1,35 @@
 package main
 
 import (
-	"log"
-	"os"
-	"os/signal"
-	"syscall"
-	"time"
+	"context"
 
-	"multiverse-core.io/shared/eventbus"
 	"multiverse-core.io/services/reality-monitor/realitymonitor"
+	"multiverse-core.io/shared/config"
+	"multiverse-core.io/shared/logging"
+	"multiverse-core.io/shared/service"
 )
 
 func main() {
-	log.Println("Starting Reality Monitor service...")
-
-	// Initialize event bus
-	kafkaBrokers := []string{"localhost:9092"} // Default Kafka broker address
-	if brokers := os.Getenv("KAFKA_BROKERS"); brokers != "" {
-		// Split brokers by comma if multiple are provided
-		// For simplicity, we'll use the default for now
-	}
-	
-	eventBus := eventbus.NewEventBus(kafkaBrokers)
+	app := service.Setup("reality-monitor", []config.Option{
+		{Env: "CRITIC_INTERVAL_MS", Default: "600000", Type: config.TypeMillis, Positive: true},
+		{Env: "METRICS_INTERVAL_MS", Default: "30000", Type: config.TypeMillis, Positive: true},
+		{Env: "KARMA_HALF_LIFE_MS", Default: "86400000", Type: config.TypeMillis, Positive: true, Usage: "half-life of player karma"},
+		{Env: "REALITY_MONITOR_PORT", Default: "8089", Type: config.TypeInt, Positive: true},
+	}, config.OracleOptions)
+	app.UseOracleAudit()
 
 	// Create Reality Monitor service
-	service := realitymonitor.NewService(eventBus)
-
-	// Start the service
-	if err := service.Start(); err != nil {
-		log.Fatalf("Failed to start Reality Monitor service: %v", err)
+	monitor := realitymonitor.NewService(app.Bus())
+
+	// Remediation policies and metrics history (optional: without MinIO anomalies are only
+	// reported and the history lives in memory)
+	minioClient, err := app.MinIO()
+	if err != nil {
+		logging.Warnf("MinIO unavailable, anomaly remediation is disabled and metrics history is not persisted: %v", err)
+	} else {
+		monitor.UsePolicyStorage(minioClient)
+		monitor.UseHistoryStorage(minioClient)
 	}
 
-	// Setup signal handling for graceful shutdown
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	<-sigChan
-
-	log.Println("Shutting down Reality Monitor service...")
-	
-	// Stop the service
-	service.Stop()
-	
-	// Give some time for graceful shutdown
-	time.Sleep(1 * time.Second)
-	
-	log.Println("Reality Monitor service stopped")
-}
\ No newline at end of file
+	app.Run(service.Background(func(context.Context) error { return monitor.Start() }, monitor.Stop))
+}
@@ -5,4 +5,5 @@ go 1.24
 require (
 	github.com/segmentio/kafka-go v0.4.49
 	github.com/google/uuid v1.6.0
+	github.com/gorilla/mux v1.8.1
 )
@@ -0,0 +1,154 @@
+package realitymonitor
+
+import (
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+)
+
+// defaultMetricsPeriod is how often metrics are computed from the event stream and checked for anomalies
+const defaultMetricsPeriod = 30 * time.Second
+
+// Event types counted by the aggregator
+const (
+	EventViolationDetected = "violation.detected"
+	EventNarrativeGenerate = "narrative.generate"
+	EventEntityCreated     = "entity.created"
+	EventEntityDeleted     = "entity.deleted"
+)
+
+// WorldStats are the metrics of a world computed from its events over one interval
+type WorldStats struct {
+	EventRate        float64 // events per second
+	ViolationRatio   float64 // violations per player action, 0..1
+	NarrativeLatency float64 // mean seconds from a player action to the narrative answering it
+	EntityChurn      float64 // entities created and deleted per minute
+}
+
+// worldWindow counts the events of a world since the last flush
+type worldWindow struct {
+	events        int
+	playerActions int
+	violations    int
+	narratives    int
+	latency       time.Duration
+	churn         int
+	// pendingSince is the oldest player action not yet answered by a narrative
+	pendingSince time.Time
+}
+
+// aggregator derives world metrics from the raw event stream
+type aggregator struct {
+	mu        sync.Mutex
+	now       func() time.Time
+	lastFlush time.Time
+	worlds    map[string]*worldWindow
+}
+
+func newAggregator() *aggregator {
+	return &aggregator{
+		now:       time.Now,
+		lastFlush: time.Now(),
+		worlds:    make(map[string]*worldWindow),
+	}
+}
+
+// observe counts an event received from topic; events without a world are ignored.
+func (a *aggregator) observe(topic string, event eventbus.Event) {
+	worldID := eventbus.GetWorldIDFromEvent(event)
+	if worldID == "" {
+		return
+	}
+	at := event.Timestamp
+	if at.IsZero() {
+		at = a.now()
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	window, ok := a.worlds[worldID]
+	if !ok {
+		window = &worldWindow{}
+		a.worlds[worldID] = window
+	}
+	window.events++
+
+	if topic == eventbus.TopicPlayerEvents {
+		window.playerActions++
+		if window.pendingSince.IsZero() {
+			window.pendingSince = at
+		}
+	}
+
+	switch event.Type {
+	case EventViolationDetected:
+		window.violations++
+	case EventNarrativeGenerate:
+		if !window.pendingSince.IsZero() {
+			if latency := at.Sub(window.pendingSince); latency > 0 {
+				window.latency += latency
+			}
+			window.narratives++
+			window.pendingSince = time.Time{}
+		}
+	case EventEntityCreated, EventEntityDeleted:
+		window.churn++
+	}
+}
+
+// flush returns the stats of every world seen so far for the interval since the previous flush
+// and starts a new interval. Unanswered player actions carry over.
+func (a *aggregator) flush() map[string]WorldStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.now()
+	elapsed := now.Sub(a.lastFlush).Seconds()
+	a.lastFlush = now
+	if elapsed <= 0 {
+		elapsed = defaultMetricsPeriod.Seconds()
+	}
+
+	stats := make(map[string]WorldStats, len(a.worlds))
+	for worldID, window := range a.worlds {
+		var s WorldStats
+		s.EventRate = float64(window.events) / elapsed
+		s.EntityChurn = float64(window.churn) / elapsed * 60
+		if window.playerActions > 0 {
+			s.ViolationRatio = float64(window.violations) / float64(window.playerActions)
+			if s.ViolationRatio > 1 {
+				s.ViolationRatio = 1
+			}
+		}
+		if window.narratives > 0 {
+			s.NarrativeLatency = window.latency.Seconds() / float64(window.narratives)
+		}
+		stats[worldID] = s
+
+		a.worlds[worldID] = &worldWindow{pendingSince: window.pendingSince}
+	}
+	return stats
+}
+
+// applyStats writes the computed stats into the world metrics, creating worlds seen for the first time
+func (s *Service) applyStats(stats map[string]WorldStats) {
+	now := time.Now()
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+	for worldID, computed := range stats {
+		metrics, exists := s.state.Metrics[worldID]
+		if !exists {
+			metrics = &WorldMetrics{WorldID: worldID}
+			if entropy, ok := s.karma.entropy(worldID); ok {
+				metrics.KarmaEntropy = entropy
+			}
+			s.state.Metrics[worldID] = metrics
+		}
+		metrics.EventRate = computed.EventRate
+		metrics.ViolationRatio = computed.ViolationRatio
+		metrics.NarrativeLatency = computed.NarrativeLatency
+		metrics.EntityChurn = computed.EntityChurn
+		metrics.LastUpdated = now
+	}
+}
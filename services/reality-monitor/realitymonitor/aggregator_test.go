@@ -0,0 +1,79 @@
+package realitymonitor
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+)
+
+func TestAggregatorStats(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+	agg := newAggregator()
+	agg.now = func() time.Time { return now }
+	agg.lastFlush = start
+
+	event := func(eventType string, at time.Duration) eventbus.Event {
+		ev := eventbus.NewEvent(eventType, "test", "w1", nil)
+		ev.Timestamp = start.Add(at)
+		return ev
+	}
+	agg.observe(eventbus.TopicPlayerEvents, event("player.moved", 0))
+	agg.observe(eventbus.TopicPlayerEvents, event("player.attacked", time.Second))
+	agg.observe(eventbus.TopicWorldEvents, event(EventViolationDetected, 2*time.Second))
+	agg.observe(eventbus.TopicNarrativeOutput, event(EventNarrativeGenerate, 4*time.Second))
+	agg.observe(eventbus.TopicPlayerEvents, event("player.moved", 5*time.Second))
+	agg.observe(eventbus.TopicWorldEvents, event(EventEntityCreated, 6*time.Second))
+	agg.observe(eventbus.TopicSystemEvents, eventbus.NewEvent("service.started", "test", "", nil))
+
+	now = start.Add(10 * time.Second)
+	stats := agg.flush()
+	s, ok := stats["w1"]
+	if !ok || len(stats) != 1 {
+		t.Fatalf("expected stats for w1 only, got %v", stats)
+	}
+	if math.Abs(s.EventRate-0.6) > 1e-9 {
+		t.Errorf("event rate = %v", s.EventRate)
+	}
+	if math.Abs(s.ViolationRatio-1.0/3) > 1e-9 {
+		t.Errorf("violation ratio = %v", s.ViolationRatio)
+	}
+	if s.NarrativeLatency != 4 {
+		t.Errorf("narrative latency = %v", s.NarrativeLatency)
+	}
+	if math.Abs(s.EntityChurn-6) > 1e-9 {
+		t.Errorf("entity churn = %v", s.EntityChurn)
+	}
+
+	// The unanswered action carries over into the next interval
+	agg.observe(eventbus.TopicNarrativeOutput, event(EventNarrativeGenerate, 12*time.Second))
+	now = start.Add(20 * time.Second)
+	s = agg.flush()["w1"]
+	if s.NarrativeLatency != 7 || s.ViolationRatio != 0 || math.Abs(s.EventRate-0.1) > 1e-9 {
+		t.Errorf("unexpected second interval %+v", s)
+	}
+}
+
+func TestApplyStatsKeepsReportedMetrics(t *testing.T) {
+	s := &Service{
+		state: &State{Metrics: map[string]*WorldMetrics{}, AnomalyCounts: map[string]int{}},
+		karma: newKarmaEngine(0),
+	}
+	s.applyStats(map[string]WorldStats{"w1": {EventRate: 2}})
+	if metrics := s.state.Metrics["w1"]; metrics == nil || metrics.EventRate != 2 {
+		t.Fatalf("computed world not created: %+v", metrics)
+	}
+	// A world known only from the event stream has no spatial or resonance readings to judge
+	if s.isAnomaly(s.state.Metrics["w1"]) {
+		t.Errorf("unexpected anomaly %q", s.state.Metrics["w1"].AnomalyType)
+	}
+
+	s.handleWorldMetricsEvent(eventbus.NewEvent("world.metrics.updated", "test", "w1", map[string]any{
+		"WorldID": "w1", "SpatialIntegrity": 0.5, "CoreResonance": 0.6,
+	}))
+	if metrics := s.state.Metrics["w1"]; metrics.EventRate != 2 || metrics.SpatialIntegrity != 0.5 {
+		t.Errorf("reported metrics must keep computed ones: %+v", metrics)
+	}
+}
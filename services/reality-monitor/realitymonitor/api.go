@@ -0,0 +1,132 @@
+package realitymonitor
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"multiverse-core.io/shared/logging"
+
+	"github.com/gorilla/mux"
+)
+
+// NewRouter creates the admin HTTP API of the Reality Monitor
+func (s *Service) NewRouter() *mux.Router {
+	r := mux.NewRouter()
+	r.HandleFunc("/health", s.handleHealth).Methods("GET")
+	r.HandleFunc("/metrics", s.handleMetrics).Methods("GET")
+	r.HandleFunc("/v1/worlds/{id}/metrics", s.handleWorldMetrics).Methods("GET")
+	r.HandleFunc("/v1/worlds/{id}/metrics/history", s.handleWorldMetricsHistory).Methods("GET")
+	r.HandleFunc("/v1/worlds/{id}/karma", s.handleWorldKarma).Methods("GET")
+	r.HandleFunc("/v1/admin/inconsistencies", s.handleListInconsistencies).Methods("GET")
+	r.HandleFunc("/v1/admin/inconsistencies/{id}", s.handleGetInconsistency).Methods("GET")
+	r.HandleFunc("/v1/admin/inconsistencies/{id}/resolve", s.handleResolveInconsistency).Methods("POST")
+	r.HandleFunc("/v1/admin/audits/{world_id}", s.handleRunAudit).Methods("POST")
+	return r
+}
+
+// handleHealth reports service liveness
+func (s *Service) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleListInconsistencies handles GET /v1/admin/inconsistencies?world_id=&status=&limit=
+func (s *Service) handleListInconsistencies(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit := 100
+	if raw := query.Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid limit"})
+			return
+		}
+		limit = parsed
+	}
+
+	reports := s.critic.Reports().List(query.Get("world_id"), query.Get("status"), limit)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"inconsistencies": reports,
+		"count":           len(reports),
+	})
+}
+
+// handleGetInconsistency handles GET /v1/admin/inconsistencies/{id}
+func (s *Service) handleGetInconsistency(w http.ResponseWriter, r *http.Request) {
+	report, exists := s.critic.Reports().Get(mux.Vars(r)["id"])
+	if !exists {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "report not found"})
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// handleResolveInconsistency handles POST /v1/admin/inconsistencies/{id}/resolve
+// with body {"status": "resolved|dismissed", "resolution": "..."}
+func (s *Service) handleResolveInconsistency(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Status     string `json:"status"`
+		Resolution string `json:"resolution"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	if req.Status == "" {
+		req.Status = ReportStatusResolved
+	}
+	if req.Status != ReportStatusResolved && req.Status != ReportStatusDismissed {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "status must be resolved or dismissed"})
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	if _, exists := s.critic.Reports().Get(id); !exists {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "report not found"})
+		return
+	}
+
+	report, err := s.critic.Resolve(r.Context(), id, req.Status, req.Resolution)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// handleRunAudit handles POST /v1/admin/audits/{world_id} — runs a consistency audit immediately
+func (s *Service) handleRunAudit(w http.ResponseWriter, r *http.Request) {
+	worldID := mux.Vars(r)["world_id"]
+
+	reports, err := s.critic.Audit(r.Context(), worldID)
+	if err != nil {
+		logging.Errorf("Manual audit for world %s failed: %v", worldID, err)
+		status := http.StatusBadGateway
+		switch {
+		case errors.Is(err, ErrNoSample):
+			status = http.StatusNotFound
+		case errors.Is(err, ErrOracleNotConfigured):
+			status = http.StatusServiceUnavailable
+		}
+		writeJSON(w, status, map[string]string{"error": err.Error()})
+		return
+	}
+	if reports == nil {
+		reports = []*InconsistencyReport{}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"world_id":        worldID,
+		"inconsistencies": reports,
+		"count":           len(reports),
+	})
+}
+
+// writeJSON writes a JSON response with the given status
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		logging.Errorf("Failed to encode response: %v", err)
+	}
+}
@@ -0,0 +1,527 @@
+package realitymonitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+
+	"github.com/google/uuid"
+)
+
+// Limits of the per-world sample kept for consistency audits
+const (
+	maxCanonFacts      = 50
+	maxNarratives      = 30
+	maxEntityStates    = 100
+	auditNarratives    = 20
+	auditEntityStates  = 30
+	maxStoredReports   = 1000
+	defaultAuditPeriod = 10 * time.Minute
+)
+
+// Report statuses
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusResolved  = "resolved"
+	ReportStatusDismissed = "dismissed"
+)
+
+// Inconsistency event types published to system_events
+const (
+	EventInconsistencyDetected = "reality.inconsistency.detected"
+	EventInconsistencyResolved = "reality.inconsistency.resolved"
+)
+
+// Audit errors
+var (
+	ErrOracleNotConfigured = errors.New("oracle client not configured")
+	ErrNoSample            = errors.New("no data sampled for world")
+)
+
+// CriticOracle is the subset of the Oracle client used by the critic
+type CriticOracle interface {
+	CallStructuredJSON(ctx context.Context, systemPrompt, userPrompt string) (string, error)
+}
+
+// InconsistencyReport describes a contradiction or rule violation found by the critic
+type InconsistencyReport struct {
+	ID           string    `json:"id"`
+	WorldID      string    `json:"world_id"`
+	Type         string    `json:"type"`     // contradiction | rule_violation
+	Severity     string    `json:"severity"` // low | medium | high
+	Description  string    `json:"description"`
+	Evidence     []string  `json:"evidence,omitempty"`
+	EntityIDs    []string  `json:"entity_ids,omitempty"`
+	SuggestedFix string    `json:"suggested_fix,omitempty"`
+	Status       string    `json:"status"`
+	Resolution   string    `json:"resolution,omitempty"`
+	DetectedAt   time.Time `json:"detected_at"`
+	ResolvedAt   time.Time `json:"resolved_at,omitempty"`
+}
+
+// narrativeSample is a recent narrative text of a world
+type narrativeSample struct {
+	EventID   string
+	Timestamp time.Time
+	Text      string
+}
+
+// entityState is the latest known state of an entity
+type entityState struct {
+	EntityID  string
+	UpdatedAt time.Time
+	State     map[string]interface{}
+}
+
+// worldSample accumulates what the critic knows about a world between audits
+type worldSample struct {
+	canon       []string
+	narratives  []narrativeSample
+	entities    map[string]*entityState
+	lastEventAt time.Time
+	lastAuditAt time.Time
+}
+
+// Critic samples canon facts, narratives and entity states of each world and
+// periodically asks the Oracle to find contradictions between them
+type Critic struct {
+	oracle   CriticOracle
+	bus      *eventbus.EventBus
+	interval time.Duration
+
+	mu      sync.Mutex
+	worlds  map[string]*worldSample
+	reports *ReportStore
+}
+
+// NewCritic creates a critic. A nil oracle disables audits but keeps sampling.
+func NewCritic(oracle CriticOracle, bus *eventbus.EventBus, interval time.Duration) *Critic {
+	if interval <= 0 {
+		interval = defaultAuditPeriod
+	}
+	return &Critic{
+		oracle:   oracle,
+		bus:      bus,
+		interval: interval,
+		worlds:   make(map[string]*worldSample),
+		reports:  NewReportStore(maxStoredReports),
+	}
+}
+
+// Reports returns the inconsistency report store
+func (c *Critic) Reports() *ReportStore {
+	return c.reports
+}
+
+// Observe records an event into the sample of its world
+func (c *Critic) Observe(event eventbus.Event) {
+	worldID := eventbus.GetWorldIDFromEvent(event)
+	if worldID == "" {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	sample := c.worlds[worldID]
+	if sample == nil {
+		sample = &worldSample{entities: make(map[string]*entityState)}
+		c.worlds[worldID] = sample
+	}
+
+	for _, fact := range canonFacts(event) {
+		sample.addCanon(fact)
+	}
+
+	if strings.HasPrefix(event.Type, "narrative.") || strings.HasPrefix(event.Type, "gm.narrative") {
+		if text := payloadText(event.Payload, "narrative", "description"); text != "" {
+			sample.narratives = append(sample.narratives, narrativeSample{EventID: event.ID, Timestamp: event.Timestamp, Text: text})
+			if len(sample.narratives) > maxNarratives {
+				sample.narratives = sample.narratives[len(sample.narratives)-maxNarratives:]
+			}
+			sample.lastEventAt = event.Timestamp
+		}
+	}
+
+	for entityID, state := range entityStates(event) {
+		sample.entities[entityID] = &entityState{EntityID: entityID, UpdatedAt: event.Timestamp, State: state}
+		sample.lastEventAt = event.Timestamp
+	}
+	sample.trimEntities()
+}
+
+// Run audits worlds with new activity every interval until ctx is cancelled
+func (c *Critic) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, worldID := range c.pendingWorlds() {
+				if _, err := c.Audit(ctx, worldID); err != nil {
+					logging.Errorf("Consistency audit for world %s failed: %v", worldID, err)
+				}
+			}
+		}
+	}
+}
+
+// pendingWorlds returns worlds that received events since their last audit
+func (c *Critic) pendingWorlds() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var worlds []string
+	for worldID, sample := range c.worlds {
+		if sample.lastEventAt.After(sample.lastAuditAt) {
+			worlds = append(worlds, worldID)
+		}
+	}
+	return worlds
+}
+
+// Audit asks the Oracle to check the world sample and files the reported inconsistencies
+func (c *Critic) Audit(ctx context.Context, worldID string) ([]*InconsistencyReport, error) {
+	if c.oracle == nil {
+		return nil, ErrOracleNotConfigured
+	}
+
+	systemPrompt, userPrompt, ok := c.buildPrompt(worldID)
+	if !ok {
+		return nil, fmt.Errorf("%w %s", ErrNoSample, worldID)
+	}
+
+	response, err := c.oracle.CallStructuredJSON(ctx, systemPrompt, userPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("oracle call failed: %w", err)
+	}
+
+	reports, err := parseCriticResponse(worldID, response)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, report := range reports {
+		c.reports.Add(report)
+		c.publish(ctx, EventInconsistencyDetected, report)
+	}
+	logging.Infof("Consistency audit for world %s found %d inconsistencies", worldID, len(reports))
+	return reports, nil
+}
+
+// Resolve closes a report and notifies other services about the reconciliation
+func (c *Critic) Resolve(ctx context.Context, reportID, status, resolution string) (*InconsistencyReport, error) {
+	report, err := c.reports.Resolve(reportID, status, resolution)
+	if err != nil {
+		return nil, err
+	}
+	c.publish(ctx, EventInconsistencyResolved, report)
+	return report, nil
+}
+
+// buildPrompt assembles the critic prompt from the world sample and marks the world as audited
+func (c *Critic) buildPrompt(worldID string) (string, string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	sample, exists := c.worlds[worldID]
+	if !exists || (len(sample.canon) == 0 && len(sample.narratives) == 0 && len(sample.entities) == 0) {
+		return "", "", false
+	}
+	sample.lastAuditAt = time.Now()
+
+	var user strings.Builder
+	fmt.Fprintf(&user, "<world id=\"%s\">\n", worldID)
+
+	user.WriteString("<canon>\n")
+	for _, fact := range sample.canon {
+		fmt.Fprintf(&user, "- %s\n", fact)
+	}
+	user.WriteString("</canon>\n")
+
+	user.WriteString("<narratives>\n")
+	narratives := sample.narratives
+	if len(narratives) > auditNarratives {
+		narratives = narratives[len(narratives)-auditNarratives:]
+	}
+	for _, n := range narratives {
+		fmt.Fprintf(&user, "- [%s] %s\n", n.EventID, n.Text)
+	}
+	user.WriteString("</narratives>\n")
+
+	user.WriteString("<entities>\n")
+	for _, state := range sample.recentEntities(auditEntityStates) {
+		data, _ := json.Marshal(state.State)
+		fmt.Fprintf(&user, "- %s: %s\n", state.EntityID, data)
+	}
+	user.WriteString("</entities>\n</world>\n")
+
+	systemPrompt := `Ты — критик согласованности игрового мира. Сравни канон мира, недавние повествования и состояния сущностей.
+Найди противоречия (факты, несовместимые друг с другом) и нарушения правил канона.
+Не сообщай о мелких стилистических расхождениях. Не выдумывай факты.
+Ответь JSON: {"inconsistencies": [{"type": "contradiction|rule_violation", "severity": "low|medium|high",
+"description": "...", "evidence": ["id события или факт"], "entity_ids": ["..."], "suggested_fix": "..."}]}.
+Если противоречий нет — {"inconsistencies": []}.`
+
+	return systemPrompt, user.String(), true
+}
+
+// publish sends an inconsistency event to system_events
+func (c *Critic) publish(ctx context.Context, eventType string, report *InconsistencyReport) {
+	if c.bus == nil {
+		return
+	}
+	data, _ := json.Marshal(report)
+	var payload map[string]interface{}
+	json.Unmarshal(data, &payload)
+
+	event := eventbus.NewEvent(eventType, "reality-monitor", report.WorldID, payload)
+	if err := c.bus.PublishSystemEvent(ctx, event); err != nil {
+		logging.Errorf("Failed to publish %s for report %s: %v", eventType, report.ID, err)
+	}
+}
+
+// criticResponse is the JSON answer expected from the Oracle
+type criticResponse struct {
+	Inconsistencies []struct {
+		Type         string   `json:"type"`
+		Severity     string   `json:"severity"`
+		Description  string   `json:"description"`
+		Evidence     []string `json:"evidence"`
+		EntityIDs    []string `json:"entity_ids"`
+		SuggestedFix string   `json:"suggested_fix"`
+	} `json:"inconsistencies"`
+}
+
+// parseCriticResponse converts the Oracle answer into open reports
+func parseCriticResponse(worldID, response string) ([]*InconsistencyReport, error) {
+	var parsed criticResponse
+	if err := json.Unmarshal([]byte(response), &parsed); err != nil {
+		return nil, fmt.Errorf("invalid critic response: %w", err)
+	}
+
+	now := time.Now().UTC()
+	var reports []*InconsistencyReport
+	for _, item := range parsed.Inconsistencies {
+		if strings.TrimSpace(item.Description) == "" {
+			continue
+		}
+		report := &InconsistencyReport{
+			ID:           uuid.NewString(),
+			WorldID:      worldID,
+			Type:         item.Type,
+			Severity:     item.Severity,
+			Description:  item.Description,
+			Evidence:     item.Evidence,
+			EntityIDs:    item.EntityIDs,
+			SuggestedFix: item.SuggestedFix,
+			Status:       ReportStatusOpen,
+			DetectedAt:   now,
+		}
+		if report.Type != "rule_violation" {
+			report.Type = "contradiction"
+		}
+		switch report.Severity {
+		case "low", "medium", "high":
+		default:
+			report.Severity = "medium"
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// addCanon adds a canon fact, skipping duplicates
+func (s *worldSample) addCanon(fact string) {
+	for _, existing := range s.canon {
+		if existing == fact {
+			return
+		}
+	}
+	s.canon = append(s.canon, fact)
+	if len(s.canon) > maxCanonFacts {
+		s.canon = s.canon[len(s.canon)-maxCanonFacts:]
+	}
+}
+
+// trimEntities evicts the least recently updated entities over the limit
+func (s *worldSample) trimEntities() {
+	if len(s.entities) <= maxEntityStates {
+		return
+	}
+	for _, state := range s.recentEntities(len(s.entities))[maxEntityStates:] {
+		delete(s.entities, state.EntityID)
+	}
+}
+
+// recentEntities returns up to limit entity states, most recently updated first
+func (s *worldSample) recentEntities(limit int) []*entityState {
+	states := make([]*entityState, 0, len(s.entities))
+	for _, state := range s.entities {
+		states = append(states, state)
+	}
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].UpdatedAt.After(states[j].UpdatedAt)
+	})
+	if len(states) > limit {
+		states = states[:limit]
+	}
+	return states
+}
+
+// canonFacts extracts canon facts from world creation and canon events
+func canonFacts(event eventbus.Event) []string {
+	var facts []string
+	switch raw := event.Payload["canon"].(type) {
+	case string:
+		facts = append(facts, raw)
+	case []interface{}:
+		for _, item := range raw {
+			if fact, ok := item.(string); ok && fact != "" {
+				facts = append(facts, fact)
+			}
+		}
+	}
+
+	if event.Type == "world.generated" || event.Type == "world.created" {
+		for _, key := range []string{"core", "theme", "era"} {
+			if value := payloadText(event.Payload, key); value != "" {
+				facts = append(facts, fmt.Sprintf("%s: %s", key, value))
+			}
+		}
+	}
+	return facts
+}
+
+// entityStates extracts entity states from snapshot and entity lifecycle events
+func entityStates(event eventbus.Event) map[string]map[string]interface{} {
+	states := make(map[string]map[string]interface{})
+
+	if snapshots, ok := event.Payload["entity_snapshots"].([]interface{}); ok {
+		for _, raw := range snapshots {
+			snapshot, ok := raw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if id, ok := snapshot["entity_id"].(string); ok && id != "" {
+				states[id] = snapshot
+			}
+		}
+	}
+
+	if event.Type == "entity.created" || event.Type == "entity.updated" {
+		if ref := eventbus.ExtractEntityID(event.Payload); ref != nil && ref.ID != "" {
+			if payload, ok := event.Payload["payload"].(map[string]interface{}); ok {
+				states[ref.ID] = payload
+			} else {
+				states[ref.ID] = event.Payload
+			}
+		}
+	}
+	return states
+}
+
+// payloadText returns the first non-empty string field of the payload
+func payloadText(payload map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		if text, ok := payload[key].(string); ok && text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+// ReportStore keeps inconsistency reports in memory, newest last
+type ReportStore struct {
+	mu       sync.RWMutex
+	reports  map[string]*InconsistencyReport
+	order    []string
+	capacity int
+}
+
+// NewReportStore creates a report store bounded by capacity
+func NewReportStore(capacity int) *ReportStore {
+	return &ReportStore{
+		reports:  make(map[string]*InconsistencyReport),
+		capacity: capacity,
+	}
+}
+
+// Add stores a report, evicting the oldest one over capacity
+func (rs *ReportStore) Add(report *InconsistencyReport) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	rs.reports[report.ID] = report
+	rs.order = append(rs.order, report.ID)
+	if len(rs.order) > rs.capacity {
+		delete(rs.reports, rs.order[0])
+		rs.order = rs.order[1:]
+	}
+}
+
+// Get returns a copy of a report by ID
+func (rs *ReportStore) Get(reportID string) (InconsistencyReport, bool) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	report, exists := rs.reports[reportID]
+	if !exists {
+		return InconsistencyReport{}, false
+	}
+	return *report, true
+}
+
+// List returns reports filtered by world and status (empty filter matches all), newest first
+func (rs *ReportStore) List(worldID, status string, limit int) []InconsistencyReport {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	result := make([]InconsistencyReport, 0)
+	for i := len(rs.order) - 1; i >= 0; i-- {
+		report := rs.reports[rs.order[i]]
+		if worldID != "" && report.WorldID != worldID {
+			continue
+		}
+		if status != "" && report.Status != status {
+			continue
+		}
+		result = append(result, *report)
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+	}
+	return result
+}
+
+// Resolve marks a report as resolved or dismissed
+func (rs *ReportStore) Resolve(reportID, status, resolution string) (*InconsistencyReport, error) {
+	if status != ReportStatusResolved && status != ReportStatusDismissed {
+		return nil, fmt.Errorf("invalid status %q", status)
+	}
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	report, exists := rs.reports[reportID]
+	if !exists {
+		return nil, fmt.Errorf("report %s not found", reportID)
+	}
+	report.Status = status
+	report.Resolution = resolution
+	report.ResolvedAt = time.Now().UTC()
+
+	resolved := *report
+	return &resolved, nil
+}
@@ -0,0 +1,93 @@
+package realitymonitor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+)
+
+type stubOracle struct {
+	response   string
+	userPrompt string
+}
+
+func (o *stubOracle) CallStructuredJSON(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	o.userPrompt = userPrompt
+	return o.response, nil
+}
+
+func TestCriticAudit(t *testing.T) {
+	oracle := &stubOracle{response: `{"inconsistencies": [
+		{"type": "contradiction", "severity": "high", "description": "Кузнец мёртв по канону, но говорит с игроком", "entity_ids": ["npc-7"]},
+		{"type": "unknown", "severity": "extreme", "description": "Магия запрещена, но игрок колдует"},
+		{"type": "contradiction", "description": "   "}
+	]}`}
+	critic := NewCritic(oracle, nil, time.Minute)
+
+	critic.Observe(eventbus.NewEvent("world.generated", "world-generator", "w1", map[string]any{
+		"core":  "Мир без магии",
+		"canon": []any{"Кузнец погиб в войне"},
+	}))
+	critic.Observe(eventbus.NewEvent("narrative.generate", "narrative-orchestrator", "w1", map[string]any{
+		"narrative": "Кузнец приветствует игрока",
+	}))
+
+	if pending := critic.pendingWorlds(); len(pending) != 1 || pending[0] != "w1" {
+		t.Fatalf("expected w1 pending audit, got %v", pending)
+	}
+
+	reports, err := critic.Audit(context.Background(), "w1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(reports) != 2 {
+		t.Fatalf("expected 2 reports, got %d", len(reports))
+	}
+	if reports[1].Type != "contradiction" || reports[1].Severity != "medium" {
+		t.Errorf("expected normalized type and severity, got %s/%s", reports[1].Type, reports[1].Severity)
+	}
+	for _, want := range []string{"Кузнец погиб в войне", "core: Мир без магии", "Кузнец приветствует игрока"} {
+		if !strings.Contains(oracle.userPrompt, want) {
+			t.Errorf("prompt missing %q", want)
+		}
+	}
+	if pending := critic.pendingWorlds(); len(pending) != 0 {
+		t.Errorf("expected no pending worlds after audit, got %v", pending)
+	}
+
+	if _, err := critic.Audit(context.Background(), "w2"); !errors.Is(err, ErrNoSample) {
+		t.Errorf("expected ErrNoSample, got %v", err)
+	}
+}
+
+func TestReportStore(t *testing.T) {
+	store := NewReportStore(2)
+	store.Add(&InconsistencyReport{ID: "a", WorldID: "w1", Status: ReportStatusOpen})
+	store.Add(&InconsistencyReport{ID: "b", WorldID: "w2", Status: ReportStatusOpen})
+	store.Add(&InconsistencyReport{ID: "c", WorldID: "w1", Status: ReportStatusOpen})
+
+	if _, exists := store.Get("a"); exists {
+		t.Error("expected oldest report to be evicted")
+	}
+	if got := store.List("w1", "", 0); len(got) != 1 || got[0].ID != "c" {
+		t.Errorf("unexpected world filter result: %+v", got)
+	}
+
+	if _, err := store.Resolve("b", "open", ""); err == nil {
+		t.Error("expected error for invalid status")
+	}
+	resolved, err := store.Resolve("b", ReportStatusDismissed, "false positive")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resolved.Status != ReportStatusDismissed || resolved.ResolvedAt.IsZero() {
+		t.Errorf("unexpected resolved report: %+v", resolved)
+	}
+	if got := store.List("", ReportStatusOpen, 0); len(got) != 1 || got[0].ID != "c" {
+		t.Errorf("unexpected status filter result: %+v", got)
+	}
+}
@@ -0,0 +1,248 @@
+package realitymonitor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+)
+
+const (
+	// historyBucket keeps the metric samples of every world as {world_id}.json
+	historyBucket = "reality-metrics-history"
+	// maxHistorySamples bounds the in-memory history of a world (24 hours at the default interval)
+	maxHistorySamples = 2880
+	// defaultHistoryDumpPeriod is how often the history is written to MinIO
+	defaultHistoryDumpPeriod = 5 * time.Minute
+	// trendSamples is how many recent samples the anomaly detector averages
+	trendSamples = 10
+)
+
+// MetricSample is the metrics of a world at one point in time
+type MetricSample struct {
+	Timestamp        time.Time `json:"timestamp"`
+	SpatialIntegrity float64   `json:"spatial_integrity"`
+	KarmaEntropy     float64   `json:"karma_entropy"`
+	CoreResonance    float64   `json:"core_resonance"`
+	EventRate        float64   `json:"event_rate"`
+	ViolationRatio   float64   `json:"violation_ratio"`
+	NarrativeLatency float64   `json:"narrative_latency_seconds"`
+	EntityChurn      float64   `json:"entity_churn"`
+	// Reported is set when spatial integrity and core resonance came from world.metrics.*
+	Reported bool `json:"reported"`
+}
+
+// sampleOf captures the current metrics of a world
+func sampleOf(metrics *WorldMetrics, at time.Time) MetricSample {
+	return MetricSample{
+		Timestamp:        at,
+		SpatialIntegrity: metrics.SpatialIntegrity,
+		KarmaEntropy:     metrics.KarmaEntropy,
+		CoreResonance:    metrics.CoreResonance,
+		EventRate:        metrics.EventRate,
+		ViolationRatio:   metrics.ViolationRatio,
+		NarrativeLatency: metrics.NarrativeLatency,
+		EntityChurn:      metrics.EntityChurn,
+		Reported:         metrics.reported,
+	}
+}
+
+// metricsHistory keeps the recent samples of every world, oldest first
+type metricsHistory struct {
+	storage storage.ObjectStorage // nil — history lives in memory only
+
+	mu     sync.Mutex
+	worlds map[string][]MetricSample
+	// dirty holds the worlds with samples not yet written to storage
+	dirty map[string]bool
+}
+
+func newMetricsHistory() *metricsHistory {
+	return &metricsHistory{
+		worlds: make(map[string][]MetricSample),
+		dirty:  make(map[string]bool),
+	}
+}
+
+// record appends a sample, dropping the oldest one when the history is full
+func (h *metricsHistory) record(worldID string, sample MetricSample) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	samples := append(h.worlds[worldID], sample)
+	if len(samples) > maxHistorySamples {
+		samples = append([]MetricSample(nil), samples[len(samples)-maxHistorySamples:]...)
+	}
+	h.worlds[worldID] = samples
+	h.dirty[worldID] = true
+}
+
+// query returns the samples of the world in [from, to]. With a positive resolution samples
+// are averaged into buckets of that width, timestamped with the bucket start.
+func (h *metricsHistory) query(worldID string, from, to time.Time, resolution time.Duration) []MetricSample {
+	h.mu.Lock()
+	var samples []MetricSample
+	for _, sample := range h.worlds[worldID] {
+		if !sample.Timestamp.Before(from) && !sample.Timestamp.After(to) {
+			samples = append(samples, sample)
+		}
+	}
+	h.mu.Unlock()
+
+	if resolution <= 0 || len(samples) == 0 {
+		return samples
+	}
+	var (
+		result  []MetricSample
+		bucket  []MetricSample
+		current time.Time
+	)
+	for _, sample := range samples {
+		start := from.Add(sample.Timestamp.Sub(from).Truncate(resolution))
+		if len(bucket) > 0 && !start.Equal(current) {
+			result = append(result, average(current, bucket))
+			bucket = bucket[:0]
+		}
+		current = start
+		bucket = append(bucket, sample)
+	}
+	return append(result, average(current, bucket))
+}
+
+// recent returns up to n latest samples of the world
+func (h *metricsHistory) recent(worldID string, n int) []MetricSample {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	samples := h.worlds[worldID]
+	if len(samples) > n {
+		samples = samples[len(samples)-n:]
+	}
+	return append([]MetricSample(nil), samples...)
+}
+
+// average merges samples into one; spatial integrity and core resonance are averaged
+// over the reported samples only
+func average(at time.Time, samples []MetricSample) MetricSample {
+	result := MetricSample{Timestamp: at}
+	reported := 0
+	for _, s := range samples {
+		result.KarmaEntropy += s.KarmaEntropy
+		result.EventRate += s.EventRate
+		result.ViolationRatio += s.ViolationRatio
+		result.NarrativeLatency += s.NarrativeLatency
+		result.EntityChurn += s.EntityChurn
+		if s.Reported {
+			result.SpatialIntegrity += s.SpatialIntegrity
+			result.CoreResonance += s.CoreResonance
+			reported++
+		}
+	}
+	n := float64(len(samples))
+	result.KarmaEntropy /= n
+	result.EventRate /= n
+	result.ViolationRatio /= n
+	result.NarrativeLatency /= n
+	result.EntityChurn /= n
+	if reported > 0 {
+		result.SpatialIntegrity /= float64(reported)
+		result.CoreResonance /= float64(reported)
+		result.Reported = true
+	}
+	return result
+}
+
+// load reads the stored history of every world; samples recorded before loading are kept after them
+func (h *metricsHistory) load() error {
+	if h.storage == nil {
+		return nil
+	}
+	objects, err := h.storage.ListObjects(historyBucket, "")
+	if err != nil {
+		if storage.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("list metrics history: %w", err)
+	}
+	for _, object := range objects {
+		if !strings.HasSuffix(object.Key, ".json") {
+			continue
+		}
+		data, err := h.storage.GetObject(historyBucket, object.Key)
+		if err != nil {
+			logging.Errorf("Failed to load metrics history %s: %v", object.Key, err)
+			continue
+		}
+		var stored []MetricSample
+		if err := json.Unmarshal(data, &stored); err != nil {
+			logging.Warnf("Skipping corrupted metrics history %s: %v", object.Key, err)
+			continue
+		}
+		worldID := strings.TrimSuffix(object.Key, ".json")
+
+		h.mu.Lock()
+		samples := append(stored, h.worlds[worldID]...)
+		sort.SliceStable(samples, func(i, j int) bool { return samples[i].Timestamp.Before(samples[j].Timestamp) })
+		if len(samples) > maxHistorySamples {
+			samples = samples[len(samples)-maxHistorySamples:]
+		}
+		h.worlds[worldID] = samples
+		h.mu.Unlock()
+	}
+	logging.Infof("Loaded metrics history of %d worlds", len(objects))
+	return nil
+}
+
+// dump writes the history of the worlds that changed since the last dump
+func (h *metricsHistory) dump() {
+	if h.storage == nil {
+		return
+	}
+	h.mu.Lock()
+	pending := make(map[string][]MetricSample, len(h.dirty))
+	for worldID := range h.dirty {
+		pending[worldID] = append([]MetricSample(nil), h.worlds[worldID]...)
+	}
+	h.dirty = make(map[string]bool)
+	h.mu.Unlock()
+
+	for worldID, samples := range pending {
+		data, err := json.Marshal(samples)
+		if err != nil {
+			logging.Errorf("Failed to encode metrics history of %s: %v", worldID, err)
+			continue
+		}
+		if err := h.storage.PutObject(historyBucket, worldID+".json", bytes.NewReader(data), int64(len(data))); err != nil {
+			logging.Errorf("Failed to save metrics history of %s: %v", worldID, err)
+			// Retried on the next dump
+			h.mu.Lock()
+			h.dirty[worldID] = true
+			h.mu.Unlock()
+		}
+	}
+}
+
+// recordHistory samples the metrics of every world
+func (s *Service) recordHistory() {
+	now := time.Now()
+	s.state.mu.Lock()
+	samples := make(map[string]MetricSample, len(s.state.Metrics))
+	for worldID, metrics := range s.state.Metrics {
+		samples[worldID] = sampleOf(metrics, now)
+	}
+	s.state.mu.Unlock()
+
+	for worldID, sample := range samples {
+		s.history.record(worldID, sample)
+	}
+}
+
+// GetWorldMetricsHistory returns the metric samples of a world in [from, to], averaged into
+// buckets of resolution width when it is positive
+func (s *Service) GetWorldMetricsHistory(worldID string, from, to time.Time, resolution time.Duration) []MetricSample {
+	return s.history.query(worldID, from, to, resolution)
+}
@@ -0,0 +1,92 @@
+package realitymonitor
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"multiverse-core.io/shared/minio/miniotest"
+)
+
+func TestMetricsHistoryQuery(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	history := newMetricsHistory()
+	for i := 0; i < 6; i++ {
+		history.record("w1", MetricSample{
+			Timestamp:        start.Add(time.Duration(i) * time.Minute),
+			SpatialIntegrity: 0.5,
+			EventRate:        float64(i),
+			Reported:         i%2 == 0,
+		})
+	}
+
+	if raw := history.query("w1", start.Add(time.Minute), start.Add(3*time.Minute), 0); len(raw) != 3 || raw[0].EventRate != 1 {
+		t.Fatalf("unexpected raw samples %+v", raw)
+	}
+
+	buckets := history.query("w1", start, start.Add(time.Hour), 2*time.Minute)
+	if len(buckets) != 3 {
+		t.Fatalf("expected 3 buckets, got %+v", buckets)
+	}
+	if !buckets[1].Timestamp.Equal(start.Add(2*time.Minute)) || buckets[1].EventRate != 2.5 {
+		t.Errorf("unexpected bucket %+v", buckets[1])
+	}
+	// Spatial readings are averaged over reported samples only
+	if !buckets[1].Reported || buckets[1].SpatialIntegrity != 0.5 {
+		t.Errorf("unexpected spatial integrity %+v", buckets[1])
+	}
+
+	for i := 0; i < maxHistorySamples; i++ {
+		history.record("w1", MetricSample{Timestamp: start.Add(time.Hour + time.Duration(i)*time.Second)})
+	}
+	if recent := history.recent("w1", maxHistorySamples+10); len(recent) != maxHistorySamples || recent[0].Timestamp.Before(start.Add(time.Hour)) {
+		t.Errorf("history must keep the latest %d samples", maxHistorySamples)
+	}
+}
+
+func TestMetricsHistoryPersistence(t *testing.T) {
+	store := miniotest.New()
+	history := newMetricsHistory()
+	history.storage = store
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	history.record("w1", MetricSample{Timestamp: at, KarmaEntropy: 0.4})
+	history.dump()
+
+	restored := newMetricsHistory()
+	restored.storage = store
+	restored.record("w1", MetricSample{Timestamp: at.Add(time.Minute), KarmaEntropy: 0.6})
+	if err := restored.load(); err != nil {
+		t.Fatal(err)
+	}
+	samples := restored.recent("w1", 10)
+	if len(samples) != 2 || samples[0].KarmaEntropy != 0.4 || samples[1].KarmaEntropy != 0.6 {
+		t.Errorf("unexpected restored history %+v", samples)
+	}
+}
+
+func TestAnomalyUsesTrend(t *testing.T) {
+	s := &Service{history: newMetricsHistory()}
+	metrics := &WorldMetrics{WorldID: "w1", SpatialIntegrity: 0.05, CoreResonance: 0.6, reported: true}
+
+	// A single low sample is an anomaly until the history holds a trend
+	if !s.isAnomaly(metrics) {
+		t.Fatal("expected a single-sample anomaly without history")
+	}
+	for i := 0; i < trendSamples-1; i++ {
+		s.history.record("w1", MetricSample{SpatialIntegrity: 0.5, CoreResonance: 0.6, Reported: true})
+	}
+	s.history.record("w1", sampleOf(metrics, time.Now()))
+	if s.isAnomaly(metrics) {
+		t.Errorf("a single dip must not break a healthy trend, got %q", metrics.AnomalyType)
+	}
+
+	for i := 0; i < trendSamples; i++ {
+		s.history.record("w1", MetricSample{KarmaEntropy: 0.95, SpatialIntegrity: 0.5, CoreResonance: 0.6, Reported: true})
+	}
+	if !s.isAnomaly(metrics) || metrics.AnomalyType != AnomalyKarmaEntropy {
+		t.Errorf("sustained karma entropy must be an anomaly, got %q", metrics.AnomalyType)
+	}
+	if trend := s.trend(metrics); math.Abs(trend.KarmaEntropy-0.95) > 1e-9 {
+		t.Errorf("unexpected trend %+v", trend)
+	}
+}
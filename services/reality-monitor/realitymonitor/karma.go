@@ -0,0 +1,324 @@
+package realitymonitor
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+
+	"github.com/gorilla/mux"
+)
+
+// EventPlayerKarma is published by BanOfWorld with a player's karma in [-100, 0].
+const EventPlayerKarma = "player.reputation.karma"
+
+// Events of player actions that change karma, and the event the karma engine publishes
+const (
+	EventQuestCompleted = "quest.completed"
+	EventNPCInteraction = "npc.interaction"
+	EventKarmaUpdated   = "karma.updated"
+)
+
+// Karma bounds and the default decay half-life, KARMA_HALF_LIFE_MS
+const (
+	maxKarma             = 100
+	defaultKarmaHalfLife = 24 * time.Hour
+	// negligibleKarma is the score below which a decayed player is forgotten
+	negligibleKarma = 0.5
+)
+
+// violationKarma is the karma lost per violation by punishment tier (BanOfWorld ledger tiers)
+var violationKarma = map[string]float64{
+	"warning":        -5,
+	"transformation": -15,
+	"imprisonment":   -30,
+	"exile":          -50,
+}
+
+// defaultViolationKarma is lost for violations without a known tier
+const defaultViolationKarma = -10
+
+// questKarma is gained per completed quest
+const questKarma = 10
+
+// hostileInteractions lose karma; any other NPC interaction gains a little
+var hostileInteractions = map[string]bool{
+	"attack":   true,
+	"threaten": true,
+	"steal":    true,
+	"insult":   true,
+	"extort":   true,
+}
+
+const (
+	hostileInteractionKarma  = -5
+	peacefulInteractionKarma = 1
+)
+
+// playerKarma is the karma of one player in the world of their latest action
+type playerKarma struct {
+	worldID string
+	score   float64   // from player actions, decays towards 0
+	ban     float64   // latest BanOfWorld karma, 0 once forgiven
+	decayed time.Time // when score was last decayed
+}
+
+// value is the effective karma: unforgiven BanOfWorld karma caps the score
+func (p *playerKarma) value() float64 {
+	if p.ban < 0 {
+		return math.Min(p.score, math.Max(p.ban, -maxKarma))
+	}
+	return p.score
+}
+
+// KarmaUpdate is the karma of a player and their world after a change
+type KarmaUpdate struct {
+	PlayerID     string  `json:"player_id"`
+	WorldID      string  `json:"world_id"`
+	Karma        float64 `json:"karma"`
+	Delta        float64 `json:"delta"`
+	WorldKarma   float64 `json:"world_karma"`
+	KarmaEntropy float64 `json:"karma_entropy"`
+}
+
+// WorldKarma is the karma of a world and its players
+type WorldKarma struct {
+	WorldID      string             `json:"world_id"`
+	Karma        float64            `json:"karma"`
+	KarmaEntropy float64            `json:"karma_entropy"`
+	Players      map[string]float64 `json:"players"`
+}
+
+// karmaEngine keeps per-player karma fed by player actions, decaying towards 0 with halfLife,
+// and aggregates it per world
+type karmaEngine struct {
+	mu       sync.Mutex
+	halfLife time.Duration
+	now      func() time.Time
+	players  map[string]*playerKarma // player ID → karma
+}
+
+func newKarmaEngine(halfLife time.Duration) *karmaEngine {
+	if halfLife <= 0 {
+		halfLife = defaultKarmaHalfLife
+	}
+	return &karmaEngine{
+		halfLife: halfLife,
+		now:      time.Now,
+		players:  make(map[string]*playerKarma),
+	}
+}
+
+// player returns the player's karma decayed to now, moving it to worldID; the caller holds the lock
+func (k *karmaEngine) player(playerID, worldID string, now time.Time) *playerKarma {
+	p, ok := k.players[playerID]
+	if !ok {
+		p = &playerKarma{worldID: worldID, decayed: now}
+		k.players[playerID] = p
+	}
+	k.decayLocked(p, now)
+	if worldID != "" {
+		p.worldID = worldID
+	}
+	return p
+}
+
+// decayLocked halves the score every halfLife since the last decay
+func (k *karmaEngine) decayLocked(p *playerKarma, now time.Time) {
+	if elapsed := now.Sub(p.decayed); elapsed > 0 {
+		p.score *= math.Pow(0.5, elapsed.Seconds()/k.halfLife.Seconds())
+		p.decayed = now
+	}
+}
+
+// apply changes the player's karma by delta and returns the update
+func (k *karmaEngine) apply(worldID, playerID string, delta float64) KarmaUpdate {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	p := k.player(playerID, worldID, k.now())
+	p.score = math.Max(-maxKarma, math.Min(maxKarma, p.score+delta))
+	return k.updateLocked(playerID, p, delta)
+}
+
+// observeBan records the karma announced by BanOfWorld; forgiven players (karma 0) are no longer capped
+func (k *karmaEngine) observeBan(worldID, playerID string, karma float64) KarmaUpdate {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	p := k.player(playerID, worldID, k.now())
+	before := p.value()
+	p.ban = math.Min(karma, 0)
+	return k.updateLocked(playerID, p, p.value()-before)
+}
+
+func (k *karmaEngine) updateLocked(playerID string, p *playerKarma, delta float64) KarmaUpdate {
+	world := k.worldLocked(p.worldID)
+	return KarmaUpdate{
+		PlayerID:     playerID,
+		WorldID:      p.worldID,
+		Karma:        p.value(),
+		Delta:        delta,
+		WorldKarma:   world.Karma,
+		KarmaEntropy: world.KarmaEntropy,
+	}
+}
+
+// decay decays every player's karma to now and forgets players whose karma has faded
+func (k *karmaEngine) decay() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	now := k.now()
+	for playerID, p := range k.players {
+		k.decayLocked(p, now)
+		if math.Abs(p.value()) < negligibleKarma {
+			delete(k.players, playerID)
+		}
+	}
+}
+
+// world returns the karma of the world's players; false if no player of the world has karma
+func (k *karmaEngine) world(worldID string) (WorldKarma, bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	world := k.worldLocked(worldID)
+	return world, len(world.Players) > 0
+}
+
+// worldLocked aggregates the world: Karma is the mean player karma, KarmaEntropy the mean
+// karma debt (-karma / 100 of players in debt, 0 for the rest) in [0, 1]
+func (k *karmaEngine) worldLocked(worldID string) WorldKarma {
+	world := WorldKarma{WorldID: worldID, Players: make(map[string]float64)}
+	var total, debt float64
+	for playerID, p := range k.players {
+		if p.worldID != worldID {
+			continue
+		}
+		value := p.value()
+		world.Players[playerID] = value
+		total += value
+		if value < 0 {
+			debt += -value / maxKarma
+		}
+	}
+	if n := float64(len(world.Players)); n > 0 {
+		world.Karma = total / n
+		world.KarmaEntropy = debt / n
+	}
+	return world
+}
+
+// entropy returns the karma entropy of the world; false if no player of the world has karma
+func (k *karmaEngine) entropy(worldID string) (float64, bool) {
+	world, ok := k.world(worldID)
+	return world.KarmaEntropy, ok
+}
+
+// karmaDelta returns the karma change of a player action event; false for events that do not affect karma
+func karmaDelta(event eventbus.Event) (float64, bool) {
+	switch event.Type {
+	case EventViolationDetected:
+		tier, _ := event.Path().GetString("tier")
+		if delta, ok := violationKarma[tier]; ok {
+			return delta, true
+		}
+		return defaultViolationKarma, true
+	case EventQuestCompleted:
+		return questKarma, true
+	case EventNPCInteraction:
+		interaction, _ := event.Path().GetString("interaction_type")
+		if hostileInteractions[interaction] {
+			return hostileInteractionKarma, true
+		}
+		return peacefulInteractionKarma, true
+	}
+	return 0, false
+}
+
+// handleKarmaEvent applies player actions and BanOfWorld karma to the karma engine,
+// publishes karma.updated and updates the karma entropy of the world
+func (s *Service) handleKarmaEvent(event eventbus.Event) {
+	var update KarmaUpdate
+	switch event.Type {
+	case EventPlayerKarma:
+		entityInfo, ok := event.GetEntityIDWithFallback()
+		karma, hasKarma := event.Path().GetFloat("karma")
+		if !ok || !hasKarma {
+			return
+		}
+		update = s.karma.observeBan(eventbus.GetWorldIDFromEvent(event), entityInfo.ID, karma)
+	default:
+		delta, ok := karmaDelta(event)
+		if !ok {
+			return
+		}
+		playerID := eventPlayerID(event)
+		if playerID == "" {
+			return
+		}
+		update = s.karma.apply(eventbus.GetWorldIDFromEvent(event), playerID, delta)
+	}
+
+	s.state.mu.Lock()
+	if metrics, exists := s.state.Metrics[update.WorldID]; exists {
+		metrics.KarmaEntropy = update.KarmaEntropy
+	}
+	s.state.mu.Unlock()
+
+	if update.Delta != 0 {
+		s.publishKarmaUpdate(event, update)
+	}
+}
+
+// publishKarmaUpdate publishes karma.updated caused by event to world_events
+func (s *Service) publishKarmaUpdate(cause eventbus.Event, update KarmaUpdate) {
+	if s.eventBus == nil {
+		return
+	}
+	payload := map[string]interface{}{
+		"entity":        map[string]interface{}{"id": update.PlayerID, "type": "player"},
+		"karma":         update.Karma,
+		"delta":         update.Delta,
+		"reason":        cause.Type,
+		"world_karma":   update.WorldKarma,
+		"karma_entropy": update.KarmaEntropy,
+	}
+	event := eventbus.NewEvent(EventKarmaUpdated, "reality-monitor", update.WorldID, payload).CausedBy(cause)
+	if err := s.eventBus.Publish(context.Background(), eventbus.TopicWorldEvents, event); err != nil {
+		logging.Warnf("Failed to publish karma update of player %s: %v", update.PlayerID, err)
+	}
+}
+
+// refreshKarma decays karma and writes the current karma entropy into the metrics of every world
+func (s *Service) refreshKarma() {
+	s.karma.decay()
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+	for worldID, metrics := range s.state.Metrics {
+		// Worlds without karma keep the entropy they reported
+		if entropy, ok := s.karma.entropy(worldID); ok || !metrics.reported {
+			metrics.KarmaEntropy = entropy
+		}
+	}
+}
+
+// handleWorldKarma handles GET /v1/worlds/{id}/karma
+func (s *Service) handleWorldKarma(w http.ResponseWriter, r *http.Request) {
+	world, ok := s.karma.world(mux.Vars(r)["id"])
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "no karma recorded for the world"})
+		return
+	}
+	writeJSON(w, http.StatusOK, world)
+}
+
+// eventPlayerID returns the acting player of an event: entity.id, then player_id
+func eventPlayerID(event eventbus.Event) string {
+	if entityInfo, ok := event.GetEntityIDWithFallback(); ok {
+		return entityInfo.ID
+	}
+	playerID, _ := event.Path().GetString("player_id")
+	return playerID
+}
@@ -0,0 +1,92 @@
+package realitymonitor
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+)
+
+func TestKarmaEngine(t *testing.T) {
+	engine := newKarmaEngine(time.Hour)
+	now := time.Now()
+	engine.now = func() time.Time { return now }
+	if _, ok := engine.entropy("w1"); ok {
+		t.Errorf("worlds without players have no karma entropy")
+	}
+
+	engine.apply("w1", "p1", -50)
+	update := engine.apply("w1", "p2", 10)
+	if update.Karma != 10 || update.WorldKarma != -20 || math.Abs(update.KarmaEntropy-0.25) > 1e-9 {
+		t.Errorf("unexpected update %+v", update)
+	}
+
+	// Karma halves every half-life; faded players are forgotten
+	now = now.Add(time.Hour)
+	engine.decay()
+	if world, _ := engine.world("w1"); world.Players["p1"] != -25 || world.Players["p2"] != 5 {
+		t.Errorf("expected halved karma, got %v", world.Players)
+	}
+	now = now.Add(5 * time.Hour)
+	engine.decay()
+	if world, _ := engine.world("w1"); len(world.Players) != 1 {
+		t.Errorf("expected p2 forgotten, got %v", world.Players)
+	}
+
+	// Unforgiven BanOfWorld karma caps the score and moves the player with the latest world
+	if update := engine.observeBan("w2", "p1", -60); update.Karma != -60 || update.WorldID != "w2" {
+		t.Errorf("unexpected ban update %+v", update)
+	}
+	if _, ok := engine.entropy("w1"); ok {
+		t.Errorf("p1 must leave w1")
+	}
+	engine.apply("w2", "p1", questKarma)
+	if entropy, _ := engine.entropy("w2"); math.Abs(entropy-0.6) > 1e-9 {
+		t.Errorf("karma above the ban must stay capped, got entropy %v", entropy)
+	}
+	// Forgiven: the score earned by the quest (on top of the faded violation) is no longer capped
+	if update := engine.observeBan("w2", "p1", 0); update.Karma < 9 || update.Karma > questKarma || update.Delta <= 0 {
+		t.Errorf("forgiven player must get their own score back, got %+v", update)
+	}
+}
+
+func TestKarmaDelta(t *testing.T) {
+	cases := []struct {
+		eventType string
+		payload   map[string]interface{}
+		want      float64
+		ok        bool
+	}{
+		{EventViolationDetected, map[string]interface{}{"tier": "exile"}, -50, true},
+		{EventViolationDetected, nil, defaultViolationKarma, true},
+		{EventQuestCompleted, nil, questKarma, true},
+		{EventNPCInteraction, map[string]interface{}{"interaction_type": "attack"}, hostileInteractionKarma, true},
+		{EventNPCInteraction, map[string]interface{}{"interaction_type": "trade"}, peacefulInteractionKarma, true},
+		{"player.moved", nil, 0, false},
+	}
+	for _, c := range cases {
+		delta, ok := karmaDelta(eventbus.NewEvent(c.eventType, "test", "w1", c.payload))
+		if delta != c.want || ok != c.ok {
+			t.Errorf("%s %v: got %v %v, want %v %v", c.eventType, c.payload, delta, ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestHandleKarmaEventUpdatesEntropy(t *testing.T) {
+	s := &Service{
+		state: &State{Metrics: map[string]*WorldMetrics{"w1": {WorldID: "w1"}}, AnomalyCounts: map[string]int{}},
+		karma: newKarmaEngine(0),
+	}
+	s.handleKarmaEvent(eventbus.NewEvent(EventViolationDetected, "ban-of-world", "w1", map[string]interface{}{
+		"entity": map[string]interface{}{"id": "p1", "type": "player"},
+		"tier":   "imprisonment",
+	}))
+	if entropy := s.state.Metrics["w1"].KarmaEntropy; math.Abs(entropy-0.3) > 1e-9 {
+		t.Errorf("expected karma entropy 0.3, got %v", entropy)
+	}
+	s.handleKarmaEvent(eventbus.NewEvent(EventQuestCompleted, "city-governor", "w1", map[string]interface{}{"player_id": "p2"}))
+	if entropy := s.state.Metrics["w1"].KarmaEntropy; math.Abs(entropy-0.15) > 1e-9 {
+		t.Errorf("good players must dilute the entropy, got %v", entropy)
+	}
+}
@@ -0,0 +1,205 @@
+package realitymonitor
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// prometheusContentType is the Prometheus text exposition format
+const prometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// WorldHealth is a point-in-time copy of the metrics of a world, safe to read outside the state lock
+type WorldHealth struct {
+	WorldID          string     `json:"world_id"`
+	LastUpdated      time.Time  `json:"last_updated"`
+	SpatialIntegrity float64    `json:"spatial_integrity"`
+	KarmaEntropy     float64    `json:"karma_entropy"`
+	CoreResonance    float64    `json:"core_resonance"`
+	EventRate        float64    `json:"event_rate"`
+	ViolationRatio   float64    `json:"violation_ratio"`
+	NarrativeLatency float64    `json:"narrative_latency_seconds"`
+	EntityChurn      float64    `json:"entity_churn"`
+	AnomalyDetected  bool       `json:"anomaly_detected"`
+	AnomalyType      string     `json:"anomaly_type,omitempty"`
+	AnomalyTimestamp *time.Time `json:"anomaly_timestamp,omitempty"`
+	AnomalyCount     int        `json:"anomaly_count"`
+}
+
+// snapshot copies the metrics of every world, sorted by world ID
+func (s *Service) snapshot() []WorldHealth {
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+
+	worlds := make([]WorldHealth, 0, len(s.state.Metrics))
+	for worldID, metrics := range s.state.Metrics {
+		worlds = append(worlds, s.state.health(worldID, metrics))
+	}
+	sort.Slice(worlds, func(i, j int) bool { return worlds[i].WorldID < worlds[j].WorldID })
+	return worlds
+}
+
+// worldHealth copies the metrics of one world
+func (s *Service) worldHealth(worldID string) (WorldHealth, bool) {
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+
+	metrics, exists := s.state.Metrics[worldID]
+	if !exists {
+		return WorldHealth{}, false
+	}
+	return s.state.health(worldID, metrics), true
+}
+
+// health builds the snapshot of a world; the caller holds the state lock
+func (st *State) health(worldID string, metrics *WorldMetrics) WorldHealth {
+	health := WorldHealth{
+		WorldID:          worldID,
+		LastUpdated:      metrics.LastUpdated,
+		SpatialIntegrity: metrics.SpatialIntegrity,
+		KarmaEntropy:     metrics.KarmaEntropy,
+		CoreResonance:    metrics.CoreResonance,
+		EventRate:        metrics.EventRate,
+		ViolationRatio:   metrics.ViolationRatio,
+		NarrativeLatency: metrics.NarrativeLatency,
+		EntityChurn:      metrics.EntityChurn,
+		AnomalyDetected:  metrics.AnomalyDetected,
+		AnomalyType:      metrics.AnomalyType,
+		AnomalyCount:     st.AnomalyCounts[worldID],
+	}
+	if !metrics.AnomalyTimestamp.IsZero() {
+		ts := metrics.AnomalyTimestamp
+		health.AnomalyTimestamp = &ts
+	}
+	return health
+}
+
+// handleMetrics handles GET /metrics — world health in the Prometheus text format
+func (s *Service) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", prometheusContentType)
+	writePrometheus(w, s.snapshot())
+}
+
+// handleWorldMetrics handles GET /v1/worlds/{id}/metrics
+func (s *Service) handleWorldMetrics(w http.ResponseWriter, r *http.Request) {
+	health, exists := s.worldHealth(mux.Vars(r)["id"])
+	if !exists {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "world not found"})
+		return
+	}
+	writeJSON(w, http.StatusOK, health)
+}
+
+// defaultHistoryWindow is the history returned when the query sets no from
+const defaultHistoryWindow = time.Hour
+
+// handleWorldMetricsHistory handles GET /v1/worlds/{id}/metrics/history?from=&to=&resolution=
+// with RFC 3339 from/to and a Go duration resolution (e.g. 5m); no resolution returns raw samples
+func (s *Service) handleWorldMetricsHistory(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	to := time.Now()
+	if raw := query.Get("to"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid to"})
+			return
+		}
+		to = parsed
+	}
+	from := to.Add(-defaultHistoryWindow)
+	if raw := query.Get("from"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil || parsed.After(to) {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid from"})
+			return
+		}
+		from = parsed
+	}
+	var resolution time.Duration
+	if raw := query.Get("resolution"); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil || parsed < 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid resolution"})
+			return
+		}
+		resolution = parsed
+	}
+
+	worldID := mux.Vars(r)["id"]
+	samples := s.GetWorldMetricsHistory(worldID, from, to, resolution)
+	if samples == nil {
+		samples = []MetricSample{}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"world_id":   worldID,
+		"from":       from.Format(time.RFC3339),
+		"to":         to.Format(time.RFC3339),
+		"resolution": resolution.String(),
+		"samples":    samples,
+	})
+}
+
+// prometheusMetric describes one metric family exported per world
+type prometheusMetric struct {
+	name  string
+	help  string
+	kind  string
+	value func(WorldHealth) float64
+}
+
+var prometheusMetrics = []prometheusMetric{
+	{"reality_spatial_integrity", "Spatial integrity of the world (0..1).", "gauge",
+		func(h WorldHealth) float64 { return h.SpatialIntegrity }},
+	{"reality_karma_entropy", "Mean karma debt of the world's offenders (0..1).", "gauge",
+		func(h WorldHealth) float64 { return h.KarmaEntropy }},
+	{"reality_core_resonance", "Resonance of the world core.", "gauge",
+		func(h WorldHealth) float64 { return h.CoreResonance }},
+	{"reality_event_rate", "Events per second in the world over the last metrics interval.", "gauge",
+		func(h WorldHealth) float64 { return h.EventRate }},
+	{"reality_violation_ratio", "BanOfWorld violations per player action (0..1).", "gauge",
+		func(h WorldHealth) float64 { return h.ViolationRatio }},
+	{"reality_narrative_latency_seconds", "Mean time from a player action to the narrative answering it.", "gauge",
+		func(h WorldHealth) float64 { return h.NarrativeLatency }},
+	{"reality_entity_churn", "Entities created and deleted per minute.", "gauge",
+		func(h WorldHealth) float64 { return h.EntityChurn }},
+	{"reality_anomaly_detected", "1 if the last check found an anomaly in the world.", "gauge",
+		func(h WorldHealth) float64 {
+			if h.AnomalyDetected {
+				return 1
+			}
+			return 0
+		}},
+	{"reality_anomalies_total", "Anomalies published for the world since the service started.", "counter",
+		func(h WorldHealth) float64 { return float64(h.AnomalyCount) }},
+	{"reality_metrics_last_updated_timestamp_seconds", "Unix time of the last metrics update of the world.", "gauge",
+		func(h WorldHealth) float64 {
+			if h.LastUpdated.IsZero() {
+				return 0
+			}
+			return float64(h.LastUpdated.UnixNano()) / 1e9
+		}},
+}
+
+// writePrometheus renders the world metrics in the Prometheus text exposition format
+func writePrometheus(w io.Writer, worlds []WorldHealth) {
+	for _, metric := range prometheusMetrics {
+		fmt.Fprintf(w, "# HELP %s %s\n", metric.name, metric.help)
+		fmt.Fprintf(w, "# TYPE %s %s\n", metric.name, metric.kind)
+		for _, world := range worlds {
+			fmt.Fprintf(w, "%s{world_id=\"%s\"} %s\n", metric.name, escapeLabel(world.WorldID),
+				strconv.FormatFloat(metric.value(world), 'g', -1, 64))
+		}
+	}
+}
+
+// escapeLabel escapes a Prometheus label value
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
@@ -0,0 +1,68 @@
+package realitymonitor
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetricsEndpoints(t *testing.T) {
+	s := &Service{state: &State{
+		Metrics: map[string]*WorldMetrics{
+			"w2": {WorldID: "w2", SpatialIntegrity: 0.5, CoreResonance: 0.7},
+			"w1": {WorldID: "w1", SpatialIntegrity: 0.05, KarmaEntropy: 0.25, CoreResonance: 0.6, reported: true},
+		},
+		AnomalyCounts: map[string]int{},
+	}}
+	s.isAnomaly(s.state.Metrics["w1"])
+	s.state.AnomalyCounts["w1"] = 2
+	router := s.NewRouter()
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain") {
+		t.Errorf("unexpected content type %q", rec.Header().Get("Content-Type"))
+	}
+	for _, line := range []string{
+		"# TYPE reality_spatial_integrity gauge",
+		`reality_spatial_integrity{world_id="w1"} 0.05`,
+		`reality_karma_entropy{world_id="w1"} 0.25`,
+		`reality_core_resonance{world_id="w2"} 0.7`,
+		`reality_anomaly_detected{world_id="w1"} 1`,
+		"# TYPE reality_anomalies_total counter",
+		`reality_anomalies_total{world_id="w1"} 2`,
+		`reality_anomalies_total{world_id="w2"} 0`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("missing %q in:\n%s", line, body)
+		}
+	}
+	if strings.Index(body, `{world_id="w1"} 0.05`) > strings.Index(body, `{world_id="w2"} 0.5`) {
+		t.Error("worlds must be sorted by ID")
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/worlds/w1/metrics", nil))
+	var health WorldHealth
+	if err := json.NewDecoder(rec.Body).Decode(&health); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusOK || health.AnomalyType != "spatial_integrity" || health.AnomalyCount != 2 {
+		t.Errorf("unexpected world metrics %d %+v", rec.Code, health)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/worlds/unknown/metrics", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown world, got %d", rec.Code)
+	}
+}
+
+func TestEscapeLabel(t *testing.T) {
+	if got := escapeLabel("a\"b\\c\nd"); got != `a\"b\\c\nd` {
+		t.Errorf("escapeLabel = %q", got)
+	}
+}
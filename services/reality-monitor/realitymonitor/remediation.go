@@ -0,0 +1,220 @@
+package realitymonitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+)
+
+// Remediation policies are stored in MinIO as {world_id}.json with default.json as the fallback
+const (
+	policyBucket        = "reality-policies"
+	defaultPolicyObject = "default.json"
+	// policyRefreshInterval is how long a loaded policy is reused before it is read again
+	policyRefreshInterval = time.Minute
+	// defaultRemediationCooldown separates two remediations of the same anomaly in a world
+	defaultRemediationCooldown = 30 * time.Minute
+)
+
+// Anomaly types detected by the monitor
+const (
+	AnomalySpatialIntegrity = "spatial_integrity"
+	AnomalyKarmaEntropy     = "karma_entropy"
+	AnomalyCoreResonance    = "core_resonance"
+)
+
+// Remediation requests published to system_events
+const (
+	// EventGeometryRevalidation asks WorldGenerator to revalidate the world geometry
+	EventGeometryRevalidation = "world.geometry.revalidation.requested"
+	// EventRulesTighten asks BanOfWorld to tighten the rules of the world
+	EventRulesTighten = "ban.rules.tighten.requested"
+	// EventWorldEventRequested asks NarrativeOrchestrator to stage a world event
+	EventWorldEventRequested = "narrative.world_event.requested"
+)
+
+// remediationEvents maps each anomaly type to the request that remediates it
+var remediationEvents = map[string]string{
+	AnomalySpatialIntegrity: EventGeometryRevalidation,
+	AnomalyKarmaEntropy:     EventRulesTighten,
+	AnomalyCoreResonance:    EventWorldEventRequested,
+}
+
+// RemediationAction configures the remediation of one anomaly type
+type RemediationAction struct {
+	Enabled bool `json:"enabled"`
+	// CooldownMinutes overrides the default cooldown between remediations
+	CooldownMinutes float64 `json:"cooldown_minutes,omitempty"`
+	// Params are passed to the remediating service in the request payload
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+// cooldown returns the minimum time between two remediations
+func (a RemediationAction) cooldown() time.Duration {
+	if a.CooldownMinutes > 0 {
+		return time.Duration(a.CooldownMinutes * float64(time.Minute))
+	}
+	return defaultRemediationCooldown
+}
+
+// RemediationPolicy is the per-world remediation config; worlds without a policy are only observed
+type RemediationPolicy struct {
+	Enabled bool                         `json:"enabled"`
+	Actions map[string]RemediationAction `json:"actions"`
+}
+
+// action returns the enabled action for the anomaly type
+func (p RemediationPolicy) action(anomalyType string) (RemediationAction, bool) {
+	if !p.Enabled {
+		return RemediationAction{}, false
+	}
+	action, ok := p.Actions[anomalyType]
+	return action, ok && action.Enabled
+}
+
+// cachedPolicy is a loaded policy; found is false when neither the world nor the default policy exists
+type cachedPolicy struct {
+	policy   RemediationPolicy
+	found    bool
+	loadedAt time.Time
+}
+
+// Remediator applies remediation policies to detected anomalies
+type Remediator struct {
+	bus     *eventbus.EventBus
+	storage storage.ObjectStorage // nil — no policies, remediation is disabled
+	now     func() time.Time
+
+	mu       sync.Mutex
+	policies map[string]cachedPolicy
+	// lastRun holds the time of the last remediation per world and anomaly type
+	lastRun map[string]time.Time
+
+	// publish sends a remediation request; replaced in tests
+	publish func(ctx context.Context, event eventbus.Event) error
+}
+
+// NewRemediator creates a remediator; see UseStorage for the policies
+func NewRemediator(bus *eventbus.EventBus) *Remediator {
+	r := &Remediator{
+		bus:      bus,
+		now:      time.Now,
+		policies: make(map[string]cachedPolicy),
+		lastRun:  make(map[string]time.Time),
+	}
+	r.publish = func(ctx context.Context, event eventbus.Event) error {
+		return r.bus.PublishSystemEvent(ctx, event)
+	}
+	return r
+}
+
+// UseStorage enables remediation with the policies stored in MinIO
+func (r *Remediator) UseStorage(client storage.ObjectStorage) {
+	r.storage = client
+}
+
+// policy returns the policy of the world, reading MinIO at most once per refresh interval
+func (r *Remediator) policy(worldID string) (RemediationPolicy, bool) {
+	if r.storage == nil {
+		return RemediationPolicy{}, false
+	}
+
+	r.mu.Lock()
+	cached, ok := r.policies[worldID]
+	r.mu.Unlock()
+	if ok && r.now().Sub(cached.loadedAt) < policyRefreshInterval {
+		return cached.policy, cached.found
+	}
+
+	policy, found, err := r.loadPolicy(worldID)
+	if err != nil {
+		// Keep the previous policy while storage is unavailable
+		logging.Errorf("Failed to load remediation policy for world %s: %v", worldID, err)
+		if ok {
+			return cached.policy, cached.found
+		}
+		return RemediationPolicy{}, false
+	}
+
+	r.mu.Lock()
+	r.policies[worldID] = cachedPolicy{policy: policy, found: found, loadedAt: r.now()}
+	r.mu.Unlock()
+	return policy, found
+}
+
+// loadPolicy reads the world policy, falling back to the default policy
+func (r *Remediator) loadPolicy(worldID string) (RemediationPolicy, bool, error) {
+	for _, object := range []string{worldID + ".json", defaultPolicyObject} {
+		data, err := r.storage.GetObject(policyBucket, object)
+		if err != nil {
+			if storage.IsNotFound(err) {
+				continue
+			}
+			return RemediationPolicy{}, false, err
+		}
+		var policy RemediationPolicy
+		if err := json.Unmarshal(data, &policy); err != nil {
+			return RemediationPolicy{}, false, fmt.Errorf("decode policy %s: %w", object, err)
+		}
+		return policy, true, nil
+	}
+	return RemediationPolicy{}, false, nil
+}
+
+// Remediate publishes the remediation request for the anomaly if the world policy enables it
+// and the cooldown has passed. It reports whether a request was published.
+func (r *Remediator) Remediate(ctx context.Context, anomaly WorldHealth) bool {
+	eventType, known := remediationEvents[anomaly.AnomalyType]
+	if !known {
+		return false
+	}
+	policy, found := r.policy(anomaly.WorldID)
+	if !found {
+		return false
+	}
+	action, enabled := policy.action(anomaly.AnomalyType)
+	if !enabled {
+		return false
+	}
+
+	key := anomaly.WorldID + "/" + anomaly.AnomalyType
+	now := r.now()
+	r.mu.Lock()
+	if last, ok := r.lastRun[key]; ok && now.Sub(last) < action.cooldown() {
+		r.mu.Unlock()
+		return false
+	}
+	r.lastRun[key] = now
+	r.mu.Unlock()
+
+	payload := map[string]interface{}{
+		"world_id":          anomaly.WorldID,
+		"anomaly_type":      anomaly.AnomalyType,
+		"spatial_integrity": anomaly.SpatialIntegrity,
+		"karma_entropy":     anomaly.KarmaEntropy,
+		"core_resonance":    anomaly.CoreResonance,
+		"requested_at":      now.UTC().Format(time.RFC3339),
+	}
+	for key, value := range action.Params {
+		if _, reserved := payload[key]; !reserved {
+			payload[key] = value
+		}
+	}
+
+	event := eventbus.NewEvent(eventType, "reality-monitor", anomaly.WorldID, payload)
+	if err := r.publish(ctx, event); err != nil {
+		logging.Errorf("Failed to publish %s for world %s: %v", eventType, anomaly.WorldID, err)
+		r.mu.Lock()
+		delete(r.lastRun, key)
+		r.mu.Unlock()
+		return false
+	}
+	logging.Infof("Requested %s for world %s after %s anomaly", eventType, anomaly.WorldID, anomaly.AnomalyType)
+	return true
+}
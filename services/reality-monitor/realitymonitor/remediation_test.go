@@ -0,0 +1,68 @@
+package realitymonitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/minio/miniotest"
+)
+
+func TestRemediate(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := miniotest.New()
+	store.Put(policyBucket, defaultPolicyObject, `{"enabled": true, "actions": {
+		"spatial_integrity": {"enabled": true},
+		"karma_entropy": {"enabled": true, "cooldown_minutes": 5, "params": {"strictness": 1.5, "world_id": "spoofed"}}
+	}}`)
+	store.Put(policyBucket, "w2.json", `{"enabled": false}`)
+
+	var published []eventbus.Event
+	r := NewRemediator(nil)
+	r.now = func() time.Time { return now }
+	r.publish = func(ctx context.Context, event eventbus.Event) error {
+		published = append(published, event)
+		return nil
+	}
+
+	anomaly := WorldHealth{WorldID: "w1", AnomalyType: AnomalyKarmaEntropy, KarmaEntropy: 0.95}
+	if r.Remediate(context.Background(), anomaly) {
+		t.Fatal("remediation without policy storage")
+	}
+	r.UseStorage(store)
+
+	if !r.Remediate(context.Background(), anomaly) {
+		t.Fatal("default policy must enable karma remediation")
+	}
+	ev := published[0]
+	if ev.Type != EventRulesTighten || ev.Payload["world_id"] != "w1" || ev.Payload["strictness"] != 1.5 {
+		t.Errorf("unexpected request %s %v", ev.Type, ev.Payload)
+	}
+
+	// Cooldown of the action
+	if r.Remediate(context.Background(), anomaly) {
+		t.Error("remediation repeated within cooldown")
+	}
+	now = now.Add(5 * time.Minute)
+	if !r.Remediate(context.Background(), anomaly) {
+		t.Error("remediation must resume after the cooldown")
+	}
+
+	// Disabled actions, disabled world policies and unknown anomalies are only reported
+	for _, skipped := range []WorldHealth{
+		{WorldID: "w1", AnomalyType: AnomalyCoreResonance},
+		{WorldID: "w2", AnomalyType: AnomalySpatialIntegrity},
+		{WorldID: "w1", AnomalyType: "unknown"},
+	} {
+		if r.Remediate(context.Background(), skipped) {
+			t.Errorf("unexpected remediation of %+v", skipped)
+		}
+	}
+	if !r.Remediate(context.Background(), WorldHealth{WorldID: "w3", AnomalyType: AnomalySpatialIntegrity}) {
+		t.Error("default policy must apply to worlds without their own")
+	}
+	if last := published[len(published)-1]; last.Type != EventGeometryRevalidation {
+		t.Errorf("expected geometry revalidation, got %s", last.Type)
+	}
+}
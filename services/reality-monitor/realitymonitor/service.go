@@ -3,23 +3,39 @@ package realitymonitor
 import (
 	"context"
 	"encoding/json"
-	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
 	"time"
 
 	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+	"multiverse-core.io/shared/oracle"
 )
 
 // Service represents the Reality Monitor service
 type Service struct {
-	eventBus *eventbus.EventBus
-	state    *State
-	ctx      context.Context
-	cancel   context.CancelFunc
+	eventBus   *eventbus.EventBus
+	state      *State
+	critic     *Critic
+	karma      *karmaEngine
+	stats      *aggregator
+	remediator *Remediator
+	history    *metricsHistory
+	interval   time.Duration
+	server     *http.Server
+	ctx        context.Context
+	cancel     context.CancelFunc
 }
 
 // State holds the current state of the reality monitor
 type State struct {
+	mu      sync.Mutex
 	Metrics map[string]*WorldMetrics
+	// AnomalyCounts counts the anomalies detected per world since the service started
+	AnomalyCounts map[string]int
 }
 
 // WorldMetrics holds aggregated metrics for a world
@@ -32,25 +48,91 @@ type WorldMetrics struct {
 	AnomalyDetected  bool
 	AnomalyType      string
 	AnomalyTimestamp time.Time
+
+	// Computed from the event stream every metrics interval
+	EventRate        float64
+	ViolationRatio   float64
+	NarrativeLatency float64
+	EntityChurn      float64
+
+	// reported is set once world.metrics.* delivered spatial integrity and core resonance
+	reported bool
 }
 
 // NewService creates a new Reality Monitor service
 func NewService(eventBus *eventbus.EventBus) *Service {
 	ctx, cancel := context.WithCancel(context.Background())
 
-	return &Service{
+	// Consistency audit interval, CRITIC_INTERVAL_MS (default 10 minutes)
+	interval := defaultAuditPeriod
+	if raw := os.Getenv("CRITIC_INTERVAL_MS"); raw != "" {
+		if ms, err := strconv.Atoi(raw); err == nil && ms > 0 {
+			interval = time.Duration(ms) * time.Millisecond
+		} else {
+			logging.Warnf("Invalid CRITIC_INTERVAL_MS value %q, using default %s", raw, interval)
+		}
+	}
+
+	// Metrics computation and anomaly check interval, METRICS_INTERVAL_MS (default 30 seconds)
+	metricsInterval := defaultMetricsPeriod
+	if raw := os.Getenv("METRICS_INTERVAL_MS"); raw != "" {
+		if ms, err := strconv.Atoi(raw); err == nil && ms > 0 {
+			metricsInterval = time.Duration(ms) * time.Millisecond
+		} else {
+			logging.Warnf("Invalid METRICS_INTERVAL_MS value %q, using default %s", raw, metricsInterval)
+		}
+	}
+
+	// Karma decay half-life, KARMA_HALF_LIFE_MS (default 24 hours)
+	karmaHalfLife := defaultKarmaHalfLife
+	if raw := os.Getenv("KARMA_HALF_LIFE_MS"); raw != "" {
+		if ms, err := strconv.Atoi(raw); err == nil && ms > 0 {
+			karmaHalfLife = time.Duration(ms) * time.Millisecond
+		} else {
+			logging.Warnf("Invalid KARMA_HALF_LIFE_MS value %q, using default %s", raw, karmaHalfLife)
+		}
+	}
+
+	port := os.Getenv("REALITY_MONITOR_PORT")
+	if port == "" {
+		port = "8089"
+	}
+
+	service := &Service{
 		eventBus: eventBus,
 		state: &State{
-			Metrics: make(map[string]*WorldMetrics),
+			Metrics:       make(map[string]*WorldMetrics),
+			AnomalyCounts: make(map[string]int),
 		},
-		ctx:    ctx,
-		cancel: cancel,
+		critic:     NewCritic(oracle.NewClient(), eventBus, interval),
+		karma:      newKarmaEngine(karmaHalfLife),
+		stats:      newAggregator(),
+		remediator: NewRemediator(eventBus),
+		history:    newMetricsHistory(),
+		interval:   metricsInterval,
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+	service.server = &http.Server{
+		Addr:    ":" + port,
+		Handler: service.NewRouter(),
 	}
+	return service
+}
+
+// UsePolicyStorage enables anomaly remediation with the per-world policies stored in MinIO
+func (s *Service) UsePolicyStorage(client storage.ObjectStorage) {
+	s.remediator.UseStorage(client)
+}
+
+// UseHistoryStorage persists the metrics history of the worlds to MinIO
+func (s *Service) UseHistoryStorage(client storage.ObjectStorage) {
+	s.history.storage = client
 }
 
 // Start starts the Reality Monitor service
 func (s *Service) Start() error {
-	log.Println("Starting Reality Monitor service...")
+	logging.Infof("Starting Reality Monitor service...")
 
 	// Subscribe to world metrics events
 	go s.eventBus.Subscribe(s.ctx, "world.metrics.*", "reality-monitor-group", s.handleWorldMetricsEvent)
@@ -58,30 +140,84 @@ func (s *Service) Start() error {
 	// Subscribe to system events for anomaly detection
 	go s.eventBus.Subscribe(s.ctx, "reality.anomaly.detected", "reality-monitor-group", s.handleAnomalyEvent)
 
+	// Sample canon facts, narratives and entity states for consistency audits
+	go s.eventBus.Subscribe(s.ctx, eventbus.TopicWorldEvents, "reality-monitor-critic-world", s.critic.Observe)
+	go s.eventBus.Subscribe(s.ctx, eventbus.TopicNarrativeOutput, "reality-monitor-critic-narrative", s.critic.Observe)
+	go s.eventBus.Subscribe(s.ctx, eventbus.TopicSystemEvents, "reality-monitor-critic-system", s.critic.Observe)
+
+	// Violations, completed quests, NPC interactions and BanOfWorld karma feed the karma of players and worlds
+	for _, topic := range []string{
+		eventbus.TopicWorldEvents,
+		eventbus.TopicPlayerEvents,
+		eventbus.TopicGameEvents,
+	} {
+		go s.eventBus.Subscribe(s.ctx, topic, "reality-monitor-karma-"+topic, s.handleKarmaEvent)
+	}
+
+	// World metrics are computed from the raw event stream
+	for _, topic := range []string{
+		eventbus.TopicWorldEvents,
+		eventbus.TopicPlayerEvents,
+		eventbus.TopicGameEvents,
+		eventbus.TopicNarrativeOutput,
+		eventbus.TopicSystemEvents,
+	} {
+		topic := topic
+		go s.eventBus.Subscribe(s.ctx, topic, "reality-monitor-metrics-"+topic, func(event eventbus.Event) {
+			s.stats.observe(topic, event)
+		})
+	}
+
+	if err := s.history.load(); err != nil {
+		logging.Infof("Metrics history not loaded: %v", err)
+	}
 	go s.run()
+	go s.critic.Run(s.ctx)
 
-	log.Println("Reality Monitor service started successfully")
+	go func() {
+		logging.Infof("Reality Monitor admin API listening on %s", s.server.Addr)
+		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logging.Errorf("Admin API server error: %v", err)
+		}
+	}()
+
+	logging.Infof("Reality Monitor service started successfully")
 	return nil
 }
 
 // Stop stops the Reality Monitor service
 func (s *Service) Stop() error {
 	s.cancel()
-	log.Println("Reality Monitor service stopped")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.server.Shutdown(ctx); err != nil {
+		logging.Errorf("Admin API shutdown error: %v", err)
+	}
+	s.history.dump()
+
+	logging.Infof("Reality Monitor service stopped")
 	return nil
 }
 
 // run runs the main loop of the service
 func (s *Service) run() {
-	ticker := time.NewTicker(30 * time.Second) // Check every 30 seconds
+	ticker := time.NewTicker(s.interval)
 	defer ticker.Stop()
+	dumpTicker := time.NewTicker(defaultHistoryDumpPeriod)
+	defer dumpTicker.Stop()
 
 	for {
 		select {
 		case <-s.ctx.Done():
 			return
 		case <-ticker.C:
+			s.applyStats(s.stats.flush())
+			s.refreshKarma()
+			s.recordHistory()
 			s.checkForAnomalies()
+		case <-dumpTicker.C:
+			s.history.dump()
 		}
 	}
 }
@@ -93,26 +229,43 @@ func (s *Service) handleWorldMetricsEvent(event eventbus.Event) {
 	// Convert payload to JSON bytes then back to map for proper parsing
 	payloadBytes, err := json.Marshal(event.Payload)
 	if err != nil {
-		log.Printf("Error marshaling event payload: %v", err)
+		logging.Errorf("Error marshaling event payload: %v", err)
 		return
 	}
 
 	// Parse the event payload
 	if err := json.Unmarshal(payloadBytes, &metrics); err != nil {
-		log.Printf("Error parsing world metrics event: %v", err)
+		logging.Errorf("Error parsing world metrics event: %v", err)
 		return
 	}
 
-	// Update metrics in state
+	// Karma entropy is derived from the karma engine when players of the world have karma
+	if entropy, ok := s.karma.entropy(metrics.WorldID); ok {
+		metrics.KarmaEntropy = entropy
+	}
+	if metrics.LastUpdated.IsZero() {
+		metrics.LastUpdated = time.Now()
+	}
+
+	// Update metrics in state, keeping the metrics computed from the event stream
+	metrics.reported = true
+	s.state.mu.Lock()
+	if previous, exists := s.state.Metrics[metrics.WorldID]; exists {
+		metrics.EventRate = previous.EventRate
+		metrics.ViolationRatio = previous.ViolationRatio
+		metrics.NarrativeLatency = previous.NarrativeLatency
+		metrics.EntityChurn = previous.EntityChurn
+	}
 	s.state.Metrics[metrics.WorldID] = &metrics
+	s.state.mu.Unlock()
 
-	log.Printf("Updated metrics for world %s: spatial=%f, karma=%f, resonance=%f",
+	logging.Infof("Updated metrics for world %s: spatial=%f, karma=%f, resonance=%f",
 		metrics.WorldID, metrics.SpatialIntegrity, metrics.KarmaEntropy, metrics.CoreResonance)
 }
 
 // handleAnomalyEvent handles anomaly detection events
 func (s *Service) handleAnomalyEvent(event eventbus.Event) {
-	log.Printf("Received anomaly detection event: %s", event.Type)
+	logging.Infof("Received anomaly detection event: %s", event.Type)
 
 	// Process anomaly event
 	// This could trigger alerts, notifications, or other actions
@@ -120,50 +273,65 @@ func (s *Service) handleAnomalyEvent(event eventbus.Event) {
 
 // checkForAnomalies performs periodic anomaly detection
 func (s *Service) checkForAnomalies() {
-	log.Println("Checking for anomalies...")
+	logging.Infof("Checking for anomalies...")
 
+	// Anomalies are collected under the lock and published after it is released
+	var anomalies []WorldHealth
+	s.state.mu.Lock()
 	for worldID, metrics := range s.state.Metrics {
 		if s.isAnomaly(metrics) {
-			// Prepare anomaly data as map for payload
-			anomalyData := map[string]interface{}{
-				"world_id":     worldID,
-				"anomaly_type": metrics.AnomalyType,
-				"timestamp":    time.Now().Format(time.RFC3339),
-			}
-
-			// Publish anomaly detected event
-			anomalyEvent := eventbus.NewEvent("reality.anomaly.detected", "reality-monitor", worldID, anomalyData)
-
-			if err := s.eventBus.PublishSystemEvent(s.ctx, anomalyEvent); err != nil {
-				log.Printf("Failed to publish anomaly event: %v", err)
-			} else {
-				log.Printf("Published anomaly detected event for world %s", worldID)
-			}
+			s.state.AnomalyCounts[worldID]++
+			anomalies = append(anomalies, s.state.health(worldID, metrics))
+		}
+	}
+	s.state.mu.Unlock()
+
+	for _, anomaly := range anomalies {
+		// Prepare anomaly data as map for payload
+		anomalyData := map[string]interface{}{
+			"world_id":     anomaly.WorldID,
+			"anomaly_type": anomaly.AnomalyType,
+			"timestamp":    time.Now().Format(time.RFC3339),
 		}
+
+		// Publish anomaly detected event
+		anomalyEvent := eventbus.NewEvent("reality.anomaly.detected", "reality-monitor", anomaly.WorldID, anomalyData)
+
+		if err := s.eventBus.PublishSystemEvent(s.ctx, anomalyEvent); err != nil {
+			logging.Errorf("Failed to publish anomaly event: %v", err)
+		} else {
+			logging.Infof("Published anomaly detected event for world %s", anomaly.WorldID)
+		}
+
+		// Remediation requests are sent to other services as the world policy allows
+		s.remediator.Remediate(s.ctx, anomaly)
 	}
 }
 
-// isAnomaly determines if metrics indicate an anomaly
+// isAnomaly determines if metrics indicate an anomaly, checking the bounds
+// against the recent trend of the world rather than a single sample
 func (s *Service) isAnomaly(metrics *WorldMetrics) bool {
-	// Check for spatial integrity anomalies
-	if metrics.SpatialIntegrity < 0.1 || metrics.SpatialIntegrity > 0.9 {
-		metrics.AnomalyType = "spatial_integrity"
+	trend := s.trend(metrics)
+
+	// Check for spatial integrity anomalies; worlds known only from the event stream have none reported
+	if trend.Reported && (trend.SpatialIntegrity < 0.1 || trend.SpatialIntegrity > 0.9) {
+		metrics.AnomalyType = AnomalySpatialIntegrity
 		metrics.AnomalyDetected = true
 		metrics.AnomalyTimestamp = time.Now()
 		return true
 	}
 
 	// Check for karma entropy anomalies
-	if metrics.KarmaEntropy > 0.9 {
-		metrics.AnomalyType = "karma_entropy"
+	if trend.KarmaEntropy > 0.9 {
+		metrics.AnomalyType = AnomalyKarmaEntropy
 		metrics.AnomalyDetected = true
 		metrics.AnomalyTimestamp = time.Now()
 		return true
 	}
 
 	// Check for core resonance anomalies
-	if metrics.CoreResonance < 0.3 || metrics.CoreResonance > 1.0 {
-		metrics.AnomalyType = "core_resonance"
+	if trend.Reported && (trend.CoreResonance < 0.3 || trend.CoreResonance > 1.0) {
+		metrics.AnomalyType = AnomalyCoreResonance
 		metrics.AnomalyDetected = true
 		metrics.AnomalyTimestamp = time.Now()
 		return true
@@ -176,13 +344,38 @@ func (s *Service) isAnomaly(metrics *WorldMetrics) bool {
 	return false
 }
 
+// trend returns the average of the latest samples of the world once the history holds
+// trendSamples of them, and the current metrics before that
+func (s *Service) trend(metrics *WorldMetrics) MetricSample {
+	current := sampleOf(metrics, time.Now())
+	if s.history == nil {
+		return current
+	}
+	samples := s.history.recent(metrics.WorldID, trendSamples)
+	if len(samples) < trendSamples {
+		return current
+	}
+	trend := average(current.Timestamp, samples)
+	// A world that stopped reporting keeps no stale spatial readings
+	trend.Reported = trend.Reported && current.Reported
+	return trend
+}
+
 // GetWorldMetrics returns metrics for a specific world
 func (s *Service) GetWorldMetrics(worldID string) (*WorldMetrics, bool) {
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
 	metrics, exists := s.state.Metrics[worldID]
 	return metrics, exists
 }
 
 // GetAllMetrics returns all world metrics
 func (s *Service) GetAllMetrics() map[string]*WorldMetrics {
-	return s.state.Metrics
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+	all := make(map[string]*WorldMetrics, len(s.state.Metrics))
+	for worldID, metrics := range s.state.Metrics {
+		all[worldID] = metrics
+	}
+	return all
 }
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
 	"multiverse-core.io/shared/minio"
 )
 
@@ -30,7 +31,7 @@ func NewEngine(bus *eventbus.EventBus, minioClient *minio.Client) *Engine {
 		minioClient: minioClient,
 		cache:       &sync.Map{},
 		rand:        rand.New(rand.NewSource(time.Now().UnixNano())),
-		logger:      log.New(log.Writer(), "RuleEngine: ", log.LstdFlags|log.Lshortfile),
+		logger:      logging.StdLogger("RuleEngine"),
 	}
 }
 
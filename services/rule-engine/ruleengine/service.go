@@ -6,6 +6,7 @@ import (
 	"log"
 
 	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
 	"multiverse-core.io/shared/minio"
 )
 
@@ -49,7 +50,7 @@ func NewService(cfg Config) (*Service, error) {
 	return &Service{
 		engine: engine,
 		bus:    bus,
-		logger: log.New(log.Writer(), "RuleEngineService: ", log.LstdFlags|log.Lshortfile),
+		logger: logging.StdLogger("RuleEngineService"),
 	}, nil
 }
 
@@ -2,46 +2,45 @@
 package main
 
 import (
-	"context"
 	"log"
-	"os"
-	"os/signal"
-	"strings"
-	"syscall"
 
-	"multiverse-core.io/shared/eventbus"
 	"multiverse-core.io/services/semantic-memory/semanticmemory"
+	"multiverse-core.io/shared/config"
+	"multiverse-core.io/shared/service"
 )
 
 func main() {
-	// Initialize event bus
-	brokers := strings.Split(os.Getenv("KAFKA_BROKERS"), ",")
-	if len(brokers) == 0 || brokers[0] == "" {
-		brokers = []string{"redpanda:9092"}
-	}
-	bus := eventbus.NewEventBus(brokers)
+	app := service.Setup("semantic-memory", config.OracleOptions, config.RegistryOptions, []config.Option{
+		{Env: "SEMANTIC_PORT", Default: "8080", Type: config.TypeInt, Positive: true},
+		{Env: "SEMANTIC_VECTOR_BACKEND", Default: "chroma", Usage: "chroma, qdrant or pgvector"},
+		{Env: "SEMANTIC_BATCH_SIZE", Default: "100", Type: config.TypeInt, Positive: true},
+		{Env: "SEMANTIC_FLUSH_INTERVAL_MS", Default: "500", Type: config.TypeMillis, Positive: true},
+		{Env: "SEMANTIC_QUEUE_SIZE", Default: "10000", Type: config.TypeInt, Positive: true},
+		{Env: "SEMANTIC_DEDUP_WINDOW_MS", Default: "300000", Type: config.TypeMillis, Positive: true, Usage: "how long indexed event IDs and payload hashes are remembered"},
+		{Env: "SEMANTIC_DEDUP_MAX_ENTRIES", Default: "100000", Type: config.TypeInt, Positive: true, Usage: "events remembered for deduplication"},
+		{Env: "CHROMA_URL", Default: "http://chromadb:8000", Type: config.TypeURL},
+		{Env: "CHROMA_USE_V2", Default: "false"},
+		{Env: "CHROMA_COLLECTION_MODE", Default: "shared"},
+		{Env: "CHROMA_COLLECTION_NAME", Default: "world_memory"},
+		{Env: "EMBEDING_URL", Default: "http://qwen3-service:11434", Type: config.TypeURL},
+		{Env: "EMBEDING_MODEL", Default: "nomic-embed-text:latest"},
+		{Env: "QDRANT_URL", Default: "http://qdrant:6333", Type: config.TypeURL},
+		{Env: "QDRANT_COLLECTION", Default: "world_memory"},
+		{Env: "QDRANT_API_KEY", Secret: true},
+		{Env: "PGVECTOR_DSN", Secret: true},
+		{Env: "PGVECTOR_TABLE", Default: "semantic_documents"},
+		{Env: "NEO4J_URI", Default: "neo4j://neo4j:7687", Type: config.TypeURL},
+		{Env: "NEO4J_USER", Default: "neo4j"},
+		{Env: "NEO4J_PASSWORD", Secret: true},
+		{Env: "RELATION_RULES_BUCKET", Default: "gnue-configs"},
+		{Env: "RELATION_RULES_KEY", Default: "semantic-memory/relationship_rules.yaml"},
+	})
+	app.UseOracleAudit()
 
-	// Create and run service
-	service, err := semanticmemory.NewService(bus)
+	memory, err := semanticmemory.NewService(app.Bus())
 	if err != nil {
 		log.Fatal("Failed to initialize SemanticMemory:", err)
 	}
 
-	// Handle shutdown
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	go func() {
-		<-sigChan
-		log.Println("Shutting down SemanticMemory...")
-		cancel()
-	}()
-
-	log.Println("SemanticMemory starting...")
-	if err := service.Run(ctx); err != nil && err != context.Canceled {
-		log.Fatal("Service failed:", err)
-	}
-	log.Println("SemanticMemory stopped.")
+	app.Run(memory)
 }
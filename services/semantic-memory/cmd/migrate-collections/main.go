@@ -0,0 +1,44 @@
+// Command migrate-collections splits the shared ChromaDB collection into per-world collections.
+//
+// Uses the same environment as SemanticMemory (CHROMA_URL, CHROMA_COLLECTION_NAME).
+// After migration run the service with CHROMA_COLLECTION_MODE=per_world.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"multiverse-core.io/services/semantic-memory/semanticmemory"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "only count documents per world, do not write")
+	deleteSource := flag.Bool("delete-source", false, "delete moved documents from the shared collection")
+	batch := flag.Int("batch", 500, "documents per read request")
+	flag.Parse()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	client := semanticmemory.NewChromaClient()
+	defer client.Close()
+
+	report, err := client.SplitByWorld(ctx, semanticmemory.SplitOptions{
+		BatchSize:    *batch,
+		DryRun:       *dryRun,
+		DeleteSource: *deleteSource,
+	})
+	if report != nil {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(report)
+	}
+	if err != nil {
+		log.Fatalf("Migration failed: %v", err)
+	}
+}
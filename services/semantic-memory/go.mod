@@ -8,5 +8,6 @@ require (
 	github.com/gorilla/mux v1.8.1
 	github.com/segmentio/kafka-go v0.4.49
 	github.com/google/uuid v1.6.0
+	github.com/jackc/pgx/v5 v5.7.2
 	github.com/stretchr/testify v1.11.1
 )
@@ -3,12 +3,12 @@ package semanticmemory
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
 	"sort"
 	"time"
 
 	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
 )
 
 // StructuredContextRequest запрос структурированного контекста
@@ -42,7 +42,7 @@ func (s *Service) HandleStructuredContext(w http.ResponseWriter, r *http.Request
 	// 1. Загружаем кэш сущностей из Neo4j
 	entityCache, err := s.indexer.neo4j.GetEntityCache(req.EntityIDs)
 	if err != nil {
-		log.Printf("Failed to load entity cache: %v", err)
+		logging.Errorf("Failed to load entity cache: %v", err)
 		// Не блокируем запрос, используем fallback
 		entityCache = buildFallbackEntityCache(req.EntityIDs)
 	}
@@ -52,7 +52,7 @@ func (s *Service) HandleStructuredContext(w http.ResponseWriter, r *http.Request
 	for _, entityID := range req.EntityIDs {
 		events, err := s.indexer.GetEventsForEntities(ctx, []string{entityID}, req.WorldID, parseTimeRange(req.TimeRange), req.MaxEvents)
 		if err != nil {
-			log.Printf("Warning: failed to search events for entity %s: %v", entityID, err)
+			logging.Warnf("Failed to search events for entity %s: %v", entityID, err)
 			continue
 		}
 		allEvents = append(allEvents, events...)
@@ -79,7 +79,7 @@ func (s *Service) HandleStructuredContext(w http.ResponseWriter, r *http.Request
 	// 6. Возвращаем ответ
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	if err := json.NewEncoder(w).Encode(structured); err != nil {
-		log.Printf("Failed to encode structured context response: %v", err)
+		logging.Errorf("Failed to encode structured context response: %v", err)
 		http.Error(w, "internal_error", http.StatusInternalServerError)
 	}
 }
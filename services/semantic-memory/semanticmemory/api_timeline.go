@@ -0,0 +1,303 @@
+// Package semanticmemory handles the entity timeline API endpoint.
+package semanticmemory
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+)
+
+const (
+	defaultTimelinePageSize = 20
+	maxTimelinePageSize     = 100
+	// maxTimelineEvents ограничивает выборку из Neo4j для одного запроса хронологии
+	maxTimelineEvents = 1000
+	// maxTimelineSummaries ограничивает кэш сводок Oracle
+	maxTimelineSummaries = 500
+)
+
+// TimelineEntry — событие хронологии в человекочитаемом виде
+type TimelineEntry struct {
+	TimelineEvent
+	Text string `json:"text"`
+}
+
+// TimelineResponse — страница хронологии сущности
+type TimelineResponse struct {
+	EntityID    string                `json:"entity_id"`
+	From        time.Time             `json:"from"`
+	To          time.Time             `json:"to"`
+	Page        int                   `json:"page"`
+	PageSize    int                   `json:"page_size"`
+	TotalEvents int                   `json:"total_events"`
+	HasMore     bool                  `json:"has_more"`
+	Entries     []TimelineEntry       `json:"entries"`
+	Summary     string                `json:"summary,omitempty"`
+	Entities    map[string]EntityInfo `json:"entities"`
+}
+
+// timelineSummaryCache кэширует сводки Oracle по набору событий страницы
+type timelineSummaryCache struct {
+	entries map[string]string
+	mutex   sync.RWMutex
+}
+
+func (c *timelineSummaryCache) get(key string) (string, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	summary, ok := c.entries[key]
+	return summary, ok
+}
+
+func (c *timelineSummaryCache) set(key, summary string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.entries == nil || len(c.entries) >= maxTimelineSummaries {
+		c.entries = make(map[string]string)
+	}
+	c.entries[key] = summary
+}
+
+// HandleTimeline обрабатывает GET /v1/timeline — хронологию сущности для UI игрока.
+//
+// Query params:
+//
+//	?entity_id=ID (обязательный)
+//	&world_id=ID
+//	&from=RFC3339&to=RFC3339 или &time_range=last_24h (default: last_24h)
+//	&page=N (default: 1, страница 1 — самые свежие события)
+//	&page_size=N (default: 20, max: 100)
+//	&summarize=true — краткая сводка страницы от Oracle
+func (s *Service) HandleTimeline(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	entityID := query.Get("entity_id")
+	if entityID == "" {
+		writeError(w, "entity_id_required", http.StatusBadRequest)
+		return
+	}
+
+	from, to, err := parseTimelineRange(query.Get("from"), query.Get("to"), query.Get("time_range"))
+	if err != nil {
+		writeError(w, "invalid_time_range", http.StatusBadRequest)
+		return
+	}
+
+	page, err := parsePositiveInt(query.Get("page"), 1)
+	if err != nil {
+		writeError(w, "invalid_page", http.StatusBadRequest)
+		return
+	}
+	pageSize, err := parsePositiveInt(query.Get("page_size"), defaultTimelinePageSize)
+	if err != nil {
+		writeError(w, "invalid_page_size", http.StatusBadRequest)
+		return
+	}
+	if pageSize > maxTimelinePageSize {
+		pageSize = maxTimelinePageSize
+	}
+
+	// 1. Загружаем события сущности за период (Neo4j возвращает от новых к старым)
+	events, err := s.indexer.neo4j.GetEventsForEntities([]string{entityID}, query.Get("world_id"), from, maxTimelineEvents)
+	if err != nil {
+		logging.Errorf("Failed to load timeline events for %s: %v", entityID, err)
+		writeError(w, "failed_to_load_events", http.StatusInternalServerError)
+		return
+	}
+	events = FilterEventsByTimeRange(events, from, to)
+
+	// 2. Страница 1 — самые свежие события; внутри страницы — хронологический порядок
+	total := len(events)
+	start := (page - 1) * pageSize
+	if start > total {
+		start = total
+	}
+	end := start + pageSize
+	if end > total {
+		end = total
+	}
+	pageEvents := make([]eventbus.Event, end-start)
+	copy(pageEvents, events[start:end])
+
+	// 3. Загружаем имена участников и строим хронологию
+	entityCache := s.loadTimelineEntities(pageEvents)
+	structured := s.indexer.BuildStructuredContext(pageEvents, entityCache)
+
+	eventsByID := make(map[string]eventbus.Event, len(pageEvents))
+	for _, ev := range pageEvents {
+		eventsByID[ev.ID] = ev
+	}
+
+	entries := make([]TimelineEntry, 0, len(structured.Timeline))
+	for _, te := range structured.Timeline {
+		entries = append(entries, TimelineEntry{
+			TimelineEvent: te,
+			Text:          humanizeTimelineEvent(eventsByID[te.EventID], te, entityCache),
+		})
+	}
+
+	response := TimelineResponse{
+		EntityID:    entityID,
+		From:        from,
+		To:          to,
+		Page:        page,
+		PageSize:    pageSize,
+		TotalEvents: total,
+		HasMore:     end < total,
+		Entries:     entries,
+		Entities:    entityCache,
+	}
+
+	// 4. Опциональная сводка страницы от Oracle
+	if query.Get("summarize") == "true" && len(entries) > 0 {
+		summary, err := s.summarizeTimelinePage(r, entityID, entries)
+		if err != nil {
+			logging.Errorf("Failed to summarize timeline for %s: %v", entityID, err)
+		} else {
+			response.Summary = summary
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logging.Errorf("Failed to encode timeline response: %v", err)
+	}
+}
+
+// loadTimelineEntities загружает информацию об участниках событий
+func (s *Service) loadTimelineEntities(events []eventbus.Event) map[string]EntityInfo {
+	idSet := make(map[string]bool)
+	for _, ev := range events {
+		if id := extractStructuredEntityID(ev); id != "" {
+			idSet[id] = true
+		}
+		if id := extractStructuredTargetEntityID(ev); id != "" {
+			idSet[id] = true
+		}
+	}
+	ids := make([]string, 0, len(idSet))
+	for id := range idSet {
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return map[string]EntityInfo{}
+	}
+
+	cache, err := s.indexer.neo4j.GetEntityCache(ids)
+	if err != nil {
+		logging.Errorf("Failed to load entity cache: %v", err)
+		return buildFallbackEntityCache(ids)
+	}
+	return cache
+}
+
+// summarizeTimelinePage запрашивает у Oracle краткий пересказ страницы хронологии
+func (s *Service) summarizeTimelinePage(r *http.Request, entityID string, entries []TimelineEntry) (string, error) {
+	if s.oracle == nil {
+		return "", fmt.Errorf("oracle client not configured")
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(entityID))
+	for _, entry := range entries {
+		hash.Write([]byte(entry.EventID))
+	}
+	key := hex.EncodeToString(hash.Sum(nil))
+	if summary, ok := s.summaries.get(key); ok {
+		return summary, nil
+	}
+
+	var lines strings.Builder
+	for _, entry := range entries {
+		lines.WriteString("- ")
+		lines.WriteString(entry.Text)
+		lines.WriteString("\n")
+	}
+
+	systemPrompt := "Ты — летописец мира. Кратко перескажи события из жизни персонажа связным текстом в 2-3 предложения, " +
+		"от третьего лица, без выдуманных фактов."
+	userPrompt := fmt.Sprintf("События персонажа %s в хронологическом порядке:\n%s", entityID, lines.String())
+
+	summary, err := s.oracle.CallStructured(r.Context(), systemPrompt, userPrompt)
+	if err != nil {
+		return "", err
+	}
+	summary = strings.TrimSpace(summary)
+	s.summaries.set(key, summary)
+	return summary, nil
+}
+
+// humanizeTimelineEvent формирует человекочитаемую строку события:
+// описание из payload, если оно есть, иначе "{источник} {действие} {цель}"
+func humanizeTimelineEvent(ev eventbus.Event, te TimelineEvent, entityCache map[string]EntityInfo) string {
+	timestamp := te.Timestamp.Format("02.01 15:04")
+
+	for _, key := range []string{"narrative", "description"} {
+		if text, ok := ev.Payload[key].(string); ok && text != "" {
+			return fmt.Sprintf("%s — %s", timestamp, text)
+		}
+	}
+
+	parts := []string{timelineEntityName(te.Entities[0], entityCache), strings.ReplaceAll(getActionFromEventType(te.Type), "_", " ")}
+	if len(te.Entities) > 1 && te.Entities[1] != "" {
+		parts = append(parts, timelineEntityName(te.Entities[1], entityCache))
+	}
+	return fmt.Sprintf("%s — %s", timestamp, strings.Join(parts, " "))
+}
+
+// timelineEntityName возвращает имя сущности или её ID
+func timelineEntityName(entityID string, entityCache map[string]EntityInfo) string {
+	if info, ok := entityCache[entityID]; ok && info.Name != "" {
+		return info.Name
+	}
+	return entityID
+}
+
+// parseTimelineRange определяет границы периода: явные from/to (RFC3339) или time_range
+func parseTimelineRange(fromStr, toStr, rangeStr string) (time.Time, time.Time, error) {
+	to := time.Now().UTC()
+	if toStr != "" {
+		parsed, err := time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		to = parsed
+	}
+
+	if fromStr != "" {
+		from, err := time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		if from.After(to) {
+			return time.Time{}, time.Time{}, fmt.Errorf("from is after to")
+		}
+		return from, to, nil
+	}
+
+	if rangeStr == "" {
+		rangeStr = "last_24h"
+	}
+	return to.Add(-parseTimeRange(rangeStr)), to, nil
+}
+
+// parsePositiveInt разбирает положительное целое или возвращает значение по умолчанию
+func parsePositiveInt(raw string, fallback int) (int, error) {
+	if raw == "" {
+		return fallback, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value <= 0 {
+		return 0, fmt.Errorf("invalid positive integer %q", raw)
+	}
+	return value, nil
+}
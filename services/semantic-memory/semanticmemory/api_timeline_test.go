@@ -0,0 +1,56 @@
+package semanticmemory
+
+import (
+	"testing"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+)
+
+func TestHumanizeTimelineEvent(t *testing.T) {
+	ts := time.Date(2025, 3, 14, 9, 5, 0, 0, time.UTC)
+	cache := map[string]EntityInfo{
+		"player-1": {ID: "player-1", Name: "Вася"},
+		"npc-7":    {ID: "npc-7", Name: "Кузнец"},
+	}
+
+	withTarget := TimelineEvent{Timestamp: ts, Type: "npc.talked", Entities: []string{"player-1", "npc-7"}}
+	if got := humanizeTimelineEvent(eventbus.Event{}, withTarget, cache); got != "14.03 09:05 — Вася talked to Кузнец" {
+		t.Errorf("unexpected text: %q", got)
+	}
+
+	withoutTarget := TimelineEvent{Timestamp: ts, Type: "player.rested", Entities: []string{"player-1", ""}}
+	if got := humanizeTimelineEvent(eventbus.Event{}, withoutTarget, cache); got != "14.03 09:05 — Вася player rested" {
+		t.Errorf("unexpected text: %q", got)
+	}
+
+	described := eventbus.Event{Payload: map[string]any{"description": "Вася нашёл древний меч"}}
+	if got := humanizeTimelineEvent(described, withTarget, cache); got != "14.03 09:05 — Вася нашёл древний меч" {
+		t.Errorf("expected payload description, got %q", got)
+	}
+}
+
+func TestParseTimelineRange(t *testing.T) {
+	from, to, err := parseTimelineRange("2025-01-01T00:00:00Z", "2025-01-02T00:00:00Z", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if to.Sub(from) != 24*time.Hour {
+		t.Errorf("expected 24h window, got %s", to.Sub(from))
+	}
+
+	from, to, err = parseTimelineRange("", "", "last_7d")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if to.Sub(from) != 7*24*time.Hour {
+		t.Errorf("expected 7d window, got %s", to.Sub(from))
+	}
+
+	if _, _, err := parseTimelineRange("2025-01-02T00:00:00Z", "2025-01-01T00:00:00Z", ""); err == nil {
+		t.Error("expected error when from is after to")
+	}
+	if _, _, err := parseTimelineRange("yesterday", "", ""); err == nil {
+		t.Error("expected error for invalid from")
+	}
+}
@@ -7,10 +7,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"os"
+	"sync"
 	"time"
+
+	"multiverse-core.io/shared/logging"
+	"multiverse-core.io/shared/tracing"
 )
 
 // ChromaClient handles communication with ChromaDB via HTTP API.
@@ -18,8 +21,11 @@ import (
 type ChromaClient struct {
 	baseURL        string
 	httpClient     *http.Client
-	collectionID   string // Кэшируем ID коллекции
-	collectionName string // Имя коллекции
+	collectionName string // Имя общей коллекции (базовое имя в режиме per_world)
+	perWorld       bool   // Отдельная коллекция на каждый мир (CHROMA_COLLECTION_MODE=per_world)
+
+	mu            sync.Mutex
+	collectionIDs map[string]string // Кэшируем ID коллекций по имени
 }
 
 // Ensure ChromaClient implements SemanticStorage interface
@@ -35,17 +41,21 @@ func NewChromaClient() *ChromaClient {
 		baseURL: url,
 		// Настройте HTTP-клиент с таймаутами
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: tracing.NewTransport("chroma", nil),
 		},
-		collectionName: "world_memory", // Фиксированное имя коллекции
+		collectionName: chromaBaseCollectionName(),
+		perWorld:       chromaPerWorldMode(),
+		collectionIDs:  make(map[string]string),
 	}
 }
 
 // --- Внутренние структуры для JSON ---
 
 type chromaCollectionRequest struct {
-	Name     string                 `json:"name"`
-	Metadata map[string]interface{} `json:"metadata,omitempty"` // Используем map[string]interface{} для метаданных коллекции
+	Name        string                 `json:"name"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"` // Используем map[string]interface{} для метаданных коллекции
+	GetOrCreate bool                   `json:"get_or_create,omitempty"`
 }
 
 // Ответ на создание коллекции
@@ -64,56 +74,72 @@ type chromaCollectionItem struct {
 
 // --- Методы ChromaClient ---
 
-// getOrCreateCollectionID получает ID коллекции по имени, создавая её при необходимости.
-// Исправлено: GET /api/v1/collections возвращает массив.
-func (c *ChromaClient) getOrCreateCollectionID(ctx context.Context) (string, error) {
-	// Проверяем кэш
-	if c.collectionID != "" {
-		return c.collectionID, nil
-	}
-
-	// Сначала получаем список всех коллекций
+// listCollections возвращает все коллекции ChromaDB.
+// GET /api/v1/collections возвращает массив.
+func (c *ChromaClient) listCollections(ctx context.Context) ([]chromaCollectionItem, error) {
 	endpoint := fmt.Sprintf("%s/api/v1/collections", c.baseURL)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to create HTTP request to list collections: %w", err)
+		return nil, fmt.Errorf("failed to create HTTP request to list collections: %w", err)
 	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to execute list collections request: %w", err)
+		return nil, fmt.Errorf("failed to execute list collections request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body) // Читаем тело для лога
-		return "", fmt.Errorf("list collections request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("list collections request failed with status %d: %s", resp.StatusCode, string(body))
 	}
 
-	//log.Println(json.NewDecoder(resp.Body))
-	// ИСПРАВЛЕНО: Декодируем в слайс, а не в структуру с полем collections
 	var listResult []chromaCollectionItem
 	if err := json.NewDecoder(resp.Body).Decode(&listResult); err != nil {
-		return "", fmt.Errorf("failed to decode list collections response: %w", err)
+		return nil, fmt.Errorf("failed to decode list collections response: %w", err)
+	}
+	return listResult, nil
+}
+
+// findCollectionID получает ID существующей коллекции по имени, не создавая её.
+func (c *ChromaClient) findCollectionID(ctx context.Context, name string) (string, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if id, ok := c.collectionIDs[name]; ok {
+		return id, true, nil
 	}
 
-	// Ищем коллекцию по имени
-	for _, col := range listResult {
-		if col.Name == c.collectionName {
-			log.Printf("Found existing collection: %s with ID: %s", col.Name, col.ID)
-			c.collectionID = col.ID // Кэшируем ID
-			return c.collectionID, nil
+	collections, err := c.listCollections(ctx)
+	if err != nil {
+		return "", false, err
+	}
+	for _, col := range collections {
+		if col.Name == name {
+			c.collectionIDs[name] = col.ID // Кэшируем ID
+			return col.ID, true, nil
 		}
 	}
+	return "", false, nil
+}
+
+// getOrCreateCollectionID получает ID коллекции по имени, создавая её при необходимости.
+func (c *ChromaClient) getOrCreateCollectionID(ctx context.Context, name string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if id, ok := c.collectionIDs[name]; ok {
+		return id, nil
+	}
 
-	// Коллекция не найдена, создаём её
-	log.Printf("Collection '%s' not found, creating it...", c.collectionName)
-	endpoint = fmt.Sprintf("%s/api/v1/collections", c.baseURL)
+	// get_or_create возвращает существующую коллекцию или создаёт новую
+	logging.Infof("Resolving collection '%s' (get or create)...", name)
+	endpoint := fmt.Sprintf("%s/api/v1/collections", c.baseURL)
 	payload := chromaCollectionRequest{
-		Name: c.collectionName,
-		// Metadata: nil, // nil будет передано как null в JSON, что обычно нормально. Или можно передать пустой map.
-		Metadata: map[string]interface{}{}, // Пустой map для ясности, если сервер ожидает объект
+		Name:        name,
+		Metadata:    map[string]interface{}{}, // Пустой map для ясности, если сервер ожидает объект
+		GetOrCreate: true,
 	}
 
 	jsonData, err := json.Marshal(payload)
@@ -121,19 +147,19 @@ func (c *ChromaClient) getOrCreateCollectionID(ctx context.Context) (string, err
 		return "", fmt.Errorf("failed to marshal create collection request: %w", err)
 	}
 
-	req, err = http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return "", fmt.Errorf("failed to create HTTP request for create collection: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err = c.httpClient.Do(req)
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to execute create collection request: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusCreated { // Обычно 201 для созданного ресурса
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body) // Читаем тело для лога
 		return "", fmt.Errorf("create collection request failed with status %d: %s", resp.StatusCode, string(body))
 	}
@@ -144,115 +170,154 @@ func (c *ChromaClient) getOrCreateCollectionID(ctx context.Context) (string, err
 		return "", fmt.Errorf("failed to decode create collection response: %w", err)
 	}
 
-	log.Printf("Created collection: %s with ID: %s", createResult.Name, createResult.ID)
-	c.collectionID = createResult.ID // Кэшируем ID
-	return c.collectionID, nil
+	logging.Infof("Using collection: %s with ID: %s", createResult.Name, createResult.ID)
+	c.collectionIDs[name] = createResult.ID // Кэшируем ID
+	return createResult.ID, nil
 }
 
-// UpsertDocument adds or updates a document in ChromaDB via HTTP POST to /api/v1/collections/{collection_id}/upsert.
-func (c *ChromaClient) UpsertDocument(ctx context.Context, entityID string, text string, metadata map[string]interface{}) error {
-	// Получаем ID коллекции (создаём, если нужно)
-	collectionID, err := c.getOrCreateCollectionID(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to get/create collection ID for upsert: %w", err)
+// collectionNameFor возвращает коллекцию для документа мира: общую или коллекцию мира в режиме per_world.
+func (c *ChromaClient) collectionNameFor(worldID string) string {
+	if !c.perWorld {
+		return c.collectionName
 	}
+	return ChromaWorldCollectionName(c.collectionName, worldID)
+}
 
-	endpoint := fmt.Sprintf("%s/api/v1/collections/%s/upsert", c.baseURL, collectionID)
-
-	payload := map[string]interface{}{
-		"ids":       []string{entityID},
-		"documents": []string{text},
-		"metadatas": []map[string]interface{}{metadata}, // ChromaDB ожидает массив метаданных
-		// "embeddings": nil, // Полагаемся на серверный эмбеддер
+// readCollectionIDs определяет коллекции для чтения по where-фильтру.
+// В режиме per_world запрос с world_id идёт только в коллекцию этого мира
+// (которой может ещё не быть), запрос без world_id — во все коллекции миров.
+func (c *ChromaClient) readCollectionIDs(ctx context.Context, where map[string]interface{}) ([]string, error) {
+	if !c.perWorld {
+		id, err := c.getOrCreateCollectionID(ctx, c.collectionName)
+		if err != nil {
+			return nil, err
+		}
+		return []string{id}, nil
 	}
 
-	jsonData, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("failed to marshal upsert request: %w", err)
+	if worldID := chromaWorldID(where); worldID != "" {
+		id, found, err := c.findCollectionID(ctx, c.collectionNameFor(worldID))
+		if err != nil || !found {
+			return nil, err
+		}
+		return []string{id}, nil
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(jsonData))
+	collections, err := c.listCollections(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to create HTTP request: %w", err)
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var ids []string
+	for _, col := range collections {
+		if isChromaWorldCollection(c.collectionName, col.Name) {
+			c.collectionIDs[col.Name] = col.ID
+			ids = append(ids, col.ID)
+		}
 	}
-	req.Header.Set("Content-Type", "application/json")
+	return ids, nil
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to execute upsert request: %w", err)
-	}
-	defer resp.Body.Close()
+// UpsertDocument adds or updates a document in ChromaDB via HTTP POST to /api/v1/collections/{collection_id}/upsert.
+func (c *ChromaClient) UpsertDocument(ctx context.Context, entityID string, text string, metadata map[string]interface{}) error {
+	return c.UpsertDocuments(ctx, []Document{{ID: entityID, Text: text, Metadata: metadata}})
+}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body) // Читаем тело для лога
-		return fmt.Errorf("upsert request failed with status %d: %s", resp.StatusCode, string(body))
+// UpsertDocuments adds or updates a batch of documents in ChromaDB.
+// In per_world mode documents are grouped by metadata world_id, one upsert request per world collection.
+func (c *ChromaClient) UpsertDocuments(ctx context.Context, docs []Document) error {
+	if len(docs) == 0 {
+		return nil
 	}
 
-	// log.Printf("Successfully upserted document for entity: %s", entityID)
+	if !c.perWorld {
+		return c.upsertToCollection(ctx, c.collectionName, docs, nil)
+	}
+	for worldID, group := range groupDocumentsByWorld(docs) {
+		if err := c.upsertToCollection(ctx, c.collectionNameFor(worldID), group, nil); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-// GetDocuments retrieves documents by their IDs via HTTP POST to /api/v1/collections/{collection_id}/get.
-func (c *ChromaClient) GetDocuments(ctx context.Context, entityIDs []string) (map[string]string, error) {
-	// Получаем ID коллекции
-	collectionID, err := c.getOrCreateCollectionID(ctx)
+// upsertToCollection upserts documents into the named collection with a single request.
+// Embeddings are optional: without them ChromaDB embeds documents on the server side.
+func (c *ChromaClient) upsertToCollection(ctx context.Context, collectionName string, docs []Document, embeddings [][]float32) error {
+	collectionID, err := c.getOrCreateCollectionID(ctx, collectionName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get collection ID for get: %w", err)
+		return fmt.Errorf("failed to get/create collection ID for batch upsert: %w", err)
 	}
 
-	endpoint := fmt.Sprintf("%s/api/v1/collections/%s/get", c.baseURL, collectionID)
+	endpoint := fmt.Sprintf("%s/api/v1/collections/%s/upsert", c.baseURL, collectionID)
+
+	ids := make([]string, len(docs))
+	texts := make([]string, len(docs))
+	metadatas := make([]map[string]interface{}, len(docs))
+	for i, doc := range docs {
+		ids[i] = doc.ID
+		texts[i] = doc.Text
+		metadatas[i] = doc.Metadata
+	}
 
-	// ChromaDB v2 API для Get позволяет фильтровать по ID через тело запроса
 	payload := map[string]interface{}{
-		"ids": entityIDs,
-		// "where": nil, // Дополнительные фильтры
-		// "where_document": nil, // Фильтры по документу
-		"include": []string{"documents", "metadatas", "embeddings"}, // Что включать в ответ
-		// Для простоты, ChromaDB обычно возвращает все по умолчанию или документы, если не указано иное
+		"ids":       ids,
+		"documents": texts,
+		"metadatas": metadatas,
+	}
+	if len(embeddings) == len(docs) {
+		payload["embeddings"] = embeddings
 	}
-	log.Println(payload)
 
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal get request: %w", err)
+		return fmt.Errorf("failed to marshal batch upsert request: %w", err)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(jsonData))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+		return fmt.Errorf("failed to create HTTP request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute get request: %w", err)
+		return fmt.Errorf("failed to execute batch upsert request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body) // Читаем тело для лога
-		return nil, fmt.Errorf("get request failed with status %d: %s", resp.StatusCode, string(body))
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("batch upsert request failed with status %d: %s", resp.StatusCode, string(body))
 	}
 
-	var result struct {
-		Ids        []string                 `json:"ids"`
-		Documents  []string                 `json:"documents"`
-		Metadatas  []map[string]interface{} `json:"metadatas"`
-		Embeddings [][]float32              `json:"embeddings"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode get response: %w", err)
+	return nil
+}
+
+// GetDocuments retrieves documents by their IDs via HTTP POST to /api/v1/collections/{collection_id}/get.
+// In per_world mode all world collections are searched, since IDs carry no world.
+func (c *ChromaClient) GetDocuments(ctx context.Context, entityIDs []string) (map[string]string, error) {
+	collectionIDs, err := c.readCollectionIDs(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection ID for get: %w", err)
 	}
 
-	// Create map from IDs to documents
 	contexts := make(map[string]string)
-	// Ensure lengths match to avoid panic
-	if len(result.Ids) != len(result.Documents) {
-		return nil, fmt.Errorf("mismatched lengths in ChromaDB get response: ids=%d, docs=%d", len(result.Ids), len(result.Documents))
-	}
+	for _, collectionID := range collectionIDs {
+		result, err := c.collectionGet(ctx, collectionID, map[string]interface{}{
+			"ids":     entityIDs,
+			"include": []string{"documents", "metadatas"},
+		})
+		if err != nil {
+			return nil, err
+		}
 
-	for i, id := range result.Ids {
-		if i < len(result.Documents) {
+		// Ensure lengths match to avoid panic
+		if len(result.Ids) != len(result.Documents) {
+			return nil, fmt.Errorf("mismatched lengths in ChromaDB get response: ids=%d, docs=%d", len(result.Ids), len(result.Documents))
+		}
+		for i, id := range result.Ids {
 			contexts[id] = result.Documents[i]
 		}
 	}
@@ -262,27 +327,16 @@ func (c *ChromaClient) GetDocuments(ctx context.Context, entityIDs []string) (ma
 
 // chromaGetResponse is the shared response structure for ChromaDB /get endpoint.
 type chromaGetResponse struct {
-	Ids       []string                 `json:"ids"`
-	Documents []string                 `json:"documents"`
-	Metadatas []map[string]interface{} `json:"metadatas"`
+	Ids        []string                 `json:"ids"`
+	Documents  []string                 `json:"documents"`
+	Metadatas  []map[string]interface{} `json:"metadatas"`
+	Embeddings [][]float32              `json:"embeddings,omitempty"`
 }
 
-// chromaGet executes a GET request against the collection using a metadata where-filter.
-// Uses /api/v1/collections/{id}/get which supports where-filtering without requiring embeddings.
-func (c *ChromaClient) chromaGet(ctx context.Context, where map[string]interface{}, limit int) (*chromaGetResponse, error) {
-	collectionID, err := c.getOrCreateCollectionID(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get collection ID: %w", err)
-	}
-
+// collectionGet executes POST /api/v1/collections/{id}/get with the given request body.
+func (c *ChromaClient) collectionGet(ctx context.Context, collectionID string, payload map[string]interface{}) (*chromaGetResponse, error) {
 	endpoint := fmt.Sprintf("%s/api/v1/collections/%s/get", c.baseURL, collectionID)
 
-	payload := map[string]interface{}{
-		"where":   where,
-		"limit":   limit,
-		"include": []string{"documents", "metadatas"},
-	}
-
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal get request: %w", err)
@@ -313,6 +367,38 @@ func (c *ChromaClient) chromaGet(ctx context.Context, where map[string]interface
 	return &result, nil
 }
 
+// chromaGet executes a GET request using a metadata where-filter.
+// Uses /api/v1/collections/{id}/get which supports where-filtering without requiring embeddings.
+// In per_world mode results of all matching collections are merged up to limit.
+func (c *ChromaClient) chromaGet(ctx context.Context, where map[string]interface{}, limit int) (*chromaGetResponse, error) {
+	collectionIDs, err := c.readCollectionIDs(ctx, where)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection ID: %w", err)
+	}
+
+	merged := &chromaGetResponse{}
+	for _, collectionID := range collectionIDs {
+		remaining := limit - len(merged.Ids)
+		if remaining <= 0 {
+			break
+		}
+
+		result, err := c.collectionGet(ctx, collectionID, map[string]interface{}{
+			"where":   where,
+			"limit":   remaining,
+			"include": []string{"documents", "metadatas"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		merged.Ids = append(merged.Ids, result.Ids...)
+		merged.Documents = append(merged.Documents, result.Documents...)
+		merged.Metadatas = append(merged.Metadatas, result.Metadatas...)
+	}
+
+	return merged, nil
+}
+
 // SearchEventsByType searches for events by type in ChromaDB using /get with where-filter.
 func (c *ChromaClient) SearchEventsByType(ctx context.Context, eventType string, limit int) ([]string, error) {
 	result, err := c.chromaGet(ctx, map[string]interface{}{"event_type": eventType}, limit)
@@ -348,6 +434,24 @@ func (c *ChromaClient) QueryByMetadata(ctx context.Context, where map[string]int
 	return out, nil
 }
 
+// Ping calls the ChromaDB heartbeat.
+func (c *ChromaClient) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/api/v1/heartbeat", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create heartbeat request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute heartbeat request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("heartbeat failed with status %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
 // Close closes the HTTP client (optional).
 func (c *ChromaClient) Close() error {
 	// http.Client не требует закрытия, если не использовался custom Transport с закрываемыми ресурсами.
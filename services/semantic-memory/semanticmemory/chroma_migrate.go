@@ -0,0 +1,150 @@
+// Package semanticmemory — перенос документов из общей коллекции ChromaDB в коллекции миров.
+package semanticmemory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"multiverse-core.io/shared/logging"
+)
+
+// SplitOptions — параметры переноса общей коллекции по мирам
+type SplitOptions struct {
+	// BatchSize — сколько документов читать из общей коллекции за один запрос
+	BatchSize int
+	// DryRun — только посчитать документы, ничего не записывая
+	DryRun bool
+	// DeleteSource — удалять перенесённые документы из общей коллекции
+	DeleteSource bool
+}
+
+// SplitReport — результат переноса
+type SplitReport struct {
+	Total   int            `json:"total"`
+	Moved   map[string]int `json:"moved"`   // Количество документов по world_id
+	Skipped int            `json:"skipped"` // Документы без world_id остаются в общей коллекции
+}
+
+// SplitByWorld переносит документы из общей коллекции в коллекции миров
+// (имена по ChromaWorldCollectionName). Эмбеддинги копируются как есть, без пересчёта.
+// Повторный запуск безопасен: запись идёт через upsert.
+func (c *ChromaClient) SplitByWorld(ctx context.Context, opts SplitOptions) (*SplitReport, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 500
+	}
+
+	sourceID, found, err := c.findCollectionID(ctx, c.collectionName)
+	if err != nil {
+		return nil, err
+	}
+	report := &SplitReport{Moved: make(map[string]int)}
+	if !found {
+		return report, nil
+	}
+
+	offset := 0
+	for {
+		page, err := c.collectionGet(ctx, sourceID, map[string]interface{}{
+			"limit":   opts.BatchSize,
+			"offset":  offset,
+			"include": []string{"documents", "metadatas", "embeddings"},
+		})
+		if err != nil {
+			return report, fmt.Errorf("failed to read source collection at offset %d: %w", offset, err)
+		}
+		if len(page.Ids) == 0 {
+			break
+		}
+		report.Total += len(page.Ids)
+
+		docs := make(map[string][]Document)
+		embeddings := make(map[string][][]float32)
+		for i, id := range page.Ids {
+			doc := Document{ID: id}
+			if i < len(page.Documents) {
+				doc.Text = page.Documents[i]
+			}
+			if i < len(page.Metadatas) {
+				doc.Metadata = page.Metadatas[i]
+			}
+			worldID := chromaWorldID(doc.Metadata)
+			if worldID == "" {
+				report.Skipped++
+				continue
+			}
+			docs[worldID] = append(docs[worldID], doc)
+			if i < len(page.Embeddings) {
+				embeddings[worldID] = append(embeddings[worldID], page.Embeddings[i])
+			}
+		}
+
+		var movedIDs []string
+		for worldID, group := range docs {
+			report.Moved[worldID] += len(group)
+			if opts.DryRun {
+				continue
+			}
+
+			// Эмбеддинги передаются, только если они есть у всех документов группы
+			groupEmbeddings := embeddings[worldID]
+			if len(groupEmbeddings) != len(group) {
+				groupEmbeddings = nil
+			}
+			if err := c.upsertToCollection(ctx, ChromaWorldCollectionName(c.collectionName, worldID), group, groupEmbeddings); err != nil {
+				return report, fmt.Errorf("failed to move documents of world %s: %w", worldID, err)
+			}
+			for _, doc := range group {
+				movedIDs = append(movedIDs, doc.ID)
+			}
+		}
+
+		if opts.DeleteSource && len(movedIDs) > 0 {
+			if err := c.deleteFromCollection(ctx, sourceID, movedIDs); err != nil {
+				return report, err
+			}
+			// Удалённые документы сдвигают оставшиеся к началу коллекции
+			offset += len(page.Ids) - len(movedIDs)
+		} else {
+			offset += len(page.Ids)
+		}
+		logging.Infof("SplitByWorld: processed %d documents (%d skipped)", report.Total, report.Skipped)
+
+		if len(page.Ids) < opts.BatchSize {
+			break
+		}
+	}
+
+	return report, nil
+}
+
+// deleteFromCollection удаляет документы по ID: POST /api/v1/collections/{id}/delete
+func (c *ChromaClient) deleteFromCollection(ctx context.Context, collectionID string, ids []string) error {
+	endpoint := fmt.Sprintf("%s/api/v1/collections/%s/delete", c.baseURL, collectionID)
+
+	jsonData, err := json.Marshal(map[string]interface{}{"ids": ids})
+	if err != nil {
+		return fmt.Errorf("failed to marshal delete request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute delete request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("delete request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+// Package semanticmemory handles routing of documents to per-world ChromaDB collections.
+package semanticmemory
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Режимы размещения документов в ChromaDB (CHROMA_COLLECTION_MODE)
+const (
+	// ChromaModeShared — все миры в одной коллекции (поведение по умолчанию)
+	ChromaModeShared = "shared"
+	// ChromaModePerWorld — отдельная коллекция на каждый мир, создаётся при первой записи
+	ChromaModePerWorld = "per_world"
+)
+
+// Ограничения имени коллекции ChromaDB: 3-63 символа [a-zA-Z0-9._-], начало и конец — буква или цифра
+const maxChromaCollectionName = 63
+
+var chromaNameInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// chromaPerWorldMode сообщает, включён ли режим коллекций по мирам
+func chromaPerWorldMode() bool {
+	return strings.ToLower(os.Getenv("CHROMA_COLLECTION_MODE")) == ChromaModePerWorld
+}
+
+// chromaBaseCollectionName возвращает имя общей коллекции (CHROMA_COLLECTION_NAME, default world_memory)
+func chromaBaseCollectionName() string {
+	if name := os.Getenv("CHROMA_COLLECTION_NAME"); name != "" {
+		return name
+	}
+	return "world_memory"
+}
+
+// ChromaWorldCollectionName возвращает имя коллекции мира: {base}_{world_id}.
+// Недопустимые символы заменяются на "_"; если имя пришлось изменить или обрезать,
+// добавляется короткий хэш world_id, чтобы разные миры не попали в одну коллекцию.
+// Пустой world_id соответствует общей коллекции.
+func ChromaWorldCollectionName(base, worldID string) string {
+	if worldID == "" {
+		return base
+	}
+
+	sanitized := strings.Trim(chromaNameInvalidChars.ReplaceAllString(worldID, "_"), "_-")
+	name := base + "_" + sanitized
+	if sanitized == worldID && len(name) <= maxChromaCollectionName {
+		return name
+	}
+
+	sum := sha256.Sum256([]byte(worldID))
+	suffix := "_" + hex.EncodeToString(sum[:])[:8]
+	if len(name)+len(suffix) > maxChromaCollectionName {
+		name = strings.TrimRight(name[:maxChromaCollectionName-len(suffix)], "_-")
+	}
+	return name + suffix
+}
+
+// isChromaWorldCollection проверяет, что коллекция относится к набору коллекций base
+func isChromaWorldCollection(base, name string) bool {
+	return name == base || strings.HasPrefix(name, base+"_")
+}
+
+// chromaWorldID извлекает world_id из метаданных документа или where-фильтра
+func chromaWorldID(fields map[string]interface{}) string {
+	if worldID, ok := fields["world_id"].(string); ok {
+		return worldID
+	}
+	return ""
+}
+
+// groupDocumentsByWorld раскладывает документы по world_id из метаданных
+func groupDocumentsByWorld(docs []Document) map[string][]Document {
+	groups := make(map[string][]Document)
+	for _, doc := range docs {
+		worldID := chromaWorldID(doc.Metadata)
+		groups[worldID] = append(groups[worldID], doc)
+	}
+	return groups
+}
@@ -0,0 +1,37 @@
+package semanticmemory
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChromaWorldCollectionName(t *testing.T) {
+	if got := ChromaWorldCollectionName("world_memory", ""); got != "world_memory" {
+		t.Errorf("empty world must map to base collection, got %s", got)
+	}
+	if got := ChromaWorldCollectionName("world_memory", "world-1"); got != "world_memory_world-1" {
+		t.Errorf("unexpected name: %s", got)
+	}
+
+	a := ChromaWorldCollectionName("world_memory", "мир:1")
+	b := ChromaWorldCollectionName("world_memory", "мир:2")
+	if a == b {
+		t.Errorf("sanitized names of different worlds must differ: %s", a)
+	}
+	long := ChromaWorldCollectionName("world_memory", strings.Repeat("w", 100))
+	if len(long) > maxChromaCollectionName || !isChromaWorldCollection("world_memory", long) {
+		t.Errorf("long name must be truncated within base prefix, got %s", long)
+	}
+}
+
+func TestGroupDocumentsByWorld(t *testing.T) {
+	groups := groupDocumentsByWorld([]Document{
+		{ID: "a", Metadata: map[string]interface{}{"world_id": "w1"}},
+		{ID: "b", Metadata: map[string]interface{}{"world_id": "w2"}},
+		{ID: "c", Metadata: map[string]interface{}{"world_id": "w1"}},
+		{ID: "d"},
+	})
+	if len(groups["w1"]) != 2 || len(groups["w2"]) != 1 || len(groups[""]) != 1 {
+		t.Errorf("unexpected grouping: %v", groups)
+	}
+}
@@ -9,6 +9,9 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"sync"
+
+	"multiverse-core.io/shared/logging"
 
 	v2 "github.com/amikos-tech/chroma-go/pkg/api/v2"
 	"github.com/amikos-tech/chroma-go/pkg/embeddings"
@@ -22,6 +25,10 @@ type ChromaV2Client struct {
 	collectionName string
 	collection     v2.Collection // Updated to correct type
 	embeddings     *ollama.OllamaEmbeddingFunction
+	perWorld       bool // Отдельная коллекция на каждый мир (CHROMA_COLLECTION_MODE=per_world)
+
+	mu          sync.Mutex
+	collections map[string]v2.Collection // Открытые коллекции по имени
 }
 
 // Ensure ChromaV2Client implements SemanticStorage interface
@@ -52,10 +59,7 @@ func NewChromaV2Client() (*ChromaV2Client, error) {
 		return nil, fmt.Errorf("failed to create ChromaDB v2 client: %w", err)
 	}
 
-	collectionName := os.Getenv("CHROMA_COLLECTION_NAME")
-	if collectionName == "" {
-		collectionName = "world_memory"
-	}
+	collectionName := chromaBaseCollectionName()
 
 	ef, err := ollama.NewOllamaEmbeddingFunction(
 		ollama.WithBaseURL(urlEmbeding),
@@ -79,6 +83,8 @@ func NewChromaV2Client() (*ChromaV2Client, error) {
 		client:         client,
 		collectionName: collectionName,
 		embeddings:     ef,
+		perWorld:       chromaPerWorldMode(),
+		collections:    make(map[string]v2.Collection),
 	}
 
 	// Initialize collection
@@ -93,12 +99,12 @@ func NewChromaV2Client() (*ChromaV2Client, error) {
 // initializeCollection gets or creates the collection.
 func (c *ChromaV2Client) initializeCollection(ctx context.Context) error {
 	// Try to get the collection first
-	log.Println("Start initializeCollection")
+	logging.Infof("Start initializeCollection")
 	count, err := c.client.CountCollections(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to count collections: %w", err)
 	}
-	log.Printf("Count collections: %d \n", count)
+	logging.Infof("Count collections: %d \n", count)
 
 	collection, err := c.client.GetOrCreateCollection(ctx, c.collectionName, v2.WithEmbeddingFunctionCreate(c.embeddings))
 	if err != nil {
@@ -106,31 +112,122 @@ func (c *ChromaV2Client) initializeCollection(ctx context.Context) error {
 	}
 
 	c.collection = collection
+	c.collections[c.collectionName] = collection
 	return nil
 }
 
+// collectionFor returns the collection for documents of a world, creating it on first use.
+// Without per_world mode (or without world_id) this is the shared collection.
+func (c *ChromaV2Client) collectionFor(ctx context.Context, worldID string) (v2.Collection, error) {
+	name := c.collectionName
+	if c.perWorld {
+		name = ChromaWorldCollectionName(c.collectionName, worldID)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if collection, ok := c.collections[name]; ok {
+		return collection, nil
+	}
+	collection, err := c.client.GetOrCreateCollection(ctx, name, v2.WithEmbeddingFunctionCreate(c.embeddings))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get or create collection %s: %w", name, err)
+	}
+	c.collections[name] = collection
+	return collection, nil
+}
+
+// readCollections returns the collections to read from. In per_world mode a world_id
+// narrows the read to that world's collection; without it all world collections are read.
+// Collections are not created on read.
+func (c *ChromaV2Client) readCollections(ctx context.Context, worldID string) ([]v2.Collection, error) {
+	if !c.perWorld {
+		return []v2.Collection{c.collection}, nil
+	}
+
+	target := ""
+	if worldID != "" {
+		target = ChromaWorldCollectionName(c.collectionName, worldID)
+	}
+
+	listed, err := c.client.ListCollections(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collections: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var collections []v2.Collection
+	for _, listedCollection := range listed {
+		name := listedCollection.Name()
+		if !isChromaWorldCollection(c.collectionName, name) || (target != "" && name != target) {
+			continue
+		}
+		collection, ok := c.collections[name]
+		if !ok {
+			collection, err = c.client.GetCollection(ctx, name, v2.WithEmbeddingFunctionGet(c.embeddings))
+			if err != nil {
+				return nil, fmt.Errorf("failed to get collection %s: %w", name, err)
+			}
+			c.collections[name] = collection
+		}
+		collections = append(collections, collection)
+	}
+	return collections, nil
+}
+
 // UpsertDocument adds or updates a document in ChromaDB via the Go client.
 func (c *ChromaV2Client) UpsertDocument(ctx context.Context, entityID string, text string, metadata map[string]interface{}) error {
-	// Convert metadata to ChromaDB metadata format
-	docMetadata, err := v2.NewDocumentMetadataFromMap(metadata)
-	if err != nil {
-		// If conversion fails, create empty metadata
-		docMetadata = v2.NewDocumentMetadata()
+	return c.UpsertDocuments(ctx, []Document{{ID: entityID, Text: text, Metadata: metadata}})
+}
+
+// UpsertDocuments adds or updates a batch of documents in ChromaDB via the Go client.
+// In per_world mode documents are grouped by metadata world_id, one upsert per world collection.
+func (c *ChromaV2Client) UpsertDocuments(ctx context.Context, docs []Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	groups := map[string][]Document{"": docs}
+	if c.perWorld {
+		groups = groupDocumentsByWorld(docs)
+	}
+	for worldID, group := range groups {
+		collection, err := c.collectionFor(ctx, worldID)
+		if err != nil {
+			return err
+		}
+		if err := c.upsertToCollection(ctx, collection, group); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	// Prepare document IDs, texts and metadatas as slices
-	docIDs := []v2.DocumentID{v2.DocumentID(entityID)}
-	docTexts := []string{text}
-	docMetadatas := []v2.DocumentMetadata{docMetadata}
+// upsertToCollection upserts a batch of documents into one collection.
+func (c *ChromaV2Client) upsertToCollection(ctx context.Context, collection v2.Collection, docs []Document) error {
+	docIDs := make([]v2.DocumentID, len(docs))
+	docTexts := make([]string, len(docs))
+	docMetadatas := make([]v2.DocumentMetadata, len(docs))
+	for i, doc := range docs {
+		docMetadata, err := v2.NewDocumentMetadataFromMap(doc.Metadata)
+		if err != nil {
+			docMetadata = v2.NewDocumentMetadata()
+		}
+		docIDs[i] = v2.DocumentID(doc.ID)
+		docTexts[i] = doc.Text
+		docMetadatas[i] = docMetadata
+	}
 
-	// Perform upsert operation using options
-	err = c.collection.Upsert(ctx,
+	err := collection.Upsert(ctx,
 		v2.WithIDs(docIDs...),
 		v2.WithTexts(docTexts...),
 		v2.WithMetadatas(docMetadatas...),
 	)
 	if err != nil {
-		return fmt.Errorf("failed to upsert document: %w", err)
+		return fmt.Errorf("failed to upsert documents: %w", err)
 	}
 
 	return nil
@@ -153,28 +250,29 @@ func (c *ChromaV2Client) GetDocuments(ctx context.Context, entityIDs []string) (
 	//	return nil, fmt.Errorf("failed to create get operation: %w", err)
 	//}
 
-	// Get documents from collection
-	result, err := c.collection.Get(ctx,
-		v2.WithIDsGet(docIDs...),
-		v2.WithIncludeGet(v2.IncludeDocuments, v2.IncludeMetadatas))
+	// IDs carry no world, so in per_world mode all world collections are searched
+	collections, err := c.readCollections(ctx, "")
 	if err != nil {
-		return nil, fmt.Errorf("failed to get documents: %w", err)
+		return nil, err
 	}
 
 	// Convert results to map
 	documents := make(map[string]string)
-	idList := result.GetIDs()
-	docList := result.GetDocuments()
+	for _, collection := range collections {
+		// Get documents from collection
+		result, err := collection.Get(ctx,
+			v2.WithIDsGet(docIDs...),
+			v2.WithIncludeGet(v2.IncludeDocuments, v2.IncludeMetadatas))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get documents: %w", err)
+		}
 
-	for i, id := range idList {
-		if i < len(docList) {
-			// Convert Document interface to string
-			//docStr, ok := docList[i].(string)
-			//if !ok {
-			//	// If conversion fails, use fmt.Sprintf as fallback
-			//	docStr = fmt.Sprintf("%v", docList[i])
-			//}
-			documents[string(id)] = docList[i].ContentString()
+		idList := result.GetIDs()
+		docList := result.GetDocuments()
+		for i, id := range idList {
+			if i < len(docList) {
+				documents[string(id)] = docList[i].ContentString()
+			}
 		}
 	}
 
@@ -186,30 +284,36 @@ func (c *ChromaV2Client) SearchEventsByType(ctx context.Context, eventType strin
 	// Create a where filter to search for documents with specific event type
 	whereFilter := v2.EqString("event_type", eventType)
 
-	// Query the collection with the filter
-	result, err := c.collection.Query(ctx,
-		v2.WithWhereQuery(whereFilter),
-		v2.WithNResults(limit),
-		v2.WithIncludeQuery(v2.IncludeDocuments, v2.IncludeMetadatas))
+	collections, err := c.readCollections(ctx, "")
 	if err != nil {
-		return nil, fmt.Errorf("failed to search events by type: %w", err)
-	}
-
-	// Extract documents from the result
-	var docList = result.GetDocumentsGroups()
-	if docList == nil {
-		return []string{}, nil
+		return nil, err
 	}
 
 	// Convert documents to string slice
-	var documents []string
-	for _, doc := range docList[0] {
-		//docStr, ok := doc.(string)
-		//if !ok {
-		//	// If conversion fails, use fmt.Sprintf as fallback
-		//	docStr = fmt.Sprintf("%v", doc)
-		//}
-		documents = append(documents, doc.ContentString())
+	documents := []string{}
+	for _, collection := range collections {
+		remaining := limit - len(documents)
+		if remaining <= 0 {
+			break
+		}
+
+		// Query the collection with the filter
+		result, err := collection.Query(ctx,
+			v2.WithWhereQuery(whereFilter),
+			v2.WithNResults(remaining),
+			v2.WithIncludeQuery(v2.IncludeDocuments, v2.IncludeMetadatas))
+		if err != nil {
+			return nil, fmt.Errorf("failed to search events by type: %w", err)
+		}
+
+		// Extract documents from the result
+		docList := result.GetDocumentsGroups()
+		if len(docList) == 0 {
+			continue
+		}
+		for _, doc := range docList[0] {
+			documents = append(documents, doc.ContentString())
+		}
 	}
 
 	return documents, nil
@@ -229,7 +333,7 @@ func (c *ChromaV2Client) QueryByMetadata(ctx context.Context, where map[string]i
 		case string:
 			clauses = append(clauses, v2.EqString(k, val))
 		default:
-			log.Printf("QueryByMetadata: skipping non-string filter key=%s", k)
+			logging.Infof("QueryByMetadata: skipping non-string filter key=%s", k)
 		}
 	}
 
@@ -244,15 +348,33 @@ func (c *ChromaV2Client) QueryByMetadata(ctx context.Context, where map[string]i
 		whereFilter = v2.And(clauses...)
 	}
 
-	result, err := c.collection.Get(ctx,
-		v2.WithWhereGet(whereFilter),
-		v2.WithLimitGet(limit),
-		v2.WithIncludeGet(v2.IncludeDocuments, v2.IncludeMetadatas),
-	)
+	collections, err := c.readCollections(ctx, chromaWorldID(where))
 	if err != nil {
-		return nil, fmt.Errorf("QueryByMetadata: failed to get documents: %w", err)
+		return nil, fmt.Errorf("QueryByMetadata: %w", err)
 	}
 
+	out := make([]map[string]interface{}, 0)
+	for _, collection := range collections {
+		remaining := limit - len(out)
+		if remaining <= 0 {
+			break
+		}
+
+		result, err := collection.Get(ctx,
+			v2.WithWhereGet(whereFilter),
+			v2.WithLimitGet(remaining),
+			v2.WithIncludeGet(v2.IncludeDocuments, v2.IncludeMetadatas),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("QueryByMetadata: failed to get documents: %w", err)
+		}
+		out = append(out, chromaV2Entries(result)...)
+	}
+	return out, nil
+}
+
+// chromaV2Entries converts a Get result into maps with "id", "document", and "metadata" keys.
+func chromaV2Entries(result v2.GetResult) []map[string]interface{} {
 	idList := result.GetIDs()
 	docList := result.GetDocuments()
 	metaList := result.GetMetadatas()
@@ -281,7 +403,12 @@ func (c *ChromaV2Client) QueryByMetadata(ctx context.Context, where map[string]i
 		}
 		out = append(out, entry)
 	}
-	return out, nil
+	return out
+}
+
+// Ping calls the ChromaDB heartbeat.
+func (c *ChromaV2Client) Ping(ctx context.Context) error {
+	return c.client.Heartbeat(ctx)
 }
 
 // Close closes the ChromaDB client connection.
@@ -4,8 +4,14 @@ package semanticmemory
 import (
 	"context"
 	"fmt"
-	"log"
+	"math"
+	"sort"
 	"strings"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+	"multiverse-core.io/shared/tracing"
 )
 
 // BuildEventBasedContext creates a context string based on recent events
@@ -25,28 +31,198 @@ func (i *Indexer) BuildEventBasedContext(ctx context.Context, eventType string,
 	return strings.Join(contextParts, "\n\n"), nil
 }
 
-// GetContextWithEvents retrieves full context for entity IDs and includes relevant events
-func (i *Indexer) GetContextWithEvents(ctx context.Context, entityIDs []string, eventTypes []string, depth int) (map[string]string, error) {
-	// Get entity context
-	entityContexts, err := i.GetContext(ctx, entityIDs, depth)
-	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve entity context: %w", err)
+// Defaults of /v1/context-with-events.
+const (
+	// DefaultContextEvents is the number of events returned per entity.
+	DefaultContextEvents = 5
+	// maxContextEvents bounds the events returned per entity.
+	maxContextEvents = 50
+	// contextCandidateFactor — candidates read per returned event, so the ranking has something to choose from.
+	contextCandidateFactor = 4
+	// contextRecencyHalfLife — an event this old gets half of the recency score.
+	contextRecencyHalfLife = time.Hour
+	// contextProximityScale — an event this far from the entity gets half of the proximity score.
+	contextProximityScale = 100.0
+)
+
+// ContextWithEventsQuery selects entity contexts and their relevant events.
+type ContextWithEventsQuery struct {
+	EntityIDs []string
+	// EventTypes filters events by type; "player" also matches "player.moved". Empty — all types.
+	EventTypes []string
+	// Depth 0 returns contexts without events
+	Depth int
+	// Limit is the number of events per entity (DefaultContextEvents when 0)
+	Limit int
+}
+
+// EntityEventsContext is the context of one entity in the /v1/context-with-events response.
+type EntityEventsContext struct {
+	// Context is the entity description followed by its relevant events, ready for a prompt
+	Context string         `json:"context"`
+	Events  []ContextEvent `json:"events"`
+}
+
+// ContextEvent is a relevant event of an entity.
+type ContextEvent struct {
+	ID          string                 `json:"id"`
+	Type        string                 `json:"type"`
+	Timestamp   time.Time              `json:"timestamp"`
+	WorldID     string                 `json:"world_id,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Payload     map[string]interface{} `json:"payload,omitempty"`
+	// Score is the relevance used for ranking: recency, plus proximity when both positions are known
+	Score float64 `json:"score"`
+	// Distance from the entity; nil when the event or the entity has no position
+	Distance *float64 `json:"distance,omitempty"`
+}
+
+// GetContextWithEvents returns the context of every entity with its most relevant recent events,
+// ranked by recency and spatial proximity to the entity. Entities unknown to Neo4j are looked up
+// as worlds. Without Neo4j it falls back to ChromaDB documents without events.
+func (i *Indexer) GetContextWithEvents(ctx context.Context, q ContextWithEventsQuery) (map[string]EntityEventsContext, error) {
+	limit := q.Limit
+	if limit <= 0 {
+		limit = DefaultContextEvents
 	}
+	limit = min(limit, maxContextEvents)
 
-	// Enhance context with events
-	for _, eventType := range eventTypes {
-		eventContext, err := i.BuildEventBasedContext(ctx, eventType, 5) // Limit to 5 recent events
+	_, span := tracing.Start(ctx, "neo4j.GetEntityCache")
+	entityCache, err := i.neo4j.GetEntityCache(q.EntityIDs)
+	tracing.End(span, err)
+	if err != nil {
+		logging.Errorf("Neo4j GetEntityCache failed, falling back to ChromaDB without events: %v", err)
+		docs, err := i.chroma.GetDocuments(ctx, q.EntityIDs)
 		if err != nil {
-			log.Printf("Warning: failed to build event context for type %s: %v", eventType, err)
+			return nil, fmt.Errorf("failed to retrieve entity context: %w", err)
+		}
+		result := make(map[string]EntityEventsContext, len(docs))
+		for id, text := range docs {
+			result[id] = EntityEventsContext{Context: text, Events: []ContextEvent{}}
+		}
+		return result, nil
+	}
+
+	now := time.Now()
+	result := make(map[string]EntityEventsContext, len(q.EntityIDs))
+	for _, id := range q.EntityIDs {
+		var text string
+		var origin *Coordinates
+		if info, ok := entityCache[id]; ok {
+			text = BuildTextContext(id, info.Type, info.Payload)
+			origin = info.Coordinates
+		} else if worldText, err := i.neo4j.GetWorldContext(id); err == nil && worldText != "" {
+			text = worldText
+		} else {
+			continue
+		}
+
+		events := []ContextEvent{}
+		if q.Depth > 0 {
+			_, span := tracing.Start(ctx, "neo4j.GetEventsByEntity")
+			candidates, err := i.neo4j.GetEventsByEntity(id, limit*contextCandidateFactor)
+			tracing.End(span, err)
+			if err != nil {
+				logging.Warnf("Failed to get events of entity %s: %v", id, err)
+			} else {
+				events = rankContextEvents(candidates, q.EventTypes, origin, now, limit)
+			}
+		}
+		if len(events) > 0 {
+			lines := make([]string, len(events))
+			for n, ev := range events {
+				lines[n] = ev.Description
+			}
+			text += "\n\nRecent Events:\n" + strings.Join(lines, "\n\n")
+		}
+		result[id] = EntityEventsContext{Context: text, Events: events}
+	}
+	return result, nil
+}
+
+// rankContextEvents filters events by type and returns the limit most relevant, best first.
+// Recency scores 1 for a fresh event and halves every contextRecencyHalfLife; when both the
+// entity and the event have a position, proximity is scored the same way over distance and
+// weighs as much as recency.
+func rankContextEvents(events []eventbus.Event, eventTypes []string, origin *Coordinates, now time.Time, limit int) []ContextEvent {
+	ranked := make([]ContextEvent, 0, len(events))
+	seen := make(map[string]bool, len(events))
+	for _, ev := range events {
+		if seen[ev.ID] || !matchesEventType(ev.Type, eventTypes) {
 			continue
 		}
+		seen[ev.ID] = true
+
+		age := max(now.Sub(ev.Timestamp), 0)
+		score := math.Pow(0.5, float64(age)/float64(contextRecencyHalfLife))
+		var distance *float64
+		if point, ok := eventCoordinates(ev.Payload); ok && origin != nil {
+			d := math.Hypot(point.X-origin.X, point.Y-origin.Y)
+			distance = &d
+			score = (score + contextProximityScale/(contextProximityScale+d)) / 2
+		}
 
-		// Add event context to each entity context
-		for entityID, entityContext := range entityContexts {
-			enhancedContext := fmt.Sprintf("%s\n\nRecent %s events:\n%s", entityContext, eventType, eventContext)
-			entityContexts[entityID] = enhancedContext
+		ranked = append(ranked, ContextEvent{
+			ID:          ev.ID,
+			Type:        ev.Type,
+			Timestamp:   ev.Timestamp,
+			WorldID:     eventbus.GetWorldIDFromEvent(ev),
+			Description: humanizeContextEvent(ev),
+			Payload:     ev.Payload,
+			Score:       score,
+			Distance:    distance,
+		})
+	}
+	sort.SliceStable(ranked, func(a, b int) bool {
+		if ranked[a].Score != ranked[b].Score {
+			return ranked[a].Score > ranked[b].Score
 		}
+		return ranked[a].Timestamp.After(ranked[b].Timestamp)
+	})
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
 	}
+	return ranked
+}
 
-	return entityContexts, nil
+// matchesEventType reports whether the event type is selected: an empty filter selects all,
+// a filter entry selects its type and every subtype ("player" matches "player.moved").
+func matchesEventType(eventType string, eventTypes []string) bool {
+	if len(eventTypes) == 0 {
+		return true
+	}
+	for _, t := range eventTypes {
+		if eventType == t || strings.HasPrefix(eventType, strings.TrimSuffix(t, ".")+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// eventCoordinates returns the position of an event: location, to or position {x, y}.
+func eventCoordinates(payload map[string]interface{}) (Coordinates, bool) {
+	for _, key := range []string{"location", "to", "position"} {
+		if loc, ok := payload[key].(map[string]interface{}); ok {
+			x, okX := loc["x"].(float64)
+			y, okY := loc["y"].(float64)
+			if okX && okY {
+				return Coordinates{X: x, Y: y}, true
+			}
+		}
+	}
+	return Coordinates{}, false
+}
+
+// humanizeContextEvent is a one-line summary of an event for the prompt context.
+func humanizeContextEvent(ev eventbus.Event) string {
+	summary := ev.Type
+	if desc, ok := ev.Payload["description"].(string); ok && desc != "" {
+		summary += ": " + desc
+	} else if entity := eventbus.ExtractEntityID(ev.Payload); entity != nil {
+		summary += ": " + entity.ID
+		if target, ok := ev.Payload["target"].(string); ok && target != "" {
+			summary += " → " + target
+		}
+	}
+	return fmt.Sprintf("[%s] %s", ev.Timestamp.UTC().Format("2006-01-02 15:04"), summary)
 }
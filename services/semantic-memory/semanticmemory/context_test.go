@@ -0,0 +1,71 @@
+package semanticmemory
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+)
+
+func TestRankContextEvents(t *testing.T) {
+	now := time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)
+	event := func(id, eventType string, age time.Duration, x, y float64) eventbus.Event {
+		ev := eventbus.NewEvent(eventType, "test", "world-1", map[string]any{
+			"player_id": "player-1",
+			"location":  map[string]any{"x": x, "y": y},
+		})
+		ev.ID = id
+		ev.Timestamp = now.Add(-age)
+		return ev
+	}
+	events := []eventbus.Event{
+		event("old-near", "player.moved", 5*time.Hour, 1, 1),
+		event("fresh-far", "player.moved", time.Minute, 900, 900),
+		event("fresh-near", "player.attacked", 2*time.Minute, 5, 0),
+		event("fresh-near", "player.attacked", 2*time.Minute, 5, 0),
+		event("weather", "weather.changed", 0, 0, 0),
+	}
+	origin := &Coordinates{X: 0, Y: 0}
+
+	ranked := rankContextEvents(events, []string{"player"}, origin, now, 3)
+	var ids []string
+	for _, ev := range ranked {
+		ids = append(ids, ev.ID)
+	}
+	if strings.Join(ids, ",") != "fresh-near,fresh-far,old-near" {
+		t.Fatalf("unexpected ranking: %v", ids)
+	}
+	if ranked[0].Distance == nil || *ranked[0].Distance != 5 {
+		t.Errorf("expected distance 5, got %v", ranked[0].Distance)
+	}
+	if !strings.Contains(ranked[0].Description, "player.attacked: player-1") {
+		t.Errorf("unexpected description %q", ranked[0].Description)
+	}
+
+	// Без позиции сущности важна только давность
+	ranked = rankContextEvents(events, nil, nil, now, 2)
+	if len(ranked) != 2 || ranked[0].ID != "weather" || ranked[1].ID != "fresh-far" || ranked[0].Distance != nil {
+		t.Errorf("unexpected recency ranking: %+v", ranked)
+	}
+}
+
+func TestMatchesEventType(t *testing.T) {
+	cases := []struct {
+		eventType string
+		filter    []string
+		want      bool
+	}{
+		{"player.moved", nil, true},
+		{"player.moved", []string{"player.moved"}, true},
+		{"player.moved", []string{"player"}, true},
+		{"player.moved", []string{"player."}, true},
+		{"players.joined", []string{"player"}, false},
+		{"npc.moved", []string{"player", "weather"}, false},
+	}
+	for _, c := range cases {
+		if got := matchesEventType(c.eventType, c.filter); got != c.want {
+			t.Errorf("matchesEventType(%q, %v) = %v, want %v", c.eventType, c.filter, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+// Package semanticmemory — deduplication of repeatedly indexed events.
+package semanticmemory
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+)
+
+// DedupConfig задаёт окно обнаружения повторов.
+type DedupConfig struct {
+	// Window — сколько помнятся event_id и хэши содержимого проиндексированных событий.
+	Window time.Duration
+	// MaxEntries — предел запомненных событий; сверх него забываются старейшие.
+	MaxEntries int
+}
+
+// DedupConfigFromEnv читает конфигурацию дедупликации из переменных окружения:
+// SEMANTIC_DEDUP_WINDOW_MS (default 300000), SEMANTIC_DEDUP_MAX_ENTRIES (default 100000).
+func DedupConfigFromEnv() DedupConfig {
+	return DedupConfig{
+		Window:     time.Duration(envInt("SEMANTIC_DEDUP_WINDOW_MS", 300000)) * time.Millisecond,
+		MaxEntries: envInt("SEMANTIC_DEDUP_MAX_ENTRIES", 100000),
+	}
+}
+
+// DedupMetrics — снимок метрик дедупликации.
+type DedupMetrics struct {
+	EventsChecked  int64 `json:"events_checked"`
+	DuplicateIDs   int64 `json:"duplicate_ids_skipped"`
+	NearDuplicates int64 `json:"near_duplicates_skipped"`
+	Tracked        int   `json:"tracked_events"`
+}
+
+// dedupVerdict — решение по событию.
+type dedupVerdict int
+
+const (
+	dedupNew dedupVerdict = iota
+	// dedupDuplicateID — событие с этим event_id уже индексировалось (получено из другого топика)
+	dedupDuplicateID
+	// dedupNearDuplicate — событие другого типа или источника с тем же миром и содержимым payload
+	// (производное событие с копией payload)
+	dedupNearDuplicate
+)
+
+// volatilePayloadKeys не влияют на содержимое события: время, идентификаторы и трасса
+// меняются при переиздании события с копией payload.
+var volatilePayloadKeys = map[string]bool{
+	"timestamp":      true,
+	"time":           true,
+	"created_at":     true,
+	"updated_at":     true,
+	"event_id":       true,
+	"trace_id":       true,
+	"traceparent":    true,
+	"correlation_id": true,
+	"causation_id":   true,
+	"caused_by":      true,
+	"scope":          true,
+	"scope_id":       true,
+}
+
+// dedupEntry — запомненное событие в порядке индексации.
+type dedupEntry struct {
+	eventID string
+	hash    string
+	seenAt  time.Time
+}
+
+// dedupOrigin — первое событие с данным содержимым.
+type dedupOrigin struct {
+	eventID   string
+	eventType string
+	source    string
+}
+
+// Deduplicator отсеивает события, уже проиндексированные в скользящем окне: по event_id
+// (идемпотентность при доставке из нескольких топиков) и по хэшу содержимого
+// (производные события с копией payload). Событие того же типа и источника с тем же
+// содержимым — повторное действие, а не копия, и индексируется.
+type Deduplicator struct {
+	cfg DedupConfig
+	now func() time.Time
+
+	mu     sync.Mutex
+	ids    map[string]bool
+	hashes map[string]dedupOrigin // хэш содержимого → первое событие
+	order  []dedupEntry
+
+	eventsChecked  atomic.Int64
+	duplicateIDs   atomic.Int64
+	nearDuplicates atomic.Int64
+}
+
+// NewDeduplicator создаёт дедупликатор. Значения конфигурации <= 0 заменяются значениями по умолчанию.
+func NewDeduplicator(cfg DedupConfig) *Deduplicator {
+	if cfg.Window <= 0 {
+		cfg.Window = 5 * time.Minute
+	}
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = 100000
+	}
+	return &Deduplicator{
+		cfg:    cfg,
+		now:    time.Now,
+		ids:    make(map[string]bool),
+		hashes: make(map[string]dedupOrigin),
+	}
+}
+
+// Check запоминает новое событие и сообщает о повторе; original — event_id
+// ранее проиндексированного события с тем же содержимым.
+func (d *Deduplicator) Check(ev eventbus.Event) (verdict dedupVerdict, original string) {
+	d.eventsChecked.Add(1)
+	hash := contentHash(ev)
+	now := d.now()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.evictLocked(now)
+
+	if d.ids[ev.ID] {
+		d.duplicateIDs.Add(1)
+		return dedupDuplicateID, ev.ID
+	}
+	if hash != "" {
+		first, ok := d.hashes[hash]
+		if ok && (first.eventType != ev.Type || first.source != ev.Source) {
+			d.nearDuplicates.Add(1)
+			return dedupNearDuplicate, first.eventID
+		}
+		if !ok {
+			d.hashes[hash] = dedupOrigin{eventID: ev.ID, eventType: ev.Type, source: ev.Source}
+		}
+	}
+	d.ids[ev.ID] = true
+	d.order = append(d.order, dedupEntry{eventID: ev.ID, hash: hash, seenAt: now})
+	return dedupNew, ""
+}
+
+// evictLocked забывает события старше окна и сверх MaxEntries.
+func (d *Deduplicator) evictLocked(now time.Time) {
+	n := 0
+	for n < len(d.order) && (now.Sub(d.order[n].seenAt) > d.cfg.Window || len(d.order)-n > d.cfg.MaxEntries) {
+		entry := d.order[n]
+		delete(d.ids, entry.eventID)
+		if entry.hash != "" && d.hashes[entry.hash].eventID == entry.eventID {
+			delete(d.hashes, entry.hash)
+		}
+		n++
+	}
+	if n > 0 {
+		d.order = append(d.order[:0], d.order[n:]...)
+	}
+}
+
+// Metrics возвращает текущий снимок метрик дедупликации.
+func (d *Deduplicator) Metrics() DedupMetrics {
+	d.mu.Lock()
+	tracked := len(d.order)
+	d.mu.Unlock()
+	return DedupMetrics{
+		EventsChecked:  d.eventsChecked.Load(),
+		DuplicateIDs:   d.duplicateIDs.Load(),
+		NearDuplicates: d.nearDuplicates.Load(),
+		Tracked:        tracked,
+	}
+}
+
+// contentHash — SHA-256 мира и payload без изменчивых полей. Тип события не учитывается:
+// производные события переиздают тот же payload под другим типом. Пусто — payload
+// без содержательных полей (только мир), такие события по содержимому не сравниваются.
+func contentHash(ev eventbus.Event) string {
+	content := make(map[string]interface{}, len(ev.Payload))
+	for key, value := range ev.Payload {
+		if !volatilePayloadKeys[key] && key != "world" && key != "world_id" {
+			content[key] = value
+		}
+	}
+	if len(content) == 0 {
+		return ""
+	}
+	// encoding/json сортирует ключи map — сериализация детерминирована
+	data, err := json.Marshal(map[string]interface{}{
+		"world":   eventbus.GetWorldIDFromEvent(ev),
+		"payload": content,
+	})
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
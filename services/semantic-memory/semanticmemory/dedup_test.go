@@ -0,0 +1,87 @@
+package semanticmemory
+
+import (
+	"testing"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+)
+
+func TestDeduplicator(t *testing.T) {
+	d := NewDeduplicator(DedupConfig{Window: time.Minute, MaxEntries: 10})
+	now := time.Now()
+	d.now = func() time.Time { return now }
+
+	payload := func() map[string]interface{} {
+		return map[string]interface{}{
+			"entity":    map[string]interface{}{"id": "npc:orin"},
+			"action":    "died",
+			"timestamp": now.Unix(),
+		}
+	}
+	first := eventbus.NewEvent("npc.died", "narrative-orchestrator", "pain-realm", payload())
+	if verdict, _ := d.Check(first); verdict != dedupNew {
+		t.Fatalf("first event must be indexed, got %v", verdict)
+	}
+
+	// То же событие из другого топика
+	if verdict, original := d.Check(first); verdict != dedupDuplicateID || original != first.ID {
+		t.Errorf("expected duplicate ID, got %v %s", verdict, original)
+	}
+
+	// Производное событие с копией payload и другим временем
+	copied := payload()
+	copied["timestamp"] = now.Add(time.Second).Unix()
+	derived := eventbus.NewEvent("entity.updated", "entity-manager", "pain-realm", copied)
+	if verdict, original := d.Check(derived); verdict != dedupNearDuplicate || original != first.ID {
+		t.Errorf("expected near-duplicate of %s, got %v %s", first.ID, verdict, original)
+	}
+
+	// Повтор действия тем же источником и то же содержимое в другом мире индексируются
+	if verdict, _ := d.Check(eventbus.NewEvent("npc.died", "narrative-orchestrator", "pain-realm", payload())); verdict != dedupNew {
+		t.Errorf("same type and source must be indexed, got %v", verdict)
+	}
+	if verdict, _ := d.Check(eventbus.NewEvent("entity.updated", "entity-manager", "other-realm", payload())); verdict != dedupNew {
+		t.Errorf("other world must be indexed, got %v", verdict)
+	}
+
+	m := d.Metrics()
+	if m.EventsChecked != 5 || m.DuplicateIDs != 1 || m.NearDuplicates != 1 || m.Tracked != 3 {
+		t.Errorf("unexpected metrics %+v", m)
+	}
+
+	// За пределами окна события забываются
+	now = now.Add(2 * time.Minute)
+	if verdict, _ := d.Check(first); verdict != dedupNew {
+		t.Errorf("expired event must be indexed again, got %v", verdict)
+	}
+	if m := d.Metrics(); m.Tracked != 1 {
+		t.Errorf("expected expired events evicted, got %d tracked", m.Tracked)
+	}
+}
+
+func TestDeduplicator_MaxEntries(t *testing.T) {
+	d := NewDeduplicator(DedupConfig{Window: time.Hour, MaxEntries: 2})
+	events := make([]eventbus.Event, 3)
+	for i := range events {
+		events[i] = eventbus.NewEvent("world.tick", "tick-service", "pain-realm", nil)
+		d.Check(events[i])
+	}
+	if verdict, _ := d.Check(events[0]); verdict != dedupNew {
+		t.Errorf("oldest event must be forgotten, got %v", verdict)
+	}
+	if verdict, _ := d.Check(events[2]); verdict != dedupDuplicateID {
+		t.Errorf("recent event must be remembered, got %v", verdict)
+	}
+}
+
+func TestContentHashIgnoresVolatileKeys(t *testing.T) {
+	a := eventbus.NewEvent("a", "s", "w", map[string]interface{}{"x": 1, "trace_id": "t1", "world_id": "w"})
+	b := eventbus.NewEvent("b", "s", "w", map[string]interface{}{"x": 1, "trace_id": "t2"})
+	if contentHash(a) == "" || contentHash(a) != contentHash(b) {
+		t.Errorf("hashes must match: %q %q", contentHash(a), contentHash(b))
+	}
+	if h := contentHash(eventbus.NewEvent("a", "s", "w", map[string]interface{}{"timestamp": 1})); h != "" {
+		t.Errorf("payload without content must not be hashed, got %q", h)
+	}
+}
@@ -23,23 +23,44 @@ Package semanticmemory реализует семантическую памят
 
  1. Внешний сервис публикует событие в EventBus (Kafka/NATS).
  2. Service подписывается на топики: player, world, game, system, scope, narrative.
- 3. Indexer.HandleEvent сохраняет событие:
-    a. В ChromaDB — текстовое представление + metadata (event_id, event_type, world_id, source, timestamp).
-    b. В Neo4j — узел Event + рёбра RELATED_TO к Entity-узлам из payload.
- 4. Данные доступны через REST API.
+ 3. Indexer.HandleEvent отсеивает повторы (dedup.go) и ставит событие в очередь IndexPipeline (pipeline.go).
+ 4. Конвейер собирает микро-пакеты (до SEMANTIC_BATCH_SIZE событий или SEMANTIC_FLUSH_INTERVAL_MS)
+    и сохраняет пакет:
+    a. В ChromaDB — один bulk upsert: текстовое представление + metadata (event_id, event_type, world_id, source, timestamp).
+    b. В Neo4j — узлы Event одним UNWIND-запросом + рёбра RELATED_TO к Entity-узлам из payload.
+    При заполненной очереди HandleEvent блокируется, создавая backpressure для Kafka.
+    Offset фиксируется при чтении, до индексации: при аварийном завершении теряется не больше
+    SEMANTIC_QUEUE_SIZE + SEMANTIC_BATCH_SIZE событий. При остановке очередь дописывается.
+    Если bulk-запись не удалась, события сохраняются по одному.
+ 5. Данные доступны через REST API.
 
 # Конфигурация (переменные окружения)
 
+	SEMANTIC_VECTOR_BACKEND Векторное хранилище: chroma | qdrant | pgvector (default: chroma)
 	CHROMA_URL          URL ChromaDB            (default: http://chromadb:8000)
 	CHROMA_USE_V2       Использовать Go-клиент  (default: false)
 	CHROMA_COLLECTION_NAME Имя коллекции        (default: world_memory)
+	CHROMA_COLLECTION_MODE shared | per_world   (default: shared)
 	EMBEDING_URL        URL Ollama для эмбеддингов (default: http://qwen3-service:11434)
 	EMBEDING_MODEL      Модель эмбеддингов      (default: nomic-embed-text:latest)
+	QDRANT_URL          URL Qdrant              (default: http://qdrant:6333)
+	QDRANT_API_KEY      API-ключ Qdrant         (optional)
+	QDRANT_COLLECTION   Коллекция Qdrant        (default: world_memory)
+	PGVECTOR_DSN        DSN Postgres с pgvector (обязателен для pgvector)
+	PGVECTOR_TABLE      Таблица документов      (default: semantic_documents)
 	NEO4J_URI           URI Neo4j               (default: neo4j://neo4j:7687)
 	NEO4J_USER          Логин Neo4j             (default: neo4j)
 	NEO4J_PASSWORD      Пароль Neo4j            (default: password)
 	MINIO_ENDPOINT      Адрес MinIO             (default: minio:9000)
 	SEMANTIC_PORT       HTTP-порт сервиса       (default: 8080)
+	SEMANTIC_BATCH_SIZE Размер пакета индексации (default: 100)
+	SEMANTIC_FLUSH_INTERVAL_MS Максимальное ожидание пакета (default: 500)
+	SEMANTIC_QUEUE_SIZE Ёмкость очереди индексации (default: 10000)
+	SEMANTIC_DEDUP_WINDOW_MS Окно дедупликации событий (default: 300000)
+	SEMANTIC_DEDUP_MAX_ENTRIES Событий в окне дедупликации (default: 100000)
+	RELATION_RULES_BUCKET Бакет правил извлечения связей (default: gnue-configs)
+	RELATION_RULES_KEY  Ключ файла правил       (default: semantic-memory/relationship_rules.yaml)
+	ORACLE_URL, ORACLE_MODEL, ORACLE_API_KEY  Oracle для сводок /v1/timeline (см. shared/oracle)
 
 # HTTP API
 
@@ -51,9 +72,15 @@ Package semanticmemory реализует семантическую памят
 	  Возвращает текстовый контекст сущностей из ChromaDB.
 
 	POST /v1/context-with-events
-	  Тело: {"entity_ids": ["id1"], "event_types": ["player.move"], "depth": 1}
-	  Ответ: {"contexts": {"id1": "<текст>"}}
-	  Возвращает контекст сущностей, дополненный событиями заданных типов.
+	  Тело: {"entity_ids": ["id1"], "event_types": ["player"], "depth": 1, "limit": 5}
+	  Ответ: {"contexts": {"id1": {"context": "<текст>", "events": [
+	    {"id": "...", "type": "player.moved", "timestamp": "...", "description": "...",
+	     "payload": {...}, "score": 0.93, "distance": 12.5}]}}}
+	  Возвращает контекст сущностей (ID мира — контекст мира) и до limit (default: 5)
+	  самых релевантных событий каждой сущности. event_types фильтрует по типу
+	  и подтипам ("player" — и "player.moved"); depth 0 — без событий.
+	  Релевантность — давность события и, если известны обе позиции,
+	  близость события к сущности. События добавляются и в конец context.
 
 	POST /v1/context/structured
 	  Тело: {
@@ -118,10 +145,36 @@ Package semanticmemory реализует семантическую памят
 	  Ответ: {"entity_id": "...", "context": {...}, "time_range": "last_24h"}
 	  Контекст сущности из MinIO (снимок состояния). Требует настроенного MinIO.
 
+## Хронология (timeline)
+
+	GET /v1/timeline?entity_id=player-1&time_range=last_7d&page=1&page_size=20&summarize=true
+	  Параметры:
+	    entity_id   — обязательно
+	    world_id    — опционально
+	    from, to    — RFC3339; если from не задан, используется time_range (default: last_24h)
+	    page        — номер страницы (default: 1 — самые свежие события)
+	    page_size   — размер страницы (default: 20, max: 100)
+	    summarize   — true: краткий пересказ страницы от Oracle (кэшируется)
+	  Ответ: TimelineResponse — entries (TimelineEvent + человекочитаемый text)
+	         в хронологическом порядке внутри страницы, total_events, has_more,
+	         entities, summary.
+	  Хронология строится через BuildStructuredContext из событий Neo4j.
+
 ## Служебные
 
 	GET /health
-	  Ответ: {"status": "healthy", "time": "<RFC3339>"}
+	  Ответ: HealthReport — {"status": "healthy"|"degraded", "time": "<RFC3339>", "ready": true,
+	         "dependencies": {"neo4j": {"status": "up", "latency_ms": 3},
+	                          "chroma": {"status": "down", "latency_ms": 2000, "error": "..."}}}
+	  Пингует Neo4j (VerifyConnectivity) и векторное хранилище (heartbeat ChromaDB,
+	  /healthz Qdrant, ping Postgres) с таймаутом 2s. Всегда 200 — liveness.
+
+	GET /ready
+	  Ответ: HealthReport; 200, когда индексация запущена и все хранилища доступны, иначе 503.
+
+	GET /v1/indexing/metrics
+	  Ответ: PipelineMetrics — глубина очереди, число пакетов/событий (в т.ч. отброшенных при остановке),
+	         латентность последнего и среднего пакета, максимальная задержка события.
 
 # Ключевые типы
 
@@ -129,12 +182,25 @@ Package semanticmemory реализует семантическую памят
 
 Интерфейс для смены бэкенда векторного хранилища:
   - UpsertDocument    — добавить/обновить документ
+  - UpsertDocuments   — добавить/обновить пакет документов одним запросом
   - GetDocuments      — получить документы по списку ID
   - SearchEventsByType — найти события по типу (through /get с where-фильтром)
   - QueryByMetadata   — гибкий запрос по metadata-полям (event_type, world_id, ...)
   - Close             — закрыть соединение
 
-Реализации: ChromaClient (chroma.go), ChromaV2Client (chroma_v2.go, тег сборки chroma_v2_enabled).
+Реализации (выбор через SEMANTIC_VECTOR_BACKEND, см. NewSemanticStorage):
+  - ChromaClient (chroma.go), ChromaV2Client (chroma_v2.go, тег сборки chroma_v2_enabled)
+    В режиме CHROMA_COLLECTION_MODE=per_world у каждого мира своя коллекция
+    {CHROMA_COLLECTION_NAME}_{world_id} (chroma_routing.go), создаётся при первой записи.
+    Запись маршрутизируется по metadata world_id, чтение — по world_id в where-фильтре;
+    без world_id чтение идёт по всем коллекциям миров. Перенос общей коллекции —
+    ChromaClient.SplitByWorld (chroma_migrate.go), утилита cmd/migrate-collections.
+  - QdrantClient (qdrant.go) — REST API, коллекция создаётся при первой записи
+  - PgVectorClient (pgvector.go) — таблица с колонкой vector и JSONB-метаданными
+
+Qdrant и pgvector получают векторы от Embedder (embedder.go, Ollama /api/embed).
+Общий набор тестов соответствия — storage_conformance_test.go: бэкенд проверяется,
+если задан CHROMA_URL, QDRANT_URL или PGVECTOR_DSN.
 
 ## EntityInfo (context_structured.go)
 
@@ -175,6 +241,10 @@ Package semanticmemory реализует семантическую памят
 Рёбра:
   - (:Event)-[:RELATED_TO]->(:Entity)  — создаётся при сохранении события
   - (:Entity)-[:CONTAINS]->(:Entity)   — создаётся для инвентаря (entity.created)
+  - (:Entity)-[:ATTACKED|TALKED_TO|LOCATED_IN|ASSIGNED|...]->(:Entity) — извлекаются из payload
+    RelationshipExtractor по правилам (relationship_extractor.go). Правила загружаются из MinIO
+    (RELATION_RULES_BUCKET / RELATION_RULES_KEY, пример: configs/relationship_rules.yaml),
+    при отсутствии файла используются DefaultRelationshipRules.
 
 # ChromaDB metadata-поля событий
 
@@ -0,0 +1,91 @@
+// Package semanticmemory handles text embeddings for vector storage backends.
+package semanticmemory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Embedder converts texts into embedding vectors.
+// ChromaDB embeds documents on the server side; Qdrant and pgvector need vectors from the client.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// OllamaEmbedder computes embeddings through the Ollama /api/embed endpoint.
+type OllamaEmbedder struct {
+	baseURL    string
+	model      string
+	httpClient *http.Client
+}
+
+// Ensure OllamaEmbedder implements Embedder interface
+var _ Embedder = (*OllamaEmbedder)(nil)
+
+// NewOllamaEmbedder creates an embedder using EMBEDING_URL and EMBEDING_MODEL (same variables as ChromaV2Client).
+func NewOllamaEmbedder() *OllamaEmbedder {
+	url := os.Getenv("EMBEDING_URL")
+	if url == "" {
+		url = "http://qwen3-service:11434"
+	}
+	model := os.Getenv("EMBEDING_MODEL")
+	if model == "" {
+		model = "nomic-embed-text:latest"
+	}
+	return &OllamaEmbedder{
+		baseURL: url,
+		model:   model,
+		httpClient: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+	}
+}
+
+// Embed returns one vector per input text, in the same order.
+func (e *OllamaEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	jsonData, err := json.Marshal(map[string]interface{}{
+		"model": e.model,
+		"input": texts,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal embed request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", e.baseURL+"/api/embed", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute embed request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("embed request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Embeddings [][]float32 `json:"embeddings"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode embed response: %w", err)
+	}
+	if len(result.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("mismatched lengths in embed response: texts=%d, embeddings=%d", len(texts), len(result.Embeddings))
+	}
+	return result.Embeddings, nil
+}
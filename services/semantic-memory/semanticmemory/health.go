@@ -0,0 +1,125 @@
+package semanticmemory
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// healthCheckTimeout bounds every dependency check of /health and /ready.
+const healthCheckTimeout = 2 * time.Second
+
+// Health statuses.
+const (
+	HealthHealthy  = "healthy"
+	HealthDegraded = "degraded"
+	DependencyUp   = "up"
+	DependencyDown = "down"
+)
+
+// DependencyStatus is the result of a dependency check.
+type DependencyStatus struct {
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// HealthReport is the response of /health and /ready.
+type HealthReport struct {
+	Status       string                      `json:"status"`
+	Time         string                      `json:"time"`
+	Ready        bool                        `json:"ready"`
+	Dependencies map[string]DependencyStatus `json:"dependencies"`
+}
+
+// dependencyCheck pings one backing store.
+type dependencyCheck func(ctx context.Context) error
+
+// dependencyChecks returns the checks of Neo4j and the vector store backend.
+func (s *Service) dependencyChecks() map[string]dependencyCheck {
+	return map[string]dependencyCheck{
+		"neo4j": func(ctx context.Context) error { return s.indexer.neo4j.Ping() },
+		VectorBackend(): func(ctx context.Context) error {
+			return s.indexer.chroma.Ping(ctx)
+		},
+	}
+}
+
+// checkDependencies runs the checks concurrently; a check that outlives the timeout is reported down.
+func checkDependencies(ctx context.Context, checks map[string]dependencyCheck, timeout time.Duration) map[string]DependencyStatus {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	statuses := make(map[string]DependencyStatus, len(checks))
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check dependencyCheck) {
+			defer wg.Done()
+			start := time.Now()
+			// Не все клиенты учитывают ctx (VerifyConnectivity Neo4j) — ждём не дольше таймаута
+			done := make(chan error, 1)
+			go func() { done <- check(ctx) }()
+			var err error
+			select {
+			case err = <-done:
+			case <-ctx.Done():
+				err = ctx.Err()
+			}
+
+			status := DependencyStatus{Status: DependencyUp, LatencyMs: time.Since(start).Milliseconds()}
+			if err != nil {
+				status.Status = DependencyDown
+				status.Error = err.Error()
+			}
+			mu.Lock()
+			statuses[name] = status
+			mu.Unlock()
+		}(name, check)
+	}
+	wg.Wait()
+	return statuses
+}
+
+// buildHealthReport summarizes dependency statuses: healthy when every dependency is up.
+func buildHealthReport(statuses map[string]DependencyStatus, running bool) HealthReport {
+	report := HealthReport{
+		Status:       HealthHealthy,
+		Time:         time.Now().Format(time.RFC3339),
+		Dependencies: statuses,
+	}
+	for _, status := range statuses {
+		if status.Status != DependencyUp {
+			report.Status = HealthDegraded
+		}
+	}
+	report.Ready = running && report.Status == HealthHealthy
+	return report
+}
+
+// handleHealth handles GET /health: liveness with dependency statuses.
+// Always 200 while the process serves requests, so a store outage does not restart the service.
+func (s *Service) handleHealth(w http.ResponseWriter, r *http.Request) {
+	report := buildHealthReport(checkDependencies(r.Context(), s.dependencyChecks(), healthCheckTimeout), s.running.Load())
+	writeHealthReport(w, http.StatusOK, report)
+}
+
+// handleReady handles GET /ready: 200 only when indexing runs and every backing store is up,
+// otherwise 503, so orchestration platforms hold traffic.
+func (s *Service) handleReady(w http.ResponseWriter, r *http.Request) {
+	report := buildHealthReport(checkDependencies(r.Context(), s.dependencyChecks(), healthCheckTimeout), s.running.Load())
+	status := http.StatusOK
+	if !report.Ready {
+		status = http.StatusServiceUnavailable
+	}
+	writeHealthReport(w, status, report)
+}
+
+func writeHealthReport(w http.ResponseWriter, status int, report HealthReport) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
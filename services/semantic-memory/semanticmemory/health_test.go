@@ -0,0 +1,42 @@
+package semanticmemory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCheckDependencies(t *testing.T) {
+	statuses := checkDependencies(context.Background(), map[string]dependencyCheck{
+		"neo4j":  func(ctx context.Context) error { return nil },
+		"chroma": func(ctx context.Context) error { return errors.New("connection refused") },
+		// Проверка, не учитывающая ctx, не задерживает ответ дольше таймаута
+		"stuck": func(ctx context.Context) error { time.Sleep(time.Second); return nil },
+	}, 50*time.Millisecond)
+
+	if statuses["neo4j"].Status != DependencyUp {
+		t.Errorf("expected neo4j up, got %+v", statuses["neo4j"])
+	}
+	if statuses["chroma"].Status != DependencyDown || statuses["chroma"].Error != "connection refused" {
+		t.Errorf("expected chroma down with error, got %+v", statuses["chroma"])
+	}
+	if statuses["stuck"].Status != DependencyDown || statuses["stuck"].LatencyMs > 500 {
+		t.Errorf("expected timed out check to be down, got %+v", statuses["stuck"])
+	}
+}
+
+func TestBuildHealthReport(t *testing.T) {
+	up := map[string]DependencyStatus{"neo4j": {Status: DependencyUp}, "chroma": {Status: DependencyUp}}
+	if report := buildHealthReport(up, true); report.Status != HealthHealthy || !report.Ready {
+		t.Errorf("expected healthy and ready, got %+v", report)
+	}
+	if report := buildHealthReport(up, false); report.Ready {
+		t.Error("expected not ready before indexing starts")
+	}
+
+	down := map[string]DependencyStatus{"neo4j": {Status: DependencyDown}, "chroma": {Status: DependencyUp}}
+	if report := buildHealthReport(down, true); report.Status != HealthDegraded || report.Ready {
+		t.Errorf("expected degraded and not ready, got %+v", report)
+	}
+}
@@ -5,16 +5,19 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"os"
 	"strings"
 	"time"
 
 	"multiverse-core.io/shared/eventbus"
 	"multiverse-core.io/shared/jsonpath"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+	"multiverse-core.io/shared/tracing"
 
 	minio "github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // toStringSlice converts an interface{} to a []string
@@ -74,33 +77,26 @@ type RelationsMetrics struct {
 	FallbackCount  int64 // events using legacy LinkEventToEntities
 	EntityCreated  int64 // stub entities auto-created from relations
 	ValidationErrs int64 // relations validation failures
+	ExtractedCount int64 // relations extracted from payload by RelationshipExtractor rules
 }
 
 // Indexer processes entity events and indexes them.
 type Indexer struct {
-	chroma  SemanticStorage
-	neo4j   *Neo4jClient
-	minio   *minio.Client
-	Metrics RelationsMetrics
+	chroma    SemanticStorage
+	neo4j     *Neo4jClient
+	minio     *minio.Client
+	pipeline  *IndexPipeline
+	relations *RelationshipExtractor
+	dedup     *Deduplicator // nil — every delivered event is indexed
+	Metrics   RelationsMetrics
 }
 
 // NewIndexer creates a new Indexer.
 func NewIndexer() (*Indexer, error) {
-	var storage SemanticStorage
-	var err error
-
-	// Check environment variable to determine which implementation to use
-	useChromaV2 := os.Getenv("CHROMA_USE_V2") == "true"
-	log.Printf("Using ChromaDB v2: %t", useChromaV2)
-	if useChromaV2 {
-		// Only try to create ChromaV2Client if the build tag is enabled
-		storage, err = createChromaV2Client()
-		if err != nil {
-			log.Printf("Warning: failed to create ChromaDB v2 client: %v. Falling back to ChromaDB v1 client.", err)
-			storage = NewChromaClient() // ← Возвращаемся к старому клиенту в случае ошибки
-		}
-	} else {
-		storage = NewChromaClient() // ← Использует старый клиент по умолчанию
+	// Backend is selected by SEMANTIC_VECTOR_BACKEND (chroma, qdrant, pgvector)
+	storage, err := NewSemanticStorage(context.Background())
+	if err != nil {
+		return nil, err
 	}
 
 	neo4j, err := NewNeo4jClient()
@@ -115,19 +111,22 @@ func NewIndexer() (*Indexer, error) {
 	}
 
 	minioClient, err := minio.New(minioEndpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4("minioadmin", "minioadmin", ""),
-		Secure: false,
+		Creds:     credentials.NewStaticV4("minioadmin", "minioadmin", ""),
+		Secure:    false,
+		Transport: tracing.NewTransport("minio", nil),
 	})
 	if err != nil {
-		log.Printf("Warning: failed to create MinIO client: %v", err)
+		logging.Warnf("Failed to create MinIO client: %v", err)
 		// Continue without MinIO client
 		minioClient = nil
 	}
 
 	return &Indexer{
-		chroma: storage,
-		neo4j:  neo4j,
-		minio:  minioClient,
+		chroma:    storage,
+		neo4j:     neo4j,
+		minio:     minioClient,
+		relations: NewRelationshipExtractor(LoadRelationshipRules(context.Background(), minioClient)),
+		dedup:     NewDeduplicator(DedupConfigFromEnv()),
 	}, nil
 }
 
@@ -135,7 +134,7 @@ func NewIndexer() (*Indexer, error) {
 func (i *Indexer) HandleEvent(ev eventbus.Event) {
 	// Validate input event
 	if ev.ID == "" {
-		log.Printf("Invalid event: missing ID")
+		logging.Warnf("Invalid event: missing ID")
 		return
 	}
 
@@ -151,8 +150,22 @@ func (i *Indexer) HandleEvent(ev eventbus.Event) {
 		return
 	}
 
-	// Process all events, not just entity-related events
-	ctx := context.Background()
+	// Skip repeats: the same event delivered from another topic, or a derived event with a copied payload
+	if i.dedup != nil {
+		if verdict, original := i.dedup.Check(ev); verdict != dedupNew {
+			logging.Debugf("Skipping duplicate event %s (%s) of %s", ev.ID, ev.Type, original)
+			return
+		}
+	}
+
+	// Если запущен конвейер — событие индексируется асинхронно пакетом
+	if i.pipeline != nil {
+		i.pipeline.Enqueue(ev)
+		return
+	}
+
+	// Process all events, not just entity-related events; calls continue the event's trace
+	ctx := ev.TraceContext(context.Background())
 
 	// Save to both ChromaDB and Neo4j independently
 	i.saveEventToChroma(ctx, ev)
@@ -164,14 +177,70 @@ func (i *Indexer) HandleEvent(ev eventbus.Event) {
 	}
 }
 
+// indexBatch индексирует пакет событий: одна bulk-запись в ChromaDB и один
+// UNWIND-запрос в Neo4j, затем связи и сущности по каждому событию.
+func (i *Indexer) indexBatch(ctx context.Context, events []eventbus.Event) {
+	// Пакет объединяет события разных трасс: спан пакета связан с каждой из них
+	linked := make([]trace.SpanContext, 0, len(events))
+	for _, ev := range events {
+		linked = append(linked, ev.SpanContext())
+	}
+	ctx, span := tracing.Start(ctx, "semantic-memory.index_batch", linked...)
+	defer span.End()
+
+	docs := make([]Document, 0, len(events))
+	for _, ev := range events {
+		docs = append(docs, i.buildEventDocument(ev))
+	}
+	if err := i.chroma.UpsertDocuments(ctx, docs); err != nil {
+		// Bulk-запись не удалась — сохраняем события по одному, как и в Neo4j
+		logging.Errorf("ChromaDB batch upsert failed for %d events, falling back to per-event writes: %v", len(docs), err)
+		for _, ev := range events {
+			i.saveEventToChroma(ctx, ev)
+		}
+	}
+
+	_, neo4jSpan := tracing.Start(ctx, "neo4j.SaveEventsAsGraph")
+	err := i.neo4j.SaveEventsAsGraph(events)
+	tracing.End(neo4jSpan, err)
+	if err != nil {
+		// Bulk-запись не удалась — сохраняем события по одному, чтобы не потерять пакет
+		logging.Errorf("Neo4j batch save failed for %d events, falling back to per-event writes: %v", len(events), err)
+		for _, ev := range events {
+			i.saveEventToNeo4j(ctx, ev)
+		}
+	} else {
+		for _, ev := range events {
+			i.linkEventInNeo4j(ev)
+		}
+	}
+
+	for _, ev := range events {
+		if ev.Type == "entity.created" || ev.Type == "entity.updated" {
+			i.processEntityEvent(ctx, ev)
+		}
+	}
+}
+
 // saveEventToChroma saves an event to ChromaDB
 func (i *Indexer) saveEventToChroma(ctx context.Context, ev eventbus.Event) {
 	// Validate input event
 	if ev.ID == "" {
-		log.Printf("Invalid event: missing ID for ChromaDB")
+		logging.Warnf("Invalid event: missing ID for ChromaDB")
 		return
 	}
 
+	// Save to ChromaDB
+	doc := i.buildEventDocument(ev)
+	if err := i.chroma.UpsertDocument(ctx, doc.ID, doc.Text, doc.Metadata); err != nil {
+		logging.Errorf("ChromaDB upsert failed for event %s: %v", ev.ID, err)
+	} else {
+		logging.Infof("Saved event %s to ChromaDB", ev.ID)
+	}
+}
+
+// buildEventDocument builds the ChromaDB document (text + metadata) for an event
+func (i *Indexer) buildEventDocument(ev eventbus.Event) Document {
 	// Create a text representation of the event for ChromaDB
 	eventText := i.buildEventTextContext(ev)
 
@@ -204,12 +273,10 @@ func (i *Indexer) saveEventToChroma(ctx context.Context, ev eventbus.Event) {
 		metadata["entity.type"] = entityType
 	}
 
-	// Save to ChromaDB
-	eventID := fmt.Sprintf("event_%s", ev.ID)
-	if err := i.chroma.UpsertDocument(ctx, eventID, eventText, metadata); err != nil {
-		log.Printf("ChromaDB upsert failed for event %s: %v", ev.ID, err)
-	} else {
-		log.Printf("Saved event %s to ChromaDB", ev.ID)
+	return Document{
+		ID:       fmt.Sprintf("event_%s", ev.ID),
+		Text:     eventText,
+		Metadata: metadata,
 	}
 }
 
@@ -258,7 +325,7 @@ func (i *Indexer) buildEventTextContext(ev eventbus.Event) string {
 // saveEventToNeo4j saves an event to Neo4j with explicit relations priority.
 // If event has Relations[] — applies them directly (Этап 3: explicit relations).
 // Falls back to legacy LinkEventToEntities for backward compatibility.
-func (i *Indexer) saveEventToNeo4j(_ context.Context, ev eventbus.Event) error {
+func (i *Indexer) saveEventToNeo4j(ctx context.Context, ev eventbus.Event) error {
 	// Serialize payload to JSON string
 	payloadJSON, err := json.Marshal(ev.Payload)
 	if err != nil {
@@ -266,19 +333,30 @@ func (i *Indexer) saveEventToNeo4j(_ context.Context, ev eventbus.Event) error {
 	}
 
 	// Save event node itself
-	if err := i.neo4j.SaveEventAsGraph(ev, string(payloadJSON)); err != nil {
-		log.Printf("Neo4j SaveEventAsGraph failed for event %s: %v", ev.ID, err)
+	_, span := tracing.Start(ctx, "neo4j.SaveEventAsGraph")
+	err = i.neo4j.SaveEventAsGraph(ev, string(payloadJSON))
+	tracing.End(span, err)
+	if err != nil {
+		logging.Errorf("Neo4j SaveEventAsGraph failed for event %s: %v", ev.ID, err)
 		return fmt.Errorf("saveEventAsGraph failed for event %s: %w", ev.ID, err)
 	}
 
+	i.linkEventInNeo4j(ev)
+	return nil
+}
+
+// linkEventInNeo4j creates relations for an already saved event node:
+// explicit Relations[] first, then legacy Event→Entity links from payload,
+// then Entity→Entity edges extracted by RelationshipExtractor rules.
+func (i *Indexer) linkEventInNeo4j(ev eventbus.Event) {
 	// ✨ Этап 3: Если есть явные связи — применяем их
 	if len(ev.Relations) > 0 {
 		i.Metrics.ExplicitCount++
 		if err := i.applyExplicitRelations(ev); err != nil {
-			log.Printf("Explicit relations apply failed for event %s: %v", ev.ID, err)
+			logging.Errorf("Explicit relations apply failed for event %s: %v", ev.ID, err)
 			// Fallback — не блокируем сохранение события
 		} else {
-			log.Printf("Applied %d explicit relations for event %s (total: %d)", len(ev.Relations), ev.ID, i.Metrics.ExplicitCount)
+			logging.Infof("Applied %d explicit relations for event %s (total: %d)", len(ev.Relations), ev.ID, i.Metrics.ExplicitCount)
 		}
 		// Не return — продолжаем к LinkEventToEntities для создания связей Event→Entity
 	}
@@ -286,10 +364,17 @@ func (i *Indexer) saveEventToNeo4j(_ context.Context, ev eventbus.Event) error {
 	// Fallback: старая логика для обратной совместимости (события без relations)
 	i.Metrics.FallbackCount++
 	if err := i.neo4j.LinkEventToEntities(ev.ID, ev.Payload); err != nil {
-		log.Printf("Neo4j LinkEventToEntities fallback failed for event %s: %v", ev.ID, err)
+		logging.Errorf("Neo4j LinkEventToEntities fallback failed for event %s: %v", ev.ID, err)
 	}
 
-	return nil
+	// Связи Entity→Entity из payload по правилам (target → ATTACKED, npc_id → TALKED_TO, ...).
+	// Применяются после LinkEventToEntities, чтобы узлы целей уже существовали.
+	if i.relations != nil {
+		if extracted := i.relations.Extract(ev); len(extracted) > 0 {
+			i.Metrics.ExtractedCount += int64(len(extracted))
+			i.applyRelations(ev, extracted)
+		}
+	}
 }
 
 // applyExplicitRelations создаёт семантические связи из ev.Relations.
@@ -302,9 +387,15 @@ func (i *Indexer) applyExplicitRelations(ev eventbus.Event) error {
 		return fmt.Errorf("validate relations: %w", err)
 	}
 
+	i.applyRelations(ev, ev.Relations)
+	return nil
+}
+
+// applyRelations создаёт stub-Entity для участников и рёбра графа для переданных связей.
+func (i *Indexer) applyRelations(ev eventbus.Event, relations []eventbus.Relation) {
 	// Создаём stub-Entity для всех участников связей
 	seen := make(map[string]bool)
-	for _, rel := range ev.Relations {
+	for _, rel := range relations {
 		for _, entityID := range []string{rel.From, rel.To} {
 			if !seen[entityID] {
 				i.ensureEntityFromRelation(entityID, eventbus.GetWorldIDFromEvent(ev))
@@ -316,10 +407,9 @@ func (i *Indexer) applyExplicitRelations(ev eventbus.Event) error {
 		if err := i.neo4j.CreateRelation(
 			rel.From, rel.To, rel.Type, rel.Directed, rel.Metadata,
 		); err != nil {
-			log.Printf("Failed to create relation %s-[%s]->%s: %v", rel.From, rel.Type, rel.To, err)
+			logging.Errorf("Failed to create relation %s-[%s]->%s: %v", rel.From, rel.Type, rel.To, err)
 		}
 	}
-	return nil
 }
 
 // ensureEntityFromRelation создаёт stub-Entity если его ещё нет.
@@ -342,12 +432,49 @@ func (i *Indexer) ensureEntityFromRelation(entityID, worldID string) {
 	}
 
 	if err := i.neo4j.EnsureEntity(realEntityID, entityType, worldID, nil); err != nil {
-		log.Printf("Failed to ensure stub entity %s: %v", realEntityID, err)
+		logging.Errorf("Failed to ensure stub entity %s: %v", realEntityID, err)
 		return
 	}
 	i.Metrics.EntityCreated++
 }
 
+// containmentRelations turns containment metadata of an entity payload into graph edges:
+// parent_region → (entity)-[:LOCATED_IN]->(region), parent_world → (entity)-[:PART_OF]->(world).
+// Producers such as WorldGenerator set parent_region on cities and parent_world on regions,
+// so the graph links city → region → world even when events carry no explicit Relations[].
+func containmentRelations(ev eventbus.Event, entityID string, payload map[string]interface{}) []eventbus.Relation {
+	var relations []eventbus.Relation
+	for _, link := range []struct{ key, relType string }{
+		{"parent_region", eventbus.RelLocatedIn},
+		{"parent_world", eventbus.RelPartOf},
+	} {
+		for _, parentID := range referencedEntityIDs(payload[link.key]) {
+			if parentID == entityID {
+				continue
+			}
+			relations = append(relations, eventbus.Relation{
+				From:     entityID,
+				To:       parentID,
+				Type:     link.relType,
+				Directed: true,
+				Metadata: map[string]any{
+					"event_id": ev.ID,
+					"source":   "containment",
+				},
+			})
+		}
+	}
+	return relations
+}
+
+// GetDedupMetrics returns deduplication statistics; zero when deduplication is disabled.
+func (i *Indexer) GetDedupMetrics() DedupMetrics {
+	if i.dedup == nil {
+		return DedupMetrics{}
+	}
+	return i.dedup.Metrics()
+}
+
 // GetRelationsMetrics returns a copy of the current relations processing metrics.
 func (i *Indexer) GetRelationsMetrics() RelationsMetrics {
 	return i.Metrics
@@ -374,7 +501,7 @@ func (i *Indexer) processEntityEvent(ctx context.Context, ev eventbus.Event) {
 		entityID, ok = pa.GetString("entity_id")
 	}
 	if !ok || entityID == "" {
-		log.Printf("Invalid entity event: missing entity.id or entity_id")
+		logging.Warnf("Invalid entity event: missing entity.id or entity_id")
 		return
 	}
 
@@ -414,7 +541,7 @@ func (i *Indexer) processEntityEvent(ctx context.Context, ev eventbus.Event) {
 
 	// Index in ChromaDB
 	if err := i.chroma.UpsertDocument(ctx, entityID, textContext, metadata); err != nil {
-		log.Printf("ChromaDB upsert failed for %s: %v", entityID, err)
+		logging.Errorf("ChromaDB upsert failed for %s: %v", entityID, err)
 	}
 
 	// Add world_id to payload for Neo4j indexing
@@ -426,7 +553,12 @@ func (i *Indexer) processEntityEvent(ctx context.Context, ev eventbus.Event) {
 
 	// Index in Neo4j
 	if err := i.neo4j.UpsertEntity(entityID, entityType, neo4jPayload); err != nil {
-		log.Printf("Neo4j upsert failed for %s: %v", entityID, err)
+		logging.Errorf("Neo4j upsert failed for %s: %v", entityID, err)
+	}
+
+	// Containment edges from creation payloads: city → region → world
+	if containment := containmentRelations(ev, entityID, payload); len(containment) > 0 {
+		i.applyRelations(ev, containment)
 	}
 
 	// Create relationships for inventory items
@@ -434,19 +566,19 @@ func (i *Indexer) processEntityEvent(ctx context.Context, ev eventbus.Event) {
 		inventory := toStringSlice(inv)
 		for _, itemID := range inventory {
 			if err := i.neo4j.CreateRelationship(entityID, itemID, "CONTAINS"); err != nil {
-				log.Printf("Neo4j relationship failed: %s -> %s: %v", entityID, itemID, err)
+				logging.Errorf("Neo4j relationship failed: %s -> %s: %v", entityID, itemID, err)
 			}
 		}
 	}
 
-	log.Printf("Indexed entity %s", entityID)
+	logging.Infof("Indexed entity %s", entityID)
 }
 
 // GetContext retrieves full context for entity IDs. Uses Neo4j as primary source, falls back to ChromaDB.
 func (i *Indexer) GetContext(ctx context.Context, entityIDs []string, depth int) (map[string]string, error) {
 	entityCache, err := i.neo4j.GetEntityCache(entityIDs)
 	if err != nil {
-		log.Printf("Neo4j GetEntityCache failed, falling back to ChromaDB: %v", err)
+		logging.Errorf("Neo4j GetEntityCache failed, falling back to ChromaDB: %v", err)
 		return i.chroma.GetDocuments(ctx, entityIDs)
 	}
 
@@ -475,7 +607,7 @@ func (i *Indexer) GetContext(ctx context.Context, entityIDs []string, depth int)
 func (i *Indexer) GetEventsByType(ctx context.Context, eventType string, limit int) ([]string, error) {
 	events, err := i.neo4j.GetEventsByTypeNeo4j(eventType, limit)
 	if err != nil {
-		log.Printf("Neo4j GetEventsByTypeNeo4j failed, falling back to ChromaDB: %v", err)
+		logging.Errorf("Neo4j GetEventsByTypeNeo4j failed, falling back to ChromaDB: %v", err)
 		return i.chroma.SearchEventsByType(ctx, eventType, limit)
 	}
 
@@ -509,20 +641,22 @@ func (i *Indexer) GetEntityContext(ctx context.Context, entityID string, timeRan
 	// Try to load from global bucket first (entities-global)
 	bucket := "entities-global"
 	obj, err := i.minio.GetObject(ctx, bucket, entityID+".json", minio.GetObjectOptions{})
-	if err == nil {
-		defer obj.Close()
-		var result map[string]interface{}
-		if err := json.NewDecoder(obj).Decode(&result); err != nil {
-			return nil, fmt.Errorf("failed to decode entity from global bucket: %w", err)
-		}
-		return result, nil
+	if err != nil {
+		return nil, fmt.Errorf("failed to load entity %s: %w", entityID, storage.ClassifyError(err))
 	}
+	defer obj.Close()
 
-	// If not found in global bucket, try to find it in world buckets
-	// This is a simplified approach - in production, we'd need better logic for world identification
-
-	// Return error if no entity found
-	return nil, fmt.Errorf("entity %s not found in storage", entityID)
+	// GetObject is lazy: a missing key surfaces only on decode
+	var result map[string]interface{}
+	if err := json.NewDecoder(obj).Decode(&result); err != nil {
+		err = storage.ClassifyError(err)
+		if storage.IsNotFound(err) {
+			// World buckets are not searched yet - production would need world identification here
+			return nil, fmt.Errorf("entity %s not found in storage: %w", entityID, err)
+		}
+		return nil, fmt.Errorf("failed to decode entity from global bucket: %w", err)
+	}
+	return result, nil
 }
 
 // Close the chroma client when indexer is done
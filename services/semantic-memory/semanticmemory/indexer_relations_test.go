@@ -2,6 +2,8 @@ package semanticmemory
 
 import (
 	"testing"
+
+	"multiverse-core.io/shared/eventbus"
 )
 
 func TestRelationsMetrics(t *testing.T) {
@@ -25,3 +27,21 @@ func TestApplyExplicitRelations_EmptyRelations(t *testing.T) {
 	// Проверяем что пустые relations не вызывают панику
 	// (полный тест требует mock Neo4j — это интеграционный тест)
 }
+
+func TestContainmentRelations(t *testing.T) {
+	ev := eventbus.NewEvent("entity.created", "world-generator", "world-1", nil)
+
+	city := containmentRelations(ev, "city-1", map[string]interface{}{"name": "Ashford", "parent_region": "region-1"})
+	if len(city) != 1 || city[0].From != "city-1" || city[0].To != "region-1" || city[0].Type != eventbus.RelLocatedIn || !city[0].Directed {
+		t.Errorf("expected city LOCATED_IN region, got %+v", city)
+	}
+
+	region := containmentRelations(ev, "region-1", map[string]interface{}{"parent_world": map[string]interface{}{"id": "world-1"}})
+	if len(region) != 1 || region[0].To != "world-1" || region[0].Type != eventbus.RelPartOf {
+		t.Errorf("expected region PART_OF world, got %+v", region)
+	}
+
+	if rels := containmentRelations(ev, "world-1", map[string]interface{}{"parent_world": "world-1"}); len(rels) != 0 {
+		t.Errorf("expected no self containment, got %+v", rels)
+	}
+}
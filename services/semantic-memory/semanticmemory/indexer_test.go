@@ -142,7 +142,11 @@ func TestIndexer_GetContextWithEvents(t *testing.T) {
 
 	// Get context with events
 	ctx := context.Background()
-	contexts, err := indexer.GetContextWithEvents(ctx, []string{"player-1"}, []string{"player.action.attack"}, 1)
+	contexts, err := indexer.GetContextWithEvents(ctx, ContextWithEventsQuery{
+		EntityIDs:  []string{"player-1"},
+		EventTypes: []string{"player.action.attack"},
+		Depth:      1,
+	})
 	if err != nil {
 		t.Fatalf("Failed to get context with events: %v", err)
 	}
@@ -4,12 +4,12 @@ package semanticmemory
 import (
 	"encoding/json"
 	"fmt"
-	"log"
 	"os"
 	"strings"
 	"time"
 
 	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
 
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
 )
@@ -48,7 +48,7 @@ func NewNeo4jClient() (*Neo4jClient, error) {
 	client := &Neo4jClient{driver: driver}
 	// Create indexes for efficient queries
 	if err := client.createIndexes(); err != nil {
-		log.Printf("Warning: failed to create indexes: %v", err)
+		logging.Warnf("Failed to create indexes: %v", err)
 	}
 
 	return client, nil
@@ -77,7 +77,7 @@ func (n *Neo4jClient) createIndexes() error {
 		_, err := session.Run(idx, nil)
 		if err != nil {
 			lastErr = err
-			log.Printf("Index creation failed: %v", err)
+			logging.Errorf("Index creation failed: %v", err)
 			continue
 		}
 	}
@@ -442,7 +442,7 @@ RETURN r
 			return nil, consumeErr
 		})
 		if err != nil {
-			log.Printf("Failed to create %s link Event(%s)→Entity(%s): %v", relType, eventID, link.TargetID, err)
+			logging.Errorf("Failed to create %s link Event(%s)→Entity(%s): %v", relType, eventID, link.TargetID, err)
 		}
 	}
 
@@ -468,7 +468,7 @@ RETURN r
 			return nil, consumeErr
 		})
 		if err != nil {
-			log.Printf("Failed to create %s link Event(%s)→Event(%s): %v", relType, eventID, link.TargetID, err)
+			logging.Errorf("Failed to create %s link Event(%s)→Event(%s): %v", relType, eventID, link.TargetID, err)
 		}
 	}
 
@@ -1002,6 +1002,14 @@ LIMIT $limit
 	return events, nil
 }
 
+// Ping verifies connectivity to Neo4j.
+func (n *Neo4jClient) Ping() error {
+	if n == nil || n.driver == nil {
+		return fmt.Errorf("neo4j driver not initialized")
+	}
+	return n.driver.VerifyConnectivity()
+}
+
 // Close closes the Neo4j driver.
 func (n *Neo4jClient) Close() {
 	_ = n.driver.Close()
@@ -1253,12 +1261,72 @@ SET e.type = $event_type,
 
 	// Link event to entities in payload
 	if linkErr := n.LinkEventToEntities(ev.ID, ev.Payload); linkErr != nil {
-		log.Printf("Warning: failed to link event %s to entities: %v", ev.ID, linkErr)
+		logging.Warnf("Failed to link event %s to entities: %v", ev.ID, linkErr)
 	}
 
 	return nil
 }
 
+// SaveEventsAsGraph saves a batch of event nodes in a single transaction using UNWIND.
+// Links to entities are not created here — callers link each event separately.
+func (n *Neo4jClient) SaveEventsAsGraph(events []eventbus.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	rows := make([]map[string]any, 0, len(events))
+	for _, ev := range events {
+		payloadJSON, err := json.Marshal(ev.Payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal payload for event %s: %w", ev.ID, err)
+		}
+		rawData, err := json.Marshal(ev)
+		if err != nil {
+			return fmt.Errorf("failed to marshal event %s to raw_data: %w", ev.ID, err)
+		}
+
+		var scopeID any
+		if scope := eventbus.GetScopeFromEvent(ev); scope != nil {
+			scopeID = scope.ID
+		}
+
+		rows = append(rows, map[string]any{
+			"event_id":     ev.ID,
+			"event_type":   ev.Type,
+			"timestamp":    ev.Timestamp,
+			"source":       ev.Source,
+			"world_id":     eventbus.GetWorldIDFromEvent(ev),
+			"scope_id":     scopeID,
+			"payload_json": string(payloadJSON),
+			"raw_data":     string(rawData),
+		})
+	}
+
+	session := n.driver.NewSession(neo4j.SessionConfig{DatabaseName: "neo4j"})
+	defer session.Close()
+
+	_, err := session.WriteTransaction(func(tx neo4j.Transaction) (any, error) {
+		query := `
+UNWIND $rows AS row
+MERGE (e:Event {id: row.event_id})
+SET e.type = row.event_type,
+    e.timestamp = row.timestamp,
+    e.source = row.source,
+    e.world_id = row.world_id,
+    e.scope_id = coalesce(row.scope_id, e.scope_id),
+    e.payload_json = row.payload_json,
+    e.raw_data = row.raw_data
+`
+		result, runErr := tx.Run(query, map[string]any{"rows": rows})
+		if runErr != nil {
+			return nil, runErr
+		}
+		_, consumeErr := result.Consume()
+		return nil, consumeErr
+	})
+	return err
+}
+
 // extractEntitiesFromPayload extracts entity IDs from event payload
 // It handles various common patterns in event payloads
 func extractEntitiesFromPayload(payload map[string]interface{}) []string {
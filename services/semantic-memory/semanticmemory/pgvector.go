@@ -0,0 +1,250 @@
+// Package semanticmemory handles Postgres/pgvector integration.
+package semanticmemory
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	_ "github.com/jackc/pgx/v5/stdlib" // Драйвер "pgx" для database/sql
+)
+
+// pgTableName ограничивает имя таблицы из PGVECTOR_TABLE безопасными символами
+var pgTableName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// PgVectorClient stores documents in a Postgres table with a pgvector embedding column.
+// Implements the SemanticStorage interface.
+type PgVectorClient struct {
+	db       *sql.DB
+	table    string
+	embedder Embedder
+}
+
+// Ensure PgVectorClient implements SemanticStorage interface
+var _ SemanticStorage = (*PgVectorClient)(nil)
+
+// NewPgVectorClient connects to Postgres and creates the documents table if needed.
+// Reads PGVECTOR_DSN (required) and PGVECTOR_TABLE (default semantic_documents).
+func NewPgVectorClient(ctx context.Context, embedder Embedder) (*PgVectorClient, error) {
+	dsn := os.Getenv("PGVECTOR_DSN")
+	if dsn == "" {
+		return nil, fmt.Errorf("PGVECTOR_DSN is not set")
+	}
+	table := os.Getenv("PGVECTOR_TABLE")
+	if table == "" {
+		table = "semantic_documents"
+	}
+	if !pgTableName.MatchString(table) {
+		return nil, fmt.Errorf("invalid PGVECTOR_TABLE %q", table)
+	}
+
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open postgres connection: %w", err)
+	}
+
+	client := &PgVectorClient{db: db, table: table, embedder: embedder}
+	if err := client.migrate(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+// migrate создаёт расширение vector и таблицу документов.
+// Размерность вектора не фиксируется, чтобы не зависеть от модели эмбеддингов.
+func (c *PgVectorClient) migrate(ctx context.Context) error {
+	statements := []string{
+		`CREATE EXTENSION IF NOT EXISTS vector`,
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			id         TEXT PRIMARY KEY,
+			document   TEXT NOT NULL,
+			metadata   JSONB NOT NULL DEFAULT '{}'::jsonb,
+			embedding  vector,
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`, c.table),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s_metadata_idx ON %s USING GIN (metadata jsonb_path_ops)`, c.table, c.table),
+	}
+	for _, stmt := range statements {
+		if _, err := c.db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to migrate pgvector schema: %w", err)
+		}
+	}
+	return nil
+}
+
+// vectorLiteral formats an embedding as a pgvector text literal: [0.1,0.2,...]
+func vectorLiteral(vector []float32) string {
+	parts := make([]string, len(vector))
+	for i, v := range vector {
+		parts[i] = strconv.FormatFloat(float64(v), 'f', -1, 32)
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
+
+// UpsertDocument adds or updates a document.
+func (c *PgVectorClient) UpsertDocument(ctx context.Context, entityID string, text string, metadata map[string]interface{}) error {
+	return c.UpsertDocuments(ctx, []Document{{ID: entityID, Text: text, Metadata: metadata}})
+}
+
+// UpsertDocuments embeds a batch of documents and upserts them in one transaction.
+func (c *PgVectorClient) UpsertDocuments(ctx context.Context, docs []Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	texts := make([]string, len(docs))
+	for i, doc := range docs {
+		texts[i] = doc.Text
+	}
+	vectors, err := c.embedder.Embed(ctx, texts)
+	if err != nil {
+		return fmt.Errorf("failed to embed documents: %w", err)
+	}
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`INSERT INTO %s (id, document, metadata, embedding, updated_at)
+		VALUES ($1, $2, $3::jsonb, $4::vector, now())
+		ON CONFLICT (id) DO UPDATE SET
+			document = EXCLUDED.document,
+			metadata = EXCLUDED.metadata,
+			embedding = EXCLUDED.embedding,
+			updated_at = now()`, c.table)
+
+	for i, doc := range docs {
+		metadata := doc.Metadata
+		if metadata == nil {
+			metadata = map[string]interface{}{}
+		}
+		metadataJSON, err := json.Marshal(metadata)
+		if err != nil {
+			return fmt.Errorf("failed to marshal metadata for %s: %w", doc.ID, err)
+		}
+		if _, err := tx.ExecContext(ctx, query, doc.ID, doc.Text, string(metadataJSON), vectorLiteral(vectors[i])); err != nil {
+			return fmt.Errorf("upsert failed for %s: %w", doc.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit upsert: %w", err)
+	}
+	return nil
+}
+
+// GetDocuments retrieves documents by their IDs.
+func (c *PgVectorClient) GetDocuments(ctx context.Context, entityIDs []string) (map[string]string, error) {
+	documents := make(map[string]string, len(entityIDs))
+	if len(entityIDs) == 0 {
+		return documents, nil
+	}
+
+	placeholders := make([]string, len(entityIDs))
+	args := make([]interface{}, len(entityIDs))
+	for i, id := range entityIDs {
+		placeholders[i] = "$" + strconv.Itoa(i+1)
+		args[i] = id
+	}
+
+	query := fmt.Sprintf(`SELECT id, document FROM %s WHERE id IN (%s)`, c.table, strings.Join(placeholders, ","))
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("get failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, text string
+		if err := rows.Scan(&id, &text); err != nil {
+			return nil, fmt.Errorf("failed to scan document: %w", err)
+		}
+		documents[id] = text
+	}
+	return documents, rows.Err()
+}
+
+// queryByMetadata selects documents whose metadata contains all key/value pairs of where.
+func (c *PgVectorClient) queryByMetadata(ctx context.Context, where map[string]interface{}, limit int) ([]map[string]interface{}, error) {
+	if where == nil {
+		where = map[string]interface{}{}
+	}
+	filterJSON, err := json.Marshal(where)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal filter: %w", err)
+	}
+
+	query := fmt.Sprintf(`SELECT id, document, metadata FROM %s
+		WHERE metadata @> $1::jsonb
+		ORDER BY updated_at DESC
+		LIMIT $2`, c.table)
+	rows, err := c.db.QueryContext(ctx, query, string(filterJSON), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []map[string]interface{}
+	for rows.Next() {
+		var id, text string
+		var metadataJSON []byte
+		if err := rows.Scan(&id, &text, &metadataJSON); err != nil {
+			return nil, fmt.Errorf("failed to scan document: %w", err)
+		}
+		metadata := map[string]interface{}{}
+		if err := json.Unmarshal(metadataJSON, &metadata); err != nil {
+			return nil, fmt.Errorf("failed to decode metadata of %s: %w", id, err)
+		}
+		out = append(out, map[string]interface{}{
+			"id":       id,
+			"document": text,
+			"metadata": metadata,
+		})
+	}
+	return out, rows.Err()
+}
+
+// SearchEventsByType searches for events by type using a metadata filter.
+func (c *PgVectorClient) SearchEventsByType(ctx context.Context, eventType string, limit int) ([]string, error) {
+	results, err := c.queryByMetadata(ctx, map[string]interface{}{"event_type": eventType}, limit)
+	if err != nil {
+		return nil, fmt.Errorf("SearchEventsByType: %w", err)
+	}
+
+	documents := make([]string, 0, len(results))
+	for _, result := range results {
+		documents = append(documents, result["document"].(string))
+	}
+	return documents, nil
+}
+
+// QueryByMetadata retrieves documents matching the given metadata filter.
+// Returns a slice of maps with "id", "document", and "metadata" keys.
+func (c *PgVectorClient) QueryByMetadata(ctx context.Context, where map[string]interface{}, limit int) ([]map[string]interface{}, error) {
+	results, err := c.queryByMetadata(ctx, where, limit)
+	if err != nil {
+		return nil, fmt.Errorf("QueryByMetadata: %w", err)
+	}
+	if results == nil {
+		results = []map[string]interface{}{}
+	}
+	return results, nil
+}
+
+// Ping checks the Postgres connection.
+func (c *PgVectorClient) Ping(ctx context.Context) error {
+	return c.db.PingContext(ctx)
+}
+
+// Close closes the connection pool.
+func (c *PgVectorClient) Close() error {
+	return c.db.Close()
+}
@@ -0,0 +1,257 @@
+// Package semanticmemory — buffered batch indexing pipeline.
+package semanticmemory
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+)
+
+// PipelineConfig задаёт границы микро-пакетов индексации.
+type PipelineConfig struct {
+	// BatchSize — максимальное число событий в пакете.
+	BatchSize int
+	// FlushInterval — максимальное время ожидания неполного пакета.
+	FlushInterval time.Duration
+	// QueueSize — ёмкость очереди; при заполнении Enqueue блокируется (backpressure на Kafka).
+	QueueSize int
+}
+
+// PipelineConfigFromEnv читает конфигурацию конвейера из переменных окружения:
+// SEMANTIC_BATCH_SIZE (default 100), SEMANTIC_FLUSH_INTERVAL_MS (default 500),
+// SEMANTIC_QUEUE_SIZE (default 10000).
+func PipelineConfigFromEnv() PipelineConfig {
+	return PipelineConfig{
+		BatchSize:     envInt("SEMANTIC_BATCH_SIZE", 100),
+		FlushInterval: time.Duration(envInt("SEMANTIC_FLUSH_INTERVAL_MS", 500)) * time.Millisecond,
+		QueueSize:     envInt("SEMANTIC_QUEUE_SIZE", 10000),
+	}
+}
+
+// envInt возвращает положительное целое из переменной окружения или значение по умолчанию.
+func envInt(key string, fallback int) int {
+	if raw := os.Getenv(key); raw != "" {
+		if v, err := strconv.Atoi(raw); err == nil && v > 0 {
+			return v
+		}
+		logging.Warnf("Invalid %s value %q, using default %d", key, raw, fallback)
+	}
+	return fallback
+}
+
+// PipelineMetrics — снимок метрик конвейера индексации.
+type PipelineMetrics struct {
+	QueueDepth        int     `json:"queue_depth"`
+	QueueCapacity     int     `json:"queue_capacity"`
+	EventsEnqueued    int64   `json:"events_enqueued"`
+	EventsIndexed     int64   `json:"events_indexed"`
+	EventsDropped     int64   `json:"events_dropped"`
+	BatchesFlushed    int64   `json:"batches_flushed"`
+	LastBatchSize     int64   `json:"last_batch_size"`
+	LastBatchMs       int64   `json:"last_batch_latency_ms"`
+	AvgBatchMs        float64 `json:"avg_batch_latency_ms"`
+	MaxEventLatencyMs int64   `json:"max_event_latency_ms"`
+}
+
+// queuedEvent — событие в очереди вместе со временем постановки (для латентности).
+type queuedEvent struct {
+	event      eventbus.Event
+	enqueuedAt time.Time
+}
+
+// IndexPipeline накапливает события в микро-пакеты (по размеру или по времени)
+// и индексирует их bulk-запросами в ChromaDB и Neo4j в отдельной горутине.
+//
+// Гарантия доставки — at-most-once для событий в очереди: consumer group EventBus
+// фиксирует offset при чтении, до индексации. При аварийном завершении процесса
+// теряется не больше QueueSize + BatchSize событий (очередь и пакет в работе);
+// при штатной остановке очередь дописывается полностью.
+type IndexPipeline struct {
+	indexer *Indexer
+	index   func(ctx context.Context, events []eventbus.Event)
+	cfg     PipelineConfig
+	queue   chan queuedEvent
+	done    chan struct{}
+
+	// mu защищает closed: после закрытия в очередь не попадает ни одно событие,
+	// поэтому финальный сброс видит их все. stopping разблокирует ждущие Enqueue.
+	mu       sync.RWMutex
+	closed   bool
+	stopping chan struct{}
+
+	eventsEnqueued    atomic.Int64
+	eventsIndexed     atomic.Int64
+	eventsDropped     atomic.Int64
+	batchesFlushed    atomic.Int64
+	lastBatchSize     atomic.Int64
+	lastBatchMs       atomic.Int64
+	totalBatchMs      atomic.Int64
+	maxEventLatencyMs atomic.Int64
+}
+
+// NewIndexPipeline создаёт конвейер для индексатора. Значения конфигурации <= 0 заменяются значениями по умолчанию.
+func NewIndexPipeline(indexer *Indexer, cfg PipelineConfig) *IndexPipeline {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = 500 * time.Millisecond
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 10000
+	}
+	p := &IndexPipeline{
+		indexer:  indexer,
+		cfg:      cfg,
+		queue:    make(chan queuedEvent, cfg.QueueSize),
+		done:     make(chan struct{}),
+		stopping: make(chan struct{}),
+	}
+	if indexer != nil {
+		p.index = indexer.indexBatch
+	}
+	return p
+}
+
+// Start запускает конвейер и подключает его к индексатору.
+// При отмене ctx оставшиеся в очереди события дописываются, после чего закрывается Done().
+func (p *IndexPipeline) Start(ctx context.Context) {
+	if p.indexer != nil {
+		p.indexer.pipeline = p
+	}
+	go p.run(ctx)
+}
+
+// Done закрывается после завершения работы конвейера и сброса последнего пакета.
+func (p *IndexPipeline) Done() <-chan struct{} {
+	return p.done
+}
+
+// Enqueue ставит событие в очередь индексации. Блокируется при заполненной очереди.
+// После начала остановки конвейера события отбрасываются.
+func (p *IndexPipeline) Enqueue(ev eventbus.Event) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if !p.closed {
+		select {
+		case p.queue <- queuedEvent{event: ev, enqueuedAt: time.Now()}:
+			p.eventsEnqueued.Add(1)
+			return
+		case <-p.stopping:
+		}
+	}
+	p.eventsDropped.Add(1)
+	logging.Warnf("Index pipeline stopped, dropping event %s", ev.ID)
+}
+
+// close запрещает постановку в очередь и дожидается уже начатых Enqueue.
+func (p *IndexPipeline) close() {
+	close(p.stopping)
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+}
+
+// run — основной цикл: собирает пакет до BatchSize или до FlushInterval.
+func (p *IndexPipeline) run(ctx context.Context) {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]queuedEvent, 0, p.cfg.BatchSize)
+	for {
+		select {
+		case item := <-p.queue:
+			batch = append(batch, item)
+			if len(batch) >= p.cfg.BatchSize {
+				p.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				p.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ctx.Done():
+			// Закрываем очередь и дописываем всё, что успело в неё попасть
+			p.close()
+			for {
+				select {
+				case item := <-p.queue:
+					batch = append(batch, item)
+					if len(batch) >= p.cfg.BatchSize {
+						p.flush(batch)
+						batch = batch[:0]
+					}
+				default:
+					if len(batch) > 0 {
+						p.flush(batch)
+					}
+					return
+				}
+			}
+		}
+	}
+}
+
+// flush индексирует пакет и обновляет метрики.
+func (p *IndexPipeline) flush(batch []queuedEvent) {
+	start := time.Now()
+
+	events := make([]eventbus.Event, len(batch))
+	for idx, item := range batch {
+		events[idx] = item.event
+	}
+
+	// Отдельный контекст: пакет должен быть дописан даже при остановке сервиса
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	p.index(ctx, events)
+	cancel()
+
+	now := time.Now()
+	elapsed := now.Sub(start).Milliseconds()
+	p.lastBatchMs.Store(elapsed)
+	p.totalBatchMs.Add(elapsed)
+	p.lastBatchSize.Store(int64(len(batch)))
+	p.batchesFlushed.Add(1)
+	p.eventsIndexed.Add(int64(len(batch)))
+
+	for _, item := range batch {
+		latency := now.Sub(item.enqueuedAt).Milliseconds()
+		for {
+			current := p.maxEventLatencyMs.Load()
+			if latency <= current || p.maxEventLatencyMs.CompareAndSwap(current, latency) {
+				break
+			}
+		}
+	}
+
+	logging.Infof("Indexed batch of %d events in %dms (queue depth: %d)", len(batch), elapsed, len(p.queue))
+}
+
+// Metrics возвращает текущий снимок метрик конвейера.
+func (p *IndexPipeline) Metrics() PipelineMetrics {
+	m := PipelineMetrics{
+		QueueDepth:        len(p.queue),
+		QueueCapacity:     cap(p.queue),
+		EventsEnqueued:    p.eventsEnqueued.Load(),
+		EventsIndexed:     p.eventsIndexed.Load(),
+		EventsDropped:     p.eventsDropped.Load(),
+		BatchesFlushed:    p.batchesFlushed.Load(),
+		LastBatchSize:     p.lastBatchSize.Load(),
+		LastBatchMs:       p.lastBatchMs.Load(),
+		MaxEventLatencyMs: p.maxEventLatencyMs.Load(),
+	}
+	if m.BatchesFlushed > 0 {
+		m.AvgBatchMs = float64(p.totalBatchMs.Load()) / float64(m.BatchesFlushed)
+	}
+	return m
+}
@@ -0,0 +1,165 @@
+package semanticmemory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+)
+
+// recordingIndex запоминает пакеты, переданные конвейером
+type recordingIndex struct {
+	mu      sync.Mutex
+	batches [][]eventbus.Event
+	flushed chan int
+}
+
+func newRecordingIndex() *recordingIndex {
+	return &recordingIndex{flushed: make(chan int, 100)}
+}
+
+func (r *recordingIndex) index(_ context.Context, events []eventbus.Event) {
+	r.mu.Lock()
+	r.batches = append(r.batches, append([]eventbus.Event(nil), events...))
+	r.mu.Unlock()
+	r.flushed <- len(events)
+}
+
+func (r *recordingIndex) events() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var ids []string
+	for _, batch := range r.batches {
+		for _, ev := range batch {
+			ids = append(ids, ev.ID)
+		}
+	}
+	return ids
+}
+
+func newTestPipeline(cfg PipelineConfig) (*IndexPipeline, *recordingIndex) {
+	rec := newRecordingIndex()
+	p := NewIndexPipeline(nil, cfg)
+	p.index = rec.index
+	return p, rec
+}
+
+func testEvent(n int) eventbus.Event {
+	return eventbus.Event{ID: fmt.Sprintf("ev-%d", n), Type: "player.action"}
+}
+
+func waitFlush(t *testing.T, rec *recordingIndex, timeout time.Duration) int {
+	t.Helper()
+	select {
+	case n := <-rec.flushed:
+		return n
+	case <-time.After(timeout):
+		t.Fatal("batch was not flushed")
+		return 0
+	}
+}
+
+func TestIndexPipelineFlushesFullBatch(t *testing.T) {
+	p, rec := newTestPipeline(PipelineConfig{BatchSize: 3, FlushInterval: time.Hour, QueueSize: 10})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p.Start(ctx)
+
+	for n := 0; n < 7; n++ {
+		p.Enqueue(testEvent(n))
+	}
+	if n := waitFlush(t, rec, time.Second); n != 3 {
+		t.Errorf("expected a batch of 3, got %d", n)
+	}
+	if n := waitFlush(t, rec, time.Second); n != 3 {
+		t.Errorf("expected a batch of 3, got %d", n)
+	}
+	select {
+	case n := <-rec.flushed:
+		t.Errorf("incomplete batch of %d flushed before the interval", n)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestIndexPipelineFlushesOnInterval(t *testing.T) {
+	p, rec := newTestPipeline(PipelineConfig{BatchSize: 100, FlushInterval: 20 * time.Millisecond, QueueSize: 10})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p.Start(ctx)
+
+	p.Enqueue(testEvent(1))
+	if n := waitFlush(t, rec, time.Second); n != 1 {
+		t.Errorf("expected a batch of 1, got %d", n)
+	}
+	cancel()
+	<-p.Done()
+
+	m := p.Metrics()
+	if m.EventsEnqueued != 1 || m.EventsIndexed != 1 || m.BatchesFlushed != 1 || m.LastBatchSize != 1 {
+		t.Errorf("unexpected metrics %+v", m)
+	}
+}
+
+func TestIndexPipelineDrainsOnShutdown(t *testing.T) {
+	p, rec := newTestPipeline(PipelineConfig{BatchSize: 4, FlushInterval: time.Hour, QueueSize: 100})
+	ctx, cancel := context.WithCancel(context.Background())
+	p.Start(ctx)
+
+	for n := 0; n < 10; n++ {
+		p.Enqueue(testEvent(n))
+	}
+	cancel()
+	select {
+	case <-p.Done():
+	case <-time.After(time.Second):
+		t.Fatal("pipeline did not stop")
+	}
+
+	if ids := rec.events(); len(ids) != 10 {
+		t.Errorf("expected all 10 events indexed, got %v", ids)
+	}
+
+	// После остановки события отбрасываются, а не остаются в очереди
+	p.Enqueue(testEvent(10))
+	m := p.Metrics()
+	if m.EventsDropped != 1 || m.QueueDepth != 0 || m.EventsIndexed != 10 {
+		t.Errorf("unexpected metrics after shutdown %+v", m)
+	}
+}
+
+func TestIndexPipelineEnqueueDuringShutdown(t *testing.T) {
+	p, rec := newTestPipeline(PipelineConfig{BatchSize: 8, FlushInterval: time.Millisecond, QueueSize: 4})
+	ctx, cancel := context.WithCancel(context.Background())
+	p.Start(ctx)
+	go func() {
+		for range rec.flushed {
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for n := 0; n < 200; n++ {
+				p.Enqueue(testEvent(w*1000 + n))
+			}
+		}(w)
+	}
+	time.Sleep(5 * time.Millisecond)
+	cancel()
+	wg.Wait()
+	<-p.Done()
+
+	// Каждое событие либо проиндексировано, либо учтено как отброшенное
+	m := p.Metrics()
+	if m.EventsEnqueued != m.EventsIndexed || m.EventsEnqueued+m.EventsDropped != 8*200 {
+		t.Errorf("events lost during shutdown: %+v", m)
+	}
+	if got := len(rec.events()); int64(got) != m.EventsIndexed {
+		t.Errorf("expected %d indexed events, got %d", m.EventsIndexed, got)
+	}
+}
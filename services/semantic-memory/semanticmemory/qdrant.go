@@ -0,0 +1,308 @@
+// Package semanticmemory handles Qdrant integration using native HTTP requests.
+package semanticmemory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Служебные поля payload точки Qdrant: исходный ID документа и его текст.
+// Остальные поля payload — метаданные документа.
+const (
+	qdrantIDField       = "_doc_id"
+	qdrantDocumentField = "_document"
+)
+
+// QdrantClient handles communication with Qdrant via HTTP API.
+// Implements the SemanticStorage interface.
+type QdrantClient struct {
+	baseURL        string
+	apiKey         string
+	httpClient     *http.Client
+	collectionName string
+	embedder       Embedder
+
+	mu              sync.Mutex
+	collectionReady bool // Коллекция создана (или уже существовала)
+}
+
+// Ensure QdrantClient implements SemanticStorage interface
+var _ SemanticStorage = (*QdrantClient)(nil)
+
+// NewQdrantClient creates a new QdrantClient using native HTTP.
+// Reads QDRANT_URL (default http://qdrant:6333), QDRANT_API_KEY and QDRANT_COLLECTION (default world_memory).
+func NewQdrantClient(embedder Embedder) *QdrantClient {
+	url := os.Getenv("QDRANT_URL")
+	if url == "" {
+		url = "http://qdrant:6333"
+	}
+	collection := os.Getenv("QDRANT_COLLECTION")
+	if collection == "" {
+		collection = "world_memory"
+	}
+	return &QdrantClient{
+		baseURL: url,
+		apiKey:  os.Getenv("QDRANT_API_KEY"),
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		collectionName: collection,
+		embedder:       embedder,
+	}
+}
+
+// qdrantPoint is a point as stored in and returned by Qdrant.
+type qdrantPoint struct {
+	ID      string                 `json:"id"`
+	Vector  []float32              `json:"vector,omitempty"`
+	Payload map[string]interface{} `json:"payload"`
+}
+
+// qdrantPointID maps an arbitrary document ID to a stable UUID, since Qdrant only accepts UUIDs or integers.
+func qdrantPointID(docID string) string {
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte(docID)).String()
+}
+
+// do executes a request against Qdrant and decodes the "result" field into out (if not nil).
+// Returns found=false when Qdrant answers 404 (e.g. the collection does not exist yet).
+func (c *QdrantClient) do(ctx context.Context, method, path string, body interface{}, out interface{}) (bool, error) {
+	var reader io.Reader
+	if body != nil {
+		jsonData, err := json.Marshal(body)
+		if err != nil {
+			return false, fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewBuffer(jsonData)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return false, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("api-key", c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return false, fmt.Errorf("request %s %s failed with status %d: %s", method, path, resp.StatusCode, string(respBody))
+	}
+
+	if out != nil {
+		envelope := struct {
+			Result interface{} `json:"result"`
+		}{Result: out}
+		if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+			return false, fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+	return true, nil
+}
+
+// ensureCollection создаёт коллекцию при первой записи, когда известна размерность векторов.
+func (c *QdrantClient) ensureCollection(ctx context.Context, vectorSize int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.collectionReady {
+		return nil
+	}
+
+	path := "/collections/" + c.collectionName
+	exists, err := c.do(ctx, "GET", path, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to check collection: %w", err)
+	}
+	if !exists {
+		payload := map[string]interface{}{
+			"vectors": map[string]interface{}{
+				"size":     vectorSize,
+				"distance": "Cosine",
+			},
+		}
+		if _, err := c.do(ctx, "PUT", path, payload, nil); err != nil {
+			return fmt.Errorf("failed to create collection: %w", err)
+		}
+	}
+
+	c.collectionReady = true
+	return nil
+}
+
+// UpsertDocument adds or updates a document in Qdrant.
+func (c *QdrantClient) UpsertDocument(ctx context.Context, entityID string, text string, metadata map[string]interface{}) error {
+	return c.UpsertDocuments(ctx, []Document{{ID: entityID, Text: text, Metadata: metadata}})
+}
+
+// UpsertDocuments embeds a batch of documents and upserts them with a single request.
+func (c *QdrantClient) UpsertDocuments(ctx context.Context, docs []Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	texts := make([]string, len(docs))
+	for i, doc := range docs {
+		texts[i] = doc.Text
+	}
+	vectors, err := c.embedder.Embed(ctx, texts)
+	if err != nil {
+		return fmt.Errorf("failed to embed documents: %w", err)
+	}
+
+	if err := c.ensureCollection(ctx, len(vectors[0])); err != nil {
+		return err
+	}
+
+	points := make([]qdrantPoint, len(docs))
+	for i, doc := range docs {
+		payload := make(map[string]interface{}, len(doc.Metadata)+2)
+		for k, v := range doc.Metadata {
+			payload[k] = v
+		}
+		payload[qdrantIDField] = doc.ID
+		payload[qdrantDocumentField] = doc.Text
+
+		points[i] = qdrantPoint{ID: qdrantPointID(doc.ID), Vector: vectors[i], Payload: payload}
+	}
+
+	path := fmt.Sprintf("/collections/%s/points?wait=true", c.collectionName)
+	if _, err := c.do(ctx, "PUT", path, map[string]interface{}{"points": points}, nil); err != nil {
+		return fmt.Errorf("upsert failed: %w", err)
+	}
+	return nil
+}
+
+// GetDocuments retrieves documents by their IDs.
+func (c *QdrantClient) GetDocuments(ctx context.Context, entityIDs []string) (map[string]string, error) {
+	ids := make([]string, len(entityIDs))
+	for i, id := range entityIDs {
+		ids[i] = qdrantPointID(id)
+	}
+
+	var points []qdrantPoint
+	path := fmt.Sprintf("/collections/%s/points", c.collectionName)
+	payload := map[string]interface{}{
+		"ids":          ids,
+		"with_payload": true,
+	}
+	if _, err := c.do(ctx, "POST", path, payload, &points); err != nil {
+		return nil, fmt.Errorf("get failed: %w", err)
+	}
+
+	documents := make(map[string]string, len(points))
+	for _, point := range points {
+		id, _ := point.Payload[qdrantIDField].(string)
+		text, _ := point.Payload[qdrantDocumentField].(string)
+		documents[id] = text
+	}
+	return documents, nil
+}
+
+// scroll returns points matching an equality filter on payload fields.
+func (c *QdrantClient) scroll(ctx context.Context, where map[string]interface{}, limit int) ([]qdrantPoint, error) {
+	must := make([]map[string]interface{}, 0, len(where))
+	for key, value := range where {
+		must = append(must, map[string]interface{}{
+			"key":   key,
+			"match": map[string]interface{}{"value": value},
+		})
+	}
+
+	payload := map[string]interface{}{
+		"limit":        limit,
+		"with_payload": true,
+	}
+	if len(must) > 0 {
+		payload["filter"] = map[string]interface{}{"must": must}
+	}
+
+	var result struct {
+		Points []qdrantPoint `json:"points"`
+	}
+	path := fmt.Sprintf("/collections/%s/points/scroll", c.collectionName)
+	if _, err := c.do(ctx, "POST", path, payload, &result); err != nil {
+		return nil, err
+	}
+	return result.Points, nil
+}
+
+// SearchEventsByType searches for events by type using a payload filter.
+func (c *QdrantClient) SearchEventsByType(ctx context.Context, eventType string, limit int) ([]string, error) {
+	points, err := c.scroll(ctx, map[string]interface{}{"event_type": eventType}, limit)
+	if err != nil {
+		return nil, fmt.Errorf("SearchEventsByType: %w", err)
+	}
+
+	documents := make([]string, 0, len(points))
+	for _, point := range points {
+		text, _ := point.Payload[qdrantDocumentField].(string)
+		documents = append(documents, text)
+	}
+	return documents, nil
+}
+
+// QueryByMetadata retrieves documents matching the given metadata filter.
+// Returns a slice of maps with "id", "document", and "metadata" keys.
+func (c *QdrantClient) QueryByMetadata(ctx context.Context, where map[string]interface{}, limit int) ([]map[string]interface{}, error) {
+	points, err := c.scroll(ctx, where, limit)
+	if err != nil {
+		return nil, fmt.Errorf("QueryByMetadata: %w", err)
+	}
+
+	out := make([]map[string]interface{}, 0, len(points))
+	for _, point := range points {
+		id, _ := point.Payload[qdrantIDField].(string)
+		text, _ := point.Payload[qdrantDocumentField].(string)
+
+		metadata := make(map[string]interface{}, len(point.Payload))
+		for k, v := range point.Payload {
+			if k != qdrantIDField && k != qdrantDocumentField {
+				metadata[k] = v
+			}
+		}
+
+		out = append(out, map[string]interface{}{
+			"id":       id,
+			"document": text,
+			"metadata": metadata,
+		})
+	}
+	return out, nil
+}
+
+// Ping calls the Qdrant health endpoint.
+func (c *QdrantClient) Ping(ctx context.Context) error {
+	found, err := c.do(ctx, http.MethodGet, "/healthz", nil, nil)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("qdrant health endpoint not found")
+	}
+	return nil
+}
+
+// Close closes the HTTP client (optional).
+func (c *QdrantClient) Close() error {
+	c.httpClient.CloseIdleConnections()
+	return nil
+}
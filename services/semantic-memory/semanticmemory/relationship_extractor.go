@@ -0,0 +1,225 @@
+// Package semanticmemory — rule-based entity relationship extraction from event payloads.
+package semanticmemory
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+
+	minio "github.com/minio/minio-go/v7"
+	"gopkg.in/yaml.v3"
+)
+
+// RelationshipRule сопоставляет ключ payload с ребром графа от действующей сущности события.
+//
+// Пример: {payload_key: "npc_id", rel_type: "TALKED_TO"} для события
+// {"entity_id": "player-1", "npc_id": "npc-7"} создаёт (player-1)-[:TALKED_TO]->(npc-7).
+type RelationshipRule struct {
+	// PayloadKey — dot-путь в payload. Значение может быть строкой-ID,
+	// ссылкой {entity: {id}} / {id} или списком таких значений.
+	PayloadKey string `yaml:"payload_key" json:"payload_key"`
+
+	// RelType — тип ребра (например "ATTACKED").
+	RelType string `yaml:"rel_type" json:"rel_type"`
+
+	// EventTypes — префиксы типов событий, к которым применяется правило (пусто — ко всем).
+	EventTypes []string `yaml:"event_types,omitempty" json:"event_types,omitempty"`
+
+	// Reverse меняет направление ребра: цель → действующая сущность.
+	Reverse bool `yaml:"reverse,omitempty" json:"reverse,omitempty"`
+}
+
+// relationshipRulesFile — формат файла правил в MinIO (YAML или JSON).
+type relationshipRulesFile struct {
+	Rules []RelationshipRule `yaml:"rules" json:"rules"`
+}
+
+// combatEventTypes — префиксы боевых событий: target в payload других событий
+// (разговор, торговля, заклинание на союзника) не означает атаку.
+var combatEventTypes = []string{"player.attacked", "player.action.attack", "npc.attack", "combat.result"}
+
+// DefaultRelationshipRules — правила, используемые когда файл правил не найден.
+var DefaultRelationshipRules = []RelationshipRule{
+	{PayloadKey: "target", RelType: eventbus.RelAttacked, EventTypes: combatEventTypes},
+	{PayloadKey: "target_id", RelType: eventbus.RelAttacked, EventTypes: combatEventTypes},
+	{PayloadKey: "npc_id", RelType: eventbus.RelTalkedTo},
+	{PayloadKey: "location_id", RelType: eventbus.RelLocatedIn},
+	{PayloadKey: "quest_id", RelType: "ASSIGNED"},
+	{PayloadKey: "inventory", RelType: eventbus.RelContains},
+}
+
+// RelationshipExtractor извлекает связи Entity→Entity из payload событий по набору правил.
+type RelationshipExtractor struct {
+	rules []RelationshipRule
+}
+
+// NewRelationshipExtractor создаёт экстрактор с заданными правилами.
+func NewRelationshipExtractor(rules []RelationshipRule) *RelationshipExtractor {
+	return &RelationshipExtractor{rules: rules}
+}
+
+// Rules возвращает активные правила.
+func (x *RelationshipExtractor) Rules() []RelationshipRule {
+	return x.rules
+}
+
+// ParseRelationshipRules разбирает файл правил (YAML или JSON) и проверяет обязательные поля.
+func ParseRelationshipRules(data []byte) ([]RelationshipRule, error) {
+	var file relationshipRulesFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid relationship rules: %w", err)
+	}
+	for idx, rule := range file.Rules {
+		if rule.PayloadKey == "" {
+			return nil, fmt.Errorf("rule[%d]: 'payload_key' must not be empty", idx)
+		}
+		if rule.RelType == "" {
+			return nil, fmt.Errorf("rule[%d]: 'rel_type' must not be empty", idx)
+		}
+	}
+	return file.Rules, nil
+}
+
+// LoadRelationshipRules загружает правила из MinIO.
+// Бакет и ключ задаются RELATION_RULES_BUCKET (default: gnue-configs) и
+// RELATION_RULES_KEY (default: semantic-memory/relationship_rules.yaml).
+// При отсутствии клиента или файла возвращаются DefaultRelationshipRules.
+func LoadRelationshipRules(ctx context.Context, client *minio.Client) []RelationshipRule {
+	if client == nil {
+		return DefaultRelationshipRules
+	}
+
+	bucket := os.Getenv("RELATION_RULES_BUCKET")
+	if bucket == "" {
+		bucket = "gnue-configs"
+	}
+	key := os.Getenv("RELATION_RULES_KEY")
+	if key == "" {
+		key = "semantic-memory/relationship_rules.yaml"
+	}
+
+	obj, err := client.GetObject(ctx, bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		logging.Warnf("Relationship rules %s/%s unavailable, using defaults: %v", bucket, key, err)
+		return DefaultRelationshipRules
+	}
+	defer obj.Close()
+
+	data, err := io.ReadAll(obj)
+	if err != nil {
+		logging.Warnf("Relationship rules %s/%s unavailable, using defaults: %v", bucket, key, err)
+		return DefaultRelationshipRules
+	}
+
+	rules, err := ParseRelationshipRules(data)
+	if err != nil {
+		logging.Infof("Relationship rules %s/%s rejected, using defaults: %v", bucket, key, err)
+		return DefaultRelationshipRules
+	}
+
+	logging.Infof("Loaded %d relationship rules from %s/%s", len(rules), bucket, key)
+	return rules
+}
+
+// Extract возвращает связи для события. Источник — действующая сущность события
+// (entity.entity.id → entity.id → entity_id/player_id/...). Самосвязи отбрасываются.
+func (x *RelationshipExtractor) Extract(ev eventbus.Event) []eventbus.Relation {
+	actor := eventbus.ExtractEntityID(ev.Payload)
+	if actor == nil {
+		return nil
+	}
+
+	var relations []eventbus.Relation
+	seen := make(map[string]bool)
+	for _, rule := range x.rules {
+		if !rule.matchesEventType(ev.Type) {
+			continue
+		}
+
+		value, ok := eventbus.GetNested(ev.Payload, rule.PayloadKey)
+		if !ok {
+			continue
+		}
+
+		for _, targetID := range referencedEntityIDs(value) {
+			if targetID == actor.ID {
+				continue
+			}
+
+			from, to := actor.ID, targetID
+			if rule.Reverse {
+				from, to = to, from
+			}
+
+			key := from + "|" + rule.RelType + "|" + to
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			relations = append(relations, eventbus.Relation{
+				From:     from,
+				To:       to,
+				Type:     rule.RelType,
+				Directed: true,
+				Metadata: map[string]any{
+					"event_id":   ev.ID,
+					"event_type": ev.Type,
+					"source":     "payload_rule",
+				},
+			})
+		}
+	}
+	return relations
+}
+
+// matchesEventType проверяет фильтр правила по префиксам типов событий.
+func (r RelationshipRule) matchesEventType(eventType string) bool {
+	if len(r.EventTypes) == 0 {
+		return true
+	}
+	for _, prefix := range r.EventTypes {
+		if strings.HasPrefix(eventType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// referencedEntityIDs извлекает ID сущностей из значения payload:
+// строка, {entity: {id}}, {id} или список таких значений.
+func referencedEntityIDs(value any) []string {
+	switch v := value.(type) {
+	case string:
+		if v != "" {
+			return []string{v}
+		}
+	case map[string]any:
+		if inner, ok := v["entity"].(map[string]any); ok {
+			if id, ok := inner["id"].(string); ok && id != "" {
+				return []string{id}
+			}
+		}
+		if id, ok := v["id"].(string); ok && id != "" {
+			return []string{id}
+		}
+	case []string:
+		var ids []string
+		for _, item := range v {
+			ids = append(ids, referencedEntityIDs(item)...)
+		}
+		return ids
+	case []any:
+		var ids []string
+		for _, item := range v {
+			ids = append(ids, referencedEntityIDs(item)...)
+		}
+		return ids
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package semanticmemory
+
+import (
+	"testing"
+
+	"multiverse-core.io/shared/eventbus"
+)
+
+func TestRelationshipExtractor_DefaultRules(t *testing.T) {
+	x := NewRelationshipExtractor(DefaultRelationshipRules)
+
+	ev := eventbus.NewEvent("player.attacked", "test", "world-1", map[string]any{
+		"entity_id":   "player-1",
+		"target":      map[string]any{"entity": map[string]any{"id": "npc-wolf", "type": "npc"}},
+		"location_id": "loc-forest",
+		"quest_id":    "quest-9",
+		"inventory":   []any{"item-1", "item-2"},
+	})
+
+	relations := x.Extract(ev)
+
+	want := map[string]string{
+		"npc-wolf":   eventbus.RelAttacked,
+		"loc-forest": eventbus.RelLocatedIn,
+		"quest-9":    "ASSIGNED",
+		"item-1":     eventbus.RelContains,
+		"item-2":     eventbus.RelContains,
+	}
+	if len(relations) != len(want) {
+		t.Fatalf("expected %d relations, got %d: %+v", len(want), len(relations), relations)
+	}
+	for _, rel := range relations {
+		if rel.From != "player-1" {
+			t.Errorf("expected From=player-1, got %s", rel.From)
+		}
+		if want[rel.To] != rel.Type {
+			t.Errorf("relation to %s: expected %s, got %s", rel.To, want[rel.To], rel.Type)
+		}
+		if rel.Metadata["event_id"] != ev.ID {
+			t.Errorf("expected event_id metadata %s, got %v", ev.ID, rel.Metadata["event_id"])
+		}
+	}
+}
+
+func TestRelationshipExtractor_AttackedOnlyForCombat(t *testing.T) {
+	x := NewRelationshipExtractor(DefaultRelationshipRules)
+
+	ev := eventbus.NewEvent("player.traded", "test", "world-1", map[string]any{
+		"entity_id": "player-1",
+		"target_id": "npc-merchant",
+	})
+	if relations := x.Extract(ev); len(relations) != 0 {
+		t.Errorf("expected no ATTACKED relation outside combat, got %+v", relations)
+	}
+
+	for _, eventType := range []string{"combat.result", "npc.attacked_player"} {
+		ev = eventbus.NewEvent(eventType, "test", "world-1", map[string]any{
+			"entity_id": "npc-wolf",
+			"target_id": "player-1",
+		})
+		relations := x.Extract(ev)
+		if len(relations) != 1 || relations[0].Type != eventbus.RelAttacked || relations[0].To != "player-1" {
+			t.Errorf("%s: expected ATTACKED player-1, got %+v", eventType, relations)
+		}
+	}
+}
+
+func TestRelationshipExtractor_SkipsSelfAndMissingActor(t *testing.T) {
+	x := NewRelationshipExtractor(DefaultRelationshipRules)
+
+	// npc_id служит и действующей сущностью (fallback), и целью — самосвязь не создаётся
+	ev := eventbus.NewEvent("npc.said", "test", "world-1", map[string]any{
+		"npc_id": "npc-7",
+	})
+	if relations := x.Extract(ev); len(relations) != 0 {
+		t.Errorf("expected no relations, got %+v", relations)
+	}
+
+	ev = eventbus.NewEvent("weather.changed", "test", "world-1", map[string]any{
+		"location_id": "loc-1",
+	})
+	if relations := x.Extract(ev); len(relations) != 0 {
+		t.Errorf("expected no relations without actor, got %+v", relations)
+	}
+}
+
+func TestRelationshipExtractor_EventTypeFilterAndReverse(t *testing.T) {
+	x := NewRelationshipExtractor([]RelationshipRule{
+		{PayloadKey: "giver_id", RelType: "GAVE_QUEST", EventTypes: []string{"quest."}, Reverse: true},
+	})
+
+	ev := eventbus.NewEvent("quest.accepted", "test", "world-1", map[string]any{
+		"player_id": "player-1",
+		"giver_id":  "npc-elder",
+	})
+	relations := x.Extract(ev)
+	if len(relations) != 1 {
+		t.Fatalf("expected 1 relation, got %d", len(relations))
+	}
+	if relations[0].From != "npc-elder" || relations[0].To != "player-1" {
+		t.Errorf("expected reversed edge npc-elder→player-1, got %s→%s", relations[0].From, relations[0].To)
+	}
+
+	ev.Type = "player.moved"
+	if relations := x.Extract(ev); len(relations) != 0 {
+		t.Errorf("expected rule to be filtered by event type, got %+v", relations)
+	}
+}
+
+func TestParseRelationshipRules(t *testing.T) {
+	rules, err := ParseRelationshipRules([]byte(`
+rules:
+  - payload_key: "npc_id"
+    rel_type: "TALKED_TO"
+  - payload_key: "guild.id"
+    rel_type: "MEMBER_OF"
+    event_types: ["guild."]
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rules) != 2 || rules[1].PayloadKey != "guild.id" || rules[1].EventTypes[0] != "guild." {
+		t.Errorf("unexpected rules: %+v", rules)
+	}
+
+	// JSON тоже допустим
+	if _, err := ParseRelationshipRules([]byte(`{"rules":[{"payload_key":"target","rel_type":"ATTACKED"}]}`)); err != nil {
+		t.Errorf("expected JSON rules to parse, got %v", err)
+	}
+
+	if _, err := ParseRelationshipRules([]byte(`rules: [{payload_key: "target"}]`)); err == nil {
+		t.Error("expected error for rule without rel_type")
+	}
+}
@@ -4,21 +4,32 @@ package semanticmemory
 import (
 	"context"
 	"encoding/json"
-	"log"
 	"net/http"
 	"os"
+	"sync/atomic"
 	"time"
 
 	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+	"multiverse-core.io/shared/oracle"
+	"multiverse-core.io/shared/registry"
+	"multiverse-core.io/shared/tracing"
 
 	"github.com/gorilla/mux"
 )
 
 // Service manages the SemanticMemory lifecycle.
 type Service struct {
-	bus     *eventbus.EventBus
-	indexer *Indexer
-	server  *http.Server
+	bus       *eventbus.EventBus
+	indexer   *Indexer
+	pipeline  *IndexPipeline
+	server    *http.Server
+	announcer *registry.Announcer
+	oracle    *oracle.Client
+	summaries timelineSummaryCache
+	// running — indexing pipeline and subscriptions are started (gates /ready)
+	running atomic.Bool
 }
 
 // contextRequest represents a context request.
@@ -38,6 +49,7 @@ type contextWithEventsRequest struct {
 	EntityIDs  []string `json:"entity_ids"`
 	EventTypes []string `json:"event_types"`
 	Depth      int      `json:"depth,omitempty"`
+	Limit      int      `json:"limit,omitempty"` // events per entity
 }
 
 // NewService creates a new SemanticMemory service.
@@ -46,6 +58,7 @@ func NewService(bus *eventbus.EventBus) (*Service, error) {
 	if err != nil {
 		return nil, err
 	}
+	pipeline := NewIndexPipeline(indexer, PipelineConfigFromEnv())
 
 	// Setup HTTP server
 	r := mux.NewRouter()
@@ -94,7 +107,7 @@ func NewService(bus *eventbus.EventBus) (*Service, error) {
 		json.NewEncoder(w).Encode(response)
 	}).Methods("POST")
 
-	// Endpoint for context with events (legacy)
+	// Endpoint for entity contexts with their relevant events
 	r.HandleFunc("/v1/context-with-events", func(w http.ResponseWriter, r *http.Request) {
 		var req contextWithEventsRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -102,9 +115,18 @@ func NewService(bus *eventbus.EventBus) (*Service, error) {
 			return
 		}
 
-		log.Println(req)
+		if len(req.EntityIDs) == 0 {
+			http.Error(w, "entity_ids required", http.StatusBadRequest)
+			return
+		}
+
 		// Retrieve context with events from storage
-		contexts, err := indexer.GetContextWithEvents(r.Context(), req.EntityIDs, req.EventTypes, req.Depth)
+		contexts, err := indexer.GetContextWithEvents(r.Context(), ContextWithEventsQuery{
+			EntityIDs:  req.EntityIDs,
+			EventTypes: req.EventTypes,
+			Depth:      req.Depth,
+			Limit:      req.Limit,
+		})
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -134,14 +156,14 @@ func NewService(bus *eventbus.EventBus) (*Service, error) {
 		// Load entity cache from Neo4j
 		entityCache, err := indexer.neo4j.GetEntityCache(req.EntityIDs)
 		if err != nil {
-			log.Printf("Failed to load entity cache: %v", err)
+			logging.Errorf("Failed to load entity cache: %v", err)
 			entityCache = buildFallbackEntityCache(req.EntityIDs)
 		}
 
 		// Get events for entities
 		events, err := indexer.GetEventsForEntities(ctx, req.EntityIDs, req.WorldID, parseTimeRange(req.TimeRange), req.MaxEvents)
 		if err != nil {
-			log.Printf("Failed to load events: %v", err)
+			logging.Errorf("Failed to load events: %v", err)
 			writeError(w, "failed_to_load_events", http.StatusInternalServerError)
 			return
 		}
@@ -159,7 +181,7 @@ func NewService(bus *eventbus.EventBus) (*Service, error) {
 		// Return response
 		w.Header().Set("Content-Type", "application/json; charset=utf-8")
 		if err := json.NewEncoder(w).Encode(structured); err != nil {
-			log.Printf("Failed to encode structured context response: %v", err)
+			logging.Errorf("Failed to encode structured context response: %v", err)
 			http.Error(w, "internal_error", http.StatusInternalServerError)
 		}
 	}).Methods("POST")
@@ -183,7 +205,14 @@ func NewService(bus *eventbus.EventBus) (*Service, error) {
 		// Load entity context from storage (using existing logic)
 		context, err := indexer.GetEntityContext(r.Context(), entityID, timeRange)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			switch {
+			case storage.IsNotFound(err):
+				http.Error(w, err.Error(), http.StatusNotFound)
+			case storage.IsUnavailable(err):
+				http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			default:
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
 			return
 		}
 
@@ -208,7 +237,7 @@ func NewService(bus *eventbus.EventBus) (*Service, error) {
 
 		entity, err := indexer.GetEntityByID(r.Context(), entityID)
 		if err != nil {
-			log.Printf("GetEntityByID(%s): %v", entityID, err)
+			logging.Infof("GetEntityByID(%s): %v", entityID, err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -232,7 +261,7 @@ func NewService(bus *eventbus.EventBus) (*Service, error) {
 
 		entities, err := indexer.QueryEntities(r.Context(), q)
 		if err != nil {
-			log.Printf("entities/query: %v", err)
+			logging.Infof("entities/query: %v", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -252,7 +281,7 @@ func NewService(bus *eventbus.EventBus) (*Service, error) {
 
 		ev, err := indexer.neo4j.GetEventByID(eventID)
 		if err != nil {
-			log.Printf("GetEventByID(%s): %v", eventID, err)
+			logging.Infof("GetEventByID(%s): %v", eventID, err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -289,7 +318,7 @@ func NewService(bus *eventbus.EventBus) (*Service, error) {
 		if len(req.EntityIDs) > 0 {
 			events, err := indexer.GetEventsForEntities(ctx, req.EntityIDs, req.WorldID, parseTimeRange(req.TimeRange), req.Limit)
 			if err != nil {
-				log.Printf("events/query GetEventsForEntities: %v", err)
+				logging.Infof("events/query GetEventsForEntities: %v", err)
 				http.Error(w, err.Error(), http.StatusInternalServerError)
 				return
 			}
@@ -305,7 +334,7 @@ func NewService(bus *eventbus.EventBus) (*Service, error) {
 		if req.WorldID != "" && len(req.EventTypes) == 0 {
 			events, err := indexer.neo4j.GetEventsByWorldID(req.WorldID, req.Limit)
 			if err != nil {
-				log.Printf("events/query GetEventsByWorldID: %v", err)
+				logging.Infof("events/query GetEventsByWorldID: %v", err)
 				http.Error(w, err.Error(), http.StatusInternalServerError)
 				return
 			}
@@ -324,7 +353,7 @@ func NewService(bus *eventbus.EventBus) (*Service, error) {
 				events, err = indexer.neo4j.GetEventsByTypeNeo4j(req.EventTypes[0], req.Limit)
 			}
 			if err != nil {
-				log.Printf("events/query GetEventsByType (Neo4j): %v", err)
+				logging.Infof("events/query GetEventsByType (Neo4j): %v", err)
 				http.Error(w, err.Error(), http.StatusInternalServerError)
 				return
 			}
@@ -337,7 +366,7 @@ func NewService(bus *eventbus.EventBus) (*Service, error) {
 		if len(req.EventTypes) > 1 || req.WorldID != "" {
 			events, err := indexer.neo4j.GetEventsByTypes(req.EventTypes, req.WorldID, req.Limit)
 			if err != nil {
-				log.Printf("events/query GetEventsByTypes (Neo4j): %v", err)
+				logging.Infof("events/query GetEventsByTypes (Neo4j): %v", err)
 				http.Error(w, err.Error(), http.StatusInternalServerError)
 				return
 			}
@@ -357,19 +386,22 @@ func NewService(bus *eventbus.EventBus) (*Service, error) {
 			"relations_fallback_count":        metrics.FallbackCount,
 			"relations_entities_auto_created": metrics.EntityCreated,
 			"relations_validation_errors":     metrics.ValidationErrs,
+			"relations_extracted_count":       metrics.ExtractedCount,
 		}
 		w.Header().Set("Content-Type", "application/json; charset=utf-8")
 		json.NewEncoder(w).Encode(response)
 	}).Methods("GET")
 
-	// Health check endpoint
-	r.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		response := map[string]string{
-			"status": "healthy",
-			"time":   time.Now().Format(time.RFC3339),
-		}
+	// GET /v1/indexing/metrics — queue depth and batch latency of the indexing pipeline.
+	r.HandleFunc("/v1/indexing/metrics", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json; charset=utf-8")
-		json.NewEncoder(w).Encode(response)
+		json.NewEncoder(w).Encode(pipeline.Metrics())
+	}).Methods("GET")
+
+	// GET /v1/dedup/metrics — events skipped as repeats by the indexer.
+	r.HandleFunc("/v1/dedup/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		json.NewEncoder(w).Encode(indexer.GetDedupMetrics())
 	}).Methods("GET")
 
 	semanticport := os.Getenv("SEMANTIC_PORT")
@@ -379,16 +411,31 @@ func NewService(bus *eventbus.EventBus) (*Service, error) {
 
 	server := &http.Server{
 		Addr:         ":" + semanticport,
-		Handler:      r,
+		Handler:      tracing.NewHandler("semantic-memory", r),
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 10 * time.Second,
 	}
 
-	return &Service{
-		bus:     bus,
-		indexer: indexer,
-		server:  server,
-	}, nil
+	announcer := registry.NewAnnouncer(bus, registry.ServiceSemanticMemory, "http://semantic-memory:"+semanticport,
+		"context", "context-with-events", "structured-context", "events", "entities", "entity-context", "timeline")
+
+	service := &Service{
+		bus:       bus,
+		indexer:   indexer,
+		pipeline:  pipeline,
+		server:    server,
+		announcer: announcer,
+		oracle:    oracle.NewClient(),
+	}
+
+	// GET /v1/timeline — human-readable entity history for the player UI.
+	r.HandleFunc("/v1/timeline", service.HandleTimeline).Methods("GET")
+
+	// Health checks: /health — liveness with dependency statuses, /ready — readiness gate
+	r.HandleFunc("/health", service.handleHealth).Methods("GET")
+	r.HandleFunc("/ready", service.handleReady).Methods("GET")
+
+	return service, nil
 }
 
 // Run starts the service and blocks until context is cancelled.
@@ -396,10 +443,16 @@ func (s *Service) Run(ctx context.Context) error {
 	// Start HTTP server
 	go func() {
 		if err := s.server.ListenAndServe(); err != http.ErrServerClosed {
-			log.Printf("HTTP server failed: %v", err)
+			logging.Errorf("HTTP server failed: %v", err)
 		}
 	}()
 
+	// Start batched indexing before subscriptions so HandleEvent enqueues
+	s.pipeline.Start(ctx)
+
+	// Publish endpoint to the service registry
+	go s.announcer.Run(ctx)
+
 	// Subscribe to all event topics for comprehensive context
 	go s.bus.Subscribe(ctx, eventbus.TopicPlayerEvents, "semantic-memory-player-group", s.indexer.HandleEvent)
 	go s.bus.Subscribe(ctx, eventbus.TopicWorldEvents, "semantic-memory-world-group", s.indexer.HandleEvent)
@@ -407,14 +460,19 @@ func (s *Service) Run(ctx context.Context) error {
 	go s.bus.Subscribe(ctx, eventbus.TopicSystemEvents, "semantic-memory-system-group", s.indexer.HandleEvent)
 	go s.bus.Subscribe(ctx, eventbus.TopicScopeManagement, "semantic-memory-scope-group", s.indexer.HandleEvent)
 	go s.bus.Subscribe(ctx, eventbus.TopicNarrativeOutput, "semantic-memory-narrative-group", s.indexer.HandleEvent)
+	s.running.Store(true)
 
 	<-ctx.Done()
+	s.running.Store(false)
 
 	// Shutdown HTTP server
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer shutdownCancel()
 	s.server.Shutdown(shutdownCtx)
 
+	// Wait for the pipeline to flush the remaining batch
+	<-s.pipeline.Done()
+
 	// Close chroma client
 	if s.indexer.chroma != nil {
 		s.indexer.chroma.Close()
@@ -1,15 +1,32 @@
 // Package semanticmemory defines interfaces for semantic storage implementations.
 package semanticmemory
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"multiverse-core.io/shared/logging"
+)
+
+// Бэкенды векторного хранилища, выбираемые через SEMANTIC_VECTOR_BACKEND
+const (
+	VectorBackendChroma   = "chroma"
+	VectorBackendQdrant   = "qdrant"
+	VectorBackendPgVector = "pgvector"
+)
 
 // SemanticStorage defines the interface for semantic storage implementations.
-// This allows switching between different storage backends (e.g., ChromaDB HTTP API, ChromaDB Go client)
+// This allows switching between different storage backends (ChromaDB, Qdrant, Postgres/pgvector)
 // while maintaining the same interface for the rest of the application.
 type SemanticStorage interface {
 	// UpsertDocument adds or updates a document in the semantic storage.
 	UpsertDocument(ctx context.Context, entityID string, text string, metadata map[string]interface{}) error
 
+	// UpsertDocuments adds or updates a batch of documents in a single request.
+	UpsertDocuments(ctx context.Context, docs []Document) error
+
 	// GetDocuments retrieves documents by their IDs from the semantic storage.
 	GetDocuments(ctx context.Context, entityIDs []string) (map[string]string, error)
 
@@ -22,6 +39,58 @@ type SemanticStorage interface {
 	// Returns a slice of maps, each containing "id", "document", and "metadata" keys.
 	QueryByMetadata(ctx context.Context, where map[string]interface{}, limit int) ([]map[string]interface{}, error)
 
+	// Ping checks that the storage is reachable (used by /health and /ready).
+	Ping(ctx context.Context) error
+
 	// Close closes the connection to the semantic storage.
 	Close() error
 }
+
+// Document is a single text document with metadata stored in the semantic storage.
+type Document struct {
+	ID       string
+	Text     string
+	Metadata map[string]interface{}
+}
+
+// NewSemanticStorage creates the storage backend selected by SEMANTIC_VECTOR_BACKEND
+// (chroma by default, qdrant or pgvector).
+func NewSemanticStorage(ctx context.Context) (SemanticStorage, error) {
+	backend := VectorBackend()
+	logging.Infof("Using semantic vector backend: %s", backend)
+
+	switch backend {
+	case VectorBackendChroma:
+		return newChromaStorage(), nil
+	case VectorBackendQdrant:
+		return NewQdrantClient(NewOllamaEmbedder()), nil
+	case VectorBackendPgVector:
+		return NewPgVectorClient(ctx, NewOllamaEmbedder())
+	default:
+		return nil, fmt.Errorf("unknown SEMANTIC_VECTOR_BACKEND %q (expected chroma, qdrant or pgvector)", backend)
+	}
+}
+
+// VectorBackend returns the backend selected by SEMANTIC_VECTOR_BACKEND (chroma by default).
+func VectorBackend() string {
+	if backend := strings.ToLower(os.Getenv("SEMANTIC_VECTOR_BACKEND")); backend != "" {
+		return backend
+	}
+	return VectorBackendChroma
+}
+
+// newChromaStorage selects the ChromaDB client: v2 when CHROMA_USE_V2=true and the build supports it.
+func newChromaStorage() SemanticStorage {
+	useChromaV2 := os.Getenv("CHROMA_USE_V2") == "true"
+	logging.Infof("Using ChromaDB v2: %t", useChromaV2)
+	if useChromaV2 {
+		// Only try to create ChromaV2Client if the build tag is enabled
+		storage, err := createChromaV2Client()
+		if err != nil {
+			logging.Warnf("Failed to create ChromaDB v2 client: %v. Falling back to ChromaDB v1 client.", err)
+			return NewChromaClient() // ← Возвращаемся к старому клиенту в случае ошибки
+		}
+		return storage
+	}
+	return NewChromaClient() // ← Использует старый клиент по умолчанию
+}
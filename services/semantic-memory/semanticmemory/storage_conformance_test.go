@@ -0,0 +1,193 @@
+// Package semanticmemory — общий набор тестов соответствия для реализаций SemanticStorage.
+package semanticmemory
+
+import (
+	"context"
+	"hash/fnv"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// hashEmbedder — детерминированный эмбеддер для тестов, не требующий Ollama
+type hashEmbedder struct{}
+
+func (hashEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vector := make([]float32, 8)
+		for j := range vector {
+			h := fnv.New32a()
+			h.Write([]byte{byte(j)})
+			h.Write([]byte(text))
+			vector[j] = float32(h.Sum32()%1000)/1000 + 0.001
+		}
+		vectors[i] = vector
+	}
+	return vectors, nil
+}
+
+// TestStorageConformance прогоняет одинаковые сценарии на всех бэкендах.
+// Бэкенд тестируется, только если задан адрес для тестов: CONFORMANCE_CHROMA_URL, CONFORMANCE_QDRANT_URL,
+// CONFORMANCE_PGVECTOR_DSN. Рабочие CHROMA_URL и другие не используются: их выставляют другие тесты процесса.
+func TestStorageConformance(t *testing.T) {
+	backends := []struct {
+		name   string
+		env    string
+		create func(t *testing.T) SemanticStorage
+	}{
+		{"chroma", "CHROMA_URL", func(t *testing.T) SemanticStorage {
+			return NewChromaClient()
+		}},
+		{"qdrant", "QDRANT_URL", func(t *testing.T) SemanticStorage {
+			t.Setenv("QDRANT_COLLECTION", "conformance_"+uuid.NewString()[:8])
+			return NewQdrantClient(hashEmbedder{})
+		}},
+		{"pgvector", "PGVECTOR_DSN", func(t *testing.T) SemanticStorage {
+			t.Setenv("PGVECTOR_TABLE", "conformance_"+uuid.NewString()[:8])
+			storage, err := NewPgVectorClient(context.Background(), hashEmbedder{})
+			if err != nil {
+				t.Fatalf("failed to create pgvector client: %v", err)
+			}
+			return storage
+		}},
+	}
+
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			address := os.Getenv("CONFORMANCE_" + backend.env)
+			if address == "" {
+				t.Skipf("CONFORMANCE_%s is not set", backend.env)
+			}
+			t.Setenv(backend.env, address)
+			storage := backend.create(t)
+			defer storage.Close()
+
+			runStorageConformance(t, storage)
+		})
+	}
+}
+
+// runStorageConformance проверяет контракт SemanticStorage на конкретной реализации
+func runStorageConformance(t *testing.T, storage SemanticStorage) {
+	ctx := context.Background()
+	run := uuid.NewString()[:8]
+	worldID := "world-" + run
+	eventType := "conformance.test." + run
+
+	t.Run("UpsertAndGet", func(t *testing.T) {
+		id := "entity-" + run
+		if err := storage.UpsertDocument(ctx, id, "первая версия", map[string]interface{}{"world_id": worldID}); err != nil {
+			t.Fatalf("UpsertDocument failed: %v", err)
+		}
+		if err := storage.UpsertDocument(ctx, id, "вторая версия", map[string]interface{}{"world_id": worldID}); err != nil {
+			t.Fatalf("UpsertDocument (update) failed: %v", err)
+		}
+
+		docs, err := storage.GetDocuments(ctx, []string{id, "missing-" + run})
+		if err != nil {
+			t.Fatalf("GetDocuments failed: %v", err)
+		}
+		if docs[id] != "вторая версия" {
+			t.Errorf("expected updated document, got %q", docs[id])
+		}
+		if _, exists := docs["missing-"+run]; exists {
+			t.Error("missing document must not be returned")
+		}
+	})
+
+	t.Run("BatchUpsert", func(t *testing.T) {
+		if err := storage.UpsertDocuments(ctx, nil); err != nil {
+			t.Fatalf("empty batch must be a no-op, got %v", err)
+		}
+
+		batch := []Document{
+			{ID: "event-a-" + run, Text: "Игрок вошёл в город", Metadata: map[string]interface{}{"event_type": eventType, "world_id": worldID}},
+			{ID: "event-b-" + run, Text: "Игрок покинул город", Metadata: map[string]interface{}{"event_type": eventType, "world_id": worldID}},
+		}
+		if err := storage.UpsertDocuments(ctx, batch); err != nil {
+			t.Fatalf("UpsertDocuments failed: %v", err)
+		}
+
+		docs, err := storage.GetDocuments(ctx, []string{batch[0].ID, batch[1].ID})
+		if err != nil {
+			t.Fatalf("GetDocuments failed: %v", err)
+		}
+		for _, doc := range batch {
+			if docs[doc.ID] != doc.Text {
+				t.Errorf("document %s: expected %q, got %q", doc.ID, doc.Text, docs[doc.ID])
+			}
+		}
+	})
+
+	t.Run("SearchEventsByType", func(t *testing.T) {
+		docs, err := storage.SearchEventsByType(ctx, eventType, 10)
+		if err != nil {
+			t.Fatalf("SearchEventsByType failed: %v", err)
+		}
+		if len(docs) != 2 {
+			t.Errorf("expected 2 events of type %s, got %d", eventType, len(docs))
+		}
+
+		docs, err = storage.SearchEventsByType(ctx, eventType, 1)
+		if err != nil {
+			t.Fatalf("SearchEventsByType with limit failed: %v", err)
+		}
+		if len(docs) != 1 {
+			t.Errorf("expected limit to be applied, got %d", len(docs))
+		}
+	})
+
+	t.Run("QueryByMetadata", func(t *testing.T) {
+		results, err := storage.QueryByMetadata(ctx, map[string]interface{}{"event_type": eventType}, 10)
+		if err != nil {
+			t.Fatalf("QueryByMetadata failed: %v", err)
+		}
+		if len(results) != 2 {
+			t.Fatalf("expected 2 results, got %d", len(results))
+		}
+		for _, result := range results {
+			if _, ok := result["id"].(string); !ok {
+				t.Errorf("result must contain string id: %v", result)
+			}
+			if _, ok := result["document"].(string); !ok {
+				t.Errorf("result must contain string document: %v", result)
+			}
+			metadata, ok := result["metadata"].(map[string]interface{})
+			if !ok || metadata["world_id"] != worldID {
+				t.Errorf("result must contain metadata with world_id, got %v", result["metadata"])
+			}
+		}
+
+		results, err = storage.QueryByMetadata(ctx, map[string]interface{}{"event_type": "unknown." + run}, 10)
+		if err != nil {
+			t.Fatalf("QueryByMetadata failed: %v", err)
+		}
+		if len(results) != 0 {
+			t.Errorf("expected no results for unknown type, got %d", len(results))
+		}
+	})
+}
+
+func TestNewSemanticStorageUnknownBackend(t *testing.T) {
+	t.Setenv("SEMANTIC_VECTOR_BACKEND", "faiss")
+	if _, err := NewSemanticStorage(context.Background()); err == nil {
+		t.Error("expected error for unknown backend")
+	}
+}
+
+func TestVectorLiteral(t *testing.T) {
+	if got := vectorLiteral([]float32{0.5, -1, 0.25}); got != "[0.5,-1,0.25]" {
+		t.Errorf("unexpected literal: %s", got)
+	}
+}
+
+func TestQdrantPointIDStable(t *testing.T) {
+	if qdrantPointID("player-1") != qdrantPointID("player-1") {
+		t.Error("point ID must be deterministic")
+	}
+	if qdrantPointID("player-1") == qdrantPointID("player-2") {
+		t.Error("different documents must map to different point IDs")
+	}
+}
@@ -2,46 +2,46 @@
 package main
 
 import (
-	"context"
 	"log"
-	"os"
-	"os/signal"
-	"strings"
-	"syscall"
 
-	"multiverse-core.io/shared/eventbus"
 	"multiverse-core.io/services/universe-genesis-oracle/universegenesis"
+	"multiverse-core.io/shared/config"
+	"multiverse-core.io/shared/registry"
+	"multiverse-core.io/shared/service"
 )
 
 func main() {
-	// Инициализация EventBus
-	brokers := strings.Split(os.Getenv("KAFKA_BROKERS"), ",")
-	if len(brokers) == 0 || brokers[0] == "" {
-		brokers = []string{"redpanda:9092"}
+	app := service.Setup("universe-genesis-oracle", config.OracleOptions, []config.Option{
+		{Env: "ARCHIVIST_URL", Type: config.TypeURL, Usage: "резервный адрес архивариуса"},
+		{Env: "UNIVERSE_GENESIS_PORT", Default: "8086", Type: config.TypeInt, Positive: true},
+		{Env: "GENESIS_BATCH_WORKERS", Default: "4", Type: config.TypeInt, Positive: true, Usage: "генезисы пакета, выполняемые одновременно"},
+		{Env: "ORACLE_MAX_PARALLEL", Default: "2", Type: config.TypeInt, Positive: true, Usage: "одновременные вызовы Oracle"},
+		{Env: "GENESIS_SCHEMA_ATTEMPTS", Default: "3", Type: config.TypeInt, Positive: true, Usage: "попытки генерации схемы сущности, прошедшей проверку"},
+		{Env: "GENESIS_DETERMINISTIC", Default: "false", Type: config.TypeBool, Usage: "воспроизводимый генезис с записью ответов Oracle"},
+	})
+	env := app.Env
+	app.UseOracleAudit()
+
+	// Инициализация клиента для OntologicalArchivist (адрес — через реестр сервисов, ARCHIVIST_URL — резерв)
+	archivistClient := universegenesis.NewArchivistClient(env.String("ARCHIVIST_URL"))
+	discovery := registry.NewDiscovery(app.Bus(), "universe-genesis-oracle")
+	archivistClient.UseDiscovery(discovery)
+	app.Go(discovery.Run)
+
+	// Контрольные точки генезиса в MinIO: прерванный генезис продолжается после перезапуска
+	minioClient, err := app.MinIO()
+	if err != nil {
+		log.Fatalf("Failed to create MinIO client: %v", err)
 	}
-	bus := eventbus.NewEventBus(brokers)
 
-	// Инициализация клиента для OntologicalArchivist
-	archivistClient := universegenesis.NewArchivistClient(os.Getenv("ARCHIVIST_URL"))
+	genesis := universegenesis.NewService(app.Bus(), archivistClient, universegenesis.NewMinioCheckpointStore(minioClient), universegenesis.Config{
+		HTTPPort:          env.String("UNIVERSE_GENESIS_PORT"),
+		BatchWorkers:      env.Int("GENESIS_BATCH_WORKERS"),
+		OracleParallelism: env.Int("ORACLE_MAX_PARALLEL"),
+		SchemaAttempts:    env.Int("GENESIS_SCHEMA_ATTEMPTS"),
+		Deterministic:     env.Bool("GENESIS_DETERMINISTIC"),
+		Recordings:        universegenesis.NewMinioOracleRecorder(minioClient),
+	})
 
-	// Инициализация сервиса
-	service := universegenesis.NewService(bus, archivistClient)
-
-	// Обработка сигналов для graceful shutdown
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-
-	go func() {
-		<-sigChan
-		log.Println("Shutting down UniverseGenesisOracle...")
-		cancel()
-	}()
-
-	log.Println("UniverseGenesisOracle starting...")
-	if err := service.Run(ctx); err != nil && err != context.Canceled {
-		log.Fatal("Service failed:", err)
-	}
-	log.Println("UniverseGenesisOracle completed its task and stopped.")
+	app.Run(genesis)
 }
@@ -7,14 +7,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"time"
+
+	"multiverse-core.io/shared/logging"
+	"multiverse-core.io/shared/registry"
 )
 
 type ArchivistClient struct {
-	BaseURL string
-	Client  *http.Client
+	BaseURL   string // резервный адрес, если в реестре нет живого архивариуса
+	Client    *http.Client
+	discovery *registry.Discovery
 }
 
 func NewArchivistClient(baseURL string) *ArchivistClient {
@@ -24,43 +27,74 @@ func NewArchivistClient(baseURL string) *ArchivistClient {
 	}
 }
 
-// SaveSchema теперь использует POST, как и WorldGenerator.
-func (ac *ArchivistClient) SaveSchema(ctx context.Context, schemaType, name, version string, schemaData []byte) error {
-	// Подготовим тело запроса в формате, ожидаемом OntologicalArchivist
+// UseDiscovery включает поиск архивариуса через реестр сервисов; BaseURL остаётся резервным адресом.
+func (ac *ArchivistClient) UseDiscovery(discovery *registry.Discovery) {
+	if ac.BaseURL != "" {
+		discovery.SetFallback(registry.ServiceArchivist, ac.BaseURL)
+	}
+	ac.discovery = discovery
+}
+
+// baseURL возвращает адрес живого экземпляра архивариуса.
+func (ac *ArchivistClient) baseURL(ctx context.Context) string {
+	if ac.discovery == nil {
+		return ac.BaseURL
+	}
+	url, err := ac.discovery.Resolve(ctx, registry.ServiceArchivist)
+	if err != nil {
+		return ac.BaseURL
+	}
+	return url
+}
+
+// PublishSchema публикует схему новой версией: архивариус сам повышает версию,
+// а неизменённую схему не сохраняет повторно. Возвращает версию, под которой схема доступна.
+func (ac *ArchivistClient) PublishSchema(ctx context.Context, schemaType, name string, schemaData []byte) (string, error) {
 	requestBody, err := json.Marshal(map[string]interface{}{
-		"schema_type": schemaType,
-		"name":        name,
-		"version":     version,
-		"schema":      json.RawMessage(schemaData), // Вложенный JSON как RawMessage
+		"schema": json.RawMessage(schemaData), // Вложенный JSON как RawMessage
 	})
 	if err != nil {
-		return fmt.Errorf("failed to marshal request body: %w", err)
+		return "", fmt.Errorf("failed to marshal request body: %w", err)
 	}
 
-	// Используем POST к /v1/schemas, как в примерах для OntologicalArchivist
-	url := fmt.Sprintf("%s/v1/schemas", ac.BaseURL)
+	baseURL := ac.baseURL(ctx)
+	url := fmt.Sprintf("%s/v1/schemas/%s/%s/versions", baseURL, schemaType, name)
 
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(requestBody))
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := ac.Client.Do(req)
 	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
+		if ac.discovery != nil {
+			ac.discovery.MarkFailed(registry.ServiceArchivist, baseURL)
+		}
+		return "", fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
-	// Проверяем, что статус 2xx
+	// Проверяем, что статус 2xx: 201 — новая версия, 200 — схема не изменилась
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("archivist returned non-2xx status %d: %s", resp.StatusCode, string(body))
+		return "", fmt.Errorf("archivist returned non-2xx status %d: %s", resp.StatusCode, string(body))
 	}
 
-	// Логируем успешное сохранение с кодом статуса
-	log.Printf("[Archivist] Successfully saved schema %s/%s v%s (status: %d)",
-		schemaType, name, version, resp.StatusCode)
-	return nil
+	var result struct {
+		Schema struct {
+			Version string `json:"version"`
+		} `json:"schema"`
+		Created bool `json:"created"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode archivist response: %w", err)
+	}
 
+	if result.Created {
+		logging.Infof("[Archivist] Published schema %s/%s v%s", schemaType, name, result.Schema.Version)
+	} else {
+		logging.Infof("[Archivist] Schema %s/%s unchanged, keeping v%s", schemaType, name, result.Schema.Version)
+	}
+	return result.Schema.Version, nil
 }
@@ -0,0 +1,195 @@
+// services/universegenesis/batch.go
+package universegenesis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+
+	"github.com/google/uuid"
+)
+
+// События пакетного генезиса (system_events)
+const (
+	EventGenesisBatchRequested = "universe.genesis.batch.requested"
+	EventGenesisBatchCompleted = "universe.genesis.batch.completed"
+	EventGenesisProgress       = "universe.genesis.progress"
+)
+
+const (
+	defaultBatchWorkers      = 4
+	defaultOracleParallelism = 2
+	// maxBatchSize ограничивает число миров в одном пакете
+	maxBatchSize = 100
+)
+
+// ErrInvalidBatch — пакет пуст, слишком велик или содержит повторяющиеся seed
+var ErrInvalidBatch = errors.New("invalid genesis batch")
+
+// BatchWorld — один мир пакета
+type BatchWorld struct {
+	Seed        string   `json:"seed,omitempty"` // пустой — генерируется случайно
+	Constraints []string `json:"constraints,omitempty"`
+}
+
+// BatchRequest — запрос на генезис нескольких миров
+type BatchRequest struct {
+	BatchID string       `json:"batch_id,omitempty"`
+	Worlds  []BatchWorld `json:"worlds"`
+	// Parallelism — генезисы пакета, выполняемые одновременно (0 — значение сервиса)
+	Parallelism int `json:"parallelism,omitempty"`
+}
+
+// BatchResult — итог пакетного генезиса
+type BatchResult struct {
+	BatchID   string            `json:"batch_id"`
+	Total     int               `json:"total"`
+	Completed []string          `json:"completed"`
+	Failed    map[string]string `json:"failed,omitempty"` // seed → ошибка
+}
+
+// normalize проверяет пакет и заполняет batch_id и пустые seed
+func (r *BatchRequest) normalize() error {
+	if len(r.Worlds) == 0 {
+		return fmt.Errorf("%w: no worlds", ErrInvalidBatch)
+	}
+	if len(r.Worlds) > maxBatchSize {
+		return fmt.Errorf("%w: %d worlds, at most %d allowed", ErrInvalidBatch, len(r.Worlds), maxBatchSize)
+	}
+	if r.Parallelism < 0 {
+		return fmt.Errorf("%w: negative parallelism", ErrInvalidBatch)
+	}
+	if r.BatchID == "" {
+		r.BatchID = uuid.New().String()
+	}
+	seen := make(map[string]bool, len(r.Worlds))
+	for i := range r.Worlds {
+		if r.Worlds[i].Seed == "" {
+			r.Worlds[i].Seed = uuid.New().String()
+		}
+		if seen[r.Worlds[i].Seed] {
+			return fmt.Errorf("%w: duplicate seed %s", ErrInvalidBatch, r.Worlds[i].Seed)
+		}
+		seen[r.Worlds[i].Seed] = true
+	}
+	return nil
+}
+
+// batchFromEvent разбирает payload universe.genesis.batch.requested
+func batchFromEvent(event eventbus.Event) BatchRequest {
+	req := BatchRequest{}
+	req.BatchID, _ = event.Payload["batch_id"].(string)
+	if parallelism, ok := event.Payload["parallelism"].(float64); ok {
+		req.Parallelism = int(parallelism)
+	}
+	worlds, _ := event.Payload["worlds"].([]interface{})
+	for _, item := range worlds {
+		world, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		seed, _ := world["seed"].(string)
+		req.Worlds = append(req.Worlds, BatchWorld{Seed: seed, Constraints: stringList(world["constraints"])})
+	}
+	return req
+}
+
+// RunBatch выполняет генезис всех миров пакета пулом воркеров и публикует
+// universe.genesis.batch.completed. Ошибка одного мира не останавливает остальные;
+// каждый мир сохраняет свою контрольную точку и может быть продолжен отдельно.
+func (g *Generator) RunBatch(ctx context.Context, req BatchRequest) (*BatchResult, error) {
+	if err := req.normalize(); err != nil {
+		return nil, err
+	}
+	workers := req.Parallelism
+	if workers == 0 {
+		workers = g.batchWorkers
+	}
+	if workers > len(req.Worlds) {
+		workers = len(req.Worlds)
+	}
+	logging.Infof("Genesis batch %s: %d worlds, %d workers", req.BatchID, len(req.Worlds), workers)
+
+	result := &BatchResult{BatchID: req.BatchID, Total: len(req.Worlds), Completed: []string{}, Failed: make(map[string]string)}
+	var mu sync.Mutex
+	jobs := make(chan BatchWorld)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for world := range jobs {
+				state := NewGenesisState(world.Seed, world.Constraints)
+				state.BatchID = req.BatchID
+				err := g.runPipeline(ctx, state)
+
+				mu.Lock()
+				if err != nil {
+					result.Failed[world.Seed] = err.Error()
+				} else {
+					result.Completed = append(result.Completed, world.Seed)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, world := range req.Worlds {
+		jobs <- world
+	}
+	close(jobs)
+	wg.Wait()
+
+	logging.Infof("Genesis batch %s finished: %d completed, %d failed", req.BatchID, len(result.Completed), len(result.Failed))
+	event := eventbus.NewEvent(EventGenesisBatchCompleted, "universe-genesis-oracle", "", map[string]interface{}{
+		"batch_id":  result.BatchID,
+		"total":     result.Total,
+		"completed": result.Completed,
+		"failed":    result.Failed,
+	})
+	if err := g.publish(ctx, event); err != nil {
+		logging.Errorf("Failed to publish %s for %s: %v", EventGenesisBatchCompleted, req.BatchID, err)
+	}
+	return result, nil
+}
+
+// publishProgress сообщает о смене статуса этапа генезиса для мониторинга
+func (g *Generator) publishProgress(ctx context.Context, state *GenesisState, stage, status string) {
+	completed := 0
+	for _, st := range state.Stages {
+		if st.Status == GenesisCompleted {
+			completed++
+		}
+	}
+	payload := map[string]interface{}{
+		"genesis_seed":     state.Seed,
+		"stage":            stage,
+		"status":           status,
+		"completed_stages": completed,
+		"total_stages":     len(g.stages),
+	}
+	if state.BatchID != "" {
+		payload["batch_id"] = state.BatchID
+	}
+	event := eventbus.NewEvent(EventGenesisProgress, "universe-genesis-oracle", state.Seed, payload)
+	if err := g.publish(ctx, event); err != nil {
+		logging.Errorf("Failed to publish %s for %s: %v", EventGenesisProgress, state.Seed, err)
+	}
+}
+
+// acquireOracle занимает слот вызова Oracle; release освобождает его.
+// Лимит общий для всех генезисов сервиса, включая пакетные.
+func (g *Generator) acquireOracle(ctx context.Context) (release func(), err error) {
+	if g.oracleSlots == nil {
+		return func() {}, nil
+	}
+	select {
+	case g.oracleSlots <- struct{}{}:
+		return func() { <-g.oracleSlots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,128 @@
+package universegenesis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+)
+
+func TestBatchRequestNormalize(t *testing.T) {
+	req := BatchRequest{Worlds: []BatchWorld{{Seed: "a"}, {}}}
+	if err := req.normalize(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.BatchID == "" || req.Worlds[1].Seed == "" {
+		t.Errorf("expected batch_id and missing seed generated, got %+v", req)
+	}
+
+	tooLarge := BatchRequest{Worlds: make([]BatchWorld, maxBatchSize+1)}
+	for _, invalid := range []BatchRequest{
+		{},
+		{Worlds: []BatchWorld{{Seed: "a"}, {Seed: "a"}}},
+		{Worlds: []BatchWorld{{Seed: "a"}}, Parallelism: -1},
+		tooLarge,
+	} {
+		if err := invalid.normalize(); !errors.Is(err, ErrInvalidBatch) {
+			t.Errorf("expected ErrInvalidBatch for %d worlds, got %v", len(invalid.Worlds), err)
+		}
+	}
+}
+
+func TestBatchFromEvent(t *testing.T) {
+	// Payload приходит из Kafka как после json.Unmarshal
+	var payload map[string]interface{}
+	json.Unmarshal([]byte(`{"batch_id": "b1", "parallelism": 3, "worlds": [{"seed": "a", "constraints": ["no_healing"]}, {"seed": "b"}]}`), &payload)
+	req := batchFromEvent(eventbus.NewEvent(EventGenesisBatchRequested, "test", "", payload))
+
+	if req.BatchID != "b1" || req.Parallelism != 3 || len(req.Worlds) != 2 {
+		t.Fatalf("unexpected batch: %+v", req)
+	}
+	if req.Worlds[0].Seed != "a" || len(req.Worlds[0].Constraints) != 1 || req.Worlds[1].Seed != "b" {
+		t.Errorf("unexpected worlds: %+v", req.Worlds)
+	}
+}
+
+func TestRunBatchLimitsParallelism(t *testing.T) {
+	var mu sync.Mutex
+	var published []eventbus.Event
+	active, peak := 0, 0
+	g := &Generator{
+		running:      make(map[string]context.CancelCauseFunc),
+		batchWorkers: 2,
+		publish: func(ctx context.Context, event eventbus.Event) error {
+			mu.Lock()
+			defer mu.Unlock()
+			published = append(published, event)
+			return nil
+		},
+	}
+	g.stages = []genesisStage{{StageCore, func(ctx context.Context, state *GenesisState) error {
+		mu.Lock()
+		active++
+		if active > peak {
+			peak = active
+		}
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		active--
+		mu.Unlock()
+		if state.Seed == "broken" {
+			return errors.New("oracle unavailable")
+		}
+		return nil
+	}}}
+
+	result, err := g.RunBatch(context.Background(), BatchRequest{
+		BatchID: "b1",
+		Worlds:  []BatchWorld{{Seed: "a"}, {Seed: "b"}, {Seed: "broken"}, {Seed: "c"}, {Seed: "d"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if peak != 2 {
+		t.Errorf("expected at most 2 concurrent geneses, peak %d", peak)
+	}
+	if result.Total != 5 || len(result.Completed) != 4 || result.Failed["broken"] == "" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	progress := eventsOfType(published, EventGenesisProgress)
+	if len(progress) != 10 {
+		t.Errorf("expected running and final progress per world, got %d", len(progress))
+	}
+	for _, event := range progress {
+		if event.Payload["batch_id"] != "b1" {
+			t.Errorf("progress event without batch_id: %+v", event.Payload)
+		}
+	}
+	if completed := eventsOfType(published, EventGenesisBatchCompleted); len(completed) != 1 {
+		t.Errorf("expected one %s, got %d", EventGenesisBatchCompleted, len(completed))
+	}
+}
+
+func TestAcquireOracleHonoursLimit(t *testing.T) {
+	g := &Generator{oracleSlots: make(chan struct{}, 1)}
+	release, err := g.acquireOracle(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := g.acquireOracle(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the second call to wait for a slot, got %v", err)
+	}
+
+	release()
+	if release, err := g.acquireOracle(context.Background()); err != nil {
+		t.Errorf("expected the slot to be free after release: %v", err)
+	} else {
+		release()
+	}
+}
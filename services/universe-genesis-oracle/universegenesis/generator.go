@@ -4,115 +4,67 @@ package universegenesis
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"sync"
 
 	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
 	"multiverse-core.io/shared/oracle"
+	"multiverse-core.io/shared/schema"
 )
 
-type Generator struct {
-	bus       *eventbus.EventBus
-	archivist *ArchivistClient // Теперь используется для сохранения universeBanProfile
-	oracle    *oracle.Client   // <-- Изменён тип
-}
-
-func NewGenerator(bus *eventbus.EventBus, archivist *ArchivistClient, oracle *oracle.Client) *Generator {
-	return &Generator{
-		bus:       bus,
-		archivist: archivist,
-		oracle:    oracle,
-	}
-}
+// EventSchemaGenerationFailed публикуется в system_events, когда схема сущности
+// не прошла проверку ни в одной из попыток генерации
+const EventSchemaGenerationFailed = "schema.generation.failed"
 
-func (g *Generator) StartGenesis(ctx context.Context, seed string, constraints []string) error {
-	log.Printf("Generating universe core laws and fundamental principles for seed: %s", seed)
+// defaultSchemaAttempts — попытки генерации схемы сущности по умолчанию
+const defaultSchemaAttempts = 3
 
-	// 1. Генерация изначальных законов Вселенной и Ядра Вселенной через ИИ
-	coreLaws, universeCore, err := g.generateUniverseCore(ctx, seed, constraints)
-	if err != nil {
-		return fmt.Errorf("failed to generate universe core: %w", err)
-	}
+type Generator struct {
+	bus         *eventbus.EventBus
+	archivist   *ArchivistClient // Сохраняет ядро, профиль Запрета и схемы сущностей
+	oracle      *oracle.Client   // <-- Изменён тип
+	checkpoints CheckpointStore  // nil — генезис не возобновляется после перезапуска
+	publish     func(ctx context.Context, event eventbus.Event) error
+	stages      []genesisStage
 
-	// 2. Генерация онтологического профиля для Запрета Вселенной через ИИ
-	// Используем coreLaws и universeCore как контекст
-	universeBanProfile, err := g.generateUniverseBanProfile(ctx, universeCore, coreLaws)
-	if err != nil {
-		return fmt.Errorf("failed to generate universe ban profile: %w", err)
-	}
+	batchWorkers int           // генезисы пакета, выполняемые одновременно по умолчанию
+	oracleSlots  chan struct{} // ограничивает одновременные вызовы Oracle
 
-	// 3. Сохранение профиля онтологии для Запрета Вселенной
-	profileJSON, err := json.Marshal(universeBanProfile)
-	if err != nil {
-		return fmt.Errorf("failed to marshal universe ban profile: %w", err)
-	}
+	deterministic bool           // фиксированная выборка Oracle с записью и воспроизведением ответов
+	recordings    OracleRecorder // записи ответов Oracle; nil — только фиксированная выборка
 
-	// Сохраняем профиль с типом "universe_ontology_profile" и именем "cosmic_law"
-	// Это даст путь в OntologicalArchivist: schemas/universe_ontology_profile/cosmic_law/1.0
-	if err := g.archivist.SaveSchema(ctx, "universe_ontology_profile", "cosmic_law", "1.0", profileJSON); err != nil {
-		log.Printf("Warning: Failed to save universe ban profile: %v", err)
-		// Опять же, не критично для завершения генезиса, но желательно сохранить
-	}
-	log.Printf("Universe ban profile saved")
+	schemaAttempts int // попытки генерации схемы сущности, прошедшей проверку Draft 7
 
-	core, err := json.Marshal(map[string]interface{}{
-		"genesis_seed":  seed,
-		"universe_core": universeCore,
-		"cosmic_laws":   coreLaws,
-		// Убираем archetypal_templates
-	})
+	mu      sync.Mutex
+	running map[string]context.CancelCauseFunc // seed → отмена выполняющегося генезиса
+}
 
-	// Это даст путь в OntologicalArchivist: schemas/universe_ontology_profile/cosmic_law/1.0
-	if err := g.archivist.SaveSchema(ctx, "universe_core", "universe_core", "1.0", core); err != nil {
-		log.Printf("Warning: Failed to save universe core: %v", err)
-		// Опять же, не критично для завершения генезиса, но желательно сохранить
+func NewGenerator(bus *eventbus.EventBus, archivist *ArchivistClient, oracle *oracle.Client, checkpoints CheckpointStore, cfg Config) *Generator {
+	if cfg.BatchWorkers <= 0 {
+		cfg.BatchWorkers = defaultBatchWorkers
 	}
-
-	// 4. Генерация базовых схем для сущностей вселенной
-	entityTypes := []string{"player", "npc", "house", "animal", "artifact"}
-	for _, entityType := range entityTypes {
-		// Используем функцию из worldgenerator для генерации схемы
-		if err := GenerateEntitySchemaWithArchivist(g.archivist, ctx, entityType, seed); err != nil {
-			log.Printf("Schema generation warning for %s: %v", entityType, err)
-			// Continue with other types
-		}
+	if cfg.OracleParallelism <= 0 {
+		cfg.OracleParallelism = defaultOracleParallelism
 	}
-
-	// Удаляем ненужную функцию
-
-	// 5. Публикация финального события о завершении генезиса
-
-	// Удаляем ненужную функцию
-
-	// 5. Публикация финального события о завершении генезиса
-	// Содержит только Ядро и Законы Вселенной
-	finalEvent := eventbus.NewEvent(
-		"universe.genesis.completed",
-		"universe-genesis-oracle",
-		seed,
-		map[string]interface{}{
-			"genesis_seed":  seed,
-			"universe_core": universeCore,
-			"cosmic_laws":   coreLaws,
-			// Убираем archetypal_templates
-		})
-	/* 	finalEvent := eventbus.Event{
-		EventID:   "universe-genesis-" + uuid.New().String()[:8],
-		EventType: "universe.genesis.completed",
-		Source:    "universe-genesis-oracle",
-		WorldID:   "universe", // Условный ID для вселенной
-		Payload: map[string]interface{}{
-			"genesis_seed":  seed,
-			"universe_core": universeCore,
-			"cosmic_laws":   coreLaws,
-			// Убираем archetypal_templates
-		},
-		Timestamp: time.Now(),
-	} */
-	g.bus.Publish(ctx, eventbus.TopicSystemEvents, finalEvent)
-
-	log.Printf("Universe Genesis for seed '%s' completed successfully", seed)
-	return nil
+	g := &Generator{
+		bus:          bus,
+		archivist:    archivist,
+		oracle:       oracle,
+		checkpoints:  checkpoints,
+		publish:      bus.PublishSystemEvent,
+		batchWorkers: cfg.BatchWorkers,
+		oracleSlots:  make(chan struct{}, cfg.OracleParallelism),
+		running:      make(map[string]context.CancelCauseFunc),
+
+		deterministic: cfg.Deterministic,
+		recordings:    cfg.Recordings,
+
+		schemaAttempts: cfg.SchemaAttempts,
+	}
+	g.stages = g.defaultStages()
+	return g
 }
 
 // generateUniverseCore вызывает Oracle для генерации изначальных законов и Ядра Вселенной.
@@ -158,9 +110,7 @@ func (g *Generator) generateUniverseCore(ctx context.Context, seed string, const
 	}
 
 	// Вызываем метод, передавая указатель на переменную
-	err := g.oracle.CallAndUnmarshal(ctx, func() (string, error) {
-		return g.oracle.CallStructured(ctx, systemPrompt, userPrompt)
-	}, &result)
+	err := g.callOracle(ctx, seed, StageCore, systemPrompt, userPrompt, &result)
 	if err != nil {
 		return nil, "", fmt.Errorf("oracle call for universe core failed: %w", err)
 	}
@@ -169,7 +119,7 @@ func (g *Generator) generateUniverseCore(ctx context.Context, seed string, const
 }
 
 // generateUniverseBanProfile вызывает Oracle для генерации онтологического профиля Запрета Вселенной.
-func (g *Generator) generateUniverseBanProfile(ctx context.Context, universeCore string, cosmicLaws []string) (*OntologyProfile, error) {
+func (g *Generator) generateUniverseBanProfile(ctx context.Context, seed, universeCore string, cosmicLaws []string) (*OntologyProfile, error) {
 	// Преобразуем []string в JSON строку для подстановки в промпт
 	cosmicLawsJSON, err := json.Marshal(cosmicLaws)
 	if err != nil {
@@ -217,9 +167,7 @@ func (g *Generator) generateUniverseBanProfile(ctx context.Context, universeCore
 	var profile OntologyProfile
 
 	// Вызываем метод, передавая указатель на переменную
-	err = g.oracle.CallAndUnmarshal(ctx, func() (string, error) {
-		return g.oracle.CallStructured(ctx, systemPrompt, userPrompt)
-	}, &profile)
+	err = g.callOracle(ctx, seed, StageBanProfile, systemPrompt, userPrompt, &profile)
 	if err != nil {
 		return nil, fmt.Errorf("oracle call for universe ban profile failed: %w", err)
 	}
@@ -263,26 +211,67 @@ const BaseEntitySchema = `{
 	"required": ["entity_id", "entity_type", "created_at", "updated_at", "payload", "history"]
 }`
 
-// GenerateEntitySchemaWithArchivist generates and saves a schema for an entity type using provided archivist client
-func GenerateEntitySchemaWithArchivist(archivist *ArchivistClient, ctx context.Context, entityType, worldSeed string) error {
-	log.Printf("Generating schema for entity type: %s", entityType)
+// GenerateEntitySchema generates and saves a schema for an entity type in the archivist
+// and returns the archivist version it is available under. A generated schema is saved only
+// after it passes the Draft 7 check; rejected schemas are regenerated with the check error
+// in the prompt, up to schemaAttempts times, after which schema.generation.failed is published.
+func (g *Generator) GenerateEntitySchema(ctx context.Context, entityType, worldSeed string) (string, error) {
+	logging.Infof("Generating schema for entity type: %s", entityType)
 
-	// Generate payload schema via Oracle
-	payloadSchemaStr, err := generatePayloadSchema(ctx, entityType, worldSeed)
-	if err != nil {
-		return fmt.Errorf("payload schema generation failed: %w", err)
+	attempts := g.schemaAttempts
+	if attempts <= 0 {
+		attempts = defaultSchemaAttempts
+	}
+	var rejection error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		// Generate payload schema via Oracle
+		payloadSchemaStr, err := g.generatePayloadSchema(ctx, entityType, worldSeed, attempt, rejection)
+		var fullSchemaBytes []byte
+		if err == nil {
+			fullSchemaBytes, err = buildEntitySchema(payloadSchemaStr)
+		}
+		var syntaxErr *json.SyntaxError
+		if err != nil && !errors.As(err, &syntaxErr) && !errors.Is(err, schema.ErrInvalidSchema) {
+			return "", fmt.Errorf("payload schema generation failed: %w", err)
+		}
+		if err == nil {
+			err = schema.CheckDraft7(fullSchemaBytes)
+		}
+		if err != nil {
+			rejection = err
+			logging.Warnf("Genesis %s: %s schema rejected (attempt %d/%d): %v", worldSeed, entityType, attempt, attempts, err)
+			continue
+		}
+
+		// Save to OntologicalArchivist
+		version, err := g.archivist.PublishSchema(ctx, "entity", entityType, fullSchemaBytes)
+		if err != nil {
+			return "", fmt.Errorf("failed to save schema to archivist: %w", err)
+		}
+
+		logging.Infof("Schema for %s saved to Archivist as v%s", entityType, version)
+		return version, nil
 	}
 
+	g.publishSchemaFailed(ctx, worldSeed, entityType, attempts, rejection)
+	return "", fmt.Errorf("%s schema rejected after %d attempts: %w", entityType, attempts, rejection)
+}
+
+// buildEntitySchema merges a generated payload schema into BaseEntitySchema.
+func buildEntitySchema(payloadSchemaStr string) ([]byte, error) {
 	// Parse base schema
 	var baseSchema map[string]interface{}
 	if err := json.Unmarshal([]byte(BaseEntitySchema), &baseSchema); err != nil {
-		return fmt.Errorf("base schema parse failed: %w", err)
+		return nil, fmt.Errorf("base schema parse failed: %w", err)
 	}
 
 	// Parse payload schema
 	var payloadSchema map[string]interface{}
 	if err := json.Unmarshal([]byte(payloadSchemaStr), &payloadSchema); err != nil {
-		return fmt.Errorf("payload schema parse failed: %w", err)
+		return nil, fmt.Errorf("%w: payload schema is not a JSON object: %v", schema.ErrInvalidSchema, err)
+	}
+	if payloadSchema["type"] != "object" {
+		return nil, fmt.Errorf("%w: payload schema must have \"type\": \"object\"", schema.ErrInvalidSchema)
 	}
 
 	// Merge schemas
@@ -292,20 +281,28 @@ func GenerateEntitySchemaWithArchivist(archivist *ArchivistClient, ctx context.C
 	// Convert to bytes
 	fullSchemaBytes, err := json.Marshal(baseSchema)
 	if err != nil {
-		return fmt.Errorf("schema marshal failed: %w", err)
+		return nil, fmt.Errorf("schema marshal failed: %w", err)
 	}
+	return fullSchemaBytes, nil
+}
 
-	// Save to OntologicalArchivist
-	if err := archivist.SaveSchema(ctx, "entity", entityType, "1.0", fullSchemaBytes); err != nil {
-		return fmt.Errorf("failed to save schema to archivist: %w", err)
+// publishSchemaFailed reports an entity type left without a schema after all attempts.
+func (g *Generator) publishSchemaFailed(ctx context.Context, worldSeed, entityType string, attempts int, cause error) {
+	event := eventbus.NewEvent(EventSchemaGenerationFailed, "universe-genesis-oracle", worldSeed, map[string]interface{}{
+		"genesis_seed": worldSeed,
+		"schema_type":  "entity",
+		"name":         entityType,
+		"attempts":     attempts,
+		"error":        cause.Error(),
+	})
+	if err := g.publish(ctx, event); err != nil {
+		logging.Errorf("Failed to publish %s for %s/%s: %v", EventSchemaGenerationFailed, worldSeed, entityType, err)
 	}
-
-	log.Printf("Schema for %s saved to Archivist", entityType)
-	return nil
 }
 
-// generatePayloadSchema asks Ascension Oracle to generate a payload schema.
-func generatePayloadSchema(ctx context.Context, entityType, worldSeed string) (string, error) {
+// generatePayloadSchema asks Ascension Oracle to generate a payload schema. Regeneration
+// attempts include the rejection of the previous schema and are recorded under their own call.
+func (g *Generator) generatePayloadSchema(ctx context.Context, entityType, worldSeed string, attempt int, rejection error) (string, error) {
 	prompt := fmt.Sprintf(`
  Сгенерируй ТОЛЬКО JSON Schema Draft 7 для поля "payload" сущности типа "%s" в мире с семенем "%s".
 
@@ -332,16 +329,16 @@ func generatePayloadSchema(ctx context.Context, entityType, worldSeed string) (s
 
  `, entityType, worldSeed)
 
-	client := oracle.NewClient()
-
-	content, err := client.CallAndLog(ctx, prompt)
-	if err != nil {
-		return "", fmt.Errorf("failed to connect to oracle: %w", err)
+	call := "entity_schema." + entityType
+	if attempt > 1 {
+		call = fmt.Sprintf("%s.retry%d", call, attempt-1)
+		prompt += fmt.Sprintf("\n Предыдущая схема отклонена: %v\n Исправь ошибку и верни схему заново.\n", rejection)
 	}
 
-	if content == "" {
-		return "", fmt.Errorf("oracle returned empty content")
+	var content json.RawMessage
+	if err := g.callOracle(ctx, worldSeed, call, "", prompt, &content); err != nil {
+		return "", fmt.Errorf("oracle call for %s payload schema failed: %w", entityType, err)
 	}
 
-	return content, nil
+	return string(content), nil
 }
@@ -0,0 +1,100 @@
+package universegenesis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/oracle"
+	"multiverse-core.io/shared/schema"
+)
+
+// schemaOracle отвечает схемами из responses по очереди и запоминает промпты
+func schemaOracle(t *testing.T, responses ...string) (*oracle.Client, *[]string) {
+	var prompts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		prompts = append(prompts, string(body))
+		content, _ := json.Marshal(responses[(len(prompts)-1)%len(responses)])
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"choices": [{"message": {"content": %s}}]}`, content)
+	}))
+	t.Cleanup(server.Close)
+	return &oracle.Client{BaseURL: server.URL, Model: "test", Client: server.Client()}, &prompts
+}
+
+// fakeArchivist принимает схемы и отвечает версией 1.0
+func fakeArchivist(t *testing.T) (*ArchivistClient, *int) {
+	saved := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		saved++
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"schema": {"version": "1.0"}, "created": true}`))
+	}))
+	t.Cleanup(server.Close)
+	return NewArchivistClient(server.URL), &saved
+}
+
+func TestGenerateEntitySchemaRegeneratesInvalidSchema(t *testing.T) {
+	schema.RegisterCustomFormats()
+	client, prompts := schemaOracle(t,
+		`{"type": "object", "properties": {"name": {"type": "string"}}, "required": ["name", "hp"], "additionalProperties": false}`,
+		`{"type": "object", "properties": {"name": {"type": "string"}, "hp": {"type": "integer", "minimum": 0}}, "required": ["name", "hp"]}`,
+	)
+	archivist, saved := fakeArchivist(t)
+	var published []eventbus.Event
+	g := &Generator{
+		oracle:    client,
+		archivist: archivist,
+		publish: func(ctx context.Context, event eventbus.Event) error {
+			published = append(published, event)
+			return nil
+		},
+	}
+
+	version, err := g.GenerateEntitySchema(context.Background(), "player", "seed-1")
+	if err != nil || version != "1.0" {
+		t.Fatalf("expected the regenerated schema saved, got %q, %v", version, err)
+	}
+	if len(*prompts) != 2 || *saved != 1 {
+		t.Errorf("expected 2 oracle calls and 1 save, got %d and %d", len(*prompts), *saved)
+	}
+	if !strings.Contains((*prompts)[1], "Предыдущая схема отклонена") {
+		t.Errorf("expected the rejection in the retry prompt")
+	}
+	if len(published) != 0 {
+		t.Errorf("unexpected events %+v", published)
+	}
+}
+
+func TestGenerateEntitySchemaGivesUp(t *testing.T) {
+	client, prompts := schemaOracle(t, `не JSON`, `{"name": {"type": "string"}}`)
+	archivist, saved := fakeArchivist(t)
+	var published []eventbus.Event
+	g := &Generator{
+		oracle:         client,
+		archivist:      archivist,
+		schemaAttempts: 2,
+		publish: func(ctx context.Context, event eventbus.Event) error {
+			published = append(published, event)
+			return nil
+		},
+	}
+
+	if _, err := g.GenerateEntitySchema(context.Background(), "npc", "seed-1"); err == nil {
+		t.Fatal("expected the schema rejected")
+	}
+	if len(*prompts) != 2 || *saved != 0 {
+		t.Errorf("expected 2 oracle calls and no saves, got %d and %d", len(*prompts), *saved)
+	}
+	failed := eventsOfType(published, EventSchemaGenerationFailed)
+	if len(failed) != 1 || failed[0].Payload["name"] != "npc" || failed[0].Payload["attempts"] != 2 {
+		t.Errorf("expected one %s for npc, got %+v", EventSchemaGenerationFailed, failed)
+	}
+}
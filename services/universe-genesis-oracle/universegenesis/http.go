@@ -0,0 +1,131 @@
+// services/universegenesis/http.go
+package universegenesis
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+)
+
+// routes собирает HTTP API UniverseGenesisOracle
+func (s *Service) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.HandleFunc("POST /v1/genesis", s.handleStartGenesis)
+	mux.HandleFunc("POST /v1/genesis/batch", s.handleStartBatch)
+	mux.HandleFunc("GET /v1/genesis/{seed}", s.handleGenesisState)
+	mux.HandleFunc("DELETE /v1/genesis/{seed}", s.handleAbortGenesis)
+	return mux
+}
+
+// GenesisRequest — запрос на генезис одного мира
+type GenesisRequest struct {
+	Seed        string   `json:"seed,omitempty"` // пустой — генерируется случайно
+	Constraints []string `json:"constraints,omitempty"`
+}
+
+// genesisStatus — контрольная точка генезиса и признак выполнения в этом процессе
+// (running в контрольной точке без running: true — генезис прерван перезапуском)
+type genesisStatus struct {
+	*GenesisState
+	Running bool `json:"running"`
+}
+
+// handleStartGenesis обрабатывает POST /v1/genesis: генезис запускается в фоне,
+// ход отслеживается через GET /v1/genesis/{seed}
+func (s *Service) handleStartGenesis(w http.ResponseWriter, r *http.Request) {
+	var req GenesisRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	seed, err := s.StartGenesis(s.ctx, req.Seed, req.Constraints)
+	if err != nil {
+		if errors.Is(err, ErrGenesisInProgress) {
+			http.Error(w, "Genesis already in progress", http.StatusConflict)
+			return
+		}
+		http.Error(w, "Failed to start genesis", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]interface{}{
+		"seed":   seed,
+		"status": GenesisRunning,
+	})
+}
+
+// handleStartBatch обрабатывает POST /v1/genesis/batch: пакет запускается в фоне,
+// ход генезиса отслеживается по событиям universe.genesis.progress
+func (s *Service) handleStartBatch(w http.ResponseWriter, r *http.Request) {
+	var req BatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	req, err := s.StartBatch(s.ctx, req)
+	if err != nil {
+		if errors.Is(err, ErrInvalidBatch) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Failed to start batch", http.StatusInternalServerError)
+		return
+	}
+
+	seeds := make([]string, 0, len(req.Worlds))
+	for _, world := range req.Worlds {
+		seeds = append(seeds, world.Seed)
+	}
+	writeJSON(w, http.StatusAccepted, map[string]interface{}{
+		"batch_id": req.BatchID,
+		"seeds":    seeds,
+	})
+}
+
+// handleGenesisState обрабатывает GET /v1/genesis/{seed}: контрольная точка генезиса
+// со статусом каждого этапа
+func (s *Service) handleGenesisState(w http.ResponseWriter, r *http.Request) {
+	if s.generator.checkpoints == nil {
+		http.Error(w, "Checkpoints are disabled", http.StatusNotImplemented)
+		return
+	}
+	state, err := s.generator.checkpoints.Load(r.Context(), r.PathValue("seed"))
+	if err != nil {
+		switch {
+		case storage.IsNotFound(err):
+			http.Error(w, "Genesis not found", http.StatusNotFound)
+		case storage.IsUnavailable(err):
+			http.Error(w, "Checkpoint storage unavailable", http.StatusServiceUnavailable)
+		default:
+			logging.Errorf("Failed to load genesis %s: %v", r.PathValue("seed"), err)
+			http.Error(w, "Failed to load genesis", http.StatusInternalServerError)
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, genesisStatus{GenesisState: state, Running: s.generator.Running(state.Seed)})
+}
+
+// handleAbortGenesis обрабатывает DELETE /v1/genesis/{seed}: остановка выполняющегося генезиса.
+// Генезис помечается aborted после прерывания текущего этапа.
+func (s *Service) handleAbortGenesis(w http.ResponseWriter, r *http.Request) {
+	seed := r.PathValue("seed")
+	if !s.generator.Abort(seed) {
+		http.Error(w, "Genesis is not running", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]interface{}{
+		"seed":   seed,
+		"status": GenesisAborted,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
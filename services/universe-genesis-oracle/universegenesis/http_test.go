@@ -0,0 +1,82 @@
+package universegenesis
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+)
+
+func TestGenesisHTTPStartStatusAbort(t *testing.T) {
+	store := &memoryCheckpoints{}
+	started := make(chan struct{})
+	g := &Generator{
+		checkpoints: store,
+		running:     make(map[string]context.CancelCauseFunc),
+		publish:     func(ctx context.Context, event eventbus.Event) error { return nil },
+	}
+	g.stages = []genesisStage{
+		{StageCore, func(ctx context.Context, state *GenesisState) error { return nil }},
+		{StageBanProfile, func(ctx context.Context, state *GenesisState) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		}},
+	}
+	s := &Service{generator: g, ctx: context.Background()}
+	server := httptest.NewServer(s.routes())
+	defer server.Close()
+
+	do := func(method, path, body string) *http.Response {
+		req, _ := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	status := func() genesisStatus {
+		resp := do(http.MethodGet, "/v1/genesis/alpha", "")
+		defer resp.Body.Close()
+		var st genesisStatus
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET status %d", resp.StatusCode)
+		}
+		json.NewDecoder(resp.Body).Decode(&st)
+		return st
+	}
+
+	if resp := do(http.MethodPost, "/v1/genesis", `{"seed": "alpha", "constraints": ["no_healing"]}`); resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", resp.StatusCode)
+	}
+	<-started
+	if resp := do(http.MethodPost, "/v1/genesis", `{"seed": "alpha"}`); resp.StatusCode != http.StatusConflict {
+		t.Errorf("expected 409 for a running genesis, got %d", resp.StatusCode)
+	}
+	if st := status(); !st.Running || st.Stages[StageCore].Status != GenesisCompleted || len(st.Constraints) != 1 {
+		t.Errorf("unexpected running status %+v", st)
+	}
+
+	if resp := do(http.MethodDelete, "/v1/genesis/alpha", ""); resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected 202 on abort, got %d", resp.StatusCode)
+	}
+	deadline := time.Now().Add(time.Second)
+	for g.Running("alpha") && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	st := status()
+	if st.Running || st.Status != GenesisAborted || st.Stages[StageBanProfile].Status != GenesisAborted {
+		t.Errorf("unexpected aborted status %+v", st)
+	}
+	if pending, _ := store.Pending(context.Background()); len(pending) != 0 {
+		t.Errorf("aborted genesis must not be resumed on start, got %d pending", len(pending))
+	}
+	if resp := do(http.MethodDelete, "/v1/genesis/alpha", ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for a stopped genesis, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,452 @@
+// services/universegenesis/pipeline.go
+package universegenesis
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+)
+
+// Этапы генезиса в порядке выполнения
+const (
+	StageCore          = "core"
+	StageBanProfile    = "ban_profile"
+	StageEntitySchemas = "entity_schemas"
+	StageCompletion    = "completion"
+)
+
+// Статусы генезиса и его этапов
+const (
+	GenesisRunning   = "running"
+	GenesisFailed    = "failed"
+	GenesisCompleted = "completed"
+	GenesisAborted   = "aborted" // остановлен оператором; не возобновляется при старте сервиса
+)
+
+// Типы событий генезиса (system_events)
+const (
+	EventGenesisRequest   = "universe.genesis.request"
+	EventGenesisResume    = "universe.genesis.resume"
+	EventGenesisCompleted = "universe.genesis.completed"
+	EventGenesisFailed    = "universe.genesis.failed"
+)
+
+// genesisBucket хранит состояние генезиса как {seed}/state.json
+const genesisBucket = "genesis"
+
+// genesisEntityTypes — типы сущностей, для которых генезис создаёт базовые схемы
+var genesisEntityTypes = []string{"player", "npc", "house", "animal", "artifact"}
+
+// ErrGenesisInProgress — генезис с этим seed уже выполняется
+var ErrGenesisInProgress = errors.New("genesis already in progress")
+
+// ErrGenesisAborted — генезис остановлен через Abort
+var ErrGenesisAborted = errors.New("genesis aborted")
+
+// StageStatus — состояние одного этапа
+type StageStatus struct {
+	Status      string     `json:"status"`
+	Attempts    int        `json:"attempts"`
+	Error       string     `json:"error,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// GenesisState — контрольная точка генезиса: статусы этапов и их результаты,
+// нужные следующим этапам после перезапуска
+type GenesisState struct {
+	Seed        string                  `json:"seed"`
+	BatchID     string                  `json:"batch_id,omitempty"` // пакет, в составе которого запущен генезис
+	Constraints []string                `json:"constraints,omitempty"`
+	Status      string                  `json:"status"`
+	Stages      map[string]*StageStatus `json:"stages"`
+	StartedAt   time.Time               `json:"started_at"`
+	UpdatedAt   time.Time               `json:"updated_at"`
+
+	// Результаты этапов
+	CosmicLaws     []string          `json:"cosmic_laws,omitempty"`
+	UniverseCore   string            `json:"universe_core,omitempty"`
+	BanProfile     *OntologyProfile  `json:"ban_profile,omitempty"`
+	SchemaVersions map[string]string `json:"schema_versions,omitempty"` // "{schema_type}/{name}" → версия в архивариусе
+}
+
+// NewGenesisState создаёт состояние нового генезиса
+func NewGenesisState(seed string, constraints []string) *GenesisState {
+	now := time.Now().UTC()
+	return &GenesisState{
+		Seed:           seed,
+		Constraints:    constraints,
+		Status:         GenesisRunning,
+		Stages:         make(map[string]*StageStatus),
+		StartedAt:      now,
+		UpdatedAt:      now,
+		SchemaVersions: make(map[string]string),
+	}
+}
+
+// stage возвращает состояние этапа, создавая его при первом обращении
+func (s *GenesisState) stage(name string) *StageStatus {
+	if s.Stages == nil {
+		s.Stages = make(map[string]*StageStatus)
+	}
+	st, ok := s.Stages[name]
+	if !ok {
+		st = &StageStatus{Status: GenesisRunning}
+		s.Stages[name] = st
+	}
+	return st
+}
+
+// CheckpointStore хранит контрольные точки генезиса
+type CheckpointStore interface {
+	// Load возвращает состояние генезиса; отсутствующее — storage.ErrNotFound
+	Load(ctx context.Context, seed string) (*GenesisState, error)
+	Save(ctx context.Context, state *GenesisState) error
+	// Pending возвращает незавершённые генезисы, кроме остановленных оператором
+	Pending(ctx context.Context) ([]*GenesisState, error)
+}
+
+// MinioCheckpointStore хранит контрольные точки в бакете genesis
+type MinioCheckpointStore struct {
+	client storage.ObjectStorage
+}
+
+// NewMinioCheckpointStore создаёт хранилище контрольных точек в MinIO
+func NewMinioCheckpointStore(client storage.ObjectStorage) *MinioCheckpointStore {
+	return &MinioCheckpointStore{client: client}
+}
+
+func checkpointKey(seed string) string {
+	return seed + "/state.json"
+}
+
+func (m *MinioCheckpointStore) Load(ctx context.Context, seed string) (*GenesisState, error) {
+	data, err := m.client.GetObject(genesisBucket, checkpointKey(seed))
+	if err != nil {
+		return nil, err
+	}
+	var state GenesisState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("corrupted genesis checkpoint %s: %w", seed, err)
+	}
+	return &state, nil
+}
+
+func (m *MinioCheckpointStore) Save(ctx context.Context, state *GenesisState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return m.client.PutObject(genesisBucket, checkpointKey(state.Seed), bytes.NewReader(data), int64(len(data)))
+}
+
+func (m *MinioCheckpointStore) Pending(ctx context.Context) ([]*GenesisState, error) {
+	objects, err := m.client.ListObjects(genesisBucket, "")
+	if err != nil {
+		return nil, err
+	}
+	var pending []*GenesisState
+	for _, obj := range objects {
+		if !strings.HasSuffix(obj.Key, "/state.json") {
+			continue
+		}
+		state, err := m.Load(ctx, strings.TrimSuffix(obj.Key, "/state.json"))
+		if err != nil {
+			logging.Warnf("Skipping genesis checkpoint %s: %v", obj.Key, err)
+			continue
+		}
+		if state.Status != GenesisCompleted && state.Status != GenesisAborted {
+			pending = append(pending, state)
+		}
+	}
+	return pending, nil
+}
+
+// genesisStage — шаг конвейера; результат записывается в state
+type genesisStage struct {
+	name string
+	run  func(ctx context.Context, state *GenesisState) error
+}
+
+// defaultStages — конвейер генезиса: ядро → профиль Запрета → схемы сущностей → событие о завершении
+func (g *Generator) defaultStages() []genesisStage {
+	return []genesisStage{
+		{StageCore, g.runCoreStage},
+		{StageBanProfile, g.runBanProfileStage},
+		{StageEntitySchemas, g.runEntitySchemasStage},
+		{StageCompletion, g.runCompletionStage},
+	}
+}
+
+// StartGenesis начинает генезис заново: прежняя контрольная точка seed перезаписывается
+func (g *Generator) StartGenesis(ctx context.Context, seed string, constraints []string) error {
+	return g.runPipeline(ctx, NewGenesisState(seed, constraints))
+}
+
+// ResumeGenesis продолжает генезис с первого незавершённого этапа.
+// Завершённый генезис не повторяется.
+func (g *Generator) ResumeGenesis(ctx context.Context, seed string) error {
+	if g.checkpoints == nil {
+		return fmt.Errorf("genesis %s cannot be resumed: checkpoints are disabled", seed)
+	}
+	state, err := g.checkpoints.Load(ctx, seed)
+	if err != nil {
+		return fmt.Errorf("load genesis checkpoint %s: %w", seed, err)
+	}
+	if state.Status == GenesisCompleted {
+		logging.Infof("Genesis %s already completed, nothing to resume", seed)
+		return nil
+	}
+	return g.runPipeline(ctx, state)
+}
+
+// ResumePending продолжает все незавершённые генезисы (вызывается при старте сервиса)
+func (g *Generator) ResumePending(ctx context.Context) {
+	if g.checkpoints == nil {
+		return
+	}
+	pending, err := g.checkpoints.Pending(ctx)
+	if err != nil {
+		logging.Errorf("Failed to list pending genesis checkpoints: %v", err)
+		return
+	}
+	for _, state := range pending {
+		logging.Infof("Resuming genesis %s", state.Seed)
+		if err := g.runPipeline(ctx, state); err != nil {
+			logging.Errorf("Resumed genesis %s failed: %v", state.Seed, err)
+		}
+	}
+}
+
+// Running сообщает, выполняется ли генезис seed в этом процессе
+func (g *Generator) Running(seed string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	_, ok := g.running[seed]
+	return ok
+}
+
+// Abort останавливает выполняющийся генезис: текущий этап прерывается отменой ctx,
+// генезис помечается aborted. Возвращает false, если генезис seed не выполняется.
+func (g *Generator) Abort(seed string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	cancel, ok := g.running[seed]
+	if ok {
+		cancel(ErrGenesisAborted)
+	}
+	return ok
+}
+
+// begin регистрирует выполняющийся генезис; release снимает регистрацию.
+// Возвращённый ctx отменяется через Abort.
+func (g *Generator) begin(ctx context.Context, seed string) (context.Context, func(), error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.running[seed]; ok {
+		return nil, nil, fmt.Errorf("%w: %s", ErrGenesisInProgress, seed)
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	g.running[seed] = cancel
+	release := func() {
+		g.mu.Lock()
+		delete(g.running, seed)
+		g.mu.Unlock()
+		cancel(nil)
+	}
+	return ctx, release, nil
+}
+
+// runPipeline выполняет незавершённые этапы по порядку, сохраняя контрольную точку после каждого.
+// При ошибке этап помечается failed, публикуется universe.genesis.failed, а следующий запуск
+// продолжит с этого этапа.
+func (g *Generator) runPipeline(ctx context.Context, state *GenesisState) error {
+	ctx, release, err := g.begin(ctx, state.Seed)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return g.execute(ctx, state)
+}
+
+// execute выполняет конвейер генезиса, уже зарегистрированного через begin
+func (g *Generator) execute(ctx context.Context, state *GenesisState) error {
+	state.Status = GenesisRunning
+	if state.SchemaVersions == nil {
+		state.SchemaVersions = make(map[string]string)
+	}
+	// Контрольная точка до первого этапа: ход генезиса виден сразу после запуска
+	g.checkpoint(ctx, state)
+	for _, stage := range g.stages {
+		st := state.stage(stage.name)
+		if st.Status == GenesisCompleted {
+			continue
+		}
+		if aborted(ctx) {
+			return g.abort(ctx, state, stage.name)
+		}
+
+		logging.Infof("Genesis %s: running stage %s", state.Seed, stage.name)
+		st.Status = GenesisRunning
+		st.Attempts++
+		g.publishProgress(ctx, state, stage.name, GenesisRunning)
+		if err := stage.run(ctx, state); err != nil {
+			if aborted(ctx) {
+				return g.abort(ctx, state, stage.name)
+			}
+			st.Status = GenesisFailed
+			st.Error = err.Error()
+			state.Status = GenesisFailed
+			g.checkpoint(ctx, state)
+			g.publishProgress(ctx, state, stage.name, GenesisFailed)
+			g.publishFailed(ctx, state, stage.name, err)
+			return fmt.Errorf("genesis stage %s failed: %w", stage.name, err)
+		}
+
+		completedAt := time.Now().UTC()
+		st.Status = GenesisCompleted
+		st.Error = ""
+		st.CompletedAt = &completedAt
+		g.checkpoint(ctx, state)
+		g.publishProgress(ctx, state, stage.name, GenesisCompleted)
+	}
+
+	state.Status = GenesisCompleted
+	g.checkpoint(ctx, state)
+	logging.Infof("Universe Genesis for seed '%s' completed successfully", state.Seed)
+	return nil
+}
+
+// aborted сообщает, остановлен ли генезис через Abort
+func aborted(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), ErrGenesisAborted)
+}
+
+// abort помечает генезис и прерванный этап aborted. Контрольная точка и событие о ходе
+// сохраняются вне отменённого ctx.
+func (g *Generator) abort(ctx context.Context, state *GenesisState, stage string) error {
+	ctx = context.WithoutCancel(ctx)
+	st := state.stage(stage)
+	st.Status = GenesisAborted
+	st.Error = ""
+	state.Status = GenesisAborted
+	g.checkpoint(ctx, state)
+	g.publishProgress(ctx, state, stage, GenesisAborted)
+	logging.Infof("Genesis %s aborted at stage %s", state.Seed, stage)
+	return fmt.Errorf("%w: %s", ErrGenesisAborted, state.Seed)
+}
+
+// checkpoint сохраняет состояние. Недоступность хранилища не останавливает генезис —
+// теряется только возможность продолжить его после перезапуска.
+func (g *Generator) checkpoint(ctx context.Context, state *GenesisState) {
+	if g.checkpoints == nil {
+		return
+	}
+	state.UpdatedAt = time.Now().UTC()
+	if err := g.checkpoints.Save(ctx, state); err != nil {
+		logging.Warnf("Failed to save genesis checkpoint %s: %v", state.Seed, err)
+	}
+}
+
+func (g *Generator) publishFailed(ctx context.Context, state *GenesisState, stage string, cause error) {
+	payload := map[string]interface{}{
+		"genesis_seed": state.Seed,
+		"stage":        stage,
+		"error":        cause.Error(),
+	}
+	if state.BatchID != "" {
+		payload["batch_id"] = state.BatchID
+	}
+	event := eventbus.NewEvent(EventGenesisFailed, "universe-genesis-oracle", state.Seed, payload)
+	if err := g.publish(ctx, event); err != nil {
+		logging.Errorf("Failed to publish %s for %s: %v", EventGenesisFailed, state.Seed, err)
+	}
+}
+
+// runCoreStage генерирует законы и Ядро Вселенной и сохраняет их в архивариусе
+func (g *Generator) runCoreStage(ctx context.Context, state *GenesisState) error {
+	logging.Infof("Generating universe core laws and fundamental principles for seed: %s", state.Seed)
+	coreLaws, universeCore, err := g.generateUniverseCore(ctx, state.Seed, state.Constraints)
+	if err != nil {
+		return fmt.Errorf("failed to generate universe core: %w", err)
+	}
+
+	core, err := json.Marshal(map[string]interface{}{
+		"genesis_seed":  state.Seed,
+		"universe_core": universeCore,
+		"cosmic_laws":   coreLaws,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal universe core: %w", err)
+	}
+	// Это даст путь в OntologicalArchivist: schemas/universe_core/universe_core/latest
+	version, err := g.archivist.PublishSchema(ctx, "universe_core", "universe_core", core)
+	if err != nil {
+		return fmt.Errorf("failed to save universe core: %w", err)
+	}
+
+	state.CosmicLaws, state.UniverseCore = coreLaws, universeCore
+	state.SchemaVersions["universe_core/universe_core"] = version
+	return nil
+}
+
+// runBanProfileStage генерирует онтологический профиль Запрета Вселенной по ядру
+func (g *Generator) runBanProfileStage(ctx context.Context, state *GenesisState) error {
+	profile, err := g.generateUniverseBanProfile(ctx, state.Seed, state.UniverseCore, state.CosmicLaws)
+	if err != nil {
+		return fmt.Errorf("failed to generate universe ban profile: %w", err)
+	}
+	profileJSON, err := json.Marshal(profile)
+	if err != nil {
+		return fmt.Errorf("failed to marshal universe ban profile: %w", err)
+	}
+	// Это даст путь в OntologicalArchivist: schemas/universe_ontology_profile/cosmic_law/latest
+	version, err := g.archivist.PublishSchema(ctx, "universe_ontology_profile", "cosmic_law", profileJSON)
+	if err != nil {
+		return fmt.Errorf("failed to save universe ban profile: %w", err)
+	}
+
+	state.BanProfile = profile
+	state.SchemaVersions["universe_ontology_profile/cosmic_law"] = version
+	return nil
+}
+
+// runEntitySchemasStage создаёт базовые схемы сущностей. Уже сохранённые схемы при повторе
+// пропускаются; этап завершается, только когда сохранены все типы.
+func (g *Generator) runEntitySchemasStage(ctx context.Context, state *GenesisState) error {
+	var failed []string
+	for _, entityType := range genesisEntityTypes {
+		key := "entity/" + entityType
+		if _, done := state.SchemaVersions[key]; done {
+			continue
+		}
+		version, err := g.GenerateEntitySchema(ctx, entityType, state.Seed)
+		if err != nil {
+			logging.Infof("Schema generation warning for %s: %v", entityType, err)
+			failed = append(failed, entityType)
+			continue
+		}
+		state.SchemaVersions[key] = version
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("entity schemas not saved: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// runCompletionStage публикует universe.genesis.completed с Ядром и Законами Вселенной
+func (g *Generator) runCompletionStage(ctx context.Context, state *GenesisState) error {
+	finalEvent := eventbus.NewEvent(EventGenesisCompleted, "universe-genesis-oracle", state.Seed, map[string]interface{}{
+		"genesis_seed":  state.Seed,
+		"universe_core": state.UniverseCore,
+		"cosmic_laws":   state.CosmicLaws,
+	})
+	return g.publish(ctx, finalEvent)
+}
@@ -0,0 +1,168 @@
+package universegenesis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"multiverse-core.io/shared/eventbus"
+	storage "multiverse-core.io/shared/minio"
+)
+
+// memoryCheckpoints хранит контрольные точки в памяти, сериализуя их как MinIO
+type memoryCheckpoints struct {
+	mu     sync.Mutex
+	states map[string][]byte
+}
+
+func (m *memoryCheckpoints) Load(ctx context.Context, seed string) (*GenesisState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.states[seed]
+	if !ok {
+		return nil, fmt.Errorf("genesis %s: %w", seed, storage.ErrNotFound)
+	}
+	var state GenesisState
+	return &state, json.Unmarshal(data, &state)
+}
+
+func (m *memoryCheckpoints) Save(ctx context.Context, state *GenesisState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.states == nil {
+		m.states = make(map[string][]byte)
+	}
+	m.states[state.Seed] = data
+	return nil
+}
+
+func (m *memoryCheckpoints) Pending(ctx context.Context) ([]*GenesisState, error) {
+	m.mu.Lock()
+	seeds := make([]string, 0, len(m.states))
+	for seed := range m.states {
+		seeds = append(seeds, seed)
+	}
+	m.mu.Unlock()
+
+	var pending []*GenesisState
+	for _, seed := range seeds {
+		state, err := m.Load(ctx, seed)
+		if err != nil {
+			return nil, err
+		}
+		if state.Status != GenesisCompleted && state.Status != GenesisAborted {
+			pending = append(pending, state)
+		}
+	}
+	return pending, nil
+}
+
+// testGenerator собирает генератор с этапами-заглушками; этап fail падает, пока failures > 0
+func testGenerator(store CheckpointStore, runs map[string]int, failures *int, published *[]eventbus.Event) *Generator {
+	g := &Generator{
+		checkpoints: store,
+		running:     make(map[string]context.CancelCauseFunc),
+		publish: func(ctx context.Context, event eventbus.Event) error {
+			*published = append(*published, event)
+			return nil
+		},
+	}
+	stage := func(name string) genesisStage {
+		return genesisStage{name, func(ctx context.Context, state *GenesisState) error {
+			runs[name]++
+			if name == StageBanProfile && *failures > 0 {
+				*failures--
+				return errors.New("oracle timeout")
+			}
+			state.SchemaVersions[name] = "1.0"
+			return nil
+		}}
+	}
+	g.stages = []genesisStage{stage(StageCore), stage(StageBanProfile), stage(StageEntitySchemas)}
+	return g
+}
+
+func eventsOfType(events []eventbus.Event, eventType string) []eventbus.Event {
+	var result []eventbus.Event
+	for _, event := range events {
+		if event.Type == eventType {
+			result = append(result, event)
+		}
+	}
+	return result
+}
+
+func TestGenesisPipelineResumesFromFailedStage(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryCheckpoints{}
+	runs := make(map[string]int)
+	failures := 1
+	var published []eventbus.Event
+	g := testGenerator(store, runs, &failures, &published)
+
+	if err := g.StartGenesis(ctx, "seed-1", []string{"no_healing"}); err == nil {
+		t.Fatal("expected the ban profile stage to fail")
+	}
+	state, err := store.Load(ctx, "seed-1")
+	if err != nil {
+		t.Fatalf("expected a checkpoint: %v", err)
+	}
+	if state.Status != GenesisFailed || state.Stages[StageCore].Status != GenesisCompleted || state.Stages[StageBanProfile].Error != "oracle timeout" {
+		t.Fatalf("unexpected checkpoint after failure: %+v", state)
+	}
+	if failed := eventsOfType(published, EventGenesisFailed); len(failed) != 1 || failed[0].Payload["stage"] != StageBanProfile {
+		t.Fatalf("expected %s for the ban profile stage, got %+v", EventGenesisFailed, failed)
+	}
+
+	if err := g.ResumeGenesis(ctx, "seed-1"); err != nil {
+		t.Fatalf("resume failed: %v", err)
+	}
+	if runs[StageCore] != 1 || runs[StageBanProfile] != 2 || runs[StageEntitySchemas] != 1 {
+		t.Errorf("expected only unfinished stages rerun, got %v", runs)
+	}
+	state, _ = store.Load(ctx, "seed-1")
+	if state.Status != GenesisCompleted || state.Stages[StageBanProfile].Attempts != 2 || len(state.Constraints) != 1 {
+		t.Errorf("unexpected final checkpoint: %+v", state)
+	}
+
+	// Завершённый генезис не повторяется
+	if err := g.ResumeGenesis(ctx, "seed-1"); err != nil || runs[StageCore] != 1 {
+		t.Errorf("completed genesis must not rerun, runs %v, err %v", runs, err)
+	}
+}
+
+func TestGenesisResumePending(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryCheckpoints{}
+	runs := make(map[string]int)
+	failures := 2
+	var published []eventbus.Event
+	g := testGenerator(store, runs, &failures, &published)
+
+	g.StartGenesis(ctx, "seed-a", nil)
+	g.StartGenesis(ctx, "seed-b", nil)
+	g.ResumePending(ctx)
+
+	for _, seed := range []string{"seed-a", "seed-b"} {
+		if state, _ := store.Load(ctx, seed); state.Status != GenesisCompleted {
+			t.Errorf("%s: expected completed after resume, got %s", seed, state.Status)
+		}
+	}
+	if pending, _ := store.Pending(ctx); len(pending) != 0 {
+		t.Errorf("expected nothing pending, got %d", len(pending))
+	}
+}
+
+func TestGenesisRejectsConcurrentRun(t *testing.T) {
+	g := &Generator{running: map[string]context.CancelCauseFunc{"seed-1": func(error) {}}}
+	if err := g.StartGenesis(context.Background(), "seed-1", nil); !errors.Is(err, ErrGenesisInProgress) {
+		t.Errorf("expected ErrGenesisInProgress, got %v", err)
+	}
+}
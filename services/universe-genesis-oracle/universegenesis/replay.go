@@ -0,0 +1,137 @@
+// services/universegenesis/replay.go
+package universegenesis
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+	"multiverse-core.io/shared/oracle"
+)
+
+// OracleRecording — ответ Oracle, записанный в детерминированном режиме.
+// PromptHash отличает запись от ответа на изменившийся промпт (другие ограничения или шаблон).
+type OracleRecording struct {
+	Seed       string    `json:"seed"`
+	Call       string    `json:"call"`
+	PromptHash string    `json:"prompt_hash"`
+	Response   string    `json:"response"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// OracleRecorder хранит ответы Oracle по (seed, вызов)
+type OracleRecorder interface {
+	// Load возвращает запись; отсутствующая — storage.ErrNotFound
+	Load(ctx context.Context, seed, call string) (*OracleRecording, error)
+	Save(ctx context.Context, rec *OracleRecording) error
+}
+
+// MinioOracleRecorder хранит записи в бакете genesis рядом с контрольной точкой:
+// {seed}/oracle/{call}.json
+type MinioOracleRecorder struct {
+	client storage.ObjectStorage
+}
+
+// NewMinioOracleRecorder создаёт хранилище записей Oracle в MinIO
+func NewMinioOracleRecorder(client storage.ObjectStorage) *MinioOracleRecorder {
+	return &MinioOracleRecorder{client: client}
+}
+
+func recordingKey(seed, call string) string {
+	return seed + "/oracle/" + call + ".json"
+}
+
+func (m *MinioOracleRecorder) Load(ctx context.Context, seed, call string) (*OracleRecording, error) {
+	data, err := m.client.GetObject(genesisBucket, recordingKey(seed, call))
+	if err != nil {
+		return nil, err
+	}
+	var rec OracleRecording
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("corrupted oracle recording %s/%s: %w", seed, call, err)
+	}
+	return &rec, nil
+}
+
+func (m *MinioOracleRecorder) Save(ctx context.Context, rec *OracleRecording) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return m.client.PutObject(genesisBucket, recordingKey(rec.Seed, rec.Call), bytes.NewReader(data), int64(len(data)))
+}
+
+// promptHash — хэш промптов, по которому сверяется запись
+func promptHash(systemPrompt, userPrompt string) string {
+	sum := sha256.Sum256([]byte(systemPrompt + "\x00" + userPrompt))
+	return hex.EncodeToString(sum[:])
+}
+
+// deterministicSampling фиксирует выборку Oracle; seed модели выводится из seed генезиса и вызова
+func deterministicSampling(seed, call string) oracle.Sampling {
+	h := fnv.New64a()
+	h.Write([]byte(seed + "/" + call))
+	return oracle.Sampling{Temperature: 0, TopP: 1, Seed: int64(h.Sum64() >> 1)}
+}
+
+// callOracle вызывает Oracle и разбирает JSON-ответ в target. Пустой systemPrompt —
+// вызов одним user-сообщением (oracle.Client.Call).
+//
+// В детерминированном режиме выборка фиксирована, а ответ записывается по (seed, call)
+// и при следующем генезисе с тем же seed и теми же промптами воспроизводится без вызова LLM.
+// Записывается только разобранный ответ, чтобы ошибочный ответ не воспроизводился вечно.
+func (g *Generator) callOracle(ctx context.Context, seed, call, systemPrompt, userPrompt string, target interface{}) error {
+	hash := promptHash(systemPrompt, userPrompt)
+	if g.deterministic && g.recordings != nil {
+		rec, err := g.recordings.Load(ctx, seed, call)
+		switch {
+		case err == nil && rec.PromptHash == hash:
+			logging.Infof("Genesis %s: replaying recorded oracle response for %s", seed, call)
+			if err := json.Unmarshal([]byte(rec.Response), target); err == nil {
+				return nil
+			}
+			logging.Infof("Genesis %s: recorded response for %s no longer parses, calling oracle", seed, call)
+		case err == nil:
+			logging.Infof("Genesis %s: prompt for %s changed since recording, calling oracle", seed, call)
+		case !storage.IsNotFound(err):
+			logging.Warnf("Failed to load oracle recording %s/%s: %v", seed, call, err)
+		}
+	}
+
+	client := g.oracle
+	if g.deterministic {
+		client = client.WithSampling(deterministicSampling(seed, call))
+	}
+	release, err := g.acquireOracle(ctx)
+	if err != nil {
+		return err
+	}
+	var response string
+	if systemPrompt == "" {
+		response, err = client.Call(ctx, userPrompt)
+	} else {
+		response, err = client.CallStructured(ctx, systemPrompt, userPrompt)
+	}
+	release()
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal([]byte(response), target); err != nil {
+		return err
+	}
+
+	if g.deterministic && g.recordings != nil {
+		rec := &OracleRecording{Seed: seed, Call: call, PromptHash: hash, Response: response, RecordedAt: time.Now().UTC()}
+		if err := g.recordings.Save(ctx, rec); err != nil {
+			logging.Warnf("Failed to record oracle response %s/%s: %v", seed, call, err)
+		}
+	}
+	return nil
+}
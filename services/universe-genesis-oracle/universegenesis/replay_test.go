@@ -0,0 +1,99 @@
+package universegenesis
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	storage "multiverse-core.io/shared/minio"
+	"multiverse-core.io/shared/oracle"
+)
+
+type memoryRecorder struct {
+	mu      sync.Mutex
+	records map[string]OracleRecording
+}
+
+func (m *memoryRecorder) Load(ctx context.Context, seed, call string) (*OracleRecording, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	rec, ok := m.records[recordingKey(seed, call)]
+	if !ok {
+		return nil, fmt.Errorf("recording %s/%s: %w", seed, call, storage.ErrNotFound)
+	}
+	return &rec, nil
+}
+
+func (m *memoryRecorder) Save(ctx context.Context, rec *OracleRecording) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.records == nil {
+		m.records = make(map[string]OracleRecording)
+	}
+	m.records[recordingKey(rec.Seed, rec.Call)] = *rec
+	return nil
+}
+
+// fakeOracle отвечает {"n": <номер вызова>}, чтобы отличать новый ответ от воспроизведённого
+func fakeOracle(t *testing.T) (*oracle.Client, *int) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"choices": [{"message": {"content": "{\"n\": %d}"}}]}`, calls)
+	}))
+	t.Cleanup(server.Close)
+	return &oracle.Client{BaseURL: server.URL, Model: "test", Client: server.Client()}, &calls
+}
+
+func TestCallOracleReplaysRecordedResponses(t *testing.T) {
+	client, calls := fakeOracle(t)
+	g := &Generator{oracle: client, deterministic: true, recordings: &memoryRecorder{}}
+	ctx := context.Background()
+
+	var first, replayed, changed struct{ N int }
+	g.callOracle(ctx, "seed-1", StageCore, "system", "user", &first)
+	g.callOracle(ctx, "seed-1", StageCore, "system", "user", &replayed)
+	if *calls != 1 || replayed.N != first.N {
+		t.Errorf("expected the second call replayed, got %d oracle calls and %d != %d", *calls, replayed.N, first.N)
+	}
+
+	// Изменившийся промпт (например, другие ограничения) вызывает Oracle заново
+	g.callOracle(ctx, "seed-1", StageCore, "system", "user with constraints", &changed)
+	if *calls != 2 || changed.N != 2 {
+		t.Errorf("expected a fresh oracle call for a changed prompt, got %d calls", *calls)
+	}
+
+	// Другой seed записывается отдельно
+	var other struct{ N int }
+	g.callOracle(ctx, "seed-2", StageCore, "system", "user", &other)
+	if *calls != 3 {
+		t.Errorf("expected a fresh oracle call for another seed, got %d calls", *calls)
+	}
+}
+
+func TestCallOracleWithoutDeterministicMode(t *testing.T) {
+	client, calls := fakeOracle(t)
+	recorder := &memoryRecorder{}
+	g := &Generator{oracle: client, recordings: recorder}
+
+	var result struct{ N int }
+	g.callOracle(context.Background(), "seed-1", StageCore, "system", "user", &result)
+	g.callOracle(context.Background(), "seed-1", StageCore, "system", "user", &result)
+	if *calls != 2 || len(recorder.records) != 0 {
+		t.Errorf("expected every call to reach oracle unrecorded, got %d calls and %d records", *calls, len(recorder.records))
+	}
+}
+
+func TestDeterministicSampling(t *testing.T) {
+	a, b := deterministicSampling("seed-1", StageCore), deterministicSampling("seed-1", StageCore)
+	if a != b || a.Temperature != 0 || a.Seed < 0 {
+		t.Errorf("expected stable pinned sampling, got %+v and %+v", a, b)
+	}
+	if deterministicSampling("seed-1", StageBanProfile).Seed == a.Seed {
+		t.Error("expected different model seeds for different calls")
+	}
+}
@@ -3,10 +3,14 @@ package universegenesis
 
 import (
 	"context"
-	"log"
+	"net"
+	"net/http"
+	"time"
 
 	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
 	"multiverse-core.io/shared/oracle" // <-- Импорт общего клиента
+	"multiverse-core.io/shared/schema"
 
 	"github.com/google/uuid"
 )
@@ -16,20 +20,68 @@ type Service struct {
 	archivist *ArchivistClient
 	oracle    *oracle.Client // <-- Изменён тип
 	generator *Generator
+	server    *http.Server
+
+	// ctx — контекст Run: генезисы, запущенные через HTTP API, не зависят от запроса
+	ctx context.Context
+}
+
+// Config — параметры сервиса
+type Config struct {
+	HTTPPort          string
+	BatchWorkers      int // генезисы пакета, выполняемые одновременно (по умолчанию 4)
+	OracleParallelism int // одновременные вызовы Oracle на все генезисы (по умолчанию 2)
+
+	// Deterministic — воспроизводимый генезис: фиксированная выборка Oracle, ответы
+	// записываются в Recordings и повторно используются для того же seed
+	Deterministic bool
+	Recordings    OracleRecorder
+
+	// SchemaAttempts — попытки генерации схемы сущности, прошедшей проверку Draft 7 (по умолчанию 3)
+	SchemaAttempts int
 }
 
-func NewService(bus *eventbus.EventBus, archivist *ArchivistClient) *Service { // <-- Принимаем URL, а не готовый клиент
+// NewService создаёт сервис; checkpoints хранит контрольные точки генезиса (nil — без возобновления)
+func NewService(bus *eventbus.EventBus, archivist *ArchivistClient, checkpoints CheckpointStore, cfg Config) *Service {
 	oracleClient := oracle.NewClient() // <-- Создаём общий клиент
-	return &Service{
+	// Форматы entity_id и event_id проверяются при проверке сгенерированных схем
+	schema.RegisterCustomFormats()
+	s := &Service{
 		bus:       bus,
 		archivist: archivist,
-		oracle:    oracleClient,                               // <-- Передаём общий клиент
-		generator: NewGenerator(bus, archivist, oracleClient), // <-- Передаём общий клиент
+		oracle:    oracleClient,                                                 // <-- Передаём общий клиент
+		generator: NewGenerator(bus, archivist, oracleClient, checkpoints, cfg), // <-- Передаём общий клиент
+		ctx:       context.Background(),
 	}
+
+	port := cfg.HTTPPort
+	if port == "" {
+		port = "8086"
+	}
+	s.server = &http.Server{
+		Addr:         ":" + port,
+		Handler:      s.routes(),
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	}
+	return s
 }
 
 func (s *Service) Run(ctx context.Context) error {
-	log.Println("UniverseGenesisOracle starting and waiting for genesis requests...")
+	logging.Infof("UniverseGenesisOracle starting and waiting for genesis requests...")
+
+	// Генезисы, прерванные перезапуском, продолжаются с последнего завершённого этапа
+	go s.generator.ResumePending(ctx)
+
+	// HTTP API запускает генезисы и пакеты в контексте сервиса, а не запроса
+	s.ctx = ctx
+	s.server.BaseContext = func(net.Listener) context.Context { return ctx }
+	go func() {
+		logging.Infof("UniverseGenesisOracle HTTP API listening on %s", s.server.Addr)
+		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logging.Errorf("UniverseGenesisOracle HTTP server failed: %v", err)
+		}
+	}()
 
 	// Подписка на системный топик для получения запросов на генерацию вселенной
 	s.bus.Subscribe(ctx, eventbus.TopicSystemEvents, "universe-genesis-oracle", func(event eventbus.Event) {
@@ -38,32 +90,100 @@ func (s *Service) Run(ctx context.Context) error {
 
 	// Сервис работает постоянно, обрабатывая события
 	<-ctx.Done()
-	log.Println("UniverseGenesisOracle shutting down...")
+	logging.Infof("UniverseGenesisOracle shutting down...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	s.server.Shutdown(shutdownCtx)
 	return nil
 }
 
 // handleSystemEvent обрабатывает события из системного топика
 func (s *Service) handleSystemEvent(ctx context.Context, event eventbus.Event) {
-	log.Printf("Received system event: %s from source: %s", event.Type, event.Source)
+	logging.Infof("Received system event: %s from source: %s", event.Type, event.Source)
 
-	// Обработка запроса на генерацию вселенной
-	if event.Type == "universe.genesis.request" {
+	switch event.Type {
+	case EventGenesisRequest:
+		// Обработка запроса на генерацию вселенной
 		seed := eventbus.GetWorldIDFromEvent(event) // Используем WorldID как seed для простоты
 		if seed == "" {
 			seed = uuid.New().String()
 		}
+		constraints := stringList(event.Payload["constraints"])
 
-		constraints, ok := event.Payload["constraints"].([]string)
-		if !ok {
-			constraints = []string{}
-		}
-
-		log.Printf("Processing universe genesis request with seed: %s", seed)
+		logging.Infof("Processing universe genesis request with seed: %s", seed)
 		if err := s.generator.StartGenesis(ctx, seed, constraints); err != nil {
-			log.Printf("Universe Genesis failed: %v", err)
-			// Можно опубликовать событие об ошибке
+			logging.Errorf("Universe Genesis failed: %v", err)
 		} else {
-			log.Printf("Universe Genesis completed for seed: %s", seed)
+			logging.Infof("Universe Genesis completed for seed: %s", seed)
+		}
+
+	case EventGenesisResume:
+		// Продолжение прерванного генезиса: seed — world_id или payload.genesis_seed
+		seed := eventbus.GetWorldIDFromEvent(event)
+		if seed == "" {
+			seed, _ = event.Payload["genesis_seed"].(string)
+		}
+		if seed == "" {
+			logging.Warnf("Ignoring %s without seed", event.Type)
+			return
+		}
+		if err := s.generator.ResumeGenesis(ctx, seed); err != nil {
+			logging.Errorf("Universe Genesis resume failed: %v", err)
+		}
+
+	case EventGenesisBatchRequested:
+		// Пакет выполняется в фоне, чтобы не задерживать чтение system_events
+		if _, err := s.StartBatch(ctx, batchFromEvent(event)); err != nil {
+			logging.Warnf("Ignoring genesis batch from %s: %v", event.Source, err)
+		}
+	}
+}
+
+// StartGenesis запускает генезис в фоне и возвращает его seed (пустой seed генерируется).
+// Генезис, который уже выполняется, отклоняется с ErrGenesisInProgress.
+func (s *Service) StartGenesis(ctx context.Context, seed string, constraints []string) (string, error) {
+	if seed == "" {
+		seed = uuid.New().String()
+	}
+	runCtx, release, err := s.generator.begin(ctx, seed)
+	if err != nil {
+		return "", err
+	}
+	go func() {
+		defer release()
+		if err := s.generator.execute(runCtx, NewGenesisState(seed, constraints)); err != nil {
+			logging.Errorf("Universe Genesis %s failed: %v", seed, err)
+		}
+	}()
+	return seed, nil
+}
+
+// StartBatch проверяет пакет и запускает его в фоне; возвращает пакет с заполненными batch_id и seed
+func (s *Service) StartBatch(ctx context.Context, req BatchRequest) (BatchRequest, error) {
+	if err := req.normalize(); err != nil {
+		return req, err
+	}
+	go func() {
+		if _, err := s.generator.RunBatch(ctx, req); err != nil {
+			logging.Errorf("Genesis batch %s failed: %v", req.BatchID, err)
+		}
+	}()
+	return req, nil
+}
+
+// stringList приводит значение payload к []string (после JSON это []interface{})
+func stringList(value interface{}) []string {
+	switch v := value.(type) {
+	case []string:
+		return v
+	case []interface{}:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			if str, ok := item.(string); ok {
+				result = append(result, str)
+			}
 		}
+		return result
 	}
+	return []string{}
 }
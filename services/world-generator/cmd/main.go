@@ -2,43 +2,30 @@
 package main
 
 import (
-	"context"
-	"log"
-	"os"
-	"os/signal"
-	"strings"
-	"syscall"
-
-	"multiverse-core.io/shared/eventbus"
 	"multiverse-core.io/services/world-generator/worldgenerator"
+	"multiverse-core.io/shared/config"
+	"multiverse-core.io/shared/logging"
+	"multiverse-core.io/shared/service"
 )
 
 func main() {
-	// Initialize event bus
-	brokers := strings.Split(os.Getenv("KAFKA_BROKERS"), ",")
-	if len(brokers) == 0 || brokers[0] == "" {
-		brokers = []string{"redpanda:9092"}
-	}
-	bus := eventbus.NewEventBus(brokers)
-
-	// Create and run service
-	service := worldgenerator.NewService(bus)
+	app := service.Setup("world-generator", config.OracleOptions, []config.Option{
+		{Env: "ARCHIVIST_URL", Type: config.TypeURL, Usage: "fallback archivist address"},
+		{Env: "WORLD_NPCS_PER_CITY", Default: "5", Type: config.TypeInt, Usage: "NPCs seeded in each generated city (0 disables seeding)"},
+	})
+	env := app.Env
+	app.UseOracleAudit()
 
-	// Handle shutdown
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	generator := worldgenerator.NewService(app.Bus())
+	generator.SetNPCsPerCity(env.Int("WORLD_NPCS_PER_CITY"))
 
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	go func() {
-		<-sigChan
-		log.Println("Shutting down WorldGenerator...")
-		cancel()
-	}()
-
-	log.Println("WorldGenerator starting...")
-	if err := service.Run(ctx); err != nil && err != context.Canceled {
-		log.Fatal("Service failed:", err)
+	// Procedural tile maps are stored in MinIO; without it worlds are generated without a map
+	minioClient, err := app.MinIO()
+	if err != nil {
+		logging.Warnf("MinIO unavailable, tile maps and world expansion disabled: %v", err)
+	} else {
+		generator.UseMapStorage(minioClient)
 	}
-	log.Println("WorldGenerator stopped.")
+
+	app.Run(generator)
 }
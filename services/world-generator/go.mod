@@ -6,4 +6,5 @@ require (
 	github.com/segmentio/kafka-go v0.4.49
 	github.com/google/uuid v1.6.0
 	github.com/xeipuuv/gojsonschema v1.2.0
+	gopkg.in/yaml.v3 v3.0.1
 )
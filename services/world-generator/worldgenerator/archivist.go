@@ -7,56 +7,89 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"os"
 	"time"
+
+	"multiverse-core.io/shared/logging"
+	"multiverse-core.io/shared/registry"
 )
 
 // ArchivistClient communicates with OntologicalArchivist.
 type ArchivistClient struct {
-	BaseURL string
+	// BaseURL is the static fallback used when the registry has no live archivist.
+	BaseURL   string
+	discovery *registry.Discovery
+}
+
+// PublishSchemaRequest represents a request to publish a new schema version.
+// An empty Version lets the archivist bump the latest one.
+type PublishSchemaRequest struct {
+	Version string          `json:"version,omitempty"`
+	Schema  json.RawMessage `json:"schema"`
 }
 
-// SchemaRequest represents a schema save request.
-type SchemaRequest struct {
-	SchemaType string          `json:"schema_type"`
-	Name       string          `json:"name"`
-	Version    string          `json:"version"`
-	Schema     json.RawMessage `json:"schema"`
+// PublishSchemaResponse is returned by the archivist on publish.
+// Created is false when the schema equals the latest version and nothing was stored.
+type PublishSchemaResponse struct {
+	Schema struct {
+		Version string `json:"version"`
+	} `json:"schema"`
+	Created bool `json:"created"`
 }
 
 // NewArchivistClient creates a new ArchivistClient.
-func NewArchivistClient() *ArchivistClient {
+// The archivist address is resolved through discovery; ARCHIVIST_URL is kept as fallback.
+func NewArchivistClient(discovery *registry.Discovery) *ArchivistClient {
 	url := os.Getenv("ARCHIVIST_URL")
 	if url == "" {
 		url = "http://ontological-archivist:8081"
 	}
-	return &ArchivistClient{BaseURL: url}
+	if discovery != nil {
+		discovery.SetFallback(registry.ServiceArchivist, url)
+	}
+	return &ArchivistClient{BaseURL: url, discovery: discovery}
 }
 
-// SaveSchema saves a schema to OntologicalArchivist.
-func (ac *ArchivistClient) SaveSchema(ctx context.Context, schemaType, name, version string, schemaData []byte) error {
-	reqBody, _ := json.Marshal(SchemaRequest{
-		SchemaType: schemaType,
-		Name:       name,
-		Version:    version,
-		Schema:     schemaData,
-	})
-
-	log.Println(ac.BaseURL + "/v1/schemas")
-	log.Println(string(reqBody))
+// baseURL returns the address of a live archivist instance.
+func (ac *ArchivistClient) baseURL(ctx context.Context) string {
+	if ac.discovery == nil {
+		return ac.BaseURL
+	}
+	url, err := ac.discovery.Resolve(ctx, registry.ServiceArchivist)
+	if err != nil {
+		return ac.BaseURL
+	}
+	return url
+}
+
+// PublishSchema publishes a schema as a new version in OntologicalArchivist
+// and returns the version the schema is available under.
+func (ac *ArchivistClient) PublishSchema(ctx context.Context, schemaType, name string, schemaData []byte) (string, error) {
+	reqBody, _ := json.Marshal(PublishSchemaRequest{Schema: schemaData})
+
+	baseURL := ac.baseURL(ctx)
+	url := fmt.Sprintf("%s/v1/schemas/%s/%s/versions", baseURL, schemaType, name)
 	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Post(ac.BaseURL+"/v1/schemas", "application/json", bytes.NewBuffer(reqBody))
+	resp, err := client.Post(url, "application/json", bytes.NewBuffer(reqBody))
 	if err != nil {
-		return fmt.Errorf("archivist connection failed: %w", err)
+		if ac.discovery != nil {
+			ac.discovery.MarkFailed(registry.ServiceArchivist, baseURL)
+		}
+		return "", fmt.Errorf("archivist connection failed: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != 201 {
+	// 201 means a new version, 200 means the schema is unchanged
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("archivist returned status %d: %s", resp.StatusCode, string(body))
+		return "", fmt.Errorf("archivist returned status %d: %s", resp.StatusCode, string(body))
 	}
 
-	return nil
+	var result PublishSchemaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("archivist response decode failed: %w", err)
+	}
+	logging.Infof("Schema %s/%s at version %s (new: %v)", schemaType, name, result.Schema.Version, result.Created)
+	return result.Schema.Version, nil
 }
@@ -0,0 +1,146 @@
+// Package worldgenerator handles Dao compatibility matrices of cultivation worlds.
+package worldgenerator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"multiverse-core.io/shared/logging"
+)
+
+// DaoCompatibilitySchemaType — тип схемы матрицы совместимости Дао в OntologicalArchivist (имя — ID мира).
+// Матрицу читает CultivationModule.
+const DaoCompatibilitySchemaType = "dao_compatibility_matrix"
+
+// Отношения между путями Дао
+const (
+	DaoHarmonize = "harmonize" // пути гармонируют: любое взаимодействие успешно
+	DaoAbsorb    = "absorb"    // source может поглотить target; слияние и гармонизация конфликтуют
+	DaoConflict  = "conflict"  // любое взаимодействие — конфликт
+)
+
+// DaoCompatibility — отношение пары путей; harmonize и conflict симметричны, absorb направлен от source к target
+type DaoCompatibility struct {
+	Source   string `json:"source"`
+	Target   string `json:"target"`
+	Relation string `json:"relation"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// DaoCompatibilityMatrix — матрица совместимости путей развития мира
+type DaoCompatibilityMatrix struct {
+	Paths []string           `json:"paths"`
+	Pairs []DaoCompatibility `json:"pairs"`
+}
+
+// buildDaoMatrixPrompts формирует system и user промпты матрицы совместимости путей мира
+func buildDaoMatrixPrompts(concept *WorldConcept, ontology WorldOntology) (systemPrompt, userPrompt string) {
+	systemPrompt = fmt.Sprintf(`Ты — Демиург, устанавливающий законы взаимодействия путей силы.
+
+Концепция мира:
+- Ядро: %s
+- Тема: %s
+
+Онтология мира:
+- Система: %s
+- Носители силы: %s
+- Запреты: %s
+
+Отношения путей должны следовать из природы мира и его запретов.
+
+Отвечай строго в формате JSON без пояснений.`,
+		concept.Core,
+		concept.Theme,
+		ontology.System,
+		strings.Join(ontology.Carriers, ", "),
+		strings.Join(ontology.Forbidden, ", "),
+	)
+
+	userPrompt = fmt.Sprintf(`Пути развития мира: %s
+
+Для каждой пары путей определи отношение:
+- harmonize — пути гармонируют, их можно объединять и гармонизировать
+- absorb — путь source может поглотить путь target (направленное отношение)
+- conflict — пути враждебны, любое взаимодействие приводит к конфликту
+
+Используй только названия путей из списка, без пар пути с самим собой.
+
+Формат JSON:
+{
+  "pairs": [{"source": "string", "target": "string", "relation": "harmonize|absorb|conflict", "reason": "string"}]
+}`, strings.Join(ontology.Paths, ", "))
+
+	return systemPrompt, userPrompt
+}
+
+// sanitizeDaoMatrix оставляет пары известных путей с допустимым отношением, без повторов и пар пути с самим собой.
+// Названия путей приводятся к написанию из онтологии.
+func sanitizeDaoMatrix(pairs []DaoCompatibility, paths []string) DaoCompatibilityMatrix {
+	canonical := make(map[string]string, len(paths))
+	for _, path := range paths {
+		canonical[strings.ToLower(strings.TrimSpace(path))] = path
+	}
+
+	matrix := DaoCompatibilityMatrix{Paths: paths, Pairs: []DaoCompatibility{}}
+	seen := make(map[string]bool, len(pairs))
+	for _, pair := range pairs {
+		source, okSource := canonical[strings.ToLower(strings.TrimSpace(pair.Source))]
+		target, okTarget := canonical[strings.ToLower(strings.TrimSpace(pair.Target))]
+		relation := strings.ToLower(strings.TrimSpace(pair.Relation))
+		if !okSource || !okTarget || source == target {
+			continue
+		}
+		if relation != DaoHarmonize && relation != DaoAbsorb && relation != DaoConflict {
+			continue
+		}
+		// Симметричные отношения задаются одной парой независимо от порядка
+		key := source + "\x00" + target
+		if relation != DaoAbsorb && target < source {
+			key = target + "\x00" + source
+		}
+		if seen[key+"\x00"+relation] {
+			continue
+		}
+		seen[key+"\x00"+relation] = true
+		matrix.Pairs = append(matrix.Pairs, DaoCompatibility{Source: source, Target: target, Relation: relation, Reason: pair.Reason})
+	}
+	return matrix
+}
+
+// generateDaoMatrix генерирует матрицу совместимости путей мира и сохраняет её в OntologicalArchivist.
+// Ошибки не прерывают генерацию мира: без матрицы CultivationModule применяет встроенные правила.
+func (wg *WorldGenerator) generateDaoMatrix(ctx context.Context, worldID string, concept *WorldConcept, ontology WorldOntology) {
+	if len(ontology.Paths) < 2 {
+		return
+	}
+	systemPrompt, userPrompt := buildDaoMatrixPrompts(concept, ontology)
+
+	var response struct {
+		Pairs []DaoCompatibility `json:"pairs"`
+	}
+	err := wg.oracle.CallAndUnmarshal(ctx, func() (string, error) {
+		return wg.oracle.CallStructuredJSON(ctx, systemPrompt, userPrompt)
+	}, &response)
+	if err != nil {
+		logging.Infof("Dao compatibility matrix of %s not generated: %v", worldID, err)
+		return
+	}
+
+	matrix := sanitizeDaoMatrix(response.Pairs, ontology.Paths)
+	if len(matrix.Pairs) == 0 {
+		logging.Infof("Dao compatibility matrix of %s has no valid pairs, skipped", worldID)
+		return
+	}
+	data, err := json.Marshal(matrix)
+	if err != nil {
+		logging.Errorf("Dao compatibility matrix of %s marshal failed: %v", worldID, err)
+		return
+	}
+	if _, err := wg.archivist.PublishSchema(ctx, DaoCompatibilitySchemaType, worldID, data); err != nil {
+		logging.Infof("Dao compatibility matrix of %s not saved: %v", worldID, err)
+		return
+	}
+	logging.Infof("Dao compatibility matrix of %s saved: %d pairs", worldID, len(matrix.Pairs))
+}
@@ -0,0 +1,45 @@
+package worldgenerator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test sanitizeDaoMatrix — неизвестные пути, отношения и повторы отбрасываются, названия приводятся к онтологии
+func TestSanitizeDaoMatrix(t *testing.T) {
+	paths := []string{"Путь Пламени", "Путь Льда", "Путь Пустоты"}
+	pairs := []DaoCompatibility{
+		{Source: "путь пламени ", Target: "Путь Льда", Relation: "Conflict"},
+		{Source: "Путь Льда", Target: "Путь Пламени", Relation: "conflict"},
+		{Source: "Путь Пустоты", Target: "Путь Пламени", Relation: "absorb"},
+		{Source: "Путь Пламени", Target: "Путь Пустоты", Relation: "absorb"},
+		{Source: "Путь Пустоты", Target: "Путь Пустоты", Relation: "harmonize"},
+		{Source: "Путь Грома", Target: "Путь Льда", Relation: "harmonize"},
+		{Source: "Путь Льда", Target: "Путь Пустоты", Relation: "friendship"},
+	}
+
+	matrix := sanitizeDaoMatrix(pairs, paths)
+
+	assert.Equal(t, paths, matrix.Paths)
+	assert.Equal(t, []DaoCompatibility{
+		{Source: "Путь Пламени", Target: "Путь Льда", Relation: DaoConflict},
+		{Source: "Путь Пустоты", Target: "Путь Пламени", Relation: DaoAbsorb},
+		{Source: "Путь Пламени", Target: "Путь Пустоты", Relation: DaoAbsorb},
+	}, matrix.Pairs)
+	assert.Empty(t, sanitizeDaoMatrix(nil, paths).Pairs)
+}
+
+// Test buildDaoMatrixPrompts — промпт содержит пути, запреты и формат ответа
+func TestBuildDaoMatrixPrompts(t *testing.T) {
+	concept := &WorldConcept{Core: "Мир вечной зимы", Theme: "cultivation"}
+	ontology := WorldOntology{System: "cultivation", Paths: []string{"Путь Льда", "Путь Пламени"}, Forbidden: []string{"Растопить вечный лёд"}}
+
+	system, user := buildDaoMatrixPrompts(concept, ontology)
+
+	assert.True(t, strings.Contains(system, "Мир вечной зимы"))
+	assert.True(t, strings.Contains(system, "Растопить вечный лёд"))
+	assert.True(t, strings.Contains(user, "Пути развития мира: Путь Льда, Путь Пламени"))
+	assert.True(t, strings.Contains(user, `"relation": "harmonize|absorb|conflict"`))
+}
@@ -0,0 +1,390 @@
+// Package worldgenerator handles expansion of existing worlds with new regions.
+package worldgenerator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+)
+
+// Расширение мира по запросу: к краю карты пристраивается один новый регион,
+// согласованный с концепцией, онтологией и соседними регионами.
+
+const (
+	// expansionDepth — на сколько единиц координат расширяется мир; новый регион занимает всю полосу
+	expansionDepth = 40.0
+
+	maxExpansionCities = 3
+	maxExpansionWaters = 2
+)
+
+// Направления расширения. Ось Y направлена на юг (строка 0 карты — север).
+const (
+	DirectionNorth = "north"
+	DirectionSouth = "south"
+	DirectionEast  = "east"
+	DirectionWest  = "west"
+)
+
+var directionNames = map[string]string{
+	DirectionNorth: "север",
+	DirectionSouth: "юг",
+	DirectionEast:  "восток",
+	DirectionWest:  "запад",
+}
+
+// RegionExpansionRequest — payload события world.region.expansion.requested
+type RegionExpansionRequest struct {
+	WorldID   string `json:"world_id"`
+	Direction string `json:"direction,omitempty"` // north|south|east|west; по умолчанию — край, ближайший к near
+	Near      *Point `json:"near,omitempty"`      // точка, у которой игроки достигли края карты
+}
+
+// RegionExpansion — ответ Oracle: новый регион, его водоёмы и города (координаты расставляет генератор)
+type RegionExpansion struct {
+	Region      Region      `json:"region"`
+	WaterBodies []WaterBody `json:"water_bodies"`
+	Cities      []City      `json:"cities"`
+}
+
+// parseExpansionRequest парсит запрос расширения; ID мира берётся из события или из payload
+func parseExpansionRequest(ev eventbus.Event) (*RegionExpansionRequest, error) {
+	data, err := json.Marshal(ev.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	var request RegionExpansionRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+	}
+	if worldID := eventbus.GetWorldIDFromEvent(ev); worldID != "" {
+		request.WorldID = worldID
+	}
+	if request.WorldID == "" {
+		return nil, fmt.Errorf("world_id is required")
+	}
+	request.Direction = strings.ToLower(request.Direction)
+	if _, ok := directionNames[request.Direction]; request.Direction != "" && !ok {
+		return nil, fmt.Errorf("unknown direction %q", request.Direction)
+	}
+	return &request, nil
+}
+
+// chooseDirection выбирает край мира: заданный явно, ближайший к near или восток
+func chooseDirection(bounds WorldBounds, direction string, near *Point) string {
+	if direction != "" {
+		return direction
+	}
+	if near == nil {
+		return DirectionEast
+	}
+	best, bestDist := DirectionEast, bounds.MaxX-near.X
+	for _, edge := range []struct {
+		direction string
+		dist      float64
+	}{
+		{DirectionWest, near.X - bounds.MinX},
+		{DirectionNorth, near.Y - bounds.MinY},
+		{DirectionSouth, bounds.MaxY - near.Y},
+	} {
+		if edge.dist < bestDist {
+			best, bestDist = edge.direction, edge.dist
+		}
+	}
+	return best
+}
+
+// expandBounds расширяет границы мира на depth в направлении direction и возвращает
+// центр полосы для нового региона: напротив near, если она задана, иначе посередине края
+func expandBounds(bounds WorldBounds, direction string, depth float64, near *Point) (WorldBounds, Point) {
+	half := depth / 2
+	along := func(value, min, max float64, useNear bool) float64 {
+		if !useNear || max-min < depth {
+			return (min + max) / 2
+		}
+		return math.Min(math.Max(value, min+half), max-half)
+	}
+	var nearX, nearY float64
+	if near != nil {
+		nearX, nearY = near.X, near.Y
+	}
+
+	expanded := bounds
+	var center Point
+	switch direction {
+	case DirectionNorth:
+		expanded.MinY -= depth
+		center = Point{X: along(nearX, bounds.MinX, bounds.MaxX, near != nil), Y: bounds.MinY - half}
+	case DirectionSouth:
+		expanded.MaxY += depth
+		center = Point{X: along(nearX, bounds.MinX, bounds.MaxX, near != nil), Y: bounds.MaxY + half}
+	case DirectionWest:
+		expanded.MinX -= depth
+		center = Point{X: bounds.MinX - half, Y: along(nearY, bounds.MinY, bounds.MaxY, near != nil)}
+	default: // DirectionEast
+		expanded.MaxX += depth
+		center = Point{X: bounds.MaxX + half, Y: along(nearY, bounds.MinY, bounds.MaxY, near != nil)}
+	}
+	return expanded, center
+}
+
+// neighborRegions возвращает регионы, граничащие с region; если таких нет — ближайший
+func neighborRegions(region Region, regions []Region) []Region {
+	var neighbors []Region
+	for _, r := range regions {
+		if adjacent(region, r) {
+			neighbors = append(neighbors, r)
+		}
+	}
+	if len(neighbors) == 0 && len(regions) > 0 {
+		nearest := regions[0]
+		for _, r := range regions[1:] {
+			if distance(region.Coordinates, r.Coordinates)-radius(r.Size) < distance(region.Coordinates, nearest.Coordinates)-radius(nearest.Size) {
+				nearest = r
+			}
+		}
+		neighbors = append(neighbors, nearest)
+	}
+	return neighbors
+}
+
+// uniqueRegionName добавляет номер к имени, уже занятому другим регионом
+func uniqueRegionName(name string, regions []Region) string {
+	taken := func(candidate string) bool {
+		for _, r := range regions {
+			if strings.EqualFold(r.Name, candidate) {
+				return true
+			}
+		}
+		return false
+	}
+	candidate := name
+	for n := 2; taken(candidate); n++ {
+		candidate = fmt.Sprintf("%s %d", name, n)
+	}
+	return candidate
+}
+
+// placeExpansion расставляет регион из ответа Oracle в центре полосы расширения,
+// водоёмы и города — внутри него. Лишние водоёмы и города отбрасываются.
+func placeExpansion(exp *RegionExpansion, center Point, depth float64, regions []Region) Geography {
+	r := depth / 2
+	region := exp.Region
+	region.Name = uniqueRegionName(region.Name, regions)
+	region.Coordinates = center
+	region.Size = math.Pi * r * r
+
+	geo := Geography{Regions: []Region{region}}
+	for i, w := range exp.WaterBodies {
+		if i == maxExpansionWaters {
+			break
+		}
+		angle := math.Pi/4 + float64(i)*math.Pi
+		w.Coordinates = Point{X: center.X + math.Cos(angle)*r/2, Y: center.Y + math.Sin(angle)*r/2}
+		w.Size = math.Pi * (r / 4) * (r / 4)
+		geo.WaterBodies = append(geo.WaterBodies, w)
+	}
+	for i, c := range exp.Cities {
+		if i == maxExpansionCities {
+			break
+		}
+		// Первый город — в центре региона, остальные — на кольце вокруг
+		c.Location = Location{Region: region.Name, Coordinates: center}
+		if i > 0 {
+			angle := 3*math.Pi/4 + float64(i-1)*math.Pi
+			c.Location.Coordinates = Point{X: center.X + math.Cos(angle)*r/2, Y: center.Y + math.Sin(angle)*r/2}
+		}
+		geo.Cities = append(geo.Cities, c)
+	}
+	return geo
+}
+
+// buildExpansionPrompts формирует system и user промпты для нового региона на краю мира
+func buildExpansionPrompts(record *WorldRecord, direction string, neighbors []Region) (systemPrompt, userPrompt string) {
+	systemPrompt = fmt.Sprintf(`Ты — Демиург, расширяющий уже существующий мир.
+
+Концепция мира:
+- Ядро: %s
+- Тема: %s
+- Эпоха: %s
+- Уникальные черты: %s
+
+Онтология мира:
+- Система: %s
+- Носители силы: %s
+- Пути развития: %s
+- Запреты: %s
+
+Новые земли должны быть согласованы с концепцией, онтологией и соседними регионами.
+
+Отвечай строго в формате JSON без пояснений.`,
+		record.Concept.Core,
+		record.Concept.Theme,
+		record.Concept.Era,
+		strings.Join(record.Concept.UniqueTraits, "; "),
+		record.Ontology.System,
+		strings.Join(record.Ontology.Carriers, ", "),
+		strings.Join(record.Ontology.Paths, ", "),
+		strings.Join(record.Ontology.Forbidden, ", "),
+	)
+
+	neighborLines := make([]string, 0, len(neighbors))
+	for _, n := range neighbors {
+		neighborLines = append(neighborLines, fmt.Sprintf("- %s (биом: %s)", n.Name, n.Biome))
+	}
+	existing := make([]string, 0, len(record.Geography.Regions))
+	for _, r := range record.Geography.Regions {
+		existing = append(existing, r.Name)
+	}
+
+	userPrompt = fmt.Sprintf(`Путешественники достигли края мира на направлении «%s». Опиши новый регион за этим краем.
+
+Новый регион граничит с:
+%s
+
+Уже существующие регионы (не повторяй их названия): %s
+
+Требования:
+- один регион с уникальным названием и биомом, естественно продолжающим соседние земли
+- 0-%d водных объектов (river|sea|lake)
+- 1-%d городов с населением и типом (major|minor)
+
+Формат JSON:
+{
+  "region": {"name": "string", "biome": "string"},
+  "water_bodies": [{"name": "string", "type": "river|sea|lake"}],
+  "cities": [{"name": "string", "population": 0, "type": "major|minor"}]
+}`,
+		directionNames[direction],
+		strings.Join(neighborLines, "\n"),
+		strings.Join(existing, ", "),
+		maxExpansionWaters,
+		maxExpansionCities,
+	)
+
+	return systemPrompt, userPrompt
+}
+
+// generateExpansion запрашивает у Oracle содержимое нового региона
+func (wg *WorldGenerator) generateExpansion(ctx context.Context, record *WorldRecord, direction string, neighbors []Region) (*RegionExpansion, error) {
+	systemPrompt, userPrompt := buildExpansionPrompts(record, direction, neighbors)
+
+	var expansion RegionExpansion
+	err := wg.oracle.CallAndUnmarshal(ctx, func() (string, error) {
+		return wg.oracle.CallStructuredJSON(ctx, systemPrompt, userPrompt)
+	}, &expansion)
+	if err != nil {
+		return nil, fmt.Errorf("region expansion generation failed: %w", err)
+	}
+	if strings.TrimSpace(expansion.Region.Name) == "" {
+		return nil, fmt.Errorf("region expansion generation failed: oracle returned a region without name")
+	}
+	return &expansion, nil
+}
+
+// expandRegion пристраивает к миру новый регион по событию world.region.expansion.requested
+func (wg *WorldGenerator) expandRegion(ev eventbus.Event) {
+	ctx := context.Background()
+
+	// 1. Парсинг запроса и загрузка состояния мира
+	request, err := parseExpansionRequest(ev)
+	if err != nil {
+		logging.Warnf("Invalid region expansion request: %v", err)
+		return
+	}
+	if wg.maps == nil {
+		logging.Infof("Region expansion for world %s skipped: world storage is not configured", request.WorldID)
+		return
+	}
+	record, err := wg.loadWorld(ctx, request.WorldID)
+	if storage.IsNotFound(err) {
+		logging.Warnf("Region expansion for world %s skipped: world record not found", request.WorldID)
+		return
+	}
+	if err != nil {
+		logging.Errorf("Failed to load world %s for expansion: %v", request.WorldID, err)
+		return
+	}
+
+	// 2. Полоса расширения и соседи нового региона
+	direction := chooseDirection(record.Bounds, request.Direction, request.Near)
+	bounds, center := expandBounds(record.Bounds, direction, expansionDepth, request.Near)
+	placeholder := Region{Coordinates: center, Size: math.Pi * expansionDepth * expansionDepth / 4}
+	neighbors := neighborRegions(placeholder, record.Geography.Regions)
+
+	logging.Infof("Expanding world %s to the %s", request.WorldID, direction)
+
+	// 3. Содержимое региона от Oracle
+	expansion, err := wg.generateExpansion(ctx, record, direction, neighbors)
+	if err != nil {
+		logging.Errorf("World %s expansion failed: %v", request.WorldID, err)
+		return
+	}
+	added := placeExpansion(expansion, center, expansionDepth, record.Geography.Regions)
+
+	// 4. Проверка согласованности вместе с существующей географией: новые объекты описаны последними,
+	// поэтому при перекрытиях сдвигаются именно они
+	geo := Geography{
+		Regions:     append(append([]Region{}, record.Geography.Regions...), added.Regions...),
+		WaterBodies: append(append([]WaterBody{}, record.Geography.WaterBodies...), added.WaterBodies...),
+		Cities:      append(append([]City{}, record.Geography.Cities...), added.Cities...),
+	}
+	if fixes := ValidateGeography(&geo, bounds); len(fixes) > 0 {
+		wg.publishGeographyCorrected(ctx, request.WorldID, fixes)
+	}
+	region := geo.Regions[len(geo.Regions)-1]
+	waters := geo.WaterBodies[len(record.Geography.WaterBodies):]
+	cities := geo.Cities[len(record.Geography.Cities):]
+
+	// 5. Сущности нового региона: связи с миром и соседями (ADJACENT_TO) индексирует SemanticMemory
+	var neighborIDs []string
+	for _, n := range neighborRegions(region, record.Geography.Regions) {
+		if id := record.RegionIDs[n.Name]; id != "" {
+			neighborIDs = append(neighborIDs, id)
+		}
+	}
+	regionID := wg.createRegionEntity(ctx, request.WorldID, region, neighborIDs)
+	for _, water := range waters {
+		wg.createWaterEntity(ctx, request.WorldID, water)
+	}
+	for _, city := range cities {
+		cityID := wg.createCityEntity(ctx, request.WorldID, city, regionID)
+		wg.seedCityNPCs(ctx, request.WorldID, cityID, city, &record.Concept, record.Ontology)
+	}
+
+	// 6. Сохранение состояния и перестроение карты в новых границах
+	record.Geography = geo
+	record.Bounds = bounds
+	record.RegionIDs[region.Name] = regionID
+	if err := wg.saveWorld(ctx, record); err != nil {
+		logging.Infof("World %s expanded, but its record was not saved: %v", request.WorldID, err)
+	}
+	wg.generateTileMap(ctx, request.WorldID, record.Seed, geo, bounds, record.Scale)
+
+	// 7. Финальное событие
+	wg.publishRegionExpanded(ctx, request.WorldID, regionID, region, direction, bounds, neighborIDs, len(cities))
+}
+
+// publishRegionExpanded публикует world.region.expanded
+func (wg *WorldGenerator) publishRegionExpanded(ctx context.Context, worldID, regionID string, region Region, direction string, bounds WorldBounds, neighborIDs []string, cities int) {
+	payload := eventbus.NewEventPayload().
+		WithEntity(regionID, "region", region.Name).
+		WithWorld(worldID)
+
+	eventbus.SetNested(payload.GetCustom(), "direction", direction)
+	eventbus.SetNested(payload.GetCustom(), "biome", region.Biome)
+	eventbus.SetNested(payload.GetCustom(), "coordinates", region.Coordinates)
+	eventbus.SetNested(payload.GetCustom(), "bounds", bounds)
+	eventbus.SetNested(payload.GetCustom(), "neighbors", neighborIDs)
+	eventbus.SetNested(payload.GetCustom(), "cities", cities)
+
+	event := eventbus.NewStructuredEvent("world.region.expanded", "world-generator", worldID, payload)
+	wg.bus.Publish(ctx, eventbus.TopicSystemEvents, event)
+	logging.Infof("Published world.region.expanded for world %s: %s (%s, %d neighbors)", worldID, region.Name, direction, len(neighborIDs))
+}
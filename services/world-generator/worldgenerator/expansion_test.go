@@ -0,0 +1,120 @@
+package worldgenerator
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test chooseDirection — явное направление, ближайший к точке край или восток по умолчанию
+func TestChooseDirection(t *testing.T) {
+	assert.Equal(t, DirectionSouth, chooseDirection(defaultWorldBounds, DirectionSouth, &Point{X: 1, Y: 50}))
+	assert.Equal(t, DirectionEast, chooseDirection(defaultWorldBounds, "", nil))
+	assert.Equal(t, DirectionWest, chooseDirection(defaultWorldBounds, "", &Point{X: 2, Y: 50}))
+	assert.Equal(t, DirectionNorth, chooseDirection(defaultWorldBounds, "", &Point{X: 40, Y: 3}))
+	assert.Equal(t, DirectionSouth, chooseDirection(defaultWorldBounds, "", &Point{X: 40, Y: 99}))
+}
+
+// Test expandBounds — мир растёт в одну сторону, регион ставится в полосе расширения
+func TestExpandBounds(t *testing.T) {
+	bounds, center := expandBounds(defaultWorldBounds, DirectionWest, 40, nil)
+	assert.Equal(t, WorldBounds{MinX: -40, MaxX: 100, MaxY: 100}, bounds)
+	assert.Equal(t, Point{X: -20, Y: 50}, center)
+
+	// Напротив точки, но не ближе половины полосы к углу
+	bounds, center = expandBounds(defaultWorldBounds, DirectionNorth, 40, &Point{X: 90, Y: 1})
+	assert.Equal(t, WorldBounds{MinY: -40, MaxX: 100, MaxY: 100}, bounds)
+	assert.Equal(t, Point{X: 80, Y: -20}, center)
+
+	bounds, center = expandBounds(bounds, DirectionEast, 40, &Point{X: 99, Y: 30})
+	assert.Equal(t, WorldBounds{MinY: -40, MaxX: 140, MaxY: 100}, bounds)
+	assert.Equal(t, Point{X: 120, Y: 30}, center)
+}
+
+// Test neighborRegions — граничащие регионы, а при их отсутствии ближайший
+func TestNeighborRegions(t *testing.T) {
+	regions := []Region{
+		{Name: "Лес", Coordinates: Point{X: 85, Y: 50}, Size: 314.159},
+		{Name: "Горы", Coordinates: Point{X: 20, Y: 20}, Size: 314.159},
+		{Name: "Степь", Coordinates: Point{X: 85, Y: 85}, Size: 314.159},
+	}
+	region := Region{Coordinates: Point{X: 120, Y: 50}, Size: math.Pi * 400}
+
+	neighbors := neighborRegions(region, regions)
+	assert.Len(t, neighbors, 1)
+	assert.Equal(t, "Лес", neighbors[0].Name)
+
+	far := Region{Coordinates: Point{X: 200, Y: 200}, Size: math.Pi * 400}
+	neighbors = neighborRegions(far, regions)
+	assert.Len(t, neighbors, 1)
+	assert.Equal(t, "Степь", neighbors[0].Name)
+}
+
+// Test placeExpansion — регион занимает полосу, города и водоёмы внутри него, имя уникально
+func TestPlaceExpansion(t *testing.T) {
+	existing := []Region{{Name: "Лес", Coordinates: Point{X: 85, Y: 50}, Size: 314.159}}
+	exp := &RegionExpansion{
+		Region:      Region{Name: "лес", Biome: "taiga"},
+		WaterBodies: []WaterBody{{Name: "Озеро", Type: "lake"}, {Name: "Река", Type: "river"}, {Name: "Лишнее", Type: "lake"}},
+		Cities:      []City{{Name: "A", Population: 100}, {Name: "B"}, {Name: "C"}, {Name: "D"}},
+	}
+
+	geo := placeExpansion(exp, Point{X: 120, Y: 50}, 40, existing)
+
+	assert.Len(t, geo.Regions, 1)
+	assert.Equal(t, "лес 2", geo.Regions[0].Name)
+	assert.Equal(t, "taiga", geo.Regions[0].Biome)
+	assert.InDelta(t, 20, radius(geo.Regions[0].Size), 0.001)
+	assert.Len(t, geo.WaterBodies, maxExpansionWaters)
+	assert.Len(t, geo.Cities, maxExpansionCities)
+	assert.Equal(t, Point{X: 120, Y: 50}, geo.Cities[0].Location.Coordinates)
+	for _, c := range geo.Cities {
+		assert.Equal(t, "лес 2", c.Location.Region)
+		_, wet := inWater(c.Location.Coordinates, geo.WaterBodies)
+		assert.False(t, wet, c.Name)
+	}
+
+	// Вместе с существующей географией в расширенных границах исправления не нужны
+	all := Geography{Regions: append(existing, geo.Regions...), WaterBodies: geo.WaterBodies, Cities: geo.Cities}
+	assert.Empty(t, ValidateGeography(&all, WorldBounds{MaxX: 140, MaxY: 100}))
+}
+
+// Test buildExpansionPrompts — промпт содержит направление, соседей, онтологию и занятые названия
+func TestBuildExpansionPrompts(t *testing.T) {
+	record := &WorldRecord{
+		Concept:   WorldConcept{Core: "Мир летающих островов", Theme: "cultivation"},
+		Ontology:  WorldOntology{System: "cultivation", Carriers: []string{"ци"}},
+		Geography: Geography{Regions: []Region{{Name: "Лес"}, {Name: "Горы"}}},
+	}
+	neighbors := []Region{{Name: "Лес", Biome: "forest"}}
+
+	system, user := buildExpansionPrompts(record, DirectionEast, neighbors)
+
+	assert.True(t, strings.Contains(system, "Мир летающих островов"))
+	assert.True(t, strings.Contains(system, "ци"))
+	assert.True(t, strings.Contains(user, "восток"))
+	assert.True(t, strings.Contains(user, "- Лес (биом: forest)"))
+	assert.True(t, strings.Contains(user, "Лес, Горы"))
+}
+
+// Test GenerateTileMap — после расширения границ рельеф прежней части мира не меняется
+func TestGenerateTileMap_StableAfterExpansion(t *testing.T) {
+	geo := testTerrainGeography()
+	before := GenerateTileMap("seed", geo, defaultWorldBounds, 100.0/64)
+	after := GenerateTileMap("seed", geo, WorldBounds{MinX: -40, MaxX: 100, MaxY: 100}, 100.0/64)
+
+	assert.InDelta(t, -40.625, after.Origin.X, 0.001)
+	assert.Equal(t, 0.0, after.Origin.Y)
+	assert.True(t, after.Width > before.Width)
+	for _, p := range []Point{{X: 5, Y: 5}, {X: 50, Y: 70}, {X: 95, Y: 20}} {
+		i, ok := before.At(p)
+		assert.True(t, ok)
+		j, ok := after.At(p)
+		assert.True(t, ok)
+		assert.Equal(t, before.Heights[i], after.Heights[j])
+	}
+	_, ok := after.At(Point{X: -20, Y: 50})
+	assert.True(t, ok)
+}
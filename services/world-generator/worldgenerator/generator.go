@@ -5,10 +5,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 
 	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
 	"multiverse-core.io/shared/oracle"
+	"multiverse-core.io/shared/registry"
 
 	"github.com/google/uuid"
 )
@@ -19,6 +21,7 @@ type WorldGenerationRequest struct {
 	Mode        string                 `json:"mode"`                   // "contextual" | "random"; default "random"
 	UserContext *UserWorldContext      `json:"user_context,omitempty"` // заполняется только для mode="contextual"
 	Constraints map[string]interface{} `json:"constraints,omitempty"`
+	Template    string                 `json:"template,omitempty"` // шаблон мира из world-templates/{template}.yaml
 }
 
 // UserWorldContext пользовательское описание желаемого мира
@@ -104,14 +107,22 @@ type WorldGenerator struct {
 	bus       *eventbus.EventBus
 	archivist ArchivistClient
 	oracle    *oracle.Client
+	discovery *registry.Discovery
+	maps      storage.ObjectStorage // хранилище карт, состояния миров и фокуса городов; nil — карты не строятся, миры не расширяются
+
+	npcsPerCity int // число NPC в каждом новом городе
 }
 
 // NewWorldGenerator creates a new WorldGenerator.
 func NewWorldGenerator(bus *eventbus.EventBus) *WorldGenerator {
+	discovery := registry.NewDiscovery(bus, "world-generator")
 	return &WorldGenerator{
 		bus:       bus,
-		archivist: *NewArchivistClient(),
+		archivist: *NewArchivistClient(discovery),
 		oracle:    oracle.NewClient(),
+		discovery: discovery,
+
+		npcsPerCity: defaultNPCsPerCity,
 	}
 }
 
@@ -181,27 +192,42 @@ func parseGenerationRequest(payload map[string]interface{}) (*WorldGenerationReq
 	return &request, nil
 }
 
-// HandleEvent processes world generation requests.
+// HandleEvent processes world generation and region expansion requests.
 func (wg *WorldGenerator) HandleEvent(ev eventbus.Event) {
-	if ev.Type != "world.generation.requested" {
-		return
+	switch ev.Type {
+	case "world.generation.requested":
+		wg.generateWorld(ev)
+	case "world.region.expansion.requested":
+		wg.expandRegion(ev)
 	}
+}
 
+// generateWorld генерирует новый мир по событию world.generation.requested
+func (wg *WorldGenerator) generateWorld(ev eventbus.Event) {
 	ctx := context.Background()
 
 	// 1. Парсинг запроса
 	request, err := parseGenerationRequest(ev.Payload)
 	if err != nil {
-		log.Printf("Invalid world generation request: %v", err)
+		logging.Warnf("Invalid world generation request: %v", err)
 		return
 	}
 
-	log.Printf("Starting world generation: seed=%s, mode=%s", request.Seed, request.Mode)
+	// Шаблон мира: ограничения и фрагменты промптов обоих этапов
+	var tpl *WorldTemplate
+	if request.Template != "" {
+		if tpl, err = wg.loadTemplate(ctx, request.Template); err != nil {
+			logging.Warnf("World template %s unavailable, generation rejected: %v", request.Template, err)
+			return
+		}
+	}
+
+	logging.Infof("Starting world generation: seed=%s, mode=%s, template=%s", request.Seed, request.Mode, defaultIfEmpty(request.Template, "none"))
 
 	// 2. Генерация концепции (этап A)
-	concept, err := wg.generateWorldConcept(ctx, request)
+	concept, err := wg.generateWorldConcept(ctx, request, tpl)
 	if err != nil {
-		log.Printf("World concept generation failed: %v", err)
+		logging.Errorf("World concept generation failed: %v", err)
 		return
 	}
 
@@ -210,19 +236,47 @@ func (wg *WorldGenerator) HandleEvent(ev eventbus.Event) {
 	wg.publishWorldCreated(ctx, worldID, request, concept)
 
 	// 4. Генерация деталей (этап B)
-	geography, err := wg.generateWorldDetails(ctx, worldID, concept, request.getScale())
+	geography, err := wg.generateWorldDetails(ctx, worldID, concept, request.getScale(), tpl)
 	if err != nil {
-		log.Printf("World details generation failed: %v", err)
+		logging.Errorf("World details generation failed: %v", err)
 		return
 	}
 
-	// 5. Создание geographic entities
-	wg.createGeographicEntities(ctx, worldID, *geography)
+	// 5. Проверка пространственной согласованности: сущности создаются уже с исправленными координатами
+	if fixes := ValidateGeography(&geography.Geography, defaultWorldBounds); len(fixes) > 0 {
+		wg.publishGeographyCorrected(ctx, worldID, fixes)
+	}
+
+	// 6. Создание geographic entities
+	regionIDs, cityIDs := wg.createGeographicEntities(ctx, worldID, *geography)
 
-	// 6. Финальное событие
+	// 7. Население городов: NPC и фокусные сущности scope города
+	for i, city := range geography.Geography.Cities {
+		wg.seedCityNPCs(ctx, worldID, cityIDs[i], city, concept, geography.Ontology)
+	}
+
+	// 8. Матрица совместимости путей Дао для CultivationModule
+	wg.generateDaoMatrix(ctx, worldID, concept, geography.Ontology)
+
+	// 9. Сохранение состояния мира для последующего расширения
+	wg.saveWorld(ctx, &WorldRecord{
+		WorldID:   worldID,
+		Seed:      request.Seed,
+		Scale:     request.getScale(),
+		Concept:   *concept,
+		Ontology:  geography.Ontology,
+		Geography: geography.Geography,
+		Bounds:    defaultWorldBounds,
+		RegionIDs: regionIDs,
+	})
+
+	// 10. Процедурная карта высот и биомов по исправленной географии
+	wg.generateTileMap(ctx, worldID, request.Seed, geography.Geography, defaultWorldBounds, request.getScale())
+
+	// 11. Финальное событие
 	wg.publishWorldGenerated(ctx, worldID, request, concept)
 
-	log.Printf("World %s generated successfully (mode=%s, theme=%s)", worldID, request.Mode, concept.Theme)
+	logging.Infof("World %s generated successfully (mode=%s, theme=%s)", worldID, request.Mode, concept.Theme)
 }
 
 // publishWorldCreated публикует entity.created для мира с концепцией
@@ -242,10 +296,13 @@ func (wg *WorldGenerator) publishWorldCreated(ctx context.Context, worldID strin
 	if req.Constraints != nil {
 		eventbus.SetNested(payload.GetCustom(), "payload.constraints", req.Constraints)
 	}
+	if req.Template != "" {
+		eventbus.SetNested(payload.GetCustom(), "payload.template", req.Template)
+	}
 
 	event := eventbus.NewStructuredEvent("entity.created", "world-generator", worldID, payload)
 	wg.bus.Publish(ctx, eventbus.TopicSystemEvents, event)
-	log.Printf("Published world.created event: %s (theme=%s)", worldID, concept.Theme)
+	logging.Infof("Published world.created event: %s (theme=%s)", worldID, concept.Theme)
 }
 
 // publishWorldGenerated публикует финальное событие world.generated
@@ -257,17 +314,29 @@ func (wg *WorldGenerator) publishWorldGenerated(ctx context.Context, worldID str
 	eventbus.SetNested(payload.GetCustom(), "seed", req.Seed)
 	eventbus.SetNested(payload.GetCustom(), "mode", req.Mode)
 	eventbus.SetNested(payload.GetCustom(), "theme", concept.Theme)
+	if req.Template != "" {
+		eventbus.SetNested(payload.GetCustom(), "template", req.Template)
+	}
 
 	event := eventbus.NewStructuredEvent("world.generated", "world-generator", worldID, payload)
 	wg.bus.Publish(ctx, eventbus.TopicSystemEvents, event)
-	log.Printf("Published world.generated event: %s", worldID)
+	logging.Infof("Published world.generated event: %s", worldID)
 }
 
-// createGeographicEntities creates entities for geographic objects
-func (wg *WorldGenerator) createGeographicEntities(ctx context.Context, worldID string, geography WorldGeography) {
-	// Create regions
-	for _, region := range geography.Geography.Regions {
-		wg.createRegionEntity(ctx, worldID, region)
+// createGeographicEntities creates entities for geographic objects.
+// It returns region IDs by name and city IDs in the order of geography.Geography.Cities.
+func (wg *WorldGenerator) createGeographicEntities(ctx context.Context, worldID string, geography WorldGeography) (map[string]string, []string) {
+	// Create regions; each region is linked to the already created regions it borders
+	regions := geography.Geography.Regions
+	regionIDs := make(map[string]string, len(regions))
+	for i, region := range regions {
+		var neighbors []string
+		for _, other := range regions[:i] {
+			if adjacent(region, other) {
+				neighbors = append(neighbors, regionIDs[other.Name])
+			}
+		}
+		regionIDs[region.Name] = wg.createRegionEntity(ctx, worldID, region, neighbors)
 	}
 
 	// Create water bodies
@@ -276,16 +345,18 @@ func (wg *WorldGenerator) createGeographicEntities(ctx context.Context, worldID
 	}
 
 	// Create cities
+	cityIDs := make([]string, 0, len(geography.Geography.Cities))
 	for _, city := range geography.Geography.Cities {
-		wg.createCityEntity(ctx, worldID, city)
+		cityIDs = append(cityIDs, wg.createCityEntity(ctx, worldID, city, regionIDs[city.Location.Region]))
 	}
 
 	// Publish geography generated event
 	wg.publishGeographyGeneratedEvent(ctx, worldID, geography)
+	return regionIDs, cityIDs
 }
 
-// createRegionEntity creates a region entity with explicit relations
-func (wg *WorldGenerator) createRegionEntity(ctx context.Context, worldID string, region Region) {
+// createRegionEntity creates a region entity linked to the world and to neighboring regions
+func (wg *WorldGenerator) createRegionEntity(ctx context.Context, worldID string, region Region, neighbors []string) string {
 	regionID := "region-" + uuid.New().String()[:8]
 	regionEntityID := regionID
 
@@ -298,6 +369,8 @@ func (wg *WorldGenerator) createRegionEntity(ctx context.Context, worldID string
 	eventbus.SetNested(payload.GetCustom(), "payload.biome", region.Biome)
 	eventbus.SetNested(payload.GetCustom(), "payload.coordinates", region.Coordinates)
 	eventbus.SetNested(payload.GetCustom(), "payload.size", region.Size)
+	// Принадлежность миру: SemanticMemory строит по ней PART_OF и без Relations[]
+	eventbus.SetNested(payload.GetCustom(), "payload.parent_world", worldID)
 
 	event := eventbus.NewStructuredEvent("entity.created", "world-generator", worldID, payload)
 
@@ -310,15 +383,30 @@ func (wg *WorldGenerator) createRegionEntity(ctx context.Context, worldID string
 			Directed: true,
 			Metadata: map[string]any{"biome": region.Biome},
 		},
+		{
+			From:     regionEntityID,
+			To:       worldID,
+			Type:     eventbus.RelPartOf,
+			Directed: true,
+		},
+	}
+	for _, neighborID := range neighbors {
+		event.Relations = append(event.Relations, eventbus.Relation{
+			From:     regionEntityID,
+			To:       neighborID,
+			Type:     eventbus.RelAdjacentTo,
+			Directed: false,
+		})
 	}
 
 	// Валидация перед публикацией
 	if err := eventbus.ValidateEventRelations(event); err != nil {
-		log.Printf("Invalid relations for region %s: %v", region.Name, err)
+		logging.Warnf("Invalid relations for region %s: %v", region.Name, err)
 	}
 
 	wg.bus.Publish(ctx, eventbus.TopicSystemEvents, event)
-	log.Printf("Created region entity: %s (%s)", region.Name, region.Biome)
+	logging.Infof("Created region entity: %s (%s)", region.Name, region.Biome)
+	return regionEntityID
 }
 
 // createWaterEntity creates a water body entity with explicit relations
@@ -335,6 +423,7 @@ func (wg *WorldGenerator) createWaterEntity(ctx context.Context, worldID string,
 	eventbus.SetNested(payload.GetCustom(), "payload.type", water.Type)
 	eventbus.SetNested(payload.GetCustom(), "payload.coordinates", water.Coordinates)
 	eventbus.SetNested(payload.GetCustom(), "payload.size", water.Size)
+	eventbus.SetNested(payload.GetCustom(), "payload.parent_world", worldID)
 
 	event := eventbus.NewStructuredEvent("entity.created", "world-generator", worldID, payload)
 
@@ -347,18 +436,24 @@ func (wg *WorldGenerator) createWaterEntity(ctx context.Context, worldID string,
 			Directed: true,
 			Metadata: map[string]any{"water_type": water.Type},
 		},
+		{
+			From:     waterEntityID,
+			To:       worldID,
+			Type:     eventbus.RelPartOf,
+			Directed: true,
+		},
 	}
 
 	if err := eventbus.ValidateEventRelations(event); err != nil {
-		log.Printf("Invalid relations for water %s: %v", water.Name, err)
+		logging.Warnf("Invalid relations for water %s: %v", water.Name, err)
 	}
 
 	wg.bus.Publish(ctx, eventbus.TopicSystemEvents, event)
-	log.Printf("Created water entity: %s (%s)", water.Name, water.Type)
+	logging.Infof("Created water entity: %s (%s)", water.Name, water.Type)
 }
 
-// createCityEntity creates a city entity with explicit relations
-func (wg *WorldGenerator) createCityEntity(ctx context.Context, worldID string, city City) {
+// createCityEntity creates a city entity with explicit relations; regionID may be empty
+func (wg *WorldGenerator) createCityEntity(ctx context.Context, worldID string, city City, regionID string) string {
 	cityID := "city-" + uuid.New().String()[:8]
 	cityEntityID := cityID
 
@@ -371,6 +466,12 @@ func (wg *WorldGenerator) createCityEntity(ctx context.Context, worldID string,
 	eventbus.SetNested(payload.GetCustom(), "payload.population", city.Population)
 	eventbus.SetNested(payload.GetCustom(), "payload.type", city.Type)
 	eventbus.SetNested(payload.GetCustom(), "payload.location", city.Location)
+	// Город входит в мир через регион (city → region → world); без региона — напрямую
+	if regionID != "" {
+		eventbus.SetNested(payload.GetCustom(), "payload.parent_region", regionID)
+	} else {
+		eventbus.SetNested(payload.GetCustom(), "payload.parent_world", worldID)
+	}
 
 	event := eventbus.NewStructuredEvent("entity.created", "world-generator", worldID, payload)
 
@@ -386,14 +487,45 @@ func (wg *WorldGenerator) createCityEntity(ctx context.Context, worldID string,
 		Metadata: map[string]any{"city_type": city.Type, "population": city.Population},
 	})
 
+	// Связь город → регион (LOCATED_IN), без региона — город → мир (PART_OF)
+	if regionID != "" {
+		relations = append(relations, eventbus.Relation{
+			From:     cityEntityID,
+			To:       regionID,
+			Type:     eventbus.RelLocatedIn,
+			Directed: true,
+		})
+	} else {
+		relations = append(relations, eventbus.Relation{
+			From:     cityEntityID,
+			To:       worldID,
+			Type:     eventbus.RelPartOf,
+			Directed: true,
+		})
+	}
+
 	event.Relations = relations
 
 	if err := eventbus.ValidateEventRelations(event); err != nil {
-		log.Printf("Invalid relations for city %s: %v", city.Name, err)
+		logging.Warnf("Invalid relations for city %s: %v", city.Name, err)
 	}
 
 	wg.bus.Publish(ctx, eventbus.TopicSystemEvents, event)
-	log.Printf("Created city entity: %s (population: %d)", city.Name, city.Population)
+	logging.Infof("Created city entity: %s (population: %d)", city.Name, city.Population)
+	return cityEntityID
+}
+
+// publishGeographyCorrected публикует world.geography.corrected со списком исправлений географии
+func (wg *WorldGenerator) publishGeographyCorrected(ctx context.Context, worldID string, fixes []GeographyFix) {
+	payload := eventbus.NewEventPayload().
+		WithWorld(worldID)
+
+	eventbus.SetNested(payload.GetCustom(), "fixes", fixes)
+	eventbus.SetNested(payload.GetCustom(), "count", len(fixes))
+
+	event := eventbus.NewStructuredEvent("world.geography.corrected", "world-generator", worldID, payload)
+	wg.bus.Publish(ctx, eventbus.TopicSystemEvents, event)
+	logging.Infof("Corrected %d geography issues in world %s", len(fixes), worldID)
 }
 
 // publishGeographyGeneratedEvent publishes an event when geography is generated
@@ -408,5 +540,5 @@ func (wg *WorldGenerator) publishGeographyGeneratedEvent(ctx context.Context, wo
 
 	event := eventbus.NewStructuredEvent("world.geography.generated", "world-generator", worldID, payload)
 	wg.bus.Publish(ctx, eventbus.TopicSystemEvents, event)
-	log.Printf("Published geography generated event for world: %s", worldID)
+	logging.Infof("Published geography generated event for world: %s", worldID)
 }
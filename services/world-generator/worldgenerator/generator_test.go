@@ -1,4 +1,3 @@
-// Package worldgenerator implements world generation logic.
 package worldgenerator
 
 import (
@@ -161,7 +160,7 @@ func TestBuildConceptPrompts_Random(t *testing.T) {
 		Mode: "random",
 	}
 
-	systemPrompt, userPrompt := buildConceptPrompts(req)
+	systemPrompt, userPrompt := buildConceptPrompts(req, nil)
 
 	// Проверить что systemPrompt содержит JSON-формат
 	assert.Contains(t, systemPrompt, "JSON")
@@ -189,7 +188,7 @@ func TestBuildConceptPrompts_Contextual(t *testing.T) {
 		},
 	}
 
-	systemPrompt, userPrompt := buildConceptPrompts(req)
+	systemPrompt, userPrompt := buildConceptPrompts(req, nil)
 
 	// Проверить что systemPrompt содержит JSON-формат
 	assert.Contains(t, systemPrompt, "JSON")
@@ -212,7 +211,7 @@ func TestBuildDetailsPrompts_ScaleSmall(t *testing.T) {
 		UniqueTraits: []string{"трейт1", "трейт2"},
 	}
 
-	systemPrompt, userPrompt := buildDetailsPrompts(concept, "small")
+	systemPrompt, userPrompt := buildDetailsPrompts(concept, "small", nil)
 
 	assert.Contains(t, systemPrompt, "cultivation")
 	assert.Contains(t, systemPrompt, "Ancient times")
@@ -229,7 +228,7 @@ func TestBuildDetailsPrompts_ScaleLarge(t *testing.T) {
 		UniqueTraits: []string{"трейт1", "трейт2"},
 	}
 
-	systemPrompt, userPrompt := buildDetailsPrompts(concept, "large")
+	systemPrompt, userPrompt := buildDetailsPrompts(concept, "large", nil)
 
 	assert.Contains(t, systemPrompt, "steampunk")
 	// Проверить что userPrompt содержит "5-8 регионов"
@@ -245,7 +244,7 @@ func TestBuildDetailsPrompts_JSONStructure(t *testing.T) {
 		UniqueTraits: []string{"trait1"},
 	}
 
-	_, userPrompt := buildDetailsPrompts(concept, "medium")
+	_, userPrompt := buildDetailsPrompts(concept, "medium", nil)
 
 	// Проверить что промпт содержит требуемую JSON-структуру
 	assert.Contains(t, userPrompt, "ontology")
@@ -321,7 +320,7 @@ func TestBuildConceptPrompts_ContextualMinimal(t *testing.T) {
 		},
 	}
 
-	_, userPrompt := buildConceptPrompts(req)
+	_, userPrompt := buildConceptPrompts(req, nil)
 
 	assert.Contains(t, userPrompt, "Clockwork Dawn")
 	assert.Contains(t, userPrompt, "Стимпанк мир с летающими городами")
@@ -337,7 +336,7 @@ func TestBuildDetailsPrompts_OntologyInPrompt(t *testing.T) {
 		UniqueTraits: []string{"natural", "wild"},
 	}
 
-	_, userPrompt := buildDetailsPrompts(concept, "medium")
+	_, userPrompt := buildDetailsPrompts(concept, "medium", nil)
 
 	// Проверить требования к онтологии
 	assert.Contains(t, userPrompt, "cultivation, magic, technology, divine, nature")
@@ -381,7 +380,7 @@ func TestBuildConceptPrompts_RussianPrompt(t *testing.T) {
 		Mode: "random",
 	}
 
-	systemPrompt, userPrompt := buildConceptPrompts(req)
+	systemPrompt, userPrompt := buildConceptPrompts(req, nil)
 
 	// Проверить что промпты на русском
 	assert.Contains(t, systemPrompt, "Демиург")
@@ -399,7 +398,7 @@ func TestBuildDetailsPrompts_RussianPrompt(t *testing.T) {
 		UniqueTraits: []string{"trait"},
 	}
 
-	systemPrompt, userPrompt := buildDetailsPrompts(concept, "medium")
+	systemPrompt, userPrompt := buildDetailsPrompts(concept, "medium", nil)
 
 	assert.Contains(t, systemPrompt, "Демиург")
 	assert.Contains(t, userPrompt, "Сгенерируй")
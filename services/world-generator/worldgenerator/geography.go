@@ -0,0 +1,260 @@
+// Package worldgenerator handles world bounds and geography validation.
+package worldgenerator
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// WorldBounds — границы координат мира; расширение мира сдвигает их наружу
+type WorldBounds struct {
+	MinX float64 `json:"min_x"`
+	MinY float64 `json:"min_y"`
+	MaxX float64 `json:"max_x"`
+	MaxY float64 `json:"max_y"`
+}
+
+// defaultWorldBounds — координаты, которые запрашиваются у Oracle (0–100 по обеим осям)
+var defaultWorldBounds = WorldBounds{MaxX: 100, MaxY: 100}
+
+// Width возвращает ширину мира
+func (b WorldBounds) Width() float64 {
+	return b.MaxX - b.MinX
+}
+
+// Height возвращает высоту мира
+func (b WorldBounds) Height() float64 {
+	return b.MaxY - b.MinY
+}
+
+// overlapTolerance — допустимая доля перекрытия соседних регионов и водоёмов
+// (границы регионов условны, небольшое перекрытие не считается ошибкой)
+const overlapTolerance = 0.2
+
+// Виды исправлений географии
+const (
+	FixOutOfBounds    = "out_of_bounds"
+	FixInvalidSize    = "invalid_size"
+	FixOverlap        = "overlap"
+	FixCityInWater    = "city_in_water"
+	FixOutsideRegion  = "outside_region"
+	FixUnknownRegion  = "unknown_region"
+	FixNoDryLandFound = "no_dry_land_found"
+)
+
+// GeographyFix описывает одно исправление географии
+type GeographyFix struct {
+	Kind   string `json:"kind"` // region, water_body, city
+	Name   string `json:"name"`
+	Issue  string `json:"issue"`
+	From   Point  `json:"from"`
+	To     Point  `json:"to"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// radius — радиус объекта; size трактуется как площадь круга
+func radius(size float64) float64 {
+	return math.Sqrt(size / math.Pi)
+}
+
+func distance(a, b Point) float64 {
+	return math.Hypot(a.X-b.X, a.Y-b.Y)
+}
+
+// clamp возвращает точку в пределах границ мира
+func (b WorldBounds) clamp(p Point) Point {
+	return Point{X: math.Min(math.Max(p.X, b.MinX), b.MaxX), Y: math.Min(math.Max(p.Y, b.MinY), b.MaxY)}
+}
+
+// adjacencySlack — во сколько раз расстояние между центрами соседних регионов может превышать сумму радиусов
+const adjacencySlack = 1.25
+
+// adjacent сообщает, граничат ли регионы (касаются с учётом adjacencySlack)
+func adjacent(a, b Region) bool {
+	return distance(a.Coordinates, b.Coordinates) <= (radius(a.Size)+radius(b.Size))*adjacencySlack
+}
+
+// blocksCities — водоём, в котором не может стоять город (города у рек допустимы)
+func blocksCities(water WaterBody) bool {
+	return !strings.EqualFold(water.Type, "river")
+}
+
+// ValidateGeography исправляет пространственные противоречия сгенерированной географии:
+// выводит координаты в границы мира, раздвигает перекрывающиеся регионы и водоёмы
+// и переносит города, стоящие в воде или вне своего региона, в объявленный регион.
+// Исправления применяются к geo на месте и возвращаются списком.
+func ValidateGeography(geo *Geography, bounds WorldBounds) []GeographyFix {
+	var fixes []GeographyFix
+
+	// 1. Размеры и границы мира
+	for i := range geo.Regions {
+		r := &geo.Regions[i]
+		fixes = append(fixes, fixSize(&r.Size, "region", r.Name)...)
+		fixes = append(fixes, fixBounds(&r.Coordinates, bounds, "region", r.Name)...)
+	}
+	for i := range geo.WaterBodies {
+		w := &geo.WaterBodies[i]
+		fixes = append(fixes, fixSize(&w.Size, "water_body", w.Name)...)
+		fixes = append(fixes, fixBounds(&w.Coordinates, bounds, "water_body", w.Name)...)
+	}
+	for i := range geo.Cities {
+		c := &geo.Cities[i]
+		fixes = append(fixes, fixBounds(&c.Location.Coordinates, bounds, "city", c.Name)...)
+	}
+
+	// 2. Перекрытия регионов между собой и водоёмов между собой: сдвигается объект, описанный позже
+	for j := range geo.Regions {
+		for i := 0; i < j; i++ {
+			if fix, ok := separate(&geo.Regions[j].Coordinates, geo.Regions[i].Coordinates,
+				radius(geo.Regions[j].Size), radius(geo.Regions[i].Size), bounds); ok {
+				fix.Kind, fix.Name = "region", geo.Regions[j].Name
+				fix.Detail = "overlapped region " + geo.Regions[i].Name
+				fixes = append(fixes, fix)
+			}
+		}
+	}
+	for j := range geo.WaterBodies {
+		if !blocksCities(geo.WaterBodies[j]) {
+			continue
+		}
+		for i := 0; i < j; i++ {
+			if !blocksCities(geo.WaterBodies[i]) {
+				continue
+			}
+			if fix, ok := separate(&geo.WaterBodies[j].Coordinates, geo.WaterBodies[i].Coordinates,
+				radius(geo.WaterBodies[j].Size), radius(geo.WaterBodies[i].Size), bounds); ok {
+				fix.Kind, fix.Name = "water_body", geo.WaterBodies[j].Name
+				fix.Detail = "overlapped water body " + geo.WaterBodies[i].Name
+				fixes = append(fixes, fix)
+			}
+		}
+	}
+
+	// 3. Города: на суше и внутри объявленного региона
+	for i := range geo.Cities {
+		fixes = append(fixes, relocateCity(&geo.Cities[i], geo, bounds)...)
+	}
+	return fixes
+}
+
+func fixSize(size *float64, kind, name string) []GeographyFix {
+	if *size >= 0 && !math.IsNaN(*size) && !math.IsInf(*size, 0) {
+		return nil
+	}
+	detail := fmt.Sprintf("size %v replaced with 0", *size)
+	*size = 0
+	return []GeographyFix{{Kind: kind, Name: name, Issue: FixInvalidSize, Detail: detail}}
+}
+
+func fixBounds(p *Point, bounds WorldBounds, kind, name string) []GeographyFix {
+	clamped := bounds.clamp(*p)
+	if clamped == *p {
+		return nil
+	}
+	fix := GeographyFix{Kind: kind, Name: name, Issue: FixOutOfBounds, From: *p, To: clamped}
+	*p = clamped
+	return []GeographyFix{fix}
+}
+
+// separate отодвигает p от other до касания, если круги перекрываются больше допустимого
+func separate(p *Point, other Point, r, otherR float64, bounds WorldBounds) (GeographyFix, bool) {
+	minDist := r + otherR
+	dist := distance(*p, other)
+	if minDist == 0 || dist >= minDist*(1-overlapTolerance) {
+		return GeographyFix{}, false
+	}
+
+	// Совпадающие центры раздвигаются по оси X
+	dx, dy := 1.0, 0.0
+	if dist > 0 {
+		dx, dy = (p.X-other.X)/dist, (p.Y-other.Y)/dist
+	}
+	moved := bounds.clamp(Point{X: other.X + dx*minDist, Y: other.Y + dy*minDist})
+	fix := GeographyFix{Issue: FixOverlap, From: *p, To: moved}
+	*p = moved
+	return fix, true
+}
+
+// inWater возвращает водоём, в котором стоит точка
+func inWater(p Point, waters []WaterBody) (WaterBody, bool) {
+	for _, w := range waters {
+		if blocksCities(w) && distance(p, w.Coordinates) < radius(w.Size) {
+			return w, true
+		}
+	}
+	return WaterBody{}, false
+}
+
+// findRegion ищет регион по имени; если его нет — ближайший к точке
+func findRegion(name string, p Point, regions []Region) (Region, bool, bool) {
+	for _, r := range regions {
+		if strings.EqualFold(r.Name, name) {
+			return r, true, true
+		}
+	}
+	if len(regions) == 0 {
+		return Region{}, false, false
+	}
+	nearest := regions[0]
+	for _, r := range regions[1:] {
+		if distance(p, r.Coordinates) < distance(p, nearest.Coordinates) {
+			nearest = r
+		}
+	}
+	return nearest, false, true
+}
+
+// relocateCity переносит город в объявленный регион, если он стоит в воде или вне региона.
+// Внутри региона выбирается первая точка на суше: центр, затем кольца вокруг него.
+func relocateCity(city *City, geo *Geography, bounds WorldBounds) []GeographyFix {
+	var fixes []GeographyFix
+	at := city.Location.Coordinates
+
+	region, declared, ok := findRegion(city.Location.Region, at, geo.Regions)
+	if !ok {
+		return nil
+	}
+	if !declared {
+		fixes = append(fixes, GeographyFix{Kind: "city", Name: city.Name, Issue: FixUnknownRegion, From: at, To: at,
+			Detail: fmt.Sprintf("region %q not found, assigned to nearest region %s", city.Location.Region, region.Name)})
+		city.Location.Region = region.Name
+	}
+
+	r := radius(region.Size)
+	water, wet := inWater(at, geo.WaterBodies)
+	outside := distance(at, region.Coordinates) > r
+	if !wet && !outside {
+		return fixes
+	}
+
+	issue, detail := FixOutsideRegion, "outside region "+region.Name
+	if wet {
+		issue, detail = FixCityInWater, "inside "+water.Type+" "+water.Name
+	}
+	target, found := dryPointInRegion(region, geo.WaterBodies, bounds)
+	if !found {
+		issue, detail = FixNoDryLandFound, detail+"; region "+region.Name+" has no dry land, moved to its center"
+	}
+	city.Location.Coordinates = target
+	return append(fixes, GeographyFix{Kind: "city", Name: city.Name, Issue: issue, From: at, To: target, Detail: detail})
+}
+
+// dryPointInRegion ищет точку суши внутри региона; если её нет — возвращает центр региона
+func dryPointInRegion(region Region, waters []WaterBody, bounds WorldBounds) (Point, bool) {
+	center := region.Coordinates
+	if _, wet := inWater(center, waters); !wet {
+		return center, true
+	}
+	r := radius(region.Size)
+	for _, fraction := range []float64{0.3, 0.6, 0.9} {
+		for step := 0; step < 8; step++ {
+			angle := float64(step) * math.Pi / 4
+			p := bounds.clamp(Point{X: center.X + math.Cos(angle)*r*fraction, Y: center.Y + math.Sin(angle)*r*fraction})
+			if _, wet := inWater(p, waters); !wet && distance(p, center) <= r {
+				return p, true
+			}
+		}
+	}
+	return center, false
+}
@@ -0,0 +1,137 @@
+package worldgenerator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fixesByIssue группирует исправления по виду проблемы
+func fixesByIssue(fixes []GeographyFix) map[string][]GeographyFix {
+	result := make(map[string][]GeographyFix)
+	for _, fix := range fixes {
+		result[fix.Issue] = append(result[fix.Issue], fix)
+	}
+	return result
+}
+
+// Test ValidateGeography — согласованная география не меняется
+func TestValidateGeography_Consistent(t *testing.T) {
+	geo := Geography{
+		Regions: []Region{
+			{Name: "Лес", Coordinates: Point{X: 25, Y: 25}, Size: 400},
+			{Name: "Горы", Coordinates: Point{X: 75, Y: 75}, Size: 400},
+		},
+		WaterBodies: []WaterBody{
+			{Name: "Озеро", Type: "lake", Coordinates: Point{X: 75, Y: 25}, Size: 100},
+			{Name: "Река", Type: "river", Coordinates: Point{X: 25, Y: 25}, Size: 50},
+		},
+		Cities: []City{
+			// Город у реки в центре леса допустим
+			{Name: "Приречье", Location: Location{Region: "Лес", Coordinates: Point{X: 25, Y: 25}}},
+		},
+	}
+	original := geo
+
+	assert.Empty(t, ValidateGeography(&geo, defaultWorldBounds))
+	assert.Equal(t, original, geo)
+}
+
+// Test ValidateGeography — координаты и размеры вне допустимых значений
+func TestValidateGeography_ClampsToBounds(t *testing.T) {
+	geo := Geography{
+		Regions:     []Region{{Name: "Пустошь", Coordinates: Point{X: -20, Y: 150}, Size: -5}},
+		WaterBodies: []WaterBody{{Name: "Море", Type: "sea", Coordinates: Point{X: 250, Y: 50}, Size: 10}},
+	}
+
+	issues := fixesByIssue(ValidateGeography(&geo, defaultWorldBounds))
+
+	assert.Len(t, issues[FixOutOfBounds], 2)
+	assert.Len(t, issues[FixInvalidSize], 1)
+	assert.Equal(t, Point{X: 0, Y: 100}, geo.Regions[0].Coordinates)
+	assert.Equal(t, 0.0, geo.Regions[0].Size)
+	assert.Equal(t, Point{X: 100, Y: 50}, geo.WaterBodies[0].Coordinates)
+}
+
+// Test ValidateGeography — перекрывающиеся регионы раздвигаются до касания
+func TestValidateGeography_SeparatesOverlappingRegions(t *testing.T) {
+	geo := Geography{
+		Regions: []Region{
+			{Name: "Север", Coordinates: Point{X: 40, Y: 50}, Size: 314.159},
+			{Name: "Юг", Coordinates: Point{X: 45, Y: 50}, Size: 314.159},
+		},
+	}
+
+	issues := fixesByIssue(ValidateGeography(&geo, defaultWorldBounds))
+
+	assert.Len(t, issues[FixOverlap], 1)
+	assert.Equal(t, "Юг", issues[FixOverlap][0].Name)
+	// Радиусы по 10: центр второго региона сдвинут на расстояние 20 от первого
+	assert.Equal(t, Point{X: 40, Y: 50}, geo.Regions[0].Coordinates)
+	assert.InDelta(t, 60, geo.Regions[1].Coordinates.X, 0.01)
+	assert.InDelta(t, 50, geo.Regions[1].Coordinates.Y, 0.01)
+}
+
+// Test ValidateGeography — город посреди моря переносится на сушу своего региона
+func TestValidateGeography_RelocatesCityFromSea(t *testing.T) {
+	geo := Geography{
+		Regions: []Region{
+			{Name: "Побережье", Coordinates: Point{X: 30, Y: 30}, Size: 1256.6},
+		},
+		WaterBodies: []WaterBody{
+			{Name: "Море", Type: "sea", Coordinates: Point{X: 70, Y: 70}, Size: 706.9},
+		},
+		Cities: []City{
+			{Name: "Порт", Location: Location{Region: "Побережье", Coordinates: Point{X: 70, Y: 70}}},
+		},
+	}
+
+	issues := fixesByIssue(ValidateGeography(&geo, defaultWorldBounds))
+
+	assert.Len(t, issues[FixCityInWater], 1)
+	assert.Equal(t, Point{X: 30, Y: 30}, geo.Cities[0].Location.Coordinates)
+}
+
+// Test ValidateGeography — центр региона под водой: город ставится на сушу внутри региона
+func TestValidateGeography_FindsDryLandInRegion(t *testing.T) {
+	geo := Geography{
+		Regions: []Region{
+			{Name: "Озёрный край", Coordinates: Point{X: 50, Y: 50}, Size: 1256.6},
+		},
+		WaterBodies: []WaterBody{
+			{Name: "Озеро", Type: "lake", Coordinates: Point{X: 50, Y: 50}, Size: 78.54},
+		},
+		Cities: []City{
+			{Name: "Рыбацкий", Location: Location{Region: "Озёрный край", Coordinates: Point{X: 50, Y: 50}}},
+		},
+	}
+
+	issues := fixesByIssue(ValidateGeography(&geo, defaultWorldBounds))
+
+	assert.Len(t, issues[FixCityInWater], 1)
+	city := geo.Cities[0].Location.Coordinates
+	assert.True(t, distance(city, Point{X: 50, Y: 50}) >= 5, "city must leave the lake")
+	assert.True(t, distance(city, Point{X: 50, Y: 50}) <= 20, "city must stay in its region")
+}
+
+// Test ValidateGeography — город вне своего региона и город с неизвестным регионом
+func TestValidateGeography_CityRegions(t *testing.T) {
+	geo := Geography{
+		Regions: []Region{
+			{Name: "Запад", Coordinates: Point{X: 20, Y: 50}, Size: 314.159},
+			{Name: "Восток", Coordinates: Point{X: 80, Y: 50}, Size: 314.159},
+		},
+		Cities: []City{
+			{Name: "Беглец", Location: Location{Region: "Запад", Coordinates: Point{X: 80, Y: 50}}},
+			{Name: "Потерянный", Location: Location{Region: "Атлантида", Coordinates: Point{X: 78, Y: 52}}},
+		},
+	}
+
+	issues := fixesByIssue(ValidateGeography(&geo, defaultWorldBounds))
+
+	assert.Len(t, issues[FixOutsideRegion], 1)
+	assert.Equal(t, Point{X: 20, Y: 50}, geo.Cities[0].Location.Coordinates)
+	assert.Len(t, issues[FixUnknownRegion], 1)
+	assert.Equal(t, "Восток", geo.Cities[1].Location.Region)
+	assert.Equal(t, Point{X: 78, Y: 52}, geo.Cities[1].Location.Coordinates)
+}
@@ -10,8 +10,8 @@ import (
 )
 
 // generateWorldConcept ЭТАП A: генерация концепции мира
-func (wg *WorldGenerator) generateWorldConcept(ctx context.Context, req *WorldGenerationRequest) (*WorldConcept, error) {
-	systemPrompt, userPrompt := buildConceptPrompts(req)
+func (wg *WorldGenerator) generateWorldConcept(ctx context.Context, req *WorldGenerationRequest, tpl *WorldTemplate) (*WorldConcept, error) {
+	systemPrompt, userPrompt := buildConceptPrompts(req, tpl)
 
 	var concept WorldConcept
 	err := wg.oracle.CallAndUnmarshal(ctx, func() (string, error) {
@@ -30,8 +30,8 @@ func (wg *WorldGenerator) generateWorldConcept(ctx context.Context, req *WorldGe
 }
 
 // generateWorldDetails ЭТАП B: детализация географии и онтологии на основе концепции
-func (wg *WorldGenerator) generateWorldDetails(ctx context.Context, worldID string, concept *WorldConcept, scale string) (*WorldGeography, error) {
-	systemPrompt, userPrompt := buildDetailsPrompts(concept, scale)
+func (wg *WorldGenerator) generateWorldDetails(ctx context.Context, worldID string, concept *WorldConcept, scale string, tpl *WorldTemplate) (*WorldGeography, error) {
+	systemPrompt, userPrompt := buildDetailsPrompts(concept, scale, tpl)
 
 	var geography WorldGeography
 	err := wg.oracle.CallAndUnmarshal(ctx, func() (string, error) {
@@ -41,11 +41,13 @@ func (wg *WorldGenerator) generateWorldDetails(ctx context.Context, worldID stri
 		return nil, fmt.Errorf("world details generation failed: %w", err)
 	}
 
+	tpl.apply(&geography)
 	return &geography, nil
 }
 
-// buildConceptPrompts формирует system и user промпты для этапа A (концепция)
-func buildConceptPrompts(req *WorldGenerationRequest) (systemPrompt, userPrompt string) {
+// buildConceptPrompts формирует system и user промпты для этапа A (концепция);
+// tpl (может быть nil) добавляет требования шаблона мира
+func buildConceptPrompts(req *WorldGenerationRequest, tpl *WorldTemplate) (systemPrompt, userPrompt string) {
 	systemPrompt = `Ты — Демиург, создатель миров. Твоя задача — создать уникальную концепцию мира.
 Отвечай строго в формате JSON без пояснений.
 
@@ -87,12 +89,14 @@ func buildConceptPrompts(req *WorldGenerationRequest) (systemPrompt, userPrompt
 Масштаб: medium.`, req.Seed)
 	}
 
-	return systemPrompt, userPrompt
+	return systemPrompt, userPrompt + tpl.conceptFragment()
 }
 
-// buildDetailsPrompts формирует system и user промпты для этапа B (детализация)
-func buildDetailsPrompts(concept *WorldConcept, scale string) (systemPrompt, userPrompt string) {
+// buildDetailsPrompts формирует system и user промпты для этапа B (детализация);
+// tpl (может быть nil) задаёт ограничения онтологии, доли биомов и число городов
+func buildDetailsPrompts(concept *WorldConcept, scale string, tpl *WorldTemplate) (systemPrompt, userPrompt string) {
 	minR, maxR, minW, maxW, minC, maxC := scaleParams(scale)
+	minC, maxC = tpl.cityRange(minC, maxC)
 
 	systemPrompt = fmt.Sprintf(`Ты — Демиург, детализирующий мир.
 
@@ -125,6 +129,8 @@ func buildDetailsPrompts(concept *WorldConcept, scale string) (systemPrompt, use
    - %d-%d регионов с уникальными биомами, координатами и размером
    - %d-%d водных объектов (реки, моря, озёра) с координатами и размером
    - %d-%d городов с населением, типом (major/minor) и привязкой к региону
+   - координаты x и y — от 0 до %g; size — площадь объекта
+   - регионы и водоёмы не должны накладываться друг на друга, города — стоять на суше внутри своего региона
 
 3. Мифология: краткий основополагающий миф мира (3-5 предложений)
 
@@ -144,9 +150,9 @@ func buildDetailsPrompts(concept *WorldConcept, scale string) (systemPrompt, use
     "cities": [{"name": "string", "population": 0, "type": "major|minor", "location": {"region": "string", "coordinates": {"x": 0.0, "y": 0.0}}}]
   },
   "mythology": "string"
-}`, minR, maxR, minW, maxW, minC, maxC)
+}`, minR, maxR, minW, maxW, minC, maxC, defaultWorldBounds.Width())
 
-	return systemPrompt, userPrompt
+	return systemPrompt, userPrompt + tpl.detailsFragment()
 }
 
 // CallOracle отправляет промпт в Ascension Oracle и возвращает ответ (для обратной совместимости).
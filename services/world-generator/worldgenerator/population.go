@@ -0,0 +1,211 @@
+// Package worldgenerator handles NPC population of generated cities.
+package worldgenerator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"multiverse-core.io/shared/config"
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// defaultNPCsPerCity — число NPC, создаваемых в каждом городе; 0 отключает заселение
+	defaultNPCsPerCity = 5
+	maxNPCsPerCity     = 20
+
+	// gmConfigBucket — бакет конфигов GM narrative-orchestrator (gm-overrides/{scope_id}.yaml)
+	gmConfigBucket = "gnue-configs"
+	cityScopeType  = "city"
+)
+
+// NPC — житель города, сгенерированный Oracle
+type NPC struct {
+	Name        string          `json:"name"`
+	Role        string          `json:"role"` // торговец, стражник, кузнец...
+	Description string          `json:"description"`
+	Schedule    []ScheduleEntry `json:"schedule"`
+}
+
+// ScheduleEntry — отрезок распорядка дня NPC
+type ScheduleEntry struct {
+	From     string `json:"from"` // "06:00"
+	To       string `json:"to"`   // "12:00"
+	Activity string `json:"activity"`
+	Place    string `json:"place"` // место в городе
+}
+
+// cityPopulation — ответ Oracle для одного города
+type cityPopulation struct {
+	NPCs []NPC `json:"npcs"`
+}
+
+// SetNPCsPerCity задаёт число NPC в каждом новом городе (0 — не заселять)
+func (wg *WorldGenerator) SetNPCsPerCity(n int) {
+	wg.npcsPerCity = min(max(n, 0), maxNPCsPerCity)
+}
+
+// buildNPCPrompts формирует system и user промпты для жителей одного города
+func buildNPCPrompts(concept *WorldConcept, ontology WorldOntology, city City, count int) (systemPrompt, userPrompt string) {
+	systemPrompt = fmt.Sprintf(`Ты — Демиург, населяющий мир жителями.
+
+Концепция мира:
+- Ядро: %s
+- Тема: %s
+- Эпоха: %s
+
+Онтология мира:
+- Система: %s
+- Пути развития: %s
+- Уровни: %s
+
+Имена, роли и занятия жителей должны соответствовать теме, эпохе и онтологии мира.
+
+Отвечай строго в формате JSON без пояснений.`,
+		concept.Core,
+		concept.Theme,
+		concept.Era,
+		ontology.System,
+		strings.Join(ontology.Paths, ", "),
+		strings.Join(ontology.Hierarchy, ", "),
+	)
+
+	userPrompt = fmt.Sprintf(`Создай %d жителей города «%s» (%s, население %d) в регионе «%s».
+
+Требования:
+- уникальные имена и разные роли, типичные для такого города
+- краткое описание (1-2 предложения)
+- распорядок дня из 3-5 отрезков, покрывающих сутки; время в формате ЧЧ:ММ
+
+Формат JSON:
+{
+  "npcs": [{
+    "name": "string",
+    "role": "string",
+    "description": "string",
+    "schedule": [{"from": "06:00", "to": "12:00", "activity": "string", "place": "string"}]
+  }]
+}`, count, city.Name, defaultIfEmpty(city.Type, "minor"), city.Population, city.Location.Region)
+
+	return systemPrompt, userPrompt
+}
+
+// generateCityNPCs запрашивает у Oracle жителей города одним вызовом
+func (wg *WorldGenerator) generateCityNPCs(ctx context.Context, concept *WorldConcept, ontology WorldOntology, city City, count int) ([]NPC, error) {
+	systemPrompt, userPrompt := buildNPCPrompts(concept, ontology, city, count)
+
+	var population cityPopulation
+	err := wg.oracle.CallAndUnmarshal(ctx, func() (string, error) {
+		return wg.oracle.CallStructuredJSON(ctx, systemPrompt, userPrompt)
+	}, &population)
+	if err != nil {
+		return nil, fmt.Errorf("npc generation for city %s failed: %w", city.Name, err)
+	}
+	return sanitizeNPCs(population.NPCs, count), nil
+}
+
+// sanitizeNPCs отбрасывает безымянных NPC и дубликаты имён и ограничивает число жителей
+func sanitizeNPCs(npcs []NPC, count int) []NPC {
+	seen := make(map[string]bool, len(npcs))
+	result := make([]NPC, 0, min(len(npcs), count))
+	for _, npc := range npcs {
+		name := strings.TrimSpace(npc.Name)
+		key := strings.ToLower(name)
+		if name == "" || seen[key] {
+			continue
+		}
+		if len(result) == count {
+			break
+		}
+		seen[key] = true
+		npc.Name = name
+		result = append(result, npc)
+	}
+	return result
+}
+
+// seedCityNPCs заселяет город: создаёт сущности NPC и регистрирует их фокусными сущностями scope города
+func (wg *WorldGenerator) seedCityNPCs(ctx context.Context, worldID, cityID string, city City, concept *WorldConcept, ontology WorldOntology) {
+	if wg.npcsPerCity == 0 || cityID == "" {
+		return
+	}
+	npcs, err := wg.generateCityNPCs(ctx, concept, ontology, city, wg.npcsPerCity)
+	if err != nil {
+		logging.Infof("City %s left unpopulated: %v", city.Name, err)
+		return
+	}
+
+	npcIDs := make([]string, 0, len(npcs))
+	for _, npc := range npcs {
+		npcIDs = append(npcIDs, wg.createNPCEntity(ctx, worldID, cityID, city, npc))
+	}
+	wg.registerCityFocus(ctx, worldID, cityID, city, npcIDs)
+}
+
+// createNPCEntity creates an npc entity located in its city
+func (wg *WorldGenerator) createNPCEntity(ctx context.Context, worldID, cityID string, city City, npc NPC) string {
+	npcID := "npc-" + uuid.New().String()[:8]
+
+	payload := eventbus.NewEventPayload().
+		WithEntity(npcID, "npc", npc.Name).
+		WithWorld(worldID).
+		WithScope(cityID, cityScopeType)
+
+	// Добавляем дополнительные поля через dot notation
+	eventbus.SetNested(payload.GetCustom(), "payload.name", npc.Name)
+	eventbus.SetNested(payload.GetCustom(), "payload.role", npc.Role)
+	eventbus.SetNested(payload.GetCustom(), "payload.description", npc.Description)
+	eventbus.SetNested(payload.GetCustom(), "payload.schedule", npc.Schedule)
+	eventbus.SetNested(payload.GetCustom(), "payload.city", city.Name)
+	eventbus.SetNested(payload.GetCustom(), "payload.location", city.Location)
+
+	event := eventbus.NewStructuredEvent("entity.created", "world-generator", worldID, payload)
+
+	// Связь NPC → город (LOCATED_IN)
+	event.Relations = []eventbus.Relation{
+		{
+			From:     npcID,
+			To:       cityID,
+			Type:     eventbus.RelLocatedIn,
+			Directed: true,
+			Metadata: map[string]any{"role": npc.Role},
+		},
+	}
+
+	if err := eventbus.ValidateEventRelations(event); err != nil {
+		logging.Warnf("Invalid relations for npc %s: %v", npc.Name, err)
+	}
+
+	wg.bus.Publish(ctx, eventbus.TopicSystemEvents, event)
+	return npcID
+}
+
+// registerCityFocus записывает NPC фокусными сущностями scope города: narrative-orchestrator
+// применяет переопределение при создании GM города. Затем публикуется city.population.seeded.
+func (wg *WorldGenerator) registerCityFocus(ctx context.Context, worldID, cityID string, city City, npcIDs []string) {
+	if len(npcIDs) == 0 {
+		return
+	}
+	if wg.maps != nil {
+		override := &config.Profile{ScopeType: cityScopeType, FocusEntities: npcIDs}
+		if err := config.SaveOverride(wg.maps, gmConfigBucket, cityID, override); err != nil {
+			logging.Errorf("Failed to register focus entities for city %s: %v", city.Name, err)
+		}
+	}
+
+	payload := eventbus.NewEventPayload().
+		WithEntity(cityID, "city", city.Name).
+		WithWorld(worldID).
+		WithScope(cityID, cityScopeType)
+
+	eventbus.SetNested(payload.GetCustom(), "focus_entities", npcIDs)
+	eventbus.SetNested(payload.GetCustom(), "count", len(npcIDs))
+
+	event := eventbus.NewStructuredEvent("city.population.seeded", "world-generator", worldID, payload)
+	wg.bus.Publish(ctx, eventbus.TopicSystemEvents, event)
+	logging.Infof("Seeded %d NPCs in city %s", len(npcIDs), city.Name)
+}
@@ -0,0 +1,53 @@
+package worldgenerator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test sanitizeNPCs — безымянные и повторяющиеся NPC отбрасываются, число ограничено
+func TestSanitizeNPCs(t *testing.T) {
+	npcs := []NPC{
+		{Name: " Ли Мэй ", Role: "травница"},
+		{Name: "", Role: "стражник"},
+		{Name: "ли мэй", Role: "торговка"},
+		{Name: "Чжан Вэй", Role: "кузнец"},
+		{Name: "Ван Лин", Role: "ученик"},
+	}
+
+	result := sanitizeNPCs(npcs, 2)
+
+	assert.Len(t, result, 2)
+	assert.Equal(t, "Ли Мэй", result[0].Name)
+	assert.Equal(t, "травница", result[0].Role)
+	assert.Equal(t, "Чжан Вэй", result[1].Name)
+	assert.Empty(t, sanitizeNPCs(nil, 5))
+}
+
+// Test buildNPCPrompts — промпт содержит город, регион, число жителей и онтологию мира
+func TestBuildNPCPrompts(t *testing.T) {
+	concept := &WorldConcept{Core: "Мир летающих островов", Theme: "cultivation", Era: "древний"}
+	ontology := WorldOntology{System: "cultivation", Hierarchy: []string{"Закалка тела", "Основание"}}
+	city := City{Name: "Облачный Пик", Population: 12000, Type: "major", Location: Location{Region: "Небесные горы"}}
+
+	system, user := buildNPCPrompts(concept, ontology, city, 4)
+
+	assert.True(t, strings.Contains(system, "Мир летающих островов"))
+	assert.True(t, strings.Contains(system, "Закалка тела, Основание"))
+	assert.True(t, strings.Contains(user, "Создай 4 жителей города «Облачный Пик» (major, население 12000) в регионе «Небесные горы»"))
+	assert.True(t, strings.Contains(user, `"schedule"`))
+}
+
+// Test SetNPCsPerCity — число NPC ограничено диапазоном 0..maxNPCsPerCity
+func TestSetNPCsPerCity(t *testing.T) {
+	wg := &WorldGenerator{}
+
+	wg.SetNPCsPerCity(-3)
+	assert.Equal(t, 0, wg.npcsPerCity)
+	wg.SetNPCsPerCity(7)
+	assert.Equal(t, 7, wg.npcsPerCity)
+	wg.SetNPCsPerCity(1000)
+	assert.Equal(t, maxNPCsPerCity, wg.npcsPerCity)
+}
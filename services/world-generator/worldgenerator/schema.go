@@ -5,7 +5,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+
+	"multiverse-core.io/shared/logging"
 )
 
 // BaseEntitySchema is the base schema for all entities.
@@ -35,7 +36,7 @@ const BaseEntitySchema = `{
 
 // GenerateEntitySchema generates and saves a schema for an entity type.
 func (wg *WorldGenerator) GenerateEntitySchema(ctx context.Context, entityType, worldSeed string) error {
-	log.Printf("Generating schema for entity type: %s", entityType)
+	logging.Infof("Generating schema for entity type: %s", entityType)
 
 	// Generate payload schema via Oracle
 	payloadSchemaStr, err := wg.generatePayloadSchema(ctx, entityType, worldSeed)
@@ -66,17 +67,18 @@ func (wg *WorldGenerator) GenerateEntitySchema(ctx context.Context, entityType,
 	}
 
 	// Save to OntologicalArchivist
-	if err := wg.archivist.SaveSchema(ctx, "entity", entityType, "1.0", fullSchemaBytes); err != nil {
+	version, err := wg.archivist.PublishSchema(ctx, "entity", entityType, fullSchemaBytes)
+	if err != nil {
 		return fmt.Errorf("failed to save schema to archivist: %w", err)
 	}
 
-	log.Printf("Schema for %s saved to Archivist", entityType)
+	logging.Infof("Schema for %s saved to Archivist as v%s", entityType, version)
 	return nil
 }
 
 // GenerateEntitySchemaWithArchivist generates and saves a schema for an entity type using provided archivist client
 func GenerateEntitySchemaWithArchivist(archivist *ArchivistClient, ctx context.Context, entityType, worldSeed string) error {
-	log.Printf("Generating schema for entity type: %s", entityType)
+	logging.Infof("Generating schema for entity type: %s", entityType)
 
 	// Create temporary WorldGenerator to use helper methods
 	// We need to create a copy of the archivist to avoid modifying the original
@@ -111,11 +113,12 @@ func GenerateEntitySchemaWithArchivist(archivist *ArchivistClient, ctx context.C
 	}
 
 	// Save to OntologicalArchivist
-	if err := archivist.SaveSchema(ctx, "entity", entityType, "1.0", fullSchemaBytes); err != nil {
+	version, err := archivist.PublishSchema(ctx, "entity", entityType, fullSchemaBytes)
+	if err != nil {
 		return fmt.Errorf("failed to save schema to archivist: %w", err)
 	}
 
-	log.Printf("Schema for %s saved to Archivist", entityType)
+	logging.Infof("Schema for %s saved to Archivist as v%s", entityType, version)
 	return nil
 }
 
@@ -5,6 +5,7 @@ import (
 	"context"
 
 	"multiverse-core.io/shared/eventbus"
+	storage "multiverse-core.io/shared/minio"
 )
 
 // Service manages the WorldGenerator lifecycle.
@@ -21,8 +22,19 @@ func NewService(bus *eventbus.EventBus) *Service {
 	}
 }
 
+// UseMapStorage enables storing procedural tile maps in MinIO.
+func (s *Service) UseMapStorage(client storage.ObjectStorage) {
+	s.generator.UseMapStorage(client)
+}
+
+// SetNPCsPerCity sets how many NPCs are seeded in each generated city (0 disables seeding).
+func (s *Service) SetNPCsPerCity(n int) {
+	s.generator.SetNPCsPerCity(n)
+}
+
 // Run starts the service and blocks until context is cancelled.
 func (s *Service) Run(ctx context.Context) error {
+	go s.generator.discovery.Run(ctx)
 	s.bus.Subscribe(ctx, eventbus.TopicSystemEvents, "world-generator-group", s.generator.HandleEvent)
 	<-ctx.Done()
 	return ctx.Err()
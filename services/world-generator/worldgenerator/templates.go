@@ -0,0 +1,210 @@
+// Package worldgenerator handles world templates stored in MinIO.
+package worldgenerator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// templatesBucket хранит шаблоны миров: world-templates/{name}.yaml
+const templatesBucket = "world-templates"
+
+// ErrTemplatesDisabled — шаблон запрошен, но хранилище MinIO не подключено
+var ErrTemplatesDisabled = errors.New("world templates require map storage")
+
+// templateNamePattern ограничивает имя шаблона, чтобы оно не выходило за пределы бакета
+var templateNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// WorldTemplate — пресет мира ("тёмный мир культивации", "мир паровых механизмов"):
+// ограничения онтологии, веса биомов, диапазон числа городов и фрагменты промптов Oracle
+type WorldTemplate struct {
+	Name        string              `yaml:"name"`
+	Description string              `yaml:"description,omitempty"`
+	Theme       string              `yaml:"theme,omitempty"` // тема концепции
+	Ontology    OntologyConstraints `yaml:"ontology,omitempty"`
+	// BiomeWeights — относительные доли биомов среди регионов (лес: 3, болото: 1)
+	BiomeWeights map[string]float64 `yaml:"biome_weights,omitempty"`
+	// Cities заменяет диапазон числа городов масштаба мира
+	Cities  *CountRange     `yaml:"cities,omitempty"`
+	Prompts PromptFragments `yaml:"prompts,omitempty"`
+}
+
+// OntologyConstraints — обязательные черты онтологии мира шаблона
+type OntologyConstraints struct {
+	System       string   `yaml:"system,omitempty"`       // система силы: cultivation, technology...
+	Carriers     []string `yaml:"carriers,omitempty"`     // носители силы, которые должны быть в мире
+	Forbidden    []string `yaml:"forbidden,omitempty"`    // запреты/табу, которые должны быть в мире
+	Restrictions []string `yaml:"restrictions,omitempty"` // чего не должно быть в мире
+}
+
+// CountRange — диапазон числа объектов
+type CountRange struct {
+	Min int `yaml:"min"`
+	Max int `yaml:"max"`
+}
+
+// PromptFragments — текст, добавляемый к промптам этапов генерации
+type PromptFragments struct {
+	Concept string `yaml:"concept,omitempty"`
+	Details string `yaml:"details,omitempty"`
+}
+
+func templateKey(name string) string {
+	return name + ".yaml"
+}
+
+// ParseWorldTemplate разбирает YAML шаблона и проверяет его
+func ParseWorldTemplate(data []byte) (*WorldTemplate, error) {
+	var tpl WorldTemplate
+	if err := yaml.Unmarshal(data, &tpl); err != nil {
+		return nil, fmt.Errorf("invalid world template: %w", err)
+	}
+	if tpl.Cities != nil && (tpl.Cities.Min < 0 || tpl.Cities.Max < tpl.Cities.Min) {
+		return nil, fmt.Errorf("world template %s: invalid cities range %d-%d", tpl.Name, tpl.Cities.Min, tpl.Cities.Max)
+	}
+	for biome, weight := range tpl.BiomeWeights {
+		if weight < 0 {
+			return nil, fmt.Errorf("world template %s: negative weight of biome %s", tpl.Name, biome)
+		}
+	}
+	return &tpl, nil
+}
+
+// loadTemplate загружает шаблон мира из MinIO; отсутствующий шаблон — storage.ErrNotFound
+func (wg *WorldGenerator) loadTemplate(ctx context.Context, name string) (*WorldTemplate, error) {
+	if !templateNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid world template name %q", name)
+	}
+	if wg.maps == nil {
+		return nil, ErrTemplatesDisabled
+	}
+	data, err := wg.maps.GetObject(templatesBucket, templateKey(name))
+	if err != nil {
+		return nil, fmt.Errorf("world template %s: %w", name, err)
+	}
+	tpl, err := ParseWorldTemplate(data)
+	if err != nil {
+		return nil, err
+	}
+	if tpl.Name == "" {
+		tpl.Name = name
+	}
+	return tpl, nil
+}
+
+// conceptFragment возвращает требования шаблона к концепции мира; nil — пустая строка
+func (t *WorldTemplate) conceptFragment() string {
+	if t == nil {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n\nШаблон мира «%s»", t.Name)
+	if t.Description != "" {
+		fmt.Fprintf(&b, ": %s", t.Description)
+	}
+	b.WriteString("\nКонцепция обязана соответствовать шаблону.")
+	if t.Theme != "" {
+		fmt.Fprintf(&b, "\nТема: %s", t.Theme)
+	}
+	if len(t.Ontology.Restrictions) > 0 {
+		fmt.Fprintf(&b, "\nЧего НЕ должно быть: %s", strings.Join(t.Ontology.Restrictions, ", "))
+	}
+	if t.Prompts.Concept != "" {
+		fmt.Fprintf(&b, "\n%s", strings.TrimSpace(t.Prompts.Concept))
+	}
+	return b.String()
+}
+
+// detailsFragment возвращает требования шаблона к онтологии и географии мира
+func (t *WorldTemplate) detailsFragment() string {
+	if t == nil {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n\nТребования шаблона мира «%s»:", t.Name)
+	if t.Ontology.System != "" {
+		fmt.Fprintf(&b, "\n- ontology.system: %s", t.Ontology.System)
+	}
+	if len(t.Ontology.Carriers) > 0 {
+		fmt.Fprintf(&b, "\n- ontology.carriers обязательно включают: %s", strings.Join(t.Ontology.Carriers, ", "))
+	}
+	if len(t.Ontology.Forbidden) > 0 {
+		fmt.Fprintf(&b, "\n- ontology.forbidden обязательно включают: %s", strings.Join(t.Ontology.Forbidden, ", "))
+	}
+	if len(t.Ontology.Restrictions) > 0 {
+		fmt.Fprintf(&b, "\n- в мире НЕ должно быть: %s", strings.Join(t.Ontology.Restrictions, ", "))
+	}
+	if shares := t.biomeShares(); shares != "" {
+		fmt.Fprintf(&b, "\n- доли биомов среди регионов: %s", shares)
+	}
+	if t.Prompts.Details != "" {
+		fmt.Fprintf(&b, "\n%s", strings.TrimSpace(t.Prompts.Details))
+	}
+	return b.String()
+}
+
+// biomeShares описывает веса биомов в процентах, по убыванию доли
+func (t *WorldTemplate) biomeShares() string {
+	total := 0.0
+	biomes := make([]string, 0, len(t.BiomeWeights))
+	for biome, weight := range t.BiomeWeights {
+		if weight > 0 {
+			total += weight
+			biomes = append(biomes, biome)
+		}
+	}
+	if total == 0 {
+		return ""
+	}
+	sort.Slice(biomes, func(i, j int) bool {
+		wi, wj := t.BiomeWeights[biomes[i]], t.BiomeWeights[biomes[j]]
+		if wi != wj {
+			return wi > wj
+		}
+		return biomes[i] < biomes[j]
+	})
+	shares := make([]string, 0, len(biomes))
+	for _, biome := range biomes {
+		shares = append(shares, fmt.Sprintf("%s %.0f%%", biome, 100*t.BiomeWeights[biome]/total))
+	}
+	return strings.Join(shares, ", ")
+}
+
+// cityRange возвращает диапазон числа городов: из шаблона или по масштабу
+func (t *WorldTemplate) cityRange(minCities, maxCities int) (int, int) {
+	if t == nil || t.Cities == nil {
+		return minCities, maxCities
+	}
+	return t.Cities.Min, t.Cities.Max
+}
+
+// apply приводит сгенерированный мир к жёстким ограничениям шаблона: система силы,
+// обязательные носители и запреты, не больше Cities.Max городов
+func (t *WorldTemplate) apply(geography *WorldGeography) {
+	if t == nil {
+		return
+	}
+	if t.Ontology.System != "" {
+		geography.Ontology.System = t.Ontology.System
+	}
+	for _, carrier := range t.Ontology.Carriers {
+		if !slices.Contains(geography.Ontology.Carriers, carrier) {
+			geography.Ontology.Carriers = append(geography.Ontology.Carriers, carrier)
+		}
+	}
+	for _, forbidden := range t.Ontology.Forbidden {
+		if !slices.Contains(geography.Ontology.Forbidden, forbidden) {
+			geography.Ontology.Forbidden = append(geography.Ontology.Forbidden, forbidden)
+		}
+	}
+	if t.Cities != nil && len(geography.Geography.Cities) > t.Cities.Max {
+		geography.Geography.Cities = geography.Geography.Cities[:t.Cities.Max]
+	}
+}
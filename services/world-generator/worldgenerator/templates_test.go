@@ -0,0 +1,92 @@
+package worldgenerator
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	storage "multiverse-core.io/shared/minio"
+	"multiverse-core.io/shared/minio/miniotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const darkCultivationTemplate = `
+name: dark-cultivation
+description: тёмный мир культивации
+theme: cultivation
+ontology:
+  system: cultivation
+  carriers: [демоническая ци]
+  forbidden: [вознесение без жертвы]
+  restrictions: [светлые боги]
+biome_weights:
+  болото: 1
+  горы: 3
+cities:
+  min: 1
+  max: 2
+prompts:
+  concept: Мир пропитан страхом перед сектами.
+  details: Города стоят у подножия гор.
+`
+
+func TestLoadTemplate(t *testing.T) {
+	maps := miniotest.New()
+	maps.Put(templatesBucket, "dark-cultivation.yaml", darkCultivationTemplate)
+	wg := &WorldGenerator{maps: maps}
+
+	tpl, err := wg.loadTemplate(context.Background(), "dark-cultivation")
+	require.NoError(t, err)
+	assert.Equal(t, "cultivation", tpl.Ontology.System)
+	assert.Equal(t, &CountRange{Min: 1, Max: 2}, tpl.Cities)
+	assert.Equal(t, "горы 75%, болото 25%", tpl.biomeShares())
+
+	_, err = wg.loadTemplate(context.Background(), "steampunk")
+	assert.True(t, storage.IsNotFound(err))
+	_, err = wg.loadTemplate(context.Background(), "../worlds/x")
+	assert.Error(t, err)
+	_, err = (&WorldGenerator{}).loadTemplate(context.Background(), "dark-cultivation")
+	assert.ErrorIs(t, err, ErrTemplatesDisabled)
+
+	_, err = ParseWorldTemplate([]byte("cities: {min: 3, max: 1}"))
+	assert.Error(t, err)
+}
+
+func TestTemplatePrompts(t *testing.T) {
+	tpl, err := ParseWorldTemplate([]byte(darkCultivationTemplate))
+	require.NoError(t, err)
+
+	_, conceptPrompt := buildConceptPrompts(&WorldGenerationRequest{Seed: "Ash", Mode: "random"}, tpl)
+	assert.Contains(t, conceptPrompt, "Шаблон мира «dark-cultivation»")
+	assert.Contains(t, conceptPrompt, "светлые боги")
+	assert.Contains(t, conceptPrompt, "Мир пропитан страхом перед сектами.")
+
+	_, detailsPrompt := buildDetailsPrompts(&WorldConcept{Core: "ядро", Theme: "cultivation"}, "large", tpl)
+	assert.Contains(t, detailsPrompt, "1-2 городов")
+	assert.NotContains(t, detailsPrompt, "4-8 городов")
+	assert.Contains(t, detailsPrompt, "ontology.system: cultivation")
+	assert.Contains(t, detailsPrompt, "горы 75%, болото 25%")
+	assert.True(t, strings.HasSuffix(detailsPrompt, "Города стоят у подножия гор."))
+
+	// Без шаблона промпты не меняются
+	_, plain := buildDetailsPrompts(&WorldConcept{Core: "ядро"}, "large", nil)
+	assert.Contains(t, plain, "4-8 городов")
+	assert.NotContains(t, plain, "Требования шаблона")
+}
+
+func TestTemplateApply(t *testing.T) {
+	tpl, err := ParseWorldTemplate([]byte(darkCultivationTemplate))
+	require.NoError(t, err)
+
+	geography := WorldGeography{
+		Ontology:  WorldOntology{System: "magic", Carriers: []string{"кровь"}, Forbidden: []string{"вознесение без жертвы"}},
+		Geography: Geography{Cities: []City{{Name: "A"}, {Name: "B"}, {Name: "C"}}},
+	}
+	tpl.apply(&geography)
+	assert.Equal(t, "cultivation", geography.Ontology.System)
+	assert.Equal(t, []string{"кровь", "демоническая ци"}, geography.Ontology.Carriers)
+	assert.Equal(t, []string{"вознесение без жертвы"}, geography.Ontology.Forbidden)
+	assert.Len(t, geography.Geography.Cities, 2)
+}
@@ -0,0 +1,260 @@
+// Package worldgenerator handles tile map generation from world geography.
+package worldgenerator
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+)
+
+// Процедурный слой: детерминированная карта высот и биомов, растеризующая регионы и водоёмы Oracle.
+// Одинаковые seed и география всегда дают одинаковую карту.
+
+const (
+	// tileMapMagic и tileMapVersion открывают бинарный формат карты
+	tileMapMagic   = "MVTM"
+	tileMapVersion = 1
+
+	// SeaLevel — высота (0–255), ниже которой тайл считается водой
+	SeaLevel = 90
+
+	// NoRegion — индекс региона тайла, не принадлежащего ни одному региону
+	NoRegion = 255
+
+	// waterBiome — биом с индексом 0 в палитре карты
+	waterBiome = "water"
+
+	noiseOctaves = 4
+)
+
+// ErrInvalidTileMap — данные не являются картой тайлов поддерживаемой версии
+var ErrInvalidTileMap = errors.New("invalid tile map")
+
+// TileMap — растровая карта мира. Слои хранятся построчно (индекс тайла — y*Width+x).
+type TileMap struct {
+	Width    int
+	Height   int
+	TileSize float64 // размер тайла в единицах координат мира
+	Origin   Point   // координаты угла тайла (0, 0); хранится в JSON-индексе
+
+	Heights []uint8 // высота 0–255
+	Biomes  []uint8 // индекс в Palette
+	Regions []uint8 // индекс региона в Geography.Regions или NoRegion
+
+	Palette []string // биомы; Palette[0] — вода
+}
+
+// mapResolution возвращает число тайлов на сторону исходного мира 100×100 по масштабу
+func mapResolution(scale string) int {
+	switch scale {
+	case "small":
+		return 64
+	case "large":
+		return 256
+	default: // "medium"
+		return 128
+	}
+}
+
+// noise — фрактальный value noise, детерминированный по seed
+type noise struct {
+	seed uint64
+}
+
+func newNoise(seed string) noise {
+	h := fnv.New64a()
+	h.Write([]byte(seed))
+	return noise{seed: h.Sum64()}
+}
+
+// lattice возвращает псевдослучайное значение 0–1 в узле решётки
+func (n noise) lattice(x, y int, octave int) float64 {
+	v := n.seed ^ uint64(int64(x))*0x9E3779B97F4A7C15 ^ uint64(int64(y))*0xC2B2AE3D27D4EB4F ^ uint64(octave)*0x165667B19E3779F9
+	v ^= v >> 33
+	v *= 0xFF51AFD7ED558CCD
+	v ^= v >> 33
+	v *= 0xC4CEB9FE1A85EC53
+	v ^= v >> 33
+	return float64(v>>11) / float64(1<<53)
+}
+
+func smoothstep(t float64) float64 {
+	return t * t * (3 - 2*t)
+}
+
+func lerp(a, b, t float64) float64 {
+	return a + (b-a)*t
+}
+
+// at возвращает значение шума 0–1 в точке (x, y), заданной в единицах координат мира
+func (n noise) at(x, y float64) float64 {
+	var sum, norm float64
+	frequency, amplitude := 1.0/25, 1.0
+	for octave := 0; octave < noiseOctaves; octave++ {
+		fx, fy := x*frequency, y*frequency
+		x0, y0 := int(math.Floor(fx)), int(math.Floor(fy))
+		tx, ty := smoothstep(fx-float64(x0)), smoothstep(fy-float64(y0))
+		top := lerp(n.lattice(x0, y0, octave), n.lattice(x0+1, y0, octave), tx)
+		bottom := lerp(n.lattice(x0, y0+1, octave), n.lattice(x0+1, y0+1, octave), tx)
+		sum += lerp(top, bottom, ty) * amplitude
+		norm += amplitude
+		frequency *= 2
+		amplitude /= 2
+	}
+	return sum / norm
+}
+
+// GenerateTileMap строит карту мира в границах bounds из тайлов размера tileSize.
+// Сетка тайлов привязана к координатам мира (угол кратен tileSize), а высоты берутся из шума
+// в координатах мира, поэтому после расширения границ рельеф прежней части не меняется. Водоёмы Oracle опускаются ниже уровня моря (реки — до берега),
+// тайлы суши получают биом ближайшего с учётом размера региона.
+func GenerateTileMap(seed string, geo Geography, bounds WorldBounds, tileSize float64) *TileMap {
+	origin := Point{X: math.Floor(bounds.MinX/tileSize) * tileSize, Y: math.Floor(bounds.MinY/tileSize) * tileSize}
+	width := int(math.Ceil((bounds.MaxX - origin.X) / tileSize))
+	height := int(math.Ceil((bounds.MaxY - origin.Y) / tileSize))
+	tiles := width * height
+	m := &TileMap{
+		Width:    width,
+		Height:   height,
+		TileSize: tileSize,
+		Origin:   origin,
+		Heights:  make([]uint8, tiles),
+		Biomes:   make([]uint8, tiles),
+		Regions:  make([]uint8, tiles),
+		Palette:  []string{waterBiome},
+	}
+	// Не более 254 регионов адресуются байтом; остальные не растеризуются
+	regions := geo.Regions
+	if len(regions) >= NoRegion {
+		regions = regions[:NoRegion-1]
+	}
+	regionBiome := make([]uint8, len(regions))
+	for i, r := range regions {
+		regionBiome[i] = m.biomeIndex(r.Biome)
+	}
+
+	n := newNoise(seed)
+	for ty := 0; ty < height; ty++ {
+		for tx := 0; tx < width; tx++ {
+			i := ty*width + tx
+			p := Point{X: m.Origin.X + (float64(tx)+0.5)*tileSize, Y: m.Origin.Y + (float64(ty)+0.5)*tileSize}
+
+			// Суша поднимается над уровнем моря, вода прорезается по кругам водоёмов
+			height := float64(SeaLevel) + n.at(p.X, p.Y)*float64(255-SeaLevel)
+			for _, w := range geo.WaterBodies {
+				r := radius(w.Size)
+				d := distance(p, w.Coordinates)
+				if r == 0 || d >= r {
+					continue
+				}
+				floor := float64(SeaLevel) * 0.5
+				if strings.EqualFold(w.Type, "river") {
+					floor = float64(SeaLevel) - 1
+				}
+				// Глубже к центру водоёма
+				height = math.Min(height, lerp(floor, float64(SeaLevel)-1, d/r))
+			}
+			m.Heights[i] = uint8(math.Round(math.Max(0, math.Min(255, height))))
+
+			m.Regions[i] = NoRegion
+			if region, ok := nearestRegion(p, regions); ok {
+				m.Regions[i] = uint8(region)
+				if m.Heights[i] >= SeaLevel {
+					m.Biomes[i] = regionBiome[region]
+				}
+			}
+		}
+	}
+	return m
+}
+
+// biomeIndex возвращает индекс биома в палитре, добавляя новый
+func (m *TileMap) biomeIndex(biome string) uint8 {
+	if biome == "" {
+		biome = "unknown"
+	}
+	for i, b := range m.Palette {
+		if b == biome {
+			return uint8(i)
+		}
+	}
+	if len(m.Palette) >= 256 {
+		return 0
+	}
+	m.Palette = append(m.Palette, biome)
+	return uint8(len(m.Palette) - 1)
+}
+
+// nearestRegion — регион с наименьшим отношением расстояния к радиусу:
+// крупный регион занимает больше тайлов, а вся карта покрыта регионами
+func nearestRegion(p Point, regions []Region) (int, bool) {
+	best, bestScore := -1, math.Inf(1)
+	for i, r := range regions {
+		score := distance(p, r.Coordinates) / math.Max(radius(r.Size), 1)
+		if score < bestScore {
+			best, bestScore = i, score
+		}
+	}
+	return best, best >= 0
+}
+
+// At возвращает индекс тайла для точки в координатах мира
+func (m *TileMap) At(p Point) (int, bool) {
+	x, y := p.X-m.Origin.X, p.Y-m.Origin.Y
+	tx, ty := int(x/m.TileSize), int(y/m.TileSize)
+	if x < 0 || y < 0 || tx >= m.Width || ty >= m.Height {
+		return 0, false
+	}
+	return ty*m.Width + tx, true
+}
+
+// MarshalBinary кодирует карту: заголовок "MVTM", версия (1 байт), ширина и высота (uint16 BE),
+// размер тайла (float32 BE), затем слои высот, биомов и регионов по Width*Height байт.
+// Палитра и имена регионов хранятся в JSON-индексе.
+func (m *TileMap) MarshalBinary() ([]byte, error) {
+	tiles := m.Width * m.Height
+	if len(m.Heights) != tiles || len(m.Biomes) != tiles || len(m.Regions) != tiles || m.Width > math.MaxUint16 || m.Height > math.MaxUint16 {
+		return nil, fmt.Errorf("%w: layer sizes do not match %dx%d", ErrInvalidTileMap, m.Width, m.Height)
+	}
+	var buf bytes.Buffer
+	buf.Grow(len(tileMapMagic) + 9 + 3*tiles)
+	buf.WriteString(tileMapMagic)
+	buf.WriteByte(tileMapVersion)
+	binary.Write(&buf, binary.BigEndian, uint16(m.Width))
+	binary.Write(&buf, binary.BigEndian, uint16(m.Height))
+	binary.Write(&buf, binary.BigEndian, float32(m.TileSize))
+	buf.Write(m.Heights)
+	buf.Write(m.Biomes)
+	buf.Write(m.Regions)
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary декодирует карту, записанную MarshalBinary (палитра и Origin — из индекса)
+func (m *TileMap) UnmarshalBinary(data []byte) error {
+	header := len(tileMapMagic) + 9
+	if len(data) < header || string(data[:len(tileMapMagic)]) != tileMapMagic {
+		return fmt.Errorf("%w: bad header", ErrInvalidTileMap)
+	}
+	if version := data[len(tileMapMagic)]; version != tileMapVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidTileMap, version)
+	}
+	fields := data[len(tileMapMagic)+1:]
+	width := int(binary.BigEndian.Uint16(fields[0:2]))
+	height := int(binary.BigEndian.Uint16(fields[2:4]))
+	tileSize := math.Float32frombits(binary.BigEndian.Uint32(fields[4:8]))
+
+	tiles := width * height
+	if len(data) != header+3*tiles {
+		return fmt.Errorf("%w: expected %d bytes for %dx%d, got %d", ErrInvalidTileMap, header+3*tiles, width, height, len(data))
+	}
+	layers := data[header:]
+	m.Width, m.Height, m.TileSize = width, height, float64(tileSize)
+	m.Heights = append([]uint8(nil), layers[:tiles]...)
+	m.Biomes = append([]uint8(nil), layers[tiles:2*tiles]...)
+	m.Regions = append([]uint8(nil), layers[2*tiles:]...)
+	return nil
+}
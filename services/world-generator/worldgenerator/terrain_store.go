@@ -0,0 +1,127 @@
+// Package worldgenerator handles tile map and map index storage in MinIO.
+package worldgenerator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+)
+
+// mapsBucket хранит карты миров: {world_id}/tiles.bin и {world_id}/index.json
+const mapsBucket = "maps"
+
+// MapIndex — JSON-индекс бинарной карты: палитра биомов, регионы и параметры растра
+type MapIndex struct {
+	WorldID     string           `json:"world_id"`
+	Seed        string           `json:"seed"`
+	Format      string           `json:"format"` // "MVTM/1"
+	Width       int              `json:"width"`
+	Height      int              `json:"height"`
+	TileSize    float64          `json:"tile_size"`
+	Origin      Point            `json:"origin"` // координаты угла тайла (0, 0)
+	Bounds      WorldBounds      `json:"bounds"`
+	SeaLevel    int              `json:"sea_level"`
+	Layers      []string         `json:"layers"` // порядок слоёв в tiles.bin
+	Biomes      []string         `json:"biomes"` // индекс слоя biome → биом
+	Regions     []MapIndexRegion `json:"regions"`
+	DataObject  string           `json:"data_object"`
+	GeneratedAt time.Time        `json:"generated_at"`
+}
+
+// MapIndexRegion — регион карты и число его тайлов суши
+type MapIndexRegion struct {
+	Index int    `json:"index"`
+	Name  string `json:"name"`
+	Biome string `json:"biome"`
+	Tiles int    `json:"land_tiles"`
+}
+
+// newMapIndex описывает карту m мира worldID
+func newMapIndex(worldID, seed string, m *TileMap, geo Geography, bounds WorldBounds) MapIndex {
+	landTiles := make(map[uint8]int)
+	for i, region := range m.Regions {
+		if region != NoRegion && m.Heights[i] >= SeaLevel {
+			landTiles[region]++
+		}
+	}
+	regions := make([]MapIndexRegion, 0, len(geo.Regions))
+	for i, r := range geo.Regions {
+		if i >= NoRegion {
+			break
+		}
+		regions = append(regions, MapIndexRegion{Index: i, Name: r.Name, Biome: r.Biome, Tiles: landTiles[uint8(i)]})
+	}
+	return MapIndex{
+		WorldID:     worldID,
+		Seed:        seed,
+		Format:      fmt.Sprintf("%s/%d", tileMapMagic, tileMapVersion),
+		Width:       m.Width,
+		Height:      m.Height,
+		TileSize:    m.TileSize,
+		Origin:      m.Origin,
+		Bounds:      bounds,
+		SeaLevel:    SeaLevel,
+		Layers:      []string{"height", "biome", "region"},
+		Biomes:      m.Palette,
+		Regions:     regions,
+		DataObject:  worldID + "/tiles.bin",
+		GeneratedAt: time.Now().UTC(),
+	}
+}
+
+// UseMapStorage включает сохранение процедурных карт в MinIO; без хранилища карта не строится
+func (wg *WorldGenerator) UseMapStorage(client storage.ObjectStorage) {
+	wg.maps = client
+}
+
+// generateTileMap строит карту мира, сохраняет её и публикует world.map.generated
+func (wg *WorldGenerator) generateTileMap(ctx context.Context, worldID, seed string, geo Geography, bounds WorldBounds, scale string) {
+	if wg.maps == nil {
+		return
+	}
+	m := GenerateTileMap(seed, geo, bounds, defaultWorldBounds.Width()/float64(mapResolution(scale)))
+	index := newMapIndex(worldID, seed, m, geo, bounds)
+
+	data, err := m.MarshalBinary()
+	if err != nil {
+		logging.Errorf("Failed to encode tile map for world %s: %v", worldID, err)
+		return
+	}
+	indexJSON, err := json.Marshal(index)
+	if err != nil {
+		logging.Errorf("Failed to encode tile map index for world %s: %v", worldID, err)
+		return
+	}
+	// Индекс сохраняется последним: его наличие означает, что карта записана целиком
+	if err := wg.maps.PutObject(mapsBucket, index.DataObject, bytes.NewReader(data), int64(len(data))); err != nil {
+		logging.Errorf("Failed to store tile map for world %s: %v", worldID, err)
+		return
+	}
+	if err := wg.maps.PutObject(mapsBucket, worldID+"/index.json", bytes.NewReader(indexJSON), int64(len(indexJSON))); err != nil {
+		logging.Errorf("Failed to store tile map index for world %s: %v", worldID, err)
+		return
+	}
+
+	payload := eventbus.NewEventPayload().
+		WithWorld(worldID)
+
+	eventbus.SetNested(payload.GetCustom(), "seed", seed)
+	eventbus.SetNested(payload.GetCustom(), "width", m.Width)
+	eventbus.SetNested(payload.GetCustom(), "height", m.Height)
+	eventbus.SetNested(payload.GetCustom(), "tile_size", m.TileSize)
+	eventbus.SetNested(payload.GetCustom(), "bucket", mapsBucket)
+	eventbus.SetNested(payload.GetCustom(), "index_object", worldID+"/index.json")
+	eventbus.SetNested(payload.GetCustom(), "data_object", index.DataObject)
+	eventbus.SetNested(payload.GetCustom(), "biomes", m.Palette)
+	eventbus.SetNested(payload.GetCustom(), "bounds", bounds)
+
+	event := eventbus.NewStructuredEvent("world.map.generated", "world-generator", worldID, payload)
+	wg.bus.Publish(ctx, eventbus.TopicSystemEvents, event)
+	logging.Infof("Published world.map.generated for world %s (%dx%d tiles, %d bytes)", worldID, m.Width, m.Height, len(data))
+}
@@ -0,0 +1,89 @@
+package worldgenerator
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testTerrainGeography() Geography {
+	return Geography{
+		Regions: []Region{
+			{Name: "Лес", Biome: "forest", Coordinates: Point{X: 25, Y: 50}, Size: 1256.6},
+			{Name: "Пустыня", Biome: "desert", Coordinates: Point{X: 75, Y: 50}, Size: 1256.6},
+		},
+		WaterBodies: []WaterBody{
+			{Name: "Озеро", Type: "lake", Coordinates: Point{X: 75, Y: 50}, Size: 78.54},
+		},
+	}
+}
+
+// Test GenerateTileMap — одинаковый seed даёт одинаковую карту, другой seed — другие высоты
+func TestGenerateTileMap_Deterministic(t *testing.T) {
+	geo := testTerrainGeography()
+	a := GenerateTileMap("Eternal Void", geo, defaultWorldBounds, 100.0/64)
+	b := GenerateTileMap("Eternal Void", geo, defaultWorldBounds, 100.0/64)
+	c := GenerateTileMap("Jade Heavens", geo, defaultWorldBounds, 100.0/64)
+
+	assert.Equal(t, a, b)
+	assert.NotEqual(t, a.Heights, c.Heights)
+}
+
+// Test GenerateTileMap — регионы и водоёмы растеризуются в слои биомов и высот
+func TestGenerateTileMap_RasterizesGeography(t *testing.T) {
+	m := GenerateTileMap("seed", testTerrainGeography(), defaultWorldBounds, 1)
+
+	assert.Equal(t, []string{"water", "forest", "desert"}, m.Palette)
+	assert.Len(t, m.Heights, 100*100)
+
+	forest, _ := m.At(Point{X: 25, Y: 50})
+	assert.Equal(t, uint8(0), m.Regions[forest])
+	assert.Equal(t, uint8(1), m.Biomes[forest])
+	assert.True(t, m.Heights[forest] >= SeaLevel, "land must be above sea level")
+
+	lake, _ := m.At(Point{X: 75, Y: 50})
+	assert.Equal(t, uint8(1), m.Regions[lake])
+	assert.Equal(t, uint8(0), m.Biomes[lake])
+	assert.True(t, m.Heights[lake] < SeaLevel, "lake must be below sea level")
+
+	// Каждый тайл принадлежит какому-то региону
+	for _, region := range m.Regions {
+		assert.NotEqual(t, uint8(NoRegion), region)
+	}
+
+	_, ok := m.At(Point{X: 120, Y: 50})
+	assert.False(t, ok)
+}
+
+// Test TileMap — бинарный формат сохраняет все слои
+func TestTileMap_BinaryRoundTrip(t *testing.T) {
+	m := GenerateTileMap("seed", testTerrainGeography(), defaultWorldBounds, 100.0/64)
+	data, err := m.MarshalBinary()
+	assert.NoError(t, err)
+	assert.Len(t, data, 13+3*64*64)
+
+	var decoded TileMap
+	assert.NoError(t, decoded.UnmarshalBinary(data))
+	assert.Equal(t, m.Width, decoded.Width)
+	assert.Equal(t, m.Heights, decoded.Heights)
+	assert.Equal(t, m.Biomes, decoded.Biomes)
+	assert.Equal(t, m.Regions, decoded.Regions)
+	assert.InDelta(t, m.TileSize, decoded.TileSize, 1e-6)
+
+	assert.True(t, errors.Is(decoded.UnmarshalBinary(data[:20]), ErrInvalidTileMap))
+	assert.True(t, errors.Is(decoded.UnmarshalBinary([]byte("PNG\x00....")), ErrInvalidTileMap))
+}
+
+// Test newMapIndex — индекс описывает палитру и тайлы суши регионов
+func TestNewMapIndex(t *testing.T) {
+	geo := testTerrainGeography()
+	m := GenerateTileMap("seed", geo, defaultWorldBounds, 100.0/64)
+	index := newMapIndex("world-1", "seed", m, geo, defaultWorldBounds)
+
+	assert.Equal(t, "MVTM/1", index.Format)
+	assert.Equal(t, "world-1/tiles.bin", index.DataObject)
+	assert.Equal(t, m.Palette, index.Biomes)
+	assert.Len(t, index.Regions, 2)
+	assert.True(t, index.Regions[0].Tiles > index.Regions[1].Tiles, "the lake takes land from the desert")
+}
@@ -0,0 +1,66 @@
+// Package worldgenerator handles storage of generated world records.
+package worldgenerator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"multiverse-core.io/shared/logging"
+)
+
+// worldsBucket хранит состояние сгенерированных миров: {world_id}/world.json
+const worldsBucket = "worlds"
+
+// WorldRecord — состояние мира, необходимое для его расширения: концепция, онтология,
+// исправленная география, текущие границы и ID сущностей регионов
+type WorldRecord struct {
+	WorldID   string            `json:"world_id"`
+	Seed      string            `json:"seed"`
+	Scale     string            `json:"scale"`
+	Concept   WorldConcept      `json:"concept"`
+	Ontology  WorldOntology     `json:"ontology"`
+	Geography Geography         `json:"geography"`
+	Bounds    WorldBounds       `json:"bounds"`
+	RegionIDs map[string]string `json:"region_ids"` // имя региона → ID сущности
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+func worldRecordKey(worldID string) string {
+	return worldID + "/world.json"
+}
+
+// saveWorld сохраняет состояние мира; без хранилища мир не сможет расширяться
+func (wg *WorldGenerator) saveWorld(ctx context.Context, record *WorldRecord) error {
+	if wg.maps == nil {
+		return nil
+	}
+	record.UpdatedAt = time.Now().UTC()
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if err := wg.maps.PutObject(worldsBucket, worldRecordKey(record.WorldID), bytes.NewReader(data), int64(len(data))); err != nil {
+		logging.Errorf("Failed to store world record %s: %v", record.WorldID, err)
+		return err
+	}
+	return nil
+}
+
+// loadWorld загружает состояние мира; отсутствующий мир — storage.ErrNotFound
+func (wg *WorldGenerator) loadWorld(ctx context.Context, worldID string) (*WorldRecord, error) {
+	data, err := wg.maps.GetObject(worldsBucket, worldRecordKey(worldID))
+	if err != nil {
+		return nil, err
+	}
+	var record WorldRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("corrupted world record %s: %w", worldID, err)
+	}
+	if record.RegionIDs == nil {
+		record.RegionIDs = make(map[string]string)
+	}
+	return &record, nil
+}
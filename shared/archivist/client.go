@@ -0,0 +1,93 @@
+// Package archivist — клиент чтения схем OntologicalArchivist для сервисов,
+// которые берут правила мира из профилей онтологии.
+package archivist
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	storage "multiverse-core.io/shared/minio"
+	"multiverse-core.io/shared/registry"
+)
+
+// DefaultURL — адрес архивариуса, если не задан ARCHIVIST_URL
+const DefaultURL = "http://ontological-archivist:8081"
+
+const requestTimeout = 10 * time.Second
+
+// Client читает схемы (например, world_ontology_profile) из OntologicalArchivist.
+type Client struct {
+	// BaseURL — резервный адрес, если в реестре нет живого архивариуса
+	BaseURL    string
+	httpClient *http.Client
+	discovery  *registry.Discovery
+}
+
+// NewClient создаёт клиент архивариуса. Адрес определяется через discovery (может быть nil);
+// baseURL остаётся резервным, пустой — DefaultURL.
+func NewClient(baseURL string, discovery *registry.Discovery) *Client {
+	if baseURL == "" {
+		baseURL = DefaultURL
+	}
+	if discovery != nil {
+		discovery.SetFallback(registry.ServiceArchivist, baseURL)
+	}
+	return &Client{
+		BaseURL:    baseURL,
+		httpClient: &http.Client{Timeout: requestTimeout},
+		discovery:  discovery,
+	}
+}
+
+// GetSchema декодирует последнюю версию schemas/{schemaType}/{name} в out.
+// Отсутствующая схема — storage.ErrNotFound, недоступный архивариус — storage.ErrUnavailable.
+func (c *Client) GetSchema(ctx context.Context, schemaType, name string, out interface{}) error {
+	return c.getJSON(ctx, fmt.Sprintf("/v1/schemas/%s/%s/latest", url.PathEscape(schemaType), url.PathEscape(name)), out)
+}
+
+// GetTemplate декодирует шаблон сущности templates/{entityType}/{name} в out; версия 0 — последняя.
+// Ошибки — как у GetSchema.
+func (c *Client) GetTemplate(ctx context.Context, entityType, name string, version int, out interface{}) error {
+	path := fmt.Sprintf("/v1/templates/%s/%s", url.PathEscape(entityType), url.PathEscape(name))
+	if version > 0 {
+		path = fmt.Sprintf("%s/%d", path, version)
+	}
+	return c.getJSON(ctx, path, out)
+}
+
+// getJSON запрашивает path у живого экземпляра архивариуса и декодирует ответ в out.
+func (c *Client) getJSON(ctx context.Context, path string, out interface{}) error {
+	baseURL := c.BaseURL
+	if c.discovery != nil {
+		if resolved, err := c.discovery.Resolve(ctx, registry.ServiceArchivist); err == nil {
+			baseURL = resolved
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if c.discovery != nil {
+			c.discovery.MarkFailed(registry.ServiceArchivist, baseURL)
+		}
+		return fmt.Errorf("archivist connection failed: %v: %w", err, storage.ErrUnavailable)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("%s: %w", path, storage.ErrNotFound)
+	case resp.StatusCode != http.StatusOK:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("%s returned status %d: %s: %w", path, resp.StatusCode, string(body), storage.ErrUnavailable)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
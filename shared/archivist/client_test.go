@@ -0,0 +1,79 @@
+package archivist
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	storage "multiverse-core.io/shared/minio"
+)
+
+func TestGetSchema(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/schemas/world_ontology_profile/world-1/latest":
+			w.Write([]byte(`{"item_rules": [{"id": "no-trade"}]}`))
+		case "/v1/schemas/world_ontology_profile/broken/latest":
+			http.Error(w, "boom", http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, nil)
+	ctx := context.Background()
+
+	var profile struct {
+		ItemRules []struct {
+			ID string `json:"id"`
+		} `json:"item_rules"`
+	}
+	if err := client.GetSchema(ctx, "world_ontology_profile", "world-1", &profile); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(profile.ItemRules) != 1 || profile.ItemRules[0].ID != "no-trade" {
+		t.Errorf("unexpected profile %+v", profile)
+	}
+
+	if err := client.GetSchema(ctx, "world_ontology_profile", "world-2", &profile); !storage.IsNotFound(err) {
+		t.Errorf("expected not found, got %v", err)
+	}
+	if err := client.GetSchema(ctx, "world_ontology_profile", "broken", &profile); !storage.IsUnavailable(err) {
+		t.Errorf("expected unavailable on a server error, got %v", err)
+	}
+	server.Close()
+	if err := client.GetSchema(ctx, "world_ontology_profile", "world-1", &profile); !storage.IsUnavailable(err) {
+		t.Errorf("expected unavailable without the archivist, got %v", err)
+	}
+}
+
+func TestGetTemplate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/templates/npc/guard":
+			w.Write([]byte(`{"name": "guard", "version": 3}`))
+		case "/v1/templates/npc/guard/2":
+			w.Write([]byte(`{"name": "guard", "version": 2}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, nil)
+	ctx := context.Background()
+
+	var tmpl struct {
+		Name    string `json:"name"`
+		Version int    `json:"version"`
+	}
+	if err := client.GetTemplate(ctx, "npc", "guard", 0, &tmpl); err != nil || tmpl.Version != 3 {
+		t.Errorf("expected the latest version, got %+v, %v", tmpl, err)
+	}
+	if err := client.GetTemplate(ctx, "npc", "guard", 2, &tmpl); err != nil || tmpl.Version != 2 {
+		t.Errorf("expected version 2, got %+v, %v", tmpl, err)
+	}
+	if err := client.GetTemplate(ctx, "npc", "merchant", 0, &tmpl); !storage.IsNotFound(err) {
+		t.Errorf("expected not found, got %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package archivist
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+	"multiverse-core.io/shared/schema"
+)
+
+// EntitySchemaType — JSON Schema типов сущностей, имя схемы — тип сущности
+const EntitySchemaType = "entity"
+
+// entitySchemaTTL — сколько используется прочитанная (или отсутствующая) схема типа
+const entitySchemaTTL = 5 * time.Minute
+
+// cachedEntitySchema — валидатор payload типа сущности; nil — у типа нет схемы
+type cachedEntitySchema struct {
+	validator *schema.Validator
+	expiresAt time.Time
+}
+
+// EntitySchemas проверяет payload сущностей по schemas/entity/{entity_type} из OntologicalArchivist
+// и кэширует схемы по типу сущности.
+type EntitySchemas struct {
+	client *Client
+	now    func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cachedEntitySchema
+}
+
+// NewEntitySchemas создаёт проверку схем сущностей через архивариуса.
+func NewEntitySchemas(client *Client) *EntitySchemas {
+	return &EntitySchemas{
+		client: client,
+		now:    time.Now,
+		cache:  make(map[string]cachedEntitySchema),
+	}
+}
+
+// payloadValidator возвращает валидатор типа из кэша, перечитывая устаревшую схему.
+// Используется только часть payload схемы сущности: конверт строит EntityManager.
+func (s *EntitySchemas) payloadValidator(ctx context.Context, entityType string) (*schema.Validator, error) {
+	s.mu.Lock()
+	cached, ok := s.cache[entityType]
+	s.mu.Unlock()
+	if ok && s.now().Before(cached.expiresAt) {
+		return cached.validator, nil
+	}
+
+	var validator *schema.Validator
+	var entitySchema map[string]interface{}
+	switch err := s.client.GetSchema(ctx, EntitySchemaType, entityType, &entitySchema); {
+	case storage.IsNotFound(err):
+		// Схемы типа нет: промах тоже кэшируется, чтобы не обращаться к архивариусу на каждую запись
+	case err != nil:
+		return nil, err
+	default:
+		properties, _ := entitySchema["properties"].(map[string]interface{})
+		if payloadSchema, exists := properties["payload"]; exists {
+			schemaData, err := json.Marshal(payloadSchema)
+			if err != nil {
+				return nil, err
+			}
+			if validator, err = schema.NewValidator(schemaData); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	s.mu.Lock()
+	s.cache[entityType] = cachedEntitySchema{validator: validator, expiresAt: s.now().Add(entitySchemaTTL)}
+	s.mu.Unlock()
+	return validator, nil
+}
+
+// Invalidate сбрасывает схему типа, чтобы следующая проверка прочитала её заново.
+func (s *EntitySchemas) Invalidate(entityType string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.cache, entityType)
+}
+
+// HandleSchemaChange сбрасывает схему типа сущности из уведомления schema.updated.
+func (s *EntitySchemas) HandleSchemaChange(change schema.Change) {
+	if change.SchemaType != EntitySchemaType {
+		return
+	}
+	logging.Infof("Entity schema %s changed (v%s), dropping cached validator", change.Name, change.Version)
+	s.Invalidate(change.Name)
+}
+
+// Validate возвращает нарушения схемы в payload. У типов без схемы нарушений нет;
+// недоступный архивариус — storage.ErrUnavailable.
+func (s *EntitySchemas) Validate(ctx context.Context, entityType string, payload map[string]interface{}) ([]schema.FieldError, error) {
+	validator, err := s.payloadValidator(ctx, entityType)
+	if err != nil || validator == nil {
+		return nil, err
+	}
+	if payload == nil {
+		payload = map[string]interface{}{}
+	}
+	return validator.ValidateFields(payload)
+}
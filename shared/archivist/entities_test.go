@@ -0,0 +1,85 @@
+package archivist
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"multiverse-core.io/shared/schema"
+)
+
+const npcSchema = `{
+	"type": "object",
+	"properties": {
+		"entity_id": {"type": "string"},
+		"payload": {
+			"type": "object",
+			"properties": {"name": {"type": "string"}, "level": {"type": "integer"}},
+			"required": ["name"]
+		}
+	}
+}`
+
+func newEntitySchemaServer(t *testing.T, requests *int32) *Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		if r.URL.Path != "/v1/schemas/entity/npc/latest" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(npcSchema))
+	}))
+	t.Cleanup(server.Close)
+	return NewClient(server.URL, nil)
+}
+
+func TestEntitySchemas(t *testing.T) {
+	var requests int32
+	validator := NewEntitySchemas(newEntitySchemaServer(t, &requests))
+	ctx := context.Background()
+
+	violations, err := validator.Validate(ctx, "npc", map[string]interface{}{"name": "Borin", "level": 3})
+	if err != nil || len(violations) != 0 {
+		t.Fatalf("expected valid payload, got %v, %v", violations, err)
+	}
+
+	violations, err = validator.Validate(ctx, "npc", map[string]interface{}{"level": "high"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(violations) != 2 {
+		t.Errorf("expected missing name and wrong level type, got %v", violations)
+	}
+
+	// Типы без схемы принимаются, промах тоже кэшируется
+	for i := 0; i < 2; i++ {
+		if violations, err := validator.Validate(ctx, "item", map[string]interface{}{"anything": true}); err != nil || len(violations) != 0 {
+			t.Fatalf("expected no violations without schema, got %v, %v", violations, err)
+		}
+	}
+	if requests != 2 {
+		t.Errorf("expected one archivist request per entity type, got %d", requests)
+	}
+}
+
+func TestEntitySchemasHandleSchemaChange(t *testing.T) {
+	var requests int32
+	validator := NewEntitySchemas(newEntitySchemaServer(t, &requests))
+	ctx := context.Background()
+	payload := map[string]interface{}{"name": "Borin"}
+
+	validator.Validate(ctx, "npc", payload)
+	validator.HandleSchemaChange(schema.Change{SchemaType: "universe_core", Name: "npc", Version: "1.1"})
+	validator.Validate(ctx, "npc", payload)
+	if requests != 1 {
+		t.Fatalf("changes of other schema types must keep the cache, got %d requests", requests)
+	}
+
+	validator.HandleSchemaChange(schema.Change{SchemaType: "entity", Name: "npc", Version: "1.1"})
+	validator.Validate(ctx, "npc", payload)
+	if requests != 2 {
+		t.Errorf("expected the schema refetched after a change, got %d requests", requests)
+	}
+}
@@ -0,0 +1,114 @@
+package archivist
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/logging"
+	storage "multiverse-core.io/shared/minio"
+	"multiverse-core.io/shared/schema"
+)
+
+// WorldProfileType — профили онтологии миров, имя схемы — ID мира
+const WorldProfileType = "world_ontology_profile"
+
+// ProfileRetryInterval — пауза перед повторным чтением схемы после недоступности архивариуса
+const ProfileRetryInterval = time.Minute
+
+const profileLoadTimeout = 5 * time.Second
+
+// profileEntry — значение, построенное из схемы одного мира
+type profileEntry[T any] struct {
+	value T
+	// found — false, если схемы нет и действует fallback
+	found bool
+	// retryAt задан, если архивариус был недоступен
+	retryAt time.Time
+}
+
+// ProfileCache хранит значения, построенные из схем архивариуса по имени (обычно ID мира),
+// и читает схему при первом обращении. Без схемы действует fallback; если архивариус недоступен,
+// остаётся прежнее значение, а чтение повторяется не раньше чем через ProfileRetryInterval.
+// Без клиента всегда действует fallback.
+type ProfileCache[P, T any] struct {
+	schemaType string
+	fallback   T
+	build      func(name string, profile P) T
+	client     *Client
+
+	mu      sync.RWMutex
+	entries map[string]*profileEntry[T]
+}
+
+// NewProfileCache создаёт кэш схем schemaType: build строит значение из декодированной схемы P.
+func NewProfileCache[P, T any](schemaType string, fallback T, build func(name string, profile P) T) *ProfileCache[P, T] {
+	return &ProfileCache[P, T]{
+		schemaType: schemaType,
+		fallback:   fallback,
+		build:      build,
+		entries:    make(map[string]*profileEntry[T]),
+	}
+}
+
+// UseClient читает схемы из OntologicalArchivist через client.
+func (c *ProfileCache[P, T]) UseClient(client *Client) {
+	c.client = client
+}
+
+// Get возвращает значение для name, читая схему при первом обращении или после истечения паузы.
+// false — схемы нет и возвращён fallback.
+func (c *ProfileCache[P, T]) Get(name string) (T, bool) {
+	c.mu.RLock()
+	entry, loaded := c.entries[name]
+	c.mu.RUnlock()
+	if c.client != nil && name != "" && (!loaded || (!entry.retryAt.IsZero() && time.Now().After(entry.retryAt))) {
+		entry = c.load(context.Background(), name)
+	}
+	if entry == nil || !entry.found {
+		return c.fallback, false
+	}
+	return entry.value, true
+}
+
+// Set кладёт значение для name, не обращаясь к архивариусу.
+func (c *ProfileCache[P, T]) Set(name string, value T) {
+	c.mu.Lock()
+	c.entries[name] = &profileEntry[T]{value: value, found: true}
+	c.mu.Unlock()
+}
+
+// HandleSchemaChange перечитывает схему, когда архивариус объявляет её новую версию.
+func (c *ProfileCache[P, T]) HandleSchemaChange(change schema.Change) {
+	if c.client == nil || change.SchemaType != c.schemaType {
+		return
+	}
+	c.load(context.Background(), change.Name)
+}
+
+// load читает схему name и заменяет значение в кэше.
+func (c *ProfileCache[P, T]) load(ctx context.Context, name string) *profileEntry[T] {
+	ctx, cancel := context.WithTimeout(ctx, profileLoadTimeout)
+	defer cancel()
+
+	entry := &profileEntry[T]{value: c.fallback}
+	var profile P
+	switch err := c.client.GetSchema(ctx, c.schemaType, name, &profile); {
+	case err == nil:
+		entry.value, entry.found = c.build(name, profile), true
+	case errors.Is(err, storage.ErrNotFound):
+		// Схемы нет: действует fallback
+	default:
+		logging.Warnf("Schema %s/%s unavailable, keeping current value: %v", c.schemaType, name, err)
+		entry.retryAt = time.Now().Add(ProfileRetryInterval)
+	}
+
+	c.mu.Lock()
+	if previous, ok := c.entries[name]; ok && !entry.retryAt.IsZero() {
+		entry.value, entry.found = previous.value, previous.found
+	}
+	c.entries[name] = entry
+	c.mu.Unlock()
+	return entry
+}
@@ -0,0 +1,75 @@
+package archivist
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"multiverse-core.io/shared/schema"
+)
+
+func TestProfileCache(t *testing.T) {
+	var limit, requests atomic.Int64
+	var failing atomic.Bool
+	limit.Store(3)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch {
+		case failing.Load():
+			http.Error(w, "boom", http.StatusInternalServerError)
+		case r.URL.Path == "/v1/schemas/world_ontology_profile/world-1/latest":
+			fmt.Fprintf(w, `{"limit": %d}`, limit.Load())
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	type profile struct {
+		Limit int `json:"limit"`
+	}
+	cache := NewProfileCache(WorldProfileType, 1, func(name string, p profile) int { return p.Limit })
+	if value, found := cache.Get("world-1"); value != 1 || found {
+		t.Fatalf("expected the fallback without a client, got %d (%v)", value, found)
+	}
+
+	cache.UseClient(NewClient(server.URL, nil))
+	if value, found := cache.Get("world-1"); value != 3 || !found {
+		t.Fatalf("expected the profile value, got %d (%v)", value, found)
+	}
+	if value, found := cache.Get("world-2"); value != 1 || found {
+		t.Errorf("expected the fallback without a profile, got %d (%v)", value, found)
+	}
+	cache.Get("world-1")
+	if requests.Load() != 2 {
+		t.Errorf("expected loaded profiles to be cached, got %d requests", requests.Load())
+	}
+
+	// Недоступный архивариус не сбрасывает значение, повтор — после паузы
+	failing.Store(true)
+	cache.HandleSchemaChange(schema.Change{SchemaType: WorldProfileType, Name: "world-1", Version: "v2"})
+	if value, found := cache.Get("world-1"); value != 3 || !found {
+		t.Errorf("expected the previous value while the archivist is unavailable, got %d (%v)", value, found)
+	}
+	if requests.Load() != 3 {
+		t.Errorf("expected no retry before the interval, got %d requests", requests.Load())
+	}
+
+	failing.Store(false)
+	limit.Store(5)
+	cache.HandleSchemaChange(schema.Change{SchemaType: "other_type", Name: "world-1"})
+	if value, _ := cache.Get("world-1"); value != 3 {
+		t.Errorf("changes of other schema types must be ignored, got %d", value)
+	}
+	cache.HandleSchemaChange(schema.Change{SchemaType: WorldProfileType, Name: "world-1", Version: "v3"})
+	if value, _ := cache.Get("world-1"); value != 5 {
+		t.Errorf("expected the new version after a change, got %d", value)
+	}
+
+	cache.Set("world-3", 7)
+	if value, found := cache.Get("world-3"); value != 7 || !found {
+		t.Errorf("expected the stored value, got %d (%v)", value, found)
+	}
+}
@@ -0,0 +1,250 @@
+// shared/config/env.go
+//
+// Уровни окружения, типы и проверка настроек. Setup проверяет все настройки сервиса при запуске
+// и возвращает Values — типизированный доступ к эффективной конфигурации, из которого main
+// собирает Config сервиса.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvTier — переменная окружения с уровнем окружения.
+const EnvTier = "APP_ENV"
+
+// Уровни окружения.
+const (
+	TierDevelopment = "development"
+	TierStaging     = "staging"
+	TierProduction  = "production"
+)
+
+// Type — тип значения настройки.
+type Type string
+
+// Типы значений. Пустой тип — произвольная строка.
+const (
+	TypeString   Type = ""
+	TypeInt      Type = "int"
+	TypeFloat    Type = "float"
+	TypeBool     Type = "bool"
+	TypeDuration Type = "duration" // длительность Go: 30s, 5m, 24h
+	TypeMillis   Type = "millis"   // целое число миллисекунд
+	TypeList     Type = "list"     // значения через запятую
+	TypeURL      Type = "url"      // абсолютный URL со схемой и хостом
+)
+
+// Tier возвращает уровень окружения из APP_ENV (по умолчанию development).
+func Tier() string {
+	if tier := strings.TrimSpace(os.Getenv(EnvTier)); tier != "" {
+		return strings.ToLower(tier)
+	}
+	return TierDevelopment
+}
+
+// TierFile возвращает путь файла уровня окружения рядом с основным файлом:
+// configs/game-service.yaml → configs/game-service.production.yaml.
+func TierFile(path, tier string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + tier + ext
+}
+
+// Validate проверяет эффективную конфигурацию и возвращает одну ошибку со всеми нарушениями,
+// чтобы при запуске было видно всё, что нужно исправить.
+func Validate(settings []Setting, tier string) error {
+	var problems []string
+	for _, setting := range settings {
+		if err := validateSetting(setting, tier); err != nil {
+			problem := fmt.Sprintf("%s (%s): %v", setting.Env, setting.FileKey(), err)
+			if setting.Usage != "" {
+				problem += " — " + setting.Usage
+			}
+			problems = append(problems, problem)
+		}
+	}
+	switch tier {
+	case TierDevelopment, TierStaging, TierProduction:
+	default:
+		problems = append(problems, fmt.Sprintf("%s: unknown tier %q (expected %s, %s or %s)",
+			EnvTier, tier, TierDevelopment, TierStaging, TierProduction))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration (%s):\n  - %s", tier, strings.Join(problems, "\n  - "))
+	}
+	return nil
+}
+
+// validateSetting проверяет одну настройку: обязательность, тип и секреты в production.
+func validateSetting(setting Setting, tier string) error {
+	if setting.Value == "" {
+		if setting.Required || setting.requiredIn(tier) {
+			if setting.Required {
+				return errors.New("required")
+			}
+			return fmt.Errorf("required in %s", tier)
+		}
+		return nil
+	}
+	if err := checkType(setting.Option, setting.Value); err != nil {
+		return err
+	}
+	// Значения по умолчанию для секретов (minioadmin и т.п.) годятся только для разработки
+	if tier == TierProduction && setting.Secret && setting.Source == SourceDefault {
+		return errors.New("secret uses the development default, set it explicitly in production")
+	}
+	return nil
+}
+
+// requiredIn сообщает, обязательна ли настройка на уровне окружения.
+func (o Option) requiredIn(tier string) bool {
+	for _, t := range o.RequiredIn {
+		if t == tier {
+			return true
+		}
+	}
+	return false
+}
+
+// checkType разбирает значение по типу настройки.
+func checkType(option Option, value string) error {
+	switch option.Type {
+	case TypeInt, TypeMillis:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("expected an integer, got %q", value)
+		}
+		if option.Positive && n <= 0 {
+			return fmt.Errorf("must be positive, got %d", n)
+		}
+	case TypeFloat:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("expected a number, got %q", value)
+		}
+		if option.Positive && f <= 0 {
+			return fmt.Errorf("must be positive, got %v", f)
+		}
+	case TypeBool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("expected true or false, got %q", value)
+		}
+	case TypeDuration:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("expected a duration like 30s or 5m, got %q", value)
+		}
+		if option.Positive && d <= 0 {
+			return fmt.Errorf("must be positive, got %s", d)
+		}
+	case TypeList:
+		if len(splitList(value)) == 0 {
+			return errors.New("expected a comma-separated list")
+		}
+	case TypeURL:
+		u, err := url.Parse(value)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("expected an absolute URL, got %q", value)
+		}
+	}
+	return nil
+}
+
+// splitList разбивает список через запятую, пропуская пустые элементы.
+func splitList(value string) []string {
+	var items []string
+	for _, part := range strings.Split(value, ",") {
+		if item := strings.TrimSpace(part); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Values — проверенная эффективная конфигурация сервиса. Значения уже разобраны при проверке,
+// поэтому методы не возвращают ошибок; обращение к необъявленной настройке или чтение
+// не того типа — ошибка программиста и приводит к панике.
+type Values struct {
+	tier     string
+	settings map[string]Setting
+}
+
+// NewValues собирает Values из эффективной конфигурации (см. Apply и Validate).
+func NewValues(tier string, settings []Setting) *Values {
+	v := &Values{tier: tier, settings: make(map[string]Setting, len(settings))}
+	for _, setting := range settings {
+		v.settings[setting.Env] = setting
+	}
+	return v
+}
+
+// Tier возвращает уровень окружения сервиса.
+func (v *Values) Tier() string {
+	return v.tier
+}
+
+// setting возвращает объявленную настройку, проверяя её тип.
+func (v *Values) setting(key string, types ...Type) Setting {
+	setting, ok := v.settings[key]
+	if !ok {
+		panic(fmt.Sprintf("config: option %s is not declared", key))
+	}
+	if len(types) == 0 {
+		return setting
+	}
+	for _, t := range types {
+		if setting.Type == t {
+			return setting
+		}
+	}
+	panic(fmt.Sprintf("config: option %s has type %q, not %q", key, setting.Type, types[0]))
+}
+
+// String возвращает значение настройки как есть.
+func (v *Values) String(key string) string {
+	return v.setting(key).Value
+}
+
+// Int возвращает целое значение (0, если значение пустое).
+func (v *Values) Int(key string) int {
+	n, _ := strconv.Atoi(v.setting(key, TypeInt).Value)
+	return n
+}
+
+// Float возвращает числовое значение (0, если значение пустое).
+func (v *Values) Float(key string) float64 {
+	f, _ := strconv.ParseFloat(v.setting(key, TypeFloat).Value, 64)
+	return f
+}
+
+// Bool возвращает логическое значение (false, если значение пустое).
+func (v *Values) Bool(key string) bool {
+	b, _ := strconv.ParseBool(v.setting(key, TypeBool).Value)
+	return b
+}
+
+// Duration возвращает длительность для настроек типа TypeDuration и TypeMillis.
+func (v *Values) Duration(key string) time.Duration {
+	setting := v.setting(key, TypeDuration, TypeMillis)
+	if setting.Value == "" {
+		return 0
+	}
+	if setting.Type == TypeMillis {
+		ms, _ := strconv.Atoi(setting.Value)
+		return time.Duration(ms) * time.Millisecond
+	}
+	d, _ := time.ParseDuration(setting.Value)
+	return d
+}
+
+// List возвращает элементы списка через запятую.
+func (v *Values) List(key string) []string {
+	return splitList(v.setting(key, TypeList).Value)
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadTierOverrides(t *testing.T) {
+	options := []Option{
+		{Env: "TEST_TIER_PORT", Default: "8080", Type: TypeInt},
+		{Env: "TEST_TIER_TTL", Default: "5m", Type: TypeDuration},
+		{Env: "TEST_TIER_BROKERS", Default: "redpanda:9092", Type: TypeList},
+	}
+	for _, option := range options {
+		os.Unsetenv(option.Env)
+		t.Cleanup(func() { os.Unsetenv(option.Env) })
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "service.yaml")
+	os.WriteFile(path, []byte("test_tier_port: 9000\ntest_tier_ttl: 1m\n"), 0o600)
+	os.WriteFile(filepath.Join(dir, "service.production.yaml"), []byte("test_tier_ttl: 1h\n"), 0o600)
+	t.Setenv("TEST_TIER_BROKERS", " a:9092, ,b:9092")
+
+	settings, err := Load(path, TierProduction, options)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := Validate(settings, TierProduction); err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+	values := NewValues(TierProduction, settings)
+	if values.Int("TEST_TIER_PORT") != 9000 {
+		t.Errorf("base file value lost: %d", values.Int("TEST_TIER_PORT"))
+	}
+	if values.Duration("TEST_TIER_TTL") != time.Hour {
+		t.Errorf("tier file must override the base file, got %s", values.Duration("TEST_TIER_TTL"))
+	}
+	if got := values.List("TEST_TIER_BROKERS"); !reflect.DeepEqual(got, []string{"a:9092", "b:9092"}) {
+		t.Errorf("unexpected brokers %v", got)
+	}
+
+	// Without a tier file only the base file applies
+	os.Unsetenv("TEST_TIER_TTL")
+	if settings, _ = Load(path, TierStaging, options); NewValues(TierStaging, settings).Duration("TEST_TIER_TTL") != time.Minute {
+		t.Errorf("unexpected staging ttl")
+	}
+}
+
+func TestValidateReportsAllProblems(t *testing.T) {
+	settings := []Setting{
+		{Option: Option{Env: "A_PORT", Type: TypeInt}, Value: "80a", Source: SourceEnv},
+		{Option: Option{Env: "A_BROKERS", Type: TypeList, Required: true}, Source: SourceDefault},
+		{Option: Option{Env: "A_TTL", Type: TypeDuration, Positive: true}, Value: "-1s", Source: SourceFile},
+		{Option: Option{Env: "A_URL", Type: TypeURL}, Value: "semantic-memory:8080", Source: SourceEnv},
+		{Option: Option{Env: "A_FLAG", Type: TypeBool}, Value: "yes", Source: SourceEnv},
+		{Option: Option{Env: "A_SECRET", Secret: true, RequiredIn: []string{TierProduction}, Usage: "signing key"}, Source: SourceDefault},
+		{Option: Option{Env: "A_KEY", Secret: true}, Value: "minioadmin", Source: SourceDefault},
+		{Option: Option{Env: "A_RATE", Type: TypeFloat, Positive: true}, Value: "1.5", Source: SourceEnv},
+	}
+
+	err := Validate(settings, TierProduction)
+	if err == nil {
+		t.Fatal("expected a validation error")
+	}
+	for _, want := range []string{"A_PORT", "A_BROKERS (a_brokers): required", "A_TTL", "A_URL", "A_FLAG",
+		"A_SECRET (a_secret): required in production — signing key", "A_KEY"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error does not mention %q:\n%v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "A_RATE") {
+		t.Errorf("valid setting reported:\n%v", err)
+	}
+
+	// Development defaults and optional secrets are fine outside production
+	if err := Validate(settings[5:], TierDevelopment); err != nil {
+		t.Errorf("unexpected development error: %v", err)
+	}
+	if err := Validate(nil, "prod"); err == nil || !strings.Contains(err.Error(), "unknown tier") {
+		t.Errorf("expected an unknown tier error, got %v", err)
+	}
+}
+
+func TestValuesTypes(t *testing.T) {
+	values := NewValues(TierDevelopment, []Setting{
+		{Option: Option{Env: "V_INTERVAL_MS", Type: TypeMillis}, Value: "1500"},
+		{Option: Option{Env: "V_SCALE", Type: TypeFloat}, Value: "2.5"},
+		{Option: Option{Env: "V_ENABLED", Type: TypeBool}, Value: "true"},
+		{Option: Option{Env: "V_NAME"}, Value: "world"},
+	})
+	if values.Duration("V_INTERVAL_MS") != 1500*time.Millisecond || values.Float("V_SCALE") != 2.5 ||
+		!values.Bool("V_ENABLED") || values.String("V_NAME") != "world" {
+		t.Errorf("unexpected values")
+	}
+
+	for name, read := range map[string]func(){
+		"undeclared": func() { values.String("V_MISSING") },
+		"wrong type": func() { values.Int("V_NAME") },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: expected a panic", name)
+				}
+			}()
+			read()
+		}()
+	}
+}
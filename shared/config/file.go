@@ -0,0 +1,272 @@
+// shared/config/file.go
+//
+// Конфигурационные файлы сервисов. Сервисы по-прежнему читают настройки из переменных окружения,
+// а файл лишь заполняет переменные, которые не заданы в окружении: env всегда важнее файла.
+
+package config
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"multiverse-core.io/shared/logging"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvConfigFile — переменная окружения с путём к файлу конфигурации (альтернатива флагу -config).
+const EnvConfigFile = "CONFIG_FILE"
+
+// Источники эффективного значения настройки.
+const (
+	SourceEnv     = "env"
+	SourceFile    = "file"
+	SourceDefault = "default"
+)
+
+// Option — настройка сервиса. Ключ в файле — имя переменной окружения в нижнем регистре
+// (KAFKA_BROKERS → kafka_brokers).
+type Option struct {
+	Env     string
+	Default string // значение по умолчанию, если настройка не задана ни в env, ни в файле
+	Secret  bool   // значение скрывается в -print-config
+	Usage   string
+
+	Type       Type     // тип значения, проверяется при запуске
+	Required   bool     // запуск без значения невозможен
+	RequiredIn []string // уровни окружения, на которых значение обязательно
+	Positive   bool     // число или длительность должны быть больше нуля
+}
+
+// FileKey возвращает ключ настройки в файле конфигурации.
+func (o Option) FileKey() string {
+	return strings.ToLower(o.Env)
+}
+
+// Общие группы настроек shared-пакетов.
+var (
+	KafkaOptions = []Option{
+		{Env: "KAFKA_BROKERS", Default: "redpanda:9092", Type: TypeList, Required: true, Usage: "адреса брокеров через запятую"},
+		{Env: "KAFKA_POLL_FREQUENCY_MS", Default: "1000", Type: TypeMillis, Positive: true, Usage: "период опроса топиков"},
+		{Env: "KAFKA_AUTO_CREATE_TOPICS", Default: "true", Type: TypeBool, Usage: "создавать отсутствующие топики при запуске"},
+		{Env: "KAFKA_TOPIC_PARTITIONS", Default: "3", Type: TypeInt, Positive: true, Usage: "партиций в создаваемых топиках"},
+		{Env: "KAFKA_TOPIC_REPLICATION_FACTOR", Default: "1", Type: TypeInt, Positive: true, Usage: "реплик создаваемых топиков"},
+		{Env: "KAFKA_TOPIC_RETENTION_MS", Type: TypeMillis, Usage: "срок хранения создаваемых топиков; пусто — настройка брокера, -1 — бессрочно"},
+	}
+	MinioOptions = []Option{
+		{Env: "MINIO_ENDPOINT", Default: "minio:9000", Required: true},
+		{Env: "MINIO_ACCESS_KEY", Default: "minioadmin", Secret: true},
+		{Env: "MINIO_SECRET_KEY", Default: "minioadmin", Secret: true},
+	}
+	OracleOptions = []Option{
+		{Env: "ORACLE_URL", Default: "http://qwen3-service:11434/v1/chat/completions", Type: TypeURL},
+		{Env: "ORACLE_MODEL", Default: "qwen3"},
+		{Env: "ORACLE_API_KEY", Secret: true},
+		{Env: "ORACLE_TIMEOUT_MS", Default: "10000", Type: TypeMillis, Positive: true},
+		{Env: "ORACLE_URLS", Type: TypeList, Usage: "адреса Oracle в порядке приоритета для переключения при отказе; заменяет ORACLE_URL"},
+		{Env: "ORACLE_BREAKER_THRESHOLD", Default: "5", Type: TypeInt, Positive: true, Usage: "неудачных вызовов подряд до отключения адреса Oracle"},
+		{Env: "ORACLE_BREAKER_COOLDOWN_MS", Default: "30000", Type: TypeMillis, Positive: true, Usage: "время до пробного запроса к отключённому адресу Oracle"},
+		{Env: "ORACLE_AUDIT", Default: "false", Type: TypeBool, Usage: "сохранять пары запрос/ответ Oracle в MinIO"},
+		{Env: "ORACLE_AUDIT_BUCKET", Default: "gnue-oracle-audit", Usage: "бакет журнала аудита Oracle"},
+		{Env: "ORACLE_AUDIT_RETENTION_DAYS", Default: "14", Type: TypeInt, Positive: true, Usage: "срок хранения журнала аудита Oracle в днях"},
+	}
+	RegistryOptions = []Option{
+		{Env: "ADVERTISE_URL", Type: TypeURL, Usage: "адрес сервиса в реестре"},
+		{Env: "REGISTRY_HEARTBEAT_INTERVAL_MS", Default: "15000", Type: TypeMillis, Positive: true},
+		{Env: "SERVICE_VERSION"},
+	}
+	LoggingOptions = []Option{
+		{Env: "LOG_LEVEL", Default: "info", Usage: "debug, info, warn или error"},
+		{Env: "LOG_FORMAT", Default: "text", Usage: "text или json"},
+	}
+	HealthOptions = []Option{
+		{Env: "HEALTH_PORT", Default: "8079", Type: TypeInt, Positive: true, Usage: "порт /health и /ready каркаса сервиса"},
+	}
+	TracingOptions = []Option{
+		{Env: "OTEL_EXPORTER_OTLP_ENDPOINT", Type: TypeURL, Usage: "OTLP/HTTP-коллектор трасс; пусто — трассы не экспортируются"},
+		{Env: "OTEL_TRACES_SAMPLER", Default: "parentbased_always_on", Usage: "сэмплер трасс OpenTelemetry"},
+		{Env: "OTEL_TRACES_SAMPLER_ARG", Usage: "параметр сэмплера, например доля трасс для parentbased_traceidratio"},
+	}
+)
+
+// Setting — эффективное значение настройки.
+type Setting struct {
+	Option
+	Value  string
+	Source string
+}
+
+// ReadFile читает YAML-файл конфигурации. Неизвестные ключи — ошибка, чтобы опечатка
+// не превращалась молча в значение по умолчанию. Списки склеиваются через запятую.
+func ReadFile(path string, options []Option) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config file: %w", err)
+	}
+
+	var raw map[string]yaml.Node
+	if err := yaml.NewDecoder(bytes.NewReader(data)).Decode(&raw); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("parse config file %s: %w", path, err)
+	}
+
+	known := make(map[string]Option, len(options))
+	for _, option := range options {
+		known[option.FileKey()] = option
+	}
+
+	var unknown []string
+	values := make(map[string]string, len(raw))
+	for key, node := range raw {
+		option, ok := known[key]
+		if !ok {
+			unknown = append(unknown, key)
+			continue
+		}
+		value, err := nodeValue(&node)
+		if err != nil {
+			return nil, fmt.Errorf("config file %s, key %s: %w", path, key, err)
+		}
+		values[option.Env] = value
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("config file %s: unknown keys: %s", path, strings.Join(unknown, ", "))
+	}
+	return values, nil
+}
+
+// nodeValue приводит скаляр или список скаляров к строке переменной окружения.
+func nodeValue(node *yaml.Node) (string, error) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return node.Value, nil
+	case yaml.SequenceNode:
+		items := make([]string, 0, len(node.Content))
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				return "", fmt.Errorf("list items must be scalars")
+			}
+			items = append(items, item.Value)
+		}
+		return strings.Join(items, ","), nil
+	default:
+		return "", fmt.Errorf("value must be a scalar or a list of scalars")
+	}
+}
+
+// Apply экспортирует значения из файла в окружение для незаданных переменных
+// и возвращает эффективную конфигурацию. Пустая переменная окружения считается незаданной.
+func Apply(options []Option, fileValues map[string]string) ([]Setting, error) {
+	settings := make([]Setting, 0, len(options))
+	for _, option := range options {
+		setting := Setting{Option: option, Value: option.Default, Source: SourceDefault}
+		if value := os.Getenv(option.Env); value != "" {
+			setting.Value, setting.Source = value, SourceEnv
+		} else if value, ok := fileValues[option.Env]; ok {
+			if err := os.Setenv(option.Env, value); err != nil {
+				return nil, err
+			}
+			setting.Value, setting.Source = value, SourceFile
+		}
+		settings = append(settings, setting)
+	}
+	return settings, nil
+}
+
+// Print выводит эффективную конфигурацию в формате файла конфигурации с источником каждого значения.
+func Print(w io.Writer, service string, settings []Setting) {
+	fmt.Fprintf(w, "# %s effective configuration\n", service)
+	for _, setting := range settings {
+		value := setting.Value
+		if setting.Secret && value != "" {
+			value = "********"
+		}
+		quoted, _ := yaml.Marshal(value)
+		comment := setting.Source
+		if setting.Usage != "" {
+			comment += ", " + setting.Usage
+		}
+		fmt.Fprintf(w, "%s: %s # %s\n", setting.FileKey(), strings.TrimSpace(string(quoted)), comment)
+	}
+}
+
+// Setup подключает конфигурацию в main сервиса. Путь к файлу задаётся флагом -config или CONFIG_FILE,
+// уровень окружения — APP_ENV (см. Load). Все настройки проверяются при запуске: при ошибке процесс
+// завершается с описанием всех нарушений. Флаг -print-config печатает эффективную конфигурацию
+// и завершает процесс. Опции одного имени из нескольких групп объединяются (побеждает первая).
+func Setup(service string, groups ...[]Option) *Values {
+	var options []Option
+	seen := make(map[string]bool)
+	for _, group := range groups {
+		for _, option := range group {
+			if !seen[option.Env] {
+				seen[option.Env] = true
+				options = append(options, option)
+			}
+		}
+	}
+
+	flags := flag.NewFlagSet(service, flag.ExitOnError)
+	path := flags.String("config", os.Getenv(EnvConfigFile), "path to YAML config file (env vars override it)")
+	printConfig := flags.Bool("print-config", false, "print the effective configuration and exit")
+	flags.Parse(os.Args[1:])
+
+	tier := Tier()
+	settings, err := Load(*path, tier, options)
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	invalid := Validate(settings, tier)
+
+	if *printConfig {
+		Print(os.Stdout, service, settings)
+		if invalid != nil {
+			fmt.Fprintln(os.Stderr, invalid)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	if invalid != nil {
+		log.Fatalf("%s: %v", service, invalid)
+	}
+	if *path != "" {
+		logging.Infof("Loaded configuration from %s", *path)
+	}
+	logging.Infof("Configuration tier: %s", tier)
+	return NewValues(tier, settings)
+}
+
+// Load читает файл конфигурации и, если он есть, файл уровня окружения рядом с ним (см. TierFile),
+// значения которого важнее основного файла, затем применяет их к окружению (см. Apply).
+// Пустой path — только переменные окружения и значения по умолчанию.
+func Load(path, tier string, options []Option) ([]Setting, error) {
+	var fileValues map[string]string
+	if path != "" {
+		var err error
+		if fileValues, err = ReadFile(path, options); err != nil {
+			return nil, err
+		}
+		tierPath := TierFile(path, tier)
+		if _, err := os.Stat(tierPath); err == nil {
+			overrides, err := ReadFile(tierPath, options)
+			if err != nil {
+				return nil, err
+			}
+			for key, value := range overrides {
+				fileValues[key] = value
+			}
+			logging.Infof("Loaded %s configuration overrides from %s", tier, tierPath)
+		}
+	}
+	settings, err := Apply(options, fileValues)
+	if err != nil {
+		return nil, fmt.Errorf("apply configuration: %w", err)
+	}
+	return settings, nil
+}
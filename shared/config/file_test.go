@@ -0,0 +1,72 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadFileAndApply(t *testing.T) {
+	options := []Option{
+		{Env: "TEST_CFG_BROKERS", Default: "redpanda:9092"},
+		{Env: "TEST_CFG_PORT", Default: "8080"},
+		{Env: "TEST_CFG_SECRET", Secret: true},
+		{Env: "TEST_CFG_UNSET", Default: "fallback"},
+	}
+	path := filepath.Join(t.TempDir(), "service.yaml")
+	data := "test_cfg_brokers: [a:9092, b:9092]\ntest_cfg_port: 9000\ntest_cfg_secret: hunter2\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	values, err := ReadFile(path, options)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if values["TEST_CFG_BROKERS"] != "a:9092,b:9092" || values["TEST_CFG_PORT"] != "9000" {
+		t.Fatalf("unexpected file values: %v", values)
+	}
+
+	// The environment overrides the file
+	t.Setenv("TEST_CFG_PORT", "7000")
+	os.Unsetenv("TEST_CFG_BROKERS")
+	os.Unsetenv("TEST_CFG_SECRET")
+	t.Cleanup(func() {
+		os.Unsetenv("TEST_CFG_BROKERS")
+		os.Unsetenv("TEST_CFG_SECRET")
+	})
+
+	settings, err := Apply(options, values)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantSources := []string{SourceFile, SourceEnv, SourceFile, SourceDefault}
+	for i, setting := range settings {
+		if setting.Source != wantSources[i] {
+			t.Errorf("%s: expected source %s, got %s", setting.Env, wantSources[i], setting.Source)
+		}
+	}
+	if os.Getenv("TEST_CFG_BROKERS") != "a:9092,b:9092" || os.Getenv("TEST_CFG_PORT") != "7000" {
+		t.Errorf("unexpected environment: brokers=%q port=%q", os.Getenv("TEST_CFG_BROKERS"), os.Getenv("TEST_CFG_PORT"))
+	}
+
+	var out bytes.Buffer
+	Print(&out, "test-service", settings)
+	if strings.Contains(out.String(), "hunter2") || !strings.Contains(out.String(), "test_cfg_port: \"7000\" # env") {
+		t.Errorf("unexpected printed config:\n%s", out.String())
+	}
+}
+
+func TestReadFileRejectsUnknownKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "service.yaml")
+	if err := os.WriteFile(path, []byte("kafka_brokers: a\nkafka_brokres: b\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := ReadFile(path, KafkaOptions)
+	if err == nil || !strings.Contains(err.Error(), "kafka_brokres") {
+		t.Errorf("expected unknown key error, got %v", err)
+	}
+}
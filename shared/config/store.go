@@ -3,13 +3,21 @@
 package config
 
 import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"io"
 	"path"
 	"sync"
 	"time"
 
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
 	"multiverse-core.io/shared/minio"
 
 	"gopkg.in/yaml.v3"
@@ -43,87 +51,472 @@ type Profile struct {
 	} `yaml:"snapshot,omitempty" json:"snapshot,omitempty"`
 }
 
+// EventConfigUpdated публикуется в system_events после записи профиля или переопределения.
+const EventConfigUpdated = "config.updated"
+
+// Виды конфигов в событии config.updated.
+const (
+	KindProfile  = "profile"
+	KindOverride = "override"
+)
+
+// ErrConflict — конфиг изменился после чтения: хеш содержимого не совпал с ожидаемым.
+var ErrConflict = errors.New("config: concurrent modification")
+
+// systemPublisher — часть eventbus.EventBus, нужная для config.updated.
+type systemPublisher interface {
+	PublishSystemEvent(ctx context.Context, event eventbus.Event) error
+}
+
+const (
+	// DefaultProfileTTL — как долго профиль используется без сверки с MinIO
+	DefaultProfileTTL = 2 * time.Minute
+	// refreshInterval — период проверки профилей с истёкшим TTL
+	refreshInterval = 30 * time.Second
+)
+
+// cachedProfile — профиль в кэше и версия, с которой он прочитан.
+type cachedProfile struct {
+	profile *Profile
+	// hash — MD5 содержимого, etag — последний ETag объекта из листинга (сначала равен hash)
+	hash      string
+	etag      string
+	checkedAt time.Time
+}
+
+// profileLoad — чтение профиля, которого ждут все одновременные промахи кэша.
+type profileLoad struct {
+	done    chan struct{}
+	profile *Profile
+	err     error
+}
+
 // Store управляет динамическими конфигами в MinIO.
+// Профили кэшируются; по истечении TTL профиль сверяется с ETag из листинга
+// и перечитывается только если изменился.
 type Store struct {
-	minioClient minio.ClientInterface
-	cache       map[string]*Profile
+	minioClient minio.ObjectStorage
+	cache       map[string]*cachedProfile
 	cacheLock   sync.RWMutex
 	bucket      string
+	now         func() time.Time
+
+	// ttls — TTL отдельных профилей, loading — текущие чтения; под cacheLock
+	defaultTTL time.Duration
+	ttls       map[string]time.Duration
+	loading    map[string]*profileLoad
+
+	// writeLock сериализует запись: проверка хеша и замена объекта выполняются без гонок внутри процесса
+	writeLock sync.Mutex
+	// events — получатель config.updated (nil — события не публикуются)
+	events systemPublisher
 }
 
-// NewStore создаёт новый config store.
-func NewStore(minioClient minio.ClientInterface, bucket string) *Store {
+// NewStore создаёт новый config store. Без хранилища (nil) store работает,
+// но каждое чтение и запись возвращают minio.ErrUnavailable.
+func NewStore(minioClient minio.ObjectStorage, bucket string) *Store {
+	if minioClient == nil {
+		minioClient = unavailableStorage{}
+	}
 	store := &Store{
 		minioClient: minioClient,
-		cache:       make(map[string]*Profile),
+		cache:       make(map[string]*cachedProfile),
 		bucket:      bucket,
+		now:         time.Now,
+		defaultTTL:  DefaultProfileTTL,
+		ttls:        make(map[string]time.Duration),
+		loading:     make(map[string]*profileLoad),
 	}
 
 	go store.backgroundRefresh()
 	return store
 }
 
+// UseEventBus включает публикацию config.updated после записи конфигов.
+func (s *Store) UseEventBus(bus *eventbus.EventBus) {
+	s.events = bus
+}
+
+// SetTTL задаёт TTL профиля scopeType; ttl <= 0 возвращает TTL по умолчанию.
+func (s *Store) SetTTL(scopeType string, ttl time.Duration) {
+	s.cacheLock.Lock()
+	defer s.cacheLock.Unlock()
+	if ttl <= 0 {
+		delete(s.ttls, scopeType)
+		return
+	}
+	s.ttls[scopeType] = ttl
+}
+
+// ttl возвращает TTL профиля; вызывается под cacheLock.
+func (s *Store) ttl(scopeType string) time.Duration {
+	if ttl, ok := s.ttls[scopeType]; ok {
+		return ttl
+	}
+	return s.defaultTTL
+}
+
 // GetProfile возвращает профиль по scope_type (с кэшированием).
+// Одновременные промахи кэша по одному профилю читают MinIO один раз.
+// Ошибки оборачивают minio.ErrNotFound / minio.ErrUnavailable — вызывающий код
+// должен откатываться на профиль по умолчанию только при отсутствии файла.
 func (s *Store) GetProfile(scopeType string) (*Profile, error) {
-
 	s.cacheLock.RLock()
-	if p, ok := s.cache[scopeType]; ok {
+	if cached, ok := s.cache[scopeType]; ok {
 		s.cacheLock.RUnlock()
-		return p, nil
+		return cached.profile, nil
 	}
 	s.cacheLock.RUnlock()
 
-	key := path.Join("gm-profiles", "gm_"+scopeType+".yaml")
-	log.Println(s.bucket)
-	log.Println(key)
-	data, err := s.minioClient.GetObject(s.bucket, key)
-	log.Println(string(data))
+	s.cacheLock.Lock()
+	if cached, ok := s.cache[scopeType]; ok {
+		s.cacheLock.Unlock()
+		return cached.profile, nil
+	}
+	if load, ok := s.loading[scopeType]; ok {
+		s.cacheLock.Unlock()
+		<-load.done
+		return load.profile, load.err
+	}
+	load := &profileLoad{done: make(chan struct{})}
+	s.loading[scopeType] = load
+	s.cacheLock.Unlock()
+
+	cached, err := s.fetchProfile(scopeType)
+	if err == nil {
+		load.profile = cached.profile
+	}
+	load.err = err
+
+	s.cacheLock.Lock()
+	delete(s.loading, scopeType)
+	if err == nil {
+		s.cache[scopeType] = cached
+	}
+	s.cacheLock.Unlock()
+	close(load.done)
+
+	return load.profile, load.err
+}
+
+// fetchProfile читает профиль из MinIO.
+func (s *Store) fetchProfile(scopeType string) (*cachedProfile, error) {
+	data, err := s.minioClient.GetObject(s.bucket, profileKey(scopeType))
 	if err != nil {
-		log.Println(err)
-		return nil, fmt.Errorf("profile %s not found: %w", scopeType, err)
+		if minio.IsNotFound(err) {
+			return nil, fmt.Errorf("profile %s not found: %w", scopeType, err)
+		}
+		return nil, fmt.Errorf("failed to load profile %s: %w", scopeType, err)
 	}
 
 	var profile Profile
-
 	if err := yaml.Unmarshal(data, &profile); err != nil {
 		return nil, fmt.Errorf("invalid YAML for %s: %w", scopeType, err)
 	}
 
-	s.cacheLock.Lock()
-	s.cache[scopeType] = &profile
-	s.cacheLock.Unlock()
+	sum := md5.Sum(data)
+	hash := hex.EncodeToString(sum[:])
+	return &cachedProfile{profile: &profile, hash: hash, etag: hash, checkedAt: s.now()}, nil
+}
 
-	log.Println(scopeType)
-	log.Println(profile)
+// GetOverride возвращает переопределение для scopeID (если есть).
+// Отсутствие файла — не ошибка (nil, nil); недоступность MinIO возвращается как ошибка.
+func (s *Store) GetOverride(scopeID string) (*Profile, error) {
+	data, err := s.minioClient.GetObject(s.bucket, overrideKey(scopeID))
+	if err != nil {
+		if minio.IsNotFound(err) {
+			return nil, nil // no override
+		}
+		return nil, fmt.Errorf("failed to load override for %s: %w", scopeID, err)
+	}
 
+	var profile Profile
+	if err := yaml.Unmarshal(data, &profile); err != nil {
+		return nil, fmt.Errorf("invalid override for %s: %w", scopeID, err)
+	}
 	return &profile, nil
 }
 
-// GetOverride возвращает переопределение для scopeID (если есть).
-func (s *Store) GetOverride(scopeID string) (*Profile, error) {
-	key := path.Join("gm-overrides", scopeID+".yaml")
+// SaveOverride записывает переопределение профиля для scopeID в бакет конфигов GM.
+// Используется сервисами, создающими scope заранее (например, фокусные сущности города);
+// orchestrator применяет переопределение при создании GM.
+func SaveOverride(client minio.ObjectStorage, bucket, scopeID string, override *Profile) error {
+	data, err := yaml.Marshal(override)
+	if err != nil {
+		return fmt.Errorf("failed to encode override for %s: %w", scopeID, err)
+	}
+	if err := client.PutObject(bucket, overrideKey(scopeID), bytes.NewReader(data), int64(len(data))); err != nil {
+		return fmt.Errorf("failed to save override for %s: %w", scopeID, err)
+	}
+	return nil
+}
+
+// unavailableStorage подставляется вместо отсутствующего хранилища.
+type unavailableStorage struct{}
+
+func (unavailableStorage) err() error {
+	return fmt.Errorf("%w: object storage is not configured", minio.ErrUnavailable)
+}
+
+func (u unavailableStorage) PutObject(bucket, object string, data io.Reader, size int64) error {
+	return u.err()
+}
+
+func (u unavailableStorage) GetObject(bucket, object string) ([]byte, error) {
+	return nil, u.err()
+}
+
+func (u unavailableStorage) GetObjectStream(bucket, object string) (io.ReadCloser, error) {
+	return nil, u.err()
+}
+
+func (u unavailableStorage) ListObjects(bucket, prefix string) ([]minio.ObjectInfo, error) {
+	return nil, u.err()
+}
+
+func (u unavailableStorage) RemoveObject(bucket, object string) error {
+	return u.err()
+}
+
+func (u unavailableStorage) PresignedGetObject(bucket, object string, expires time.Duration) (string, error) {
+	return "", u.err()
+}
+
+func overrideKey(scopeID string) string {
+	return path.Join("gm-overrides", scopeID+".yaml")
+}
+
+func profileKey(scopeType string) string {
+	return path.Join("gm-profiles", "gm_"+scopeType+".yaml")
+}
+
+// ContentHash возвращает хеш содержимого конфига для оптимистичной блокировки.
+func ContentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ProfileHash возвращает хеш сохранённого профиля; "" — профиля нет.
+func (s *Store) ProfileHash(scopeType string) (string, error) {
+	return s.currentHash(profileKey(scopeType))
+}
+
+// OverrideHash возвращает хеш сохранённого переопределения; "" — переопределения нет.
+func (s *Store) OverrideHash(scopeID string) (string, error) {
+	return s.currentHash(overrideKey(scopeID))
+}
+
+func (s *Store) currentHash(key string) (string, error) {
 	data, err := s.minioClient.GetObject(s.bucket, key)
 	if err != nil {
-		return nil, nil // no override
+		if minio.IsNotFound(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read %s: %w", key, err)
 	}
+	return ContentHash(data), nil
+}
 
+// PutProfile проверяет и атомарно записывает YAML профиля scopeType.
+// expectedHash — хеш версии, на основе которой сделано изменение (ProfileHash),
+// "" — профиль создаётся впервые; при несовпадении возвращается ErrConflict.
+// Возвращает хеш записанной версии; кэш профиля сбрасывается сразу.
+func (s *Store) PutProfile(ctx context.Context, scopeType string, data []byte, expectedHash string) (string, error) {
+	profile, err := parseProfile(data)
+	if err != nil {
+		return "", fmt.Errorf("invalid profile %s: %w", scopeType, err)
+	}
+	if profile.ScopeType != "" && profile.ScopeType != scopeType {
+		return "", fmt.Errorf("invalid profile %s: scope_type is %q", scopeType, profile.ScopeType)
+	}
+	return s.put(ctx, KindProfile, scopeType, profileKey(scopeType), data, expectedHash)
+}
+
+// PutOverride проверяет и атомарно записывает YAML переопределения для scopeID.
+// Семантика expectedHash та же, что у PutProfile (OverrideHash).
+func (s *Store) PutOverride(ctx context.Context, scopeID string, data []byte, expectedHash string) (string, error) {
+	if _, err := parseProfile(data); err != nil {
+		return "", fmt.Errorf("invalid override for %s: %w", scopeID, err)
+	}
+	return s.put(ctx, KindOverride, scopeID, overrideKey(scopeID), data, expectedHash)
+}
+
+// put записывает конфиг: сначала во временный ключ с проверкой чтением, затем в основной.
+// В MinIO нет переименования, поэтому основной ключ перезаписывается только проверенным
+// содержимым; запись одного объекта атомарна — читатели видят старую или новую версию целиком.
+func (s *Store) put(ctx context.Context, kind, id, key string, data []byte, expectedHash string) (string, error) {
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	previousHash, err := s.currentHash(key)
+	if err != nil {
+		return "", err
+	}
+	if previousHash != expectedHash {
+		return "", fmt.Errorf("%s %s: %w", kind, id, ErrConflict)
+	}
+
+	hash := ContentHash(data)
+	stagingKey := key + ".staging"
+	if err := s.minioClient.PutObject(s.bucket, stagingKey, bytes.NewReader(data), int64(len(data))); err != nil {
+		return "", fmt.Errorf("failed to stage %s: %w", key, err)
+	}
+	staged, err := s.minioClient.GetObject(s.bucket, stagingKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to verify staged %s: %w", key, err)
+	}
+	if ContentHash(staged) != hash {
+		return "", fmt.Errorf("staged %s is corrupted", key)
+	}
+	if err := s.minioClient.PutObject(s.bucket, key, bytes.NewReader(staged), int64(len(staged))); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", key, err)
+	}
+
+	if kind == KindProfile {
+		s.Invalidate(id)
+	}
+	s.publishUpdated(ctx, kind, id, key, hash, previousHash)
+	return hash, nil
+}
+
+// Invalidate сбрасывает кэш профиля scopeType; следующий GetProfile прочитает его из MinIO.
+func (s *Store) Invalidate(scopeType string) {
+	s.cacheLock.Lock()
+	delete(s.cache, scopeType)
+	s.cacheLock.Unlock()
+}
+
+// publishUpdated публикует config.updated, чтобы другие экземпляры сбросили кэш.
+func (s *Store) publishUpdated(ctx context.Context, kind, id, key, hash, previousHash string) {
+	if s.events == nil {
+		return
+	}
+	event := eventbus.NewEvent(EventConfigUpdated, "config-store", "", map[string]any{
+		"kind":          kind,
+		"id":            id,
+		"bucket":        s.bucket,
+		"key":           key,
+		"hash":          hash,
+		"previous_hash": previousHash,
+	})
+	if err := s.events.PublishSystemEvent(ctx, event); err != nil {
+		logging.Errorf("Failed to publish %s for %s %s: %v", EventConfigUpdated, kind, id, err)
+	}
+}
+
+// parseProfile разбирает и проверяет YAML профиля. Неизвестные поля допускаются:
+// профили содержат настройки других компонентов (например, geometry_buffer_m).
+func parseProfile(data []byte) (*Profile, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, errors.New("empty document")
+	}
 	var profile Profile
 	if err := yaml.Unmarshal(data, &profile); err != nil {
-		return nil, fmt.Errorf("invalid override for %s: %w", scopeID, err)
+		return nil, err
+	}
+	if profile.TimeWindow != "" {
+		if _, err := time.ParseDuration(profile.TimeWindow); err != nil {
+			return nil, fmt.Errorf("time_window: %w", err)
+		}
+	}
+	for _, field := range []struct {
+		name  string
+		value int
+	}{
+		{"context_depth.canon", profile.ContextDepth.Canon},
+		{"context_depth.history", profile.ContextDepth.History},
+		{"context_depth.entities", profile.ContextDepth.Entities},
+		{"triggers.time_interval_ms", profile.Triggers.TimeIntervalMs},
+		{"triggers.max_events", profile.Triggers.MaxEvents},
+		{"snapshot.interval_events", profile.Snapshot.IntervalEvents},
+		{"snapshot.interval_ms", profile.Snapshot.IntervalMs},
+	} {
+		if field.value < 0 {
+			return nil, fmt.Errorf("%s must not be negative", field.name)
+		}
 	}
 	return &profile, nil
 }
 
-// backgroundRefresh обновляет кэш каждые 30 сек (hot-reload).
+// backgroundRefresh сверяет профили с истёкшим TTL с MinIO.
 func (s *Store) backgroundRefresh() {
-	ticker := time.NewTicker(120 * time.Second)
+	ticker := time.NewTicker(refreshInterval)
 	defer ticker.Stop()
 
 	for range ticker.C {
+		s.refresh()
+	}
+}
+
+// refresh получает ETag профилей одним листингом и перечитывает только изменившиеся
+// профили с истёкшим TTL. Удалённые профили убираются из кэша; при недоступности
+// MinIO кэш остаётся прежним.
+func (s *Store) refresh() {
+	now := s.now()
+	s.cacheLock.RLock()
+	due := make(map[string]*cachedProfile)
+	for scopeType, cached := range s.cache {
+		if now.Sub(cached.checkedAt) >= s.ttl(scopeType) {
+			due[scopeType] = cached
+		}
+	}
+	s.cacheLock.RUnlock()
+	if len(due) == 0 {
+		return
+	}
+
+	objects, err := s.minioClient.ListObjects(s.bucket, "gm-profiles/")
+	if err != nil {
+		logging.Infof("GM config refresh skipped: %v", err)
+		return
+	}
+	etags := make(map[string]string, len(objects))
+	for _, object := range objects {
+		etags[object.Key] = object.ETag
+	}
+
+	reloaded := 0
+	for scopeType, cached := range due {
+		etag, exists := etags[profileKey(scopeType)]
+		var updated *cachedProfile
+		switch {
+		case !exists:
+			// профиль удалён
+		case etag != "" && etag == cached.etag:
+			c := *cached
+			updated = &c
+			updated.checkedAt = now
+		default:
+			fresh, err := s.fetchProfile(scopeType)
+			if err != nil {
+				logging.Errorf("GM config refresh of %s failed: %v", scopeType, err)
+				continue
+			}
+			if fresh.hash == cached.hash {
+				// содержимое не изменилось (ETag не равен MD5) — профиль остаётся прежним
+				fresh.profile = cached.profile
+			} else {
+				reloaded++
+			}
+			if etag != "" {
+				fresh.etag = etag
+			}
+			updated = fresh
+		}
+
 		s.cacheLock.Lock()
-		s.cache = make(map[string]*Profile)
+		// запись или Invalidate во время сверки важнее её результата
+		if s.cache[scopeType] == cached {
+			if updated == nil {
+				delete(s.cache, scopeType)
+			} else {
+				s.cache[scopeType] = updated
+			}
+		}
 		s.cacheLock.Unlock()
-		log.Println("GM config cache refreshed")
+	}
+	if reloaded > 0 {
+		logging.Infof("GM config cache refreshed: %d of %d profiles reloaded", reloaded, len(due))
 	}
 }
 
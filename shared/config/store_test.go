@@ -0,0 +1,187 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/minio"
+	"multiverse-core.io/shared/minio/miniotest"
+)
+
+type recordingPublisher struct {
+	events []eventbus.Event
+}
+
+func (p *recordingPublisher) PublishSystemEvent(ctx context.Context, event eventbus.Event) error {
+	p.events = append(p.events, event)
+	return nil
+}
+
+func TestPutProfile(t *testing.T) {
+	ctx := context.Background()
+	storage := miniotest.New()
+	events := &recordingPublisher{}
+	store := NewStore(storage, "gnue-configs")
+	store.events = events
+
+	v1 := []byte("scope_type: region\ntime_window: 1h\ngeometry_buffer_m: 50\n")
+	hash, err := store.PutProfile(ctx, "region", v1, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if hash != ContentHash(v1) {
+		t.Errorf("unexpected hash %s", hash)
+	}
+	if want := []string{"gm-profiles/gm_region.yaml.staging", "gm-profiles/gm_region.yaml"}; strings.Join(storage.Puts("gnue-configs"), ",") != strings.Join(want, ",") {
+		t.Errorf("expected staged write %v, got %v", want, storage.Puts("gnue-configs"))
+	}
+	profile, err := store.GetProfile("region")
+	if err != nil || profile.TimeWindow != "1h" {
+		t.Fatalf("unexpected profile %+v, %v", profile, err)
+	}
+
+	// Writing over a stale version is rejected
+	if _, err := store.PutProfile(ctx, "region", []byte("time_window: 2h\n"), ""); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected a conflict, got %v", err)
+	}
+
+	// The cache is dropped at once
+	if _, err := store.PutProfile(ctx, "region", []byte("time_window: 2h\n"), hash); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if profile, _ := store.GetProfile("region"); profile.TimeWindow != "2h" {
+		t.Errorf("stale cached profile %+v", profile)
+	}
+
+	if len(events.events) != 2 {
+		t.Fatalf("expected 2 config.updated events, got %d", len(events.events))
+	}
+	ev := events.events[1]
+	if ev.Type != EventConfigUpdated || ev.Payload["kind"] != KindProfile || ev.Payload["id"] != "region" || ev.Payload["previous_hash"] != hash {
+		t.Errorf("unexpected event %s %v", ev.Type, ev.Payload)
+	}
+}
+
+func TestPutValidation(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(miniotest.New(), "gnue-configs")
+
+	for name, data := range map[string]string{
+		"syntax":       "time_window: [1h\n",
+		"empty":        "  \n",
+		"time_window":  "time_window: soon\n",
+		"negative":     "context_depth:\n  canon: -1\n",
+		"scope_type":   "scope_type: city\n",
+		"not a record": "- region\n",
+	} {
+		if _, err := store.PutProfile(ctx, "region", []byte(data), ""); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+	}
+
+	hash, err := store.PutOverride(ctx, "city-1", []byte("focus_entities: [npc-1]\n"), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if current, _ := store.OverrideHash("city-1"); current != hash {
+		t.Errorf("override hash %s, want %s", current, hash)
+	}
+	override, err := store.GetOverride("city-1")
+	if err != nil || len(override.FocusEntities) != 1 {
+		t.Errorf("unexpected override %+v, %v", override, err)
+	}
+	if current, err := store.ProfileHash("missing"); current != "" || err != nil {
+		t.Errorf("missing profile hash %q, %v", current, err)
+	}
+}
+
+func TestRefreshReloadsOnlyChangedProfiles(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	storage := miniotest.New()
+	storage.Put("gnue-configs", "gm-profiles/gm_region.yaml", "time_window: 1h\n")
+	storage.Put("gnue-configs", "gm-profiles/gm_city.yaml", "time_window: 1h\n")
+	storage.Put("gnue-configs", "gm-profiles/gm_player.yaml", "time_window: 1h\n")
+	store := NewStore(storage, "gnue-configs")
+	store.now = func() time.Time { return now }
+	store.SetTTL("player", time.Hour)
+
+	for _, scopeType := range []string{"region", "city", "player"} {
+		if _, err := store.GetProfile(scopeType); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Nothing is due before the TTL expires
+	storage.Put("gnue-configs", "gm-profiles/gm_city.yaml", "time_window: 5m\n")
+	store.refresh()
+	if profile, _ := store.GetProfile("city"); profile.TimeWindow != "1h" {
+		t.Fatalf("profile refreshed before its TTL: %+v", profile)
+	}
+
+	now = now.Add(DefaultProfileTTL)
+	store.refresh()
+	if profile, _ := store.GetProfile("city"); profile.TimeWindow != "5m" {
+		t.Errorf("changed profile not reloaded: %+v", profile)
+	}
+	if n := storage.Gets("gnue-configs", "gm-profiles/gm_region.yaml"); n != 1 {
+		t.Errorf("unchanged profile read %d times", n)
+	}
+
+	// A profile with a longer TTL is checked later
+	storage.Put("gnue-configs", "gm-profiles/gm_player.yaml", "time_window: 5m\n")
+	store.refresh()
+	if profile, _ := store.GetProfile("player"); profile.TimeWindow != "1h" {
+		t.Errorf("player profile refreshed before its TTL: %+v", profile)
+	}
+	now = now.Add(time.Hour)
+	store.refresh()
+	if profile, _ := store.GetProfile("player"); profile.TimeWindow != "5m" {
+		t.Errorf("player profile not reloaded: %+v", profile)
+	}
+
+	// Deleted profiles leave the cache
+	storage.RemoveObject("gnue-configs", "gm-profiles/gm_region.yaml")
+	now = now.Add(DefaultProfileTTL)
+	store.refresh()
+	if _, err := store.GetProfile("region"); !minio.IsNotFound(err) {
+		t.Errorf("expected a deleted profile, got %v", err)
+	}
+}
+
+func TestConcurrentMissesShareRead(t *testing.T) {
+	storage := miniotest.New()
+	storage.Put("gnue-configs", "gm-profiles/gm_region.yaml", "time_window: 1h\n")
+	block := make(chan struct{})
+	storage.BeforeGet = func(bucket, object string) { <-block }
+	store := NewStore(storage, "gnue-configs")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if profile, err := store.GetProfile("region"); err != nil || profile.TimeWindow != "1h" {
+				t.Errorf("unexpected profile %+v, %v", profile, err)
+			}
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(block)
+	wg.Wait()
+
+	if n := storage.Gets("gnue-configs", "gm-profiles/gm_region.yaml"); n != 1 {
+		t.Errorf("expected one read, got %d", n)
+	}
+}
+
+func TestStoreWithoutStorage(t *testing.T) {
+	store := NewStore(nil, "gnue-configs")
+	if _, err := store.GetProfile("region"); !errors.Is(err, minio.ErrUnavailable) {
+		t.Errorf("expected ErrUnavailable, got %v", err)
+	}
+}
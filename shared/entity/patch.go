@@ -0,0 +1,447 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JSON-Patch (RFC 6902) operations.
+const (
+	PatchAdd     = "add"
+	PatchRemove  = "remove"
+	PatchReplace = "replace"
+	PatchMove    = "move"
+	PatchCopy    = "copy"
+	PatchTest    = "test"
+)
+
+// Legacy state_changes operations, still accepted by ApplyPatch.
+const (
+	PatchSet             = "set"               // sets a value, creating missing objects on the way
+	PatchAddToSlice      = "add_to_slice"      // appends a value to a list unless it is already there
+	PatchRemoveFromSlice = "remove_from_slice" // removes a value from a list
+)
+
+// PatchOperation is a single patch operation.
+// Path and From are JSON Pointers ("/stats/hp", "/inventory/0", "/inventory/-") relative to the payload;
+// legacy dot paths ("stats.hp") are accepted too.
+type PatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	From  string      `json:"from,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+	// HasValue reports whether the operation carries a value (which may be null)
+	HasValue bool `json:"-"`
+}
+
+// ParsePatch converts operations decoded from an event payload.
+func ParsePatch(raw []interface{}) ([]PatchOperation, error) {
+	ops := make([]PatchOperation, 0, len(raw))
+	for i, item := range raw {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("operation %d: not an object", i)
+		}
+		op := PatchOperation{}
+		op.Op, _ = m["op"].(string)
+		op.Path, _ = m["path"].(string)
+		op.From, _ = m["from"].(string)
+		op.Value, op.HasValue = m["value"]
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+// UnmarshalJSON keeps track of whether the operation carries a value.
+func (op *PatchOperation) UnmarshalJSON(data []byte) error {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	ops, err := ParsePatch([]interface{}{raw})
+	if err != nil {
+		return err
+	}
+	*op = ops[0]
+	return nil
+}
+
+// ApplyPatch applies a patch to the payload. The patch is atomic: if any operation fails
+// (including a failed test), the payload is left untouched and the error is returned.
+// Reports whether the payload changed.
+func (e *Entity) ApplyPatch(ops []PatchOperation) (bool, error) {
+	patched, changed, err := PatchDocument(e.Payload, ops)
+	if err != nil || !changed {
+		return false, err
+	}
+	e.Payload = patched
+	e.UpdatedAt = time.Now().UTC()
+	return true, nil
+}
+
+// PatchDocument applies a patch to a copy of doc and returns the patched copy.
+// Reports whether the document changed.
+func PatchDocument(doc map[string]interface{}, ops []PatchOperation) (map[string]interface{}, bool, error) {
+	before, _ := copyValue(doc).(map[string]interface{})
+	if before == nil {
+		before = make(map[string]interface{})
+	}
+	var work interface{} = copyValue(before)
+
+	for i, op := range ops {
+		var err error
+		if work, err = applyOperation(work, op); err != nil {
+			return nil, false, fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+
+	patched, ok := work.(map[string]interface{})
+	if !ok {
+		return nil, false, fmt.Errorf("patched payload must be an object")
+	}
+	return patched, !reflect.DeepEqual(before, patched), nil
+}
+
+func applyOperation(doc interface{}, op PatchOperation) (interface{}, error) {
+	path, err := parsePath(op.Path)
+	if err != nil {
+		return nil, err
+	}
+	needsValue := op.Op == PatchAdd || op.Op == PatchReplace || op.Op == PatchTest ||
+		op.Op == PatchSet || op.Op == PatchAddToSlice || op.Op == PatchRemoveFromSlice
+	if needsValue && !op.HasValue {
+		return nil, fmt.Errorf("missing value")
+	}
+	value := copyValue(op.Value)
+
+	switch op.Op {
+	case PatchAdd:
+		return update(doc, path, false, func(parent interface{}, key string) (interface{}, error) {
+			return insertChild(parent, key, value)
+		})
+
+	case PatchRemove:
+		// Legacy dot-path removals of missing keys were no-ops
+		if !isPointer(op.Path) {
+			if _, err := lookup(doc, path); err != nil {
+				return doc, nil
+			}
+		}
+		return update(doc, path, false, func(parent interface{}, key string) (interface{}, error) {
+			return removeChild(parent, key)
+		})
+
+	case PatchReplace:
+		return update(doc, path, false, func(parent interface{}, key string) (interface{}, error) {
+			if _, err := getChild(parent, key); err != nil {
+				return nil, err
+			}
+			return setChild(parent, key, value)
+		})
+
+	case PatchMove, PatchCopy:
+		from, err := parsePath(op.From)
+		if err != nil {
+			return nil, fmt.Errorf("from: %w", err)
+		}
+		moved, err := lookup(doc, from)
+		if err != nil {
+			return nil, fmt.Errorf("from: %w", err)
+		}
+		if op.Op == PatchMove {
+			if isPrefix(from, path) {
+				if len(from) == len(path) {
+					return doc, nil
+				}
+				return nil, fmt.Errorf("cannot move a value into itself")
+			}
+			if doc, err = update(doc, from, false, func(parent interface{}, key string) (interface{}, error) {
+				return removeChild(parent, key)
+			}); err != nil {
+				return nil, err
+			}
+		} else {
+			moved = copyValue(moved)
+		}
+		return update(doc, path, false, func(parent interface{}, key string) (interface{}, error) {
+			return insertChild(parent, key, moved)
+		})
+
+	case PatchTest:
+		actual, err := lookup(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !jsonEqual(actual, value) {
+			return nil, fmt.Errorf("test failed")
+		}
+		return doc, nil
+
+	case PatchSet:
+		return update(doc, path, true, func(parent interface{}, key string) (interface{}, error) {
+			return setChild(parent, key, value)
+		})
+
+	case PatchAddToSlice:
+		return update(doc, path, true, func(parent interface{}, key string) (interface{}, error) {
+			list, ok := childOrNil(parent, key).([]interface{})
+			if !ok {
+				// A missing or non-list value becomes a single-item list
+				return setChild(parent, key, []interface{}{value})
+			}
+			for _, item := range list {
+				if jsonEqual(item, value) {
+					return parent, nil
+				}
+			}
+			return setChild(parent, key, append(list, value))
+		})
+
+	case PatchRemoveFromSlice:
+		list, ok := childOrNilAt(doc, path).([]interface{})
+		if !ok {
+			return doc, nil
+		}
+		for i, item := range list {
+			if jsonEqual(item, value) {
+				remaining := append(list[:i:i], list[i+1:]...)
+				return update(doc, path, false, func(parent interface{}, key string) (interface{}, error) {
+					return setChild(parent, key, remaining)
+				})
+			}
+		}
+		return doc, nil
+
+	default:
+		return nil, fmt.Errorf("unknown operation %q", op.Op)
+	}
+}
+
+// parsePath splits a JSON Pointer or a legacy dot path into reference tokens.
+func parsePath(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	if !isPointer(path) {
+		return strings.Split(path, "."), nil
+	}
+	tokens := strings.Split(path[1:], "/")
+	for i, token := range tokens {
+		if strings.Contains(strings.ReplaceAll(strings.ReplaceAll(token, "~0", ""), "~1", ""), "~") {
+			return nil, fmt.Errorf("invalid escape in %q", path)
+		}
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func isPointer(path string) bool {
+	return strings.HasPrefix(path, "/")
+}
+
+func isPrefix(prefix, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// update applies fn to the parent container of the path and writes the rebuilt containers back up,
+// since inserting into a list may reallocate it. With create, missing or non-object intermediate
+// values are replaced by objects (legacy set semantics). An empty path replaces the whole document.
+func update(node interface{}, path []string, create bool, fn func(parent interface{}, key string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 0 {
+		wrapper := map[string]interface{}{"": node}
+		result, err := fn(wrapper, "")
+		if err != nil {
+			return nil, err
+		}
+		return result.(map[string]interface{})[""], nil
+	}
+	if len(path) == 1 {
+		return fn(node, path[0])
+	}
+
+	child, err := getChild(node, path[0])
+	if create && (err != nil || !isContainer(child)) {
+		if _, isMap := node.(map[string]interface{}); isMap {
+			child, err = make(map[string]interface{}), nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	child, err = update(child, path[1:], create, fn)
+	if err != nil {
+		return nil, err
+	}
+	return setChild(node, path[0], child)
+}
+
+func isContainer(v interface{}) bool {
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+		return true
+	}
+	return false
+}
+
+// lookup returns the value at the path.
+func lookup(node interface{}, path []string) (interface{}, error) {
+	for _, key := range path {
+		child, err := getChild(node, key)
+		if err != nil {
+			return nil, err
+		}
+		node = child
+	}
+	return node, nil
+}
+
+func childOrNil(parent interface{}, key string) interface{} {
+	child, _ := getChild(parent, key)
+	return child
+}
+
+func childOrNilAt(node interface{}, path []string) interface{} {
+	child, _ := lookup(node, path)
+	return child
+}
+
+func getChild(parent interface{}, key string) (interface{}, error) {
+	switch p := parent.(type) {
+	case map[string]interface{}:
+		child, ok := p[key]
+		if !ok {
+			return nil, fmt.Errorf("path %q not found", key)
+		}
+		return child, nil
+	case []interface{}:
+		i, err := listIndex(key, len(p)-1)
+		if err != nil {
+			return nil, err
+		}
+		return p[i], nil
+	default:
+		return nil, fmt.Errorf("cannot descend into %T at %q", parent, key)
+	}
+}
+
+// setChild replaces an existing list element or sets an object member.
+func setChild(parent interface{}, key string, value interface{}) (interface{}, error) {
+	switch p := parent.(type) {
+	case map[string]interface{}:
+		p[key] = value
+		return p, nil
+	case []interface{}:
+		if key == "-" {
+			return append(p, value), nil
+		}
+		i, err := listIndex(key, len(p)-1)
+		if err != nil {
+			return nil, err
+		}
+		p[i] = value
+		return p, nil
+	default:
+		return nil, fmt.Errorf("cannot set %q in %T", key, parent)
+	}
+}
+
+// insertChild implements add: lists get the value inserted before the index ("-" appends).
+func insertChild(parent interface{}, key string, value interface{}) (interface{}, error) {
+	p, ok := parent.([]interface{})
+	if !ok {
+		return setChild(parent, key, value)
+	}
+	if key == "-" {
+		return append(p, value), nil
+	}
+	i, err := listIndex(key, len(p))
+	if err != nil {
+		return nil, err
+	}
+	p = append(p, nil)
+	copy(p[i+1:], p[i:])
+	p[i] = value
+	return p, nil
+}
+
+func removeChild(parent interface{}, key string) (interface{}, error) {
+	switch p := parent.(type) {
+	case map[string]interface{}:
+		if _, ok := p[key]; !ok {
+			return nil, fmt.Errorf("path %q not found", key)
+		}
+		delete(p, key)
+		return p, nil
+	case []interface{}:
+		i, err := listIndex(key, len(p)-1)
+		if err != nil {
+			return nil, err
+		}
+		return append(p[:i], p[i+1:]...), nil
+	default:
+		return nil, fmt.Errorf("cannot remove %q from %T", key, parent)
+	}
+}
+
+// listIndex parses a list index in [0, max].
+func listIndex(key string, max int) (int, error) {
+	i, err := strconv.Atoi(key)
+	if err != nil || i < 0 || (len(key) > 1 && key[0] == '0') {
+		return 0, fmt.Errorf("invalid list index %q", key)
+	}
+	if i > max {
+		return 0, fmt.Errorf("list index %d out of range", i)
+	}
+	return i, nil
+}
+
+// copyValue deep-copies a JSON-like value; []string lists become []interface{}.
+func copyValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			out[k] = copyValue(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			out[i] = copyValue(item)
+		}
+		return out
+	case []string:
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			out[i] = item
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// jsonEqual compares values by their JSON form, so 1 and 1.0 are equal.
+func jsonEqual(a, b interface{}) bool {
+	left, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	right, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return string(left) == string(right)
+}
@@ -0,0 +1,137 @@
+package entity
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func decodePatch(t *testing.T, data string) []PatchOperation {
+	t.Helper()
+	var ops []PatchOperation
+	if err := json.Unmarshal([]byte(data), &ops); err != nil {
+		t.Fatalf("decode patch: %v", err)
+	}
+	return ops
+}
+
+func decodeDoc(t *testing.T, data string) map[string]interface{} {
+	t.Helper()
+	var doc map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &doc); err != nil {
+		t.Fatalf("decode document: %v", err)
+	}
+	return doc
+}
+
+func TestPatchDocumentRFC6902(t *testing.T) {
+	cases := []struct {
+		name  string
+		doc   string
+		patch string
+		want  string
+	}{
+		{"add member", `{"a":1}`, `[{"op":"add","path":"/b","value":2}]`, `{"a":1,"b":2}`},
+		{"add inserts into list", `{"l":[1,3]}`, `[{"op":"add","path":"/l/1","value":2}]`, `{"l":[1,2,3]}`},
+		{"add appends to list", `{"l":[1]}`, `[{"op":"add","path":"/l/-","value":2}]`, `{"l":[1,2]}`},
+		{"add null", `{}`, `[{"op":"add","path":"/a","value":null}]`, `{"a":null}`},
+		{"remove list element", `{"l":[1,2,3]}`, `[{"op":"remove","path":"/l/0"}]`, `{"l":[2,3]}`},
+		{"replace nested", `{"s":{"hp":10}}`, `[{"op":"replace","path":"/s/hp","value":5}]`, `{"s":{"hp":5}}`},
+		{"move", `{"a":{"x":1},"b":{}}`, `[{"op":"move","from":"/a/x","path":"/b/y"}]`, `{"a":{},"b":{"y":1}}`},
+		{"move within list", `{"l":[1,2,3]}`, `[{"op":"move","from":"/l/0","path":"/l/-"}]`, `{"l":[2,3,1]}`},
+		{"copy", `{"a":{"x":1}}`, `[{"op":"copy","from":"/a","path":"/b"}]`, `{"a":{"x":1},"b":{"x":1}}`},
+		{"test passes", `{"a":[1,{"b":"c"}]}`, `[{"op":"test","path":"/a","value":[1,{"b":"c"}]}]`, `{"a":[1,{"b":"c"}]}`},
+		{"escaped pointer", `{"a/b":{"~c":1}}`, `[{"op":"replace","path":"/a~1b/~0c","value":2}]`, `{"a/b":{"~c":2}}`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, _, err := PatchDocument(decodeDoc(t, tc.doc), decodePatch(t, tc.patch))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want := decodeDoc(t, tc.want); !reflect.DeepEqual(got, want) {
+				t.Errorf("expected %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestPatchDocumentErrors(t *testing.T) {
+	cases := []struct {
+		name  string
+		patch string
+	}{
+		{"test fails", `[{"op":"test","path":"/a","value":2}]`},
+		{"remove missing", `[{"op":"remove","path":"/missing"}]`},
+		{"replace missing", `[{"op":"replace","path":"/missing","value":1}]`},
+		{"add without parent", `[{"op":"add","path":"/x/y","value":1}]`},
+		{"index out of range", `[{"op":"add","path":"/l/5","value":1}]`},
+		{"leading zero index", `[{"op":"remove","path":"/l/01"}]`},
+		{"move into itself", `[{"op":"move","from":"/o","path":"/o/child"}]`},
+		{"missing value", `[{"op":"add","path":"/b"}]`},
+		{"unknown op", `[{"op":"merge","path":"/a","value":1}]`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, _, err := PatchDocument(decodeDoc(t, `{"a":1,"l":[1,2],"o":{}}`), decodePatch(t, tc.patch)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestApplyPatchIsAtomic(t *testing.T) {
+	ent := NewEntity("npc-1", "npc", decodeDoc(t, `{"hp":10,"inventory":["sword"]}`))
+	updatedAt := ent.UpdatedAt
+
+	changed, err := ent.ApplyPatch(decodePatch(t, `[
+		{"op":"replace","path":"/hp","value":5},
+		{"op":"add","path":"/inventory/-","value":"shield"},
+		{"op":"test","path":"/hp","value":10}
+	]`))
+	if err == nil || changed {
+		t.Fatalf("expected failed patch, got changed=%v err=%v", changed, err)
+	}
+	if want := decodeDoc(t, `{"hp":10,"inventory":["sword"]}`); !reflect.DeepEqual(ent.Payload, want) {
+		t.Errorf("payload must stay untouched, got %v", ent.Payload)
+	}
+	if !ent.UpdatedAt.Equal(updatedAt) {
+		t.Error("updated_at must not change on a failed patch")
+	}
+}
+
+func TestApplyPatchLegacyOperations(t *testing.T) {
+	ent := NewEntity("npc-1", "npc", map[string]interface{}{
+		"inventory": []string{"sword"},
+		"gone":      true,
+	})
+
+	changed, err := ent.ApplyPatch(decodePatch(t, `[
+		{"op":"set","path":"stats.hp","value":100},
+		{"op":"add_to_slice","path":"inventory","value":"shield"},
+		{"op":"add_to_slice","path":"inventory","value":"sword"},
+		{"op":"remove_from_slice","path":"inventory","value":"sword"},
+		{"op":"remove_from_slice","path":"missing","value":"x"},
+		{"op":"add_to_slice","path":"skills","value":"void_call"},
+		{"op":"remove","path":"gone"},
+		{"op":"remove","path":"never.existed"}
+	]`))
+	if err != nil || !changed {
+		t.Fatalf("expected applied patch, got changed=%v err=%v", changed, err)
+	}
+
+	want := decodeDoc(t, `{"stats":{"hp":100},"inventory":["shield"],"skills":["void_call"]}`)
+	if !reflect.DeepEqual(ent.Payload, want) {
+		t.Errorf("expected %v, got %v", want, ent.Payload)
+	}
+}
+
+func TestApplyPatchNoChange(t *testing.T) {
+	ent := NewEntity("npc-1", "npc", decodeDoc(t, `{"hp":10}`))
+	changed, err := ent.ApplyPatch(decodePatch(t, `[{"op":"set","path":"hp","value":10}]`))
+	if err != nil || changed {
+		t.Errorf("expected idempotent set, got changed=%v err=%v", changed, err)
+	}
+}
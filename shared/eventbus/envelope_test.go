@@ -0,0 +1,80 @@
+package eventbus
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNewEventSetsVersion(t *testing.T) {
+	ev := NewEvent("player.moved", "test", "world-1", nil)
+	if ev.Version != EventVersion {
+		t.Errorf("expected version %d, got %d", EventVersion, ev.Version)
+	}
+	if ev.CorrelationID != "" || ev.CausationID != "" {
+		t.Errorf("expected a root event, got correlation %q causation %q", ev.CorrelationID, ev.CausationID)
+	}
+	if ev.Correlation() != ev.ID {
+		t.Errorf("expected root event to start its own chain, got %q", ev.Correlation())
+	}
+}
+
+func TestCausedByChain(t *testing.T) {
+	root := NewEvent("player.action", "test", "world-1", nil)
+	child := NewChildEvent(root, "violation.detected", "test", map[string]any{"reason": "spam"})
+	grandchild := NewEvent("player.sanctioned", "test", "world-1", nil).CausedBy(child)
+
+	if child.CausationID != root.ID || child.CorrelationID != root.ID {
+		t.Errorf("child: expected causation and correlation %q, got %q / %q", root.ID, child.CausationID, child.CorrelationID)
+	}
+	if grandchild.CausationID != child.ID || grandchild.CorrelationID != root.ID {
+		t.Errorf("grandchild: expected causation %q correlation %q, got %q / %q",
+			child.ID, root.ID, grandchild.CausationID, grandchild.CorrelationID)
+	}
+	if GetWorldIDFromEvent(child) != "world-1" {
+		t.Errorf("expected child event in the cause's world, got %q", GetWorldIDFromEvent(child))
+	}
+}
+
+func TestEnvelopeJSON(t *testing.T) {
+	// События старого формата читаются без версии и цепочки
+	var legacy Event
+	if err := json.Unmarshal([]byte(`{"id":"ev-1","type":"player.moved","payload":{}}`), &legacy); err != nil {
+		t.Fatal(err)
+	}
+	if legacy.Version != 0 || legacy.Correlation() != "ev-1" {
+		t.Errorf("unexpected legacy envelope: version %d correlation %q", legacy.Version, legacy.Correlation())
+	}
+
+	ev := NewChildEvent(legacy, "player.warned", "test", nil)
+	data, err := json.Marshal(ev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded["version"] != float64(EventVersion) || decoded["correlation_id"] != "ev-1" || decoded["causation_id"] != "ev-1" {
+		t.Errorf("unexpected envelope %s", data)
+	}
+}
+
+func TestEventLoggerFields(t *testing.T) {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	ev := NewEvent("player.moved", "game-service", "world-1", nil)
+	ev.Scope = &ScopeRef{ID: "player:kain", Type: "player"}
+	ev.Logger().Info("Handled")
+
+	for _, field := range []string{"event_id=" + ev.ID, "world_id=world-1", "scope_id=player:kain", "event_type=player.moved"} {
+		if !strings.Contains(buf.String(), field) {
+			t.Errorf("expected %s in %q", field, buf.String())
+		}
+	}
+}
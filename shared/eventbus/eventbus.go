@@ -3,65 +3,158 @@ package eventbus
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"os"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/segmentio/kafka-go"
+	"multiverse-core.io/shared/logging"
+	"multiverse-core.io/shared/tracing"
 )
 
 type EventBus struct {
+	mu      sync.Mutex // защищает writers
 	writers map[string]*kafka.Writer
 	brokers []string
 }
 
+// ensureTopicsTimeout ограничивает создание топиков при запуске.
+const ensureTopicsTimeout = 10 * time.Second
+
+// NewEventBus создаёт шину и, если не задано KAFKA_AUTO_CREATE_TOPICS=false, создаёт
+// отсутствующие CoreTopics: в пустом кластере Redpanda публикация и подписка иначе молча не работают.
+// Ошибка создания топиков только логируется — брокер может быть ещё недоступен.
 func NewEventBus(brokers []string) *EventBus {
-	topics := []string{
-		TopicPlayerEvents,
-		TopicWorldEvents,
-		TopicGameEvents,
-		TopicSystemEvents,
-		TopicScopeManagement,
-		TopicNarrativeOutput,
-	}
 	writers := make(map[string]*kafka.Writer)
-	for _, topic := range topics {
-		writers[topic] = &kafka.Writer{
-			Addr:     kafka.TCP(brokers...),
-			Topic:    topic,
-			Balancer: &kafka.LeastBytes{},
-		}
+	for _, topic := range CoreTopics {
+		writers[topic] = newWriter(brokers, topic)
 	}
-	return &EventBus{
+	eb := &EventBus{
 		writers: writers,
 		brokers: brokers,
 	}
+
+	if autoCreateTopics() {
+		ctx, cancel := context.WithTimeout(context.Background(), ensureTopicsTimeout)
+		defer cancel()
+		if err := eb.EnsureTopics(ctx, DefaultTopicConfigs()); err != nil {
+			logging.Errorf("Failed to ensure topics: %v", err)
+		}
+	}
+	return eb
+}
+
+// autoCreateTopics сообщает, создаёт ли шина отсутствующие топики (KAFKA_AUTO_CREATE_TOPICS, по умолчанию да).
+func autoCreateTopics() bool {
+	autoCreate, err := strconv.ParseBool(os.Getenv("KAFKA_AUTO_CREATE_TOPICS"))
+	return err != nil || autoCreate
+}
+
+func newWriter(brokers []string, topic string) *kafka.Writer {
+	return &kafka.Writer{
+		Addr:     kafka.TCP(brokers...),
+		Topic:    topic,
+		Balancer: &kafka.LeastBytes{},
+	}
+}
+
+// writer возвращает writer топика. Для топиков вне CoreTopics (например, цели повторной
+// публикации архива) он создаётся при первой публикации, а топик — как CoreTopics.
+func (eb *EventBus) writer(topic string) *kafka.Writer {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+
+	if w, ok := eb.writers[topic]; ok {
+		return w
+	}
+	if autoCreateTopics() {
+		config := DefaultTopicConfigs()[0]
+		config.Topic = topic
+		ctx, cancel := context.WithTimeout(context.Background(), ensureTopicsTimeout)
+		if err := eb.EnsureTopics(ctx, []TopicConfig{config}); err != nil {
+			logging.Errorf("Failed to ensure topic %s: %v", topic, err)
+		}
+		cancel()
+	}
+	w := newWriter(eb.brokers, topic)
+	eb.writers[topic] = w
+	return w
+}
+
+// EnsureTopics создаёт отсутствующие топики через admin API брокера.
+// Уже существующие топики не считаются ошибкой и не изменяются.
+func (eb *EventBus) EnsureTopics(ctx context.Context, topics []TopicConfig) error {
+	if len(topics) == 0 {
+		return nil
+	}
+
+	req := &kafka.CreateTopicsRequest{}
+	for _, topic := range topics {
+		req.Topics = append(req.Topics, topic.kafkaConfig())
+	}
+	client := &kafka.Client{Addr: kafka.TCP(eb.brokers...)}
+	resp, err := client.CreateTopics(ctx, req)
+	if err != nil {
+		return fmt.Errorf("create topics: %w", err)
+	}
+
+	var errs []error
+	for _, topic := range topics {
+		err := resp.Errors[topic.Topic]
+		switch {
+		case err == nil:
+			logging.Infof("Created topic %s", topic.Topic)
+		case errors.Is(err, kafka.TopicAlreadyExists):
+		default:
+			errs = append(errs, fmt.Errorf("create topic %s: %w", topic.Topic, err))
+		}
+	}
+	return errors.Join(errs...)
 }
 
 func (eb *EventBus) Publish(ctx context.Context, topic string, event Event) error {
+	if topic == "" {
+		return fmt.Errorf("event %s: topic is required", event.ID)
+	}
 	if event.ID == "" || event.Type == "" {
 		return fmt.Errorf("event missing required fields: id=%q, type=%q",
 			event.ID, event.Type)
 	}
 
+	// Корневые события начинают цепочку; собранные без NewEvent получают текущую версию
+	if event.Version == 0 {
+		event.Version = EventVersion
+	}
+	event.CorrelationID = event.Correlation()
+
 	// Ключ для Kafka — world.entity.id или "global"
 	worldKey := "global"
 	if event.World != nil && event.World.Entity.ID != "" {
 		worldKey = event.World.Entity.ID
 	}
 
-	msg, err := json.Marshal(event)
+	data, err := json.Marshal(event)
 	if err != nil {
 		return fmt.Errorf("marshal event: %w", err)
 	}
-	return eb.writers[topic].WriteMessages(ctx, kafka.Message{
+
+	ctx, span := startPublishSpan(ctx, topic, event)
+	msg := kafka.Message{
 		Key:   []byte(worldKey),
-		Value: msg,
-	})
+		Value: data,
+	}
+	injectTrace(ctx, &msg)
+	err = eb.writer(topic).WriteMessages(ctx, msg)
+	tracing.End(span, err)
+	return err
 }
 
+// Subscribe читает топик в группе groupID и передаёт события handler, пока ctx не отменён.
+// topic может быть шаблоном path.Match ("world.metrics.*"): тогда читаются все подходящие топики
+// (см. subscribePattern).
 func (eb *EventBus) Subscribe(ctx context.Context, topic, groupID string, handler func(Event)) {
 	// Get polling frequency from environment variable, default to 1 second
 	pollFreqStr := os.Getenv("KAFKA_POLL_FREQUENCY_MS")
@@ -70,12 +163,18 @@ func (eb *EventBus) Subscribe(ctx context.Context, topic, groupID string, handle
 	}
 	pollFreqMs, err := strconv.Atoi(pollFreqStr)
 	if err != nil {
-		log.Printf("Invalid KAFKA_POLL_FREQUENCY_MS value: %v, using default 1000ms", err)
+		logging.Warnf("Invalid KAFKA_POLL_FREQUENCY_MS value: %v, using default 1000ms", err)
 		pollFreqMs = 1000
 	}
 
 	maxWait := time.Millisecond * time.Duration(pollFreqMs)
 
+	// Шаблон ("world.metrics.*") раскрывается в подходящие топики брокера
+	if IsTopicPattern(topic) {
+		eb.subscribePattern(ctx, topic, groupID, maxWait, handler)
+		return
+	}
+
 	reader := kafka.NewReader(kafka.ReaderConfig{
 		Brokers:  eb.brokers,
 		Topic:    topic,
@@ -84,30 +183,43 @@ func (eb *EventBus) Subscribe(ctx context.Context, topic, groupID string, handle
 		MaxBytes: 10e6,
 		MaxWait:  maxWait,
 	})
+	logging.Infof("Subscribed to %s as %s", topic, groupID)
+	eb.consume(ctx, reader, topic, groupID, handler)
+}
+
+// consume читает сообщения reader и передаёт события handler, пока ctx не отменён; закрывает reader.
+// label — топик или шаблон подписки для логов.
+func (eb *EventBus) consume(ctx context.Context, reader *kafka.Reader, label, groupID string, handler func(Event)) {
 	defer reader.Close()
-	log.Printf("Subscribed to %s as %s", topic, groupID)
 	for {
 		m, err := reader.ReadMessage(ctx)
 		if err != nil {
 			select {
 			case <-ctx.Done():
-				log.Printf("Subscription to %s stopped: %v", topic, ctx.Err())
+				logging.Infof("Subscription to %s stopped: %v", label, ctx.Err())
 				return
 			default:
-				log.Printf("Read error on %s: %v", topic, err)
+				logging.Errorf("Read error on %s: %v", label, err)
 			}
 			continue
 		}
 		var event Event
 		if err := json.Unmarshal(m.Value, &event); err != nil {
-			log.Printf("Parse error on %s key=%s: %v", topic, string(m.Key), err)
+			logging.Errorf("Parse error on %s key=%s: %v", m.Topic, string(m.Key), err)
 			continue
 		}
+		// Трасса из заголовков сообщения доступна обработчику через event.TraceContext
+		_, span := startProcessSpan(ctx, m.Topic, groupID, m, event)
+		event.spanContext = span.SpanContext()
 		handler(event)
+		span.End()
 	}
 }
 
 func (eb *EventBus) Close() error {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+
 	var errs []error
 	for topic, writer := range eb.writers {
 		if err := writer.Close(); err != nil {
@@ -116,7 +228,7 @@ func (eb *EventBus) Close() error {
 	}
 	if len(errs) > 0 {
 		for _, err := range errs[1:] {
-			log.Printf("Additional close error: %v", err)
+			logging.Errorf("Additional close error: %v", err)
 		}
 		return errs[0]
 	}
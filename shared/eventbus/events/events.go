@@ -0,0 +1,159 @@
+// Package events описывает payload основных типов событий структурами вместо map[string]any.
+//
+// Структура задаёт и формат события на шине, и способ его чтения:
+//
+//	ev, err := events.New("game-service", worldID, events.EntityCreated{...})
+//
+//	var created events.EntityCreated
+//	if err := events.Unmarshal(ev, &created); err != nil { ... }
+//
+// Payload сериализуется через JSON, поэтому числа, время и вложенные структуры читаются
+// одинаково и из только что созданного события, и из пришедшего из Kafka.
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"multiverse-core.io/shared/eventbus"
+)
+
+// ErrUnexpectedType — тип события не совпадает с типом payload.
+var ErrUnexpectedType = errors.New("unexpected event type")
+
+// Payload — типизированный payload события определённого типа.
+type Payload interface {
+	EventType() string
+}
+
+// Subject — общие поля событий о сущности: кто, над кем, в каком мире и скоупе.
+// При чтении заполняется и из событий старого формата (entity_id, player_id, world_id, ...).
+type Subject struct {
+	Entity *eventbus.Entity   `json:"entity,omitempty"`
+	Target *eventbus.Entity   `json:"target,omitempty"`
+	World  *eventbus.WorldRef `json:"world,omitempty"`
+	Scope  *eventbus.ScopeRef `json:"scope,omitempty"`
+}
+
+func (s *Subject) subject() *Subject { return s }
+
+// EntityID возвращает ID основной сущности события.
+func (s Subject) EntityID() string {
+	if s.Entity == nil {
+		return ""
+	}
+	return s.Entity.Entity.ID
+}
+
+// EntityType возвращает тип основной сущности события.
+func (s Subject) EntityType() string {
+	if s.Entity == nil {
+		return ""
+	}
+	return s.Entity.Entity.Type
+}
+
+// TargetID возвращает ID целевой сущности события.
+func (s Subject) TargetID() string {
+	if s.Target == nil {
+		return ""
+	}
+	return s.Target.Entity.ID
+}
+
+// WorldID возвращает ID мира события.
+func (s Subject) WorldID() string {
+	if s.World == nil {
+		return ""
+	}
+	return s.World.Entity.ID
+}
+
+// EntityOf возвращает ссылку на сущность для полей Subject; nil для пустого ID.
+func EntityOf(id, entityType, name string) *eventbus.Entity {
+	if id == "" {
+		return nil
+	}
+	return &eventbus.Entity{Entity: eventbus.EntityRef{ID: id, Type: entityType}, Name: name}
+}
+
+// WorldOf возвращает ссылку на мир; nil для пустого ID.
+func WorldOf(worldID string) *eventbus.WorldRef {
+	if worldID == "" {
+		return nil
+	}
+	return &eventbus.WorldRef{Entity: eventbus.EntityRef{ID: worldID, Type: "world"}}
+}
+
+// New создаёт корневое событие с типом и payload из p.
+func New(source, worldID string, p Payload) (eventbus.Event, error) {
+	payload, err := Marshal(p)
+	if err != nil {
+		return eventbus.Event{}, err
+	}
+	return eventbus.NewEvent(p.EventType(), source, worldID, payload), nil
+}
+
+// NewChild создаёт событие, вызванное cause, в мире причины (см. eventbus.NewChildEvent).
+func NewChild(cause eventbus.Event, source string, p Payload) (eventbus.Event, error) {
+	payload, err := Marshal(p)
+	if err != nil {
+		return eventbus.Event{}, err
+	}
+	return eventbus.NewChildEvent(cause, p.EventType(), source, payload), nil
+}
+
+// Marshal переводит payload в map[string]any — в том виде, в каком его увидит получатель.
+func Marshal(p Payload) (map[string]any, error) {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, fmt.Errorf("marshal %s payload: %w", p.EventType(), err)
+	}
+	var payload map[string]any
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, fmt.Errorf("marshal %s payload: %w", p.EventType(), err)
+	}
+	return payload, nil
+}
+
+// Unmarshal читает payload события в p. Тип события должен совпадать с p.EventType().
+func Unmarshal(ev eventbus.Event, p Payload) error {
+	if ev.Type != p.EventType() {
+		return fmt.Errorf("%w: %s, want %s", ErrUnexpectedType, ev.Type, p.EventType())
+	}
+	data, err := json.Marshal(ev.Payload)
+	if err != nil {
+		return fmt.Errorf("unmarshal %s payload: %w", ev.Type, err)
+	}
+	if err := json.Unmarshal(data, p); err != nil {
+		return fmt.Errorf("unmarshal %s payload: %w", ev.Type, err)
+	}
+	if s, ok := p.(interface{ subject() *Subject }); ok {
+		fillSubject(s.subject(), ev)
+	}
+	return nil
+}
+
+// fillSubject дополняет Subject ссылками из событий старого и усечённого форматов.
+func fillSubject(s *Subject, ev eventbus.Event) {
+	if s.EntityID() == "" {
+		s.Entity = entityFromInfo(eventbus.ExtractEntityID(ev.Payload))
+	}
+	if s.TargetID() == "" {
+		s.Target = entityFromInfo(eventbus.ExtractTargetEntityID(ev.Payload))
+	}
+	if s.WorldID() == "" {
+		s.World = WorldOf(eventbus.GetWorldIDFromEvent(ev))
+	}
+	if s.Scope == nil {
+		s.Scope = eventbus.GetScopeFromEvent(ev)
+	}
+}
+
+func entityFromInfo(info *eventbus.EntityInfo) *eventbus.Entity {
+	if info == nil {
+		return nil
+	}
+	return EntityOf(info.ID, info.Type, info.Name)
+}
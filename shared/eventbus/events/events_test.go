@@ -0,0 +1,138 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/worldtime"
+)
+
+// roundTrip передаёт событие через JSON, как это делает Kafka
+func roundTrip(t *testing.T, ev eventbus.Event) eventbus.Event {
+	t.Helper()
+	data, err := json.Marshal(ev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded eventbus.Event
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	return decoded
+}
+
+func TestQuestAssignedRoundTrip(t *testing.T) {
+	deadline := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	sent := QuestAssigned{
+		Subject:    Subject{Entity: EntityOf("player-1", "player", ""), World: WorldOf("world-1"), Scope: &eventbus.ScopeRef{ID: "city-1", Type: "city"}},
+		QuestID:    "quest-1",
+		QuestType:  "welcome",
+		Title:      "Слёзы Памяти",
+		Objectives: []string{"Найти", "Принести"},
+		Reward:     QuestReward{Gold: 50, Reputation: 5},
+		Deadline:   deadline,
+		City:       &CityRef{ID: "city-1"},
+	}
+	ev, err := New("city-governor", "world-1", sent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.Type != TypeQuestAssigned {
+		t.Fatalf("unexpected event type %s", ev.Type)
+	}
+	// Payload остаётся совместимым с чтением по dot-путям
+	if id, _ := ev.Path().GetString("entity.entity.id"); id != "player-1" {
+		t.Errorf("expected nested entity id, got %q", id)
+	}
+
+	var got QuestAssigned
+	if err := Unmarshal(roundTrip(t, ev), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.EntityID() != "player-1" || got.WorldID() != "world-1" || got.Scope.ID != "city-1" {
+		t.Errorf("unexpected subject %+v", got.Subject)
+	}
+	if got.QuestID != "quest-1" || got.Reward.Gold != 50 || len(got.Objectives) != 2 || !got.Deadline.Equal(deadline) {
+		t.Errorf("unexpected quest %+v", got)
+	}
+}
+
+func TestTimeSyncFromFloat(t *testing.T) {
+	now := time.UnixMilli(1740830400123)
+	// После Kafka числа приходят как float64
+	ev := eventbus.NewEvent(TypeTimeSync, "test", "world-1", map[string]any{"current_time_unix_ms": float64(now.UnixMilli())})
+
+	var tick TimeSync
+	if err := Unmarshal(ev, &tick); err != nil {
+		t.Fatal(err)
+	}
+	if !tick.Time().Equal(now) {
+		t.Errorf("expected %s, got %s", now, tick.Time())
+	}
+}
+
+func TestTimeSyncWorldTime(t *testing.T) {
+	if _, ok := NewTimeSync(time.Now()).WorldTime(); ok {
+		t.Error("tick without world time reported one")
+	}
+
+	date := worldtime.DefaultCalendar().Date(0)
+	ev, err := New("chronos", "world-1", TimeSync{CurrentTimeUnixMs: time.Now().UnixMilli(), Rate: 60, Calendar: &date})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tick TimeSync
+	if err := Unmarshal(ev, &tick); err != nil {
+		t.Fatal(err)
+	}
+	if worldTime, ok := tick.WorldTime(); !ok || worldTime != 0 {
+		t.Errorf("world start tick = %v, %v", worldTime, ok)
+	}
+	if tick.Calendar == nil || tick.Calendar.Festival != date.Festival || tick.Rate != 60 {
+		t.Errorf("unexpected tick %+v", tick)
+	}
+}
+
+func TestUnmarshalLegacyPayload(t *testing.T) {
+	ev := eventbus.NewEvent(TypePlayerUsedSkill, "test", "world-1", map[string]any{
+		"player_id": "player-1",
+		"target_id": "npc-1",
+		"skill":     "fire_breath",
+	})
+
+	var used PlayerUsedSkill
+	if err := Unmarshal(ev, &used); err != nil {
+		t.Fatal(err)
+	}
+	if used.EntityID() != "player-1" || used.TargetID() != "npc-1" || used.WorldID() != "world-1" {
+		t.Errorf("unexpected subject %+v", used.Subject)
+	}
+	if used.SkillName() != "fire_breath" {
+		t.Errorf("expected legacy skill, got %q", used.SkillName())
+	}
+}
+
+func TestUnmarshalUnexpectedType(t *testing.T) {
+	ev := eventbus.NewEvent("player.moved", "test", "world-1", nil)
+	var used PlayerUsedSkill
+	if err := Unmarshal(ev, &used); !errors.Is(err, ErrUnexpectedType) {
+		t.Errorf("expected ErrUnexpectedType, got %v", err)
+	}
+}
+
+func TestNewChild(t *testing.T) {
+	cause := eventbus.NewEvent(TypePlayerUsedSkill, "test", "world-1", nil)
+	ev, err := NewChild(cause, "ban-of-world", ViolationDetected{ViolationType: "elemental_conflict", Location: &Point{X: 1, Y: 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.CausationID != cause.ID || eventbus.GetWorldIDFromEvent(ev) != "world-1" {
+		t.Errorf("expected child of %s in world-1, got %+v", cause.ID, ev)
+	}
+	if x, ok := ev.Path().GetFloat("location.x"); !ok || x != 1 {
+		t.Errorf("expected location.x in payload, got %v", ev.Payload)
+	}
+}
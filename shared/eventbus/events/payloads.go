@@ -0,0 +1,163 @@
+package events
+
+import (
+	"time"
+
+	"multiverse-core.io/shared/worldtime"
+)
+
+// Типы событий с типизированным payload
+const (
+	TypePlayerUsedSkill     = "player.used_skill"
+	TypeEntityCreated       = "entity.created"
+	TypeViolationDetected   = "violation.detected"
+	TypeQuestAssigned       = "quest.assigned"
+	TypeAchievementUnlocked = "achievement.unlocked"
+	TypeTimeSync            = "time.syncTime"
+)
+
+// PlayerUsedSkill — player.used_skill: игрок применил навык, необязательно к цели (Target).
+type PlayerUsedSkill struct {
+	Subject
+	// Command — команда GameService, породившая событие (use_skill)
+	Command string `json:"command,omitempty"`
+	SkillID string `json:"skill_id,omitempty"`
+	// Skill — имя навыка в событиях старого формата
+	Skill string `json:"skill,omitempty"`
+}
+
+// EventType реализует Payload.
+func (PlayerUsedSkill) EventType() string { return TypePlayerUsedSkill }
+
+// SkillName возвращает применённый навык: skill_id или skill старого формата.
+func (p PlayerUsedSkill) SkillName() string {
+	if p.SkillID != "" {
+		return p.SkillID
+	}
+	return p.Skill
+}
+
+// EntityCreated — entity.created: сущность Entity создана с начальным состоянием Payload.
+type EntityCreated struct {
+	Subject
+	Payload map[string]any `json:"payload,omitempty"`
+}
+
+// EventType реализует Payload.
+func (EntityCreated) EventType() string { return TypeEntityCreated }
+
+// Point — координаты на карте мира.
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// ViolationDetected — violation.detected: BanOfWorld зафиксировал нарушение правил мира игроком Entity.
+type ViolationDetected struct {
+	Subject
+	ViolationType string `json:"violation_type"`
+	// Tier — ступень наказания с учётом истории нарушений
+	Tier        string `json:"tier,omitempty"`
+	RuleID      string `json:"rule_id,omitempty"`
+	Forbiddance string `json:"forbiddance,omitempty"`
+	// OriginalEvent — ID нарушившего события, Action — его тип
+	OriginalEvent string `json:"original_event,omitempty"`
+	Action        string `json:"action,omitempty"`
+	// Skill и Item — использованные навык и предмет
+	Skill string `json:"skill,omitempty"`
+	Item  string `json:"item,omitempty"`
+	// AttemptedDestination — мир, в который игрок пытался переместиться
+	AttemptedDestination string `json:"attempted_destination,omitempty"`
+	// Location — точка за границами мира и регионы, в которые она всё же попадает
+	Location *Point   `json:"location,omitempty"`
+	Regions  []string `json:"regions,omitempty"`
+	// Prevented — действие отклонено проверкой до публикации с вердиктом Verdict
+	Prevented bool   `json:"prevented,omitempty"`
+	Verdict   string `json:"verdict,omitempty"`
+}
+
+// EventType реализует Payload.
+func (ViolationDetected) EventType() string { return TypeViolationDetected }
+
+// QuestReward — награда за выполнение квеста.
+type QuestReward struct {
+	Gold       int      `json:"gold"`
+	Items      []string `json:"items,omitempty"`
+	Reputation int      `json:"reputation"` // репутация в городе, выдавшем квест
+}
+
+// CityRef — ссылка на город, выдавший квест.
+type CityRef struct {
+	ID string `json:"id"`
+}
+
+// QuestAssigned — quest.assigned: город выдал квест игроку Entity.
+type QuestAssigned struct {
+	Subject
+	QuestID     string      `json:"quest_id"`
+	QuestType   string      `json:"quest_type"`
+	Title       string      `json:"title"`
+	Description string      `json:"description"`
+	Objectives  []string    `json:"objectives"`
+	Reward      QuestReward `json:"reward"`
+	// Generated — квест написан Oracle, а не взят из шаблона
+	Generated bool `json:"generated"`
+	// Deadline — срок выполнения; нулевое время — бессрочный квест
+	Deadline time.Time `json:"deadline"`
+	City     *CityRef  `json:"city,omitempty"`
+}
+
+// EventType реализует Payload.
+func (QuestAssigned) EventType() string { return TypeQuestAssigned }
+
+// AchievementUnlocked — achievement.unlocked: игрок Entity получил достижение AchievementID.
+// Scope — скоуп события, завершившего достижение (город может выдать награду Reward).
+type AchievementUnlocked struct {
+	Subject
+	AchievementID string `json:"achievement_id"`
+	Name          string `json:"name"`
+	Description   string `json:"description,omitempty"`
+	// Progress — накопленное значение счётчика, Threshold — порог достижения
+	Progress   float64      `json:"progress"`
+	Threshold  float64      `json:"threshold"`
+	Reward     *QuestReward `json:"reward,omitempty"`
+	UnlockedAt time.Time    `json:"unlocked_at"`
+}
+
+// EventType реализует Payload.
+func (AchievementUnlocked) EventType() string { return TypeAchievementUnlocked }
+
+// TimeSync — time.syncTime: периодический тик мирового времени.
+// Тики Chronos публикуются для каждого мира и несут его мировое время и дату;
+// у тиков без мирового времени (WorldTimeMs == 0) есть только реальное время.
+type TimeSync struct {
+	// CurrentTimeUnixMs — реальное время тика
+	CurrentTimeUnixMs int64 `json:"current_time_unix_ms"`
+	// WorldTimeMs — мировое время от сотворения мира
+	WorldTimeMs int64 `json:"world_time_ms,omitempty"`
+	// Rate — мировых секунд на реальную секунду с учётом замедления плана мира
+	Rate      float64         `json:"rate,omitempty"`
+	PlanLevel int             `json:"plan_level,omitempty"`
+	Calendar  *worldtime.Date `json:"calendar,omitempty"`
+}
+
+// NewTimeSync возвращает тик для момента now.
+func NewTimeSync(now time.Time) TimeSync {
+	return TimeSync{CurrentTimeUnixMs: now.UnixMilli()}
+}
+
+// EventType реализует Payload.
+func (TimeSync) EventType() string { return TypeTimeSync }
+
+// Time возвращает момент тика.
+func (p TimeSync) Time() time.Time {
+	return time.UnixMilli(p.CurrentTimeUnixMs)
+}
+
+// WorldTime возвращает мировое время тика; false — тик без мирового времени.
+func (p TimeSync) WorldTime() (time.Duration, bool) {
+	if p.WorldTimeMs == 0 && p.Calendar == nil {
+		return 0, false
+	}
+	return time.Duration(p.WorldTimeMs) * time.Millisecond, true
+}
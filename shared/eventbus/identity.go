@@ -0,0 +1,28 @@
+package eventbus
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnverifiedPlayer — событие игрока без подтверждённой сессией личности
+// или от имени другого игрока.
+var ErrUnverifiedPlayer = errors.New("player is not verified by session identity")
+
+// VerifyPlayer проверяет, что действие playerID подтверждено сессией: game-service добавляет
+// в payload поле identity (player_id, world_id, session_id, verified) и перезаписывает поле клиента.
+// player_id из payload можно подделать, поэтому потребители действий игрока доверяют ему,
+// только если identity.verified и identity.player_id совпадает с playerID, а identity.world_id — с миром события.
+func VerifyPlayer(ev Event, playerID string) error {
+	pa := ev.Path()
+	if verified, _ := pa.GetBool("identity.verified"); !verified {
+		return fmt.Errorf("%w: no verified identity", ErrUnverifiedPlayer)
+	}
+	if identityID, _ := pa.GetString("identity.player_id"); playerID == "" || identityID != playerID {
+		return fmt.Errorf("%w: session of %q acts as %q", ErrUnverifiedPlayer, identityID, playerID)
+	}
+	if worldID, _ := pa.GetString("identity.world_id"); worldID != "" && worldID != GetWorldIDFromEvent(ev) {
+		return fmt.Errorf("%w: session of world %q acts in %q", ErrUnverifiedPlayer, worldID, GetWorldIDFromEvent(ev))
+	}
+	return nil
+}
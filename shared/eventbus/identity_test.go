@@ -0,0 +1,39 @@
+package eventbus
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestVerifyPlayer(t *testing.T) {
+	identity := func(playerID, worldID string, verified bool) map[string]any {
+		return map[string]any{"player_id": playerID, "world_id": worldID, "session_id": "s-1", "verified": verified}
+	}
+	cases := []struct {
+		name     string
+		identity any
+		playerID string
+		ok       bool
+	}{
+		{"verified", identity("player-1", "world-1", true), "player-1", true},
+		{"no identity", nil, "player-1", false},
+		{"not verified", identity("player-1", "world-1", false), "player-1", false},
+		{"other player", identity("player-2", "world-1", true), "player-1", false},
+		{"other world", identity("player-1", "world-2", true), "player-1", false},
+		{"no player", identity("", "world-1", true), "", false},
+	}
+	for _, tc := range cases {
+		payload := NewEventPayload().WithEntity(tc.playerID, "player", "").ToMap()
+		if tc.identity != nil {
+			payload["identity"] = tc.identity
+		}
+		ev := NewEvent("player.used_skill", "game-service", "world-1", payload)
+		err := VerifyPlayer(ev, tc.playerID)
+		if tc.ok && err != nil {
+			t.Errorf("%s: unexpected error %v", tc.name, err)
+		}
+		if !tc.ok && !errors.Is(err, ErrUnverifiedPlayer) {
+			t.Errorf("%s: expected ErrUnverifiedPlayer, got %v", tc.name, err)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"multiverse-core.io/shared/logging"
+)
+
+// DefaultTopicRefreshInterval — как часто шаблонная подписка ищет новые подходящие топики
+// (переопределяется KAFKA_TOPIC_REFRESH_MS).
+const DefaultTopicRefreshInterval = 30 * time.Second
+
+// topicMetadataTimeout ограничивает запрос списка топиков у брокера.
+const topicMetadataTimeout = 10 * time.Second
+
+// IsTopicPattern сообщает, является ли топик подписки шаблоном path.Match ("world.metrics.*").
+func IsTopicPattern(topic string) bool {
+	return strings.ContainsAny(topic, "*?[")
+}
+
+// MatchTopics возвращает отсортированные топики, подходящие под шаблон; служебные топики Kafka ("__*")
+// подходят, только если шаблон начинается с "__".
+func MatchTopics(pattern string, topics []string) []string {
+	var matched []string
+	for _, topic := range topics {
+		if strings.HasPrefix(topic, "__") && !strings.HasPrefix(pattern, "__") {
+			continue
+		}
+		if ok, err := path.Match(pattern, topic); err == nil && ok && !slices.Contains(matched, topic) {
+			matched = append(matched, topic)
+		}
+	}
+	slices.Sort(matched)
+	return matched
+}
+
+// ResolveTopics возвращает существующие топики брокера, подходящие под шаблон.
+func (eb *EventBus) ResolveTopics(ctx context.Context, pattern string) ([]string, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid topic pattern %q: %w", pattern, err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, topicMetadataTimeout)
+	defer cancel()
+
+	client := &kafka.Client{Addr: kafka.TCP(eb.brokers...)}
+	resp, err := client.Metadata(ctx, &kafka.MetadataRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("list topics: %w", err)
+	}
+	names := make([]string, 0, len(resp.Topics))
+	for _, topic := range resp.Topics {
+		if topic.Error == nil && !topic.Internal {
+			names = append(names, topic.Name)
+		}
+	}
+	return MatchTopics(pattern, names), nil
+}
+
+// subscribePattern читает все топики, подходящие под шаблон, в одной группе groupID.
+// Раз в KAFKA_TOPIC_REFRESH_MS топики раскрываются заново: при изменении набора reader
+// пересоздаётся с новым списком, зафиксированные смещения группы сохраняются.
+func (eb *EventBus) subscribePattern(ctx context.Context, pattern, groupID string, maxWait time.Duration, handler func(Event)) {
+	if groupID == "" {
+		logging.Errorf("Subscription to pattern %s requires a consumer group", pattern)
+		return
+	}
+	interval := time.Duration(envInt("KAFKA_TOPIC_REFRESH_MS", int(DefaultTopicRefreshInterval.Milliseconds()))) * time.Millisecond
+	if interval <= 0 {
+		interval = DefaultTopicRefreshInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var (
+		topics  []string
+		current *topicReader
+	)
+	defer func() { current.stop() }()
+
+	for {
+		resolved, err := eb.ResolveTopics(ctx, pattern)
+		switch {
+		case err != nil:
+			logging.Warnf("Failed to resolve topics for %s: %v", pattern, err)
+		case !slices.Equal(resolved, topics):
+			current.stop()
+			current, topics = nil, resolved
+			if len(topics) == 0 {
+				logging.Infof("No topics match %s yet, waiting", pattern)
+				break
+			}
+			current = eb.startTopicReader(ctx, pattern, topics, groupID, maxWait, handler)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// topicReader — чтение набора топиков шаблонной подписки.
+type topicReader struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// startTopicReader начинает читать topics в группе groupID.
+func (eb *EventBus) startTopicReader(ctx context.Context, pattern string, topics []string, groupID string, maxWait time.Duration, handler func(Event)) *topicReader {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     eb.brokers,
+		GroupTopics: topics,
+		GroupID:     groupID,
+		MinBytes:    10e3,
+		MaxBytes:    10e6,
+		MaxWait:     maxWait,
+	})
+	logging.Infof("Subscribed to %s (%s) as %s", pattern, strings.Join(topics, ", "), groupID)
+
+	ctx, cancel := context.WithCancel(ctx)
+	r := &topicReader{cancel: cancel, done: make(chan struct{})}
+	go func() {
+		defer close(r.done)
+		eb.consume(ctx, reader, pattern, groupID, handler)
+	}()
+	return r
+}
+
+// stop останавливает чтение и ждёт его завершения; nil — ничего не читается.
+func (r *topicReader) stop() {
+	if r == nil {
+		return
+	}
+	r.cancel()
+	<-r.done
+}
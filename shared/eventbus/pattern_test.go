@@ -0,0 +1,62 @@
+package eventbus
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestIsTopicPattern(t *testing.T) {
+	for topic, want := range map[string]bool{
+		"world.metrics.*":   true,
+		"world_events":      false,
+		"world.metrics.?":   true,
+		"events.[ab]":       true,
+		"world.metrics.all": false,
+	} {
+		if got := IsTopicPattern(topic); got != want {
+			t.Errorf("IsTopicPattern(%q) = %v, want %v", topic, got, want)
+		}
+	}
+}
+
+func TestMatchTopics(t *testing.T) {
+	topics := []string{"world.metrics.pain-realm", "world_events", "world.metrics.ash-realm", "world.metrics", "__consumer_offsets", "world.metrics.ash-realm"}
+
+	got := MatchTopics("world.metrics.*", topics)
+	if want := []string{"world.metrics.ash-realm", "world.metrics.pain-realm"}; !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if got := MatchTopics("*", topics); slices.Contains(got, "__consumer_offsets") {
+		t.Errorf("internal topics must not match a generic pattern, got %v", got)
+	}
+	if got := MatchTopics("__*", topics); !slices.Equal(got, []string{"__consumer_offsets"}) {
+		t.Errorf("expected internal topics for an explicit pattern, got %v", got)
+	}
+	if got := MatchTopics("world.[", topics); len(got) != 0 {
+		t.Errorf("invalid pattern must match nothing, got %v", got)
+	}
+}
+
+func TestSubscribePatternStops(t *testing.T) {
+	t.Setenv("KAFKA_TOPIC_REFRESH_MS", "10")
+	eb := &EventBus{brokers: []string{"127.0.0.1:1"}}
+	if _, err := eb.ResolveTopics(context.Background(), "world.["); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+
+	// Без брокера подписка ждёт появления топиков и завершается с контекстом
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		eb.Subscribe(ctx, "world.metrics.*", "test-group", func(Event) {})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("pattern subscription did not stop with its context")
+	}
+}
@@ -0,0 +1,28 @@
+package eventbus
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPublishToNonCoreTopic(t *testing.T) {
+	t.Setenv("KAFKA_AUTO_CREATE_TOPICS", "false")
+	bus := NewEventBus([]string{"127.0.0.1:1"})
+	defer bus.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	event := NewEvent("test.event", "test", "world-1", map[string]any{})
+
+	// Брокер недоступен: публикация в топик вне CoreTopics завершается ошибкой, а не паникой
+	if err := bus.Publish(ctx, "replay_events", event); err == nil {
+		t.Error("expected an error from an unreachable broker")
+	}
+	if _, ok := bus.writers["replay_events"]; !ok {
+		t.Error("expected a writer created for replay_events")
+	}
+	if err := bus.Publish(ctx, "", event); err == nil {
+		t.Error("expected an error for an empty topic")
+	}
+}
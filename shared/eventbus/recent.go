@@ -0,0 +1,88 @@
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"multiverse-core.io/shared/logging"
+)
+
+// partitionRetryInterval — пауза между попытками узнать партиции топика, которого ещё нет.
+const partitionRetryInterval = 5 * time.Second
+
+// SubscribeRecent читает все партиции топика без consumer group, начиная с сообщений не старше since,
+// и передаёт события handler, пока ctx не отменён. Каждый подписчик получает все события топика,
+// смещения не фиксируются и группы в брокере не создаются — для широковещательных событий
+// (анонсы сервисов), которые нужны каждому экземпляру. handler вызывается из горутин партиций параллельно.
+// Партиции, добавленные после подписки, не читаются.
+func (eb *EventBus) SubscribeRecent(ctx context.Context, topic string, since time.Duration, handler func(Event)) {
+	var partitions []int
+	for {
+		var err error
+		partitions, err = eb.topicPartitions(ctx, topic)
+		if err == nil && len(partitions) > 0 {
+			break
+		}
+		if err == nil {
+			err = fmt.Errorf("topic has no partitions")
+		}
+		logging.Warnf("Failed to list partitions of %s, retrying: %v", topic, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(partitionRetryInterval):
+		}
+	}
+
+	start := time.Now().Add(-since)
+	var wg sync.WaitGroup
+	for _, partition := range partitions {
+		reader := kafka.NewReader(kafka.ReaderConfig{
+			Brokers:   eb.brokers,
+			Topic:     topic,
+			Partition: partition,
+			MinBytes:  1,
+			MaxBytes:  10e6,
+			MaxWait:   time.Second,
+		})
+		if err := reader.SetOffsetAt(ctx, start); err != nil {
+			logging.Warnf("Failed to seek %s[%d] to %s, reading new messages only: %v", topic, partition, start.Format(time.RFC3339), err)
+			reader.SetOffset(kafka.LastOffset)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			eb.consume(ctx, reader, fmt.Sprintf("%s[%d]", topic, partition), "", handler)
+		}()
+	}
+	logging.Infof("Subscribed to %s (%d partitions) from %s", topic, len(partitions), start.Format(time.RFC3339))
+	wg.Wait()
+}
+
+// topicPartitions возвращает номера партиций топика по метаданным кластера.
+func (eb *EventBus) topicPartitions(ctx context.Context, topic string) ([]int, error) {
+	ctx, cancel := context.WithTimeout(ctx, topicMetadataTimeout)
+	defer cancel()
+
+	client := &kafka.Client{Addr: kafka.TCP(eb.brokers...)}
+	resp, err := client.Metadata(ctx, &kafka.MetadataRequest{Topics: []string{topic}})
+	if err != nil {
+		return nil, fmt.Errorf("topic metadata: %w", err)
+	}
+	var partitions []int
+	for _, t := range resp.Topics {
+		if t.Name != topic {
+			continue
+		}
+		if t.Error != nil {
+			return nil, t.Error
+		}
+		for _, p := range t.Partitions {
+			partitions = append(partitions, p.ID)
+		}
+	}
+	return partitions, nil
+}
@@ -17,6 +17,7 @@ const (
 	RelLocatedIn = "LOCATED_IN"
 	RelWorldOf   = "WORLD_OF"
 	RelContains  = "CONTAINS"
+	RelPartOf    = "PART_OF" // containment upwards: region → world
 
 	// Social relations
 	RelAlliedWith = "ALLIED_WITH" // undirected
@@ -1,5 +1,15 @@
 package eventbus
 
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"multiverse-core.io/shared/logging"
+
+	"github.com/segmentio/kafka-go"
+)
+
 const (
 	TopicPlayerEvents    = "player_events"
 	TopicWorldEvents     = "world_events"
@@ -9,6 +19,17 @@ const (
 	TopicNarrativeOutput = "narrative_output"
 )
 
+// CoreTopics — основные топики EventBus: создаются при запуске, writer-ы для них готовы сразу.
+// Writer прочих топиков создаётся при первой публикации.
+var CoreTopics = []string{
+	TopicPlayerEvents,
+	TopicWorldEvents,
+	TopicGameEvents,
+	TopicSystemEvents,
+	TopicScopeManagement,
+	TopicNarrativeOutput,
+}
+
 const (
 	TypePlayerAction = "player."
 	TypeViolation    = "violation."
@@ -18,3 +39,76 @@ const (
 	TypeWorld        = "world."
 	TypeMetric       = "metric."
 )
+
+// Значения по умолчанию для создаваемых топиков.
+const (
+	DefaultTopicPartitions        = 3
+	DefaultTopicReplicationFactor = 1
+)
+
+// TopicConfig — параметры топика, создаваемого EnsureTopics.
+// Применяются только при создании: существующие топики не изменяются.
+type TopicConfig struct {
+	Topic             string
+	Partitions        int           // 0 — значение брокера по умолчанию
+	ReplicationFactor int           // 0 — значение брокера по умолчанию
+	Retention         time.Duration // 0 — значение брокера по умолчанию, < 0 — хранить бессрочно
+}
+
+// kafkaConfig переводит параметры в запрос CreateTopics.
+func (c TopicConfig) kafkaConfig() kafka.TopicConfig {
+	config := kafka.TopicConfig{
+		Topic:             c.Topic,
+		NumPartitions:     -1,
+		ReplicationFactor: -1,
+	}
+	if c.Partitions > 0 {
+		config.NumPartitions = c.Partitions
+	}
+	if c.ReplicationFactor > 0 {
+		config.ReplicationFactor = c.ReplicationFactor
+	}
+	switch {
+	case c.Retention > 0:
+		config.ConfigEntries = append(config.ConfigEntries, kafka.ConfigEntry{
+			ConfigName:  "retention.ms",
+			ConfigValue: strconv.FormatInt(c.Retention.Milliseconds(), 10),
+		})
+	case c.Retention < 0:
+		config.ConfigEntries = append(config.ConfigEntries, kafka.ConfigEntry{ConfigName: "retention.ms", ConfigValue: "-1"})
+	}
+	return config
+}
+
+// DefaultTopicConfigs возвращает параметры CoreTopics из окружения:
+// KAFKA_TOPIC_PARTITIONS, KAFKA_TOPIC_REPLICATION_FACTOR и KAFKA_TOPIC_RETENTION_MS.
+func DefaultTopicConfigs() []TopicConfig {
+	partitions := envInt("KAFKA_TOPIC_PARTITIONS", DefaultTopicPartitions)
+	replication := envInt("KAFKA_TOPIC_REPLICATION_FACTOR", DefaultTopicReplicationFactor)
+	retention := time.Duration(envInt("KAFKA_TOPIC_RETENTION_MS", 0)) * time.Millisecond
+
+	configs := make([]TopicConfig, 0, len(CoreTopics))
+	for _, topic := range CoreTopics {
+		configs = append(configs, TopicConfig{
+			Topic:             topic,
+			Partitions:        partitions,
+			ReplicationFactor: replication,
+			Retention:         retention,
+		})
+	}
+	return configs
+}
+
+// envInt читает целое из переменной окружения; пустое или неверное значение — fallback.
+func envInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		logging.Warnf("Invalid %s value: %v, using default %d", key, err, fallback)
+		return fallback
+	}
+	return n
+}
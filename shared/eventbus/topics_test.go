@@ -0,0 +1,42 @@
+package eventbus
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDefaultTopicConfigs(t *testing.T) {
+	t.Setenv("KAFKA_TOPIC_PARTITIONS", "6")
+	t.Setenv("KAFKA_TOPIC_REPLICATION_FACTOR", "bad")
+	t.Setenv("KAFKA_TOPIC_RETENTION_MS", "3600000")
+
+	configs := DefaultTopicConfigs()
+	if len(configs) != len(CoreTopics) {
+		t.Fatalf("expected a config per core topic, got %d", len(configs))
+	}
+	for _, config := range configs {
+		if config.Partitions != 6 || config.ReplicationFactor != DefaultTopicReplicationFactor || config.Retention != time.Hour {
+			t.Errorf("unexpected config %+v", config)
+		}
+	}
+}
+
+func TestTopicConfigKafkaConfig(t *testing.T) {
+	config := TopicConfig{Topic: "world_events", Partitions: 3, ReplicationFactor: 1, Retention: 24 * time.Hour}.kafkaConfig()
+	if config.NumPartitions != 3 || config.ReplicationFactor != 1 {
+		t.Errorf("unexpected partitions %+v", config)
+	}
+	if len(config.ConfigEntries) != 1 || config.ConfigEntries[0].ConfigName != "retention.ms" || config.ConfigEntries[0].ConfigValue != "86400000" {
+		t.Errorf("unexpected config entries %+v", config.ConfigEntries)
+	}
+
+	// Unset values fall back to the broker defaults
+	config = TopicConfig{Topic: "world_events"}.kafkaConfig()
+	if config.NumPartitions != -1 || config.ReplicationFactor != -1 || len(config.ConfigEntries) != 0 {
+		t.Errorf("expected broker defaults, got %+v", config)
+	}
+	config = TopicConfig{Topic: "world_events", Retention: -1}.kafkaConfig()
+	if len(config.ConfigEntries) != 1 || config.ConfigEntries[0].ConfigValue != "-1" {
+		t.Errorf("expected unlimited retention, got %+v", config.ConfigEntries)
+	}
+}
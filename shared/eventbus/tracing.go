@@ -0,0 +1,102 @@
+package eventbus
+
+import (
+	"context"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Трассировка шины: Publish создаёт спан отправки и передаёт его контекст в заголовках
+// сообщения Kafka (W3C traceparent), Subscribe продолжает трассу спаном обработки.
+// Пропагатор и экспорт настраивает shared/tracing.Setup.
+
+const tracerName = "multiverse-core.io/shared/eventbus"
+
+// headerCarrier — заголовки сообщения Kafka как носитель контекста трассировки.
+type headerCarrier struct {
+	headers *[]kafka.Header
+}
+
+func (c headerCarrier) Get(key string) string {
+	for _, header := range *c.headers {
+		if header.Key == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+func (c headerCarrier) Set(key, value string) {
+	for i, header := range *c.headers {
+		if header.Key == key {
+			(*c.headers)[i].Value = []byte(value)
+			return
+		}
+	}
+	*c.headers = append(*c.headers, kafka.Header{Key: key, Value: []byte(value)})
+}
+
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(*c.headers))
+	for _, header := range *c.headers {
+		keys = append(keys, header.Key)
+	}
+	return keys
+}
+
+// TraceContext возвращает ctx, продолжающий трассу события: спан обработки, в котором оно получено
+// из шины, или трассу причины (см. CausedBy). Без трассы ctx возвращается как есть.
+// Обработчики передают его в исходящие вызовы (Oracle, HTTP), чтобы они попали в трассу события.
+func (e Event) TraceContext(ctx context.Context) context.Context {
+	if !e.spanContext.IsValid() {
+		return ctx
+	}
+	return trace.ContextWithSpanContext(ctx, e.spanContext)
+}
+
+// SpanContext возвращает контекст трассы события (недействительный, если трассы нет),
+// например чтобы связать с ним спан пакетной обработки.
+func (e Event) SpanContext() trace.SpanContext {
+	return e.spanContext
+}
+
+// messagingAttributes — атрибуты спанов шины по соглашениям OpenTelemetry для messaging.
+func messagingAttributes(topic string, event Event) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("messaging.system", "kafka"),
+		attribute.String("messaging.destination.name", topic),
+		attribute.String("messaging.message.id", event.ID),
+		attribute.String("event.type", event.Type),
+		attribute.String("event.correlation_id", event.Correlation()),
+	}
+}
+
+// startPublishSpan начинает спан отправки события в topic. Родитель — спан ctx, а если его нет —
+// трасса события (CausedBy), поэтому производные события попадают в трассу причины.
+func startPublishSpan(ctx context.Context, topic string, event Event) (context.Context, trace.Span) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		ctx = event.TraceContext(ctx)
+	}
+	return otel.Tracer(tracerName).Start(ctx, "publish "+topic,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(messagingAttributes(topic, event)...),
+	)
+}
+
+// injectTrace записывает контекст трассировки ctx в заголовки сообщения.
+func injectTrace(ctx context.Context, msg *kafka.Message) {
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier{headers: &msg.Headers})
+}
+
+// startProcessSpan продолжает трассу из заголовков полученного сообщения спаном обработки события.
+func startProcessSpan(ctx context.Context, topic, groupID string, msg kafka.Message, event Event) (context.Context, trace.Span) {
+	ctx = otel.GetTextMapPropagator().Extract(ctx, headerCarrier{headers: &msg.Headers})
+	return otel.Tracer(tracerName).Start(ctx, "process "+topic,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(messagingAttributes(topic, event)...),
+		trace.WithAttributes(attribute.String("messaging.consumer.group.name", groupID)),
+	)
+}
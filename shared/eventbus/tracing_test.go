@@ -0,0 +1,63 @@
+package eventbus
+
+import (
+	"context"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestTracePropagatesThroughHeaders(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	})
+
+	root := NewEvent("player.action", "game-service", "world-1", nil)
+	ctx, publish := startPublishSpan(context.Background(), TopicPlayerEvents, root)
+	msg := kafka.Message{Headers: []kafka.Header{{Key: "traceparent", Value: []byte("stale")}}}
+	injectTrace(ctx, &msg)
+	publish.End()
+	if len(msg.Headers) != 1 || string(msg.Headers[0].Value) == "stale" {
+		t.Fatalf("expected traceparent to be replaced, got %v", msg.Headers)
+	}
+
+	// Потребитель продолжает трассу из заголовков
+	_, process := startProcessSpan(context.Background(), TopicPlayerEvents, "narrative-group", msg, root)
+	received := root
+	received.spanContext = process.SpanContext()
+	process.End()
+	if process.SpanContext().TraceID() != publish.SpanContext().TraceID() {
+		t.Fatalf("expected consumer span in the producer's trace")
+	}
+
+	// Производное событие без спана в ctx публикуется в трассе причины
+	child := NewChildEvent(received, "narrative.generate", "narrative-orchestrator", nil)
+	_, next := startPublishSpan(context.Background(), TopicNarrativeOutput, child)
+	next.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("expected 3 spans, got %d", len(spans))
+	}
+	if spans[1].SpanKind() != trace.SpanKindConsumer || spans[1].Parent().SpanID() != publish.SpanContext().SpanID() {
+		t.Errorf("expected consumer span under the publish span, got %v parent %v", spans[1].SpanKind(), spans[1].Parent())
+	}
+	if spans[2].Parent().SpanID() != process.SpanContext().SpanID() || spans[2].SpanContext().TraceID() != publish.SpanContext().TraceID() {
+		t.Errorf("expected child publish under the processing span, got parent %v", spans[2].Parent())
+	}
+
+	// Событие без трассы не меняет ctx
+	if NewEvent("time.tick", "chronos", "", nil).TraceContext(ctx) != ctx {
+		t.Errorf("expected ctx unchanged for an event without trace")
+	}
+}
@@ -2,16 +2,24 @@ package eventbus
 
 import (
 	"context"
+	"log/slog"
 	"maps"
 	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
 	"multiverse-core.io/shared/jsonpath"
+	"multiverse-core.io/shared/logging"
 )
 
+// EventVersion — текущая версия конверта события.
+// События без версии (0) опубликованы до её введения и не несут ID цепочки.
+const EventVersion = 1
+
 type Event struct {
 	ID        string         `json:"id"`
 	Type      string         `json:"type"`
+	Version   int            `json:"version,omitempty"`
 	Timestamp time.Time      `json:"timestamp"`
 	Source    string         `json:"source"`
 	World     *WorldRef      `json:"world,omitempty"`
@@ -20,6 +28,16 @@ type Event struct {
 	// Relations declares explicit semantic edges for the knowledge graph (optional).
 	// Produced by Oracle/GM/WorldGenerator, consumed by semantic-memory → Neo4j.
 	Relations []Relation `json:"relations,omitempty"`
+
+	// CorrelationID объединяет цепочку событий: у корневого события равен его ID
+	// (проставляется при публикации), производные события наследуют его от причины.
+	CorrelationID string `json:"correlation_id,omitempty"`
+	// CausationID — ID события, непосредственно вызвавшего это; пусто у корневых событий.
+	CausationID string `json:"causation_id,omitempty"`
+
+	// spanContext — трасса, в которой событие получено из шины или которую унаследовало от причины;
+	// передаётся в заголовках Kafka, а не в JSON события (см. TraceContext).
+	spanContext trace.SpanContext
 }
 
 func NewEvent(eventType, source, worldID string, payload map[string]any) Event {
@@ -35,6 +53,7 @@ func NewEvent(eventType, source, worldID string, payload map[string]any) Event {
 	return Event{
 		ID:        uuid.NewString(),
 		Type:      eventType,
+		Version:   EventVersion,
 		Timestamp: time.Now().UTC(),
 		Source:    source,
 		World:     worldRef,
@@ -42,6 +61,30 @@ func NewEvent(eventType, source, worldID string, payload map[string]any) Event {
 	}
 }
 
+// NewChildEvent создаёт событие, вызванное cause, в мире причины.
+func NewChildEvent(cause Event, eventType, source string, payload map[string]any) Event {
+	return NewEvent(eventType, source, GetWorldIDFromEvent(cause), payload).CausedBy(cause)
+}
+
+// CausedBy возвращает копию события, связанную с вызвавшим его событием cause:
+// CausationID — ID причины, CorrelationID — цепочка причины. Событие продолжает и трассу причины,
+// если при публикации в ctx нет своего спана.
+func (e Event) CausedBy(cause Event) Event {
+	e.CausationID = cause.ID
+	e.CorrelationID = cause.Correlation()
+	e.spanContext = cause.spanContext
+	return e
+}
+
+// Correlation возвращает ID цепочки события. Событие без CorrelationID (ещё не опубликованное
+// корневое или старого формата) начинает собственную цепочку.
+func (e Event) Correlation() string {
+	if e.CorrelationID != "" {
+		return e.CorrelationID
+	}
+	return e.ID
+}
+
 // NewEventWithDescription creates an event with a pre-filled description in payload
 func NewEventWithDescription(eventType, source, worldID, description string) Event {
 	payload := map[string]any{
@@ -122,6 +165,16 @@ func GetScopeFromEvent(event Event) *ScopeRef {
 	return nil
 }
 
+// Logger возвращает логгер по умолчанию со стандартными полями события:
+// event_id, world_id, scope_id и event_type.
+func (e Event) Logger() *slog.Logger {
+	scopeID := ""
+	if scope := GetScopeFromEvent(e); scope != nil {
+		scopeID = scope.ID
+	}
+	return logging.With(slog.Default(), GetWorldIDFromEvent(e), scopeID, e.ID).With("event_type", e.Type)
+}
+
 // Path возвращает универсальный jsonpath.Accessor для доступа к данным события по dot-путям.
 // Пример: event.Path().GetString("entity.id")
 func (e *Event) Path() *jsonpath.Accessor {
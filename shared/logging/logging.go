@@ -0,0 +1,132 @@
+// shared/logging/logging.go
+//
+// Структурированные логи сервисов на log/slog. Уровень и формат задаются окружением, каждая запись
+// несёт имя сервиса, записи обработки событий — world_id, scope_id и event_id. После Setup
+// вывод стандартного пакета log (log.Fatal, сторонний код) идёт через тот же обработчик.
+
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Переменные окружения логирования.
+const (
+	EnvLevel  = "LOG_LEVEL"  // debug, info, warn, error (по умолчанию info)
+	EnvFormat = "LOG_FORMAT" // text или json (по умолчанию text)
+)
+
+// Форматы вывода.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Стандартные поля записей.
+const (
+	KeyService   = "service"
+	KeyComponent = "component"
+	KeyWorldID   = "world_id"
+	KeyScopeID   = "scope_id"
+	KeyEventID   = "event_id"
+)
+
+// Setup подключает логирование в main сервиса: логгер с уровнем LOG_LEVEL и форматом LOG_FORMAT
+// становится логгером по умолчанию slog и пакета log. Вызывается после config.Setup, чтобы
+// значения из файла конфигурации уже были в окружении. Неверный уровень заменяется на info.
+func Setup(service string) *slog.Logger {
+	level, err := ParseLevel(os.Getenv(EnvLevel))
+	logger := New(os.Stderr, service, level, os.Getenv(EnvFormat))
+	slog.SetDefault(logger)
+	if err != nil {
+		logger.Warn("Invalid log level, using info", "error", err)
+	}
+	return logger
+}
+
+// New создаёт логгер сервиса, пишущий в w записи не ниже level в формате format (FormatText, если пусто).
+func New(w io.Writer, service string, level slog.Level, format string) *slog.Logger {
+	options := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	if strings.EqualFold(format, FormatJSON) {
+		handler = slog.NewJSONHandler(w, options)
+	} else {
+		handler = slog.NewTextHandler(w, options)
+	}
+	logger := slog.New(handler)
+	if service != "" {
+		logger = logger.With(KeyService, service)
+	}
+	return logger
+}
+
+// ParseLevel разбирает уровень LOG_LEVEL (без учёта регистра; пусто — info).
+func ParseLevel(value string) (slog.Level, error) {
+	if value == "" {
+		return slog.LevelInfo, nil
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(value)); err != nil {
+		return slog.LevelInfo, fmt.Errorf("invalid %s %q: %w", EnvLevel, value, err)
+	}
+	return level, nil
+}
+
+// With добавляет к логгеру стандартные поля мира, scope и события; пустые значения пропускаются.
+func With(logger *slog.Logger, worldID, scopeID, eventID string) *slog.Logger {
+	var attrs []any
+	if worldID != "" {
+		attrs = append(attrs, KeyWorldID, worldID)
+	}
+	if scopeID != "" {
+		attrs = append(attrs, KeyScopeID, scopeID)
+	}
+	if eventID != "" {
+		attrs = append(attrs, KeyEventID, eventID)
+	}
+	if len(attrs) == 0 {
+		return logger
+	}
+	return logger.With(attrs...)
+}
+
+// StdLogger возвращает *log.Logger компонента для кода, принимающего log.Logger:
+// его записи уровня info идут в логгер по умолчанию с полем component.
+func StdLogger(component string) *log.Logger {
+	return slog.NewLogLogger(slog.Default().With(KeyComponent, component).Handler(), slog.LevelInfo)
+}
+
+// Debugf, Infof, Warnf и Errorf заменяют log.Printf: форматированное сообщение
+// пишется в логгер по умолчанию с уровнем записи.
+
+func Debugf(format string, args ...any) {
+	logf(slog.LevelDebug, format, args...)
+}
+
+func Infof(format string, args ...any) {
+	logf(slog.LevelInfo, format, args...)
+}
+
+func Warnf(format string, args ...any) {
+	logf(slog.LevelWarn, format, args...)
+}
+
+func Errorf(format string, args ...any) {
+	logf(slog.LevelError, format, args...)
+}
+
+// logf форматирует сообщение, только если уровень включён.
+func logf(level slog.Level, format string, args ...any) {
+	logger := slog.Default()
+	ctx := context.Background()
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+	logger.Log(ctx, level, fmt.Sprintf(format, args...))
+}
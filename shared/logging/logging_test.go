@@ -0,0 +1,62 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	for value, want := range map[string]slog.Level{"": slog.LevelInfo, "debug": slog.LevelDebug, "WARN": slog.LevelWarn, "error": slog.LevelError} {
+		level, err := ParseLevel(value)
+		if err != nil || level != want {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v", value, level, err, want)
+		}
+	}
+	if level, err := ParseLevel("loud"); err == nil || level != slog.LevelInfo {
+		t.Errorf("expected an error and info for an unknown level, got %v, %v", level, err)
+	}
+}
+
+func TestJSONOutputWithStandardFields(t *testing.T) {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(New(&buf, "narrative-orchestrator", slog.LevelWarn, FormatJSON))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	Infof("below the level %d", 1)
+	With(slog.Default(), "world-1", "", "ev-1").Warn("Oracle returned too many events", "count", 7)
+	Errorf("Failed to publish %s", "ev-2")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 records above info, got %q", buf.String())
+	}
+	var record map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("expected JSON output: %v", err)
+	}
+	if record[KeyService] != "narrative-orchestrator" || record[KeyWorldID] != "world-1" || record[KeyEventID] != "ev-1" || record["count"] != 7.0 {
+		t.Errorf("unexpected record %v", record)
+	}
+	if _, ok := record[KeyScopeID]; ok {
+		t.Errorf("expected empty scope_id to be omitted, got %v", record)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &record); err != nil || record["level"] != "ERROR" || record["msg"] != "Failed to publish ev-2" {
+		t.Errorf("unexpected record %v, %v", record, err)
+	}
+}
+
+func TestStdLoggerWritesToDefault(t *testing.T) {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(New(&buf, "rule-engine", slog.LevelInfo, FormatText))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	StdLogger("RuleEngine").Printf("rule %s applied", "fireball")
+	if out := buf.String(); !strings.Contains(out, "component=RuleEngine") || !strings.Contains(out, `msg="rule fireball applied"`) || !strings.Contains(out, "service=rule-engine") {
+		t.Errorf("unexpected output %q", out)
+	}
+}
@@ -23,19 +23,34 @@ type ObjectInfo struct {
 	Key          string
 	LastModified time.Time
 	Size         int64
+	ETag         string // без кавычек; для объектов, загруженных одним запросом, — MD5 содержимого
 }
 
-// ClientInterface определяет общий интерфейс для всех реализаций MinIO клиента
-type ClientInterface interface {
-	// PutObject загружает объект в MinIO
+// ObjectStorage — хранилище объектов. Реализуют HTTP-клиент, официальный клиент
+// и miniotest.Storage для тестов; сервисы зависят только от этого интерфейса.
+type ObjectStorage interface {
+	// PutObject загружает объект в MinIO потоком; size < 0 — размер заранее неизвестен
 	PutObject(bucket, object string, data io.Reader, size int64) error
-	
-	// GetObject скачивает объект из MinIO
+
+	// GetObject скачивает объект из MinIO.
+	// Отсутствующий объект — ErrNotFound, недоступность хранилища — ErrUnavailable.
 	GetObject(bucket, object string) ([]byte, error)
-	
-	// ListObjects возвращает список объектов с префиксом
+
+	// GetObjectStream открывает объект на чтение потоком; вызывающий обязан закрыть поток.
+	// Отсутствующий объект — ErrNotFound сразу, а не при первом чтении.
+	GetObjectStream(bucket, object string) (io.ReadCloser, error)
+
+	// ListObjects возвращает объекты с префиксом рекурсивно, новые — первыми
 	ListObjects(bucket, prefix string) ([]ObjectInfo, error)
-	
+
+	// RemoveObject удаляет объект; удаление отсутствующего объекта — не ошибка
+	RemoveObject(bucket, object string) error
+
 	// PresignedGetObject генерирует подписанную ссылку для скачивания
 	PresignedGetObject(bucket, object string, expires time.Duration) (string, error)
-}
\ No newline at end of file
+}
+
+var (
+	_ ObjectStorage = (*Client)(nil)
+	_ ObjectStorage = (*MinIOOfficialClient)(nil)
+)
@@ -0,0 +1,74 @@
+// internal/minio/errors.go
+//
+// Типизированные ошибки MinIO: отсутствие объекта и недоступность хранилища
+
+package minio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+
+	minio "github.com/minio/minio-go/v7"
+)
+
+var (
+	// ErrNotFound — объект или бакет не существует. Повторять запрос бессмысленно.
+	ErrNotFound = errors.New("minio: object not found")
+
+	// ErrUnavailable — хранилище временно недоступно (сеть, таймаут, 5xx).
+	// Запрос можно повторить; данные при этом могут существовать.
+	ErrUnavailable = errors.New("minio: storage unavailable")
+)
+
+// IsNotFound сообщает, что ошибка означает отсутствие объекта.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// IsUnavailable сообщает, что ошибка временная и хранилище недоступно.
+func IsUnavailable(err error) bool {
+	return errors.Is(err, ErrUnavailable)
+}
+
+// ClassifyError оборачивает ошибку minio-go или сети в ErrNotFound / ErrUnavailable.
+// Прочие ошибки (доступ запрещён, неверный запрос) возвращаются без изменений.
+// Используется также сервисами, работающими с minio-go напрямую.
+func ClassifyError(err error) error {
+	if err == nil || IsNotFound(err) || IsUnavailable(err) {
+		return err
+	}
+
+	resp := minio.ToErrorResponse(err)
+	switch resp.Code {
+	case "NoSuchKey", "NoSuchBucket", "NotFound":
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	case "SlowDown", "ServiceUnavailable", "InternalError", "RequestTimeout":
+		return fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+	if resp.StatusCode != 0 {
+		return classifyStatus(resp.StatusCode, err)
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) ||
+		errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+	return err
+}
+
+// classifyStatus оборачивает ошибку по HTTP-статусу ответа MinIO.
+func classifyStatus(status int, err error) error {
+	switch {
+	case status == http.StatusNotFound:
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	case status == http.StatusTooManyRequests || status >= 500:
+		return fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+	return err
+}
@@ -0,0 +1,45 @@
+package minio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	minio "github.com/minio/minio-go/v7"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		notFound    bool
+		unavailable bool
+	}{
+		{"nil", nil, false, false},
+		{"no such key", minio.ErrorResponse{Code: "NoSuchKey", StatusCode: http.StatusNotFound}, true, false},
+		{"no such bucket", minio.ErrorResponse{Code: "NoSuchBucket", StatusCode: http.StatusNotFound}, true, false},
+		{"slow down", minio.ErrorResponse{Code: "SlowDown", StatusCode: http.StatusServiceUnavailable}, false, true},
+		{"server error", minio.ErrorResponse{StatusCode: http.StatusBadGateway}, false, true},
+		{"access denied", minio.ErrorResponse{Code: "AccessDenied", StatusCode: http.StatusForbidden}, false, false},
+		{"deadline", fmt.Errorf("get: %w", context.DeadlineExceeded), false, true},
+		{"plain", errors.New("boom"), false, false},
+		{"already classified", fmt.Errorf("%w: x", ErrNotFound), true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ClassifyError(tt.err)
+			if IsNotFound(err) != tt.notFound {
+				t.Errorf("IsNotFound(%v) = %v, want %v", err, !tt.notFound, tt.notFound)
+			}
+			if IsUnavailable(err) != tt.unavailable {
+				t.Errorf("IsUnavailable(%v) = %v, want %v", err, !tt.unavailable, tt.unavailable)
+			}
+			if tt.err != nil && !errors.Is(err, tt.err) && !errors.Is(tt.err, ErrNotFound) {
+				t.Errorf("classified error lost original cause: %v", err)
+			}
+		})
+	}
+}
@@ -19,7 +19,7 @@ const (
 )
 
 // NewClientFromType создает новый клиент MinIO в зависимости от типа
-func NewClientFromType(cfg Config, clientType ClientType) (ClientInterface, error) {
+func NewClientFromType(cfg Config, clientType ClientType) (ObjectStorage, error) {
 	switch clientType {
 	case HTTPClientType:
 		return NewClientHTTP(cfg)
@@ -6,7 +6,6 @@
 package minio
 
 import (
-	"bytes"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
@@ -16,15 +15,25 @@ import (
 	"net/http"
 	"net/url"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
+
+	"multiverse-core.io/shared/tracing"
 )
 
+// unsignedPayload — значение x-amz-content-sha256 для тела, не входящего в подпись:
+// тело передаётся потоком, без предварительного чтения ради SHA-256.
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
 // Client — HTTP-клиент для MinIO.
 type Client struct {
-	config  Config
-	http    *http.Client
-	baseURL *url.URL
+	config Config
+	http   *http.Client
+	// transfer передаёт тела объектов: без общего таймаута, который обрывал бы большие объекты,
+	// но с таймаутом ожидания ответа; зависшая передача тела обрывается по простою (stallGuard)
+	transfer *http.Client
+	baseURL  *url.URL
 }
 
 // newClientHTTP создаёт новый MinIO HTTP-клиент.
@@ -51,7 +60,14 @@ func newClientHTTP(cfg Config) (*Client, error) {
 	return &Client{
 		config: cfg,
 		http: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: tracing.NewTransport("minio", nil),
+		},
+		transfer: &http.Client{
+			Transport: tracing.NewTransport("minio", &http.Transport{
+				Proxy:                 http.ProxyFromEnvironment,
+				ResponseHeaderTimeout: 30 * time.Second,
+			}),
 		},
 		baseURL: baseURL,
 	}, nil
@@ -59,14 +75,14 @@ func newClientHTTP(cfg Config) (*Client, error) {
 
 // ensureBucket создаёт бакет, если он не существует.
 func (c *Client) ensureBucket(bucket string) error {
-	req, err := c.newRequest("HEAD", bucket, "", nil)
+	req, err := c.newRequest("HEAD", bucket, "", nil, "")
 	if err != nil {
 		return err
 	}
 
 	resp, err := c.http.Do(req)
 	if err != nil {
-		return err
+		return ClassifyError(err)
 	}
 	defer resp.Body.Close()
 
@@ -75,78 +91,127 @@ func (c *Client) ensureBucket(bucket string) error {
 	}
 	if resp.StatusCode == 404 {
 		// Создаём бакет
-		createReq, err := c.newRequest("PUT", bucket, "", nil)
+		createReq, err := c.newRequest("PUT", bucket, "", nil, "")
 		if err != nil {
 			return err
 		}
 		createResp, err := c.http.Do(createReq)
 		if err != nil {
-			return err
+			return ClassifyError(err)
 		}
 		defer createResp.Body.Close()
 		if createResp.StatusCode >= 400 {
 			body, _ := io.ReadAll(createResp.Body)
-			return fmt.Errorf("failed to create bucket %s: %d %s", bucket, createResp.StatusCode, string(body))
+			return classifyStatus(createResp.StatusCode, fmt.Errorf("failed to create bucket %s: %d %s", bucket, createResp.StatusCode, string(body)))
 		}
 		return nil
 	}
-	return fmt.Errorf("unexpected status for HEAD bucket: %d", resp.StatusCode)
+	return classifyStatus(resp.StatusCode, fmt.Errorf("unexpected status for HEAD bucket: %d", resp.StatusCode))
 }
 
-// PutObject загружает объект в MinIO.
+// PutObject загружает объект в MinIO потоком: тело не входит в подпись (UNSIGNED-PAYLOAD).
+// Данные неизвестного размера (size < 0) сначала копируются во временный файл.
 func (c *Client) PutObject(bucket, object string, data io.Reader, size int64) error {
 	if err := c.ensureBucket(bucket); err != nil {
 		return fmt.Errorf("ensure bucket: %w", err)
 	}
 
-	// Читаем всё в память (для подписи)
-	body, err := io.ReadAll(data)
-	if err != nil {
-		return err
+	if size < 0 {
+		spooled, n, err := spool(data)
+		if err != nil {
+			return err
+		}
+		defer spooled.Close()
+		data, size = spooled, n
+	}
+	if size == 0 {
+		data = http.NoBody
 	}
 
-	req, err := c.newRequest("PUT", bucket, object, bytes.NewReader(body))
+	req, err := c.newRequest("PUT", bucket, object, data, unsignedPayload)
 	if err != nil {
 		return err
 	}
-	req.ContentLength = int64(len(body))
+	req.ContentLength = size
 
-	resp, err := c.http.Do(req)
+	ctx, guard := newStallGuard(transferStallTimeout)
+	defer guard.stop()
+	req = req.WithContext(ctx)
+	if req.Body != nil && req.Body != http.NoBody {
+		req.Body = struct {
+			io.Reader
+			io.Closer
+		}{progressReader{req.Body, guard}, req.Body}
+	}
+
+	resp, err := c.transfer.Do(req)
 	if err != nil {
-		return err
+		return guard.classify(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("put object failed: %d %s", resp.StatusCode, string(body))
+		return classifyStatus(resp.StatusCode, fmt.Errorf("put object failed: %d %s", resp.StatusCode, string(body)))
 	}
 	return nil
 }
 
 // GetObject скачивает объект из MinIO.
 func (c *Client) GetObject(bucket, object string) ([]byte, error) {
-	req, err := c.newRequest("GET", bucket, object, nil)
+	body, err := c.GetObjectStream(bucket, object)
 	if err != nil {
 		return nil, err
 	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
 
-	resp, err := c.http.Do(req)
+// GetObjectStream открывает объект на чтение потоком.
+func (c *Client) GetObjectStream(bucket, object string) (io.ReadCloser, error) {
+	req, err := c.newRequest("GET", bucket, object, nil, "")
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+
+	ctx, guard := newStallGuard(transferStallTimeout)
+	resp, err := c.transfer.Do(req.WithContext(ctx))
+	if err != nil {
+		guard.stop()
+		return nil, guard.classify(err)
+	}
+	body := guardedReader{resp.Body, guard}
 
 	if resp.StatusCode == 404 {
-		return nil, fmt.Errorf("object not found: %s/%s", bucket, object)
+		body.Close()
+		return nil, fmt.Errorf("%w: %s/%s", ErrNotFound, bucket, object)
 	}
 	if resp.StatusCode >= 400 {
+		defer body.Close()
+		data, _ := io.ReadAll(body)
+		return nil, classifyStatus(resp.StatusCode, fmt.Errorf("get object failed: %d %s", resp.StatusCode, string(data)))
+	}
+	return body, nil
+}
 
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("get object failed: %d %s", resp.StatusCode, string(body))
+// RemoveObject удаляет объект; S3 отвечает 204 и для отсутствующего объекта.
+func (c *Client) RemoveObject(bucket, object string) error {
+	req, err := c.newRequest("DELETE", bucket, object, nil, "")
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return ClassifyError(err)
 	}
+	defer resp.Body.Close()
 
-	return io.ReadAll(resp.Body)
+	if resp.StatusCode >= 400 && resp.StatusCode != 404 {
+		body, _ := io.ReadAll(resp.Body)
+		return classifyStatus(resp.StatusCode, fmt.Errorf("remove object failed: %d %s", resp.StatusCode, string(body)))
+	}
+	return nil
 }
 
 // ListObjects возвращает список объектов с префиксом.
@@ -203,13 +268,13 @@ func (c *Client) ListObjects(bucket, prefix string) ([]ObjectInfo, error) {
 
 	resp, err := c.http.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, ClassifyError(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("list objects failed: %d %s", resp.StatusCode, string(body))
+		return nil, classifyStatus(resp.StatusCode, fmt.Errorf("list objects failed: %d %s", resp.StatusCode, string(body)))
 	}
 
 	var result ListBucketResult
@@ -223,6 +288,7 @@ func (c *Client) ListObjects(bucket, prefix string) ([]ObjectInfo, error) {
 			Key:          c.Key,
 			LastModified: c.LastModified,
 			Size:         c.Size,
+			ETag:         strings.Trim(c.ETag, `"`),
 		})
 	}
 	// Сортируем по времени (новые — первями)
@@ -232,15 +298,52 @@ func (c *Client) ListObjects(bucket, prefix string) ([]ObjectInfo, error) {
 	return objects, nil
 }
 
-// PresignedGetObject генерирует подписанную ссылку для скачивания.
+// PresignedGetObject генерирует подписанную ссылку для скачивания (AWS Signature V4 в строке запроса).
+// Срок действия — от секунды до 7 суток, как у официального клиента; без ключей доступа
+// ссылка не подписывается.
 func (c *Client) PresignedGetObject(bucket, object string, expires time.Duration) (string, error) {
-	return fmt.Sprintf("%s/%s/%s?debug=1", c.baseURL.String(), bucket, object), nil
+	if expires < time.Second || expires > maxPresignExpiry {
+		return "", fmt.Errorf("presigned url expiry must be between 1s and %s, got %s", maxPresignExpiry, expires)
+	}
+	u := *c.baseURL
+	u.Path = "/" + bucket + "/" + object
+	return c.presignURL("GET", &u, expires, time.Now().UTC()), nil
+}
+
+// maxPresignExpiry — наибольший срок действия подписанной ссылки, допускаемый S3.
+const maxPresignExpiry = 7 * 24 * time.Hour
+
+// presignURL подписывает ссылку параметрами X-Amz-*: подписывается только заголовок host,
+// тело не входит в подпись (UNSIGNED-PAYLOAD).
+func (c *Client) presignURL(method string, u *url.URL, expires time.Duration, now time.Time) string {
+	path := c.uriEncode(u.Path, false)
+	if path == "" {
+		path = "/"
+	}
+	link := u.Scheme + "://" + u.Host + path
+	if c.config.AccessKeyID == "" || c.config.SecretAccessKey == "" {
+		return link
+	}
+
+	date := now.Format("20060102T150405Z")
+	params := url.Values{}
+	params.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	params.Set("X-Amz-Credential", fmt.Sprintf("%s/%s/%s/s3/aws4_request", c.config.AccessKeyID, date[:8], c.config.Region))
+	params.Set("X-Amz-Date", date)
+	params.Set("X-Amz-Expires", strconv.FormatInt(int64(expires/time.Second), 10))
+	params.Set("X-Amz-SignedHeaders", "host")
+	query := c.canonicalQueryString(params)
+
+	canonicalRequest := fmt.Sprintf("%s\n%s\n%s\nhost:%s\n\nhost\n%s", method, path, query, u.Host, unsignedPayload)
+	signature := c.calculateSignature(c.buildStringToSign(canonicalRequest, date), date[:8])
+	return link + "?" + query + "&X-Amz-Signature=" + signature
 }
 
 // --- Внутренние вспомогательные методы ---
 
-// newRequest создаёт подписанной HTTP-запрос к MinIO.
-func (c *Client) newRequest(method, bucket, object string, body io.Reader) (*http.Request, error) {
+// newRequest создаёт подписанный HTTP-запрос к MinIO. Тело не читается: payloadHash — его SHA-256
+// или unsignedPayload; пустой payloadHash — запрос без тела.
+func (c *Client) newRequest(method, bucket, object string, body io.Reader, payloadHash string) (*http.Request, error) {
 	u := *c.baseURL
 	if bucket != "" {
 		u.Path = "/" + bucket
@@ -249,24 +352,13 @@ func (c *Client) newRequest(method, bucket, object string, body io.Reader) (*htt
 		}
 	}
 
-	// Подготовим тело запроса для подписи и последующего использования
-	var bodyBytes []byte
-	var hashedPayload string
-
-	if body != nil {
-		var err error
-		bodyBytes, err = io.ReadAll(body)
-		if err != nil {
-			return nil, err
-		}
-		h := sha256.Sum256(bodyBytes)
-		hashedPayload = hex.EncodeToString(h[:])
-	} else {
+	hashedPayload := payloadHash
+	if hashedPayload == "" {
 		h := sha256.Sum256([]byte{})
 		hashedPayload = hex.EncodeToString(h[:])
 	}
 
-	req, err := http.NewRequest(method, u.String(), bytes.NewReader(bodyBytes))
+	req, err := http.NewRequest(method, u.String(), body)
 	if err != nil {
 		return nil, err
 	}
@@ -371,9 +463,9 @@ func (c *Client) canonicalQueryString(queryValues url.Values) string {
 		values := queryValues[k]
 		sort.Strings(values)
 		for _, v := range values {
-			// Кодируем ключ и значение согласно спецификации
-			encodedK := c.uriEncode(k, false)
-			encodedV := c.uriEncode(v, false)
+			// Кодируем ключ и значение согласно спецификации: в параметрах кодируется и «/»
+			encodedK := c.uriEncode(k, true)
+			encodedV := c.uriEncode(v, true)
 			pairs = append(pairs, fmt.Sprintf("%s=%s", encodedK, encodedV))
 		}
 	}
@@ -434,4 +526,5 @@ type Content struct {
 	Key          string    `xml:"Key"`
 	LastModified time.Time `xml:"LastModified"`
 	Size         int64     `xml:"Size"`
+	ETag         string    `xml:"ETag"`
 }
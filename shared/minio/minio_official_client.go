@@ -9,10 +9,12 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strings"
 	"time"
 
 	minio "github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
+	"multiverse-core.io/shared/tracing"
 )
 
 // MinIOOfficialClient — клиент MinIO на основе официальной библиотеки github.com/minio/minio-go/v7
@@ -23,11 +25,17 @@ type MinIOOfficialClient struct {
 
 // NewMinIOOfficialClient создаёт новый MinIO клиент с использованием официальной библиотеки
 func NewMinIOOfficialClient(cfg Config) (*MinIOOfficialClient, error) {
+	transport, err := minio.DefaultTransport(cfg.UseSSL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create minio transport: %w", err)
+	}
+
 	// Создаем клиент MinIO с использованием официальной библиотеки
 	client, err := minio.New(cfg.Endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(cfg.AccessKeyID, cfg.SecretAccessKey, ""),
-		Secure: cfg.UseSSL,
-		Region: cfg.Region,
+		Creds:     credentials.NewStaticV4(cfg.AccessKeyID, cfg.SecretAccessKey, ""),
+		Secure:    cfg.UseSSL,
+		Region:    cfg.Region,
+		Transport: tracing.NewTransport("minio", transport),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create minio client: %w", err)
@@ -44,7 +52,7 @@ func (c *MinIOOfficialClient) ensureBucket(bucket string) error {
 	// Проверяем существование бакета
 	exists, err := c.client.BucketExists(context.Background(), bucket)
 	if err != nil {
-		return fmt.Errorf("failed to check bucket existence: %w", err)
+		return fmt.Errorf("failed to check bucket existence: %w", ClassifyError(err))
 	}
 
 	if !exists {
@@ -53,7 +61,7 @@ func (c *MinIOOfficialClient) ensureBucket(bucket string) error {
 			Region: c.config.Region,
 		})
 		if err != nil {
-			return fmt.Errorf("failed to create bucket %s: %w", bucket, err)
+			return fmt.Errorf("failed to create bucket %s: %w", bucket, ClassifyError(err))
 		}
 	}
 
@@ -70,7 +78,7 @@ func (c *MinIOOfficialClient) PutObject(bucket, object string, data io.Reader, s
 		ContentType: "application/octet-stream",
 	})
 	if err != nil {
-		return fmt.Errorf("put object failed: %w", err)
+		return fmt.Errorf("put object failed: %w", ClassifyError(err))
 	}
 	return nil
 }
@@ -79,18 +87,36 @@ func (c *MinIOOfficialClient) PutObject(bucket, object string, data io.Reader, s
 func (c *MinIOOfficialClient) GetObject(bucket, object string) ([]byte, error) {
 	reader, err := c.client.GetObject(context.Background(), bucket, object, minio.GetObjectOptions{})
 	if err != nil {
-		return nil, fmt.Errorf("get object failed: %w", err)
+		return nil, fmt.Errorf("get object failed: %w", ClassifyError(err))
 	}
 	defer reader.Close()
 
+	// minio-go открывает объект лениво: NoSuchKey приходит только при чтении
 	data, err := io.ReadAll(reader)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read object data: %w", err)
+		return nil, fmt.Errorf("failed to read object data: %w", ClassifyError(err))
 	}
 
 	return data, nil
 }
 
+// GetObjectStream открывает объект на чтение потоком.
+func (c *MinIOOfficialClient) GetObjectStream(bucket, object string) (io.ReadCloser, error) {
+	ctx, guard := newStallGuard(transferStallTimeout)
+	reader, err := c.client.GetObject(ctx, bucket, object, minio.GetObjectOptions{})
+	if err != nil {
+		guard.stop()
+		return nil, fmt.Errorf("get object failed: %w", guard.classify(err))
+	}
+	// Stat выполняет запрос сразу, чтобы отсутствие объекта не откладывалось до первого чтения
+	if _, err := reader.Stat(); err != nil {
+		reader.Close()
+		guard.stop()
+		return nil, fmt.Errorf("get object failed: %w", guard.classify(err))
+	}
+	return guardedReader{reader, guard}, nil
+}
+
 // ListObjects возвращает список объектов с префиксом.
 func (c *MinIOOfficialClient) ListObjects(bucket, prefix string) ([]ObjectInfo, error) {
 	if err := c.ensureBucket(bucket); err != nil {
@@ -110,12 +136,13 @@ func (c *MinIOOfficialClient) ListObjects(bucket, prefix string) ([]ObjectInfo,
 	var objects []ObjectInfo
 	for object := range objectCh {
 		if object.Err != nil {
-			return nil, fmt.Errorf("list objects failed: %w", object.Err)
+			return nil, fmt.Errorf("list objects failed: %w", ClassifyError(object.Err))
 		}
 		objects = append(objects, ObjectInfo{
 			Key:          object.Key,
 			LastModified: object.LastModified,
 			Size:         object.Size,
+			ETag:         strings.Trim(object.ETag, `"`),
 		})
 	}
 
@@ -128,11 +155,21 @@ func (c *MinIOOfficialClient) ListObjects(bucket, prefix string) ([]ObjectInfo,
 	return objects, nil
 }
 
+// RemoveObject удаляет объект; удаление отсутствующего объекта — не ошибка.
+func (c *MinIOOfficialClient) RemoveObject(bucket, object string) error {
+	if err := c.client.RemoveObject(context.Background(), bucket, object, minio.RemoveObjectOptions{}); err != nil {
+		if err = ClassifyError(err); !IsNotFound(err) {
+			return fmt.Errorf("remove object failed: %w", err)
+		}
+	}
+	return nil
+}
+
 // PresignedGetObject генерирует подписанную ссылку для скачивания.
 func (c *MinIOOfficialClient) PresignedGetObject(bucket, object string, expires time.Duration) (string, error) {
 	url, err := c.client.PresignedGetObject(context.Background(), bucket, object, expires, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to generate presigned URL: %w", err)
+		return "", fmt.Errorf("failed to generate presigned URL: %w", ClassifyError(err))
 	}
 	return url.String(), nil
 }
\ No newline at end of file
@@ -0,0 +1,176 @@
+// internal/minio/miniotest/storage.go
+//
+// Хранилище объектов в памяти для модульных тестов сервисов
+
+// Package miniotest предоставляет реализацию minio.ObjectStorage в памяти.
+package miniotest
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/minio"
+)
+
+var _ minio.ObjectStorage = (*Storage)(nil)
+
+type object struct {
+	data     []byte
+	modified time.Time
+}
+
+// Storage — minio.ObjectStorage в памяти. Безопасен для конкурентного использования.
+type Storage struct {
+	// Now задаёт время изменения объектов (по умолчанию time.Now)
+	Now func() time.Time
+	// BeforeGet вызывается перед каждым чтением объекта, вне блокировки:
+	// позволяет задержать чтение или подменить объект
+	BeforeGet func(bucket, object string)
+
+	mu      sync.Mutex
+	objects map[string]object
+	gets    map[string]int
+	puts    map[string][]string
+	putErr  error
+}
+
+// New создаёт пустое хранилище.
+func New() *Storage {
+	return &Storage{
+		objects: make(map[string]object),
+		gets:    make(map[string]int),
+		puts:    make(map[string][]string),
+	}
+}
+
+// PutObject сохраняет объект; при FailPuts возвращает заданную ошибку.
+func (s *Storage) PutObject(bucket, object string, data io.Reader, size int64) error {
+	s.mu.Lock()
+	putErr := s.putErr
+	s.mu.Unlock()
+	if putErr != nil {
+		return putErr
+	}
+	content, err := io.ReadAll(data)
+	if err != nil {
+		return err
+	}
+	s.store(bucket, object, content)
+	return nil
+}
+
+// GetObject возвращает копию объекта или ErrNotFound.
+func (s *Storage) GetObject(bucket, object string) ([]byte, error) {
+	if s.BeforeGet != nil {
+		s.BeforeGet(bucket, object)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gets[bucket+"/"+object]++
+	stored, ok := s.objects[bucket+"/"+object]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s/%s", minio.ErrNotFound, bucket, object)
+	}
+	return bytes.Clone(stored.data), nil
+}
+
+// GetObjectStream возвращает объект потоком.
+func (s *Storage) GetObjectStream(bucket, object string) (io.ReadCloser, error) {
+	data, err := s.GetObject(bucket, object)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// ListObjects возвращает объекты с префиксом рекурсивно, новые — первыми.
+func (s *Storage) ListObjects(bucket, prefix string) ([]minio.ObjectInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var objects []minio.ObjectInfo
+	for key, stored := range s.objects {
+		name, ok := strings.CutPrefix(key, bucket+"/")
+		if !ok || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		sum := md5.Sum(stored.data)
+		objects = append(objects, minio.ObjectInfo{
+			Key:          name,
+			LastModified: stored.modified,
+			Size:         int64(len(stored.data)),
+			ETag:         hex.EncodeToString(sum[:]),
+		})
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		if !objects[i].LastModified.Equal(objects[j].LastModified) {
+			return objects[i].LastModified.After(objects[j].LastModified)
+		}
+		return objects[i].Key < objects[j].Key
+	})
+	return objects, nil
+}
+
+// RemoveObject удаляет объект; отсутствующий объект — не ошибка.
+func (s *Storage) RemoveObject(bucket, object string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, bucket+"/"+object)
+	return nil
+}
+
+// PresignedGetObject возвращает ссылку вида memory://bucket/object.
+func (s *Storage) PresignedGetObject(bucket, object string, expires time.Duration) (string, error) {
+	return "memory://" + bucket + "/" + object, nil
+}
+
+// Put сохраняет объект в обход FailPuts.
+func (s *Storage) Put(bucket, object, data string) {
+	s.store(bucket, object, []byte(data))
+}
+
+// Object возвращает содержимое объекта без учёта в Gets.
+func (s *Storage) Object(bucket, object string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok := s.objects[bucket+"/"+object]
+	return bytes.Clone(stored.data), ok
+}
+
+// Gets возвращает число чтений объекта через GetObject/GetObjectStream.
+func (s *Storage) Gets(bucket, object string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.gets[bucket+"/"+object]
+}
+
+// Puts возвращает имена объектов, записанных в бакет, в порядке записи.
+func (s *Storage) Puts(bucket string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.puts[bucket]...)
+}
+
+// FailPuts заставляет PutObject возвращать err; nil восстанавливает запись.
+func (s *Storage) FailPuts(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.putErr = err
+}
+
+func (s *Storage) store(bucket, name string, data []byte) {
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[bucket+"/"+name] = object{data: data, modified: now()}
+	s.puts[bucket] = append(s.puts[bucket], name)
+}
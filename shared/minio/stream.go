@@ -0,0 +1,127 @@
+// internal/minio/stream.go
+//
+// Потоковое чтение и запись объектов без буферизации целиком в памяти
+// (карты миров, снапшоты ГМ и другие большие объекты)
+
+package minio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// transferStallTimeout — сколько передача тела объекта может стоять без единого байта.
+// Общего таймаута у передачи нет (большие объекты), поэтому зависшее соединение обрывается по простою.
+var transferStallTimeout = 30 * time.Second
+
+// stallGuard отменяет контекст передачи, если данные не идут дольше timeout.
+type stallGuard struct {
+	timer   *time.Timer
+	timeout time.Duration
+	cancel  context.CancelFunc
+	stalled atomic.Bool
+}
+
+// newStallGuard возвращает контекст передачи и сторож простоя; stop обязателен.
+func newStallGuard(timeout time.Duration) (context.Context, *stallGuard) {
+	ctx, cancel := context.WithCancel(context.Background())
+	g := &stallGuard{timeout: timeout, cancel: cancel}
+	g.timer = time.AfterFunc(timeout, func() {
+		g.stalled.Store(true)
+		cancel()
+	})
+	return ctx, g
+}
+
+// progress откладывает обрыв: данные идут.
+func (g *stallGuard) progress() {
+	g.timer.Reset(g.timeout)
+}
+
+// stop завершает передачу и освобождает контекст.
+func (g *stallGuard) stop() {
+	g.timer.Stop()
+	g.cancel()
+}
+
+// classify классифицирует ошибку передачи; обрыв по простою — ErrUnavailable.
+func (g *stallGuard) classify(err error) error {
+	if g.stalled.Load() {
+		return fmt.Errorf("%w: no data transferred for %s: %w", ErrUnavailable, g.timeout, err)
+	}
+	return ClassifyError(err)
+}
+
+// progressReader сообщает сторожу о каждом прочитанном фрагменте (тело PUT-запроса).
+type progressReader struct {
+	io.Reader
+	guard *stallGuard
+}
+
+func (r progressReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if n > 0 {
+		r.guard.progress()
+	}
+	return n, err
+}
+
+// guardedReader — тело объекта под сторожем простоя: ошибки чтения классифицируются
+// (обрыв соединения и простой — ErrUnavailable), Close останавливает сторожа.
+type guardedReader struct {
+	io.ReadCloser
+	guard *stallGuard
+}
+
+func (r guardedReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		r.guard.progress()
+	}
+	if err != nil && err != io.EOF {
+		err = r.guard.classify(err)
+	}
+	return n, err
+}
+
+func (r guardedReader) Close() error {
+	err := r.ReadCloser.Close()
+	r.guard.stop()
+	return err
+}
+
+// spoolFile — временный файл с данными неизвестного размера; удаляется при закрытии.
+type spoolFile struct {
+	*os.File
+}
+
+func (f spoolFile) Close() error {
+	err := f.File.Close()
+	if removeErr := os.Remove(f.Name()); err == nil {
+		err = removeErr
+	}
+	return err
+}
+
+// spool копирует поток неизвестной длины во временный файл: S3 PUT требует Content-Length,
+// а держать весь объект в памяти ради этого нельзя.
+func spool(data io.Reader) (spoolFile, int64, error) {
+	file, err := os.CreateTemp("", "minio-upload-*")
+	if err != nil {
+		return spoolFile{}, 0, fmt.Errorf("spool upload: %w", err)
+	}
+	spooled := spoolFile{file}
+	size, err := io.Copy(file, data)
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		return spoolFile{}, 0, errors.Join(fmt.Errorf("spool upload: %w", err), spooled.Close())
+	}
+	return spooled, size, nil
+}
@@ -0,0 +1,150 @@
+package minio
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// s3Stub — минимальный S3: бакеты существуют, объекты хранятся в памяти
+type s3Stub struct {
+	mu       sync.Mutex
+	objects  map[string][]byte
+	hashes   []string
+	lengths  []int64
+	encoding []string
+}
+
+func (s *s3Stub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if strings.Count(strings.Trim(r.URL.Path, "/"), "/") == 0 {
+		return // HEAD/PUT бакета
+	}
+	switch r.Method {
+	case http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		s.objects[r.URL.Path] = data
+		s.hashes = append(s.hashes, r.Header.Get("x-amz-content-sha256"))
+		s.lengths = append(s.lengths, r.ContentLength)
+		s.encoding = append(s.encoding, strings.Join(r.TransferEncoding, ","))
+	case http.MethodGet:
+		data, ok := s.objects[r.URL.Path]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	}
+}
+
+func newStubClient(t *testing.T) (*Client, *s3Stub) {
+	stub := &s3Stub{objects: make(map[string][]byte)}
+	server := httptest.NewServer(stub)
+	t.Cleanup(server.Close)
+	client, err := NewClientHTTP(Config{Endpoint: server.URL, AccessKeyID: "key", SecretAccessKey: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, stub
+}
+
+func TestHTTPClientStreamsObjects(t *testing.T) {
+	client, stub := newStubClient(t)
+	payload := bytes.Repeat([]byte("tile"), 64*1024)
+
+	// The body is streamed unsigned with the declared length
+	if err := client.PutObject("maps", "w1/map.bin", bytes.NewReader(payload), int64(len(payload))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stub.hashes[0] != unsignedPayload || stub.lengths[0] != int64(len(payload)) || stub.encoding[0] != "" {
+		t.Errorf("unexpected upload: hash=%s length=%d encoding=%q", stub.hashes[0], stub.lengths[0], stub.encoding[0])
+	}
+
+	// Data of unknown size is spooled so the request still has a Content-Length
+	if err := client.PutObject("maps", "w1/unknown.bin", io.MultiReader(bytes.NewReader(payload)), -1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stub.lengths[1] != int64(len(payload)) {
+		t.Errorf("unknown-size upload sent length %d", stub.lengths[1])
+	}
+	if spooled, _ := filepath.Glob(filepath.Join(os.TempDir(), "minio-upload-*")); len(spooled) != 0 {
+		t.Errorf("spool files left behind: %v", spooled)
+	}
+
+	stream, err := client.GetObjectStream("maps", "w1/unknown.bin")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := io.ReadAll(stream)
+	stream.Close()
+	if err != nil || !bytes.Equal(data, payload) {
+		t.Errorf("streamed object differs (%d bytes, %v)", len(data), err)
+	}
+
+	if _, err := client.GetObjectStream("maps", "missing"); !IsNotFound(err) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+// zeroReader отдаёт нули без конца
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+func TestHTTPClientAbortsStalledTransfers(t *testing.T) {
+	defer func(timeout time.Duration) { transferStallTimeout = timeout }(transferStallTimeout)
+	transferStallTimeout = 100 * time.Millisecond
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Count(strings.Trim(r.URL.Path, "/"), "/") == 0 {
+			return // HEAD/PUT бакета
+		}
+		if r.Method == http.MethodGet {
+			// Заголовки и начало тела приходят, затем соединение зависает
+			w.Write([]byte("partial"))
+			w.(http.Flusher).Flush()
+		}
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+	client, err := NewClientHTTP(Config{Endpoint: server.URL, AccessKeyID: "key", SecretAccessKey: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	stream, err := client.GetObjectStream("maps", "w1/map.bin")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = io.ReadAll(stream)
+	stream.Close()
+	if !IsUnavailable(err) {
+		t.Errorf("expected a stalled download to fail with ErrUnavailable, got %v", err)
+	}
+
+	// Сервер перестал читать тело загрузки
+	const size = 256 << 20
+	if err := client.PutObject("maps", "w1/map.bin", io.LimitReader(zeroReader{}, size), size); !IsUnavailable(err) {
+		t.Errorf("expected a stalled upload to fail with ErrUnavailable, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("stalled transfers took %s to abort", elapsed)
+	}
+}
@@ -0,0 +1,271 @@
+// internal/oracle/audit.go
+
+package oracle
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	"multiverse-core.io/shared/logging"
+	"multiverse-core.io/shared/minio"
+)
+
+// DefaultAuditBucket — бакет журнала аудита Oracle по умолчанию.
+const DefaultAuditBucket = "gnue-oracle-audit"
+
+// auditDayLayout — раздел ключа записи: {yyyy}/{mm}/{dd}/{service}/{время}-{id}.json.gz
+const auditDayLayout = "2006/01/02"
+
+// AuditRecord — пара запрос/ответ Oracle в журнале аудита.
+type AuditRecord struct {
+	ID               string          `json:"id"`
+	Time             time.Time       `json:"time"`
+	Service          string          `json:"service"`
+	ScopeID          string          `json:"scope_id,omitempty"`
+	Endpoint         string          `json:"endpoint,omitempty"`
+	Model            string          `json:"model"`
+	Request          json.RawMessage `json:"request"`
+	Response         string          `json:"response,omitempty"`
+	Error            string          `json:"error,omitempty"`
+	LatencyMs        int64           `json:"latency_ms"`
+	PromptTokens     int             `json:"prompt_tokens,omitempty"`
+	CompletionTokens int             `json:"completion_tokens,omitempty"`
+	TotalTokens      int             `json:"total_tokens,omitempty"`
+}
+
+// AuditConfig задаёт журнал аудита.
+type AuditConfig struct {
+	Bucket    string        // пусто — DefaultAuditBucket
+	Service   string        // сервис-источник вызовов
+	Retention time.Duration // срок хранения записей; <= 0 — 14 дней
+}
+
+// Auditor сохраняет пары запрос/ответ Oracle сжатым JSON в MinIO. Запись выполняется в фоне
+// и не задерживает вызов; ошибка записи только логируется.
+type Auditor struct {
+	storage minio.ObjectStorage
+	cfg     AuditConfig
+	now     func() time.Time
+	wg      sync.WaitGroup
+}
+
+// NewAuditor создаёт журнал аудита в storage.
+func NewAuditor(storage minio.ObjectStorage, cfg AuditConfig) *Auditor {
+	if cfg.Bucket == "" {
+		cfg.Bucket = DefaultAuditBucket
+	}
+	if cfg.Retention <= 0 {
+		cfg.Retention = 14 * 24 * time.Hour
+	}
+	return &Auditor{storage: storage, cfg: cfg, now: time.Now}
+}
+
+// audit — журнал аудита процесса. Клиенты создаются на каждый вызов (NewClient),
+// поэтому журнал, как и выключатели адресов, общий для процесса.
+var audit atomic.Pointer[Auditor]
+
+// EnableAudit включает журнал аудита для всех клиентов процесса; nil — выключает.
+func EnableAudit(a *Auditor) {
+	audit.Store(a)
+}
+
+type auditScopeKey struct{}
+
+// WithAuditScope помечает вызовы Oracle с контекстом ctx скоупом вызывающего ГМ.
+func WithAuditScope(ctx context.Context, scopeID string) context.Context {
+	return context.WithValue(ctx, auditScopeKey{}, scopeID)
+}
+
+func auditScope(ctx context.Context) string {
+	scopeID, _ := ctx.Value(auditScopeKey{}).(string)
+	return scopeID
+}
+
+// Record сохраняет запись в фоне, заполняя ID, Time и Service.
+func (a *Auditor) Record(rec AuditRecord) {
+	if rec.ID == "" {
+		rec.ID = uuid.New().String()
+	}
+	if rec.Time.IsZero() {
+		rec.Time = a.now()
+	}
+	if rec.Service == "" {
+		rec.Service = a.cfg.Service
+	}
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		if err := a.store(rec); err != nil {
+			logging.Warnf("Failed to store Oracle audit record %s: %v", rec.ID, err)
+		}
+	}()
+}
+
+func (a *Auditor) store(rec AuditRecord) error {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(rec); err != nil {
+		return fmt.Errorf("encode audit record: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("compress audit record: %w", err)
+	}
+	return a.storage.PutObject(a.cfg.Bucket, auditKey(rec), &buf, int64(buf.Len()))
+}
+
+// Close дожидается записи сохраняемых записей.
+func (a *Auditor) Close() {
+	a.wg.Wait()
+}
+
+func auditKey(rec AuditRecord) string {
+	t := rec.Time.UTC()
+	service := rec.Service
+	if service == "" {
+		service = "unknown"
+	}
+	return fmt.Sprintf("%s/%s/%s-%s.json.gz", t.Format(auditDayLayout), service, t.Format("150405.000000000"), rec.ID)
+}
+
+// auditDay возвращает дневной раздел ключа записи.
+func auditDay(key string) (time.Time, bool) {
+	if len(key) < len(auditDayLayout) {
+		return time.Time{}, false
+	}
+	day, err := time.Parse(auditDayLayout, key[:len(auditDayLayout)])
+	return day, err == nil
+}
+
+// Prune удаляет записи дневных разделов, целиком вышедших за срок хранения.
+func (a *Auditor) Prune() (int, error) {
+	objects, err := a.storage.ListObjects(a.cfg.Bucket, "")
+	if err != nil {
+		if minio.IsNotFound(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("list audit records: %w", err)
+	}
+	cutoff := a.now().UTC().Add(-a.cfg.Retention)
+	removed := 0
+	for _, obj := range objects {
+		day, ok := auditDay(obj.Key)
+		if !ok || !day.Add(24*time.Hour).Before(cutoff) {
+			continue
+		}
+		if err := a.storage.RemoveObject(a.cfg.Bucket, obj.Key); err != nil {
+			return removed, fmt.Errorf("remove audit record %s: %w", obj.Key, err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// RunRetention удаляет устаревшие записи каждые interval до отмены ctx.
+func (a *Auditor) RunRetention(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			removed, err := a.Prune()
+			if err != nil {
+				logging.Warnf("Oracle audit retention failed: %v", err)
+			} else if removed > 0 {
+				logging.Infof("Oracle audit retention removed %d records", removed)
+			}
+		}
+	}
+}
+
+// AuditQuery — фильтр записей журнала.
+type AuditQuery struct {
+	From    time.Time // нулевое — за сутки до To
+	To      time.Time // нулевое — сейчас
+	Service string    // пусто — все сервисы
+	ScopeID string    // пусто — все скоупы
+	Limit   int       // <= 0 — 100
+}
+
+// Query возвращает записи журнала, подходящие под фильтр, новые — первыми.
+func (a *Auditor) Query(q AuditQuery) ([]AuditRecord, error) {
+	if q.To.IsZero() {
+		q.To = a.now()
+	}
+	if q.From.IsZero() {
+		q.From = q.To.Add(-24 * time.Hour)
+	}
+	if q.Limit <= 0 {
+		q.Limit = 100
+	}
+
+	var records []AuditRecord
+	first := q.From.UTC().Truncate(24 * time.Hour)
+	for day := q.To.UTC().Truncate(24 * time.Hour); !day.Before(first); day = day.Add(-24 * time.Hour) {
+		prefix := day.Format(auditDayLayout) + "/"
+		if q.Service != "" {
+			prefix += q.Service + "/"
+		}
+		objects, err := a.storage.ListObjects(a.cfg.Bucket, prefix)
+		if err != nil {
+			if minio.IsNotFound(err) {
+				return records, nil
+			}
+			return nil, fmt.Errorf("list audit records: %w", err)
+		}
+
+		var daily []AuditRecord
+		for _, obj := range objects {
+			rec, err := a.read(obj.Key)
+			if err != nil {
+				logging.Warnf("Skipping unreadable Oracle audit record %s: %v", obj.Key, err)
+				continue
+			}
+			if rec.Time.Before(q.From) || rec.Time.After(q.To) || (q.ScopeID != "" && rec.ScopeID != q.ScopeID) {
+				continue
+			}
+			daily = append(daily, rec)
+		}
+		sort.Slice(daily, func(i, j int) bool { return daily[i].Time.After(daily[j].Time) })
+		for _, rec := range daily {
+			records = append(records, rec)
+			if len(records) == q.Limit {
+				return records, nil
+			}
+		}
+	}
+	return records, nil
+}
+
+func (a *Auditor) read(key string) (AuditRecord, error) {
+	var rec AuditRecord
+	if !strings.HasSuffix(key, ".json.gz") {
+		return rec, fmt.Errorf("not an audit record")
+	}
+	data, err := a.storage.GetObject(a.cfg.Bucket, key)
+	if err != nil {
+		return rec, err
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return rec, err
+	}
+	defer zr.Close()
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		return rec, err
+	}
+	return rec, json.Unmarshal(raw, &rec)
+}
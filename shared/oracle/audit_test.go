@@ -0,0 +1,74 @@
+package oracle
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"multiverse-core.io/shared/minio/miniotest"
+)
+
+func TestAuditRecordsCalls(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices": [{"message": {"content": "Дождь стих"}}], "usage": {"prompt_tokens": 12, "completion_tokens": 3, "total_tokens": 15}}`))
+	}))
+	defer server.Close()
+
+	storage := miniotest.New()
+	auditor := NewAuditor(storage, AuditConfig{Service: "narrative-orchestrator"})
+	EnableAudit(auditor)
+	defer EnableAudit(nil)
+
+	client := &Client{BaseURL: server.URL, Model: "test", Client: server.Client()}
+	ctx := WithAuditScope(context.Background(), "player:kain")
+	if _, err := client.CallStructured(ctx, "system", "user"); err != nil {
+		t.Fatal(err)
+	}
+	client.Call(context.Background(), "prompt")
+	auditor.Close()
+
+	records, err := auditor.Query(AuditQuery{ScopeID: "player:kain"})
+	if err != nil || len(records) != 1 {
+		t.Fatalf("expected one record of the scope, got %d, %v", len(records), err)
+	}
+	rec := records[0]
+	if rec.Service != "narrative-orchestrator" || rec.Response != "Дождь стих" || rec.TotalTokens != 15 || rec.PromptTokens != 12 {
+		t.Errorf("unexpected record %+v", rec)
+	}
+	if rec.Endpoint != server.URL || !strings.Contains(string(rec.Request), `"system"`) {
+		t.Errorf("record must keep the endpoint and request, got %s %s", rec.Endpoint, rec.Request)
+	}
+	if all, _ := auditor.Query(AuditQuery{Service: "narrative-orchestrator"}); len(all) != 2 || !all[0].Time.After(all[1].Time) {
+		t.Errorf("expected 2 records, newest first, got %+v", all)
+	}
+
+	objects, _ := storage.ListObjects(DefaultAuditBucket, time.Now().UTC().Format(auditDayLayout)+"/narrative-orchestrator/")
+	if len(objects) != 2 || !strings.HasSuffix(objects[0].Key, ".json.gz") {
+		t.Errorf("expected compressed records in the date partition, got %+v", objects)
+	}
+}
+
+func TestAuditPrune(t *testing.T) {
+	storage := miniotest.New()
+	auditor := NewAuditor(storage, AuditConfig{Service: "world-generator", Retention: 48 * time.Hour})
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	auditor.now = func() time.Time { return now }
+
+	for _, age := range []time.Duration{0, 36 * time.Hour, 4 * 24 * time.Hour} {
+		auditor.Record(AuditRecord{Time: now.Add(-age), Model: "test"})
+	}
+	auditor.Close()
+
+	removed, err := auditor.Prune()
+	if err != nil || removed != 1 {
+		t.Fatalf("expected one expired record removed, got %d, %v", removed, err)
+	}
+	records, err := auditor.Query(AuditQuery{From: now.Add(-7 * 24 * time.Hour)})
+	if err != nil || len(records) != 2 {
+		t.Errorf("expected 2 records kept, got %d, %v", len(records), err)
+	}
+}
@@ -0,0 +1,130 @@
+// internal/oracle/breaker.go
+
+package oracle
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen возвращается без запроса, когда все адреса Oracle отключены автоматическим выключателем.
+var ErrCircuitOpen = errors.New("oracle circuit open: all endpoints unavailable")
+
+// Состояния автоматического выключателя адреса.
+const (
+	BreakerClosed   = "closed"    // запросы проходят
+	BreakerOpen     = "open"      // запросы отклоняются до истечения Cooldown
+	BreakerHalfOpen = "half_open" // пропускается один пробный запрос
+)
+
+// BreakerConfig задаёт срабатывание автоматического выключателя.
+type BreakerConfig struct {
+	// Threshold — число неудачных вызовов подряд, после которого адрес отключается.
+	Threshold int
+	// Cooldown — время до пробного запроса к отключённому адресу.
+	Cooldown time.Duration
+}
+
+// BreakerConfigFromEnv читает ORACLE_BREAKER_THRESHOLD (default 5) и ORACLE_BREAKER_COOLDOWN_MS (default 30000).
+func BreakerConfigFromEnv() BreakerConfig {
+	cfg := BreakerConfig{Threshold: 5, Cooldown: 30 * time.Second}
+	if n, err := strconv.Atoi(os.Getenv("ORACLE_BREAKER_THRESHOLD")); err == nil && n > 0 {
+		cfg.Threshold = n
+	}
+	if ms, err := strconv.Atoi(os.Getenv("ORACLE_BREAKER_COOLDOWN_MS")); err == nil && ms > 0 {
+		cfg.Cooldown = time.Duration(ms) * time.Millisecond
+	}
+	return cfg
+}
+
+// circuitBreaker отключает адрес после Threshold неудач подряд; по истечении Cooldown
+// пропускает один пробный запрос: успех возвращает адрес в работу, неудача — снова отключает.
+type circuitBreaker struct {
+	cfg BreakerConfig
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+}
+
+func newCircuitBreaker(cfg BreakerConfig) *circuitBreaker {
+	return &circuitBreaker{cfg: cfg, state: BreakerClosed}
+}
+
+// allow сообщает, можно ли отправить запрос; переводит отключённый адрес в пробный режим.
+func (b *circuitBreaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case BreakerOpen:
+		if now.Sub(b.openedAt) < b.cfg.Cooldown {
+			return false
+		}
+		b.state = BreakerHalfOpen
+		return true
+	case BreakerHalfOpen:
+		// Пробный запрос уже отправлен — остальные ждут его результата
+		return false
+	}
+	return true
+}
+
+// success фиксирует ответ адреса.
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = BreakerClosed
+	b.failures = 0
+}
+
+// failure фиксирует неудачу и сообщает, отключён ли адрес этой неудачей.
+func (b *circuitBreaker) failure(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	if b.state == BreakerHalfOpen || (b.state == BreakerClosed && b.failures >= b.cfg.Threshold) {
+		b.state = BreakerOpen
+		b.openedAt = now
+		return true
+	}
+	return false
+}
+
+func (b *circuitBreaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// breakers — выключатели по адресам. Общие для процесса: клиенты создаются на каждый
+// вызов (NewClient), а состояние адреса должно переживать отдельный клиент.
+var breakers = struct {
+	sync.Mutex
+	byURL map[string]*circuitBreaker
+}{byURL: make(map[string]*circuitBreaker)}
+
+func breakerFor(url string) *circuitBreaker {
+	breakers.Lock()
+	defer breakers.Unlock()
+	b, ok := breakers.byURL[url]
+	if !ok {
+		b = newCircuitBreaker(BreakerConfigFromEnv())
+		breakers.byURL[url] = b
+	}
+	return b
+}
+
+// EndpointStates возвращает состояние выключателя каждого адреса, к которому обращался процесс.
+func EndpointStates() map[string]string {
+	breakers.Lock()
+	defer breakers.Unlock()
+	states := make(map[string]string, len(breakers.byURL))
+	for url, b := range breakers.byURL {
+		states[url] = b.State()
+	}
+	return states
+}
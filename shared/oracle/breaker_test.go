@@ -0,0 +1,77 @@
+package oracle
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	b := newCircuitBreaker(BreakerConfig{Threshold: 2, Cooldown: time.Minute})
+	now := time.Now()
+
+	if b.failure(now) || !b.allow(now) {
+		t.Fatal("single failure must not open the breaker")
+	}
+	if !b.failure(now) || b.allow(now) || b.State() != BreakerOpen {
+		t.Fatalf("expected open breaker, got %s", b.State())
+	}
+
+	// После Cooldown проходит один пробный запрос
+	probe := now.Add(time.Minute)
+	if !b.allow(probe) || b.allow(probe) || b.State() != BreakerHalfOpen {
+		t.Fatalf("expected a single half-open probe, got %s", b.State())
+	}
+	if !b.failure(probe) || b.allow(probe.Add(time.Second)) {
+		t.Fatal("failed probe must reopen the breaker")
+	}
+
+	b.allow(probe.Add(time.Minute))
+	b.success()
+	if b.State() != BreakerClosed || b.failure(probe) {
+		t.Errorf("successful probe must close the breaker, got %s", b.State())
+	}
+}
+
+func TestClientFailover(t *testing.T) {
+	t.Setenv("ORACLE_BREAKER_THRESHOLD", "1")
+	t.Setenv("ORACLE_BREAKER_COOLDOWN_MS", "60000")
+
+	var primaryCalls atomic.Int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		primaryCalls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer primary.Close()
+	backup := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices": [{"message": {"content": "backup"}}]}`))
+	}))
+
+	client := &Client{URLs: []string{primary.URL, backup.URL}, Model: "test", Client: &http.Client{Timeout: time.Second}}
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		content, err := client.Call(ctx, "prompt")
+		if err != nil || content != "backup" {
+			t.Fatalf("expected failover to backup, got %q, %v", content, err)
+		}
+	}
+	// Первый вызов отключил основной адрес — второй ушёл сразу на резервный
+	if calls := primaryCalls.Load(); calls != 3 {
+		t.Errorf("expected only the first call's retries on the primary endpoint, got %d", calls)
+	}
+	if states := EndpointStates(); states[primary.URL] != BreakerOpen || states[backup.URL] != BreakerClosed {
+		t.Errorf("unexpected endpoint states %v", states)
+	}
+
+	// Когда отключены все адреса, вызов завершается сразу
+	backup.Close()
+	client.Call(ctx, "prompt")
+	if _, err := client.Call(ctx, "prompt"); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected ErrCircuitOpen, got %v", err)
+	}
+}
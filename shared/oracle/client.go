@@ -8,20 +8,56 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"os"
 	"strconv"
 	"strings"
 	"time"
+
+	"multiverse-core.io/shared/logging"
+	"multiverse-core.io/shared/tracing"
 )
 
 // Client отвечает за взаимодействие с Ascension Oracle (Qwen3 и совместимые).
 type Client struct {
 	BaseURL string
+	// URLs — адреса Oracle в порядке приоритета; пусто — только BaseURL
+	URLs    []string
 	Model   string
 	Client  *http.Client
 	API_KEY string
+	// Sampling заменяет параметры выборки методов вызова (nil — значения по умолчанию методов)
+	Sampling *Sampling
+}
+
+// Sampling — фиксированные параметры выборки, например для воспроизводимой генерации.
+type Sampling struct {
+	Temperature float64
+	TopP        float64
+	Seed        int64 // передаётся как "seed"; OpenAI-совместимые API без его поддержки игнорируют поле
+}
+
+// WithSampling возвращает копию клиента, передающую во всех вызовах параметры s.
+func (c *Client) WithSampling(s Sampling) *Client {
+	clone := *c
+	clone.Sampling = &s
+	return &clone
+}
+
+// applySampling подставляет фиксированные параметры выборки в тело запроса.
+func (c *Client) applySampling(body map[string]interface{}) {
+	if c.Sampling == nil {
+		return
+	}
+	body["temperature"] = c.Sampling.Temperature
+	body["top_p"] = c.Sampling.TopP
+	body["seed"] = c.Sampling.Seed
+}
+
+// marshalRequest сериализует тело запроса с учётом Sampling.
+func (c *Client) marshalRequest(body map[string]interface{}) ([]byte, error) {
+	c.applySampling(body)
+	return json.Marshal(body)
 }
 
 // NewClient создаёт новый экземпляр клиента Oracle.
@@ -30,16 +66,26 @@ func NewClient() *Client {
 	if baseURL == "" {
 		baseURL = "http://qwen3-service:11434/v1/chat/completions"
 	}
+	// ORACLE_URLS — резервные адреса в порядке приоритета, заменяет ORACLE_URL
+	var urls []string
+	for _, u := range strings.Split(os.Getenv("ORACLE_URLS"), ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	if len(urls) > 0 {
+		baseURL = urls[0]
+	}
 
 	model := os.Getenv("ORACLE_MODEL")
 	if model == "" {
 		model = "qwen3"
-		log.Printf("ORACLE_MODEL not set, using default: %s", model)
+		logging.Warnf("ORACLE_MODEL not set, using default: %s", model)
 	}
 
 	apiKey := os.Getenv("ORACLE_API_KEY")
 	if apiKey == "" {
-		log.Println("WARNING: ORACLE_API_KEY not set — requests may fail")
+		logging.Warnf("ORACLE_API_KEY not set — requests may fail")
 	}
 
 	timeoutMsStr := os.Getenv("ORACLE_TIMEOUT_MS")
@@ -51,9 +97,11 @@ func NewClient() *Client {
 
 	return &Client{
 		BaseURL: baseURL,
+		URLs:    urls,
 		Model:   model,
 		Client: &http.Client{
-			Timeout: timeout,
+			Timeout:   timeout,
+			Transport: tracing.NewTransport("oracle", nil),
 		},
 		API_KEY: apiKey,
 	}
@@ -65,6 +113,12 @@ func (c *Client) doRequest(ctx context.Context, req *http.Request) (*http.Respon
 	var err error
 
 	for attempt := 0; attempt < 3; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			// Тело запроса прочитано предыдущей попыткой
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
 		resp, err = c.Client.Do(req.WithContext(ctx))
 		if err == nil && resp.StatusCode < 500 {
 			return resp, nil
@@ -82,22 +136,109 @@ func (c *Client) doRequest(ctx context.Context, req *http.Request) (*http.Respon
 	return resp, err
 }
 
-// callRaw выполняет вызов Oracle с произвольным телом запроса.
+// endpoints возвращает адреса Oracle в порядке приоритета.
+func (c *Client) endpoints() []string {
+	if len(c.URLs) > 0 {
+		return c.URLs
+	}
+	return []string{c.BaseURL}
+}
+
+// send отправляет запрос первому доступному адресу Oracle. Адрес, не ответивший после
+// повторов (ошибка соединения или статус 5xx), засчитывается выключателю как неудача,
+// и запрос уходит следующему; отключённые выключателем адреса пропускаются без запроса.
+func (c *Client) send(ctx context.Context, requestBody []byte) (*http.Response, error) {
+	var lastErr error
+	for _, url := range c.endpoints() {
+		breaker := breakerFor(url)
+		if !breaker.allow(time.Now()) {
+			continue
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(requestBody))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if c.API_KEY != "" {
+			req.Header.Set("Authorization", "Bearer "+c.API_KEY)
+		}
+
+		resp, err := c.doRequest(ctx, req)
+		if err == nil && resp.StatusCode < 500 {
+			breaker.success()
+			return resp, nil
+		}
+		lastErr = err
+		if err == nil {
+			lastErr = fmt.Errorf("oracle returned status %d", resp.StatusCode)
+		}
+		if breaker.failure(time.Now()) {
+			logging.Warnf("Oracle endpoint %s disabled after repeated failures: %v", url, lastErr)
+		} else {
+			logging.Warnf("Oracle endpoint %s failed: %v", url, lastErr)
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if lastErr == nil {
+		return nil, ErrCircuitOpen
+	}
+	return nil, fmt.Errorf("request failed: %w", lastErr)
+}
+
+// tokenUsage — расход токенов из поля usage ответа.
+type tokenUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// callInfo — сведения о вызове для журнала аудита.
+type callInfo struct {
+	endpoint string
+	usage    tokenUsage
+}
+
+// callRaw выполняет вызов Oracle с произвольным телом запроса и записывает его в журнал аудита, если он включён.
 func (c *Client) callRaw(ctx context.Context, requestBody []byte) (string, error) {
-	req, err := http.NewRequestWithContext(ctx, "POST", c.BaseURL, bytes.NewBuffer(requestBody))
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+	auditor := audit.Load()
+	if auditor == nil {
+		return c.call(ctx, requestBody, &callInfo{})
 	}
-	req.Header.Set("Content-Type", "application/json")
-	if c.API_KEY != "" {
-		req.Header.Set("Authorization", "Bearer "+c.API_KEY)
+
+	start := time.Now()
+	var info callInfo
+	content, err := c.call(ctx, requestBody, &info)
+	rec := AuditRecord{
+		ScopeID:          auditScope(ctx),
+		Endpoint:         info.endpoint,
+		Model:            c.Model,
+		Request:          json.RawMessage(requestBody),
+		Response:         content,
+		LatencyMs:        time.Since(start).Milliseconds(),
+		PromptTokens:     info.usage.PromptTokens,
+		CompletionTokens: info.usage.CompletionTokens,
+		TotalTokens:      info.usage.TotalTokens,
+	}
+	if err != nil {
+		rec.Error = err.Error()
 	}
+	auditor.Record(rec)
+	return content, err
+}
 
-	resp, err := c.doRequest(ctx, req)
+// call отправляет запрос и извлекает текст ответа, заполняя info.
+func (c *Client) call(ctx context.Context, requestBody []byte, info *callInfo) (string, error) {
+	resp, err := c.send(ctx, requestBody)
 	if err != nil {
-		return "", fmt.Errorf("request failed: %w", err)
+		return "", err
 	}
 	defer resp.Body.Close()
+	if resp.Request != nil {
+		info.endpoint = resp.Request.URL.String()
+	}
 
 	// Проверка Content-Type
 	if ct := resp.Header.Get("Content-Type"); !strings.Contains(strings.ToLower(ct), "application/json") {
@@ -115,7 +256,7 @@ func (c *Client) callRaw(ctx context.Context, requestBody []byte) (string, error
 	}
 
 	// Безопасное логирование
-	log.Printf("Oracle response: %d bytes, status %d", len(responseBody), resp.StatusCode)
+	logging.Infof("Oracle response: %d bytes, status %d", len(responseBody), resp.StatusCode)
 
 	var responseStruct struct {
 		Choices []struct {
@@ -123,10 +264,12 @@ func (c *Client) callRaw(ctx context.Context, requestBody []byte) (string, error
 				Content string `json:"content"`
 			} `json:"message"`
 		} `json:"choices"`
+		Usage tokenUsage `json:"usage"`
 	}
 	if err := json.Unmarshal(responseBody, &responseStruct); err != nil {
 		return "", fmt.Errorf("failed to unmarshal oracle response: %w", err)
 	}
+	info.usage = responseStruct.Usage
 
 	if len(responseStruct.Choices) == 0 {
 		return "", fmt.Errorf("oracle returned no choices")
@@ -143,7 +286,7 @@ func (c *Client) callRaw(ctx context.Context, requestBody []byte) (string, error
 // systemPrompt — контекст (факты, сущности, окружение),
 // userPrompt — задача и требования.
 func (c *Client) CallStructured(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
-	requestBody, err := json.Marshal(map[string]interface{}{
+	requestBody, err := c.marshalRequest(map[string]interface{}{
 		"model": c.Model,
 		"messages": []map[string]interface{}{
 			{"role": "system", "content": systemPrompt},
@@ -168,7 +311,7 @@ func (c *Client) CallStructured(ctx context.Context, systemPrompt, userPrompt st
 // Использует response_format: {"type": "json_object"} вместо "text".
 // Старый CallStructured остаётся без изменений для обратной совместимости.
 func (c *Client) CallStructuredJSON(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
-	requestBody, err := json.Marshal(map[string]interface{}{
+	requestBody, err := c.marshalRequest(map[string]interface{}{
 		"model": c.Model,
 		"messages": []map[string]interface{}{
 			{"role": "system", "content": systemPrompt},
@@ -201,7 +344,7 @@ func (c *Client) CallStructuredJSON(ctx context.Context, systemPrompt, userPromp
 // Передаёт весь промт как один user-месседж.
 // Рекомендуется использовать CallStructured.
 func (c *Client) Call(ctx context.Context, prompt string) (string, error) {
-	requestBody, err := json.Marshal(map[string]interface{}{
+	requestBody, err := c.marshalRequest(map[string]interface{}{
 		"model": c.Model,
 		"messages": []map[string]interface{}{
 			{"role": "user", "content": prompt},
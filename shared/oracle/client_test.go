@@ -0,0 +1,44 @@
+package oracle
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithSamplingPinsParameters(t *testing.T) {
+	var bodies []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices": [{"message": {"content": "ok"}}]}`))
+	}))
+	defer server.Close()
+
+	client := &Client{BaseURL: server.URL, Model: "test", Client: server.Client()}
+	pinned := client.WithSampling(Sampling{Temperature: 0, TopP: 1, Seed: 42})
+	if client.Sampling != nil {
+		t.Fatal("WithSampling must not modify the original client")
+	}
+
+	ctx := context.Background()
+	client.CallStructured(ctx, "system", "user")
+	pinned.CallStructured(ctx, "system", "user")
+	pinned.Call(ctx, "prompt")
+	if len(bodies) != 3 {
+		t.Fatalf("expected 3 requests, got %d", len(bodies))
+	}
+
+	if bodies[0]["temperature"] != 0.8 || bodies[0]["seed"] != nil {
+		t.Errorf("default sampling changed: %v", bodies[0])
+	}
+	for _, body := range bodies[1:] {
+		if body["temperature"] != 0.0 || body["top_p"] != 1.0 || body["seed"] != 42.0 {
+			t.Errorf("expected pinned sampling, got temperature=%v top_p=%v seed=%v", body["temperature"], body["top_p"], body["seed"])
+		}
+	}
+}
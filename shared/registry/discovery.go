@@ -0,0 +1,206 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+)
+
+// ErrNoInstances — для сервиса нет ни живых экземпляров, ни резервного адреса.
+var ErrNoInstances = errors.New("registry: no healthy instances")
+
+const (
+	defaultResolveTTL = 10 * time.Second
+	defaultHealthTTL  = 10 * time.Second
+	healthTimeout     = 2 * time.Second
+)
+
+// resolution — закэшированный результат Resolve.
+type resolution struct {
+	url       string
+	expiresAt time.Time
+}
+
+// healthStatus — закэшированный результат health-проверки экземпляра.
+type healthStatus struct {
+	healthy   bool
+	checkedAt time.Time
+}
+
+// Discovery отслеживает анонсы сервисов и выбирает живой экземпляр по имени сервиса.
+type Discovery struct {
+	bus    *eventbus.EventBus
+	owner  string
+	client *http.Client
+
+	resolveTTL time.Duration
+	healthTTL  time.Duration
+
+	mu        sync.RWMutex
+	instances map[string]map[string]Endpoint // service → instance_id → endpoint
+	fallbacks map[string]string              // service → статический URL из окружения
+	resolved  map[string]resolution
+	health    map[string]healthStatus // url → статус
+}
+
+// NewDiscovery создаёт клиент обнаружения для сервиса owner.
+func NewDiscovery(bus *eventbus.EventBus, owner string) *Discovery {
+	return &Discovery{
+		bus:        bus,
+		owner:      owner,
+		client:     &http.Client{Timeout: healthTimeout},
+		resolveTTL: defaultResolveTTL,
+		healthTTL:  defaultHealthTTL,
+		instances:  make(map[string]map[string]Endpoint),
+		fallbacks:  make(map[string]string),
+		resolved:   make(map[string]resolution),
+		health:     make(map[string]healthStatus),
+	}
+}
+
+// SetFallback задаёт адрес, используемый если живых экземпляров нет
+// (обычно значение прежней переменной окружения, например SEMANTIC_MEMORY_URL).
+func (d *Discovery) SetFallback(service, url string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.fallbacks[service] = url
+}
+
+// Run подписывается на анонсы и блокируется до отмены ctx.
+// Анонсы нужны каждому экземпляру, поэтому они читаются без consumer group (группы в брокере
+// не копятся от перезапусков), начиная с последних announceWindow — за это время каждый живой
+// экземпляр успевает повторить анонс.
+func (d *Discovery) Run(ctx context.Context) {
+	logging.Infof("%s: watching service announcements in %s", d.owner, eventbus.TopicSystemEvents)
+	d.bus.SubscribeRecent(ctx, eventbus.TopicSystemEvents, announceWindow, d.HandleEvent)
+}
+
+// HandleEvent обновляет реестр по событиям service.announced / service.departed.
+func (d *Discovery) HandleEvent(ev eventbus.Event) {
+	if ev.Type != EventServiceAnnounced && ev.Type != EventServiceDeparted {
+		return
+	}
+
+	ep, err := endpointFromEvent(ev)
+	if err != nil {
+		logging.Warnf("Ignoring registry event: %v", err)
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	switch ev.Type {
+	case EventServiceAnnounced:
+		if ep.Expired(time.Now()) {
+			return // старый анонс из истории топика
+		}
+		if d.instances[ep.Service] == nil {
+			d.instances[ep.Service] = make(map[string]Endpoint)
+		}
+		if _, known := d.instances[ep.Service][ep.InstanceID]; !known {
+			logging.Infof("Discovered %s instance %s at %s", ep.Service, ep.InstanceID, ep.URL)
+		}
+		d.instances[ep.Service][ep.InstanceID] = ep
+	case EventServiceDeparted:
+		delete(d.instances[ep.Service], ep.InstanceID)
+		if cached, ok := d.resolved[ep.Service]; ok && cached.url == ep.URL {
+			delete(d.resolved, ep.Service)
+		}
+		logging.Infof("%s instance %s departed", ep.Service, ep.InstanceID)
+	}
+}
+
+// Instances возвращает живые экземпляры сервиса, от самых свежих анонсов к старым.
+func (d *Discovery) Instances(service string) []Endpoint {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	now := time.Now()
+	var result []Endpoint
+	for _, ep := range d.instances[service] {
+		if !ep.Expired(now) {
+			result = append(result, ep)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].AnnouncedAt.After(result[j].AnnouncedAt)
+	})
+	return result
+}
+
+// Resolve возвращает базовый URL живого экземпляра сервиса.
+// Результат кэшируется; экземпляры проверяются через health-эндпоинт,
+// при отсутствии здоровых используется резервный адрес из SetFallback.
+func (d *Discovery) Resolve(ctx context.Context, service string) (string, error) {
+	d.mu.RLock()
+	cached, ok := d.resolved[service]
+	d.mu.RUnlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.url, nil
+	}
+
+	for _, ep := range d.Instances(service) {
+		if d.isHealthy(ctx, ep) {
+			d.mu.Lock()
+			d.resolved[service] = resolution{url: ep.URL, expiresAt: time.Now().Add(d.resolveTTL)}
+			d.mu.Unlock()
+			return ep.URL, nil
+		}
+	}
+
+	d.mu.RLock()
+	fallback, ok := d.fallbacks[service]
+	d.mu.RUnlock()
+	if ok && fallback != "" {
+		return fallback, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrNoInstances, service)
+}
+
+// MarkFailed сообщает о неудачном запросе к экземпляру: он считается нездоровым
+// до следующей проверки, и следующий Resolve выберет другой экземпляр.
+func (d *Discovery) MarkFailed(service, url string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if cached, ok := d.resolved[service]; ok && cached.url == url {
+		delete(d.resolved, service)
+	}
+	d.health[url] = healthStatus{healthy: false, checkedAt: time.Now()}
+}
+
+// isHealthy проверяет экземпляр через health-эндпоинт с кэшированием результата.
+func (d *Discovery) isHealthy(ctx context.Context, ep Endpoint) bool {
+	d.mu.RLock()
+	status, ok := d.health[ep.URL]
+	d.mu.RUnlock()
+	if ok && time.Since(status.checkedAt) < d.healthTTL {
+		return status.healthy
+	}
+
+	healthy := false
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ep.healthURL(), nil)
+	if err == nil {
+		resp, err := d.client.Do(req)
+		if err == nil {
+			healthy = resp.StatusCode < 400
+			resp.Body.Close()
+		}
+	}
+	if !healthy {
+		logging.Errorf("%s instance %s at %s failed health check", ep.Service, ep.InstanceID, ep.URL)
+	}
+
+	d.mu.Lock()
+	d.health[ep.URL] = healthStatus{healthy: healthy, checkedAt: time.Now()}
+	d.mu.Unlock()
+	return healthy
+}
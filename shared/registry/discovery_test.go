@@ -0,0 +1,106 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+)
+
+func announcement(eventType string, ep Endpoint) eventbus.Event {
+	return eventbus.NewEvent(eventType, ep.Service, "", ep.toPayload())
+}
+
+func healthServer(status int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+}
+
+func TestDiscovery_ResolveSkipsUnhealthyInstance(t *testing.T) {
+	healthy := healthServer(http.StatusOK)
+	defer healthy.Close()
+	broken := healthServer(http.StatusServiceUnavailable)
+	defer broken.Close()
+
+	d := NewDiscovery(nil, "test")
+	now := time.Now()
+	d.HandleEvent(announcement(EventServiceAnnounced, Endpoint{
+		Service: "semantic-memory", InstanceID: "a", URL: healthy.URL, TTLMs: 60000, AnnouncedAt: now.Add(-time.Second),
+	}))
+	d.HandleEvent(announcement(EventServiceAnnounced, Endpoint{
+		Service: "semantic-memory", InstanceID: "b", URL: broken.URL, TTLMs: 60000, AnnouncedAt: now,
+	}))
+
+	url, err := d.Resolve(context.Background(), "semantic-memory")
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if url != healthy.URL {
+		t.Errorf("expected healthy instance %s, got %s", healthy.URL, url)
+	}
+}
+
+func TestDiscovery_MarkFailedFailsOver(t *testing.T) {
+	first := healthServer(http.StatusOK)
+	defer first.Close()
+	second := healthServer(http.StatusOK)
+	defer second.Close()
+
+	d := NewDiscovery(nil, "test")
+	now := time.Now()
+	d.HandleEvent(announcement(EventServiceAnnounced, Endpoint{
+		Service: "archivist", InstanceID: "1", URL: first.URL, TTLMs: 60000, AnnouncedAt: now,
+	}))
+	d.HandleEvent(announcement(EventServiceAnnounced, Endpoint{
+		Service: "archivist", InstanceID: "2", URL: second.URL, TTLMs: 60000, AnnouncedAt: now.Add(-time.Second),
+	}))
+
+	url, _ := d.Resolve(context.Background(), "archivist")
+	if url != first.URL {
+		t.Fatalf("expected freshest instance %s, got %s", first.URL, url)
+	}
+
+	d.MarkFailed("archivist", url)
+	url, _ = d.Resolve(context.Background(), "archivist")
+	if url != second.URL {
+		t.Errorf("expected failover to %s, got %s", second.URL, url)
+	}
+}
+
+func TestDiscovery_FallbackAndDeparture(t *testing.T) {
+	server := healthServer(http.StatusOK)
+	defer server.Close()
+
+	d := NewDiscovery(nil, "test")
+	if _, err := d.Resolve(context.Background(), "semantic-memory"); !errors.Is(err, ErrNoInstances) {
+		t.Fatalf("expected ErrNoInstances, got %v", err)
+	}
+
+	d.SetFallback("semantic-memory", "http://semantic-memory:8080")
+	ep := Endpoint{Service: "semantic-memory", InstanceID: "a", URL: server.URL, TTLMs: 60000, AnnouncedAt: time.Now()}
+	d.HandleEvent(announcement(EventServiceAnnounced, ep))
+	if url, _ := d.Resolve(context.Background(), "semantic-memory"); url != server.URL {
+		t.Fatalf("expected announced instance, got %s", url)
+	}
+
+	d.HandleEvent(announcement(EventServiceDeparted, ep))
+	if url, _ := d.Resolve(context.Background(), "semantic-memory"); url != "http://semantic-memory:8080" {
+		t.Errorf("expected fallback after departure, got %s", url)
+	}
+}
+
+func TestDiscovery_IgnoresExpiredAnnouncements(t *testing.T) {
+	d := NewDiscovery(nil, "test")
+	d.HandleEvent(announcement(EventServiceAnnounced, Endpoint{
+		Service: "semantic-memory", InstanceID: "old", URL: "http://old:8080", TTLMs: 1000, AnnouncedAt: time.Now().Add(-time.Hour),
+	}))
+
+	if instances := d.Instances("semantic-memory"); len(instances) != 0 {
+		t.Errorf("expected expired announcement to be ignored, got %d instances", len(instances))
+	}
+}
@@ -0,0 +1,189 @@
+// Package registry — самоописание сервисов и их обнаружение через анонсы в Kafka.
+//
+// Каждый сервис с HTTP API при старте публикует в system_events событие
+// service.announced со своим адресом и возможностями и повторяет его как heartbeat.
+// Клиенты используют Discovery вместо жёстко заданных *_URL: адреса кэшируются,
+// экземпляры проверяются через health-эндпоинт, при сбое выбирается следующий.
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+
+	"github.com/google/uuid"
+)
+
+// Типы событий реестра (публикуются в eventbus.TopicSystemEvents).
+const (
+	EventServiceAnnounced = "service.announced"
+	EventServiceDeparted  = "service.departed"
+)
+
+// Имена сервисов, публикующих HTTP API.
+const (
+	ServiceSemanticMemory = "semantic-memory"
+	ServiceArchivist      = "ontological-archivist"
+)
+
+const (
+	defaultHeartbeatInterval = 15 * time.Second
+	defaultHealthPath        = "/health"
+
+	// Экземпляр считается живым, пока не пропущено ttlHeartbeats подряд heartbeat-ов.
+	ttlHeartbeats = 3
+	// announceWindow — сколько истории анонсов Discovery читает при старте
+	announceWindow = ttlHeartbeats * defaultHeartbeatInterval
+)
+
+// Endpoint описывает экземпляр сервиса и его возможности.
+type Endpoint struct {
+	Service      string    `json:"service"`
+	InstanceID   string    `json:"instance_id"`
+	URL          string    `json:"url"`
+	Capabilities []string  `json:"capabilities,omitempty"`
+	Version      string    `json:"version,omitempty"`
+	HealthPath   string    `json:"health_path,omitempty"`
+	TTLMs        int64     `json:"ttl_ms"`
+	AnnouncedAt  time.Time `json:"announced_at"`
+}
+
+// HasCapability проверяет, заявлена ли возможность экземпляром.
+func (e Endpoint) HasCapability(capability string) bool {
+	for _, c := range e.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// Expired сообщает, что heartbeat экземпляра не приходил дольше TTL.
+func (e Endpoint) Expired(now time.Time) bool {
+	ttl := time.Duration(e.TTLMs) * time.Millisecond
+	if ttl <= 0 {
+		ttl = ttlHeartbeats * defaultHeartbeatInterval
+	}
+	return now.Sub(e.AnnouncedAt) > ttl
+}
+
+// healthURL возвращает адрес health-эндпоинта экземпляра.
+func (e Endpoint) healthURL() string {
+	path := e.HealthPath
+	if path == "" {
+		path = defaultHealthPath
+	}
+	return e.URL + path
+}
+
+// toPayload сериализует Endpoint в payload события.
+func (e Endpoint) toPayload() map[string]any {
+	data, _ := json.Marshal(e)
+	var payload map[string]any
+	json.Unmarshal(data, &payload)
+	return payload
+}
+
+// endpointFromEvent восстанавливает Endpoint из события реестра.
+func endpointFromEvent(ev eventbus.Event) (Endpoint, error) {
+	var ep Endpoint
+	data, err := json.Marshal(ev.Payload)
+	if err != nil {
+		return ep, err
+	}
+	if err := json.Unmarshal(data, &ep); err != nil {
+		return ep, err
+	}
+	if ep.Service == "" || ep.InstanceID == "" {
+		return ep, fmt.Errorf("announcement %s missing service or instance_id", ev.ID)
+	}
+	return ep, nil
+}
+
+// Announcer публикует анонс сервиса при старте, heartbeat-ы и уход при остановке.
+type Announcer struct {
+	bus      *eventbus.EventBus
+	endpoint Endpoint
+	interval time.Duration
+}
+
+// NewAnnouncer создаёт анонсер для сервиса.
+// Адрес можно переопределить через ADVERTISE_URL, период heartbeat —
+// через REGISTRY_HEARTBEAT_INTERVAL_MS (default 15000).
+func NewAnnouncer(bus *eventbus.EventBus, service, url string, capabilities ...string) *Announcer {
+	if advertised := os.Getenv("ADVERTISE_URL"); advertised != "" {
+		url = advertised
+	}
+
+	interval := defaultHeartbeatInterval
+	if raw := os.Getenv("REGISTRY_HEARTBEAT_INTERVAL_MS"); raw != "" {
+		if ms, err := strconv.Atoi(raw); err == nil && ms > 0 {
+			interval = time.Duration(ms) * time.Millisecond
+		} else {
+			logging.Warnf("Invalid REGISTRY_HEARTBEAT_INTERVAL_MS value %q, using default %s", raw, interval)
+		}
+	}
+
+	instanceID, _ := os.Hostname()
+	if instanceID == "" {
+		instanceID = uuid.NewString()
+	}
+
+	return &Announcer{
+		bus: bus,
+		endpoint: Endpoint{
+			Service:      service,
+			InstanceID:   instanceID,
+			URL:          url,
+			Capabilities: capabilities,
+			Version:      os.Getenv("SERVICE_VERSION"),
+			HealthPath:   defaultHealthPath,
+			TTLMs:        (ttlHeartbeats * interval).Milliseconds(),
+		},
+		interval: interval,
+	}
+}
+
+// Endpoint возвращает анонсируемое описание экземпляра.
+func (a *Announcer) Endpoint() Endpoint {
+	return a.endpoint
+}
+
+// Run публикует анонс и heartbeat-ы до отмены ctx, затем сообщает об уходе экземпляра.
+func (a *Announcer) Run(ctx context.Context) {
+	a.publish(ctx, EventServiceAnnounced)
+	logging.Infof("Announced %s (%s) at %s", a.endpoint.Service, a.endpoint.InstanceID, a.endpoint.URL)
+
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			a.publish(ctx, EventServiceAnnounced)
+		case <-ctx.Done():
+			// Отдельный контекст: уход должен быть опубликован и при остановке сервиса
+			departCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			a.publish(departCtx, EventServiceDeparted)
+			cancel()
+			return
+		}
+	}
+}
+
+// publish отправляет событие реестра с текущим временем анонса.
+func (a *Announcer) publish(ctx context.Context, eventType string) {
+	ep := a.endpoint
+	ep.AnnouncedAt = time.Now().UTC()
+
+	ev := eventbus.NewEvent(eventType, ep.Service, "", ep.toPayload())
+	if err := a.bus.PublishSystemEvent(ctx, ev); err != nil {
+		logging.Errorf("Failed to publish %s for %s: %v", eventType, ep.Service, err)
+	}
+}
@@ -0,0 +1,215 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// ErrInvalidSchema reports a schema that is not usable JSON Schema Draft 7.
+var ErrInvalidSchema = errors.New("invalid JSON schema")
+
+// maxSampleDepth bounds SampleInstance on recursive $ref schemas.
+const maxSampleDepth = 32
+
+// CheckDraft7 checks that schemaData is usable JSON Schema Draft 7: it must compile against
+// the Draft 7 meta-schema, and a synthetic instance built by SampleInstance must validate
+// against it. The second step catches contradictory schemas, e.g. required properties
+// that additionalProperties forbids. Errors wrap ErrInvalidSchema.
+// Custom formats are checked only after RegisterCustomFormats.
+func CheckDraft7(schemaData []byte) error {
+	var doc interface{}
+	if err := json.Unmarshal(schemaData, &doc); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+
+	loader := gojsonschema.NewSchemaLoader()
+	loader.Draft = gojsonschema.Draft7
+	loader.Validate = true
+	compiled, err := loader.Compile(gojsonschema.NewGoLoader(doc))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+
+	result, err := compiled.Validate(gojsonschema.NewGoLoader(SampleInstance(doc)))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+	var violations []string
+	for _, desc := range result.Errors() {
+		// Patterns cannot be satisfied by sampling; the meta-schema already checked the regex.
+		if desc.Type() == "pattern" {
+			continue
+		}
+		violations = append(violations, desc.String())
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("%w: sample instance rejected: %s", ErrInvalidSchema, strings.Join(violations, "; "))
+	}
+	return nil
+}
+
+// SampleInstance builds a minimal instance of a decoded schema: required properties only,
+// the first enum value or alternative, minimal lengths and bounds. It is used to test
+// that a schema accepts anything at all, not to produce realistic data.
+func SampleInstance(schema interface{}) interface{} {
+	return sampler{root: schema}.sample(schema, 0)
+}
+
+type sampler struct {
+	root interface{}
+}
+
+func (s sampler) sample(schema interface{}, depth int) interface{} {
+	node, ok := schema.(map[string]interface{})
+	if !ok || depth > maxSampleDepth {
+		return nil
+	}
+	if ref, ok := node["$ref"].(string); ok {
+		return s.sample(s.resolve(ref), depth+1)
+	}
+	if value, ok := node["const"]; ok {
+		return value
+	}
+	if values, ok := node["enum"].([]interface{}); ok && len(values) > 0 {
+		return values[0]
+	}
+	for _, keyword := range []string{"allOf", "anyOf", "oneOf"} {
+		if alternatives, ok := node[keyword].([]interface{}); ok && len(alternatives) > 0 && node["type"] == nil {
+			return s.sample(alternatives[0], depth+1)
+		}
+	}
+
+	switch schemaType(node) {
+	case "object":
+		obj := make(map[string]interface{})
+		properties, _ := node["properties"].(map[string]interface{})
+		required, _ := node["required"].([]interface{})
+		for _, name := range required {
+			key, ok := name.(string)
+			if !ok {
+				continue
+			}
+			propSchema, ok := properties[key]
+			if !ok {
+				propSchema = node["additionalProperties"]
+			}
+			obj[key] = s.sample(propSchema, depth+1)
+		}
+		return obj
+	case "array":
+		n := int(number(node, "minItems", 0))
+		items := make([]interface{}, 0, n)
+		tuple, isTuple := node["items"].([]interface{})
+		for i := 0; i < n; i++ {
+			if isTuple && i < len(tuple) {
+				items = append(items, s.sample(tuple[i], depth+1))
+			} else {
+				items = append(items, s.sample(node["items"], depth+1))
+			}
+		}
+		return items
+	case "string":
+		return sampleString(node)
+	case "integer":
+		return math.Ceil(sampleNumber(node))
+	case "number":
+		return sampleNumber(node)
+	case "boolean":
+		return false
+	}
+	return nil
+}
+
+// resolve follows local references (#/definitions/...); others resolve to an empty schema.
+func (s sampler) resolve(ref string) interface{} {
+	if !strings.HasPrefix(ref, "#") {
+		return nil
+	}
+	node := s.root
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#"), "/") {
+		if part == "" {
+			continue
+		}
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		node = m[strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")]
+	}
+	return node
+}
+
+// schemaType returns the first non-null type, inferring objects and arrays from their keywords.
+func schemaType(node map[string]interface{}) string {
+	switch t := node["type"].(type) {
+	case string:
+		return t
+	case []interface{}:
+		for _, v := range t {
+			if s, ok := v.(string); ok && s != "null" {
+				return s
+			}
+		}
+		return "null"
+	}
+	if _, ok := node["properties"]; ok {
+		return "object"
+	}
+	if _, ok := node["items"]; ok {
+		return "array"
+	}
+	return ""
+}
+
+func sampleString(node map[string]interface{}) string {
+	var value string
+	switch node["format"] {
+	case "date-time":
+		value = "2000-01-01T00:00:00Z"
+	case "date":
+		value = "2000-01-01"
+	case "time":
+		value = "00:00:00Z"
+	case "event_id", "uuid":
+		value = "00000000-0000-4000-8000-000000000000"
+	case "email":
+		value = "sample@example.com"
+	case "uri", "uri-reference":
+		value = "https://example.com"
+	default:
+		value = "sample"
+	}
+	if minLength := int(number(node, "minLength", 0)); len(value) < minLength {
+		value += strings.Repeat("x", minLength-len(value))
+	}
+	if maxLength, ok := node["maxLength"].(float64); ok && len(value) > int(maxLength) {
+		value = value[:int(maxLength)]
+	}
+	return value
+}
+
+func sampleNumber(node map[string]interface{}) float64 {
+	value := 0.0
+	if minimum, ok := node["minimum"].(float64); ok {
+		value = minimum
+	}
+	if exclusive, ok := node["exclusiveMinimum"].(float64); ok && value <= exclusive {
+		value = exclusive + 1
+	}
+	if maximum, ok := node["maximum"].(float64); ok && value > maximum {
+		value = maximum
+	}
+	return value
+}
+
+func number(node map[string]interface{}, keyword string, fallback float64) float64 {
+	if v, ok := node[keyword].(float64); ok {
+		return v
+	}
+	return fallback
+}
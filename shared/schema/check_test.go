@@ -0,0 +1,34 @@
+package schema
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckDraft7(t *testing.T) {
+	RegisterCustomFormats()
+
+	valid := []string{
+		`{"type": "object", "properties": {"name": {"type": "string", "minLength": 3}, "hp": {"type": "integer", "minimum": 1}, "owner": {"type": "string", "format": "entity_id"}, "tags": {"type": "array", "items": {"enum": ["a", "b"]}, "minItems": 2}}, "required": ["name", "hp", "owner", "tags"]}`,
+		`{"definitions": {"stat": {"type": "number", "exclusiveMinimum": 0}}, "type": "object", "properties": {"power": {"$ref": "#/definitions/stat"}}, "required": ["power"]}`,
+		`{"type": "object", "properties": {"code": {"type": "string", "pattern": "^[A-Z]{3}$"}}, "required": ["code"]}`,
+	}
+	for _, s := range valid {
+		if err := CheckDraft7([]byte(s)); err != nil {
+			t.Errorf("expected valid schema %s, got %v", s, err)
+		}
+	}
+
+	invalid := []string{
+		`{"type": "object", "properties": {`,
+		`{"type": "objekt"}`,
+		`{"type": "object", "required": "name"}`,
+		`{"type": "object", "properties": {"name": {"type": "string"}}, "required": ["name", "hp"], "additionalProperties": false}`,
+		`{"type": "integer", "minimum": 10, "maximum": 5}`,
+	}
+	for _, s := range invalid {
+		if err := CheckDraft7([]byte(s)); !errors.Is(err, ErrInvalidSchema) {
+			t.Errorf("expected ErrInvalidSchema for %s, got %v", s, err)
+		}
+	}
+}
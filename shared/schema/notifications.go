@@ -0,0 +1,116 @@
+// Package schema announces schema changes on the event bus.
+package schema
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+
+	"github.com/google/uuid"
+)
+
+// EventSchemaUpdated is published to eventbus.TopicSystemEvents whenever
+// OntologicalArchivist stores a schema version or changes its deprecation.
+const EventSchemaUpdated = "schema.updated"
+
+// Change describes an updated schema version.
+type Change struct {
+	SchemaType string `json:"schema_type"`
+	Name       string `json:"name"`
+	Version    string `json:"version"`
+	// Hash is the content hash of the stored schema ("sha256:<hex>");
+	// consumers compare it to skip reloads of unchanged content.
+	Hash       string    `json:"hash"`
+	Deprecated bool      `json:"deprecated,omitempty"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// ContentHash returns the hash used in Change.Hash.
+func ContentHash(schemaData []byte) string {
+	sum := sha256.Sum256(schemaData)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// NewChangeEvent builds a schema.updated event.
+func NewChangeEvent(source string, change Change) eventbus.Event {
+	data, _ := json.Marshal(change)
+	var payload map[string]interface{}
+	json.Unmarshal(data, &payload)
+	return eventbus.NewEvent(EventSchemaUpdated, source, "", payload)
+}
+
+// ChangeFromEvent extracts the change from a schema.updated event.
+func ChangeFromEvent(ev eventbus.Event) (Change, error) {
+	var change Change
+	if ev.Type != EventSchemaUpdated {
+		return change, fmt.Errorf("event %s is not %s", ev.Type, EventSchemaUpdated)
+	}
+	data, err := json.Marshal(ev.Payload)
+	if err != nil {
+		return change, err
+	}
+	if err := json.Unmarshal(data, &change); err != nil {
+		return change, err
+	}
+	if change.SchemaType == "" || change.Name == "" {
+		return change, fmt.Errorf("schema change %s missing schema_type or name", ev.ID)
+	}
+	return change, nil
+}
+
+// ChangeSubscriber delivers schema.updated events to registered handlers so consumers
+// can drop cached schemas instead of polling the archivist.
+type ChangeSubscriber struct {
+	bus     *eventbus.EventBus
+	groupID string
+
+	mu       sync.RWMutex
+	handlers []func(Change)
+}
+
+// NewChangeSubscriber creates a subscriber for service owner.
+// Every instance reads in its own consumer group, since each one holds its own caches.
+func NewChangeSubscriber(bus *eventbus.EventBus, owner string) *ChangeSubscriber {
+	return &ChangeSubscriber{
+		bus:     bus,
+		groupID: fmt.Sprintf("schema-changes-%s-%s", owner, uuid.NewString()),
+	}
+}
+
+// OnChange registers a handler; handlers run in the subscriber goroutine.
+func (s *ChangeSubscriber) OnChange(handler func(Change)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers = append(s.handlers, handler)
+}
+
+// Run subscribes to system events and blocks until ctx is cancelled.
+func (s *ChangeSubscriber) Run(ctx context.Context) {
+	s.bus.Subscribe(ctx, eventbus.TopicSystemEvents, s.groupID, s.HandleEvent)
+}
+
+// HandleEvent dispatches a schema.updated event; other events are ignored.
+func (s *ChangeSubscriber) HandleEvent(ev eventbus.Event) {
+	if ev.Type != EventSchemaUpdated {
+		return
+	}
+	change, err := ChangeFromEvent(ev)
+	if err != nil {
+		logging.Warnf("Ignoring schema change event: %v", err)
+		return
+	}
+
+	s.mu.RLock()
+	handlers := append([]func(Change){}, s.handlers...)
+	s.mu.RUnlock()
+	for _, handler := range handlers {
+		handler(change)
+	}
+}
@@ -37,6 +37,34 @@ func (v *Validator) Validate(data map[string]interface{}) error {
 	return nil
 }
 
+// FieldError describes a single schema violation.
+type FieldError struct {
+	Field       string      `json:"field"`
+	Type        string      `json:"type"`
+	Description string      `json:"description"`
+	Value       interface{} `json:"value,omitempty"`
+}
+
+// ValidateFields validates data and returns every violation instead of a joined error.
+// The error is non-nil only when the schema itself cannot be loaded.
+func (v *Validator) ValidateFields(data map[string]interface{}) ([]FieldError, error) {
+	result, err := gojsonschema.Validate(v.schemaLoader, gojsonschema.NewGoLoader(data))
+	if err != nil {
+		return nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	var violations []FieldError
+	for _, desc := range result.Errors() {
+		violations = append(violations, FieldError{
+			Field:       desc.Field(),
+			Type:        desc.Type(),
+			Description: desc.Description(),
+			Value:       desc.Value(),
+		})
+	}
+	return violations, nil
+}
+
 // ValidateBytes validates raw JSON bytes.
 func (v *Validator) ValidateBytes(data []byte) error {
 	var obj map[string]interface{}
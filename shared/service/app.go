@@ -0,0 +1,162 @@
+// shared/service/app.go
+//
+// App — общая часть main всех сервисов: стандартные группы настроек, логирование, трассировка,
+// шина событий, обработка SIGINT/SIGTERM и health-сервер. Сервис лишь собирает свои компоненты
+// и передаёт их в App.Run.
+
+package service
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"multiverse-core.io/shared/config"
+	"multiverse-core.io/shared/eventbus"
+	"multiverse-core.io/shared/logging"
+	"multiverse-core.io/shared/minio"
+	"multiverse-core.io/shared/oracle"
+	"multiverse-core.io/shared/tracing"
+)
+
+// StandardOptions — группы настроек, которые App подключает каждому сервису.
+var StandardOptions = [][]config.Option{
+	config.KafkaOptions,
+	config.MinioOptions,
+	config.LoggingOptions,
+	config.TracingOptions,
+	config.HealthOptions,
+}
+
+// App — процесс сервиса.
+type App struct {
+	Name string
+	Env  *config.Values
+
+	hooks  Hooks
+	health *health
+	bus    *eventbus.EventBus
+}
+
+// Setup подключает конфигурацию (группы сервиса, затем StandardOptions; при совпадении имён
+// побеждает опция сервиса), логирование и трассировку. Ошибка конфигурации завершает процесс.
+func Setup(name string, groups ...[]config.Option) *App {
+	env := config.Setup(name, append(groups, StandardOptions...)...)
+	logging.Setup(name)
+	app := &App{Name: name, Env: env, health: newHealth(name)}
+	app.OnStop(tracing.Setup(name))
+	return app
+}
+
+// Bus возвращает шину событий на KAFKA_BROKERS; создаётся при первом вызове
+// и закрывается после остановки сервиса.
+func (a *App) Bus() *eventbus.EventBus {
+	if a.bus == nil {
+		a.bus = eventbus.NewEventBus(a.Env.List("KAFKA_BROKERS"))
+		bus := a.bus
+		a.OnStop(func() {
+			if err := bus.Close(); err != nil {
+				logging.Warnf("Failed to close event bus: %v", err)
+			}
+		})
+	}
+	return a.bus
+}
+
+// MinIO создаёт клиент MinIO из MINIO_ENDPOINT, MINIO_ACCESS_KEY и MINIO_SECRET_KEY.
+func (a *App) MinIO() (*minio.MinIOOfficialClient, error) {
+	return minio.NewMinIOOfficialClient(minio.Config{
+		Endpoint:        a.Env.String("MINIO_ENDPOINT"),
+		AccessKeyID:     a.Env.String("MINIO_ACCESS_KEY"),
+		SecretAccessKey: a.Env.String("MINIO_SECRET_KEY"),
+	})
+}
+
+// UseOracleAudit включает журнал аудита Oracle для всех клиентов процесса, если ORACLE_AUDIT=true
+// (настройки config.OracleOptions). Устаревшие записи удаляются раз в час.
+func (a *App) UseOracleAudit() {
+	if !a.Env.Bool("ORACLE_AUDIT") {
+		return
+	}
+	storage, err := a.MinIO()
+	if err != nil {
+		logging.Warnf("MinIO unavailable, Oracle audit is disabled: %v", err)
+		return
+	}
+	auditor := oracle.NewAuditor(storage, oracle.AuditConfig{
+		Bucket:    a.Env.String("ORACLE_AUDIT_BUCKET"),
+		Service:   a.Name,
+		Retention: time.Duration(a.Env.Int("ORACLE_AUDIT_RETENTION_DAYS")) * 24 * time.Hour,
+	})
+	oracle.EnableAudit(auditor)
+	a.Go(func(ctx context.Context) { auditor.RunRetention(ctx, time.Hour) })
+	a.OnStop(auditor.Close)
+	logging.Infof("Oracle audit enabled: bucket %s", a.Env.String("ORACLE_AUDIT_BUCKET"))
+}
+
+// OnStart добавляет обработчик, вызываемый перед запуском сервиса; ошибка отменяет запуск.
+func (a *App) OnStart(start func(ctx context.Context) error) {
+	a.hooks.OnStart = append(a.hooks.OnStart, start)
+}
+
+// OnStop добавляет обработчик, вызываемый после остановки сервиса (в обратном порядке добавления).
+func (a *App) OnStop(stop func()) {
+	a.hooks.OnStop = append(a.hooks.OnStop, stop)
+}
+
+// Go запускает fn в фоне на время работы сервиса (обнаружение сервисов, анонсы в реестре).
+func (a *App) Go(fn func(ctx context.Context)) {
+	a.OnStart(func(ctx context.Context) error {
+		go fn(ctx)
+		return nil
+	})
+}
+
+// AddCheck добавляет проверку зависимости в /health и /ready.
+func (a *App) AddCheck(name string, check Check) {
+	a.health.addCheck(name, check)
+}
+
+// Run запускает health-сервер на HEALTH_PORT и сервис до SIGINT/SIGTERM. Ошибка сервиса
+// завершает процесс после обработчиков остановки.
+func (a *App) Run(svc Service) {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		logging.Infof("Shutting down %s...", a.Name)
+	}()
+
+	a.serveHealth(ctx)
+
+	logging.Infof("%s starting...", a.Name)
+	a.health.setRunning(true)
+	err := Run(ctx, Func(func(ctx context.Context) error {
+		defer a.health.setRunning(false)
+		return svc.Run(ctx)
+	}), a.hooks)
+	if err != nil {
+		log.Fatalf("%s failed: %v", a.Name, err)
+	}
+	logging.Infof("%s stopped.", a.Name)
+}
+
+// serveHealth обслуживает /health и /ready до отмены ctx. Занятый порт не останавливает сервис.
+func (a *App) serveHealth(ctx context.Context) {
+	server := &http.Server{
+		Addr:              ":" + a.Env.String("HEALTH_PORT"),
+		Handler:           a.health.handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		if err := HTTPServer(server).Run(ctx); err != nil {
+			logging.Errorf("Health server on %s failed: %v", server.Addr, err)
+		}
+	}()
+	if checks := a.health.checkNames(); len(checks) > 0 {
+		logging.Infof("Health checks on %s: %v", server.Addr, checks)
+	}
+}
@@ -0,0 +1,164 @@
+// shared/service/health.go
+//
+// Health-сервер каркаса: GET /health — процесс жив, GET /ready — сервис запущен и все
+// зарегистрированные проверки зависимостей проходят (иначе 503, оркестратор придерживает трафик).
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// CheckTimeout ограничивает каждую проверку зависимости в /health и /ready.
+const CheckTimeout = 2 * time.Second
+
+// Статусы health-отчёта.
+const (
+	StatusHealthy  = "healthy"
+	StatusDegraded = "degraded"
+	StatusUp       = "up"
+	StatusDown     = "down"
+)
+
+// Check проверяет доступность зависимости (MinIO, Kafka, ...).
+type Check func(ctx context.Context) error
+
+// DependencyStatus — результат проверки зависимости.
+type DependencyStatus struct {
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// HealthReport — ответ /health и /ready.
+type HealthReport struct {
+	Service      string                      `json:"service"`
+	Status       string                      `json:"status"`
+	Time         string                      `json:"time"`
+	Ready        bool                        `json:"ready"`
+	Dependencies map[string]DependencyStatus `json:"dependencies,omitempty"`
+}
+
+// health хранит проверки и признак работы сервиса.
+type health struct {
+	service string
+
+	mu      sync.RWMutex
+	checks  map[string]Check
+	running bool
+}
+
+func newHealth(service string) *health {
+	return &health{service: service, checks: make(map[string]Check)}
+}
+
+func (h *health) addCheck(name string, check Check) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks[name] = check
+}
+
+func (h *health) setRunning(running bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.running = running
+}
+
+// report выполняет проверки параллельно; проверка дольше CheckTimeout считается неудачной.
+func (h *health) report(ctx context.Context) HealthReport {
+	h.mu.RLock()
+	checks := make(map[string]Check, len(h.checks))
+	for name, check := range h.checks {
+		checks[name] = check
+	}
+	running := h.running
+	h.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, CheckTimeout)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	statuses := make(map[string]DependencyStatus, len(checks))
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check Check) {
+			defer wg.Done()
+			start := time.Now()
+			// Не все клиенты учитывают ctx — ждём не дольше таймаута
+			done := make(chan error, 1)
+			go func() { done <- check(ctx) }()
+			var err error
+			select {
+			case err = <-done:
+			case <-ctx.Done():
+				err = ctx.Err()
+			}
+
+			status := DependencyStatus{Status: StatusUp, LatencyMs: time.Since(start).Milliseconds()}
+			if err != nil {
+				status.Status = StatusDown
+				status.Error = err.Error()
+			}
+			mu.Lock()
+			statuses[name] = status
+			mu.Unlock()
+		}(name, check)
+	}
+	wg.Wait()
+
+	report := HealthReport{
+		Service:      h.service,
+		Status:       StatusHealthy,
+		Time:         time.Now().Format(time.RFC3339),
+		Dependencies: statuses,
+	}
+	for _, status := range statuses {
+		if status.Status != StatusUp {
+			report.Status = StatusDegraded
+		}
+	}
+	report.Ready = running && report.Status == StatusHealthy
+	return report
+}
+
+// checkNames возвращает имена проверок по алфавиту (для логов).
+func (h *health) checkNames() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	names := make([]string, 0, len(h.checks))
+	for name := range h.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// handler обслуживает /health (всегда 200, пока процесс отвечает) и /ready (503, пока сервис
+// не запущен или зависимость недоступна).
+func (h *health) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, http.StatusOK, h.report(r.Context()))
+	})
+	mux.HandleFunc("/ready", func(w http.ResponseWriter, r *http.Request) {
+		report := h.report(r.Context())
+		status := http.StatusOK
+		if !report.Ready {
+			status = http.StatusServiceUnavailable
+		}
+		writeReport(w, status, report)
+	})
+	return mux
+}
+
+func writeReport(w http.ResponseWriter, status int, report HealthReport) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,103 @@
+// shared/service/service.go
+//
+// Каркас запуска сервисов. Run управляет жизненным циклом: обработчики запуска, работа сервиса
+// до отмены ctx, обработчики остановки в обратном порядке. App (app.go) добавляет к нему общую
+// часть main: конфигурацию, логирование, трассировку, шину событий, сигналы и health-сервер.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"multiverse-core.io/shared/logging"
+)
+
+// ShutdownTimeout ограничивает остановку HTTP-серверов после отмены ctx.
+const ShutdownTimeout = 5 * time.Second
+
+// Service — долгоживущая часть процесса: Run работает до отмены ctx.
+// Возврат context.Canceled после отмены считается штатной остановкой.
+type Service interface {
+	Run(ctx context.Context) error
+}
+
+// Func превращает функцию в Service.
+type Func func(ctx context.Context) error
+
+// Run вызывает f.
+func (f Func) Run(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Background адаптирует сервисы с фоновым запуском: start запускает обработку и сразу возвращается,
+// stop (может быть nil) вызывается после отмены ctx, его ошибка становится ошибкой сервиса.
+func Background(start func(ctx context.Context) error, stop func() error) Service {
+	return Func(func(ctx context.Context) error {
+		if err := start(ctx); err != nil {
+			return err
+		}
+		<-ctx.Done()
+		if stop == nil {
+			return nil
+		}
+		return stop()
+	})
+}
+
+// HTTPServer обслуживает запросы server до отмены ctx, затем дожидается
+// завершения активных запросов не дольше ShutdownTimeout.
+func HTTPServer(server *http.Server) Service {
+	return Func(func(ctx context.Context) error {
+		errCh := make(chan error, 1)
+		go func() { errCh <- server.ListenAndServe() }()
+
+		select {
+		case err := <-errCh:
+			return err
+		case <-ctx.Done():
+		}
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			logging.Warnf("HTTP server shutdown on %s: %v", server.Addr, err)
+		}
+		if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	})
+}
+
+// Hooks — обработчики жизненного цикла Run.
+type Hooks struct {
+	// OnStart вызываются по порядку до запуска сервиса; ошибка отменяет запуск.
+	OnStart []func(ctx context.Context) error
+	// OnStop вызываются после остановки сервиса в обратном порядке регистрации.
+	OnStop []func()
+}
+
+// Run запускает сервис и блокируется до его остановки. Обработчики остановки вызываются
+// и при ошибке запуска, чтобы освободить уже созданные ресурсы.
+func Run(ctx context.Context, svc Service, hooks Hooks) error {
+	defer func() {
+		for i := len(hooks.OnStop) - 1; i >= 0; i-- {
+			hooks.OnStop[i]()
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	for _, start := range hooks.OnStart {
+		if err := start(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err := svc.Run(ctx); err != nil && !(errors.Is(err, context.Canceled) && ctx.Err() != nil) {
+		return err
+	}
+	return nil
+}
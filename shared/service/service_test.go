@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRunHooksOrder(t *testing.T) {
+	var calls []string
+	ctx, cancel := context.WithCancel(context.Background())
+
+	err := Run(ctx, Func(func(ctx context.Context) error {
+		calls = append(calls, "run")
+		cancel()
+		<-ctx.Done()
+		return ctx.Err()
+	}), Hooks{
+		OnStart: []func(context.Context) error{
+			func(context.Context) error { calls = append(calls, "start 1"); return nil },
+			func(context.Context) error { calls = append(calls, "start 2"); return nil },
+		},
+		OnStop: []func(){
+			func() { calls = append(calls, "stop 1") },
+			func() { calls = append(calls, "stop 2") },
+		},
+	})
+	if err != nil {
+		t.Fatalf("expected cancellation to be a clean stop, got %v", err)
+	}
+	want := []string{"start 1", "start 2", "run", "stop 2", "stop 1"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
+
+func TestRunStartFailure(t *testing.T) {
+	stopped := false
+	failure := errors.New("kafka unavailable")
+
+	err := Run(context.Background(), Func(func(context.Context) error {
+		t.Fatal("service must not run after a failed start hook")
+		return nil
+	}), Hooks{
+		OnStart: []func(context.Context) error{func(context.Context) error { return failure }},
+		OnStop:  []func(){func() { stopped = true }},
+	})
+	if !errors.Is(err, failure) || !stopped {
+		t.Errorf("expected the start error and stop hooks, got %v, stopped %v", err, stopped)
+	}
+}
+
+func TestBackgroundStopError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	started := false
+	failure := errors.New("flush failed")
+
+	svc := Background(func(context.Context) error {
+		started = true
+		cancel()
+		return nil
+	}, func() error { return failure })
+	if err := Run(ctx, svc, Hooks{}); !started || !errors.Is(err, failure) {
+		t.Errorf("expected the stop error after start, got %v, started %v", err, started)
+	}
+}
+
+func TestHTTPServerStopsOnCancel(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- HTTPServer(&http.Server{Addr: addr, Handler: http.NotFoundHandler()}).Run(ctx)
+	}()
+
+	// Ждём, пока сервер начнёт принимать соединения
+	for i := 0; i < 50; i++ {
+		if conn, err := net.Dial("tcp", addr); err == nil {
+			conn.Close()
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("expected a clean shutdown, got %v", err)
+		}
+	case <-time.After(ShutdownTimeout):
+		t.Fatal("server did not stop")
+	}
+}
+
+func TestHealthReadiness(t *testing.T) {
+	h := newHealth("plan-manager")
+	server := httptest.NewServer(h.handler())
+	defer server.Close()
+
+	get := func(path string) int {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if get("/health") != http.StatusOK || get("/ready") != http.StatusServiceUnavailable {
+		t.Errorf("expected live but not ready before the service runs")
+	}
+	h.setRunning(true)
+	if get("/ready") != http.StatusOK {
+		t.Errorf("expected ready while running")
+	}
+
+	h.addCheck("minio", func(context.Context) error { return errors.New("connection refused") })
+	report := h.report(context.Background())
+	if report.Ready || report.Status != StatusDegraded || report.Dependencies["minio"].Status != StatusDown {
+		t.Errorf("expected a failed check to degrade readiness, got %+v", report)
+	}
+	if get("/health") != http.StatusOK || get("/ready") != http.StatusServiceUnavailable {
+		t.Errorf("expected live but not ready with a failed dependency")
+	}
+}
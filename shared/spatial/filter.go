@@ -11,8 +11,14 @@ func (vs *VisibilityScope) IsInScope(p Point) bool {
 		return true
 	}
 	if vs.Polygon != nil {
+		if vs.Margin > 0 {
+			return vs.Polygon.Distance(p) <= vs.Margin
+		}
 		return vs.Polygon.Contains(p)
 	}
+	if vs.IsEmpty() {
+		return false
+	}
 	dx := p.X - vs.Center.X
 	dy := p.Y - vs.Center.Y
 	return dx*dx+dy*dy <= vs.Radius*vs.Radius
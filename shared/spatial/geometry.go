@@ -79,6 +79,84 @@ func (g *Geometry) MaxRadius() float64 {
 	}
 }
 
+// Contains проверяет, лежит ли точка внутри круга (граница включительно).
+func (c Circle) Contains(p Point) bool {
+	return DistanceBetween(c.Center, p) <= c.Radius
+}
+
+// Bounds возвращает ограничивающий прямоугольник круга.
+func (c Circle) Bounds() BoundingBox {
+	return BoundingBox{
+		Min: Point{X: c.Center.X - c.Radius, Y: c.Center.Y - c.Radius},
+		Max: Point{X: c.Center.X + c.Radius, Y: c.Center.Y + c.Radius},
+	}
+}
+
+// Contains проверяет, лежит ли точка внутри прямоугольника (граница включительно).
+func (b BoundingBox) Contains(p Point) bool {
+	return p.X >= b.Min.X && p.X <= b.Max.X && p.Y >= b.Min.Y && p.Y <= b.Max.Y
+}
+
+// Bounds возвращает сам прямоугольник (BoundingBox реализует Shape).
+func (b BoundingBox) Bounds() BoundingBox {
+	return b
+}
+
+// Intersects проверяет, пересекаются ли прямоугольники (касание считается пересечением).
+func (b BoundingBox) Intersects(other BoundingBox) bool {
+	return b.Min.X <= other.Max.X && other.Min.X <= b.Max.X && b.Min.Y <= other.Max.Y && other.Min.Y <= b.Max.Y
+}
+
+// Expand возвращает прямоугольник, расширенный на distance во все стороны.
+func (b BoundingBox) Expand(distance float64) BoundingBox {
+	return BoundingBox{
+		Min: Point{X: b.Min.X - distance, Y: b.Min.Y - distance},
+		Max: Point{X: b.Max.X + distance, Y: b.Max.Y + distance},
+	}
+}
+
+// IsInfinite — прямоугольник без конечных границ (например, область всего мира).
+func (b BoundingBox) IsInfinite() bool {
+	return math.IsInf(b.Min.X, 0) || math.IsInf(b.Min.Y, 0) || math.IsInf(b.Max.X, 0) || math.IsInf(b.Max.Y, 0)
+}
+
+// InfiniteBounds — прямоугольник, содержащий любую точку.
+func InfiniteBounds() BoundingBox {
+	return BoundingBox{Min: Point{X: math.Inf(-1), Y: math.Inf(-1)}, Max: Point{X: math.Inf(1), Y: math.Inf(1)}}
+}
+
+// Contains проверяет, лежит ли точка внутри геометрии. Точка содержит только саму себя.
+func (g *Geometry) Contains(p Point) bool {
+	switch {
+	case g.Point != nil:
+		return *g.Point == p
+	case g.Circle != nil:
+		return g.Circle.Contains(p)
+	case g.Polygon != nil:
+		return g.Polygon.Contains(p)
+	case g.BoundingBox != nil:
+		return g.BoundingBox.Contains(p)
+	default:
+		return false
+	}
+}
+
+// Bounds возвращает ограничивающий прямоугольник геометрии.
+func (g *Geometry) Bounds() BoundingBox {
+	switch {
+	case g.Point != nil:
+		return BoundingBox{Min: *g.Point, Max: *g.Point}
+	case g.Circle != nil:
+		return g.Circle.Bounds()
+	case g.Polygon != nil:
+		return g.Polygon.Bounds()
+	case g.BoundingBox != nil:
+		return *g.BoundingBox
+	default:
+		return BoundingBox{}
+	}
+}
+
 // centroid вычисляет центр масс полигона (упрощённо — среднее).
 func centroid(poly Polygon) Point {
 	if len(poly) == 0 {
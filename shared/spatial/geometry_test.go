@@ -0,0 +1,108 @@
+package spatial
+
+import "testing"
+
+// lShape — невыпуклый L-образный полигон: квадрат 0..10 без правого верхнего угла 5..10
+var lShape = Polygon{{0, 0}, {10, 0}, {10, 5}, {5, 5}, {5, 10}, {0, 10}}
+
+func TestPolygonContains(t *testing.T) {
+	cases := []struct {
+		p    Point
+		want bool
+	}{
+		{Point{2, 2}, true},
+		{Point{8, 2}, true},
+		{Point{2, 8}, true},
+		{Point{8, 8}, false}, // вырез
+		{Point{10, 2}, true}, // граница
+		{Point{5, 7}, true},  // внутренний угол
+		{Point{-1, 5}, false},
+	}
+	for _, c := range cases {
+		if got := lShape.Contains(c.p); got != c.want {
+			t.Errorf("Contains(%v) = %v, want %v", c.p, got, c.want)
+		}
+	}
+
+	triangle := Polygon{{0, 0}, {10, 0}, {0, 10}}
+	if !triangle.Contains(Point{2, 2}) || triangle.Contains(Point{6, 6}) {
+		t.Errorf("triangle containment is wrong")
+	}
+	if (&Polygon{{0, 0}, {1, 1}}).Contains(Point{0, 0}) {
+		t.Errorf("degenerate polygon must not contain points")
+	}
+}
+
+func TestPolygonDistance(t *testing.T) {
+	if d := lShape.Distance(Point{2, 2}); d != 0 {
+		t.Errorf("inside distance = %v, want 0", d)
+	}
+	if d := lShape.Distance(Point{8, 8}); d != 3 {
+		t.Errorf("distance from notch = %v, want 3", d)
+	}
+}
+
+func TestIntersects(t *testing.T) {
+	square := func(x, y, size float64) *Geometry {
+		return &Geometry{Polygon: &Polygon{{x, y}, {x + size, y}, {x + size, y + size}, {x, y + size}}}
+	}
+	circle := func(x, y, r float64) *Geometry {
+		return &Geometry{Circle: &Circle{Center: Point{x, y}, Radius: r}}
+	}
+	l := &Geometry{Polygon: &lShape}
+
+	cases := []struct {
+		name string
+		a, b *Geometry
+		want bool
+	}{
+		{"overlapping squares", square(0, 0, 10), square(5, 5, 10), true},
+		{"nested squares", square(0, 0, 10), square(2, 2, 2), true},
+		{"disjoint squares", square(0, 0, 10), square(20, 20, 5), false},
+		{"square in L notch", l, square(6, 6, 3), false},
+		{"circle crosses L edge", l, circle(7, 7, 2.5), true},
+		{"circle in L notch", l, circle(8, 8, 1), false},
+		{"circles touch", circle(0, 0, 1), circle(2, 0, 1), true},
+		{"point in box", &Geometry{Point: &Point{3, 3}}, &Geometry{BoundingBox: &BoundingBox{Max: Point{5, 5}}}, true},
+		{"box and L", &Geometry{BoundingBox: &BoundingBox{Min: Point{6, 6}, Max: Point{9, 9}}}, l, false},
+	}
+	for _, c := range cases {
+		if got := Intersects(c.a, c.b); got != c.want {
+			t.Errorf("%s: Intersects = %v, want %v", c.name, got, c.want)
+		}
+		if got := Intersects(c.b, c.a); got != c.want {
+			t.Errorf("%s (swapped): Intersects = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestDefaultScopePolygon(t *testing.T) {
+	region := &Geometry{Polygon: &lShape}
+	vs := DefaultScope("region", region, nil)
+	if vs.Polygon == nil || vs.Margin != 200 {
+		t.Fatalf("region scope must keep its polygon with margin, got %+v", vs)
+	}
+	if !vs.IsInScope(Point{8, 8}) || !vs.IsInScope(Point{210, 5}) || vs.IsInScope(Point{215, 5}) {
+		t.Errorf("polygon margin is wrong")
+	}
+
+	tight := VisibilityScope{Polygon: &lShape}
+	if tight.IsInScope(Point{8, 8}) {
+		t.Errorf("point in notch must be outside polygon without margin")
+	}
+	buffered := tight.Buffer(4)
+	if !buffered.IsInScope(Point{8, 8}) || buffered.Polygon != tight.Polygon {
+		t.Errorf("buffer must grow margin and keep the polygon")
+	}
+}
+
+func TestDefaultScopeWithoutGeometry(t *testing.T) {
+	vs := DefaultScope("location", nil, nil)
+	if !vs.IsEmpty() || vs.IsInScope(Point{0, 0}) {
+		t.Errorf("scope without geometry must be empty, got %+v", vs)
+	}
+	world := DefaultScope("world", nil, nil)
+	if !world.IsInScope(Point{1e6, -1e6}) || !world.Bounds().IsInfinite() {
+		t.Errorf("world scope must be infinite")
+	}
+}
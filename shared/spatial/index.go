@@ -0,0 +1,273 @@
+// pkg/spatial/index.go
+
+package spatial
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// DefaultCellSize — размер ячейки сетки индекса по умолчанию (в единицах координат мира).
+const DefaultCellSize = 100.0
+
+// maxCellsPerShape — области, занимающие больше ячеек, хранятся отдельно и проверяются при каждом запросе.
+const maxCellsPerShape = 4096
+
+// Shape — область, которую можно поместить в пространственный индекс.
+// Реализуют Circle, BoundingBox, *Polygon, *Geometry и *VisibilityScope.
+type Shape interface {
+	Bounds() BoundingBox
+	Contains(p Point) bool
+}
+
+type cellKey struct {
+	x, y int
+}
+
+type indexEntry struct {
+	shape Shape
+	cells []cellKey // nil — область в списке крупных
+}
+
+// Index — пространственный индекс на равномерной сетке: область регистрируется во всех
+// ячейках, которые покрывает её ограничивающий прямоугольник, а запрос проверяет только
+// кандидатов из ячеек точки или прямоугольника. Не потокобезопасен (см. WorldIndex).
+type Index struct {
+	cellSize float64
+	cells    map[cellKey]map[string]struct{}
+	entries  map[string]indexEntry
+	large    map[string]struct{} // бесконечные и слишком крупные области
+}
+
+// NewIndex создаёт индекс с ячейками размера cellSize (<= 0 — DefaultCellSize).
+func NewIndex(cellSize float64) *Index {
+	if cellSize <= 0 {
+		cellSize = DefaultCellSize
+	}
+	return &Index{
+		cellSize: cellSize,
+		cells:    make(map[cellKey]map[string]struct{}),
+		entries:  make(map[string]indexEntry),
+		large:    make(map[string]struct{}),
+	}
+}
+
+// Len возвращает число областей в индексе.
+func (ix *Index) Len() int {
+	return len(ix.entries)
+}
+
+// Insert добавляет или заменяет область id.
+func (ix *Index) Insert(id string, shape Shape) {
+	ix.Remove(id)
+
+	box := shape.Bounds()
+	cells, ok := ix.cellRange(box)
+	if !ok {
+		ix.large[id] = struct{}{}
+		ix.entries[id] = indexEntry{shape: shape}
+		return
+	}
+	for _, key := range cells {
+		bucket := ix.cells[key]
+		if bucket == nil {
+			bucket = make(map[string]struct{})
+			ix.cells[key] = bucket
+		}
+		bucket[id] = struct{}{}
+	}
+	ix.entries[id] = indexEntry{shape: shape, cells: cells}
+}
+
+// Remove удаляет область id (отсутствующая игнорируется).
+func (ix *Index) Remove(id string) {
+	entry, ok := ix.entries[id]
+	if !ok {
+		return
+	}
+	delete(ix.entries, id)
+	delete(ix.large, id)
+	for _, key := range entry.cells {
+		bucket := ix.cells[key]
+		delete(bucket, id)
+		if len(bucket) == 0 {
+			delete(ix.cells, key)
+		}
+	}
+}
+
+// Containing возвращает отсортированные ID областей, содержащих точку.
+func (ix *Index) Containing(p Point) []string {
+	var result []string
+	check := func(id string) {
+		if ix.entries[id].shape.Contains(p) {
+			result = append(result, id)
+		}
+	}
+	for id := range ix.cells[ix.cell(p)] {
+		check(id)
+	}
+	for id := range ix.large {
+		check(id)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Intersecting возвращает отсортированные ID областей, чей ограничивающий прямоугольник
+// пересекает box (запрос по диапазону).
+func (ix *Index) Intersecting(box BoundingBox) []string {
+	seen := make(map[string]struct{})
+	var result []string
+	check := func(id string) {
+		if _, dup := seen[id]; dup {
+			return
+		}
+		seen[id] = struct{}{}
+		if ix.entries[id].shape.Bounds().Intersects(box) {
+			result = append(result, id)
+		}
+	}
+	if cells, ok := ix.cellRange(box); ok {
+		for _, key := range cells {
+			for id := range ix.cells[key] {
+				check(id)
+			}
+		}
+	} else {
+		for id := range ix.entries {
+			check(id)
+		}
+	}
+	for id := range ix.large {
+		check(id)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Within возвращает отсортированные ID областей, содержащих хотя бы одну точку круга
+// с центром center и радиусом radius (проверка по ограничивающим прямоугольникам и расстоянию до центра).
+func (ix *Index) Within(center Point, radius float64) []string {
+	circle := Circle{Center: center, Radius: radius}
+	var result []string
+	for _, id := range ix.Intersecting(circle.Bounds()) {
+		shape := ix.entries[id].shape
+		if shape.Contains(center) || distanceToBox(center, shape.Bounds()) <= radius {
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+func (ix *Index) cell(p Point) cellKey {
+	return cellKey{x: int(math.Floor(p.X / ix.cellSize)), y: int(math.Floor(p.Y / ix.cellSize))}
+}
+
+// cellRange возвращает ячейки прямоугольника; false — прямоугольник бесконечен или слишком велик.
+func (ix *Index) cellRange(box BoundingBox) ([]cellKey, bool) {
+	if box.IsInfinite() || math.IsNaN(box.Min.X) || math.IsNaN(box.Min.Y) || math.IsNaN(box.Max.X) || math.IsNaN(box.Max.Y) {
+		return nil, false
+	}
+	minCell, maxCell := ix.cell(box.Min), ix.cell(box.Max)
+	width, height := maxCell.x-minCell.x+1, maxCell.y-minCell.y+1
+	if width <= 0 || height <= 0 || width*height > maxCellsPerShape {
+		return nil, false
+	}
+	cells := make([]cellKey, 0, width*height)
+	for x := minCell.x; x <= maxCell.x; x++ {
+		for y := minCell.y; y <= maxCell.y; y++ {
+			cells = append(cells, cellKey{x: x, y: y})
+		}
+	}
+	return cells, true
+}
+
+// distanceToBox — расстояние от точки до прямоугольника (0 — внутри).
+func distanceToBox(p Point, box BoundingBox) float64 {
+	dx := max(box.Min.X-p.X, 0, p.X-box.Max.X)
+	dy := max(box.Min.Y-p.Y, 0, p.Y-box.Max.Y)
+	return math.Hypot(dx, dy)
+}
+
+// WorldIndex — потокобезопасный набор индексов по мирам. Каждый ID принадлежит одному миру:
+// повторная вставка в другой мир переносит область.
+type WorldIndex struct {
+	mu       sync.RWMutex
+	cellSize float64
+	worlds   map[string]*Index
+	owners   map[string]string // ID области → мир
+}
+
+// NewWorldIndex создаёт индекс миров с ячейками размера cellSize (<= 0 — DefaultCellSize).
+func NewWorldIndex(cellSize float64) *WorldIndex {
+	return &WorldIndex{
+		cellSize: cellSize,
+		worlds:   make(map[string]*Index),
+		owners:   make(map[string]string),
+	}
+}
+
+// Insert добавляет или заменяет область id в мире worldID.
+func (w *WorldIndex) Insert(worldID, id string, shape Shape) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.removeLocked(id)
+	ix := w.worlds[worldID]
+	if ix == nil {
+		ix = NewIndex(w.cellSize)
+		w.worlds[worldID] = ix
+	}
+	ix.Insert(id, shape)
+	w.owners[id] = worldID
+}
+
+// Remove удаляет область id из её мира.
+func (w *WorldIndex) Remove(id string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.removeLocked(id)
+}
+
+func (w *WorldIndex) removeLocked(id string) {
+	worldID, ok := w.owners[id]
+	if !ok {
+		return
+	}
+	delete(w.owners, id)
+	ix := w.worlds[worldID]
+	ix.Remove(id)
+	if ix.Len() == 0 {
+		delete(w.worlds, worldID)
+	}
+}
+
+// Containing возвращает ID областей мира worldID, содержащих точку.
+// Пустой worldID — поиск по всем мирам.
+func (w *WorldIndex) Containing(worldID string, p Point) []string {
+	return w.query(worldID, func(ix *Index) []string { return ix.Containing(p) })
+}
+
+// Intersecting возвращает ID областей мира worldID, пересекающих прямоугольник box.
+// Пустой worldID — поиск по всем мирам.
+func (w *WorldIndex) Intersecting(worldID string, box BoundingBox) []string {
+	return w.query(worldID, func(ix *Index) []string { return ix.Intersecting(box) })
+}
+
+func (w *WorldIndex) query(worldID string, fn func(ix *Index) []string) []string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if worldID != "" {
+		if ix := w.worlds[worldID]; ix != nil {
+			return fn(ix)
+		}
+		return nil
+	}
+	var result []string
+	for _, ix := range w.worlds {
+		result = append(result, fn(ix)...)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,80 @@
+package spatial
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestIndexContaining(t *testing.T) {
+	ix := NewIndex(10)
+	ix.Insert("l", &lShape)
+	ix.Insert("circle", Circle{Center: Point{50, 50}, Radius: 15})
+	ix.Insert("box", BoundingBox{Min: Point{-100, -100}, Max: Point{100, 100}})
+	ix.Insert("world", &VisibilityScope{Radius: 1e9})
+
+	cases := []struct {
+		p    Point
+		want []string
+	}{
+		{Point{2, 2}, []string{"box", "l", "world"}},
+		{Point{8, 8}, []string{"box", "world"}},
+		{Point{60, 55}, []string{"box", "circle", "world"}},
+		{Point{500, 500}, []string{"world"}},
+	}
+	for _, c := range cases {
+		if got := ix.Containing(c.p); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Containing(%v) = %v, want %v", c.p, got, c.want)
+		}
+	}
+
+	// Замена и удаление
+	ix.Insert("circle", Circle{Center: Point{500, 500}, Radius: 1})
+	if got := ix.Containing(Point{500, 500}); !reflect.DeepEqual(got, []string{"circle", "world"}) {
+		t.Errorf("moved circle not found: %v", got)
+	}
+	ix.Remove("circle")
+	ix.Remove("missing")
+	if ix.Len() != 3 || len(ix.Containing(Point{500, 500})) != 1 {
+		t.Errorf("remove failed: len=%d", ix.Len())
+	}
+}
+
+func TestIndexRangeQueries(t *testing.T) {
+	ix := NewIndex(10)
+	for i := 0; i < 10; i++ {
+		ix.Insert(fmt.Sprintf("c%d", i), Circle{Center: Point{float64(i * 20), 0}, Radius: 5})
+	}
+
+	got := ix.Intersecting(BoundingBox{Min: Point{30, -1}, Max: Point{62, 1}})
+	if want := []string{"c2", "c3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Intersecting = %v, want %v", got, want)
+	}
+	got = ix.Within(Point{100, 10}, 6)
+	if want := []string{"c5"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Within = %v, want %v", got, want)
+	}
+}
+
+func TestWorldIndex(t *testing.T) {
+	w := NewWorldIndex(0)
+	w.Insert("world-a", "city-1", Circle{Center: Point{0, 0}, Radius: 10})
+	w.Insert("world-b", "city-2", Circle{Center: Point{0, 0}, Radius: 10})
+
+	if got := w.Containing("world-a", Point{1, 1}); !reflect.DeepEqual(got, []string{"city-1"}) {
+		t.Errorf("world-a = %v", got)
+	}
+	if got := w.Containing("", Point{1, 1}); !reflect.DeepEqual(got, []string{"city-1", "city-2"}) {
+		t.Errorf("all worlds = %v", got)
+	}
+
+	// Повторная вставка в другой мир переносит область
+	w.Insert("world-b", "city-1", Circle{Center: Point{0, 0}, Radius: 10})
+	if got := w.Containing("world-a", Point{1, 1}); len(got) != 0 {
+		t.Errorf("city-1 must leave world-a, got %v", got)
+	}
+	w.Remove("city-1")
+	if got := w.Containing("world-b", Point{1, 1}); !reflect.DeepEqual(got, []string{"city-2"}) {
+		t.Errorf("world-b = %v", got)
+	}
+}
@@ -0,0 +1,80 @@
+// pkg/spatial/intersect.go
+
+package spatial
+
+// Intersects проверяет, пересекаются ли две геометрии (касание считается пересечением).
+// Прямоугольники обрабатываются как полигоны, пустая геометрия ни с чем не пересекается.
+func Intersects(a, b *Geometry) bool {
+	if a == nil || b == nil || !a.Bounds().Intersects(b.Bounds()) {
+		return false
+	}
+	switch {
+	case a.Point != nil:
+		return b.Contains(*a.Point)
+	case b.Point != nil:
+		return a.Contains(*b.Point)
+	case a.Circle != nil && b.Circle != nil:
+		return DistanceBetween(a.Circle.Center, b.Circle.Center) <= a.Circle.Radius+b.Circle.Radius
+	case a.Circle != nil:
+		poly, ok := b.polygon()
+		return ok && circleIntersectsPolygon(*a.Circle, poly)
+	case b.Circle != nil:
+		poly, ok := a.polygon()
+		return ok && circleIntersectsPolygon(*b.Circle, poly)
+	}
+	pa, okA := a.polygon()
+	pb, okB := b.polygon()
+	return okA && okB && polygonsIntersect(pa, pb)
+}
+
+// polygon возвращает полигон или прямоугольник геометрии в виде полигона.
+func (g *Geometry) polygon() (Polygon, bool) {
+	switch {
+	case g.Polygon != nil && len(*g.Polygon) >= 3:
+		return *g.Polygon, true
+	case g.BoundingBox != nil:
+		b := g.BoundingBox
+		return Polygon{b.Min, {X: b.Max.X, Y: b.Min.Y}, b.Max, {X: b.Min.X, Y: b.Max.Y}}, true
+	default:
+		return nil, false
+	}
+}
+
+func circleIntersectsPolygon(c Circle, poly Polygon) bool {
+	return poly.Distance(c.Center) <= c.Radius
+}
+
+// polygonsIntersect — пересекаются рёбра или один полигон целиком внутри другого.
+func polygonsIntersect(a, b Polygon) bool {
+	for i, j := 0, len(a)-1; i < len(a); j, i = i, i+1 {
+		for k, l := 0, len(b)-1; k < len(b); l, k = k, k+1 {
+			if segmentsIntersect(a[j], a[i], b[l], b[k]) {
+				return true
+			}
+		}
+	}
+	return a.Contains(b[0]) || b.Contains(a[0])
+}
+
+// segmentsIntersect проверяет пересечение отрезков pq и rs (включая касание и наложение).
+func segmentsIntersect(p, q, r, s Point) bool {
+	d1 := orientation(r, s, p)
+	d2 := orientation(r, s, q)
+	d3 := orientation(p, q, r)
+	d4 := orientation(p, q, s)
+	if ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0)) {
+		return true
+	}
+	return (d1 == 0 && onSegment(r, s, p)) || (d2 == 0 && onSegment(r, s, q)) ||
+		(d3 == 0 && onSegment(p, q, r)) || (d4 == 0 && onSegment(p, q, s))
+}
+
+// orientation — знак векторного произведения (b-a)×(c-a).
+func orientation(a, b, c Point) float64 {
+	return (b.X-a.X)*(c.Y-a.Y) - (b.Y-a.Y)*(c.X-a.X)
+}
+
+// onSegment — точка c, коллинеарная ab, лежит в пределах отрезка.
+func onSegment(a, b, c Point) bool {
+	return c.X >= min(a.X, b.X) && c.X <= max(a.X, b.X) && c.Y >= min(a.Y, b.Y) && c.Y <= max(a.Y, b.Y)
+}
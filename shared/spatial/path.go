@@ -0,0 +1,266 @@
+// pkg/spatial/path.go
+
+package spatial
+
+import (
+	"container/heap"
+	"errors"
+	"math"
+	"time"
+)
+
+// Ошибки поиска пути
+var (
+	// ErrOutsideGrid — начало или конец маршрута вне сетки проходимости
+	ErrOutsideGrid = errors.New("point is outside the navigation grid")
+	// ErrNoPath — между точками нет проходимого пути
+	ErrNoPath = errors.New("no path between points")
+)
+
+// maxGridCells ограничивает размер сетки проходимости (при превышении ячейки укрупняются).
+const maxGridCells = 512 * 512
+
+// NavGrid — сетка проходимости над географией мира. Стоимость ячейки — множитель длины пути
+// через неё (1 — равнина, больше — труднопроходимая местность, +Inf — непроходимая).
+type NavGrid struct {
+	Origin   Point   // угол ячейки (0, 0)
+	CellSize float64 // размер ячейки в единицах координат мира
+	Width    int
+	Height   int
+
+	costs   []float64 // построчно: y*Width+x
+	minCost float64   // минимальная конечная стоимость — для допустимой эвристики A*
+}
+
+// NewNavGrid создаёт сетку над прямоугольником bounds с ячейками размера cellSize
+// (<= 0 — 1) и стоимостью 1 во всех ячейках.
+func NewNavGrid(bounds BoundingBox, cellSize float64) *NavGrid {
+	if cellSize <= 0 {
+		cellSize = 1
+	}
+	width := max(int(math.Ceil((bounds.Max.X-bounds.Min.X)/cellSize)), 1)
+	height := max(int(math.Ceil((bounds.Max.Y-bounds.Min.Y)/cellSize)), 1)
+	if width*height > maxGridCells {
+		scale := math.Sqrt(float64(width*height) / maxGridCells)
+		cellSize *= scale
+		width = max(int(math.Ceil((bounds.Max.X-bounds.Min.X)/cellSize)), 1)
+		height = max(int(math.Ceil((bounds.Max.Y-bounds.Min.Y)/cellSize)), 1)
+	}
+
+	costs := make([]float64, width*height)
+	for i := range costs {
+		costs[i] = 1
+	}
+	return &NavGrid{Origin: bounds.Min, CellSize: cellSize, Width: width, Height: height, costs: costs, minCost: 1}
+}
+
+// SetCost задаёт стоимость ячеек, центры которых лежат в области shape.
+// Области, заданные позже, перекрывают ранее заданные.
+func (g *NavGrid) SetCost(shape Shape, cost float64) {
+	if math.IsNaN(cost) || cost <= 0 {
+		return
+	}
+	box := shape.Bounds()
+	minX, minY := g.cellOf(box.Min)
+	maxX, maxY := g.cellOf(box.Max)
+	for y := max(minY, 0); y <= min(maxY, g.Height-1); y++ {
+		for x := max(minX, 0); x <= min(maxX, g.Width-1); x++ {
+			if shape.Contains(g.center(x, y)) {
+				g.costs[y*g.Width+x] = cost
+			}
+		}
+	}
+	if !math.IsInf(cost, 1) && cost < g.minCost {
+		g.minCost = cost
+	}
+}
+
+// Cost возвращает стоимость ячейки точки; false — точка вне сетки.
+func (g *NavGrid) Cost(p Point) (float64, bool) {
+	x, y := g.cellOf(p)
+	if !g.inside(x, y) {
+		return 0, false
+	}
+	return g.costs[y*g.Width+x], true
+}
+
+// Route — маршрут между двумя точками.
+type Route struct {
+	Waypoints []Point // начало, повороты и конец маршрута
+	Distance  float64 // геометрическая длина маршрута
+	Cost      float64 // длина с учётом стоимости местности («эффективное» расстояние)
+}
+
+// FindPath ищет кратчайший по стоимости путь A* между точками (8 направлений,
+// без срезания углов непроходимых ячеек).
+func (g *NavGrid) FindPath(from, to Point) (*Route, error) {
+	sx, sy := g.cellOf(from)
+	tx, ty := g.cellOf(to)
+	if !g.inside(sx, sy) || !g.inside(tx, ty) {
+		return nil, ErrOutsideGrid
+	}
+	start, goal := sy*g.Width+sx, ty*g.Width+tx
+	if math.IsInf(g.costs[start], 1) || math.IsInf(g.costs[goal], 1) {
+		return nil, ErrNoPath
+	}
+
+	cameFrom := make(map[int]int)
+	best := map[int]float64{start: 0}
+	open := &pathQueue{{cell: start, priority: g.heuristic(start, goal)}}
+	closed := make(map[int]bool)
+
+	for open.Len() > 0 {
+		current := heap.Pop(open).(pathNode).cell
+		if current == goal {
+			return g.buildRoute(from, to, cameFrom, start, goal), nil
+		}
+		if closed[current] {
+			continue
+		}
+		closed[current] = true
+
+		cx, cy := current%g.Width, current/g.Width
+		for _, d := range pathDirections {
+			nx, ny := cx+d[0], cy+d[1]
+			if !g.inside(nx, ny) {
+				continue
+			}
+			next := ny*g.Width + nx
+			if closed[next] || math.IsInf(g.costs[next], 1) {
+				continue
+			}
+			// Диагональ допустима, только если обе соседние ячейки проходимы
+			if d[0] != 0 && d[1] != 0 &&
+				(math.IsInf(g.costs[cy*g.Width+nx], 1) || math.IsInf(g.costs[ny*g.Width+cx], 1)) {
+				continue
+			}
+			step := math.Hypot(float64(d[0]), float64(d[1])) * g.CellSize * (g.costs[current] + g.costs[next]) / 2
+			cost := best[current] + step
+			if known, ok := best[next]; ok && known <= cost {
+				continue
+			}
+			best[next] = cost
+			cameFrom[next] = current
+			heap.Push(open, pathNode{cell: next, priority: cost + g.heuristic(next, goal)})
+		}
+	}
+	return nil, ErrNoPath
+}
+
+// buildRoute восстанавливает путь по ячейкам и оставляет только точки поворота.
+func (g *NavGrid) buildRoute(from, to Point, cameFrom map[int]int, start, goal int) *Route {
+	var cells []int
+	for cell := goal; cell != start; cell = cameFrom[cell] {
+		cells = append(cells, cell)
+	}
+	cells = append(cells, start)
+
+	waypoints := []Point{from}
+	for i := len(cells) - 2; i >= 1; i-- {
+		px, py := cells[i+1]%g.Width, cells[i+1]/g.Width
+		cx, cy := cells[i]%g.Width, cells[i]/g.Width
+		nx, ny := cells[i-1]%g.Width, cells[i-1]/g.Width
+		if cx-px != nx-cx || cy-py != ny-cy {
+			waypoints = append(waypoints, g.center(cx, cy))
+		}
+	}
+	waypoints = append(waypoints, to)
+
+	route := &Route{Waypoints: waypoints}
+	for i := 1; i < len(waypoints); i++ {
+		length := DistanceBetween(waypoints[i-1], waypoints[i])
+		route.Distance += length
+		route.Cost += length * g.segmentCost(waypoints[i-1], waypoints[i])
+	}
+	return route
+}
+
+// segmentCost — средняя стоимость ячеек вдоль отрезка (по точкам через половину ячейки).
+func (g *NavGrid) segmentCost(a, b Point) float64 {
+	steps := max(int(math.Ceil(DistanceBetween(a, b)/(g.CellSize/2))), 1)
+	total, count := 0.0, 0
+	for i := 0; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		if cost, ok := g.Cost(Point{X: a.X + (b.X-a.X)*t, Y: a.Y + (b.Y-a.Y)*t}); ok && !math.IsInf(cost, 1) {
+			total += cost
+			count++
+		}
+	}
+	if count == 0 {
+		return 1
+	}
+	return total / float64(count)
+}
+
+func (g *NavGrid) heuristic(cell, goal int) float64 {
+	dx := float64(cell%g.Width - goal%g.Width)
+	dy := float64(cell/g.Width - goal/g.Width)
+	return math.Hypot(dx, dy) * g.CellSize * g.minCost
+}
+
+func (g *NavGrid) cellOf(p Point) (int, int) {
+	return int(math.Floor((p.X - g.Origin.X) / g.CellSize)), int(math.Floor((p.Y - g.Origin.Y) / g.CellSize))
+}
+
+func (g *NavGrid) center(x, y int) Point {
+	return Point{X: g.Origin.X + (float64(x)+0.5)*g.CellSize, Y: g.Origin.Y + (float64(y)+0.5)*g.CellSize}
+}
+
+func (g *NavGrid) inside(x, y int) bool {
+	return x >= 0 && y >= 0 && x < g.Width && y < g.Height
+}
+
+// PointAt возвращает точку маршрута после прохождения доли fraction (0–1) его длины.
+func (r *Route) PointAt(fraction float64) Point {
+	if len(r.Waypoints) == 0 {
+		return Point{}
+	}
+	if fraction <= 0 || r.Distance == 0 {
+		return r.Waypoints[0]
+	}
+	if fraction >= 1 {
+		return r.Waypoints[len(r.Waypoints)-1]
+	}
+	remaining := fraction * r.Distance
+	for i := 1; i < len(r.Waypoints); i++ {
+		a, b := r.Waypoints[i-1], r.Waypoints[i]
+		length := DistanceBetween(a, b)
+		if remaining <= length {
+			t := remaining / length
+			return Point{X: a.X + (b.X-a.X)*t, Y: a.Y + (b.Y-a.Y)*t}
+		}
+		remaining -= length
+	}
+	return r.Waypoints[len(r.Waypoints)-1]
+}
+
+// TravelTime возвращает длительность маршрута в игровом времени при скорости speed
+// (единиц координат в игровой час) с учётом стоимости местности.
+func (r *Route) TravelTime(speed float64) time.Duration {
+	if speed <= 0 {
+		return 0
+	}
+	return time.Duration(r.Cost / speed * float64(time.Hour))
+}
+
+// pathDirections — 8 направлений соседних ячеек
+var pathDirections = [8][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}, {1, 1}, {1, -1}, {-1, 1}, {-1, -1}}
+
+type pathNode struct {
+	cell     int
+	priority float64
+}
+
+// pathQueue — очередь с приоритетом для A* (container/heap)
+type pathQueue []pathNode
+
+func (q pathQueue) Len() int            { return len(q) }
+func (q pathQueue) Less(i, j int) bool  { return q[i].priority < q[j].priority }
+func (q pathQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *pathQueue) Push(x interface{}) { *q = append(*q, x.(pathNode)) }
+func (q *pathQueue) Pop() interface{} {
+	old := *q
+	node := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return node
+}
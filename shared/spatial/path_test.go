@@ -0,0 +1,67 @@
+package spatial
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestFindPathStraight(t *testing.T) {
+	g := NewNavGrid(BoundingBox{Max: Point{10, 10}}, 1)
+	route, err := g.FindPath(Point{0.5, 0.5}, Point{9.5, 0.5})
+	if err != nil {
+		t.Fatalf("FindPath: %v", err)
+	}
+	if len(route.Waypoints) != 2 || route.Distance != 9 || route.Cost != 9 {
+		t.Errorf("straight route = %+v", route)
+	}
+	if got := route.TravelTime(4.5); got != 2*time.Hour {
+		t.Errorf("TravelTime = %v, want 2h", got)
+	}
+	if p := route.PointAt(0.5); p != (Point{5, 0.5}) {
+		t.Errorf("PointAt(0.5) = %v", p)
+	}
+}
+
+func TestFindPathAroundObstacle(t *testing.T) {
+	g := NewNavGrid(BoundingBox{Max: Point{20, 20}}, 1)
+	// Стена x=10 от y=0 до y=15 с проходом сверху
+	g.SetCost(BoundingBox{Min: Point{10, 0}, Max: Point{11, 15}}, math.Inf(1))
+
+	route, err := g.FindPath(Point{5.5, 5.5}, Point{15.5, 5.5})
+	if err != nil {
+		t.Fatalf("FindPath: %v", err)
+	}
+	if route.Distance <= 10 || len(route.Waypoints) < 3 {
+		t.Errorf("route must go around the wall, got %+v", route)
+	}
+	for _, p := range route.Waypoints {
+		if cost, _ := g.Cost(p); math.IsInf(cost, 1) {
+			t.Errorf("waypoint %v lies in the wall", p)
+		}
+	}
+
+	// Полностью перекрытый проход
+	g.SetCost(BoundingBox{Min: Point{10, 0}, Max: Point{11, 20}}, math.Inf(1))
+	if _, err := g.FindPath(Point{5.5, 5.5}, Point{15.5, 5.5}); !errors.Is(err, ErrNoPath) {
+		t.Errorf("expected ErrNoPath, got %v", err)
+	}
+	if _, err := g.FindPath(Point{5.5, 5.5}, Point{25, 5}); !errors.Is(err, ErrOutsideGrid) {
+		t.Errorf("expected ErrOutsideGrid, got %v", err)
+	}
+}
+
+func TestFindPathPrefersCheapTerrain(t *testing.T) {
+	g := NewNavGrid(BoundingBox{Max: Point{20, 20}}, 1)
+	// Горы на прямой линии: путь в обход короче по стоимости
+	g.SetCost(Circle{Center: Point{10, 10}, Radius: 4}, 5)
+
+	route, err := g.FindPath(Point{2.5, 10.5}, Point{17.5, 10.5})
+	if err != nil {
+		t.Fatalf("FindPath: %v", err)
+	}
+	if route.Cost >= 15*3 || route.Distance <= 15 {
+		t.Errorf("route must avoid mountains, got distance=%.1f cost=%.1f", route.Distance, route.Cost)
+	}
+}
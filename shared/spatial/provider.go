@@ -8,28 +8,140 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 )
 
+// DefaultGeometryTTL — время жизни геометрии в кэше SemanticMemoryProvider.
+const DefaultGeometryTTL = time.Minute
+
+// maxGeometryBatch — наибольшее число сущностей в одном запросе GetGeometries.
+const maxGeometryBatch = 100
+
 // GeometryProvider интерфейс для получения геометрии.
 type GeometryProvider interface {
 	GetGeometry(ctx context.Context, worldID, entityID string) (*Geometry, error)
 }
 
+// BatchGeometryProvider получает геометрии нескольких сущностей мира одним обращением.
+// В результате только найденные сущности с геометрией.
+type BatchGeometryProvider interface {
+	GeometryProvider
+	GetGeometries(ctx context.Context, worldID string, entityIDs []string) (map[string]*Geometry, error)
+}
+
+// cachedGeometry — геометрия в кэше провайдера.
+type cachedGeometry struct {
+	geometry  *Geometry
+	expiresAt time.Time
+}
+
+// geometryCall — запрос геометрии, который ждут одновременные вызовы GetGeometry.
+type geometryCall struct {
+	done     chan struct{}
+	geometry *Geometry
+	err      error
+}
+
 // SemanticMemoryProvider реализует получение через HTTP.
+// Геометрии кэшируются на TTL; одновременные запросы одной сущности объединяются в один.
 type SemanticMemoryProvider struct {
 	BaseURL string
 	Client  *http.Client
+	// TTL — время жизни геометрии в кэше (0 — DefaultGeometryTTL, отрицательное — без кэша)
+	TTL time.Duration
+
+	mu       sync.Mutex
+	cache    map[string]cachedGeometry
+	inflight map[string]*geometryCall
+	now      func() time.Time
 }
 
 func NewSemanticMemoryProvider(baseURL string) *SemanticMemoryProvider {
 	return &SemanticMemoryProvider{
 		BaseURL: baseURL,
 		Client:  &http.Client{Timeout: 5 * time.Second},
+		TTL:     DefaultGeometryTTL,
+	}
+}
+
+func geometryKey(worldID, entityID string) string {
+	return worldID + "/" + entityID
+}
+
+func (p *SemanticMemoryProvider) ttl() time.Duration {
+	if p.TTL == 0 {
+		return DefaultGeometryTTL
+	}
+	return p.TTL
+}
+
+func (p *SemanticMemoryProvider) clock() time.Time {
+	if p.now != nil {
+		return p.now()
+	}
+	return time.Now()
+}
+
+// cachedLocked возвращает неустаревшую геометрию из кэша; вызывается под мьютексом.
+func (p *SemanticMemoryProvider) cachedLocked(key string) (*Geometry, bool) {
+	cached, ok := p.cache[key]
+	if !ok || !p.clock().Before(cached.expiresAt) {
+		return nil, false
 	}
+	return cached.geometry, true
 }
 
+// storeLocked кладёт геометрию в кэш; вызывается под мьютексом.
+func (p *SemanticMemoryProvider) storeLocked(key string, geometry *Geometry) {
+	if p.ttl() < 0 {
+		return
+	}
+	if p.cache == nil {
+		p.cache = make(map[string]cachedGeometry)
+	}
+	p.cache[key] = cachedGeometry{geometry: geometry, expiresAt: p.clock().Add(p.ttl())}
+}
+
+// GetGeometry возвращает геометрию сущности из кэша или из SemanticMemory.
+// Ошибки не кэшируются.
 func (p *SemanticMemoryProvider) GetGeometry(ctx context.Context, worldID, entityID string) (*Geometry, error) {
+	key := geometryKey(worldID, entityID)
+
+	p.mu.Lock()
+	if geometry, ok := p.cachedLocked(key); ok {
+		p.mu.Unlock()
+		return geometry, nil
+	}
+	if call, ok := p.inflight[key]; ok {
+		p.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.geometry, call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	call := &geometryCall{done: make(chan struct{})}
+	if p.inflight == nil {
+		p.inflight = make(map[string]*geometryCall)
+	}
+	p.inflight[key] = call
+	p.mu.Unlock()
+
+	call.geometry, call.err = p.fetchGeometry(ctx, entityID)
+
+	p.mu.Lock()
+	delete(p.inflight, key)
+	if call.err == nil {
+		p.storeLocked(key, call.geometry)
+	}
+	p.mu.Unlock()
+	close(call.done)
+	return call.geometry, call.err
+}
+
+func (p *SemanticMemoryProvider) fetchGeometry(ctx context.Context, entityID string) (*Geometry, error) {
 	reqBody, _ := json.Marshal(map[string]interface{}{
 		"entity_id": entityID,
 		"fields":    []string{"geometry"},
@@ -58,6 +170,130 @@ func (p *SemanticMemoryProvider) GetGeometry(ctx context.Context, worldID, entit
 	return &result.Geometry, nil
 }
 
+// GetGeometries возвращает геометрии сущностей мира: закэшированные — из кэша,
+// остальные — запросами POST /v1/entities/query по maxGeometryBatch сущностей.
+func (p *SemanticMemoryProvider) GetGeometries(ctx context.Context, worldID string, entityIDs []string) (map[string]*Geometry, error) {
+	result := make(map[string]*Geometry, len(entityIDs))
+	var missing []string
+	seen := make(map[string]bool, len(entityIDs))
+
+	p.mu.Lock()
+	for _, id := range entityIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if geometry, ok := p.cachedLocked(geometryKey(worldID, id)); ok {
+			result[id] = geometry
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	p.mu.Unlock()
+
+	fetched, err := p.Refresh(ctx, worldID, missing)
+	for id, geometry := range fetched {
+		result[id] = geometry
+	}
+	return result, err
+}
+
+// Refresh запрашивает геометрии сущностей мира мимо кэша и обновляет кэш.
+// Сущности без геометрии удаляются из кэша. При ошибке возвращает уже полученные геометрии.
+func (p *SemanticMemoryProvider) Refresh(ctx context.Context, worldID string, entityIDs []string) (map[string]*Geometry, error) {
+	result := make(map[string]*Geometry, len(entityIDs))
+	for start := 0; start < len(entityIDs); start += maxGeometryBatch {
+		batch := entityIDs[start:min(start+maxGeometryBatch, len(entityIDs))]
+		fetched, err := p.fetchGeometries(ctx, worldID, batch)
+		if err != nil {
+			return result, err
+		}
+
+		p.mu.Lock()
+		for _, id := range batch {
+			key := geometryKey(worldID, id)
+			if geometry, ok := fetched[id]; ok {
+				result[id] = geometry
+				p.storeLocked(key, geometry)
+			} else {
+				delete(p.cache, key)
+			}
+		}
+		p.mu.Unlock()
+	}
+	p.pruneExpired()
+	return result, nil
+}
+
+// pruneExpired удаляет устаревшие геометрии, например сущностей удалённых ГМ.
+func (p *SemanticMemoryProvider) pruneExpired() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.clock()
+	for key, cached := range p.cache {
+		if !now.Before(cached.expiresAt) {
+			delete(p.cache, key)
+		}
+	}
+}
+
+// Invalidate удаляет геометрию сущности из кэша.
+func (p *SemanticMemoryProvider) Invalidate(worldID, entityID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.cache, geometryKey(worldID, entityID))
+}
+
+// fetchGeometries запрашивает сущности мира в SemanticMemory. Геометрия берётся из payload.geometry,
+// а при её отсутствии — точка из координат сущности.
+func (p *SemanticMemoryProvider) fetchGeometries(ctx context.Context, worldID string, entityIDs []string) (map[string]*Geometry, error) {
+	reqBody, _ := json.Marshal(map[string]interface{}{
+		"ids":      entityIDs,
+		"world_id": worldID,
+		"limit":    len(entityIDs),
+	})
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.BaseURL+"/v1/entities/query", bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Entities []struct {
+			ID      string `json:"id"`
+			Payload struct {
+				Geometry *Geometry `json:"geometry"`
+			} `json:"payload"`
+			Coordinates *Point `json:"coordinates"`
+		} `json:"entities"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	geometries := make(map[string]*Geometry, len(result.Entities))
+	for _, ent := range result.Entities {
+		switch {
+		case ent.Payload.Geometry != nil:
+			geometries[ent.ID] = ent.Payload.Geometry
+		case ent.Coordinates != nil:
+			geometries[ent.ID] = &Geometry{Point: ent.Coordinates}
+		}
+	}
+	return geometries, nil
+}
+
 // StaticProvider для тестов и простых сценариев.
 type StaticProvider map[string]*Geometry
 
@@ -67,3 +303,13 @@ func (sp StaticProvider) GetGeometry(_ context.Context, _, entityID string) (*Ge
 	}
 	return nil, fmt.Errorf("geometry not found: %s", entityID)
 }
+
+func (sp StaticProvider) GetGeometries(_ context.Context, _ string, entityIDs []string) (map[string]*Geometry, error) {
+	result := make(map[string]*Geometry, len(entityIDs))
+	for _, id := range entityIDs {
+		if g, ok := sp[id]; ok {
+			result[id] = g
+		}
+	}
+	return result, nil
+}
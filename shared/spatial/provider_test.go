@@ -0,0 +1,213 @@
+package spatial
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeSemanticMemory отвечает на POST /v1/entity/{id} и POST /v1/entities/query геометриями сущностей
+type fakeSemanticMemory struct {
+	single, batch atomic.Int32
+	release       chan struct{} // если задан, одиночные запросы ждут его
+	mu            sync.Mutex
+	entities      map[string]map[string]interface{}
+	queried       [][]string
+}
+
+func (f *fakeSemanticMemory) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.URL.Path == "/v1/entities/query" {
+		f.batch.Add(1)
+		var q struct {
+			IDs     []string `json:"ids"`
+			WorldID string   `json:"world_id"`
+		}
+		json.NewDecoder(r.Body).Decode(&q)
+		f.queried = append(f.queried, q.IDs)
+		found := []interface{}{}
+		for _, id := range q.IDs {
+			if ent, ok := f.entities[id]; ok {
+				found = append(found, ent)
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"entities": found})
+		return
+	}
+
+	f.single.Add(1)
+	if f.release != nil {
+		f.mu.Unlock()
+		<-f.release
+		f.mu.Lock()
+	}
+	id := r.URL.Path[len("/v1/entity/"):]
+	ent, ok := f.entities[id]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	payload, _ := ent["payload"].(map[string]interface{})
+	json.NewEncoder(w).Encode(map[string]interface{}{"geometry": payload["geometry"]})
+}
+
+func newTestProvider(t *testing.T, sm *fakeSemanticMemory) (*SemanticMemoryProvider, *time.Time) {
+	server := httptest.NewServer(sm)
+	t.Cleanup(server.Close)
+	now := time.Now()
+	provider := NewSemanticMemoryProvider(server.URL)
+	provider.now = func() time.Time { return now }
+	return provider, &now
+}
+
+func circleEntity(id string, x, radius float64) map[string]interface{} {
+	return map[string]interface{}{
+		"id": id,
+		"payload": map[string]interface{}{
+			"geometry": map[string]interface{}{"circle": map[string]interface{}{"center": map[string]interface{}{"x": x, "y": 0}, "radius": radius}},
+		},
+	}
+}
+
+func TestSemanticMemoryProviderCache(t *testing.T) {
+	sm := &fakeSemanticMemory{entities: map[string]map[string]interface{}{"city-1": circleEntity("city-1", 10, 5)}}
+	provider, now := newTestProvider(t, sm)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		geometry, err := provider.GetGeometry(ctx, "world-1", "city-1")
+		if err != nil || geometry.Circle == nil || geometry.Circle.Radius != 5 {
+			t.Fatalf("unexpected geometry %+v (%v)", geometry, err)
+		}
+	}
+	if n := sm.single.Load(); n != 1 {
+		t.Errorf("expected 1 request for cached geometry, got %d", n)
+	}
+
+	// Ошибки не кэшируются
+	if _, err := provider.GetGeometry(ctx, "world-1", "city-2"); err == nil {
+		t.Fatal("expected an error for unknown entity")
+	}
+	provider.GetGeometry(ctx, "world-1", "city-2")
+	if n := sm.single.Load(); n != 3 {
+		t.Errorf("expected failed lookups to be repeated, got %d requests", n)
+	}
+
+	*now = now.Add(DefaultGeometryTTL)
+	provider.GetGeometry(ctx, "world-1", "city-1")
+	if n := sm.single.Load(); n != 4 {
+		t.Errorf("expected expired geometry to be requested again, got %d requests", n)
+	}
+
+	provider.Invalidate("world-1", "city-1")
+	provider.GetGeometry(ctx, "world-1", "city-1")
+	if n := sm.single.Load(); n != 5 {
+		t.Errorf("expected invalidated geometry to be requested again, got %d requests", n)
+	}
+}
+
+func TestSemanticMemoryProviderSingleFlight(t *testing.T) {
+	sm := &fakeSemanticMemory{
+		entities: map[string]map[string]interface{}{"city-1": circleEntity("city-1", 10, 5)},
+		release:  make(chan struct{}),
+	}
+	provider, _ := newTestProvider(t, sm)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if geometry, err := provider.GetGeometry(context.Background(), "world-1", "city-1"); err != nil || geometry.Circle == nil {
+				t.Errorf("unexpected geometry %+v (%v)", geometry, err)
+			}
+		}()
+	}
+	// Ждём, пока первый запрос дойдёт до сервера, а остальные встанут в ожидание
+	for sm.single.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(sm.release)
+	wg.Wait()
+
+	if n := sm.single.Load(); n != 1 {
+		t.Errorf("expected concurrent lookups to share 1 request, got %d", n)
+	}
+}
+
+func TestSemanticMemoryProviderGetGeometries(t *testing.T) {
+	sm := &fakeSemanticMemory{entities: map[string]map[string]interface{}{
+		"city-1":   circleEntity("city-1", 10, 5),
+		"player-1": {"id": "player-1", "coordinates": map[string]interface{}{"x": 3, "y": 4, "z": 0}},
+		"npc-1":    {"id": "npc-1", "payload": map[string]interface{}{"name": "Стражник"}},
+	}}
+	provider, _ := newTestProvider(t, sm)
+	ctx := context.Background()
+
+	provider.GetGeometry(ctx, "world-1", "city-1")
+	geometries, err := provider.GetGeometries(ctx, "world-1", []string{"city-1", "player-1", "npc-1", "player-1", "ghost"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(geometries) != 2 || geometries["city-1"].Circle == nil {
+		t.Fatalf("expected city-1 and player-1 geometries, got %+v", geometries)
+	}
+	if point := geometries["player-1"].Point; point == nil || point.X != 3 || point.Y != 4 {
+		t.Errorf("expected a point from coordinates, got %+v", geometries["player-1"])
+	}
+	if len(sm.queried) != 1 || len(sm.queried[0]) != 3 {
+		t.Fatalf("expected one batch of the 3 uncached entities, got %v", sm.queried)
+	}
+
+	// Полученные пакетом геометрии кэшируются
+	if _, err := provider.GetGeometries(ctx, "world-1", []string{"city-1", "player-1"}); err != nil || sm.batch.Load() != 1 {
+		t.Errorf("expected cached geometries without requests, got %d batches (%v)", sm.batch.Load(), err)
+	}
+	provider.GetGeometry(ctx, "world-1", "player-1")
+	if n := sm.single.Load(); n != 1 {
+		t.Errorf("expected player-1 from the cache, got %d single requests", n)
+	}
+}
+
+func TestSemanticMemoryProviderRefresh(t *testing.T) {
+	sm := &fakeSemanticMemory{entities: map[string]map[string]interface{}{"city-1": circleEntity("city-1", 10, 5)}}
+	provider, _ := newTestProvider(t, sm)
+	ctx := context.Background()
+
+	provider.GetGeometry(ctx, "world-1", "city-1")
+	sm.mu.Lock()
+	sm.entities["city-1"] = circleEntity("city-1", 10, 50)
+	sm.mu.Unlock()
+
+	ids := make([]string, maxGeometryBatch+1)
+	ids[0] = "city-1"
+	for i := 1; i < len(ids); i++ {
+		ids[i] = "unknown"
+	}
+	geometries, err := provider.Refresh(ctx, "world-1", ids)
+	if err != nil || geometries["city-1"].Circle.Radius != 50 {
+		t.Fatalf("expected refreshed geometry, got %+v (%v)", geometries, err)
+	}
+	if n := sm.batch.Load(); n != 2 {
+		t.Errorf("expected %d ids to be split into 2 batches, got %d", len(ids), n)
+	}
+	if geometry, _ := provider.GetGeometry(ctx, "world-1", "city-1"); geometry.Circle.Radius != 50 || sm.single.Load() != 1 {
+		t.Errorf("expected the refreshed geometry from the cache, got %+v", geometry)
+	}
+
+	// Сущность, потерявшая геометрию, удаляется из кэша
+	sm.mu.Lock()
+	delete(sm.entities, "city-1")
+	sm.mu.Unlock()
+	provider.Refresh(ctx, "world-1", []string{"city-1"})
+	if _, err := provider.GetGeometry(ctx, "world-1", "city-1"); err == nil {
+		t.Error("expected the removed geometry to be requested again")
+	}
+}
@@ -2,16 +2,49 @@
 
 package spatial
 
-// VisibilityScope — динамическая область наблюдения.
+// VisibilityScope — динамическая область наблюдения: круг (Center, Radius) или полигон
+// с буфером Margin. Нулевая область (нет геометрии) не содержит ни одной точки.
 type VisibilityScope struct {
 	Center   Point
 	Radius   float64
 	Polygon  *Polygon
+	Margin   float64                     // буфер вокруг полигона
 	Override func(eventPoint Point) bool `json:"-"` // Кастомная логика (опционально)
 }
 
-// DefaultScope создаёт область по типу и параметрам.
+// IsEmpty — область без геометрии (например, геометрия scope ещё неизвестна).
+func (vs *VisibilityScope) IsEmpty() bool {
+	return vs.Override == nil && vs.Polygon == nil && vs.Radius <= 0
+}
+
+// Contains — то же, что IsInScope (VisibilityScope реализует Shape).
+func (vs *VisibilityScope) Contains(p Point) bool {
+	return vs.IsInScope(p)
+}
+
+// Bounds возвращает ограничивающий прямоугольник области; для бесконечной
+// области и области с Override — бесконечный.
+func (vs *VisibilityScope) Bounds() BoundingBox {
+	switch {
+	case vs.Override != nil || vs.Radius > 1e8:
+		return InfiniteBounds()
+	case vs.Polygon != nil:
+		return vs.Polygon.Bounds().Expand(vs.Margin)
+	default:
+		return Circle{Center: vs.Center, Radius: vs.Radius}.Bounds()
+	}
+}
+
+// DefaultScope создаёт область по типу и параметрам. Без геометрии у мира и вселенной
+// область бесконечна, у остальных — пуста. Локация или регион с полигоном получают
+// сам полигон с буфером 200 вместо описанного круга.
 func DefaultScope(entityType string, geometry *Geometry, config map[string]interface{}) VisibilityScope {
+	switch entityType {
+	case "player", "group", "location", "region", "city":
+		if geometry == nil {
+			return VisibilityScope{}
+		}
+	}
 	baseRadius := 200.0
 	switch entityType {
 	case "player":
@@ -20,7 +53,11 @@ func DefaultScope(entityType string, geometry *Geometry, config map[string]inter
 		}
 	case "group":
 		baseRadius = 300 + geometry.MaxRadius()
-	case "location", "region":
+	case "location", "region", "city":
+		if geometry.Polygon != nil && len(*geometry.Polygon) >= 3 {
+			polygon := append(Polygon(nil), *geometry.Polygon...)
+			return VisibilityScope{Center: geometry.Center(), Polygon: &polygon, Margin: 200}
+		}
 		baseRadius = geometry.MaxRadius() + 200
 	default: // world, universe
 		return VisibilityScope{Radius: 1e9}
@@ -33,11 +70,14 @@ func DefaultScope(entityType string, geometry *Geometry, config map[string]inter
 	}
 }
 
-// Buffer расширяет область на заданное расстояние (метров).
+// Buffer расширяет область на заданное расстояние (метров). У полигона растёт Margin,
+// форма полигона сохраняется.
 func (vs *VisibilityScope) Buffer(distance float64) VisibilityScope {
+	if vs.IsEmpty() {
+		return *vs
+	}
 	if vs.Polygon != nil {
-		buffered := vs.Polygon.Buffer(distance)
-		return VisibilityScope{Polygon: &buffered}
+		return VisibilityScope{Center: vs.Center, Polygon: vs.Polygon, Margin: vs.Margin + distance}
 	}
 	return VisibilityScope{
 		Center: vs.Center,
@@ -37,29 +37,67 @@ func (poly *Polygon) Buffer(distance float64) Polygon {
 	}
 }
 
-// Contains проверяет, содержится ли точка в прямоугольнике.
+// Contains проверяет, лежит ли точка внутри полигона (ray casting, граница включительно).
+// Полигон может быть невыпуклым; замыкающая вершина не обязательна.
 func (poly *Polygon) Contains(p Point) bool {
-	if len(*poly) != 4 {
-		return false // поддерживаем только прямоугольники
+	pts := *poly
+	if len(pts) < 3 {
+		return false
 	}
-	minX := min((*poly)[0].X, (*poly)[2].X)
-	maxX := max((*poly)[0].X, (*poly)[2].X)
-	minY := min((*poly)[0].Y, (*poly)[2].Y)
-	maxY := max((*poly)[0].Y, (*poly)[2].Y)
-	return p.X >= minX && p.X <= maxX && p.Y >= minY && p.Y <= maxY
+	inside := false
+	for i, j := 0, len(pts)-1; i < len(pts); j, i = i, i+1 {
+		a, b := pts[i], pts[j]
+		if distanceToSegment(p, a, b) <= boundaryEpsilon {
+			return true
+		}
+		if (a.Y > p.Y) != (b.Y > p.Y) && p.X < (b.X-a.X)*(p.Y-a.Y)/(b.Y-a.Y)+a.X {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// Bounds возвращает ограничивающий прямоугольник полигона.
+func (poly *Polygon) Bounds() BoundingBox {
+	if len(*poly) == 0 {
+		return BoundingBox{}
+	}
+	box := BoundingBox{Min: (*poly)[0], Max: (*poly)[0]}
+	for _, p := range *poly {
+		box.Min.X = min(box.Min.X, p.X)
+		box.Min.Y = min(box.Min.Y, p.Y)
+		box.Max.X = max(box.Max.X, p.X)
+		box.Max.Y = max(box.Max.Y, p.Y)
+	}
+	return box
 }
 
-func min(a, b float64) float64 {
-	if a < b {
-		return a
+// Distance возвращает расстояние от точки до полигона (0 — точка внутри или на границе).
+func (poly *Polygon) Distance(p Point) float64 {
+	if poly.Contains(p) {
+		return 0
 	}
-	return b
+	pts := *poly
+	best := math.Inf(1)
+	for i, j := 0, len(pts)-1; i < len(pts); j, i = i, i+1 {
+		best = min(best, distanceToSegment(p, pts[i], pts[j]))
+	}
+	return best
 }
-func max(a, b float64) float64 {
-	if a > b {
-		return a
+
+// boundaryEpsilon — допуск, с которым точка считается лежащей на границе
+const boundaryEpsilon = 1e-9
+
+// distanceToSegment — расстояние от точки до отрезка ab.
+func distanceToSegment(p, a, b Point) float64 {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	lengthSq := dx*dx + dy*dy
+	if lengthSq == 0 {
+		return DistanceBetween(p, a)
 	}
-	return b
+	t := ((p.X-a.X)*dx + (p.Y-a.Y)*dy) / lengthSq
+	t = max(0, min(1, t))
+	return DistanceBetween(p, Point{X: a.X + t*dx, Y: a.Y + t*dy})
 }
 
 // DistanceBetween вычисляет евклидово расстояние.
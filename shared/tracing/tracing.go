@@ -0,0 +1,127 @@
+// shared/tracing/tracing.go
+//
+// Трассировка OpenTelemetry: одна генерация повествования проходит через несколько сервисов
+// (шина событий, Oracle, MinIO, ChromaDB, Neo4j), и общий trace_id связывает все её шаги.
+// Контекст трассировки передаётся в заголовках Kafka (см. eventbus) и HTTP (W3C traceparent).
+
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"time"
+
+	"multiverse-core.io/shared/logging"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Переменные окружения экспорта (стандартные имена OpenTelemetry).
+const (
+	// EnvEndpoint — адрес OTLP/HTTP-коллектора, например http://otel-collector:4318
+	EnvEndpoint = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	// EnvTracesEndpoint — адрес только для трасс; важнее EnvEndpoint
+	EnvTracesEndpoint = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
+)
+
+// TracerName — имя трассировщика спанов, созданных через Start.
+const TracerName = "multiverse-core.io/shared/tracing"
+
+// shutdownTimeout ограничивает отправку накопленных спанов при остановке сервиса.
+const shutdownTimeout = 5 * time.Second
+
+// Setup подключает трассировку в main сервиса. Распространение контекста (W3C traceparent и baggage)
+// включается всегда, чтобы сервис без экспорта не разрывал цепочку; спаны экспортируются,
+// только если задан OTLP endpoint. Возвращает функцию, отправляющую накопленные спаны при остановке.
+// Ошибка создания экспортёра только логируется — сервис работает без экспорта.
+func Setup(service string) func() {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	if os.Getenv(EnvEndpoint) == "" && os.Getenv(EnvTracesEndpoint) == "" {
+		logging.Infof("Tracing export disabled: %s is not set", EnvEndpoint)
+		return func() {}
+	}
+
+	ctx := context.Background()
+	// Endpoint, заголовки и таймаут экспортёр читает из OTEL_EXPORTER_OTLP_* сам
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		logging.Errorf("Failed to create trace exporter: %v", err)
+		return func() {}
+	}
+	// OTEL_SERVICE_NAME и OTEL_RESOURCE_ATTRIBUTES важнее имени сервиса из кода
+	res, err := resource.New(ctx,
+		resource.WithSchemaURL(semconv.SchemaURL),
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(semconv.ServiceName(service)),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		logging.Errorf("Failed to detect trace resource: %v", err)
+	}
+
+	// Сэмплер задаётся OTEL_TRACES_SAMPLER / OTEL_TRACES_SAMPLER_ARG (по умолчанию — все трассы)
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	logging.Infof("Tracing enabled for %s", service)
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := provider.Shutdown(ctx); err != nil {
+			logging.Errorf("Failed to flush traces: %v", err)
+		}
+	}
+}
+
+// NewTransport оборачивает base (nil — http.DefaultTransport) спанами исходящих запросов:
+// спан "<component> <METHOD>" на каждый запрос, заголовок traceparent для принимающей стороны.
+func NewTransport(component string, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return otelhttp.NewTransport(base, otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+		return component + " " + r.Method
+	}))
+}
+
+// NewHandler продолжает трассировку входящих HTTP-запросов: спан "<component> <METHOD> <path>"
+// становится дочерним по отношению к traceparent вызывающего сервиса.
+func NewHandler(component string, handler http.Handler) http.Handler {
+	return otelhttp.NewHandler(handler, component, otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+		return component + " " + r.Method + " " + r.URL.Path
+	}))
+}
+
+// Start начинает спан операции name, дочерний по отношению к спану ctx. Спаны пакетной обработки
+// связываются с трассами каждого элемента через linked (недействительные контексты пропускаются).
+func Start(ctx context.Context, name string, linked ...trace.SpanContext) (context.Context, trace.Span) {
+	var links []trace.Link
+	for _, sc := range linked {
+		if sc.IsValid() {
+			links = append(links, trace.Link{SpanContext: sc})
+		}
+	}
+	return otel.Tracer(TracerName).Start(ctx, name, trace.WithLinks(links...))
+}
+
+// End завершает спан, отмечая его ошибкой err, если она не nil.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
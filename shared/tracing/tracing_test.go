@@ -0,0 +1,80 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func useRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	})
+	return recorder
+}
+
+func TestTransportAndHandlerShareTrace(t *testing.T) {
+	recorder := useRecorder(t)
+
+	server := httptest.NewServer(NewHandler("semantic-memory", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})))
+	defer server.Close()
+
+	ctx, parent := Start(context.Background(), "narrative.generate")
+	client := &http.Client{Transport: NewTransport("oracle", nil)}
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, server.URL+"/v1/context", nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	End(parent, nil)
+
+	names := make(map[string]bool)
+	for _, span := range recorder.Ended() {
+		names[span.Name()] = true
+		if span.SpanContext().TraceID() != parent.SpanContext().TraceID() {
+			t.Errorf("span %q is outside the caller's trace", span.Name())
+		}
+	}
+	for _, name := range []string{"narrative.generate", "oracle POST", "semantic-memory POST /v1/context"} {
+		if !names[name] {
+			t.Errorf("expected span %q, got %v", name, names)
+		}
+	}
+}
+
+func TestStartLinksAndEnd(t *testing.T) {
+	recorder := useRecorder(t)
+
+	_, first := Start(context.Background(), "first")
+	End(first, nil)
+	_, batch := Start(context.Background(), "batch", first.SpanContext(), noop.Span{}.SpanContext())
+	End(batch, errors.New("neo4j unavailable"))
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	if links := spans[1].Links(); len(links) != 1 || links[0].SpanContext.SpanID() != first.SpanContext().SpanID() {
+		t.Errorf("expected only the valid span context to be linked, got %v", links)
+	}
+	if spans[1].Status().Code != codes.Error || len(spans[1].Events()) != 1 {
+		t.Errorf("expected the error to be recorded, got %v", spans[1].Status())
+	}
+}
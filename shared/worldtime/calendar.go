@@ -0,0 +1,153 @@
+// Package worldtime описывает мировое время: часы мира, идущие со своей скоростью,
+// и внутриигровой календарь (фазы суток, сезоны, праздники).
+//
+// Мировое время — длительность от сотворения мира; дата по нему вычисляется календарём:
+//
+//	date := worldtime.DefaultCalendar().Date(clock.At(time.Now()))
+package worldtime
+
+import (
+	"fmt"
+	"time"
+)
+
+// DayPhase — фаза суток, начинающаяся с доли суток Start (0 — полночь, 0.5 — полдень).
+type DayPhase struct {
+	Name  string  `json:"name"`
+	Start float64 `json:"start"`
+}
+
+// Festival — праздник с дня Day (с 1) сезона Season (индекс в Calendar.Seasons), длится Days дней.
+type Festival struct {
+	Name   string `json:"name"`
+	Season int    `json:"season"`
+	Day    int    `json:"day"`
+	Days   int    `json:"days"`
+}
+
+// Calendar — внутриигровой календарь.
+type Calendar struct {
+	// DayLength — длительность суток в мировом времени
+	DayLength     time.Duration `json:"day_length"`
+	DaysPerSeason int           `json:"days_per_season"`
+	Seasons       []string      `json:"seasons"`
+	// DayPhases — фазы по возрастанию Start; до первой фазы продолжается последняя
+	DayPhases []DayPhase `json:"day_phases"`
+	Festivals []Festival `json:"festivals,omitempty"`
+}
+
+// DefaultCalendar возвращает календарь по умолчанию: сутки по 24 часа, четыре сезона по 30 дней.
+func DefaultCalendar() Calendar {
+	return Calendar{
+		DayLength:     24 * time.Hour,
+		DaysPerSeason: 30,
+		Seasons:       []string{"весна", "лето", "осень", "зима"},
+		DayPhases: []DayPhase{
+			{Name: "ночь", Start: 0},
+			{Name: "утро", Start: 5.0 / 24},
+			{Name: "день", Start: 12.0 / 24},
+			{Name: "вечер", Start: 18.0 / 24},
+			{Name: "ночь", Start: 22.0 / 24},
+		},
+		Festivals: []Festival{
+			{Name: "Праздник Пробуждения", Season: 0, Day: 1, Days: 3},
+			{Name: "Ночь Фонарей", Season: 1, Day: 15, Days: 1},
+			{Name: "Праздник Урожая", Season: 2, Day: 20, Days: 3},
+			{Name: "Ночь Долгой Тьмы", Season: 3, Day: 30, Days: 1},
+		},
+	}
+}
+
+// Date — дата мирового времени.
+type Date struct {
+	// Year — год от сотворения мира, с 1
+	Year int `json:"year"`
+	// Season — название сезона, SeasonIndex — его номер в календаре
+	Season      string `json:"season"`
+	SeasonIndex int    `json:"season_index"`
+	// Day — день сезона, с 1; DayOfYear — день года, с 1
+	Day       int `json:"day"`
+	DayOfYear int `json:"day_of_year"`
+	// Hour и Minute — время суток, приведённое к 24 часам независимо от DayLength
+	Hour     int    `json:"hour"`
+	Minute   int    `json:"minute"`
+	DayPhase string `json:"day_phase"`
+	Festival string `json:"festival,omitempty"`
+}
+
+// String возвращает дату в виде «год 3, весна, день 12, 14:05 (день)».
+func (d Date) String() string {
+	s := fmt.Sprintf("год %d, %s, день %d, %02d:%02d (%s)", d.Year, d.Season, d.Day, d.Hour, d.Minute, d.DayPhase)
+	if d.Festival != "" {
+		s += ", " + d.Festival
+	}
+	return s
+}
+
+// Date возвращает дату для мирового времени worldTime (отрицательное считается нулём).
+func (c Calendar) Date(worldTime time.Duration) Date {
+	c = c.withDefaults()
+	if worldTime < 0 {
+		worldTime = 0
+	}
+
+	days := int(worldTime / c.DayLength)
+	fraction := float64(worldTime%c.DayLength) / float64(c.DayLength)
+	daysPerYear := c.DaysPerSeason * len(c.Seasons)
+	dayOfYear := days % daysPerYear
+	season := dayOfYear / c.DaysPerSeason
+	minutes := int(fraction * 24 * 60)
+
+	date := Date{
+		Year:        days/daysPerYear + 1,
+		Season:      c.Seasons[season],
+		SeasonIndex: season,
+		Day:         dayOfYear%c.DaysPerSeason + 1,
+		DayOfYear:   dayOfYear + 1,
+		Hour:        minutes / 60,
+		Minute:      minutes % 60,
+		DayPhase:    c.dayPhase(fraction),
+	}
+	date.Festival = c.festival(season, date.Day)
+	return date
+}
+
+// dayPhase возвращает фазу суток для доли суток fraction.
+func (c Calendar) dayPhase(fraction float64) string {
+	if len(c.DayPhases) == 0 {
+		return ""
+	}
+	phase := c.DayPhases[len(c.DayPhases)-1].Name
+	for _, p := range c.DayPhases {
+		if fraction >= p.Start {
+			phase = p.Name
+		}
+	}
+	return phase
+}
+
+// festival возвращает праздник, идущий в день day сезона season.
+func (c Calendar) festival(season, day int) string {
+	for _, f := range c.Festivals {
+		days := max(f.Days, 1)
+		if f.Season == season && day >= f.Day && day < f.Day+days {
+			return f.Name
+		}
+	}
+	return ""
+}
+
+// withDefaults заполняет незаданные поля значениями DefaultCalendar.
+func (c Calendar) withDefaults() Calendar {
+	defaults := DefaultCalendar()
+	if c.DayLength <= 0 {
+		c.DayLength = defaults.DayLength
+	}
+	if c.DaysPerSeason <= 0 {
+		c.DaysPerSeason = defaults.DaysPerSeason
+	}
+	if len(c.Seasons) == 0 {
+		c.Seasons = defaults.Seasons
+	}
+	return c
+}
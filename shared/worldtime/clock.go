@@ -0,0 +1,33 @@
+package worldtime
+
+import "time"
+
+// Clock — часы мира: от точки привязки мировое время растёт в Rate раз быстрее реального.
+// Смена скорости переносит точку привязки, поэтому мировое время не скачет.
+type Clock struct {
+	AnchorReal    time.Time `json:"anchor_real"`
+	AnchorWorldMs int64     `json:"anchor_world_ms"`
+	// Rate — мировых секунд на реальную секунду
+	Rate float64 `json:"rate"`
+}
+
+// NewClock запускает часы нового мира с нулевого мирового времени в момент now.
+func NewClock(now time.Time, rate float64) Clock {
+	return Clock{AnchorReal: now.UTC(), Rate: rate}
+}
+
+// At возвращает мировое время в реальный момент now; до точки привязки время не идёт назад.
+func (c Clock) At(now time.Time) time.Duration {
+	elapsed := now.Sub(c.AnchorReal)
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	return time.Duration(c.AnchorWorldMs)*time.Millisecond + time.Duration(float64(elapsed)*c.Rate)
+}
+
+// SetRate меняет скорость часов с момента now.
+func (c *Clock) SetRate(now time.Time, rate float64) {
+	c.AnchorWorldMs = c.At(now).Milliseconds()
+	c.AnchorReal = now.UTC()
+	c.Rate = rate
+}
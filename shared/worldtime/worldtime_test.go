@@ -0,0 +1,58 @@
+package worldtime
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalendarDate(t *testing.T) {
+	calendar := DefaultCalendar()
+
+	start := calendar.Date(0)
+	if start.Year != 1 || start.Season != "весна" || start.Day != 1 || start.DayPhase != "ночь" || start.Festival != "Праздник Пробуждения" {
+		t.Errorf("world start = %+v", start)
+	}
+
+	// 45 дней и 14:30 — 16-й день лета, без праздника
+	date := calendar.Date(45*24*time.Hour + 14*time.Hour + 30*time.Minute)
+	if date.Season != "лето" || date.Day != 16 || date.DayOfYear != 46 || date.Hour != 14 || date.Minute != 30 || date.DayPhase != "день" {
+		t.Errorf("date = %+v", date)
+	}
+	if date.Festival != "" {
+		t.Errorf("festival = %q, want none", date.Festival)
+	}
+
+	// Ночь Фонарей — 15-й день лета; год — 120 дней
+	lanterns := calendar.Date((120+44)*24*time.Hour + 23*time.Hour)
+	if lanterns.Year != 2 || lanterns.Festival != "Ночь Фонарей" || lanterns.DayPhase != "ночь" {
+		t.Errorf("lanterns = %+v", lanterns)
+	}
+}
+
+func TestCalendarDayLength(t *testing.T) {
+	calendar := Calendar{DayLength: 12 * time.Hour}
+	date := calendar.Date(18 * time.Hour)
+	if date.Day != 2 || date.Hour != 12 || date.Season != "весна" {
+		t.Errorf("half-length day date = %+v", date)
+	}
+}
+
+func TestClockSetRate(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewClock(start, 60)
+
+	if got := clock.At(start.Add(time.Minute)); got != time.Hour {
+		t.Errorf("one real minute at rate 60 = %v, want 1h", got)
+	}
+	if got := clock.At(start.Add(-time.Minute)); got != 0 {
+		t.Errorf("world time before the anchor = %v, want 0", got)
+	}
+
+	clock.SetRate(start.Add(time.Minute), 120)
+	if got := clock.At(start.Add(time.Minute)); got != time.Hour {
+		t.Errorf("rate change moved world time to %v", got)
+	}
+	if got := clock.At(start.Add(2 * time.Minute)); got != 3*time.Hour {
+		t.Errorf("after rate change = %v, want 3h", got)
+	}
+}